@@ -0,0 +1,2 @@
+ALTER TABLE `bkiam`.`expression` ADD COLUMN `ref_count` INT NOT NULL DEFAULT 0 AFTER `type`;
+UPDATE `bkiam`.`expression` e INNER JOIN (SELECT `expression_pk`, COUNT(*) AS `cnt` FROM `bkiam`.`policy` GROUP BY `expression_pk`) p ON e.`pk` = p.`expression_pk` SET e.`ref_count` = p.`cnt`;
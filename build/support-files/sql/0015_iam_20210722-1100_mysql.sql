@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `bkiam`.`subject_department_history` (
+  `pk` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `subject_pk` INT UNSIGNED NOT NULL,
+  `department_pk` INT UNSIGNED NOT NULL,
+  `action` VARCHAR(16) NOT NULL,  /* added or removed */
+  `source` VARCHAR(64) NOT NULL DEFAULT '',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`pk`),
+  KEY `idx_subject_created` (`subject_pk`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
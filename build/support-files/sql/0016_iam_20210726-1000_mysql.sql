@@ -0,0 +1,2 @@
+/* the built-in subject represents all authenticated users */
+INSERT IGNORE INTO `bkiam`.`subject` (`type`, `id`, `name`) VALUES ("special", "all_users", "所有用户");
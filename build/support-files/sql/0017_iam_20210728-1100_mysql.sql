@@ -0,0 +1,2 @@
+/* the effect of policy, deny overrides allow on the same action/resource */
+ALTER TABLE `bkiam`.`policy` ADD COLUMN `effect` VARCHAR(8) NOT NULL DEFAULT 'allow' AFTER `expression_pk`;
@@ -0,0 +1,2 @@
+/* the priority of policy, only works for the systems in `first_match` evaluation mode, higher is evaluated first */
+ALTER TABLE `bkiam`.`policy` ADD COLUMN `priority` INT NOT NULL DEFAULT 0 AFTER `effect`;
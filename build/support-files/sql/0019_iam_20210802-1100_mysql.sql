@@ -0,0 +1,2 @@
+/* the status of action, the disabled action will be denied(or bypassed, configurable) by the pdp */
+ALTER TABLE `bkiam`.`saas_action` ADD COLUMN `status` VARCHAR(16) NOT NULL DEFAULT 'enabled' AFTER `type`;
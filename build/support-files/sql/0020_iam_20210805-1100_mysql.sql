@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `bkiam`.`subject_role_history` (
+  `pk` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `subject_pk` INT UNSIGNED NOT NULL,
+  `role_type` VARCHAR(32) NOT NULL,
+  `system_id` VARCHAR(32) NOT NULL,
+  `action` VARCHAR(16) NOT NULL,  /* granted or revoked */
+  `operator` VARCHAR(64) NOT NULL DEFAULT '',
+  `source` VARCHAR(64) NOT NULL DEFAULT '',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,  /* the role change takes effect from */
+  PRIMARY KEY (`pk`),
+  KEY `idx_role_created` (`role_type`, `system_id`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
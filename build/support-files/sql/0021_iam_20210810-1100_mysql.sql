@@ -0,0 +1,3 @@
+/* the delivery status of the system's model change event callback, empty if the system has no callback registered */
+ALTER TABLE `bkiam`.`model_change_event` ADD COLUMN `callback_status` VARCHAR(16) NOT NULL DEFAULT '' AFTER `model_pk`;
+ALTER TABLE `bkiam`.`model_change_event` ADD COLUMN `callback_message` VARCHAR(255) NOT NULL DEFAULT '' AFTER `callback_status`;
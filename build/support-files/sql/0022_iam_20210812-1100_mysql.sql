@@ -0,0 +1,3 @@
+ALTER TABLE `bkiam`.`subject_role_history` ADD INDEX `idx_subject_created` (`subject_pk`, `created_at`);
+ALTER TABLE `bkiam`.`subject_role_history` ADD INDEX `idx_created` (`created_at`);
+ALTER TABLE `bkiam`.`subject_department_history` ADD INDEX `idx_created` (`created_at`);
@@ -0,0 +1,23 @@
+ALTER TABLE `bkiam`.`subject_relation` ADD INDEX `idx_parent_pk` (`parent_pk`);
+
+CREATE TABLE IF NOT EXISTS `bkiam`.`subject_system_group` (
+  `pk` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `subject_pk` INT UNSIGNED NOT NULL,
+  `system_id` VARCHAR(32) NOT NULL,
+  `group_pk` INT UNSIGNED NOT NULL,  /* the group which has policies in the system */
+  `policy_expired_at` INT UNSIGNED NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`pk`),
+  UNIQUE KEY `idx_uk_subject_system_group` (`subject_pk`, `system_id`, `group_pk`),
+  KEY `idx_system_group` (`system_id`, `group_pk`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+/* backfill: the groups joined directly which have policies in the system */
+INSERT IGNORE INTO `bkiam`.`subject_system_group` (`subject_pk`, `system_id`, `group_pk`, `policy_expired_at`)
+  SELECT r.`subject_pk`, g.`system_id`, r.`parent_pk`, r.`policy_expired_at`
+  FROM `bkiam`.`subject_relation` r INNER JOIN (
+    SELECT DISTINCT p.`subject_pk`, a.`system_id` FROM `bkiam`.`policy` p
+    INNER JOIN `bkiam`.`action` a ON a.`pk` = p.`action_pk`
+  ) g ON g.`subject_pk` = r.`parent_pk`
+  WHERE r.`policy_expired_at` > UNIX_TIMESTAMP();
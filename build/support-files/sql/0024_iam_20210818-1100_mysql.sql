@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `bkiam`.`subject_attribute_provider` (
+  `pk` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `id` VARCHAR(32) NOT NULL,
+  `type` VARCHAR(16) NOT NULL,  /* http or static */
+  `attributes` VARCHAR(1024) NOT NULL DEFAULT '',  /* json list, the subject attributes provided */
+  `config` MEDIUMTEXT NOT NULL,  /* json, http: url/token/timeout, static: the attributes of each subject */
+  `cache_ttl` INT UNSIGNED NOT NULL DEFAULT 0,  /* seconds, the cache expiration of the http provider */
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`pk`),
+  UNIQUE KEY `idx_uk_id` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
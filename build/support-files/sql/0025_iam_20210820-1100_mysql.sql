@@ -0,0 +1 @@
+ALTER TABLE `bkiam`.`subject_relation` ADD INDEX `idx_expire` (`policy_expired_at`);
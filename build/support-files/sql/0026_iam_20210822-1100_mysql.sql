@@ -0,0 +1,4 @@
+ALTER TABLE `bkiam`.`subject`
+  ADD INDEX `idx_type_id` (`type`, `id`),
+  ADD INDEX `idx_type_name` (`type`, `name`),
+  ADD INDEX `idx_name` (`name`);
@@ -0,0 +1,3 @@
+ALTER TABLE `bkiam`.`subject`
+  ADD COLUMN `frozen` TINYINT(1) NOT NULL DEFAULT 0,
+  ADD INDEX `idx_frozen` (`frozen`);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS `bkiam`.`subject_member_event` (
+  `pk` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `group_type` VARCHAR(32) NOT NULL,
+  `group_id` VARCHAR(64) NOT NULL,
+  `member_type` VARCHAR(32) NOT NULL,
+  `member_id` VARCHAR(64) NOT NULL,
+  `action` VARCHAR(16) NOT NULL,  /* added, removed or renewed */
+  `policy_expired_at` INT UNSIGNED NOT NULL DEFAULT 0,
+  `operator` VARCHAR(64) NOT NULL DEFAULT '',
+  `source` VARCHAR(64) NOT NULL DEFAULT '',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,  /* the membership change takes effect from */
+  PRIMARY KEY (`pk`),
+  KEY `idx_group_created` (`group_type`, `group_id`, `created_at`),
+  KEY `idx_operator_created` (`operator`, `created_at`),
+  KEY `idx_created` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `bkiam`.`subject_member_snapshot` (
+  `pk` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `group_type` VARCHAR(32) NOT NULL,
+  `group_id` VARCHAR(64) NOT NULL,
+  `member_count` INT UNSIGNED NOT NULL DEFAULT 0,
+  `members` MEDIUMTEXT NOT NULL,  /* JSON */
+  `operator` VARCHAR(64) NOT NULL DEFAULT '',
+  `source` VARCHAR(64) NOT NULL DEFAULT '',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`pk`),
+  KEY `idx_group_created` (`group_type`, `group_id`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
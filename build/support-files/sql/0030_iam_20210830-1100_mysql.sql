@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `bkiam`.`department_relation` (
+  `pk` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `department_pk` BIGINT UNSIGNED NOT NULL,
+  `parent_pk` BIGINT UNSIGNED NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`pk`),
+  UNIQUE KEY `idx_uk_department` (`department_pk`),
+  KEY `idx_parent` (`parent_pk`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
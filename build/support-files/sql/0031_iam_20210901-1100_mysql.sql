@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `bkiam`.`group_setting` (
+  `pk` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `group_pk` BIGINT UNSIGNED NOT NULL,
+  `max_members` INT UNSIGNED NOT NULL DEFAULT 0,  /* 0 means unlimited */
+  `default_expiration_days` INT UNSIGNED NOT NULL DEFAULT 0,
+  `max_expiration_days` INT UNSIGNED NOT NULL DEFAULT 0,  /* 0 means unlimited */
+  `renewal_window_days` INT UNSIGNED NOT NULL DEFAULT 0,  /* 0 means renew at any time */
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`pk`),
+  UNIQUE KEY `idx_uk_group` (`group_pk`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
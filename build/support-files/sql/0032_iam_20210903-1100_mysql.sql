@@ -0,0 +1 @@
+ALTER TABLE `bkiam`.`policy` ADD INDEX `idx_expired_at_pk` (`expired_at`, `pk`);
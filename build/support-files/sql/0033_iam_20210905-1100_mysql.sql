@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS `bkiam`.`department_member` (
+  `pk` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `department_pk` INT UNSIGNED NOT NULL,
+  `subject_pk` INT UNSIGNED NOT NULL,  /* belongs to the department directly, same as subject_department */
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`pk`),
+  UNIQUE KEY `idx_uk_department_subject` (`department_pk`, `subject_pk`),
+  KEY `idx_subject` (`subject_pk`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+/* backfill: split the comma separated subject_department.department_pks, department_pks is at most 1024 chars */
+INSERT IGNORE INTO `bkiam`.`department_member` (`department_pk`, `subject_pk`)
+  SELECT
+    CAST(SUBSTRING_INDEX(SUBSTRING_INDEX(sd.`department_pks`, ',', n.`n`), ',', -1) AS UNSIGNED),
+    sd.`subject_pk`
+  FROM `bkiam`.`subject_department` sd
+  JOIN (
+    SELECT a.`d` + b.`d` * 10 + c.`d` * 100 + 1 AS `n`
+    FROM (SELECT 0 AS `d` UNION ALL SELECT 1 UNION ALL SELECT 2 UNION ALL SELECT 3 UNION ALL SELECT 4
+      UNION ALL SELECT 5 UNION ALL SELECT 6 UNION ALL SELECT 7 UNION ALL SELECT 8 UNION ALL SELECT 9) a,
+      (SELECT 0 AS `d` UNION ALL SELECT 1 UNION ALL SELECT 2 UNION ALL SELECT 3 UNION ALL SELECT 4
+      UNION ALL SELECT 5 UNION ALL SELECT 6 UNION ALL SELECT 7 UNION ALL SELECT 8 UNION ALL SELECT 9) b,
+      (SELECT 0 AS `d` UNION ALL SELECT 1 UNION ALL SELECT 2 UNION ALL SELECT 3 UNION ALL SELECT 4
+      UNION ALL SELECT 5 UNION ALL SELECT 6 UNION ALL SELECT 7 UNION ALL SELECT 8 UNION ALL SELECT 9) c
+  ) n ON n.`n` <= 1 + LENGTH(sd.`department_pks`) - LENGTH(REPLACE(sd.`department_pks`, ',', ''))
+  WHERE sd.`department_pks` != '';
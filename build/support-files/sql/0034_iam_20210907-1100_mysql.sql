@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `bkiam`.`subject_template` (
+  `pk` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `subject_pk` INT UNSIGNED NOT NULL,
+  `template_id` INT UNSIGNED NOT NULL,
+  `expired_at` INT UNSIGNED NOT NULL,  /* the expired_at of all the policies generated by the template */
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`pk`),
+  UNIQUE KEY `idx_uk_subject_template` (`subject_pk`, `template_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
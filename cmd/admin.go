@@ -0,0 +1,415 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"iam/pkg/abac/pdp"
+	abactypes "iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/api/common"
+	"iam/pkg/cache/impls"
+	"iam/pkg/logging/debug"
+	"iam/pkg/offboarding"
+	"iam/pkg/service"
+	"iam/pkg/service/types"
+)
+
+// adminCmd 运维命令, 直接复用service层, SaaS不可用时可以登录机器执行
+// NOTE: 与server使用同一份配置文件, 只初始化db/redis/cache等必要的依赖, 不启动http server
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Admin commands for BK-IAM operations",
+	Long: `Admin commands sharing the service layer of BK-IAM,
+           usable over SSH when the SaaS is down`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		initAdmin()
+	},
+}
+
+func initAdmin() {
+	if cfgFile != "" {
+		viper.SetConfigFile(cfgFile)
+	}
+	initConfig()
+
+	initLogger()
+	initDatabase()
+	initRedis()
+	// NOTE: should be after initRedis
+	initCaches()
+	initPolicyCacheSettings()
+	initEvalConcurrencyLimits()
+	initEvaluationModes()
+	initEvalFailurePolicies()
+	initRemoteResourceBreakers()
+	initDisabledActionModes()
+	initSubjectRelationEventSink()
+}
+
+// printJSON 命令的结果统一以json格式输出到stdout
+func printJSON(data interface{}) {
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		exitWithError(err)
+	}
+	fmt.Println(string(out))
+}
+
+func exitWithError(err error) {
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(1)
+}
+
+// ======== cache ========
+
+var (
+	cacheSystemID      string
+	cacheActionIDs     []string
+	cacheResourceTypes []string
+	cacheSubjectType   string
+	cacheSubjectIDs    []string
+)
+
+var adminCacheCmd = &cobra.Command{
+	Use:   "invalidate-cache",
+	Short: "Invalidate the caches of system/actions/resource types/subjects",
+	Run: func(cmd *cobra.Command, args []string) {
+		deleted := map[string]interface{}{}
+
+		if cacheSystemID != "" {
+			if len(cacheActionIDs) > 0 {
+				if err := impls.BatchDeleteActionCache(cacheSystemID, cacheActionIDs); err != nil {
+					exitWithError(err)
+				}
+				deleted["actions"] = cacheActionIDs
+			}
+			if len(cacheResourceTypes) > 0 {
+				if err := impls.BatchDeleteResourceTypeCache(cacheSystemID, cacheResourceTypes); err != nil {
+					exitWithError(err)
+				}
+				deleted["resource_types"] = cacheResourceTypes
+			}
+			// 未指定操作/资源类型时, 清理系统本身的缓存
+			if len(cacheActionIDs) == 0 && len(cacheResourceTypes) == 0 {
+				if err := impls.DeleteSystemCache(cacheSystemID); err != nil {
+					exitWithError(err)
+				}
+				deleted["system"] = cacheSystemID
+			}
+		}
+
+		if len(cacheSubjectIDs) > 0 {
+			svc := service.NewSubjectService()
+			pks := make([]int64, 0, len(cacheSubjectIDs))
+			for _, id := range cacheSubjectIDs {
+				pk, err := svc.GetPK(cacheSubjectType, id)
+				if err != nil {
+					exitWithError(fmt.Errorf("get subject pk type=`%s`, id=`%s` fail: %w", cacheSubjectType, id, err))
+				}
+				pks = append(pks, pk)
+
+				if err = impls.DeleteSubjectPK(cacheSubjectType, id); err != nil {
+					exitWithError(err)
+				}
+			}
+			if err := impls.BatchDeleteSubjectCache(pks); err != nil {
+				exitWithError(err)
+			}
+			deleted["subjects"] = cacheSubjectIDs
+		}
+
+		if len(deleted) == 0 {
+			exitWithError(errors.New("nothing to invalidate, should specify --system or --subject-id"))
+		}
+		printJSON(deleted)
+	},
+}
+
+// ======== eval ========
+
+var (
+	evalSystemID     string
+	evalActionID     string
+	evalSubjectType  string
+	evalSubjectID    string
+	evalResources    string
+	evalWithoutCache bool
+)
+
+type adminEvalResource struct {
+	System    string                 `json:"system"`
+	Type      string                 `json:"type"`
+	ID        string                 `json:"id"`
+	Attribute map[string]interface{} `json:"attribute"`
+}
+
+var adminEvalCmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Run a single eval with debug",
+	Example: `  bk-iam admin eval -c config.yaml --system bk_cmdb --action edit_host --subject-id admin \
+    --resources '[{"system": "bk_cmdb", "type": "host", "id": "1"}]'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var resources []adminEvalResource
+		if evalResources != "" {
+			if err := json.Unmarshal([]byte(evalResources), &resources); err != nil {
+				exitWithError(fmt.Errorf("invalid --resources, should be a json array: %w", err))
+			}
+		}
+
+		req := request.NewRequest()
+		req.System = evalSystemID
+		req.Action.ID = evalActionID
+		req.Subject.Type = evalSubjectType
+		req.Subject.ID = evalSubjectID
+		for _, r := range resources {
+			req.Resources = append(req.Resources, abactypes.Resource{
+				System:    r.System,
+				Type:      r.Type,
+				ID:        r.ID,
+				Attribute: r.Attribute,
+			})
+		}
+
+		entry := debug.EntryPool.Get()
+		defer debug.EntryPool.Put(entry)
+
+		allowed, err := pdp.Eval(req, entry, evalWithoutCache)
+		debug.WithError(entry, err)
+
+		printJSON(map[string]interface{}{
+			"allowed": allowed,
+			"debug":   entry,
+		})
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+// ======== cleanup ========
+
+var (
+	cleanupWait         bool
+	cleanupWaitInterval time.Duration
+)
+
+var adminCleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Trigger the cleanup jobs",
+}
+
+var adminCleanupSystemCmd = &cobra.Command{
+	Use:   "system SYSTEM_ID",
+	Short: "Delete the policies and the model of a frozen system",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		systemID := args[0]
+
+		// the system should be frozen first, avoid the model/policies being modified during the cleanup
+		if !common.IsSystemFrozen(systemID) {
+			exitWithError(fmt.Errorf("system(%s) should be frozen before cleanup", systemID))
+		}
+
+		task, err := offboarding.StartSystemCleanup(systemID)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		// NOTE: 清理任务在后台goroutine中执行, 进程退出任务即中断, 默认等待任务结束
+		for cleanupWait && task.Status == offboarding.TaskStatusRunning {
+			time.Sleep(cleanupWaitInterval)
+
+			task, err = offboarding.GetTask(task.ID)
+			if err != nil {
+				exitWithError(err)
+			}
+		}
+		printJSON(task)
+	},
+}
+
+var adminCleanupRedundantMembersCmd = &cobra.Command{
+	Use:   "redundant-members GROUP_ID",
+	Short: "Delete the group members which are fully covered by the departments",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		groupID := args[0]
+
+		svc := service.NewSubjectService()
+		members, err := svc.ListRedundantMembers(types.GroupType, groupID)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		removed := make([]types.Subject, 0, len(members))
+		for _, m := range members {
+			if m.Removable {
+				removed = append(removed, types.Subject{Type: m.Type, ID: m.ID, Name: m.Name})
+			}
+		}
+
+		if len(removed) > 0 {
+			_, err = svc.BulkDeleteSubjectMembers(types.GroupType, groupID, removed)
+			if err != nil {
+				exitWithError(err)
+			}
+		}
+
+		printJSON(map[string]interface{}{
+			"count":   len(removed),
+			"results": removed,
+		})
+	},
+}
+
+// ======== subject groups ========
+
+var (
+	groupsSubjectType string
+	groupsSubjectID   string
+)
+
+// adminSubjectGroup 生效的用户组, source为direct表示直接加入, 否则为继承的部门
+type adminSubjectGroup struct {
+	PK              int64  `json:"pk"`
+	Type            string `json:"type"`
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	PolicyExpiredAt int64  `json:"policy_expired_at"`
+	Source          string `json:"source"`
+}
+
+const adminSubjectGroupSourceDirect = "direct"
+
+var adminSubjectGroupsCmd = &cobra.Command{
+	Use:   "subject-groups",
+	Short: "Inspect the effective groups of a subject, query from database without cache",
+	Run: func(cmd *cobra.Command, args []string) {
+		svc := service.NewSubjectService()
+
+		pk, err := svc.GetPK(groupsSubjectType, groupsSubjectID)
+		if err != nil {
+			exitWithError(fmt.Errorf("get subject pk type=`%s`, id=`%s` fail: %w",
+				groupsSubjectType, groupsSubjectID, err))
+		}
+
+		// 1. 直接加入的用户组
+		now := time.Now().Unix()
+		sources := map[string][]types.ThinSubjectGroup{}
+		directGroups, err := svc.GetThinSubjectGroups(pk)
+		if err != nil {
+			exitWithError(err)
+		}
+		for _, g := range directGroups {
+			if g.PolicyExpiredAt > now {
+				sources[adminSubjectGroupSourceDirect] = append(sources[adminSubjectGroupSourceDirect], g)
+			}
+		}
+
+		// 2. 继承部门加入的用户组
+		deptPKs, err := svc.GetSubjectDepartmentPKs(pk)
+		if err != nil {
+			exitWithError(err)
+		}
+		if len(deptPKs) > 0 {
+			deptGroups, err := svc.ListSubjectEffectGroups(deptPKs)
+			if err != nil {
+				exitWithError(err)
+			}
+			for deptPK, groups := range deptGroups {
+				dept, err := svc.Get(deptPK)
+				if err != nil {
+					exitWithError(err)
+				}
+				source := fmt.Sprintf("%s:%s", types.DepartmentType, dept.ID)
+				sources[source] = append(sources[source], groups...)
+			}
+		}
+
+		results := make([]adminSubjectGroup, 0, len(directGroups))
+		for source, groups := range sources {
+			for _, g := range groups {
+				group, err := svc.Get(g.PK)
+				if err != nil {
+					exitWithError(err)
+				}
+				results = append(results, adminSubjectGroup{
+					PK:              g.PK,
+					Type:            group.Type,
+					ID:              group.ID,
+					Name:            group.Name,
+					PolicyExpiredAt: g.PolicyExpiredAt,
+					Source:          source,
+				})
+			}
+		}
+
+		printJSON(map[string]interface{}{
+			"subject": map[string]interface{}{
+				"pk":             pk,
+				"type":           groupsSubjectType,
+				"id":             groupsSubjectID,
+				"department_pks": deptPKs,
+			},
+			"count":   len(results),
+			"results": results,
+		})
+	},
+}
+
+func init() {
+	adminCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default is config.yml;required)")
+	adminCmd.MarkPersistentFlagRequired("config")
+
+	adminCacheCmd.Flags().StringVar(&cacheSystemID, "system", "", "the system id")
+	adminCacheCmd.Flags().StringSliceVar(&cacheActionIDs, "action", nil,
+		"the action ids of the system, comma separated")
+	adminCacheCmd.Flags().StringSliceVar(&cacheResourceTypes, "resource-type", nil,
+		"the resource type ids of the system, comma separated")
+	adminCacheCmd.Flags().StringVar(&cacheSubjectType, "subject-type", types.UserType, "the subject type")
+	adminCacheCmd.Flags().StringSliceVar(&cacheSubjectIDs, "subject-id", nil, "the subject ids, comma separated")
+
+	adminEvalCmd.Flags().StringVar(&evalSystemID, "system", "", "the system id")
+	adminEvalCmd.Flags().StringVar(&evalActionID, "action", "", "the action id")
+	adminEvalCmd.Flags().StringVar(&evalSubjectType, "subject-type", types.UserType, "the subject type")
+	adminEvalCmd.Flags().StringVar(&evalSubjectID, "subject-id", "", "the subject id")
+	adminEvalCmd.Flags().StringVar(&evalResources, "resources", "",
+		`the resources in json array, e.g. [{"system": "", "type": "", "id": "", "attribute": {}}]`)
+	adminEvalCmd.Flags().BoolVar(&evalWithoutCache, "force", false, "eval without the policy cache")
+	adminEvalCmd.MarkFlagRequired("system")
+	adminEvalCmd.MarkFlagRequired("action")
+	adminEvalCmd.MarkFlagRequired("subject-id")
+
+	adminCleanupSystemCmd.Flags().BoolVar(&cleanupWait, "wait", true, "wait until the cleanup task finished")
+	adminCleanupSystemCmd.Flags().DurationVar(&cleanupWaitInterval, "wait-interval", 2*time.Second,
+		"the interval to check the progress of the cleanup task")
+	adminCleanupCmd.AddCommand(adminCleanupSystemCmd)
+	adminCleanupCmd.AddCommand(adminCleanupRedundantMembersCmd)
+
+	adminSubjectGroupsCmd.Flags().StringVar(&groupsSubjectType, "subject-type", types.UserType, "the subject type")
+	adminSubjectGroupsCmd.Flags().StringVar(&groupsSubjectID, "subject-id", "", "the subject id")
+	adminSubjectGroupsCmd.MarkFlagRequired("subject-id")
+
+	adminCmd.AddCommand(adminCacheCmd)
+	adminCmd.AddCommand(adminEvalCmd)
+	adminCmd.AddCommand(adminCleanupCmd)
+	adminCmd.AddCommand(adminSubjectGroupsCmd)
+
+	rootCmd.AddCommand(adminCmd)
+}
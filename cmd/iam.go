@@ -29,7 +29,14 @@ import (
 	// init debug entry pool
 	_ "iam/pkg/logging/debug"
 
+	"iam/pkg/abac/prp"
+	"iam/pkg/cache/impls"
+	"iam/pkg/cache/invalidation"
+	"iam/pkg/cache/warmup"
+	"iam/pkg/database"
 	"iam/pkg/server"
+	"iam/pkg/service"
+	"iam/pkg/task"
 )
 
 // cmd for iam
@@ -98,7 +105,16 @@ func Start() {
 	initSupportShieldFeatures()
 	initComponents()
 	initQuota()
+	initEvalConcurrencyLimits()
+	initEvaluationModes()
+	initEvalFailurePolicies()
+	initRemoteResourceBreakers()
+	initDisabledActionModes()
 	initSwitch()
+	initMemberAddHooks()
+	initExport()
+	// NOTE: should be after initRedis
+	initSubjectRelationEventSink()
 
 	// 2. watch the signal
 	ctx, cancelFunc := context.WithCancel(context.Background())
@@ -106,7 +122,54 @@ func Start() {
 		interrupt(cancelFunc)
 	}()
 
-	// 3. start the server
+	// 3. subscribe the policy cache invalidation and the cache flush broadcast by all instances,
+	//    and retry the failed invalidations
+	go impls.SubscribePolicyInvalidation(ctx)
+	go impls.SubscribeCacheFlush(ctx)
+	go invalidation.Run(ctx)
+
+	// 4. write the group member change events(audit trail) asynchronously,
+	//    and run the async template unbind tasks, take over the tasks of the exited instances,
+	//    and refresh the subject system groups of the groups with many members asynchronously
+	go service.RunSubjectMemberEventWriter(ctx)
+	go prp.RunTemplateUnbindWorker(ctx)
+	go service.RunSubjectSystemGroupRefreshWorker(ctx)
+
+	// 5. record the hot subjects, and warm up the caches before serving
+	if globalConfig.Warmup.Enabled {
+		impls.EnableHotSubjectRecord()
+		go impls.RunHotSubjectRecorder(ctx)
+
+		warmup.Run(globalConfig.Warmup)
+	}
+
+	// 6. detect the database pressure, for shedding the low priority requests
+	if globalConfig.PriorityLane.Enabled {
+		go database.RunPressureMonitor(
+			ctx,
+			database.GetDefaultDBClient(),
+			time.Duration(globalConfig.PriorityLane.CheckInterval)*time.Second,
+			time.Duration(globalConfig.PriorityLane.WaitThreshold)*time.Millisecond,
+		)
+	}
+
+	// 7. purge the expired group members, and reconcile the subject system groups periodically
+	if globalConfig.ExpiredMemberPurge.Enabled {
+		go task.Schedule(
+			ctx,
+			task.NewExpiredMemberPurgeTask(globalConfig.ExpiredMemberPurge),
+			task.ExpiredMemberPurgeInterval(globalConfig.ExpiredMemberPurge),
+		)
+	}
+	if globalConfig.SubjectSystemGroupReconcile.Enabled {
+		go task.Schedule(
+			ctx,
+			task.NewSubjectSystemGroupReconcileTask(globalConfig.SubjectSystemGroupReconcile),
+			task.SubjectSystemGroupReconcileInterval(globalConfig.SubjectSystemGroupReconcile),
+		)
+	}
+
+	// 8. start the server
 	httpServer := server.NewServer(globalConfig)
 	httpServer.Run(ctx)
 }
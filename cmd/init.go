@@ -17,15 +17,23 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/pdp/evaluation"
+	"iam/pkg/abac/prp/policy"
 	"iam/pkg/api/common"
 	"iam/pkg/cache/impls"
+	"iam/pkg/cache/invalidation"
+	"iam/pkg/cache/memory"
 	"iam/pkg/cache/redis"
 	"iam/pkg/component"
 	"iam/pkg/config"
 	"iam/pkg/database"
 	"iam/pkg/errorx"
+	"iam/pkg/eventsink"
+	"iam/pkg/export"
 	"iam/pkg/logging"
 	"iam/pkg/metric"
+	"iam/pkg/service"
 )
 
 var globalConfig *config.Config
@@ -76,22 +84,41 @@ func initDatabase() {
 		panic("database bk-iam should be configured")
 	}
 
+	// 批量任务使用独立的连接池, 未配置时使用iam的连接信息
+	batchDBConfig, ok := globalConfig.DatabaseMap["iam_batch"]
+	if !ok {
+		batchDBConfig = database.NewBatchDBConfig(defaultDBConfig)
+	} else {
+		batchDBConfig = database.FillBatchDBConfigTimeout(batchDBConfig)
+	}
+
 	// TODO: 不应该成为强依赖
 	bkPaaSDBConfig, ok := globalConfig.DatabaseMap["open_paas"]
 	if !ok {
 		panic("database open_paas should be configured")
 	}
 
-	database.InitDBClients(&defaultDBConfig, &bkPaaSDBConfig)
+	database.InitDBClients(&defaultDBConfig, &batchDBConfig, &bkPaaSDBConfig)
 	log.Info("init Database success")
 }
 
 func initRedis() {
 	standaloneConfig, isStandalone := globalConfig.RedisMap[redis.ModeStandalone]
 	sentinelConfig, isSentinel := globalConfig.RedisMap[redis.ModeSentinel]
+	clusterConfig, isCluster := globalConfig.RedisMap[redis.ModeCluster]
+
+	if !(isStandalone || isSentinel || isCluster) {
+		panic("redis id=standalone, id=sentinel or id=cluster should be configured")
+	}
+
+	// the priority: cluster > sentinel > standalone
+	if isCluster && (isSentinel || isStandalone) {
+		log.Info("redis id=cluster and id=standalone/sentinel configured, will use cluster")
 
-	if !(isStandalone || isSentinel) {
-		panic("redis id=standalone or id=sentinel should be configured")
+		delete(globalConfig.RedisMap, redis.ModeStandalone)
+		delete(globalConfig.RedisMap, redis.ModeSentinel)
+		isStandalone = false
+		isSentinel = false
 	}
 
 	if isSentinel && isStandalone {
@@ -101,6 +128,14 @@ func initRedis() {
 		isStandalone = false
 	}
 
+	if isCluster {
+		if clusterConfig.ClusterAddr == "" {
+			panic("redis id=cluster, the `clusterAddr` required")
+		}
+		log.Info("init Redis mode=`cluster`")
+		redis.InitRedisClient(globalConfig.Debug, &clusterConfig)
+	}
+
 	if isSentinel {
 		if sentinelConfig.MasterName == "" {
 			panic("redis id=sentinel, the `masterName` required")
@@ -122,11 +157,17 @@ func initLogger() {
 }
 
 func initCaches() {
+	memory.InitLimits(globalConfig.Cache)
 	impls.InitCaches(false)
+	// 系统模型的写操作成功后, 通过统一的失效入口清理所有实例的操作索引
+	invalidation.RegisterSystemModelChangeHandler()
+	// 成员变更及subject删除后, 维护subject在系统下有策略的用户组
+	service.RegisterSubjectChangeHandler(service.HandleSubjectChangeEventForSystemGroups)
 }
 
 func initPolicyCacheSettings() {
 	impls.InitPolicyCacheSettings(globalConfig.PolicyCache.Disabled, globalConfig.PolicyCache.ExpirationDays)
+	policy.InitCacheBackends(globalConfig.PolicyCache)
 }
 
 func initSuperAppCode() {
@@ -143,12 +184,45 @@ func initSupportShieldFeatures() {
 
 func initComponents() {
 	component.InitComponentClients()
+	component.InitRemoteResourceRetry(globalConfig.RemoteResourceRetry)
+}
+
+func initExport() {
+	export.Init(globalConfig.Export)
 }
 
 func initQuota() {
 	common.InitQuota(globalConfig.Quota, globalConfig.CustomQuotasMap)
 }
 
+func initEvalConcurrencyLimits() {
+	pdp.InitEvalConcurrencyLimits(globalConfig.EvalConcurrency.Default, globalConfig.EvalConcurrencyMap)
+}
+
+func initEvaluationModes() {
+	evaluation.InitModes(globalConfig.EvaluationMode.Default, globalConfig.EvaluationModeMap)
+}
+
+func initEvalFailurePolicies() {
+	pdp.InitFailurePolicies(globalConfig.EvalFailurePolicy)
+}
+
+func initRemoteResourceBreakers() {
+	pdp.InitRemoteResourceBreakers(globalConfig.RemoteResourceBreaker)
+}
+
+func initDisabledActionModes() {
+	pdp.InitDisabledActionModes(globalConfig.DisabledAction)
+}
+
+func initMemberAddHooks() {
+	common.InitMemberAddHooks(globalConfig.MemberAddHooks)
+}
+
 func initSwitch() {
 	common.InitSwitch(globalConfig.Switch)
 }
+
+func initSubjectRelationEventSink() {
+	eventsink.Init(globalConfig.SubjectRelationEventSink)
+}
@@ -26,6 +26,20 @@ databases:
     maxIdleConns: 50
     connMaxLifetimeSecond: 600
 
+  # the pool for batch jobs(e.g. engine full sync, export, cleanup),
+  # use the settings of `iam` with a smaller pool and read/write timeouts if not configured
+  # - id: "iam_batch"
+  #   host: "127.0.0.1"
+  #   port: 3306
+  #   user: "root"
+  #   password: "123456"
+  #   name: "bkiam"
+  #   maxOpenConns: 10
+  #   maxIdleConns: 2
+  #   connMaxLifetimeSecond: 1800
+  #   readTimeoutSecond: 300
+  #   writeTimeoutSecond: 60
+
   - id: "open_paas"
     host: "127.0.0.1"
     port: 3306
@@ -44,6 +58,179 @@ redis:
     readTimeout: 5
     writeTimeout: 5
     masterName: ""
+  # - id: "sentinel"
+  #   sentinelAddr: "127.0.0.1:26379,127.0.0.2:26379"
+  #   masterName: "mymaster"
+  #   password: ""
+  #   # where the read-only commands are routed to: master(default)/replica/nearest
+  #   readPreference: "master"
+  # - id: "cluster"
+  #   clusterAddr: "127.0.0.1:7000,127.0.0.2:7000,127.0.0.3:7000"
+  #   password: ""
+  #   readPreference: "master"
+
+# the size limit of each local memory cache, 0 means unlimited(only expired by the ttl)
+# the least recently used entries will be evicted when exceeding the limit
+cache:
+  maxEntries: 0
+  maxBytes: 0
+  # limits:
+  #   - name: "local_subject_pk"
+  #     maxEntries: 100000
+  #   - name: "local_unmarshaled_expression"
+  #     maxBytes: 104857600
+
+# if cache the policy in redis, for better performance
+policyCache:
+  disabled: false
+  expirationDays: 7
+  # the cache backend of the policies, can be overridden by the system config `policy_cache_backend`
+  #   both: (default) local memory -> redis -> database
+  #   memory: local memory -> database, for the high-QPS systems
+  #   redis: redis -> database, for the low-QPS systems to save the memory
+  backend: "both"
+  # systems:
+  #   - id: "bk_cmdb"
+  #     backend: "memory"
+
+# preload the hot caches(systems, actions, action resource types, and the top N subjects by recent auth traffic)
+# before the server starts serving, to avoid the latency spikes of cold start
+# the hot subjects are recorded into redis while serving auth requests, only when enabled
+warmup:
+  enabled: false
+  topSubjects: 1000
+  # the max seconds of warming up, the rest will be skipped after timeout
+  timeout: 30
+
+# shed or queue the low priority requests(web/model apis) when the database connection pool is under pressure,
+# the auth/query apis are never shed, the shed requests fail with `too many requests`
+priorityLane:
+  enabled: false
+  # under pressure when the average wait time(ms) of getting a connection exceeds the threshold
+  waitThreshold: 100
+  # the seconds between two samplings of the connection pool
+  checkInterval: 5
+  # the max concurrent low priority requests when under pressure
+  concurrency: 10
+  # the max milliseconds of queueing for a slot
+  queueTimeout: 1000
+
+expiredMemberPurge:
+  enabled: false
+  # purge the group members expired more than the days
+  retentionDays: 90
+  # the seconds between two runs, only one instance runs the task in a period
+  interval: 3600
+  # the count of members purged in one transaction
+  batchSize: 1000
+
+subjectSystemGroupReconcile:
+  enabled: true
+  # the seconds between two runs, only one instance runs the task in a period
+  interval: 3600
+  # the count of subjects reconciled in one batch
+  batchSize: 500
+
+# limit the concurrent evaluations of each system, 0 means no limit
+# the requests exceeding the limit will fail fast with `too many requests`
+evalConcurrency:
+  default: 0
+  # systems:
+  #   - id: "bk_cmdb"
+  #     limit: 200
+
+# the policy evaluation mode of each system
+#   deny_overrides: (default) any matched deny policy denies the request, otherwise any matched allow policy allows it
+#   first_match: evaluate the policies by priority from high to low, the first matched policy decides
+#   the mode applies to both the auth api and the policy query api(translated expression)
+evaluationMode:
+  default: "deny_overrides"
+  # systems:
+  #   - id: "bk_legacy"
+  #     mode: "first_match"
+
+# the behavior of each system when the dependencies(redis/database/remote resource) fail during evaluation
+#   error: (default) return the error to the caller
+#   fail_closed: deny the request
+#   serve_stale: use the latest decision of the same request in this instance(keep 10 minutes), deny if not found
+# the failOpenActions(read-only and low-risk actions) will be allowed if no stale decision
+evalFailurePolicy:
+  default: "error"
+  # systems:
+  #   - id: "bk_cmdb"
+  #     mode: "serve_stale"
+  #     failOpenActions: ["view_host"]
+
+# the circuit breaker of the remote resource attribute calls of each resource provider system
+# the breaker opens after failureThreshold consecutive failures(0 means disabled), and retries after openSeconds
+# the behavior when the calls fail or the breaker is open
+#   fail_closed: (default) return the error, then decided by the evalFailurePolicy of the requesting system
+#   degrade: do not filter the policies by the remote resources, e.g. the auth will not check the remote resources
+remoteResourceBreaker:
+  failureThreshold: 0
+  openSeconds: 30
+  default: "fail_closed"
+  # systems:
+  #   - id: "bk_cmdb"
+  #     mode: "degrade"
+
+# the retry of the remote resource attribute calls, only retry on the network errors or 5xx responses
+# wait backoffMilliseconds before the first retry, and double it for each next retry
+# all the attempts should be finished within deadlineSeconds, and never exceed the timeout of the incoming request
+remoteResourceRetry:
+  count: 0
+  backoffMilliseconds: 100
+  deadlineSeconds: 30
+
+# the evaluation behavior when the requested action is disabled by the system(e.g. the feature is sunset temporarily)
+#   deny:   (default) response with the action disabled error(code 1901423)
+#   bypass: allowed without evaluating the policies
+disabledAction:
+  default: "deny"
+  # systems:
+  #   - id: "bk_cmdb"
+  #     mode: "bypass"
+
+# the default and maximum expiration days of group members added/renewed, 0 means no default/limit
+# quota:
+#   member:
+#     default_expiration_days: 180
+#     max_expiration_days: 365
+# customQuotas, keyed by the `system` of the member request:
+# customQuotas:
+#   - id: "bk_cmdb"
+#     quota:
+#       member:
+#         max_expiration_days: 730
+
+# the pre-commit hooks of adding group members, the members can be approved/rejected/pending
+# the pending members will not be added, should be committed by the bypassClients after approved
+# memberAddHooks:
+#   - name: "sensitive_groups"
+#     groupIDs: ["1", "2"]
+#     bypassClients: ["bk_itsm"]
+#     # http callback
+#     url: "http://approval.example.com/api/v1/member-add/check/"
+#     token: ""
+#     timeout: 5
+#   - name: "no_department"
+#     # embedded rule, works if the url is empty
+#     ruleMemberTypes: ["department"]
+#     ruleDecision: "rejected"
+
+# export the subject/group/department relations to the object storage, for BI
+# the usernames will be replaced by hmac-sha256(anonymizeSalt, username) if anonymize
+# export:
+#   storageURL: "http://bkrepo.example.com/generic/bk_iam/export"
+#   storageUsername: ""
+#   storagePassword: ""
+#   anonymizeSalt: ""
+
+# send the group member change events(added/removed/renewed) to the sink after committed, for downstream sync
+# subjectRelationEventSink:
+#   type: "redis_stream"
+#   stream: "iam:subject_relation_events"
+#   maxLen: 100000
 
 logger:
   system:
@@ -70,3 +257,16 @@ logger:
     level: info
     writer: file
     settings: {name: iam_component.log, size: 100, backups: 10, age: 7, path: ./}
+  access:
+    level: info
+    writer: file
+    settings: {name: iam_access.log, size: 100, backups: 10, age: 7, path: ./}
+
+# the sampling of the access log, the error requests(status >= 400) are always logged
+# accessLog:
+#   sampleRate: 1.0
+#   routes:
+#     - path: "/api/v1/policy/auth"
+#       sampleRate: 0.01
+#     - path: "/ping"
+#       sampleRate: 0
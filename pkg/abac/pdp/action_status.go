@@ -0,0 +1,87 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"fmt"
+
+	"iam/pkg/abac/pip"
+	"iam/pkg/config"
+	"iam/pkg/errorx"
+	"iam/pkg/logging/debug"
+	"iam/pkg/util"
+)
+
+/*
+接入系统临时下线某个功能时, 可以将对应的操作置为disabled, 鉴权时的处理模式每个系统可以单独配置
+
+1. deny:   (默认) 返回操作已禁用的错误(code 1901423), 由调用方决定
+2. bypass: 不查询策略, 直接有权限(查询策略接口返回any表达式)
+*/
+
+// 操作被禁用时的处理模式
+const (
+	DisabledActionModeDeny   = "deny"
+	DisabledActionModeBypass = "bypass"
+)
+
+// ErrActionDisabled 请求的操作被接入系统禁用
+var ErrActionDisabled = fmt.Errorf("the action is disabled by the system: %w", util.ErrActionDisabled)
+
+// NOTE: 初始化后只读, 不需要加锁
+var (
+	defaultDisabledActionMode = DisabledActionModeDeny
+	systemDisabledActionModes = map[string]string{}
+)
+
+// InitDisabledActionModes 初始化每个系统的操作禁用处理模式, 未配置或模式非法的系统使用默认模式
+func InitDisabledActionModes(cfg config.DisabledAction) {
+	defaultDisabledActionMode = validDisabledActionMode(cfg.Default)
+
+	systemDisabledActionModes = make(map[string]string, len(cfg.Systems))
+	for _, m := range cfg.Systems {
+		systemDisabledActionModes[m.ID] = validDisabledActionMode(m.Mode)
+	}
+}
+
+func validDisabledActionMode(mode string) string {
+	if mode == DisabledActionModeBypass {
+		return mode
+	}
+	return DisabledActionModeDeny
+}
+
+// GetDisabledActionMode 获取系统的操作禁用处理模式
+func GetDisabledActionMode(system string) string {
+	if mode, ok := systemDisabledActionModes[system]; ok {
+		return mode
+	}
+	return defaultDisabledActionMode
+}
+
+// checkActionStatus 检查操作是否被禁用; 禁用时bypass模式返回bypass=true, deny模式返回ErrActionDisabled
+func checkActionStatus(system, actionID string, entry *debug.Entry) (bypass bool, err error) {
+	disabled, err := pip.IsActionDisabled(system, actionID)
+	if err != nil {
+		err = errorx.Wrapf(err, PDP, "checkActionStatus",
+			"pip.IsActionDisabled system=`%s`, actionID=`%s` fail", system, actionID)
+		return false, err
+	}
+	if !disabled {
+		return false, nil
+	}
+
+	mode := GetDisabledActionMode(system)
+	debug.WithValue(entry, "actionDisabled", mode)
+	if mode == DisabledActionModeBypass {
+		return true, nil
+	}
+	return false, ErrActionDisabled
+}
@@ -0,0 +1,190 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"database/sql/driver"
+	"reflect"
+
+	"github.com/agiledragon/gomonkey"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/pip"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/config"
+	"iam/pkg/logging/debug"
+	"iam/pkg/util"
+)
+
+var _ = Describe("ActionStatus", func() {
+
+	AfterEach(func() {
+		InitDisabledActionModes(config.DisabledAction{})
+	})
+
+	Describe("GetDisabledActionMode", func() {
+		It("default", func() {
+			InitDisabledActionModes(config.DisabledAction{})
+			assert.Equal(GinkgoT(), DisabledActionModeDeny, GetDisabledActionMode("test"))
+		})
+
+		It("system mode, invalid mode use deny", func() {
+			InitDisabledActionModes(config.DisabledAction{
+				Default: DisabledActionModeBypass,
+				Systems: []config.SystemDisabledAction{
+					{ID: "deny", Mode: DisabledActionModeDeny},
+					{ID: "invalid", Mode: "abc"},
+				},
+			})
+
+			assert.Equal(GinkgoT(), DisabledActionModeBypass, GetDisabledActionMode("test"))
+			assert.Equal(GinkgoT(), DisabledActionModeDeny, GetDisabledActionMode("deny"))
+			assert.Equal(GinkgoT(), DisabledActionModeDeny, GetDisabledActionMode("invalid"))
+		})
+	})
+
+	Describe("checkActionStatus", func() {
+		var patches *gomonkey.Patches
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("IsActionDisabled fail", func() {
+			patches = gomonkey.ApplyFunc(pip.IsActionDisabled, func(system, id string) (bool, error) {
+				return false, driver.ErrBadConn
+			})
+
+			_, err := checkActionStatus("test", "edit", nil)
+			assert.Error(GinkgoT(), err)
+			assert.True(GinkgoT(), isDependencyError(err))
+		})
+
+		It("enabled", func() {
+			patches = gomonkey.ApplyFunc(pip.IsActionDisabled, func(system, id string) (bool, error) {
+				return false, nil
+			})
+
+			bypass, err := checkActionStatus("test", "edit", nil)
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), bypass)
+		})
+
+		It("disabled, deny", func() {
+			patches = gomonkey.ApplyFunc(pip.IsActionDisabled, func(system, id string) (bool, error) {
+				return true, nil
+			})
+
+			_, err := checkActionStatus("test", "edit", nil)
+			assert.ErrorIs(GinkgoT(), err, ErrActionDisabled)
+			assert.ErrorIs(GinkgoT(), err, util.ErrActionDisabled)
+			assert.False(GinkgoT(), isDependencyError(err))
+		})
+
+		It("disabled, bypass", func() {
+			InitDisabledActionModes(config.DisabledAction{Default: DisabledActionModeBypass})
+			patches = gomonkey.ApplyFunc(pip.IsActionDisabled, func(system, id string) (bool, error) {
+				return true, nil
+			})
+
+			bypass, err := checkActionStatus("test", "edit", nil)
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), bypass)
+		})
+	})
+
+	Describe("entrance", func() {
+		var req *request.Request
+		var patches *gomonkey.Patches
+		BeforeEach(func() {
+			req = &request.Request{
+				System:  "test",
+				Subject: types.NewSubject(),
+				Action:  types.NewAction(),
+				Resources: []types.Resource{{
+					System: "test",
+					ID:     "1",
+				}},
+			}
+			req.Subject.Type = "user"
+			req.Subject.ID = "admin"
+			req.Action.ID = "edit"
+
+			patches = gomonkey.NewPatches()
+			patches.ApplyMethod(reflect.TypeOf(req), "ValidateActionResource",
+				func(_ *request.Request) bool {
+					return true
+				})
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(pip.IsActionDisabled, func(system, id string) (bool, error) {
+				return id == "edit", nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(evalPolicies, func(r *request.Request, entry *debug.Entry, withoutCache bool) (bool, error) {
+				return false, nil
+			})
+		})
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("Eval, deny", func() {
+			_, err := Eval(req, nil, false)
+			assert.ErrorIs(GinkgoT(), err, ErrActionDisabled)
+		})
+
+		It("Eval, bypass", func() {
+			InitDisabledActionModes(config.DisabledAction{Default: DisabledActionModeBypass})
+
+			isPass, err := Eval(req, nil, false)
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), isPass)
+		})
+
+		It("BatchEvalActions, deny", func() {
+			_, err := BatchEvalActions(req, []string{"edit", "view"}, nil, false)
+			assert.ErrorIs(GinkgoT(), err, ErrActionDisabled)
+		})
+
+		It("BatchEvalActions, bypass", func() {
+			InitDisabledActionModes(config.DisabledAction{Default: DisabledActionModeBypass})
+
+			results, err := BatchEvalActions(req, []string{"edit", "view"}, nil, false)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]bool{"edit": true, "view": false}, results)
+		})
+
+		It("BatchEvalResources, bypass", func() {
+			InitDisabledActionModes(config.DisabledAction{Default: DisabledActionModeBypass})
+
+			results, err := BatchEvalResources(req, [][]types.Resource{req.Resources, req.Resources}, nil, false)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []bool{true, true}, results)
+		})
+
+		It("Query, deny", func() {
+			_, err := Query(req, nil, false, false)
+			assert.ErrorIs(GinkgoT(), err, ErrActionDisabled)
+		})
+
+		It("Query, bypass", func() {
+			InitDisabledActionModes(config.DisabledAction{Default: DisabledActionModeBypass})
+
+			expr, err := Query(req, nil, false, false)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), AnyExpression, expr)
+		})
+	})
+})
@@ -0,0 +1,216 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/config"
+)
+
+/*
+第三方资源属性接口的熔断, 每个资源提供方系统独立, 避免单个系统的接口慢/不可用拖垮所有的鉴权请求
+
+1. closed:    正常调用, 连续失败 failureThreshold 次后打开
+2. open:      不调用接口, 直接失败; 持续 openSeconds 后半开
+3. half_open: 只放行一个请求试探, 成功则关闭, 失败则再次打开
+
+接口失败(包括熔断打开)时的处理模式, 每个资源提供方系统可以单独配置
+
+1. fail_closed: (默认) 返回错误, 再由请求系统的依赖失败处理策略(evalFailurePolicy)决定
+2. degrade:     不使用第三方资源过滤策略, 即鉴权/查询的结果不受第三方资源属性的约束
+*/
+
+// 第三方资源属性接口失败时的处理模式
+const (
+	RemoteResourceModeFailClosed = "fail_closed"
+	RemoteResourceModeDegrade    = "degrade"
+)
+
+const defaultBreakerOpenDuration = 30 * time.Second
+
+var (
+	// ErrRemoteResourceCircuitOpen 资源提供方系统的熔断打开, 不调用第三方接口
+	ErrRemoteResourceCircuitOpen = errors.New("remote resource circuit breaker is open")
+	// ErrRemoteResourceDegraded 第三方接口失败, 降级为不使用第三方资源过滤策略
+	ErrRemoteResourceDegraded = errors.New("remote resource degraded")
+)
+
+// 熔断器的状态
+const (
+	breakerClosed = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type circuitBreaker struct {
+	threshold    int
+	openDuration time.Duration
+
+	mu       sync.Mutex
+	state    int
+	failures int
+	openedAt time.Time
+}
+
+// allow 是否可以调用, half_open状态下只放行一个请求
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.openDuration {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+// done 记录调用结果
+func (b *circuitBreaker) done(success bool) (opened bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.state = breakerClosed
+		b.failures = 0
+		return false
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+		return true
+	}
+	return false
+}
+
+// NOTE: 初始化后只读, 不需要加锁
+var (
+	breakerThreshold    int
+	breakerOpenDuration = defaultBreakerOpenDuration
+
+	defaultRemoteResourceMode = RemoteResourceModeFailClosed
+	systemRemoteResourceModes = map[string]string{}
+
+	remoteResourceBreakers sync.Map // systemID => *circuitBreaker
+)
+
+// InitRemoteResourceBreakers 初始化第三方资源属性接口的熔断及每个资源提供方系统的失败处理模式
+func InitRemoteResourceBreakers(cfg config.RemoteResourceBreaker) {
+	breakerThreshold = cfg.FailureThreshold
+	breakerOpenDuration = defaultBreakerOpenDuration
+	if cfg.OpenSeconds > 0 {
+		breakerOpenDuration = time.Duration(cfg.OpenSeconds) * time.Second
+	}
+
+	defaultRemoteResourceMode = validRemoteResourceMode(cfg.Default)
+	systemRemoteResourceModes = make(map[string]string, len(cfg.Systems))
+	for _, s := range cfg.Systems {
+		systemRemoteResourceModes[s.ID] = validRemoteResourceMode(s.Mode)
+	}
+
+	remoteResourceBreakers = sync.Map{}
+}
+
+func validRemoteResourceMode(mode string) string {
+	if mode == RemoteResourceModeDegrade {
+		return mode
+	}
+	return RemoteResourceModeFailClosed
+}
+
+// getRemoteResourceMode 获取资源提供方系统的接口失败处理模式
+func getRemoteResourceMode(system string) string {
+	if mode, ok := systemRemoteResourceModes[system]; ok {
+		return mode
+	}
+	return defaultRemoteResourceMode
+}
+
+// getRemoteResourceBreaker 获取资源提供方系统的熔断器, 未开启熔断时返回nil
+func getRemoteResourceBreaker(system string) *circuitBreaker {
+	if breakerThreshold <= 0 {
+		return nil
+	}
+
+	if b, ok := remoteResourceBreakers.Load(system); ok {
+		return b.(*circuitBreaker)
+	}
+
+	actual, _ := remoteResourceBreakers.LoadOrStore(system, &circuitBreaker{
+		threshold:    breakerThreshold,
+		openDuration: breakerOpenDuration,
+	})
+	return actual.(*circuitBreaker)
+}
+
+// callRemoteResource 经过资源提供方系统的熔断器调用第三方资源属性接口
+// 失败时, degrade模式返回ErrRemoteResourceDegraded, 由调用方忽略第三方资源
+func callRemoteResource(system string, call func() error) (err error) {
+	if b := getRemoteResourceBreaker(system); b != nil {
+		if b.allow() {
+			err = call()
+			if b.done(err == nil) {
+				log.WithError(err).Warnf("remote resource circuit breaker of system `%s` is open", system)
+			}
+		} else {
+			err = ErrRemoteResourceCircuitOpen
+		}
+	} else {
+		err = call()
+	}
+
+	if err == nil {
+		return nil
+	}
+	if getRemoteResourceMode(system) == RemoteResourceModeDegrade {
+		return fmt.Errorf("%w: %s", ErrRemoteResourceDegraded, err)
+	}
+	return remoteResourceError{err: err}
+}
+
+// remoteResourceError 调用第三方资源属性接口失败的错误, 鉴权时作为依赖失败处理
+type remoteResourceError struct {
+	err error
+}
+
+func (e remoteResourceError) Error() string {
+	return e.err.Error()
+}
+
+func (e remoteResourceError) Unwrap() error {
+	return e.err
+}
+
+// getLocalResources 获取请求中接入系统自身的资源, 用于第三方资源降级时只按本地资源过滤策略
+func getLocalResources(r *request.Request) []*types.Resource {
+	resources := make([]*types.Resource, 0, len(r.Resources))
+	for i := range r.Resources {
+		if r.System == r.Resources[i].System {
+			resources = append(resources, &r.Resources[i])
+		}
+	}
+	return resources
+}
@@ -0,0 +1,138 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/config"
+)
+
+var _ = Describe("Breaker", func() {
+
+	AfterEach(func() {
+		InitRemoteResourceBreakers(config.RemoteResourceBreaker{})
+	})
+
+	Describe("circuitBreaker", func() {
+		It("open after threshold, half open after duration", func() {
+			b := &circuitBreaker{threshold: 2, openDuration: 10 * time.Millisecond}
+
+			assert.True(GinkgoT(), b.allow())
+			assert.False(GinkgoT(), b.done(false))
+			assert.True(GinkgoT(), b.allow())
+			assert.True(GinkgoT(), b.done(false))
+
+			// open
+			assert.False(GinkgoT(), b.allow())
+
+			// half open, only one probe
+			time.Sleep(15 * time.Millisecond)
+			assert.True(GinkgoT(), b.allow())
+			assert.False(GinkgoT(), b.allow())
+
+			// probe fail, open again
+			assert.True(GinkgoT(), b.done(false))
+			assert.False(GinkgoT(), b.allow())
+
+			// probe success, closed
+			time.Sleep(15 * time.Millisecond)
+			assert.True(GinkgoT(), b.allow())
+			assert.False(GinkgoT(), b.done(true))
+			assert.True(GinkgoT(), b.allow())
+			assert.Equal(GinkgoT(), 0, b.failures)
+		})
+
+		It("success reset the failures", func() {
+			b := &circuitBreaker{threshold: 2, openDuration: time.Minute}
+
+			b.done(false)
+			b.done(true)
+			assert.False(GinkgoT(), b.done(false))
+			assert.True(GinkgoT(), b.allow())
+		})
+	})
+
+	Describe("callRemoteResource", func() {
+		callErr := errors.New("timeout")
+
+		It("breaker disabled", func() {
+			InitRemoteResourceBreakers(config.RemoteResourceBreaker{})
+			assert.Nil(GinkgoT(), getRemoteResourceBreaker("test"))
+
+			for i := 0; i < 5; i++ {
+				called := false
+				err := callRemoteResource("test", func() error {
+					called = true
+					return callErr
+				})
+				assert.True(GinkgoT(), called)
+				assert.ErrorIs(GinkgoT(), err, callErr)
+				assert.True(GinkgoT(), isDependencyError(err))
+			}
+		})
+
+		It("fail closed, circuit open", func() {
+			InitRemoteResourceBreakers(config.RemoteResourceBreaker{FailureThreshold: 2})
+
+			for i := 0; i < 2; i++ {
+				err := callRemoteResource("test", func() error { return callErr })
+				assert.ErrorIs(GinkgoT(), err, callErr)
+				assert.True(GinkgoT(), isDependencyError(err))
+			}
+
+			called := false
+			err := callRemoteResource("test", func() error {
+				called = true
+				return nil
+			})
+			assert.False(GinkgoT(), called)
+			assert.ErrorIs(GinkgoT(), err, ErrRemoteResourceCircuitOpen)
+
+			// the breaker of other system not affected
+			err = callRemoteResource("other", func() error { return nil })
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("degrade", func() {
+			InitRemoteResourceBreakers(config.RemoteResourceBreaker{
+				FailureThreshold: 1,
+				Systems: []config.SystemRemoteResourceBreaker{
+					{ID: "test", Mode: RemoteResourceModeDegrade},
+					{ID: "invalid", Mode: "abc"},
+				},
+			})
+			assert.Equal(GinkgoT(), RemoteResourceModeFailClosed, getRemoteResourceMode("invalid"))
+
+			err := callRemoteResource("test", func() error { return callErr })
+			assert.ErrorIs(GinkgoT(), err, ErrRemoteResourceDegraded)
+
+			err = callRemoteResource("test", func() error { return nil })
+			assert.ErrorIs(GinkgoT(), err, ErrRemoteResourceDegraded)
+			assert.Contains(GinkgoT(), err.Error(), ErrRemoteResourceCircuitOpen.Error())
+		})
+	})
+
+	It("getLocalResources", func() {
+		r := request.NewRequest()
+		r.System = "test"
+		r.Resources = []types.Resource{{System: "remote", ID: "1"}, {System: "test", ID: "2"}}
+
+		resources := getLocalResources(r)
+		assert.Len(GinkgoT(), resources, 1)
+		assert.Equal(GinkgoT(), "2", resources[0].ID)
+	})
+})
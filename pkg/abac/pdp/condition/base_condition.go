@@ -19,6 +19,11 @@ type baseCondition struct {
 	Value []interface{}
 }
 
+// GetKey 返回条件的属性key
+func (c *baseCondition) GetKey() string {
+	return c.Key
+}
+
 // GetValues 如果Value中有参数, 获取参数的值
 func (c *baseCondition) GetValues() []interface{} {
 	return c.Value
@@ -53,7 +58,10 @@ func (c *baseCondition) forOr(ctx types.AttributeGetter, fn func(interface{}, in
 	return false
 }
 
-// GetKeys 返回条件中属性key值
+// GetKeys 返回条件中属性key值, subject的属性不是资源属性, 不需要查询
 func (c *baseCondition) GetKeys() []string {
+	if types.IsSubjectAttrKey(c.Key) {
+		return []string{}
+	}
 	return []string{c.Key}
 }
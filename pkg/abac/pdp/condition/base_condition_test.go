@@ -130,6 +130,14 @@ var _ = Describe("BaseCondition", func() {
 			assert.Equal(GinkgoT(), []string{expectedKey}, c.GetKeys())
 		})
 
+		It("subject attr", func() {
+			c := baseCondition{
+				Key:   "subject.groups",
+				Value: nil,
+			}
+			assert.Equal(GinkgoT(), []string{}, c.GetKeys())
+		})
+
 	})
 
 })
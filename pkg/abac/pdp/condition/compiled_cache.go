@@ -0,0 +1,118 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package condition
+
+import (
+	"container/list"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+编译后的条件缓存
+
+策略表达式每次鉴权都需要 反序列化 + 构造条件树, 热点策略重复这部分开销没有意义
+条件树构造完成后是只读的(Eval/GetKeys不修改状态), 可以在多个请求之间共享
+
+key = {expression_pk}:{signature}:{system}:{type}
+	- signature是表达式内容的md5, 内容变化后签名变化, 旧的key自然不再命中, 由LRU淘汰
+	- 无signature的表达式(例如saas接口/模拟计算构造的策略)不缓存
+*/
+
+// defaultCompiledConditionCacheSize 默认缓存的条件个数
+const defaultCompiledConditionCacheSize = 10000
+
+var compiledConditionCache = newConditionLRU(defaultCompiledConditionCacheSize)
+
+type conditionLRUEntry struct {
+	key       string
+	condition Condition
+}
+
+// conditionLRU 并发安全的LRU, 超过容量时淘汰最久未使用的条件
+type conditionLRU struct {
+	sync.Mutex
+
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+func newConditionLRU(size int) *conditionLRU {
+	return &conditionLRU{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// Get ...
+func (c *conditionLRU) Get(key string) (Condition, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		return e.Value.(*conditionLRUEntry).condition, true
+	}
+	return nil, false
+}
+
+// Add ...
+func (c *conditionLRU) Add(key string, condition Condition) {
+	c.Lock()
+	defer c.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		e.Value.(*conditionLRUEntry).condition = condition
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&conditionLRUEntry{key: key, condition: condition})
+
+	if c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*conditionLRUEntry).key)
+	}
+}
+
+// Len ...
+func (c *conditionLRU) Len() int {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.ll.Len()
+}
+
+// Purge ...
+func (c *conditionLRU) Purge() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[string]*list.Element, c.size)
+}
+
+func compiledConditionCacheKey(expressionPK int64, signature, system, _type string) string {
+	var sb strings.Builder
+	sb.Grow(len(signature) + len(system) + len(_type) + 24)
+
+	sb.WriteString(strconv.FormatInt(expressionPK, 10))
+	sb.WriteByte(':')
+	sb.WriteString(signature)
+	sb.WriteByte(':')
+	sb.WriteString(system)
+	sb.WriteByte(':')
+	sb.WriteString(_type)
+	return sb.String()
+}
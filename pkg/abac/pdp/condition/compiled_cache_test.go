@@ -0,0 +1,213 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package condition
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/types"
+	"iam/pkg/cache/impls"
+	"iam/pkg/cache/memory"
+)
+
+const (
+	benchExpression = `[{"system": "bk_test", "type": "host", 
+"expression": {"AND": {"content": [{"StringEquals": {"id": ["192.168.1.1", "192.168.1.2"]}}, 
+{"StringPrefix": {"path": ["/biz,1/"]}}, {"NumericGt": {"cpu": [4]}}]}}}]`
+	benchExpressionSignature = "bench-signature"
+)
+
+var _ = Describe("CompiledCache", func() {
+
+	Describe("conditionLRU", func() {
+		var c *conditionLRU
+		BeforeEach(func() {
+			c = newConditionLRU(2)
+		})
+
+		It("get miss", func() {
+			_, ok := c.Get("a")
+			assert.False(GinkgoT(), ok)
+		})
+
+		It("add and get", func() {
+			cond := &AnyCondition{}
+			c.Add("a", cond)
+
+			got, ok := c.Get("a")
+			assert.True(GinkgoT(), ok)
+			assert.Same(GinkgoT(), cond, got)
+			assert.Equal(GinkgoT(), 1, c.Len())
+		})
+
+		It("evict the least recently used", func() {
+			c.Add("a", &AnyCondition{})
+			c.Add("b", &AnyCondition{})
+			// a 被访问后, b 成为最久未使用
+			c.Get("a")
+			c.Add("c", &AnyCondition{})
+
+			assert.Equal(GinkgoT(), 2, c.Len())
+			_, ok := c.Get("b")
+			assert.False(GinkgoT(), ok)
+			_, ok = c.Get("a")
+			assert.True(GinkgoT(), ok)
+			_, ok = c.Get("c")
+			assert.True(GinkgoT(), ok)
+		})
+
+		It("add exists key, replace", func() {
+			c.Add("a", &AnyCondition{})
+			cond := &AnyCondition{}
+			c.Add("a", cond)
+
+			got, _ := c.Get("a")
+			assert.Same(GinkgoT(), cond, got)
+			assert.Equal(GinkgoT(), 1, c.Len())
+		})
+
+		It("purge", func() {
+			c.Add("a", &AnyCondition{})
+			c.Purge()
+			assert.Equal(GinkgoT(), 0, c.Len())
+		})
+	})
+
+	Describe("ParseResourceConditionFromPolicy", func() {
+		var resource *types.Resource
+		BeforeEach(func() {
+			resource = &types.Resource{
+				System: "bk_test",
+				Type:   "host",
+				ID:     "1",
+			}
+
+			impls.LocalUnmarshaledExpressionCache = memory.NewMockCache(impls.UnmarshalExpression)
+			compiledConditionCache.Purge()
+		})
+
+		It("ok, cached", func() {
+			policy := types.AuthPolicy{
+				ExpressionPK:        1,
+				Expression:          benchExpression,
+				ExpressionSignature: benchExpressionSignature,
+			}
+			c1, err := ParseResourceConditionFromPolicy(resource, policy)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "AND", c1.GetName())
+			assert.Equal(GinkgoT(), 1, compiledConditionCache.Len())
+
+			c2, err := ParseResourceConditionFromPolicy(resource, policy)
+			assert.NoError(GinkgoT(), err)
+			assert.Same(GinkgoT(), c1, c2)
+		})
+
+		It("ok, different resource type, different key", func() {
+			expr := `[{"system": "bk_test", "type": "host", "expression": {"Any": {"id": []}}}, 
+{"system": "bk_test", "type": "module", "expression": {"StringEquals": {"id": ["1"]}}}]`
+			policy := types.AuthPolicy{
+				ExpressionPK:        1,
+				Expression:          expr,
+				ExpressionSignature: "two-types",
+			}
+			c1, err := ParseResourceConditionFromPolicy(resource, policy)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "Any", c1.GetName())
+
+			resource.Type = "module"
+			c2, err := ParseResourceConditionFromPolicy(resource, policy)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "StringEquals", c2.GetName())
+			assert.Equal(GinkgoT(), 2, compiledConditionCache.Len())
+		})
+
+		It("no signature, not cached", func() {
+			policy := types.AuthPolicy{
+				Expression: benchExpression,
+			}
+			_, err := ParseResourceConditionFromPolicy(resource, policy)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), 0, compiledConditionCache.Len())
+		})
+
+		It("fail, not cached", func() {
+			policy := types.AuthPolicy{
+				ExpressionPK:        1,
+				Expression:          benchExpression,
+				ExpressionSignature: benchExpressionSignature,
+			}
+			resource.Type = "module"
+			_, err := ParseResourceConditionFromPolicy(resource, policy)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "resource not match expression")
+			assert.Equal(GinkgoT(), 0, compiledConditionCache.Len())
+		})
+	})
+})
+
+func benchmarkResource() *types.Resource {
+	return &types.Resource{
+		System: "bk_test",
+		Type:   "host",
+		ID:     "1",
+	}
+}
+
+// BenchmarkParseResourceConditionNoCache 每次都反序列化表达式并构造条件
+func BenchmarkParseResourceConditionNoCache(b *testing.B) {
+	impls.LocalUnmarshaledExpressionCache = memory.NewCache("bench", true, impls.UnmarshalExpression, time.Minute)
+	resource := benchmarkResource()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := ParseResourceConditionFromExpression(resource, benchExpression, benchExpressionSignature)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParseResourceConditionUnmarshaledCache 仅缓存反序列化结果, 每次构造条件
+func BenchmarkParseResourceConditionUnmarshaledCache(b *testing.B) {
+	impls.LocalUnmarshaledExpressionCache = memory.NewMockCache(impls.UnmarshalExpression)
+	resource := benchmarkResource()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := ParseResourceConditionFromExpression(resource, benchExpression, benchExpressionSignature)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParseResourceConditionCompiledCache 命中编译后的条件缓存
+func BenchmarkParseResourceConditionCompiledCache(b *testing.B) {
+	impls.LocalUnmarshaledExpressionCache = memory.NewMockCache(impls.UnmarshalExpression)
+	compiledConditionCache.Purge()
+	resource := benchmarkResource()
+	policy := types.AuthPolicy{
+		ExpressionPK:        1,
+		Expression:          benchExpression,
+		ExpressionSignature: benchExpressionSignature,
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := ParseResourceConditionFromPolicy(resource, policy)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -36,13 +36,25 @@ var conditionFactories map[string]conditionFunc
 
 func init() {
 	conditionFactories = map[string]conditionFunc{
-		new(AndCondition).GetName():           newAndCondition,
-		new(OrCondition).GetName():            newOrCondition,
-		new(AnyCondition).GetName():           newAnyCondition,
-		new(StringEqualsCondition).GetName():  newStringEqualsCondition,
-		new(StringPrefixCondition).GetName():  newStringPrefixCondition,
-		new(NumericEqualsCondition).GetName(): newNumericEqualsCondition,
-		new(BoolCondition).GetName():          newBoolCondition,
+		new(AndCondition).GetName():            newAndCondition,
+		new(OrCondition).GetName():             newOrCondition,
+		new(NotCondition).GetName():            newNotCondition,
+		new(AnyCondition).GetName():            newAnyCondition,
+		new(StringEqualsCondition).GetName():   newStringEqualsCondition,
+		new(StringPrefixCondition).GetName():   newStringPrefixCondition,
+		new(StringWildcardCondition).GetName(): newStringWildcardCondition,
+		new(StringRegexCondition).GetName():    newStringRegexCondition,
+		new(NumericEqualsCondition).GetName():  newNumericEqualsCondition,
+		new(NumericGtCondition).GetName():      newNumericGtCondition,
+		new(NumericGteCondition).GetName():     newNumericGteCondition,
+		new(NumericLtCondition).GetName():      newNumericLtCondition,
+		new(NumericLteCondition).GetName():     newNumericLteCondition,
+		new(BoolCondition).GetName():           newBoolCondition,
+		new(TimeWindowCondition).GetName():     newTimeWindowCondition,
+		new(HourRangeCondition).GetName():      newHourRangeCondition,
+		new(WeekdayInCondition).GetName():      newWeekdayInCondition,
+		new(IPInCIDRCondition).GetName():       newIPInCIDRCondition,
+		"StringEqualsIgnoreCase":               newStringEqualsIgnoreCaseCondition,
 	}
 }
 
@@ -83,7 +95,8 @@ func NewConditionFromPolicyCondition(data types.PolicyCondition) (Condition, err
 		}
 
 		for k, v := range options {
-			return newConditionFunc(k, v)
+			// key可以是属性值转换函数, 例如 lower(name)
+			return newTransformCondition(newConditionFunc, k, v)
 		}
 	}
 	return nil, fmt.Errorf("can not support data %v", data)
@@ -195,6 +208,49 @@ func (c *OrCondition) GetKeys() []string {
 	return keys
 }
 
+// NotCondition 逻辑NOT, content中只能有一个条件
+type NotCondition struct {
+	content Condition
+}
+
+func newNotCondition(key string, values []interface{}) (Condition, error) {
+	if key != "content" {
+		return nil, fmt.Errorf("not condition not support key %s", key)
+	}
+
+	if len(values) != 1 {
+		return nil, fmt.Errorf("not condition should have only one condition in content, got %d", len(values))
+	}
+
+	condition, err := newConditionFromInterface(values[0])
+	if err != nil {
+		return nil, fmt.Errorf("not condition parser error: %w", err)
+	}
+
+	return &NotCondition{content: condition}, nil
+}
+
+// GetName 名称
+func (c *NotCondition) GetName() string {
+	return "NOT"
+}
+
+// Eval 求值, 内部条件依赖的属性在上下文中不存在时不满足, 避免属性缺失时取反后放行
+func (c *NotCondition) Eval(ctx types.AttributeGetter) bool {
+	for _, key := range c.content.GetKeys() {
+		value, err := ctx.GetAttr(key)
+		if err != nil || value == nil {
+			return false
+		}
+	}
+	return !c.content.Eval(ctx)
+}
+
+// GetKeys 返回嵌套条件中所有包含的属性key
+func (c *NotCondition) GetKeys() []string {
+	return c.content.GetKeys()
+}
+
 // AnyCondition 任意条件
 type AnyCondition struct {
 	baseCondition
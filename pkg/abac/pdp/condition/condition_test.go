@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"iam/pkg/abac/pdp/types"
+	abactypes "iam/pkg/abac/types"
 )
 
 var _ = Describe("Condition", func() {
@@ -276,6 +277,96 @@ var _ = Describe("Condition", func() {
 		})
 	})
 
+	Describe("NotCondition", func() {
+		var c *NotCondition
+		BeforeEach(func() {
+			c1, _ := newStringPrefixCondition("path", []interface{}{"/folder,1/"})
+			c = &NotCondition{content: c1}
+		})
+
+		Describe("New", func() {
+			It("wrong key", func() {
+				_, err := newNotCondition("wrong", []interface{}{"abc"})
+				assert.Error(GinkgoT(), err)
+			})
+
+			It("wrong content length", func() {
+				data := []interface{}{
+					map[string]interface{}{"StringEquals": map[string]interface{}{"system": []interface{}{"linux"}}},
+					map[string]interface{}{"StringPrefix": map[string]interface{}{"path": []interface{}{"/biz,1/"}}},
+				}
+				_, err := newNotCondition("content", data)
+				assert.Error(GinkgoT(), err)
+
+				_, err = newNotCondition("content", []interface{}{})
+				assert.Error(GinkgoT(), err)
+			})
+
+			It("fail", func() {
+				_, err := newNotCondition("content", []interface{}{1})
+				assert.Error(GinkgoT(), err)
+			})
+
+			It("ok", func() {
+				c, err := NewConditionByJSON([]byte(`{"NOT": {"content": [{"StringPrefix": {"path": ["/folder,1/"]}}]}}`))
+				assert.NoError(GinkgoT(), err)
+				assert.Equal(GinkgoT(), &NotCondition{
+					content: &StringPrefixCondition{
+						baseCondition: baseCondition{
+							Key:   "path",
+							Value: []interface{}{"/folder,1/"},
+						},
+					},
+				}, c)
+			})
+		})
+
+		It("GetName", func() {
+			assert.Equal(GinkgoT(), "NOT", c.GetName())
+		})
+
+		It("Eval", func() {
+			assert.True(GinkgoT(), c.Eval(strCtx("/folder,2/job,1/")))
+			assert.False(GinkgoT(), c.Eval(strCtx("/folder,1/job,1/")))
+		})
+
+		It("Eval, missing key", func() {
+			assert.False(GinkgoT(), c.Eval(errCtx(1)))
+
+			ctx := &types.ExprContext{Resource: &abactypes.Resource{
+				System:    "bk_test",
+				Type:      "job",
+				ID:        "1",
+				Attribute: abactypes.Attribute{"name": "job1"},
+			}}
+			assert.False(GinkgoT(), c.Eval(ctx))
+
+			ctx.Resource.Attribute["path"] = "/folder,2/job,1/"
+			assert.True(GinkgoT(), c.Eval(ctx))
+		})
+
+		It("Eval, missing key in nested condition", func() {
+			c1, _ := newStringEqualsCondition("name", []interface{}{"job1"})
+			c2, _ := newStringPrefixCondition("path", []interface{}{"/folder,1/"})
+			c = &NotCondition{content: &OrCondition{content: []Condition{c1, c2}}}
+
+			ctx := &types.ExprContext{Resource: &abactypes.Resource{
+				System:    "bk_test",
+				Type:      "job",
+				ID:        "1",
+				Attribute: abactypes.Attribute{"name": "job2"},
+			}}
+			assert.False(GinkgoT(), c.Eval(ctx))
+
+			ctx.Resource.Attribute["path"] = "/folder,2/job,1/"
+			assert.True(GinkgoT(), c.Eval(ctx))
+		})
+
+		It("GetKeys", func() {
+			assert.Equal(GinkgoT(), []string{"path"}, c.GetKeys())
+		})
+	})
+
 	Describe("AnyCondition", func() {
 		var c *AnyCondition
 		BeforeEach(func() {
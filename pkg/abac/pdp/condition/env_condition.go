@@ -0,0 +1,338 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package condition
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"iam/pkg/abac/pdp/types"
+)
+
+/*
+环境属性条件
+
+环境属性由鉴权上下文注入(如请求时间), 条件的key为带前缀的属性名, 例如:
+	{"HourRange": {"env.hour": [9, 18]}}
+	{"WeekdayIn": {"env.weekday": [1, 2, 3, 4, 5]}}
+	{"TimeWindow": {"env.ts": [1609430400, 1640966399]}}
+	{"IPInCIDR": {"env.source_ip": ["10.0.0.0/8", "192.168.1.1"]}}
+*/
+
+const envKeyPrefix = "env."
+
+// TimeWindowCondition 请求时间(unix时间戳, 秒)在[start, end]之间
+type TimeWindowCondition struct {
+	baseCondition
+	start int64
+	end   int64
+}
+
+func newTimeWindowCondition(key string, values []interface{}) (Condition, error) {
+	if err := validateEnvKey(key); err != nil {
+		return nil, err
+	}
+
+	nums, err := toInt64Values(values)
+	if err != nil {
+		return nil, fmt.Errorf("time window condition %w", err)
+	}
+	if len(nums) != 2 || nums[0] > nums[1] {
+		return nil, fmt.Errorf("time window condition values should be [start, end], got %v", values)
+	}
+
+	return &TimeWindowCondition{
+		baseCondition: baseCondition{
+			Key:   key,
+			Value: values,
+		},
+		start: nums[0],
+		end:   nums[1],
+	}, nil
+}
+
+// GetName 名称
+func (c *TimeWindowCondition) GetName() string {
+	return "TimeWindow"
+}
+
+// Eval 求值
+func (c *TimeWindowCondition) Eval(ctx types.AttributeGetter) bool {
+	ts, ok := getEnvInt64Attr(ctx, c.Key)
+	if !ok {
+		return false
+	}
+	return ts >= c.start && ts <= c.end
+}
+
+// GetKeys 环境属性不是资源属性, 不需要查询
+func (c *TimeWindowCondition) GetKeys() []string {
+	return []string{}
+}
+
+// HourRangeCondition 请求时间的小时在[start, end)之间, start > end 表示跨越零点, 例如 [22, 6]
+type HourRangeCondition struct {
+	baseCondition
+	start int64
+	end   int64
+}
+
+func newHourRangeCondition(key string, values []interface{}) (Condition, error) {
+	if err := validateEnvKey(key); err != nil {
+		return nil, err
+	}
+
+	nums, err := toInt64Values(values)
+	if err != nil {
+		return nil, fmt.Errorf("hour range condition %w", err)
+	}
+	if len(nums) != 2 || nums[0] < 0 || nums[0] > 24 || nums[1] < 0 || nums[1] > 24 {
+		return nil, fmt.Errorf("hour range condition values should be [start, end] in 0-24, got %v", values)
+	}
+
+	return &HourRangeCondition{
+		baseCondition: baseCondition{
+			Key:   key,
+			Value: values,
+		},
+		start: nums[0],
+		end:   nums[1],
+	}, nil
+}
+
+// GetName 名称
+func (c *HourRangeCondition) GetName() string {
+	return "HourRange"
+}
+
+// Eval 求值
+func (c *HourRangeCondition) Eval(ctx types.AttributeGetter) bool {
+	hour, ok := getEnvInt64Attr(ctx, c.Key)
+	if !ok {
+		return false
+	}
+
+	if c.start <= c.end {
+		return hour >= c.start && hour < c.end
+	}
+	return hour >= c.start || hour < c.end
+}
+
+// GetKeys 环境属性不是资源属性, 不需要查询
+func (c *HourRangeCondition) GetKeys() []string {
+	return []string{}
+}
+
+// WeekdayInCondition 请求时间是星期中的某几天, 0 表示星期日
+type WeekdayInCondition struct {
+	baseCondition
+	weekdays []int64
+}
+
+func newWeekdayInCondition(key string, values []interface{}) (Condition, error) {
+	if err := validateEnvKey(key); err != nil {
+		return nil, err
+	}
+
+	nums, err := toInt64Values(values)
+	if err != nil {
+		return nil, fmt.Errorf("weekday in condition %w", err)
+	}
+	if len(nums) == 0 {
+		return nil, fmt.Errorf("weekday in condition values must not be empty")
+	}
+	for _, n := range nums {
+		if n < 0 || n > 6 {
+			return nil, fmt.Errorf("weekday in condition values should be in 0-6, got %v", values)
+		}
+	}
+
+	return &WeekdayInCondition{
+		baseCondition: baseCondition{
+			Key:   key,
+			Value: values,
+		},
+		weekdays: nums,
+	}, nil
+}
+
+// GetName 名称
+func (c *WeekdayInCondition) GetName() string {
+	return "WeekdayIn"
+}
+
+// Eval 求值
+func (c *WeekdayInCondition) Eval(ctx types.AttributeGetter) bool {
+	weekday, ok := getEnvInt64Attr(ctx, c.Key)
+	if !ok {
+		return false
+	}
+
+	for _, d := range c.weekdays {
+		if d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// GetKeys 环境属性不是资源属性, 不需要查询
+func (c *WeekdayInCondition) GetKeys() []string {
+	return []string{}
+}
+
+// IPInCIDRCondition 请求来源IP在任一CIDR网段内, 单个IP视为/32(IPv6为/128)
+type IPInCIDRCondition struct {
+	baseCondition
+	networks []*net.IPNet
+}
+
+func newIPInCIDRCondition(key string, values []interface{}) (Condition, error) {
+	if err := validateEnvKey(key); err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("ip in cidr condition values must not be empty")
+	}
+
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("ip in cidr condition value %v is not a string", v)
+		}
+
+		network, err := parseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("ip in cidr condition %w", err)
+		}
+		networks = append(networks, network)
+	}
+
+	return &IPInCIDRCondition{
+		baseCondition: baseCondition{
+			Key:   key,
+			Value: values,
+		},
+		networks: networks,
+	}, nil
+}
+
+// GetName 名称
+func (c *IPInCIDRCondition) GetName() string {
+	return "IPInCIDR"
+}
+
+// Eval 求值
+func (c *IPInCIDRCondition) Eval(ctx types.AttributeGetter) bool {
+	value, err := ctx.GetFullNameAttr(c.Key)
+	if err != nil {
+		return false
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range c.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetKeys 环境属性不是资源属性, 不需要查询
+func (c *IPInCIDRCondition) GetKeys() []string {
+	return []string{}
+}
+
+func parseCIDR(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid ip %s", s)
+		}
+
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+			bits = 8 * net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cidr %s: %w", s, err)
+	}
+	return network, nil
+}
+
+func validateEnvKey(key string) error {
+	if !strings.HasPrefix(key, envKeyPrefix) {
+		return fmt.Errorf("env condition key should start with `%s`, got %s", envKeyPrefix, key)
+	}
+	return nil
+}
+
+func getEnvInt64Attr(ctx types.AttributeGetter, key string) (int64, bool) {
+	value, err := ctx.GetFullNameAttr(key)
+	if err != nil {
+		return 0, false
+	}
+
+	n, err := toInt64(value)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+func toInt64Values(values []interface{}) ([]int64, error) {
+	nums := make([]int64, 0, len(values))
+	for _, v := range values {
+		n, err := toInt64(v)
+		if err != nil {
+			return nil, err
+		}
+		nums = append(nums, n)
+	}
+	return nums, nil
+}
+
+func toInt64(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, fmt.Errorf("value %v is not an integer", value)
+		}
+		return int64(v), nil
+	case json.Number:
+		return v.Int64()
+	default:
+		return 0, fmt.Errorf("value %v is not a number", value)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package condition
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+)
+
+type envCtx map[string]interface{}
+
+func (c envCtx) GetAttr(key string) (interface{}, error) {
+	return nil, errors.New("not resource attr")
+}
+
+func (c envCtx) GetFullNameAttr(key string) (interface{}, error) {
+	value, ok := c[key]
+	if !ok {
+		return nil, errors.New("missing key")
+	}
+	return value, nil
+}
+
+var _ = Describe("EnvCondition", func() {
+
+	Describe("TimeWindowCondition", func() {
+		It("new fail", func() {
+			_, err := newTimeWindowCondition("ts", []interface{}{1, 2})
+			assert.Error(GinkgoT(), err)
+
+			_, err = newTimeWindowCondition("env.ts", []interface{}{1})
+			assert.Error(GinkgoT(), err)
+
+			_, err = newTimeWindowCondition("env.ts", []interface{}{2, 1})
+			assert.Error(GinkgoT(), err)
+
+			_, err = newTimeWindowCondition("env.ts", []interface{}{"a", "b"})
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("from json", func() {
+			c, err := NewConditionByJSON([]byte(`{"TimeWindow": {"env.ts": [100, 200]}}`))
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "TimeWindow", c.GetName())
+			assert.Empty(GinkgoT(), c.GetKeys())
+
+			assert.True(GinkgoT(), c.Eval(envCtx{"env.ts": int64(100)}))
+			assert.True(GinkgoT(), c.Eval(envCtx{"env.ts": int64(200)}))
+			assert.False(GinkgoT(), c.Eval(envCtx{"env.ts": int64(201)}))
+			assert.False(GinkgoT(), c.Eval(envCtx{}))
+		})
+	})
+
+	Describe("HourRangeCondition", func() {
+		It("new fail", func() {
+			_, err := newHourRangeCondition("env.hour", []interface{}{9, 25})
+			assert.Error(GinkgoT(), err)
+
+			_, err = newHourRangeCondition("env.hour", []interface{}{9.5, 18})
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("eval", func() {
+			c, err := newHourRangeCondition("env.hour", []interface{}{float64(9), float64(18)})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "HourRange", c.GetName())
+
+			assert.True(GinkgoT(), c.Eval(envCtx{"env.hour": int64(9)}))
+			assert.True(GinkgoT(), c.Eval(envCtx{"env.hour": int64(17)}))
+			assert.False(GinkgoT(), c.Eval(envCtx{"env.hour": int64(18)}))
+			assert.False(GinkgoT(), c.Eval(envCtx{"env.hour": int64(8)}))
+			assert.False(GinkgoT(), c.Eval(envCtx{"env.hour": "9"}))
+		})
+
+		It("eval cross midnight", func() {
+			c, err := newHourRangeCondition("env.hour", []interface{}{22, 6})
+			assert.NoError(GinkgoT(), err)
+
+			assert.True(GinkgoT(), c.Eval(envCtx{"env.hour": int64(23)}))
+			assert.True(GinkgoT(), c.Eval(envCtx{"env.hour": int64(0)}))
+			assert.False(GinkgoT(), c.Eval(envCtx{"env.hour": int64(6)}))
+			assert.False(GinkgoT(), c.Eval(envCtx{"env.hour": int64(12)}))
+		})
+	})
+
+	Describe("WeekdayInCondition", func() {
+		It("new fail", func() {
+			_, err := newWeekdayInCondition("env.weekday", []interface{}{})
+			assert.Error(GinkgoT(), err)
+
+			_, err = newWeekdayInCondition("env.weekday", []interface{}{7})
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("eval", func() {
+			c, err := newWeekdayInCondition("env.weekday", []interface{}{json.Number("1"), json.Number("5")})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "WeekdayIn", c.GetName())
+			assert.Empty(GinkgoT(), c.GetKeys())
+
+			assert.True(GinkgoT(), c.Eval(envCtx{"env.weekday": int64(1)}))
+			assert.True(GinkgoT(), c.Eval(envCtx{"env.weekday": int64(5)}))
+			assert.False(GinkgoT(), c.Eval(envCtx{"env.weekday": int64(0)}))
+		})
+	})
+
+	Describe("IPInCIDRCondition", func() {
+		It("new fail", func() {
+			_, err := newIPInCIDRCondition("source_ip", []interface{}{"10.0.0.0/8"})
+			assert.Error(GinkgoT(), err)
+
+			_, err = newIPInCIDRCondition("env.source_ip", []interface{}{})
+			assert.Error(GinkgoT(), err)
+
+			_, err = newIPInCIDRCondition("env.source_ip", []interface{}{123})
+			assert.Error(GinkgoT(), err)
+
+			_, err = newIPInCIDRCondition("env.source_ip", []interface{}{"10.0.0.0/33"})
+			assert.Error(GinkgoT(), err)
+
+			_, err = newIPInCIDRCondition("env.source_ip", []interface{}{"abc"})
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("eval", func() {
+			c, err := NewConditionByJSON(
+				[]byte(`{"IPInCIDR": {"env.source_ip": ["10.0.0.0/8", "192.168.1.1", "fd00::/8"]}}`))
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "IPInCIDR", c.GetName())
+			assert.Empty(GinkgoT(), c.GetKeys())
+
+			assert.True(GinkgoT(), c.Eval(envCtx{"env.source_ip": "10.1.2.3"}))
+			assert.True(GinkgoT(), c.Eval(envCtx{"env.source_ip": "192.168.1.1"}))
+			assert.True(GinkgoT(), c.Eval(envCtx{"env.source_ip": "fd00::1"}))
+			assert.False(GinkgoT(), c.Eval(envCtx{"env.source_ip": "192.168.1.2"}))
+			assert.False(GinkgoT(), c.Eval(envCtx{"env.source_ip": ""}))
+			assert.False(GinkgoT(), c.Eval(envCtx{"env.source_ip": 1}))
+			assert.False(GinkgoT(), c.Eval(envCtx{}))
+		})
+	})
+})
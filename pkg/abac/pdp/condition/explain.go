@@ -0,0 +1,112 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package condition
+
+import (
+	"fmt"
+	"strings"
+
+	"iam/pkg/abac/pdp/types"
+)
+
+// Explanation 条件的求值过程, 包括条件中的值与请求中属性的实际值, 用于排查鉴权结果
+type Explanation struct {
+	Operator string `json:"operator"`
+	Field    string `json:"field,omitempty"`
+	// Value 条件中配置的值
+	Value []interface{} `json:"value,omitempty"`
+	// Attribute 请求中属性的实际值
+	Attribute interface{} `json:"attribute,omitempty"`
+	Result    bool        `json:"result"`
+	// Content 逻辑条件(AND/OR/NOT)的子条件
+	Content []Explanation `json:"content,omitempty"`
+}
+
+type leafCondition interface {
+	GetKey() string
+	GetValues() []interface{}
+}
+
+// Explain 对条件求值, 并返回每个子条件的求值过程
+// NOTE: 不会短路, 所有子条件都会求值, 只用于排查, 不要用于鉴权
+func Explain(c Condition, ctx types.AttributeGetter) Explanation {
+	explanation := Explanation{
+		Operator: c.GetName(),
+		Result:   c.Eval(ctx),
+	}
+
+	switch cond := c.(type) {
+	case *AndCondition:
+		explanation.Content = explainContent(cond.content, ctx)
+	case *OrCondition:
+		explanation.Content = explainContent(cond.content, ctx)
+	case *NotCondition:
+		explanation.Content = explainContent([]Condition{cond.content}, ctx)
+	case *TransformCondition:
+		// 展示转换后的属性值, field使用条件中的原始key
+		explanation = Explain(cond.content, cond.wrap(ctx))
+		explanation.Operator = cond.GetName()
+		if explanation.Field != "" {
+			explanation.Field = cond.field
+		}
+	case *AnyCondition:
+		// any条件没有属性
+	case leafCondition:
+		explanation.Field = cond.GetKey()
+		explanation.Value = cond.GetValues()
+		explanation.Attribute = getExplainAttr(ctx, cond.GetKey())
+	}
+
+	return explanation
+}
+
+func explainContent(conditions []Condition, ctx types.AttributeGetter) []Explanation {
+	content := make([]Explanation, 0, len(conditions))
+	for _, condition := range conditions {
+		content = append(content, Explain(condition, ctx))
+	}
+	return content
+}
+
+func getExplainAttr(ctx types.AttributeGetter, key string) interface{} {
+	var (
+		value interface{}
+		err   error
+	)
+	// 环境属性带有前缀, 资源属性不带前缀
+	if strings.HasPrefix(key, envKeyPrefix) {
+		value, err = ctx.GetFullNameAttr(key)
+	} else {
+		value, err = ctx.GetAttr(key)
+	}
+
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+// MatchedNodes 返回求值结果为true的条件节点在表达式中的位置, 例如 OR.content[1].StringEquals
+func MatchedNodes(e Explanation) []string {
+	nodes := []string{}
+	collectMatchedNodes(e, "", &nodes)
+	return nodes
+}
+
+func collectMatchedNodes(e Explanation, prefix string, nodes *[]string) {
+	path := prefix + e.Operator
+	if e.Result {
+		*nodes = append(*nodes, path)
+	}
+
+	for i, c := range e.Content {
+		collectMatchedNodes(c, fmt.Sprintf("%s.content[%d].", path, i), nodes)
+	}
+}
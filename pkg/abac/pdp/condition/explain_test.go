@@ -0,0 +1,120 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package condition
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+)
+
+// explainCtx resource attrs without prefix, env attrs with prefix `env.`
+type explainCtx map[string]interface{}
+
+func (c explainCtx) GetAttr(key string) (interface{}, error) {
+	value, ok := c[key]
+	if !ok {
+		return nil, errors.New("missing key")
+	}
+	return value, nil
+}
+
+func (c explainCtx) GetFullNameAttr(key string) (interface{}, error) {
+	return c.GetAttr(key)
+}
+
+var _ = Describe("Explain", func() {
+
+	It("leaf", func() {
+		c, err := NewConditionByJSON([]byte(`{"StringEquals": {"system": ["linux"]}}`))
+		assert.NoError(GinkgoT(), err)
+
+		e := Explain(c, explainCtx{"system": "windows"})
+		assert.Equal(GinkgoT(), Explanation{
+			Operator:  "StringEquals",
+			Field:     "system",
+			Value:     []interface{}{"linux"},
+			Attribute: "windows",
+			Result:    false,
+		}, e)
+	})
+
+	It("missing attr", func() {
+		c, err := NewConditionByJSON([]byte(`{"StringEquals": {"system": ["linux"]}}`))
+		assert.NoError(GinkgoT(), err)
+
+		e := Explain(c, explainCtx{})
+		assert.Nil(GinkgoT(), e.Attribute)
+		assert.False(GinkgoT(), e.Result)
+	})
+
+	It("any", func() {
+		c, err := NewConditionByJSON([]byte(`{"Any": {"id": []}}`))
+		assert.NoError(GinkgoT(), err)
+
+		e := Explain(c, explainCtx{})
+		assert.Equal(GinkgoT(), Explanation{Operator: "Any", Result: true}, e)
+	})
+
+	It("nested", func() {
+		c, err := NewConditionByJSON([]byte(`{"AND": {"content": [
+			{"StringPrefix": {"path": ["/biz,1/"]}},
+			{"NOT": {"content": [{"IPInCIDR": {"env.source_ip": ["10.0.0.0/8"]}}]}},
+			{"OR": {"content": [
+				{"NumericGt": {"level": [3]}},
+				{"Bool": {"public": [true]}}
+			]}}
+		]}}`))
+		assert.NoError(GinkgoT(), err)
+
+		e := Explain(c, explainCtx{
+			"path":          "/biz,1/set,2/",
+			"env.source_ip": "192.168.1.1",
+			"level":         1,
+			"public":        true,
+		})
+
+		assert.Equal(GinkgoT(), "AND", e.Operator)
+		assert.True(GinkgoT(), e.Result)
+		assert.Len(GinkgoT(), e.Content, 3)
+
+		assert.True(GinkgoT(), e.Content[0].Result)
+		assert.Equal(GinkgoT(), "/biz,1/set,2/", e.Content[0].Attribute)
+
+		not := e.Content[1]
+		assert.Equal(GinkgoT(), "NOT", not.Operator)
+		assert.True(GinkgoT(), not.Result)
+		assert.Equal(GinkgoT(), "env.source_ip", not.Content[0].Field)
+		assert.Equal(GinkgoT(), "192.168.1.1", not.Content[0].Attribute)
+		assert.False(GinkgoT(), not.Content[0].Result)
+
+		or := e.Content[2]
+		assert.True(GinkgoT(), or.Result)
+		// not short-circuit, all the sub conditions are explained
+		assert.False(GinkgoT(), or.Content[0].Result)
+		assert.True(GinkgoT(), or.Content[1].Result)
+	})
+
+	It("MatchedNodes", func() {
+		c, err := NewConditionByJSON([]byte(`{"OR": {"content": [
+			{"NumericGt": {"level": [3]}},
+			{"NOT": {"content": [{"Bool": {"public": [false]}}]}}
+		]}}`))
+		assert.NoError(GinkgoT(), err)
+
+		e := Explain(c, explainCtx{"level": 1, "public": true})
+		assert.Equal(GinkgoT(), []string{"OR", "OR.content[1].NOT"}, MatchedNodes(e))
+
+		e = Explain(c, explainCtx{"level": 5, "public": false})
+		assert.Equal(GinkgoT(), []string{"OR", "OR.content[0].NumericGt", "OR.content[1].NOT.content[0].Bool"},
+			MatchedNodes(e))
+	})
+})
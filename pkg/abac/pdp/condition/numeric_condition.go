@@ -0,0 +1,217 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package condition
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"iam/pkg/abac/pdp/types"
+)
+
+/*
+数值比较条件
+
+属性值与表达式中的任一值满足比较关系即为true, 例如:
+	{"NumericGt": {"level": [3]}}  =>  level > 3
+*/
+
+// NumericGtCondition 数值大于
+type NumericGtCondition struct {
+	baseCondition
+}
+
+func newNumericGtCondition(key string, values []interface{}) (Condition, error) {
+	if err := validateNumericValues(values); err != nil {
+		return nil, fmt.Errorf("numeric gt condition %w", err)
+	}
+
+	return &NumericGtCondition{
+		baseCondition: baseCondition{
+			Key:   key,
+			Value: values,
+		},
+	}, nil
+}
+
+// GetName 名称
+func (c *NumericGtCondition) GetName() string {
+	return "NumericGt"
+}
+
+// Eval 求值
+func (c *NumericGtCondition) Eval(ctx types.AttributeGetter) bool {
+	return c.forOr(ctx, func(a, b interface{}) bool {
+		result, ok := compareNumeric(a, b)
+		return ok && result > 0
+	})
+}
+
+// NumericGteCondition 数值大于等于
+type NumericGteCondition struct {
+	baseCondition
+}
+
+func newNumericGteCondition(key string, values []interface{}) (Condition, error) {
+	if err := validateNumericValues(values); err != nil {
+		return nil, fmt.Errorf("numeric gte condition %w", err)
+	}
+
+	return &NumericGteCondition{
+		baseCondition: baseCondition{
+			Key:   key,
+			Value: values,
+		},
+	}, nil
+}
+
+// GetName 名称
+func (c *NumericGteCondition) GetName() string {
+	return "NumericGte"
+}
+
+// Eval 求值
+func (c *NumericGteCondition) Eval(ctx types.AttributeGetter) bool {
+	return c.forOr(ctx, func(a, b interface{}) bool {
+		result, ok := compareNumeric(a, b)
+		return ok && result >= 0
+	})
+}
+
+// NumericLtCondition 数值小于
+type NumericLtCondition struct {
+	baseCondition
+}
+
+func newNumericLtCondition(key string, values []interface{}) (Condition, error) {
+	if err := validateNumericValues(values); err != nil {
+		return nil, fmt.Errorf("numeric lt condition %w", err)
+	}
+
+	return &NumericLtCondition{
+		baseCondition: baseCondition{
+			Key:   key,
+			Value: values,
+		},
+	}, nil
+}
+
+// GetName 名称
+func (c *NumericLtCondition) GetName() string {
+	return "NumericLt"
+}
+
+// Eval 求值
+func (c *NumericLtCondition) Eval(ctx types.AttributeGetter) bool {
+	return c.forOr(ctx, func(a, b interface{}) bool {
+		result, ok := compareNumeric(a, b)
+		return ok && result < 0
+	})
+}
+
+// NumericLteCondition 数值小于等于
+type NumericLteCondition struct {
+	baseCondition
+}
+
+func newNumericLteCondition(key string, values []interface{}) (Condition, error) {
+	if err := validateNumericValues(values); err != nil {
+		return nil, fmt.Errorf("numeric lte condition %w", err)
+	}
+
+	return &NumericLteCondition{
+		baseCondition: baseCondition{
+			Key:   key,
+			Value: values,
+		},
+	}, nil
+}
+
+// GetName 名称
+func (c *NumericLteCondition) GetName() string {
+	return "NumericLte"
+}
+
+// Eval 求值
+func (c *NumericLteCondition) Eval(ctx types.AttributeGetter) bool {
+	return c.forOr(ctx, func(a, b interface{}) bool {
+		result, ok := compareNumeric(a, b)
+		return ok && result <= 0
+	})
+}
+
+func validateNumericValues(values []interface{}) error {
+	if len(values) == 0 {
+		return fmt.Errorf("values must not be empty")
+	}
+
+	for _, v := range values {
+		if _, ok := toFloat64(v); !ok {
+			return fmt.Errorf("value %v is not a number", v)
+		}
+	}
+	return nil
+}
+
+// compareNumeric 比较两个数值, 返回 -1/0/1; 任一不是数值时ok为false
+// NOTE: 都是整数时按int64比较, 避免大整数转换为float64丢失精度
+func compareNumeric(a, b interface{}) (result int, ok bool) {
+	ai, aErr := toInt64(a)
+	bi, bErr := toInt64(b)
+	if aErr == nil && bErr == nil {
+		switch {
+		case ai < bi:
+			return -1, true
+		case ai > bi:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+
+	af, ok := toFloat64(a)
+	if !ok {
+		return 0, false
+	}
+	bf, ok := toFloat64(b)
+	if !ok {
+		return 0, false
+	}
+
+	switch {
+	case af < bf:
+		return -1, true
+	case af > bf:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
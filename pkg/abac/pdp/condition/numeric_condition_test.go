@@ -0,0 +1,104 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package condition
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	"github.com/stretchr/testify/assert"
+)
+
+type anyCtx struct {
+	value interface{}
+}
+
+func (c anyCtx) GetAttr(key string) (interface{}, error) {
+	return c.value, nil
+}
+
+func (c anyCtx) GetFullNameAttr(key string) (interface{}, error) {
+	return c.value, nil
+}
+
+var _ = Describe("NumericCondition", func() {
+
+	It("new fail", func() {
+		_, err := newNumericGtCondition("level", []interface{}{})
+		assert.Error(GinkgoT(), err)
+
+		_, err = newNumericGteCondition("level", []interface{}{"3"})
+		assert.Error(GinkgoT(), err)
+
+		_, err = newNumericLtCondition("level", []interface{}{true})
+		assert.Error(GinkgoT(), err)
+
+		_, err = newNumericLteCondition("level", []interface{}{nil})
+		assert.Error(GinkgoT(), err)
+	})
+
+	It("from json", func() {
+		c, err := NewConditionByJSON([]byte(`{"NumericGt": {"level": [3]}}`))
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), "NumericGt", c.GetName())
+		assert.Equal(GinkgoT(), []string{"level"}, c.GetKeys())
+
+		assert.True(GinkgoT(), c.Eval(anyCtx{int64(4)}))
+		assert.False(GinkgoT(), c.Eval(anyCtx{3}))
+		assert.True(GinkgoT(), c.Eval(anyCtx{3.5}))
+		assert.False(GinkgoT(), c.Eval(anyCtx{"4"}))
+		assert.False(GinkgoT(), c.Eval(errCtx(1)))
+		// any of the attribute values
+		assert.True(GinkgoT(), c.Eval(listCtx{1, 5}))
+	})
+
+	DescribeTable("eval", func(name string, attr interface{}, value interface{}, expected bool) {
+		var c Condition
+		var err error
+		switch name {
+		case "NumericGt":
+			c, err = newNumericGtCondition("level", []interface{}{value})
+		case "NumericGte":
+			c, err = newNumericGteCondition("level", []interface{}{value})
+		case "NumericLt":
+			c, err = newNumericLtCondition("level", []interface{}{value})
+		case "NumericLte":
+			c, err = newNumericLteCondition("level", []interface{}{value})
+		}
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), name, c.GetName())
+		assert.Equal(GinkgoT(), expected, c.Eval(anyCtx{attr}))
+	},
+		Entry("gt true", "NumericGt", int64(2), float64(1), true),
+		Entry("gt equal", "NumericGt", int64(1), float64(1), false),
+		Entry("gte equal", "NumericGte", 1, float64(1), true),
+		Entry("gte false", "NumericGte", 0.5, float64(1), false),
+		Entry("lt true", "NumericLt", float64(0.5), 1, true),
+		Entry("lt equal", "NumericLt", json.Number("1"), 1, false),
+		Entry("lte equal", "NumericLte", int64(1), json.Number("1"), true),
+		Entry("lte false", "NumericLte", int64(2), 1, false),
+		// big int64 should not lose precision
+		Entry("gt big int64", "NumericGt", int64(9007199254740993), int64(9007199254740992), true),
+	)
+
+	It("compareNumeric", func() {
+		_, ok := compareNumeric("a", 1)
+		assert.False(GinkgoT(), ok)
+
+		_, ok = compareNumeric(1, "a")
+		assert.False(GinkgoT(), ok)
+
+		result, ok := compareNumeric(float32(1.5), 1)
+		assert.True(GinkgoT(), ok)
+		assert.Equal(GinkgoT(), 1, result)
+	})
+})
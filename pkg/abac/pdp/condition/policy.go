@@ -49,7 +49,7 @@ func parseResourceConditionFromPolicies(
 
 	// 查询policies的key
 	for _, policy := range policies {
-		condition, err := ParseResourceConditionFromExpression(resource, policy.Expression, policy.ExpressionSignature)
+		condition, err := ParseResourceConditionFromPolicy(resource, policy)
 		if err != nil {
 			return nil, err
 		}
@@ -59,6 +59,27 @@ func parseResourceConditionFromPolicies(
 	return conditions, nil
 }
 
+// ParseResourceConditionFromPolicy 从policy中解析出resource相关的condition, 优先使用编译后的条件缓存
+func ParseResourceConditionFromPolicy(resource *types.Resource, policy types.AuthPolicy) (Condition, error) {
+	// 没有签名无法确定表达式的唯一性, 不缓存
+	if policy.ExpressionSignature == "" {
+		return ParseResourceConditionFromExpression(resource, policy.Expression, policy.ExpressionSignature)
+	}
+
+	key := compiledConditionCacheKey(policy.ExpressionPK, policy.ExpressionSignature, resource.System, resource.Type)
+	if condition, ok := compiledConditionCache.Get(key); ok {
+		return condition, nil
+	}
+
+	condition, err := ParseResourceConditionFromExpression(resource, policy.Expression, policy.ExpressionSignature)
+	if err != nil {
+		return nil, err
+	}
+
+	compiledConditionCache.Add(key, condition)
+	return condition, nil
+}
+
 // ParseResourceConditionFromExpression ...
 func ParseResourceConditionFromExpression(
 	resource *types.Resource,
@@ -0,0 +1,173 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package condition
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+
+	"iam/pkg/abac/pdp/types"
+)
+
+/*
+字符串模式匹配条件
+
+属性值匹配表达式中的任一模式即为true, 例如:
+	{"StringWildcard": {"name": ["prod-*-db?"]}}  =>  * 匹配任意个字符, ? 匹配单个字符
+	{"StringRegex": {"name": ["^prod-[a-z]+-\\d+$"]}}  =>  正则匹配, 未加^$时为部分匹配
+*/
+
+// patternCache 编译后的正则缓存, key为 {kind}:{pattern}, 多个条件之间共享
+var patternCache = gocache.New(30*time.Minute, 10*time.Minute)
+
+const (
+	patternKindWildcard = "wildcard"
+	patternKindRegex    = "regex"
+)
+
+// StringWildcardCondition 字符串通配符匹配
+type StringWildcardCondition struct {
+	baseCondition
+}
+
+func newStringWildcardCondition(key string, values []interface{}) (Condition, error) {
+	if err := validatePatternValues(patternKindWildcard, values); err != nil {
+		return nil, fmt.Errorf("string wildcard condition %w", err)
+	}
+
+	return &StringWildcardCondition{
+		baseCondition: baseCondition{
+			Key:   key,
+			Value: values,
+		},
+	}, nil
+}
+
+// GetName 名称
+func (c *StringWildcardCondition) GetName() string {
+	return "StringWildcard"
+}
+
+// Eval 求值
+func (c *StringWildcardCondition) Eval(ctx types.AttributeGetter) bool {
+	return c.forOr(ctx, func(a, b interface{}) bool {
+		return matchPattern(patternKindWildcard, a, b)
+	})
+}
+
+// StringRegexCondition 字符串正则匹配
+type StringRegexCondition struct {
+	baseCondition
+}
+
+func newStringRegexCondition(key string, values []interface{}) (Condition, error) {
+	if err := validatePatternValues(patternKindRegex, values); err != nil {
+		return nil, fmt.Errorf("string regex condition %w", err)
+	}
+
+	return &StringRegexCondition{
+		baseCondition: baseCondition{
+			Key:   key,
+			Value: values,
+		},
+	}, nil
+}
+
+// GetName 名称
+func (c *StringRegexCondition) GetName() string {
+	return "StringRegex"
+}
+
+// Eval 求值
+func (c *StringRegexCondition) Eval(ctx types.AttributeGetter) bool {
+	return c.forOr(ctx, func(a, b interface{}) bool {
+		return matchPattern(patternKindRegex, a, b)
+	})
+}
+
+func validatePatternValues(kind string, values []interface{}) error {
+	if len(values) == 0 {
+		return fmt.Errorf("values must not be empty")
+	}
+
+	for _, v := range values {
+		pattern, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("value %v is not a string", v)
+		}
+
+		if _, err := compilePattern(kind, pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchPattern(kind string, value, pattern interface{}) bool {
+	valueStr, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	patternStr, ok := pattern.(string)
+	if !ok {
+		return false
+	}
+
+	re, err := compilePattern(kind, patternStr)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(valueStr)
+}
+
+// compilePattern 编译模式, 优先从缓存中获取
+func compilePattern(kind, pattern string) (*regexp.Regexp, error) {
+	cacheKey := kind + ":" + pattern
+	if re, found := patternCache.Get(cacheKey); found {
+		return re.(*regexp.Regexp), nil
+	}
+
+	expr := pattern
+	if kind == patternKindWildcard {
+		expr = wildcardToRegex(pattern)
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s pattern `%s`: %w", kind, pattern, err)
+	}
+
+	patternCache.SetDefault(cacheKey, re)
+	return re, nil
+}
+
+// wildcardToRegex 通配符转为正则, 整串匹配
+func wildcardToRegex(pattern string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package condition
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ = Describe("StringPatternCondition", func() {
+
+	It("new fail", func() {
+		_, err := newStringWildcardCondition("name", []interface{}{})
+		assert.Error(GinkgoT(), err)
+
+		_, err = newStringWildcardCondition("name", []interface{}{1})
+		assert.Error(GinkgoT(), err)
+
+		_, err = newStringRegexCondition("name", []interface{}{"prod-[a-"})
+		assert.Error(GinkgoT(), err)
+		assert.Contains(GinkgoT(), err.Error(), "invalid regex pattern")
+	})
+
+	It("from json", func() {
+		c, err := NewConditionByJSON([]byte(`{"StringWildcard": {"name": ["prod-*", "test-?"]}}`))
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), "StringWildcard", c.GetName())
+		assert.Equal(GinkgoT(), []string{"name"}, c.GetKeys())
+
+		assert.True(GinkgoT(), c.Eval(anyCtx{"prod-db"}))
+		assert.True(GinkgoT(), c.Eval(anyCtx{"test-1"}))
+		assert.False(GinkgoT(), c.Eval(anyCtx{"test-12"}))
+		assert.False(GinkgoT(), c.Eval(anyCtx{1}))
+		assert.False(GinkgoT(), c.Eval(errCtx(1)))
+		// any of the attribute values
+		assert.True(GinkgoT(), c.Eval(anyCtx{[]interface{}{"dev-db", "prod-db"}}))
+
+		c, err = NewConditionByJSON([]byte(`{"StringRegex": {"name": ["^prod-\\d+$"]}}`))
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), "StringRegex", c.GetName())
+		assert.True(GinkgoT(), c.Eval(anyCtx{"prod-12"}))
+		assert.False(GinkgoT(), c.Eval(anyCtx{"prod-db"}))
+	})
+
+	DescribeTable("wildcard", func(pattern, value string, expected bool) {
+		c, err := newStringWildcardCondition("name", []interface{}{pattern})
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), expected, c.Eval(anyCtx{value}))
+	},
+		Entry("exact", "prod", "prod", true),
+		Entry("exact, not match", "prod", "prod1", false),
+		Entry("star", "prod-*", "prod-", true),
+		Entry("star in middle", "prod-*-db", "prod-a/b-db", true),
+		Entry("question", "db?", "db1", true),
+		Entry("question, not match empty", "db?", "db", false),
+		Entry("regex meta is literal", "a.b(c)", "a.b(c)", true),
+		Entry("regex meta is literal, not match", "a.b", "axb", false),
+	)
+
+	DescribeTable("regex", func(pattern, value string, expected bool) {
+		c, err := newStringRegexCondition("name", []interface{}{pattern})
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), expected, c.Eval(anyCtx{value}))
+	},
+		Entry("partial match", "prod", "my-prod-1", true),
+		Entry("anchored", "^prod$", "my-prod-1", false),
+		Entry("class", `^[a-z]+-\d+$`, "prod-1", true),
+	)
+
+	It("compilePattern cached", func() {
+		re1, err := compilePattern(patternKindRegex, "^cached$")
+		assert.NoError(GinkgoT(), err)
+
+		re2, err := compilePattern(patternKindRegex, "^cached$")
+		assert.NoError(GinkgoT(), err)
+		assert.Same(GinkgoT(), re1, re2)
+
+		// the same pattern of different kind
+		re3, err := compilePattern(patternKindWildcard, "^cached$")
+		assert.NoError(GinkgoT(), err)
+		assert.NotSame(GinkgoT(), re1, re3)
+	})
+})
@@ -0,0 +1,210 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package condition
+
+import (
+	"fmt"
+	"strings"
+
+	"iam/pkg/abac/pdp/types"
+	"iam/pkg/abac/pdp/util"
+)
+
+/*
+属性值转换条件
+
+装饰其他条件, 求值前先对属性值做转换, 避免接入系统为了大小写/层级等变体重复配置策略的值, 例如:
+	{"StringEquals": {"lower(name)": ["admin"]}}                     =>  name转小写后比较
+	{"StringEquals": {"split(tags, ;)": ["prod"]}}                   =>  tags按;拆分为数组, 任一元素相等即为true
+	{"StringPrefix": {"path_parent(_bk_iam_path_)": ["/biz,1/"]}}    =>  取上一级路径后比较
+	{"StringEqualsIgnoreCase": {"name": ["Admin"]}}                  =>  等价于 lower(name) 与小写后的值比较
+
+属性值为数组时, 对每个元素做转换; 非字符串的属性值保持原样
+*/
+
+type attrTransformFunc func(value string) interface{}
+
+// attrTransformFactories 转换函数名 => 根据参数生成转换函数
+var attrTransformFactories = map[string]func(arg string) (attrTransformFunc, error){
+	"lower": func(arg string) (attrTransformFunc, error) {
+		return func(value string) interface{} { return strings.ToLower(value) }, nil
+	},
+	"upper": func(arg string) (attrTransformFunc, error) {
+		return func(value string) interface{} { return strings.ToUpper(value) }, nil
+	},
+	"split": func(arg string) (attrTransformFunc, error) {
+		sep := arg
+		if sep == "" {
+			sep = ","
+		}
+		return func(value string) interface{} {
+			parts := strings.Split(value, sep)
+			values := make([]interface{}, 0, len(parts))
+			for _, p := range parts {
+				values = append(values, p)
+			}
+			return values
+		}, nil
+	},
+	"path_parent": func(arg string) (attrTransformFunc, error) {
+		return func(value string) interface{} { return pathParent(value) }, nil
+	},
+}
+
+// pathParent 返回上一级路径, 例如 /biz,1/set,2/ => /biz,1/
+func pathParent(path string) string {
+	trimmed := strings.TrimSuffix(path, "/")
+	idx := strings.LastIndex(trimmed, "/")
+	if idx < 0 {
+		return ""
+	}
+	return trimmed[:idx+1]
+}
+
+// TransformCondition 属性值转换的装饰条件
+type TransformCondition struct {
+	name string
+	// field 条件中的原始key, 例如 lower(name)
+	field     string
+	key       string
+	transform attrTransformFunc
+	content   Condition
+}
+
+// newTransformCondition 解析key中的转换函数, 用属性名创建被装饰的条件; key不是函数形式时直接创建条件
+func newTransformCondition(newConditionFunc conditionFunc, key string, values []interface{}) (Condition, error) {
+	t, ok := util.ParseAttrTransform(key)
+	if !ok {
+		return newConditionFunc(key, values)
+	}
+
+	factory, ok := attrTransformFactories[t.Func]
+	if !ok {
+		return nil, fmt.Errorf("can not support attribute transform %s in key %s", t.Func, key)
+	}
+	transform, err := factory(t.Arg)
+	if err != nil {
+		return nil, fmt.Errorf("attribute transform %s in key %s error: %w", t.Func, key, err)
+	}
+
+	content, err := newConditionFunc(t.Attr, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TransformCondition{
+		name:      content.GetName(),
+		field:     key,
+		key:       t.Attr,
+		transform: transform,
+		content:   content,
+	}, nil
+}
+
+// newStringEqualsIgnoreCaseCondition 忽略大小写的字符串相等, 即 lower(key) 与小写后的值比较
+func newStringEqualsIgnoreCaseCondition(key string, values []interface{}) (Condition, error) {
+	lowerValues := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			v = strings.ToLower(s)
+		}
+		lowerValues = append(lowerValues, v)
+	}
+
+	content, err := newStringEqualsCondition(key, lowerValues)
+	if err != nil {
+		return nil, err
+	}
+
+	transform, _ := attrTransformFactories["lower"]("")
+	return &TransformCondition{
+		name:      "StringEqualsIgnoreCase",
+		field:     key,
+		key:       key,
+		transform: transform,
+		content:   content,
+	}, nil
+}
+
+// GetName 名称
+func (c *TransformCondition) GetName() string {
+	return c.name
+}
+
+// Eval 求值
+func (c *TransformCondition) Eval(ctx types.AttributeGetter) bool {
+	return c.content.Eval(c.wrap(ctx))
+}
+
+// GetKeys 返回被装饰条件的属性key, 即转换前的属性名
+func (c *TransformCondition) GetKeys() []string {
+	return c.content.GetKeys()
+}
+
+func (c *TransformCondition) wrap(ctx types.AttributeGetter) types.AttributeGetter {
+	return &transformAttributeGetter{
+		AttributeGetter: ctx,
+		key:             c.key,
+		transform:       c.transform,
+	}
+}
+
+// transformAttributeGetter 获取指定的属性时, 返回转换后的值
+type transformAttributeGetter struct {
+	types.AttributeGetter
+
+	key       string
+	transform attrTransformFunc
+}
+
+// GetAttr ...
+func (g *transformAttributeGetter) GetAttr(name string) (interface{}, error) {
+	value, err := g.AttributeGetter.GetAttr(name)
+	if err != nil || name != g.key {
+		return value, err
+	}
+	return g.apply(value), nil
+}
+
+// GetFullNameAttr ...
+func (g *transformAttributeGetter) GetFullNameAttr(name string) (interface{}, error) {
+	value, err := g.AttributeGetter.GetFullNameAttr(name)
+	if err != nil || name != g.key {
+		return value, err
+	}
+	return g.apply(value), nil
+}
+
+func (g *transformAttributeGetter) apply(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return g.transform(v)
+	case []interface{}:
+		values := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				values = append(values, item)
+				continue
+			}
+
+			// split之后的结果展开到同一个数组中
+			switch tv := g.transform(s).(type) {
+			case []interface{}:
+				values = append(values, tv...)
+			default:
+				values = append(values, tv)
+			}
+		}
+		return values
+	default:
+		return value
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package condition
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ = Describe("TransformCondition", func() {
+
+	It("new fail", func() {
+		_, err := NewConditionByJSON([]byte(`{"StringEquals": {"reverse(name)": ["a"]}}`))
+		assert.Error(GinkgoT(), err)
+		assert.Contains(GinkgoT(), err.Error(), "can not support attribute transform reverse")
+
+		_, err = NewConditionByJSON([]byte(`{"StringWildcard": {"lower(name)": []}}`))
+		assert.Error(GinkgoT(), err)
+	})
+
+	It("lower", func() {
+		c, err := NewConditionByJSON([]byte(`{"StringEquals": {"lower(name)": ["admin"]}}`))
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), "StringEquals", c.GetName())
+		assert.Equal(GinkgoT(), []string{"name"}, c.GetKeys())
+
+		assert.True(GinkgoT(), c.Eval(explainCtx{"name": "Admin"}))
+		assert.True(GinkgoT(), c.Eval(explainCtx{"name": []interface{}{"x", "ADMIN"}}))
+		assert.False(GinkgoT(), c.Eval(explainCtx{"name": "root"}))
+		assert.False(GinkgoT(), c.Eval(explainCtx{}))
+	})
+
+	It("split", func() {
+		c, err := NewConditionByJSON([]byte(`{"StringEquals": {"split(tags, ;)": ["prod"]}}`))
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), []string{"tags"}, c.GetKeys())
+
+		assert.True(GinkgoT(), c.Eval(explainCtx{"tags": "dev;prod"}))
+		assert.True(GinkgoT(), c.Eval(explainCtx{"tags": []interface{}{"a;b", "c;prod"}}))
+		assert.False(GinkgoT(), c.Eval(explainCtx{"tags": "dev,prod"}))
+
+		// 默认按,拆分
+		c, err = NewConditionByJSON([]byte(`{"StringEquals": {"split(tags)": ["prod"]}}`))
+		assert.NoError(GinkgoT(), err)
+		assert.True(GinkgoT(), c.Eval(explainCtx{"tags": "dev,prod"}))
+	})
+
+	It("path_parent", func() {
+		c, err := NewConditionByJSON([]byte(`{"StringEquals": {"path_parent(_bk_iam_path_)": ["/biz,1/"]}}`))
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), []string{"_bk_iam_path_"}, c.GetKeys())
+
+		assert.True(GinkgoT(), c.Eval(explainCtx{"_bk_iam_path_": "/biz,1/set,2/"}))
+		assert.False(GinkgoT(), c.Eval(explainCtx{"_bk_iam_path_": "/biz,1/set,2/module,3/"}))
+	})
+
+	It("non-string attribute keep the origin value", func() {
+		c, err := NewConditionByJSON([]byte(`{"NumericEquals": {"lower(cpu)": [4]}}`))
+		assert.NoError(GinkgoT(), err)
+		assert.True(GinkgoT(), c.Eval(explainCtx{"cpu": float64(4)}))
+	})
+
+	It("StringEqualsIgnoreCase", func() {
+		c, err := NewConditionByJSON([]byte(`{"StringEqualsIgnoreCase": {"name": ["Admin", "Root"]}}`))
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), "StringEqualsIgnoreCase", c.GetName())
+		assert.Equal(GinkgoT(), []string{"name"}, c.GetKeys())
+
+		assert.True(GinkgoT(), c.Eval(explainCtx{"name": "ADMIN"}))
+		assert.True(GinkgoT(), c.Eval(explainCtx{"name": "root"}))
+		assert.False(GinkgoT(), c.Eval(explainCtx{"name": "guest"}))
+	})
+
+	It("StringEqualsIgnoreCase with transform", func() {
+		c, err := NewConditionByJSON(
+			[]byte(`{"StringEqualsIgnoreCase": {"path_parent(_bk_iam_path_)": ["/Biz,1/"]}}`),
+		)
+		assert.NoError(GinkgoT(), err)
+		assert.True(GinkgoT(), c.Eval(explainCtx{"_bk_iam_path_": "/BIZ,1/set,2/"}))
+	})
+
+	It("explain", func() {
+		c, err := NewConditionByJSON([]byte(`{"StringEquals": {"lower(name)": ["admin"]}}`))
+		assert.NoError(GinkgoT(), err)
+
+		e := Explain(c, explainCtx{"name": "Admin"})
+		assert.Equal(GinkgoT(), Explanation{
+			Operator:  "StringEquals",
+			Field:     "lower(name)",
+			Value:     []interface{}{"admin"},
+			Attribute: "admin",
+			Result:    true,
+		}, e)
+	})
+
+	DescribeTable("pathParent", func(path, expected string) {
+		assert.Equal(GinkgoT(), expected, pathParent(path))
+	},
+		Entry("two level", "/biz,1/set,2/", "/biz,1/"),
+		Entry("one level", "/biz,1/", "/"),
+		Entry("without tailing slash", "/biz,1/set,2", "/biz,1/"),
+		Entry("root", "/", ""),
+		Entry("empty", "", ""),
+	)
+})
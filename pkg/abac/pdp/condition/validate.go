@@ -0,0 +1,238 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package condition
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"iam/pkg/abac/pdp/types"
+	"iam/pkg/abac/pdp/util"
+	abactypes "iam/pkg/abac/types"
+)
+
+/*
+策略表达式校验
+
+与鉴权时的解析使用同一套条件工厂, 但不会在第一个错误处停止, 返回所有错误及其在表达式中的位置, 例如:
+	[0].expression.OR.content[1].StringPrefix: ...
+
+除了条件本身能否解析, 还会校验条件值的类型:
+	1. 值需要与操作符要求的类型一致, 例如 StringEquals 的值为字符串, NumericGt 的值为数值
+	2. _bk_iam_path_ 的值需要是合法的路径, 例如 /biz,1/set,2/, 最后一个节点的id可以为*
+	3. 系统注册了资源属性schema时, 属性的类型需要与操作符要求的类型一致
+这些值在鉴权时不会报错, 只会导致条件永远不满足
+*/
+
+// AttributeSchemaFunc 查询资源类型注册的属性schema {attribute_name: attribute_type}, 未注册时返回空
+type AttributeSchemaFunc func(system, _type string) (map[string]string, error)
+
+// ValidationError 表达式校验错误, Path为错误在表达式中的位置
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Error ...
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Validate 校验策略表达式(即policy.Expression), 包括json格式/操作符/属性/值
+// resourceTypes 为操作关联的资源类型, 非nil时校验表达式的资源类型与操作关联的资源类型一一对应
+func Validate(expression string, resourceTypes []abactypes.ActionResourceType) []ValidationError {
+	// schemaFunc为nil时不会返回err
+	errs, _ := ValidateWithSchema(expression, resourceTypes, nil)
+	return errs
+}
+
+// ValidateWithSchema 同Validate, 并根据schemaFunc查询到的资源属性schema校验属性与条件值的类型是否一致
+// err 为查询资源属性schema失败
+func ValidateWithSchema(
+	expression string,
+	resourceTypes []abactypes.ActionResourceType,
+	schemaFunc AttributeSchemaFunc,
+) ([]ValidationError, error) {
+	expressions := []types.ResourceExpression{}
+	if err := jsoniter.UnmarshalFromString(expression, &expressions); err != nil {
+		return []ValidationError{{Message: fmt.Sprintf("json unmarshal fail: %s", err)}}, nil
+	}
+
+	errs := []ValidationError{}
+	if resourceTypes != nil {
+		errs = append(errs, validateResourceTypes(expressions, resourceTypes)...)
+	}
+
+	for i, e := range expressions {
+		path := fmt.Sprintf("[%d]", i)
+		if e.System == "" || e.Type == "" {
+			errs = append(errs, ValidationError{Path: path, Message: "system and type should not be empty"})
+		}
+
+		var schema map[string]string
+		if schemaFunc != nil && e.System != "" && e.Type != "" {
+			var err error
+			schema, err = schemaFunc(e.System, e.Type)
+			if err != nil {
+				return nil, err
+			}
+		}
+		errs = append(errs, validatePolicyCondition(path+".expression", e.Expression, schema)...)
+	}
+	return errs, nil
+}
+
+func validateResourceTypes(
+	expressions []types.ResourceExpression,
+	resourceTypes []abactypes.ActionResourceType,
+) []ValidationError {
+	errs := []ValidationError{}
+
+	expected := make(map[string]bool, len(resourceTypes))
+	for _, rt := range resourceTypes {
+		expected[rt.System+":"+rt.Type] = false
+	}
+
+	for i, e := range expressions {
+		key := e.System + ":" + e.Type
+		found, ok := expected[key]
+		if !ok {
+			errs = append(errs, ValidationError{
+				Path:    fmt.Sprintf("[%d]", i),
+				Message: fmt.Sprintf("resource type `%s` is not related to the action", key),
+			})
+			continue
+		}
+		if found {
+			errs = append(errs, ValidationError{
+				Path:    fmt.Sprintf("[%d]", i),
+				Message: fmt.Sprintf("resource type `%s` is duplicated", key),
+			})
+			continue
+		}
+		expected[key] = true
+	}
+
+	// NOTE: 鉴权时每个资源类型都需要匹配到表达式, 否则会求值失败
+	for _, rt := range resourceTypes {
+		key := rt.System + ":" + rt.Type
+		if !expected[key] {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("missing expression of resource type `%s`", key),
+			})
+			// avoid reporting the duplicated resource types of the action twice
+			expected[key] = true
+		}
+	}
+	return errs
+}
+
+// ValidatePolicyCondition 递归校验单个资源类型的条件, path为条件在表达式中的位置
+func ValidatePolicyCondition(path string, condition types.PolicyCondition) []ValidationError {
+	return validatePolicyCondition(path, condition, nil)
+}
+
+// validatePolicyCondition schema为资源类型注册的属性schema, 为空时不校验属性的类型
+func validatePolicyCondition(path string, condition types.PolicyCondition, schema map[string]string) []ValidationError {
+	if len(condition) != 1 {
+		return []ValidationError{{
+			Path:    path,
+			Message: fmt.Sprintf("should contain exactly one operator, got %d", len(condition)),
+		}}
+	}
+
+	errs := []ValidationError{}
+	for operator, options := range condition {
+		operatorPath := path + "." + operator
+
+		newConditionFunc, ok := conditionFactories[operator]
+		if !ok {
+			errs = append(errs, ValidationError{
+				Path:    operatorPath,
+				Message: fmt.Sprintf("operator `%s` not supported, should be one of %s", operator, supportedOperators()),
+			})
+			continue
+		}
+
+		if len(options) != 1 {
+			errs = append(errs, ValidationError{
+				Path:    operatorPath,
+				Message: fmt.Sprintf("should contain exactly one attribute, got %d", len(options)),
+			})
+			continue
+		}
+
+		for key, values := range options {
+			switch operator {
+			case "AND", "OR", "NOT":
+				errs = append(errs, validateLogicalCondition(operatorPath, operator, key, values, schema)...)
+			default:
+				// 叶子条件直接使用工厂函数校验属性及值
+				if _, err := newTransformCondition(newConditionFunc, key, values); err != nil {
+					errs = append(errs, ValidationError{Path: operatorPath + "." + key, Message: err.Error()})
+					continue
+				}
+				errs = append(errs, validateValueTypes(operatorPath+"."+key, operator, key, values, schema)...)
+			}
+		}
+	}
+	return errs
+}
+
+func validateLogicalCondition(
+	path, operator, key string,
+	values []interface{},
+	schema map[string]string,
+) []ValidationError {
+	if key != "content" {
+		return []ValidationError{{
+			Path:    path,
+			Message: fmt.Sprintf("logical operator should use key `content`, got `%s`", key),
+		}}
+	}
+
+	if operator == "NOT" && len(values) != 1 {
+		return []ValidationError{{
+			Path:    path + ".content",
+			Message: fmt.Sprintf("should have only one condition, got %d", len(values)),
+		}}
+	}
+
+	errs := []ValidationError{}
+	for i, v := range values {
+		contentPath := fmt.Sprintf("%s.content[%d]", path, i)
+
+		pc, err := util.InterfaceToPolicyCondition(v)
+		if err != nil {
+			errs = append(errs, ValidationError{
+				Path:    contentPath,
+				Message: fmt.Sprintf("should be a condition like {\"operator\": {\"attribute\": [values]}}: %s", err),
+			})
+			continue
+		}
+		errs = append(errs, validatePolicyCondition(contentPath, pc, schema)...)
+	}
+	return errs
+}
+
+func supportedOperators() string {
+	operators := make([]string, 0, len(conditionFactories))
+	for operator := range conditionFactories {
+		operators = append(operators, operator)
+	}
+	sort.Strings(operators)
+	return "[" + strings.Join(operators, ", ") + "]"
+}
@@ -0,0 +1,204 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package condition
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	abactypes "iam/pkg/abac/types"
+)
+
+var _ = Describe("Validate", func() {
+	resourceTypes := []abactypes.ActionResourceType{
+		{System: "bk_cmdb", Type: "host"},
+	}
+
+	It("ok", func() {
+		expression := `[{"system": "bk_cmdb", "type": "host", "expression": {"OR": {"content": [
+			{"StringEquals": {"id": ["1", "2"]}},
+			{"StringPrefix": {"_bk_iam_path_": ["/biz,1/"]}},
+			{"NOT": {"content": [{"StringEquals": {"lower(os)": ["linux"]}}]}}
+		]}}}]`
+		errs := Validate(expression, resourceTypes)
+		assert.Empty(GinkgoT(), errs)
+	})
+
+	It("ok without resource types", func() {
+		errs := Validate(`[]`, []abactypes.ActionResourceType{})
+		assert.Empty(GinkgoT(), errs)
+
+		errs = Validate(`[{"system": "a", "type": "b", "expression": {"Any": {"id": []}}}]`, nil)
+		assert.Empty(GinkgoT(), errs)
+	})
+
+	It("invalid json", func() {
+		errs := Validate(`test`, nil)
+		assert.Len(GinkgoT(), errs, 1)
+		assert.Contains(GinkgoT(), errs[0].Error(), "json unmarshal fail")
+	})
+
+	It("resource types not match", func() {
+		expression := `[
+			{"system": "bk_cmdb", "type": "host", "expression": {"Any": {"id": []}}},
+			{"system": "bk_cmdb", "type": "host", "expression": {"Any": {"id": []}}},
+			{"system": "bk_cmdb", "type": "biz", "expression": {"Any": {"id": []}}}
+		]`
+		errs := Validate(expression, resourceTypes)
+		assert.Equal(GinkgoT(), []ValidationError{
+			{Path: "[1]", Message: "resource type `bk_cmdb:host` is duplicated"},
+			{Path: "[2]", Message: "resource type `bk_cmdb:biz` is not related to the action"},
+		}, errs)
+
+		errs = Validate(`[]`, resourceTypes)
+		assert.Equal(GinkgoT(), []ValidationError{
+			{Message: "missing expression of resource type `bk_cmdb:host`"},
+		}, errs)
+	})
+
+	It("invalid conditions with path", func() {
+		expression := `[{"system": "bk_cmdb", "type": "host", "expression": {"AND": {"content": [
+			{"StringEquals": {"id": ["1"]}},
+			{"StringEqual": {"id": ["1"]}},
+			{"OR": {"content": [{"StringRegex": {"name": ["("]}}, "abc"]}},
+			{"NOT": {"content": []}},
+			{"AND": {"contents": []}},
+			{"StringEquals": {"id": ["1"], "name": ["a"]}},
+			{}
+		]}}}]`
+		errs := Validate(expression, resourceTypes)
+
+		paths := make([]string, 0, len(errs))
+		for _, e := range errs {
+			paths = append(paths, e.Path)
+		}
+		assert.Equal(GinkgoT(), []string{
+			"[0].expression.AND.content[1].StringEqual",
+			"[0].expression.AND.content[2].OR.content[0].StringRegex.name",
+			"[0].expression.AND.content[2].OR.content[1]",
+			"[0].expression.AND.content[3].NOT.content",
+			"[0].expression.AND.content[4].AND",
+			"[0].expression.AND.content[5].StringEquals",
+			"[0].expression.AND.content[6]",
+		}, paths)
+		assert.Contains(GinkgoT(), errs[0].Message, "operator `StringEqual` not supported")
+	})
+
+	It("empty system or type", func() {
+		errs := Validate(`[{"system": "", "type": "host", "expression": {"Any": {"id": []}}}]`, nil)
+		assert.Equal(GinkgoT(), []ValidationError{
+			{Path: "[0]", Message: "system and type should not be empty"},
+		}, errs)
+	})
+
+	It("value types not match the operator", func() {
+		expression := `[{"system": "bk_cmdb", "type": "host", "expression": {"AND": {"content": [
+			{"StringEquals": {"id": [1]}},
+			{"NumericEquals": {"level": ["1"]}},
+			{"Bool": {"enabled": ["true"]}},
+			{"NumericGte": {"level": [1.5]}}
+		]}}}]`
+		errs := Validate(expression, resourceTypes)
+		assert.Equal(GinkgoT(), []ValidationError{
+			{
+				Path:    "[0].expression.AND.content[0].StringEquals.id[0]",
+				Message: "operator `StringEquals` requires `string` value, got `float64`(1)",
+			},
+			{
+				Path:    "[0].expression.AND.content[1].NumericEquals.level[0]",
+				Message: "operator `NumericEquals` requires `numeric` value, got `string`(1)",
+			},
+			{
+				Path:    "[0].expression.AND.content[2].Bool.enabled[0]",
+				Message: "operator `Bool` requires `bool` value, got `string`(true)",
+			},
+		}, errs)
+	})
+
+	It("invalid path", func() {
+		expression := `[{"system": "bk_cmdb", "type": "host", "expression": {"OR": {"content": [
+			{"StringPrefix": {"_bk_iam_path_": ["/biz,1/set,*/", "/biz,1/", "/"]}},
+			{"StringEquals": {"path_parent(_bk_iam_path_)": ["/biz,1/set,2/"]}},
+			{"StringPrefix": {"_bk_iam_path_": ["/biz,1", "biz,1/", "/biz/", "/biz,*/set,1/", "/biz,1//"]}},
+			{"StringEquals": {"path_parent(_bk_iam_path_)": ["/biz"]}},
+			{"StringEquals": {"lower(_bk_iam_path_)": ["biz"]}}
+		]}}}]`
+		errs := Validate(expression, resourceTypes)
+
+		paths := make([]string, 0, len(errs))
+		for _, e := range errs {
+			paths = append(paths, e.Path)
+		}
+		assert.Equal(GinkgoT(), []string{
+			"[0].expression.OR.content[2].StringPrefix._bk_iam_path_[0]",
+			"[0].expression.OR.content[2].StringPrefix._bk_iam_path_[1]",
+			"[0].expression.OR.content[2].StringPrefix._bk_iam_path_[2]",
+			"[0].expression.OR.content[2].StringPrefix._bk_iam_path_[3]",
+			"[0].expression.OR.content[2].StringPrefix._bk_iam_path_[4]",
+			"[0].expression.OR.content[3].StringEquals.path_parent(_bk_iam_path_)[0]",
+		}, paths)
+		assert.Contains(GinkgoT(), errs[0].Message, "path `/biz,1` invalid")
+	})
+
+	It("with schema", func() {
+		schemaFunc := func(system, _type string) (map[string]string, error) {
+			assert.Equal(GinkgoT(), "bk_cmdb", system)
+			assert.Equal(GinkgoT(), "host", _type)
+			return map[string]string{"level": "numeric", "os": "string", "enabled": "bool"}, nil
+		}
+
+		expression := `[{"system": "bk_cmdb", "type": "host", "expression": {"AND": {"content": [
+			{"NumericGt": {"level": [1]}},
+			{"StringEquals": {"lower(os)": ["linux"]}},
+			{"Bool": {"enabled": [true]}},
+			{"StringEquals": {"other": ["a"]}}
+		]}}}]`
+		errs, err := ValidateWithSchema(expression, resourceTypes, schemaFunc)
+		assert.NoError(GinkgoT(), err)
+		assert.Empty(GinkgoT(), errs)
+
+		expression = `[{"system": "bk_cmdb", "type": "host", "expression": {"AND": {"content": [
+			{"StringEquals": {"level": ["1"]}},
+			{"NumericEquals": {"os": [1]}},
+			{"StringEquals": {"lower(enabled)": ["true"]}},
+			{"NumericEquals": {"id": [1]}}
+		]}}}]`
+		errs, err = ValidateWithSchema(expression, resourceTypes, schemaFunc)
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), []ValidationError{
+			{
+				Path:    "[0].expression.AND.content[0].StringEquals.level",
+				Message: "attribute `level` is `numeric`, can not use operator `StringEquals`",
+			},
+			{
+				Path:    "[0].expression.AND.content[1].NumericEquals.os",
+				Message: "attribute `os` is `string`, can not use operator `NumericEquals`",
+			},
+			{
+				Path:    "[0].expression.AND.content[2].StringEquals.lower(enabled)",
+				Message: "attribute `enabled` is `bool`, can not use operator `StringEquals`",
+			},
+			{
+				Path:    "[0].expression.AND.content[3].NumericEquals.id",
+				Message: "attribute `id` is `string`, can not use operator `NumericEquals`",
+			},
+		}, errs)
+	})
+
+	It("get schema fail", func() {
+		_, err := ValidateWithSchema(`[{"system": "bk_cmdb", "type": "host", "expression": {"Any": {"id": []}}}]`,
+			resourceTypes, func(system, _type string) (map[string]string, error) {
+				return nil, errors.New("get schema fail")
+			})
+		assert.Error(GinkgoT(), err)
+	})
+})
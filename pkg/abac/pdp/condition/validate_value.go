@@ -0,0 +1,137 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package condition
+
+import (
+	"fmt"
+	"strings"
+
+	"iam/pkg/abac/pdp/util"
+)
+
+// 条件值的类型, 与资源属性schema中的属性类型一致
+const (
+	valueTypeString  = "string"
+	valueTypeNumeric = "numeric"
+	valueTypeBool    = "bool"
+)
+
+// operatorValueTypes 操作符要求的值类型; 未列出的操作符(Any/环境条件等)由工厂函数自行校验
+var operatorValueTypes = map[string]string{
+	"StringEquals":           valueTypeString,
+	"StringEqualsIgnoreCase": valueTypeString,
+	"StringPrefix":           valueTypeString,
+	"StringWildcard":         valueTypeString,
+	"StringRegex":            valueTypeString,
+	"NumericEquals":          valueTypeNumeric,
+	"NumericGt":              valueTypeNumeric,
+	"NumericGte":             valueTypeNumeric,
+	"NumericLt":              valueTypeNumeric,
+	"NumericLte":             valueTypeNumeric,
+	"Bool":                   valueTypeBool,
+}
+
+// validateValueTypes 校验叶子条件的值类型, key可能是转换函数的形式, 例如 lower(name)
+func validateValueTypes(
+	path, operator, key string,
+	values []interface{},
+	schema map[string]string,
+) []ValidationError {
+	valueType, ok := operatorValueTypes[operator]
+	if !ok {
+		return nil
+	}
+
+	attr := key
+	transformFunc := ""
+	if t, ok := util.ParseAttrTransform(key); ok {
+		attr = t.Attr
+		transformFunc = t.Func
+	}
+
+	errs := []ValidationError{}
+	for i, value := range values {
+		valuePath := fmt.Sprintf("%s[%d]", path, i)
+		if !isValueOfType(value, valueType) {
+			message := fmt.Sprintf("operator `%s` requires `%s` value, got `%T`(%v)", operator, valueType, value, value)
+			errs = append(errs, ValidationError{Path: valuePath, Message: message})
+			continue
+		}
+
+		// 取上一级路径后比较的值同样是路径, 其他转换函数的结果不再是路径
+		if attr == iamPath && (transformFunc == "" || transformFunc == "path_parent") {
+			if err := validateIAMPath(value.(string)); err != nil {
+				errs = append(errs, ValidationError{Path: valuePath, Message: err.Error()})
+			}
+		}
+	}
+
+	// 内置属性为字符串; NOTE: 转换函数只作用于字符串属性, 与字符串操作符一并校验
+	attrType := schema[attr]
+	if _, ok := builtinAttrs[attr]; ok {
+		attrType = valueTypeString
+	}
+	if attrType != "" && attrType != valueType {
+		errs = append(errs, ValidationError{
+			Path:    path,
+			Message: fmt.Sprintf("attribute `%s` is `%s`, can not use operator `%s`", attr, attrType, operator),
+		})
+	}
+	return errs
+}
+
+// 内置的资源属性, 不需要注册schema
+var builtinAttrs = map[string]struct{}{
+	"id":    {},
+	iamPath: {},
+}
+
+func isValueOfType(value interface{}, valueType string) bool {
+	switch valueType {
+	case valueTypeString:
+		_, ok := value.(string)
+		return ok
+	case valueTypeNumeric:
+		switch value.(type) {
+		case int, int32, int64, uint, uint32, uint64, float32, float64:
+			return true
+		}
+		return false
+	case valueTypeBool:
+		_, ok := value.(bool)
+		return ok
+	}
+	return false
+}
+
+// validateIAMPath 校验拓扑路径, 例如 /biz,1/set,2/, 最后一个节点的id可以为*; 根路径为 /
+func validateIAMPath(path string) error {
+	if path == "/" {
+		return nil
+	}
+
+	invalid := fmt.Errorf("path `%s` invalid, should be like `/biz,1/set,2/` or `/biz,1/set,*/`", path)
+	if len(path) < 2 || !strings.HasPrefix(path, "/") || !strings.HasSuffix(path, "/") {
+		return invalid
+	}
+
+	nodes := strings.Split(path[1:len(path)-1], "/")
+	for i, node := range nodes {
+		parts := strings.SplitN(node, ",", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return invalid
+		}
+		if parts[1] == "*" && i != len(nodes)-1 {
+			return invalid
+		}
+	}
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	"iam/pkg/abac/types/request"
 	"iam/pkg/errorx"
 	"iam/pkg/logging/debug"
+	"iam/pkg/util"
 )
 
 /*
@@ -48,6 +49,9 @@ const PDP = "PDP"
 var (
 	EmptyPolicies = map[string]interface{}{}
 
+	// AnyExpression 有任意资源的权限, 操作被禁用且配置为bypass时返回
+	AnyExpression = map[string]interface{}{"op": "any", "field": "", "value": []interface{}{}}
+
 	ErrInvalidActionResource = errors.New("validateActionResource fail")
 )
 
@@ -59,6 +63,13 @@ func Eval(
 ) (isPass bool, err error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "Eval")
 
+	release, err := acquireEval(r.System)
+	if err != nil {
+		err = errorWrapf(err, "acquireEval system=`%s` fail", r.System)
+		return
+	}
+	defer release()
+
 	// init debug entry with values
 	if entry != nil {
 		debug.WithValues(entry, map[string]interface{}{
@@ -70,17 +81,379 @@ func Eval(
 		})
 	}
 
-	// 1. PIP查询action
+	// NOTE: 需要在求值之前生成, 求值过程中会填充远程资源的属性
+	key := staleDecisionKey(r, r.Action.ID, r.Resources)
+
+	isPass, err = eval(r, entry, withoutCache)
+	if err != nil {
+		// 依赖失败时, 根据系统配置的策略决定结果
+		return handleEvalFailure(r.System, r.Action.ID, key, err, entry)
+	}
+
+	recordDecision(key, isPass)
+	return isPass, nil
+}
+
+func eval(r *request.Request, entry *debug.Entry, withoutCache bool) (isPass bool, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "Eval")
+
+	// 1. PIP查询action, 并检查请求资源与action关联的类型是否匹配
+	err = fillAndValidateAction(r, entry)
+	if err != nil {
+		if errors.Is(err, ErrInvalidAction) {
+			return false, err
+		}
+
+		err = errorWrapf(err, "fillAndValidateAction action=`%+v` fail", r.Action)
+		return false, err
+	}
+
+	// 操作被禁用时, 根据系统配置拒绝或放行
+	bypass, err := checkActionStatus(r.System, r.Action.ID, entry)
+	if err != nil || bypass {
+		return bypass, err
+	}
+
+	// 2. PIP查询subject相关的属性
+	debug.AddStep(entry, "Fetch subject details")
+	stopTiming := debug.StartTiming(entry, debug.TimingCache)
+	err = fillSubjectDetail(r)
+	stopTiming()
+	if err != nil {
+		// 如果用户不存在或被冻结, 表现为没有权限
+		// if the subject not exists or is frozen
+		if isSubjectInactive(err) {
+			return false, nil
+		}
+
+		err = errorWrapf(err, "request fillSubjectDetail subject=`%+v`", r.Subject)
+
+		return
+	}
+	debug.WithValue(entry, "subject", r.Subject)
+
+	// 3. 查询策略并计算
+	return evalPolicies(r, entry, withoutCache)
+}
+
+// BatchEvalActions 批量鉴权入口: 同一个subject与资源, 对多个action鉴权; subject的属性只查询一次
+func BatchEvalActions(
+	r *request.Request,
+	actionIDs []string,
+	entry *debug.Entry,
+	withoutCache bool,
+) (results map[string]bool, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "BatchEvalActions")
+
+	release, err := acquireEval(r.System)
+	if err != nil {
+		err = errorWrapf(err, "acquireEval system=`%s` fail", r.System)
+		return
+	}
+	defer release()
+
+	if entry != nil {
+		debug.WithValues(entry, map[string]interface{}{
+			"system":       r.System,
+			"subject":      r.Subject,
+			"actions":      actionIDs,
+			"resources":    r.Resources,
+			"cacheEnabled": !withoutCache,
+		})
+	}
+
+	keys := make(map[string]string, len(actionIDs))
+	for _, actionID := range actionIDs {
+		keys[actionID] = staleDecisionKey(r, actionID, r.Resources)
+	}
+
+	results, err = batchEvalActions(r, actionIDs, entry, withoutCache)
+	if err != nil {
+		// 依赖失败时, 每个action根据系统配置的策略决定结果
+		evalErr := err
+		failureResults := make(map[string]bool, len(actionIDs))
+		for _, actionID := range actionIDs {
+			var isPass bool
+			isPass, err = handleEvalFailure(r.System, actionID, keys[actionID], evalErr, entry)
+			if err != nil {
+				return nil, err
+			}
+			failureResults[actionID] = isPass
+		}
+		return failureResults, nil
+	}
+
+	for actionID, isPass := range results {
+		recordDecision(keys[actionID], isPass)
+	}
+	return results, nil
+}
+
+func batchEvalActions(
+	r *request.Request,
+	actionIDs []string,
+	entry *debug.Entry,
+	withoutCache bool,
+) (results map[string]bool, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "BatchEvalActions")
+
+	// 1. PIP查询所有的action, 并检查请求资源与action关联的类型是否匹配
+	debug.AddStep(entry, "Fetch and validate actions")
+	reqs := make([]*request.Request, 0, len(actionIDs))
+	bypassActionIDs := util.NewStringSet()
+	for _, actionID := range actionIDs {
+		req := &request.Request{
+			System:    r.System,
+			Subject:   r.Subject,
+			Action:    types.NewAction(),
+			Resources: r.Resources,
+			Env:       r.Env,
+		}
+		req.Action.ID = actionID
+
+		err = fillAndValidateAction(req, nil)
+		if err != nil {
+			if errors.Is(err, ErrInvalidAction) {
+				return nil, err
+			}
+
+			err = errorWrapf(err, "fillAndValidateAction action=`%s` fail", actionID)
+			return nil, err
+		}
+
+		var bypass bool
+		bypass, err = checkActionStatus(r.System, actionID, entry)
+		if err != nil {
+			return nil, err
+		}
+		if bypass {
+			bypassActionIDs.Add(actionID)
+			continue
+		}
+		reqs = append(reqs, req)
+	}
+
+	results = make(map[string]bool, len(actionIDs))
+	for _, actionID := range bypassActionIDs.ToSlice() {
+		results[actionID] = true
+	}
+
+	// 2. PIP查询subject相关的属性, 所有action共用
+	debug.AddStep(entry, "Fetch subject details")
+	stopTiming := debug.StartTiming(entry, debug.TimingCache)
+	err = fillSubjectDetail(r)
+	stopTiming()
+	if err != nil {
+		// 如果用户不存在或被冻结, 表现为没有权限
+		if isSubjectInactive(err) {
+			for _, actionID := range actionIDs {
+				results[actionID] = bypassActionIDs.Has(actionID)
+			}
+			return results, nil
+		}
+
+		err = errorWrapf(err, "request fillSubjectDetail subject=`%+v`", r.Subject)
+		return nil, err
+	}
+	debug.WithValue(entry, "subject", r.Subject)
+
+	// 3. 逐个action查询策略并计算
+	debug.AddStep(entry, "Eval actions")
+	for _, req := range reqs {
+		req.Subject = r.Subject
+
+		subEntry := debug.NewSubDebug(entry)
+		debug.WithValue(subEntry, "action", req.Action)
+
+		var isPass bool
+		isPass, err = evalPolicies(req, subEntry, withoutCache)
+		debug.WithError(subEntry, err)
+		if err != nil {
+			err = errorWrapf(err, "evalPolicies action=`%s` fail", req.Action.ID)
+			return nil, err
+		}
+
+		results[req.Action.ID] = isPass
+	}
+
+	return results, nil
+}
+
+// BatchEvalResources 批量鉴权入口: 同一个subject与action, 对多组资源实例鉴权; 策略只查询一次, 每组资源分别计算
+// 返回的结果与resourcesList一一对应
+func BatchEvalResources(
+	r *request.Request,
+	resourcesList [][]types.Resource,
+	entry *debug.Entry,
+	withoutCache bool,
+) (results []bool, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "BatchEvalResources")
+
+	release, err := acquireEval(r.System)
+	if err != nil {
+		err = errorWrapf(err, "acquireEval system=`%s` fail", r.System)
+		return
+	}
+	defer release()
+
+	if entry != nil {
+		debug.WithValues(entry, map[string]interface{}{
+			"system":        r.System,
+			"subject":       r.Subject,
+			"action":        r.Action,
+			"resourcesList": resourcesList,
+			"cacheEnabled":  !withoutCache,
+		})
+	}
+
+	keys := make([]string, 0, len(resourcesList))
+	for _, resources := range resourcesList {
+		keys = append(keys, staleDecisionKey(r, r.Action.ID, resources))
+	}
+
+	results, err = batchEvalResources(r, resourcesList, entry, withoutCache)
+	if err != nil {
+		// 依赖失败时, 每组资源根据系统配置的策略决定结果
+		evalErr := err
+		failureResults := make([]bool, len(resourcesList))
+		for i := range resourcesList {
+			failureResults[i], err = handleEvalFailure(r.System, r.Action.ID, keys[i], evalErr, entry)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return failureResults, nil
+	}
+
+	for i, isPass := range results {
+		recordDecision(keys[i], isPass)
+	}
+	return results, nil
+}
+
+func batchEvalResources(
+	r *request.Request,
+	resourcesList [][]types.Resource,
+	entry *debug.Entry,
+	withoutCache bool,
+) (results []bool, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "BatchEvalResources")
+
+	// 1. PIP查询action, 并检查每组请求资源与action关联的类型是否匹配
 	debug.AddStep(entry, "Fetch action details")
+	stopTiming := debug.StartTiming(entry, debug.TimingCache)
 	err = fillActionDetail(r)
+	stopTiming()
 	if err != nil {
-		err = errorWrapf(err, "Fetch action detail action=`%+v` fail", r.Action)
 		if errors.Is(err, sql.ErrNoRows) {
-			err = ErrInvalidAction
-			return
+			return nil, ErrInvalidAction
 		}
 
-		return
+		err = errorWrapf(err, "fillActionDetail action=`%+v` fail", r.Action)
+		return nil, err
+	}
+	debug.WithValue(entry, "action", r.Action)
+
+	// 操作被禁用时, 根据系统配置拒绝或放行
+	bypass, err := checkActionStatus(r.System, r.Action.ID, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	debug.AddStep(entry, "Validate action resources")
+	reqs := make([]*request.Request, 0, len(resourcesList))
+	for _, resources := range resourcesList {
+		req := &request.Request{
+			System:    r.System,
+			Subject:   r.Subject,
+			Action:    r.Action,
+			Resources: resources,
+			Env:       r.Env,
+		}
+		if !req.ValidateActionResource() {
+			err = errorWrapf(ErrInvalidActionResource,
+				"ValidateActionResource systemID=`%s`, actionID=`%s`, resources=`%+v` fail, "+
+					"request resources not match action",
+				r.System, r.Action.ID, resources)
+			return nil, err
+		}
+		reqs = append(reqs, req)
+	}
+
+	results = make([]bool, len(resourcesList))
+	if bypass {
+		for i := range results {
+			results[i] = true
+		}
+		return results, nil
+	}
+
+	// 2. PIP查询subject相关的属性
+	debug.AddStep(entry, "Fetch subject details")
+	stopTiming = debug.StartTiming(entry, debug.TimingCache)
+	err = fillSubjectDetail(r)
+	stopTiming()
+	if err != nil {
+		// 如果用户不存在或被冻结, 表现为没有权限
+		if isSubjectInactive(err) {
+			return results, nil
+		}
+
+		err = errorWrapf(err, "request fillSubjectDetail subject=`%+v`", r.Subject)
+		return nil, err
+	}
+	debug.WithValue(entry, "subject", r.Subject)
+
+	// 3. PRP查询subject-action相关的policies, 所有资源共用
+	debug.AddStep(entry, "Query Policies")
+	policies, err := queryPolicies(r.System, r.Subject, r.Action, withoutCache, entry)
+	if err != nil {
+		if errors.Is(err, ErrNoPolicies) {
+			return results, nil
+		}
+
+		err = errorWrapf(err, "queryPolicies system=`%s`, subject=`%+v`, action=`%+v`, withoutCache=`%t` fail",
+			r.System, r.Subject, r.Action, withoutCache)
+		return nil, err
+	}
+
+	// 4. 逐组资源计算
+	debug.AddStep(entry, "Eval resources")
+	for i, req := range reqs {
+		req.Subject = r.Subject
+
+		subEntry := debug.NewSubDebug(entry)
+		debug.WithValue(subEntry, "resources", req.Resources)
+
+		var isPass bool
+		isPass, err = evalQueriedPolicies(req, policies, subEntry)
+		debug.WithError(subEntry, err)
+		if err != nil {
+			err = errorWrapf(err, "evalQueriedPolicies resources=`%+v` fail", req.Resources)
+			return nil, err
+		}
+
+		results[i] = isPass
+	}
+
+	return results, nil
+}
+
+// fillAndValidateAction 查询action的详情, 并检查请求的资源与action关联的资源类型是否匹配
+func fillAndValidateAction(r *request.Request, entry *debug.Entry) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "fillAndValidateAction")
+
+	// 1. PIP查询action
+	debug.AddStep(entry, "Fetch action details")
+	stopTiming := debug.StartTiming(entry, debug.TimingCache)
+	err := fillActionDetail(r)
+	stopTiming()
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidAction
+		}
+
+		return errorWrapf(err, "Fetch action detail action=`%+v` fail", r.Action)
 	}
 	debug.WithValue(entry, "action", r.Action)
 
@@ -91,26 +464,28 @@ func Eval(
 			"ValidateActionResource systemID=`%s`, actionID=`%d`, resources=`%+v` fail, "+
 				"request resources not match action",
 			r.System, r.Action.ID, r.Resources)
-		return false, err
+		return err
 	}
 
-	// 3. PIP查询subject相关的属性
-	debug.AddStep(entry, "Fetch subject details")
-	err = fillSubjectDetail(r)
-	if err != nil {
-		// 如果用户不存在, 表现为没有权限
-		// if the subject not exists
-		if errors.Is(err, sql.ErrNoRows) {
-			return false, nil
+	// NOTE: debug mode, validate the resource attributes with the schema registered by the system
+	if entry != nil {
+		debug.AddStep(entry, "Validate resource attributes")
+		mismatches, err1 := validateResourceAttrs(r)
+		if err1 != nil {
+			debug.WithValue(entry, "resourceAttributeMismatches", "validate fail: "+err1.Error())
+		} else if len(mismatches) > 0 {
+			debug.WithValue(entry, "resourceAttributeMismatches", mismatches)
 		}
+	}
 
-		err = errorWrapf(err, "request fillSubjectDetail subject=`%+v`", r.Subject)
+	return nil
+}
 
-		return
-	}
-	debug.WithValue(entry, "subject", r.Subject)
+// evalPolicies 查询subject-action相关的policies, 并根据请求的资源计算是否有权限
+func evalPolicies(r *request.Request, entry *debug.Entry, withoutCache bool) (isPass bool, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "evalPolicies")
 
-	// 4. PRP查询subject-action相关的policies: 根据 system / subject / action 获取策略列表
+	// 1. PRP查询subject-action相关的policies: 根据 system / subject / action 获取策略列表
 	debug.AddStep(entry, "Query Policies")
 	policies, err := queryPolicies(r.System, r.Subject, r.Action, withoutCache, entry)
 	if err != nil {
@@ -122,6 +497,14 @@ func Eval(
 			r.System, r.Subject, r.Action, withoutCache)
 		return false, err
 	}
+
+	return evalQueriedPolicies(r, policies, entry)
+}
+
+// evalQueriedPolicies 对查询到的policies进行计算
+func evalQueriedPolicies(r *request.Request, policies []types.AuthPolicy, entry *debug.Entry) (isPass bool, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "evalQueriedPolicies")
+
 	debug.WithValue(entry, "policies", policies)
 	debug.WithUnknownEvalPolicies(entry, policies)
 
@@ -132,15 +515,21 @@ func Eval(
 		//queryResourceTypes, err := r.GetQueryResourceTypes()
 		queryResourceTypes, err1 := r.Action.Attribute.GetResourceTypes()
 		if err1 == nil {
-			expr, err2 := translate.PoliciesTranslate(policies, queryResourceTypes)
+			expr, err2 := TranslatePolicies(r.System, policies, queryResourceTypes)
 			if err2 == nil {
 				debug.WithValue(entry, "expression", expr)
 			}
 		}
 	}
 
+	// 没有deny策略且有any策略时直接通过, 不需要填充资源的属性, 也不需要查询远程资源
+	if evaluation.HasAnyPolicy(policies) {
+		debug.AddStep(entry, "Any policy pass")
+		return true, nil
+	}
+
 	debug.AddStep(entry, "Eval")
-	// 5. 针对只有一个本地资源的操作, 只需要计算一次(大部分场景, 只计算一次)
+	// 2. 针对只有一个本地资源的操作, 只需要计算一次(大部分场景, 只计算一次)
 	if r.HasSingleLocalResource() {
 		debug.AddStep(entry, "Single local resource eval")
 		resource := r.GetSortedResources()[0]
@@ -164,10 +553,10 @@ func Eval(
 		return isPass, err
 	}
 
-	// 6. 过滤policies
+	// 3. 过滤policies
 	debug.AddStep(entry, "Filter policies by eval resources")
 	var filteredPolicies []types.AuthPolicy
-	filteredPolicies, err = filterPoliciesByEvalResources(r, policies)
+	filteredPolicies, err = filterPoliciesByEvalResources(r, policies, entry)
 	if err != nil {
 		if errors.Is(err, ErrNoPolicies) {
 			// if is len(filteredPolicies) == 0, update all to no pass
@@ -181,6 +570,13 @@ func Eval(
 		return false, err
 	}
 
+	// 根据系统的求值模式判断: 默认命中deny策略没有权限; first_match模式由优先级最高的命中策略决定
+	if !evaluation.IsAllowed(r.System, filteredPolicies) {
+		debug.WithNoPassEvalPolicies(entry, policies)
+
+		return false, nil
+	}
+
 	// update all  filteredPolicies to pass, 有一条过就算过
 	debug.WithPassEvalPolicies(entry, filteredPolicies)
 
@@ -196,6 +592,21 @@ func Query(
 ) (map[string]interface{}, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "Query")
 
+	release, err := acquireEval(r.System)
+	if err != nil {
+		return nil, errorWrapf(err, "acquireEval system=`%s` fail", r.System)
+	}
+	defer release()
+
+	// 操作被禁用时, 根据系统配置拒绝或放行
+	bypass, err := checkActionStatus(r.System, r.Action.ID, entry)
+	if err != nil {
+		return nil, err
+	}
+	if bypass {
+		return AnyExpression, nil
+	}
+
 	// 1. 查询请求相关的策略
 	policies, err := queryFilterPolicies(r, entry, willCheckRemoteResource, withoutCache)
 	if err != nil {
@@ -203,11 +614,134 @@ func Query(
 		return nil, err
 	}
 
+	// 2. policies表达式转换
+	return translateQueriedPolicies(r, policies, entry)
+}
+
+// BatchQueryByActions 批量查询入口: 同一个subject与资源, 查询多个action相关的Policy; subject的属性只查询一次
+// 返回 action_id => expression
+func BatchQueryByActions(
+	r *request.Request,
+	actionIDs []string,
+	entry *debug.Entry,
+	willCheckRemoteResource,
+	withoutCache bool,
+) (exprs map[string]map[string]interface{}, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "BatchQueryByActions")
+
+	release, err := acquireEval(r.System)
+	if err != nil {
+		return nil, errorWrapf(err, "acquireEval system=`%s` fail", r.System)
+	}
+	defer release()
+
+	if entry != nil {
+		debug.WithValues(entry, map[string]interface{}{
+			"system":       r.System,
+			"subject":      r.Subject,
+			"actions":      actionIDs,
+			"resources":    r.Resources,
+			"cacheEnabled": !withoutCache,
+		})
+	}
+
+	exprs = make(map[string]map[string]interface{}, len(actionIDs))
+
+	// 1. PIP查询所有的action, 并检查请求资源与action关联的外部依赖资源类型是否匹配
+	debug.AddStep(entry, "Fetch and validate actions")
+	reqs := make([]*request.Request, 0, len(actionIDs))
+	for _, actionID := range actionIDs {
+		req := &request.Request{
+			System:    r.System,
+			Subject:   r.Subject,
+			Action:    types.NewAction(),
+			Resources: r.Resources,
+			Env:       r.Env,
+		}
+		req.Action.ID = actionID
+
+		stopTiming := debug.StartTiming(entry, debug.TimingCache)
+		err = fillActionDetail(req)
+		stopTiming()
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrInvalidAction
+			}
+
+			return nil, errorWrapf(err, "fillActionDetail action=`%s` fail", actionID)
+		}
+
+		if willCheckRemoteResource && !req.ValidateActionRemoteResource() {
+			return nil, errorWrapf(ErrInvalidActionResource,
+				"ValidateActionRemoteResource systemID=`%s`, actionID=`%s`, resources=`%+v` fail, "+
+					"request resources not match action",
+				r.System, actionID, r.Resources)
+		}
+
+		var bypass bool
+		bypass, err = checkActionStatus(r.System, actionID, entry)
+		if err != nil {
+			return nil, err
+		}
+		if bypass {
+			exprs[actionID] = AnyExpression
+			continue
+		}
+		reqs = append(reqs, req)
+	}
+
+	// 2. PIP查询subject相关的属性, 所有action共用
+	debug.AddStep(entry, "Fetch subject details")
+	stopTiming := debug.StartTiming(entry, debug.TimingCache)
+	err = fillSubjectDetail(r)
+	stopTiming()
+	if err != nil {
+		// 如果用户不存在或被冻结, 表现为没有权限
+		if isSubjectInactive(err) {
+			for _, req := range reqs {
+				exprs[req.Action.ID] = EmptyPolicies
+			}
+			return exprs, nil
+		}
+
+		return nil, errorWrapf(err, "request fillSubjectDetail subject=`%+v`", r.Subject)
+	}
+	debug.WithValue(entry, "subject", r.Subject)
+
+	// 3. 逐个action查询策略并转换表达式
+	debug.AddStep(entry, "Query actions")
+	for _, req := range reqs {
+		req.Subject = r.Subject
+
+		subEntry := debug.NewSubDebug(entry)
+		debug.WithValue(subEntry, "action", req.Action)
+
+		var policies []types.AuthPolicy
+		policies, err = queryAndFilterPolicies(req, subEntry, withoutCache)
+		if err == nil {
+			exprs[req.Action.ID], err = translateQueriedPolicies(req, policies, subEntry)
+		}
+		debug.WithError(subEntry, err)
+		if err != nil {
+			return nil, errorWrapf(err, "query action=`%s` fail", req.Action.ID)
+		}
+	}
+
+	return exprs, nil
+}
+
+// translateQueriedPolicies 将查询到的policies转换为请求的local action resource type的表达式
+func translateQueriedPolicies(
+	r *request.Request,
+	policies []types.AuthPolicy,
+	entry *debug.Entry,
+) (map[string]interface{}, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "translateQueriedPolicies")
+
 	if len(policies) == 0 {
 		return EmptyPolicies, nil
 	}
 
-	// 2. policies表达式转换
 	// 查找请求的local action resource type
 	queryResourceTypes, err := r.GetQueryResourceTypes()
 	if err != nil {
@@ -216,9 +750,9 @@ func Query(
 		return nil, err
 	}
 
-	expr, err := translate.PoliciesTranslate(policies, queryResourceTypes)
+	expr, err := TranslatePolicies(r.System, policies, queryResourceTypes)
 	if err != nil {
-		err = errorWrapf(err, "PoliciesTranslate resourceTypes=`%+v` fail", queryResourceTypes)
+		err = errorWrapf(err, "TranslatePolicies resourceTypes=`%+v` fail", queryResourceTypes)
 
 		return nil, err
 	}
@@ -236,17 +770,28 @@ func QueryByExtResources(
 ) (map[string]interface{}, []types.ExtResourceWithAttribute, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "QueryByExtResources")
 
-	var (
-		policies []types.AuthPolicy
-		err      error
-	)
-	// 1. 查询请求相关的策略
-	policies, err = queryFilterPolicies(r, entry, false, withoutCache)
+	release, err := acquireEval(r.System)
+	if err != nil {
+		return nil, nil, errorWrapf(err, "acquireEval system=`%s` fail", r.System)
+	}
+	defer release()
+
+	// 操作被禁用时, 根据系统配置拒绝或放行
+	bypass, err := checkActionStatus(r.System, r.Action.ID, entry)
 	if err != nil {
-		err = errorWrapf(err, "queryFilterPolicies fail", r.Action)
 		return nil, nil, err
 	}
 
+	var policies []types.AuthPolicy
+	// 1. 查询请求相关的策略, 操作被放行时不需要查询
+	if !bypass {
+		policies, err = queryFilterPolicies(r, entry, false, withoutCache)
+		if err != nil {
+			err = errorWrapf(err, "queryFilterPolicies fail", r.Action)
+			return nil, nil, err
+		}
+	}
+
 	extResourcesWithAttr := make([]types.ExtResourceWithAttribute, 0, len(extResources))
 	for _, resource := range extResources {
 		extResourcesWithAttr = append(extResourcesWithAttr, types.ExtResourceWithAttribute{
@@ -256,8 +801,8 @@ func QueryByExtResources(
 		})
 	}
 
-	// 如果策略为空, 直接返回空结果
-	if len(policies) == 0 {
+	// 如果策略为空或操作被放行, 直接返回空属性的结果
+	if len(policies) == 0 || bypass {
 		for i, resource := range extResources {
 			for _, id := range resource.IDs {
 				extResourcesWithAttr[i].Instances = append(extResourcesWithAttr[i].Instances, types.Instance{
@@ -267,18 +812,25 @@ func QueryByExtResources(
 			}
 		}
 
+		if bypass {
+			return AnyExpression, extResourcesWithAttr, nil
+		}
 		return EmptyPolicies, extResourcesWithAttr, nil
 	}
 
-	// 2. 批量查询 ext resource 的属性
-	var remoteResources []map[string]interface{}
-	for i := range extResources {
-		remoteResources, err = queryExtResourceAttrs(&extResources[i], policies)
-		if err != nil {
-			err = errorWrapf(err, "queryExtResourceAttrs resource=`%+v` fail", extResources[i])
-			return nil, nil, err
-		}
+	// 2. 批量查询 ext resource 的属性, 多个ext resource并发查询
+	stopTiming := debug.StartTiming(entry, debug.TimingRemote)
+	remoteResourcesList, degraded, err := batchQueryExtResourceAttrs(r.Context(), extResources, policies)
+	stopTiming()
+	if err != nil {
+		err = errorWrapf(err, "batchQueryExtResourceAttrs extResources=`%+v` fail", extResources)
+		return nil, nil, err
+	}
+	if degraded {
+		debug.WithValue(entry, "remoteResourceDegraded", true)
+	}
 
+	for i, remoteResources := range remoteResourcesList {
 		for _, rr := range remoteResources {
 			extResourcesWithAttr[i].Instances = append(extResourcesWithAttr[i].Instances, types.Instance{
 				ID:        fmt.Sprint(rr["id"]),
@@ -298,9 +850,9 @@ func QueryByExtResources(
 	}
 
 	var expr map[string]interface{}
-	expr, err = translate.PoliciesTranslate(policies, queryResourceTypes)
+	expr, err = TranslatePolicies(r.System, policies, queryResourceTypes)
 	if err != nil {
-		err = errorWrapf(err, "PoliciesTranslate resourceTypes=`%+v` fail", queryResourceTypes)
+		err = errorWrapf(err, "TranslatePolicies resourceTypes=`%+v` fail", queryResourceTypes)
 
 		return nil, nil, err
 	}
@@ -318,6 +870,13 @@ func QueryAuthPolicies(
 	// NOTE: the r.resources is empty here!!!!!!
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "BatchResourcesEval")
 
+	release, err := acquireEval(r.System)
+	if err != nil {
+		err = errorWrapf(err, "acquireEval system=`%s` fail", r.System)
+		return
+	}
+	defer release()
+
 	// init debug entry with values
 	if entry != nil {
 		debug.WithValues(entry, map[string]interface{}{
@@ -331,7 +890,9 @@ func QueryAuthPolicies(
 
 	// 1. PIP查询action
 	debug.AddStep(entry, "Fetch action details")
+	stopTiming := debug.StartTiming(entry, debug.TimingCache)
 	err = fillActionDetail(r)
+	stopTiming()
 	if err != nil {
 		err = errorWrapf(err, "Fetch action detail action=`%+v` fail", r.Action)
 		if errors.Is(err, sql.ErrNoRows) {
@@ -345,7 +906,9 @@ func QueryAuthPolicies(
 
 	// 3. PIP查询subject相关的属性
 	debug.AddStep(entry, "Fetch subject details")
+	stopTiming = debug.StartTiming(entry, debug.TimingCache)
 	err = fillSubjectDetail(r)
+	stopTiming()
 	if err != nil {
 		// 如果用户不存在, 表现为没有权限
 		// if the subject not exists
@@ -353,6 +916,11 @@ func QueryAuthPolicies(
 			err = ErrSubjectNotExists
 			return
 		}
+		// 被冻结的subject, 表现为没有策略
+		if errors.Is(err, ErrSubjectFrozen) {
+			err = ErrNoPolicies
+			return
+		}
 
 		err = errorWrapf(err, "request fillSubjectDetail subject=`%+v`", r.Subject)
 		return
@@ -380,7 +948,7 @@ func QueryAuthPolicies(
 func EvalPolicies(req *request.Request, policies []types.AuthPolicy) (bool, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "EvalPolicies")
 
-	_, err := filterPoliciesByEvalResources(req, policies)
+	filteredPolicies, err := filterPoliciesByEvalResources(req, policies, nil)
 	if err != nil {
 		if errors.Is(err, ErrNoPolicies) {
 			return false, nil
@@ -389,5 +957,19 @@ func EvalPolicies(req *request.Request, policies []types.AuthPolicy) (bool, erro
 		err = errorWrapf(err, "filterPoliciesByEvalResources policies=`%+v` fail", policies)
 		return false, err
 	}
-	return true, nil
+
+	// 根据系统的求值模式判断: 默认命中deny策略没有权限; first_match模式由优先级最高的命中策略决定
+	return evaluation.IsAllowed(req.System, filteredPolicies), nil
+}
+
+// TranslatePolicies 根据系统的求值模式将策略转换为表达式, 保证查询(query)与鉴权(auth)的结果一致
+func TranslatePolicies(
+	system string,
+	policies []types.AuthPolicy,
+	resourceTypes []types.ActionResourceType,
+) (map[string]interface{}, error) {
+	if evaluation.GetMode(system) == evaluation.ModeFirstMatch {
+		return translate.PrioritizedPoliciesTranslate(evaluation.SortPoliciesByPriority(policies), resourceTypes)
+	}
+	return translate.PoliciesTranslate(policies, resourceTypes)
 }
@@ -11,6 +11,8 @@
 package pdp
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"reflect"
 
@@ -197,6 +199,7 @@ var _ = Describe("Entrance", func() {
 			patches.ApplyFunc(filterPoliciesByEvalResources, func(
 				r *request.Request,
 				policies []types.AuthPolicy,
+				entry *debug.Entry,
 			) (filteredPolicies []types.AuthPolicy, err error) {
 				return nil, errors.New("test")
 			})
@@ -228,6 +231,7 @@ var _ = Describe("Entrance", func() {
 			patches.ApplyFunc(filterPoliciesByEvalResources, func(
 				r *request.Request,
 				policies []types.AuthPolicy,
+				entry *debug.Entry,
 			) (filteredPolicies []types.AuthPolicy, err error) {
 				return []types.AuthPolicy{{}}, nil
 			})
@@ -237,6 +241,344 @@ var _ = Describe("Entrance", func() {
 			assert.True(GinkgoT(), ok)
 			assert.NoError(GinkgoT(), err)
 		})
+
+		It("ok, any policy skip filter", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyMethod(reflect.TypeOf(req), "HasSingleLocalResource",
+				func(_ *request.Request) bool {
+					return false
+				})
+			patches.ApplyFunc(queryPolicies, func(system string,
+				subject types.Subject,
+				action types.Action,
+				withoutCache bool,
+				entry *debug.Entry,
+			) (policies []types.AuthPolicy, err error) {
+				return []types.AuthPolicy{{ID: 1}}, nil
+			})
+			patches.ApplyFunc(evaluation.HasAnyPolicy, func(policies []types.AuthPolicy) bool {
+				return true
+			})
+			patches.ApplyFunc(filterPoliciesByEvalResources, func(
+				r *request.Request,
+				policies []types.AuthPolicy,
+				entry *debug.Entry,
+			) (filteredPolicies []types.AuthPolicy, err error) {
+				return nil, errors.New("should not fill resources")
+			})
+			defer patches.Reset()
+
+			ok, err := Eval(req, entry, false)
+			assert.True(GinkgoT(), ok)
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("fail, QueryPolicies filter hit deny", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyMethod(reflect.TypeOf(req), "HasSingleLocalResource",
+				func(_ *request.Request) bool {
+					return false
+				})
+			patches.ApplyFunc(queryPolicies, func(system string,
+				subject types.Subject,
+				action types.Action,
+				withoutCache bool,
+				entry *debug.Entry,
+			) (policies []types.AuthPolicy, err error) {
+				return []types.AuthPolicy{}, nil
+			})
+			patches.ApplyFunc(filterPoliciesByEvalResources, func(
+				r *request.Request,
+				policies []types.AuthPolicy,
+				entry *debug.Entry,
+			) (filteredPolicies []types.AuthPolicy, err error) {
+				return []types.AuthPolicy{{}, {Effect: "deny"}}, nil
+			})
+			defer patches.Reset()
+
+			ok, err := Eval(req, entry, false)
+			assert.False(GinkgoT(), ok)
+			assert.NoError(GinkgoT(), err)
+		})
+	})
+
+	Describe("BatchEvalActions", func() {
+		var entry *debug.Entry
+		var req *request.Request
+		var patches *gomonkey.Patches
+		BeforeEach(func() {
+			req = &request.Request{
+				System: "test",
+				Resources: []types.Resource{{
+					System: "test",
+				}},
+			}
+
+			patches = gomonkey.NewPatches()
+			patches.ApplyMethod(reflect.TypeOf(req), "ValidateActionResource",
+				func(_ *request.Request) bool {
+					return true
+				})
+			patches.ApplyMethod(reflect.TypeOf(req), "HasSingleLocalResource",
+				func(_ *request.Request) bool {
+					return true
+				})
+		})
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("FillAction invalid", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return sql.ErrNoRows
+			})
+
+			results, err := BatchEvalActions(req, []string{"edit", "view"}, entry, false)
+			assert.Nil(GinkgoT(), results)
+			assert.ErrorIs(GinkgoT(), err, ErrInvalidAction)
+		})
+
+		It("FillSubject error", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return errors.New("fill subject fail")
+			})
+
+			results, err := BatchEvalActions(req, []string{"edit", "view"}, entry, false)
+			assert.Nil(GinkgoT(), results)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "fill subject fail")
+		})
+
+		It("subject not exists", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return sql.ErrNoRows
+			})
+
+			results, err := BatchEvalActions(req, []string{"edit", "view"}, entry, false)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]bool{"edit": false, "view": false}, results)
+		})
+
+		It("subject frozen", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return ErrSubjectFrozen
+			})
+
+			results, err := BatchEvalActions(req, []string{"edit", "view"}, entry, false)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]bool{"edit": false, "view": false}, results)
+		})
+
+		It("QueryPolicies error", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(queryPolicies, func(system string,
+				subject types.Subject,
+				action types.Action,
+				withoutCache bool,
+				entry *debug.Entry,
+			) (policies []types.AuthPolicy, err error) {
+				return nil, errors.New("queryPolicies fail")
+			})
+
+			results, err := BatchEvalActions(req, []string{"edit", "view"}, entry, false)
+			assert.Nil(GinkgoT(), results)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "queryPolicies fail")
+		})
+
+		It("ok", func() {
+			fillSubjectCount := 0
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				fillSubjectCount++
+				return nil
+			})
+			patches.ApplyFunc(queryPolicies, func(system string,
+				subject types.Subject,
+				action types.Action,
+				withoutCache bool,
+				entry *debug.Entry,
+			) (policies []types.AuthPolicy, err error) {
+				if action.ID == "view" {
+					return nil, ErrNoPolicies
+				}
+				return []types.AuthPolicy{}, nil
+			})
+			patches.ApplyFunc(evaluation.EvalPolicies, func(
+				ctx *pdptypes.ExprContext, policies []types.AuthPolicy,
+			) (isPass bool, policyID int64, err error) {
+				return true, 1, nil
+			})
+
+			results, err := BatchEvalActions(req, []string{"edit", "view"}, entry, false)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]bool{"edit": true, "view": false}, results)
+			assert.Equal(GinkgoT(), 1, fillSubjectCount)
+		})
+	})
+
+	Describe("BatchEvalResources", func() {
+		var entry *debug.Entry
+		var req *request.Request
+		var resourcesList [][]types.Resource
+		var patches *gomonkey.Patches
+		BeforeEach(func() {
+			req = &request.Request{
+				System: "test",
+			}
+			resourcesList = [][]types.Resource{
+				{{System: "test", Type: "app", ID: "1"}},
+				{{System: "test", Type: "app", ID: "2"}},
+			}
+
+			patches = gomonkey.NewPatches()
+			patches.ApplyMethod(reflect.TypeOf(req), "ValidateActionResource",
+				func(_ *request.Request) bool {
+					return true
+				})
+			patches.ApplyMethod(reflect.TypeOf(req), "HasSingleLocalResource",
+				func(_ *request.Request) bool {
+					return true
+				})
+		})
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("FillAction invalid", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return sql.ErrNoRows
+			})
+
+			results, err := BatchEvalResources(req, resourcesList, entry, false)
+			assert.Nil(GinkgoT(), results)
+			assert.ErrorIs(GinkgoT(), err, ErrInvalidAction)
+		})
+
+		It("ValidateActionResource fail", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyMethod(reflect.TypeOf(req), "ValidateActionResource",
+				func(_ *request.Request) bool {
+					return false
+				})
+
+			results, err := BatchEvalResources(req, resourcesList, entry, false)
+			assert.Nil(GinkgoT(), results)
+			assert.ErrorIs(GinkgoT(), err, ErrInvalidActionResource)
+		})
+
+		It("subject not exists", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return sql.ErrNoRows
+			})
+
+			results, err := BatchEvalResources(req, resourcesList, entry, false)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []bool{false, false}, results)
+		})
+
+		It("no policies", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(queryPolicies, func(system string,
+				subject types.Subject,
+				action types.Action,
+				withoutCache bool,
+				entry *debug.Entry,
+			) (policies []types.AuthPolicy, err error) {
+				return nil, ErrNoPolicies
+			})
+
+			results, err := BatchEvalResources(req, resourcesList, entry, false)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []bool{false, false}, results)
+		})
+
+		It("QueryPolicies error", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(queryPolicies, func(system string,
+				subject types.Subject,
+				action types.Action,
+				withoutCache bool,
+				entry *debug.Entry,
+			) (policies []types.AuthPolicy, err error) {
+				return nil, errors.New("queryPolicies fail")
+			})
+
+			results, err := BatchEvalResources(req, resourcesList, entry, false)
+			assert.Nil(GinkgoT(), results)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "queryPolicies fail")
+		})
+
+		It("ok", func() {
+			queryCount := 0
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(queryPolicies, func(system string,
+				subject types.Subject,
+				action types.Action,
+				withoutCache bool,
+				entry *debug.Entry,
+			) (policies []types.AuthPolicy, err error) {
+				queryCount++
+				return []types.AuthPolicy{{ID: 1}}, nil
+			})
+			patches.ApplyFunc(evaluation.EvalPolicies, func(
+				ctx *pdptypes.ExprContext, policies []types.AuthPolicy,
+			) (isPass bool, policyID int64, err error) {
+				return ctx.Resource.ID == "1", 1, nil
+			})
+
+			results, err := BatchEvalResources(req, resourcesList, entry, false)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []bool{true, false}, results)
+			assert.Equal(GinkgoT(), 1, queryCount)
+		})
 	})
 
 	Describe("Query", func() {
@@ -359,6 +701,136 @@ var _ = Describe("Entrance", func() {
 
 	})
 
+	Describe("BatchQueryByActions", func() {
+		var entry *debug.Entry
+		var req *request.Request
+		var patches *gomonkey.Patches
+		BeforeEach(func() {
+			entry = debug.EntryPool.Get()
+			req = &request.Request{
+				System: "test",
+				Resources: []types.Resource{{
+					System: "test",
+				}},
+			}
+
+			patches = gomonkey.NewPatches()
+			patches.ApplyMethod(reflect.TypeOf(req), "ValidateActionRemoteResource",
+				func(_ *request.Request) bool {
+					return true
+				})
+		})
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("FillAction invalid", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return sql.ErrNoRows
+			})
+
+			exprs, err := BatchQueryByActions(req, []string{"edit", "view"}, entry, true, false)
+			assert.Nil(GinkgoT(), exprs)
+			assert.ErrorIs(GinkgoT(), err, ErrInvalidAction)
+		})
+
+		It("FillSubject error", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return errors.New("fill subject fail")
+			})
+
+			exprs, err := BatchQueryByActions(req, []string{"edit", "view"}, entry, true, false)
+			assert.Nil(GinkgoT(), exprs)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "fill subject fail")
+		})
+
+		It("subject not exists", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return sql.ErrNoRows
+			})
+
+			exprs, err := BatchQueryByActions(req, []string{"edit", "view"}, entry, true, false)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]map[string]interface{}{
+				"edit": EmptyPolicies,
+				"view": EmptyPolicies,
+			}, exprs)
+		})
+
+		It("QueryPolicies error", func() {
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(queryPolicies, func(system string,
+				subject types.Subject,
+				action types.Action,
+				withoutCache bool,
+				entry *debug.Entry,
+			) (policies []types.AuthPolicy, err error) {
+				return nil, errors.New("queryPolicies fail")
+			})
+
+			exprs, err := BatchQueryByActions(req, []string{"edit", "view"}, entry, true, false)
+			assert.Nil(GinkgoT(), exprs)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "queryPolicies fail")
+		})
+
+		It("ok", func() {
+			fillSubjectCount := 0
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				fillSubjectCount++
+				return nil
+			})
+			patches.ApplyFunc(queryPolicies, func(system string,
+				subject types.Subject,
+				action types.Action,
+				withoutCache bool,
+				entry *debug.Entry,
+			) (policies []types.AuthPolicy, err error) {
+				if action.ID == "view" {
+					return nil, ErrNoPolicies
+				}
+				return []types.AuthPolicy{{ID: 1}}, nil
+			})
+			patches.ApplyFunc(filterPoliciesByEvalResources, func(
+				r *request.Request, policies []types.AuthPolicy, entry *debug.Entry,
+			) ([]types.AuthPolicy, error) {
+				return policies, nil
+			})
+			patches.ApplyMethod(reflect.TypeOf(req), "GetQueryResourceTypes",
+				func(_ *request.Request) ([]types.ActionResourceType, error) {
+					return []types.ActionResourceType{}, nil
+				})
+			patches.ApplyFunc(translate.PoliciesTranslate, func(policies []types.AuthPolicy,
+				resourceTypes []types.ActionResourceType,
+			) (map[string]interface{}, error) {
+				return map[string]interface{}{"op": "eq"}, nil
+			})
+
+			exprs, err := BatchQueryByActions(req, []string{"edit", "view"}, entry, true, false)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]map[string]interface{}{
+				"edit": {"op": "eq"},
+				"view": EmptyPolicies,
+			}, exprs)
+			assert.Equal(GinkgoT(), 1, fillSubjectCount)
+		})
+	})
+
 	Describe("QueryByExtResources", func() {
 		var entry *debug.Entry
 		var req *request.Request
@@ -421,6 +893,7 @@ var _ = Describe("Entrance", func() {
 				return []types.AuthPolicy{{}}, nil
 			})
 			patches.ApplyFunc(queryExtResourceAttrs, func(
+				ctx context.Context,
 				resource *types.ExtResource,
 				policies []types.AuthPolicy,
 			) (resources []map[string]interface{}, err error) {
@@ -18,17 +18,56 @@ import (
 	"iam/pkg/abac/pdp/condition"
 	pdptypes "iam/pkg/abac/pdp/types"
 	"iam/pkg/abac/types"
+	"iam/pkg/cache/impls"
 )
 
 /*
 求值逻辑, 包括:
 
-对Policy的condition求值
+对Policy的condition求值, 根据系统配置的求值模式:
+deny优先(默认): 命中任意一条deny策略, 无论是否命中allow策略, 都没有权限
+first_match: 按优先级从高到低求值, 第一条命中的策略决定是否有权限
+
+deny策略求值失败时视为命中(没有权限), 避免求值失败时放行
+
+没有deny策略时, 有表达式为any的allow策略即有权限, 不需要对资源求值
 */
 
-// EvalPolicies 计算是否满足
+// EvalPolicies 计算是否满足; 命中deny策略时, 返回false及该deny策略的ID
 func EvalPolicies(ctx *pdptypes.ExprContext, policies []types.AuthPolicy) (isPass bool, policyID int64, err error) {
+	// 有any策略时, 不需要对资源求值
+	if policy, ok := findAnyPolicy(policies); ok {
+		log.Debugf("pdp evalPolicies any policy: %+v pass", policy)
+		return true, policy.ID, nil
+	}
+
+	if GetMode(ctx.System) == ModeFirstMatch {
+		return evalPoliciesFirstMatch(ctx, policies)
+	}
+
+	for _, policy := range policies {
+		if !policy.IsDeny() {
+			continue
+		}
+
+		var isDeny bool
+		isDeny, err = EvalPolicy(ctx, policy)
+		if err != nil {
+			log.Debugf("pdp evalPolicies EvalPolicy deny policy: %+v ctx: %+v error: %s", policy, ctx, err)
+			return false, policy.ID, err
+		}
+
+		if isDeny {
+			log.Debugf("pdp evalPolicies EvalPolicy deny policy: %+v ctx: %+v hit", policy, ctx)
+			return false, policy.ID, nil
+		}
+	}
+
 	for _, policy := range policies {
+		if policy.IsDeny() {
+			continue
+		}
+
 		isPass, err = EvalPolicy(ctx, policy)
 		if err != nil {
 			log.Debugf("pdp evalPolicies EvalPolicy policy: %+v ctx: %+v error: %s", policy, ctx, err)
@@ -42,7 +81,29 @@ func EvalPolicies(ctx *pdptypes.ExprContext, policies []types.AuthPolicy) (isPas
 	return isPass, -1, err
 }
 
-// FilterPolicies 筛选check pass的policies
+// evalPoliciesFirstMatch 按优先级从高到低求值, 第一条命中的策略决定结果
+func evalPoliciesFirstMatch(
+	ctx *pdptypes.ExprContext, policies []types.AuthPolicy,
+) (isPass bool, policyID int64, err error) {
+	for _, policy := range SortPoliciesByPriority(policies) {
+		var isHit bool
+		isHit, err = EvalPolicy(ctx, policy)
+		if err != nil {
+			log.Debugf("pdp evalPoliciesFirstMatch EvalPolicy policy: %+v ctx: %+v error: %s", policy, ctx, err)
+			if policy.IsDeny() {
+				return false, policy.ID, err
+			}
+		}
+
+		if isHit {
+			log.Debugf("pdp evalPoliciesFirstMatch EvalPolicy policy: %+v ctx: %+v hit", policy, ctx)
+			return !policy.IsDeny(), policy.ID, nil
+		}
+	}
+	return false, -1, err
+}
+
+// FilterPolicies 筛选check pass的policies, 包括deny策略, 由调用方判断是否命中deny; 求值失败的deny策略视为命中
 func FilterPolicies(ctx *pdptypes.ExprContext, policies []types.AuthPolicy) ([]types.AuthPolicy, error) {
 	passPolicies := make([]types.AuthPolicy, 0, len(policies))
 	var (
@@ -53,6 +114,7 @@ func FilterPolicies(ctx *pdptypes.ExprContext, policies []types.AuthPolicy) ([]t
 		isPass, err = EvalPolicy(ctx, policy)
 		if err != nil {
 			log.Debugf("pdp filterPolicies EvalPolicy policy: %+v ctx: %+v error: %s", policy, ctx, err)
+			isPass = policy.IsDeny()
 		}
 
 		if isPass {
@@ -63,6 +125,69 @@ func FilterPolicies(ctx *pdptypes.ExprContext, policies []types.AuthPolicy) ([]t
 	return passPolicies, err
 }
 
+// ContainsDenyPolicy 策略列表中是否有deny策略
+func ContainsDenyPolicy(policies []types.AuthPolicy) bool {
+	for _, policy := range policies {
+		if policy.IsDeny() {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsAllowPolicy 策略列表中是否有allow策略
+func ContainsAllowPolicy(policies []types.AuthPolicy) bool {
+	for _, policy := range policies {
+		if !policy.IsDeny() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAnyPolicy 没有deny策略, 且有表达式为any的allow策略, 此时不需要资源的信息即可确定有权限
+func HasAnyPolicy(policies []types.AuthPolicy) bool {
+	_, ok := findAnyPolicy(policies)
+	return ok
+}
+
+func findAnyPolicy(policies []types.AuthPolicy) (types.AuthPolicy, bool) {
+	// deny策略需要对资源求值, 无论哪种求值模式都不能跳过
+	if ContainsDenyPolicy(policies) {
+		return types.AuthPolicy{}, false
+	}
+
+	for _, policy := range policies {
+		if isAnyPolicy(policy) {
+			return policy, true
+		}
+	}
+	return types.AuthPolicy{}, false
+}
+
+// isAnyPolicy 策略中所有资源类型的表达式都是Any
+// NOTE: 表达式为空时(操作不关联资源类型)求值本身不依赖资源, 不作为any处理
+func isAnyPolicy(policy types.AuthPolicy) bool {
+	if policy.Expression == "" {
+		return false
+	}
+
+	expressions, err := impls.GetUnmarshalledResourceExpression(policy.Expression, policy.ExpressionSignature)
+	if err != nil || len(expressions) == 0 {
+		return false
+	}
+
+	for _, expression := range expressions {
+		if len(expression.Expression) != 1 {
+			return false
+		}
+		if _, ok := expression.Expression["Any"]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 // EvalPolicy 计算单个policy是否满足
 func EvalPolicy(ctx *pdptypes.ExprContext, policy types.AuthPolicy) (bool, error) {
 	// action 不关联资源类型时, 直接返回true
@@ -76,9 +201,7 @@ func EvalPolicy(ctx *pdptypes.ExprContext, policy types.AuthPolicy) (bool, error
 		return false, fmt.Errorf("evalPolicy action: %s get resource nil", ctx.Action.ID)
 	}
 
-	cond, err := condition.ParseResourceConditionFromExpression(ctx.Resource,
-		policy.Expression,
-		policy.ExpressionSignature)
+	cond, err := condition.ParseResourceConditionFromPolicy(ctx.Resource, policy)
 	if err != nil {
 		log.Debugf("pdp EvalPolicy policy id: %d expression: %s format error: %v",
 			policy.ID, policy.Expression, err)
@@ -88,3 +211,22 @@ func EvalPolicy(ctx *pdptypes.ExprContext, policy types.AuthPolicy) (bool, error
 	isPass := cond.Eval(ctx)
 	return isPass, err
 }
+
+// ExplainPolicy 计算单个policy是否满足, 并返回条件的求值过程; action不关联资源类型时, 求值过程为nil
+func ExplainPolicy(ctx *pdptypes.ExprContext, policy types.AuthPolicy) (bool, *condition.Explanation, error) {
+	if ctx.Action.WithoutResourceType() {
+		return true, nil, nil
+	}
+
+	if ctx.Resource == nil {
+		return false, nil, fmt.Errorf("explainPolicy action: %s get resource nil", ctx.Action.ID)
+	}
+
+	cond, err := condition.ParseResourceConditionFromPolicy(ctx.Resource, policy)
+	if err != nil {
+		return false, nil, err
+	}
+
+	explanation := condition.Explain(cond, ctx)
+	return explanation.Result, &explanation, nil
+}
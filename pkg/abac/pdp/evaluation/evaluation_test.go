@@ -77,6 +77,21 @@ var _ = Describe("Evaluation", func() {
 		ExpressionSignature: "7dc6d19025f790d4509e6b732ed624a9",
 		ExpiredAt:           0,
 	}
+	anyPolicy := types.AuthPolicy{
+		ID: 3,
+		Expression: `[
+						{
+							"system": "iam",
+							"type": "job",
+							"expression": {
+								"Any": {
+									"id": []
+								}
+							}
+						}
+					]`,
+		ExpressionSignature: "cbd6a7ab5a4a1f1e1b6f9b1ef0a9fe21",
+	}
 
 	BeforeEach(func() {
 		request := &request.Request{
@@ -172,6 +187,198 @@ var _ = Describe("Evaluation", func() {
 			assert.False(GinkgoT(), allowed)
 		})
 
+		It("fail, deny policy pass", func() {
+			denyPolicy := willPassPolicy
+			denyPolicy.ID = 2
+			denyPolicy.Effect = "deny"
+			policies := []types.AuthPolicy{
+				willPassPolicy,
+				denyPolicy,
+			}
+
+			allowed, id, err := evaluation.EvalPolicies(c, policies)
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), allowed)
+			assert.Equal(GinkgoT(), int64(2), id)
+		})
+
+		It("ok, deny policy not pass", func() {
+			denyPolicy := willNotPassPolicy
+			denyPolicy.Effect = "deny"
+			policies := []types.AuthPolicy{
+				denyPolicy,
+				willPassPolicy,
+			}
+
+			allowed, _, err := evaluation.EvalPolicies(c, policies)
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), allowed)
+		})
+
+		It("fail, deny policy EvalPolicy err", func() {
+			denyPolicy := types.AuthPolicy{ID: 2, Effect: "deny", Expression: "123"}
+			policies := []types.AuthPolicy{
+				willPassPolicy,
+				denyPolicy,
+			}
+
+			allowed, id, err := evaluation.EvalPolicies(c, policies)
+			assert.Error(GinkgoT(), err)
+			assert.False(GinkgoT(), allowed)
+			assert.Equal(GinkgoT(), int64(2), id)
+		})
+
+		It("fail, only deny policy", func() {
+			denyPolicy := willPassPolicy
+			denyPolicy.Effect = "deny"
+
+			allowed, _, err := evaluation.EvalPolicies(c, []types.AuthPolicy{denyPolicy})
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), allowed)
+		})
+	})
+
+	Describe("EvalPolicies any policy", func() {
+		It("ok, skip resource eval", func() {
+			c.Resource = nil
+
+			allowed, id, err := evaluation.EvalPolicies(c, []types.AuthPolicy{willNotPassPolicy, anyPolicy})
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), allowed)
+			assert.Equal(GinkgoT(), int64(3), id)
+		})
+
+		It("fail, deny policy pass", func() {
+			denyPolicy := willPassPolicy
+			denyPolicy.ID = 2
+			denyPolicy.Effect = "deny"
+
+			allowed, id, err := evaluation.EvalPolicies(c, []types.AuthPolicy{anyPolicy, denyPolicy})
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), allowed)
+			assert.Equal(GinkgoT(), int64(2), id)
+		})
+	})
+
+	Describe("HasAnyPolicy", func() {
+		It("empty", func() {
+			assert.False(GinkgoT(), evaluation.HasAnyPolicy([]types.AuthPolicy{}))
+		})
+
+		It("no any policy", func() {
+			assert.False(GinkgoT(), evaluation.HasAnyPolicy([]types.AuthPolicy{willPassPolicy, policy}))
+		})
+
+		It("invalid expression", func() {
+			assert.False(GinkgoT(), evaluation.HasAnyPolicy([]types.AuthPolicy{{Expression: "123"}}))
+		})
+
+		It("any policy", func() {
+			assert.True(GinkgoT(), evaluation.HasAnyPolicy([]types.AuthPolicy{willPassPolicy, anyPolicy}))
+		})
+
+		It("any policy with deny policy", func() {
+			denyPolicy := willNotPassPolicy
+			denyPolicy.Effect = "deny"
+			assert.False(GinkgoT(), evaluation.HasAnyPolicy([]types.AuthPolicy{anyPolicy, denyPolicy}))
+		})
+
+		It("not all resource types any", func() {
+			p := types.AuthPolicy{
+				Expression: `[{"system": "iam", "type": "job", "expression": {"Any": {"id": []}}},
+					{"system": "iam", "type": "host", "expression": {"StringEquals": {"id": ["1"]}}}]`,
+			}
+			assert.False(GinkgoT(), evaluation.HasAnyPolicy([]types.AuthPolicy{p}))
+		})
+	})
+
+	Describe("EvalPolicies first_match", func() {
+		BeforeEach(func() {
+			evaluation.InitModes("", map[string]string{"iam": evaluation.ModeFirstMatch})
+		})
+
+		AfterEach(func() {
+			evaluation.InitModes("", nil)
+		})
+
+		It("ok, higher priority allow overrides deny", func() {
+			allowPolicy := willPassPolicy
+			allowPolicy.ID = 1
+			allowPolicy.Priority = 10
+			denyPolicy := willPassPolicy
+			denyPolicy.ID = 2
+			denyPolicy.Effect = "deny"
+
+			allowed, id, err := evaluation.EvalPolicies(c, []types.AuthPolicy{denyPolicy, allowPolicy})
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), allowed)
+			assert.Equal(GinkgoT(), int64(1), id)
+		})
+
+		It("fail, higher priority deny", func() {
+			allowPolicy := willPassPolicy
+			allowPolicy.ID = 1
+			denyPolicy := willPassPolicy
+			denyPolicy.ID = 2
+			denyPolicy.Effect = "deny"
+			denyPolicy.Priority = 10
+
+			allowed, id, err := evaluation.EvalPolicies(c, []types.AuthPolicy{allowPolicy, denyPolicy})
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), allowed)
+			assert.Equal(GinkgoT(), int64(2), id)
+		})
+
+		It("ok, skip not matched higher priority deny", func() {
+			allowPolicy := willPassPolicy
+			allowPolicy.ID = 1
+			denyPolicy := willNotPassPolicy
+			denyPolicy.ID = 2
+			denyPolicy.Effect = "deny"
+			denyPolicy.Priority = 10
+
+			allowed, id, err := evaluation.EvalPolicies(c, []types.AuthPolicy{denyPolicy, allowPolicy})
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), allowed)
+			assert.Equal(GinkgoT(), int64(1), id)
+		})
+
+		It("fail, higher priority deny EvalPolicy err", func() {
+			allowPolicy := willPassPolicy
+			allowPolicy.ID = 1
+			denyPolicy := types.AuthPolicy{ID: 2, Effect: "deny", Expression: "123", Priority: 10}
+
+			allowed, id, err := evaluation.EvalPolicies(c, []types.AuthPolicy{allowPolicy, denyPolicy})
+			assert.Error(GinkgoT(), err)
+			assert.False(GinkgoT(), allowed)
+			assert.Equal(GinkgoT(), int64(2), id)
+		})
+
+		It("fail, no policy matched", func() {
+			allowed, id, err := evaluation.EvalPolicies(c, []types.AuthPolicy{willNotPassPolicy})
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), allowed)
+			assert.Equal(GinkgoT(), int64(-1), id)
+		})
+	})
+
+	Describe("ContainsDenyPolicy/ContainsAllowPolicy", func() {
+		It("empty", func() {
+			assert.False(GinkgoT(), evaluation.ContainsDenyPolicy([]types.AuthPolicy{}))
+			assert.False(GinkgoT(), evaluation.ContainsAllowPolicy([]types.AuthPolicy{}))
+		})
+
+		It("allow only, empty effect is allow", func() {
+			policies := []types.AuthPolicy{{Effect: ""}, {Effect: "allow"}}
+			assert.False(GinkgoT(), evaluation.ContainsDenyPolicy(policies))
+			assert.True(GinkgoT(), evaluation.ContainsAllowPolicy(policies))
+		})
+
+		It("deny only", func() {
+			policies := []types.AuthPolicy{{Effect: "deny"}}
+			assert.True(GinkgoT(), evaluation.ContainsDenyPolicy(policies))
+			assert.False(GinkgoT(), evaluation.ContainsAllowPolicy(policies))
+		})
 	})
 
 	Describe("FilterPolicies", func() {
@@ -215,6 +422,19 @@ var _ = Describe("Evaluation", func() {
 			assert.Error(GinkgoT(), err)
 			assert.Empty(GinkgoT(), ps)
 		})
+
+		It("deny policy EvalPolicy err, treat as hit", func() {
+			denyPolicy := types.AuthPolicy{ID: 2, Effect: "deny", Expression: "123"}
+			policies := []types.AuthPolicy{
+				denyPolicy,
+				willPassPolicy,
+			}
+
+			ps, err := evaluation.FilterPolicies(c, policies)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.AuthPolicy{denyPolicy, willPassPolicy}, ps)
+			assert.False(GinkgoT(), evaluation.IsAllowed("iam", ps))
+		})
 	})
 
 	Describe("EvalPolicy", func() {
@@ -308,4 +528,31 @@ var _ = Describe("Evaluation", func() {
 		})
 
 	})
+
+	Describe("ExplainPolicy", func() {
+		It("ctx.Action.WithoutResourceType", func() {
+			c.Action.FillAttributes(1, []types.ActionResourceType{})
+			matched, explanation, err := evaluation.ExplainPolicy(c, policy)
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), matched)
+			assert.Nil(GinkgoT(), explanation)
+		})
+
+		It("ctx.Resource == nil", func() {
+			c.Resource = nil
+			_, _, err := evaluation.ExplainPolicy(c, policy)
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("ok, not matched", func() {
+			matched, explanation, err := evaluation.ExplainPolicy(c, willNotPassPolicy)
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), matched)
+			assert.Equal(GinkgoT(), "AND", explanation.Operator)
+			assert.Equal(GinkgoT(), "system", explanation.Content[0].Field)
+			assert.Equal(GinkgoT(), "linux", explanation.Content[0].Attribute)
+			assert.False(GinkgoT(), explanation.Content[0].Result)
+			assert.True(GinkgoT(), explanation.Content[1].Result)
+		})
+	})
 })
@@ -0,0 +1,81 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package evaluation
+
+import (
+	"sort"
+
+	"iam/pkg/abac/types"
+)
+
+// 策略的求值模式
+const (
+	// ModeDenyOverrides 默认模式, 命中任意一条deny策略即没有权限, 否则命中任意一条allow策略即有权限
+	ModeDenyOverrides = "deny_overrides"
+	// ModeFirstMatch 按优先级从高到低求值, 第一条命中的策略决定结果, 用于从依赖规则顺序的其他引擎迁移的系统
+	// NOTE: 鉴权(auth)与策略查询(query)的表达式翻译都按该模式处理, 见 pdp.TranslatePolicies
+	ModeFirstMatch = "first_match"
+)
+
+// NOTE: 初始化后只读, 不需要加锁
+var (
+	defaultMode = ModeDenyOverrides
+	systemModes = map[string]string{}
+)
+
+// InitModes 初始化每个系统的求值模式, 未配置或配置非法的系统使用默认模式
+func InitModes(mode string, modes map[string]string) {
+	defaultMode = ModeDenyOverrides
+	if mode == ModeFirstMatch {
+		defaultMode = ModeFirstMatch
+	}
+
+	systemModes = make(map[string]string, len(modes))
+	for systemID, m := range modes {
+		if m == ModeDenyOverrides || m == ModeFirstMatch {
+			systemModes[systemID] = m
+		}
+	}
+}
+
+// GetMode 获取系统的求值模式
+func GetMode(system string) string {
+	if mode, ok := systemModes[system]; ok {
+		return mode
+	}
+	return defaultMode
+}
+
+// SortPoliciesByPriority 按优先级从高到低排序, 同优先级时deny策略在前, 其余保持原有顺序
+// NOTE: 返回新的slice, 不修改传入的policies(可能来自缓存)
+func SortPoliciesByPriority(policies []types.AuthPolicy) []types.AuthPolicy {
+	sorted := make([]types.AuthPolicy, len(policies))
+	copy(sorted, policies)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority > sorted[j].Priority
+		}
+		return sorted[i].IsDeny() && !sorted[j].IsDeny()
+	})
+	return sorted
+}
+
+// IsAllowed 根据系统的求值模式, 判断命中(满足所有请求资源)的策略是否有权限
+func IsAllowed(system string, matchedPolicies []types.AuthPolicy) bool {
+	if GetMode(system) == ModeFirstMatch {
+		if len(matchedPolicies) == 0 {
+			return false
+		}
+		return !SortPoliciesByPriority(matchedPolicies)[0].IsDeny()
+	}
+
+	return !ContainsDenyPolicy(matchedPolicies) && ContainsAllowPolicy(matchedPolicies)
+}
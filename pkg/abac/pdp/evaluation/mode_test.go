@@ -0,0 +1,88 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package evaluation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/pdp/evaluation"
+	"iam/pkg/abac/types"
+)
+
+var _ = Describe("Mode", func() {
+
+	AfterEach(func() {
+		evaluation.InitModes("", nil)
+	})
+
+	Describe("GetMode", func() {
+		It("default", func() {
+			evaluation.InitModes("", nil)
+			assert.Equal(GinkgoT(), evaluation.ModeDenyOverrides, evaluation.GetMode("iam"))
+		})
+
+		It("invalid default", func() {
+			evaluation.InitModes("abc", nil)
+			assert.Equal(GinkgoT(), evaluation.ModeDenyOverrides, evaluation.GetMode("iam"))
+		})
+
+		It("system mode", func() {
+			evaluation.InitModes(evaluation.ModeFirstMatch, map[string]string{
+				"iam":     evaluation.ModeDenyOverrides,
+				"bk_test": "abc",
+			})
+			assert.Equal(GinkgoT(), evaluation.ModeDenyOverrides, evaluation.GetMode("iam"))
+			assert.Equal(GinkgoT(), evaluation.ModeFirstMatch, evaluation.GetMode("bk_test"))
+			assert.Equal(GinkgoT(), evaluation.ModeFirstMatch, evaluation.GetMode("bk_cmdb"))
+		})
+	})
+
+	Describe("SortPoliciesByPriority", func() {
+		It("ok", func() {
+			policies := []types.AuthPolicy{
+				{ID: 1},
+				{ID: 2, Priority: 10},
+				{ID: 3, Effect: "deny"},
+				{ID: 4},
+			}
+
+			sorted := evaluation.SortPoliciesByPriority(policies)
+			ids := make([]int64, 0, len(sorted))
+			for _, p := range sorted {
+				ids = append(ids, p.ID)
+			}
+			assert.Equal(GinkgoT(), []int64{2, 3, 1, 4}, ids)
+			// not modify the origin policies
+			assert.Equal(GinkgoT(), int64(1), policies[0].ID)
+		})
+	})
+
+	Describe("IsAllowed", func() {
+		policies := []types.AuthPolicy{
+			{ID: 1, Priority: 10},
+			{ID: 2, Effect: "deny"},
+		}
+
+		It("deny_overrides", func() {
+			assert.False(GinkgoT(), evaluation.IsAllowed("iam", policies))
+			assert.True(GinkgoT(), evaluation.IsAllowed("iam", policies[:1]))
+			assert.False(GinkgoT(), evaluation.IsAllowed("iam", []types.AuthPolicy{}))
+		})
+
+		It("first_match", func() {
+			evaluation.InitModes("", map[string]string{"iam": evaluation.ModeFirstMatch})
+
+			assert.True(GinkgoT(), evaluation.IsAllowed("iam", policies))
+			assert.False(GinkgoT(), evaluation.IsAllowed("iam", policies[1:]))
+			assert.False(GinkgoT(), evaluation.IsAllowed("iam", []types.AuthPolicy{}))
+		})
+	})
+})
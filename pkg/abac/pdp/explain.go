@@ -0,0 +1,237 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"database/sql"
+	"errors"
+
+	"iam/pkg/abac/pdp/condition"
+	"iam/pkg/abac/pdp/evaluation"
+	pdptypes "iam/pkg/abac/pdp/types"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/errorx"
+	"iam/pkg/logging/debug"
+)
+
+// 鉴权结果的原因
+const (
+	ExplainReasonSuperPermission    = "super_permission"
+	ExplainReasonSubjectNotExists   = "subject_not_exists"
+	ExplainReasonSubjectFrozen      = "subject_frozen"
+	ExplainReasonNoPolicies         = "no_policies"
+	ExplainReasonNoPolicyMatched    = "no_policy_matched"
+	ExplainReasonAllowPolicyMatched = "allow_policy_matched"
+	ExplainReasonDenyPolicyMatched  = "deny_policy_matched"
+)
+
+// Explanation 鉴权结果的解释: 查询到哪些策略, 每个策略的条件在每个资源上的求值过程, 以及最终由哪条策略决定
+type Explanation struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+	// Mode 系统的求值模式
+	Mode string `json:"mode"`
+	// DecisivePolicyID 决定鉴权结果的策略, 没有时为0
+	DecisivePolicyID int64               `json:"decisive_policy_id"`
+	Policies         []PolicyExplanation `json:"policies"`
+}
+
+// PolicyExplanation 单条策略的求值过程, Matched表示策略满足请求中的所有资源
+type PolicyExplanation struct {
+	ID        int64                 `json:"id"`
+	Effect    string                `json:"effect"`
+	Priority  int64                 `json:"priority"`
+	ExpiredAt int64                 `json:"expired_at"`
+	Matched   bool                  `json:"matched"`
+	Resources []ResourceExplanation `json:"resources"`
+}
+
+// ResourceExplanation 策略的条件在单个资源上的求值过程
+type ResourceExplanation struct {
+	System    string                 `json:"system"`
+	Type      string                 `json:"type"`
+	ID        string                 `json:"id"`
+	Matched   bool                   `json:"matched"`
+	Condition *condition.Explanation `json:"condition,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// Explain 鉴权并解释结果, 流程与Eval一致, 但不会短路, 所有策略在所有资源上都会求值
+func Explain(
+	r *request.Request,
+	entry *debug.Entry,
+	withoutCache bool,
+) (explanation Explanation, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "Explain")
+
+	release, err := acquireEval(r.System)
+	if err != nil {
+		err = errorWrapf(err, "acquireEval system=`%s` fail", r.System)
+		return
+	}
+	defer release()
+
+	debug.WithValues(entry, map[string]interface{}{
+		"system":       r.System,
+		"subject":      r.Subject,
+		"action":       r.Action,
+		"resources":    r.Resources,
+		"cacheEnabled": !withoutCache,
+	})
+
+	explanation.Mode = evaluation.GetMode(r.System)
+	explanation.Policies = []PolicyExplanation{}
+
+	// 1. PIP查询action, 并检查请求资源与action关联的类型是否匹配
+	err = fillAndValidateAction(r, entry)
+	if err != nil {
+		if errors.Is(err, ErrInvalidAction) {
+			return
+		}
+
+		err = errorWrapf(err, "fillAndValidateAction action=`%+v` fail", r.Action)
+		return
+	}
+
+	// 2. PIP查询subject相关的属性
+	debug.AddStep(entry, "Fetch subject details")
+	stopTiming := debug.StartTiming(entry, debug.TimingCache)
+	err = fillSubjectDetail(r)
+	stopTiming()
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			explanation.Reason = ExplainReasonSubjectNotExists
+			return explanation, nil
+		}
+		if errors.Is(err, ErrSubjectFrozen) {
+			explanation.Reason = ExplainReasonSubjectFrozen
+			return explanation, nil
+		}
+
+		err = errorWrapf(err, "request fillSubjectDetail subject=`%+v`", r.Subject)
+		return
+	}
+	debug.WithValue(entry, "subject", r.Subject)
+
+	// 3. PRP查询subject-action相关的policies
+	debug.AddStep(entry, "Query Policies")
+	policies, err := queryPolicies(r.System, r.Subject, r.Action, withoutCache, entry)
+	if err != nil {
+		if errors.Is(err, ErrNoPolicies) {
+			explanation.Reason = ExplainReasonNoPolicies
+			return explanation, nil
+		}
+
+		err = errorWrapf(err, "queryPolicies system=`%s`, subject=`%+v`, action=`%+v`, withoutCache=`%t` fail",
+			r.System, r.Subject, r.Action, withoutCache)
+		return
+	}
+	debug.WithValue(entry, "policies", policies)
+
+	resources := r.GetSortedResources()
+	if r.HasRemoteResources() {
+		debug.AddStep(entry, "Fetch remote resource attributes")
+		stopTiming = debug.StartTiming(entry, debug.TimingRemote)
+		err = fillRemoteResourceAttrs(r, policies)
+		stopTiming()
+		if err != nil {
+			if !errors.Is(err, ErrRemoteResourceDegraded) {
+				err = errorWrapf(err, "fillRemoteResourceAttrs fail")
+				return
+			}
+
+			// 第三方资源降级, 与鉴权一致, 只在本地资源上求值
+			debug.WithValue(entry, "remoteResourceDegraded", true)
+			resources = getLocalResources(r)
+			err = nil
+		}
+	}
+
+	// 4. 每条策略在每个资源上求值
+	debug.AddStep(entry, "Explain policies")
+	matchedPolicies := make([]types.AuthPolicy, 0, len(policies))
+	for _, policy := range policies {
+		pe := explainPolicy(r, resources, policy)
+		if pe.Matched {
+			matchedPolicies = append(matchedPolicies, policy)
+			debug.WithPassEvalPolicy(entry, policy.ID)
+		} else {
+			debug.WithNoPassEvalPolicy(entry, policy.ID)
+		}
+		explanation.Policies = append(explanation.Policies, pe)
+	}
+
+	// 5. 根据求值模式决定结果
+	explanation.Allowed = evaluation.IsAllowed(r.System, matchedPolicies)
+	explanation.Reason, explanation.DecisivePolicyID = explainDecision(explanation.Mode, matchedPolicies)
+	return explanation, nil
+}
+
+func explainPolicy(r *request.Request, resources []*types.Resource, policy types.AuthPolicy) PolicyExplanation {
+	pe := PolicyExplanation{
+		ID:        policy.ID,
+		Effect:    policy.Effect,
+		Priority:  policy.Priority,
+		ExpiredAt: policy.ExpiredAt,
+		Matched:   true,
+		Resources: make([]ResourceExplanation, 0, len(resources)),
+	}
+
+	// action不关联资源类型, 有策略即满足
+	if r.Action.WithoutResourceType() {
+		return pe
+	}
+
+	for _, resource := range resources {
+		re := ResourceExplanation{
+			System: resource.System,
+			Type:   resource.Type,
+			ID:     resource.ID,
+		}
+
+		matched, ce, err := evaluation.ExplainPolicy(pdptypes.NewExprContext(r, resource), policy)
+		if err != nil {
+			re.Error = err.Error()
+		}
+		re.Matched = matched
+		re.Condition = ce
+
+		// 策略需要满足所有资源
+		if !matched {
+			pe.Matched = false
+		}
+		pe.Resources = append(pe.Resources, re)
+	}
+	return pe
+}
+
+// explainDecision 返回决定鉴权结果的原因及策略
+func explainDecision(mode string, matchedPolicies []types.AuthPolicy) (string, int64) {
+	if len(matchedPolicies) == 0 {
+		return ExplainReasonNoPolicyMatched, 0
+	}
+
+	if mode == evaluation.ModeFirstMatch {
+		policy := evaluation.SortPoliciesByPriority(matchedPolicies)[0]
+		if policy.IsDeny() {
+			return ExplainReasonDenyPolicyMatched, policy.ID
+		}
+		return ExplainReasonAllowPolicyMatched, policy.ID
+	}
+
+	// deny优先
+	for _, policy := range matchedPolicies {
+		if policy.IsDeny() {
+			return ExplainReasonDenyPolicyMatched, policy.ID
+		}
+	}
+	return ExplainReasonAllowPolicyMatched, matchedPolicies[0].ID
+}
@@ -0,0 +1,181 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+
+	"github.com/agiledragon/gomonkey"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/pdp/condition"
+	"iam/pkg/abac/pdp/evaluation"
+	pdptypes "iam/pkg/abac/pdp/types"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/logging/debug"
+	svctypes "iam/pkg/service/types"
+)
+
+var _ = Describe("Explain", func() {
+
+	Describe("Explain", func() {
+		var entry *debug.Entry
+		var req *request.Request
+		var patches *gomonkey.Patches
+		BeforeEach(func() {
+			req = &request.Request{
+				System: "test",
+				Resources: []types.Resource{{
+					System: "test",
+					Type:   "app",
+					ID:     "a1",
+				}},
+			}
+
+			patches = gomonkey.NewPatches()
+			patches.ApplyMethod(reflect.TypeOf(req), "ValidateActionResource",
+				func(_ *request.Request) bool {
+					return true
+				})
+			patches.ApplyMethod(reflect.TypeOf(&req.Action), "WithoutResourceType",
+				func(_ *types.Action) bool {
+					return false
+				})
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+		})
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("subject not exists", func() {
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return sql.ErrNoRows
+			})
+
+			e, err := Explain(req, entry, false)
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), e.Allowed)
+			assert.Equal(GinkgoT(), ExplainReasonSubjectNotExists, e.Reason)
+		})
+
+		It("no policies", func() {
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(queryPolicies, func(system string,
+				subject types.Subject,
+				action types.Action,
+				withoutCache bool,
+				entry *debug.Entry,
+			) (policies []types.AuthPolicy, err error) {
+				return nil, ErrNoPolicies
+			})
+
+			e, err := Explain(req, entry, false)
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), e.Allowed)
+			assert.Equal(GinkgoT(), ExplainReasonNoPolicies, e.Reason)
+		})
+
+		It("queryPolicies fail", func() {
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(queryPolicies, func(system string,
+				subject types.Subject,
+				action types.Action,
+				withoutCache bool,
+				entry *debug.Entry,
+			) (policies []types.AuthPolicy, err error) {
+				return nil, errors.New("queryPolicies fail")
+			})
+
+			_, err := Explain(req, entry, false)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "queryPolicies fail")
+		})
+
+		It("ok, deny policy matched", func() {
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return nil
+			})
+			patches.ApplyFunc(queryPolicies, func(system string,
+				subject types.Subject,
+				action types.Action,
+				withoutCache bool,
+				entry *debug.Entry,
+			) (policies []types.AuthPolicy, err error) {
+				return []types.AuthPolicy{
+					{ID: 1, Effect: svctypes.PolicyEffectAllow},
+					{ID: 2, Effect: svctypes.PolicyEffectDeny},
+					{ID: 3, Effect: svctypes.PolicyEffectAllow},
+				}, nil
+			})
+			patches.ApplyFunc(evaluation.ExplainPolicy, func(
+				ctx *pdptypes.ExprContext, policy types.AuthPolicy,
+			) (bool, *condition.Explanation, error) {
+				if policy.ID == 3 {
+					return false, nil, errors.New("eval fail")
+				}
+				return true, &condition.Explanation{Operator: "any", Result: true}, nil
+			})
+
+			e, err := Explain(req, entry, false)
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), e.Allowed)
+			assert.Equal(GinkgoT(), ExplainReasonDenyPolicyMatched, e.Reason)
+			assert.Equal(GinkgoT(), int64(2), e.DecisivePolicyID)
+			assert.Len(GinkgoT(), e.Policies, 3)
+			assert.True(GinkgoT(), e.Policies[0].Matched)
+			assert.Equal(GinkgoT(), "a1", e.Policies[0].Resources[0].ID)
+			assert.Equal(GinkgoT(), "any", e.Policies[0].Resources[0].Condition.Operator)
+			assert.False(GinkgoT(), e.Policies[2].Matched)
+			assert.Equal(GinkgoT(), "eval fail", e.Policies[2].Resources[0].Error)
+		})
+	})
+
+	Describe("explainDecision", func() {
+		var policies []types.AuthPolicy
+		BeforeEach(func() {
+			policies = []types.AuthPolicy{
+				{ID: 1, Effect: svctypes.PolicyEffectAllow, Priority: 10},
+				{ID: 2, Effect: svctypes.PolicyEffectDeny},
+			}
+		})
+
+		It("no policy matched", func() {
+			reason, policyID := explainDecision(evaluation.ModeDenyOverrides, nil)
+			assert.Equal(GinkgoT(), ExplainReasonNoPolicyMatched, reason)
+			assert.Equal(GinkgoT(), int64(0), policyID)
+		})
+
+		It("deny overrides", func() {
+			reason, policyID := explainDecision(evaluation.ModeDenyOverrides, policies)
+			assert.Equal(GinkgoT(), ExplainReasonDenyPolicyMatched, reason)
+			assert.Equal(GinkgoT(), int64(2), policyID)
+
+			reason, policyID = explainDecision(evaluation.ModeDenyOverrides, policies[:1])
+			assert.Equal(GinkgoT(), ExplainReasonAllowPolicyMatched, reason)
+			assert.Equal(GinkgoT(), int64(1), policyID)
+		})
+
+		It("first match", func() {
+			reason, policyID := explainDecision(evaluation.ModeFirstMatch, policies)
+			assert.Equal(GinkgoT(), ExplainReasonAllowPolicyMatched, reason)
+			assert.Equal(GinkgoT(), int64(1), policyID)
+		})
+	})
+})
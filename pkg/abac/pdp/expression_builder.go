@@ -0,0 +1,210 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"iam/pkg/abac/pdp/condition"
+	"iam/pkg/abac/pdp/types"
+	"iam/pkg/abac/pip"
+	"iam/pkg/errorx"
+)
+
+/*
+结构化表达式构造
+
+接入系统提交 field/op/value 三元组, 根据资源类型注册的属性schema校验后, 转换为策略中存储的表达式, 例如:
+	{"system": "bk_test", "type": "host", "filters": [{"field": "os", "op": "in", "value": ["linux"]}]}
+=>
+	[{"system": "bk_test", "type": "host", "expression": {"StringEquals": {"os": ["linux"]}}}]
+*/
+
+// 结构化过滤条件支持的操作符
+const (
+	FilterOpEq         = "eq"
+	FilterOpIn         = "in"
+	FilterOpNotIn      = "not_in"
+	FilterOpStartsWith = "starts_with"
+	FilterOpGt         = "gt"
+	FilterOpGte        = "gte"
+	FilterOpLt         = "lt"
+	FilterOpLte        = "lte"
+	FilterOpAny        = "any"
+)
+
+// 操作符在不同属性类型下对应的条件, 不存在的组合即不支持
+var filterOpConditions = map[string]map[string]string{
+	FilterOpEq: {
+		AttributeTypeString:  "StringEquals",
+		AttributeTypeNumeric: "NumericEquals",
+		AttributeTypeBool:    "Bool",
+	},
+	FilterOpIn: {
+		AttributeTypeString:  "StringEquals",
+		AttributeTypeNumeric: "NumericEquals",
+	},
+	FilterOpNotIn: {
+		AttributeTypeString:  "StringEquals",
+		AttributeTypeNumeric: "NumericEquals",
+	},
+	FilterOpStartsWith: {
+		AttributeTypeString: "StringPrefix",
+	},
+	FilterOpGt: {
+		AttributeTypeNumeric: "NumericGt",
+	},
+	FilterOpGte: {
+		AttributeTypeNumeric: "NumericGte",
+	},
+	FilterOpLt: {
+		AttributeTypeNumeric: "NumericLt",
+	},
+	FilterOpLte: {
+		AttributeTypeNumeric: "NumericLte",
+	},
+}
+
+// ExpressionFilter 结构化的过滤条件
+type ExpressionFilter struct {
+	Field string
+	Op    string
+	Value interface{}
+}
+
+// ResourceExpressionFilters 一个资源类型的过滤条件, 多个条件之间为AND关系, 没有条件时为任意资源
+type ResourceExpressionFilters struct {
+	System  string
+	Type    string
+	Filters []ExpressionFilter
+}
+
+// BuildResourceExpression 校验并转换结构化的过滤条件为策略表达式(即policy.Expression)
+// errs为不合法的过滤条件及其位置, err为查询资源属性schema失败
+func BuildResourceExpression(
+	resources []ResourceExpressionFilters,
+) (expression string, errs []condition.ValidationError, err error) {
+	expressions := make([]types.ResourceExpression, 0, len(resources))
+	errs = []condition.ValidationError{}
+	for i, resource := range resources {
+		var schema map[string]string
+		schema, err = pip.GetResourceAttributeSchema(resource.System, resource.Type)
+		if err != nil {
+			err = errorx.Wrapf(err, PDP, "BuildResourceExpression",
+				"pip.GetResourceAttributeSchema system=`%s`, type=`%s` fail", resource.System, resource.Type)
+			return "", nil, err
+		}
+
+		conditions := make([]interface{}, 0, len(resource.Filters))
+		for j, filter := range resource.Filters {
+			pc, filterErr := buildFilterCondition(filter, schema)
+			if filterErr != nil {
+				errs = append(errs, condition.ValidationError{
+					Path:    fmt.Sprintf("[%d].filters[%d]", i, j),
+					Message: filterErr.Error(),
+				})
+				continue
+			}
+			conditions = append(conditions, pc)
+		}
+
+		var pc types.PolicyCondition
+		switch len(conditions) {
+		case 0:
+			pc = types.PolicyCondition{"Any": {"id": {}}}
+		case 1:
+			pc = conditions[0].(types.PolicyCondition)
+		default:
+			pc = types.PolicyCondition{"AND": {"content": conditions}}
+		}
+
+		expressions = append(expressions, types.ResourceExpression{
+			System:     resource.System,
+			Type:       resource.Type,
+			Expression: pc,
+		})
+	}
+	if len(errs) > 0 {
+		return "", errs, nil
+	}
+
+	// NOTE: the expression contains maps, use encoding/json
+	data, err := json.Marshal(expressions)
+	if err != nil {
+		err = errorx.Wrapf(err, PDP, "BuildResourceExpression", "json.Marshal expressions=`%+v` fail", expressions)
+		return "", nil, err
+	}
+	return string(data), errs, nil
+}
+
+func buildFilterCondition(filter ExpressionFilter, schema map[string]string) (types.PolicyCondition, error) {
+	if filter.Op == FilterOpAny {
+		if filter.Value != nil {
+			return nil, fmt.Errorf("op `%s` should not have value", filter.Op)
+		}
+		field := filter.Field
+		if field == "" {
+			field = "id"
+		}
+		return types.PolicyCondition{"Any": {field: {}}}, nil
+	}
+
+	if filter.Field == "" {
+		return nil, fmt.Errorf("field should not be empty")
+	}
+
+	// 内置属性为字符串, 其他属性需要在schema中注册
+	_type := AttributeTypeString
+	if _, ok := builtinResourceAttrs[filter.Field]; !ok {
+		registeredType, ok := schema[filter.Field]
+		if !ok {
+			return nil, fmt.Errorf("field `%s` not registered in the resource attribute schema", filter.Field)
+		}
+		_type = registeredType
+	}
+
+	opConditions, ok := filterOpConditions[filter.Op]
+	if !ok {
+		return nil, fmt.Errorf("op `%s` not supported", filter.Op)
+	}
+	operator, ok := opConditions[_type]
+	if !ok {
+		return nil, fmt.Errorf("op `%s` not supported by field `%s` of type `%s`", filter.Op, filter.Field, _type)
+	}
+
+	var values []interface{}
+	switch filter.Op {
+	case FilterOpIn, FilterOpNotIn:
+		list, ok := filter.Value.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, fmt.Errorf("value of op `%s` should be a non-empty list", filter.Op)
+		}
+		values = list
+	default:
+		if _, ok := filter.Value.([]interface{}); ok || filter.Value == nil {
+			return nil, fmt.Errorf("value of op `%s` should be a single value", filter.Op)
+		}
+		values = []interface{}{filter.Value}
+	}
+
+	for _, value := range values {
+		if !isAttributeValueOfType(value, _type) {
+			return nil, fmt.Errorf("value of field `%s` should be `%s`, got `%T`(%v)",
+				filter.Field, _type, value, value)
+		}
+	}
+
+	pc := types.PolicyCondition{operator: {filter.Field: values}}
+	if filter.Op == FilterOpNotIn {
+		pc = types.PolicyCondition{"NOT": {"content": {pc}}}
+	}
+	return pc, nil
+}
@@ -0,0 +1,122 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"errors"
+
+	"github.com/agiledragon/gomonkey"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/pip"
+)
+
+var _ = Describe("ExpressionBuilder", func() {
+
+	Describe("BuildResourceExpression", func() {
+		var patches *gomonkey.Patches
+		BeforeEach(func() {
+			patches = gomonkey.ApplyFunc(pip.GetResourceAttributeSchema, func(
+				system, _type string,
+			) (map[string]string, error) {
+				return map[string]string{
+					"os":     AttributeTypeString,
+					"cpu":    AttributeTypeNumeric,
+					"online": AttributeTypeBool,
+				}, nil
+			})
+		})
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("get schema fail", func() {
+			patches.Reset()
+			patches = gomonkey.ApplyFunc(pip.GetResourceAttributeSchema, func(
+				system, _type string,
+			) (map[string]string, error) {
+				return nil, errors.New("get schema fail")
+			})
+
+			_, _, err := BuildResourceExpression([]ResourceExpressionFilters{{System: "test", Type: "host"}})
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("empty filters", func() {
+			expression, errs, err := BuildResourceExpression(
+				[]ResourceExpressionFilters{{System: "test", Type: "host"}})
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), errs)
+			assert.Equal(GinkgoT(), `[{"system":"test","type":"host","expression":{"Any":{"id":[]}}}]`, expression)
+		})
+
+		It("single filter", func() {
+			expression, errs, err := BuildResourceExpression([]ResourceExpressionFilters{{
+				System:  "test",
+				Type:    "host",
+				Filters: []ExpressionFilter{{Field: "id", Op: FilterOpIn, Value: []interface{}{"1", "2"}}},
+			}})
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), errs)
+			assert.Equal(GinkgoT(),
+				`[{"system":"test","type":"host","expression":{"StringEquals":{"id":["1","2"]}}}]`, expression)
+		})
+
+		It("multiple filters", func() {
+			expression, errs, err := BuildResourceExpression([]ResourceExpressionFilters{{
+				System: "test",
+				Type:   "host",
+				Filters: []ExpressionFilter{
+					{Field: "_bk_iam_path_", Op: FilterOpStartsWith, Value: "/biz,1/"},
+					{Field: "cpu", Op: FilterOpGte, Value: float64(4)},
+					{Field: "online", Op: FilterOpEq, Value: true},
+					{Field: "os", Op: FilterOpNotIn, Value: []interface{}{"windows"}},
+				},
+			}})
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), errs)
+			assert.Equal(GinkgoT(), `[{"system":"test","type":"host","expression":{"AND":{"content":[`+
+				`{"StringPrefix":{"_bk_iam_path_":["/biz,1/"]}},`+
+				`{"NumericGte":{"cpu":[4]}},`+
+				`{"Bool":{"online":[true]}},`+
+				`{"NOT":{"content":[{"StringEquals":{"os":["windows"]}}]}}]}}}]`, expression)
+		})
+
+		It("invalid filters", func() {
+			expression, errs, err := BuildResourceExpression([]ResourceExpressionFilters{{
+				System: "test",
+				Type:   "host",
+				Filters: []ExpressionFilter{
+					{Field: "unknown", Op: FilterOpEq, Value: "a"},
+					{Field: "os", Op: FilterOpGt, Value: "a"},
+					{Field: "os", Op: FilterOpIn, Value: "linux"},
+					{Field: "cpu", Op: FilterOpEq, Value: []interface{}{float64(1)}},
+					{Field: "cpu", Op: FilterOpEq, Value: "1"},
+					{Field: "id", Op: FilterOpAny, Value: "1"},
+					{Field: "", Op: FilterOpEq, Value: "1"},
+					{Field: "id", Op: "regex", Value: "1"},
+				},
+			}})
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), expression)
+			assert.Len(GinkgoT(), errs, 8)
+			assert.Equal(GinkgoT(), "[0].filters[0]", errs[0].Path)
+			assert.Contains(GinkgoT(), errs[0].Message, "not registered")
+			assert.Contains(GinkgoT(), errs[1].Message, "not supported by field `os`")
+			assert.Contains(GinkgoT(), errs[2].Message, "non-empty list")
+			assert.Contains(GinkgoT(), errs[3].Message, "single value")
+			assert.Contains(GinkgoT(), errs[4].Message, "should be `numeric`")
+			assert.Contains(GinkgoT(), errs[5].Message, "should not have value")
+			assert.Contains(GinkgoT(), errs[6].Message, "field should not be empty")
+			assert.Contains(GinkgoT(), errs[7].Message, "op `regex` not supported")
+		})
+	})
+})
@@ -0,0 +1,191 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-sql-driver/mysql"
+	gocache "github.com/patrickmn/go-cache"
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/config"
+	"iam/pkg/logging/debug"
+	"iam/pkg/util"
+)
+
+/*
+鉴权过程中依赖(redis/数据库/第三方资源属性接口)失败时的处理策略, 每个系统可以单独配置
+
+1. error:       (默认) 返回错误, 由调用方决定
+2. fail_closed: 没有权限
+3. serve_stale: 使用本实例最近一次的鉴权结果, 没有时没有权限
+
+配置了 fail_open_actions 的操作(只读/低风险), 依赖失败且没有可用的旧结果时, 有权限
+
+NOTE: 只有数据库/redis/第三方资源属性接口/网络的错误属于依赖失败; 其他错误, 例如请求非法(操作不存在/资源类型不匹配),
+并发超限与操作被禁用, 都直接返回错误, 不受影响
+*/
+
+// 依赖失败时的处理模式
+const (
+	FailureModeError      = "error"
+	FailureModeFailClosed = "fail_closed"
+	FailureModeServeStale = "serve_stale"
+)
+
+// staleDecisionExpiration serve_stale模式下, 最近一次鉴权结果的保留时间
+const staleDecisionExpiration = 10 * time.Minute
+
+// FailurePolicy 系统的依赖失败处理策略
+type FailurePolicy struct {
+	Mode            string
+	FailOpenActions *util.StringSet
+}
+
+// NOTE: 初始化后只读, 不需要加锁
+var (
+	defaultFailurePolicy  = FailurePolicy{Mode: FailureModeError, FailOpenActions: util.NewStringSet()}
+	systemFailurePolicies = map[string]FailurePolicy{}
+
+	staleDecisionCache = gocache.New(staleDecisionExpiration, staleDecisionExpiration)
+)
+
+// InitFailurePolicies 初始化每个系统的依赖失败处理策略, 未配置或模式非法的系统使用默认模式
+func InitFailurePolicies(cfg config.EvalFailurePolicy) {
+	defaultFailurePolicy = FailurePolicy{Mode: validFailureMode(cfg.Default), FailOpenActions: util.NewStringSet()}
+
+	systemFailurePolicies = make(map[string]FailurePolicy, len(cfg.Systems))
+	for _, p := range cfg.Systems {
+		systemFailurePolicies[p.ID] = FailurePolicy{
+			Mode:            validFailureMode(p.Mode),
+			FailOpenActions: util.NewStringSetWithValues(p.FailOpenActions),
+		}
+	}
+
+	staleDecisionCache.Flush()
+}
+
+func validFailureMode(mode string) string {
+	if mode == FailureModeFailClosed || mode == FailureModeServeStale {
+		return mode
+	}
+	return FailureModeError
+}
+
+// GetFailurePolicy 获取系统的依赖失败处理策略
+func GetFailurePolicy(system string) FailurePolicy {
+	if policy, ok := systemFailurePolicies[system]; ok {
+		return policy
+	}
+	return defaultFailurePolicy
+}
+
+// isDependencyError 是否是依赖(数据库/redis/第三方资源属性接口)失败导致的错误
+// NOTE: 只识别明确的依赖错误, 其他错误(请求非法/数据异常等)都直接返回, 不走失败处理策略
+func isDependencyError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// 第三方资源属性接口
+	var remoteErr remoteResourceError
+	if errors.As(err, &remoteErr) {
+		return true
+	}
+
+	// 数据库
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) ||
+		errors.Is(err, mysql.ErrInvalidConn) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+
+	// redis, NOTE: redis.Nil 是key不存在, 不是依赖失败
+	var redisErr redis.Error
+	if errors.Is(err, redis.Nil) {
+		return false
+	}
+	if errors.As(err, &redisErr) || errors.Is(err, redis.ErrClosed) {
+		return true
+	}
+
+	// 网络/超时
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// staleDecisionKey 鉴权结果的key, 非serve_stale模式返回空, 不记录
+func staleDecisionKey(r *request.Request, actionID string, resources []types.Resource) string {
+	if GetFailurePolicy(r.System).Mode != FailureModeServeStale {
+		return ""
+	}
+	return fmt.Sprintf("%s:%s:%s:%s:%v", r.System, r.Subject.Type, r.Subject.ID, actionID, resources)
+}
+
+// recordDecision serve_stale模式下记录鉴权结果, 用于依赖失败时兜底
+func recordDecision(key string, isPass bool) {
+	if key == "" {
+		return
+	}
+	staleDecisionCache.SetDefault(key, isPass)
+}
+
+// handleEvalFailure 依赖失败时, 根据系统的策略决定鉴权结果; 返回error表示不处理, 由调用方返回错误
+func handleEvalFailure(
+	system, actionID, key string,
+	evalErr error,
+	entry *debug.Entry,
+) (isPass bool, err error) {
+	if !isDependencyError(evalErr) {
+		return false, evalErr
+	}
+
+	policy := GetFailurePolicy(system)
+
+	decision := ""
+	if key != "" {
+		if value, ok := staleDecisionCache.Get(key); ok {
+			isPass = value.(bool)
+			decision = "stale"
+		}
+	}
+
+	if decision == "" {
+		switch {
+		case policy.FailOpenActions.Has(actionID):
+			isPass = true
+			decision = "fail_open"
+		case policy.Mode == FailureModeError:
+			return false, evalErr
+		default:
+			isPass = false
+			decision = "fail_closed"
+		}
+	}
+
+	log.WithError(evalErr).Warnf("eval dependency fail, system=`%s`, action=`%s`, mode=`%s`, decision=`%s`, pass=%t",
+		system, actionID, policy.Mode, decision, isPass)
+	debug.WithValue(entry, "failureDecision", decision)
+	debug.WithError(entry, evalErr)
+
+	return isPass, nil
+}
@@ -0,0 +1,261 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"reflect"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/go-redis/redis/v8"
+	"github.com/go-sql-driver/mysql"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/config"
+	"iam/pkg/errorx"
+	"iam/pkg/logging/debug"
+)
+
+var _ = Describe("Failure", func() {
+
+	AfterEach(func() {
+		InitFailurePolicies(config.EvalFailurePolicy{})
+	})
+
+	Describe("GetFailurePolicy", func() {
+		It("default", func() {
+			InitFailurePolicies(config.EvalFailurePolicy{})
+			assert.Equal(GinkgoT(), FailureModeError, GetFailurePolicy("test").Mode)
+		})
+
+		It("system policy, invalid mode use error", func() {
+			InitFailurePolicies(config.EvalFailurePolicy{
+				Default: FailureModeFailClosed,
+				Systems: []config.SystemEvalFailurePolicy{
+					{ID: "stale", Mode: FailureModeServeStale, FailOpenActions: []string{"view"}},
+					{ID: "invalid", Mode: "abc"},
+				},
+			})
+
+			assert.Equal(GinkgoT(), FailureModeFailClosed, GetFailurePolicy("test").Mode)
+			assert.Equal(GinkgoT(), FailureModeServeStale, GetFailurePolicy("stale").Mode)
+			assert.True(GinkgoT(), GetFailurePolicy("stale").FailOpenActions.Has("view"))
+			assert.Equal(GinkgoT(), FailureModeError, GetFailurePolicy("invalid").Mode)
+		})
+	})
+
+	Describe("isDependencyError", func() {
+		It("dependency error", func() {
+			errs := []error{
+				&mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"},
+				mysql.ErrInvalidConn,
+				driver.ErrBadConn,
+				redis.ErrClosed,
+				&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+				context.DeadlineExceeded,
+				remoteResourceError{err: errors.New("query resources not 200")},
+				errorx.Wrapf(driver.ErrBadConn, "Dao", "Get", "query fail"),
+				errorx.Wrapf(&mysql.MySQLError{Number: 2006}, "Dao", "Get", "query fail"),
+			}
+			for _, err := range errs {
+				assert.True(GinkgoT(), isDependencyError(err), err.Error())
+			}
+		})
+
+		It("not dependency error", func() {
+			errs := []error{
+				nil,
+				errors.New("unknown"),
+				redis.Nil,
+				ErrInvalidAction,
+				ErrTooManyEvaluations,
+				ErrActionDisabled,
+				errorx.Wrapf(ErrSubjectNotExists, "PDP", "Eval", "fill subject fail"),
+			}
+			for _, err := range errs {
+				assert.False(GinkgoT(), isDependencyError(err))
+			}
+		})
+	})
+
+	Describe("handleEvalFailure", func() {
+		evalErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("redis down")}
+
+		It("not dependency error", func() {
+			InitFailurePolicies(config.EvalFailurePolicy{Default: FailureModeFailClosed})
+
+			_, err := handleEvalFailure("test", "edit", "", ErrInvalidAction, nil)
+			assert.ErrorIs(GinkgoT(), err, ErrInvalidAction)
+
+			_, err = handleEvalFailure("test", "edit", "", ErrTooManyEvaluations, nil)
+			assert.ErrorIs(GinkgoT(), err, ErrTooManyEvaluations)
+
+			// fail open的操作, 非依赖错误也直接返回
+			InitFailurePolicies(config.EvalFailurePolicy{
+				Systems: []config.SystemEvalFailurePolicy{
+					{ID: "test", Mode: FailureModeFailClosed, FailOpenActions: []string{"view"}},
+				},
+			})
+			unknownErr := errors.New("unknown")
+			_, err = handleEvalFailure("test", "view", "", unknownErr, nil)
+			assert.Equal(GinkgoT(), unknownErr, err)
+		})
+
+		It("error mode", func() {
+			_, err := handleEvalFailure("test", "edit", "", evalErr, nil)
+			assert.Equal(GinkgoT(), evalErr, err)
+		})
+
+		It("fail closed", func() {
+			InitFailurePolicies(config.EvalFailurePolicy{Default: FailureModeFailClosed})
+
+			isPass, err := handleEvalFailure("test", "edit", "", evalErr, nil)
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), isPass)
+		})
+
+		It("fail open action", func() {
+			InitFailurePolicies(config.EvalFailurePolicy{
+				Systems: []config.SystemEvalFailurePolicy{
+					{ID: "test", FailOpenActions: []string{"view"}},
+				},
+			})
+
+			isPass, err := handleEvalFailure("test", "view", "", evalErr, nil)
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), isPass)
+
+			_, err = handleEvalFailure("test", "edit", "", evalErr, nil)
+			assert.Equal(GinkgoT(), evalErr, err)
+		})
+
+		It("serve stale", func() {
+			InitFailurePolicies(config.EvalFailurePolicy{
+				Systems: []config.SystemEvalFailurePolicy{
+					{ID: "test", Mode: FailureModeServeStale, FailOpenActions: []string{"view"}},
+				},
+			})
+			recordDecision("k1", true)
+			recordDecision("k2", false)
+
+			entry := debug.EntryPool.Get()
+			defer debug.EntryPool.Put(entry)
+
+			isPass, err := handleEvalFailure("test", "edit", "k1", evalErr, entry)
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), isPass)
+			assert.Equal(GinkgoT(), "stale", entry.Context["failureDecision"])
+
+			// 旧的结果优先于fail open
+			isPass, err = handleEvalFailure("test", "view", "k2", evalErr, nil)
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), isPass)
+
+			// 没有旧的结果
+			isPass, err = handleEvalFailure("test", "view", "k3", evalErr, nil)
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), isPass)
+
+			isPass, err = handleEvalFailure("test", "edit", "k3", evalErr, nil)
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), isPass)
+		})
+	})
+
+	Describe("entrance", func() {
+		var req *request.Request
+		var patches *gomonkey.Patches
+		BeforeEach(func() {
+			req = &request.Request{
+				System:  "test",
+				Subject: types.NewSubject(),
+				Action:  types.NewAction(),
+				Resources: []types.Resource{{
+					System: "test",
+					ID:     "1",
+				}},
+			}
+			req.Subject.Type = "user"
+			req.Subject.ID = "admin"
+			req.Action.ID = "edit"
+
+			patches = gomonkey.NewPatches()
+			patches.ApplyMethod(reflect.TypeOf(req), "ValidateActionResource",
+				func(_ *request.Request) bool {
+					return true
+				})
+			patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+				return nil
+			})
+		})
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("Eval, serve stale", func() {
+			InitFailurePolicies(config.EvalFailurePolicy{Default: FailureModeServeStale})
+
+			var fillSubjectErr error
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return fillSubjectErr
+			})
+			patches.ApplyFunc(evalPolicies, func(r *request.Request, entry *debug.Entry, withoutCache bool) (bool, error) {
+				return true, nil
+			})
+			isPass, err := Eval(req, nil, false)
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), isPass)
+
+			fillSubjectErr = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("redis down")}
+			isPass, err = Eval(req, nil, false)
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), isPass)
+
+			// 其他资源没有旧的结果
+			req.Resources[0].ID = "2"
+			isPass, err = Eval(req, nil, false)
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), isPass)
+		})
+
+		It("BatchEvalActions, fail open", func() {
+			InitFailurePolicies(config.EvalFailurePolicy{
+				Default: FailureModeFailClosed,
+				Systems: []config.SystemEvalFailurePolicy{
+					{ID: "test", Mode: FailureModeFailClosed, FailOpenActions: []string{"view"}},
+				},
+			})
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return redis.ErrClosed
+			})
+
+			results, err := BatchEvalActions(req, []string{"edit", "view"}, nil, false)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]bool{"edit": false, "view": true}, results)
+		})
+
+		It("BatchEvalResources, error", func() {
+			patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+				return redis.ErrClosed
+			})
+
+			results, err := BatchEvalResources(req, [][]types.Resource{req.Resources}, nil, false)
+			assert.Nil(GinkgoT(), results)
+			assert.Error(GinkgoT(), err)
+			assert.ErrorIs(GinkgoT(), err, redis.ErrClosed)
+		})
+	})
+})
@@ -14,6 +14,8 @@ import (
 	"database/sql"
 	"errors"
 
+	log "github.com/sirupsen/logrus"
+
 	"iam/pkg/abac/pdp/evaluation"
 	pdptypes "iam/pkg/abac/pdp/types"
 	"iam/pkg/abac/pip"
@@ -32,8 +34,14 @@ var (
 	ErrNoPolicies       = errors.New("no policies")
 	ErrInvalidAction    = errors.New("action.id invalid")
 	ErrSubjectNotExists = errors.New("subject not exists")
+	ErrSubjectFrozen    = errors.New("subject is frozen")
 )
 
+// isSubjectInactive subject不存在或被冻结, 都表现为没有权限
+func isSubjectInactive(err error) bool {
+	return errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrSubjectFrozen)
+}
+
 func queryPolicies(
 	system string,
 	subject types.Subject,
@@ -45,7 +53,13 @@ func queryPolicies(
 
 	manager := prp.NewPolicyManager()
 
+	category := debug.TimingCache
+	if withoutCache {
+		category = debug.TimingDB
+	}
+	stopTiming := debug.StartTiming(entry, category)
 	policies, err = manager.ListBySubjectAction(system, subject, action, withoutCache, entry)
+	stopTiming()
 	if err != nil {
 		err = errorWrapf(err,
 			"ListBySubjectAction system=`%s`, subject=`%s`, action=`%s`, withoutCache=`%t` fail",
@@ -62,25 +76,37 @@ func queryPolicies(
 	return
 }
 
+// filterPoliciesByEvalResources 根据请求的资源过滤policies
+// NOTE: 返回的policies中可能包含满足所有请求资源的deny策略, 由调用方处理
 func filterPoliciesByEvalResources(
 	r *request.Request,
 	policies []types.AuthPolicy,
+	entry *debug.Entry,
 ) (filteredPolicies []types.AuthPolicy, err error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDPHelper, "filterPoliciesByEvalResources")
 
+	// get local + remote resources
+	resources := r.GetSortedResources()
+
 	// 问题: 一次性取? 还是计算一个取一个?
-	// NOTE: 重要, 这个需要处理, 以降低影响?
-	// 问题: 第三方系统查询不到, policy列表 和 auth鉴权结果怎么返回? 鉴权false? policy列表直接不过滤全返回?
+	// 第三方系统查询不到时, 由资源提供方系统的处理模式决定: 返回错误, 或者降级为不按第三方资源过滤
 	// if contains remote Resource
 	if r.HasRemoteResources() {
+		stopTiming := debug.StartTiming(entry, debug.TimingRemote)
 		err = fillRemoteResourceAttrs(r, policies)
+		stopTiming()
 		if err != nil {
-			return nil, errorWrapf(err, "fillRemoteResourceAttrs fail", "")
+			if !errors.Is(err, ErrRemoteResourceDegraded) {
+				return nil, errorWrapf(err, "fillRemoteResourceAttrs fail", "")
+			}
+
+			log.WithError(err).Warnf("remote resources degraded, system=`%s`, action=`%s`", r.System, r.Action.ID)
+			debug.WithValue(entry, "remoteResourceDegraded", true)
+			resources = getLocalResources(r)
+			err = nil
 		}
 	}
 
-	// get local + remote resources
-	resources := r.GetSortedResources()
 	for _, resource := range resources {
 		ctx := pdptypes.NewExprContext(r, resource)
 
@@ -92,7 +118,8 @@ func filterPoliciesByEvalResources(
 			return
 		}
 
-		if len(policies) == 0 {
+		// 没有allow策略满足, 即使有deny策略, 也是没有权限
+		if !evaluation.ContainsAllowPolicy(policies) {
 			err = ErrNoPolicies
 			return
 		}
@@ -124,7 +151,9 @@ func queryFilterPolicies(
 
 	// 1. PIP查询action的scop
 	debug.AddStep(entry, "Fetch action details")
+	stopTiming := debug.StartTiming(entry, debug.TimingCache)
 	err := fillActionDetail(r)
+	stopTiming()
 	if err != nil {
 		err = errorWrapf(err, "Fetch action detail action=`%+v` fail", r.Action)
 		if errors.Is(err, sql.ErrNoRows) {
@@ -150,11 +179,13 @@ func queryFilterPolicies(
 
 	// 3. PIP查询subject相关的属性
 	debug.AddStep(entry, "Fetch subject details")
+	stopTiming = debug.StartTiming(entry, debug.TimingCache)
 	err = fillSubjectDetail(r)
+	stopTiming()
 	if err != nil {
-		// 如果用户不存在, 表现为没有权限
-		// if the subject not exists
-		if errors.Is(err, sql.ErrNoRows) {
+		// 如果用户不存在或被冻结, 表现为没有权限
+		// if the subject not exists or is frozen
+		if isSubjectInactive(err) {
 			return []types.AuthPolicy{}, nil
 		}
 
@@ -163,6 +194,13 @@ func queryFilterPolicies(
 	}
 	debug.WithValue(entry, "subject", r.Subject)
 
+	return queryAndFilterPolicies(r, entry, withoutCache)
+}
+
+// queryAndFilterPolicies 查询subject-action相关的policies, 并根据请求的资源过滤; r的action与subject需要已填充
+func queryAndFilterPolicies(r *request.Request, entry *debug.Entry, withoutCache bool) ([]types.AuthPolicy, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "queryAndFilterPolicies")
+
 	// 4. PRP查询subject-action相关的policies
 	debug.AddStep(entry, "Query Policies")
 	policies, err := queryPolicies(r.System, r.Subject, r.Action, withoutCache, entry)
@@ -183,7 +221,7 @@ func queryFilterPolicies(
 	// 这里需要返回剩下的policies
 	debug.AddStep(entry, "Filter policies by eval resources")
 	var filteredPolicies []types.AuthPolicy
-	filteredPolicies, err = filterPoliciesByEvalResources(r, policies)
+	filteredPolicies, err = filterPoliciesByEvalResources(r, policies, entry)
 	if err != nil {
 		if errors.Is(err, ErrNoPolicies) {
 			// if is len(filteredPolicies) == 0, update all to no pass
@@ -217,6 +255,16 @@ func fillSubjectDetail(r *request.Request) error {
 		return err
 	}
 
+	// 冻结的subject没有任何权限, 不需要再查询其他属性
+	frozen, err := pip.IsSubjectFrozen(pk)
+	if err != nil {
+		err = errorWrapf(err, "IsSubjectFrozen pk=`%d` fail", pk)
+		return err
+	}
+	if frozen {
+		return ErrSubjectFrozen
+	}
+
 	departments, groups, err := pip.GetSubjectDetail(pk)
 	if err != nil {
 		err = errorWrapf(err, "GetSubjectDetail pk=`%d` fail", pk)
@@ -224,6 +272,15 @@ func fillSubjectDetail(r *request.Request) error {
 	}
 
 	r.Subject.FillAttributes(pk, groups, departments)
+
+	// 外部属性提供方的属性, 查询失败不影响鉴权, 引用缺失属性的条件不会满足
+	extAttrs, err := pip.GetSubjectExtAttributes(_type, id)
+	if err != nil {
+		log.WithError(err).Warnf("GetSubjectExtAttributes _type=`%s`, id=`%s` fail", _type, id)
+	}
+	if len(extAttrs) > 0 {
+		r.Subject.Attribute.SetExtAttributes(extAttrs)
+	}
 	return nil
 }
 
@@ -12,6 +12,7 @@ package pdp
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 
 	"github.com/agiledragon/gomonkey"
@@ -112,19 +113,39 @@ var _ = Describe("Helper", func() {
 					return errors.New("test")
 				})
 
-			policies, err := filterPoliciesByEvalResources(req, []types.AuthPolicy{})
+			policies, err := filterPoliciesByEvalResources(req, []types.AuthPolicy{}, nil)
 			assert.Nil(GinkgoT(), policies)
 			assert.Error(GinkgoT(), err, "test1")
 
 		})
 
+		It("fillRemoteResourceAttrs degraded, filter by local resources only", func() {
+			req.Resources = append(req.Resources, types.Resource{System: "remote"})
+			patches = gomonkey.ApplyFunc(fillRemoteResourceAttrs,
+				func(r *request.Request, policies []types.AuthPolicy) error {
+					return fmt.Errorf("%w: timeout", ErrRemoteResourceDegraded)
+				})
+			filtered := []*types.Resource{}
+			patches.ApplyFunc(evaluation.FilterPolicies,
+				func(ctx *pdptypes.ExprContext, policies []types.AuthPolicy) ([]types.AuthPolicy, error) {
+					filtered = append(filtered, ctx.Resource)
+					return policies, nil
+				})
+
+			policies, err := filterPoliciesByEvalResources(req, []types.AuthPolicy{{}}, nil)
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), policies, 1)
+			assert.Len(GinkgoT(), filtered, 1)
+			assert.Equal(GinkgoT(), "test", filtered[0].System)
+		})
+
 		It("filter error", func() {
 			patches = gomonkey.ApplyFunc(evaluation.FilterPolicies,
 				func(ctx *pdptypes.ExprContext, policies []types.AuthPolicy) ([]types.AuthPolicy, error) {
 					return nil, errors.New("test")
 				})
 
-			policies, err := filterPoliciesByEvalResources(req, []types.AuthPolicy{})
+			policies, err := filterPoliciesByEvalResources(req, []types.AuthPolicy{}, nil)
 			assert.Nil(GinkgoT(), policies)
 			assert.Error(GinkgoT(), err, "test1")
 
@@ -135,7 +156,7 @@ var _ = Describe("Helper", func() {
 				func(ctx *pdptypes.ExprContext, policies []types.AuthPolicy) ([]types.AuthPolicy, error) {
 					return []types.AuthPolicy{}, nil
 				})
-			policies, err := filterPoliciesByEvalResources(req, []types.AuthPolicy{})
+			policies, err := filterPoliciesByEvalResources(req, []types.AuthPolicy{}, nil)
 			assert.Len(GinkgoT(), policies, 0)
 			assert.Error(GinkgoT(), err, "no")
 		})
@@ -145,11 +166,31 @@ var _ = Describe("Helper", func() {
 				func(ctx *pdptypes.ExprContext, policies []types.AuthPolicy) ([]types.AuthPolicy, error) {
 					return []types.AuthPolicy{{}}, nil
 				})
-			policies, err := filterPoliciesByEvalResources(req, []types.AuthPolicy{})
+			policies, err := filterPoliciesByEvalResources(req, []types.AuthPolicy{}, nil)
 			assert.Len(GinkgoT(), policies, 1)
 			assert.NoError(GinkgoT(), err)
 		})
 
+		It("only deny left", func() {
+			patches = gomonkey.ApplyFunc(evaluation.FilterPolicies,
+				func(ctx *pdptypes.ExprContext, policies []types.AuthPolicy) ([]types.AuthPolicy, error) {
+					return []types.AuthPolicy{{Effect: "deny"}}, nil
+				})
+			policies, err := filterPoliciesByEvalResources(req, []types.AuthPolicy{}, nil)
+			assert.Nil(GinkgoT(), policies)
+			assert.ErrorIs(GinkgoT(), err, ErrNoPolicies)
+		})
+
+		It("ok, allow and deny left", func() {
+			patches = gomonkey.ApplyFunc(evaluation.FilterPolicies,
+				func(ctx *pdptypes.ExprContext, policies []types.AuthPolicy) ([]types.AuthPolicy, error) {
+					return []types.AuthPolicy{{}, {Effect: "deny"}}, nil
+				})
+			policies, err := filterPoliciesByEvalResources(req, []types.AuthPolicy{}, nil)
+			assert.Len(GinkgoT(), policies, 2)
+			assert.NoError(GinkgoT(), err)
+		})
+
 	})
 
 	Describe("queryFilterPolicies", func() {
@@ -254,6 +295,7 @@ var _ = Describe("Helper", func() {
 			})
 			patches.ApplyFunc(filterPoliciesByEvalResources, func(r *request.Request,
 				policies []types.AuthPolicy,
+				entry *debug.Entry,
 			) (filteredPolicies []types.AuthPolicy, err error) {
 				return nil, errors.New("filter error")
 			})
@@ -281,6 +323,7 @@ var _ = Describe("Helper", func() {
 			})
 			patches.ApplyFunc(filterPoliciesByEvalResources, func(r *request.Request,
 				policies []types.AuthPolicy,
+				entry *debug.Entry,
 			) (filteredPolicies []types.AuthPolicy, err error) {
 				return nil, ErrNoPolicies
 			})
@@ -307,6 +350,7 @@ var _ = Describe("Helper", func() {
 			})
 			patches.ApplyFunc(filterPoliciesByEvalResources, func(r *request.Request,
 				policies []types.AuthPolicy,
+				entry *debug.Entry,
 			) (filteredPolicies []types.AuthPolicy, err error) {
 				return []types.AuthPolicy{{}}, nil
 			})
@@ -341,10 +385,39 @@ var _ = Describe("Helper", func() {
 
 		})
 
+		It("pip.IsSubjectFrozen fail", func() {
+			patches = gomonkey.ApplyFunc(pip.GetSubjectPK, func(_type, id string) (pk int64, err error) {
+				return 123, nil
+			})
+			patches.ApplyFunc(pip.IsSubjectFrozen, func(pk int64) (bool, error) {
+				return false, errors.New("is frozen fail")
+			})
+
+			err := fillSubjectDetail(r)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "is frozen fail")
+		})
+
+		It("frozen", func() {
+			patches = gomonkey.ApplyFunc(pip.GetSubjectPK, func(_type, id string) (pk int64, err error) {
+				return 123, nil
+			})
+			patches.ApplyFunc(pip.IsSubjectFrozen, func(pk int64) (bool, error) {
+				return true, nil
+			})
+
+			err := fillSubjectDetail(r)
+			assert.ErrorIs(GinkgoT(), err, ErrSubjectFrozen)
+			assert.True(GinkgoT(), isSubjectInactive(err))
+		})
+
 		It("pip.GetSubjectDetail fail", func() {
 			patches = gomonkey.ApplyFunc(pip.GetSubjectPK, func(_type, id string) (pk int64, err error) {
 				return 123, nil
 			})
+			patches.ApplyFunc(pip.IsSubjectFrozen, func(pk int64) (bool, error) {
+				return false, nil
+			})
 			patches.ApplyFunc(pip.GetSubjectDetail, func(pk int64) ([]int64, []types.SubjectGroup, error) {
 				return nil, nil, errors.New("get GetSubjectDetail fail")
 			})
@@ -358,6 +431,9 @@ var _ = Describe("Helper", func() {
 			patches = gomonkey.ApplyFunc(pip.GetSubjectPK, func(_type, id string) (pk int64, err error) {
 				return 123, nil
 			})
+			patches.ApplyFunc(pip.IsSubjectFrozen, func(pk int64) (bool, error) {
+				return false, nil
+			})
 			returned := []types.SubjectGroup{
 				{
 					PK:              1,
@@ -368,9 +444,35 @@ var _ = Describe("Helper", func() {
 			patches.ApplyFunc(pip.GetSubjectDetail, func(pk int64) ([]int64, []types.SubjectGroup, error) {
 				return []int64{1, 2, 3}, returned, nil
 			})
+			patches.ApplyFunc(pip.GetSubjectExtAttributes, func(_type, id string) (map[string]interface{}, error) {
+				return nil, nil
+			})
+
+			err := fillSubjectDetail(r)
+			assert.NoError(GinkgoT(), err)
+			_, ok := r.Subject.Attribute.GetExtAttribute("job_level")
+			assert.False(GinkgoT(), ok)
+		})
+
+		It("ok with ext attributes, ignore the provider error", func() {
+			patches = gomonkey.ApplyFunc(pip.GetSubjectPK, func(_type, id string) (pk int64, err error) {
+				return 123, nil
+			})
+			patches.ApplyFunc(pip.IsSubjectFrozen, func(pk int64) (bool, error) {
+				return false, nil
+			})
+			patches.ApplyFunc(pip.GetSubjectDetail, func(pk int64) ([]int64, []types.SubjectGroup, error) {
+				return []int64{}, []types.SubjectGroup{}, nil
+			})
+			patches.ApplyFunc(pip.GetSubjectExtAttributes, func(_type, id string) (map[string]interface{}, error) {
+				return map[string]interface{}{"job_level": "P7"}, errors.New("provider hr fail")
+			})
 
 			err := fillSubjectDetail(r)
 			assert.NoError(GinkgoT(), err)
+			value, ok := r.Subject.Attribute.GetExtAttribute("job_level")
+			assert.True(GinkgoT(), ok)
+			assert.Equal(GinkgoT(), "P7", value)
 		})
 	})
 
@@ -0,0 +1,109 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"errors"
+
+	"iam/pkg/abac/prp"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/errorx"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// HypotheticalSubject 内联定义的虚拟用户, 不存在于DB, 只有所属的部门和直接加入的用户组
+type HypotheticalSubject struct {
+	DepartmentPKs []int64
+	GroupPKs      []int64
+}
+
+func (s HypotheticalSubject) toSubject() types.Subject {
+	groups := make([]types.SubjectGroup, 0, len(s.GroupPKs))
+	for _, pk := range s.GroupPKs {
+		groups = append(groups, types.SubjectGroup{
+			PK:              pk,
+			PolicyExpiredAt: util.NeverExpiresUnixTime,
+		})
+	}
+
+	subject := types.NewSubject()
+	subject.Type = svctypes.UserType
+	// NOTE: 虚拟用户没有pk, 不会有直接授权的策略
+	subject.FillAttributes(0, groups, s.DepartmentPKs)
+	return subject
+}
+
+// EvalHypothetical 对虚拟用户鉴权, 用于评估"部门X的新员工入职后会有哪些权限"
+// NOTE: 虚拟用户的权限来自部门继承/加入的用户组/所有用户subject; 策略只从DB查询, 不会写DB, 也不会读写策略缓存
+func EvalHypothetical(
+	system string,
+	subject HypotheticalSubject,
+	reqs []*request.Request,
+) (results []bool, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "EvalHypothetical")
+
+	release, err := acquireEval(system)
+	if err != nil {
+		err = errorWrapf(err, "acquireEval system=`%s` fail", system)
+		return
+	}
+	defer release()
+
+	manager := prp.NewPolicyManager()
+
+	results = make([]bool, 0, len(reqs))
+	for _, r := range reqs {
+		var isPass bool
+		isPass, err = evalHypothetical(manager, subject, r)
+		if err != nil {
+			if errors.Is(err, ErrInvalidAction) {
+				return
+			}
+
+			err = errorWrapf(err, "evalHypothetical request=`%+v` fail", r)
+			return
+		}
+		results = append(results, isPass)
+	}
+	return results, nil
+}
+
+func evalHypothetical(
+	manager prp.PolicyManager,
+	subject HypotheticalSubject,
+	r *request.Request,
+) (isPass bool, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "evalHypothetical")
+
+	// 1. PIP查询action, 并检查请求资源与action关联的类型是否匹配
+	err = fillAndValidateAction(r, nil)
+	if err != nil {
+		if errors.Is(err, ErrInvalidAction) {
+			return
+		}
+
+		err = errorWrapf(err, "fillAndValidateAction action=`%+v` fail", r.Action)
+		return
+	}
+
+	// 2. 使用内联定义的subject属性, 不查询PIP
+	r.Subject = subject.toSubject()
+
+	// 3. 查询策略并计算
+	policies, err := manager.ListBySubjectAction(r.System, r.Subject, r.Action, true, nil)
+	if err != nil {
+		err = errorWrapf(err, "ListBySubjectAction system=`%s`, subject=`%+v`, action=`%+v` fail",
+			r.System, subject, r.Action)
+		return
+	}
+	return evalSimulatedPolicies(r, policies)
+}
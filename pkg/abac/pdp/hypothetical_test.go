@@ -0,0 +1,116 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/prp"
+	"iam/pkg/abac/prp/mock"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/logging/debug"
+)
+
+var _ = Describe("EvalHypothetical", func() {
+	var ctl *gomock.Controller
+	var manager *mock.MockPolicyManager
+	var patches *gomonkey.Patches
+	var req *request.Request
+	var subject HypotheticalSubject
+	BeforeEach(func() {
+		ctl = gomock.NewController(GinkgoT())
+		manager = mock.NewMockPolicyManager(ctl)
+		subject = HypotheticalSubject{DepartmentPKs: []int64{10}, GroupPKs: []int64{20}}
+		req = &request.Request{
+			System: "test",
+			Resources: []types.Resource{{
+				System: "test",
+			}},
+		}
+
+		patches = gomonkey.ApplyFunc(prp.NewPolicyManager, func() prp.PolicyManager {
+			return manager
+		})
+		patches.ApplyMethod(reflect.TypeOf(req), "ValidateActionResource",
+			func(_ *request.Request) bool {
+				return true
+			})
+		patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+			return nil
+		})
+	})
+	AfterEach(func() {
+		ctl.Finish()
+		patches.Reset()
+	})
+
+	It("fillAndValidateAction fail", func() {
+		patches.ApplyFunc(fillAndValidateAction, func(r *request.Request, entry *debug.Entry) error {
+			return errors.New("fill action fail")
+		})
+
+		_, err := EvalHypothetical("test", subject, []*request.Request{req})
+		assert.Error(GinkgoT(), err)
+		assert.Contains(GinkgoT(), err.Error(), "fill action fail")
+	})
+
+	It("ListBySubjectAction fail", func() {
+		manager.EXPECT().ListBySubjectAction("test", gomock.Any(), gomock.Any(), true, gomock.Any()).
+			Return(nil, errors.New("list fail"))
+
+		_, err := EvalHypothetical("test", subject, []*request.Request{req})
+		assert.Error(GinkgoT(), err)
+		assert.Contains(GinkgoT(), err.Error(), "list fail")
+	})
+
+	It("no policies", func() {
+		manager.EXPECT().ListBySubjectAction("test", gomock.Any(), gomock.Any(), true, gomock.Any()).
+			Return([]types.AuthPolicy{}, nil)
+
+		results, err := EvalHypothetical("test", subject, []*request.Request{req})
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), []bool{false}, results)
+	})
+
+	It("ok", func() {
+		patches.ApplyFunc(evalQueriedPolicies, func(
+			r *request.Request, policies []types.AuthPolicy, entry *debug.Entry,
+		) (bool, error) {
+			return true, nil
+		})
+		manager.EXPECT().ListBySubjectAction("test", gomock.Any(), gomock.Any(), true, gomock.Any()).
+			DoAndReturn(func(
+				system string, s types.Subject, action types.Action, withoutCache bool, entry *debug.Entry,
+			) ([]types.AuthPolicy, error) {
+				assert.Equal(GinkgoT(), "user", s.Type)
+				pk, err := s.Attribute.GetPK()
+				assert.NoError(GinkgoT(), err)
+				assert.Equal(GinkgoT(), int64(0), pk)
+				deptPKs, err := s.GetDepartmentPKs()
+				assert.NoError(GinkgoT(), err)
+				assert.Equal(GinkgoT(), []int64{10}, deptPKs)
+				groupPKs, err := s.GetEffectGroupPKs()
+				assert.NoError(GinkgoT(), err)
+				assert.Equal(GinkgoT(), []int64{20}, groupPKs)
+				return []types.AuthPolicy{{ID: 1}}, nil
+			})
+
+		results, err := EvalHypothetical("test", subject, []*request.Request{req})
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), []bool{true}, results)
+	})
+})
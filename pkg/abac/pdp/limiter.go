@@ -0,0 +1,75 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package pdp
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrTooManyEvaluations 系统的并发鉴权数超过限制
+var ErrTooManyEvaluations = errors.New("too many concurrent evaluations of the system")
+
+// NOTE: 每个系统独立的信号量, 避免单个系统的流量突增耗尽所有系统鉴权共用的DB连接
+var (
+	defaultEvalConcurrencyLimit int
+	systemEvalConcurrencyLimits = map[string]int{}
+
+	evalSemaphores sync.Map // systemID => chan struct{}, nil means no limit
+)
+
+// InitEvalConcurrencyLimits 初始化每个系统的并发鉴权数限制, limit <= 0 表示不限制
+func InitEvalConcurrencyLimits(defaultLimit int, systemLimits map[string]int) {
+	defaultEvalConcurrencyLimit = defaultLimit
+
+	systemEvalConcurrencyLimits = make(map[string]int, len(systemLimits))
+	for systemID, limit := range systemLimits {
+		systemEvalConcurrencyLimits[systemID] = limit
+	}
+
+	evalSemaphores = sync.Map{}
+}
+
+func getEvalSemaphore(system string) chan struct{} {
+	if sem, ok := evalSemaphores.Load(system); ok {
+		return sem.(chan struct{})
+	}
+
+	limit, ok := systemEvalConcurrencyLimits[system]
+	if !ok {
+		limit = defaultEvalConcurrencyLimit
+	}
+
+	var sem chan struct{}
+	if limit > 0 {
+		sem = make(chan struct{}, limit)
+	}
+
+	actual, _ := evalSemaphores.LoadOrStore(system, sem)
+	return actual.(chan struct{})
+}
+
+func noopRelease() {}
+
+// acquireEval 获取系统的鉴权槽位, 没有空闲槽位时直接失败, 不排队等待
+func acquireEval(system string) (release func(), err error) {
+	sem := getEvalSemaphore(system)
+	if sem == nil {
+		return noopRelease, nil
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	default:
+		return nil, ErrTooManyEvaluations
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package pdp
+
+import (
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/types/request"
+)
+
+var _ = Describe("Limiter", func() {
+
+	AfterEach(func() {
+		InitEvalConcurrencyLimits(0, nil)
+	})
+
+	Describe("acquireEval", func() {
+		It("no limit", func() {
+			InitEvalConcurrencyLimits(0, nil)
+
+			for i := 0; i < 10; i++ {
+				_, err := acquireEval("test")
+				assert.NoError(GinkgoT(), err)
+			}
+		})
+
+		It("default limit", func() {
+			InitEvalConcurrencyLimits(1, nil)
+
+			release, err := acquireEval("test")
+			assert.NoError(GinkgoT(), err)
+
+			_, err = acquireEval("test")
+			assert.ErrorIs(GinkgoT(), err, ErrTooManyEvaluations)
+
+			// other systems are not affected
+			_, err = acquireEval("other")
+			assert.NoError(GinkgoT(), err)
+
+			release()
+			_, err = acquireEval("test")
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("system limit", func() {
+			InitEvalConcurrencyLimits(1, map[string]int{"test": 2, "unlimited": 0})
+
+			_, err := acquireEval("test")
+			assert.NoError(GinkgoT(), err)
+			_, err = acquireEval("test")
+			assert.NoError(GinkgoT(), err)
+			_, err = acquireEval("test")
+			assert.ErrorIs(GinkgoT(), err, ErrTooManyEvaluations)
+
+			for i := 0; i < 3; i++ {
+				_, err = acquireEval("unlimited")
+				assert.NoError(GinkgoT(), err)
+			}
+		})
+	})
+
+	Describe("Eval", func() {
+		It("too many evaluations", func() {
+			InitEvalConcurrencyLimits(1, nil)
+			_, err := acquireEval("test")
+			assert.NoError(GinkgoT(), err)
+
+			ok, err := Eval(&request.Request{System: "test"}, nil, false)
+			assert.False(GinkgoT(), ok)
+			assert.ErrorIs(GinkgoT(), err, ErrTooManyEvaluations)
+		})
+	})
+})
@@ -15,9 +15,21 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+
+	"iam/pkg/cache"
+	"iam/pkg/cache/impls"
+	"iam/pkg/cache/memory"
+	"iam/pkg/util"
 )
 
 func TestPdp(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Pdp Suite")
 }
+
+var _ = BeforeSuite(func() {
+	// no disabled actions by default
+	impls.LocalSystemDisabledActionsCache = memory.NewMockCache(func(key cache.Key) (interface{}, error) {
+		return util.NewStringSet(), nil
+	})
+})
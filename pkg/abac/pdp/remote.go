@@ -11,6 +11,12 @@
 package pdp
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
 	"iam/pkg/abac/pdp/condition"
 	"iam/pkg/abac/pip"
 	"iam/pkg/abac/types"
@@ -24,7 +30,7 @@ func fillRemoteResourceAttrs(r *request.Request, policies []types.AuthPolicy) (e
 
 	resources := r.GetRemoteResources()
 	for _, resource := range resources {
-		attrs, err = queryRemoteResourceAttrs(resource, policies)
+		attrs, err = queryRemoteResourceAttrs(r.Context(), resource, policies)
 		if err != nil {
 			err = errorWrapf(err, "queryRemoteResourceAttrs resource=`%+v` fail", resource)
 			return err
@@ -35,6 +41,7 @@ func fillRemoteResourceAttrs(r *request.Request, policies []types.AuthPolicy) (e
 }
 
 func queryRemoteResourceAttrs(
+	ctx context.Context,
 	resource *types.Resource,
 	policies []types.AuthPolicy,
 ) (attrs map[string]interface{}, err error) {
@@ -49,8 +56,11 @@ func queryRemoteResourceAttrs(
 		return
 	}
 
-	// 6. PIP查询依赖resource相关keys的属性
-	attrs, err = pip.QueryRemoteResourceAttribute(resource.System, resource.Type, resource.ID, keys)
+	// 6. PIP查询依赖resource相关keys的属性, 经过资源提供方系统的熔断器
+	err = callRemoteResource(resource.System, func() (callErr error) {
+		attrs, callErr = pip.QueryRemoteResourceAttribute(ctx, resource.System, resource.Type, resource.ID, keys)
+		return callErr
+	})
 	if err != nil {
 		err = errorWrapf(err,
 			"pip.QueryRemoteResourceAttribute system=`%s`, resourceType=`%s`, resourceID=`%s`, keys=`%+v` fail",
@@ -61,6 +71,7 @@ func queryRemoteResourceAttrs(
 }
 
 func queryExtResourceAttrs(
+	ctx context.Context,
 	resource *types.ExtResource,
 	policies []types.AuthPolicy,
 ) (resources []map[string]interface{}, err error) {
@@ -78,8 +89,12 @@ func queryExtResourceAttrs(
 		return
 	}
 
-	// 6. PIP查询依赖resource相关keys的属性
-	resources, err = pip.BatchQueryRemoteResourcesAttribute(resource.System, resource.Type, resource.IDs, keys)
+	// 6. PIP查询依赖resource相关keys的属性, 经过资源提供方系统的熔断器
+	err = callRemoteResource(resource.System, func() (callErr error) {
+		resources, callErr = pip.BatchQueryRemoteResourcesAttribute(
+			ctx, resource.System, resource.Type, resource.IDs, keys)
+		return callErr
+	})
 	if err != nil {
 		err = errorWrapf(err,
 			"pip.BatchQueryRemoteResourcesAttribute system=`%s`, resourceType=`%s`, resourceIDs length=`%d`, keys=`%+v` fail",
@@ -88,3 +103,78 @@ func queryExtResourceAttrs(
 	}
 	return
 }
+
+// extResourceFetchConcurrency 并发查询多个ext resource属性的最大并发数
+const extResourceFetchConcurrency = 4
+
+// batchQueryExtResourceAttrs 并发查询多个ext resource的属性, 结果与extResources一一对应
+// 降级的资源返回只有id属性的结果, degraded为true; 其他的失败汇总后返回
+func batchQueryExtResourceAttrs(
+	ctx context.Context,
+	extResources []types.ExtResource,
+	policies []types.AuthPolicy,
+) (results [][]map[string]interface{}, degraded bool, err error) {
+	results = make([][]map[string]interface{}, len(extResources))
+	errs := make([]error, len(extResources))
+
+	concurrency := extResourceFetchConcurrency
+	if len(extResources) < concurrency {
+		concurrency = len(extResources)
+	}
+
+	// bounded worker pool, 每个worker从队列中取资源的下标
+	indexes := make(chan int, len(extResources))
+	for i := range extResources {
+		indexes <- i
+	}
+	close(indexes)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i], errs[i] = queryExtResourceAttrs(ctx, &extResources[i], policies)
+			}
+		}()
+	}
+	wg.Wait()
+
+	failed := make([]error, 0, len(errs))
+	for i, e := range errs {
+		if e == nil {
+			continue
+		}
+
+		// 第三方资源降级, 返回只有id属性的结果, 不按第三方资源过滤
+		if errors.Is(e, ErrRemoteResourceDegraded) {
+			degraded = true
+			results[i] = make([]map[string]interface{}, 0, len(extResources[i].IDs))
+			for _, id := range extResources[i].IDs {
+				results[i] = append(results[i], map[string]interface{}{"id": id})
+			}
+			continue
+		}
+
+		failed = append(failed, fmt.Errorf("resource=`%+v`: %w", extResources[i], e))
+	}
+
+	if len(failed) > 0 {
+		return nil, degraded, aggregateErrors(failed)
+	}
+	return results, degraded, nil
+}
+
+// aggregateErrors 汇总多个错误, 保留第一个错误用于errors.Is判断
+func aggregateErrors(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		messages = append(messages, e.Error())
+	}
+	return fmt.Errorf("%d errors, first: %w, all: [%s]", len(errs), errs[0], strings.Join(messages, "; "))
+}
@@ -11,7 +11,10 @@
 package pdp
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/agiledragon/gomonkey"
 	"github.com/golang/mock/gomock"
@@ -88,7 +91,7 @@ var _ = Describe("Remote", func() {
 				}},
 			}
 			patches = gomonkey.ApplyFunc(queryRemoteResourceAttrs, func(
-				resource *types.Resource, policies []types.AuthPolicy,
+				ctx context.Context, resource *types.Resource, policies []types.AuthPolicy,
 			) (attrs map[string]interface{}, err error) {
 				return nil, errors.New("query remote remote resource attrs fail")
 			})
@@ -110,7 +113,7 @@ var _ = Describe("Remote", func() {
 				"hello": "world",
 			}
 			patches = gomonkey.ApplyFunc(queryRemoteResourceAttrs, func(
-				resource *types.Resource, policies []types.AuthPolicy,
+				ctx context.Context, resource *types.Resource, policies []types.AuthPolicy,
 			) (attrs map[string]interface{}, err error) {
 				return want, nil
 			})
@@ -133,4 +136,76 @@ var _ = Describe("Remote", func() {
 	Describe("queryExtResourceAttrs", func() {
 
 	})
+
+	Describe("batchQueryExtResourceAttrs", func() {
+		var patches *gomonkey.Patches
+		extResources := []types.ExtResource{
+			{System: "s1", Type: "t1", IDs: []string{"1"}},
+			{System: "s2", Type: "t2", IDs: []string{"2", "3"}},
+			{System: "s3", Type: "t3", IDs: []string{"4"}},
+		}
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("ok, results in order", func() {
+			var mu sync.Mutex
+			called := 0
+			patches = gomonkey.ApplyFunc(queryExtResourceAttrs, func(
+				ctx context.Context, resource *types.ExtResource, policies []types.AuthPolicy,
+			) ([]map[string]interface{}, error) {
+				mu.Lock()
+				called++
+				mu.Unlock()
+				return []map[string]interface{}{{"id": resource.IDs[0], "system": resource.System}}, nil
+			})
+
+			results, degraded, err := batchQueryExtResourceAttrs(
+				context.Background(), extResources, []types.AuthPolicy{{}})
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), degraded)
+			assert.Equal(GinkgoT(), 3, called)
+			assert.Len(GinkgoT(), results, 3)
+			for i, r := range results {
+				assert.Equal(GinkgoT(), extResources[i].System, r[0]["system"])
+			}
+		})
+
+		It("degraded", func() {
+			patches = gomonkey.ApplyFunc(queryExtResourceAttrs, func(
+				ctx context.Context, resource *types.ExtResource, policies []types.AuthPolicy,
+			) ([]map[string]interface{}, error) {
+				if resource.System == "s2" {
+					return nil, fmt.Errorf("%w: timeout", ErrRemoteResourceDegraded)
+				}
+				return []map[string]interface{}{{"id": resource.IDs[0], "level": 1}}, nil
+			})
+
+			results, degraded, err := batchQueryExtResourceAttrs(
+				context.Background(), extResources, []types.AuthPolicy{{}})
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), degraded)
+			assert.Equal(GinkgoT(), []map[string]interface{}{{"id": "2"}, {"id": "3"}}, results[1])
+			assert.Equal(GinkgoT(), 1, results[2][0]["level"])
+		})
+
+		It("aggregate errors", func() {
+			patches = gomonkey.ApplyFunc(queryExtResourceAttrs, func(
+				ctx context.Context, resource *types.ExtResource, policies []types.AuthPolicy,
+			) ([]map[string]interface{}, error) {
+				if resource.System == "s1" {
+					return []map[string]interface{}{{"id": "1"}}, nil
+				}
+				return nil, ErrRemoteResourceCircuitOpen
+			})
+
+			results, _, err := batchQueryExtResourceAttrs(
+				context.Background(), extResources, []types.AuthPolicy{{}})
+			assert.Nil(GinkgoT(), results)
+			assert.ErrorIs(GinkgoT(), err, ErrRemoteResourceCircuitOpen)
+			assert.Contains(GinkgoT(), err.Error(), "2 errors")
+			assert.Contains(GinkgoT(), err.Error(), "s2")
+			assert.Contains(GinkgoT(), err.Error(), "s3")
+		})
+	})
 })
@@ -0,0 +1,93 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/abac/pdp/evaluation"
+	pdptypes "iam/pkg/abac/pdp/types"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/errorx"
+)
+
+// FilterResourcePolicies 计算请求的资源实例满足哪些策略, 用于反查"谁可以访问该资源"
+// NOTE: 策略来自不同的subject, 请求中没有subject, 依赖subject属性的条件视为不满足;
+// 返回的策略中包含deny策略, 由调用方处理
+func FilterResourcePolicies(
+	r *request.Request,
+	policies []types.AuthPolicy,
+) (filteredPolicies []types.AuthPolicy, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "FilterResourcePolicies")
+
+	release, err := acquireEval(r.System)
+	if err != nil {
+		err = errorWrapf(err, "acquireEval system=`%s` fail", r.System)
+		return
+	}
+	defer release()
+
+	// 1. PIP查询action, 并检查请求资源与action关联的类型是否匹配
+	err = fillAndValidateAction(r, nil)
+	if err != nil {
+		if errors.Is(err, ErrInvalidAction) {
+			return
+		}
+
+		err = errorWrapf(err, "fillAndValidateAction action=`%+v` fail", r.Action)
+		return
+	}
+
+	if len(policies) == 0 {
+		return []types.AuthPolicy{}, nil
+	}
+
+	// 2. 查询第三方资源的属性, 降级时只按本地资源计算
+	resources := r.GetSortedResources()
+	if r.HasRemoteResources() {
+		err = fillRemoteResourceAttrs(r, policies)
+		if err != nil {
+			if !errors.Is(err, ErrRemoteResourceDegraded) {
+				err = errorWrapf(err, "fillRemoteResourceAttrs fail", "")
+				return
+			}
+
+			resources = getLocalResources(r)
+			err = nil
+		}
+	}
+
+	// 3. 逐条计算, 策略需要满足请求中的所有资源
+	filteredPolicies = make([]types.AuthPolicy, 0, len(policies))
+	for _, policy := range policies {
+		isPass := true
+		for _, resource := range resources {
+			ctx := pdptypes.NewExprContext(r, resource)
+
+			var err1 error
+			isPass, err1 = evaluation.EvalPolicy(ctx, policy)
+			if err1 != nil {
+				log.Debugf("pdp FilterResourcePolicies EvalPolicy policy: %+v resource: %+v error: %s",
+					policy, resource, err1)
+			}
+			if !isPass {
+				break
+			}
+		}
+
+		if isPass {
+			filteredPolicies = append(filteredPolicies, policy)
+		}
+	}
+	return filteredPolicies, nil
+}
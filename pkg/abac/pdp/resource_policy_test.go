@@ -0,0 +1,106 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"errors"
+
+	"github.com/agiledragon/gomonkey"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/cache/impls"
+	"iam/pkg/cache/memory"
+	"iam/pkg/logging/debug"
+)
+
+var _ = Describe("ResourcePolicy", func() {
+	Describe("FilterResourcePolicies", func() {
+		var patches *gomonkey.Patches
+		var req *request.Request
+		linuxPolicy := types.AuthPolicy{
+			ID:                  1,
+			Expression:          `[{"system": "iam", "type": "job", "expression": {"StringEquals": {"os": ["linux"]}}}]`,
+			ExpressionSignature: "linux",
+		}
+		windowsPolicy := types.AuthPolicy{
+			ID:                  2,
+			Expression:          `[{"system": "iam", "type": "job", "expression": {"StringEquals": {"os": ["windows"]}}}]`,
+			ExpressionSignature: "windows",
+		}
+		denyPolicy := types.AuthPolicy{
+			ID:                  3,
+			Expression:          `[{"system": "iam", "type": "job", "expression": {"StringEquals": {"id": ["1"]}}}]`,
+			ExpressionSignature: "deny",
+			Effect:              "deny",
+		}
+		anyPolicy := types.AuthPolicy{
+			ID:                  4,
+			Expression:          `[{"system": "iam", "type": "job", "expression": {"Any": {"id": []}}}]`,
+			ExpressionSignature: "any",
+		}
+		BeforeEach(func() {
+			impls.LocalUnmarshaledExpressionCache = memory.NewMockCache(impls.UnmarshalExpression)
+
+			req = request.NewRequest()
+			req.System = "iam"
+			req.Action.ID = "edit"
+			req.Action.FillAttributes(1, []types.ActionResourceType{{System: "iam", Type: "job"}})
+			req.Resources = []types.Resource{{
+				System:    "iam",
+				Type:      "job",
+				ID:        "1",
+				Attribute: map[string]interface{}{"os": "linux"},
+			}}
+
+			patches = gomonkey.ApplyFunc(fillAndValidateAction, func(r *request.Request, entry *debug.Entry) error {
+				return nil
+			})
+		})
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("fillAndValidateAction fail", func() {
+			patches.Reset()
+			patches = gomonkey.ApplyFunc(fillAndValidateAction, func(r *request.Request, entry *debug.Entry) error {
+				return ErrInvalidAction
+			})
+
+			_, err := FilterResourcePolicies(req, []types.AuthPolicy{linuxPolicy})
+			assert.ErrorIs(GinkgoT(), err, ErrInvalidAction)
+		})
+
+		It("no policies", func() {
+			policies, err := FilterResourcePolicies(req, nil)
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), policies, 0)
+		})
+
+		It("remote resource fail", func() {
+			patches.ApplyFunc(fillRemoteResourceAttrs, func(r *request.Request, policies []types.AuthPolicy) error {
+				return errors.New("remote fail")
+			})
+			req.System = "test"
+
+			_, err := FilterResourcePolicies(req, []types.AuthPolicy{linuxPolicy})
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("ok", func() {
+			policies, err := FilterResourcePolicies(req,
+				[]types.AuthPolicy{linuxPolicy, windowsPolicy, denyPolicy, anyPolicy})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.AuthPolicy{linuxPolicy, denyPolicy, anyPolicy}, policies)
+		})
+	})
+})
@@ -0,0 +1,94 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"errors"
+	"fmt"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"iam/pkg/abac/pdp/condition"
+	pdptypes "iam/pkg/abac/pdp/types"
+	"iam/pkg/abac/types/request"
+)
+
+// ErrInvalidExpression 沙箱求值的表达式不是合法的json
+var ErrInvalidExpression = errors.New("expression invalid")
+
+// ExpressionEvaluation 沙箱中表达式的求值结果, Allowed表示表达式满足所有资源
+type ExpressionEvaluation struct {
+	Allowed   bool                           `json:"allowed"`
+	Resources []ResourceExpressionEvaluation `json:"resources"`
+}
+
+// ResourceExpressionEvaluation 表达式在单个资源上的求值过程, MatchedNodes为求值结果为true的条件节点
+type ResourceExpressionEvaluation struct {
+	ResourceExplanation
+	MatchedNodes []string `json:"matched_nodes"`
+}
+
+// EvalExpression 沙箱求值: 使用请求中模拟的资源属性对表达式(即policy.Expression)求值
+// 不查询策略/subject/远程资源属性, 不使用表达式缓存, 用于接入系统调试表达式
+func EvalExpression(r *request.Request, expression string) (evaluation ExpressionEvaluation, err error) {
+	expressions := []pdptypes.ResourceExpression{}
+	if err = jsoniter.UnmarshalFromString(expression, &expressions); err != nil {
+		err = fmt.Errorf("%w: json unmarshal fail: %s", ErrInvalidExpression, err)
+		return
+	}
+
+	evaluation.Allowed = true
+	evaluation.Resources = make([]ResourceExpressionEvaluation, 0, len(r.Resources))
+	for i := range r.Resources {
+		resource := &r.Resources[i]
+		re := ResourceExpressionEvaluation{
+			ResourceExplanation: ResourceExplanation{
+				System: resource.System,
+				Type:   resource.Type,
+				ID:     resource.ID,
+			},
+			MatchedNodes: []string{},
+		}
+
+		cond, parseErr := newSandboxCondition(expressions, resource.System, resource.Type)
+		if parseErr != nil {
+			re.Error = parseErr.Error()
+		} else {
+			ce := condition.Explain(cond, pdptypes.NewExprContext(r, resource))
+			re.Matched = ce.Result
+			re.Condition = &ce
+			re.MatchedNodes = condition.MatchedNodes(ce)
+		}
+
+		// 表达式需要满足所有资源
+		if !re.Matched {
+			evaluation.Allowed = false
+		}
+		evaluation.Resources = append(evaluation.Resources, re)
+	}
+	return evaluation, nil
+}
+
+// newSandboxCondition 解析表达式中与资源类型对应的条件, 同condition.ParseResourceConditionFromExpression, 但不经过缓存
+func newSandboxCondition(
+	expressions []pdptypes.ResourceExpression,
+	system, _type string,
+) (condition.Condition, error) {
+	for _, expression := range expressions {
+		if expression.System == system && expression.Type == _type {
+			cond, err := condition.NewConditionFromPolicyCondition(expression.Expression)
+			if err != nil {
+				return nil, fmt.Errorf("expression parser error: %w", err)
+			}
+			return cond, nil
+		}
+	}
+	return nil, fmt.Errorf("resource not match expression")
+}
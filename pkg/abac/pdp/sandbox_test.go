@@ -0,0 +1,80 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+)
+
+var _ = Describe("Sandbox", func() {
+
+	Describe("EvalExpression", func() {
+		var req *request.Request
+		BeforeEach(func() {
+			req = request.NewRequest()
+			req.System = "test"
+			req.Resources = []types.Resource{
+				{System: "test", Type: "app", ID: "a1", Attribute: map[string]interface{}{"level": 5}},
+				{System: "test", Type: "app", ID: "a2", Attribute: map[string]interface{}{"level": 1}},
+			}
+		})
+
+		It("invalid json", func() {
+			_, err := EvalExpression(req, "[")
+			assert.Error(GinkgoT(), err)
+			assert.True(GinkgoT(), errors.Is(err, ErrInvalidExpression))
+		})
+
+		It("matched", func() {
+			e, err := EvalExpression(req, `[{"system": "test", "type": "app", "expression": `+
+				`{"OR": {"content": [{"StringEquals": {"id": ["a2"]}}, {"NumericGt": {"level": [3]}}]}}}]`)
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), e.Allowed)
+			assert.Len(GinkgoT(), e.Resources, 2)
+
+			assert.True(GinkgoT(), e.Resources[0].Matched)
+			assert.Equal(GinkgoT(), []string{"OR", "OR.content[1].NumericGt"}, e.Resources[0].MatchedNodes)
+			assert.True(GinkgoT(), e.Resources[1].Matched)
+			assert.Equal(GinkgoT(), []string{"OR", "OR.content[0].StringEquals"}, e.Resources[1].MatchedNodes)
+
+			e, err = EvalExpression(req, `[{"system": "test", "type": "app", "expression": `+
+				`{"NumericGt": {"level": [3]}}}]`)
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), e.Allowed)
+			assert.True(GinkgoT(), e.Resources[0].Matched)
+			assert.False(GinkgoT(), e.Resources[1].Matched)
+			assert.Equal(GinkgoT(), 1, e.Resources[1].Condition.Attribute)
+			assert.Empty(GinkgoT(), e.Resources[1].MatchedNodes)
+		})
+
+		It("resource not match expression", func() {
+			e, err := EvalExpression(req, `[{"system": "test", "type": "host", "expression": `+
+				`{"Any": {"id": []}}}]`)
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), e.Allowed)
+			assert.Equal(GinkgoT(), "resource not match expression", e.Resources[0].Error)
+			assert.Nil(GinkgoT(), e.Resources[0].Condition)
+		})
+
+		It("invalid operator", func() {
+			e, err := EvalExpression(req, `[{"system": "test", "type": "app", "expression": `+
+				`{"NotExists": {"id": ["a1"]}}}]`)
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), e.Allowed)
+			assert.Contains(GinkgoT(), e.Resources[0].Error, "expression parser error")
+		})
+	})
+})
@@ -0,0 +1,114 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package pdp
+
+import (
+	"fmt"
+	"sort"
+
+	"iam/pkg/abac/pip"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+)
+
+// 资源属性schema中支持的属性类型
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeNumeric = "numeric"
+	AttributeTypeBool    = "bool"
+)
+
+// 内置的资源属性, 不需要注册schema
+var builtinResourceAttrs = map[string]struct{}{
+	"id":            {},
+	"_bk_iam_path_": {},
+}
+
+// validateResourceAttrs 根据接入系统注册的资源属性schema, 校验请求中资源的属性, 返回不匹配的信息
+// NOTE: 只用于debug, 不影响鉴权结果; 系统没有注册schema的资源类型不校验
+func validateResourceAttrs(r *request.Request) (mismatches []string, err error) {
+	for _, resource := range r.Resources {
+		if len(resource.Attribute) == 0 {
+			continue
+		}
+
+		var schema map[string]string
+		schema, err = pip.GetResourceAttributeSchema(resource.System, resource.Type)
+		if err != nil {
+			return nil, err
+		}
+		if len(schema) == 0 {
+			continue
+		}
+
+		mismatches = append(mismatches, validateAttribute(resource, schema)...)
+	}
+	return mismatches, nil
+}
+
+func validateAttribute(resource types.Resource, schema map[string]string) []string {
+	// sort the keys, make the debug output stable
+	keys := make([]string, 0, len(resource.Attribute))
+	for key := range resource.Attribute {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	mismatches := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := builtinResourceAttrs[key]; ok {
+			continue
+		}
+
+		_type, ok := schema[key]
+		if !ok {
+			mismatches = append(mismatches, fmt.Sprintf("resource `%s:%s` attribute `%s` not registered in schema",
+				resource.System, resource.Type, key))
+			continue
+		}
+
+		value := resource.Attribute[key]
+		if !isAttributeValueOfType(value, _type) {
+			mismatches = append(mismatches, fmt.Sprintf(
+				"resource `%s:%s` attribute `%s` should be `%s`, got `%T`(%v)",
+				resource.System, resource.Type, key, _type, value, value))
+		}
+	}
+	return mismatches
+}
+
+// isAttributeValueOfType 属性值可以是单值, 也可以是同类型值的列表
+func isAttributeValueOfType(value interface{}, _type string) bool {
+	if values, ok := value.([]interface{}); ok {
+		for _, v := range values {
+			if !isAttributeValueOfType(v, _type) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch _type {
+	case AttributeTypeString:
+		_, ok := value.(string)
+		return ok
+	case AttributeTypeNumeric:
+		switch value.(type) {
+		case int, int32, int64, uint, uint32, uint64, float32, float64:
+			return true
+		}
+		return false
+	case AttributeTypeBool:
+		_, ok := value.(bool)
+		return ok
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package pdp
+
+import (
+	"errors"
+
+	"github.com/agiledragon/gomonkey"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/pip"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+)
+
+var _ = Describe("Schema", func() {
+
+	Describe("validateResourceAttrs", func() {
+		var req *request.Request
+		var patches *gomonkey.Patches
+		BeforeEach(func() {
+			req = &request.Request{
+				System: "test",
+				Resources: []types.Resource{{
+					System: "test",
+					Type:   "host",
+					ID:     "1",
+					Attribute: map[string]interface{}{
+						"id":            "1",
+						"_bk_iam_path_": []interface{}{"/biz,1/"},
+						"owner":         "admin",
+						"cpu":           4,
+						"os":            []interface{}{"linux", 1},
+						"unknown":       true,
+					},
+				}},
+			}
+			patches = gomonkey.NewPatches()
+		})
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("get schema fail", func() {
+			patches.ApplyFunc(pip.GetResourceAttributeSchema, func(system, _type string) (map[string]string, error) {
+				return nil, errors.New("get schema fail")
+			})
+
+			_, err := validateResourceAttrs(req)
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("no schema", func() {
+			patches.ApplyFunc(pip.GetResourceAttributeSchema, func(system, _type string) (map[string]string, error) {
+				return nil, nil
+			})
+
+			mismatches, err := validateResourceAttrs(req)
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), mismatches)
+		})
+
+		It("ok", func() {
+			patches.ApplyFunc(pip.GetResourceAttributeSchema, func(system, _type string) (map[string]string, error) {
+				return map[string]string{
+					"owner": "string",
+					"cpu":   "numeric",
+					"os":    "string",
+				}, nil
+			})
+
+			mismatches, err := validateResourceAttrs(req)
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), mismatches, 2)
+			assert.Contains(GinkgoT(), mismatches[0], "attribute `os` should be `string`")
+			assert.Contains(GinkgoT(), mismatches[1], "attribute `unknown` not registered")
+		})
+	})
+
+	Describe("isAttributeValueOfType", func() {
+		It("string", func() {
+			assert.True(GinkgoT(), isAttributeValueOfType("a", AttributeTypeString))
+			assert.True(GinkgoT(), isAttributeValueOfType([]interface{}{"a", "b"}, AttributeTypeString))
+			assert.False(GinkgoT(), isAttributeValueOfType(1, AttributeTypeString))
+		})
+
+		It("numeric", func() {
+			assert.True(GinkgoT(), isAttributeValueOfType(1, AttributeTypeNumeric))
+			assert.True(GinkgoT(), isAttributeValueOfType(1.5, AttributeTypeNumeric))
+			assert.False(GinkgoT(), isAttributeValueOfType("1", AttributeTypeNumeric))
+		})
+
+		It("bool", func() {
+			assert.True(GinkgoT(), isAttributeValueOfType(true, AttributeTypeBool))
+			assert.False(GinkgoT(), isAttributeValueOfType("true", AttributeTypeBool))
+		})
+
+		It("unknown type", func() {
+			assert.False(GinkgoT(), isAttributeValueOfType("a", "datetime"))
+		})
+	})
+})
@@ -0,0 +1,127 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"errors"
+
+	"iam/pkg/abac/prp"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/errorx"
+)
+
+// SimulationResult 鉴权请求在策略变更前后的结果
+type SimulationResult struct {
+	Before  bool `json:"before"`
+	After   bool `json:"after"`
+	Changed bool `json:"changed"`
+}
+
+// Simulate 模拟策略变更后的鉴权结果
+// NOTE: 策略只从DB查询, 变更只叠加在内存中, 不会写DB, 也不会读写策略缓存
+func Simulate(
+	system string,
+	overlay *prp.PolicyOverlay,
+	reqs []*request.Request,
+) (results []SimulationResult, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "Simulate")
+
+	release, err := acquireEval(system)
+	if err != nil {
+		err = errorWrapf(err, "acquireEval system=`%s` fail", system)
+		return
+	}
+	defer release()
+
+	manager := prp.NewPolicyManager()
+
+	results = make([]SimulationResult, 0, len(reqs))
+	for _, r := range reqs {
+		var result SimulationResult
+		result, err = simulate(manager, overlay, r)
+		if err != nil {
+			if errors.Is(err, ErrInvalidAction) {
+				return
+			}
+
+			err = errorWrapf(err, "simulate request=`%+v` fail", r)
+			return
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func simulate(
+	manager prp.PolicyManager,
+	overlay *prp.PolicyOverlay,
+	r *request.Request,
+) (result SimulationResult, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PDP, "simulate")
+
+	// 1. PIP查询action, 并检查请求资源与action关联的类型是否匹配
+	err = fillAndValidateAction(r, nil)
+	if err != nil {
+		if errors.Is(err, ErrInvalidAction) {
+			return
+		}
+
+		err = errorWrapf(err, "fillAndValidateAction action=`%+v` fail", r.Action)
+		return
+	}
+
+	// 2. PIP查询subject相关的属性
+	err = fillSubjectDetail(r)
+	if err != nil {
+		// 用户不存在或被冻结, 变更前后都没有权限
+		if isSubjectInactive(err) {
+			return result, nil
+		}
+
+		err = errorWrapf(err, "request fillSubjectDetail subject=`%+v`", r.Subject)
+		return
+	}
+
+	// 3. 分别查询变更前后的策略并计算
+	before, err := manager.ListBySubjectAction(r.System, r.Subject, r.Action, true, nil)
+	if err != nil {
+		err = errorWrapf(err, "ListBySubjectAction system=`%s`, subject=`%+v`, action=`%+v` fail",
+			r.System, r.Subject, r.Action)
+		return
+	}
+	result.Before, err = evalSimulatedPolicies(r, before)
+	if err != nil {
+		err = errorWrapf(err, "evalSimulatedPolicies before policies=`%+v` fail", before)
+		return
+	}
+
+	after, err := manager.ListBySubjectActionWithOverlay(r.System, r.Subject, r.Action, overlay, nil)
+	if err != nil {
+		err = errorWrapf(err, "ListBySubjectActionWithOverlay system=`%s`, subject=`%+v`, action=`%+v` fail",
+			r.System, r.Subject, r.Action)
+		return
+	}
+	result.After, err = evalSimulatedPolicies(r, after)
+	if err != nil {
+		err = errorWrapf(err, "evalSimulatedPolicies after policies=`%+v` fail", after)
+		return
+	}
+
+	result.Changed = result.Before != result.After
+	return result, nil
+}
+
+func evalSimulatedPolicies(r *request.Request, policies []types.AuthPolicy) (bool, error) {
+	if len(policies) == 0 {
+		return false, nil
+	}
+	return evalQueriedPolicies(r, policies, nil)
+}
@@ -0,0 +1,104 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/prp"
+	"iam/pkg/abac/prp/mock"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/logging/debug"
+)
+
+var _ = Describe("Simulate", func() {
+	var ctl *gomock.Controller
+	var manager *mock.MockPolicyManager
+	var patches *gomonkey.Patches
+	var req *request.Request
+	var overlay *prp.PolicyOverlay
+	BeforeEach(func() {
+		ctl = gomock.NewController(GinkgoT())
+		manager = mock.NewMockPolicyManager(ctl)
+		overlay = &prp.PolicyOverlay{}
+		req = &request.Request{
+			System: "test",
+			Resources: []types.Resource{{
+				System: "test",
+			}},
+		}
+
+		patches = gomonkey.ApplyFunc(prp.NewPolicyManager, func() prp.PolicyManager {
+			return manager
+		})
+		patches.ApplyMethod(reflect.TypeOf(req), "ValidateActionResource",
+			func(_ *request.Request) bool {
+				return true
+			})
+		patches.ApplyFunc(fillActionDetail, func(req *request.Request) error {
+			return nil
+		})
+	})
+	AfterEach(func() {
+		ctl.Finish()
+		patches.Reset()
+	})
+
+	It("subject not exists", func() {
+		patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+			return sql.ErrNoRows
+		})
+
+		results, err := Simulate("test", overlay, []*request.Request{req})
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), []SimulationResult{{}}, results)
+	})
+
+	It("ListBySubjectActionWithOverlay fail", func() {
+		patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+			return nil
+		})
+		manager.EXPECT().ListBySubjectAction("test", gomock.Any(), gomock.Any(), true, gomock.Any()).
+			Return([]types.AuthPolicy{}, nil)
+		manager.EXPECT().ListBySubjectActionWithOverlay("test", gomock.Any(), gomock.Any(), overlay, gomock.Any()).
+			Return(nil, errors.New("list fail"))
+
+		_, err := Simulate("test", overlay, []*request.Request{req})
+		assert.Error(GinkgoT(), err)
+		assert.Contains(GinkgoT(), err.Error(), "list fail")
+	})
+
+	It("ok, changed", func() {
+		patches.ApplyFunc(fillSubjectDetail, func(req *request.Request) error {
+			return nil
+		})
+		patches.ApplyFunc(evalQueriedPolicies, func(
+			r *request.Request, policies []types.AuthPolicy, entry *debug.Entry,
+		) (bool, error) {
+			return true, nil
+		})
+		manager.EXPECT().ListBySubjectAction("test", gomock.Any(), gomock.Any(), true, gomock.Any()).
+			Return([]types.AuthPolicy{}, nil)
+		manager.EXPECT().ListBySubjectActionWithOverlay("test", gomock.Any(), gomock.Any(), overlay, gomock.Any()).
+			Return([]types.AuthPolicy{{ID: -1}}, nil)
+
+		results, err := Simulate("test", overlay, []*request.Request{req})
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), []SimulationResult{{Before: false, After: true, Changed: true}}, results)
+	})
+})
@@ -0,0 +1,33 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package pdp
+
+import (
+	pdptypes "iam/pkg/abac/pdp/types"
+	"iam/pkg/abac/pip"
+	"iam/pkg/abac/types"
+)
+
+// pipSubjectAttrRetriever 通过pip查询subject的用户组/部门, 供条件中的subject.groups/subject.departments求值
+type pipSubjectAttrRetriever struct{}
+
+// ListDepartmentEffectGroups ...
+func (pipSubjectAttrRetriever) ListDepartmentEffectGroups(deptPKs []int64) ([]types.SubjectGroup, error) {
+	return pip.ListDepartmentEffectGroups(deptPKs)
+}
+
+// ListSubjectIDsByPKs ...
+func (pipSubjectAttrRetriever) ListSubjectIDsByPKs(pks []int64) ([]string, error) {
+	return pip.ListSubjectIDsByPKs(pks)
+}
+
+func init() {
+	pdptypes.RegisterSubjectAttrRetriever(pipSubjectAttrRetriever{})
+}
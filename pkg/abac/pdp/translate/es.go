@@ -0,0 +1,149 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package translate
+
+import (
+	"errors"
+	"fmt"
+
+	"iam/pkg/abac/pdp/util"
+	"iam/pkg/errorx"
+)
+
+/*
+表达式转换为Elasticsearch bool query
+
+将策略转换后的条件表达式(PoliciesTranslate的结果)转换为Elasticsearch query DSL, 供使用ES存储资源的接入系统直接作为查询条件:
+	{"op": "in", "field": "host.id", "value": ["1", "2"]}  => {"terms": {"host.id": ["1", "2"]}}
+
+支持 eq/in/starts_with/any 及其取反, AND/OR/not 转换为bool query的 filter/should/must_not
+表达式中的字段默认原样作为ES的字段, 可以由调用方提供映射, 例如 {"host.id": "id"}
+*/
+
+// ErrESUnsupportedOperator 操作符无法转换为ES query, 例如环境属性/比较相关的操作符
+var ErrESUnsupportedOperator = errors.New("operator not supported in es query")
+
+// ESQuery Elasticsearch query DSL
+type ESQuery map[string]interface{}
+
+// PoliciesTranslateToES 策略条件表达式转换为Elasticsearch bool query
+// 空表达式表示没有权限, 转换为不匹配任何文档的query; any转换为match_all
+func PoliciesTranslateToES(expr map[string]interface{}, fieldMapping map[string]string) (ESQuery, error) {
+	if len(expr) == 0 {
+		return esMustNot(ESQuery{"match_all": map[string]interface{}{}}), nil
+	}
+
+	t := esTranslator{fieldMapping: fieldMapping}
+	query, err := t.translate(expr)
+	if err != nil {
+		return nil, errorx.Wrapf(err, Translate, "PoliciesTranslateToES", "translate expr=`%+v` fail", expr)
+	}
+	return query, nil
+}
+
+type esTranslator struct {
+	fieldMapping map[string]string
+}
+
+func (t *esTranslator) translate(expr ExprCell) (ESQuery, error) {
+	op, ok := expr["op"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid expression %+v, op required", expr)
+	}
+
+	switch op {
+	case "any":
+		return ESQuery{"match_all": map[string]interface{}{}}, nil
+	case "AND", "OR", "not":
+		cells, err := exprCellContent(expr)
+		if err != nil {
+			return nil, err
+		}
+		if len(cells) == 0 {
+			return nil, fmt.Errorf("invalid expression %+v, content must not be empty", expr)
+		}
+		if op == "not" && len(cells) != 1 {
+			return nil, fmt.Errorf("invalid not expression content %+v", expr["content"])
+		}
+
+		queries := make([]ESQuery, 0, len(cells))
+		for _, cell := range cells {
+			query, err := t.translate(cell)
+			if err != nil {
+				return nil, err
+			}
+			queries = append(queries, query)
+		}
+
+		switch op {
+		case "AND":
+			return ESQuery{"bool": map[string]interface{}{"filter": queries}}, nil
+		case "OR":
+			return ESQuery{"bool": map[string]interface{}{"should": queries, "minimum_should_match": 1}}, nil
+		default:
+			return esMustNot(queries[0]), nil
+		}
+	}
+
+	field, err := t.field(expr["field"])
+	if err != nil {
+		return nil, err
+	}
+	value := expr["value"]
+
+	var query ESQuery
+	switch op {
+	case "eq", "not_eq":
+		query = ESQuery{"term": map[string]interface{}{field: value}}
+	case "in", "not_in":
+		values, err := toValueSlice(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("invalid expression %+v, %w", expr, errMustNotEmpty)
+		}
+		query = ESQuery{"terms": map[string]interface{}{field: values}}
+	case "starts_with", "not_starts_with":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid expression %+v, value should be string", expr)
+		}
+		query = ESQuery{"prefix": map[string]interface{}{field: s}}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrESUnsupportedOperator, op)
+	}
+
+	if op == "not_eq" || op == "not_in" || op == "not_starts_with" {
+		return esMustNot(query), nil
+	}
+	return query, nil
+}
+
+// field 字段映射为ES的字段, 没有映射时原样返回; 属性值转换函数(例如lower)无法转换
+func (t *esTranslator) field(f interface{}) (string, error) {
+	field, ok := f.(string)
+	if !ok || field == "" {
+		return "", fmt.Errorf("invalid field %+v", f)
+	}
+
+	if _, ok := util.ParseAttrTransform(field); ok {
+		return "", fmt.Errorf("%w: transform field %s", ErrESUnsupportedOperator, field)
+	}
+
+	if mapped, ok := t.fieldMapping[field]; ok && mapped != "" {
+		return mapped, nil
+	}
+	return field, nil
+}
+
+func esMustNot(query ESQuery) ESQuery {
+	return ESQuery{"bool": map[string]interface{}{"must_not": []ESQuery{query}}}
+}
@@ -0,0 +1,133 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package translate
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/types"
+)
+
+var _ = Describe("ES", func() {
+	Describe("PoliciesTranslateToES", func() {
+		matchAll := ESQuery{"match_all": map[string]interface{}{}}
+
+		It("empty, no permission", func() {
+			query, err := PoliciesTranslateToES(map[string]interface{}{}, nil)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ESQuery{"bool": map[string]interface{}{"must_not": []ESQuery{matchAll}}}, query)
+		})
+
+		It("any", func() {
+			query, err := PoliciesTranslateToES(map[string]interface{}{
+				"op":    "any",
+				"field": "",
+				"value": []string{},
+			}, nil)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), matchAll, query)
+		})
+
+		It("eq with field mapping", func() {
+			query, err := PoliciesTranslateToES(map[string]interface{}{
+				"op":    "eq",
+				"field": "host.id",
+				"value": "1",
+			}, map[string]string{"host.id": "id"})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ESQuery{"term": map[string]interface{}{"id": "1"}}, query)
+		})
+
+		It("not_in", func() {
+			query, err := PoliciesTranslateToES(map[string]interface{}{
+				"op":    "not_in",
+				"field": "host.name",
+				"value": []interface{}{"a", "b"},
+			}, nil)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ESQuery{"bool": map[string]interface{}{"must_not": []ESQuery{
+				{"terms": map[string]interface{}{"host.name": []interface{}{"a", "b"}}},
+			}}}, query)
+		})
+
+		It("nested AND/OR/not", func() {
+			query, err := PoliciesTranslateToES(map[string]interface{}{
+				"op": "AND",
+				"content": []ExprCell{
+					{
+						"op": "OR",
+						"content": []interface{}{
+							map[string]interface{}{
+								"op": "starts_with", "field": "host._bk_iam_path_", "value": "/biz,1/",
+							},
+							map[string]interface{}{"op": "in", "field": "host.os", "value": []string{"linux"}},
+						},
+					},
+					{
+						"op":      "not",
+						"content": []interface{}{ExprCell{"op": "eq", "field": "host.id", "value": "2"}},
+					},
+				},
+			}, nil)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ESQuery{"bool": map[string]interface{}{"filter": []ESQuery{
+				{"bool": map[string]interface{}{
+					"should": []ESQuery{
+						{"prefix": map[string]interface{}{"host._bk_iam_path_": "/biz,1/"}},
+						{"terms": map[string]interface{}{"host.os": []interface{}{"linux"}}},
+					},
+					"minimum_should_match": 1,
+				}},
+				{"bool": map[string]interface{}{"must_not": []ESQuery{
+					{"term": map[string]interface{}{"host.id": "2"}},
+				}}},
+			}}}, query)
+		})
+
+		It("unsupported operator", func() {
+			_, err := PoliciesTranslateToES(map[string]interface{}{
+				"op":    "gte",
+				"field": "host.cpu",
+				"value": 4,
+			}, nil)
+			assert.Error(GinkgoT(), err)
+			assert.True(GinkgoT(), errors.Is(err, ErrESUnsupportedOperator))
+		})
+
+		It("unsupported transform field", func() {
+			_, err := PoliciesTranslateToES(map[string]interface{}{
+				"op":    "eq",
+				"field": "lower(host.name)",
+				"value": "test",
+			}, nil)
+			assert.Error(GinkgoT(), err)
+			assert.True(GinkgoT(), errors.Is(err, ErrESUnsupportedOperator))
+		})
+
+		It("translated policies", func() {
+			expr, err := PoliciesTranslate(
+				[]types.AuthPolicy{{
+					ID: 1,
+					Expression: `[{"system": "bk_cmdb", "type": "host", ` +
+						`"expression": {"StringEquals": {"id": ["1", "2"]}}}]`,
+				}},
+				[]types.ActionResourceType{{System: "bk_cmdb", Type: "host"}},
+			)
+			assert.NoError(GinkgoT(), err)
+
+			query, err := PoliciesTranslateToES(expr, nil)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ESQuery{"terms": map[string]interface{}{"host.id": []interface{}{"1", "2"}}}, query)
+		})
+	})
+})
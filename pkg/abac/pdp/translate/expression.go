@@ -11,6 +11,8 @@
 package translate
 
 import (
+	"fmt"
+
 	jsoniter "github.com/json-iterator/go"
 
 	pdptypes "iam/pkg/abac/pdp/types"
@@ -22,13 +24,89 @@ import (
 // Translate ...
 const Translate = "Translate"
 
-// PoliciesTranslate 策略列表转换为QL表达式
+// PoliciesTranslate 策略列表转换为QL表达式, 并对结果做规范化, 相同的策略总是得到相同的表达式
 func PoliciesTranslate(
 	policies []types.AuthPolicy,
 	resourceTypes []types.ActionResourceType,
 ) (map[string]interface{}, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(Translate, "PoliciesTranslate")
 
+	expr, err := policiesTranslate(policies, resourceTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized, err := normalizeExprCell(expr)
+	if err != nil {
+		return nil, errorWrapf(err, "normalizeExprCell expr=`%+v` fail", expr)
+	}
+	return normalized, nil
+}
+
+// PrioritizedPoliciesTranslate first_match求值模式的策略转换, policies需要已按优先级从高到低排序
+// 每条allow策略只在优先级比它高的deny策略都不命中的资源上生效: (allow1 AND NOT deny0) OR (allow2 AND NOT deny0) ...
+func PrioritizedPoliciesTranslate(
+	policies []types.AuthPolicy,
+	resourceTypes []types.ActionResourceType,
+) (map[string]interface{}, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(Translate, "PrioritizedPoliciesTranslate")
+
+	content := make([]ExprCell, 0, len(policies))
+	denyPolicies := make([]types.AuthPolicy, 0, 2)
+	for _, policy := range policies {
+		if policy.IsDeny() {
+			denyPolicies = append(denyPolicies, policy)
+			continue
+		}
+
+		// 与优先级更高的deny策略按deny优先组合
+		higherPolicies := make([]types.AuthPolicy, 0, len(denyPolicies)+1)
+		higherPolicies = append(higherPolicies, policy)
+		higherPolicies = append(higherPolicies, denyPolicies...)
+		expr, err := policiesTranslate(higherPolicies, resourceTypes)
+		if err != nil {
+			return nil, errorWrapf(err, "policiesTranslate policies=`%+v` fail", higherPolicies)
+		}
+
+		// 优先级更高的deny策略命中所有资源, 之后的allow策略都不会生效
+		if len(expr) == 0 {
+			break
+		}
+		// allow策略命中所有资源, 之后的策略都不需要再求值
+		if expr.Op() == "any" {
+			content = []ExprCell{expr}
+			break
+		}
+		content = append(content, expr)
+	}
+
+	var expr ExprCell
+	switch len(content) {
+	case 0:
+		expr = ExprCell{}
+	case 1:
+		expr = content[0]
+	default:
+		expr = ExprCell{
+			"op":      "OR",
+			"content": content,
+		}
+	}
+
+	normalized, err := normalizeExprCell(expr)
+	if err != nil {
+		return nil, errorWrapf(err, "normalizeExprCell expr=`%+v` fail", expr)
+	}
+	return normalized, nil
+}
+
+// policiesTranslate allow策略组合成 OR 关系表达式, deny策略取反后与其组合成 AND 关系表达式
+func policiesTranslate(
+	policies []types.AuthPolicy,
+	resourceTypes []types.ActionResourceType,
+) (ExprCell, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(Translate, "PoliciesTranslate")
+
 	// make a resourceTypeSet
 	resourceTypeSet := util.NewFixedLengthStringSet(len(resourceTypes))
 	for _, rt := range resourceTypes {
@@ -36,6 +114,66 @@ func PoliciesTranslate(
 		resourceTypeSet.Add(key)
 	}
 
+	allowPolicies := make([]types.AuthPolicy, 0, len(policies))
+	denyPolicies := make([]types.AuthPolicy, 0, 2)
+	for _, policy := range policies {
+		if policy.IsDeny() {
+			denyPolicies = append(denyPolicies, policy)
+		} else {
+			allowPolicies = append(allowPolicies, policy)
+		}
+	}
+
+	policiesCondition, err := allowPoliciesTranslate(allowPolicies, resourceTypeSet)
+	if err != nil {
+		return nil, errorWrapf(err, "allowPoliciesTranslate policies=`%+v` fail", allowPolicies)
+	}
+
+	if len(denyPolicies) == 0 {
+		return policiesCondition, nil
+	}
+
+	// allow为any时, 不需要再与deny的取反条件组合
+	content := make([]ExprCell, 0, len(denyPolicies)+1)
+	if policiesCondition.Op() != "any" {
+		content = append(content, policiesCondition)
+	}
+
+	for _, policy := range denyPolicies {
+		condition, err := PolicyTranslate(policy.Expression, resourceTypeSet)
+		if err != nil {
+			err = errorWrapf(err, "PolicyTranslate deny policyID=`%d` expression=`%s` resourceType=`%+v`",
+				policy.ID, policy.Expression, resourceTypeSet)
+			return nil, err
+		}
+
+		negated, err := negateExprCell(condition)
+		if err != nil {
+			err = errorWrapf(err, "negateExprCell deny policyID=`%d` condition=`%+v`", policy.ID, condition)
+			return nil, err
+		}
+
+		// deny策略命中所有资源, 没有权限
+		if negated == nil {
+			return ExprCell{}, nil
+		}
+		content = append(content, negated)
+	}
+
+	switch len(content) {
+	case 1:
+		return content[0], nil
+	default:
+		return ExprCell{
+			"op":      "AND",
+			"content": content,
+		}, nil
+	}
+}
+
+func allowPoliciesTranslate(policies []types.AuthPolicy, resourceTypeSet *util.StringSet) (ExprCell, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(Translate, "allowPoliciesTranslate")
+
 	// 对每一条policy转换成一个条件表达式, 再组合成一个 OR 关系表达式
 	content := make([]ExprCell, 0, len(policies))
 	for _, policy := range policies {
@@ -120,8 +258,11 @@ func PolicyTranslate(
 	}
 }
 
+// mergeContentField 合并OR条件中field相同, op为eq, in的条件, 合并后的值去重
+// NOTE: 按field首次出现的顺序输出, 保证相同的输入得到相同的输出
 func mergeContentField(content []ExprCell) []ExprCell {
 	mergeableExprs := map[string][]ExprCell{}
+	fields := make([]string, 0, len(content))
 	newContent := make([]ExprCell, 0, len(content))
 
 	for _, expr := range content {
@@ -134,25 +275,40 @@ func mergeContentField(content []ExprCell) []ExprCell {
 				mergeableExprs[field] = exprs
 			} else {
 				mergeableExprs[field] = []ExprCell{expr}
+				fields = append(fields, field)
 			}
 		default:
 			newContent = append(newContent, expr)
 		}
 	}
 
-	for field, exprs := range mergeableExprs {
+	for _, field := range fields {
+		exprs := mergeableExprs[field]
 		if len(exprs) == 1 {
 			newContent = append(newContent, exprs[0])
 		} else {
 			values := make([]interface{}, 0, len(exprs))
+			seen := make(map[string]struct{}, len(exprs))
 
 			// 合并
 			for _, expr := range exprs {
+				var exprValues []interface{}
 				switch expr.Op() {
 				case "eq":
-					values = append(values, expr["value"])
+					exprValues = []interface{}{expr["value"]}
 				case "in":
-					values = append(values, expr["value"].([]interface{})...)
+					exprValues = expr["value"].([]interface{})
+				}
+
+				for _, v := range exprValues {
+					key, err := canonicalKey(v)
+					if err == nil {
+						if _, ok := seen[key]; ok {
+							continue
+						}
+						seen[key] = struct{}{}
+					}
+					values = append(values, v)
 				}
 			}
 
@@ -166,3 +322,138 @@ func mergeContentField(content []ExprCell) []ExprCell {
 
 	return newContent
 }
+
+// negatedOperators 用于deny策略的取反, 只包含有直接取反操作符的操作符, 其他操作符取反时使用not包裹
+var negatedOperators = map[string]string{
+	"eq":          "not_eq",
+	"not_eq":      "eq",
+	"in":          "not_in",
+	"not_in":      "in",
+	"starts_with": "not_starts_with",
+	"wildcard":    "not_wildcard",
+	"regex":       "not_regex",
+	"gt":          "lte",
+	"lte":         "gt",
+	"gte":         "lt",
+	"lt":          "gte",
+}
+
+// negateExprCell 对表达式取反, 返回nil表示取反后不匹配任何资源(原表达式为any)
+func negateExprCell(expr ExprCell) (ExprCell, error) {
+	op := expr.Op()
+	switch op {
+	case "any":
+		return nil, nil
+	case "AND":
+		// NOT (a AND b) => (NOT a) OR (NOT b), 取反为nil的项可以忽略
+		cells, err := exprCellContent(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		content := make([]ExprCell, 0, len(cells))
+		for _, cell := range cells {
+			negated, err := negateExprCell(cell)
+			if err != nil {
+				return nil, err
+			}
+			if negated != nil {
+				content = append(content, negated)
+			}
+		}
+
+		switch len(content) {
+		case 0:
+			return nil, nil
+		case 1:
+			return content[0], nil
+		default:
+			return ExprCell{
+				"op":      "OR",
+				"content": content,
+			}, nil
+		}
+	case "OR":
+		// NOT (a OR b) => (NOT a) AND (NOT b), 任意一项取反为nil则整体为nil
+		cells, err := exprCellContent(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		content := make([]ExprCell, 0, len(cells))
+		for _, cell := range cells {
+			negated, err := negateExprCell(cell)
+			if err != nil {
+				return nil, err
+			}
+			if negated == nil {
+				return nil, nil
+			}
+			content = append(content, negated)
+		}
+
+		switch len(content) {
+		case 1:
+			return content[0], nil
+		default:
+			return ExprCell{
+				"op":      "AND",
+				"content": content,
+			}, nil
+		}
+	case "not":
+		// NOT (NOT a) => a
+		cells, err := exprCellContent(expr)
+		if err != nil {
+			return nil, err
+		}
+		if len(cells) != 1 {
+			return nil, fmt.Errorf("invalid not expression content %+v", expr["content"])
+		}
+		return cells[0], nil
+	default:
+		negatedOp, ok := negatedOperators[op]
+		if !ok {
+			// 没有直接取反的操作符, 使用not包裹
+			return ExprCell{
+				"op":      "not",
+				"content": []ExprCell{expr},
+			}, nil
+		}
+
+		return ExprCell{
+			"op":    negatedOp,
+			"field": expr["field"],
+			"value": expr["value"],
+		}, nil
+	}
+}
+
+// exprCellContent 获取AND/OR/not表达式的content, 兼容translate生成的不同类型
+func exprCellContent(expr ExprCell) ([]ExprCell, error) {
+	switch content := expr["content"].(type) {
+	case []ExprCell:
+		return content, nil
+	case []map[string]interface{}:
+		cells := make([]ExprCell, 0, len(content))
+		for _, c := range content {
+			cells = append(cells, c)
+		}
+		return cells, nil
+	case []interface{}:
+		cells := make([]ExprCell, 0, len(content))
+		for _, c := range content {
+			switch cell := c.(type) {
+			case ExprCell:
+				cells = append(cells, cell)
+			case map[string]interface{}:
+				cells = append(cells, cell)
+			default:
+				return nil, fmt.Errorf("invalid expression content %+v", c)
+			}
+		}
+		return cells, nil
+	default:
+		return nil, fmt.Errorf("invalid expression content %+v", expr["content"])
+	}
+}
@@ -106,17 +106,9 @@ var _ = Describe("Expression", func() {
 					{"field": "job.name", "op": "eq", "value": "def"},
 				},
 			}
-			want2 := map[string]interface{}{
-				"op": "OR",
-				"content": []ExprCell{
-					{"field": "job.name", "op": "eq", "value": "def"},
-					{"field": "job.id", "op": "eq", "value": "abc"},
-				},
-			}
 			ec, err := PoliciesTranslate(policies, resourceTypeSet)
 			assert.NoError(GinkgoT(), err)
-			//assert.EqualValues(GinkgoT(), want, ec)
-			assert.True(GinkgoT(), assert.ObjectsAreEqualValues(want, ec) || assert.ObjectsAreEqualValues(want2, ec))
+			assert.EqualValues(GinkgoT(), want, ec)
 		})
 
 		It("ok, two resource", func() {
@@ -141,18 +133,8 @@ var _ = Describe("Expression", func() {
 			want := map[string]interface{}{
 				"op": "AND",
 				"content": []ExprCell{
-					{
-						"op": "OR",
-						"content": []interface{}{
-							ExprCell{"field": "job.id", "op": "any", "value": []interface{}{}},
-						},
-					},
-					{
-						"op": "OR",
-						"content": []interface{}{
-							ExprCell{"field": "host.id", "op": "any", "value": []interface{}{}},
-						},
-					},
+					{"field": "host.id", "op": "any", "value": []interface{}{}},
+					{"field": "job.id", "op": "any", "value": []interface{}{}},
 				},
 			}
 			ec, err := PoliciesTranslate(policies, resourceTypeSet)
@@ -177,6 +159,271 @@ var _ = Describe("Expression", func() {
 			assert.Equal(GinkgoT(), want, ec)
 		})
 
+		It("ok, allow and deny", func() {
+			policies = []types.AuthPolicy{
+				{
+					Expression: `[{"system": "iam", "type": "job", 
+"expression": {"StringPrefix": {"path": ["/biz,1/"]}}}]`,
+				},
+				{
+					Expression: `[{"system": "iam", "type": "job", 
+"expression": {"StringEquals": {"id": ["abc"]}}}]`,
+					Effect: "deny",
+				},
+			}
+			want := map[string]interface{}{
+				"op": "AND",
+				"content": []ExprCell{
+					{"field": "job.id", "op": "not_eq", "value": "abc"},
+					{"field": "job.path", "op": "starts_with", "value": "/biz,1/"},
+				},
+			}
+			ec, err := PoliciesTranslate(policies, resourceTypeSet)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), want, ec)
+		})
+
+		It("ok, allow any and deny", func() {
+			policies = []types.AuthPolicy{
+				{
+					Expression: ``,
+				},
+				{
+					Expression: `[{"system": "iam", "type": "job", 
+"expression": {"StringEquals": {"id": ["abc", "def"]}}}]`,
+					Effect: "deny",
+				},
+			}
+			want := map[string]interface{}{"field": "job.id", "op": "not_in", "value": []interface{}{"abc", "def"}}
+			ec, err := PoliciesTranslate(policies, resourceTypeSet)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), want, ec)
+		})
+
+		It("ok, deny any", func() {
+			policies = []types.AuthPolicy{
+				{
+					Expression: `[{"system": "iam", "type": "job", 
+"expression": {"StringEquals": {"id": ["abc"]}}}]`,
+				},
+				{
+					Expression: ``,
+					Effect:     "deny",
+				},
+			}
+			ec, err := PoliciesTranslate(policies, resourceTypeSet)
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), ec)
+		})
+
+		It("fail, deny policyTranslate fail", func() {
+			policies = []types.AuthPolicy{
+				{
+					Expression: ``,
+				},
+				{
+					Expression: `123`,
+					Effect:     "deny",
+				},
+			}
+			_, err := PoliciesTranslate(policies, resourceTypeSet)
+			assert.Error(GinkgoT(), err)
+		})
+	})
+
+	Describe("PrioritizedPoliciesTranslate", func() {
+		resourceTypeSet := []types.ActionResourceType{{System: "iam", Type: "job"}}
+		allowID := types.AuthPolicy{
+			Expression: `[{"system": "iam", "type": "job", "expression": {"StringEquals": {"id": ["abc"]}}}]`,
+		}
+		allowPath := types.AuthPolicy{
+			Expression: `[{"system": "iam", "type": "job", "expression": {"StringPrefix": {"path": ["/biz,2/"]}}}]`,
+		}
+		denyPath := types.AuthPolicy{
+			Expression: `[{"system": "iam", "type": "job", "expression": {"StringPrefix": {"path": ["/biz,1/"]}}}]`,
+			Effect:     "deny",
+		}
+
+		It("ok, the higher priority allow is not limited by the lower priority deny", func() {
+			ec, err := PrioritizedPoliciesTranslate([]types.AuthPolicy{allowID, denyPath, allowPath}, resourceTypeSet)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]interface{}{
+				"op": "OR",
+				"content": []ExprCell{
+					{
+						"op": "AND",
+						"content": []ExprCell{
+							{"field": "job.path", "op": "not_starts_with", "value": "/biz,1/"},
+							{"field": "job.path", "op": "starts_with", "value": "/biz,2/"},
+						},
+					},
+					{"field": "job.id", "op": "eq", "value": "abc"},
+				},
+			}, ec)
+		})
+
+		It("ok, the highest priority deny limits all allows", func() {
+			ec, err := PrioritizedPoliciesTranslate([]types.AuthPolicy{denyPath, allowID, allowPath}, resourceTypeSet)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]interface{}{
+				"op": "OR",
+				"content": []ExprCell{
+					{
+						"op": "AND",
+						"content": []ExprCell{
+							{"field": "job.id", "op": "eq", "value": "abc"},
+							{"field": "job.path", "op": "not_starts_with", "value": "/biz,1/"},
+						},
+					},
+					{
+						"op": "AND",
+						"content": []ExprCell{
+							{"field": "job.path", "op": "not_starts_with", "value": "/biz,1/"},
+							{"field": "job.path", "op": "starts_with", "value": "/biz,2/"},
+						},
+					},
+				},
+			}, ec)
+		})
+
+		It("ok, the higher priority allow any", func() {
+			ec, err := PrioritizedPoliciesTranslate(
+				[]types.AuthPolicy{{Expression: ``}, denyPath, allowPath}, resourceTypeSet)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), anyExpr, ec)
+		})
+
+		It("ok, the higher priority deny any", func() {
+			ec, err := PrioritizedPoliciesTranslate(
+				[]types.AuthPolicy{{Expression: ``, Effect: "deny"}, allowID, allowPath}, resourceTypeSet)
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), ec)
+		})
+
+		It("ok, deny only", func() {
+			ec, err := PrioritizedPoliciesTranslate([]types.AuthPolicy{denyPath}, resourceTypeSet)
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), ec)
+		})
+
+		It("fail, policyTranslate fail", func() {
+			_, err := PrioritizedPoliciesTranslate(
+				[]types.AuthPolicy{{Expression: `123`, Effect: "deny"}, allowID}, resourceTypeSet)
+			assert.Error(GinkgoT(), err)
+		})
+	})
+
+	Describe("negateExprCell", func() {
+		It("any", func() {
+			ec, err := negateExprCell(anyExpr)
+			assert.NoError(GinkgoT(), err)
+			assert.Nil(GinkgoT(), ec)
+		})
+
+		It("ok, op without inverse", func() {
+			expr := ExprCell{"op": "contains", "field": "job.id", "value": 1}
+			negated, err := negateExprCell(expr)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ExprCell{"op": "not", "content": []ExprCell{expr}}, negated)
+
+			// not包裹后再取反, 还原为原表达式
+			negated, err = negateExprCell(negated)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), expr, negated)
+		})
+
+		It("ok, numeric compare", func() {
+			negated, err := negateExprCell(ExprCell{"op": "gt", "field": "job.level", "value": 1})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ExprCell{"op": "lte", "field": "job.level", "value": 1}, negated)
+
+			negated, err = negateExprCell(ExprCell{"op": "lt", "field": "job.level", "value": 1})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ExprCell{"op": "gte", "field": "job.level", "value": 1}, negated)
+		})
+
+		It("ok, not", func() {
+			inner := ExprCell{"op": "starts_with", "field": "job._bk_iam_path_", "value": "/folder,1/"}
+			negated, err := negateExprCell(ExprCell{"op": "not", "content": []interface{}{inner}})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), inner, negated)
+
+			_, err = negateExprCell(ExprCell{"op": "not", "content": []interface{}{}})
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("ok, env condition", func() {
+			for _, op := range []string{"time_window", "hour_range", "weekday_in", "ip_in_cidr"} {
+				expr := ExprCell{"op": op, "field": "env.ts", "value": []interface{}{1, 2}}
+				negated, err := negateExprCell(expr)
+				assert.NoError(GinkgoT(), err)
+				assert.Equal(GinkgoT(), ExprCell{"op": "not", "content": []ExprCell{expr}}, negated)
+			}
+		})
+
+		It("ok, string pattern", func() {
+			negated, err := negateExprCell(ExprCell{"op": "wildcard", "field": "job.name", "value": "prod-*"})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ExprCell{"op": "not_wildcard", "field": "job.name", "value": "prod-*"}, negated)
+
+			negated, err = negateExprCell(ExprCell{"op": "regex", "field": "job.name", "value": "^prod"})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ExprCell{"op": "not_regex", "field": "job.name", "value": "^prod"}, negated)
+		})
+
+		It("ok, AND => OR", func() {
+			expr := ExprCell{
+				"op": "AND",
+				"content": []interface{}{
+					ExprCell{"field": "job.id", "op": "in", "value": []interface{}{"a", "b"}},
+					ExprCell{"field": "job.name", "op": "any", "value": []interface{}{}},
+					ExprCell{"field": "job.path", "op": "starts_with", "value": "/biz,1/"},
+				},
+			}
+			want := ExprCell{
+				"op": "OR",
+				"content": []ExprCell{
+					{"field": "job.id", "op": "not_in", "value": []interface{}{"a", "b"}},
+					{"field": "job.path", "op": "not_starts_with", "value": "/biz,1/"},
+				},
+			}
+			ec, err := negateExprCell(expr)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), want, ec)
+		})
+
+		It("ok, OR => AND", func() {
+			expr := ExprCell{
+				"op": "OR",
+				"content": []map[string]interface{}{
+					{"field": "job.path", "op": "starts_with", "value": "/biz,1/"},
+					{"field": "job.path", "op": "starts_with", "value": "/biz,2/"},
+				},
+			}
+			want := ExprCell{
+				"op": "AND",
+				"content": []ExprCell{
+					{"field": "job.path", "op": "not_starts_with", "value": "/biz,1/"},
+					{"field": "job.path", "op": "not_starts_with", "value": "/biz,2/"},
+				},
+			}
+			ec, err := negateExprCell(expr)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), want, ec)
+		})
+
+		It("ok, OR with any", func() {
+			expr := ExprCell{
+				"op": "OR",
+				"content": []interface{}{
+					ExprCell{"field": "job.id", "op": "eq", "value": "a"},
+					ExprCell{"field": "job.id", "op": "any", "value": []interface{}{}},
+				},
+			}
+			ec, err := negateExprCell(expr)
+			assert.NoError(GinkgoT(), err)
+			assert.Nil(GinkgoT(), ec)
+		})
 	})
 
 	Describe("PolicyTranslate", func() {
@@ -369,7 +616,7 @@ var _ = Describe("Expression", func() {
 				{
 					"op":    "in",
 					"field": "host.id",
-					"value": []interface{}{"abc", "def", "abc"},
+					"value": []interface{}{"abc", "def"},
 				},
 			}
 			assert.EqualValues(GinkgoT(), want, content)
@@ -401,7 +648,7 @@ var _ = Describe("Expression", func() {
 				{
 					"op":    "in",
 					"field": "host.id",
-					"value": []interface{}{"abc", "def", "abc"},
+					"value": []interface{}{"abc", "def"},
 				},
 			}
 			assert.EqualValues(GinkgoT(), want, content)
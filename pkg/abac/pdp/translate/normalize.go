@@ -0,0 +1,221 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package translate
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// NOTE: 下游系统会按表达式的hash做缓存, 相同的策略必须转换出完全相同的表达式
+//       normalizeExprCell 对表达式做规范化:
+//       1. 展开嵌套的同类AND/OR
+//       2. OR中合并field相同的eq/in条件
+//       3. 去除重复的条件, 并对content做确定性的排序
+//       4. 只有一个条件的AND/OR折叠为该条件本身
+
+// normalizeExprCell 规范化表达式, 返回新的表达式, 不修改原表达式
+func normalizeExprCell(expr ExprCell) (ExprCell, error) {
+	if len(expr) == 0 {
+		return expr, nil
+	}
+
+	op := expr.Op()
+	switch op {
+	case "AND", "OR":
+		cells, err := exprCellContent(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		content := make([]ExprCell, 0, len(cells))
+		for _, cell := range cells {
+			normalized, err := normalizeExprCell(cell)
+			if err != nil {
+				return nil, err
+			}
+
+			// 展开嵌套的同类表达式, (a OR (b OR c)) => (a OR b OR c)
+			if normalized.Op() == op {
+				children, err := exprCellContent(normalized)
+				if err != nil {
+					return nil, err
+				}
+				content = append(content, children...)
+			} else {
+				content = append(content, normalized)
+			}
+		}
+
+		if op == "OR" && len(content) > 1 {
+			content = mergeContentField(content)
+
+			// 合并后的in条件需要再次规范化(值排序, 单值转为eq)
+			for i, cell := range content {
+				if cell.Op() == "in" {
+					if content[i], err = normalizeExprCell(cell); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+
+		content, err = sortAndDeduplicateContent(content)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(content) == 1 {
+			return content[0], nil
+		}
+		return ExprCell{
+			"op":      op,
+			"content": content,
+		}, nil
+	case "not":
+		cells, err := exprCellContent(expr)
+		if err != nil {
+			return nil, err
+		}
+		if len(cells) != 1 {
+			return nil, fmt.Errorf("invalid not expression content %+v", expr["content"])
+		}
+
+		normalized, err := normalizeExprCell(cells[0])
+		if err != nil {
+			return nil, err
+		}
+		return ExprCell{
+			"op":      "not",
+			"content": []ExprCell{normalized},
+		}, nil
+	case "in":
+		values, ok := expr["value"].([]interface{})
+		if !ok {
+			return expr, nil
+		}
+
+		values, err := sortAndDeduplicateValues(values)
+		if err != nil {
+			return nil, err
+		}
+
+		// 去重后只剩一个值, 与stringEqualsTranslate保持一致, 使用eq
+		if len(values) == 1 {
+			return ExprCell{
+				"op":    "eq",
+				"field": expr["field"],
+				"value": values[0],
+			}, nil
+		}
+		return ExprCell{
+			"op":    "in",
+			"field": expr["field"],
+			"value": values,
+		}, nil
+	default:
+		return expr, nil
+	}
+}
+
+// canonicalKey 表达式/值的规范化序列化结果, map的key有序, 用于去重及排序
+func canonicalKey(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func sortAndDeduplicateContent(content []ExprCell) ([]ExprCell, error) {
+	keys := make(map[string]ExprCell, len(content))
+	for _, cell := range content {
+		key, err := canonicalKey(cell)
+		if err != nil {
+			return nil, err
+		}
+		keys[key] = cell
+	}
+
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	newContent := make([]ExprCell, 0, len(sortedKeys))
+	for _, key := range sortedKeys {
+		newContent = append(newContent, keys[key])
+	}
+	return newContent, nil
+}
+
+type keyedValue struct {
+	key   string
+	value interface{}
+}
+
+func sortAndDeduplicateValues(values []interface{}) ([]interface{}, error) {
+	keyed := make([]keyedValue, 0, len(values))
+	seen := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		key, err := canonicalKey(v)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		keyed = append(keyed, keyedValue{key: key, value: v})
+	}
+
+	sort.SliceStable(keyed, func(i, j int) bool {
+		return lessValue(keyed[i], keyed[j])
+	})
+
+	newValues := make([]interface{}, 0, len(keyed))
+	for _, kv := range keyed {
+		newValues = append(newValues, kv.value)
+	}
+	return newValues, nil
+}
+
+// valueClass 值的排序分组: 数值 < 字符串 < 其它
+func valueClass(v interface{}) int {
+	if _, ok := knownValueToFloat64(v); ok {
+		return 0
+	}
+	if _, ok := v.(string); ok {
+		return 1
+	}
+	return 2
+}
+
+// lessValue 先按分组排序, 组内数值按大小, 字符串按字典序, 其它按序列化结果排序
+func lessValue(a, b keyedValue) bool {
+	ac, bc := valueClass(a.value), valueClass(b.value)
+	if ac != bc {
+		return ac < bc
+	}
+
+	switch ac {
+	case 0:
+		af, _ := knownValueToFloat64(a.value)
+		bf, _ := knownValueToFloat64(b.value)
+		if af != bf {
+			return af < bf
+		}
+	case 1:
+		return a.value.(string) < b.value.(string)
+	}
+	return a.key < b.key
+}
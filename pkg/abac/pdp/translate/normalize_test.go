@@ -0,0 +1,147 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package translate
+
+import (
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/types"
+)
+
+var _ = Describe("Normalize", func() {
+	Describe("normalizeExprCell", func() {
+		osCell := ExprCell{"op": "eq", "field": "host.os", "value": "linux"}
+		pathCell := ExprCell{"op": "starts_with", "field": "host.path", "value": "/biz,1/"}
+
+		It("empty", func() {
+			expr, err := normalizeExprCell(ExprCell{})
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), expr, 0)
+		})
+
+		It("collapse single child", func() {
+			expr, err := normalizeExprCell(ExprCell{
+				"op":      "AND",
+				"content": []interface{}{ExprCell{"op": "OR", "content": []ExprCell{osCell}}},
+			})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), osCell, expr)
+		})
+
+		It("deduplicate and sort content", func() {
+			expr, err := normalizeExprCell(ExprCell{
+				"op":      "AND",
+				"content": []ExprCell{pathCell, osCell, pathCell},
+			})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ExprCell{"op": "AND", "content": []ExprCell{osCell, pathCell}}, expr)
+
+			expr2, err := normalizeExprCell(ExprCell{
+				"op":      "AND",
+				"content": []ExprCell{osCell, pathCell},
+			})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), expr, expr2)
+		})
+
+		It("merge in/eq across nesting levels", func() {
+			expr, err := normalizeExprCell(ExprCell{
+				"op": "OR",
+				"content": []ExprCell{
+					{"op": "eq", "field": "host.id", "value": "3"},
+					{
+						"op": "OR",
+						"content": []ExprCell{
+							{"op": "in", "field": "host.id", "value": []interface{}{"2", "1", "3"}},
+							pathCell,
+						},
+					},
+				},
+			})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ExprCell{
+				"op": "OR",
+				"content": []ExprCell{
+					{"op": "in", "field": "host.id", "value": []interface{}{"1", "2", "3"}},
+					pathCell,
+				},
+			}, expr)
+		})
+
+		It("in with single value to eq", func() {
+			expr, err := normalizeExprCell(ExprCell{
+				"op": "OR",
+				"content": []ExprCell{
+					osCell,
+					{"op": "in", "field": "host.os", "value": []interface{}{"linux"}},
+				},
+			})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), osCell, expr)
+		})
+
+		It("sort numeric values", func() {
+			expr, err := normalizeExprCell(ExprCell{
+				"op": "in", "field": "host.cpu", "value": []interface{}{float64(10), 9, "a", float64(9)},
+			})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ExprCell{
+				"op": "in", "field": "host.cpu", "value": []interface{}{9, float64(10), "a"},
+			}, expr)
+		})
+
+		It("not", func() {
+			expr, err := normalizeExprCell(ExprCell{
+				"op": "not",
+				"content": []interface{}{
+					ExprCell{"op": "AND", "content": []ExprCell{osCell, osCell}},
+				},
+			})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ExprCell{"op": "not", "content": []ExprCell{osCell}}, expr)
+		})
+
+		It("fail, invalid content", func() {
+			_, err := normalizeExprCell(ExprCell{"op": "AND", "content": "abc"})
+			assert.Error(GinkgoT(), err)
+		})
+	})
+
+	Describe("PoliciesTranslate stable", func() {
+		It("same policies in different order", func() {
+			resourceTypes := []types.ActionResourceType{{System: "iam", Type: "job"}}
+			policies := []types.AuthPolicy{
+				{Expression: `[{"system": "iam", "type": "job", "expression": {"StringEquals": {"name": ["b"]}}}]`},
+				{Expression: `[{"system": "iam", "type": "job", "expression": {"StringEquals": {"id": ["2", "1"]}}}]`},
+				{Expression: `[{"system": "iam", "type": "job", "expression": {"StringEquals": {"name": ["a"]}}}]`},
+				{Expression: `[{"system": "iam", "type": "job", "expression": {"StringEquals": {"id": ["1"]}}}]`},
+			}
+			expr, err := PoliciesTranslate(policies, resourceTypes)
+			assert.NoError(GinkgoT(), err)
+
+			reversed := make([]types.AuthPolicy, 0, len(policies))
+			for i := len(policies) - 1; i >= 0; i-- {
+				reversed = append(reversed, policies[i])
+			}
+			expr2, err := PoliciesTranslate(reversed, resourceTypes)
+			assert.NoError(GinkgoT(), err)
+
+			assert.Equal(GinkgoT(), expr, expr2)
+			assert.Equal(GinkgoT(), map[string]interface{}{
+				"op": "OR",
+				"content": []ExprCell{
+					{"op": "in", "field": "job.id", "value": []interface{}{"1", "2"}},
+					{"op": "in", "field": "job.name", "value": []interface{}{"a", "b"}},
+				},
+			}, expr)
+		})
+	})
+})
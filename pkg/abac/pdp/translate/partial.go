@@ -0,0 +1,242 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package translate
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/*
+部分求值
+
+调用方在查询时传入已知的资源属性(key为表达式中的field, 例如 host.os), 与已知属性相关的条件可以直接计算出结果,
+返回只包含未决条件的表达式, 减少接入系统需要执行的过滤条件:
+	{"op": "AND", "content": [{"op": "eq", "field": "host.os", "value": "linux"}, {"op": "in", "field": "host.id", ...}]}
+	已知 host.os = linux => {"op": "in", "field": "host.id", ...}
+
+NOTE: 只对比较类的操作符求值; 属性转换函数(lower(host.name))/模式匹配/环境属性等条件保持原样
+*/
+
+// PartialEvaluate 使用已知属性对表达式部分求值
+// 整个表达式计算为true时返回any, 计算为false时返回空表达式(没有权限)
+func PartialEvaluate(expr map[string]interface{}, known map[string]interface{}) (map[string]interface{}, error) {
+	if len(expr) == 0 || len(known) == 0 {
+		return expr, nil
+	}
+
+	cell, decided, result, err := partialEvaluate(expr, known)
+	if err != nil {
+		return nil, err
+	}
+	if !decided {
+		return cell, nil
+	}
+	if result {
+		return ExprCell{
+			"op":    "any",
+			"field": "",
+			"value": []interface{}{},
+		}, nil
+	}
+	return ExprCell{}, nil
+}
+
+// partialEvaluate 返回未决的表达式; decided为true时表达式已经有结果result
+func partialEvaluate(
+	expr ExprCell, known map[string]interface{},
+) (cell ExprCell, decided bool, result bool, err error) {
+	op, ok := expr["op"].(string)
+	if !ok {
+		err = fmt.Errorf("invalid expression %+v, op required", expr)
+		return
+	}
+
+	switch op {
+	case "any":
+		return nil, true, true, nil
+	case "AND", "OR":
+		return partialEvaluateLogical(op, expr, known)
+	case "not":
+		cells, err := exprCellContent(expr)
+		if err != nil {
+			return nil, false, false, err
+		}
+		if len(cells) != 1 {
+			return nil, false, false, fmt.Errorf("invalid not expression content %+v", expr["content"])
+		}
+
+		child, decided, result, err := partialEvaluate(cells[0], known)
+		if err != nil {
+			return nil, false, false, err
+		}
+		if decided {
+			return nil, true, !result, nil
+		}
+		return ExprCell{
+			"op":      "not",
+			"content": []ExprCell{child},
+		}, false, false, nil
+	default:
+		field, _ := expr["field"].(string)
+		attrValue, ok := known[field]
+		if !ok {
+			return expr, false, false, nil
+		}
+
+		result, ok := evalKnownAttr(op, attrValue, expr["value"])
+		if !ok {
+			return expr, false, false, nil
+		}
+		return nil, true, result, nil
+	}
+}
+
+func partialEvaluateLogical(
+	op string, expr ExprCell, known map[string]interface{},
+) (cell ExprCell, decided bool, result bool, err error) {
+	cells, err := exprCellContent(expr)
+	if err != nil {
+		return
+	}
+
+	// AND: 任一为false则为false, 为true的条件可以去掉; OR: 任一为true则为true, 为false的条件可以去掉
+	shortCircuit := op == "OR"
+	content := make([]ExprCell, 0, len(cells))
+	for _, c := range cells {
+		child, childDecided, childResult, err := partialEvaluate(c, known)
+		if err != nil {
+			return nil, false, false, err
+		}
+		if !childDecided {
+			content = append(content, child)
+			continue
+		}
+		if childResult == shortCircuit {
+			return nil, true, shortCircuit, nil
+		}
+	}
+
+	switch len(content) {
+	case 0:
+		return nil, true, !shortCircuit, nil
+	case 1:
+		return content[0], false, false, nil
+	default:
+		return ExprCell{
+			"op":      op,
+			"content": content,
+		}, false, false, nil
+	}
+}
+
+// evalKnownAttr 计算已知属性的条件, 不支持的操作符返回ok=false
+// 属性值为数组时, 任一元素满足即满足; 取反的操作符为不满足
+func evalKnownAttr(op string, attrValue, value interface{}) (result bool, ok bool) {
+	switch op {
+	case "not_eq", "not_in", "not_starts_with":
+		result, ok = evalKnownAttr(strings.TrimPrefix(op, "not_"), attrValue, value)
+		return !result, ok
+	case "eq", "in", "starts_with", "gt", "gte", "lt", "lte":
+	default:
+		return false, false
+	}
+
+	attrValues := []interface{}{attrValue}
+	if rv := reflect.ValueOf(attrValue); rv.Kind() == reflect.Slice {
+		attrValues = make([]interface{}, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			attrValues = append(attrValues, rv.Index(i).Interface())
+		}
+	}
+
+	for _, av := range attrValues {
+		if evalSingleKnownAttr(op, av, value) {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+func evalSingleKnownAttr(op string, attrValue, value interface{}) bool {
+	switch op {
+	case "eq":
+		return knownValueEquals(attrValue, value)
+	case "in":
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice {
+			return knownValueEquals(attrValue, value)
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if knownValueEquals(attrValue, rv.Index(i).Interface()) {
+				return true
+			}
+		}
+		return false
+	case "starts_with":
+		a, ok1 := attrValue.(string)
+		v, ok2 := value.(string)
+		return ok1 && ok2 && strings.HasPrefix(a, v)
+	default:
+		a, ok1 := knownValueToFloat64(attrValue)
+		v, ok2 := knownValueToFloat64(value)
+		if !ok1 || !ok2 {
+			return false
+		}
+		switch op {
+		case "gt":
+			return a > v
+		case "gte":
+			return a >= v
+		case "lt":
+			return a < v
+		case "lte":
+			return a <= v
+		}
+		return false
+	}
+}
+
+// knownValueEquals 数值统一转换为float64比较, 避免json解析出的类型不一致
+func knownValueEquals(a, b interface{}) bool {
+	if fa, ok := knownValueToFloat64(a); ok {
+		fb, ok := knownValueToFloat64(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func knownValueToFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
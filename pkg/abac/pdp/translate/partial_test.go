@@ -0,0 +1,122 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package translate
+
+import (
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ = Describe("Partial", func() {
+	Describe("PartialEvaluate", func() {
+		anyExpr := map[string]interface{}{"op": "any", "field": "", "value": []interface{}{}}
+		idCell := ExprCell{"op": "in", "field": "host.id", "value": []interface{}{"1", "2"}}
+		osCell := ExprCell{"op": "eq", "field": "host.os", "value": "linux"}
+		cpuCell := ExprCell{"op": "gte", "field": "host.cpu", "value": float64(4)}
+
+		It("no known attributes", func() {
+			expr := map[string]interface{}{"op": "AND", "content": []ExprCell{osCell, idCell}}
+			reduced, err := PartialEvaluate(expr, nil)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), expr, reduced)
+		})
+
+		It("empty, no permission", func() {
+			reduced, err := PartialEvaluate(map[string]interface{}{}, map[string]interface{}{"host.os": "linux"})
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), reduced)
+		})
+
+		It("single decided true", func() {
+			reduced, err := PartialEvaluate(osCell, map[string]interface{}{"host.os": "linux"})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), anyExpr, reduced)
+		})
+
+		It("single decided false", func() {
+			reduced, err := PartialEvaluate(osCell, map[string]interface{}{"host.os": "windows"})
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), reduced)
+		})
+
+		It("AND, fold the true condition", func() {
+			expr := map[string]interface{}{"op": "AND", "content": []ExprCell{osCell, idCell}}
+			reduced, err := PartialEvaluate(expr, map[string]interface{}{"host.os": "linux"})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]interface{}(idCell), reduced)
+		})
+
+		It("AND, short circuit false", func() {
+			expr := map[string]interface{}{"op": "AND", "content": []ExprCell{osCell, idCell}}
+			reduced, err := PartialEvaluate(expr, map[string]interface{}{"host.os": "windows"})
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), reduced)
+		})
+
+		It("OR, short circuit true", func() {
+			expr := map[string]interface{}{"op": "OR", "content": []ExprCell{idCell, cpuCell}}
+			reduced, err := PartialEvaluate(expr, map[string]interface{}{"host.cpu": 8})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), anyExpr, reduced)
+		})
+
+		It("OR, fold the false condition", func() {
+			expr := map[string]interface{}{"op": "OR", "content": []ExprCell{idCell, osCell, cpuCell}}
+			reduced, err := PartialEvaluate(expr, map[string]interface{}{"host.cpu": 2})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]interface{}{"op": "OR", "content": []ExprCell{idCell, osCell}}, reduced)
+		})
+
+		It("not", func() {
+			expr := map[string]interface{}{"op": "AND", "content": []ExprCell{
+				idCell,
+				{"op": "not", "content": []map[string]interface{}{osCell}},
+			}}
+			reduced, err := PartialEvaluate(expr, map[string]interface{}{"host.os": "windows"})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]interface{}(idCell), reduced)
+
+			reduced, err = PartialEvaluate(expr, map[string]interface{}{"host.id": "1"})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]interface{}{"op": "not", "content": []ExprCell{osCell}}, reduced)
+		})
+
+		It("negated operators and array attribute", func() {
+			expr := map[string]interface{}{"op": "not_in", "field": "host.tag", "value": []interface{}{"test", "dev"}}
+			reduced, err := PartialEvaluate(expr, map[string]interface{}{"host.tag": []interface{}{"prod", "dev"}})
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), reduced)
+
+			reduced, err = PartialEvaluate(expr, map[string]interface{}{"host.tag": []interface{}{"prod"}})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), anyExpr, reduced)
+		})
+
+		It("starts_with path", func() {
+			expr := map[string]interface{}{"op": "starts_with", "field": "host._bk_iam_path_", "value": "/biz,1/"}
+			reduced, err := PartialEvaluate(expr, map[string]interface{}{"host._bk_iam_path_": "/biz,1/set,2/"})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), anyExpr, reduced)
+		})
+
+		It("unsupported operator kept", func() {
+			expr := map[string]interface{}{"op": "regex", "field": "host.name", "value": "^web"}
+			reduced, err := PartialEvaluate(expr, map[string]interface{}{"host.name": "web-1"})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), expr, reduced)
+		})
+
+		It("invalid expression", func() {
+			_, err := PartialEvaluate(map[string]interface{}{"op": "AND", "content": "bad"},
+				map[string]interface{}{"host.os": "linux"})
+			assert.Error(GinkgoT(), err)
+		})
+	})
+})
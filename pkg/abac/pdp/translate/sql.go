@@ -0,0 +1,246 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package translate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"iam/pkg/abac/pdp/util"
+	"iam/pkg/errorx"
+)
+
+/*
+表达式转换为SQL WHERE子句
+
+将策略转换后的条件表达式(PoliciesTranslate的结果)转换为参数化的SQL WHERE子句, 供使用MySQL存储资源的接入系统直接拼接到列表查询中:
+	{"op": "in", "field": "host.id", "value": ["1", "2"]}  => `id` IN (?, ?)  ["1", "2"]
+
+表达式中的字段需要由调用方提供到数据库列的映射, 例如 {"host.id": "id", "host.owner": "h.owner"}
+*/
+
+// ErrSQLFieldNotMapped 表达式中的字段没有提供对应的数据库列
+var ErrSQLFieldNotMapped = errors.New("field not mapped to sql column")
+
+// ErrSQLInvalidColumn 映射的数据库列名不合法
+var ErrSQLInvalidColumn = errors.New("invalid sql column")
+
+// ErrSQLUnsupportedOperator 操作符无法转换为SQL, 例如环境属性相关的操作符
+var ErrSQLUnsupportedOperator = errors.New("operator not supported in sql")
+
+const (
+	sqlAlwaysTrue  = "1 = 1"
+	sqlAlwaysFalse = "1 = 0"
+)
+
+// 数据库列名, 支持带表名/别名的形式, 例如 id, h.id
+var sqlColumnRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+var sqlCompareOperators = map[string]string{
+	"eq":     "=",
+	"not_eq": "!=",
+	"gt":     ">",
+	"gte":    ">=",
+	"lt":     "<",
+	"lte":    "<=",
+}
+
+var sqlLikeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SQLWhere 参数化的SQL WHERE子句, Args与Clause中的占位符?一一对应
+type SQLWhere struct {
+	Clause string        `json:"clause"`
+	Args   []interface{} `json:"args"`
+}
+
+// PoliciesTranslateToSQL 策略条件表达式转换为参数化的SQL WHERE子句
+// 空表达式表示没有权限, 转换为恒假的条件; any转换为恒真的条件
+func PoliciesTranslateToSQL(expr map[string]interface{}, fieldMapping map[string]string) (SQLWhere, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(Translate, "PoliciesTranslateToSQL")
+
+	if len(expr) == 0 {
+		return SQLWhere{Clause: sqlAlwaysFalse, Args: []interface{}{}}, nil
+	}
+
+	t := sqlTranslator{fieldMapping: fieldMapping, args: []interface{}{}}
+	clause, err := t.translate(expr)
+	if err != nil {
+		return SQLWhere{}, errorWrapf(err, "translate expr=`%+v` fail", expr)
+	}
+
+	return SQLWhere{Clause: clause, Args: t.args}, nil
+}
+
+type sqlTranslator struct {
+	fieldMapping map[string]string
+	args         []interface{}
+}
+
+func (t *sqlTranslator) translate(expr ExprCell) (string, error) {
+	op, ok := expr["op"].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid expression %+v, op required", expr)
+	}
+
+	switch op {
+	case "any":
+		return sqlAlwaysTrue, nil
+	case "AND", "OR":
+		cells, err := exprCellContent(expr)
+		if err != nil {
+			return "", err
+		}
+		if len(cells) == 0 {
+			return "", fmt.Errorf("invalid expression %+v, content must not be empty", expr)
+		}
+
+		clauses := make([]string, 0, len(cells))
+		for _, cell := range cells {
+			clause, err := t.translate(cell)
+			if err != nil {
+				return "", err
+			}
+			clauses = append(clauses, clause)
+		}
+		if len(clauses) == 1 {
+			return clauses[0], nil
+		}
+		return "(" + strings.Join(clauses, " "+op+" ") + ")", nil
+	case "not":
+		cells, err := exprCellContent(expr)
+		if err != nil {
+			return "", err
+		}
+		if len(cells) != 1 {
+			return "", fmt.Errorf("invalid not expression content %+v", expr["content"])
+		}
+
+		clause, err := t.translate(cells[0])
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + clause + ")", nil
+	}
+
+	column, err := t.column(expr["field"])
+	if err != nil {
+		return "", err
+	}
+	value := expr["value"]
+
+	if operator, ok := sqlCompareOperators[op]; ok {
+		t.args = append(t.args, value)
+		return column + " " + operator + " ?", nil
+	}
+
+	switch op {
+	case "in", "not_in":
+		values, err := toValueSlice(value)
+		if err != nil {
+			return "", err
+		}
+		if len(values) == 0 {
+			return "", fmt.Errorf("invalid expression %+v, %w", expr, errMustNotEmpty)
+		}
+
+		t.args = append(t.args, values...)
+		operator := "IN"
+		if op == "not_in" {
+			operator = "NOT IN"
+		}
+		return column + " " + operator + " (" + strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ") + ")", nil
+	case "starts_with", "not_starts_with":
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("invalid expression %+v, value should be string", expr)
+		}
+
+		t.args = append(t.args, sqlLikeEscaper.Replace(s)+"%")
+		return column + " " + negateSQLOperator("LIKE", op) + " ?", nil
+	case "wildcard", "not_wildcard":
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("invalid expression %+v, value should be string", expr)
+		}
+
+		// 通配符: * 匹配任意字符, ? 匹配单个字符
+		pattern := strings.NewReplacer("*", "%", "?", "_").Replace(sqlLikeEscaper.Replace(s))
+		t.args = append(t.args, pattern)
+		return column + " " + negateSQLOperator("LIKE", op) + " ?", nil
+	case "regex", "not_regex":
+		t.args = append(t.args, value)
+		return column + " " + negateSQLOperator("REGEXP", op) + " ?", nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrSQLUnsupportedOperator, op)
+}
+
+// column 字段映射为数据库列, 支持lower(field)转换为LOWER(column)
+func (t *sqlTranslator) column(f interface{}) (string, error) {
+	field, ok := f.(string)
+	if !ok || field == "" {
+		return "", fmt.Errorf("invalid field %+v", f)
+	}
+
+	if transform, ok := util.ParseAttrTransform(field); ok {
+		if transform.Func != "lower" {
+			return "", fmt.Errorf("%w: transform function %s", ErrSQLUnsupportedOperator, transform.Func)
+		}
+
+		column, err := t.mappedColumn(transform.Attr)
+		if err != nil {
+			return "", err
+		}
+		return "LOWER(" + column + ")", nil
+	}
+
+	return t.mappedColumn(field)
+}
+
+func (t *sqlTranslator) mappedColumn(field string) (string, error) {
+	column, ok := t.fieldMapping[field]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSQLFieldNotMapped, field)
+	}
+	if !sqlColumnRegex.MatchString(column) {
+		return "", fmt.Errorf("%w `%s` of field %s", ErrSQLInvalidColumn, column, field)
+	}
+
+	// 使用反引号包裹, 避免列名与保留字冲突
+	return "`" + strings.ReplaceAll(column, ".", "`.`") + "`", nil
+}
+
+func negateSQLOperator(operator, op string) string {
+	if strings.HasPrefix(op, "not_") {
+		return "NOT " + operator
+	}
+	return operator
+}
+
+func toValueSlice(value interface{}) ([]interface{}, error) {
+	if values, ok := value.([]interface{}); ok {
+		return values, nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("invalid value %+v, should be array", value)
+	}
+
+	values := make([]interface{}, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		values = append(values, v.Index(i).Interface())
+	}
+	return values, nil
+}
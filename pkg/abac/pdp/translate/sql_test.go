@@ -0,0 +1,152 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package translate
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/types"
+)
+
+var _ = Describe("SQL", func() {
+	Describe("PoliciesTranslateToSQL", func() {
+		fieldMapping := map[string]string{
+			"host.id":            "id",
+			"host.name":          "h.name",
+			"host.os":            "os",
+			"host.cpu":           "cpu",
+			"host._bk_iam_path_": "iam_path",
+		}
+
+		hostExpression := func(condition string) string {
+			return `[{"system": "bk_cmdb", "type": "host", "expression": ` + condition + `}]`
+		}
+
+		It("empty, no permission", func() {
+			where, err := PoliciesTranslateToSQL(map[string]interface{}{}, fieldMapping)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "1 = 0", where.Clause)
+			assert.Empty(GinkgoT(), where.Args)
+		})
+
+		It("any", func() {
+			where, err := PoliciesTranslateToSQL(map[string]interface{}{
+				"op":    "any",
+				"field": "",
+				"value": []string{},
+			}, fieldMapping)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "1 = 1", where.Clause)
+			assert.Empty(GinkgoT(), where.Args)
+		})
+
+		It("eq", func() {
+			where, err := PoliciesTranslateToSQL(map[string]interface{}{
+				"op":    "eq",
+				"field": "host.id",
+				"value": "1",
+			}, fieldMapping)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "`id` = ?", where.Clause)
+			assert.Equal(GinkgoT(), []interface{}{"1"}, where.Args)
+		})
+
+		It("in with table alias", func() {
+			where, err := PoliciesTranslateToSQL(map[string]interface{}{
+				"op":    "not_in",
+				"field": "host.name",
+				"value": []interface{}{"a", "b"},
+			}, fieldMapping)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "`h`.`name` NOT IN (?, ?)", where.Clause)
+			assert.Equal(GinkgoT(), []interface{}{"a", "b"}, where.Args)
+		})
+
+		It("nested AND/OR/not", func() {
+			where, err := PoliciesTranslateToSQL(map[string]interface{}{
+				"op": "AND",
+				"content": []ExprCell{
+					{
+						"op": "OR",
+						"content": []interface{}{
+							map[string]interface{}{"op": "starts_with", "field": "host._bk_iam_path_", "value": "/biz,1/"},
+							map[string]interface{}{"op": "wildcard", "field": "host.os", "value": "lin*_?"},
+						},
+					},
+					{"op": "gte", "field": "host.cpu", "value": 4},
+					{
+						"op":      "not",
+						"content": []interface{}{ExprCell{"op": "regex", "field": "lower(host.name)", "value": "^test"}},
+					},
+				},
+			}, fieldMapping)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(),
+				"((`iam_path` LIKE ? OR `os` LIKE ?) AND `cpu` >= ? AND NOT (LOWER(`h`.`name`) REGEXP ?))",
+				where.Clause)
+			assert.Equal(GinkgoT(), []interface{}{"/biz,1/%", `lin%\__`, 4, "^test"}, where.Args)
+		})
+
+		It("field not mapped", func() {
+			_, err := PoliciesTranslateToSQL(map[string]interface{}{
+				"op":    "eq",
+				"field": "host.owner",
+				"value": "admin",
+			}, fieldMapping)
+			assert.Error(GinkgoT(), err)
+			assert.True(GinkgoT(), errors.Is(err, ErrSQLFieldNotMapped))
+		})
+
+		It("invalid column", func() {
+			_, err := PoliciesTranslateToSQL(map[string]interface{}{
+				"op":    "eq",
+				"field": "host.id",
+				"value": "1",
+			}, map[string]string{"host.id": "id; DROP TABLE host"})
+			assert.Error(GinkgoT(), err)
+			assert.True(GinkgoT(), errors.Is(err, ErrSQLInvalidColumn))
+		})
+
+		It("unsupported operator", func() {
+			_, err := PoliciesTranslateToSQL(map[string]interface{}{
+				"op":    "hour_range",
+				"field": "env.hour",
+				"value": []interface{}{9, 18},
+			}, map[string]string{"env.hour": "hour"})
+			assert.Error(GinkgoT(), err)
+			assert.True(GinkgoT(), errors.Is(err, ErrSQLUnsupportedOperator))
+		})
+
+		It("translated policies", func() {
+			expr, err := PoliciesTranslate(
+				[]types.AuthPolicy{
+					{ID: 1, Expression: hostExpression(`{"StringEquals": {"id": ["1"]}}`)},
+					{ID: 2, Expression: hostExpression(`{"StringEquals": {"os": ["linux"]}}`)},
+					{ID: 3, Expression: hostExpression(`{"StringEquals": {"id": ["2"]}}`), Effect: "deny"},
+				},
+				[]types.ActionResourceType{{System: "bk_cmdb", Type: "host"}},
+			)
+			assert.NoError(GinkgoT(), err)
+
+			where, err := PoliciesTranslateToSQL(expr, fieldMapping)
+			assert.NoError(GinkgoT(), err)
+			assert.Contains(GinkgoT(), []string{
+				"((`id` = ? OR `os` = ?) AND `id` != ?)",
+				"((`os` = ? OR `id` = ?) AND `id` != ?)",
+			}, where.Clause)
+			assert.Len(GinkgoT(), where.Args, 3)
+			assert.Equal(GinkgoT(), "2", where.Args[2])
+		})
+	})
+})
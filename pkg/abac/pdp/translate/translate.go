@@ -13,6 +13,7 @@ package translate
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"iam/pkg/abac/pdp/types"
 	"iam/pkg/abac/pdp/util"
@@ -42,13 +43,26 @@ var translateFactories map[string]translateFunc
 
 func init() {
 	translateFactories = map[string]translateFunc{
-		"AND":           andTranslate,
-		"OR":            orTranslate,
-		"Any":           anyTranslate,
-		"StringEquals":  stringEqualsTranslate,
-		"StringPrefix":  stringPrefixTranslate,
-		"NumericEquals": numericEqualsTranslate,
-		"Bool":          boolTranslate,
+		"AND":            andTranslate,
+		"OR":             orTranslate,
+		"NOT":            notTranslate,
+		"Any":            anyTranslate,
+		"StringEquals":   stringEqualsTranslate,
+		"StringPrefix":   stringPrefixTranslate,
+		"StringWildcard": newOrCompareTranslate("wildcard"),
+		"StringRegex":    newOrCompareTranslate("regex"),
+		"NumericEquals":  numericEqualsTranslate,
+		"NumericGt":      newOrCompareTranslate("gt"),
+		"NumericGte":     newOrCompareTranslate("gte"),
+		"NumericLt":      newOrCompareTranslate("lt"),
+		"NumericLte":     newOrCompareTranslate("lte"),
+		"Bool":           boolTranslate,
+		"TimeWindow":     timeWindowTranslate,
+		"HourRange":      hourRangeTranslate,
+		"WeekdayIn":      weekdayInTranslate,
+		"IPInCIDR":       ipInCIDRTranslate,
+
+		"StringEqualsIgnoreCase": stringEqualsIgnoreCaseTranslate,
 	}
 }
 
@@ -77,12 +91,14 @@ func singleTranslate(expression types.PolicyCondition, _type string) (ExprCell,
 
 		for field, value := range option {
 			switch operator {
-			case "OR", "AND":
+			case "OR", "AND", "NOT":
 				return translateFunc(_type, value)
+			case "TimeWindow", "HourRange", "WeekdayIn", "IPInCIDR":
+				// 环境属性与资源类型无关, field保持原样, 例如 env.hour
+				return translateFunc(field, value)
 			default:
 				//typeField := fmt.Sprintf("%s.%s", _type, field)
-				typeField := _type + "." + field
-				return translateFunc(typeField, value)
+				return translateFunc(typeField(_type, field), value)
 			}
 		}
 	}
@@ -133,6 +149,41 @@ func orTranslate(_type string, value []interface{}) (ExprCell, error) {
 	}, nil
 }
 
+func notTranslate(_type string, value []interface{}) (ExprCell, error) {
+	if len(value) != 1 {
+		return nil, fmt.Errorf("not should have only one condition in content, got %d", len(value))
+	}
+
+	m, err := util.InterfaceToPolicyCondition(value[0])
+	if err != nil {
+		return nil, err
+	}
+	condition, err := singleTranslate(m, _type)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"op":      "not",
+		"content": []interface{}{condition},
+	}, nil
+}
+
+// typeField 属性加上资源类型前缀, 属性值转换函数保持函数形式, 例如 lower(name) => lower(host.name)
+// NOTE: subject的属性(subject.groups)不是资源属性, 不加前缀
+func typeField(_type, field string) string {
+	if t, ok := util.ParseAttrTransform(field); ok {
+		if types.IsSubjectAttrKey(t.Attr) {
+			return field
+		}
+		return t.Field(_type + "." + t.Attr)
+	}
+	if types.IsSubjectAttrKey(field) {
+		return field
+	}
+	return _type + "." + field
+}
+
 //nolint:unparam
 func anyTranslate(field string, value []interface{}) (ExprCell, error) {
 	return map[string]interface{}{
@@ -160,6 +211,18 @@ func stringEqualsTranslate(field string, value []interface{}) (ExprCell, error)
 	return exprCell, nil
 }
 
+// stringEqualsIgnoreCaseTranslate 转换为 lower(field) 与小写后的值相等
+func stringEqualsIgnoreCaseTranslate(field string, value []interface{}) (ExprCell, error) {
+	lowerValue := make([]interface{}, 0, len(value))
+	for _, v := range value {
+		if s, ok := v.(string); ok {
+			v = strings.ToLower(s)
+		}
+		lowerValue = append(lowerValue, v)
+	}
+	return stringEqualsTranslate("lower("+field+")", lowerValue)
+}
+
 func stringPrefixTranslate(field string, value []interface{}) (ExprCell, error) {
 	content := make([]map[string]interface{}, 0, len(value))
 	for _, v := range value {
@@ -201,6 +264,32 @@ func numericEqualsTranslate(field string, value []interface{}) (ExprCell, error)
 	return exprCell, nil
 }
 
+// newOrCompareTranslate 单值比较(数值比较/模式匹配), 多个值之间为OR的关系
+func newOrCompareTranslate(op string) translateFunc {
+	return func(field string, value []interface{}) (ExprCell, error) {
+		content := make([]map[string]interface{}, 0, len(value))
+		for _, v := range value {
+			content = append(content, map[string]interface{}{
+				"op":    op,
+				"field": field,
+				"value": v,
+			})
+		}
+
+		switch len(content) {
+		case 0:
+			return nil, errMustNotEmpty
+		case 1:
+			return content[0], nil
+		default:
+			return map[string]interface{}{
+				"op":      "OR",
+				"content": content,
+			}, nil
+		}
+	}
+}
+
 func boolTranslate(field string, value []interface{}) (ExprCell, error) {
 	if len(value) != 1 {
 		return nil, fmt.Errorf("bool not support multi value %+v", value)
@@ -212,3 +301,51 @@ func boolTranslate(field string, value []interface{}) (ExprCell, error) {
 		"value": value[0],
 	}, nil
 }
+
+func timeWindowTranslate(field string, value []interface{}) (ExprCell, error) {
+	if len(value) != 2 {
+		return nil, fmt.Errorf("time window value should be [start, end], got %+v", value)
+	}
+
+	return map[string]interface{}{
+		"op":    "time_window",
+		"field": field,
+		"value": value,
+	}, nil
+}
+
+func hourRangeTranslate(field string, value []interface{}) (ExprCell, error) {
+	if len(value) != 2 {
+		return nil, fmt.Errorf("hour range value should be [start, end], got %+v", value)
+	}
+
+	return map[string]interface{}{
+		"op":    "hour_range",
+		"field": field,
+		"value": value,
+	}, nil
+}
+
+func weekdayInTranslate(field string, value []interface{}) (ExprCell, error) {
+	if len(value) == 0 {
+		return nil, errMustNotEmpty
+	}
+
+	return map[string]interface{}{
+		"op":    "weekday_in",
+		"field": field,
+		"value": value,
+	}, nil
+}
+
+func ipInCIDRTranslate(field string, value []interface{}) (ExprCell, error) {
+	if len(value) == 0 {
+		return nil, errMustNotEmpty
+	}
+
+	return map[string]interface{}{
+		"op":    "ip_in_cidr",
+		"field": field,
+		"value": value,
+	}, nil
+}
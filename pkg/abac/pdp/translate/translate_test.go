@@ -41,6 +41,70 @@ var _ = Describe("Translate", func() {
 			assert.Equal(GinkgoT(), expected, ec)
 		})
 
+		It("ok, env field without type prefix", func() {
+			expected := ExprCell{
+				"op":    "hour_range",
+				"field": "env.hour",
+				"value": []interface{}{9, 18},
+			}
+			expression := types.PolicyCondition{
+				"HourRange": {
+					"env.hour": []interface{}{9, 18},
+				},
+			}
+			ec, err := singleTranslate(expression, "host")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), expected, ec)
+		})
+
+		It("ok, transform field", func() {
+			expected := ExprCell{
+				"op":    "eq",
+				"field": "path_parent(host._bk_iam_path_)",
+				"value": "/biz,1/",
+			}
+			expression := types.PolicyCondition{
+				"StringEquals": {
+					"path_parent(_bk_iam_path_)": []interface{}{"/biz,1/"},
+				},
+			}
+			ec, err := singleTranslate(expression, "host")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), expected, ec)
+		})
+
+		It("ok, subject field", func() {
+			expected := ExprCell{
+				"op":    "in",
+				"field": "subject.groups",
+				"value": []interface{}{"1", "2"},
+			}
+			expression := types.PolicyCondition{
+				"StringEquals": {
+					"subject.groups": []interface{}{"1", "2"},
+				},
+			}
+			ec, err := singleTranslate(expression, "host")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), expected, ec)
+		})
+
+		It("ok, StringEqualsIgnoreCase", func() {
+			expected := ExprCell{
+				"op":    "in",
+				"field": "lower(host.os)",
+				"value": []interface{}{"linux", "windows"},
+			}
+			expression := types.PolicyCondition{
+				"StringEqualsIgnoreCase": {
+					"os": []interface{}{"Linux", "WINDOWS"},
+				},
+			}
+			ec, err := singleTranslate(expression, "host")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), expected, ec)
+		})
+
 		It("ok, AND", func() {
 			expected := ExprCell{
 				"op": "AND",
@@ -169,6 +233,40 @@ var _ = Describe("Translate", func() {
 
 	})
 
+	Describe("notTranslate", func() {
+		It("fail, wrong content length", func() {
+			_, err := notTranslate("job", []interface{}{})
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("fail, wrong value", func() {
+			_, err := notTranslate("job", []interface{}{123})
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("ok", func() {
+			want := ExprCell{
+				"op": "not",
+				"content": []interface{}{
+					ExprCell{
+						"op":    "starts_with",
+						"field": "job._bk_iam_path_",
+						"value": "/folder,1/",
+					},
+				},
+			}
+			ec, err := singleTranslate(types.PolicyCondition{
+				"NOT": {"content": []interface{}{
+					map[string]interface{}{
+						"StringPrefix": map[string]interface{}{"_bk_iam_path_": []interface{}{"/folder,1/"}},
+					},
+				}},
+			}, "job")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), want, ec)
+		})
+	})
+
 	Describe("orTranslate", func() {
 		It("ok, empty", func() {
 			want := ExprCell{
@@ -355,6 +453,67 @@ var _ = Describe("Translate", func() {
 		})
 
 	})
+	Describe("newOrCompareTranslate", func() {
+		It("ok, single value", func() {
+			expected := ExprCell{
+				"op":    "gt",
+				"field": "key",
+				"value": 1,
+			}
+			c, err := newOrCompareTranslate("gt")("key", []interface{}{1})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), expected, c)
+		})
+
+		It("ok, multi value", func() {
+			expected := ExprCell{
+				"op": "OR",
+				"content": []map[string]interface{}{
+					{"op": "lte", "field": "key", "value": 1},
+					{"op": "lte", "field": "key", "value": 2},
+				},
+			}
+			c, err := newOrCompareTranslate("lte")("key", []interface{}{1, 2})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), expected, c)
+		})
+
+		It("fail, empty value", func() {
+			_, err := newOrCompareTranslate("lt")("key", []interface{}{})
+			assert.Equal(GinkgoT(), errMustNotEmpty, err)
+		})
+
+		It("singleTranslate", func() {
+			expected := ExprCell{
+				"op":    "gte",
+				"field": "host.level",
+				"value": 3,
+			}
+			ec, err := singleTranslate(types.PolicyCondition{"NumericGte": {"level": []interface{}{3}}}, "host")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), expected, ec)
+		})
+
+		It("singleTranslate, string pattern", func() {
+			expected := ExprCell{
+				"op":    "wildcard",
+				"field": "host.name",
+				"value": "prod-*",
+			}
+			ec, err := singleTranslate(types.PolicyCondition{"StringWildcard": {"name": []interface{}{"prod-*"}}}, "host")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), expected, ec)
+
+			expected = ExprCell{
+				"op":    "regex",
+				"field": "host.name",
+				"value": "^prod-\\d+$",
+			}
+			ec, err = singleTranslate(types.PolicyCondition{"StringRegex": {"name": []interface{}{"^prod-\\d+$"}}}, "host")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), expected, ec)
+		})
+	})
 	Describe("boolTranslate", func() {
 		It("not support multi value", func() {
 			_, err := boolTranslate("key", []interface{}{true, false})
@@ -373,6 +532,59 @@ var _ = Describe("Translate", func() {
 			assert.Equal(GinkgoT(), expected, c)
 		})
 	})
+	Describe("time conditions translate", func() {
+		It("timeWindowTranslate", func() {
+			_, err := timeWindowTranslate("env.ts", []interface{}{1})
+			assert.Error(GinkgoT(), err)
+
+			c, err := timeWindowTranslate("env.ts", []interface{}{1, 2})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ExprCell{
+				"op":    "time_window",
+				"field": "env.ts",
+				"value": []interface{}{1, 2},
+			}, c)
+		})
+
+		It("hourRangeTranslate", func() {
+			_, err := hourRangeTranslate("env.hour", []interface{}{9, 18, 20})
+			assert.Error(GinkgoT(), err)
+
+			c, err := hourRangeTranslate("env.hour", []interface{}{9, 18})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ExprCell{
+				"op":    "hour_range",
+				"field": "env.hour",
+				"value": []interface{}{9, 18},
+			}, c)
+		})
+
+		It("ipInCIDRTranslate", func() {
+			_, err := ipInCIDRTranslate("env.source_ip", []interface{}{})
+			assert.Equal(GinkgoT(), errMustNotEmpty, err)
+
+			c, err := ipInCIDRTranslate("env.source_ip", []interface{}{"10.0.0.0/8"})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ExprCell{
+				"op":    "ip_in_cidr",
+				"field": "env.source_ip",
+				"value": []interface{}{"10.0.0.0/8"},
+			}, c)
+		})
+
+		It("weekdayInTranslate", func() {
+			_, err := weekdayInTranslate("env.weekday", []interface{}{})
+			assert.Equal(GinkgoT(), errMustNotEmpty, err)
+
+			c, err := weekdayInTranslate("env.weekday", []interface{}{1, 2})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ExprCell{
+				"op":    "weekday_in",
+				"field": "env.weekday",
+				"value": []interface{}{1, 2},
+			}, c)
+		})
+	})
 	Describe("ExprCell.Op", func() {
 		It("ok", func() {
 			cell := ExprCell{
@@ -0,0 +1,138 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package translate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// 表达式输出格式的版本, 由调用方通过 expression_version 指定
+// NOTE: 已发布版本的输出格式不能修改, 格式演进只能新增版本, 避免影响已有的SDK
+const (
+	// v1: 原始格式, {"op": "eq", "field": "host.id", "value": "1"}
+	ExpressionVersionV1 = "v1"
+	// v2: 条件节点增加值类型, {"op": "eq", "field": "host.id", "value": "1", "value_type": "string"}
+	ExpressionVersionV2 = "v2"
+
+	DefaultExpressionVersion = ExpressionVersionV1
+)
+
+// 表达式值的类型
+const (
+	ValueTypeString  = "string"
+	ValueTypeNumeric = "numeric"
+	ValueTypeBool    = "bool"
+	ValueTypeMixed   = "mixed"
+)
+
+// ErrUnsupportedExpressionVersion 不支持的表达式版本
+var ErrUnsupportedExpressionVersion = errors.New("unsupported expression version")
+
+// versionConverter 将v1格式的表达式转换为对应版本的格式
+type versionConverter func(expr ExprCell) (ExprCell, error)
+
+var versionConverters = map[string]versionConverter{
+	ExpressionVersionV1: func(expr ExprCell) (ExprCell, error) { return expr, nil },
+	ExpressionVersionV2: convertToV2,
+}
+
+// ConvertExpressionVersion 将translate生成的(v1)表达式转换为指定版本的格式, version为空时使用默认版本
+func ConvertExpressionVersion(expr map[string]interface{}, version string) (map[string]interface{}, error) {
+	if version == "" {
+		version = DefaultExpressionVersion
+	}
+
+	converter, ok := versionConverters[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: `%s`", ErrUnsupportedExpressionVersion, version)
+	}
+
+	// 空表达式代表没有权限, 所有版本都一致
+	if len(expr) == 0 {
+		return expr, nil
+	}
+	return converter(expr)
+}
+
+func convertToV2(expr ExprCell) (ExprCell, error) {
+	op, ok := expr["op"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid expression %+v, op required", expr)
+	}
+
+	switch op {
+	case "AND", "OR", "not":
+		cells, err := exprCellContent(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		content := make([]ExprCell, 0, len(cells))
+		for _, cell := range cells {
+			converted, err := convertToV2(cell)
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, converted)
+		}
+		return ExprCell{
+			"op":      op,
+			"content": content,
+		}, nil
+	default:
+		converted := make(ExprCell, len(expr)+1)
+		for k, v := range expr {
+			converted[k] = v
+		}
+		// any的值为空, 不需要类型
+		if valueType := exprValueType(expr["value"]); valueType != "" {
+			converted["value_type"] = valueType
+		}
+		return converted, nil
+	}
+}
+
+// exprValueType 值的类型, 数组时为元素的类型, 元素类型不一致时为mixed, 无法判断时为空
+func exprValueType(value interface{}) string {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return scalarValueType(value)
+	}
+
+	valueType := ""
+	for i := 0; i < rv.Len(); i++ {
+		t := scalarValueType(rv.Index(i).Interface())
+		switch {
+		case t == "":
+			return ""
+		case valueType == "":
+			valueType = t
+		case valueType != t:
+			return ValueTypeMixed
+		}
+	}
+	return valueType
+}
+
+func scalarValueType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return ValueTypeString
+	case bool:
+		return ValueTypeBool
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return ValueTypeNumeric
+	default:
+		return ""
+	}
+}
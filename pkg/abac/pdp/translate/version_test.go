@@ -0,0 +1,102 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package translate
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ = Describe("Version", func() {
+	Describe("ConvertExpressionVersion", func() {
+		expr := map[string]interface{}{
+			"op": "AND",
+			"content": []ExprCell{
+				{"op": "in", "field": "host.id", "value": []interface{}{"1", "2"}},
+				{"op": "gt", "field": "host.cpu", "value": float64(4)},
+				{
+					"op": "not",
+					"content": []map[string]interface{}{
+						{"op": "eq", "field": "host.online", "value": true},
+					},
+				},
+			},
+		}
+
+		It("unsupported version", func() {
+			_, err := ConvertExpressionVersion(expr, "v0")
+			assert.Error(GinkgoT(), err)
+			assert.True(GinkgoT(), errors.Is(err, ErrUnsupportedExpressionVersion))
+		})
+
+		It("default v1, no change", func() {
+			converted, err := ConvertExpressionVersion(expr, "")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), expr, converted)
+
+			converted, err = ConvertExpressionVersion(expr, ExpressionVersionV1)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), expr, converted)
+		})
+
+		It("empty, no permission", func() {
+			converted, err := ConvertExpressionVersion(map[string]interface{}{}, ExpressionVersionV2)
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), converted)
+		})
+
+		It("v2 any", func() {
+			converted, err := ConvertExpressionVersion(map[string]interface{}{
+				"op": "any", "field": "", "value": []interface{}{},
+			}, ExpressionVersionV2)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]interface{}{
+				"op": "any", "field": "", "value": []interface{}{},
+			}, converted)
+		})
+
+		It("v2 typed values", func() {
+			converted, err := ConvertExpressionVersion(expr, ExpressionVersionV2)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]interface{}{
+				"op": "AND",
+				"content": []ExprCell{
+					{"op": "in", "field": "host.id", "value": []interface{}{"1", "2"}, "value_type": "string"},
+					{"op": "gt", "field": "host.cpu", "value": float64(4), "value_type": "numeric"},
+					{
+						"op": "not",
+						"content": []ExprCell{
+							{"op": "eq", "field": "host.online", "value": true, "value_type": "bool"},
+						},
+					},
+				},
+			}, converted)
+			// 原表达式不会被修改
+			assert.NotContains(GinkgoT(), expr["content"].([]ExprCell)[0], "value_type")
+		})
+
+		It("v2 mixed values", func() {
+			converted, err := ConvertExpressionVersion(map[string]interface{}{
+				"op": "in", "field": "host.id", "value": []interface{}{"1", 2},
+			}, ExpressionVersionV2)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ValueTypeMixed, converted["value_type"])
+		})
+
+		It("v2 invalid expression", func() {
+			_, err := ConvertExpressionVersion(map[string]interface{}{
+				"op": "AND", "content": "bad",
+			}, ExpressionVersionV2)
+			assert.Error(GinkgoT(), err)
+		})
+	})
+})
@@ -13,6 +13,7 @@ package types
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"iam/pkg/abac/types"
 	"iam/pkg/abac/types/request"
@@ -29,13 +30,21 @@ PDP模块表达式求值
 type ExprContext struct {
 	*request.Request
 	Resource *types.Resource
+	// Now 请求时间, 用于环境属性(env.*)求值
+	Now time.Time
 }
 
 // NewExprContext new context
 func NewExprContext(ctx *request.Request, resource *types.Resource) *ExprContext {
+	now := ctx.Env.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
 	return &ExprContext{
 		Request:  ctx,
 		Resource: resource,
+		Now:      now,
 	}
 }
 
@@ -54,13 +63,18 @@ func (c *ExprContext) GetFullNameAttr(name string) (interface{}, error) {
 		return c.getActionAttr(parts[1])
 	case "subject":
 		return c.getSubjectAttr(parts[1])
+	case "env":
+		return c.getEnvAttr(parts[1])
 	default:
 		return nil, fmt.Errorf("name not support %s", name)
 	}
 }
 
-// GetAttr 获取资源的属性值
+// GetAttr 获取资源的属性值, 带subject.前缀时获取subject的属性值
 func (c *ExprContext) GetAttr(name string) (interface{}, error) {
+	if IsSubjectAttrKey(name) {
+		return c.getSubjectAttr(strings.TrimPrefix(name, subjectKeyPrefix))
+	}
 	return c.getResourceAttr(name)
 }
 
@@ -89,7 +103,36 @@ func (c *ExprContext) getSubjectAttr(name string) (interface{}, error) {
 		return c.Subject.Type, nil
 	case "id":
 		return c.Subject.ID, nil
-	default: // subject 的公用属性暂时只有group相关信息, 存储为interface{}, 暂不处理
+	case "group", "groups":
+		return c.getSubjectGroupIDs()
+	case "department", "departments":
+		return c.getSubjectDepartmentIDs()
+	default:
+		// 外部属性提供方提供的属性
+		if c.Subject.Attribute != nil {
+			if value, ok := c.Subject.Attribute.GetExtAttribute(name); ok {
+				return value, nil
+			}
+		}
 		return nil, nil
 	}
 }
+
+func (c *ExprContext) getEnvAttr(name string) (interface{}, error) {
+	switch name {
+	case "time", "ts": // ts为time的旧名称, 保留兼容
+		return c.Now.Unix(), nil
+	case "hour":
+		return int64(c.Now.Hour()), nil
+	case "weekday":
+		return int64(c.Now.Weekday()), nil
+	case "tz":
+		return c.Now.Location().String(), nil
+	case "client_ip", "source_ip": // source_ip为client_ip的旧名称, 保留兼容
+		return c.Env.ClientIP, nil
+	case "source_app":
+		return c.Env.SourceApp, nil
+	default:
+		return nil, fmt.Errorf("env attribute not support %s", name)
+	}
+}
@@ -11,6 +11,8 @@
 package types
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	"github.com/stretchr/testify/assert"
 
@@ -82,6 +84,76 @@ var _ = Describe("Context", func() {
 			assert.Equal(GinkgoT(), "admin", a)
 		})
 
+		It("ok env attr", func() {
+			// 2021-08-02 10:30:00 Monday
+			c.Now = time.Date(2021, 8, 2, 10, 30, 0, 0, time.Local)
+
+			a, err := c.GetFullNameAttr("env.hour")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(10), a)
+
+			a, err = c.GetFullNameAttr("env.weekday")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(1), a)
+
+			a, err = c.GetFullNameAttr("env.ts")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), c.Now.Unix(), a)
+		})
+
+		It("ok env source_ip", func() {
+			c.Env.ClientIP = "10.0.0.1"
+
+			a, err := c.GetFullNameAttr("env.source_ip")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "10.0.0.1", a)
+
+			a, err = c.GetFullNameAttr("env.client_ip")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "10.0.0.1", a)
+		})
+
+		It("ok env source_app", func() {
+			c.Env.SourceApp = "bk_paas"
+
+			a, err := c.GetFullNameAttr("env.source_app")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "bk_paas", a)
+		})
+
+		It("ok env time and tz", func() {
+			c.Now = time.Date(2021, 8, 2, 10, 30, 0, 0, time.UTC)
+
+			a, err := c.GetFullNameAttr("env.time")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), c.Now.Unix(), a)
+
+			a, err = c.GetFullNameAttr("env.tz")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "UTC", a)
+		})
+
+		It("ok env from request", func() {
+			loc := time.FixedZone("UTC+8", 8*3600)
+			req := request.NewRequest()
+			// 2021-08-02 02:30:00 UTC = 2021-08-02 10:30:00 UTC+8
+			req.Env.Time = time.Date(2021, 8, 2, 2, 30, 0, 0, time.UTC).In(loc)
+			ctx := NewExprContext(req, &types.Resource{})
+
+			a, err := ctx.GetFullNameAttr("env.hour")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(10), a)
+
+			a, err = ctx.GetFullNameAttr("env.tz")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "UTC+8", a)
+		})
+
+		It("env attr not support", func() {
+			_, err := c.GetFullNameAttr("env.abc")
+			assert.Error(GinkgoT(), err)
+		})
+
 	})
 
 	Describe("GetAttr", func() {
@@ -91,6 +163,12 @@ var _ = Describe("Context", func() {
 			assert.Equal(GinkgoT(), "job1", a)
 		})
 
+		It("ok subject attr", func() {
+			a, err := c.GetAttr("subject.id")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "admin", a)
+		})
+
 	})
 
 	Describe("getResourceAttr", func() {
@@ -150,4 +228,74 @@ var _ = Describe("Context", func() {
 		})
 
 	})
+
+	Describe("subject multi-valued attrs", func() {
+		var retriever SubjectAttrRetriever
+		BeforeEach(func() {
+			retriever = subjectAttrRetriever
+			RegisterSubjectAttrRetriever(mockSubjectAttrRetriever{
+				deptGroups: []types.SubjectGroup{
+					{PK: 2, PolicyExpiredAt: 4102444800},
+					{PK: 3, PolicyExpiredAt: 1},
+				},
+				ids: map[int64]string{1: "g1", 2: "g2", 3: "g3", 10: "d10"},
+			})
+
+			c.Now = time.Date(2021, 8, 2, 10, 30, 0, 0, time.UTC)
+			c.Subject.Attribute = types.NewSubjectAttribute()
+			c.Subject.FillAttributes(
+				100,
+				[]types.SubjectGroup{{PK: 1, PolicyExpiredAt: 4102444800}, {PK: 2, PolicyExpiredAt: 4102444800}},
+				[]int64{10},
+			)
+		})
+		AfterEach(func() {
+			RegisterSubjectAttrRetriever(retriever)
+		})
+
+		It("ok groups", func() {
+			a, err := c.GetAttr("subject.groups")
+			assert.NoError(GinkgoT(), err)
+			assert.ElementsMatch(GinkgoT(), []interface{}{"g1", "g2"}, a)
+
+			a, err = c.GetFullNameAttr("subject.group")
+			assert.NoError(GinkgoT(), err)
+			assert.ElementsMatch(GinkgoT(), []interface{}{"g1", "g2"}, a)
+		})
+
+		It("ok departments", func() {
+			a, err := c.GetAttr("subject.departments")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []interface{}{"d10"}, a)
+		})
+
+		It("fail subject attribute not filled", func() {
+			c.Subject.Attribute = nil
+			_, err := c.GetAttr("subject.groups")
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("fail retriever not registered", func() {
+			RegisterSubjectAttrRetriever(nil)
+			_, err := c.GetAttr("subject.departments")
+			assert.ErrorIs(GinkgoT(), err, ErrSubjectAttrRetrieverNotRegistered)
+		})
+	})
 })
+
+type mockSubjectAttrRetriever struct {
+	deptGroups []types.SubjectGroup
+	ids        map[int64]string
+}
+
+func (r mockSubjectAttrRetriever) ListDepartmentEffectGroups(deptPKs []int64) ([]types.SubjectGroup, error) {
+	return r.deptGroups, nil
+}
+
+func (r mockSubjectAttrRetriever) ListSubjectIDsByPKs(pks []int64) ([]string, error) {
+	ids := make([]string, 0, len(pks))
+	for _, pk := range pks {
+		ids = append(ids, r.ids[pk])
+	}
+	return ids, nil
+}
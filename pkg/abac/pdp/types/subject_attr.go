@@ -0,0 +1,126 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package types
+
+import (
+	"errors"
+	"strings"
+
+	"iam/pkg/abac/types"
+	"iam/pkg/util"
+)
+
+/*
+subject的多值属性
+
+条件中可以引用subject的用户组/部门, 值为id, 例如:
+	{"StringEquals": {"subject.groups": ["1", "2"]}}
+	{"StringEquals": {"subject.departments": ["10"]}}
+
+subject.group/subject.department 为等价的单数写法
+
+外部属性提供方(http回调/静态映射)提供的属性同样以subject.前缀引用, 例如:
+	{"NumericGte": {"subject.job_level": [3]}}
+*/
+
+// subjectKeyPrefix 条件中带subject.前缀的key为subject的属性
+const subjectKeyPrefix = "subject."
+
+// ErrSubjectAttrRetrieverNotRegistered ...
+var ErrSubjectAttrRetrieverNotRegistered = errors.New("subject attribute retriever not registered")
+
+// IsSubjectAttrKey 条件的key是否为subject的属性, subject属性不需要查询资源属性
+func IsSubjectAttrKey(key string) bool {
+	return strings.HasPrefix(key, subjectKeyPrefix)
+}
+
+// SubjectAttrRetriever 查询subject的用户组/部门信息
+// NOTE: 缓存层依赖本包, 所以由pdp注册实现, 避免循环引用
+type SubjectAttrRetriever interface {
+	ListDepartmentEffectGroups(deptPKs []int64) ([]types.SubjectGroup, error)
+	ListSubjectIDsByPKs(pks []int64) ([]string, error)
+}
+
+var subjectAttrRetriever SubjectAttrRetriever
+
+// RegisterSubjectAttrRetriever 注册subject属性的查询实现, 一般在pdp初始化时注册
+func RegisterSubjectAttrRetriever(retriever SubjectAttrRetriever) {
+	subjectAttrRetriever = retriever
+}
+
+// getSubjectGroupIDs subject生效的用户组id, 包含直接加入的以及通过部门继承的, 多值属性
+func (c *ExprContext) getSubjectGroupIDs() (interface{}, error) {
+	if subjectAttrRetriever == nil {
+		return nil, ErrSubjectAttrRetrieverNotRegistered
+	}
+	if c.Subject.Attribute == nil {
+		return nil, errors.New("subject attribute not filled")
+	}
+
+	groups, err := c.Subject.Attribute.GetGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	deptPKs, err := c.Subject.GetDepartmentPKs()
+	if err != nil {
+		return nil, err
+	}
+	deptGroups, err := subjectAttrRetriever.ListDepartmentEffectGroups(deptPKs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 多个部门可能加入同一个用户组, 需要去重
+	now := c.Now.Unix()
+	groupPKSet := util.NewFixedLengthInt64Set(len(groups) + len(deptGroups))
+	for _, gs := range [][]types.SubjectGroup{groups, deptGroups} {
+		for _, group := range gs {
+			if group.PolicyExpiredAt > now {
+				groupPKSet.Add(group.PK)
+			}
+		}
+	}
+
+	ids, err := subjectAttrRetriever.ListSubjectIDsByPKs(groupPKSet.ToSlice())
+	if err != nil {
+		return nil, err
+	}
+	return toInterfaceSlice(ids), nil
+}
+
+// getSubjectDepartmentIDs subject所属的部门id, 多值属性
+func (c *ExprContext) getSubjectDepartmentIDs() (interface{}, error) {
+	if subjectAttrRetriever == nil {
+		return nil, ErrSubjectAttrRetrieverNotRegistered
+	}
+	if c.Subject.Attribute == nil {
+		return nil, errors.New("subject attribute not filled")
+	}
+
+	deptPKs, err := c.Subject.GetDepartmentPKs()
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := subjectAttrRetriever.ListSubjectIDsByPKs(deptPKs)
+	if err != nil {
+		return nil, err
+	}
+	return toInterfaceSlice(ids), nil
+}
+
+func toInterfaceSlice(values []string) []interface{} {
+	s := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		s = append(s, v)
+	}
+	return s
+}
@@ -0,0 +1,64 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package util
+
+import (
+	"regexp"
+	"strings"
+)
+
+/*
+属性值转换
+
+条件的属性key可以是一个转换函数, 求值前先对属性值做转换, 例如:
+	{"StringEquals": {"lower(name)": ["admin"]}}
+	{"StringEquals": {"split(tags, ;)": ["prod"]}}
+	{"StringEquals": {"path_parent(_bk_iam_path_)": ["/biz,1/"]}}
+
+函数只能作用于属性本身, 不支持嵌套
+*/
+
+var attrTransformRegex = regexp.MustCompile(`^([a-z_]+)\(\s*([^,()\s]+)\s*(?:,\s*(.*?)\s*)?\)$`)
+
+// AttrTransform 属性key中解析出的转换函数
+type AttrTransform struct {
+	Func string
+	Attr string
+	// Arg 函数的参数, 例如 split 的分隔符, 可选
+	Arg string
+}
+
+// ParseAttrTransform 解析属性key中的转换函数, 不是函数形式时返回false
+func ParseAttrTransform(key string) (AttrTransform, bool) {
+	matches := attrTransformRegex.FindStringSubmatch(key)
+	if matches == nil {
+		return AttrTransform{}, false
+	}
+
+	return AttrTransform{
+		Func: matches[1],
+		Attr: matches[2],
+		Arg:  matches[3],
+	}, true
+}
+
+// Field 用新的属性名重新生成函数形式的key, 例如 lower(name) => lower(host.name)
+func (t AttrTransform) Field(attr string) string {
+	var sb strings.Builder
+	sb.WriteString(t.Func)
+	sb.WriteByte('(')
+	sb.WriteString(attr)
+	if t.Arg != "" {
+		sb.WriteString(", ")
+		sb.WriteString(t.Arg)
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
@@ -63,4 +63,32 @@ var _ = Describe("Util", func() {
 			assert.Equal(GinkgoT(), util.ErrTypeAssertFail, err)
 		})
 	})
+
+	Describe("ParseAttrTransform", func() {
+		It("not transform", func() {
+			_, ok := util.ParseAttrTransform("name")
+			assert.False(GinkgoT(), ok)
+
+			_, ok = util.ParseAttrTransform("lower(upper(name))")
+			assert.False(GinkgoT(), ok)
+		})
+
+		It("ok", func() {
+			t, ok := util.ParseAttrTransform("lower(name)")
+			assert.True(GinkgoT(), ok)
+			assert.Equal(GinkgoT(), util.AttrTransform{Func: "lower", Attr: "name"}, t)
+			assert.Equal(GinkgoT(), "lower(host.name)", t.Field("host.name"))
+		})
+
+		It("ok, with arg", func() {
+			t, ok := util.ParseAttrTransform("split( tags , ; )")
+			assert.True(GinkgoT(), ok)
+			assert.Equal(GinkgoT(), util.AttrTransform{Func: "split", Attr: "tags", Arg: ";"}, t)
+			assert.Equal(GinkgoT(), "split(host.tags, ;)", t.Field("host.tags"))
+
+			t, ok = util.ParseAttrTransform("split(tags, ,)")
+			assert.True(GinkgoT(), ok)
+			assert.Equal(GinkgoT(), ",", t.Arg)
+		})
+	})
 })
@@ -41,3 +41,13 @@ func GetActionDetail(system, id string) (pk int64, arts []types.ActionResourceTy
 	}
 	return pk, arts, nil
 }
+
+// IsActionDisabled 操作是否被接入系统置为disabled
+func IsActionDisabled(system, id string) (bool, error) {
+	disabledActions, err := impls.GetSystemDisabledActions(system)
+	if err != nil {
+		return false, errorx.Wrapf(err, ActionPIP, "IsActionDisabled",
+			"impls.GetSystemDisabledActions system=`%s` fail", system)
+	}
+	return disabledActions.Has(id), nil
+}
@@ -21,6 +21,7 @@ import (
 	"iam/pkg/abac/pip"
 	"iam/pkg/cache/impls"
 	"iam/pkg/service/types"
+	"iam/pkg/util"
 )
 
 var _ = Describe("Action", func() {
@@ -63,4 +64,35 @@ var _ = Describe("Action", func() {
 		})
 
 	})
+
+	Describe("IsActionDisabled", func() {
+		var patches *gomonkey.Patches
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("GetSystemDisabledActions fail", func() {
+			patches = gomonkey.ApplyFunc(impls.GetSystemDisabledActions, func(system string) (*util.StringSet, error) {
+				return nil, errors.New("get GetSystemDisabledActions fail")
+			})
+
+			_, err := pip.IsActionDisabled("bk_test", "edit")
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "get GetSystemDisabledActions fail")
+		})
+
+		It("ok", func() {
+			patches = gomonkey.ApplyFunc(impls.GetSystemDisabledActions, func(system string) (*util.StringSet, error) {
+				return util.NewStringSetWithValues([]string{"edit"}), nil
+			})
+
+			disabled, err := pip.IsActionDisabled("bk_test", "edit")
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), disabled)
+
+			disabled, err = pip.IsActionDisabled("bk_test", "view")
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), disabled)
+		})
+	})
 })
@@ -11,6 +11,8 @@
 package pip
 
 import (
+	"context"
+
 	"iam/pkg/cache/impls"
 	"iam/pkg/errorx"
 )
@@ -19,7 +21,11 @@ import (
 const ResourcePIP = "ResourcePIP"
 
 // QueryRemoteResourceAttribute 查询被依赖资源的属性
-func QueryRemoteResourceAttribute(system, _type, id string, keys []string) (map[string]interface{}, error) {
+func QueryRemoteResourceAttribute(
+	ctx context.Context,
+	system, _type, id string,
+	keys []string,
+) (map[string]interface{}, error) {
 	// if no keys, return without query
 	// 如果不需要属性, iam不查询第三方, 不负责校验id的存在与否
 	if len(keys) == 0 || (len(keys) == 1 && keys[0] == "id") {
@@ -28,7 +34,7 @@ func QueryRemoteResourceAttribute(system, _type, id string, keys []string) (map[
 		}, nil
 	}
 
-	resource, err := impls.GetRemoteResource(system, _type, id, keys)
+	resource, err := impls.GetRemoteResource(ctx, system, _type, id, keys)
 	if err != nil {
 		err = errorx.Wrapf(err, ResourcePIP, "QueryRemoteResourceAttribute",
 			"impls.GetRemoteResource system=`%s`, _type=`%s`, id=`%s`, keys=`%+v` fail",
@@ -41,6 +47,7 @@ func QueryRemoteResourceAttribute(system, _type, id string, keys []string) (map[
 
 // BatchQueryRemoteResourcesAttribute 批量查询资源的属性 without cache
 func BatchQueryRemoteResourcesAttribute(
+	ctx context.Context,
 	system, _type string, ids []string, keys []string,
 ) ([]map[string]interface{}, error) {
 	if len(keys) == 0 || (len(keys) == 1 && keys[0] == "id") {
@@ -53,7 +60,7 @@ func BatchQueryRemoteResourcesAttribute(
 		return resources, nil
 	}
 
-	resources, err := impls.ListRemoteResources(system, _type, ids, keys)
+	resources, err := impls.ListRemoteResources(ctx, system, _type, ids, keys)
 	if err != nil {
 		err = errorx.Wrapf(err, ResourcePIP, "BatchQueryRemoteResourcesAttribute",
 			"listRemoteResources system=`%s`, _type=`%s`, ids=`%+v`, keys=`%+v` fail",
@@ -63,3 +70,15 @@ func BatchQueryRemoteResourcesAttribute(
 
 	return resources, nil
 }
+
+// GetResourceAttributeSchema 查询资源类型注册的属性schema {attribute_name: attribute_type}, 未注册时返回空
+func GetResourceAttributeSchema(system, _type string) (map[string]string, error) {
+	schemas, err := impls.GetResourceAttributeSchemas(system)
+	if err != nil {
+		err = errorx.Wrapf(err, ResourcePIP, "GetResourceAttributeSchema",
+			"impls.GetResourceAttributeSchemas system=`%s` fail", system)
+		return nil, err
+	}
+
+	return schemas[_type], nil
+}
@@ -11,6 +11,7 @@
 package pip_test
 
 import (
+	"context"
 	"errors"
 
 	"github.com/agiledragon/gomonkey"
@@ -38,7 +39,7 @@ var _ = Describe("Resource", func() {
 		})
 
 		It("keys empty", func() {
-			d, err := pip.QueryRemoteResourceAttribute("bk_test", "app", "demo123", []string{})
+			d, err := pip.QueryRemoteResourceAttribute(context.Background(), "bk_test", "app", "demo123", []string{})
 			assert.NoError(GinkgoT(), err)
 			assert.Len(GinkgoT(), d, 1)
 			id, ok := d["id"]
@@ -48,7 +49,7 @@ var _ = Describe("Resource", func() {
 
 		It("keys only have id", func() {
 			d, err := pip.QueryRemoteResourceAttribute(
-				"bk_test", "app", "demo123", []string{"id"})
+				context.Background(), "bk_test", "app", "demo123", []string{"id"})
 			assert.NoError(GinkgoT(), err)
 			assert.Len(GinkgoT(), d, 1)
 			id, ok := d["id"]
@@ -58,12 +59,12 @@ var _ = Describe("Resource", func() {
 
 		It("GetRemoteResource fail", func() {
 			patches = gomonkey.ApplyFunc(impls.GetRemoteResource,
-				func(system, _type, id string, keys []string) (map[string]interface{}, error) {
+				func(ctx context.Context, system, _type, id string, keys []string) (map[string]interface{}, error) {
 					return nil, errors.New("get remote resource fail")
 				})
 
 			_, err := pip.QueryRemoteResourceAttribute(
-				"bk_test", "app", "demo123", []string{"id", "name"})
+				context.Background(), "bk_test", "app", "demo123", []string{"id", "name"})
 			assert.Error(GinkgoT(), err)
 			assert.Contains(GinkgoT(), err.Error(), "get remote resource fail")
 		})
@@ -73,12 +74,12 @@ var _ = Describe("Resource", func() {
 				"hello": 1,
 			}
 			patches = gomonkey.ApplyFunc(impls.GetRemoteResource,
-				func(system, _type, id string, keys []string) (map[string]interface{}, error) {
+				func(ctx context.Context, system, _type, id string, keys []string) (map[string]interface{}, error) {
 					return want, nil
 				})
 
 			r, err := pip.QueryRemoteResourceAttribute(
-				"bk_test", "app", "demo123", []string{"id", "name"})
+				context.Background(), "bk_test", "app", "demo123", []string{"id", "name"})
 			assert.NoError(GinkgoT(), err)
 			assert.Equal(GinkgoT(), want, r)
 
@@ -109,7 +110,7 @@ var _ = Describe("Resource", func() {
 
 		It("keys empty", func() {
 			d, err := pip.BatchQueryRemoteResourcesAttribute(
-				"bk_test", "app", []string{"demo123", "demo456"}, []string{})
+				context.Background(), "bk_test", "app", []string{"demo123", "demo456"}, []string{})
 			assert.NoError(GinkgoT(), err)
 			assert.Len(GinkgoT(), d, 2)
 			assert.Equal(GinkgoT(), wantIDAttrs, d)
@@ -117,7 +118,7 @@ var _ = Describe("Resource", func() {
 
 		It("keys only have id", func() {
 			d, err := pip.BatchQueryRemoteResourcesAttribute(
-				"bk_test", "app", []string{"demo123", "demo456"}, []string{"id"})
+				context.Background(), "bk_test", "app", []string{"demo123", "demo456"}, []string{"id"})
 			assert.NoError(GinkgoT(), err)
 			assert.Len(GinkgoT(), d, 2)
 			assert.Equal(GinkgoT(), wantIDAttrs, d)
@@ -125,12 +126,14 @@ var _ = Describe("Resource", func() {
 
 		It("ListRemoteResources fail", func() {
 			patches = gomonkey.ApplyFunc(impls.ListRemoteResources,
-				func(system, _type string, ids []string, keys []string) ([]map[string]interface{}, error) {
+				func(
+					ctx context.Context, system, _type string, ids []string, keys []string,
+				) ([]map[string]interface{}, error) {
 					return nil, errors.New("list remote resource fail")
 				})
 
 			_, err := pip.BatchQueryRemoteResourcesAttribute(
-				"bk_test", "app", []string{"demo123", "demo456"}, []string{"id", "name"})
+				context.Background(), "bk_test", "app", []string{"demo123", "demo456"}, []string{"id", "name"})
 			assert.Error(GinkgoT(), err)
 			assert.Contains(GinkgoT(), err.Error(), "list remote resource fail")
 		})
@@ -145,12 +148,14 @@ var _ = Describe("Resource", func() {
 				},
 			}
 			patches = gomonkey.ApplyFunc(impls.ListRemoteResources,
-				func(system, _type string, ids []string, keys []string) ([]map[string]interface{}, error) {
+				func(
+					ctx context.Context, system, _type string, ids []string, keys []string,
+				) ([]map[string]interface{}, error) {
 					return want, nil
 				})
 
 			r, err := pip.BatchQueryRemoteResourcesAttribute(
-				"bk_test", "app", []string{"demo123", "demo456"}, []string{"id", "name"})
+				context.Background(), "bk_test", "app", []string{"demo123", "demo456"}, []string{"id", "name"})
 			assert.NoError(GinkgoT(), err)
 			assert.Equal(GinkgoT(), want, r)
 
@@ -11,6 +11,8 @@
 package pip
 
 import (
+	"fmt"
+
 	"iam/pkg/abac/types"
 	"iam/pkg/cache/impls"
 	"iam/pkg/errorx"
@@ -34,6 +36,9 @@ func convertSubjectGroups(subjectGroups []svctypes.ThinSubjectGroup) []types.Sub
 
 // GetSubjectPK 获取subject的PK, note this will cache in local for 1 minutes
 func GetSubjectPK(_type, id string) (int64, error) {
+	// 记录热点subject, 用于服务启动时的缓存预热
+	impls.RecordHotSubject(_type, id)
+
 	// pk, err := impls.GetSubjectPK(_type, id)
 	pk, err := impls.GetLocalSubjectPK(_type, id)
 	if err != nil {
@@ -44,6 +49,16 @@ func GetSubjectPK(_type, id string) (int64, error) {
 	return pk, err
 }
 
+// IsSubjectFrozen subject是否被冻结, 冻结的subject没有任何权限
+func IsSubjectFrozen(pk int64) (bool, error) {
+	frozen, err := impls.IsSubjectFrozen(pk)
+	if err != nil {
+		return false, errorx.Wrapf(err, SubjectPIP, "IsSubjectFrozen",
+			"impls.IsSubjectFrozen pk=`%d` fail", pk)
+	}
+	return frozen, nil
+}
+
 // GetSubjectDetail ...
 func GetSubjectDetail(pk int64) (departments []int64, groups []types.SubjectGroup, err error) {
 	detail, err := impls.GetSubjectDetail(pk)
@@ -53,7 +68,96 @@ func GetSubjectDetail(pk int64) (departments []int64, groups []types.SubjectGrou
 		return
 	}
 
+	// 展开用户组加入的用户组
+	subjectGroups, err := impls.ExpandNestedGroups(detail.SubjectGroups)
+	if err != nil {
+		err = errorx.Wrapf(err, SubjectPIP, "GetSubjectDetail",
+			"impls.ExpandNestedGroups pk=`%d` fail", pk)
+		return
+	}
+
 	departments = detail.DepartmentPKs
-	groups = convertSubjectGroups(detail.SubjectGroups)
+	groups = convertSubjectGroups(subjectGroups)
 	return departments, groups, nil
 }
+
+// ListSubjectIDsByPKs 将subject pk转换为id, 用于条件中引用subject的用户组/部门属性
+func ListSubjectIDsByPKs(pks []int64) ([]string, error) {
+	ids := make([]string, 0, len(pks))
+	for _, pk := range pks {
+		subject, err := impls.GetSubjectByPK(pk)
+		if err != nil {
+			return nil, errorx.Wrapf(err, SubjectPIP, "ListSubjectIDsByPKs",
+				"impls.GetSubjectByPK pk=`%d` fail", pk)
+		}
+		ids = append(ids, subject.ID)
+	}
+	return ids, nil
+}
+
+// ListDepartmentEffectGroups 获取部门加入的有效用户组, 包含用户组加入的用户组
+func ListDepartmentEffectGroups(deptPKs []int64) ([]types.SubjectGroup, error) {
+	if len(deptPKs) == 0 {
+		return []types.SubjectGroup{}, nil
+	}
+
+	subjectGroups, err := impls.ListSubjectEffectGroups(deptPKs)
+	if err != nil {
+		return nil, errorx.Wrapf(err, SubjectPIP, "ListDepartmentEffectGroups",
+			"impls.ListSubjectEffectGroups deptPKs=`%+v` fail", deptPKs)
+	}
+
+	subjectGroups, err = impls.ExpandNestedGroups(subjectGroups)
+	if err != nil {
+		return nil, errorx.Wrapf(err, SubjectPIP, "ListDepartmentEffectGroups",
+			"impls.ExpandNestedGroups deptPKs=`%+v` fail", deptPKs)
+	}
+	return convertSubjectGroups(subjectGroups), nil
+}
+
+// GetSubjectExtAttributes 查询外部属性提供方提供的subject属性, 只保留提供方声明的属性
+// 某个提供方查询失败时, 返回其他提供方的属性以及失败的错误
+func GetSubjectExtAttributes(_type, id string) (map[string]interface{}, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectPIP, "GetSubjectExtAttributes")
+
+	providers, err := impls.ListSubjectAttributeProviders()
+	if err != nil {
+		return nil, errorWrapf(err, "impls.ListSubjectAttributeProviders fail")
+	}
+	if len(providers) == 0 {
+		return nil, nil
+	}
+
+	attrs := make(map[string]interface{})
+	var firstErr error
+	for _, provider := range providers {
+		var provided map[string]interface{}
+		switch provider.Type {
+		case svctypes.SubjectAttributeProviderTypeStatic:
+			provided = provider.Config.Mapping[_type+":"+id]
+		case svctypes.SubjectAttributeProviderTypeHTTP:
+			provided, err = impls.GetSubjectProvidedAttributes(provider.ID, _type, id)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = errorWrapf(err,
+						"impls.GetSubjectProvidedAttributes provider=`%s`, _type=`%s`, id=`%s` fail",
+						provider.ID, _type, id)
+				}
+				continue
+			}
+		default:
+			if firstErr == nil {
+				firstErr = errorWrapf(fmt.Errorf("unsupported provider type %s", provider.Type),
+					"provider=`%s`", provider.ID)
+			}
+			continue
+		}
+
+		for _, name := range provider.Attributes {
+			if value, ok := provided[name]; ok {
+				attrs[name] = value
+			}
+		}
+	}
+	return attrs, firstErr
+}
@@ -102,12 +102,219 @@ var _ = Describe("Subject", func() {
 				}, nil
 			})
 
+			patches.ApplyFunc(impls.BatchListSubjectEffectGroups,
+				func(pks []int64) (map[int64][]svctypes.ThinSubjectGroup, error) {
+					return map[int64][]svctypes.ThinSubjectGroup{}, nil
+				})
+
 			depts, groups, err := pip.GetSubjectDetail(123)
 			assert.NoError(GinkgoT(), err)
 			assert.Equal(GinkgoT(), []int64{1, 2, 3}, depts)
 			assert.Equal(GinkgoT(), want, groups)
 		})
 
+		It("ok, expand the nested groups", func() {
+			patches = gomonkey.ApplyFunc(impls.GetSubjectDetail, func(pk int64) (svctypes.SubjectDetail, error) {
+				return svctypes.SubjectDetail{
+					DepartmentPKs: []int64{},
+					SubjectGroups: []svctypes.ThinSubjectGroup{{PK: 1, PolicyExpiredAt: 123}},
+				}, nil
+			})
+			patches.ApplyFunc(impls.BatchListSubjectEffectGroups,
+				func(pks []int64) (map[int64][]svctypes.ThinSubjectGroup, error) {
+					return map[int64][]svctypes.ThinSubjectGroup{
+						1: {{PK: 2, PolicyExpiredAt: 456}},
+					}, nil
+				})
+
+			_, groups, err := pip.GetSubjectDetail(123)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.SubjectGroup{
+				{PK: 1, PolicyExpiredAt: 123},
+				{PK: 2, PolicyExpiredAt: 123},
+			}, groups)
+		})
+
+		It("ExpandNestedGroups fail", func() {
+			patches = gomonkey.ApplyFunc(impls.GetSubjectDetail, func(pk int64) (svctypes.SubjectDetail, error) {
+				return svctypes.SubjectDetail{
+					SubjectGroups: []svctypes.ThinSubjectGroup{{PK: 1, PolicyExpiredAt: 123}},
+				}, nil
+			})
+			patches.ApplyFunc(impls.ExpandNestedGroups,
+				func(groups []svctypes.ThinSubjectGroup) ([]svctypes.ThinSubjectGroup, error) {
+					return nil, errors.New("expand fail")
+				})
+
+			_, _, err := pip.GetSubjectDetail(123)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "expand fail")
+		})
+
+	})
+
+	Describe("ListSubjectIDsByPKs", func() {
+		var patches *gomonkey.Patches
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("GetSubjectByPK fail", func() {
+			patches = gomonkey.ApplyFunc(impls.GetSubjectByPK, func(pk int64) (svctypes.Subject, error) {
+				return svctypes.Subject{}, errors.New("get subject fail")
+			})
+
+			_, err := pip.ListSubjectIDsByPKs([]int64{1})
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "get subject fail")
+		})
+
+		It("ok", func() {
+			patches = gomonkey.ApplyFunc(impls.GetSubjectByPK, func(pk int64) (svctypes.Subject, error) {
+				if pk == 1 {
+					return svctypes.Subject{Type: "group", ID: "10"}, nil
+				}
+				return svctypes.Subject{Type: "group", ID: "20"}, nil
+			})
+
+			ids, err := pip.ListSubjectIDsByPKs([]int64{1, 2})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []string{"10", "20"}, ids)
+		})
+	})
+
+	Describe("ListDepartmentEffectGroups", func() {
+		var patches *gomonkey.Patches
+		AfterEach(func() {
+			if patches != nil {
+				patches.Reset()
+			}
+		})
+
+		It("empty", func() {
+			groups, err := pip.ListDepartmentEffectGroups([]int64{})
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), groups)
+		})
+
+		It("ListSubjectEffectGroups fail", func() {
+			patches = gomonkey.ApplyFunc(impls.ListSubjectEffectGroups,
+				func(pks []int64) ([]svctypes.ThinSubjectGroup, error) {
+					return nil, errors.New("list fail")
+				})
+
+			_, err := pip.ListDepartmentEffectGroups([]int64{1})
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "list fail")
+		})
+
+		It("ok", func() {
+			patches = gomonkey.ApplyFunc(impls.ListSubjectEffectGroups,
+				func(pks []int64) ([]svctypes.ThinSubjectGroup, error) {
+					return []svctypes.ThinSubjectGroup{{PK: 1, PolicyExpiredAt: 123}}, nil
+				})
+			patches.ApplyFunc(impls.BatchListSubjectEffectGroups,
+				func(pks []int64) (map[int64][]svctypes.ThinSubjectGroup, error) {
+					return map[int64][]svctypes.ThinSubjectGroup{}, nil
+				})
+
+			groups, err := pip.ListDepartmentEffectGroups([]int64{1})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.SubjectGroup{{PK: 1, PolicyExpiredAt: 123}}, groups)
+		})
 	})
 
+	Describe("GetSubjectExtAttributes", func() {
+		var patches *gomonkey.Patches
+		AfterEach(func() {
+			if patches != nil {
+				patches.Reset()
+			}
+		})
+
+		It("ListSubjectAttributeProviders fail", func() {
+			patches = gomonkey.ApplyFunc(impls.ListSubjectAttributeProviders,
+				func() ([]svctypes.SubjectAttributeProvider, error) {
+					return nil, errors.New("list fail")
+				})
+
+			_, err := pip.GetSubjectExtAttributes("user", "admin")
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "list fail")
+		})
+
+		It("no providers", func() {
+			patches = gomonkey.ApplyFunc(impls.ListSubjectAttributeProviders,
+				func() ([]svctypes.SubjectAttributeProvider, error) {
+					return []svctypes.SubjectAttributeProvider{}, nil
+				})
+
+			attrs, err := pip.GetSubjectExtAttributes("user", "admin")
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), attrs)
+		})
+
+		It("ok, only the declared attributes", func() {
+			patches = gomonkey.ApplyFunc(impls.ListSubjectAttributeProviders,
+				func() ([]svctypes.SubjectAttributeProvider, error) {
+					return []svctypes.SubjectAttributeProvider{
+						{
+							ID:         "static",
+							Type:       svctypes.SubjectAttributeProviderTypeStatic,
+							Attributes: []string{"region"},
+							Config: svctypes.SubjectAttributeProviderConfig{
+								Mapping: map[string]map[string]interface{}{
+									"user:admin": {"region": "sz", "secret": "x"},
+								},
+							},
+						},
+						{
+							ID:         "hr",
+							Type:       svctypes.SubjectAttributeProviderTypeHTTP,
+							Attributes: []string{"job_level"},
+						},
+					}, nil
+				})
+			patches.ApplyFunc(impls.GetSubjectProvidedAttributes,
+				func(providerID, subjectType, subjectID string) (map[string]interface{}, error) {
+					return map[string]interface{}{"job_level": float64(3), "salary": float64(100)}, nil
+				})
+
+			attrs, err := pip.GetSubjectExtAttributes("user", "admin")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string]interface{}{"region": "sz", "job_level": float64(3)}, attrs)
+		})
+
+		It("http provider fail, return the others", func() {
+			patches = gomonkey.ApplyFunc(impls.ListSubjectAttributeProviders,
+				func() ([]svctypes.SubjectAttributeProvider, error) {
+					return []svctypes.SubjectAttributeProvider{
+						{
+							ID:         "hr",
+							Type:       svctypes.SubjectAttributeProviderTypeHTTP,
+							Attributes: []string{"job_level"},
+						},
+						{
+							ID:         "static",
+							Type:       svctypes.SubjectAttributeProviderTypeStatic,
+							Attributes: []string{"region"},
+							Config: svctypes.SubjectAttributeProviderConfig{
+								Mapping: map[string]map[string]interface{}{
+									"user:admin": {"region": "sz"},
+								},
+							},
+						},
+					}, nil
+				})
+			patches.ApplyFunc(impls.GetSubjectProvidedAttributes,
+				func(providerID, subjectType, subjectID string) (map[string]interface{}, error) {
+					return nil, errors.New("callback fail")
+				})
+
+			attrs, err := pip.GetSubjectExtAttributes("user", "admin")
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "callback fail")
+			assert.Equal(GinkgoT(), map[string]interface{}{"region": "sz"}, attrs)
+		})
+	})
 })
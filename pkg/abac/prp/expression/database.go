@@ -11,11 +11,21 @@
 package expression
 
 import (
+	"golang.org/x/sync/singleflight"
+
 	"iam/pkg/service"
 	"iam/pkg/service/types"
 	"iam/pkg/util"
 )
 
+// retrieveGroup 合并相同expressionPKs的并发回源, 避免热点表达式的缓存失效时大量相同的DB查询
+var retrieveGroup singleflight.Group
+
+type databaseRetrieveResult struct {
+	expressions []types.AuthExpression
+	missingPKs  []int64
+}
+
 type databaseRetriever struct {
 	policyService service.PolicyService
 }
@@ -27,13 +37,25 @@ func newDatabaseRetriever() *databaseRetriever {
 }
 
 func (r *databaseRetriever) retrieve(pks []int64) ([]types.AuthExpression, []int64, error) {
-	expressions, err := r.policyService.ListExpressionByPKs(pks)
+	value, err, _ := retrieveGroup.Do(util.Int64SliceToString(pks, ","), func() (interface{}, error) {
+		expressions, err := r.policyService.ListExpressionByPKs(pks)
+		if err != nil {
+			return nil, err
+		}
+
+		return databaseRetrieveResult{
+			expressions: expressions,
+			missingPKs:  r.getMissingPKs(pks, expressions),
+		}, nil
+	})
 	if err != nil {
 		return nil, nil, err
 	}
 
-	missingPKs := r.getMissingPKs(pks, expressions)
-
+	// NOTE: the result is shared by the concurrent callers, copy it, the upper layers will append to the slices
+	result := value.(databaseRetrieveResult)
+	expressions := append(make([]types.AuthExpression, 0, len(result.expressions)), result.expressions...)
+	missingPKs := append(make([]int64, 0, len(result.missingPKs)), result.missingPKs...)
 	return expressions, missingPKs, nil
 }
 
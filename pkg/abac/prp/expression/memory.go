@@ -31,7 +31,7 @@ const (
 
 	// fetch the top 1000 in change list, protect the auth/query api performance,
 	// if hit 1000, some local-cached(action -> expressionPK) updated event will not be notified,
-	// will be expired in policyLocalCacheTTL, accepted by now
+	// will be expired in expressionLocalCacheTTL, accepted by now
 	maxChangeListCount = 1000
 )
 
@@ -41,6 +41,11 @@ const (
 
 var changeList = common.NewChangeList(changeListTypeExpression, expressionLocalCacheTTL, maxChangeListCount)
 
+// LocalCacheExpiration 表达式本地缓存的有效期
+func LocalCacheExpiration() time.Duration {
+	return expressionLocalCacheTTL * time.Second
+}
+
 // TODO: 如何加入debug? 感知两层缓存+database的结果?
 
 type memoryRetriever struct {
@@ -94,9 +99,10 @@ func (r *memoryRetriever) retrieve(pks []int64) ([]types.AuthExpression, []int64
 				continue
 			}
 
-			// 如果 5min内有更新, 那么这个算missing
+			// 如果 1min内有更新, 那么这个算missing
+			// NOTE: 时间戳精度为秒, 同一秒内缓存的数据可能是变更前从db/redis读到的, 所以相等时也算missing
 			if changedTS, ok := changedTimestamps[key]; ok {
-				if cached.timestamp < changedTS {
+				if cached.timestamp <= changedTS {
 					// not the newest
 					// 1. append to missing
 					missExpressionPKs = append(missExpressionPKs, expressionPK)
@@ -137,10 +143,12 @@ func (r *memoryRetriever) setMissing(expressions []types.AuthExpression, missing
 	for _, expr := range expressions {
 		key := r.genKey(expr.PK)
 
+		// NOTE: the ttl should be the same as the change list, otherwise the expression changed before the
+		// window of change list will be kept in local cache
 		impls.LocalExpressionCache.Set(key, &cachedExpression{
 			timestamp:  nowTimestamp,
 			expression: expr,
-		}, 0)
+		}, expressionLocalCacheTTL*time.Second)
 	}
 	for _, pk := range missingPKs {
 		key := r.genKey(pk)
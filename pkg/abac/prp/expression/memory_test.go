@@ -195,6 +195,31 @@ var _ = Describe("Memory", func() {
 			assert.Len(GinkgoT(), expressions, 3)
 			assert.Empty(GinkgoT(), missingPKs)
 		})
+		It("all hit, changed in the same second", func() {
+			patches.ApplyMethod(reflect.TypeOf(impls.ChangeListCache), "ZRevRangeByScore",
+				func(c *redis.Cache, k string, min int64, max int64, offset int64, count int64) ([]rds.Z, error) {
+					return []rds.Z{
+						{
+							Score:  float64(now),
+							Member: "456",
+						},
+					}, nil
+				})
+
+			for key, cached := range hitExpressions {
+				impls.LocalExpressionCache.Set(key, cached, 0)
+			}
+
+			r.missingRetrieveFunc = func(pks []int64) (expressions []types.AuthExpression, missingPKs []int64, err error) {
+				assert.Equal(GinkgoT(), []int64{456}, pks)
+				return retrievedExpressions[1:2], nil, nil
+			}
+
+			expressions, missingPKs, err := r.retrieve([]int64{123, 456, 789})
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), expressions, 3)
+			assert.Empty(GinkgoT(), missingPKs)
+		})
 		It("one expression cast fail", func() {
 			// all hit
 			for key, cached := range hitExpressions {
@@ -268,6 +293,11 @@ var _ = Describe("Memory", func() {
 			assert.True(GinkgoT(), ok)
 			_, ok = impls.LocalExpressionCache.Get("111")
 			assert.False(GinkgoT(), ok)
+
+			// the ttl should be the same as the change list
+			_, expiration, ok := impls.LocalExpressionCache.GetWithExpiration("123")
+			assert.True(GinkgoT(), ok)
+			assert.True(GinkgoT(), expiration.Before(time.Now().Add((expressionLocalCacheTTL+1)*time.Second)))
 		})
 	})
 
@@ -128,8 +128,9 @@ func (r *redisRetriever) batchGet(expressionPKs []int64) (
 }
 
 func (r *redisRetriever) batchSet(authExpressions map[int64]types.AuthExpression) error {
-	// set into cache
+	// set into cache, 不存在的表达式(空值)单独设置较短的缓存时间
 	kvs := make([]redis.KV, 0, len(authExpressions))
+	emptyKVs := make([]redis.KV, 0, len(authExpressions))
 	for pk, expression := range authExpressions {
 		key := cache.NewInt64Key(pk)
 
@@ -138,20 +139,36 @@ func (r *redisRetriever) batchSet(authExpressions map[int64]types.AuthExpression
 			return err
 		}
 
-		kvs = append(kvs, redis.KV{
+		kv := redis.KV{
 			Key:   key.Key(),
 			Value: util.BytesToString(exprBytes),
-		})
+		}
+		if expression.IsEmpty() {
+			emptyKVs = append(emptyKVs, kv)
+		} else {
+			kvs = append(kvs, kv)
+		}
 	}
 
 	// keep cache for 7 days
-	err := impls.ExpressionCache.BatchSetWithTx(
-		kvs,
-		impls.PolicyCacheExpiration+time.Duration(rand.Intn(RandExpireSeconds))*time.Second,
-	)
-	if err != nil {
-		log.WithError(err).Errorf("[%s] impls.ExpressionCache.BatchSetWithTx fail kvs=`%+v`", RedisLayer, kvs)
-		return err
+	if len(kvs) > 0 {
+		err := impls.ExpressionCache.BatchSetWithTx(
+			kvs,
+			impls.PolicyCacheExpiration+time.Duration(rand.Intn(RandExpireSeconds))*time.Second,
+		)
+		if err != nil {
+			log.WithError(err).Errorf("[%s] impls.ExpressionCache.BatchSetWithTx fail kvs=`%+v`", RedisLayer, kvs)
+			return err
+		}
+	}
+
+	if len(emptyKVs) > 0 {
+		err := impls.ExpressionCache.BatchSetWithTx(emptyKVs, impls.PolicyCacheEmptyExpiration)
+		if err != nil {
+			log.WithError(err).Errorf("[%s] impls.ExpressionCache.BatchSetWithTx fail emptyKVs=`%+v`",
+				RedisLayer, emptyKVs)
+			return err
+		}
 	}
 
 	return nil
@@ -381,6 +381,22 @@ var _ = Describe("Redis", func() {
 			assert.True(GinkgoT(), impls.ExpressionCache.Exists(cache.NewInt64Key(456)))
 		})
 
+		It("ok, empty expression with short expiration", func() {
+			var expirations []time.Duration
+			patches.ApplyMethod(reflect.TypeOf(impls.ExpressionCache), "BatchSetWithTx",
+				func(c *redis.Cache, kvs []redis.KV, expiration time.Duration) error {
+					expirations = append(expirations, expiration)
+					return nil
+				})
+
+			noCacheExpressions[789] = types.AuthExpression{}
+			err := r.batchSet(noCacheExpressions)
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), expirations, 2)
+			assert.True(GinkgoT(), expirations[0] >= impls.PolicyCacheExpiration)
+			assert.Equal(GinkgoT(), impls.PolicyCacheEmptyExpiration, expirations[1])
+		})
+
 	})
 
 	Describe("batchDelete", func() {
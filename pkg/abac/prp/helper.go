@@ -11,24 +11,29 @@
 package prp
 
 import (
+	"database/sql"
+	"errors"
 	"time"
 
 	"iam/pkg/abac/types"
 	"iam/pkg/cache/impls"
 	"iam/pkg/errorx"
+	svctypes "iam/pkg/service/types"
 	"iam/pkg/util"
 )
 
 /*
 NOTE:
  - 当前部门不会直接配置权限, 只能通过加入用户组的方式配置; 所以 dept PKs 不加入最终生效的pks
-
-TODO:
- - 当前  impls.ListSubjectEffectGroups pipeline获取的性能有问题, 需要考虑走cache?
+ - 内置的所有用户subject的权限对每个用户生效, 其pk加入用户最终生效的pks, 策略按其pk独立缓存
+ - 服务账号(service_account)不是自然人, 不继承部门加入的用户组, 也不享有所有用户subject的权限
+ - 用户/部门的用户组通过 impls.ListSubjectSystemGroups 获取: 只包含在该系统下有策略的用户组(已展开嵌套加入的用户组),
+   由 subject_system_group 表在成员/策略变更时维护, 避免查询大量在该系统下没有策略的用户组
+ - subject属性中的用户组(直接加入的, 或虚拟用户内联定义的)同样生效, 不依赖 subject_system_group 的记录
 
 */
 
-func getEffectSubjectPKs(subject types.Subject) ([]int64, error) {
+func getEffectSubjectPKs(system string, subject types.Subject) ([]int64, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PRP, "getEffectSubjectPKs")
 
 	subjectPK, err := subject.Attribute.GetPK()
@@ -50,38 +55,56 @@ func getEffectSubjectPKs(subject types.Subject) ([]int64, error) {
 		return nil, err
 	}
 
-	// 用户继承组织加入的用户组 => 多个部门属于同一个组, 所以需要去重
+	// 用户加入的用户组 + 用户继承组织加入的用户组
+	pks := make([]int64, 0, 1+len(deptPKs))
+	pks = append(pks, subjectPK)
+	if subject.Type != svctypes.ServiceAccountType {
+		pks = append(pks, deptPKs...)
+	}
+
+	pkSubjectGroups, err := impls.ListSubjectSystemGroups(system, pks)
+	if err != nil {
+		err = errorWrapf(err, "ListSubjectSystemGroups system=`%s`, pks=`%+v` fail", system, pks)
+		return nil, err
+	}
+
+	// 多个部门属于同一个组, 所以需要去重, 只获取有效的
 	now := time.Now().Unix()
-	inheritGroupPKSet := util.NewInt64Set()
-	if len(deptPKs) > 0 {
-		subjectGroups, newErr := impls.ListSubjectEffectGroups(deptPKs)
-		if newErr != nil {
-			newErr = errorWrapf(newErr, "ListSubjectEffectGroups deptPKs=`%+v` fail", deptPKs)
-			return nil, newErr
-		}
-		for _, sg := range subjectGroups {
+	groupPKSet := util.NewInt64SetWithValues(groupPKs)
+	for _, pk := range pks {
+		for _, sg := range pkSubjectGroups[pk] {
 			if sg.PolicyExpiredAt > now {
-				inheritGroupPKSet.Add(sg.PK)
+				groupPKSet.Add(sg.PK)
 			}
 		}
 	}
 
-	inheritGroupPKs := inheritGroupPKSet.ToSlice()
-
-	// 1. merge `user-groupPKs` and `user-dept-groupPKs`
-	groupPKMaxLen := len(groupPKs) + len(inheritGroupPKs)
-	groupPKSet := util.NewFixedLengthInt64Set(groupPKMaxLen)
-	// 用户加入的用户组
-	groupPKSet.Append(groupPKs...)
-	// 用户继承组织加入的用户组
-	groupPKSet.Append(inheritGroupPKs...)
-
-	// 2. collect all pks
-	effectSubjectPKs := make([]int64, 0, 1+groupPKSet.Size())
+	// 1. collect all pks
+	effectSubjectPKs := make([]int64, 0, 2+groupPKSet.Size())
 	// 将用户自身添加进去
 	effectSubjectPKs = append(effectSubjectPKs, subjectPK)
-	// 用户加入的用户组 + 用户继承组织加入的用户组
 	effectSubjectPKs = append(effectSubjectPKs, groupPKSet.ToSlice()...)
 
+	// 2. 所有用户
+	if subject.Type == svctypes.UserType {
+		allUsersPK, err := getAllUsersSubjectPK()
+		if err != nil {
+			err = errorWrapf(err, "getAllUsersSubjectPK fail")
+			return nil, err
+		}
+		if allUsersPK != 0 {
+			effectSubjectPKs = append(effectSubjectPKs, allUsersPK)
+		}
+	}
+
 	return effectSubjectPKs, nil
 }
+
+// getAllUsersSubjectPK 获取内置的所有用户subject的pk, 不存在时返回0
+func getAllUsersSubjectPK() (int64, error) {
+	pk, err := impls.GetLocalSubjectPK(svctypes.AllUsersSubjectType, svctypes.AllUsersSubjectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return pk, err
+}
@@ -11,6 +11,7 @@
 package prp
 
 import (
+	"database/sql"
 	"errors"
 	"time"
 
@@ -44,88 +45,151 @@ var _ = Describe("Helper", func() {
 		})
 
 		It("subject GetPK fail", func() {
-			_, err := getEffectSubjectPKs(s)
+			_, err := getEffectSubjectPKs("test", s)
 			assert.Error(GinkgoT(), err)
 			assert.Contains(GinkgoT(), err.Error(), "subject.Attribute.GetPK")
 		})
 
-		It("subject GetEffectGroupPKs fail", func() {
-			s.FillAttributes(123, []types.SubjectGroup{}, []int64{1, 2, 3})
-			s.Attribute.Delete(types.GroupAttrName)
-
-			_, err := getEffectSubjectPKs(s)
-			assert.Error(GinkgoT(), err)
-			assert.Contains(GinkgoT(), err.Error(), "subject.GetEffectGroupPKs")
-		})
-
 		It("subject GetDepartmentPKs fail", func() {
 			s.FillAttributes(123, []types.SubjectGroup{}, []int64{1, 2, 3})
 			s.Attribute.Delete(types.DeptAttrName)
 
-			_, err := getEffectSubjectPKs(s)
+			_, err := getEffectSubjectPKs("test", s)
 			assert.Error(GinkgoT(), err)
 			assert.Contains(GinkgoT(), err.Error(), "subject.GetDepartmentPKs")
 		})
 
-		It("impls.ListSubjectEffectGroups fail", func() {
-			patches = gomonkey.ApplyFunc(impls.ListSubjectEffectGroups,
-				func(pks []int64) ([]svctypes.ThinSubjectGroup, error) {
-					return nil, errors.New("list subject_group fail")
+		It("impls.ListSubjectSystemGroups fail", func() {
+			patches = gomonkey.ApplyFunc(impls.ListSubjectSystemGroups,
+				func(system string, pks []int64) (map[int64][]svctypes.ThinSubjectGroup, error) {
+					return nil, errors.New("list subject_system_group fail")
 				})
 			s.FillAttributes(123, []types.SubjectGroup{}, []int64{1, 2, 3})
-			_, err := getEffectSubjectPKs(s)
+			_, err := getEffectSubjectPKs("test", s)
 			assert.Error(GinkgoT(), err)
-			assert.Contains(GinkgoT(), err.Error(), "ListSubjectEffectGroups")
+			assert.Contains(GinkgoT(), err.Error(), "ListSubjectSystemGroups")
 		})
 
 		It("ok", func() {
-			patches = gomonkey.ApplyFunc(impls.ListSubjectEffectGroups,
-				func(pks []int64) ([]svctypes.ThinSubjectGroup, error) {
-					return []svctypes.ThinSubjectGroup{
-						{
-							PK:              4,
-							PolicyExpiredAt: 0,
+			expiredAt := time.Now().Add(1 * time.Minute).Unix()
+			patches = gomonkey.ApplyFunc(impls.ListSubjectSystemGroups,
+				func(system string, pks []int64) (map[int64][]svctypes.ThinSubjectGroup, error) {
+					assert.Equal(GinkgoT(), "test", system)
+					assert.Equal(GinkgoT(), []int64{123, 1, 2, 3}, pks)
+					return map[int64][]svctypes.ThinSubjectGroup{
+						123: {
+							{PK: 7, PolicyExpiredAt: expiredAt},
+							{PK: 8, PolicyExpiredAt: expiredAt},
+							{PK: 9, PolicyExpiredAt: 0},
 						},
-						{
-							PK:              5,
-							PolicyExpiredAt: time.Now().Add(1 * time.Minute).Unix(),
+						1: {
+							{PK: 4, PolicyExpiredAt: 0},
+							{PK: 5, PolicyExpiredAt: expiredAt},
+							{PK: 6, PolicyExpiredAt: expiredAt},
 						},
-						{
-							PK:              6,
-							PolicyExpiredAt: time.Now().Add(1 * time.Minute).Unix(),
-						},
-						{
-							PK:              6,
-							PolicyExpiredAt: time.Now().Add(1 * time.Minute).Unix(),
-						},
-						{
-							PK:              7,
-							PolicyExpiredAt: time.Now().Add(1 * time.Minute).Unix(),
+						2: {
+							{PK: 6, PolicyExpiredAt: expiredAt},
+							{PK: 7, PolicyExpiredAt: expiredAt},
 						},
 					}, nil
 				})
 
-			userGroups := []types.SubjectGroup{
-				{
-					PK:              7,
-					PolicyExpiredAt: time.Now().Add(1 * time.Minute).Unix(),
-				},
-				{
-					PK:              8,
-					PolicyExpiredAt: time.Now().Add(1 * time.Minute).Unix(),
-				},
-				{
-					PK:              9,
-					PolicyExpiredAt: 0,
-				},
-			}
-			s.FillAttributes(123, userGroups, []int64{1, 2, 3})
-			pks, err := getEffectSubjectPKs(s)
+			s.FillAttributes(123, []types.SubjectGroup{}, []int64{1, 2, 3})
+			pks, err := getEffectSubjectPKs("test", s)
 			assert.NoError(GinkgoT(), err)
 
 			// all = user(123) +  groups(5,6,7,8)
 			assert.ElementsMatch(GinkgoT(), []int64{123, 5, 6, 7, 8}, pks)
 		})
+
+		It("ok, with the groups of subject attribute", func() {
+			expiredAt := time.Now().Add(1 * time.Minute).Unix()
+			patches = gomonkey.ApplyFunc(impls.ListSubjectSystemGroups,
+				func(system string, pks []int64) (map[int64][]svctypes.ThinSubjectGroup, error) {
+					return map[int64][]svctypes.ThinSubjectGroup{
+						1: {{PK: 5, PolicyExpiredAt: expiredAt}},
+					}, nil
+				})
+
+			// the inline groups of hypothetical subject, or the rows of subject_system_group missing
+			s.FillAttributes(123, []types.SubjectGroup{
+				{PK: 11, PolicyExpiredAt: expiredAt},
+				{PK: 12, PolicyExpiredAt: 0},
+				{PK: 5, PolicyExpiredAt: expiredAt},
+			}, []int64{1})
+			pks, err := getEffectSubjectPKs("test", s)
+			assert.NoError(GinkgoT(), err)
+			assert.ElementsMatch(GinkgoT(), []int64{123, 5, 11}, pks)
+		})
+
+		It("user with all users subject", func() {
+			patches = gomonkey.ApplyFunc(impls.GetLocalSubjectPK, func(_type, id string) (int64, error) {
+				assert.Equal(GinkgoT(), svctypes.AllUsersSubjectType, _type)
+				assert.Equal(GinkgoT(), svctypes.AllUsersSubjectID, id)
+				return 100, nil
+			})
+			patches.ApplyFunc(impls.ListSubjectSystemGroups,
+				func(system string, pks []int64) (map[int64][]svctypes.ThinSubjectGroup, error) {
+					return map[int64][]svctypes.ThinSubjectGroup{}, nil
+				})
+
+			s.Type = svctypes.UserType
+			s.FillAttributes(123, []types.SubjectGroup{}, []int64{})
+			pks, err := getEffectSubjectPKs("test", s)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []int64{123, 100}, pks)
+		})
+
+		It("user without all users subject", func() {
+			patches = gomonkey.ApplyFunc(impls.GetLocalSubjectPK, func(_type, id string) (int64, error) {
+				return 0, sql.ErrNoRows
+			})
+			patches.ApplyFunc(impls.ListSubjectSystemGroups,
+				func(system string, pks []int64) (map[int64][]svctypes.ThinSubjectGroup, error) {
+					return map[int64][]svctypes.ThinSubjectGroup{}, nil
+				})
+
+			s.Type = svctypes.UserType
+			s.FillAttributes(123, []types.SubjectGroup{}, []int64{})
+			pks, err := getEffectSubjectPKs("test", s)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []int64{123}, pks)
+		})
+
+		It("user get all users subject pk fail", func() {
+			patches = gomonkey.ApplyFunc(impls.GetLocalSubjectPK, func(_type, id string) (int64, error) {
+				return 0, errors.New("get pk fail")
+			})
+			patches.ApplyFunc(impls.ListSubjectSystemGroups,
+				func(system string, pks []int64) (map[int64][]svctypes.ThinSubjectGroup, error) {
+					return map[int64][]svctypes.ThinSubjectGroup{}, nil
+				})
+
+			s.Type = svctypes.UserType
+			s.FillAttributes(123, []types.SubjectGroup{}, []int64{})
+			_, err := getEffectSubjectPKs("test", s)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "getAllUsersSubjectPK")
+		})
+
+		It("service account without department inheritance", func() {
+			patches = gomonkey.ApplyFunc(impls.ListSubjectSystemGroups,
+				func(system string, pks []int64) (map[int64][]svctypes.ThinSubjectGroup, error) {
+					assert.Equal(GinkgoT(), []int64{123}, pks)
+					return map[int64][]svctypes.ThinSubjectGroup{
+						123: {{PK: 7, PolicyExpiredAt: time.Now().Add(1 * time.Minute).Unix()}},
+					}, nil
+				})
+			patches.ApplyFunc(impls.GetLocalSubjectPK, func(_type, id string) (int64, error) {
+				return 100, nil
+			})
+
+			s.Type = svctypes.ServiceAccountType
+			s.FillAttributes(123, []types.SubjectGroup{}, []int64{1})
+			pks, err := getEffectSubjectPKs("test", s)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []int64{123, 7}, pks)
+		})
 	})
 
 })
@@ -6,9 +6,10 @@ package mock
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	prp "iam/pkg/abac/prp"
 	types "iam/pkg/abac/types"
 	debug "iam/pkg/logging/debug"
-	types0 "iam/pkg/service/types"
+	svctypes "iam/pkg/service/types"
 	reflect "reflect"
 )
 
@@ -65,6 +66,36 @@ func (mr *MockPolicyManagerMockRecorder) ListBySubjectAction(system, subject, ac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBySubjectAction", reflect.TypeOf((*MockPolicyManager)(nil).ListBySubjectAction), system, subject, action, withoutCache, entry)
 }
 
+// ListBySubjectActionWithOverlay mocks base method
+func (m *MockPolicyManager) ListBySubjectActionWithOverlay(system string, subject types.Subject, action types.Action, overlay *prp.PolicyOverlay, entry *debug.Entry) ([]types.AuthPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBySubjectActionWithOverlay", system, subject, action, overlay, entry)
+	ret0, _ := ret[0].([]types.AuthPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBySubjectActionWithOverlay indicates an expected call of ListBySubjectActionWithOverlay
+func (mr *MockPolicyManagerMockRecorder) ListBySubjectActionWithOverlay(system, subject, action, overlay, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBySubjectActionWithOverlay", reflect.TypeOf((*MockPolicyManager)(nil).ListBySubjectActionWithOverlay), system, subject, action, overlay, entry)
+}
+
+// ListBySubjectsAction mocks base method
+func (m *MockPolicyManager) ListBySubjectsAction(system string, subjectPKs []int64, action types.Action, withoutCache bool) (map[int64][]types.AuthPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBySubjectsAction", system, subjectPKs, action, withoutCache)
+	ret0, _ := ret[0].(map[int64][]types.AuthPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBySubjectsAction indicates an expected call of ListBySubjectsAction
+func (mr *MockPolicyManagerMockRecorder) ListBySubjectsAction(system, subjectPKs, action, withoutCache interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBySubjectsAction", reflect.TypeOf((*MockPolicyManager)(nil).ListBySubjectsAction), system, subjectPKs, action, withoutCache)
+}
+
 // ListSaaSBySubjectSystemTemplate mocks base method
 func (m *MockPolicyManager) ListSaaSBySubjectSystemTemplate(system, subjectType, subjectID string, templateID int64) ([]types.SaaSPolicy, error) {
 	m.ctrl.T.Helper()
@@ -138,10 +169,10 @@ func (mr *MockPolicyManagerMockRecorder) DeleteByIDs(system, subjectType, subjec
 }
 
 // GetExpressionsFromCache mocks base method
-func (m *MockPolicyManager) GetExpressionsFromCache(actionPK int64, expressionPKs []int64) ([]types0.AuthExpression, error) {
+func (m *MockPolicyManager) GetExpressionsFromCache(actionPK int64, expressionPKs []int64) ([]svctypes.AuthExpression, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetExpressionsFromCache", actionPK, expressionPKs)
-	ret0, _ := ret[0].([]types0.AuthExpression)
+	ret0, _ := ret[0].([]svctypes.AuthExpression)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -166,6 +197,21 @@ func (mr *MockPolicyManagerMockRecorder) DeleteByActionID(systemID, actionID int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByActionID", reflect.TypeOf((*MockPolicyManager)(nil).DeleteByActionID), systemID, actionID)
 }
 
+// NewPolicyOverlay mocks base method
+func (m *MockPolicyManager) NewPolicyOverlay(systemID, subjectType, subjectID string, createPolicies []types.Policy, deletePolicyIDs []int64) (*prp.PolicyOverlay, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewPolicyOverlay", systemID, subjectType, subjectID, createPolicies, deletePolicyIDs)
+	ret0, _ := ret[0].(*prp.PolicyOverlay)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewPolicyOverlay indicates an expected call of NewPolicyOverlay
+func (mr *MockPolicyManagerMockRecorder) NewPolicyOverlay(systemID, subjectType, subjectID, createPolicies, deletePolicyIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewPolicyOverlay", reflect.TypeOf((*MockPolicyManager)(nil).NewPolicyOverlay), systemID, subjectType, subjectID, createPolicies, deletePolicyIDs)
+}
+
 // CreateAndDeleteTemplatePolicies mocks base method
 func (m *MockPolicyManager) CreateAndDeleteTemplatePolicies(systemID, subjectType, subjectID string, templateID int64, createPolicies []types.Policy, deletePolicyIDs []int64) error {
 	m.ctrl.T.Helper()
@@ -207,3 +253,33 @@ func (mr *MockPolicyManagerMockRecorder) DeleteTemplatePolicies(systemID, subjec
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTemplatePolicies", reflect.TypeOf((*MockPolicyManager)(nil).DeleteTemplatePolicies), systemID, subjectType, subjectID, templateID)
 }
+
+// UpdateTemplatePoliciesExpiredAt mocks base method
+func (m *MockPolicyManager) UpdateTemplatePoliciesExpiredAt(systemID, subjectType, subjectID string, templateID, expiredAt int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplatePoliciesExpiredAt", systemID, subjectType, subjectID, templateID, expiredAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTemplatePoliciesExpiredAt indicates an expected call of UpdateTemplatePoliciesExpiredAt
+func (mr *MockPolicyManagerMockRecorder) UpdateTemplatePoliciesExpiredAt(systemID, subjectType, subjectID, templateID, expiredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplatePoliciesExpiredAt", reflect.TypeOf((*MockPolicyManager)(nil).UpdateTemplatePoliciesExpiredAt), systemID, subjectType, subjectID, templateID, expiredAt)
+}
+
+// AsyncDeleteTemplatePolicies mocks base method
+func (m *MockPolicyManager) AsyncDeleteTemplatePolicies(systemID, subjectType, subjectID string, templateID int64) (types.TemplateUnbindTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AsyncDeleteTemplatePolicies", systemID, subjectType, subjectID, templateID)
+	ret0, _ := ret[0].(types.TemplateUnbindTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AsyncDeleteTemplatePolicies indicates an expected call of AsyncDeleteTemplatePolicies
+func (mr *MockPolicyManagerMockRecorder) AsyncDeleteTemplatePolicies(systemID, subjectType, subjectID, templateID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AsyncDeleteTemplatePolicies", reflect.TypeOf((*MockPolicyManager)(nil).AsyncDeleteTemplatePolicies), systemID, subjectType, subjectID, templateID)
+}
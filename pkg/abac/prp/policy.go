@@ -30,6 +30,13 @@ type PolicyManager interface {
 		system, subjectType, subjectID, actionID string, templateID int64) (policy types.AuthPolicy, err error)
 	ListBySubjectAction(system string, subject types.Subject, action types.Action,
 		withoutCache bool, entry *debug.Entry) ([]types.AuthPolicy, error) // 需要对service查询来的policy去重
+	ListBySubjectActionWithOverlay(system string, subject types.Subject, action types.Action,
+		overlay *PolicyOverlay, entry *debug.Entry) ([]types.AuthPolicy, error)
+
+	// in policy_list_subjects.go
+
+	ListBySubjectsAction(system string, subjectPKs []int64, action types.Action,
+		withoutCache bool) (map[int64][]types.AuthPolicy, error)
 
 	ListSaaSBySubjectSystemTemplate(system, subjectType, subjectID string, templateID int64) ([]types.SaaSPolicy,
 		error)
@@ -48,25 +55,39 @@ type PolicyManager interface {
 	GetExpressionsFromCache(actionPK int64, expressionPKs []int64) ([]svctypes.AuthExpression, error)
 	DeleteByActionID(systemID, actionID string) error
 
+	// in policy_overlay.go
+
+	NewPolicyOverlay(systemID, subjectType, subjectID string,
+		createPolicies []types.Policy, deletePolicyIDs []int64) (*PolicyOverlay, error)
+
 	// template
 
 	CreateAndDeleteTemplatePolicies(systemID, subjectType, subjectID string, templateID int64,
 		createPolicies []types.Policy, deletePolicyIDs []int64) error
 	UpdateTemplatePolicies(systemID, subjectType, subjectID string, policies []types.Policy) error
 	DeleteTemplatePolicies(systemID, subjectType, subjectID string, templateID int64) error
+	UpdateTemplatePoliciesExpiredAt(systemID, subjectType, subjectID string, templateID, expiredAt int64) (int64, error)
+
+	// in template_unbind.go
+
+	AsyncDeleteTemplatePolicies(systemID, subjectType, subjectID string, templateID int64) (types.TemplateUnbindTask, error)
 }
 
 type policyManager struct {
-	subjectService service.SubjectService
+	subjectService service.SubjectBaseReadService
 	actionService  service.ActionService
 	policyService  service.PolicyService
+
+	subjectSystemGroupService service.SubjectSystemGroupService
 }
 
 // NewPolicyManager ...
 func NewPolicyManager() PolicyManager {
 	return &policyManager{
-		subjectService: service.NewSubjectService(),
+		subjectService: service.NewSubjectBaseReadService(),
 		actionService:  service.NewActionService(),
 		policyService:  service.NewPolicyService(),
+
+		subjectSystemGroupService: service.NewSubjectSystemGroupService(),
 	}
 }
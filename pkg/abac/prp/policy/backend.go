@@ -0,0 +1,78 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package policy
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/cache/impls"
+	"iam/pkg/config"
+)
+
+/*
+策略缓存的存储方式, 每个系统可以单独设置
+
+1. both:   (默认) memory -> redis -> database, 查询结果逐层回写
+2. memory: memory -> database, 高QPS的系统, 减少redis的访问
+3. redis:  redis -> database, 低QPS的系统, 节省本地内存
+
+优先级: 接入系统通过 system config `policy_cache_backend` 的设置 > 配置文件中系统的设置 > 配置文件的默认值
+
+NOTE: 删除缓存时不区分存储方式, memory和redis都会删除, 保证切换存储方式后不会读到旧数据
+*/
+
+// 策略缓存的存储方式
+const (
+	CacheBackendBoth   = "both"
+	CacheBackendMemory = "memory"
+	CacheBackendRedis  = "redis"
+)
+
+// NOTE: 初始化后只读, 不需要加锁
+var (
+	defaultCacheBackend = CacheBackendBoth
+	systemCacheBackends = map[string]string{}
+)
+
+// InitCacheBackends 初始化每个系统的策略缓存存储方式, 未配置或配置非法的系统使用默认值
+func InitCacheBackends(cfg config.PolicyCache) {
+	defaultCacheBackend = CacheBackendBoth
+	if IsValidCacheBackend(cfg.Backend) {
+		defaultCacheBackend = cfg.Backend
+	}
+
+	systemCacheBackends = make(map[string]string, len(cfg.Systems))
+	for _, s := range cfg.Systems {
+		if IsValidCacheBackend(s.Backend) {
+			systemCacheBackends[s.ID] = s.Backend
+		}
+	}
+}
+
+// IsValidCacheBackend ...
+func IsValidCacheBackend(backend string) bool {
+	return backend == CacheBackendBoth || backend == CacheBackendMemory || backend == CacheBackendRedis
+}
+
+// GetCacheBackend 获取系统的策略缓存存储方式
+func GetCacheBackend(system string) string {
+	backend, err := impls.GetSystemPolicyCacheBackend(system)
+	if err != nil {
+		// 查询系统的设置失败不影响鉴权, 使用配置文件中的设置
+		log.WithError(err).Errorf("get the policy cache backend of system `%s` fail, will use the config file", system)
+	} else if IsValidCacheBackend(backend) {
+		return backend
+	}
+
+	if backend, ok := systemCacheBackends[system]; ok {
+		return backend
+	}
+	return defaultCacheBackend
+}
@@ -0,0 +1,73 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package policy
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache"
+	"iam/pkg/cache/impls"
+	"iam/pkg/cache/memory"
+	"iam/pkg/config"
+)
+
+var _ = Describe("Backend", func() {
+	var systemBackend string
+	var systemBackendErr error
+	BeforeEach(func() {
+		systemBackend = ""
+		systemBackendErr = nil
+		impls.LocalSystemPolicyCacheBackendCache = memory.NewCache(
+			"mockCache", true, func(key cache.Key) (interface{}, error) {
+				return systemBackend, systemBackendErr
+			}, 1*time.Minute)
+
+		InitCacheBackends(config.PolicyCache{
+			Backend: CacheBackendRedis,
+			Systems: []config.SystemPolicyCacheBackend{
+				{ID: "bk_cmdb", Backend: CacheBackendMemory},
+				{ID: "bk_job", Backend: "invalid"},
+			},
+		})
+	})
+	AfterEach(func() {
+		InitCacheBackends(config.PolicyCache{})
+	})
+
+	It("default", func() {
+		InitCacheBackends(config.PolicyCache{Backend: "invalid"})
+		assert.Equal(GinkgoT(), CacheBackendBoth, GetCacheBackend("bk_cmdb"))
+	})
+
+	It("config file", func() {
+		assert.Equal(GinkgoT(), CacheBackendMemory, GetCacheBackend("bk_cmdb"))
+		assert.Equal(GinkgoT(), CacheBackendRedis, GetCacheBackend("bk_job"))
+		assert.Equal(GinkgoT(), CacheBackendRedis, GetCacheBackend("bk_sops"))
+	})
+
+	It("system config", func() {
+		systemBackend = CacheBackendBoth
+		assert.Equal(GinkgoT(), CacheBackendBoth, GetCacheBackend("bk_cmdb"))
+	})
+
+	It("system config invalid", func() {
+		systemBackend = "invalid"
+		assert.Equal(GinkgoT(), CacheBackendMemory, GetCacheBackend("bk_cmdb"))
+	})
+
+	It("get system config fail", func() {
+		systemBackendErr = errors.New("get fail")
+		assert.Equal(GinkgoT(), CacheBackendMemory, GetCacheBackend("bk_cmdb"))
+	})
+})
@@ -11,11 +11,23 @@
 package policy
 
 import (
+	"strconv"
+
+	"golang.org/x/sync/singleflight"
+
 	"iam/pkg/service"
 	"iam/pkg/service/types"
 	"iam/pkg/util"
 )
 
+// retrieveGroup 合并相同action+subjectPKs的并发回源, 避免热点subject的缓存失效时大量相同的DB查询
+var retrieveGroup singleflight.Group
+
+type databaseRetrieveResult struct {
+	policies          []types.AuthPolicy
+	missingSubjectPKs []int64
+}
+
 type databaseRetriever struct {
 	policyService service.PolicyService
 	actionPK      int64
@@ -29,12 +41,26 @@ func newDatabaseRetriever(actionPK int64) *databaseRetriever {
 }
 
 func (r *databaseRetriever) retrieve(subjectPKs []int64) ([]types.AuthPolicy, []int64, error) {
-	policies, err := r.policyService.ListAuthBySubjectAction(subjectPKs, r.actionPK)
+	key := strconv.FormatInt(r.actionPK, 10) + ":" + util.Int64SliceToString(subjectPKs, ",")
+	value, err, _ := retrieveGroup.Do(key, func() (interface{}, error) {
+		policies, err := r.policyService.ListAuthBySubjectAction(subjectPKs, r.actionPK)
+		if err != nil {
+			return nil, err
+		}
+
+		return databaseRetrieveResult{
+			policies:          policies,
+			missingSubjectPKs: r.getMissingPKs(subjectPKs, policies),
+		}, nil
+	})
 	if err != nil {
 		return nil, nil, err
 	}
 
-	missingSubjectPKs := r.getMissingPKs(subjectPKs, policies)
+	// NOTE: the result is shared by the concurrent callers, copy it, the upper layers will append to the slices
+	result := value.(databaseRetrieveResult)
+	policies := append(make([]types.AuthPolicy, 0, len(result.policies)), result.policies...)
+	missingSubjectPKs := append(make([]int64, 0, len(result.missingSubjectPKs)), result.missingSubjectPKs...)
 	return policies, missingSubjectPKs, nil
 }
 
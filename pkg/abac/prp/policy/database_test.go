@@ -12,6 +12,8 @@ package policy
 
 import (
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/agiledragon/gomonkey"
 	"github.com/golang/mock/gomock"
@@ -105,6 +107,36 @@ var _ = Describe("Database", func() {
 			assert.Len(GinkgoT(), missingSubjectPKs, 1)
 			assert.Contains(GinkgoT(), missingSubjectPKs, int64(456))
 		})
+
+		It("concurrent retrieve of the same subjects merged", func() {
+			release := make(chan struct{})
+			mockPolicyService.EXPECT().ListAuthBySubjectAction([]int64{123, 456}, int64(1)).DoAndReturn(
+				func(subjectPKs []int64, actionPK int64) ([]types.AuthPolicy, error) {
+					<-release
+					return []types.AuthPolicy{{PK: 1, SubjectPK: 123}}, nil
+				},
+			).Times(1)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+
+					r := newDatabaseRetriever(1)
+					policies, missingSubjectPKs, err := r.retrieve([]int64{123, 456})
+					assert.NoError(GinkgoT(), err)
+					assert.Len(GinkgoT(), policies, 1)
+					assert.Equal(GinkgoT(), []int64{456}, missingSubjectPKs)
+				}()
+			}
+
+			// wait for all the callers blocked in the retrieve
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+		})
 	})
 
 	Describe("getMissingPKs", func() {
@@ -17,14 +17,24 @@ import (
 )
 
 // GetPoliciesFromCache will retrieve policies from cache, the order is memory->redis->database
+// the memory or redis layer will be skipped according to the cache backend of the system
 func GetPoliciesFromCache(system string, actionPK int64, subjectPKs []int64) ([]types.AuthPolicy, error) {
 	l3 := newDatabaseRetriever(actionPK)
 
-	l2 := newRedisRetriever(system, actionPK, l3.retrieve)
-
-	l1 := newMemoryRetriever(system, actionPK, l2.retrieve)
-
-	policies, _, err := l1.retrieve(subjectPKs)
+	var policies []types.AuthPolicy
+	var err error
+	switch GetCacheBackend(system) {
+	case CacheBackendMemory:
+		l1 := newMemoryRetriever(system, actionPK, l3.retrieve)
+		policies, _, err = l1.retrieve(subjectPKs)
+	case CacheBackendRedis:
+		l1 := newRedisRetriever(system, actionPK, l3.retrieve)
+		policies, _, err = l1.retrieve(subjectPKs)
+	default:
+		l2 := newRedisRetriever(system, actionPK, l3.retrieve)
+		l1 := newMemoryRetriever(system, actionPK, l2.retrieve)
+		policies, _, err = l1.retrieve(subjectPKs)
+	}
 	return policies, err
 }
 
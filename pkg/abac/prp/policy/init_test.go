@@ -20,8 +20,11 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"iam/pkg/abac/prp/policy"
+	"iam/pkg/cache"
 	"iam/pkg/cache/impls"
+	"iam/pkg/cache/memory"
 	"iam/pkg/cache/redis"
+	"iam/pkg/config"
 	"iam/pkg/service"
 	"iam/pkg/service/mock"
 	"iam/pkg/service/types"
@@ -35,6 +38,10 @@ var _ = Describe("Init", func() {
 		impls.LocalPolicyCache = gocache.New(1*time.Minute, 1*time.Minute)
 		impls.ChangeListCache = redis.NewMockCache("changelist", 1*time.Minute)
 		impls.PolicyCache = redis.NewMockCache("policy", 1*time.Minute)
+		impls.LocalSystemPolicyCacheBackendCache = memory.NewCache(
+			"mockCache", false, func(key cache.Key) (interface{}, error) {
+				return "", nil
+			}, 1*time.Minute)
 
 		ctl = gomock.NewController(GinkgoT())
 		patches = gomonkey.NewPatches()
@@ -73,6 +80,54 @@ var _ = Describe("Init", func() {
 
 	})
 
+	Describe("GetPoliciesFromCache with backend", func() {
+		BeforeEach(func() {
+			mockPolicyService := mock.NewMockPolicyService(ctl)
+			patches.ApplyFunc(service.NewPolicyService, func() service.PolicyService {
+				return mockPolicyService
+			})
+			mockPolicyService.EXPECT().ListAuthBySubjectAction([]int64{123}, int64(1)).Return(
+				[]types.AuthPolicy{{PK: 1, SubjectPK: 123}}, nil,
+			).AnyTimes()
+		})
+		AfterEach(func() {
+			policy.InitCacheBackends(config.PolicyCache{})
+		})
+
+		It("memory", func() {
+			policy.InitCacheBackends(config.PolicyCache{
+				Systems: []config.SystemPolicyCacheBackend{{ID: "test", Backend: policy.CacheBackendMemory}},
+			})
+
+			policies, err := policy.GetPoliciesFromCache("test", 1, []int64{123})
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), policies, 1)
+
+			assert.Equal(GinkgoT(), 1, impls.LocalPolicyCache.ItemCount())
+			assert.False(GinkgoT(), impls.PolicyCache.Exists(cache.NewStringKey("test:123")))
+		})
+
+		It("redis", func() {
+			policy.InitCacheBackends(config.PolicyCache{Backend: policy.CacheBackendRedis})
+
+			policies, err := policy.GetPoliciesFromCache("test", 1, []int64{123})
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), policies, 1)
+
+			assert.Equal(GinkgoT(), 0, impls.LocalPolicyCache.ItemCount())
+			assert.True(GinkgoT(), impls.PolicyCache.Exists(cache.NewStringKey("test:123")))
+		})
+
+		It("both", func() {
+			policies, err := policy.GetPoliciesFromCache("test", 1, []int64{123})
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), policies, 1)
+
+			assert.Equal(GinkgoT(), 1, impls.LocalPolicyCache.ItemCount())
+			assert.True(GinkgoT(), impls.PolicyCache.Exists(cache.NewStringKey("test:123")))
+		})
+	})
+
 	It("DeleteSystemSubjectPKsFromCache", func() {
 		mockActionService := mock.NewMockActionService(ctl)
 		mockActionService.EXPECT().ListThinActionBySystem("test").Return(
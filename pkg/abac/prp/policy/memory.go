@@ -39,6 +39,11 @@ const (
 
 var changeList = common.NewChangeList(changeListTypePolicy, policyLocalCacheTTL, maxChangeListCount)
 
+// LocalCacheExpiration 策略本地缓存的有效期
+func LocalCacheExpiration() time.Duration {
+	return policyLocalCacheTTL * time.Second
+}
+
 type memoryRetriever struct {
 	system              string
 	actionPK            int64
@@ -183,9 +188,6 @@ func deleteSystemSubjectPKsFromMemory(system string, subjectPKs []int64) error {
 }
 
 func batchDeleteSystemSubjectPKsFromMemory(systems []string, subjectPKs []int64) error {
-	// NOTE :Can't delete the key from the local cache, while no actionPK
-	//       Local cache will be update after next retrieve
-
 	if len(systems) == 0 || len(subjectPKs) == 0 {
 		return nil
 	}
@@ -227,9 +229,11 @@ func batchDeleteSystemSubjectPKsFromMemory(systems []string, subjectPKs []int64)
 		}
 	}
 
+	// NOTE: 广播给所有实例立即删除本地缓存; 广播丢失时, 由changelist保证在本地缓存时间内失效
 	err := multierr.Combine(
 		changeList.AddToChangeList(keyMembers),
 		changeList.Truncate(changeListKeys),
+		impls.BroadcastPolicyInvalidation(keyMembers),
 	)
 	return err
 }
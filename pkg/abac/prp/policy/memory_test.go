@@ -304,9 +304,15 @@ var _ = Describe("Memory", func() {
 		BeforeEach(func() {
 			impls.LocalPolicyCache = gocache.New(1*time.Minute, 1*time.Minute)
 			impls.ChangeListCache = redis.NewMockCache("test", 5*time.Minute)
+			impls.PubSubCache = redis.NewMockCache("test", 0)
 
 			ctl = gomock.NewController(GinkgoT())
 			patches = gomonkey.NewPatches()
+			// NOTE: the mock redis not support publish
+			patches.ApplyMethod(reflect.TypeOf(impls.PubSubCache), "Publish",
+				func(*redis.Cache, string, string) error {
+					return nil
+				})
 		})
 
 		AfterEach(func() {
@@ -375,11 +381,9 @@ var _ = Describe("Memory", func() {
 			err = batchDeleteSystemSubjectPKsFromMemory([]string{"test", "test2"}, []int64{123, 456})
 			assert.NoError(GinkgoT(), err)
 
-			// check the local cache
+			// check the local cache, deleted by the actionPKs of the system
 			_, ok = impls.LocalPolicyCache.Get("test:1:123")
-			// NOTE: Can't delete the key from the local cache, while no actionPK
-			//       so here is true
-			assert.True(GinkgoT(), ok)
+			assert.False(GinkgoT(), ok)
 
 			// check the change list
 			assert.True(GinkgoT(), impls.ChangeListCache.Exists(cache.NewStringKey("policy:test:1")))
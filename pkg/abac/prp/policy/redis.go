@@ -57,6 +57,10 @@ const RedisLayer = "PolicyRedisLayer"
 
 const RandExpireSeconds = 60
 
+// emptyPoliciesMarker 没有策略时缓存的占位值前缀, 后面跟过期的时间戳
+// NOTE: hash的field不能单独设置过期时间, 所以过期时间记录在值中, 过期后当做missing重新查询
+const emptyPoliciesMarker = "empty:"
+
 type redisRetriever struct {
 	system              string
 	actionPK            int64
@@ -108,6 +112,15 @@ func (r *redisRetriever) retrieve(subjectPKs []int64) ([]types.AuthPolicy, []int
 
 	noPoliciesSubjectPKs := make([]int64, 0, len(subjectPKs))
 	for subjectPK, policiesStr := range hitPolicies {
+		if strings.HasPrefix(policiesStr, emptyPoliciesMarker) {
+			if isEmptyPoliciesMarkerValid(policiesStr, nowUnix) {
+				noPoliciesSubjectPKs = append(noPoliciesSubjectPKs, subjectPK)
+			} else {
+				missSubjectPKs = append(missSubjectPKs, subjectPK)
+			}
+			continue
+		}
+
 		var ps []types.AuthPolicy
 		err = impls.PolicyCache.Unmarshal(util.StringToBytes(policiesStr), &ps)
 		if err != nil {
@@ -168,6 +181,15 @@ func (r *redisRetriever) setMissing(policies []types.AuthPolicy, missingPKs []in
 	return r.batchSet(groupedPolicies)
 }
 
+// isEmptyPoliciesMarkerValid 空策略占位值是否还在有效期内
+func isEmptyPoliciesMarkerValid(value string, nowUnix int64) bool {
+	expiredAt, err := strconv.ParseInt(strings.TrimPrefix(value, emptyPoliciesMarker), 10, 64)
+	if err != nil {
+		return false
+	}
+	return expiredAt > nowUnix
+}
+
 func (r *redisRetriever) batchGet(subjectPKs []int64) (
 	hitPolicies map[int64]string,
 	missSubjectPKs []int64,
@@ -222,12 +244,20 @@ func (r *redisRetriever) batchSet(subjectPKPolicies map[int64][]types.AuthPolicy
 	hashes := make([]redis.Hash, 0, len(subjectPKPolicies))
 	keys := make([]cache.Key, 0, len(subjectPKPolicies))
 
+	emptyMarker := emptyPoliciesMarker + strconv.FormatInt(
+		time.Now().Add(impls.PolicyCacheEmptyExpiration).Unix(), 10)
 	for subjectPK, policies := range subjectPKPolicies {
 		key := r.genKey(subjectPK)
 		field := strconv.FormatInt(r.actionPK, 10)
-		policiesBytes, err := impls.PolicyCache.Marshal(policies)
-		if err != nil {
-			return err
+
+		// 没有策略, 缓存较短时间的空值占位
+		value := emptyMarker
+		if len(policies) > 0 {
+			policiesBytes, err := impls.PolicyCache.Marshal(policies)
+			if err != nil {
+				return err
+			}
+			value = util.BytesToString(policiesBytes)
 		}
 
 		// make a hash
@@ -236,7 +266,7 @@ func (r *redisRetriever) batchSet(subjectPKPolicies map[int64][]types.AuthPolicy
 				Key:   key.Key(),
 				Field: field,
 			},
-			Value: util.BytesToString(policiesBytes),
+			Value: value,
 		})
 
 		// collect keys to set expire
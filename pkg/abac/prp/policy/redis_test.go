@@ -13,6 +13,8 @@ package policy
 import (
 	"errors"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/agiledragon/gomonkey"
@@ -227,6 +229,32 @@ var _ = Describe("Redis", func() {
 
 		})
 
+		It("empty policies marker", func() {
+			r.setMissing(retrievedPolicies, []int64{})
+			impls.PolicyCache.BatchHSetWithTx([]redis.Hash{
+				{
+					HashKeyField: redis.HashKeyField{Key: r.keyPrefix + "1000", Field: "1"},
+					Value:        emptyPoliciesMarker + strconv.FormatInt(now+60, 10),
+				},
+				{
+					HashKeyField: redis.HashKeyField{Key: r.keyPrefix + "1001", Field: "1"},
+					Value:        emptyPoliciesMarker + strconv.FormatInt(now-1, 10),
+				},
+			})
+
+			// the expired marker should be retrieved again
+			var retrievedPKs []int64
+			r.missingRetrieveFunc = func(pks []int64) (policies []types.AuthPolicy, missingSubjectPKs []int64, err error) {
+				retrievedPKs = pks
+				return nil, pks, nil
+			}
+			policies, missingSubjectPKs, err := r.retrieve([]int64{123, 456, 789, 1000, 1001})
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), policies, 3)
+			assert.Equal(GinkgoT(), []int64{1001}, retrievedPKs)
+			assert.ElementsMatch(GinkgoT(), []int64{1000, 1001}, missingSubjectPKs)
+		})
+
 		It("retrieve fail", func() {
 			subjectPKs := []int64{123, 456, 789}
 
@@ -359,6 +387,12 @@ var _ = Describe("Redis", func() {
 
 			assert.True(GinkgoT(), impls.PolicyCache.Exists(r.genKey(123)))
 			assert.True(GinkgoT(), impls.PolicyCache.Exists(r.genKey(456)))
+
+			// empty policies cached as the marker
+			hitPolicies, _, err := r.batchGet([]int64{123})
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), strings.HasPrefix(hitPolicies[123], emptyPoliciesMarker))
+			assert.True(GinkgoT(), isEmptyPoliciesMarkerValid(hitPolicies[123], time.Now().Unix()))
 		})
 
 		It("marshal fail", func() {
@@ -367,7 +401,7 @@ var _ = Describe("Redis", func() {
 					return nil, errors.New("marshal fail")
 				})
 
-			err := r.batchSet(subjectPKPolicies)
+			err := r.batchSet(map[int64][]types.AuthPolicy{123: {{PK: 1, SubjectPK: 123}}})
 			assert.Error(GinkgoT(), err)
 			assert.Equal(GinkgoT(), "marshal fail", err.Error())
 		})
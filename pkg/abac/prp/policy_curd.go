@@ -13,9 +13,11 @@ package prp
 import (
 	"errors"
 
-	"iam/pkg/abac/prp/expression"
-	"iam/pkg/abac/prp/policy"
+	log "github.com/sirupsen/logrus"
+
 	"iam/pkg/abac/types"
+	"iam/pkg/cache/impls"
+	"iam/pkg/cache/invalidation"
 	"iam/pkg/errorx"
 	svctypes "iam/pkg/service/types"
 	"iam/pkg/util"
@@ -48,6 +50,8 @@ func convertToServicePolicies(
 			SubjectPK:  subjectPK,
 			ActionPK:   actionPK,
 			Expression: p.Expression,
+			Effect:     p.Effect,
+			Priority:   p.Priority,
 			ExpiredAt:  p.ExpiredAt,
 			TemplateID: p.TemplateID,
 		})
@@ -68,29 +72,14 @@ func (m *policyManager) querySubjectActionForAlterPolicies(
 		return
 	}
 
-	// 2. 查询操作列表
-	actions, err := m.actionService.ListThinActionBySystem(systemID)
-	if err != nil {
-		err = errorWrapf(err, "actionService.ListThinActionBySystem systemID=`%s` fail", systemID)
-		return
-	}
-	actionPKMap = make(map[string]int64, len(actions))
-	for _, a := range actions {
-		actionPKMap[a.ID] = a.PK
-	}
-
-	// 3. 查询关联了资源类型的操作pk set
-	actionResourceTypes, err := m.actionService.ListActionResourceTypeIDByActionSystem(systemID)
+	// 2. 查询系统的操作索引: 操作列表, 以及关联了资源类型的操作pk set
+	index, err := impls.GetSystemActionIndex(systemID)
 	if err != nil {
-		err = errorWrapf(err, "actionService.ListActionResourceTypeIDByActionSystem systemID=`%s` fail", systemID)
+		err = errorWrapf(err, "impls.GetSystemActionIndex systemID=`%s` fail", systemID)
 		return
 	}
-	actionPKWithResourceTypeSet = util.NewInt64Set()
-	for _, t := range actionResourceTypes {
-		actionPKWithResourceTypeSet.Add(actionPKMap[t.ActionID])
-	}
 
-	return subjectPK, actionPKMap, actionPKWithResourceTypeSet, nil
+	return subjectPK, index.ActionPKMap, index.ActionPKWithResourceTypeSet, nil
 }
 
 // DeleteByIDs 通过IDs批量删除策略
@@ -107,7 +96,8 @@ func (m *policyManager) DeleteByIDs(system string, subjectType, subjectID string
 	// 判断policyIDs是否为空，避免执行无效SQL
 	if len(policyIDs) > 0 {
 		// NOTE: delete cache here => 可以查actionPK
-		defer policy.DeleteSystemSubjectPKsFromCache(system, []int64{pk})
+		defer invalidation.DeleteSystemSubjectPolicies(system, []int64{pk})
+		defer m.syncGroupSystems(subjectType, pk)
 
 		err := m.policyService.DeleteByPKs(pk, policyIDs)
 		if err != nil {
@@ -150,7 +140,8 @@ func (m *policyManager) AlterCustomPolicies(
 	}
 
 	// NOTE: delete the policy cache before leave => 可以查actionPK
-	defer policy.DeleteSystemSubjectPKsFromCache(systemID, []int64{subjectPK})
+	defer invalidation.DeleteSystemSubjectPolicies(systemID, []int64{subjectPK})
+	defer m.syncGroupSystems(subjectType, subjectPK)
 
 	// 3. service执行 create, update, delete
 	updatedActionPKExpressionPKs, err := m.policyService.AlterCustomPolicies(
@@ -160,7 +151,7 @@ func (m *policyManager) AlterCustomPolicies(
 		return
 	}
 
-	defer expression.BatchDeleteExpressionsFromCache(updatedActionPKExpressionPKs)
+	defer invalidation.DeleteExpressions(updatedActionPKExpressionPKs)
 
 	return nil
 }
@@ -189,7 +180,8 @@ func (m *policyManager) CreateAndDeleteTemplatePolicies(
 	}
 
 	// NOTE: delete the policy cache before leave
-	defer policy.DeleteSystemSubjectPKsFromCache(systemID, []int64{subjectPK})
+	defer invalidation.DeleteSystemSubjectPolicies(systemID, []int64{subjectPK})
+	defer m.syncGroupSystems(subjectType, subjectPK)
 
 	// 3. service执行 create, delete
 	err = m.policyService.CreateAndDeleteTemplatePolicies(
@@ -226,7 +218,7 @@ func (m *policyManager) UpdateTemplatePolicies(
 	}
 
 	// NOTE: delete the policy cache before leave => 可以查actionPK
-	defer policy.DeleteSystemSubjectPKsFromCache(systemID, []int64{subjectPK})
+	defer invalidation.DeleteSystemSubjectPolicies(systemID, []int64{subjectPK})
 
 	// 3. service执行 update
 	err = m.policyService.UpdateTemplatePolicies(subjectPK, ups, actionPKWithResourceTypeSet)
@@ -251,7 +243,8 @@ func (m *policyManager) DeleteTemplatePolicies(systemID, subjectType, subjectID
 	}
 
 	// NOTE: delete the policy cache before leave
-	defer policy.DeleteSystemSubjectPKsFromCache(systemID, []int64{subjectPK})
+	defer invalidation.DeleteSystemSubjectPolicies(systemID, []int64{subjectPK})
+	defer m.syncGroupSystems(subjectType, subjectPK)
 
 	// 2. service执行 delete
 	err = m.policyService.DeleteTemplatePolicies(subjectPK, templateID)
@@ -264,6 +257,35 @@ func (m *policyManager) DeleteTemplatePolicies(systemID, subjectType, subjectID
 	return nil
 }
 
+// UpdateTemplatePoliciesExpiredAt 更新模板过期时间, 模板生成的所有策略使用同一个过期时间
+func (m *policyManager) UpdateTemplatePoliciesExpiredAt(
+	systemID, subjectType, subjectID string, templateID, expiredAt int64,
+) (updated int64, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PRP, "UpdateTemplatePoliciesExpiredAt")
+
+	// 1. 查询 subject subjectPK
+	subjectPK, err := m.subjectService.GetPK(subjectType, subjectID)
+	if err != nil {
+		err = errorWrapf(err, "subjectService.GetPK subjectType=`%s`, subjectID=`%s` fail",
+			subjectType, subjectID)
+		return
+	}
+
+	// NOTE: delete the policy cache before leave, the policies may be partially updated
+	defer invalidation.DeleteSystemSubjectPolicies(systemID, []int64{subjectPK})
+
+	// 2. service分批更新
+	updated, err = m.policyService.UpdateTemplatePoliciesExpiredAt(subjectPK, templateID, expiredAt)
+	if err != nil {
+		err = errorWrapf(err,
+			"policyService.UpdateTemplatePoliciesExpiredAt subjectPK=`%d`, templateID=`%d`, expiredAt=`%d` fail",
+			subjectPK, templateID, expiredAt)
+		return
+	}
+
+	return updated, nil
+}
+
 // UpdateSubjectPoliciesExpiredAt 更新过期时间
 func (m *policyManager) UpdateSubjectPoliciesExpiredAt(
 	subjectType, subjectID string, policies []types.PolicyPKExpiredAt,
@@ -316,7 +338,7 @@ func (m *policyManager) UpdateSubjectPoliciesExpiredAt(
 	}
 
 	// 清理缓存 => NOTE: 这里是可以知道actionPK的!!!!1
-	defer policy.BatchDeleteSystemSubjectPKsFromCache(systemSet.ToSlice(), []int64{subjectPK})
+	defer invalidation.BatchDeleteSystemSubjectPolicies(systemSet.ToSlice(), []int64{subjectPK})
 
 	err = m.policyService.UpdateExpiredAt(updatePolicies)
 	if err != nil {
@@ -351,6 +373,19 @@ func (m *policyManager) queryPoliciesSystemSet(policies []svctypes.QueryPolicy)
 	return systemSet, nil
 }
 
+// syncGroupSystems 用户组的策略变更后, 同步用户组在系统下有策略的记录, 鉴权时据此获取subject有效的用户组
+// NOTE: 失败只记录日志, 策略已变更成功; 多余的记录不影响鉴权结果, 缺少的记录在下次策略或成员变更时补齐
+func (m *policyManager) syncGroupSystems(subjectType string, subjectPK int64) {
+	if subjectType != svctypes.GroupType {
+		return
+	}
+
+	err := m.subjectSystemGroupService.SyncGroupSystems(subjectPK)
+	if err != nil {
+		log.WithError(err).Errorf("subjectSystemGroupService.SyncGroupSystems groupPK=`%d` fail", subjectPK)
+	}
+}
+
 // DeleteByActionID 通过ActionID批量删除策略
 func (m *policyManager) DeleteByActionID(systemID, actionID string) error {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PRP, "`DeleteByActionID`")
@@ -15,8 +15,10 @@ import (
 
 	"iam/pkg/abac/prp/policy"
 	"iam/pkg/abac/types"
+	"iam/pkg/cache/impls"
 	"iam/pkg/service/mock"
 	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
 
 	"github.com/agiledragon/gomonkey"
 	"github.com/golang/mock/gomock"
@@ -24,6 +26,12 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func mockGetSystemActionIndex(index impls.SystemActionIndex, err error) *gomonkey.Patches {
+	return gomonkey.ApplyFunc(impls.GetSystemActionIndex, func(systemID string) (impls.SystemActionIndex, error) {
+		return index, err
+	})
+}
+
 var _ = Describe("PolicyCurd", func() {
 
 	Describe("DeleteByIDs", func() {
@@ -40,7 +48,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("subjectService.GetPK fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(0), errors.New("get pk fail"),
 			).AnyTimes()
@@ -55,7 +63,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("policyService.DeleteByPKs fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
@@ -82,7 +90,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("success", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
@@ -107,6 +115,30 @@ var _ = Describe("PolicyCurd", func() {
 			assert.NoError(GinkgoT(), err)
 		})
 
+		It("success, sync the systems of group", func() {
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
+			mockSubjectService.EXPECT().GetPK("group", "test").Return(int64(1), nil)
+			mockPolicyService := mock.NewMockPolicyService(ctl)
+			mockPolicyService.EXPECT().DeleteByPKs(int64(1), []int64{1, 2}).Return(nil)
+			mockSubjectSystemGroupService := mock.NewMockSubjectSystemGroupService(ctl)
+			mockSubjectSystemGroupService.EXPECT().SyncGroupSystems(int64(1)).Return(errors.New("sync fail"))
+
+			patches = gomonkey.ApplyFunc(policy.DeleteSystemSubjectPKsFromCache,
+				func(systemID string, pks []int64) error {
+					return nil
+				})
+
+			manager := &policyManager{
+				subjectService:            mockSubjectService,
+				policyService:             mockPolicyService,
+				subjectSystemGroupService: mockSubjectSystemGroupService,
+			}
+
+			// the sync fail will not fail the policies deletion
+			err := manager.DeleteByIDs("test", "group", "test", []int64{1, 2})
+			assert.NoError(GinkgoT(), err)
+		})
+
 	})
 
 	Describe("AlterCustomPolicies", func() {
@@ -123,7 +155,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("subjectService.GetPK fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(0), errors.New("get pk fail"),
 			).AnyTimes()
@@ -137,68 +169,36 @@ var _ = Describe("PolicyCurd", func() {
 			assert.Contains(GinkgoT(), err.Error(), "subjectService.GetPK")
 		})
 
-		It("actionService.ListThinActionBySystem fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
-			mockSubjectService.EXPECT().GetPK("user", "test").Return(
-				int64(1), nil,
-			).AnyTimes()
-
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, errors.New("list action fail"),
-			).AnyTimes()
-
-			manager := &policyManager{
-				subjectService: mockSubjectService,
-				actionService:  mockActionService,
-			}
-
-			err := manager.AlterCustomPolicies("test", "user", "test", []types.Policy{}, []types.Policy{}, []int64{1})
-			assert.Error(GinkgoT(), err)
-			assert.Contains(GinkgoT(), err.Error(), "actionService.ListThinActionBySystem")
-		})
-
-		It("actionService.ListActionResourceTypeIDByActionSystem fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+		It("impls.GetSystemActionIndex fail", func() {
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
 
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, nil,
-			).AnyTimes()
-			mockActionService.EXPECT().ListActionResourceTypeIDByActionSystem("test").Return(
-				[]svctypes.ActionResourceTypeID{}, errors.New("list action fail"),
-			).AnyTimes()
+			patches = mockGetSystemActionIndex(impls.SystemActionIndex{}, errors.New("get index fail"))
 
 			manager := &policyManager{
 				subjectService: mockSubjectService,
-				actionService:  mockActionService,
 			}
 
 			err := manager.AlterCustomPolicies("test", "user", "test", []types.Policy{}, []types.Policy{}, []int64{1})
 			assert.Error(GinkgoT(), err)
-			assert.Contains(GinkgoT(), err.Error(), "actionService.ListActionResourceTypeIDByActionSystem")
+			assert.Contains(GinkgoT(), err.Error(), "impls.GetSystemActionIndex")
 		})
 
 		It("ErrCreateActionNotExists fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
 
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, nil,
-			).AnyTimes()
-			mockActionService.EXPECT().ListActionResourceTypeIDByActionSystem("test").Return(
-				[]svctypes.ActionResourceTypeID{}, nil,
-			).AnyTimes()
+			patches = mockGetSystemActionIndex(impls.SystemActionIndex{
+				ActionPKMap:                 map[string]int64{},
+				ActionPKWithResourceTypeSet: util.NewInt64Set(),
+			}, nil)
 
 			manager := &policyManager{
 				subjectService: mockSubjectService,
-				actionService:  mockActionService,
 			}
 
 			err := manager.AlterCustomPolicies("test", "user", "test", []types.Policy{{
@@ -211,22 +211,18 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("ErrUpdateActionNotExists fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
 
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, nil,
-			).AnyTimes()
-			mockActionService.EXPECT().ListActionResourceTypeIDByActionSystem("test").Return(
-				[]svctypes.ActionResourceTypeID{}, nil,
-			).AnyTimes()
+			patches = mockGetSystemActionIndex(impls.SystemActionIndex{
+				ActionPKMap:                 map[string]int64{},
+				ActionPKWithResourceTypeSet: util.NewInt64Set(),
+			}, nil)
 
 			manager := &policyManager{
 				subjectService: mockSubjectService,
-				actionService:  mockActionService,
 			}
 
 			err := manager.AlterCustomPolicies("test", "user", "test", []types.Policy{}, []types.Policy{{
@@ -239,18 +235,15 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("policyService.AlterCustomPolicies fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
 
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, nil,
-			).AnyTimes()
-			mockActionService.EXPECT().ListActionResourceTypeIDByActionSystem("test").Return(
-				[]svctypes.ActionResourceTypeID{}, nil,
-			).AnyTimes()
+			patches = mockGetSystemActionIndex(impls.SystemActionIndex{
+				ActionPKMap:                 map[string]int64{},
+				ActionPKWithResourceTypeSet: util.NewInt64Set(),
+			}, nil)
 			mockPolicyService := mock.NewMockPolicyService(ctl)
 			mockPolicyService.EXPECT().AlterCustomPolicies(
 				gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
@@ -258,14 +251,13 @@ var _ = Describe("PolicyCurd", func() {
 				map[int64][]int64{}, errors.New("alter policies fail"),
 			).AnyTimes()
 
-			patches = gomonkey.ApplyFunc(policy.DeleteSystemSubjectPKsFromCache,
+			patches.ApplyFunc(policy.DeleteSystemSubjectPKsFromCache,
 				func(systemID string, pks []int64) error {
 					return nil
 				})
 
 			manager := &policyManager{
 				subjectService: mockSubjectService,
-				actionService:  mockActionService,
 				policyService:  mockPolicyService,
 			}
 
@@ -275,18 +267,15 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("AlterCustomPolicies success", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
 
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, nil,
-			).AnyTimes()
-			mockActionService.EXPECT().ListActionResourceTypeIDByActionSystem("test").Return(
-				[]svctypes.ActionResourceTypeID{}, nil,
-			).AnyTimes()
+			patches = mockGetSystemActionIndex(impls.SystemActionIndex{
+				ActionPKMap:                 map[string]int64{},
+				ActionPKWithResourceTypeSet: util.NewInt64Set(),
+			}, nil)
 			mockPolicyService := mock.NewMockPolicyService(ctl)
 			mockPolicyService.EXPECT().AlterCustomPolicies(
 				gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
@@ -294,14 +283,13 @@ var _ = Describe("PolicyCurd", func() {
 				map[int64][]int64{}, nil,
 			).AnyTimes()
 
-			patches = gomonkey.ApplyFunc(policy.DeleteSystemSubjectPKsFromCache,
+			patches.ApplyFunc(policy.DeleteSystemSubjectPKsFromCache,
 				func(systemID string, pks []int64) error {
 					return nil
 				})
 
 			manager := &policyManager{
 				subjectService: mockSubjectService,
-				actionService:  mockActionService,
 				policyService:  mockPolicyService,
 			}
 
@@ -325,7 +313,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("subjectService.GetPK fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(0), errors.New("get pk fail"),
 			).AnyTimes()
@@ -340,7 +328,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("empty return", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
@@ -354,7 +342,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("policyService.ListQueryByPKs", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
@@ -377,7 +365,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("empty update policies return", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
@@ -399,7 +387,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("actionService.ListThinActionByPKs fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
@@ -431,7 +419,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("policyService.UpdateExpiredAt fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
@@ -470,7 +458,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("success", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
@@ -521,7 +509,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("subjectService.GetPK fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(0), errors.New("get pk fail"),
 			).AnyTimes()
@@ -535,68 +523,36 @@ var _ = Describe("PolicyCurd", func() {
 			assert.Contains(GinkgoT(), err.Error(), "subjectService.GetPK")
 		})
 
-		It("actionService.ListThinActionBySystem fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+		It("impls.GetSystemActionIndex fail", func() {
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
 
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, errors.New("list action fail"),
-			).AnyTimes()
+			patches = mockGetSystemActionIndex(impls.SystemActionIndex{}, errors.New("get index fail"))
 
 			manager := &policyManager{
 				subjectService: mockSubjectService,
-				actionService:  mockActionService,
 			}
 
 			err := manager.CreateAndDeleteTemplatePolicies("test", "user", "test", int64(1), []types.Policy{}, []int64{1})
 			assert.Error(GinkgoT(), err)
-			assert.Contains(GinkgoT(), err.Error(), "actionService.ListThinActionBySystem")
-		})
-
-		It("actionService.ListActionResourceTypeIDByActionSystem fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
-			mockSubjectService.EXPECT().GetPK("user", "test").Return(
-				int64(1), nil,
-			).AnyTimes()
-
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, nil,
-			).AnyTimes()
-			mockActionService.EXPECT().ListActionResourceTypeIDByActionSystem("test").Return(
-				[]svctypes.ActionResourceTypeID{}, errors.New("list action fail"),
-			).AnyTimes()
-
-			manager := &policyManager{
-				subjectService: mockSubjectService,
-				actionService:  mockActionService,
-			}
-
-			err := manager.CreateAndDeleteTemplatePolicies("test", "user", "test", int64(1), []types.Policy{}, []int64{1})
-			assert.Error(GinkgoT(), err)
-			assert.Contains(GinkgoT(), err.Error(), "actionService.ListActionResourceTypeIDByActionSystem")
+			assert.Contains(GinkgoT(), err.Error(), "impls.GetSystemActionIndex")
 		})
 
 		It("ErrActionNotExists fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
 
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, nil,
-			).AnyTimes()
-			mockActionService.EXPECT().ListActionResourceTypeIDByActionSystem("test").Return(
-				[]svctypes.ActionResourceTypeID{}, nil,
-			).AnyTimes()
+			patches = mockGetSystemActionIndex(impls.SystemActionIndex{
+				ActionPKMap:                 map[string]int64{},
+				ActionPKWithResourceTypeSet: util.NewInt64Set(),
+			}, nil)
 
 			manager := &policyManager{
 				subjectService: mockSubjectService,
-				actionService:  mockActionService,
 			}
 
 			err := manager.CreateAndDeleteTemplatePolicies("test", "user", "test", int64(1), []types.Policy{{
@@ -609,31 +565,27 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("policyService.CreateAndDeleteTemplatePolicies fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
 
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, nil,
-			).AnyTimes()
-			mockActionService.EXPECT().ListActionResourceTypeIDByActionSystem("test").Return(
-				[]svctypes.ActionResourceTypeID{}, nil,
-			).AnyTimes()
+			patches = mockGetSystemActionIndex(impls.SystemActionIndex{
+				ActionPKMap:                 map[string]int64{},
+				ActionPKWithResourceTypeSet: util.NewInt64Set(),
+			}, nil)
 			mockPolicyService := mock.NewMockPolicyService(ctl)
 			mockPolicyService.EXPECT().CreateAndDeleteTemplatePolicies(
 				gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
 			).Return(errors.New("create policies fail")).AnyTimes()
 
-			patches = gomonkey.ApplyFunc(policy.DeleteSystemSubjectPKsFromCache,
+			patches.ApplyFunc(policy.DeleteSystemSubjectPKsFromCache,
 				func(systemID string, pks []int64) error {
 					return nil
 				})
 
 			manager := &policyManager{
 				subjectService: mockSubjectService,
-				actionService:  mockActionService,
 				policyService:  mockPolicyService,
 			}
 
@@ -643,31 +595,27 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("CreateAndDeleteTemplatePolicies success", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
 
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, nil,
-			).AnyTimes()
-			mockActionService.EXPECT().ListActionResourceTypeIDByActionSystem("test").Return(
-				[]svctypes.ActionResourceTypeID{}, nil,
-			).AnyTimes()
+			patches = mockGetSystemActionIndex(impls.SystemActionIndex{
+				ActionPKMap:                 map[string]int64{},
+				ActionPKWithResourceTypeSet: util.NewInt64Set(),
+			}, nil)
 			mockPolicyService := mock.NewMockPolicyService(ctl)
 			mockPolicyService.EXPECT().CreateAndDeleteTemplatePolicies(
 				gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
 			).Return(nil).AnyTimes()
 
-			patches = gomonkey.ApplyFunc(policy.DeleteSystemSubjectPKsFromCache,
+			patches.ApplyFunc(policy.DeleteSystemSubjectPKsFromCache,
 				func(systemID string, pks []int64) error {
 					return nil
 				})
 
 			manager := &policyManager{
 				subjectService: mockSubjectService,
-				actionService:  mockActionService,
 				policyService:  mockPolicyService,
 			}
 
@@ -691,7 +639,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("subjectService.GetPK fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(0), errors.New("get pk fail"),
 			).AnyTimes()
@@ -705,68 +653,36 @@ var _ = Describe("PolicyCurd", func() {
 			assert.Contains(GinkgoT(), err.Error(), "subjectService.GetPK")
 		})
 
-		It("actionService.ListThinActionBySystem fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
-			mockSubjectService.EXPECT().GetPK("user", "test").Return(
-				int64(1), nil,
-			).AnyTimes()
-
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, errors.New("list action fail"),
-			).AnyTimes()
-
-			manager := &policyManager{
-				subjectService: mockSubjectService,
-				actionService:  mockActionService,
-			}
-
-			err := manager.UpdateTemplatePolicies("test", "user", "test", []types.Policy{})
-			assert.Error(GinkgoT(), err)
-			assert.Contains(GinkgoT(), err.Error(), "actionService.ListThinActionBySystem")
-		})
-
-		It("actionService.ListActionResourceTypeIDByActionSystem fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+		It("impls.GetSystemActionIndex fail", func() {
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
 
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, nil,
-			).AnyTimes()
-			mockActionService.EXPECT().ListActionResourceTypeIDByActionSystem("test").Return(
-				[]svctypes.ActionResourceTypeID{}, errors.New("list action fail"),
-			).AnyTimes()
+			patches = mockGetSystemActionIndex(impls.SystemActionIndex{}, errors.New("get index fail"))
 
 			manager := &policyManager{
 				subjectService: mockSubjectService,
-				actionService:  mockActionService,
 			}
 
 			err := manager.UpdateTemplatePolicies("test", "user", "test", []types.Policy{})
 			assert.Error(GinkgoT(), err)
-			assert.Contains(GinkgoT(), err.Error(), "actionService.ListActionResourceTypeIDByActionSystem")
+			assert.Contains(GinkgoT(), err.Error(), "impls.GetSystemActionIndex")
 		})
 
 		It("ErrActionNotExists fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
 
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, nil,
-			).AnyTimes()
-			mockActionService.EXPECT().ListActionResourceTypeIDByActionSystem("test").Return(
-				[]svctypes.ActionResourceTypeID{}, nil,
-			).AnyTimes()
+			patches = mockGetSystemActionIndex(impls.SystemActionIndex{
+				ActionPKMap:                 map[string]int64{},
+				ActionPKWithResourceTypeSet: util.NewInt64Set(),
+			}, nil)
 
 			manager := &policyManager{
 				subjectService: mockSubjectService,
-				actionService:  mockActionService,
 			}
 
 			err := manager.UpdateTemplatePolicies("test", "user", "test", []types.Policy{{
@@ -779,31 +695,27 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("policyService.UpdateTemplatePolicies fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
 
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, nil,
-			).AnyTimes()
-			mockActionService.EXPECT().ListActionResourceTypeIDByActionSystem("test").Return(
-				[]svctypes.ActionResourceTypeID{}, nil,
-			).AnyTimes()
+			patches = mockGetSystemActionIndex(impls.SystemActionIndex{
+				ActionPKMap:                 map[string]int64{},
+				ActionPKWithResourceTypeSet: util.NewInt64Set(),
+			}, nil)
 			mockPolicyService := mock.NewMockPolicyService(ctl)
 			mockPolicyService.EXPECT().UpdateTemplatePolicies(
 				gomock.Any(), gomock.Any(), gomock.Any(),
 			).Return(errors.New("update fail")).AnyTimes()
 
-			patches = gomonkey.ApplyFunc(policy.DeleteSystemSubjectPKsFromCache,
+			patches.ApplyFunc(policy.DeleteSystemSubjectPKsFromCache,
 				func(systemID string, pks []int64) error {
 					return nil
 				})
 
 			manager := &policyManager{
 				subjectService: mockSubjectService,
-				actionService:  mockActionService,
 				policyService:  mockPolicyService,
 			}
 
@@ -813,31 +725,27 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("UpdateTemplatePolicies success", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
 
-			mockActionService := mock.NewMockActionService(ctl)
-			mockActionService.EXPECT().ListThinActionBySystem("test").Return(
-				[]svctypes.ThinAction{}, nil,
-			).AnyTimes()
-			mockActionService.EXPECT().ListActionResourceTypeIDByActionSystem("test").Return(
-				[]svctypes.ActionResourceTypeID{}, nil,
-			).AnyTimes()
+			patches = mockGetSystemActionIndex(impls.SystemActionIndex{
+				ActionPKMap:                 map[string]int64{},
+				ActionPKWithResourceTypeSet: util.NewInt64Set(),
+			}, nil)
 			mockPolicyService := mock.NewMockPolicyService(ctl)
 			mockPolicyService.EXPECT().UpdateTemplatePolicies(
 				gomock.Any(), gomock.Any(), gomock.Any(),
 			).Return(nil).AnyTimes()
 
-			patches = gomonkey.ApplyFunc(policy.DeleteSystemSubjectPKsFromCache,
+			patches.ApplyFunc(policy.DeleteSystemSubjectPKsFromCache,
 				func(systemID string, pks []int64) error {
 					return nil
 				})
 
 			manager := &policyManager{
 				subjectService: mockSubjectService,
-				actionService:  mockActionService,
 				policyService:  mockPolicyService,
 			}
 
@@ -861,7 +769,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("subjectService.GetPK fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(0), errors.New("get pk fail"),
 			).AnyTimes()
@@ -876,7 +784,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("policyService.DeleteTemplatePolicies fail", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
@@ -903,7 +811,7 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 		It("success", func() {
-			mockSubjectService := mock.NewMockSubjectService(ctl)
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
 			mockSubjectService.EXPECT().GetPK("user", "test").Return(
 				int64(1), nil,
 			).AnyTimes()
@@ -929,4 +837,75 @@ var _ = Describe("PolicyCurd", func() {
 		})
 
 	})
+
+	Describe("UpdateTemplatePoliciesExpiredAt", func() {
+		var ctl *gomock.Controller
+		var patches *gomonkey.Patches
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			patches = gomonkey.ApplyFunc(policy.DeleteSystemSubjectPKsFromCache,
+				func(systemID string, pks []int64) error {
+					return nil
+				})
+		})
+		AfterEach(func() {
+			ctl.Finish()
+			patches.Reset()
+		})
+
+		It("subjectService.GetPK fail", func() {
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
+			mockSubjectService.EXPECT().GetPK("user", "test").Return(
+				int64(0), errors.New("get pk fail"),
+			).AnyTimes()
+
+			manager := &policyManager{
+				subjectService: mockSubjectService,
+			}
+
+			_, err := manager.UpdateTemplatePoliciesExpiredAt("test", "user", "test", int64(1), int64(100))
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "subjectService.GetPK")
+		})
+
+		It("policyService.UpdateTemplatePoliciesExpiredAt fail", func() {
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
+			mockSubjectService.EXPECT().GetPK("user", "test").Return(
+				int64(1), nil,
+			).AnyTimes()
+			mockPolicyService := mock.NewMockPolicyService(ctl)
+			mockPolicyService.EXPECT().UpdateTemplatePoliciesExpiredAt(int64(1), int64(1), int64(100)).Return(
+				int64(0), errors.New("update fail"),
+			).AnyTimes()
+
+			manager := &policyManager{
+				subjectService: mockSubjectService,
+				policyService:  mockPolicyService,
+			}
+
+			_, err := manager.UpdateTemplatePoliciesExpiredAt("test", "user", "test", int64(1), int64(100))
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "policyService.UpdateTemplatePoliciesExpiredAt")
+		})
+
+		It("ok", func() {
+			mockSubjectService := mock.NewMockSubjectBaseReadService(ctl)
+			mockSubjectService.EXPECT().GetPK("user", "test").Return(
+				int64(1), nil,
+			).AnyTimes()
+			mockPolicyService := mock.NewMockPolicyService(ctl)
+			mockPolicyService.EXPECT().UpdateTemplatePoliciesExpiredAt(int64(1), int64(1), int64(100)).Return(
+				int64(3), nil,
+			).AnyTimes()
+
+			manager := &policyManager{
+				subjectService: mockSubjectService,
+				policyService:  mockPolicyService,
+			}
+
+			updated, err := manager.UpdateTemplatePoliciesExpiredAt("test", "user", "test", int64(1), int64(100))
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(3), updated)
+		})
+	})
 })
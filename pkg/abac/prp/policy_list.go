@@ -12,6 +12,7 @@ package prp
 
 import (
 	"fmt"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -35,13 +36,26 @@ var (
 	emptyAuthExpression = svctypes.AuthExpression{}
 )
 
+// LocalCacheExpiration 策略及表达式本地缓存中较短的有效期, 在此时间内重复查询同一subject/action的策略会直接命中本地缓存
+func LocalCacheExpiration() time.Duration {
+	policyExpiration := policy.LocalCacheExpiration()
+	expressionExpiration := expression.LocalCacheExpiration()
+	if expressionExpiration < policyExpiration {
+		return expressionExpiration
+	}
+	return policyExpiration
+}
+
 func convertToAuthPolicy(svcPolicy svctypes.AuthPolicy, svcExpression svctypes.AuthExpression) types.AuthPolicy {
 	return types.AuthPolicy{
 		Version:             service.PolicyVersion,
 		ID:                  svcPolicy.PK,
+		ExpressionPK:        svcExpression.PK,
 		Expression:          svcExpression.Expression,
 		ExpressionSignature: svcExpression.Signature,
 		ExpiredAt:           svcPolicy.ExpiredAt,
+		Effect:              svcPolicy.Effect,
+		Priority:            svcPolicy.Priority,
 	}
 }
 
@@ -89,6 +103,33 @@ func reportTooLargeReturnedPolicies(count int, system, actionID, subjectType, su
 	)
 }
 
+// pickPoliciesWithoutResourceType 操作没有关联资源类型时, 所有策略都会命中, 只需要返回allow/deny中优先级最高的各一条
+func pickPoliciesWithoutResourceType(effectPolicies []svctypes.AuthPolicy) []svctypes.AuthPolicy {
+	var allowPolicy, denyPolicy *svctypes.AuthPolicy
+	for i := range effectPolicies {
+		p := &effectPolicies[i]
+		if p.Effect == svctypes.PolicyEffectDeny {
+			if denyPolicy == nil || p.Priority > denyPolicy.Priority {
+				denyPolicy = p
+			}
+			continue
+		}
+
+		if allowPolicy == nil || p.Priority > allowPolicy.Priority {
+			allowPolicy = p
+		}
+	}
+
+	policies := make([]svctypes.AuthPolicy, 0, 2)
+	if denyPolicy != nil {
+		policies = append(policies, *denyPolicy)
+	}
+	if allowPolicy != nil {
+		policies = append(policies, *allowPolicy)
+	}
+	return policies
+}
+
 // ListBySubjectAction ...
 func (m *policyManager) ListBySubjectAction(
 	system string,
@@ -96,6 +137,28 @@ func (m *policyManager) ListBySubjectAction(
 	action types.Action,
 	withoutCache bool,
 	parentEntry *debug.Entry,
+) (policies []types.AuthPolicy, err error) {
+	return m.listBySubjectAction(system, subject, action, withoutCache, nil, parentEntry)
+}
+
+// ListBySubjectActionWithOverlay 查询叠加了策略变更的策略, 只查询DB, 不读写策略缓存
+func (m *policyManager) ListBySubjectActionWithOverlay(
+	system string,
+	subject types.Subject,
+	action types.Action,
+	overlay *PolicyOverlay,
+	parentEntry *debug.Entry,
+) (policies []types.AuthPolicy, err error) {
+	return m.listBySubjectAction(system, subject, action, true, overlay, parentEntry)
+}
+
+func (m *policyManager) listBySubjectAction(
+	system string,
+	subject types.Subject,
+	action types.Action,
+	withoutCache bool,
+	overlay *PolicyOverlay,
+	parentEntry *debug.Entry,
 ) (policies []types.AuthPolicy, err error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PRP, "ListPolicyBySubjectAction")
 
@@ -107,7 +170,7 @@ func (m *policyManager) ListBySubjectAction(
 	// 1. get effect subject pks
 	debug.AddStep(entry, "Get Effect Subject PKs")
 	// 通过subject对象获取PK
-	effectSubjectPKs, err := getEffectSubjectPKs(subject)
+	effectSubjectPKs, err := getEffectSubjectPKs(system, subject)
 	if err != nil {
 		err = errorWrapf(err, "getEffectSubjectPKs subject=`%+v` fail", subject)
 		return
@@ -147,24 +210,32 @@ func (m *policyManager) ListBySubjectAction(
 			return
 		}
 	}
+	// apply the overlay: remove the deleted policies, append the created policies at last
+	var createdPolicies []types.AuthPolicy
+	if overlay.affect(effectSubjectPKs) {
+		effectPolicies = overlay.filterDeleted(effectPolicies)
+		createdPolicies = overlay.listCreated(actionPK)
+		debug.WithValue(entry, "overlayCreatedPolicies", createdPolicies)
+	}
 	debug.WithValue(entry, "effectPolicies", effectPolicies)
 
 	// if no effect policies, return
 	if len(effectPolicies) == 0 {
 		debug.WithValue(entry, "got_no_policies", true)
-		return
+		return createdPolicies, nil
 	}
 
 	// if action has not resource types, will not query expression!!!!!!
 	if action.WithoutResourceType() {
 		debug.WithValue(entry, "without_resource_types", true)
-		// only return the first policy with empty expression, will auth=True or policy=Any
-		policy := effectPolicies[0]
-
+		// only return the allow/deny policy with the highest priority and empty expression,
+		// will auth=True or policy=Any, unless the deny policy hit(decided by the evaluation mode of system)
 		// NOTE: the expression will be ""
 		// TODO: ? should be "" or "[]"?
-		policies = append(policies, convertToAuthPolicy(policy, emptyAuthExpression))
-		return
+		for _, policy := range pickPoliciesWithoutResourceType(effectPolicies) {
+			policies = append(policies, convertToAuthPolicy(policy, emptyAuthExpression))
+		}
+		return append(policies, createdPolicies...), nil
 	}
 
 	// 4. expressionPK 去重
@@ -207,6 +278,20 @@ func (m *policyManager) ListBySubjectAction(
 	}
 
 	// NOTE: any 排在前面的逻辑去掉, 应该在计算或转换的时候处理合并 remove policy with `Any` first
+	policies = uniqAuthPoliciesBySignature(effectPolicies, expressionMap)
+
+	// 7. return
+	// debug.WithValue(entry, "return policies", policies)
+	reportTooLargeReturnedPolicies(len(policies), system, action.ID, subject.Type, subject.ID)
+	return append(policies, createdPolicies...), nil
+}
+
+// uniqAuthPoliciesBySignature 转换为鉴权策略, 相同表达式(signature)的策略只保留第一个
+func uniqAuthPoliciesBySignature(
+	effectPolicies []svctypes.AuthPolicy,
+	expressionMap map[int64]svctypes.AuthExpression,
+) []types.AuthPolicy {
+	policies := make([]types.AuthPolicy, 0, len(effectPolicies))
 	signatureSet := util.NewFixedLengthStringSet(len(effectPolicies))
 	for _, p := range effectPolicies {
 		expression := expressionMap[p.ExpressionPK]
@@ -216,11 +301,7 @@ func (m *policyManager) ListBySubjectAction(
 			policies = append(policies, convertToAuthPolicy(p, expression))
 		}
 	}
-
-	// 7. return
-	// debug.WithValue(entry, "return policies", policies)
-	reportTooLargeReturnedPolicies(len(policies), system, action.ID, subject.Type, subject.ID)
-	return policies, nil
+	return policies
 }
 
 // GetExpressionsFromCache will retrieve expression from cache
@@ -101,6 +101,8 @@ func (m *policyManager) GetByActionTemplate(
 		ID:         svcTypesPolicy.ID,
 		Expression: svcTypesPolicy.Expression,
 		ExpiredAt:  svcTypesPolicy.ExpiredAt,
+		Effect:     svcTypesPolicy.Effect,
+		Priority:   svcTypesPolicy.Priority,
 	}
 	return policy, err
 }
@@ -159,6 +161,8 @@ func (m *policyManager) convertToSaaSPolicies(
 			ID:        p.ID,
 			System:    actionMap[p.ActionPK].System,
 			ActionID:  actionMap[p.ActionPK].ID,
+			Effect:    p.Effect,
+			Priority:  p.Priority,
 			ExpiredAt: p.ExpiredAt,
 		})
 	}
@@ -0,0 +1,115 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package prp
+
+import (
+	"iam/pkg/abac/prp/expression"
+	"iam/pkg/abac/prp/policy"
+	"iam/pkg/abac/types"
+	"iam/pkg/errorx"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// ListBySubjectsAction 批量查询多个subject(例如用户组)对同一个操作的策略, 按subjectPK分组返回
+// 所有subject的策略只查询一次(DB的IN查询, 或缓存的批量读取), 表达式也只查询一次, 避免逐个subject循环查询
+// NOTE: subjectPKs即生效的subject, 不会再展开subject所属的部门/用户组; 没有策略的subject不在返回中
+func (m *policyManager) ListBySubjectsAction(
+	system string,
+	subjectPKs []int64,
+	action types.Action,
+	withoutCache bool,
+) (subjectPolicies map[int64][]types.AuthPolicy, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PRP, "ListBySubjectsAction")
+
+	subjectPolicies = map[int64][]types.AuthPolicy{}
+	if len(subjectPKs) == 0 {
+		return subjectPolicies, nil
+	}
+
+	// 1. get action pk
+	actionPK, err := action.Attribute.GetPK()
+	if err != nil {
+		err = errorWrapf(err, "action.Attribute.GetPK action=`%+v` fail", action)
+		return nil, err
+	}
+
+	// 2. get the policies of all subjects
+	var effectPolicies []svctypes.AuthPolicy
+	if withoutCache {
+		effectPolicies, err = m.policyService.ListAuthBySubjectAction(subjectPKs, actionPK)
+		if err != nil {
+			err = errorWrapf(err,
+				"policyService.ListAuthBySubjectAction system=`%s`, subjectPKs=`%+v`, actionPK=`%d` fail",
+				system, subjectPKs, actionPK)
+			return nil, err
+		}
+	} else {
+		effectPolicies, err = policy.GetPoliciesFromCache(system, actionPK, subjectPKs)
+		if err != nil {
+			err = errorWrapf(err,
+				"policy.GetPoliciesFromCache system=`%s`, actionPK=`%d`, subjectPKs=`%+v` fail",
+				system, actionPK, subjectPKs)
+			return nil, err
+		}
+	}
+	if len(effectPolicies) == 0 {
+		return subjectPolicies, nil
+	}
+
+	groupedPolicies := make(map[int64][]svctypes.AuthPolicy, len(subjectPKs))
+	for _, p := range effectPolicies {
+		groupedPolicies[p.SubjectPK] = append(groupedPolicies[p.SubjectPK], p)
+	}
+
+	// if action has not resource types, will not query expression, the same as ListBySubjectAction
+	if action.WithoutResourceType() {
+		for subjectPK, ps := range groupedPolicies {
+			subjectPolicies[subjectPK] = []types.AuthPolicy{convertToAuthPolicy(ps[0], emptyAuthExpression)}
+		}
+		return subjectPolicies, nil
+	}
+
+	// 3. query the expressions of all subjects at once
+	expressionPKs := make([]int64, 0, len(effectPolicies))
+	expressionPKSet := util.NewFixedLengthInt64Set(len(effectPolicies))
+	for _, p := range effectPolicies {
+		if !expressionPKSet.Has(p.ExpressionPK) {
+			expressionPKSet.Add(p.ExpressionPK)
+			expressionPKs = append(expressionPKs, p.ExpressionPK)
+		}
+	}
+
+	var expressions []svctypes.AuthExpression
+	if withoutCache {
+		expressions, err = m.policyService.ListExpressionByPKs(expressionPKs)
+		if err != nil {
+			err = errorWrapf(err, "policyService.ListExpressionByPKs pks=`%+v` fail", expressionPKs)
+			return nil, err
+		}
+	} else {
+		expressions, err = expression.GetExpressionsFromCache(actionPK, expressionPKs)
+		if err != nil {
+			err = errorWrapf(err, "GetExpressionsFromCache expressionPKs=`%+v` fail", expressionPKs)
+			return nil, err
+		}
+	}
+
+	expressionMap := make(map[int64]svctypes.AuthExpression, len(expressions))
+	for _, e := range expressions {
+		expressionMap[e.PK] = e
+	}
+
+	// 4. uniq the policies of each subject
+	for subjectPK, ps := range groupedPolicies {
+		subjectPolicies[subjectPK] = uniqAuthPoliciesBySignature(ps, expressionMap)
+	}
+	return subjectPolicies, nil
+}
@@ -0,0 +1,135 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package prp
+
+import (
+	"errors"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/prp/expression"
+	"iam/pkg/abac/prp/policy"
+	"iam/pkg/abac/types"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+)
+
+var _ = Describe("PolicyListSubjects", func() {
+	var ctl *gomock.Controller
+	var mockPolicyService *mock.MockPolicyService
+	var manager *policyManager
+	var action types.Action
+	BeforeEach(func() {
+		ctl = gomock.NewController(GinkgoT())
+		mockPolicyService = mock.NewMockPolicyService(ctl)
+		manager = &policyManager{
+			policyService: mockPolicyService,
+		}
+
+		action = types.Action{ID: "view", Attribute: types.NewActionAttribute()}
+		action.Attribute.SetPK(1)
+		action.Attribute.SetResourceTypes([]types.ActionResourceType{{System: "test", Type: "app"}})
+	})
+	AfterEach(func() {
+		ctl.Finish()
+	})
+
+	Describe("ListBySubjectsAction", func() {
+		var effectPolicies []svctypes.AuthPolicy
+		var expressions []svctypes.AuthExpression
+		BeforeEach(func() {
+			effectPolicies = []svctypes.AuthPolicy{
+				{PK: 1, SubjectPK: 10, ExpressionPK: 100, ExpiredAt: 4102444800},
+				{PK: 2, SubjectPK: 10, ExpressionPK: 101, ExpiredAt: 4102444800},
+				{PK: 3, SubjectPK: 20, ExpressionPK: 100, ExpiredAt: 4102444800},
+			}
+			expressions = []svctypes.AuthExpression{
+				{PK: 100, Expression: "[1]", Signature: "s1"},
+				{PK: 101, Expression: "[1]", Signature: "s1"},
+			}
+		})
+
+		It("empty subjects", func() {
+			policies, err := manager.ListBySubjectsAction("test", nil, action, true)
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), policies)
+		})
+
+		It("ListAuthBySubjectAction fail", func() {
+			mockPolicyService.EXPECT().ListAuthBySubjectAction([]int64{10, 20, 30}, int64(1)).Return(
+				nil, errors.New("list fail"))
+
+			_, err := manager.ListBySubjectsAction("test", []int64{10, 20, 30}, action, true)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "list fail")
+		})
+
+		It("ListExpressionByPKs fail", func() {
+			mockPolicyService.EXPECT().ListAuthBySubjectAction([]int64{10, 20, 30}, int64(1)).Return(
+				effectPolicies, nil)
+			mockPolicyService.EXPECT().ListExpressionByPKs([]int64{100, 101}).Return(
+				nil, errors.New("list expression fail"))
+
+			_, err := manager.ListBySubjectsAction("test", []int64{10, 20, 30}, action, true)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "list expression fail")
+		})
+
+		It("ok without cache", func() {
+			mockPolicyService.EXPECT().ListAuthBySubjectAction([]int64{10, 20, 30}, int64(1)).Return(
+				effectPolicies, nil)
+			mockPolicyService.EXPECT().ListExpressionByPKs([]int64{100, 101}).Return(expressions, nil)
+
+			policies, err := manager.ListBySubjectsAction("test", []int64{10, 20, 30}, action, true)
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), policies, 2)
+			// the same signature of subject 10, only keep the first one
+			assert.Len(GinkgoT(), policies[10], 1)
+			assert.Equal(GinkgoT(), int64(1), policies[10][0].ID)
+			assert.Equal(GinkgoT(), "[1]", policies[10][0].Expression)
+			assert.Len(GinkgoT(), policies[20], 1)
+			assert.Equal(GinkgoT(), int64(3), policies[20][0].ID)
+			// no policies
+			_, ok := policies[30]
+			assert.False(GinkgoT(), ok)
+		})
+
+		It("ok with cache", func() {
+			patches := gomonkey.ApplyFunc(policy.GetPoliciesFromCache,
+				func(system string, actionPK int64, subjectPKs []int64) ([]svctypes.AuthPolicy, error) {
+					return effectPolicies, nil
+				})
+			patches.ApplyFunc(expression.GetExpressionsFromCache,
+				func(actionPK int64, expressionPKs []int64) ([]svctypes.AuthExpression, error) {
+					return expressions, nil
+				})
+			defer patches.Reset()
+
+			policies, err := manager.ListBySubjectsAction("test", []int64{10, 20, 30}, action, false)
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), policies, 2)
+		})
+
+		It("action without resource types", func() {
+			action.Attribute.SetResourceTypes([]types.ActionResourceType{})
+			mockPolicyService.EXPECT().ListAuthBySubjectAction([]int64{10, 20}, int64(1)).Return(
+				effectPolicies, nil)
+
+			policies, err := manager.ListBySubjectsAction("test", []int64{10, 20}, action, true)
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), policies, 2)
+			assert.Len(GinkgoT(), policies[10], 1)
+			assert.Equal(GinkgoT(), "", policies[10][0].Expression)
+		})
+	})
+})
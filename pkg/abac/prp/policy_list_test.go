@@ -8,10 +8,18 @@
  * specific language governing permissions and limitations under the License.
  */
 
-package prp_test
+package prp
 
 import (
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/types"
+	"iam/pkg/cache/impls"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
 )
 
 var _ = Describe("PolicyList", func() {
@@ -20,7 +28,62 @@ var _ = Describe("PolicyList", func() {
 
 	})
 	Describe("ListBySubjectAction", func() {
+		var ctl *gomock.Controller
+		var patches *gomonkey.Patches
+		var subject types.Subject
+		var action types.Action
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			patches = gomonkey.ApplyFunc(impls.ListSubjectSystemGroups,
+				func(system string, pks []int64) (map[int64][]svctypes.ThinSubjectGroup, error) {
+					return map[int64][]svctypes.ThinSubjectGroup{}, nil
+				})
+
+			subject = types.NewSubject()
+			subject.Type = svctypes.GroupType
+			subject.FillAttributes(123, []types.SubjectGroup{}, []int64{})
+			action = types.NewAction()
+			action.FillAttributes(1, []types.ActionResourceType{})
+		})
+		AfterEach(func() {
+			ctl.Finish()
+			patches.Reset()
+		})
+
+		It("without resource type, return the deny and the allow policy with highest priority", func() {
+			mockPolicyService := mock.NewMockPolicyService(ctl)
+			mockPolicyService.EXPECT().ListAuthBySubjectAction([]int64{123}, int64(1)).Return([]svctypes.AuthPolicy{
+				{PK: 1, SubjectPK: 123, ExpressionPK: -1},
+				{PK: 2, SubjectPK: 123, ExpressionPK: -1, Effect: svctypes.PolicyEffectDeny},
+				{PK: 3, SubjectPK: 123, ExpressionPK: -1, Priority: 5},
+			}, nil)
+			manager := &policyManager{policyService: mockPolicyService}
+
+			policies, err := manager.ListBySubjectAction("test", subject, action, true, nil)
+			assert.NoError(GinkgoT(), err)
+			if assert.Len(GinkgoT(), policies, 2) {
+				assert.Equal(GinkgoT(), int64(2), policies[0].ID)
+				assert.True(GinkgoT(), policies[0].IsDeny())
+				assert.Equal(GinkgoT(), int64(3), policies[1].ID)
+				assert.Equal(GinkgoT(), int64(5), policies[1].Priority)
+			}
+		})
+
+		It("without resource type, allow policies only", func() {
+			mockPolicyService := mock.NewMockPolicyService(ctl)
+			mockPolicyService.EXPECT().ListAuthBySubjectAction([]int64{123}, int64(1)).Return([]svctypes.AuthPolicy{
+				{PK: 1, SubjectPK: 123, ExpressionPK: -1},
+				{PK: 3, SubjectPK: 123, ExpressionPK: -1},
+			}, nil)
+			manager := &policyManager{policyService: mockPolicyService}
 
+			policies, err := manager.ListBySubjectAction("test", subject, action, true, nil)
+			assert.NoError(GinkgoT(), err)
+			if assert.Len(GinkgoT(), policies, 1) {
+				assert.Equal(GinkgoT(), int64(1), policies[0].ID)
+				assert.False(GinkgoT(), policies[0].IsDeny())
+			}
+		})
 	})
 	Describe("getPoliciesFromCache", func() {
 
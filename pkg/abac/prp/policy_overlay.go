@@ -0,0 +1,118 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package prp
+
+import (
+	"time"
+
+	"iam/pkg/abac/types"
+	"iam/pkg/errorx"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// PolicyOverlay 策略变更的内存叠加层, 查询策略时剔除被删除的策略并追加新建的策略, 不会写DB也不会读写策略缓存
+// 用于模拟策略变更后的鉴权结果
+type PolicyOverlay struct {
+	subjectPK int64
+	// actionPK => policies
+	createPolicies  map[int64][]types.AuthPolicy
+	deletePolicyIDs *util.Int64Set
+}
+
+// NewPolicyOverlay 根据自定义策略的变更(同AlterCustomPolicies)生成叠加层
+// NOTE: 新建的策略没有ID, 使用负数ID区分
+func (m *policyManager) NewPolicyOverlay(
+	systemID, subjectType, subjectID string,
+	createPolicies []types.Policy,
+	deletePolicyIDs []int64,
+) (*PolicyOverlay, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PRP, "NewPolicyOverlay")
+
+	subjectPK, actionPKMap, actionPKWithResourceTypeSet, err := m.querySubjectActionForAlterPolicies(
+		systemID, subjectType, subjectID)
+	if err != nil {
+		return nil, errorWrapf(err, "m.querySubjectActionForAlterPolicies systemID=`%s` fail", systemID)
+	}
+
+	cps, err := convertToServicePolicies(subjectPK, createPolicies, actionPKMap)
+	if err != nil {
+		return nil, errorWrapf(err, "convertServicePolicies subjectPK=`%d`, policies=`%+v`, actionMap=`%+v` fail",
+			subjectPK, createPolicies, actionPKMap)
+	}
+
+	overlay := &PolicyOverlay{
+		subjectPK:       subjectPK,
+		createPolicies:  make(map[int64][]types.AuthPolicy, len(cps)),
+		deletePolicyIDs: util.NewInt64SetWithValues(deletePolicyIDs),
+	}
+	for i, p := range cps {
+		// 同AlterCustomPolicies, 不关联资源类型的操作, 表达式为空
+		expression := emptyAuthExpression
+		if actionPKWithResourceTypeSet.Has(p.ActionPK) {
+			expression = svctypes.AuthExpression{
+				Expression: p.Expression,
+				Signature:  util.GetMD5Hash(p.Expression),
+			}
+		}
+
+		overlay.createPolicies[p.ActionPK] = append(overlay.createPolicies[p.ActionPK], convertToAuthPolicy(
+			svctypes.AuthPolicy{
+				PK:        -int64(i + 1),
+				SubjectPK: subjectPK,
+				ExpiredAt: p.ExpiredAt,
+				Effect:    p.Effect,
+				Priority:  p.Priority,
+			}, expression))
+	}
+	return overlay, nil
+}
+
+// affect 叠加层的subject是否在鉴权subject的有效subject中(本身/所属的用户组/部门)
+func (o *PolicyOverlay) affect(effectSubjectPKs []int64) bool {
+	if o == nil {
+		return false
+	}
+
+	for _, pk := range effectSubjectPKs {
+		if pk == o.subjectPK {
+			return true
+		}
+	}
+	return false
+}
+
+// filterDeleted 剔除被删除的策略
+func (o *PolicyOverlay) filterDeleted(policies []svctypes.AuthPolicy) []svctypes.AuthPolicy {
+	if o.deletePolicyIDs.Size() == 0 {
+		return policies
+	}
+
+	filtered := make([]svctypes.AuthPolicy, 0, len(policies))
+	for _, p := range policies {
+		if !o.deletePolicyIDs.Has(p.PK) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// listCreated 返回操作下新建的未过期策略
+func (o *PolicyOverlay) listCreated(actionPK int64) []types.AuthPolicy {
+	now := time.Now().Unix()
+
+	policies := make([]types.AuthPolicy, 0, len(o.createPolicies[actionPK]))
+	for _, p := range o.createPolicies[actionPK] {
+		if p.ExpiredAt > now {
+			policies = append(policies, p)
+		}
+	}
+	return policies
+}
@@ -0,0 +1,168 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package prp
+
+import (
+	"errors"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/types"
+	"iam/pkg/cache/impls"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+var _ = Describe("PolicyOverlay", func() {
+	var ctl *gomock.Controller
+	var mockSubjectService *mock.MockSubjectBaseReadService
+	var mockPolicyService *mock.MockPolicyService
+	var manager *policyManager
+	BeforeEach(func() {
+		ctl = gomock.NewController(GinkgoT())
+		mockSubjectService = mock.NewMockSubjectBaseReadService(ctl)
+		mockPolicyService = mock.NewMockPolicyService(ctl)
+		manager = &policyManager{
+			subjectService: mockSubjectService,
+			policyService:  mockPolicyService,
+		}
+	})
+	AfterEach(func() {
+		ctl.Finish()
+	})
+
+	newPolicy := func(actionID, expression string, expiredAt int64) types.Policy {
+		return types.Policy{
+			Action:     types.Action{ID: actionID},
+			Expression: expression,
+			Effect:     svctypes.PolicyEffectDeny,
+			ExpiredAt:  expiredAt,
+		}
+	}
+
+	Describe("NewPolicyOverlay", func() {
+		var patches *gomonkey.Patches
+		BeforeEach(func() {
+			mockSubjectService.EXPECT().GetPK("user", "tom").Return(int64(10), nil).AnyTimes()
+			patches = mockGetSystemActionIndex(impls.SystemActionIndex{
+				ActionPKMap:                 map[string]int64{"view": 1, "create": 2},
+				ActionPKWithResourceTypeSet: util.NewInt64SetWithValues([]int64{1}),
+			}, nil)
+		})
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("action not exists", func() {
+			_, err := manager.NewPolicyOverlay("test", "user", "tom",
+				[]types.Policy{newPolicy("edit", "[]", 4102444800)}, nil)
+			assert.Error(GinkgoT(), err)
+			assert.ErrorIs(GinkgoT(), err, ErrActionNotExists)
+		})
+
+		It("ok", func() {
+			overlay, err := manager.NewPolicyOverlay("test", "user", "tom", []types.Policy{
+				newPolicy("view", `[{"system":"test"}]`, 4102444800),
+				newPolicy("view", `[]`, 1),
+				newPolicy("create", `[{"system":"test"}]`, 4102444800),
+			}, []int64{100})
+			assert.NoError(GinkgoT(), err)
+
+			assert.True(GinkgoT(), overlay.affect([]int64{1, 10}))
+			assert.False(GinkgoT(), overlay.affect([]int64{1, 2}))
+
+			created := overlay.listCreated(1)
+			assert.Len(GinkgoT(), created, 1)
+			assert.Equal(GinkgoT(), int64(-1), created[0].ID)
+			assert.Equal(GinkgoT(), `[{"system":"test"}]`, created[0].Expression)
+			assert.True(GinkgoT(), created[0].IsDeny())
+
+			// the action without resource types, the expression is empty
+			created = overlay.listCreated(2)
+			assert.Len(GinkgoT(), created, 1)
+			assert.Equal(GinkgoT(), "", created[0].Expression)
+
+			assert.Equal(GinkgoT(), []svctypes.AuthPolicy{{PK: 101}}, overlay.filterDeleted(
+				[]svctypes.AuthPolicy{{PK: 100}, {PK: 101}}))
+		})
+	})
+
+	Describe("ListBySubjectActionWithOverlay", func() {
+		var patches *gomonkey.Patches
+		var action types.Action
+		BeforeEach(func() {
+			patches = gomonkey.ApplyFunc(getEffectSubjectPKs,
+				func(system string, subject types.Subject) ([]int64, error) {
+					return []int64{10, 20}, nil
+				})
+
+			action = types.Action{ID: "view", Attribute: types.NewActionAttribute()}
+			action.Attribute.SetPK(1)
+			action.Attribute.SetResourceTypes([]types.ActionResourceType{{System: "test", Type: "app"}})
+		})
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("ListAuthBySubjectAction fail", func() {
+			mockPolicyService.EXPECT().ListAuthBySubjectAction([]int64{10, 20}, int64(1)).Return(
+				nil, errors.New("list fail"))
+
+			_, err := manager.ListBySubjectActionWithOverlay("test", types.Subject{}, action, nil, nil)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "list fail")
+		})
+
+		It("ok", func() {
+			overlay := &PolicyOverlay{
+				subjectPK: 20,
+				createPolicies: map[int64][]types.AuthPolicy{
+					1: {{ID: -1, Expression: "created", ExpiredAt: time.Now().Unix() + 100}},
+				},
+				deletePolicyIDs: util.NewInt64SetWithValues([]int64{100}),
+			}
+
+			mockPolicyService.EXPECT().ListAuthBySubjectAction([]int64{10, 20}, int64(1)).Return(
+				[]svctypes.AuthPolicy{
+					{PK: 100, SubjectPK: 20, ExpressionPK: 1000},
+					{PK: 101, SubjectPK: 10, ExpressionPK: 1001},
+				}, nil)
+			mockPolicyService.EXPECT().ListExpressionByPKs([]int64{1001}).Return(
+				[]svctypes.AuthExpression{{PK: 1001, Expression: "exist", Signature: "s1"}}, nil)
+
+			policies, err := manager.ListBySubjectActionWithOverlay("test", types.Subject{}, action, overlay, nil)
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), policies, 2)
+			assert.Equal(GinkgoT(), int64(101), policies[0].ID)
+			assert.Equal(GinkgoT(), "exist", policies[0].Expression)
+			assert.Equal(GinkgoT(), int64(-1), policies[1].ID)
+		})
+
+		It("ok, all deleted", func() {
+			overlay := &PolicyOverlay{
+				subjectPK:       20,
+				createPolicies:  map[int64][]types.AuthPolicy{},
+				deletePolicyIDs: util.NewInt64SetWithValues([]int64{100}),
+			}
+
+			mockPolicyService.EXPECT().ListAuthBySubjectAction([]int64{10, 20}, int64(1)).Return(
+				[]svctypes.AuthPolicy{{PK: 100, SubjectPK: 20, ExpressionPK: 1000}}, nil)
+
+			policies, err := manager.ListBySubjectActionWithOverlay("test", types.Subject{}, action, overlay, nil)
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), policies, 0)
+		})
+	})
+})
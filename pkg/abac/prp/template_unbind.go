@@ -0,0 +1,314 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package prp
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	rediscache "github.com/go-redis/cache/v8"
+	"github.com/gofrs/uuid"
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/abac/types"
+	"iam/pkg/cache"
+	"iam/pkg/cache/impls"
+	"iam/pkg/cache/invalidation"
+	"iam/pkg/errorx"
+)
+
+// ErrTemplateUnbindTaskNotFound 任务不存在或已过期
+var ErrTemplateUnbindTaskNotFound = errors.New("template unbind task not found")
+
+var (
+	// 分批删除, 每批的数量以及间隔, 避免一次删除大量数据锁表影响鉴权
+	templateUnbindChunkSize     int64 = 1000
+	templateUnbindChunkInterval       = 100 * time.Millisecond
+
+	// 执行任务的实例持有租约, 每批删除后续期并更新心跳; 实例退出后租约过期, 任务由其他实例接管
+	templateUnbindLease = 1 * time.Minute
+	// 任务进度在redis中保留24小时, 超过该时间仍未完成的任务不再接管, 标记为失败
+	templateUnbindMaxDuration = 12 * time.Hour
+	// 检查心跳超时任务的间隔
+	templateUnbindRecoverInterval = 1 * time.Minute
+	// 同时执行的任务数量
+	templateUnbindWorkerCount = 4
+)
+
+// templateUnbindRunningKey 执行中任务的索引(hash), field为taskID, value为subjectPK, 用于接管心跳超时的任务
+const templateUnbindRunningKey = "running"
+
+type templateUnbindJob struct {
+	taskID    string
+	subjectPK int64
+}
+
+// templateUnbindJobs 待执行的任务, 队列满时任务在心跳超时后由 recoverTemplateUnbindTasks 重新入队
+var templateUnbindJobs = make(chan templateUnbindJob, 1000)
+
+// AsyncDeleteTemplatePolicies 异步解绑模板: 创建任务后立即返回, 由 RunTemplateUnbindWorker 分批删除模板生成的策略
+func (m *policyManager) AsyncDeleteTemplatePolicies(
+	systemID, subjectType, subjectID string, templateID int64,
+) (task types.TemplateUnbindTask, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PRP, "AsyncDeleteTemplatePolicies")
+
+	// 1. 查询 subject subjectPK
+	subjectPK, err := m.subjectService.GetPK(subjectType, subjectID)
+	if err != nil {
+		err = errorWrapf(err, "subjectService.GetPK subjectType=`%s`, subjectID=`%s` fail",
+			subjectType, subjectID)
+		return
+	}
+
+	// 2. 统计需要删除的策略数量, 用于进度展示
+	total, err := m.policyService.GetTemplatePolicyCount(subjectPK, templateID)
+	if err != nil {
+		err = errorWrapf(err, "policyService.GetTemplatePolicyCount subjectPK=`%d`, templateID=`%d` fail",
+			subjectPK, templateID)
+		return
+	}
+
+	now := time.Now().Unix()
+	task = types.TemplateUnbindTask{
+		ID:          hex.EncodeToString(uuid.Must(uuid.NewV4()).Bytes()),
+		SystemID:    systemID,
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		TemplateID:  templateID,
+		Status:      types.TemplateUnbindTaskStatusRunning,
+		Total:       total,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		HeartbeatAt: now,
+	}
+	err = saveTemplateUnbindTask(task)
+	if err != nil {
+		err = errorWrapf(err, "saveTemplateUnbindTask task=`%+v` fail", task)
+		return
+	}
+
+	// 3. 记录到执行中任务的索引, 再入队执行
+	err = impls.TemplateUnbindTaskCache.HSet(templateUnbindRunningKey, task.ID, strconv.FormatInt(subjectPK, 10))
+	if err != nil {
+		err = errorWrapf(err, "TemplateUnbindTaskCache.HSet taskID=`%s` fail", task.ID)
+		return
+	}
+	enqueueTemplateUnbindJob(templateUnbindJob{taskID: task.ID, subjectPK: subjectPK})
+
+	return task, nil
+}
+
+func enqueueTemplateUnbindJob(job templateUnbindJob) {
+	select {
+	case templateUnbindJobs <- job:
+	default:
+		log.Warnf("template unbind job queue is full, task `%s` will be recovered after heartbeat timeout", job.taskID)
+	}
+}
+
+// RunTemplateUnbindWorker 执行异步解绑模板任务, 阻塞直到ctx结束
+// 启动时以及之后每隔一段时间, 接管心跳超时(例如执行的实例已退出)的任务
+func RunTemplateUnbindWorker(ctx context.Context) {
+	// NOTE: 用户组的策略删除完成后需要同步用户组有策略的系统, 所以使用完整初始化的manager
+	m := NewPolicyManager().(*policyManager)
+
+	for i := 0; i < templateUnbindWorkerCount; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-templateUnbindJobs:
+					m.runTemplateUnbindJob(ctx, job)
+				}
+			}
+		}()
+	}
+
+	recoverTemplateUnbindTasks()
+
+	ticker := time.NewTicker(templateUnbindRecoverInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			recoverTemplateUnbindTasks()
+		}
+	}
+}
+
+// recoverTemplateUnbindTasks 心跳超时的任务重新入队, 超过最长执行时间的任务标记为失败
+func recoverTemplateUnbindTasks() {
+	running, err := impls.TemplateUnbindTaskCache.HGetAll(templateUnbindRunningKey)
+	if err != nil {
+		log.WithError(err).Error("recoverTemplateUnbindTasks TemplateUnbindTaskCache.HGetAll fail")
+		return
+	}
+
+	now := time.Now()
+	for taskID, value := range running {
+		task, err := GetTemplateUnbindTask(taskID)
+		if errors.Is(err, ErrTemplateUnbindTaskNotFound) || (err == nil && !isTemplateUnbindTaskRunning(task)) {
+			removeRunningTemplateUnbindTask(taskID)
+			continue
+		}
+		if err != nil {
+			log.WithError(err).Errorf("recoverTemplateUnbindTasks GetTemplateUnbindTask taskID=`%s` fail", taskID)
+			continue
+		}
+
+		// 心跳未超时, 任务执行中或在队列中等待执行
+		if now.Sub(time.Unix(task.HeartbeatAt, 0)) < templateUnbindLease {
+			continue
+		}
+
+		subjectPK, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || now.Sub(time.Unix(task.CreatedAt, 0)) > templateUnbindMaxDuration {
+			failStaleTemplateUnbindTask(task)
+			continue
+		}
+
+		log.Infof("recover template unbind task `%s`, heartbeat_at=`%d`", taskID, task.HeartbeatAt)
+		enqueueTemplateUnbindJob(templateUnbindJob{taskID: taskID, subjectPK: subjectPK})
+	}
+}
+
+func failStaleTemplateUnbindTask(task types.TemplateUnbindTask) {
+	lease := templateUnbindLeaseName(task.ID)
+	locked, err := impls.AcquireTaskLock(lease, templateUnbindLease)
+	if err != nil || !locked {
+		return
+	}
+	defer impls.ReleaseTaskLock(lease)
+
+	task.Status = types.TemplateUnbindTaskStatusFailed
+	task.Error = "task heartbeat timeout"
+	task.UpdatedAt = time.Now().Unix()
+	finishTemplateUnbindTask(task)
+}
+
+// runTemplateUnbindJob 获取任务的租约后执行, 同一个任务同时只在一个实例中执行
+func (m *policyManager) runTemplateUnbindJob(ctx context.Context, job templateUnbindJob) {
+	lease := templateUnbindLeaseName(job.taskID)
+	locked, err := impls.AcquireTaskLock(lease, templateUnbindLease)
+	if err != nil {
+		log.WithError(err).Errorf("runTemplateUnbindJob acquire the lease of task `%s` fail", job.taskID)
+		return
+	}
+	if !locked {
+		return
+	}
+	defer impls.ReleaseTaskLock(lease)
+
+	// NOTE: 获取租约后重新查询进度, 任务可能已被其他实例执行完
+	task, err := GetTemplateUnbindTask(job.taskID)
+	if err != nil {
+		log.WithError(err).Errorf("runTemplateUnbindJob GetTemplateUnbindTask taskID=`%s` fail", job.taskID)
+		return
+	}
+	if !isTemplateUnbindTaskRunning(task) {
+		return
+	}
+
+	m.runTemplateUnbindTask(ctx, task, job.subjectPK)
+}
+
+func (m *policyManager) runTemplateUnbindTask(ctx context.Context, task types.TemplateUnbindTask, subjectPK int64) {
+	lease := templateUnbindLeaseName(task.ID)
+
+	// NOTE: ctx结束时直接返回, 任务保持执行中, 心跳超时后由其他实例接管
+	for ctx.Err() == nil {
+		deleted, err := m.policyService.DeleteTemplatePoliciesWithLimit(
+			subjectPK, task.TemplateID, templateUnbindChunkSize)
+
+		// NOTE: delete the policy cache after each chunk
+		invalidation.DeleteSystemSubjectPolicies(task.SystemID, []int64{subjectPK})
+
+		task.UpdatedAt = time.Now().Unix()
+		task.HeartbeatAt = task.UpdatedAt
+		if err != nil {
+			log.WithError(err).Errorf("runTemplateUnbindTask fail, task=`%+v`", task)
+
+			task.Status = types.TemplateUnbindTaskStatusFailed
+			task.Error = err.Error()
+			finishTemplateUnbindTask(task)
+			return
+		}
+
+		task.Deleted += deleted
+		if deleted < templateUnbindChunkSize {
+			m.syncGroupSystems(task.SubjectType, subjectPK)
+
+			task.Status = types.TemplateUnbindTaskStatusFinished
+			// the policies may be created or deleted by others after counting
+			if task.Deleted > task.Total {
+				task.Total = task.Deleted
+			}
+			finishTemplateUnbindTask(task)
+			return
+		}
+
+		if err = saveTemplateUnbindTask(task); err != nil {
+			log.WithError(err).Errorf("runTemplateUnbindTask saveTemplateUnbindTask fail, task=`%+v`", task)
+		}
+		if err = impls.RenewTaskLock(lease, templateUnbindLease); err != nil {
+			log.WithError(err).Errorf("runTemplateUnbindTask renew the lease of task `%s` fail", task.ID)
+		}
+
+		time.Sleep(templateUnbindChunkInterval)
+	}
+}
+
+// finishTemplateUnbindTask 保存任务的最终状态, 并从执行中任务的索引中移除
+func finishTemplateUnbindTask(task types.TemplateUnbindTask) {
+	if err := saveTemplateUnbindTask(task); err != nil {
+		log.WithError(err).Errorf("finishTemplateUnbindTask saveTemplateUnbindTask fail, task=`%+v`", task)
+		return
+	}
+	removeRunningTemplateUnbindTask(task.ID)
+}
+
+func removeRunningTemplateUnbindTask(taskID string) {
+	if err := impls.TemplateUnbindTaskCache.HDel(templateUnbindRunningKey, taskID); err != nil {
+		log.WithError(err).Errorf("TemplateUnbindTaskCache.HDel taskID=`%s` fail", taskID)
+	}
+}
+
+func isTemplateUnbindTaskRunning(task types.TemplateUnbindTask) bool {
+	return task.Status == types.TemplateUnbindTaskStatusRunning
+}
+
+func templateUnbindLeaseName(taskID string) string {
+	return "template_unbind:" + taskID
+}
+
+func saveTemplateUnbindTask(task types.TemplateUnbindTask) error {
+	return impls.TemplateUnbindTaskCache.Set(cache.NewStringKey(task.ID), task, 0)
+}
+
+// GetTemplateUnbindTask 查询异步解绑模板任务的进度
+func GetTemplateUnbindTask(taskID string) (task types.TemplateUnbindTask, err error) {
+	err = impls.TemplateUnbindTaskCache.Get(cache.NewStringKey(taskID), &task)
+	if errors.Is(err, rediscache.ErrCacheMiss) {
+		err = ErrTemplateUnbindTaskNotFound
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, PRP, "GetTemplateUnbindTask",
+			"TemplateUnbindTaskCache.Get taskID=`%s` fail", taskID)
+	}
+	return
+}
@@ -0,0 +1,196 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package prp
+
+import (
+	"context"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/types"
+	"iam/pkg/cache/impls"
+	"iam/pkg/cache/invalidation"
+	"iam/pkg/cache/redis"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+)
+
+var _ = Describe("TemplateUnbind", func() {
+	var ctl *gomock.Controller
+	var mockPolicyService *mock.MockPolicyService
+	var manager *policyManager
+	var patches *gomonkey.Patches
+
+	newTask := func(id string, heartbeatAt, createdAt int64) types.TemplateUnbindTask {
+		task := types.TemplateUnbindTask{
+			ID:          id,
+			SystemID:    "bk_test",
+			TemplateID:  1,
+			Status:      types.TemplateUnbindTaskStatusRunning,
+			Total:       5,
+			CreatedAt:   createdAt,
+			UpdatedAt:   heartbeatAt,
+			HeartbeatAt: heartbeatAt,
+		}
+		assert.NoError(GinkgoT(), saveTemplateUnbindTask(task))
+		assert.NoError(GinkgoT(), impls.TemplateUnbindTaskCache.HSet(templateUnbindRunningKey, id, "10"))
+		return task
+	}
+
+	isRunningIndexed := func(id string) bool {
+		ok, err := impls.TemplateUnbindTaskCache.HExists(templateUnbindRunningKey, id)
+		assert.NoError(GinkgoT(), err)
+		return ok
+	}
+
+	drainJobs := func() (jobs []templateUnbindJob) {
+		for {
+			select {
+			case job := <-templateUnbindJobs:
+				jobs = append(jobs, job)
+			default:
+				return
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		ctl = gomock.NewController(GinkgoT())
+		mockPolicyService = mock.NewMockPolicyService(ctl)
+		manager = &policyManager{
+			policyService: mockPolicyService,
+		}
+
+		impls.TemplateUnbindTaskCache = redis.NewMockCache("tpl_ubd", 24*time.Hour)
+		impls.TaskLockCache = redis.NewMockCache("tsk_lck", time.Hour)
+		patches = gomonkey.ApplyFunc(invalidation.DeleteSystemSubjectPolicies,
+			func(system string, subjectPKs []int64) error {
+				return nil
+			})
+	})
+	AfterEach(func() {
+		ctl.Finish()
+		patches.Reset()
+		drainJobs()
+	})
+
+	Describe("runTemplateUnbindJob", func() {
+		It("ok, finished", func() {
+			now := time.Now().Unix()
+			newTask("t1", now, now)
+			mockPolicyService.EXPECT().DeleteTemplatePoliciesWithLimit(int64(10), int64(1), templateUnbindChunkSize).
+				Return(int64(5), nil)
+
+			manager.runTemplateUnbindJob(context.Background(), templateUnbindJob{taskID: "t1", subjectPK: 10})
+
+			task, err := GetTemplateUnbindTask("t1")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), types.TemplateUnbindTaskStatusFinished, task.Status)
+			assert.Equal(GinkgoT(), int64(5), task.Deleted)
+			assert.False(GinkgoT(), isRunningIndexed("t1"))
+
+			// the lease is released
+			locked, err := impls.AcquireTaskLock(templateUnbindLeaseName("t1"), time.Minute)
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), locked)
+		})
+
+		It("skip, the lease is held by other instance", func() {
+			now := time.Now().Unix()
+			newTask("t1", now, now)
+			locked, err := impls.AcquireTaskLock(templateUnbindLeaseName("t1"), time.Minute)
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), locked)
+
+			manager.runTemplateUnbindJob(context.Background(), templateUnbindJob{taskID: "t1", subjectPK: 10})
+
+			task, err := GetTemplateUnbindTask("t1")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), types.TemplateUnbindTaskStatusRunning, task.Status)
+			assert.True(GinkgoT(), isRunningIndexed("t1"))
+		})
+
+		It("skip, the task is finished by other instance", func() {
+			now := time.Now().Unix()
+			task := newTask("t1", now, now)
+			task.Status = types.TemplateUnbindTaskStatusFinished
+			assert.NoError(GinkgoT(), saveTemplateUnbindTask(task))
+
+			manager.runTemplateUnbindJob(context.Background(), templateUnbindJob{taskID: "t1", subjectPK: 10})
+		})
+	})
+
+	Describe("RunTemplateUnbindWorker", func() {
+		It("ok, sync the systems of group", func() {
+			now := time.Now().Unix()
+			task := newTask("t1", now, now)
+			task.SubjectType = "group"
+			assert.NoError(GinkgoT(), saveTemplateUnbindTask(task))
+
+			mockPolicyService.EXPECT().DeleteTemplatePoliciesWithLimit(int64(10), int64(1), templateUnbindChunkSize).
+				Return(int64(5), nil)
+			mockSubjectSystemGroupService := mock.NewMockSubjectSystemGroupService(ctl)
+			mockSubjectSystemGroupService.EXPECT().SyncGroupSystems(int64(10)).Return(nil)
+
+			patches.ApplyFunc(service.NewPolicyService, func() service.PolicyService {
+				return mockPolicyService
+			})
+			patches.ApplyFunc(service.NewSubjectSystemGroupService, func() service.SubjectSystemGroupService {
+				return mockSubjectSystemGroupService
+			})
+			patches.ApplyFunc(service.NewSubjectBaseReadService, func() service.SubjectBaseReadService {
+				return mock.NewMockSubjectBaseReadService(ctl)
+			})
+			patches.ApplyFunc(service.NewActionService, func() service.ActionService {
+				return mock.NewMockActionService(ctl)
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go RunTemplateUnbindWorker(ctx)
+			enqueueTemplateUnbindJob(templateUnbindJob{taskID: "t1", subjectPK: 10})
+
+			assert.Eventually(GinkgoT(), func() bool {
+				task, err := GetTemplateUnbindTask("t1")
+				return err == nil && task.Status == types.TemplateUnbindTaskStatusFinished
+			}, time.Second, 10*time.Millisecond)
+		})
+	})
+
+	Describe("recoverTemplateUnbindTasks", func() {
+		It("ok", func() {
+			now := time.Now()
+			stale := now.Add(-2 * templateUnbindLease).Unix()
+
+			newTask("alive", now.Unix(), now.Unix())
+			newTask("stale", stale, stale)
+			newTask("timeout", stale, now.Add(-2*templateUnbindMaxDuration).Unix())
+			// the task expired
+			assert.NoError(GinkgoT(), impls.TemplateUnbindTaskCache.HSet(templateUnbindRunningKey, "expired", "10"))
+
+			recoverTemplateUnbindTasks()
+
+			assert.Equal(GinkgoT(), []templateUnbindJob{{taskID: "stale", subjectPK: 10}}, drainJobs())
+
+			assert.True(GinkgoT(), isRunningIndexed("alive"))
+			assert.True(GinkgoT(), isRunningIndexed("stale"))
+			assert.False(GinkgoT(), isRunningIndexed("timeout"))
+			assert.False(GinkgoT(), isRunningIndexed("expired"))
+
+			task, err := GetTemplateUnbindTask("timeout")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), types.TemplateUnbindTaskStatusFailed, task.Status)
+		})
+	})
+})
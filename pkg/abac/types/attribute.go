@@ -181,3 +181,22 @@ func (a *SubjectAttribute) GetDepartments() ([]int64, error) {
 func (a *SubjectAttribute) SetDepartments(department []int64) {
 	a.Set(DeptAttrName, department)
 }
+
+// SetExtAttributes 设置外部属性提供方提供的属性
+func (a *SubjectAttribute) SetExtAttributes(attrs map[string]interface{}) {
+	a.Set(ExtAttrName, attrs)
+}
+
+// GetExtAttribute 获取外部属性提供方提供的属性, 未提供时返回false
+func (a *SubjectAttribute) GetExtAttribute(name string) (interface{}, bool) {
+	attrs, ok := a.Get(ExtAttrName)
+	if !ok {
+		return nil, false
+	}
+	extAttrs, ok := attrs.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	value, ok := extAttrs[name]
+	return value, ok
+}
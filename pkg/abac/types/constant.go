@@ -17,4 +17,7 @@ const (
 	PKAttrName    = "pk"
 	GroupAttrName = "group"
 	DeptAttrName  = "department"
+
+	// ExtAttrName 外部属性提供方提供的subject属性
+	ExtAttrName = "ext"
 )
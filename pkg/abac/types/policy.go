@@ -10,6 +10,10 @@
 
 package types
 
+import (
+	svctypes "iam/pkg/service/types"
+)
+
 // 参考来源：https://docs.aws.amazon.com/en_pv/IAM/latest/UserGuide/reference_policies_elements_condition_operators.html
 /*
 const (
@@ -17,21 +21,34 @@ const (
 	StringEquals  = "StringEquals"
 	StringPrefix  = "StringPrefix"
 	NumericEquals = "NumericEquals"
+	NumericGt     = "NumericGt"
+	NumericGte    = "NumericGte"
+	NumericLt     = "NumericLt"
+	NumericLte    = "NumericLte"
 	Bool          = "Bool"
 	Any           = "Any"
+	// 逻辑操作
+	AND = "AND"
+	OR  = "OR"
+	NOT = "NOT"
+	// 字符串模式匹配
+	StringWildcard = "StringWildcard"
+	StringRegex    = "StringRegex"
+	// 环境属性(env.*)相关的操作
+	TimeWindow = "TimeWindow"
+	HourRange  = "HourRange"
+	WeekdayIn  = "WeekdayIn"
+	IPInCIDR   = "IPInCIDR"
+	// 属性值转换
+	StringEqualsIgnoreCase = "StringEqualsIgnoreCase"
 	// 暂未支持的操作
 	// 字符串
 	StringNotEquals           = "StringNotEquals"
-	StringEqualsIgnoreCase    = "StringEqualsIgnoreCase"
 	StringNotEqualsIgnoreCase = "StringNotEqualsIgnoreCase"
 	StringLike                = "StringLike"
 	StringNotLike             = "StringNotLike"
 	// 数字
 	NumericNotEquals         = "NumericNotEquals"
-	NumericLessThan          = "NumericLessThan"
-	NumericLessThanEquals    = "NumericLessThanEquals"
-	NumericGreaterThan       = "NumericGreaterThan"
-	NumericGreaterThanEquals = "NumericGreaterThanEquals"
 	// 时间
 	DateEquals = "DateEquals"
 	DateNotEquals = "DateNotEquals"
@@ -59,6 +76,8 @@ type Policy struct {
 	Action  Action
 	// PRP 暂时不解析ResourceExpression里的
 	Expression string
+	Effect     string
+	Priority   int64
 	ExpiredAt  int64
 	TemplateID int64
 }
@@ -70,6 +89,8 @@ type SaaSPolicy struct {
 
 	System    string `json:"system"`
 	ActionID  string `json:"action_id"`
+	Effect    string `json:"effect"`
+	Priority  int64  `json:"priority"`
 	ExpiredAt int64  `json:"expired_at"`
 }
 
@@ -78,9 +99,19 @@ type AuthPolicy struct {
 	Version string
 	ID      int64
 
+	// ExpressionPK 表达式的主键, 用于编译后的条件缓存; 非DB来源的策略为0
+	ExpressionPK        int64
 	Expression          string
 	ExpressionSignature string
 	ExpiredAt           int64
+	Effect              string
+	// Priority 优先级, 仅在first_match求值模式下生效, 值越大越先求值
+	Priority int64
+}
+
+// IsDeny 是否是deny策略, 空值视为allow(兼容历史数据与缓存)
+func (p AuthPolicy) IsDeny() bool {
+	return p.Effect == svctypes.PolicyEffectDeny
 }
 
 // PolicyPKExpiredAt ...
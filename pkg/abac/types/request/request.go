@@ -11,6 +11,9 @@
 package request
 
 import (
+	"context"
+	"time"
+
 	"iam/pkg/abac/types"
 	"iam/pkg/errorx"
 	"iam/pkg/util"
@@ -26,6 +29,22 @@ type Request struct {
 	Subject   types.Subject
 	Action    types.Action
 	Resources []types.Resource
+
+	// Env 环境属性, 由API层根据请求上下文注入
+	Env Environment
+
+	// Ctx 请求的context, 由API层注入, 查询第三方资源属性时不会超过请求的deadline
+	Ctx context.Context
+}
+
+// Environment 鉴权请求的环境属性, 对应表达式中的env.*
+type Environment struct {
+	// Time 请求时间, 已转换到调用方指定的时区
+	Time time.Time
+	// ClientIP 调用方的IP
+	ClientIP string
+	// SourceApp 调用方的app_code
+	SourceApp string
 }
 
 // NewRequest new request
@@ -37,6 +56,14 @@ func NewRequest() *Request {
 	}
 }
 
+// Context 请求的context, 未注入时返回context.Background()
+func (r *Request) Context() context.Context {
+	if r.Ctx == nil {
+		return context.Background()
+	}
+	return r.Ctx
+}
+
 // HasSingleLocalResource 是否只有一个本地依赖资源
 func (r *Request) HasSingleLocalResource() bool {
 	resourceTypes, _ := r.Action.Attribute.GetResourceTypes()
@@ -0,0 +1,37 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package types
+
+// TemplateUnbindTask status
+const (
+	TemplateUnbindTaskStatusRunning  = "running"
+	TemplateUnbindTaskStatusFinished = "finished"
+	TemplateUnbindTaskStatusFailed   = "failed"
+)
+
+// TemplateUnbindTask 异步解绑模板(删除模板生成的策略)的任务进度
+type TemplateUnbindTask struct {
+	ID          string `json:"id"`
+	SystemID    string `json:"system_id"`
+	SubjectType string `json:"subject_type"`
+	SubjectID   string `json:"subject_id"`
+	TemplateID  int64  `json:"template_id"`
+
+	Status  string `json:"status"`
+	Total   int64  `json:"total"`
+	Deleted int64  `json:"deleted"`
+	Error   string `json:"error"`
+
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+	// 执行任务的实例每批删除后更新, 超时未更新的任务由其他实例接管
+	HeartbeatAt int64 `json:"heartbeat_at"`
+}
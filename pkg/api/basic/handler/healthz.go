@@ -31,7 +31,7 @@ func checkDatabase(dbConfig *config.Database) error {
 }
 
 func checkRedis(redisConfig *config.Redis) error {
-	var rds *redis.Client
+	var rds redis.UniversalClient
 	switch redisConfig.ID {
 	case pkgredis.ModeStandalone:
 		opt := &redis.Options{
@@ -58,8 +58,16 @@ func checkRedis(redisConfig *config.Redis) error {
 		}
 
 		rds = redis.NewFailoverClient(opt)
+	case pkgredis.ModeCluster:
+		opt := &redis.ClusterOptions{
+			Addrs:    strings.Split(redisConfig.ClusterAddr, ","),
+			Password: redisConfig.Password,
+			PoolSize: 1,
+		}
+
+		rds = redis.NewClusterClient(opt)
 	default:
-		return errors.New("invalid redis ID, should be `standalone` or `sentinel`")
+		return errors.New("invalid redis ID, should be `standalone`, `sentinel` or `cluster`")
 	}
 
 	defer rds.Close()
@@ -115,6 +123,12 @@ func NewHealthzHandleFunc(cfg *config.Config) gin.HandlerFunc {
 			err = checkRedis(&redisConfig)
 		}
 
+		redisConfig, ok = cfg.RedisMap[pkgredis.ModeCluster]
+		if ok {
+			addr = redisConfig.ClusterAddr
+			err = checkRedis(&redisConfig)
+		}
+
 		if err != nil {
 			message := fmt.Sprintf("redis(mode=%s) connect fail: %s [addr=%s]", redisConfig.ID, err.Error(), addr)
 			c.String(http.StatusInternalServerError, message)
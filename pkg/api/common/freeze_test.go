@@ -0,0 +1,105 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache/impls"
+	"iam/pkg/cache/redis"
+	"iam/pkg/util"
+)
+
+var _ = Describe("Freeze", func() {
+	BeforeEach(func() {
+		impls.SystemFreezeCache = redis.NewMockCache("sys_frz", 0)
+	})
+
+	AfterEach(func() {
+		impls.SystemFreezeCache = nil
+	})
+
+	It("not init", func() {
+		impls.SystemFreezeCache = nil
+		assert.False(GinkgoT(), IsSystemFrozen("bk_cmdb"))
+	})
+
+	It("freeze and unfreeze", func() {
+		assert.False(GinkgoT(), IsSystemFrozen("bk_cmdb"))
+
+		err := FreezeSystem("bk_cmdb", "migration")
+		assert.NoError(GinkgoT(), err)
+		err = FreezeSystem("bk_job", "")
+		assert.NoError(GinkgoT(), err)
+
+		assert.True(GinkgoT(), IsSystemFrozen("bk_cmdb"))
+		assert.False(GinkgoT(), IsSystemFrozen("bk_sops"))
+
+		systems, err := ListFrozenSystems()
+		assert.NoError(GinkgoT(), err)
+		assert.Len(GinkgoT(), systems, 2)
+		assert.Equal(GinkgoT(), "bk_cmdb", systems[0].SystemID)
+		assert.Equal(GinkgoT(), "migration", systems[0].Reason)
+		assert.InDelta(GinkgoT(), time.Now().Unix(), systems[0].FrozenAt, 5)
+		assert.Equal(GinkgoT(), "bk_job", systems[1].SystemID)
+
+		err = UnfreezeSystem("bk_cmdb")
+		assert.NoError(GinkgoT(), err)
+		assert.False(GinkgoT(), IsSystemFrozen("bk_cmdb"))
+
+		systems, err = ListFrozenSystems()
+		assert.NoError(GinkgoT(), err)
+		assert.Len(GinkgoT(), systems, 1)
+	})
+
+	Describe("SystemNotFrozen", func() {
+		var r *gin.Engine
+		BeforeEach(func() {
+			gin.SetMode(gin.ReleaseMode)
+			r = gin.New()
+			s := r.Group("/systems/:system_id")
+			s.Use(SystemNotFrozen())
+			s.GET("", func(c *gin.Context) { util.SuccessJSONResponse(c, "ok", nil) })
+			s.POST("", func(c *gin.Context) { util.SuccessJSONResponse(c, "ok", nil) })
+
+			err := FreezeSystem("bk_cmdb", "migration")
+			assert.NoError(GinkgoT(), err)
+		})
+
+		serve := func(method, path string) string {
+			req, _ := http.NewRequest(method, path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(GinkgoT(), 200, w.Code)
+			return w.Body.String()
+		}
+
+		It("get is allowed", func() {
+			assert.Contains(GinkgoT(), serve("GET", "/systems/bk_cmdb"), `"code":0`)
+		})
+
+		It("modification of frozen system is rejected", func() {
+			body := serve("POST", "/systems/bk_cmdb")
+			assert.Contains(GinkgoT(), body, "1901409")
+			assert.Contains(GinkgoT(), body, "frozen")
+		})
+
+		It("modification of other system is allowed", func() {
+			assert.Contains(GinkgoT(), serve("POST", "/systems/bk_job"), `"code":0`)
+		})
+	})
+})
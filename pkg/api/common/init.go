@@ -26,6 +26,13 @@ const (
 	DefaultMaxResourceTypesLimit      = 50
 	DefaultMaxInstanceSelectionsLimit = 50
 
+	// member expiration, 0 means no default/limit
+	memberDefaultExpirationDaysKey = "default_expiration_days"
+	memberMaxExpirationDaysKey     = "max_expiration_days"
+
+	DefaultMemberDefaultExpirationDays = 0
+	DefaultMemberMaxExpirationDays     = 0
+
 	// triggers
 	triggerDisableCreateSystemClientValidationKey = "disable_create_system_client_validation"
 
@@ -76,6 +83,29 @@ var (
 	GetMaxInstanceSelectionsLimit = makeGetModelLimitFunc(maxInstanceSelectionsLimitKey, DefaultMaxInstanceSelectionsLimit)
 )
 
+func makeGetMemberLimitFunc(key string, defaultLimit int) func(string) int {
+	return func(systemID string) int {
+		// custom
+		if cq, ok := customQuotas[systemID]; ok {
+			if limit, ok := cq.Member[key]; ok && limit > 0 {
+				return limit
+			}
+		}
+		// config file default
+		if limit, ok := quota.Member[key]; ok && limit > 0 {
+			return limit
+		}
+		// default
+		return defaultLimit
+	}
+}
+
+var (
+	GetMemberDefaultExpirationDays = makeGetMemberLimitFunc(
+		memberDefaultExpirationDaysKey, DefaultMemberDefaultExpirationDays)
+	GetMemberMaxExpirationDays = makeGetMemberLimitFunc(memberMaxExpirationDaysKey, DefaultMemberMaxExpirationDays)
+)
+
 func makeGetSwitchFunc(key string, defaultValue bool) func() bool {
 	return func() bool {
 		if b, ok := switches[key]; ok {
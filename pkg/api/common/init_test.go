@@ -65,6 +65,45 @@ var _ = Describe("Init", func() {
 		})
 	})
 
+	Describe("member expiration", func() {
+		It("all default", func() {
+			InitQuota(config.Quota{}, map[string]config.Quota{})
+
+			assert.Equal(GinkgoT(), DefaultMemberDefaultExpirationDays, GetMemberDefaultExpirationDays("abc"))
+			assert.Equal(GinkgoT(), DefaultMemberMaxExpirationDays, GetMemberMaxExpirationDays("abc"))
+		})
+
+		It("hit config file default", func() {
+			InitQuota(config.Quota{
+				Member: map[string]int{
+					memberDefaultExpirationDaysKey: 180,
+					memberMaxExpirationDaysKey:     365,
+				},
+			}, map[string]config.Quota{})
+
+			assert.Equal(GinkgoT(), 180, GetMemberDefaultExpirationDays("abc"))
+			assert.Equal(GinkgoT(), 365, GetMemberMaxExpirationDays("abc"))
+		})
+
+		It("hit custom quotas", func() {
+			InitQuota(config.Quota{
+				Member: map[string]int{
+					memberDefaultExpirationDaysKey: 180,
+					memberMaxExpirationDaysKey:     365,
+				},
+			}, map[string]config.Quota{
+				"abc": {
+					Member: map[string]int{
+						memberMaxExpirationDaysKey: 730,
+					},
+				},
+			})
+
+			assert.Equal(GinkgoT(), 180, GetMemberDefaultExpirationDays("abc"))
+			assert.Equal(GinkgoT(), 730, GetMemberMaxExpirationDays("abc"))
+		})
+	})
+
 	Describe("switches", func() {
 		It("hit default", func() {
 			InitSwitch(map[string]bool{})
@@ -0,0 +1,207 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package common
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/component"
+	"iam/pkg/config"
+	"iam/pkg/errorx"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// 用户组添加成员的决策, 多个钩子的决策取最严格的: rejected > pending > approved
+const (
+	MemberAddDecisionApproved = "approved"
+	MemberAddDecisionPending  = "pending"
+	MemberAddDecisionRejected = "rejected"
+)
+
+var memberAddDecisionLevels = map[string]int{
+	MemberAddDecisionApproved: 0,
+	MemberAddDecisionPending:  1,
+	MemberAddDecisionRejected: 2,
+}
+
+// MemberAddHook 用户组添加成员的预提交钩子, 可以拒绝成员或者将成员标记为待审批
+type MemberAddHook interface {
+	// Check 返回成员的决策, key为`type:id`, 不在结果中的成员视为approved
+	Check(group types.Subject, members []types.Subject, policyExpiredAt int64, clientID string) (map[string]string, error)
+}
+
+// MemberAddCheckResult ...
+type MemberAddCheckResult struct {
+	Approved []types.Subject
+	Pending  []types.Subject
+	Rejected []types.Subject
+}
+
+type memberAddHookEntry struct {
+	name          string
+	groupIDs      *util.StringSet
+	bypassClients *util.StringSet
+	hook          MemberAddHook
+}
+
+// match 钩子是否对该用户组及调用方生效
+func (e *memberAddHookEntry) match(group types.Subject, clientID string) bool {
+	if e.bypassClients.Has(clientID) {
+		return false
+	}
+	return e.groupIDs.Size() == 0 || e.groupIDs.Has(group.ID)
+}
+
+var memberAddHooks []memberAddHookEntry
+
+// InitMemberAddHooks ...
+func InitMemberAddHooks(hooks []config.MemberAddHook) {
+	entries := make([]memberAddHookEntry, 0, len(hooks))
+	for _, h := range hooks {
+		var hook MemberAddHook
+		if h.URL != "" {
+			hook = &httpMemberAddHook{
+				req: component.MemberAddHookRequest{
+					URL:     h.URL,
+					Token:   h.Token,
+					Timeout: time.Duration(h.Timeout) * time.Second,
+				},
+			}
+		} else {
+			if _, ok := memberAddDecisionLevels[h.RuleDecision]; !ok {
+				panic(fmt.Sprintf("init member add hook %s fail, invalid ruleDecision `%s`", h.Name, h.RuleDecision))
+			}
+			hook = &ruleMemberAddHook{
+				memberTypes: util.NewStringSetWithValues(h.RuleMemberTypes),
+				decision:    h.RuleDecision,
+			}
+		}
+
+		entries = append(entries, memberAddHookEntry{
+			name:          h.Name,
+			groupIDs:      util.NewStringSetWithValues(h.GroupIDs),
+			bypassClients: util.NewStringSetWithValues(h.BypassClients),
+			hook:          hook,
+		})
+	}
+	memberAddHooks = entries
+
+	log.Infof("init member add hooks: %+v", hooks)
+}
+
+// CheckMemberAddHooks 执行对用户组生效的钩子, 将成员分为 approved/pending/rejected
+func CheckMemberAddHooks(
+	group types.Subject, members []types.Subject, policyExpiredAt int64, clientID string,
+) (result MemberAddCheckResult, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Common", "CheckMemberAddHooks")
+
+	decisions := make(map[string]string, len(members))
+	for i := range memberAddHooks {
+		entry := &memberAddHooks[i]
+		if !entry.match(group, clientID) {
+			continue
+		}
+
+		var hookDecisions map[string]string
+		hookDecisions, err = entry.hook.Check(group, members, policyExpiredAt, clientID)
+		if err != nil {
+			err = errorWrapf(err, "hook `%s` check group=`%+v` fail", entry.name, group)
+			return
+		}
+
+		for key, decision := range hookDecisions {
+			if memberAddDecisionLevels[decision] > memberAddDecisionLevels[decisions[key]] {
+				decisions[key] = decision
+			}
+		}
+	}
+
+	result = MemberAddCheckResult{
+		Approved: make([]types.Subject, 0, len(members)),
+		Pending:  []types.Subject{},
+		Rejected: []types.Subject{},
+	}
+	for _, m := range members {
+		switch decisions[memberAddHookKey(m.Type, m.ID)] {
+		case MemberAddDecisionPending:
+			result.Pending = append(result.Pending, m)
+		case MemberAddDecisionRejected:
+			result.Rejected = append(result.Rejected, m)
+		default:
+			result.Approved = append(result.Approved, m)
+		}
+	}
+	return result, nil
+}
+
+func memberAddHookKey(_type, id string) string {
+	return _type + ":" + id
+}
+
+// httpMemberAddHook 通过HTTP回调第三方审批系统
+type httpMemberAddHook struct {
+	req component.MemberAddHookRequest
+}
+
+// Check ...
+func (h *httpMemberAddHook) Check(
+	group types.Subject, members []types.Subject, policyExpiredAt int64, clientID string,
+) (map[string]string, error) {
+	hookMembers := make([]component.MemberAddHookSubject, 0, len(members))
+	for _, m := range members {
+		hookMembers = append(hookMembers, component.MemberAddHookSubject{Type: m.Type, ID: m.ID})
+	}
+
+	results, err := component.BKMemberAddHook.Check(
+		h.req,
+		component.MemberAddHookSubject{Type: group.Type, ID: group.ID},
+		hookMembers,
+		policyExpiredAt,
+		clientID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	decisions := make(map[string]string, len(results))
+	for _, r := range results {
+		decision := r.Decision
+		// NOTE: 未知的决策按rejected处理
+		if _, ok := memberAddDecisionLevels[decision]; !ok {
+			decision = MemberAddDecisionRejected
+		}
+		decisions[memberAddHookKey(r.Type, r.ID)] = decision
+	}
+	return decisions, nil
+}
+
+// ruleMemberAddHook 内置规则, 对指定类型的成员做出决策
+type ruleMemberAddHook struct {
+	memberTypes *util.StringSet
+	decision    string
+}
+
+// Check ...
+func (h *ruleMemberAddHook) Check(
+	group types.Subject, members []types.Subject, policyExpiredAt int64, clientID string,
+) (map[string]string, error) {
+	decisions := make(map[string]string, len(members))
+	for _, m := range members {
+		if h.memberTypes.Has(m.Type) {
+			decisions[memberAddHookKey(m.Type, m.ID)] = h.decision
+		}
+	}
+	return decisions, nil
+}
@@ -0,0 +1,145 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package common
+
+import (
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/component"
+	"iam/pkg/component/mock"
+	"iam/pkg/config"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("MemberAddHook", func() {
+	var group types.Subject
+	var members []types.Subject
+
+	BeforeEach(func() {
+		group = types.Subject{Type: "group", ID: "1"}
+		members = []types.Subject{
+			{Type: "user", ID: "admin"},
+			{Type: "department", ID: "10"},
+		}
+	})
+
+	AfterEach(func() {
+		InitMemberAddHooks(nil)
+	})
+
+	It("no hooks, all approved", func() {
+		result, err := CheckMemberAddHooks(group, members, 10, "bk_test")
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), members, result.Approved)
+		assert.Empty(GinkgoT(), result.Pending)
+		assert.Empty(GinkgoT(), result.Rejected)
+	})
+
+	It("invalid rule decision", func() {
+		assert.Panics(GinkgoT(), func() {
+			InitMemberAddHooks([]config.MemberAddHook{{Name: "bad", RuleDecision: "unknown"}})
+		})
+	})
+
+	It("rule hook, most restrictive decision wins", func() {
+		InitMemberAddHooks([]config.MemberAddHook{
+			{Name: "dept_pending", RuleMemberTypes: []string{"department"}, RuleDecision: MemberAddDecisionPending},
+			{Name: "dept_reject", RuleMemberTypes: []string{"department"}, RuleDecision: MemberAddDecisionRejected},
+			{Name: "user_approve", RuleMemberTypes: []string{"user"}, RuleDecision: MemberAddDecisionApproved},
+		})
+
+		result, err := CheckMemberAddHooks(group, members, 10, "bk_test")
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), []types.Subject{{Type: "user", ID: "admin"}}, result.Approved)
+		assert.Empty(GinkgoT(), result.Pending)
+		assert.Equal(GinkgoT(), []types.Subject{{Type: "department", ID: "10"}}, result.Rejected)
+	})
+
+	It("group not match and bypass clients", func() {
+		InitMemberAddHooks([]config.MemberAddHook{
+			{
+				Name:            "other_group",
+				GroupIDs:        []string{"2"},
+				RuleMemberTypes: []string{"user"},
+				RuleDecision:    MemberAddDecisionRejected,
+			},
+			{
+				Name:            "bypass",
+				BypassClients:   []string{"bk_test"},
+				RuleMemberTypes: []string{"user"},
+				RuleDecision:    MemberAddDecisionRejected,
+			},
+		})
+
+		result, err := CheckMemberAddHooks(group, members, 10, "bk_test")
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), members, result.Approved)
+
+		result, err = CheckMemberAddHooks(group, members, 10, "bk_other")
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), []types.Subject{{Type: "user", ID: "admin"}}, result.Rejected)
+	})
+
+	Describe("http hook", func() {
+		var ctl *gomock.Controller
+		var mockClient *mock.MockMemberAddHookClient
+		var oldClient component.MemberAddHookClient
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			mockClient = mock.NewMockMemberAddHookClient(ctl)
+			oldClient = component.BKMemberAddHook
+			component.BKMemberAddHook = mockClient
+
+			InitMemberAddHooks([]config.MemberAddHook{
+				{Name: "approval", GroupIDs: []string{"1"}, URL: "http://approval/check", Token: "abc", Timeout: 3},
+			})
+		})
+
+		AfterEach(func() {
+			component.BKMemberAddHook = oldClient
+			ctl.Finish()
+		})
+
+		It("ok", func() {
+			mockClient.EXPECT().Check(
+				gomock.Any(),
+				component.MemberAddHookSubject{Type: "group", ID: "1"},
+				[]component.MemberAddHookSubject{{Type: "user", ID: "admin"}, {Type: "department", ID: "10"}},
+				int64(10),
+				"bk_test",
+			).Return([]component.MemberAddHookResult{
+				{Type: "user", ID: "admin", Decision: MemberAddDecisionPending},
+				{Type: "department", ID: "10", Decision: "whatever"},
+			}, nil)
+
+			result, err := CheckMemberAddHooks(group, members, 10, "bk_test")
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), result.Approved)
+			assert.Equal(GinkgoT(), []types.Subject{{Type: "user", ID: "admin"}}, result.Pending)
+			assert.Equal(GinkgoT(), []types.Subject{{Type: "department", ID: "10"}}, result.Rejected)
+		})
+
+		It("error", func() {
+			mockClient.EXPECT().Check(
+				gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+			).Return(nil, errors.New("timeout"))
+
+			_, err := CheckMemberAddHooks(group, members, 10, "bk_test")
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "approval")
+		})
+	})
+})
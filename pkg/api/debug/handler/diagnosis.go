@@ -0,0 +1,30 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/service"
+	"iam/pkg/util"
+)
+
+// DiagnoseSubjectRelationIndex EXPLAIN subject_relation表的高频查询, 报告缺失或未被使用的索引
+func DiagnoseSubjectRelationIndex(c *gin.Context) {
+	svc := service.NewSubjectRelationIndexService()
+	report, err := svc.Advise()
+	if err != nil {
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", report)
+}
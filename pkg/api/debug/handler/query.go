@@ -179,6 +179,10 @@ func QueryPolicies(c *gin.Context) {
 	expr, err := pdp.Query(req, entry, false, isForce)
 	debug.WithError(entry, err)
 	if err != nil {
+		if errors.Is(err, pdp.ErrTooManyEvaluations) {
+			util.TooManyRequestsJSONResponse(c, err.Error())
+			return
+		}
 		if errors.Is(err, pdp.ErrInvalidAction) {
 			util.BadRequestErrorJSONResponse(c, err.Error())
 			return
@@ -42,4 +42,10 @@ func Register(r *gin.RouterGroup) {
 
 		// TODO:  精准删除缓存 => policy / expression
 	}
+
+	d := r.Group("/diagnosis")
+	{
+		// subject_relation表的索引诊断, 发现线上表结构的索引漂移  /api/v1/debug/diagnosis/subject_relation/index
+		d.GET("/subject_relation/index", handler.DiagnoseSubjectRelationIndex)
+	}
 }
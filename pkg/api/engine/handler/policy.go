@@ -286,6 +286,8 @@ func constructEnginePolicy(p types.EngineQueryPolicy, expr string) (policy engin
 			Name: subj.Name,
 		},
 		Expression: translatedExpr,
+		Effect:     p.Effect,
+		Priority:   p.Priority,
 		TemplateID: p.TemplateID,
 		ExpiredAt:  p.ExpiredAt,
 		UpdatedAt:  p.UpdatedAt,
@@ -0,0 +1,109 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/cache/impls"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/util"
+)
+
+// ExportSystemPolicies godoc
+// @Summary system policy export
+// @Description export the policies of a system page by page, the expression is translated to engine expression
+// @ID api-engine-system-policies-export
+// @Tags engine
+// @Accept json
+// @Produce json
+// @Param system_id path string true "System ID"
+// @Param params query exportSystemPolicySerializer true "the export request"
+// @Success 200 {object} util.Response{data=exportSystemPolicyResponse}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/engine/systems/{system_id}/policies [get]
+func ExportSystemPolicies(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "ExportSystemPolicies")
+
+	var query exportSystemPolicySerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	ok, message := query.validate()
+	if !ok {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+	query.initDefault()
+
+	systemID := c.Param("system_id")
+	_, err := impls.GetSystem(systemID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.NotFoundJSONResponse(c, fmt.Sprintf("system(%s)", systemID))
+			return
+		}
+
+		util.SystemErrorJSONResponse(c, errorWrapf(err, "impls.GetSystem systemID=`%s` fail", systemID))
+		return
+	}
+
+	index, err := impls.GetSystemActionIndex(systemID)
+	if err != nil {
+		util.SystemErrorJSONResponse(c, errorWrapf(err, "impls.GetSystemActionIndex systemID=`%s` fail", systemID))
+		return
+	}
+
+	actionPKs := make([]int64, 0, len(index.ActionPKMap))
+	for _, pk := range index.ActionPKMap {
+		actionPKs = append(actionPKs, pk)
+	}
+
+	svc := service.NewEnginePolicyService()
+	policies, err := svc.ListAfterPKByActionPKs(query.Timestamp, query.AfterID, actionPKs, query.Limit)
+	if err != nil {
+		err = errorWrapf(err, "svc.ListAfterPKByActionPKs systemID=`%s`, afterID=`%d`, limit=`%d` fail",
+			systemID, query.AfterID, query.Limit)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	// 与 ListPolicy 使用同一套转换, 保证导出的表达式与同步接口语义一致
+	results, err := convertEngineQueryPoliciesToEnginePolicies(policies)
+	if err != nil {
+		err = errorWrapf(err, "convertEngineQueryPoliciesToEnginePolicies policies length=`%d` fail", len(policies))
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+	if len(results) == 0 {
+		results = []enginePolicyResponse{}
+	}
+
+	// NOTE: 游标取查询到的最后一条策略, 而不是results的最后一条; subject不存在的策略会被忽略
+	nextID := query.AfterID
+	if len(policies) > 0 {
+		nextID = policies[len(policies)-1].PK
+	}
+
+	util.SuccessJSONResponse(c, "ok", exportSystemPolicyResponse{
+		Metadata: query,
+		Results:  results,
+		NextID:   nextID,
+		HasNext:  int64(len(policies)) == query.Limit,
+	})
+}
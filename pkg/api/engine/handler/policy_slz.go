@@ -98,6 +98,8 @@ type enginePolicyResponse struct {
 	Action     policyResponseAction   `json:"action"`
 	Subject    policyResponseSubject  `json:"subject"`
 	Expression map[string]interface{} `json:"expression"`
+	Effect     string                 `json:"effect" example:"allow"`
+	Priority   int64                  `json:"priority" example:"0"`
 	TemplateID int64                  `json:"template_id"`
 	ExpiredAt  int64                  `json:"expired_at" example:"4102444800"`
 	UpdatedAt  int64                  `json:"updated_at" example:"4102444800"`
@@ -140,3 +142,51 @@ type getMaxPolicyIDSerializer struct {
 type getMaxPolicyIDResponse struct {
 	ID int64 `json:"id"`
 }
+
+// -- exportSystemPolicies
+
+const (
+	defaultExportPolicyLimit = 500
+	maxExportPolicyLimit     = 1000
+)
+
+type exportSystemPolicySerializer struct {
+	Timestamp int64 `form:"timestamp" json:"timestamp" binding:"omitempty,min=1" example:"1592899208"`
+
+	// 游标分页, 返回pk大于after_id的策略, 首页为0
+	AfterID int64 `form:"after_id" json:"after_id" binding:"omitempty,min=0" example:"0"`
+	Limit   int64 `form:"limit" json:"limit" binding:"omitempty,min=1" example:"500"`
+}
+
+func (s *exportSystemPolicySerializer) validate() (bool, string) {
+	if s.Limit > maxExportPolicyLimit {
+		return false, fmt.Sprintf("limit=%d, should not greater than %d", s.Limit, maxExportPolicyLimit)
+	}
+
+	if s.Timestamp != 0 {
+		timestamp := util.TodayStartTimestamp()
+		if timestamp-s.Timestamp > 24*60*60 {
+			return false, fmt.Sprintf("timestamp(%d) should not less than one day before(%d)", s.Timestamp, timestamp)
+		}
+	}
+
+	return true, "ok"
+}
+
+func (s *exportSystemPolicySerializer) initDefault() {
+	// 与listPolicy一致, 默认只导出今天00:00:00之后未过期的策略
+	if s.Timestamp == 0 {
+		s.Timestamp = util.TodayStartTimestamp()
+	}
+	if s.Limit == 0 {
+		s.Limit = defaultExportPolicyLimit
+	}
+}
+
+type exportSystemPolicyResponse struct {
+	Metadata exportSystemPolicySerializer `json:"metadata"`
+	Results  []enginePolicyResponse       `json:"results"`
+	// NextID 下一页的after_id
+	NextID  int64 `json:"next_id" example:"10001"`
+	HasNext bool  `json:"has_next" example:"true"`
+}
@@ -32,6 +32,9 @@ func Register(r *gin.RouterGroup) {
 	// GET /api/v1/engine/systems/:system_id 查询系统信息
 	r.GET("/systems/:system_id", handler.GetSystem)
 
+	// GET /api/v1/engine/systems/:system_id/policies 按系统分页导出策略, 表达式已转换为engine表达式
+	r.GET("/systems/:system_id/policies", handler.ExportSystemPolicies)
+
 	// POST /api/v1/engine/credentials/verify 认证信息验证
 	r.POST("/credentials/verify", handler.CredentialsVerify)
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/gin-gonic/gin/binding"
 
 	"iam/pkg/cache/impls"
+	"iam/pkg/cache/invalidation"
 	"iam/pkg/errorx"
 	"iam/pkg/service"
 	svctypes "iam/pkg/service/types"
@@ -83,6 +84,7 @@ func BatchCreateActions(c *gin.Context) {
 			Description:   ac.Description,
 			DescriptionEn: ac.DescriptionEn,
 			Type:          ac.Type,
+			Status:        ac.Status,
 			Version:       ac.Version,
 
 			RelatedActions: ac.RelatedActions,
@@ -193,6 +195,7 @@ func UpdateAction(c *gin.Context) {
 		DescriptionEn:        body.DescriptionEn,
 		Version:              body.Version,
 		Type:                 body.Type,
+		Status:               body.Status,
 		RelatedResourceTypes: convertToRelatedResourceTypes(body.RelatedResourceTypes),
 		RelatedActions:       body.RelatedActions,
 
@@ -209,7 +212,7 @@ func UpdateAction(c *gin.Context) {
 	}
 
 	// delete from cache
-	impls.BatchDeleteActionCache(systemID, []string{actionID})
+	invalidation.DeleteActions(systemID, []string{actionID})
 
 	util.SuccessJSONResponse(c, "ok", nil)
 }
@@ -344,7 +347,7 @@ func batchDeleteActions(c *gin.Context, systemID string, ids []string) {
 		}
 
 		// delete from cache
-		impls.BatchDeleteActionCache(systemID, newIDs)
+		invalidation.DeleteActions(systemID, newIDs)
 	}
 
 	util.SuccessJSONResponse(c, "ok", nil)
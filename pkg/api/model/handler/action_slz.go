@@ -41,6 +41,8 @@ type actionSerializer struct {
 	DescriptionEn string `json:"description_en" binding:"omitempty" example:"biz_create is"`
 
 	Type string `json:"type" binding:"omitempty,oneof=create edit view delete list manage execute debug use"`
+	// 操作的状态, 默认为enabled; 置为disabled后, 鉴权会拒绝(或放行, 取决于配置)
+	Status string `json:"status" binding:"omitempty,oneof=enabled disabled" example:"enabled"`
 
 	RelatedResourceTypes []relatedResourceType `json:"related_resource_types"`
 	RelatedActions       []string              `json:"related_actions"`
@@ -55,6 +57,8 @@ type actionUpdateSerializer struct {
 	DescriptionEn string `json:"description_en" binding:"omitempty" example:"biz_create is"`
 
 	Type string `json:"type" binding:"omitempty,oneof=create edit view delete list manage execute debug use"`
+	// 操作的状态, 默认为enabled; 置为disabled后, 鉴权会拒绝(或放行, 取决于配置)
+	Status string `json:"status" binding:"omitempty,oneof=enabled disabled" example:"enabled"`
 
 	RelatedResourceTypes []relatedResourceType `json:"related_resource_types"`
 	RelatedActions       []string              `json:"related_actions"`
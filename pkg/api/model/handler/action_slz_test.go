@@ -188,6 +188,23 @@ var _ = Describe("ActionSlz", func() {
 			assert.Equal(GinkgoT(), "valid", message)
 
 		})
+		It("with invalid status", func() {
+			b := []actionSerializer{
+				{
+					ID:     "aaa",
+					Name:   "aaa",
+					NameEn: "aaa",
+					Status: "sunset",
+				},
+			}
+			valid, message := validateAction(b)
+			assert.False(GinkgoT(), valid)
+			assert.Contains(GinkgoT(), message, "Status")
+
+			b[0].Status = "disabled"
+			valid, _ = validateAction(b)
+			assert.True(GinkgoT(), valid)
+		})
 		It("with invalid relatedResourceTypes", func() {
 			c := []actionSerializer{
 				{
@@ -11,6 +11,9 @@
 package handler
 
 import (
+	"database/sql"
+	"errors"
+
 	"github.com/gin-gonic/gin"
 
 	"iam/pkg/errorx"
@@ -20,14 +23,15 @@ import (
 
 // SystemQueryFieldBaseInfo ...
 const (
-	SystemQueryFieldBaseInfo               = "base_info"
-	SystemQueryFieldResourceTypes          = "resource_types"
-	SystemQueryFieldActions                = "actions"
-	SystemQueryFieldInstanceSelections     = "instance_selections"
-	SystemQueryFieldActionGroups           = "action_groups"
-	SystemQueryFieldResourceCreatorActions = "resource_creator_actions"
-	SystemQueryFieldCommonActions          = "common_actions"
-	SystemQueryFieldFeatureShieldRules     = "feature_shield_rules"
+	SystemQueryFieldBaseInfo                 = "base_info"
+	SystemQueryFieldResourceTypes            = "resource_types"
+	SystemQueryFieldActions                  = "actions"
+	SystemQueryFieldInstanceSelections       = "instance_selections"
+	SystemQueryFieldActionGroups             = "action_groups"
+	SystemQueryFieldResourceCreatorActions   = "resource_creator_actions"
+	SystemQueryFieldCommonActions            = "common_actions"
+	SystemQueryFieldFeatureShieldRules       = "feature_shield_rules"
+	SystemQueryFieldResourceAttributeSchemas = "resource_attribute_schemas"
 )
 
 // SystemInfoQuery godoc
@@ -131,7 +135,8 @@ func BuildSystemInfoQueryResponse(c *gin.Context, systemID string, fieldSet *uti
 	if fieldSet.Has(SystemQueryFieldActionGroups) ||
 		fieldSet.Has(SystemQueryFieldResourceCreatorActions) ||
 		fieldSet.Has(SystemQueryFieldCommonActions) ||
-		fieldSet.Has(SystemQueryFieldFeatureShieldRules) {
+		fieldSet.Has(SystemQueryFieldFeatureShieldRules) ||
+		fieldSet.Has(SystemQueryFieldResourceAttributeSchemas) {
 		svc := service.NewSystemConfigService()
 
 		if fieldSet.Has(SystemQueryFieldActionGroups) {
@@ -164,6 +169,19 @@ func BuildSystemInfoQueryResponse(c *gin.Context, systemID string, fieldSet *uti
 			}
 			data[SystemQueryFieldFeatureShieldRules] = fsrs
 		}
+		if fieldSet.Has(SystemQueryFieldResourceAttributeSchemas) {
+			rass, err := svc.GetResourceAttributeSchemas(systemID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				err = errorx.Wrapf(err, "Handler", "SystemInfoQuery",
+					"svc.GetResourceAttributeSchemas system_id=`%s` fail", systemID)
+				util.SystemErrorJSONResponse(c, err)
+				return
+			}
+			if rass == nil {
+				rass = map[string]interface{}{}
+			}
+			data[SystemQueryFieldResourceAttributeSchemas] = rass
+		}
 	}
 
 	util.SuccessJSONResponse(c, "ok", data)
@@ -19,6 +19,7 @@ import (
 
 	"iam/pkg/api/common"
 	"iam/pkg/cache/impls"
+	"iam/pkg/cache/invalidation"
 	"iam/pkg/errorx"
 	"iam/pkg/service"
 	svctypes "iam/pkg/service/types"
@@ -196,7 +197,7 @@ func UpdateResourceType(c *gin.Context) {
 	}
 
 	// delete the cache
-	impls.BatchDeleteResourceTypeCache(systemID, []string{resourceTypeID})
+	invalidation.DeleteResourceTypes(systemID, []string{resourceTypeID})
 
 	util.SuccessJSONResponse(c, "ok", nil)
 }
@@ -314,7 +315,7 @@ func batchDeleteResourceTypes(c *gin.Context, systemID string, ids []string) {
 	}
 
 	// delete the cache
-	impls.BatchDeleteResourceTypeCache(systemID, ids)
+	invalidation.DeleteResourceTypes(systemID, ids)
 
 	util.SuccessJSONResponse(c, "ok", nil)
 }
@@ -20,6 +20,8 @@ import (
 type resourceProviderConfig struct {
 	// TODO: valid path?
 	Path string `json:"path" structs:"path" binding:"required,uri" example:"/api/v1/resources/biz_set/query"`
+	// the cache ttl seconds of the resource attributes queried from the system, 0 means the default ttl
+	AttrCacheTTL int64 `json:"attribute_cache_ttl" structs:"attribute_cache_ttl,omitempty" binding:"gte=0,lte=86400"`
 }
 
 type resourceTypeSerializer struct {
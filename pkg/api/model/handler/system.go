@@ -18,7 +18,7 @@ import (
 	"github.com/gin-gonic/gin/binding"
 
 	"iam/pkg/api/common"
-	"iam/pkg/cache/impls"
+	"iam/pkg/cache/invalidation"
 	"iam/pkg/service"
 	svctypes "iam/pkg/service/types"
 	"iam/pkg/util"
@@ -193,7 +193,7 @@ func UpdateSystem(c *gin.Context) {
 	}
 
 	// delete the cache
-	impls.DeleteSystemCache(systemID)
+	invalidation.DeleteSystem(systemID)
 
 	util.SuccessJSONResponse(c, "ok", nil)
 }
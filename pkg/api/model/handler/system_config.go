@@ -16,6 +16,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"iam/pkg/cache/invalidation"
 	"iam/pkg/errorx"
 	"iam/pkg/service"
 	"iam/pkg/util"
@@ -23,12 +24,16 @@ import (
 
 // AllowConfigNames ...
 const (
-	AllowConfigNames = "action_groups,resource_creator_actions,common_actions,feature_shield_rules"
+	AllowConfigNames = "action_groups,resource_creator_actions,common_actions,feature_shield_rules," +
+		"resource_attribute_schemas,policy_cache_backend,model_change_event_callback"
 
-	ConfigNameActionGroups           = "action_groups"
-	ConfigNameResourceCreatorActions = "resource_creator_actions"
-	ConfigCommonActions              = "common_actions"
-	ConfigNameFeatureShieldRules     = "feature_shield_rules"
+	ConfigNameActionGroups             = "action_groups"
+	ConfigNameResourceCreatorActions   = "resource_creator_actions"
+	ConfigCommonActions                = "common_actions"
+	ConfigNameFeatureShieldRules       = "feature_shield_rules"
+	ConfigNameResourceAttributeSchemas = "resource_attribute_schemas"
+	ConfigNamePolicyCacheBackend       = "policy_cache_backend"
+	ConfigNameModelChangeEventCallback = "model_change_event_callback"
 )
 
 // CreateOrUpdateConfigDispatch godoc
@@ -70,6 +75,15 @@ func CreateOrUpdateConfigDispatch(c *gin.Context) {
 	case ConfigNameFeatureShieldRules:
 		featureShieldRuleHandler(systemID, c)
 		return
+	case ConfigNameResourceAttributeSchemas:
+		resourceAttributeSchemaHandler(systemID, c)
+		return
+	case ConfigNamePolicyCacheBackend:
+		policyCacheBackendHandler(systemID, c)
+		return
+	case ConfigNameModelChangeEventCallback:
+		modelChangeEventCallbackHandler(systemID, c)
+		return
 	default:
 		util.SystemErrorJSONResponse(c, errors.New("should not be here"))
 		return
@@ -222,3 +236,83 @@ func featureShieldRuleHandler(systemID string, c *gin.Context) {
 
 	util.SuccessJSONResponse(c, "ok", nil)
 }
+
+func resourceAttributeSchemaHandler(systemID string, c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "resourceAttributeSchemaHandler")
+	var body []resourceAttributeSchemaSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	if valid, message := validateResourceAttributeSchemas(body); !valid {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
+	// 所有resource type id合法
+	resourceTypeIDs := make([]string, 0, len(body))
+	for _, ras := range body {
+		resourceTypeIDs = append(resourceTypeIDs, ras.ID)
+	}
+	if err := checkResourceTypeIDsExist(systemID, resourceTypeIDs); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	// do create, 全量覆盖
+	svc := service.NewSystemConfigService()
+	err := svc.CreateOrUpdateResourceAttributeSchemas(systemID, toResourceAttributeSchemasMap(body))
+	if err != nil {
+		err = errorWrapf(err, "svc.CreateOrUpdateResourceAttributeSchemas systemID=`%s` fail", systemID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", nil)
+}
+
+func policyCacheBackendHandler(systemID string, c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "policyCacheBackendHandler")
+	var body policyCacheBackendSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	svc := service.NewSystemConfigService()
+	err := svc.CreateOrUpdatePolicyCacheBackend(systemID, body.Backend)
+	if err != nil {
+		err = errorWrapf(err, "svc.CreateOrUpdatePolicyCacheBackend systemID=`%s` fail", systemID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	// 清理本地缓存, 使新的策略缓存后端尽快生效
+	invalidation.DeleteSystemPolicyCacheBackend(systemID)
+
+	util.SuccessJSONResponse(c, "ok", nil)
+}
+
+func modelChangeEventCallbackHandler(systemID string, c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "modelChangeEventCallbackHandler")
+	var body modelChangeEventCallbackSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	svc := service.NewSystemConfigService()
+	err := svc.CreateOrUpdateModelChangeEventCallback(systemID, map[string]interface{}{
+		"url":     body.URL,
+		"token":   body.Token,
+		"timeout": body.Timeout,
+	})
+	if err != nil {
+		err = errorWrapf(err, "svc.CreateOrUpdateModelChangeEventCallback systemID=`%s` fail", systemID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", nil)
+}
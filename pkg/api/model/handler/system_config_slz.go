@@ -232,3 +232,72 @@ func validateFeatureShieldRules(featureShieldRules []featureShieldRuleSerializer
 	}
 	return true, "valid"
 }
+
+type resourceAttributeSerializer struct {
+	Name string `json:"name" binding:"required" example:"owner"`
+	Type string `json:"type" binding:"required,oneof=string numeric bool" example:"string"`
+}
+
+type resourceAttributeSchemaSerializer struct {
+	// resource type id
+	ID         string                        `json:"id" binding:"required" example:"host"`
+	Attributes []resourceAttributeSerializer `json:"attributes" binding:"required,gt=0,dive"`
+}
+
+func (r *resourceAttributeSchemaSerializer) validate() error {
+	if err := binding.Validator.ValidateStruct(r); err != nil {
+		return err
+	}
+
+	names := util.NewStringSet()
+	for _, attr := range r.Attributes {
+		if names.Has(attr.Name) {
+			return fmt.Errorf("resource type[%s] attribute[%s] duplicated", r.ID, attr.Name)
+		}
+		names.Add(attr.Name)
+	}
+	return nil
+}
+
+func validateResourceAttributeSchemas(schemas []resourceAttributeSchemaSerializer) (bool, string) {
+	if len(schemas) == 0 {
+		return false, "the array should contain at least 1 item"
+	}
+
+	ids := util.NewStringSet()
+	for _, ras := range schemas {
+		if err := ras.validate(); err != nil {
+			return false, util.ValidationErrorMessage(err)
+		}
+		if ids.Has(ras.ID) {
+			return false, fmt.Sprintf("resource type[%s] duplicated", ras.ID)
+		}
+		ids.Add(ras.ID)
+	}
+	return true, "valid"
+}
+
+// toResourceAttributeSchemasMap 转换为 {resource_type_id: {attribute_name: attribute_type}}, 方便鉴权时查找
+func toResourceAttributeSchemasMap(schemas []resourceAttributeSchemaSerializer) map[string]interface{} {
+	data := make(map[string]interface{}, len(schemas))
+	for _, ras := range schemas {
+		attrs := make(map[string]interface{}, len(ras.Attributes))
+		for _, attr := range ras.Attributes {
+			attrs[attr.Name] = attr.Type
+		}
+		data[ras.ID] = attrs
+	}
+	return data
+}
+
+type policyCacheBackendSerializer struct {
+	Backend string `json:"backend" binding:"required,oneof=both memory redis" example:"both"`
+}
+
+// modelChangeEventCallbackSerializer 接入系统的模型变更事件被处理后(例如操作的策略已清理), 回调通知接入系统
+type modelChangeEventCallbackSerializer struct {
+	URL   string `json:"url" binding:"required,url" example:"http://bk-cmdb.example.com/iam/model_change_events"`
+	Token string `json:"token" binding:"omitempty,max=255"`
+	// seconds, 0 means the default timeout
+	Timeout int `json:"timeout" binding:"omitempty,min=0,max=30" example:"5"`
+}
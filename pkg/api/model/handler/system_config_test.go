@@ -11,8 +11,76 @@
 package handler_test
 
 import (
+	"testing"
+
 	. "github.com/onsi/ginkgo"
+
+	"iam/pkg/api/model/handler"
+	"iam/pkg/util"
 )
 
 var _ = Describe("SystemConfig", func() {
 })
+
+func TestCreateOrUpdatePolicyCacheBackendConfig(t *testing.T) {
+	t.Parallel()
+
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"post", "/api/v1/systems/test/configs/policy_cache_backend", handler.CreateOrUpdateConfigDispatch,
+		"/api/v1/systems/:system_id/configs/:name",
+	)
+
+	t.Run("no json", func(t *testing.T) {
+		newRequestFunc(t).NoJSON()
+	})
+
+	t.Run("bad request missing backend", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON(map[string]interface{}{}).
+			BadRequestContainsMessage("bad request:Backend is required")
+	})
+
+	t.Run("bad request invalid backend", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"backend": "mysql",
+			}).
+			BadRequestContainsMessage("bad request:Backend must be one of")
+	})
+}
+
+func TestCreateOrUpdateModelChangeEventCallbackConfig(t *testing.T) {
+	t.Parallel()
+
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"post", "/api/v1/systems/test/configs/model_change_event_callback", handler.CreateOrUpdateConfigDispatch,
+		"/api/v1/systems/:system_id/configs/:name",
+	)
+
+	t.Run("no json", func(t *testing.T) {
+		newRequestFunc(t).NoJSON()
+	})
+
+	t.Run("bad request missing url", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON(map[string]interface{}{}).
+			BadRequestContainsMessage("bad request:URL is required")
+	})
+
+	t.Run("bad request invalid url", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"url": "bk_test",
+			}).
+			BadRequestContainsMessage("bad request:URL")
+	})
+
+	t.Run("bad request invalid timeout", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"url":     "http://bk_test.example.com/callback",
+				"timeout": 60,
+			}).
+			BadRequestContainsMessage("bad request:Timeout")
+	})
+}
@@ -26,6 +26,8 @@ func Register(r *gin.RouterGroup) {
 	s := r.Group("/systems/:system_id")
 	// validate: 1) system exists 2) client_id is valid, in system.clients
 	s.Use(common.SystemExistsAndClientValid())
+	// the modification of frozen system is not allowed
+	s.Use(common.SystemNotFrozen())
 	{
 		// system
 		s.PUT("", handler.UpdateSystem)
@@ -0,0 +1,104 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/errorx"
+	"iam/pkg/logging/debug"
+	"iam/pkg/util"
+)
+
+// BatchAuthByResources godoc
+// @Summary batch auth by resources
+// @Description auth the same subject/action against multiple resource instances, the policies are queried only once
+// @Description NOTE: only eval the policies, the super manager/system manager is not included
+// @ID api-open-system-auth-by-resources
+// @Tags open
+// @Accept json
+// @Produce json
+// @Param system_id path string true "System ID"
+// @Param body body authByResourcesSerializer true "the batch auth by resources request"
+// @Success 200 {object} util.Response{data=authByResourcesResponse}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/systems/{system_id}/auth/resources [post]
+func BatchAuthByResources(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "BatchAuthByResources")
+
+	var body authByResourcesSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	systemID := c.Param("system_id")
+
+	req := request.NewRequest()
+	req.System = systemID
+	req.Subject.Type = body.Subject.Type
+	req.Subject.ID = body.Subject.ID
+	req.Action.ID = body.Action.ID
+	req.Env = request.Environment{
+		Time:      time.Now(),
+		ClientIP:  c.ClientIP(),
+		SourceApp: util.GetClientID(c),
+	}
+	req.Ctx = c.Request.Context()
+
+	// 每个资源实例单独计算
+	resourcesList := make([][]types.Resource, 0, len(body.Resources))
+	for _, r := range body.Resources {
+		resourcesList = append(resourcesList, []types.Resource{{
+			System:    r.System,
+			Type:      r.Type,
+			ID:        r.ID,
+			Attribute: r.Attribute,
+		}})
+	}
+
+	var entry *debug.Entry
+	if _, isDebug := c.GetQuery("debug"); isDebug {
+		entry = debug.EntryPool.Get()
+		defer debug.EntryPool.Put(entry)
+	}
+	_, isForce := c.GetQuery("force")
+
+	results, err := pdp.BatchEvalResources(req, resourcesList, entry, isForce)
+	debug.WithError(entry, err)
+	if err != nil {
+		if errors.Is(err, pdp.ErrTooManyEvaluations) {
+			util.TooManyRequestsJSONResponse(c, err.Error())
+			return
+		}
+		if errors.Is(err, pdp.ErrInvalidAction) || errors.Is(err, pdp.ErrInvalidActionResource) {
+			util.BadRequestErrorJSONResponse(c, err.Error())
+			return
+		}
+
+		err = errorWrapf(err, "systemID=`%s`, body=`%+v`", systemID, body)
+		util.SystemErrorJSONResponseWithDebug(c, err, entry)
+		return
+	}
+
+	data := make(authByResourcesResponse, len(body.Resources))
+	for i, r := range body.Resources {
+		data[r.ID] = results[i]
+	}
+	util.SuccessJSONResponseWithDebug(c, "ok", data, entry)
+}
@@ -0,0 +1,73 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/logging/debug"
+	"iam/pkg/util"
+)
+
+func TestBatchAuthByResources(t *testing.T) {
+	url := "/api/v1/systems/bk_test/auth/resources"
+	handlerURL := "/api/v1/systems/:system_id/auth/resources"
+	body := map[string]interface{}{
+		"subject": map[string]string{"type": "user", "id": "tom"},
+		"action":  map[string]string{"id": "edit"},
+		"resources": []map[string]interface{}{
+			{"system": "bk_test", "type": "app", "id": "a1"},
+			{"system": "bk_test", "type": "app", "id": "a2"},
+		},
+	}
+
+	newPatches := func(results []bool, evalErr error) *gomonkey.Patches {
+		return gomonkey.ApplyFunc(pdp.BatchEvalResources, func(
+			r *request.Request, resourcesList [][]types.Resource, entry *debug.Entry, withoutCache bool,
+		) ([]bool, error) {
+			return results, evalErr
+		})
+	}
+
+	t.Run("bad request without resources", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthByResources, handlerURL)(t).JSON(map[string]interface{}{
+			"subject": body["subject"],
+			"action":  body["action"],
+		}).BadRequestContainsMessage("Resources")
+	})
+
+	t.Run("bad request invalid action", func(t *testing.T) {
+		patches := newPatches(nil, pdp.ErrInvalidAction)
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthByResources, handlerURL)(t).JSON(body).
+			BadRequestContainsMessage(pdp.ErrInvalidAction.Error())
+	})
+
+	t.Run("eval fail", func(t *testing.T) {
+		patches := newPatches(nil, errors.New("eval fail"))
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthByResources, handlerURL)(t).JSON(body).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := newPatches([]bool{true, false}, nil)
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthByResources, handlerURL)(t).JSON(body).OK()
+	})
+}
@@ -0,0 +1,89 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/pdp/condition"
+	"iam/pkg/abac/pip"
+	"iam/pkg/errorx"
+	"iam/pkg/util"
+)
+
+// BuildExpression godoc
+// @Summary expression build
+// @Description build the resource expression from the field/op/value filters, validated by the registered schemas
+// @ID api-open-system-expressions-build
+// @Tags open
+// @Accept json
+// @Produce json
+// @Param system_id path string true "System ID"
+// @Param body body expressionBuildSerializer true "the expression build request"
+// @Success 200 {object} util.Response{data=expressionBuildResponse}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/systems/{system_id}/expressions/build [post]
+func BuildExpression(c *gin.Context) {
+	var body expressionBuildSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	systemID := c.Param("system_id")
+
+	_, actionResourceTypes, err := pip.GetActionDetail(systemID, body.Action.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.NotFoundJSONResponse(c, fmt.Sprintf("action(%s) of system(%s) not exists", body.Action.ID, systemID))
+			return
+		}
+
+		err = errorx.Wrapf(err, "Handler", "BuildExpression",
+			"pip.GetActionDetail systemID=`%s`, actionID=`%s` fail", systemID, body.Action.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	expression, errs, err := pdp.BuildResourceExpression(body.toPDPResources())
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "BuildExpression",
+			"pdp.BuildResourceExpression systemID=`%s`, actionID=`%s` fail", systemID, body.Action.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	// 构造的表达式同样需要与操作关联的资源类型一致
+	if len(errs) == 0 {
+		errs = condition.Validate(expression, actionResourceTypes)
+	}
+	if len(errs) > 0 {
+		util.BadRequestErrorJSONResponse(c, validationErrorsMessage(errs))
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", expressionBuildResponse{Expression: expression})
+}
+
+func validationErrorsMessage(errs []condition.ValidationError) string {
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		messages = append(messages, e.Error())
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,56 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import "iam/pkg/abac/pdp"
+
+type expressionFilterSerializer struct {
+	Field string `json:"field" example:"os"`
+	Op    string `json:"op" binding:"required,oneof=eq in not_in starts_with gt gte lt lte any" example:"in"`
+	// single value for eq/starts_with/gt/gte/lt/lte, list for in/not_in, empty for any
+	Value interface{} `json:"value"`
+}
+
+type resourceExpressionFiltersSerializer struct {
+	System string `json:"system" binding:"required" example:"bk_test"`
+	Type   string `json:"type" binding:"required" example:"host"`
+	// the filters are combined with AND, empty means any resource
+	Filters []expressionFilterSerializer `json:"filters" binding:"omitempty,dive"`
+}
+
+type expressionBuildSerializer struct {
+	Action    authAction                            `json:"action" binding:"required"`
+	Resources []resourceExpressionFiltersSerializer `json:"resources" binding:"required,min=1,dive"`
+}
+
+func (s *expressionBuildSerializer) toPDPResources() []pdp.ResourceExpressionFilters {
+	resources := make([]pdp.ResourceExpressionFilters, 0, len(s.Resources))
+	for _, r := range s.Resources {
+		filters := make([]pdp.ExpressionFilter, 0, len(r.Filters))
+		for _, f := range r.Filters {
+			filters = append(filters, pdp.ExpressionFilter{
+				Field: f.Field,
+				Op:    f.Op,
+				Value: f.Value,
+			})
+		}
+		resources = append(resources, pdp.ResourceExpressionFilters{
+			System:  r.System,
+			Type:    r.Type,
+			Filters: filters,
+		})
+	}
+	return resources
+}
+
+type expressionBuildResponse struct {
+	// the same json as policy.Expression
+	Expression string `json:"expression" example:"[]"`
+}
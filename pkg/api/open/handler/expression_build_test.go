@@ -0,0 +1,105 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+
+	"iam/pkg/abac/pip"
+	"iam/pkg/abac/types"
+	"iam/pkg/util"
+)
+
+func TestBuildExpression(t *testing.T) {
+	url := "/api/v1/systems/bk_test/expressions/build"
+	handlerURL := "/api/v1/systems/:system_id/expressions/build"
+	body := map[string]interface{}{
+		"action": map[string]string{"id": "edit"},
+		"resources": []map[string]interface{}{
+			{
+				"system":  "bk_test",
+				"type":    "app",
+				"filters": []map[string]interface{}{{"field": "id", "op": "eq", "value": "a1"}},
+			},
+		},
+	}
+
+	newPatches := func(arts []types.ActionResourceType, err error) *gomonkey.Patches {
+		patches := gomonkey.ApplyFunc(pip.GetActionDetail, func(
+			system, id string,
+		) (int64, []types.ActionResourceType, error) {
+			return 1, arts, err
+		})
+		patches.ApplyFunc(pip.GetResourceAttributeSchema, func(system, _type string) (map[string]string, error) {
+			return nil, nil
+		})
+		return patches
+	}
+
+	t.Run("bad request without resources", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, BuildExpression, handlerURL)(t).JSON(map[string]interface{}{
+			"action": body["action"],
+		}).BadRequestContainsMessage("Resources")
+	})
+
+	t.Run("bad request invalid op", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, BuildExpression, handlerURL)(t).JSON(map[string]interface{}{
+			"action": body["action"],
+			"resources": []map[string]interface{}{
+				{
+					"system":  "bk_test",
+					"type":    "app",
+					"filters": []map[string]interface{}{{"field": "id", "op": "like", "value": "a1"}},
+				},
+			},
+		}).BadRequestContainsMessage("Op")
+	})
+
+	t.Run("get action detail fail", func(t *testing.T) {
+		patches := newPatches(nil, errors.New("get action detail fail"))
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BuildExpression, handlerURL)(t).JSON(body).SystemError()
+	})
+
+	t.Run("field not registered", func(t *testing.T) {
+		patches := newPatches([]types.ActionResourceType{{System: "bk_test", Type: "app"}}, nil)
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BuildExpression, handlerURL)(t).JSON(map[string]interface{}{
+			"action": body["action"],
+			"resources": []map[string]interface{}{
+				{
+					"system":  "bk_test",
+					"type":    "app",
+					"filters": []map[string]interface{}{{"field": "owner", "op": "eq", "value": "admin"}},
+				},
+			},
+		}).BadRequestContainsMessage("not registered")
+	})
+
+	t.Run("resource type not related", func(t *testing.T) {
+		patches := newPatches([]types.ActionResourceType{{System: "bk_test", Type: "host"}}, nil)
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BuildExpression, handlerURL)(t).JSON(body).
+			BadRequestContainsMessage("not related to the action")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := newPatches([]types.ActionResourceType{{System: "bk_test", Type: "app"}}, nil)
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BuildExpression, handlerURL)(t).JSON(body).OK()
+	})
+}
@@ -0,0 +1,78 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/errorx"
+	"iam/pkg/util"
+)
+
+// EvalExpression godoc
+// @Summary expression eval sandbox
+// @Description eval a resource expression with the mock resource attributes, without the real policies or subjects
+// @ID api-open-system-expressions-eval
+// @Tags open
+// @Accept json
+// @Produce json
+// @Param system_id path string true "System ID"
+// @Param body body expressionEvalSerializer true "the expression eval request"
+// @Success 200 {object} util.Response{data=pdp.ExpressionEvaluation}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/systems/{system_id}/expressions/eval [post]
+func EvalExpression(c *gin.Context) {
+	var body expressionEvalSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	systemID := c.Param("system_id")
+
+	req := request.NewRequest()
+	req.System = systemID
+	req.Env = request.Environment{
+		Time:      time.Now(),
+		ClientIP:  c.ClientIP(),
+		SourceApp: util.GetClientID(c),
+	}
+	req.Ctx = c.Request.Context()
+	for _, r := range body.Resources {
+		req.Resources = append(req.Resources, types.Resource{
+			System:    r.System,
+			Type:      r.Type,
+			ID:        r.ID,
+			Attribute: r.Attribute,
+		})
+	}
+
+	evaluation, err := pdp.EvalExpression(req, body.Expression)
+	if err != nil {
+		if errors.Is(err, pdp.ErrInvalidExpression) {
+			util.BadRequestErrorJSONResponse(c, err.Error())
+			return
+		}
+
+		err = errorx.Wrapf(err, "Handler", "EvalExpression",
+			"pdp.EvalExpression systemID=`%s`, expression=`%s` fail", systemID, body.Expression)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+	util.SuccessJSONResponse(c, "ok", evaluation)
+}
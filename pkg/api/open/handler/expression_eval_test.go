@@ -0,0 +1,67 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/steinfletcher/apitest"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/util"
+)
+
+func TestEvalExpression(t *testing.T) {
+	url := "/api/v1/systems/bk_test/expressions/eval"
+	handlerURL := "/api/v1/systems/:system_id/expressions/eval"
+	resources := []map[string]interface{}{
+		{"system": "bk_test", "type": "app", "id": "a1", "attribute": map[string]interface{}{"level": 5}},
+	}
+
+	t.Run("bad request without resources", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, EvalExpression, handlerURL)(t).JSON(map[string]interface{}{
+			"expression": "[]",
+		}).BadRequestContainsMessage("Resources")
+	})
+
+	t.Run("bad request invalid expression", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, EvalExpression, handlerURL)(t).JSON(map[string]interface{}{
+			"expression": "[",
+			"resources":  resources,
+		}).BadRequestContainsMessage("expression invalid")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		r := gin.Default()
+		r.POST(handlerURL, EvalExpression)
+		apitest.New().
+			Handler(r).
+			Post(url).
+			JSON(map[string]interface{}{
+				"expression": `[{"system": "bk_test", "type": "app", "expression": ` +
+					`{"OR": {"content": [{"StringEquals": {"id": ["a2"]}}, {"NumericGt": {"level": [3]}}]}}}]`,
+				"resources": resources,
+			}).
+			Expect(t).
+			Assert(util.NewResponseAssertFunc(t, func(resp util.Response) error {
+				assert.Equal(t, util.NoError, resp.Code)
+				data := resp.Data.(map[string]interface{})
+				assert.Equal(t, true, data["allowed"])
+				resource := data["resources"].([]interface{})[0].(map[string]interface{})
+				assert.Equal(t, "a1", resource["id"])
+				assert.Equal(t, []interface{}{"OR", "OR.content[1].NumericGt"}, resource["matched_nodes"])
+				return nil
+			})).
+			Status(http.StatusOK).
+			End()
+	})
+}
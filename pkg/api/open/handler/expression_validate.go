@@ -0,0 +1,73 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/abac/pdp/condition"
+	"iam/pkg/abac/pip"
+	"iam/pkg/errorx"
+	"iam/pkg/util"
+)
+
+// ValidateExpression godoc
+// @Summary expression validate
+// @Description validate a resource expression against the resource types of the action and the supported operators
+// @ID api-open-system-expressions-validate
+// @Tags open
+// @Accept json
+// @Produce json
+// @Param system_id path string true "System ID"
+// @Param body body expressionValidateSerializer true "the expression validate request"
+// @Success 200 {object} util.Response{data=expressionValidateResponse}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/systems/{system_id}/expressions/validate [post]
+func ValidateExpression(c *gin.Context) {
+	var body expressionValidateSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	systemID := c.Param("system_id")
+
+	// 表达式中的资源类型需要与操作注册的关联资源类型一致
+	_, actionResourceTypes, err := pip.GetActionDetail(systemID, body.Action.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.NotFoundJSONResponse(c, fmt.Sprintf("action(%s) of system(%s) not exists", body.Action.ID, systemID))
+			return
+		}
+
+		err = errorx.Wrapf(err, "Handler", "ValidateExpression",
+			"pip.GetActionDetail systemID=`%s`, actionID=`%s` fail", systemID, body.Action.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	errs, err := condition.ValidateWithSchema(body.Expression, actionResourceTypes, pip.GetResourceAttributeSchema)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "ValidateExpression",
+			"condition.ValidateWithSchema systemID=`%s`, actionID=`%s` fail", systemID, body.Action.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+	util.SuccessJSONResponse(c, "ok", expressionValidateResponse{
+		Valid:  len(errs) == 0,
+		Errors: errs,
+	})
+}
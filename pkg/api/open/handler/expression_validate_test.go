@@ -0,0 +1,126 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/gin-gonic/gin"
+	"github.com/steinfletcher/apitest"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/pip"
+	"iam/pkg/abac/types"
+	"iam/pkg/util"
+)
+
+func TestValidateExpression(t *testing.T) {
+	url := "/api/v1/systems/bk_test/expressions/validate"
+	handlerURL := "/api/v1/systems/:system_id/expressions/validate"
+	body := map[string]interface{}{
+		"action": map[string]string{"id": "edit"},
+		"expression": `[{"system": "bk_test", "type": "app", "expression": ` +
+			`{"StringEquals": {"id": ["a1"]}}}]`,
+	}
+
+	newPatches := func(arts []types.ActionResourceType, err error) *gomonkey.Patches {
+		return gomonkey.ApplyFunc(pip.GetActionDetail, func(
+			system, id string,
+		) (int64, []types.ActionResourceType, error) {
+			return 1, arts, err
+		})
+	}
+
+	t.Run("bad request without expression", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, ValidateExpression, handlerURL)(t).JSON(map[string]interface{}{
+			"action": body["action"],
+		}).BadRequestContainsMessage("Expression")
+	})
+
+	t.Run("action not found", func(t *testing.T) {
+		patches := newPatches(nil, sql.ErrNoRows)
+		defer patches.Reset()
+
+		r := gin.Default()
+		r.POST(handlerURL, ValidateExpression)
+		apitest.New().
+			Handler(r).
+			Post(url).
+			JSON(body).
+			Expect(t).
+			Assert(util.NewResponseAssertFunc(t, func(resp util.Response) error {
+				assert.Equal(t, util.NotFoundError, resp.Code)
+				return nil
+			})).
+			Status(http.StatusOK).
+			End()
+	})
+
+	t.Run("get action detail fail", func(t *testing.T) {
+		patches := newPatches(nil, errors.New("get action detail fail"))
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, ValidateExpression, handlerURL)(t).JSON(body).SystemError()
+	})
+
+	t.Run("get attribute schema fail", func(t *testing.T) {
+		patches := newPatches([]types.ActionResourceType{{System: "bk_test", Type: "app"}}, nil)
+		defer patches.Reset()
+		patches.ApplyFunc(pip.GetResourceAttributeSchema, func(system, _type string) (map[string]string, error) {
+			return nil, errors.New("get schema fail")
+		})
+
+		util.CreateNewAPIRequestFunc("post", url, ValidateExpression, handlerURL)(t).JSON(body).SystemError()
+	})
+
+	t.Run("attribute type not match the schema", func(t *testing.T) {
+		patches := newPatches([]types.ActionResourceType{{System: "bk_test", Type: "app"}}, nil)
+		defer patches.Reset()
+		patches.ApplyFunc(pip.GetResourceAttributeSchema, func(system, _type string) (map[string]string, error) {
+			return map[string]string{"level": "numeric"}, nil
+		})
+
+		r := gin.Default()
+		r.POST(handlerURL, ValidateExpression)
+		apitest.New().
+			Handler(r).
+			Post(url).
+			JSON(map[string]interface{}{
+				"action": body["action"],
+				"expression": `[{"system": "bk_test", "type": "app", "expression": ` +
+					`{"StringEquals": {"level": ["1"]}}}]`,
+			}).
+			Expect(t).
+			Assert(util.NewResponseAssertFunc(t, func(resp util.Response) error {
+				assert.Equal(t, util.NoError, resp.Code)
+				data := resp.Data.(map[string]interface{})
+				assert.Equal(t, false, data["valid"])
+				assert.Contains(t, data["errors"].([]interface{})[0].(map[string]interface{})["message"],
+					"attribute `level` is `numeric`")
+				return nil
+			})).
+			Status(http.StatusOK).
+			End()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := newPatches([]types.ActionResourceType{{System: "bk_test", Type: "app"}}, nil)
+		defer patches.Reset()
+		patches.ApplyFunc(pip.GetResourceAttributeSchema, func(system, _type string) (map[string]string, error) {
+			return map[string]string{}, nil
+		})
+
+		util.CreateNewAPIRequestFunc("post", url, ValidateExpression, handlerURL)(t).JSON(body).OK()
+	})
+}
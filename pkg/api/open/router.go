@@ -32,4 +32,24 @@ func Register(r *gin.RouterGroup) {
 		// GET /api/v1/systems/:system/policies/-/subjects?ids=1,2,3,4
 		policies.GET("/:policy_id/subjects", handler.Subjects)
 	}
+
+	auth := r.Group("/:system_id/auth")
+	auth.Use(common.SystemExistsAndClientValid())
+	{
+		// POST /api/v1/systems/:system/auth/resources  同一个subject/action, 批量对多个资源实例鉴权
+		auth.POST("/resources", handler.BatchAuthByResources)
+	}
+
+	expressions := r.Group("/:system_id/expressions")
+	expressions.Use(common.SystemExistsAndClientValid())
+	{
+		// POST /api/v1/systems/:system/expressions/validate  校验策略表达式
+		expressions.POST("/validate", handler.ValidateExpression)
+
+		// POST /api/v1/systems/:system/expressions/build  根据结构化的过滤条件构造策略表达式
+		expressions.POST("/build", handler.BuildExpression)
+
+		// POST /api/v1/systems/:system/expressions/eval  沙箱求值: 使用模拟的资源属性对表达式求值
+		expressions.POST("/eval", handler.EvalExpression)
+	}
 }
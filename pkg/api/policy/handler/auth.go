@@ -12,11 +12,11 @@ package handler
 
 import (
 	"errors"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"iam/pkg/abac/pdp"
-	"iam/pkg/abac/pdp/translate"
 	"iam/pkg/abac/types"
 	"iam/pkg/abac/types/request"
 	"iam/pkg/errorx"
@@ -46,6 +46,12 @@ func Auth(c *gin.Context) {
 		return
 	}
 
+	env, err := newRequestEnvironment(c, &body.Environment)
+	if err != nil {
+		util.BadRequestErrorJSONResponse(c, err.Error())
+		return
+	}
+
 	// check system
 	systemID := body.System
 	clientID := util.GetClientID(c)
@@ -70,6 +76,8 @@ func Auth(c *gin.Context) {
 	// 隔离结构体
 	var req = request.NewRequest()
 	copyRequestFromAuthBody(req, &body)
+	req.Env = env
+	req.Ctx = c.Request.Context()
 
 	// 鉴权
 	var entry *debug.Entry
@@ -83,6 +91,10 @@ func Auth(c *gin.Context) {
 	allowed, err := pdp.Eval(req, entry, isForce)
 	debug.WithError(entry, err)
 	if err != nil {
+		if errors.Is(err, pdp.ErrTooManyEvaluations) {
+			util.TooManyRequestsJSONResponse(c, err.Error())
+			return
+		}
 		if errors.Is(err, pdp.ErrInvalidAction) {
 			util.BadRequestErrorJSONResponse(c, err.Error())
 			return
@@ -121,6 +133,12 @@ func BatchAuthByActions(c *gin.Context) {
 		return
 	}
 
+	env, err := newRequestEnvironment(c, &body.Environment)
+	if err != nil {
+		util.BadRequestErrorJSONResponse(c, err.Error())
+		return
+	}
+
 	// check system
 	systemID := body.System
 	clientID := util.GetClientID(c)
@@ -156,34 +174,36 @@ func BatchAuthByActions(c *gin.Context) {
 
 	_, isForce := c.GetQuery("force")
 
-	// 查询  subject-system-action的policies, 然后执行鉴权!
+	// 查询  subject-system-action的policies, 然后执行鉴权! subject的属性只查询一次
+	req := request.NewRequest()
+	copyRequestFromAuthByActionsBody(req, &body)
+	req.Env = env
+	req.Ctx = c.Request.Context()
+
+	actionIDs := make([]string, 0, len(body.Actions))
 	for _, action := range body.Actions {
-		req := request.NewRequest()
-		copyRequestFromAuthByActionsBody(req, &body)
-		req.Action.ID = action.ID
+		actionIDs = append(actionIDs, action.ID)
+	}
 
-		var subEntry *debug.Entry
-		if isDebug {
-			// NOTE: no need to call EntryPool.Put here, the global entry will do the put
-			subEntry = debug.EntryPool.Get()
+	results, err := pdp.BatchEvalActions(req, actionIDs, entry, isForce)
+	debug.WithError(entry, err)
+	if err != nil {
+		if errors.Is(err, pdp.ErrTooManyEvaluations) {
+			util.TooManyRequestsJSONResponse(c, err.Error())
+			return
 		}
-
-		allowed, err := pdp.Eval(req, subEntry, isForce)
-		debug.WithError(subEntry, err)
-		if err != nil {
-			if errors.Is(err, pdp.ErrInvalidAction) {
-				util.BadRequestErrorJSONResponse(c, err.Error())
-				return
-			}
-
-			err = errorWrapf(err, "systemID=`%s`, body=`%+v`", systemID, body)
-			util.SystemErrorJSONResponseWithDebug(c, err, entry)
+		if errors.Is(err, pdp.ErrInvalidAction) {
+			util.BadRequestErrorJSONResponse(c, err.Error())
 			return
 		}
 
-		result[action.ID] = allowed
+		err = errorWrapf(err, "systemID=`%s`, body=`%+v`", systemID, body)
+		util.SystemErrorJSONResponseWithDebug(c, err, entry)
+		return
+	}
 
-		debug.AddSubDebug(entry, subEntry)
+	for actionID, allowed := range results {
+		result[actionID] = allowed
 	}
 
 	util.SuccessJSONResponseWithDebug(c, "ok", result, entry)
@@ -211,6 +231,12 @@ func BatchAuthByResources(c *gin.Context) {
 		return
 	}
 
+	env, err := newRequestEnvironment(c, &body.Environment)
+	if err != nil {
+		util.BadRequestErrorJSONResponse(c, err.Error())
+		return
+	}
+
 	// check system
 	systemID := body.System
 	clientID := util.GetClientID(c)
@@ -240,6 +266,8 @@ func BatchAuthByResources(c *gin.Context) {
 	// 隔离结构体
 	var req = request.NewRequest()
 	copyRequestFromAuthByResourcesBody(req, &body)
+	req.Env = env
+	req.Ctx = c.Request.Context()
 
 	// 鉴权
 	var entry *debug.Entry
@@ -254,6 +282,10 @@ func BatchAuthByResources(c *gin.Context) {
 	policies, err := pdp.QueryAuthPolicies(req, entry, isForce)
 	if err != nil {
 		debug.WithError(entry, err)
+		if errors.Is(err, pdp.ErrTooManyEvaluations) {
+			util.TooManyRequestsJSONResponse(c, err.Error())
+			return
+		}
 		if errors.Is(err, pdp.ErrInvalidAction) {
 			util.BadRequestErrorJSONResponse(c, err.Error())
 			return
@@ -305,7 +337,7 @@ func BatchAuthByResources(c *gin.Context) {
 		debug.WithValue(entry, "expression", "set fail")
 		queryResourceTypes, err := req.Action.Attribute.GetResourceTypes()
 		if err == nil {
-			expr, err := translate.PoliciesTranslate(policies, queryResourceTypes)
+			expr, err := pdp.TranslatePolicies(req.System, policies, queryResourceTypes)
 			if err == nil {
 				debug.WithValue(entry, "expression", expr)
 			}
@@ -314,3 +346,175 @@ func BatchAuthByResources(c *gin.Context) {
 
 	util.SuccessJSONResponseWithDebug(c, "ok", data, entry)
 }
+
+// BatchAuthWarm godoc
+// @Summary batch auth and warm the cache/页面批量鉴权并预热缓存
+// @Description eval the (action, resources) pairs a page will render, and warm the cache for the per-button auth later
+// @ID api-policy-batch-auth-warm
+// @Tags policy
+// @Accept json
+// @Produce json
+// @Param body body authWarmRequest true "the batch auth warm request"
+// @Success 200 {object} authWarmResponse
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/policy/auth_warm [post]
+func BatchAuthWarm(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "BatchAuthWarm")
+
+	var body authWarmRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	env, err := newRequestEnvironment(c, &body.Environment)
+	if err != nil {
+		util.BadRequestErrorJSONResponse(c, err.Error())
+		return
+	}
+
+	// check system
+	systemID := body.System
+	clientID := util.GetClientID(c)
+	if err := ValidateSystemMatchClient(systemID, clientID); err != nil {
+		util.BadRequestErrorJSONResponse(c, err.Error())
+		return
+	}
+
+	data := authWarmResponse{
+		Results: make([]authWarmResult, 0, len(body.Items)),
+		Hint: authWarmHint{
+			WarmedActions: []string{},
+		},
+	}
+	hintExpiresIn := int64(authWarmHintExpiration() / time.Second)
+
+	// super admin and system admin
+	hasSuperPerm, err := hasSystemSuperPermission(systemID, body.Subject.Type, body.Subject.ID)
+	if err != nil {
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	if hasSuperPerm {
+		for _, item := range body.Items {
+			data.Results = append(data.Results, authWarmResult{
+				Action:     actionInResponse{ID: item.Action.ID},
+				ResourceID: buildResourceID(item.Resources),
+				Allowed:    true,
+			})
+		}
+
+		// 超级权限来自subject角色的本地缓存, 同样可以作为预热的结果
+		if hintExpiresIn > 0 {
+			data.Hint.WarmedActions = distinctAuthWarmActions(body.Items)
+			data.Hint.ExpiresIn = hintExpiresIn
+		}
+		util.SuccessJSONResponse(c, "ok", data)
+		return
+	}
+
+	var entry *debug.Entry
+	_, isDebug := c.GetQuery("debug")
+	if isDebug {
+		entry = debug.EntryPool.Get()
+		defer debug.EntryPool.Put(entry)
+	}
+	_, isForce := c.GetQuery("force")
+
+	// 同一个action只查询一次策略, 查询的同时会预热 action/subject/policy 的缓存
+	actionPolicies := make(map[string][]types.AuthPolicy, len(body.Items))
+	for _, item := range body.Items {
+		if _, ok := actionPolicies[item.Action.ID]; ok {
+			continue
+		}
+
+		req := request.NewRequest()
+		copyRequestFromAuthWarmBody(req, &body)
+		req.Env = env
+		req.Ctx = c.Request.Context()
+		req.Action.ID = item.Action.ID
+
+		var subEntry *debug.Entry
+		if isDebug {
+			// NOTE: no need to call EntryPool.Put here, the global entry will do the put
+			subEntry = debug.EntryPool.Get()
+			// add before querying, the sub entry shares the timing of the entry
+			debug.AddSubDebug(entry, subEntry)
+		}
+
+		policies, err := pdp.QueryAuthPolicies(req, subEntry, isForce)
+		if err != nil {
+			debug.WithError(subEntry, err)
+			if errors.Is(err, pdp.ErrTooManyEvaluations) {
+				util.TooManyRequestsJSONResponse(c, err.Error())
+				return
+			}
+			if errors.Is(err, pdp.ErrInvalidAction) {
+				util.BadRequestErrorJSONResponse(c, err.Error())
+				return
+			}
+
+			// no permission => all the items of the action are not allowed
+			if !errors.Is(err, pdp.ErrSubjectNotExists) && !errors.Is(err, pdp.ErrNoPolicies) {
+				err = errorWrapf(err, "systemID=`%s`, body=`%+v`", systemID, body)
+				util.SystemErrorJSONResponseWithDebug(c, err, entry)
+				return
+			}
+		}
+
+		actionPolicies[item.Action.ID] = policies
+
+		// 策略为空也会被缓存, 同样是预热的结果; subject不存在时没有可以缓存的策略
+		if !errors.Is(err, pdp.ErrSubjectNotExists) {
+			data.Hint.WarmedActions = append(data.Hint.WarmedActions, item.Action.ID)
+		}
+	}
+
+	// force模式下跳过了缓存, 没有预热
+	if !isForce && hintExpiresIn > 0 && len(data.Hint.WarmedActions) > 0 {
+		data.Hint.ExpiresIn = hintExpiresIn
+	} else {
+		data.Hint.WarmedActions = []string{}
+	}
+
+	// do eval for each item
+	for _, item := range body.Items {
+		allowed := false
+
+		policies := actionPolicies[item.Action.ID]
+		if len(policies) > 0 {
+			req := request.NewRequest()
+			copyRequestFromAuthWarmBody(req, &body)
+			req.Env = env
+			req.Ctx = c.Request.Context()
+			req.Action.ID = item.Action.ID
+			req.Resources = make([]types.Resource, 0, len(item.Resources))
+			for _, resource := range item.Resources {
+				req.Resources = append(req.Resources, types.Resource{
+					System:    resource.System,
+					Type:      resource.Type,
+					ID:        resource.ID,
+					Attribute: resource.Attribute,
+				})
+			}
+
+			allowed, err = pdp.EvalPolicies(req, policies)
+			if err != nil {
+				err = errorWrapf(err, "pdp.EvalPolicies req=`%+v`, policies=`%+v` fail", req, policies)
+				util.SystemErrorJSONResponseWithDebug(c, err, entry)
+				return
+			}
+		}
+
+		data.Results = append(data.Results, authWarmResult{
+			Action:     actionInResponse{ID: item.Action.ID},
+			ResourceID: buildResourceID(item.Resources),
+			Allowed:    allowed,
+		})
+	}
+
+	util.SuccessJSONResponseWithDebug(c, "ok", data, entry)
+}
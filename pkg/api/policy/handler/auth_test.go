@@ -0,0 +1,250 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/steinfletcher/apitest"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/logging/debug"
+	"iam/pkg/util"
+)
+
+func TestBatchAuthWarm(t *testing.T) {
+	url := "/api/v1/policy/auth_warm"
+	body := map[string]interface{}{
+		"system":  "bk_test",
+		"subject": map[string]string{"type": "user", "id": "tom"},
+		"items": []map[string]interface{}{
+			{
+				"action": map[string]string{"id": "edit"},
+				"resources": []map[string]interface{}{
+					{"system": "bk_test", "type": "app", "id": "a1", "attribute": map[string]interface{}{}},
+				},
+			},
+		},
+	}
+
+	newPatchesWithHint := func(queryErr error, hasSuperPerm bool, hintExpiration time.Duration) *gomonkey.Patches {
+		patches := gomonkey.ApplyFunc(ValidateSystemMatchClient, func(systemID, clientID string) error {
+			return nil
+		})
+		patches.ApplyFunc(hasSystemSuperPermission, func(systemID, _type, id string) (bool, error) {
+			return hasSuperPerm, nil
+		})
+		patches.ApplyFunc(authWarmHintExpiration, func() time.Duration {
+			return hintExpiration
+		})
+		patches.ApplyFunc(pdp.QueryAuthPolicies,
+			func(r *request.Request, entry *debug.Entry, withoutCache bool) ([]types.AuthPolicy, error) {
+				return nil, queryErr
+			})
+		return patches
+	}
+	newPatches := func(queryErr error) *gomonkey.Patches {
+		return newPatchesWithHint(queryErr, false, time.Minute)
+	}
+
+	t.Run("bad request without items", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthWarm)(t).JSON(map[string]interface{}{
+			"system":  "bk_test",
+			"subject": map[string]string{"type": "user", "id": "tom"},
+		}).BadRequestContainsMessage("Items")
+	})
+
+	t.Run("bad request invalid environment tz", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthWarm)(t).JSON(map[string]interface{}{
+			"system":      body["system"],
+			"subject":     body["subject"],
+			"items":       body["items"],
+			"environment": map[string]string{"tz": "Mars/Olympus"},
+		}).BadRequestContainsMessage("environment.tz")
+	})
+
+	t.Run("bad request system not match client", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthWarm)(t).JSON(body).
+			BadRequestContainsMessage("system_id or client_id do not allow empty")
+	})
+
+	t.Run("bad request invalid action", func(t *testing.T) {
+		patches := newPatches(pdp.ErrInvalidAction)
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthWarm)(t).JSON(body).BadRequestContainsMessage(pdp.ErrInvalidAction.Error())
+	})
+
+	t.Run("query policies fail", func(t *testing.T) {
+		patches := newPatches(errors.New("query fail"))
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthWarm)(t).JSON(body).SystemError()
+	})
+
+	t.Run("ok, no policies", func(t *testing.T) {
+		patches := newPatches(pdp.ErrNoPolicies)
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthWarm)(t).JSON(body).OK()
+	})
+
+	assertHint := func(t *testing.T, query map[string]string, warmedActions []interface{}, expiresIn float64) {
+		r := util.SetupRouter()
+		r.POST(url, BatchAuthWarm)
+		apitest.New().
+			Handler(r).
+			Post(url).
+			QueryParams(query).
+			JSON(body).
+			Expect(t).
+			Assert(util.NewResponseAssertFunc(t, func(resp util.Response) error {
+				assert.Equal(t, util.NoError, resp.Code)
+				assert.Equal(t, map[string]interface{}{
+					"warmed_actions": warmedActions,
+					"expires_in":     expiresIn,
+				}, resp.Data.(map[string]interface{})["hint"])
+				return nil
+			})).
+			Status(http.StatusOK).
+			End()
+	}
+
+	t.Run("ok, hint the warmed actions", func(t *testing.T) {
+		patches := newPatches(nil)
+		defer patches.Reset()
+
+		assertHint(t, map[string]string{}, []interface{}{"edit"}, 60)
+	})
+
+	t.Run("ok, no hint with force", func(t *testing.T) {
+		patches := newPatches(nil)
+		defer patches.Reset()
+
+		assertHint(t, map[string]string{"force": ""}, []interface{}{}, 0)
+	})
+
+	t.Run("ok, no hint when subject not exists", func(t *testing.T) {
+		patches := newPatches(pdp.ErrSubjectNotExists)
+		defer patches.Reset()
+
+		assertHint(t, map[string]string{}, []interface{}{}, 0)
+	})
+
+	t.Run("ok, no hint when local cache disabled", func(t *testing.T) {
+		patches := newPatchesWithHint(nil, false, 0)
+		defer patches.Reset()
+
+		assertHint(t, map[string]string{}, []interface{}{}, 0)
+	})
+
+	t.Run("ok, hint with super permission", func(t *testing.T) {
+		patches := newPatchesWithHint(nil, true, time.Minute)
+		defer patches.Reset()
+
+		assertHint(t, map[string]string{}, []interface{}{"edit"}, 60)
+	})
+}
+
+func TestBatchAuthByActions(t *testing.T) {
+	url := "/api/v1/policy/auth_by_actions"
+	body := map[string]interface{}{
+		"system":  "bk_test",
+		"subject": map[string]string{"type": "user", "id": "tom"},
+		"actions": []map[string]string{{"id": "edit"}, {"id": "view"}},
+		"resources": []map[string]interface{}{
+			{"system": "bk_test", "type": "app", "id": "a1", "attribute": map[string]interface{}{}},
+		},
+	}
+
+	newPatches := func(results map[string]bool, evalErr error) *gomonkey.Patches {
+		patches := gomonkey.ApplyFunc(ValidateSystemMatchClient, func(systemID, clientID string) error {
+			return nil
+		})
+		patches.ApplyFunc(hasSystemSuperPermission, func(systemID, _type, id string) (bool, error) {
+			return false, nil
+		})
+		patches.ApplyFunc(pdp.BatchEvalActions,
+			func(r *request.Request, actionIDs []string, entry *debug.Entry, withoutCache bool) (map[string]bool, error) {
+				return results, evalErr
+			})
+		return patches
+	}
+
+	t.Run("bad request without actions", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthByActions)(t).JSON(map[string]interface{}{
+			"system":    "bk_test",
+			"subject":   map[string]string{"type": "user", "id": "tom"},
+			"resources": []map[string]interface{}{},
+		}).BadRequestContainsMessage("Actions")
+	})
+
+	t.Run("bad request invalid action", func(t *testing.T) {
+		patches := newPatches(nil, pdp.ErrInvalidAction)
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthByActions)(t).JSON(body).
+			BadRequestContainsMessage(pdp.ErrInvalidAction.Error())
+	})
+
+	t.Run("invalid action", func(t *testing.T) {
+		patches := newPatches(nil, pdp.ErrInvalidAction)
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthByActions)(t).JSON(body).BadRequestContainsMessage("action.id invalid")
+	})
+
+	t.Run("ok with environment", func(t *testing.T) {
+		var env request.Environment
+		patches := gomonkey.ApplyFunc(ValidateSystemMatchClient, func(systemID, clientID string) error {
+			return nil
+		})
+		defer patches.Reset()
+		patches.ApplyFunc(hasSystemSuperPermission, func(systemID, _type, id string) (bool, error) {
+			return false, nil
+		})
+		patches.ApplyFunc(pdp.BatchEvalActions,
+			func(r *request.Request, actionIDs []string, entry *debug.Entry, withoutCache bool) (map[string]bool, error) {
+				env = r.Env
+				return map[string]bool{"edit": true, "view": false}, nil
+			})
+
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthByActions)(t).JSON(map[string]interface{}{
+			"system":      body["system"],
+			"subject":     body["subject"],
+			"actions":     body["actions"],
+			"resources":   body["resources"],
+			"environment": map[string]string{"tz": "UTC"},
+		}).OK()
+		assert.Equal(t, "UTC", env.Time.Location().String())
+	})
+
+	t.Run("eval fail", func(t *testing.T) {
+		patches := newPatches(nil, errors.New("eval fail"))
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthByActions)(t).JSON(body).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := newPatches(map[string]bool{"edit": true, "view": false}, nil)
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BatchAuthByActions)(t).JSON(body).OK()
+	})
+}
@@ -0,0 +1,107 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/logging/debug"
+	"iam/pkg/util"
+)
+
+func TestExplain(t *testing.T) {
+	url := "/api/v1/policy/explain"
+	body := map[string]interface{}{
+		"system":  "bk_test",
+		"subject": map[string]string{"type": "user", "id": "tom"},
+		"action":  map[string]string{"id": "edit"},
+		"resources": []map[string]interface{}{
+			{"system": "bk_test", "type": "app", "id": "a1", "attribute": map[string]interface{}{}},
+		},
+	}
+
+	newPatches := func(explanation pdp.Explanation, explainErr error) *gomonkey.Patches {
+		patches := gomonkey.ApplyFunc(ValidateSystemMatchClient, func(systemID, clientID string) error {
+			return nil
+		})
+		patches.ApplyFunc(hasSystemSuperPermission, func(systemID, _type, id string) (bool, error) {
+			return false, nil
+		})
+		patches.ApplyFunc(pdp.Explain,
+			func(r *request.Request, entry *debug.Entry, withoutCache bool) (pdp.Explanation, error) {
+				return explanation, explainErr
+			})
+		return patches
+	}
+
+	t.Run("bad request without action", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, Explain)(t).JSON(map[string]interface{}{
+			"system":    "bk_test",
+			"subject":   map[string]string{"type": "user", "id": "tom"},
+			"resources": []map[string]interface{}{},
+		}).BadRequestContainsMessage("ID is required")
+	})
+
+	t.Run("bad request system not match client", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, Explain)(t).JSON(body).
+			BadRequestContainsMessage("system_id or client_id do not allow empty")
+	})
+
+	t.Run("bad request invalid action", func(t *testing.T) {
+		patches := newPatches(pdp.Explanation{}, pdp.ErrInvalidAction)
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, Explain)(t).JSON(body).
+			BadRequestContainsMessage(pdp.ErrInvalidAction.Error())
+	})
+
+	t.Run("explain fail", func(t *testing.T) {
+		patches := newPatches(pdp.Explanation{}, errors.New("explain fail"))
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, Explain)(t).JSON(body).SystemError()
+	})
+
+	t.Run("ok, super permission", func(t *testing.T) {
+		called := false
+		patches := gomonkey.ApplyFunc(ValidateSystemMatchClient, func(systemID, clientID string) error {
+			return nil
+		})
+		defer patches.Reset()
+		patches.ApplyFunc(hasSystemSuperPermission, func(systemID, _type, id string) (bool, error) {
+			return true, nil
+		})
+		patches.ApplyFunc(pdp.Explain,
+			func(r *request.Request, entry *debug.Entry, withoutCache bool) (pdp.Explanation, error) {
+				called = true
+				return pdp.Explanation{}, nil
+			})
+
+		util.CreateNewAPIRequestFunc("post", url, Explain)(t).JSON(body).OK()
+		assert.False(t, called)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := newPatches(pdp.Explanation{
+			Allowed:          true,
+			Reason:           pdp.ExplainReasonAllowPolicyMatched,
+			DecisivePolicyID: 1,
+		}, nil)
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, Explain)(t).JSON(body).OK()
+	})
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/pdp/translate"
 	"iam/pkg/abac/types"
 	"iam/pkg/abac/types/request"
 	"iam/pkg/errorx"
@@ -62,7 +63,15 @@ func Query(c *gin.Context) {
 	}
 
 	if hasSuperPerm {
-		util.SuccessJSONResponse(c, "ok", AnyExpression)
+		data, err := convertQueryExpression(AnyExpression, &body)
+		if err != nil {
+			err = errorWrapf(err, "convertQueryExpression dialect=`%s`, version=`%s`",
+				body.Dialect, body.ExpressionVersion)
+			util.SystemErrorJSONResponse(c, err)
+			return
+		}
+
+		util.SuccessJSONResponse(c, "ok", data)
 		return
 	}
 
@@ -88,6 +97,10 @@ func Query(c *gin.Context) {
 	expr, err := pdp.Query(req, entry, willCheckRemoteResource, isForce)
 	debug.WithError(entry, err)
 	if err != nil {
+		if errors.Is(err, pdp.ErrTooManyEvaluations) {
+			util.TooManyRequestsJSONResponse(c, err.Error())
+			return
+		}
 		if errors.Is(err, pdp.ErrInvalidAction) {
 			util.BadRequestErrorJSONResponse(c, err.Error())
 			return
@@ -98,7 +111,35 @@ func Query(c *gin.Context) {
 		return
 	}
 
-	util.SuccessJSONResponseWithDebug(c, "ok", expr, entry)
+	expr, err = translate.PartialEvaluate(expr, body.KnownAttributes)
+	if err != nil {
+		err = errorWrapf(err, "PartialEvaluate knownAttributes=`%+v`", body.KnownAttributes)
+		util.SystemErrorJSONResponseWithDebug(c, err, entry)
+		return
+	}
+
+	data, err := convertQueryExpression(expr, &body)
+	if err != nil {
+		// 表达式中有无法转换为ES query的条件(例如环境属性), 由调用方处理
+		if errors.Is(err, translate.ErrESUnsupportedOperator) {
+			util.BadRequestErrorJSONResponse(c, err.Error())
+			return
+		}
+
+		err = errorWrapf(err, "convertQueryExpression dialect=`%s`, version=`%s`", body.Dialect, body.ExpressionVersion)
+		util.SystemErrorJSONResponseWithDebug(c, err, entry)
+		return
+	}
+
+	util.SuccessJSONResponseWithDebug(c, "ok", data, entry)
+}
+
+// convertQueryExpression 按请求的查询语言或表达式版本转换返回的表达式
+func convertQueryExpression(expr map[string]interface{}, body *queryRequest) (interface{}, error) {
+	if body.Dialect == queryDialectES {
+		return translate.PoliciesTranslateToES(expr, body.ESFieldMapping)
+	}
+	return translate.ConvertExpressionVersion(expr, body.ExpressionVersion)
 }
 
 // BatchQueryByActions godoc
@@ -140,10 +181,17 @@ func BatchQueryByActions(c *gin.Context) {
 	}
 
 	if hasSuperPerm {
+		expr, err := translate.ConvertExpressionVersion(AnyExpression, body.ExpressionVersion)
+		if err != nil {
+			err = errorWrapf(err, "ConvertExpressionVersion version=`%s`", body.ExpressionVersion)
+			util.SystemErrorJSONResponse(c, err)
+			return
+		}
+
 		for _, action := range body.Actions {
 			policies = append(policies, actionPoliciesResponse{
 				Action:    actionInResponse(action),
-				Condition: AnyExpression,
+				Condition: expr,
 			})
 		}
 		util.SuccessJSONResponse(c, "ok", policies)
@@ -160,23 +208,33 @@ func BatchQueryByActions(c *gin.Context) {
 
 	_, isForce := c.GetQuery("force")
 
-	// TODO: 这里, subject/resource都是一致的, 只是action是多个, 所以其中pdp.Query会存在重复查询/重复计算?
+	req := request.NewRequest()
+	copyRequestFromQueryByActionsBody(req, &body)
+
+	actionIDs := make([]string, 0, len(body.Actions))
 	for _, action := range body.Actions {
-		req := request.NewRequest()
-		copyRequestFromQueryByActionsBody(req, &body)
-		req.Action.ID = action.ID
-
-		var subEntry *debug.Entry
-		if isDebug {
-			// NOTE: no need to call EntryPool.Put here, the global entry will do the put
-			subEntry = debug.EntryPool.Get()
+		actionIDs = append(actionIDs, action.ID)
+	}
+
+	// NOTE: subject/resource都是一致的, 只是action是多个, subject的属性只查询一次
+	exprs, err := pdp.BatchQueryByActions(req, actionIDs, entry, true, isForce)
+	debug.WithError(entry, err)
+	if err != nil {
+		if errors.Is(err, pdp.ErrTooManyEvaluations) {
+			util.TooManyRequestsJSONResponse(c, err.Error())
+			return
 		}
 
-		expr, err := pdp.Query(req, subEntry, true, isForce)
-		debug.WithError(subEntry, err)
+		err = errorWrapf(err, "systemID=`%s`, body=`%+v`", systemID, body)
+		util.SystemErrorJSONResponseWithDebug(c, err, entry)
+		return
+	}
+
+	for _, action := range body.Actions {
+		expr, err := translate.ConvertExpressionVersion(exprs[action.ID], body.ExpressionVersion)
 		if err != nil {
-			err = errorWrapf(err, "systemID=`%s`, request.Action.ID=`%s`, body=`%+v`", systemID, action.ID, body)
-			util.SystemErrorJSONResponseWithDebug(c, err, subEntry)
+			err = errorWrapf(err, "ConvertExpressionVersion version=`%s`", body.ExpressionVersion)
+			util.SystemErrorJSONResponseWithDebug(c, err, entry)
 			return
 		}
 
@@ -184,8 +242,6 @@ func BatchQueryByActions(c *gin.Context) {
 			Action:    actionInResponse(action),
 			Condition: expr,
 		})
-
-		debug.AddSubDebug(entry, subEntry)
 	}
 
 	util.SuccessJSONResponseWithDebug(c, "ok", policies, entry)
@@ -250,8 +306,15 @@ func QueryByExtResources(c *gin.Context) {
 			extResourcesWithAttr = append(extResourcesWithAttr, extResourceWithAttr)
 		}
 
+		expr, err := translate.ConvertExpressionVersion(AnyExpression, body.ExpressionVersion)
+		if err != nil {
+			err = errorWrapf(err, "ConvertExpressionVersion version=`%s`", body.ExpressionVersion)
+			util.SystemErrorJSONResponse(c, err)
+			return
+		}
+
 		util.SuccessJSONResponse(c, "ok", map[string]interface{}{
-			"expression":    AnyExpression,
+			"expression":    expr,
 			"ext_resources": extResourcesWithAttr,
 		})
 		return
@@ -260,6 +323,7 @@ func QueryByExtResources(c *gin.Context) {
 	// 隔离结构体
 	var req = request.NewRequest()
 	copyRequestFromQueryBody(req, &body.queryRequest)
+	req.Ctx = c.Request.Context()
 
 	var entry *debug.Entry
 	if _, isDebug := c.GetQuery("debug"); isDebug {
@@ -282,11 +346,30 @@ func QueryByExtResources(c *gin.Context) {
 	expr, extResourcesWithAttr, err := pdp.QueryByExtResources(req, extResources, entry, isForce)
 	debug.WithError(entry, err)
 	if err != nil {
+		if errors.Is(err, pdp.ErrTooManyEvaluations) {
+			util.TooManyRequestsJSONResponse(c, err.Error())
+			return
+		}
+
 		err = errorWrapf(err, "systemID=`%s`, body=`%+v`", systemID, body)
 		util.SystemErrorJSONResponseWithDebug(c, err, entry)
 		return
 	}
 
+	expr, err = translate.PartialEvaluate(expr, body.KnownAttributes)
+	if err != nil {
+		err = errorWrapf(err, "PartialEvaluate knownAttributes=`%+v`", body.KnownAttributes)
+		util.SystemErrorJSONResponseWithDebug(c, err, entry)
+		return
+	}
+
+	expr, err = translate.ConvertExpressionVersion(expr, body.ExpressionVersion)
+	if err != nil {
+		err = errorWrapf(err, "ConvertExpressionVersion version=`%s`", body.ExpressionVersion)
+		util.SystemErrorJSONResponseWithDebug(c, err, entry)
+		return
+	}
+
 	util.SuccessJSONResponseWithDebug(c, "ok", gin.H{
 		"expression":    expr,
 		"ext_resources": extResourcesWithAttr,
@@ -0,0 +1,121 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/pdp/translate"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/errorx"
+	"iam/pkg/logging/debug"
+	"iam/pkg/util"
+)
+
+// QuerySQL godoc
+// @Summary policy query sql/策略查询, 返回SQL WHERE子句
+// @Description query the policy like query, and translate the expression to a parameterized sql where clause by the field mapping
+// @ID api-policy-query-sql
+// @Tags policy
+// @Accept json
+// @Produce json
+// @Param body body querySQLRequest true "the policy request"
+// @Success 200 {object} util.Response{data=translate.SQLWhere}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/policy/query_sql [post]
+func QuerySQL(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "QuerySQL")
+
+	var body querySQLRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	// check system
+	systemID := body.System
+	clientID := util.GetClientID(c)
+	if err := ValidateSystemMatchClient(systemID, clientID); err != nil {
+		util.BadRequestErrorJSONResponse(c, err.Error())
+		return
+	}
+
+	hasSuperPerm, err := hasSystemSuperPermission(systemID, body.Subject.Type, body.Subject.ID)
+	if err != nil {
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	var expr map[string]interface{}
+	var entry *debug.Entry
+	if hasSuperPerm {
+		expr = AnyExpression
+	} else {
+		// 隔离结构体
+		var req = request.NewRequest()
+		copyRequestFromQueryBody(req, &body.queryRequest)
+
+		if _, isDebug := c.GetQuery("debug"); isDebug {
+			entry = debug.EntryPool.Get()
+			defer debug.EntryPool.Put(entry)
+		}
+
+		_, isForce := c.GetQuery("force")
+
+		// 如果传的筛选的资源实例为空, 则不判断外部依赖资源是否满足
+		willCheckRemoteResource := len(req.Resources) != 0
+
+		expr, err = pdp.Query(req, entry, willCheckRemoteResource, isForce)
+		debug.WithError(entry, err)
+		if err != nil {
+			if errors.Is(err, pdp.ErrTooManyEvaluations) {
+				util.TooManyRequestsJSONResponse(c, err.Error())
+				return
+			}
+			if errors.Is(err, pdp.ErrInvalidAction) {
+				util.BadRequestErrorJSONResponse(c, err.Error())
+				return
+			}
+
+			err = errorWrapf(err, "systemID=`%s`, body=`%+v`", systemID, body)
+			util.SystemErrorJSONResponseWithDebug(c, err, entry)
+			return
+		}
+
+		expr, err = translate.PartialEvaluate(expr, body.KnownAttributes)
+		if err != nil {
+			err = errorWrapf(err, "PartialEvaluate knownAttributes=`%+v`", body.KnownAttributes)
+			util.SystemErrorJSONResponseWithDebug(c, err, entry)
+			return
+		}
+	}
+
+	where, err := translate.PoliciesTranslateToSQL(expr, body.FieldMapping)
+	if err != nil {
+		// 字段映射缺失/不合法, 或表达式中有无法转换为SQL的条件(例如环境属性), 由调用方处理
+		if errors.Is(err, translate.ErrSQLFieldNotMapped) || errors.Is(err, translate.ErrSQLInvalidColumn) ||
+			errors.Is(err, translate.ErrSQLUnsupportedOperator) {
+			util.BadRequestErrorJSONResponse(c, err.Error())
+			return
+		}
+
+		err = errorWrapf(err, "translate.PoliciesTranslateToSQL expr=`%+v` fail", expr)
+		util.SystemErrorJSONResponseWithDebug(c, err, entry)
+		return
+	}
+
+	util.SuccessJSONResponseWithDebug(c, "ok", where, entry)
+}
@@ -0,0 +1,113 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/logging/debug"
+	"iam/pkg/util"
+)
+
+func TestQuerySQL(t *testing.T) {
+	url := "/api/v1/policy/query_sql"
+	body := map[string]interface{}{
+		"system":        "bk_test",
+		"subject":       map[string]string{"type": "user", "id": "tom"},
+		"action":        map[string]string{"id": "edit"},
+		"resources":     []map[string]interface{}{},
+		"field_mapping": map[string]string{"app.id": "id"},
+	}
+
+	newPatches := func(expr map[string]interface{}, queryErr error) *gomonkey.Patches {
+		patches := gomonkey.ApplyFunc(ValidateSystemMatchClient, func(systemID, clientID string) error {
+			return nil
+		})
+		patches.ApplyFunc(hasSystemSuperPermission, func(systemID, _type, id string) (bool, error) {
+			return false, nil
+		})
+		patches.ApplyFunc(pdp.Query,
+			func(r *request.Request, entry *debug.Entry, willCheckRemoteResource, withoutCache bool,
+			) (map[string]interface{}, error) {
+				return expr, queryErr
+			})
+		return patches
+	}
+
+	t.Run("bad request without field_mapping", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, QuerySQL)(t).JSON(map[string]interface{}{
+			"system":  "bk_test",
+			"subject": map[string]string{"type": "user", "id": "tom"},
+			"action":  map[string]string{"id": "edit"},
+		}).BadRequestContainsMessage("FieldMapping is required")
+	})
+
+	t.Run("bad request system not match client", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, QuerySQL)(t).JSON(body).
+			BadRequestContainsMessage("system_id or client_id do not allow empty")
+	})
+
+	t.Run("query fail", func(t *testing.T) {
+		patches := newPatches(nil, errors.New("query fail"))
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, QuerySQL)(t).JSON(body).SystemError()
+	})
+
+	t.Run("bad request field not mapped", func(t *testing.T) {
+		patches := newPatches(map[string]interface{}{
+			"op":    "eq",
+			"field": "app.owner",
+			"value": "tom",
+		}, nil)
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, QuerySQL)(t).JSON(body).
+			BadRequestContainsMessage("app.owner")
+	})
+
+	t.Run("ok, super permission", func(t *testing.T) {
+		called := false
+		patches := gomonkey.ApplyFunc(ValidateSystemMatchClient, func(systemID, clientID string) error {
+			return nil
+		})
+		defer patches.Reset()
+		patches.ApplyFunc(hasSystemSuperPermission, func(systemID, _type, id string) (bool, error) {
+			return true, nil
+		})
+		patches.ApplyFunc(pdp.Query,
+			func(r *request.Request, entry *debug.Entry, willCheckRemoteResource, withoutCache bool,
+			) (map[string]interface{}, error) {
+				called = true
+				return nil, nil
+			})
+
+		util.CreateNewAPIRequestFunc("post", url, QuerySQL)(t).JSON(body).OK()
+		assert.False(t, called)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := newPatches(map[string]interface{}{
+			"op":    "in",
+			"field": "app.id",
+			"value": []interface{}{"a1", "a2"},
+		}, nil)
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, QuerySQL)(t).JSON(body).OK()
+	})
+}
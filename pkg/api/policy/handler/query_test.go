@@ -0,0 +1,315 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/steinfletcher/apitest"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/logging/debug"
+	"iam/pkg/util"
+)
+
+func TestQueryExpressionVersion(t *testing.T) {
+	url := "/api/v1/policy/query"
+	newBody := func(version string) map[string]interface{} {
+		return map[string]interface{}{
+			"system":             "bk_test",
+			"subject":            map[string]string{"type": "user", "id": "tom"},
+			"action":             map[string]string{"id": "edit"},
+			"resources":          []map[string]interface{}{},
+			"expression_version": version,
+		}
+	}
+
+	newPatches := func(expr map[string]interface{}, queryErr error) *gomonkey.Patches {
+		patches := gomonkey.ApplyFunc(ValidateSystemMatchClient, func(systemID, clientID string) error {
+			return nil
+		})
+		patches.ApplyFunc(hasSystemSuperPermission, func(systemID, _type, id string) (bool, error) {
+			return false, nil
+		})
+		patches.ApplyFunc(pdp.Query,
+			func(r *request.Request, entry *debug.Entry, willCheckRemoteResource, withoutCache bool,
+			) (map[string]interface{}, error) {
+				return expr, queryErr
+			})
+		return patches
+	}
+
+	t.Run("bad request unsupported version", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, Query)(t).JSON(newBody("v0")).
+			BadRequestContainsMessage("ExpressionVersion")
+	})
+
+	t.Run("query fail", func(t *testing.T) {
+		patches := newPatches(nil, errors.New("query fail"))
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, Query)(t).JSON(newBody("v2")).SystemError()
+	})
+
+	expr := map[string]interface{}{
+		"op":    "in",
+		"field": "app.id",
+		"value": []interface{}{"a1", "a2"},
+	}
+
+	t.Run("ok, default v1", func(t *testing.T) {
+		patches := newPatches(expr, nil)
+		defer patches.Reset()
+
+		r := util.SetupRouter()
+		r.POST(url, Query)
+		apitest.New().
+			Handler(r).
+			Post(url).
+			JSON(newBody("")).
+			Expect(t).
+			Assert(util.NewResponseAssertFunc(t, func(resp util.Response) error {
+				assert.Equal(t, util.NoError, resp.Code)
+				assert.NotContains(t, resp.Data, "value_type")
+				return nil
+			})).
+			Status(http.StatusOK).
+			End()
+	})
+
+	t.Run("ok, v2", func(t *testing.T) {
+		patches := newPatches(expr, nil)
+		defer patches.Reset()
+
+		r := util.SetupRouter()
+		r.POST(url, Query)
+		apitest.New().
+			Handler(r).
+			Post(url).
+			JSON(newBody("v2")).
+			Expect(t).
+			Assert(util.NewResponseAssertFunc(t, func(resp util.Response) error {
+				assert.Equal(t, util.NoError, resp.Code)
+				assert.Equal(t, "string", resp.Data.(map[string]interface{})["value_type"])
+				return nil
+			})).
+			Status(http.StatusOK).
+			End()
+	})
+}
+
+func TestQueryKnownAttributes(t *testing.T) {
+	url := "/api/v1/policy/query"
+	body := map[string]interface{}{
+		"system":           "bk_test",
+		"subject":          map[string]string{"type": "user", "id": "tom"},
+		"action":           map[string]string{"id": "edit"},
+		"resources":        []map[string]interface{}{},
+		"known_attributes": map[string]interface{}{"app.os": "linux"},
+	}
+
+	patches := gomonkey.ApplyFunc(ValidateSystemMatchClient, func(systemID, clientID string) error {
+		return nil
+	})
+	defer patches.Reset()
+	patches.ApplyFunc(hasSystemSuperPermission, func(systemID, _type, id string) (bool, error) {
+		return false, nil
+	})
+	patches.ApplyFunc(pdp.Query,
+		func(r *request.Request, entry *debug.Entry, willCheckRemoteResource, withoutCache bool,
+		) (map[string]interface{}, error) {
+			return map[string]interface{}{
+				"op": "AND",
+				"content": []map[string]interface{}{
+					{"op": "eq", "field": "app.os", "value": "linux"},
+					{"op": "in", "field": "app.id", "value": []interface{}{"a1", "a2"}},
+				},
+			}, nil
+		})
+
+	r := util.SetupRouter()
+	r.POST(url, Query)
+	apitest.New().
+		Handler(r).
+		Post(url).
+		JSON(body).
+		Expect(t).
+		Assert(util.NewResponseAssertFunc(t, func(resp util.Response) error {
+			assert.Equal(t, util.NoError, resp.Code)
+			assert.Equal(t, map[string]interface{}{
+				"op":    "in",
+				"field": "app.id",
+				"value": []interface{}{"a1", "a2"},
+			}, resp.Data)
+			return nil
+		})).
+		Status(http.StatusOK).
+		End()
+}
+
+func TestQueryDialectES(t *testing.T) {
+	url := "/api/v1/policy/query"
+	newBody := func(dialect string) map[string]interface{} {
+		return map[string]interface{}{
+			"system":           "bk_test",
+			"subject":          map[string]string{"type": "user", "id": "tom"},
+			"action":           map[string]string{"id": "edit"},
+			"resources":        []map[string]interface{}{},
+			"dialect":          dialect,
+			"es_field_mapping": map[string]string{"app.id": "id"},
+		}
+	}
+
+	newPatches := func(expr map[string]interface{}) *gomonkey.Patches {
+		patches := gomonkey.ApplyFunc(ValidateSystemMatchClient, func(systemID, clientID string) error {
+			return nil
+		})
+		patches.ApplyFunc(hasSystemSuperPermission, func(systemID, _type, id string) (bool, error) {
+			return false, nil
+		})
+		patches.ApplyFunc(pdp.Query,
+			func(r *request.Request, entry *debug.Entry, willCheckRemoteResource, withoutCache bool,
+			) (map[string]interface{}, error) {
+				return expr, nil
+			})
+		return patches
+	}
+
+	t.Run("bad request unsupported dialect", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, Query)(t).JSON(newBody("sql")).
+			BadRequestContainsMessage("Dialect")
+	})
+
+	t.Run("bad request unsupported operator", func(t *testing.T) {
+		patches := newPatches(map[string]interface{}{
+			"op":    "hour_range",
+			"field": "env.hour",
+			"value": []interface{}{9, 18},
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, Query)(t).JSON(newBody("es")).
+			BadRequestContainsMessage("not supported in es query")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := newPatches(map[string]interface{}{
+			"op":    "in",
+			"field": "app.id",
+			"value": []interface{}{"a1", "a2"},
+		})
+		defer patches.Reset()
+
+		r := util.SetupRouter()
+		r.POST(url, Query)
+		apitest.New().
+			Handler(r).
+			Post(url).
+			JSON(newBody("es")).
+			Expect(t).
+			Assert(util.NewResponseAssertFunc(t, func(resp util.Response) error {
+				assert.Equal(t, util.NoError, resp.Code)
+				assert.Equal(t, map[string]interface{}{
+					"terms": map[string]interface{}{"id": []interface{}{"a1", "a2"}},
+				}, resp.Data)
+				return nil
+			})).
+			Status(http.StatusOK).
+			End()
+	})
+}
+
+func TestBatchQueryByActions(t *testing.T) {
+	url := "/api/v1/policy/query_by_actions"
+	body := map[string]interface{}{
+		"system":    "bk_test",
+		"subject":   map[string]string{"type": "user", "id": "tom"},
+		"actions":   []map[string]string{{"id": "edit"}, {"id": "view"}},
+		"resources": []map[string]interface{}{},
+	}
+
+	newPatches := func(exprs map[string]map[string]interface{}, queryErr error) *gomonkey.Patches {
+		patches := gomonkey.ApplyFunc(ValidateSystemMatchClient, func(systemID, clientID string) error {
+			return nil
+		})
+		patches.ApplyFunc(hasSystemSuperPermission, func(systemID, _type, id string) (bool, error) {
+			return false, nil
+		})
+		patches.ApplyFunc(pdp.BatchQueryByActions,
+			func(r *request.Request, actionIDs []string, entry *debug.Entry, willCheckRemoteResource, withoutCache bool,
+			) (map[string]map[string]interface{}, error) {
+				return exprs, queryErr
+			})
+		return patches
+	}
+
+	t.Run("query fail", func(t *testing.T) {
+		patches := newPatches(nil, errors.New("query fail"))
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BatchQueryByActions)(t).JSON(body).SystemError()
+	})
+
+	t.Run("too many evaluations", func(t *testing.T) {
+		patches := newPatches(nil, pdp.ErrTooManyEvaluations)
+		defer patches.Reset()
+
+		r := util.SetupRouter()
+		r.POST(url, BatchQueryByActions)
+		apitest.New().
+			Handler(r).
+			Post(url).
+			JSON(body).
+			Expect(t).
+			Assert(util.NewResponseAssertFunc(t, func(resp util.Response) error {
+				assert.Equal(t, util.TooManyRequests, resp.Code)
+				return nil
+			})).
+			End()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := newPatches(map[string]map[string]interface{}{
+			"edit": {"op": "eq", "field": "app.id", "value": "a1"},
+			"view": pdp.EmptyPolicies,
+		}, nil)
+		defer patches.Reset()
+
+		r := util.SetupRouter()
+		r.POST(url, BatchQueryByActions)
+		apitest.New().
+			Handler(r).
+			Post(url).
+			JSON(body).
+			Expect(t).
+			Assert(util.NewResponseAssertFunc(t, func(resp util.Response) error {
+				assert.Equal(t, util.NoError, resp.Code)
+
+				data := resp.Data.([]interface{})
+				assert.Len(t, data, 2)
+
+				edit := data[0].(map[string]interface{})
+				assert.Equal(t, map[string]interface{}{"id": "edit"}, edit["action"])
+				assert.Equal(t, "eq", edit["condition"].(map[string]interface{})["op"])
+
+				view := data[1].(map[string]interface{})
+				assert.Equal(t, map[string]interface{}{"id": "view"}, view["action"])
+				assert.Empty(t, view["condition"])
+				return nil
+			})).
+			Status(http.StatusOK).
+			End()
+	})
+}
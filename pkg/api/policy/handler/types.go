@@ -46,6 +46,12 @@ type extResource struct {
 	IDs    []string `json:"ids" binding:"required,gt=0"`
 }
 
+// environment 调用方传入的环境属性, 用于env.*表达式求值
+type environment struct {
+	// TZ 时区, 影响env.hour/env.weekday的计算, 为空时使用服务端时区
+	TZ string `json:"tz" binding:"omitempty" example:"Asia/Shanghai"`
+}
+
 type baseRequest struct {
 	System  string  `json:"system" binding:"required" example:"bk_paas"`
 	Subject subject `json:"subject" binding:"required"`
@@ -55,8 +61,9 @@ type baseRequest struct {
 type authRequest struct {
 	baseRequest
 	// required
-	Resources []resource `json:"resources" binding:"required"`
-	Action    action     `json:"action" binding:"required"`
+	Resources   []resource  `json:"resources" binding:"required"`
+	Action      action      `json:"action" binding:"required"`
+	Environment environment `json:"environment" binding:"omitempty"`
 }
 
 type authResponse struct {
@@ -68,8 +75,9 @@ type authResponse struct {
 type authByActionsRequest struct {
 	baseRequest
 	// can't be empty
-	Resources []resource `json:"resources" binding:"required"`
-	Actions   []action   `json:"actions" binding:"required,max=10"`
+	Resources   []resource  `json:"resources" binding:"required"`
+	Actions     []action    `json:"actions" binding:"required,max=10"`
+	Environment environment `json:"environment" binding:"omitempty"`
 }
 
 type authByActionsResponse map[string]bool
@@ -80,16 +88,67 @@ type authByResourcesRequest struct {
 	baseRequest
 	Action        action       `json:"action" binding:"required"`
 	ResourcesList [][]resource `json:"resources_list" binding:"required,max=100"`
+	Environment   environment  `json:"environment" binding:"omitempty"`
 }
 
 type authByResourcesResponse map[string]bool
 
+// ======= auth warm
+
+type authWarmItem struct {
+	Action    action     `json:"action" binding:"required"`
+	Resources []resource `json:"resources" binding:"required"`
+}
+
+type authWarmRequest struct {
+	baseRequest
+	Items       []authWarmItem `json:"items" binding:"required,gt=0,max=200"`
+	Environment environment    `json:"environment" binding:"omitempty"`
+}
+
+type authWarmResult struct {
+	Action     actionInResponse `json:"action"`
+	ResourceID string           `json:"resource_id" example:"bk_paas,app,framework"`
+	Allowed    bool             `json:"allowed" example:"false"`
+}
+
+// authWarmHint 告知调用方: 在ExpiresIn秒内, 对WarmedActions的单个鉴权请求会直接命中服务端的本地缓存
+type authWarmHint struct {
+	WarmedActions []string `json:"warmed_actions"`
+	ExpiresIn     int64    `json:"expires_in" example:"60"`
+}
+
+type authWarmResponse struct {
+	Results []authWarmResult `json:"results"`
+	Hint    authWarmHint     `json:"hint"`
+}
+
 // ====== query
 type queryRequest struct {
 	baseRequest
 	// can be empty
 	Resources []resource `json:"resources" binding:"omitempty"`
 	Action    action     `json:"action" binding:"required"`
+	// 返回的表达式格式版本, 为空时为v1; NOTE: query_sql返回的是SQL, 不受影响
+	ExpressionVersion string `json:"expression_version" binding:"omitempty,oneof=v1 v2" example:"v1"`
+	// 调用方已知的资源属性, key为表达式中的field, 例如 {"host.os": "linux"}; 相关的条件会被直接计算, 只返回未决的条件
+	KnownAttributes map[string]interface{} `json:"known_attributes" binding:"omitempty"`
+	// 返回的查询语言, 为空时返回条件表达式; es返回Elasticsearch的bool query, 此时expression_version不生效
+	// NOTE: query_sql返回的是SQL, 不受影响
+	Dialect string `json:"dialect" binding:"omitempty,oneof=es" example:"es"`
+	// dialect=es时, 表达式中的字段到ES字段的映射, 没有映射的字段原样返回, 例如 {"host.id": "id"}
+	ESFieldMapping map[string]string `json:"es_field_mapping" binding:"omitempty"`
+}
+
+// queryDialectES 策略查询返回Elasticsearch的bool query
+const queryDialectES = "es"
+
+// ======= query sql
+
+type querySQLRequest struct {
+	queryRequest
+	// 表达式中的字段到数据库列的映射, 例如 {"host.id": "id", "host.owner": "h.owner"}
+	FieldMapping map[string]string `json:"field_mapping" binding:"required"`
 }
 
 // ======= query by actions
@@ -99,6 +158,8 @@ type queryByActionsRequest struct {
 	// can be empty
 	Resources []resource `json:"resources" binding:"omitempty"`
 	Actions   []action   `json:"actions" binding:"required"`
+	// 返回的表达式格式版本, 为空时为v1
+	ExpressionVersion string `json:"expression_version" binding:"omitempty,oneof=v1 v2" example:"v1"`
 }
 
 type actionInResponse struct {
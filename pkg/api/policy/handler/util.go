@@ -13,13 +13,19 @@ package handler
 import (
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/abac/prp"
 	"iam/pkg/abac/types"
 	"iam/pkg/abac/types/request"
 	"iam/pkg/cache/impls"
 	"iam/pkg/config"
 	"iam/pkg/errorx"
 	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
 )
 
 const superSystemID = "SUPER"
@@ -31,6 +37,40 @@ var AnyExpression = map[string]interface{}{
 	"value": []interface{}{},
 }
 
+// locationCache time.LoadLocation每次都会读取时区文件, 缓存已加载的时区
+var locationCache sync.Map
+
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locationCache.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locationCache.Store(name, loc)
+	return loc, nil
+}
+
+// newRequestEnvironment 根据请求上下文及调用方传入的environment, 生成鉴权请求的环境属性
+func newRequestEnvironment(c *gin.Context, env *environment) (request.Environment, error) {
+	now := time.Now()
+	if env.TZ != "" {
+		loc, err := loadLocation(env.TZ)
+		if err != nil {
+			return request.Environment{}, fmt.Errorf("environment.tz `%s` is not a valid time zone", env.TZ)
+		}
+		now = now.In(loc)
+	}
+
+	return request.Environment{
+		Time:      now,
+		ClientIP:  c.ClientIP(),
+		SourceApp: util.GetClientID(c),
+	}, nil
+}
+
 func copyRequestFromAuthBody(req *request.Request, body *authRequest) {
 	req.System = body.System
 
@@ -108,6 +148,43 @@ func copyRequestFromAuthByResourcesBody(req *request.Request, body *authByResour
 	req.Subject.ID = body.Subject.ID
 }
 
+func copyRequestFromAuthWarmBody(req *request.Request, body *authWarmRequest) {
+	req.System = body.System
+
+	req.Subject.Type = body.Subject.Type
+	req.Subject.ID = body.Subject.ID
+}
+
+// authWarmHintExpiration 预热后单个鉴权请求可以直接命中本地缓存的时间,
+// 取鉴权链路上本地缓存有效期的最小值: subject角色(超级权限判断), subject pk, 策略及表达式
+// NOTE: 本地缓存被禁用时没有预热的效果, 返回0
+func authWarmHintExpiration() time.Duration {
+	if impls.LocalSubjectRoleCache.Disabled() || impls.LocalSubjectPKCache.Disabled() {
+		return 0
+	}
+
+	expiration := prp.LocalCacheExpiration()
+	for _, e := range []time.Duration{impls.LocalSubjectRoleCacheExpiration, impls.LocalSubjectPKCacheExpiration} {
+		if e < expiration {
+			expiration = e
+		}
+	}
+	return expiration
+}
+
+func distinctAuthWarmActions(items []authWarmItem) []string {
+	actionIDs := make([]string, 0, len(items))
+	actionIDSet := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		if _, ok := actionIDSet[item.Action.ID]; ok {
+			continue
+		}
+		actionIDSet[item.Action.ID] = struct{}{}
+		actionIDs = append(actionIDs, item.Action.ID)
+	}
+	return actionIDs
+}
+
 func hasSystemSuperPermission(systemID, _type, id string) (bool, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "validateSystemSuperUser")
 
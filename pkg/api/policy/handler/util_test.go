@@ -12,6 +12,7 @@ package handler
 
 import (
 	"errors"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	"iam/pkg/cache/memory"
 
 	"github.com/agiledragon/gomonkey"
+	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo"
 	"github.com/stretchr/testify/assert"
 
@@ -26,6 +28,7 @@ import (
 	"iam/pkg/abac/types/request"
 	"iam/pkg/cache/impls"
 	"iam/pkg/config"
+	"iam/pkg/util"
 )
 
 var baseReq = baseRequest{
@@ -315,6 +318,29 @@ var _ = Describe("util", func() {
 	})
 })
 
+func Test_newRequestEnvironment(t *testing.T) {
+	t.Parallel()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/policy/auth", nil)
+	c.Request.RemoteAddr = "10.0.0.1:12345"
+	util.SetClientID(c, "bk_paas")
+
+	env, err := newRequestEnvironment(c, &environment{})
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", env.ClientIP)
+	assert.Equal(t, "bk_paas", env.SourceApp)
+	assert.False(t, env.Time.IsZero())
+
+	env, err = newRequestEnvironment(c, &environment{TZ: "UTC"})
+	assert.NoError(t, err)
+	assert.Equal(t, "UTC", env.Time.Location().String())
+
+	_, err = newRequestEnvironment(c, &environment{TZ: "Mars/Olympus"})
+	assert.Error(t, err)
+}
+
 func Test_validateSystemMatchClient(t *testing.T) {
 	var (
 		expiration = 5 * time.Minute
@@ -376,3 +402,40 @@ func Test_validateSystemMatchClient(t *testing.T) {
 		})
 	}
 }
+
+func Test_authWarmHintExpiration(t *testing.T) {
+	retrieveFunc := func(key cache.Key) (interface{}, error) {
+		return nil, nil
+	}
+	oldRoleCache, oldPKCache := impls.LocalSubjectRoleCache, impls.LocalSubjectPKCache
+	defer func() {
+		impls.LocalSubjectRoleCache, impls.LocalSubjectPKCache = oldRoleCache, oldPKCache
+	}()
+
+	t.Run("ok, the min expiration of the local caches", func(t *testing.T) {
+		impls.LocalSubjectRoleCache = memory.NewCache("mockCache", false, retrieveFunc, time.Minute)
+		impls.LocalSubjectPKCache = memory.NewCache("mockCache", false, retrieveFunc, time.Minute)
+
+		assert.Equal(t, 60*time.Second, authWarmHintExpiration())
+	})
+
+	t.Run("ok, the subject local cache is shorter", func(t *testing.T) {
+		impls.LocalSubjectRoleCache = memory.NewCache("mockCache", false, retrieveFunc, time.Minute)
+		impls.LocalSubjectPKCache = memory.NewCache("mockCache", false, retrieveFunc, time.Minute)
+
+		oldExpiration := impls.LocalSubjectPKCacheExpiration
+		impls.LocalSubjectPKCacheExpiration = 10 * time.Second
+		defer func() {
+			impls.LocalSubjectPKCacheExpiration = oldExpiration
+		}()
+
+		assert.Equal(t, 10*time.Second, authWarmHintExpiration())
+	})
+
+	t.Run("ok, the local cache disabled", func(t *testing.T) {
+		impls.LocalSubjectRoleCache = memory.NewCache("mockCache", true, retrieveFunc, time.Minute)
+		impls.LocalSubjectPKCache = memory.NewCache("mockCache", false, retrieveFunc, time.Minute)
+
+		assert.Equal(t, time.Duration(0), authWarmHintExpiration())
+	})
+}
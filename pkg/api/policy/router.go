@@ -25,6 +25,12 @@ func Register(r *gin.RouterGroup) {
 	r.POST("/auth_by_actions", handler.BatchAuthByActions)
 	// 批量鉴权 - resources批量
 	r.POST("/auth_by_resources", handler.BatchAuthByResources)
+	// 批量鉴权 - 页面加载时批量鉴权(action, resources), 并预热缓存
+	r.POST("/auth_warm", handler.BatchAuthWarm)
+
+	// in explain.go
+	// 鉴权结果解释
+	r.POST("/explain", handler.Explain)
 
 	// in query.go
 	// 查询
@@ -33,4 +39,8 @@ func Register(r *gin.RouterGroup) {
 	r.POST("/query_by_actions", handler.BatchQueryByActions)
 	// 批量第三方依赖策略查询
 	r.POST("/query_by_ext_resources", handler.QueryByExtResources)
+
+	// in query_sql.go
+	// 查询, 返回参数化的SQL WHERE子句
+	r.POST("/query_sql", handler.QuerySQL)
 }
@@ -0,0 +1,191 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/cache/impls"
+	"iam/pkg/errorx"
+	"iam/pkg/util"
+)
+
+type peekCacheSerializer struct {
+	Key string `form:"key" binding:"required"`
+}
+
+type flushCacheSerializer struct {
+	System    string `form:"system"`
+	SubjectPK int64  `form:"subject_pk" binding:"omitempty,min=1"`
+}
+
+func (s *flushCacheSerializer) validate() (bool, string) {
+	if s.System != "" && s.SubjectPK != 0 {
+		return false, "system and subject_pk can not be both set"
+	}
+	return true, "valid"
+}
+
+// scope the flush scope and value, whole cache if neither system nor subject_pk set
+func (s *flushCacheSerializer) scope() (string, string) {
+	if s.System != "" {
+		return impls.FlushScopeSystem, s.System
+	}
+	if s.SubjectPK != 0 {
+		return impls.FlushScopeSubjectPK, strconv.FormatInt(s.SubjectPK, 10)
+	}
+	return impls.FlushScopeAll, ""
+}
+
+func isCacheRegistryBadRequest(err error) bool {
+	return errors.Is(err, impls.ErrCacheNotRegistered) || errors.Is(err, impls.ErrFlushScopeNotSupported)
+}
+
+// ListCaches godoc
+// @Summary list caches/查询缓存概览
+// @Description list the registered caches, with the size and hit ratio of the local caches on this instance
+// @ID api-web-admin-list-caches
+// @Tags web
+// @Accept json
+// @Produce json
+// @Success 200 {object} util.Response{data=[]impls.CacheInfo}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/admin/caches [get]
+func ListCaches(c *gin.Context) {
+	util.SuccessJSONResponse(c, "ok", impls.ListCacheInfos())
+}
+
+// PeekCache godoc
+// @Summary peek cache/查看缓存中的key
+// @Description peek the value of the key in the cache, without retrieving from the database
+// @ID api-web-admin-peek-cache
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param name path string true "cache name"
+// @Param params query peekCacheSerializer true "the key of cache"
+// @Success 200 {object} util.Response
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/admin/caches/{name}/peek [get]
+func PeekCache(c *gin.Context) {
+	var query peekCacheSerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	name := c.Param("name")
+	value, found, err := impls.PeekCache(name, query.Key)
+	if err != nil {
+		if isCacheRegistryBadRequest(err) {
+			util.BadRequestErrorJSONResponse(c, err.Error())
+			return
+		}
+
+		err = errorx.Wrapf(err, "Handler", "PeekCache", "name=`%s`, key=`%s`", name, query.Key)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"name":  name,
+		"key":   query.Key,
+		"found": found,
+		"value": value,
+	})
+}
+
+// FlushCache godoc
+// @Summary flush cache/清理缓存
+// @Description flush the whole cache, or only the keys of the system/subject;
+// @Description the local caches of all instances will be flushed via broadcast
+// @ID api-web-admin-flush-cache
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param name path string true "cache name"
+// @Param params query flushCacheSerializer false "flush the keys of the system or the subject only"
+// @Success 200 {object} util.Response{data=map[string]int}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/admin/caches/{name} [delete]
+func FlushCache(c *gin.Context) {
+	var query flushCacheSerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	if valid, message := query.validate(); !valid {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
+	name := c.Param("name")
+	flushCaches(c, "FlushCache", []string{name}, query)
+}
+
+// FlushCaches godoc
+// @Summary flush caches/按系统或subject清理缓存
+// @Description flush the keys of the system/subject in all the caches support the scope;
+// @Description the local caches of all instances will be flushed via broadcast
+// @ID api-web-admin-flush-caches
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param params query flushCacheSerializer true "system or subject_pk, one of them required"
+// @Success 200 {object} util.Response{data=map[string]int}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/admin/caches [delete]
+func FlushCaches(c *gin.Context) {
+	var query flushCacheSerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	if valid, message := query.validate(); !valid {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+	// NOTE: flush all the caches is not allowed, should flush them one by one
+	if query.System == "" && query.SubjectPK == 0 {
+		util.BadRequestErrorJSONResponse(c, "system or subject_pk required")
+		return
+	}
+
+	flushCaches(c, "FlushCaches", nil, query)
+}
+
+func flushCaches(c *gin.Context, function string, names []string, query flushCacheSerializer) {
+	scope, value := query.scope()
+	counts, err := impls.FlushCaches(names, scope, value)
+	if err != nil {
+		if isCacheRegistryBadRequest(err) {
+			util.BadRequestErrorJSONResponse(c, err.Error())
+			return
+		}
+
+		err = errorx.Wrapf(err, "Handler", function, "names=`%+v`, scope=`%s`, value=`%s`", names, scope, value)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", counts)
+}
@@ -0,0 +1,160 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache/impls"
+	"iam/pkg/util"
+)
+
+func TestListCaches(t *testing.T) {
+	patches := gomonkey.ApplyFunc(impls.ListCacheInfos, func() []impls.CacheInfo {
+		return []impls.CacheInfo{{Name: "local_subject", Type: impls.CacheTypeMemory, Size: 1}}
+	})
+	defer patches.Reset()
+
+	util.CreateNewAPIRequestFunc("get", "/api/v1/web/admin/caches", ListCaches)(t).OK()
+}
+
+func TestPeekCache(t *testing.T) {
+	newRequest := func(url string) *util.GinAPIRequest {
+		return util.CreateNewAPIRequestFunc("get", url, PeekCache, "/api/v1/web/admin/caches/:name/peek")(t)
+	}
+
+	t.Run("key required", func(t *testing.T) {
+		newRequest("/api/v1/web/admin/caches/local_subject/peek").BadRequestContainsMessage("Key")
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(impls.PeekCache, func(name, key string) (interface{}, bool, error) {
+			return nil, false, impls.ErrCacheNotRegistered
+		})
+		defer patches.Reset()
+
+		newRequest("/api/v1/web/admin/caches/abc/peek").
+			QueryParams(map[string]string{"key": "1"}).BadRequestContainsMessage("not registered")
+	})
+
+	t.Run("peek fail", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(impls.PeekCache, func(name, key string) (interface{}, bool, error) {
+			return nil, false, errors.New("redis fail")
+		})
+		defer patches.Reset()
+
+		newRequest("/api/v1/web/admin/caches/sub_grp/peek").
+			QueryParams(map[string]string{"key": "1"}).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(impls.PeekCache, func(name, key string) (interface{}, bool, error) {
+			assert.Equal(t, "local_subject", name)
+			assert.Equal(t, "1", key)
+			return "admin", true, nil
+		})
+		defer patches.Reset()
+
+		newRequest("/api/v1/web/admin/caches/local_subject/peek").
+			QueryParams(map[string]string{"key": "1"}).OK()
+	})
+}
+
+func TestFlushCache(t *testing.T) {
+	newRequest := func(url string) *util.GinAPIRequest {
+		return util.CreateNewAPIRequestFunc("delete", url, FlushCache, "/api/v1/web/admin/caches/:name")(t)
+	}
+
+	t.Run("both system and subject_pk", func(t *testing.T) {
+		newRequest("/api/v1/web/admin/caches/pl").
+			QueryParams(map[string]string{"system": "bk_cmdb", "subject_pk": "1"}).
+			BadRequest("bad request:system and subject_pk can not be both set")
+	})
+
+	t.Run("scope not supported", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(impls.FlushCaches,
+			func(names []string, scope, value string) (map[string]int, error) {
+				return nil, impls.ErrFlushScopeNotSupported
+			})
+		defer patches.Reset()
+
+		newRequest("/api/v1/web/admin/caches/ex").
+			QueryParams(map[string]string{"system": "bk_cmdb"}).BadRequestContainsMessage("not supported")
+	})
+
+	t.Run("flush fail", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(impls.FlushCaches,
+			func(names []string, scope, value string) (map[string]int, error) {
+				return nil, errors.New("redis fail")
+			})
+		defer patches.Reset()
+
+		newRequest("/api/v1/web/admin/caches/pl").SystemError()
+	})
+
+	t.Run("whole cache", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(impls.FlushCaches,
+			func(names []string, scope, value string) (map[string]int, error) {
+				assert.Equal(t, []string{"pl"}, names)
+				assert.Equal(t, impls.FlushScopeAll, scope)
+				return map[string]int{"pl": 10}, nil
+			})
+		defer patches.Reset()
+
+		newRequest("/api/v1/web/admin/caches/pl").OK()
+	})
+
+	t.Run("by subject pk", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(impls.FlushCaches,
+			func(names []string, scope, value string) (map[string]int, error) {
+				assert.Equal(t, impls.FlushScopeSubjectPK, scope)
+				assert.Equal(t, "123", value)
+				return map[string]int{"pl": 1}, nil
+			})
+		defer patches.Reset()
+
+		newRequest("/api/v1/web/admin/caches/pl").
+			QueryParams(map[string]string{"subject_pk": "123"}).OK()
+	})
+}
+
+func TestFlushCaches(t *testing.T) {
+	newRequest := func(url string) *util.GinAPIRequest {
+		return util.CreateNewAPIRequestFunc("delete", url, FlushCaches)(t)
+	}
+
+	t.Run("scope required", func(t *testing.T) {
+		newRequest("/api/v1/web/admin/caches").BadRequest("bad request:system or subject_pk required")
+	})
+
+	t.Run("invalid subject_pk", func(t *testing.T) {
+		newRequest("/api/v1/web/admin/caches").
+			QueryParams(map[string]string{"subject_pk": "-1"}).BadRequestContainsMessage("SubjectPK")
+	})
+
+	t.Run("by system", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(impls.FlushCaches,
+			func(names []string, scope, value string) (map[string]int, error) {
+				assert.Empty(t, names)
+				assert.Equal(t, impls.FlushScopeSystem, scope)
+				assert.Equal(t, "bk_cmdb", value)
+				return map[string]int{"sys": 1}, nil
+			})
+		defer patches.Reset()
+
+		newRequest("/api/v1/web/admin/caches").
+			QueryParams(map[string]string{"system": "bk_cmdb"}).OK()
+	})
+}
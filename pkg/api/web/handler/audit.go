@@ -0,0 +1,205 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+/*
+ * > 审计查询
+ *
+ * 按过滤条件查询部门/角色的变更记录, 使用pk游标分页, 最近的在前;
+ * 导出时按游标分批查询并以CSV流式返回, 使用批量任务的连接池, 不影响在线请求
+ */
+
+// 导出时每批查询的记录数量
+var auditExportChunkSize int64 = 1000
+
+var auditCSVHeader = []string{
+	"pk", "type", "subject_type", "subject_id", "subject_name", "target_id", "target_name",
+	"system_id", "action", "operator", "source", "created_at",
+}
+
+// ListAuditRecords godoc
+// @Summary list audit records/查询审计记录
+// @Description filter the department/role change records, cursor-based paging, the latest first
+// @ID api-web-list-audit-records
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param params query listAuditSerializer true "the filter and the cursor"
+// @Success 200 {object} util.Response{data=auditListResponse}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/audits [get]
+func ListAuditRecords(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "ListAuditRecords")
+
+	var query listAuditSerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	if ok, message := query.validate(); !ok {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+	query.initDefault()
+
+	data := auditListResponse{
+		NextCursor: query.Cursor,
+		Results:    []types.AuditRecord{},
+	}
+
+	subjectPK, exists, err := getAuditSubjectPK(query.SubjectType, query.SubjectID)
+	if err != nil {
+		util.SystemErrorJSONResponse(c, errorWrapf(err, "getAuditSubjectPK fail"))
+		return
+	}
+	// subject不存在(或已被删除), 没有审计记录
+	if !exists {
+		util.SuccessJSONResponse(c, "ok", data)
+		return
+	}
+
+	filter := query.filter(subjectPK)
+	svc := service.NewAuditService()
+	records, err := svc.ListBeforePK(filter, query.Cursor, query.Limit)
+	if err != nil {
+		err = errorWrapf(err, "svc.ListBeforePK filter=`%+v`, cursor=`%d`, limit=`%d`",
+			filter, query.Cursor, query.Limit)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	data.HasMore = int64(len(records)) == query.Limit
+	data.Results = records
+	if len(records) > 0 {
+		data.NextCursor = records[len(records)-1].PK
+	}
+
+	util.SuccessJSONResponse(c, "ok", data)
+}
+
+// ExportAuditRecords godoc
+// @Summary export audit records/导出审计记录
+// @Description export all the filtered department/role change records as a csv file, the latest first
+// @ID api-web-export-audit-records
+// @Tags web
+// @Accept json
+// @Produce text/csv
+// @Param params query auditFilterSerializer true "the filter"
+// @Success 200 {string} string "the csv file"
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/audits/export [get]
+func ExportAuditRecords(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "ExportAuditRecords")
+
+	var query auditFilterSerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	if ok, message := query.validate(); !ok {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
+	subjectPK, exists, err := getAuditSubjectPK(query.SubjectType, query.SubjectID)
+	if err != nil {
+		util.SystemErrorJSONResponse(c, errorWrapf(err, "getAuditSubjectPK fail"))
+		return
+	}
+
+	filter := query.filter(subjectPK)
+	svc := service.NewBatchAuditService()
+
+	// 第一批查询失败时还可以返回错误
+	var records []types.AuditRecord
+	if exists {
+		records, err = svc.ListBeforePK(filter, 0, auditExportChunkSize)
+		if err != nil {
+			err = errorWrapf(err, "svc.ListBeforePK filter=`%+v`, limit=`%d`", filter, auditExportChunkSize)
+			util.SystemErrorJSONResponse(c, err)
+			return
+		}
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s_audit_records.csv", query.Type))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write(auditCSVHeader)
+	for {
+		for _, r := range records {
+			w.Write([]string{
+				strconv.FormatInt(r.PK, 10), r.Type, r.SubjectType, r.SubjectID, r.SubjectName, r.TargetID,
+				r.TargetName, r.System, r.Action, r.Operator, r.Source, r.CreatedAt.Format(time.RFC3339),
+			})
+		}
+		w.Flush()
+		if err = w.Error(); err != nil {
+			log.WithError(err).Errorf("export audit records, write csv fail, filter=`%+v`", filter)
+			return
+		}
+		c.Writer.Flush()
+
+		if int64(len(records)) < auditExportChunkSize {
+			return
+		}
+
+		// NOTE: 已经开始返回数据, 失败时只能中断, 由调用方根据不完整的文件重试
+		cursor := records[len(records)-1].PK
+		records, err = svc.ListBeforePK(filter, cursor, auditExportChunkSize)
+		if err != nil {
+			log.WithError(err).Errorf("export audit records, svc.ListBeforePK filter=`%+v`, cursor=`%d` fail",
+				filter, cursor)
+			c.Abort()
+			return
+		}
+	}
+}
+
+// getAuditSubjectPK 查询审计过滤条件中subject的pk, 没有指定subject时返回0; subject不存在时exists为false
+func getAuditSubjectPK(subjectType, subjectID string) (pk int64, exists bool, err error) {
+	if subjectType == "" {
+		return 0, true, nil
+	}
+
+	pk, err = service.NewSubjectBaseReadService().GetPK(subjectType, subjectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, errorx.Wrapf(err, "Handler", "getAuditSubjectPK",
+			"svc.GetPK type=`%s`, id=`%s` fail", subjectType, subjectID)
+	}
+	return pk, true, nil
+}
@@ -0,0 +1,86 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"time"
+
+	"iam/pkg/service/types"
+)
+
+const (
+	defaultAuditLimit = 100
+)
+
+// auditFilterSerializer 审计查询的过滤条件, 变更时间范围[start_time, end_time), unix时间戳, 不传时不限制
+type auditFilterSerializer struct {
+	// 记录的类型: department(部门变更记录) / role(角色变更记录)
+	Type string `form:"type" binding:"required,oneof=department role" example:"role"`
+	// 受影响的subject, 需要同时传subject_type和subject_id
+	SubjectType string `form:"subject_type" binding:"omitempty,oneof=user group department" example:"user"`
+	SubjectID   string `form:"subject_id" binding:"omitempty,max=64" example:"admin"`
+	// system_id/operator只有角色变更记录支持
+	SystemID  string `form:"system_id" binding:"omitempty,max=32" example:"bk_cmdb"`
+	Operator  string `form:"operator" binding:"omitempty,max=64" example:"admin"`
+	Source    string `form:"source" binding:"omitempty,max=64" example:"bk_iam"`
+	StartTime int64  `form:"start_time" binding:"omitempty,min=1" example:"1630000000"`
+	EndTime   int64  `form:"end_time" binding:"omitempty,min=1" example:"1630086400"`
+}
+
+func (s *auditFilterSerializer) validate() (bool, string) {
+	if (s.SubjectType == "") != (s.SubjectID == "") {
+		return false, "subject_type and subject_id should be both set or both empty"
+	}
+	if s.Type != types.AuditTypeRole && (s.SystemID != "" || s.Operator != "") {
+		return false, "system_id and operator only support type role"
+	}
+	if s.StartTime > 0 && s.EndTime > 0 && s.EndTime <= s.StartTime {
+		return false, "end_time should be greater than start_time"
+	}
+	return true, "valid"
+}
+
+func (s *auditFilterSerializer) filter(subjectPK int64) types.AuditFilter {
+	filter := types.AuditFilter{
+		Type:      s.Type,
+		SubjectPK: subjectPK,
+		System:    s.SystemID,
+		Operator:  s.Operator,
+		Source:    s.Source,
+	}
+	if s.StartTime > 0 {
+		filter.StartTime = time.Unix(s.StartTime, 0)
+	}
+	if s.EndTime > 0 {
+		filter.EndTime = time.Unix(s.EndTime, 0)
+	}
+	return filter
+}
+
+type listAuditSerializer struct {
+	auditFilterSerializer
+	// 游标: 上一页返回的next_cursor, 首页为0
+	Cursor int64 `form:"cursor" binding:"omitempty,min=0" example:"0"`
+	Limit  int64 `form:"limit" binding:"omitempty,min=1,max=1000" example:"100"`
+}
+
+func (s *listAuditSerializer) initDefault() {
+	if s.Limit == 0 {
+		s.Limit = defaultAuditLimit
+	}
+}
+
+type auditListResponse struct {
+	// 下一页的游标, has_more=false时无需继续拉取
+	NextCursor int64               `json:"next_cursor"`
+	HasMore    bool                `json:"has_more"`
+	Results    []types.AuditRecord `json:"results"`
+}
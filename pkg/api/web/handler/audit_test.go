@@ -0,0 +1,178 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestListAuditRecords(t *testing.T) {
+	url := "/api/v1/web/audits"
+
+	t.Run("bad request with invalid type", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, ListAuditRecords)(t).
+			QueryParams(map[string]string{"type": "policy"}).BadRequestContainsMessage("Type")
+	})
+
+	t.Run("bad request with subject_id only", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, ListAuditRecords)(t).
+			QueryParams(map[string]string{"type": "role", "subject_id": "admin"}).
+			BadRequestContainsMessage("subject_type and subject_id")
+	})
+
+	t.Run("bad request with system_id of department", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, ListAuditRecords)(t).
+			QueryParams(map[string]string{"type": "department", "system_id": "bk_cmdb"}).
+			BadRequestContainsMessage("only support type role")
+	})
+
+	t.Run("subject not exists", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectReadService(ctl)
+		mockSvc.EXPECT().GetPK("user", "admin").Return(int64(0), sql.ErrNoRows)
+		patches := gomonkey.ApplyFunc(service.NewSubjectBaseReadService, func() service.SubjectBaseReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListAuditRecords)(t).
+			QueryParams(map[string]string{"type": "department", "subject_type": "user", "subject_id": "admin"}).
+			OK()
+	})
+
+	t.Run("list fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockAuditService(ctl)
+		mockSvc.EXPECT().ListBeforePK(gomock.Any(), int64(0), int64(100)).Return(nil, errors.New("list fail"))
+		patches := gomonkey.ApplyFunc(service.NewAuditService, func() service.AuditService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListAuditRecords)(t).
+			QueryParams(map[string]string{"type": "role"}).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockAuditService(ctl)
+		mockSvc.EXPECT().ListBeforePK(types.AuditFilter{
+			Type:      "role",
+			System:    "bk_cmdb",
+			StartTime: time.Unix(1630000000, 0),
+		}, int64(10), int64(1)).Return([]types.AuditRecord{{PK: 9, Type: "role"}}, nil)
+		patches := gomonkey.ApplyFunc(service.NewAuditService, func() service.AuditService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListAuditRecords)(t).
+			QueryParams(map[string]string{
+				"type": "role", "system_id": "bk_cmdb", "start_time": "1630000000", "cursor": "10", "limit": "1",
+			}).OK()
+	})
+}
+
+func TestExportAuditRecords(t *testing.T) {
+	doRequest := func(url string) *httptest.ResponseRecorder {
+		r := util.SetupRouter()
+		r.GET("/api/v1/web/audits/export", ExportAuditRecords)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("bad request", func(t *testing.T) {
+		w := doRequest("/api/v1/web/audits/export?type=role&start_time=2&end_time=1")
+		assert.Contains(t, w.Body.String(), "end_time should be greater than start_time")
+	})
+
+	t.Run("list fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockAuditService(ctl)
+		mockSvc.EXPECT().ListBeforePK(gomock.Any(), int64(0), auditExportChunkSize).Return(nil, errors.New("error"))
+		patches := gomonkey.ApplyFunc(service.NewBatchAuditService, func() service.AuditService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		w := doRequest("/api/v1/web/audits/export?type=role")
+		assert.Contains(t, w.Body.String(), "system error")
+	})
+
+	t.Run("ok, in chunks", func(t *testing.T) {
+		oldChunkSize := auditExportChunkSize
+		auditExportChunkSize = 2
+		defer func() {
+			auditExportChunkSize = oldChunkSize
+		}()
+
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		createdAt := time.Unix(1630000000, 0).UTC()
+		filter := types.AuditFilter{Type: "department", Source: "bk_iam"}
+		mockSvc := mock.NewMockAuditService(ctl)
+		gomock.InOrder(
+			mockSvc.EXPECT().ListBeforePK(filter, int64(0), int64(2)).Return([]types.AuditRecord{
+				{PK: 9, Type: "department", SubjectType: "user", SubjectID: "admin", TargetID: "d1",
+					Action: "added", Source: "bk_iam", CreatedAt: createdAt},
+				{PK: 7, Type: "department", SubjectType: "user", SubjectID: "tom", TargetID: "d1",
+					Action: "removed", Source: "bk_iam", CreatedAt: createdAt},
+			}, nil),
+			mockSvc.EXPECT().ListBeforePK(filter, int64(7), int64(2)).Return([]types.AuditRecord{
+				{PK: 3, Type: "department", SubjectType: "user", SubjectID: "bob", TargetID: "d2",
+					Action: "added", Source: "bk_iam", CreatedAt: createdAt},
+			}, nil),
+		)
+		patches := gomonkey.ApplyFunc(service.NewBatchAuditService, func() service.AuditService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		w := doRequest("/api/v1/web/audits/export?type=department&source=bk_iam")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		assert.Equal(t, []string{
+			"pk,type,subject_type,subject_id,subject_name,target_id,target_name,system_id,action,operator," +
+				"source,created_at",
+			"9,department,user,admin,,d1,,,added,,bk_iam,2021-08-26T17:46:40Z",
+			"7,department,user,tom,,d1,,,removed,,bk_iam,2021-08-26T17:46:40Z",
+			"3,department,user,bob,,d2,,,added,,bk_iam,2021-08-26T17:46:40Z",
+		}, lines)
+	})
+}
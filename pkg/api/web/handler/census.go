@@ -0,0 +1,75 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/census"
+	"iam/pkg/errorx"
+	"iam/pkg/util"
+)
+
+// CreateExpressionCensus godoc
+// @Summary census the expression usage/统计表达式中操作符及属性的使用情况
+// @Description scan all the expressions offline, count the operator/field usage frequency per system
+// @ID api-web-create-expression-census
+// @Tags web
+// @Accept json
+// @Produce json
+// @Success 200 {object} util.Response{data=census.Task}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/census/expressions [post]
+func CreateExpressionCensus(c *gin.Context) {
+	task, err := census.StartExpressionCensus()
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "CreateExpressionCensus", "census.StartExpressionCensus fail")
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", task)
+}
+
+// GetCensusTask godoc
+// @Summary get the progress and result of census task/查询统计任务的进度及结果
+// @Description get the progress of the census task, and the usage per system after finished
+// @ID api-web-get-census-task
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param task_id path string true "Task ID"
+// @Success 200 {object} util.Response{data=census.Task}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/census/tasks/{task_id} [get]
+func GetCensusTask(c *gin.Context) {
+	taskID := c.Param("task_id")
+
+	task, err := census.GetTask(taskID)
+	if errors.Is(err, census.ErrTaskNotFound) {
+		// the task not exists or expired
+		util.NotFoundJSONResponse(c, fmt.Sprintf("census task(%s)", taskID))
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "GetCensusTask", "taskID=`%s`", taskID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", task)
+}
@@ -0,0 +1,77 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+
+	"iam/pkg/census"
+	"iam/pkg/util"
+)
+
+func TestCreateExpressionCensus(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"post", "/api/v1/web/census/expressions", CreateExpressionCensus,
+	)
+
+	t.Run("error", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(census.StartExpressionCensus, func() (census.Task, error) {
+			return census.Task{}, errors.New("save task fail")
+		})
+		defer patches.Reset()
+
+		newRequestFunc(t).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(census.StartExpressionCensus, func() (census.Task, error) {
+			return census.Task{ID: "abc", Status: census.TaskStatusRunning}, nil
+		})
+		defer patches.Reset()
+
+		newRequestFunc(t).OK()
+	})
+}
+
+func TestGetCensusTask(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"get", "/api/v1/web/census/tasks/abc", GetCensusTask,
+	)
+
+	t.Run("error", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(census.GetTask, func(taskID string) (census.Task, error) {
+			return census.Task{}, errors.New("redis fail")
+		})
+		defer patches.Reset()
+
+		newRequestFunc(t).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(census.GetTask, func(taskID string) (census.Task, error) {
+			return census.Task{
+				ID:     "abc",
+				Status: census.TaskStatusFinished,
+				Systems: []census.SystemExpressionUsage{{
+					System:      "bk_test",
+					Expressions: 1,
+					Operators:   map[string]int64{"StringEquals": 1},
+					Fields:      map[string]int64{"host.id": 1},
+				}},
+			}, nil
+		})
+		defer patches.Reset()
+
+		newRequestFunc(t).OK()
+	})
+}
@@ -0,0 +1,126 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/errorx"
+	"iam/pkg/export"
+	"iam/pkg/util"
+)
+
+type subjectRelationExportSerializer struct {
+	// 是否将用户名替换为hash
+	Anonymize bool `json:"anonymize"`
+}
+
+// CreateSubjectRelationExport godoc
+// @Summary export subject relations/导出subject/group/department关系
+// @Description export the subject/group/department relations to the object storage for BI, the usernames can be anonymized
+// @ID api-web-create-subject-relation-export
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param body body subjectRelationExportSerializer true "export options"
+// @Success 200 {object} util.Response{data=export.Task}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/exports/subject-relations [post]
+func CreateSubjectRelationExport(c *gin.Context) {
+	var body subjectRelationExportSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	task, err := export.StartSubjectRelationExport(body.Anonymize)
+	if errors.Is(err, export.ErrAnonymizeSaltNotConfigured) {
+		util.BadRequestErrorJSONResponse(c, err.Error())
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "CreateSubjectRelationExport", "anonymize=`%t`", body.Anonymize)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", task)
+}
+
+// GetExportTask godoc
+// @Summary get the progress of export task/查询导出任务的进度
+// @Description get the progress and the uploaded files of the export task
+// @ID api-web-get-export-task
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param task_id path string true "Task ID"
+// @Success 200 {object} util.Response{data=export.Task}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/exports/tasks/{task_id} [get]
+func GetExportTask(c *gin.Context) {
+	taskID := c.Param("task_id")
+
+	task, err := export.GetTask(taskID)
+	if errors.Is(err, export.ErrTaskNotFound) {
+		// the task not exists or expired
+		util.NotFoundJSONResponse(c, fmt.Sprintf("export task(%s)", taskID))
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "GetExportTask", "taskID=`%s`", taskID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", task)
+}
+
+// GetExportTaskEvents godoc
+// @Summary stream the progress of export task/推送导出任务的进度
+// @Description stream the progress of the export task via Server-Sent Events, until the task finished or failed
+// @ID api-web-get-export-task-events
+// @Tags web
+// @Accept json
+// @Produce text/event-stream
+// @Param task_id path string true "Task ID"
+// @Success 200 {object} export.Task
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/exports/tasks/{task_id}/events [get]
+func GetExportTaskEvents(c *gin.Context) {
+	taskID := c.Param("task_id")
+
+	task, err := export.GetTask(taskID)
+	if errors.Is(err, export.ErrTaskNotFound) {
+		// the task not exists or expired
+		util.NotFoundJSONResponse(c, fmt.Sprintf("export task(%s)", taskID))
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "GetExportTaskEvents", "taskID=`%s`", taskID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	streamTaskEvents(c, task, task.Status != export.TaskStatusRunning, func() (interface{}, bool, error) {
+		t, err := export.GetTask(taskID)
+		return t, t.Status != export.TaskStatusRunning, err
+	})
+}
@@ -0,0 +1,88 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+
+	"iam/pkg/export"
+	"iam/pkg/util"
+)
+
+func TestCreateSubjectRelationExport(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"post", "/api/v1/web/exports/subject-relations", CreateSubjectRelationExport,
+	)
+
+	t.Run("no json", func(t *testing.T) {
+		newRequestFunc(t).NoJSON()
+	})
+
+	t.Run("anonymize salt not configured", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(export.StartSubjectRelationExport, func(anonymize bool) (export.Task, error) {
+			return export.Task{}, export.ErrAnonymizeSaltNotConfigured
+		})
+		defer patches.Reset()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{"anonymize": true}).
+			BadRequestContainsMessage("salt not configured")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(export.StartSubjectRelationExport, func(anonymize bool) (export.Task, error) {
+			return export.Task{}, errors.New("save task fail")
+		})
+		defer patches.Reset()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{"anonymize": false}).
+			SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(export.StartSubjectRelationExport, func(anonymize bool) (export.Task, error) {
+			return export.Task{ID: "abc", Anonymize: anonymize, Status: export.TaskStatusRunning}, nil
+		})
+		defer patches.Reset()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{"anonymize": true}).
+			OK()
+	})
+}
+
+func TestGetExportTask(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"get", "/api/v1/web/exports/tasks/abc", GetExportTask,
+	)
+
+	t.Run("error", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(export.GetTask, func(taskID string) (export.Task, error) {
+			return export.Task{}, errors.New("redis fail")
+		})
+		defer patches.Reset()
+
+		newRequestFunc(t).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(export.GetTask, func(taskID string) (export.Task, error) {
+			return export.Task{ID: "abc", Status: export.TaskStatusFinished}, nil
+		})
+		defer patches.Reset()
+
+		newRequestFunc(t).OK()
+	})
+}
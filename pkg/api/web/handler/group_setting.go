@@ -0,0 +1,177 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// GetGroupSetting godoc
+// @Summary get group setting/查询用户组的成员配置
+// @Description get the member limit and the expiry policy of the group, all zero if not configured
+// @ID api-web-get-group-setting
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param params query groupSettingQuerySerializer true "the group"
+// @Success 200 {object} util.Response{data=types.GroupSetting}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/group-settings [get]
+func GetGroupSetting(c *gin.Context) {
+	var query groupSettingQuerySerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	svc := service.NewSubjectGroupReadService()
+	setting, err := svc.GetGroupSetting(query.Type, query.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		util.BadRequestErrorJSONResponse(c, fmt.Sprintf("group(%s) not exists", query.ID))
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "GetGroupSetting", "type=`%s`, id=`%s`", query.Type, query.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", setting)
+}
+
+// SaveGroupSetting godoc
+// @Summary save group setting/创建或更新用户组的成员配置
+// @Description set the member limit and the expiry policy of the group, 0 means no limit
+// @ID api-web-save-group-setting
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param body body saveGroupSettingSerializer true "the group and the setting"
+// @Success 200 {object} util.Response{data=gin.H}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/group-settings [put]
+func SaveGroupSetting(c *gin.Context) {
+	var body saveGroupSettingSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	if ok, message := body.validate(); !ok {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
+	setting := types.GroupSetting{
+		MaxMembers:            body.MaxMembers,
+		DefaultExpirationDays: body.DefaultExpirationDays,
+		MaxExpirationDays:     body.MaxExpirationDays,
+		RenewalWindowDays:     body.RenewalWindowDays,
+	}
+
+	svc := service.NewSubjectService()
+	err := svc.SaveGroupSetting(body.Type, body.ID, setting)
+	if errors.Is(err, sql.ErrNoRows) {
+		util.BadRequestErrorJSONResponse(c, fmt.Sprintf("group(%s) not exists", body.ID))
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "SaveGroupSetting",
+			"type=`%s`, id=`%s`, setting=`%+v`", body.Type, body.ID, setting)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{})
+}
+
+// DeleteGroupSetting godoc
+// @Summary delete group setting/删除用户组的成员配置
+// @Description delete the setting of the group, the members will not be limited any more
+// @ID api-web-delete-group-setting
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param body body groupSettingQuerySerializer true "the group"
+// @Success 200 {object} util.Response{data=gin.H}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/group-settings [delete]
+func DeleteGroupSetting(c *gin.Context) {
+	var body groupSettingQuerySerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	svc := service.NewSubjectService()
+	err := svc.DeleteGroupSetting(body.Type, body.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		util.BadRequestErrorJSONResponse(c, fmt.Sprintf("group(%s) not exists", body.ID))
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "DeleteGroupSetting", "type=`%s`, id=`%s`", body.Type, body.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{})
+}
+
+// fillGroupDefaultPolicyExpiredAt 未指定过期时间时, 优先使用用户组配置的默认过期天数, 其次才是系统的配置
+func fillGroupDefaultPolicyExpiredAt(_type, id string, policyExpiredAt int64) (int64, error) {
+	if policyExpiredAt != 0 || _type != types.GroupType {
+		return policyExpiredAt, nil
+	}
+
+	svc := service.NewSubjectGroupReadService()
+	setting, err := svc.GetGroupSetting(_type, id)
+	// NOTE: 用户组不存在时不处理, 由后续的添加成员返回错误
+	if errors.Is(err, sql.ErrNoRows) {
+		return policyExpiredAt, nil
+	}
+	if err != nil {
+		return policyExpiredAt, err
+	}
+
+	if setting.DefaultExpirationDays > 0 {
+		policyExpiredAt = time.Now().AddDate(0, 0, int(setting.DefaultExpirationDays)).Unix()
+	}
+	return policyExpiredAt, nil
+}
+
+// groupSettingError 违反用户组成员配置的错误需要返回给调用方, 返回匹配的错误, 不匹配时返回nil
+func groupSettingError(err error) error {
+	for _, e := range []error{
+		service.ErrGroupMemberLimitExceeded,
+		service.ErrGroupMemberExpiredAtExceeded,
+		service.ErrGroupMemberRenewalNotAllowed,
+	} {
+		if errors.Is(err, e) {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestGetGroupSetting(t *testing.T) {
+	url := "/api/v1/web/group-settings"
+
+	t.Run("bad request", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, GetGroupSetting)(t).
+			QueryParams(map[string]string{"type": "user", "id": "1"}).
+			BadRequestContainsMessage("Type")
+	})
+
+	t.Run("group not exists", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectGroupReadService(ctl)
+		mockSvc.EXPECT().GetGroupSetting("group", "1").Return(
+			svctypes.GroupSetting{}, errorx.Wrapf(sql.ErrNoRows, "SubjectSVC", "GetGroupSetting", ""))
+		patches := gomonkey.ApplyFunc(service.NewSubjectGroupReadService, func() service.SubjectGroupReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, GetGroupSetting)(t).
+			QueryParams(map[string]string{"type": "group", "id": "1"}).
+			BadRequestContainsMessage("group(1) not exists")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectGroupReadService(ctl)
+		mockSvc.EXPECT().GetGroupSetting("group", "1").Return(svctypes.GroupSetting{MaxMembers: 100}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectGroupReadService, func() service.SubjectGroupReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, GetGroupSetting)(t).
+			QueryParams(map[string]string{"type": "group", "id": "1"}).OK()
+	})
+}
+
+func TestSaveGroupSetting(t *testing.T) {
+	url := "/api/v1/web/group-settings"
+
+	t.Run("bad request", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("put", url, SaveGroupSetting)(t).
+			JSON(map[string]interface{}{"type": "group", "id": "1", "max_members": -1}).
+			BadRequestContainsMessage("MaxMembers")
+	})
+
+	t.Run("bad request default greater than max", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("put", url, SaveGroupSetting)(t).
+			JSON(map[string]interface{}{
+				"type": "group", "id": "1", "default_expiration_days": 60, "max_expiration_days": 30,
+			}).
+			BadRequest("bad request:default_expiration_days should not be greater than max_expiration_days")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().SaveGroupSetting("group", "1", svctypes.GroupSetting{
+			MaxMembers: 100, DefaultExpirationDays: 30, MaxExpirationDays: 180, RenewalWindowDays: 7,
+		}).Return(nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("put", url, SaveGroupSetting)(t).
+			JSON(map[string]interface{}{
+				"type":                    "group",
+				"id":                      "1",
+				"max_members":             100,
+				"default_expiration_days": 30,
+				"max_expiration_days":     180,
+				"renewal_window_days":     7,
+			}).OK()
+	})
+}
+
+func TestDeleteGroupSetting(t *testing.T) {
+	url := "/api/v1/web/group-settings"
+
+	t.Run("error", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().DeleteGroupSetting("group", "1").Return(errors.New("error"))
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("delete", url, DeleteGroupSetting)(t).
+			JSON(map[string]interface{}{"type": "group", "id": "1"}).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().DeleteGroupSetting("group", "1").Return(nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("delete", url, DeleteGroupSetting)(t).
+			JSON(map[string]interface{}{"type": "group", "id": "1"}).OK()
+	})
+}
+
+func TestFillGroupDefaultPolicyExpiredAt(t *testing.T) {
+	t.Run("specified", func(t *testing.T) {
+		policyExpiredAt, err := fillGroupDefaultPolicyExpiredAt("group", "1", 100)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(100), policyExpiredAt)
+	})
+
+	t.Run("group default", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectGroupReadService(ctl)
+		mockSvc.EXPECT().GetGroupSetting("group", "1").Return(svctypes.GroupSetting{DefaultExpirationDays: 30}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectGroupReadService, func() service.SubjectGroupReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		policyExpiredAt, err := fillGroupDefaultPolicyExpiredAt("group", "1", 0)
+		assert.NoError(t, err)
+		assert.InDelta(t, time.Now().AddDate(0, 0, 30).Unix(), policyExpiredAt, 5)
+	})
+
+	t.Run("group not exists", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectGroupReadService(ctl)
+		mockSvc.EXPECT().GetGroupSetting("group", "1").Return(svctypes.GroupSetting{}, sql.ErrNoRows)
+		patches := gomonkey.ApplyFunc(service.NewSubjectGroupReadService, func() service.SubjectGroupReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		policyExpiredAt, err := fillGroupDefaultPolicyExpiredAt("group", "1", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), policyExpiredAt)
+	})
+}
+
+func TestGroupSettingError(t *testing.T) {
+	err := errorx.Wrapf(service.ErrGroupMemberLimitExceeded, "SubjectSVC", "BulkCreateSubjectMembers", "")
+	assert.Equal(t, service.ErrGroupMemberLimitExceeded, groupSettingError(err))
+	assert.Nil(t, groupSettingError(errors.New("error")))
+	assert.Nil(t, groupSettingError(nil))
+}
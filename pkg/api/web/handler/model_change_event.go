@@ -11,11 +11,24 @@
 package handler
 
 import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"iam/pkg/component"
 	"iam/pkg/errorx"
 	"iam/pkg/service"
 	"iam/pkg/util"
 
 	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	modelChangeEventStatusFinished = "finished"
+
+	// the length of model_change_event.callback_message
+	modelChangeEventCallbackMessageMaxLength = 255
 )
 
 // ListModelChangeEvent 查询变更事件列表
@@ -56,5 +69,62 @@ func UpdateModelChangeEvent(c *gin.Context) {
 		util.SystemErrorJSONResponse(c, err)
 		return
 	}
+
+	// 事件处理完成后回调通知接入系统; NOTE: 事件状态已更新, 回调失败只记录投递状态, 不影响返回
+	if body.Status == modelChangeEventStatusFinished {
+		err = notifyModelChangeEventCallback(eventPK)
+		if err != nil {
+			log.WithError(err).Errorf("notifyModelChangeEventCallback eventPK=`%d` fail", eventPK)
+		}
+	}
 	util.SuccessJSONResponse(c, "ok", nil)
 }
+
+// notifyModelChangeEventCallback 回调通知注册了回调的接入系统, 并将投递状态记录到事件中
+func notifyModelChangeEventCallback(eventPK int64) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "notifyModelChangeEventCallback")
+
+	svc := service.NewModelChangeService()
+	event, err := svc.Get(eventPK)
+	if err != nil {
+		return errorWrapf(err, "svc.Get eventPK=`%d` fail", eventPK)
+	}
+
+	configSvc := service.NewSystemConfigService()
+	callback, err := configSvc.GetModelChangeEventCallback(event.SystemID)
+	// 系统未注册回调
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return errorWrapf(err, "configSvc.GetModelChangeEventCallback systemID=`%s` fail", event.SystemID)
+	}
+
+	req := component.ModelChangeEventCallbackRequest{}
+	req.URL, _ = callback["url"].(string)
+	req.Token, _ = callback["token"].(string)
+	if timeout, ok := callback["timeout"].(float64); ok {
+		req.Timeout = time.Duration(timeout) * time.Second
+	}
+
+	callbackStatus, callbackMessage := service.ModelChangeEventCallbackStatusSucceeded, ""
+	err = component.BKModelChangeEventCallback.Notify(req, component.ModelChangeEvent{
+		PK:        event.PK,
+		Type:      event.Type,
+		Status:    modelChangeEventStatusFinished,
+		SystemID:  event.SystemID,
+		ModelType: event.ModelType,
+		ModelID:   event.ModelID,
+	})
+	if err != nil {
+		callbackStatus = service.ModelChangeEventCallbackStatusFailed
+		callbackMessage = util.TruncateString(err.Error(), modelChangeEventCallbackMessageMaxLength)
+	}
+
+	err = svc.UpdateCallbackStatusByPK(eventPK, callbackStatus, callbackMessage)
+	if err != nil {
+		return errorWrapf(err, "svc.UpdateCallbackStatusByPK eventPK=`%d`, callbackStatus=`%s` fail",
+			eventPK, callbackStatus)
+	}
+	return nil
+}
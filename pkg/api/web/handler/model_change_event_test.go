@@ -0,0 +1,186 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/component"
+	componentmock "iam/pkg/component/mock"
+	"iam/pkg/database/sdao"
+	sdaomock "iam/pkg/database/sdao/mock"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestUpdateModelChangeEvent(t *testing.T) {
+	url := "/api/v1/web/model-change-event/1"
+	handlerURL := "/api/v1/web/model-change-event/:event_pk"
+
+	event := svctypes.ModelChangeEvent{
+		PK:        1,
+		Type:      "action_policy_deleted",
+		Status:    "pending",
+		SystemID:  "bk_test",
+		ModelType: "action",
+		ModelID:   "edit",
+		ModelPK:   2,
+	}
+
+	t.Run("bad request missing status", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("put", url, UpdateModelChangeEvent, handlerURL)(t).
+			JSON(map[string]interface{}{}).BadRequestContainsMessage("Status is required")
+	})
+
+	t.Run("not finished without callback", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockModelChangeEventService(ctl)
+		mockSvc.EXPECT().UpdateStatusByPK(int64(1), "pending").Return(nil)
+		patches := gomonkey.ApplyFunc(service.NewModelChangeService, func() service.ModelChangeEventService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("put", url, UpdateModelChangeEvent, handlerURL)(t).
+			JSON(map[string]interface{}{"status": "pending"}).OK()
+	})
+
+	t.Run("finished without callback registered", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockModelChangeEventService(ctl)
+		mockSvc.EXPECT().UpdateStatusByPK(int64(1), "finished").Return(nil)
+		mockSvc.EXPECT().Get(int64(1)).Return(event, nil)
+		patches := gomonkey.ApplyFunc(service.NewModelChangeService, func() service.ModelChangeEventService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		mockConfigManager := sdaomock.NewMockSaaSSystemConfigManager(ctl)
+		mockConfigManager.EXPECT().Get("bk_test", service.ConfigKeyModelChangeEventCallback).Return(
+			sdao.SaaSSystemConfig{}, sql.ErrNoRows)
+		patches.ApplyFunc(sdao.NewSaaSSystemConfigManager, func() sdao.SaaSSystemConfigManager {
+			return mockConfigManager
+		})
+
+		util.CreateNewAPIRequestFunc("put", url, UpdateModelChangeEvent, handlerURL)(t).
+			JSON(map[string]interface{}{"status": "finished"}).OK()
+	})
+
+	t.Run("finished with callback", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockModelChangeEventService(ctl)
+		mockSvc.EXPECT().UpdateStatusByPK(int64(1), "finished").Return(nil)
+		mockSvc.EXPECT().Get(int64(1)).Return(event, nil)
+		mockSvc.EXPECT().UpdateCallbackStatusByPK(int64(1), "succeeded", "").Return(nil)
+		patches := gomonkey.ApplyFunc(service.NewModelChangeService, func() service.ModelChangeEventService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		mockConfigManager := sdaomock.NewMockSaaSSystemConfigManager(ctl)
+		mockConfigManager.EXPECT().Get("bk_test", service.ConfigKeyModelChangeEventCallback).Return(
+			sdao.SaaSSystemConfig{
+				Type:  "json",
+				Value: `{"url": "http://bk_test.example.com/callback", "token": "123", "timeout": 3}`,
+			}, nil)
+		patches.ApplyFunc(sdao.NewSaaSSystemConfigManager, func() sdao.SaaSSystemConfigManager {
+			return mockConfigManager
+		})
+
+		mockCallback := componentmock.NewMockModelChangeEventCallbackClient(ctl)
+		mockCallback.EXPECT().Notify(
+			component.ModelChangeEventCallbackRequest{
+				URL:     "http://bk_test.example.com/callback",
+				Token:   "123",
+				Timeout: 3 * time.Second,
+			},
+			component.ModelChangeEvent{
+				PK:        1,
+				Type:      "action_policy_deleted",
+				Status:    "finished",
+				SystemID:  "bk_test",
+				ModelType: "action",
+				ModelID:   "edit",
+			},
+		).Return(nil)
+		oldClient := component.BKModelChangeEventCallback
+		component.BKModelChangeEventCallback = mockCallback
+		defer func() {
+			component.BKModelChangeEventCallback = oldClient
+		}()
+
+		util.CreateNewAPIRequestFunc("put", url, UpdateModelChangeEvent, handlerURL)(t).
+			JSON(map[string]interface{}{"status": "finished"}).OK()
+	})
+
+	t.Run("finished with callback fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockModelChangeEventService(ctl)
+		mockSvc.EXPECT().UpdateStatusByPK(int64(1), "finished").Return(nil)
+		mockSvc.EXPECT().Get(int64(1)).Return(event, nil)
+		mockSvc.EXPECT().UpdateCallbackStatusByPK(int64(1), "failed", "notify fail").Return(nil)
+		patches := gomonkey.ApplyFunc(service.NewModelChangeService, func() service.ModelChangeEventService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		mockConfigManager := sdaomock.NewMockSaaSSystemConfigManager(ctl)
+		mockConfigManager.EXPECT().Get("bk_test", service.ConfigKeyModelChangeEventCallback).Return(
+			sdao.SaaSSystemConfig{Type: "json", Value: `{"url": "http://bk_test.example.com/callback"}`}, nil)
+		patches.ApplyFunc(sdao.NewSaaSSystemConfigManager, func() sdao.SaaSSystemConfigManager {
+			return mockConfigManager
+		})
+
+		mockCallback := componentmock.NewMockModelChangeEventCallbackClient(ctl)
+		mockCallback.EXPECT().Notify(gomock.Any(), gomock.Any()).Return(errors.New("notify fail"))
+		oldClient := component.BKModelChangeEventCallback
+		component.BKModelChangeEventCallback = mockCallback
+		defer func() {
+			component.BKModelChangeEventCallback = oldClient
+		}()
+
+		// the status of event is updated, the callback fail should not fail the request
+		util.CreateNewAPIRequestFunc("put", url, UpdateModelChangeEvent, handlerURL)(t).
+			JSON(map[string]interface{}{"status": "finished"}).OK()
+	})
+}
+
+func TestNotifyModelChangeEventCallbackGetEventFail(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockSvc := mock.NewMockModelChangeEventService(ctl)
+	mockSvc.EXPECT().Get(int64(1)).Return(svctypes.ModelChangeEvent{}, errors.New("get fail"))
+	patches := gomonkey.ApplyFunc(service.NewModelChangeService, func() service.ModelChangeEventService {
+		return mockSvc
+	})
+	defer patches.Reset()
+
+	err := notifyModelChangeEventCallback(1)
+	assert.Error(t, err)
+}
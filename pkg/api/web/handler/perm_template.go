@@ -11,10 +11,14 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/gin-gonic/gin"
 
 	"iam/pkg/abac/prp"
 	"iam/pkg/abac/types"
+	"iam/pkg/api/common"
 	"iam/pkg/errorx"
 	"iam/pkg/util"
 )
@@ -44,6 +48,23 @@ func CreateAndDeleteTemplatePolicies(c *gin.Context) {
 	}
 
 	systemID := body.SystemID
+	if common.IsSystemFrozen(systemID) {
+		common.FrozenSystemJSONResponse(c, systemID)
+		return
+	}
+
+	valid, message, err := validateResourceExpressionAttributeTypes("create_policies", body.CreatePolicies)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "CreateAndDeleteTemplatePolicies",
+			"validateResourceExpressionAttributeTypes systemID=`%s` fail", systemID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+	if !valid {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
 	subject := types.Subject{
 		Type:      body.Subject.Type,
 		ID:        body.Subject.ID,
@@ -57,7 +78,22 @@ func CreateAndDeleteTemplatePolicies(c *gin.Context) {
 	}
 
 	manager := prp.NewPolicyManager()
-	err := manager.CreateAndDeleteTemplatePolicies(systemID, body.Subject.Type, body.Subject.ID, body.TemplateID,
+
+	// 记录模板授权的过期时间, 新增的策略会使用该过期时间
+	if body.ExpiredAt != 0 {
+		_, err = manager.UpdateTemplatePoliciesExpiredAt(
+			systemID, body.Subject.Type, body.Subject.ID, body.TemplateID, body.ExpiredAt)
+		if err != nil {
+			err = errorx.Wrapf(err, "Handler", "CreateAndDeleteTemplatePolicies",
+				"UpdateTemplatePoliciesExpiredAt systemID=`%s`, subjectType=`%s`, subjectID=`%s`, "+
+					"templateID=`%d`, expiredAt=`%d` fail",
+				systemID, body.Subject.Type, body.Subject.ID, body.TemplateID, body.ExpiredAt)
+			util.SystemErrorJSONResponse(c, err)
+			return
+		}
+	}
+
+	err = manager.CreateAndDeleteTemplatePolicies(systemID, body.Subject.Type, body.Subject.ID, body.TemplateID,
 		createPolicies, body.DeletePolicyIDs)
 	if err != nil {
 		err = errorx.Wrapf(err, "Handler", "CreateAndDeleteTemplatePolicies",
@@ -96,6 +132,11 @@ func UpdateTemplatePolicies(c *gin.Context) {
 	}
 
 	systemID := body.SystemID
+	if common.IsSystemFrozen(systemID) {
+		common.FrozenSystemJSONResponse(c, systemID)
+		return
+	}
+
 	subject := types.Subject{
 		Type:      body.Subject.Type,
 		ID:        body.Subject.ID,
@@ -109,6 +150,21 @@ func UpdateTemplatePolicies(c *gin.Context) {
 	}
 
 	manager := prp.NewPolicyManager()
+
+	// 记录模板授权的过期时间, 同步的策略会使用该过期时间
+	if body.ExpiredAt != 0 {
+		_, err := manager.UpdateTemplatePoliciesExpiredAt(
+			systemID, body.Subject.Type, body.Subject.ID, body.TemplateID, body.ExpiredAt)
+		if err != nil {
+			err = errorx.Wrapf(err, "Handler", "UpdateTemplatePolicies",
+				"UpdateTemplatePoliciesExpiredAt systemID=`%s`, subjectType=`%s`, subjectID=`%s`, "+
+					"templateID=`%d`, expiredAt=`%d` fail",
+				systemID, body.Subject.Type, body.Subject.ID, body.TemplateID, body.ExpiredAt)
+			util.SystemErrorJSONResponse(c, err)
+			return
+		}
+	}
+
 	err := manager.UpdateTemplatePolicies(systemID, body.Subject.Type, body.Subject.ID,
 		updatePolicies)
 	if err != nil {
@@ -142,6 +198,11 @@ func DeleteSubjectTemplatePolicies(c *gin.Context) {
 		return
 	}
 
+	if common.IsSystemFrozen(body.SystemID) {
+		common.FrozenSystemJSONResponse(c, body.SystemID)
+		return
+	}
+
 	manager := prp.NewPolicyManager()
 	err := manager.DeleteTemplatePolicies(body.SystemID, body.SubjectType, body.SubjectID, body.TemplateID)
 	if err != nil {
@@ -154,3 +215,145 @@ func DeleteSubjectTemplatePolicies(c *gin.Context) {
 
 	util.SuccessJSONResponse(c, "ok", gin.H{})
 }
+
+// UpdateTemplatePoliciesExpiredAt godoc
+// @Summary update the expiration of template policies/模板授权续期
+// @Description update the expired_at of all the policies generated by the template, in chunks
+// @ID api-web-update-template-policies-expired-at
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param body body templatePolicyExpiredAtSerializer true "the template expiration"
+// @Success 200 {object} util.Response
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/perm-templates/policies/expired_at [put]
+func UpdateTemplatePoliciesExpiredAt(c *gin.Context) {
+	var body templatePolicyExpiredAtSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	if common.IsSystemFrozen(body.SystemID) {
+		common.FrozenSystemJSONResponse(c, body.SystemID)
+		return
+	}
+
+	manager := prp.NewPolicyManager()
+	updated, err := manager.UpdateTemplatePoliciesExpiredAt(
+		body.SystemID, body.SubjectType, body.SubjectID, body.TemplateID, body.ExpiredAt)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "UpdateTemplatePoliciesExpiredAt",
+			"systemID=`%s`, subjectType=`%s`, subjectID=`%s`, templateID=`%d`, expiredAt=`%d`",
+			body.SystemID, body.SubjectType, body.SubjectID, body.TemplateID, body.ExpiredAt)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{"updated": updated})
+}
+
+// AsyncDeleteSubjectTemplatePolicies godoc
+// @Summary async delete template policy/异步解绑模板
+// @Description unbind the template from a subject(user or group), the policies will be deleted in chunks by the background task
+// @ID api-web-async-delete-template-policies
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param body body subjectTemplateSerializer true "unbind the template"
+// @Success 200 {object} util.Response{data=types.TemplateUnbindTask}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/perm-templates/policies/async [delete]
+func AsyncDeleteSubjectTemplatePolicies(c *gin.Context) {
+	var body subjectTemplateSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	if common.IsSystemFrozen(body.SystemID) {
+		common.FrozenSystemJSONResponse(c, body.SystemID)
+		return
+	}
+
+	manager := prp.NewPolicyManager()
+	task, err := manager.AsyncDeleteTemplatePolicies(body.SystemID, body.SubjectType, body.SubjectID, body.TemplateID)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "AsyncDeleteSubjectTemplatePolicies",
+			"systemID=`%s`, subjectType=`%s`, subjectID=`%s`, templateID=`%d`",
+			body.SystemID, body.SubjectType, body.SubjectID, body.TemplateID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", task)
+}
+
+// GetTemplateUnbindTask godoc
+// @Summary get the progress of async template unbinding/查询异步解绑模板的进度
+// @Description get the progress of async template unbinding
+// @ID api-web-get-template-unbind-task
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param task_id path string true "Task ID"
+// @Success 200 {object} util.Response{data=types.TemplateUnbindTask}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/perm-templates/unbind-tasks/{task_id} [get]
+func GetTemplateUnbindTask(c *gin.Context) {
+	taskID := c.Param("task_id")
+
+	task, err := prp.GetTemplateUnbindTask(taskID)
+	if errors.Is(err, prp.ErrTemplateUnbindTaskNotFound) {
+		// the task not exists or expired
+		util.NotFoundJSONResponse(c, fmt.Sprintf("template unbind task(%s)", taskID))
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "GetTemplateUnbindTask", "taskID=`%s`", taskID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", task)
+}
+
+// GetTemplateUnbindTaskEvents godoc
+// @Summary stream the progress of async template unbinding/推送异步解绑模板的进度
+// @Description stream the progress of async template unbinding via SSE, until the task finished or failed
+// @ID api-web-get-template-unbind-task-events
+// @Tags web
+// @Accept json
+// @Produce text/event-stream
+// @Param task_id path string true "Task ID"
+// @Success 200 {object} types.TemplateUnbindTask
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/perm-templates/unbind-tasks/{task_id}/events [get]
+func GetTemplateUnbindTaskEvents(c *gin.Context) {
+	taskID := c.Param("task_id")
+
+	task, err := prp.GetTemplateUnbindTask(taskID)
+	if errors.Is(err, prp.ErrTemplateUnbindTaskNotFound) {
+		// the task not exists or expired
+		util.NotFoundJSONResponse(c, fmt.Sprintf("template unbind task(%s)", taskID))
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "GetTemplateUnbindTaskEvents", "taskID=`%s`", taskID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	streamTaskEvents(c, task, task.Status != types.TemplateUnbindTaskStatusRunning, func() (interface{}, bool, error) {
+		t, err := prp.GetTemplateUnbindTask(taskID)
+		return t, t.Status != types.TemplateUnbindTaskStatusRunning, err
+	})
+}
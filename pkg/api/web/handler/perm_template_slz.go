@@ -25,6 +25,8 @@ type createAndDeleteTemplatePolicySerializer struct {
 	TemplateID      int64    `json:"template_id" binding:"required,min=1"`
 	CreatePolicies  []policy `json:"create_policies" binding:"required"`
 	DeletePolicyIDs []int64  `json:"delete_policy_ids" binding:"required"`
+	// 模板的过期时间, 不为0时记录为模板授权的过期时间, 模板的所有策略都使用该过期时间
+	ExpiredAt int64 `json:"expired_at" binding:"omitempty,min=0,max=4102444800"`
 }
 
 func (slz *createAndDeleteTemplatePolicySerializer) validate() (bool, string) {
@@ -32,6 +34,9 @@ func (slz *createAndDeleteTemplatePolicySerializer) validate() (bool, string) {
 		if valid, message := common.ValidateArray(slz.CreatePolicies); !valid {
 			return false, message
 		}
+		if valid, message := validateResourceExpressions("create_policies", slz.CreatePolicies); !valid {
+			return false, message
+		}
 	}
 	return true, ""
 }
@@ -41,6 +46,8 @@ type updateTemplatePolicySerializer struct {
 	SystemID       string         `json:"system_id" binding:"required"`
 	TemplateID     int64          `json:"template_id" binding:"required,min=1"`
 	UpdatePolicies []updatePolicy `json:"update_policies" binding:"required"`
+	// 模板的过期时间, 不为0时记录为模板授权的过期时间, 模板的所有策略都使用该过期时间
+	ExpiredAt int64 `json:"expired_at" binding:"omitempty,min=0,max=4102444800"`
 }
 
 func (slz *updateTemplatePolicySerializer) validate() (bool, string) {
@@ -50,3 +57,8 @@ func (slz *updateTemplatePolicySerializer) validate() (bool, string) {
 
 	return true, ""
 }
+
+type templatePolicyExpiredAtSerializer struct {
+	subjectTemplateSerializer
+	ExpiredAt int64 `json:"expired_at" binding:"required,min=1,max=4102444800"`
+}
@@ -16,9 +16,12 @@ import (
 
 	"github.com/agiledragon/gomonkey"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 
+	"iam/pkg/abac/pip"
 	"iam/pkg/abac/prp"
 	"iam/pkg/abac/prp/mock"
+	"iam/pkg/abac/types"
 	"iam/pkg/util"
 )
 
@@ -108,6 +111,73 @@ func TestCreateAndDeleteTemplatePolicies(t *testing.T) {
 				"delete_policy_ids": []int64{1},
 			}).OK()
 	})
+
+	t.Run("template expired_at error", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockManager := mock.NewMockPolicyManager(ctl)
+		mockManager.EXPECT().UpdateTemplatePoliciesExpiredAt(
+			"test", "user", "test", int64(1), int64(4102444800),
+		).Return(
+			int64(0), errors.New("update expired_at fail"),
+		)
+		patches = gomonkey.ApplyFunc(prp.NewPolicyManager, func() prp.PolicyManager {
+			return mockManager
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject":           map[string]interface{}{"type": "user", "id": "test"},
+				"system_id":         "test",
+				"template_id":       int64(1),
+				"expired_at":        int64(4102444800),
+				"create_policies":   []map[string]interface{}{},
+				"delete_policy_ids": []int64{1},
+			}).SystemError()
+	})
+
+	t.Run("ok with template expired_at", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockManager := mock.NewMockPolicyManager(ctl)
+		gomock.InOrder(
+			mockManager.EXPECT().UpdateTemplatePoliciesExpiredAt(
+				"test", "user", "test", int64(1), int64(4102444800),
+			).Return(
+				int64(2), nil,
+			),
+			mockManager.EXPECT().CreateAndDeleteTemplatePolicies(
+				"test", "user", "test", int64(1), gomock.Any(), []int64{},
+			).DoAndReturn(
+				func(systemID, subjectType, subjectID string, templateID int64,
+					createPolicies []types.Policy, deletePolicyIDs []int64,
+				) error {
+					assert.Len(t, createPolicies, 1)
+					return nil
+				},
+			),
+		)
+		patches = gomonkey.ApplyFunc(prp.NewPolicyManager, func() prp.PolicyManager {
+			return mockManager
+		})
+		patches.ApplyFunc(pip.GetResourceAttributeSchema, func(system, _type string) (map[string]string, error) {
+			return map[string]string{}, nil
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject":     map[string]interface{}{"type": "user", "id": "test"},
+				"system_id":   "test",
+				"template_id": int64(1),
+				"expired_at":  int64(4102444800),
+				"create_policies": []map[string]interface{}{{
+					"action_id":           "edit",
+					"resource_expression": `[{"system": "test", "type": "app", "expression": {"Any": {"id": []}}}]`,
+					"expired_at":          int64(100),
+				}},
+				"delete_policy_ids": []int64{},
+			}).OK()
+	})
 }
 
 func TestUpdateTemplatePolicies(t *testing.T) {
@@ -208,4 +278,191 @@ func TestUpdateTemplatePolicies(t *testing.T) {
 				"delete_policy_ids": []int64{1},
 			}).OK()
 	})
+
+	t.Run("ok with template expired_at", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockManager := mock.NewMockPolicyManager(ctl)
+		gomock.InOrder(
+			mockManager.EXPECT().UpdateTemplatePoliciesExpiredAt(
+				"test", "user", "test", int64(1), int64(4102444800),
+			).Return(
+				int64(1), nil,
+			),
+			mockManager.EXPECT().UpdateTemplatePolicies(
+				"test", "user", "test", gomock.Any(),
+			).Return(
+				nil,
+			),
+		)
+		patches = gomonkey.ApplyFunc(prp.NewPolicyManager, func() prp.PolicyManager {
+			return mockManager
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject":     map[string]interface{}{"type": "user", "id": "test"},
+				"system_id":   "test",
+				"template_id": int64(1),
+				"expired_at":  int64(4102444800),
+				"update_policies": []map[string]interface{}{
+					{
+						"id":                  int64(1),
+						"action_id":           "test",
+						"resource_expression": "test",
+						"expired_at":          1,
+					},
+				},
+			}).OK()
+	})
+}
+
+func TestAsyncDeleteSubjectTemplatePolicies(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"delete", "/api/v1/perm-templates/policies/async", AsyncDeleteSubjectTemplatePolicies,
+	)
+
+	t.Run("no json", func(t *testing.T) {
+		newRequestFunc(t).NoJSON()
+	})
+
+	t.Run("bad request invalid template_id", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject_type": "user",
+				"subject_id":   "test",
+				"system_id":    "test",
+				"template_id":  int64(0),
+			}).BadRequestContainsMessage("TemplateID is required")
+	})
+
+	var ctl *gomock.Controller
+	var patches *gomonkey.Patches
+
+	restMock := func() {
+		ctl.Finish()
+		if patches != nil {
+			patches.Reset()
+		}
+	}
+
+	t.Run("manager error", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockManager := mock.NewMockPolicyManager(ctl)
+		mockManager.EXPECT().AsyncDeleteTemplatePolicies(
+			"test", "user", "test", int64(1),
+		).Return(
+			types.TemplateUnbindTask{}, errors.New("create task fail"),
+		).AnyTimes()
+		patches = gomonkey.ApplyFunc(prp.NewPolicyManager, func() prp.PolicyManager {
+			return mockManager
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject_type": "user",
+				"subject_id":   "test",
+				"system_id":    "test",
+				"template_id":  int64(1),
+			}).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockManager := mock.NewMockPolicyManager(ctl)
+		mockManager.EXPECT().AsyncDeleteTemplatePolicies(
+			"test", "user", "test", int64(1),
+		).Return(
+			types.TemplateUnbindTask{ID: "abc", Status: types.TemplateUnbindTaskStatusRunning, Total: 10}, nil,
+		).AnyTimes()
+		patches = gomonkey.ApplyFunc(prp.NewPolicyManager, func() prp.PolicyManager {
+			return mockManager
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject_type": "user",
+				"subject_id":   "test",
+				"system_id":    "test",
+				"template_id":  int64(1),
+			}).OK()
+	})
+}
+
+func TestUpdateTemplatePoliciesExpiredAt(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"put", "/api/v1/perm-templates/policies/expired_at", UpdateTemplatePoliciesExpiredAt,
+	)
+
+	t.Run("no json", func(t *testing.T) {
+		newRequestFunc(t).NoJSON()
+	})
+
+	t.Run("bad request invalid expired_at", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject_type": "user",
+				"subject_id":   "test",
+				"system_id":    "test",
+				"template_id":  int64(1),
+			}).BadRequestContainsMessage("ExpiredAt is required")
+	})
+
+	var ctl *gomock.Controller
+	var patches *gomonkey.Patches
+
+	restMock := func() {
+		ctl.Finish()
+		if patches != nil {
+			patches.Reset()
+		}
+	}
+
+	t.Run("manager error", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockManager := mock.NewMockPolicyManager(ctl)
+		mockManager.EXPECT().UpdateTemplatePoliciesExpiredAt(
+			"test", "user", "test", int64(1), int64(4102444800),
+		).Return(
+			int64(0), errors.New("update fail"),
+		).AnyTimes()
+		patches = gomonkey.ApplyFunc(prp.NewPolicyManager, func() prp.PolicyManager {
+			return mockManager
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject_type": "user",
+				"subject_id":   "test",
+				"system_id":    "test",
+				"template_id":  int64(1),
+				"expired_at":   int64(4102444800),
+			}).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockManager := mock.NewMockPolicyManager(ctl)
+		mockManager.EXPECT().UpdateTemplatePoliciesExpiredAt(
+			"test", "user", "test", int64(1), int64(4102444800),
+		).Return(
+			int64(10), nil,
+		).AnyTimes()
+		patches = gomonkey.ApplyFunc(prp.NewPolicyManager, func() prp.PolicyManager {
+			return mockManager
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject_type": "user",
+				"subject_id":   "test",
+				"system_id":    "test",
+				"template_id":  int64(1),
+				"expired_at":   int64(4102444800),
+			}).OK()
+	})
 }
@@ -17,6 +17,7 @@ import (
 	"iam/pkg/abac/pip"
 	"iam/pkg/abac/prp"
 	"iam/pkg/abac/types"
+	"iam/pkg/api/common"
 	"iam/pkg/errorx"
 	"iam/pkg/service"
 	"iam/pkg/util"
@@ -73,6 +74,8 @@ func convertToInternalTypesPolicy(systemID string, subject types.Subject, id, te
 			Attribute: types.NewActionAttribute(),
 		},
 		Expression: policy.ResourceExpression,
+		Effect:     policy.Effect,
+		Priority:   policy.Priority,
 		ExpiredAt:  policy.ExpiredAt,
 		TemplateID: templateID,
 	}
@@ -108,6 +111,18 @@ func AlterPolicies(c *gin.Context) {
 
 	systemID := c.Param("system_id")
 
+	valid, message, err := validateResourceExpressionAttributeTypes("create_policies", body.CreatePolicies)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "AlterPolicies",
+			"validateResourceExpressionAttributeTypes systemID=`%s` fail", systemID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+	if !valid {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
 	subject := types.Subject{
 		Type:      body.Subject.Type,
 		ID:        body.Subject.ID,
@@ -127,7 +142,7 @@ func AlterPolicies(c *gin.Context) {
 	}
 
 	manager := prp.NewPolicyManager()
-	err := manager.AlterCustomPolicies(systemID, body.Subject.Type, body.Subject.ID,
+	err = manager.AlterCustomPolicies(systemID, body.Subject.Type, body.Subject.ID,
 		createPolicies, updatePolicies, body.DeletePolicyIDs)
 	if err != nil {
 		err = errorx.Wrapf(err, "Handler", "AlterPolicies",
@@ -160,6 +175,11 @@ func BatchDeletePolicies(c *gin.Context) {
 		return
 	}
 
+	if common.IsSystemFrozen(body.SystemID) {
+		common.FrozenSystemJSONResponse(c, body.SystemID)
+		return
+	}
+
 	manager := prp.NewPolicyManager()
 	err := manager.DeleteByIDs(body.SystemID, body.SubjectType, body.SubjectID, body.IDs)
 	if err != nil {
@@ -210,11 +230,12 @@ func GetCustomPolicy(c *gin.Context) {
 	}
 
 	if policy.Expression == "" {
-		util.SuccessJSONResponse(c, "ok", gin.H{"policy_id": policy.ID, "expression": map[string]interface{}{
-			"op":    "any",
-			"field": "",
-			"value": []interface{}{},
-		}})
+		util.SuccessJSONResponse(c, "ok", gin.H{"policy_id": policy.ID, "effect": policy.Effect, "priority": policy.Priority,
+			"expression": map[string]interface{}{
+				"op":    "any",
+				"field": "",
+				"value": []interface{}{},
+			}})
 		return
 	}
 
@@ -226,7 +247,9 @@ func GetCustomPolicy(c *gin.Context) {
 		return
 	}
 
-	expr, err := translate.PoliciesTranslate([]types.AuthPolicy{policy}, actionResourceTypes)
+	// NOTE: 展示的是策略本身的资源范围, 所以翻译时不带effect, 避免deny策略被取反
+	scope := types.AuthPolicy{ID: policy.ID, Expression: policy.Expression}
+	expr, err := translate.PoliciesTranslate([]types.AuthPolicy{scope}, actionResourceTypes)
 	if err != nil {
 		err = errorWrapf(err, "system=`%s`, subjectType=`%s`, subjectID=`%s`, actionID=`%+v`",
 			systemID, query.SubjectType, query.SubjectID, query.ActionID)
@@ -234,7 +257,12 @@ func GetCustomPolicy(c *gin.Context) {
 		return
 	}
 
-	util.SuccessJSONResponse(c, "ok", gin.H{"policy_id": policy.ID, "expression": expr})
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"policy_id":  policy.ID,
+		"effect":     policy.Effect,
+		"priority":   policy.Priority,
+		"expression": expr,
+	})
 }
 
 // ListPolicy godoc
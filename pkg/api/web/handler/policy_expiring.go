@@ -0,0 +1,150 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/cache/impls"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// ListExpiringPolicy godoc
+// @Summary List expiring policies/查询即将过期的策略
+// @Description cursor-based list of the policies which will expire in N days, for renewal notification
+// @ID api-web-list-expiring-policy
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param params query expiringPolicySerializer true "the list request"
+// @Success 200 {object} util.Response{data=expiringPolicyListResponse}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/policies/expiring [get]
+func ListExpiringPolicy(c *gin.Context) {
+	var query expiringPolicySerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	query.initDefault()
+
+	// 已过期的不返回, 只返回 (now, now + N days] 之间过期的
+	// 首页的now记录在游标中, 翻页时使用同一个时间窗口
+	cursor := expiringPolicyCursor{BeginExpiredAt: time.Now().Unix()}
+	if query.Cursor != "" {
+		var err error
+		cursor, err = parseExpiringPolicyCursor(query.Cursor)
+		if err != nil {
+			util.BadRequestErrorJSONResponse(c, err.Error())
+			return
+		}
+	}
+	beginExpiredAt := cursor.BeginExpiredAt
+	endExpiredAt := beginExpiredAt + query.Days*24*60*60
+
+	svc := service.NewPolicyService()
+	policies, err := svc.ListPagingQueryBetweenExpiredAtAfterPK(
+		cursor.ExpiredAt, cursor.PK, beginExpiredAt, endExpiredAt, query.Limit)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "ListExpiringPolicy",
+			"svc.ListPagingQueryBetweenExpiredAtAfterPK cursor=`%s`, endExpiredAt=`%d`, limit=`%d` fail",
+			query.Cursor, endExpiredAt, query.Limit)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	results, err := convertToExpiringPolicies(policies)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "ListExpiringPolicy",
+			"convertToExpiringPolicies policies length=`%d` fail", len(policies))
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	// 游标为本页最后一条策略的(expired_at, pk), 即使该策略的subject/action已被删除而被忽略
+	if len(policies) > 0 {
+		last := policies[len(policies)-1]
+		cursor.ExpiredAt, cursor.PK = last.ExpiredAt, last.PK
+	}
+	data := expiringPolicyListResponse{
+		NextCursor: cursor.String(),
+		HasMore:    int64(len(policies)) == query.Limit,
+		Results:    results,
+	}
+
+	util.SuccessJSONResponse(c, "ok", data)
+}
+
+func convertToExpiringPolicies(policies []svctypes.QueryPolicy) ([]expiringPolicyResponse, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "convertToExpiringPolicies")
+
+	results := make([]expiringPolicyResponse, 0, len(policies))
+	// system -> action id -> action, 同一页中的策略大多属于少量的系统
+	systemActions := map[string]map[string]svctypes.Action{}
+	actionSvc := service.NewActionService()
+
+	for _, p := range policies {
+		// subject或action可能已被删除, 策略还未清理, 忽略
+		subject, err := impls.GetSubjectByPK(p.SubjectPK)
+		if err != nil {
+			log.Infof("convertToExpiringPolicies impls.GetSubjectByPK subjectPK=`%d` fail, err=%s",
+				p.SubjectPK, err)
+			continue
+		}
+
+		thinAction, err := impls.GetAction(p.ActionPK)
+		if err != nil {
+			log.Infof("convertToExpiringPolicies impls.GetAction actionPK=`%d` fail, err=%s",
+				p.ActionPK, err)
+			continue
+		}
+
+		actions, ok := systemActions[thinAction.System]
+		if !ok {
+			svcActions, err := actionSvc.ListBySystem(thinAction.System)
+			if err != nil {
+				return nil, errorWrapf(err, "actionSvc.ListBySystem system=`%s` fail", thinAction.System)
+			}
+
+			actions = make(map[string]svctypes.Action, len(svcActions))
+			for _, a := range svcActions {
+				actions[a.ID] = a
+			}
+			systemActions[thinAction.System] = actions
+		}
+		action := actions[thinAction.ID]
+
+		results = append(results, expiringPolicyResponse{
+			ID:     p.PK,
+			System: thinAction.System,
+			Action: expiringPolicyAction{
+				ID:     thinAction.ID,
+				Name:   action.Name,
+				NameEn: action.NameEn,
+			},
+			Subject: expiringPolicySubject{
+				Type: subject.Type,
+				ID:   subject.ID,
+				Name: subject.Name,
+			},
+			ExpiredAt: p.ExpiredAt,
+		})
+	}
+	return results, nil
+}
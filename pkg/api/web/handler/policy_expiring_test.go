@@ -0,0 +1,146 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache/impls"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestListExpiringPolicy(t *testing.T) {
+	var ctl *gomock.Controller
+	var patches *gomonkey.Patches
+
+	restMock := func() {
+		ctl.Finish()
+		if patches != nil {
+			patches.Reset()
+		}
+	}
+
+	t.Run("bad request without days", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc(
+			"get", "/api/v1/web/policies/expiring", ListExpiringPolicy,
+		)(t).BadRequest("bad request:Days is required")
+	})
+
+	t.Run("bad request days too large", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc(
+			"get", "/api/v1/web/policies/expiring", ListExpiringPolicy,
+		)(t).QueryParams(map[string]string{"days": "100"}).BadRequestContainsMessage("Days")
+	})
+
+	t.Run("bad request invalid cursor", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc(
+			"get", "/api/v1/web/policies/expiring", ListExpiringPolicy,
+		)(t).QueryParams(map[string]string{"days": "7", "cursor": "10"}).BadRequestContainsMessage("cursor")
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockSvc := mock.NewMockPolicyService(ctl)
+		mockSvc.EXPECT().ListPagingQueryBetweenExpiredAtAfterPK(
+			int64(0), int64(0), gomock.Any(), gomock.Any(), int64(100),
+		).Return(nil, errors.New("list fail")).AnyTimes()
+		patches = gomonkey.ApplyFunc(service.NewPolicyService, func() service.PolicyService {
+			return mockSvc
+		})
+		defer restMock()
+
+		util.CreateNewAPIRequestFunc(
+			"get", "/api/v1/web/policies/expiring", ListExpiringPolicy,
+		)(t).QueryParams(map[string]string{"days": "7"}).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockSvc := mock.NewMockPolicyService(ctl)
+		// 翻页时使用游标中的时间窗口
+		mockSvc.EXPECT().ListPagingQueryBetweenExpiredAtAfterPK(
+			int64(90), int64(10), int64(50), int64(50+7*24*60*60), int64(2),
+		).Return([]svctypes.QueryPolicy{
+			{PK: 11, SubjectPK: 1, ActionPK: 1, ExpiredAt: 100},
+			{PK: 12, SubjectPK: 2, ActionPK: 1, ExpiredAt: 100},
+		}, nil).AnyTimes()
+		mockActionSvc := mock.NewMockActionService(ctl)
+		mockActionSvc.EXPECT().ListBySystem("bk_test").Return([]svctypes.Action{
+			{ID: "edit", Name: "编辑", NameEn: "edit"},
+		}, nil).Times(1)
+
+		patches = gomonkey.ApplyFunc(service.NewPolicyService, func() service.PolicyService {
+			return mockSvc
+		})
+		patches.ApplyFunc(service.NewActionService, func() service.ActionService {
+			return mockActionSvc
+		})
+		patches.ApplyFunc(impls.GetSubjectByPK, func(pk int64) (svctypes.Subject, error) {
+			// subject 2 deleted
+			if pk == 2 {
+				return svctypes.Subject{}, errors.New("not exists")
+			}
+			return svctypes.Subject{Type: "user", ID: "admin", Name: "admin"}, nil
+		})
+		patches.ApplyFunc(impls.GetAction, func(pk int64) (svctypes.ThinAction, error) {
+			return svctypes.ThinAction{PK: pk, System: "bk_test", ID: "edit"}, nil
+		})
+		defer restMock()
+
+		util.CreateNewAPIRequestFunc(
+			"get", "/api/v1/web/policies/expiring", ListExpiringPolicy,
+		)(t).QueryParams(map[string]string{"days": "7", "cursor": "50_90_10", "limit": "2"}).OK()
+	})
+}
+
+func TestConvertToExpiringPolicies(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockActionSvc := mock.NewMockActionService(ctl)
+	mockActionSvc.EXPECT().ListBySystem("bk_test").Return(nil, errors.New("list fail")).Times(1)
+
+	patches := gomonkey.ApplyFunc(service.NewActionService, func() service.ActionService {
+		return mockActionSvc
+	})
+	defer patches.Reset()
+	patches.ApplyFunc(impls.GetSubjectByPK, func(pk int64) (svctypes.Subject, error) {
+		return svctypes.Subject{Type: "user", ID: "admin", Name: "admin"}, nil
+	})
+	patches.ApplyFunc(impls.GetAction, func(pk int64) (svctypes.ThinAction, error) {
+		return svctypes.ThinAction{PK: pk, System: "bk_test", ID: "edit"}, nil
+	})
+
+	_, err := convertToExpiringPolicies([]svctypes.QueryPolicy{{PK: 1, SubjectPK: 1, ActionPK: 1}})
+	if err == nil {
+		t.Error("should return error while ListBySystem fail")
+	}
+}
+
+func TestParseExpiringPolicyCursor(t *testing.T) {
+	c, err := parseExpiringPolicyCursor("50_90_10")
+	assert.NoError(t, err)
+	assert.Equal(t, expiringPolicyCursor{BeginExpiredAt: 50, ExpiredAt: 90, PK: 10}, c)
+	assert.Equal(t, "50_90_10", c.String())
+
+	for _, cursor := range []string{"10", "a_1_2", "1_-1_2", "1_2_3_4"} {
+		_, err = parseExpiringPolicyCursor(cursor)
+		assert.Error(t, err, cursor)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// EvalHypotheticalSubject godoc
+// @Summary Eval hypothetical subject/虚拟用户鉴权
+// @Description eval the auth requests of a hypothetical user defined inline(departments + groups)
+// @Description the user does not need to exist, nothing will be written to the db or the policy cache
+// @ID api-web-eval-hypothetical-subject
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param system_id path string true "system id"
+// @Param body body hypotheticalAuthSerializer true "the hypothetical subject and the auth requests"
+// @Success 200 {object} util.Response{data=[]hypotheticalAuthResult}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/systems/{system_id}/policies/hypothetical-auth [post]
+func EvalHypotheticalSubject(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "EvalHypotheticalSubject")
+
+	var body hypotheticalAuthSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	if ok, message := body.validate(); !ok {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
+	systemID := c.Param("system_id")
+
+	// 部门和用户组必须存在
+	svc := service.NewSubjectService()
+	departmentPKs, err := listExistSubjectPKs(svc, svctypes.DepartmentType, body.Subject.Departments)
+	if err != nil {
+		if errors.Is(err, errSubjectNotExists) {
+			util.BadRequestErrorJSONResponse(c, err.Error())
+			return
+		}
+
+		err = errorWrapf(err, "listExistSubjectPKs departments=`%v`", body.Subject.Departments)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+	groupPKs, err := listExistSubjectPKs(svc, svctypes.GroupType, body.Subject.Groups)
+	if err != nil {
+		if errors.Is(err, errSubjectNotExists) {
+			util.BadRequestErrorJSONResponse(c, err.Error())
+			return
+		}
+
+		err = errorWrapf(err, "listExistSubjectPKs groups=`%v`", body.Subject.Groups)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	reqs := make([]*request.Request, 0, len(body.Requests))
+	for _, r := range body.Requests {
+		req := request.NewRequest()
+		req.System = systemID
+		req.Action.ID = r.Action.ID
+		for _, resource := range r.Resources {
+			req.Resources = append(req.Resources, types.Resource{
+				System:    resource.System,
+				Type:      resource.Type,
+				ID:        resource.ID,
+				Attribute: resource.Attribute,
+			})
+		}
+		reqs = append(reqs, req)
+	}
+
+	subject := pdp.HypotheticalSubject{
+		DepartmentPKs: departmentPKs,
+		GroupPKs:      groupPKs,
+	}
+	allowed, err := pdp.EvalHypothetical(systemID, subject, reqs)
+	if err != nil {
+		if errors.Is(err, pdp.ErrTooManyEvaluations) {
+			util.TooManyRequestsJSONResponse(c, err.Error())
+			return
+		}
+		if errors.Is(err, pdp.ErrInvalidAction) {
+			util.BadRequestErrorJSONResponse(c, err.Error())
+			return
+		}
+
+		err = errorWrapf(err, "systemID=`%s`, subject=`%+v`", systemID, subject)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	results := make([]hypotheticalAuthResult, 0, len(allowed))
+	for i, isPass := range allowed {
+		results = append(results, hypotheticalAuthResult{
+			Action:  body.Requests[i].Action.ID,
+			Allowed: isPass,
+		})
+	}
+	util.SuccessJSONResponse(c, "ok", results)
+}
+
+var errSubjectNotExists = errors.New("subject not exists")
+
+// listExistSubjectPKs 查询subject的pk, 有不存在的subject时返回errSubjectNotExists
+func listExistSubjectPKs(svc service.SubjectService, _type string, ids []string) ([]int64, error) {
+	if len(ids) == 0 {
+		return []int64{}, nil
+	}
+
+	uniqueIDs := util.NewStringSetWithValues(ids).ToSlice()
+	subjects := make([]svctypes.Subject, 0, len(uniqueIDs))
+	for _, id := range uniqueIDs {
+		subjects = append(subjects, svctypes.Subject{Type: _type, ID: id})
+	}
+
+	pks, err := svc.ListPKsBySubjects(subjects)
+	if err != nil {
+		return nil, err
+	}
+	if len(pks) != len(uniqueIDs) {
+		return nil, fmt.Errorf("%w: some of the %s `%v` not exists", errSubjectNotExists, _type, uniqueIDs)
+	}
+	return pks, nil
+}
@@ -0,0 +1,125 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestEvalHypotheticalSubject(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"post", "/api/v1/web/systems/bk_test/policies/hypothetical-auth", EvalHypotheticalSubject,
+		"/api/v1/web/systems/:system_id/policies/hypothetical-auth",
+	)
+
+	body := map[string]interface{}{
+		"subject": map[string]interface{}{"departments": []string{"10"}, "groups": []string{"1"}},
+		"requests": []map[string]interface{}{{
+			"action": map[string]interface{}{"id": "view"},
+			"resources": []map[string]interface{}{
+				{"system": "bk_test", "type": "app", "id": "a1"},
+			},
+		}},
+	}
+
+	t.Run("no json", func(t *testing.T) {
+		newRequestFunc(t).NoJSON()
+	})
+
+	t.Run("bad request empty requests", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject":  body["subject"],
+				"requests": []map[string]interface{}{},
+			}).BadRequestContainsMessage("Requests")
+	})
+
+	t.Run("bad request invalid requests", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject": body["subject"],
+				"requests": []map[string]interface{}{{
+					"resources": []map[string]interface{}{},
+				}},
+			}).BadRequestContainsMessage("data in array[0]")
+	})
+
+	var ctl *gomock.Controller
+	var patches *gomonkey.Patches
+
+	newPatches := func(departmentPKs []int64, listErr, evalErr error) {
+		ctl = gomock.NewController(t)
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().ListPKsBySubjects(
+			[]svctypes.Subject{{Type: "department", ID: "10"}},
+		).Return(departmentPKs, listErr).AnyTimes()
+		mockSvc.EXPECT().ListPKsBySubjects(
+			[]svctypes.Subject{{Type: "group", ID: "1"}},
+		).Return([]int64{1}, nil).AnyTimes()
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		patches.ApplyFunc(pdp.EvalHypothetical, func(
+			system string, subject pdp.HypotheticalSubject, reqs []*request.Request,
+		) ([]bool, error) {
+			return []bool{true}, evalErr
+		})
+	}
+	restMock := func() {
+		ctl.Finish()
+		patches.Reset()
+	}
+
+	t.Run("department not exists", func(t *testing.T) {
+		newPatches([]int64{}, nil, nil)
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).BadRequestContainsMessage("not exists")
+	})
+
+	t.Run("list pks error", func(t *testing.T) {
+		newPatches(nil, errors.New("list fail"), nil)
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).SystemError()
+	})
+
+	t.Run("invalid action", func(t *testing.T) {
+		newPatches([]int64{10}, nil, pdp.ErrInvalidAction)
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).BadRequestContainsMessage(pdp.ErrInvalidAction.Error())
+	})
+
+	t.Run("eval error", func(t *testing.T) {
+		newPatches([]int64{10}, nil, errors.New("eval fail"))
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		newPatches([]int64{10}, nil, nil)
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).OK()
+	})
+}
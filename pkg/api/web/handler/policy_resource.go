@@ -0,0 +1,219 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/prp"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/cache/impls"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// anyExpressionPK is the pk for expression=any
+const anyExpressionPK = -1
+
+// ListResourcePolicy godoc
+// @Summary List resource policies/反查资源实例的授权策略
+// @Description cursor-based scan of the policies of the action, return the policies granting the resource instance
+// @Description for the "who can access this resource" audit view; the deny policies are included
+// @ID api-web-list-resource-policy
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param system_id path string true "system id"
+// @Param body body resourcePolicySerializer true "the action, the resource instance and the cursor"
+// @Success 200 {object} util.Response{data=resourcePolicyListResponse}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/systems/{system_id}/policies/by-resource [post]
+func ListResourcePolicy(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "ListResourcePolicy")
+
+	var body resourcePolicySerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	body.initDefault()
+
+	systemID := c.Param("system_id")
+
+	actionPK, err := impls.GetActionPK(systemID, body.Action.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.BadRequestErrorJSONResponse(c, fmt.Sprintf("action(%s) not exists", body.Action.ID))
+			return
+		}
+
+		util.SystemErrorJSONResponse(c, errorWrapf(err, "impls.GetActionPK system=`%s`, action=`%s` fail",
+			systemID, body.Action.ID))
+		return
+	}
+
+	// NOTE: 没有对接iam-engine, 从DB按策略pk分页扫描操作的所有策略, 逐条计算
+	svc := service.NewEnginePolicyService()
+	queryPolicies, err := svc.ListAfterPKByActionPKs(time.Now().Unix(), body.Cursor, []int64{actionPK}, body.Limit)
+	if err != nil {
+		err = errorWrapf(err, "svc.ListAfterPKByActionPKs actionPK=`%d`, cursor=`%d`, limit=`%d` fail",
+			actionPK, body.Cursor, body.Limit)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	policies, err := convertToResourceAuthPolicies(actionPK, queryPolicies)
+	if err != nil {
+		err = errorWrapf(err, "convertToResourceAuthPolicies policies length=`%d` fail", len(queryPolicies))
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	req := request.NewRequest()
+	req.System = systemID
+	req.Action.ID = body.Action.ID
+	for _, resource := range body.Resources {
+		req.Resources = append(req.Resources, types.Resource{
+			System:    resource.System,
+			Type:      resource.Type,
+			ID:        resource.ID,
+			Attribute: resource.Attribute,
+		})
+	}
+
+	filteredPolicies, err := pdp.FilterResourcePolicies(req, policies)
+	if err != nil {
+		if errors.Is(err, pdp.ErrTooManyEvaluations) {
+			util.TooManyRequestsJSONResponse(c, err.Error())
+			return
+		}
+		if errors.Is(err, pdp.ErrInvalidAction) || errors.Is(err, pdp.ErrInvalidActionResource) {
+			util.BadRequestErrorJSONResponse(c, err.Error())
+			return
+		}
+
+		err = errorWrapf(err, "pdp.FilterResourcePolicies system=`%s`, action=`%s`, resources=`%+v` fail",
+			systemID, body.Action.ID, body.Resources)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	// 游标为本页扫描的最后一条策略的pk, 即使该策略不满足或subject已被删除
+	data := resourcePolicyListResponse{
+		NextCursor: body.Cursor,
+		HasMore:    int64(len(queryPolicies)) == body.Limit,
+		Results:    convertToResourcePolicies(queryPolicies, filteredPolicies),
+	}
+	if len(queryPolicies) > 0 {
+		data.NextCursor = queryPolicies[len(queryPolicies)-1].PK
+	}
+
+	util.SuccessJSONResponse(c, "ok", data)
+}
+
+func convertToResourceAuthPolicies(
+	actionPK int64,
+	queryPolicies []svctypes.EngineQueryPolicy,
+) ([]types.AuthPolicy, error) {
+	expressionPKs := make([]int64, 0, len(queryPolicies))
+	for _, p := range queryPolicies {
+		if p.ExpressionPK != anyExpressionPK {
+			expressionPKs = append(expressionPKs, p.ExpressionPK)
+		}
+	}
+
+	expressions := map[int64]svctypes.AuthExpression{
+		// NOTE: -1 for the `any`
+		anyExpressionPK: {PK: anyExpressionPK},
+	}
+	if len(expressionPKs) > 0 {
+		exprs, err := prp.NewPolicyManager().GetExpressionsFromCache(actionPK, expressionPKs)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range exprs {
+			expressions[e.PK] = e
+		}
+	}
+
+	policies := make([]types.AuthPolicy, 0, len(queryPolicies))
+	for _, p := range queryPolicies {
+		expr, ok := expressions[p.ExpressionPK]
+		if !ok {
+			log.Errorf("convertToResourceAuthPolicies p.ExpressionPK=`%d` missing in expressions", p.ExpressionPK)
+			continue
+		}
+
+		policies = append(policies, types.AuthPolicy{
+			Version:             service.PolicyVersion,
+			ID:                  p.PK,
+			ExpressionPK:        p.ExpressionPK,
+			Expression:          expr.Expression,
+			ExpressionSignature: expr.Signature,
+			ExpiredAt:           p.ExpiredAt,
+			Effect:              p.Effect,
+			Priority:            p.Priority,
+		})
+	}
+	return policies, nil
+}
+
+func convertToResourcePolicies(
+	queryPolicies []svctypes.EngineQueryPolicy,
+	filteredPolicies []types.AuthPolicy,
+) []resourcePolicyResponse {
+	queryPolicyMap := make(map[int64]svctypes.EngineQueryPolicy, len(queryPolicies))
+	for _, p := range queryPolicies {
+		queryPolicyMap[p.PK] = p
+	}
+
+	results := make([]resourcePolicyResponse, 0, len(filteredPolicies))
+	for _, policy := range filteredPolicies {
+		p := queryPolicyMap[policy.ID]
+
+		// subject可能已被删除, 策略还未清理, 忽略
+		subject, err := impls.GetSubjectByPK(p.SubjectPK)
+		if err != nil {
+			log.Infof("convertToResourcePolicies impls.GetSubjectByPK subjectPK=`%d` fail, err=%s",
+				p.SubjectPK, err)
+			continue
+		}
+
+		effect := svctypes.PolicyEffectAllow
+		if policy.IsDeny() {
+			effect = svctypes.PolicyEffectDeny
+		}
+
+		results = append(results, resourcePolicyResponse{
+			ID: p.PK,
+			Subject: resourcePolicySubject{
+				Type: subject.Type,
+				ID:   subject.ID,
+				Name: subject.Name,
+			},
+			Effect:     effect,
+			TemplateID: p.TemplateID,
+			ExpiredAt:  p.ExpiredAt,
+		})
+	}
+	return results
+}
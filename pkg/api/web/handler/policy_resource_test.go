@@ -0,0 +1,184 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/steinfletcher/apitest"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/prp"
+	prpmock "iam/pkg/abac/prp/mock"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/cache/impls"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestListResourcePolicy(t *testing.T) {
+	url := "/api/v1/web/systems/bk_test/policies/by-resource"
+	routeURL := "/api/v1/web/systems/:system_id/policies/by-resource"
+	newRequestFunc := util.CreateNewAPIRequestFunc("post", url, ListResourcePolicy, routeURL)
+
+	body := map[string]interface{}{
+		"action": map[string]interface{}{"id": "view"},
+		"resources": []map[string]interface{}{
+			{"system": "bk_test", "type": "app", "id": "a1"},
+		},
+		"cursor": 10,
+		"limit":  3,
+	}
+
+	t.Run("no json", func(t *testing.T) {
+		newRequestFunc(t).NoJSON()
+	})
+
+	t.Run("bad request limit too large", func(t *testing.T) {
+		newRequestFunc(t).JSON(map[string]interface{}{
+			"action":    body["action"],
+			"resources": body["resources"],
+			"limit":     1001,
+		}).BadRequestContainsMessage("Limit")
+	})
+
+	var ctl *gomock.Controller
+	var patches *gomonkey.Patches
+
+	newPatches := func(actionPKErr, listErr, filterErr error) {
+		ctl = gomock.NewController(t)
+		mockSvc := mock.NewMockEnginePolicyService(ctl)
+		mockSvc.EXPECT().ListAfterPKByActionPKs(
+			gomock.Any(), int64(10), []int64{1}, int64(3),
+		).Return([]svctypes.EngineQueryPolicy{
+			{QueryPolicy: svctypes.QueryPolicy{PK: 11, SubjectPK: 1, ExpressionPK: 1, ExpiredAt: 100}},
+			{QueryPolicy: svctypes.QueryPolicy{PK: 12, SubjectPK: 2, ExpressionPK: 2, ExpiredAt: 100}},
+			{
+				QueryPolicy: svctypes.QueryPolicy{PK: 13, SubjectPK: 3, ExpressionPK: 1, ExpiredAt: 200},
+				Effect:      svctypes.PolicyEffectDeny,
+				TemplateID:  5,
+			},
+		}, listErr).AnyTimes()
+		mockManager := prpmock.NewMockPolicyManager(ctl)
+		mockManager.EXPECT().GetExpressionsFromCache(int64(1), []int64{1, 2, 1}).Return([]svctypes.AuthExpression{
+			{PK: 1, Expression: "expr1", Signature: "s1"},
+			{PK: 2, Expression: "expr2", Signature: "s2"},
+		}, nil).AnyTimes()
+
+		patches = gomonkey.ApplyFunc(impls.GetActionPK, func(systemID, actionID string) (int64, error) {
+			return 1, actionPKErr
+		})
+		patches.ApplyFunc(service.NewEnginePolicyService, func() service.EnginePolicyService {
+			return mockSvc
+		})
+		patches.ApplyFunc(prp.NewPolicyManager, func() prp.PolicyManager {
+			return mockManager
+		})
+		patches.ApplyFunc(pdp.FilterResourcePolicies, func(
+			r *request.Request, policies []types.AuthPolicy,
+		) ([]types.AuthPolicy, error) {
+			// 只有表达式1满足
+			filtered := []types.AuthPolicy{}
+			for _, p := range policies {
+				if p.ExpressionSignature == "s1" {
+					filtered = append(filtered, p)
+				}
+			}
+			return filtered, filterErr
+		})
+		patches.ApplyFunc(impls.GetSubjectByPK, func(pk int64) (svctypes.Subject, error) {
+			if pk == 3 {
+				return svctypes.Subject{Type: "group", ID: "g1", Name: "group1"}, nil
+			}
+			return svctypes.Subject{Type: "user", ID: "admin", Name: "Administer"}, nil
+		})
+	}
+	restMock := func() {
+		ctl.Finish()
+		patches.Reset()
+	}
+
+	t.Run("action not exists", func(t *testing.T) {
+		newPatches(sql.ErrNoRows, nil, nil)
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).BadRequestContainsMessage("action(view) not exists")
+	})
+
+	t.Run("list policies error", func(t *testing.T) {
+		newPatches(nil, errors.New("list fail"), nil)
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).SystemError()
+	})
+
+	t.Run("invalid action resource", func(t *testing.T) {
+		newPatches(nil, nil, pdp.ErrInvalidActionResource)
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).BadRequestContainsMessage(pdp.ErrInvalidActionResource.Error())
+	})
+
+	t.Run("filter error", func(t *testing.T) {
+		newPatches(nil, nil, errors.New("filter fail"))
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		newPatches(nil, nil, nil)
+		defer restMock()
+
+		r := util.SetupRouter()
+		r.POST(routeURL, ListResourcePolicy)
+		apitest.New().
+			Handler(r).
+			Post(url).
+			JSON(body).
+			Expect(t).
+			Assert(util.NewResponseAssertFunc(t, func(resp util.Response) error {
+				assert.Equal(t, util.NoError, resp.Code)
+				assert.Equal(t, gin.H{
+					"next_cursor": float64(13),
+					"has_more":    true,
+					"results": []interface{}{
+						map[string]interface{}{
+							"id":          float64(11),
+							"subject":     map[string]interface{}{"type": "user", "id": "admin", "name": "Administer"},
+							"effect":      "allow",
+							"template_id": float64(0),
+							"expired_at":  float64(100),
+						},
+						map[string]interface{}{
+							"id":          float64(13),
+							"subject":     map[string]interface{}{"type": "group", "id": "g1", "name": "group1"},
+							"effect":      "deny",
+							"template_id": float64(5),
+							"expired_at":  float64(200),
+						},
+					},
+				}, gin.H(resp.Data.(map[string]interface{})))
+				return nil
+			})).
+			Status(http.StatusOK).
+			End()
+	})
+}
@@ -0,0 +1,118 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/prp"
+	"iam/pkg/abac/types"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/util"
+)
+
+// SimulatePolicies godoc
+// @Summary Simulate policies/模拟策略变更
+// @Description simulate the policies change(same as alter policies), return the auth results before and after the change
+// @Description nothing will be written to the db or the policy cache
+// @ID api-web-simulate-policies
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param system_id path string true "system id"
+// @Param body body policiesSimulateSerializer true "the policies change and the auth requests"
+// @Success 200 {object} util.Response{data=[]pdp.SimulationResult}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/systems/{system_id}/policies/simulate [post]
+func SimulatePolicies(c *gin.Context) {
+	var body policiesSimulateSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	if ok, message := body.validate(); !ok {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
+	systemID := c.Param("system_id")
+
+	subject := types.Subject{
+		Type:      body.Subject.Type,
+		ID:        body.Subject.ID,
+		Attribute: types.NewSubjectAttribute(),
+	}
+
+	createPolicies := make([]types.Policy, 0, len(body.CreatePolicies))
+	for _, p := range body.CreatePolicies {
+		createPolicies = append(createPolicies,
+			convertToInternalTypesPolicy(systemID, subject, 0, service.PolicyTemplateIDCustom, p))
+	}
+
+	manager := prp.NewPolicyManager()
+	overlay, err := manager.NewPolicyOverlay(systemID, body.Subject.Type, body.Subject.ID,
+		createPolicies, body.DeletePolicyIDs)
+	if err != nil {
+		if errors.Is(err, prp.ErrActionNotExists) {
+			util.BadRequestErrorJSONResponse(c, err.Error())
+			return
+		}
+
+		err = errorx.Wrapf(err, "Handler", "SimulatePolicies",
+			"systemID=`%s`, subjectType=`%s`, subjectID=`%s`, createPolicies=`%+v`",
+			systemID, body.Subject.Type, body.Subject.ID, createPolicies)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	reqs := make([]*request.Request, 0, len(body.Requests))
+	for _, r := range body.Requests {
+		req := request.NewRequest()
+		req.System = systemID
+		req.Subject.Type = r.Subject.Type
+		req.Subject.ID = r.Subject.ID
+		req.Action.ID = r.Action.ID
+		for _, resource := range r.Resources {
+			req.Resources = append(req.Resources, types.Resource{
+				System:    resource.System,
+				Type:      resource.Type,
+				ID:        resource.ID,
+				Attribute: resource.Attribute,
+			})
+		}
+		reqs = append(reqs, req)
+	}
+
+	results, err := pdp.Simulate(systemID, overlay, reqs)
+	if err != nil {
+		if errors.Is(err, pdp.ErrTooManyEvaluations) {
+			util.TooManyRequestsJSONResponse(c, err.Error())
+			return
+		}
+		if errors.Is(err, pdp.ErrInvalidAction) {
+			util.BadRequestErrorJSONResponse(c, err.Error())
+			return
+		}
+
+		err = errorx.Wrapf(err, "Handler", "SimulatePolicies", "systemID=`%s`", systemID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", results)
+}
@@ -0,0 +1,125 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+
+	"iam/pkg/abac/pdp"
+	"iam/pkg/abac/prp"
+	"iam/pkg/abac/prp/mock"
+	"iam/pkg/abac/types/request"
+	"iam/pkg/util"
+)
+
+func TestSimulatePolicies(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"post", "/api/v1/web/systems/bk_test/policies/simulate", SimulatePolicies,
+		"/api/v1/web/systems/:system_id/policies/simulate",
+	)
+
+	body := map[string]interface{}{
+		"subject":           map[string]interface{}{"type": "group", "id": "1"},
+		"delete_policy_ids": []int64{1},
+		"requests": []map[string]interface{}{{
+			"subject": map[string]interface{}{"type": "user", "id": "tom"},
+			"action":  map[string]interface{}{"id": "view"},
+			"resources": []map[string]interface{}{
+				{"system": "bk_test", "type": "app", "id": "a1"},
+			},
+		}},
+	}
+
+	t.Run("no json", func(t *testing.T) {
+		newRequestFunc(t).NoJSON()
+	})
+
+	t.Run("bad request empty change", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject":  body["subject"],
+				"requests": body["requests"],
+			}).BadRequestContainsMessage("should not be both empty")
+	})
+
+	t.Run("bad request invalid requests", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject":           body["subject"],
+				"delete_policy_ids": []int64{1},
+				"requests": []map[string]interface{}{{
+					"subject":   map[string]interface{}{"type": "user", "id": "tom"},
+					"resources": []map[string]interface{}{},
+				}},
+			}).BadRequestContainsMessage("data in array[0]")
+	})
+
+	var ctl *gomock.Controller
+	var patches *gomonkey.Patches
+
+	newPatches := func(overlayErr, simulateErr error) {
+		ctl = gomock.NewController(t)
+		mockManager := mock.NewMockPolicyManager(ctl)
+		mockManager.EXPECT().NewPolicyOverlay(
+			"bk_test", "group", "1", gomock.Any(), []int64{1},
+		).Return(&prp.PolicyOverlay{}, overlayErr).AnyTimes()
+		patches = gomonkey.ApplyFunc(prp.NewPolicyManager, func() prp.PolicyManager {
+			return mockManager
+		})
+		patches.ApplyFunc(pdp.Simulate, func(
+			system string, overlay *prp.PolicyOverlay, reqs []*request.Request,
+		) ([]pdp.SimulationResult, error) {
+			return []pdp.SimulationResult{{Before: true, After: false, Changed: true}}, simulateErr
+		})
+	}
+	restMock := func() {
+		ctl.Finish()
+		patches.Reset()
+	}
+
+	t.Run("action not exists", func(t *testing.T) {
+		newPatches(prp.ErrActionNotExists, nil)
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).BadRequestContainsMessage("action not exists")
+	})
+
+	t.Run("overlay error", func(t *testing.T) {
+		newPatches(errors.New("get pk fail"), nil)
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).SystemError()
+	})
+
+	t.Run("invalid action", func(t *testing.T) {
+		newPatches(nil, pdp.ErrInvalidAction)
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).BadRequestContainsMessage(pdp.ErrInvalidAction.Error())
+	})
+
+	t.Run("simulate error", func(t *testing.T) {
+		newPatches(nil, errors.New("simulate fail"))
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		newPatches(nil, nil)
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).OK()
+	})
+}
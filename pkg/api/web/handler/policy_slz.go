@@ -11,6 +11,13 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"iam/pkg/abac/pdp/condition"
+	"iam/pkg/abac/pip"
 	"iam/pkg/api/common"
 )
 
@@ -38,6 +45,10 @@ type policy struct {
 	ActionID           string `json:"action_id" binding:"required"`
 	ResourceExpression string `json:"resource_expression" binding:"required"`
 	ExpiredAt          int64  `json:"expired_at" binding:"required,min=0,max=4102444800"`
+	// 策略效果, 默认为allow; NOTE: 仅创建时生效, 更新策略时不会修改
+	Effect string `json:"effect" binding:"omitempty,oneof=allow deny"`
+	// 策略优先级, 仅在系统配置为first_match求值模式时生效, 值越大越先求值; NOTE: 仅创建时生效
+	Priority int64 `json:"priority" binding:"omitempty"`
 
 	// NOTE: this field not used!
 	Environment string `json:"environment" binding:"omitempty"`
@@ -48,11 +59,39 @@ type updatePolicy struct {
 	policy
 }
 
+// validateResourceExpressions 校验新建策略的资源表达式, 与鉴权时使用同一套条件解析
+func validateResourceExpressions(field string, policies []policy) (bool, string) {
+	for i, p := range policies {
+		if errs := condition.Validate(p.ResourceExpression, nil); len(errs) > 0 {
+			return false, fmt.Sprintf("%s[%d].resource_expression invalid, %s", field, i, errs[0])
+		}
+	}
+	return true, ""
+}
+
+// validateResourceExpressionAttributeTypes 根据接入系统注册的资源属性schema, 校验新建策略中条件值的类型
+// NOTE: 需要查询schema, 所以不在serializer的validate中处理; err为查询schema失败
+func validateResourceExpressionAttributeTypes(field string, policies []policy) (bool, string, error) {
+	for i, p := range policies {
+		errs, err := condition.ValidateWithSchema(p.ResourceExpression, nil, pip.GetResourceAttributeSchema)
+		if err != nil {
+			return false, "", err
+		}
+		if len(errs) > 0 {
+			return false, fmt.Sprintf("%s[%d].resource_expression invalid, %s", field, i, errs[0]), nil
+		}
+	}
+	return true, "", nil
+}
+
 func (slz *policiesAlterSerializer) validate() (bool, string) {
 	if len(slz.CreatePolicies) > 0 {
 		if valid, message := common.ValidateArray(slz.CreatePolicies); !valid {
 			return false, message
 		}
+		if valid, message := validateResourceExpressions("create_policies", slz.CreatePolicies); !valid {
+			return false, message
+		}
 	}
 
 	if len(slz.UpdatePolicies) > 0 {
@@ -64,6 +103,126 @@ func (slz *policiesAlterSerializer) validate() (bool, string) {
 	return true, ""
 }
 
+// 模拟策略变更 request body
+type policiesSimulateSerializer struct {
+	Subject         subject             `json:"subject" binding:"required"`
+	CreatePolicies  []policy            `json:"create_policies" binding:"omitempty"`
+	DeletePolicyIDs []int64             `json:"delete_policy_ids" binding:"omitempty"`
+	Requests        []simulationRequest `json:"requests" binding:"required,max=100"`
+}
+
+type simulationRequest struct {
+	Subject struct {
+		Type string `json:"type" binding:"required"`
+		ID   string `json:"id" binding:"required"`
+	} `json:"subject" binding:"required"`
+	Action struct {
+		ID string `json:"id" binding:"required"`
+	} `json:"action" binding:"required"`
+	Resources []simulationResource `json:"resources" binding:"required,dive"`
+}
+
+type simulationResource struct {
+	System    string                 `json:"system" binding:"required"`
+	Type      string                 `json:"type" binding:"required"`
+	ID        string                 `json:"id" binding:"required"`
+	Attribute map[string]interface{} `json:"attribute" binding:"omitempty"`
+}
+
+func (slz *policiesSimulateSerializer) validate() (bool, string) {
+	if len(slz.CreatePolicies) == 0 && len(slz.DeletePolicyIDs) == 0 {
+		return false, "create_policies and delete_policy_ids should not be both empty"
+	}
+
+	if len(slz.CreatePolicies) > 0 {
+		if valid, message := common.ValidateArray(slz.CreatePolicies); !valid {
+			return false, message
+		}
+		if valid, message := validateResourceExpressions("create_policies", slz.CreatePolicies); !valid {
+			return false, message
+		}
+	}
+
+	if valid, message := common.ValidateArray(slz.Requests); !valid {
+		return false, message
+	}
+
+	return true, ""
+}
+
+// 虚拟用户鉴权 request body
+type hypotheticalAuthSerializer struct {
+	Subject  hypotheticalSubject   `json:"subject" binding:"required"`
+	Requests []hypotheticalRequest `json:"requests" binding:"required,gt=0,max=100"`
+}
+
+type hypotheticalSubject struct {
+	Departments []string `json:"departments" binding:"omitempty,max=100"`
+	Groups      []string `json:"groups" binding:"omitempty,max=100"`
+}
+
+type hypotheticalRequest struct {
+	Action struct {
+		ID string `json:"id" binding:"required"`
+	} `json:"action" binding:"required"`
+	Resources []simulationResource `json:"resources" binding:"required,dive"`
+}
+
+type hypotheticalAuthResult struct {
+	Action  string `json:"action"`
+	Allowed bool   `json:"allowed"`
+}
+
+func (slz *hypotheticalAuthSerializer) validate() (bool, string) {
+	if valid, message := common.ValidateArray(slz.Requests); !valid {
+		return false, message
+	}
+	return true, ""
+}
+
+const (
+	defaultResourcePolicyLimit = 500
+)
+
+// 资源实例反查策略 request body
+type resourcePolicySerializer struct {
+	Action struct {
+		ID string `json:"id" binding:"required"`
+	} `json:"action" binding:"required"`
+	Resources []simulationResource `json:"resources" binding:"required,dive"`
+	// 游标: 上一页返回的next_cursor, 首页为0
+	Cursor int64 `json:"cursor" binding:"omitempty,min=0" example:"0"`
+	// 每页扫描的策略数量, 返回的结果只包含对资源生效的策略, 可能少于limit
+	Limit int64 `json:"limit" binding:"omitempty,min=1,max=1000" example:"500"`
+}
+
+func (s *resourcePolicySerializer) initDefault() {
+	if s.Limit == 0 {
+		s.Limit = defaultResourcePolicyLimit
+	}
+}
+
+type resourcePolicySubject struct {
+	Type string `json:"type" example:"user"`
+	ID   string `json:"id" example:"admin"`
+	Name string `json:"name" example:"Administer"`
+}
+
+type resourcePolicyResponse struct {
+	ID         int64                 `json:"id" example:"100"`
+	Subject    resourcePolicySubject `json:"subject"`
+	Effect     string                `json:"effect" example:"allow"`
+	TemplateID int64                 `json:"template_id" example:"0"`
+	ExpiredAt  int64                 `json:"expired_at" example:"4102444800"`
+}
+
+type resourcePolicyListResponse struct {
+	// 下一页的游标, has_more=false时无需继续拉取
+	NextCursor int64                    `json:"next_cursor"`
+	HasMore    bool                     `json:"has_more"`
+	Results    []resourcePolicyResponse `json:"results"`
+}
+
 type policiesDeleteSerializer struct {
 	policySerializer
 	SystemID string  `json:"system_id" binding:"required"`
@@ -102,3 +261,82 @@ func (slz *policiesUpdateExpiredAtSerializer) validate() (bool, string) {
 
 	return true, ""
 }
+
+const (
+	defaultExpiringPolicyLimit = 100
+)
+
+type expiringPolicySerializer struct {
+	// 多少天内将要过期
+	Days int64 `form:"days" json:"days" binding:"required,min=1,max=90" example:"7"`
+	// 游标: 上一页返回的next_cursor, 首页为空
+	Cursor string `form:"cursor" json:"cursor" binding:"omitempty" example:"1630000000_1630086400_100"`
+	Limit  int64  `form:"limit" json:"limit" binding:"omitempty,min=1,max=500" example:"100"`
+}
+
+func (s *expiringPolicySerializer) initDefault() {
+	if s.Limit == 0 {
+		s.Limit = defaultExpiringPolicyLimit
+	}
+}
+
+// expiringPolicyCursor 游标中记录首页的查询时间, 翻页时过期时间窗口不随时间移动
+// 格式: {begin_expired_at}_{expired_at}_{pk}, 后两者为上一页最后一条策略的过期时间和pk
+type expiringPolicyCursor struct {
+	BeginExpiredAt int64
+	ExpiredAt      int64
+	PK             int64
+}
+
+func parseExpiringPolicyCursor(cursor string) (c expiringPolicyCursor, err error) {
+	parts := strings.Split(cursor, "_")
+	if len(parts) != 3 {
+		err = errors.New("cursor should be {begin_expired_at}_{expired_at}_{pk}")
+		return
+	}
+
+	values := make([]int64, 0, 3)
+	for _, part := range parts {
+		var v int64
+		v, err = strconv.ParseInt(part, 10, 64)
+		if err != nil || v < 0 {
+			err = errors.New("cursor should be {begin_expired_at}_{expired_at}_{pk}")
+			return
+		}
+		values = append(values, v)
+	}
+
+	c = expiringPolicyCursor{BeginExpiredAt: values[0], ExpiredAt: values[1], PK: values[2]}
+	return
+}
+
+func (c expiringPolicyCursor) String() string {
+	return fmt.Sprintf("%d_%d_%d", c.BeginExpiredAt, c.ExpiredAt, c.PK)
+}
+
+type expiringPolicySubject struct {
+	Type string `json:"type" example:"user"`
+	ID   string `json:"id" example:"admin"`
+	Name string `json:"name" example:"Administer"`
+}
+
+type expiringPolicyAction struct {
+	ID     string `json:"id" example:"edit"`
+	Name   string `json:"name" example:"编辑"`
+	NameEn string `json:"name_en" example:"edit"`
+}
+
+type expiringPolicyResponse struct {
+	ID        int64                 `json:"id" example:"100"`
+	System    string                `json:"system" example:"bk_cmdb"`
+	Action    expiringPolicyAction  `json:"action"`
+	Subject   expiringPolicySubject `json:"subject"`
+	ExpiredAt int64                 `json:"expired_at" example:"4102444800"`
+}
+
+type expiringPolicyListResponse struct {
+	// 下一页的游标, has_more=false时无需继续拉取
+	NextCursor string                   `json:"next_cursor"`
+	HasMore    bool                     `json:"has_more"`
+	Results    []expiringPolicyResponse `json:"results"`
+}
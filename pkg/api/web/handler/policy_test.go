@@ -14,6 +14,7 @@ import (
 	"errors"
 	"testing"
 
+	"iam/pkg/abac/pip"
 	"iam/pkg/abac/prp"
 	"iam/pkg/abac/prp/mock"
 	"iam/pkg/util"
@@ -62,16 +63,77 @@ func TestAlterPolicies(t *testing.T) {
 			}).BadRequest("bad request:data in array[0], ID is required")
 	})
 
+	t.Run("bad request invalid path value", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject": map[string]interface{}{"type": "user", "id": "test"},
+				"create_policies": []map[string]interface{}{{
+					"action_id": "edit",
+					"resource_expression": `[{"system": "bk_test", "type": "app", "expression": ` +
+						`{"StringPrefix": {"_bk_iam_path_": ["/biz,1"]}}}]`,
+					"expired_at": int64(100),
+				}},
+				"update_policies":   []map[string]interface{}{},
+				"delete_policy_ids": []int64{},
+			}).BadRequestContainsMessage("create_policies[0].resource_expression invalid")
+	})
+
 	var ctl *gomock.Controller
 	var patches *gomonkey.Patches
 
 	restMock := func() {
-		ctl.Finish()
+		if ctl != nil {
+			ctl.Finish()
+		}
 		if patches != nil {
 			patches.Reset()
 		}
 	}
 
+	t.Run("bad request attribute type not match the schema", func(t *testing.T) {
+		patches = gomonkey.ApplyFunc(pip.GetResourceAttributeSchema, func(
+			system, _type string,
+		) (map[string]string, error) {
+			return map[string]string{"level": "numeric"}, nil
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject": map[string]interface{}{"type": "user", "id": "test"},
+				"create_policies": []map[string]interface{}{{
+					"action_id": "edit",
+					"resource_expression": `[{"system": "bk_test", "type": "app", "expression": ` +
+						`{"StringEquals": {"level": ["1"]}}}]`,
+					"expired_at": int64(100),
+				}},
+				"update_policies":   []map[string]interface{}{},
+				"delete_policy_ids": []int64{},
+			}).BadRequest("bad request:create_policies[0].resource_expression invalid, " +
+			"[0].expression.StringEquals.level: attribute `level` is `numeric`, can not use operator `StringEquals`")
+	})
+
+	t.Run("get schema error", func(t *testing.T) {
+		patches = gomonkey.ApplyFunc(pip.GetResourceAttributeSchema, func(
+			system, _type string,
+		) (map[string]string, error) {
+			return nil, errors.New("get schema fail")
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subject": map[string]interface{}{"type": "user", "id": "test"},
+				"create_policies": []map[string]interface{}{{
+					"action_id":           "edit",
+					"resource_expression": `[{"system": "bk_test", "type": "app", "expression": {"Any": {"id": []}}}]`,
+					"expired_at":          int64(100),
+				}},
+				"update_policies":   []map[string]interface{}{},
+				"delete_policy_ids": []int64{},
+			}).SystemError()
+	})
+
 	t.Run("manager error", func(t *testing.T) {
 		ctl = gomock.NewController(t)
 		mockManager := mock.NewMockPolicyManager(ctl)
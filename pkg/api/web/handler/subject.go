@@ -11,37 +11,22 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
 	log "github.com/sirupsen/logrus"
 
-	pl "iam/pkg/abac/prp/policy"
 	"iam/pkg/api/common"
 	"iam/pkg/cache/impls"
+	"iam/pkg/cache/invalidation"
 	"iam/pkg/errorx"
 	"iam/pkg/service"
 	"iam/pkg/service/types"
 	"iam/pkg/util"
 )
 
-func batchDeleteMembersFromCache(members []memberSerializer) error {
-	pks := make([]int64, 0, len(members))
-	for _, m := range members {
-		pk, _ := impls.GetSubjectPK(m.Type, m.ID)
-		pks = append(pks, pk)
-	}
-	return impls.BatchDeleteSubjectCache(pks)
-}
-func batchDeleteUpdatedMembersFromCache(members []types.SubjectMember) error {
-	pks := make([]int64, 0, len(members))
-	for _, m := range members {
-		pks = append(pks, m.PK)
-	}
-	return impls.BatchDeleteSubjectCache(pks)
-}
-
 // ListSubject 查询用户/部门/用户组列表
 func ListSubject(c *gin.Context) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "ListSubject")
@@ -76,6 +61,40 @@ func ListSubject(c *gin.Context) {
 	})
 }
 
+// SearchSubjects 按关键字搜索subject, 前缀匹配id/name, 用于SaaS的成员选择
+func SearchSubjects(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "SearchSubjects")
+
+	var body searchSubjectSerializer
+	if err := c.ShouldBindQuery(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	body.Default()
+
+	svc := service.NewSubjectService()
+	count, err := svc.GetSearchCount(body.Type, body.Keyword)
+	if err != nil {
+		err = errorWrapf(err, "svc.GetSearchCount type=`%s` keyword=`%s`", body.Type, body.Keyword)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	subjects, err := svc.SearchPaging(body.Type, body.Keyword, body.Limit, body.Offset)
+	if err != nil {
+		err = errorWrapf(err, "svc.SearchPaging type=`%s` keyword=`%s` limit=`%d` offset=`%d`",
+			body.Type, body.Keyword, body.Limit, body.Offset)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"count":   count,
+		"results": subjects,
+	})
+}
+
 // BatchCreateSubjects 批量创建subject
 func BatchCreateSubjects(c *gin.Context) {
 	var subjects []createSubjectSerializer
@@ -87,6 +106,12 @@ func BatchCreateSubjects(c *gin.Context) {
 		util.BadRequestErrorJSONResponse(c, message)
 		return
 	}
+	for i := range subjects {
+		if valid, message := subjects[i].validate(); !valid {
+			util.BadRequestErrorJSONResponse(c, message)
+			return
+		}
+	}
 
 	svc := service.NewSubjectService()
 	svcSubjects := make([]types.Subject, 0, len(subjects))
@@ -122,7 +147,7 @@ func deleteGroupPKPolicyCache(groupPKs []int64) {
 			systemIDs = append(systemIDs, s.ID)
 		}
 
-		err = pl.BatchDeleteSystemSubjectPKsFromCache(systemIDs, groupPKs)
+		err = invalidation.BatchDeleteSystemSubjectPolicies(systemIDs, groupPKs)
 		if err != nil {
 			log.Error(err.Error())
 		}
@@ -157,7 +182,7 @@ func BatchDeleteSubjects(c *gin.Context) {
 		}
 	}
 
-	pks, err := svc.BulkDelete(svcSubjects)
+	_, err := svc.BulkDelete(svcSubjects)
 	if err != nil {
 		err = errorx.Wrapf(err, "Handler", "BatchDeleteSubjects",
 			"svc.BulkDelete subjects=`%v`", svcSubjects)
@@ -165,13 +190,7 @@ func BatchDeleteSubjects(c *gin.Context) {
 		return
 	}
 
-	// 清除涉及的所有缓存 [subjectGroup / subjectDetails]
-	impls.BatchDeleteSubjectCache(pks)
-
-	for _, s := range subjects {
-		impls.DeleteSubjectPK(s.Type, s.ID)
-		impls.DeleteLocalSubjectPK(s.Type, s.ID)
-	}
+	// NOTE: subject相关的缓存 [subjectGroup / subjectDetails / subjectPK] 由svc.BulkDelete发出的变更事件清理
 	// Note: 不需要清除subject的成员其对应的SubjectGroup和SubjectDepartment，
 	//       =>  保证拿到的group pk 没有对应的policy cache/回源也查不到
 	deleteGroupPKPolicyCache(groupPKs)
@@ -179,6 +198,49 @@ func BatchDeleteSubjects(c *gin.Context) {
 	util.SuccessJSONResponse(c, "ok", nil)
 }
 
+// DeleteSubjectsCache 批量清理subject的缓存 [subjectPK / subjectDetail / subjectGroup]
+// NOTE: 用于SaaS在权限中心之外修复数据后, 主动清理相关subject的缓存, 避免清空整个redis
+func DeleteSubjectsCache(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "DeleteSubjectsCache")
+
+	var body deleteSubjectCacheSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	if valid, message := body.validate(); !valid {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
+	svcSubjects := make([]types.Subject, 0, len(body.Subjects))
+	copier.Copy(&svcSubjects, &body.Subjects)
+
+	// 1. 先清理 type+id => pk, 数据修复可能导致pk变化
+	err := invalidation.DeleteSubjectPKs(svcSubjects)
+	if err != nil {
+		err = errorWrapf(err, "invalidation.DeleteSubjectPKs subjects=`%+v`", svcSubjects)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	// 2. 从DB查询最新的pk, 清理pk相关的缓存; 已经不存在的subject不需要清理
+	svc := service.NewSubjectService()
+	pks, err := svc.ListPKsBySubjects(svcSubjects)
+	if err != nil {
+		err = errorWrapf(err, "svc.ListPKsBySubjects subjects=`%+v`", svcSubjects)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+	invalidation.DeleteSubjects(pks)
+
+	log.Infof("DeleteSubjectsCache by client=`%s`, subjects=`%+v`, pks=`%v`", util.GetClientID(c), svcSubjects, pks)
+
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"count": len(pks),
+	})
+}
+
 // ListSubjectMember 查询用户组的成员列表
 func ListSubjectMember(c *gin.Context) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "ListSubjectMember")
@@ -192,7 +254,12 @@ func ListSubjectMember(c *gin.Context) {
 	subject.Default()
 
 	svc := service.NewSubjectService()
-	count, err := svc.GetMemberCount(subject.Type, subject.ID)
+	if subject.MemberType != "" {
+		listSubjectMemberByType(c, svc, &subject)
+		return
+	}
+
+	count, err := impls.GetGroupMemberCount(subject.Type, subject.ID)
 	if err != nil {
 		err = errorWrapf(err, "type=`%s`, id=`%s`", subject.Type, subject.ID)
 		util.SystemErrorJSONResponse(c, err)
@@ -213,6 +280,32 @@ func ListSubjectMember(c *gin.Context) {
 	})
 }
 
+// listSubjectMemberByType 按成员类型过滤, 例如只查询服务账号成员; 按类型的计数不走缓存
+func listSubjectMemberByType(c *gin.Context, svc service.SubjectService, subject *listSubjectMemberSerializer) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "ListSubjectMember")
+
+	count, err := svc.GetMemberCountBySubjectType(subject.Type, subject.ID, subject.MemberType)
+	if err != nil {
+		err = errorWrapf(err, "type=`%s`, id=`%s`, member_type=`%s`", subject.Type, subject.ID, subject.MemberType)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	relations, err := svc.ListPagingMemberBySubjectType(
+		subject.Type, subject.ID, subject.MemberType, subject.Limit, subject.Offset)
+	if err != nil {
+		err = errorWrapf(err, "type=`%s`, id=`%s`, member_type=`%s`, limit=`%d`, offset=`%d`",
+			subject.Type, subject.ID, subject.MemberType, subject.Limit, subject.Offset)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"count":   count,
+		"results": relations,
+	})
+}
+
 // GetSubjectGroup 获取subject关联的用户组
 func GetSubjectGroup(c *gin.Context) {
 	var subject subjectRelationSerializer
@@ -222,6 +315,11 @@ func GetSubjectGroup(c *gin.Context) {
 	}
 
 	svc := service.NewSubjectService()
+	if subject.isPaging() {
+		getPagingSubjectGroup(c, svc, &subject)
+		return
+	}
+
 	groups, err := svc.ListSubjectGroups(subject.Type, subject.ID, subject.BeforeExpiredAt)
 	if err != nil {
 		err = errorx.Wrapf(err, "Handler", "ListSubjectGroups",
@@ -233,6 +331,33 @@ func GetSubjectGroup(c *gin.Context) {
 	util.SuccessJSONResponse(c, "ok", groups)
 }
 
+// getPagingSubjectGroup 分页获取subject关联的用户组
+func getPagingSubjectGroup(c *gin.Context, svc service.SubjectService, subject *subjectRelationSerializer) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "GetSubjectGroup")
+
+	count, err := svc.GetSubjectGroupCount(subject.Type, subject.ID, subject.BeforeExpiredAt)
+	if err != nil {
+		err = errorWrapf(err, "type=`%s`, id=`%s`, before_expired_at=`%d`",
+			subject.Type, subject.ID, subject.BeforeExpiredAt)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	groups, err := svc.ListPagingSubjectGroups(
+		subject.Type, subject.ID, subject.BeforeExpiredAt, subject.Limit, subject.Offset)
+	if err != nil {
+		err = errorWrapf(err, "type=`%s`, id=`%s`, before_expired_at=`%d`, limit=`%d`, offset=`%d`",
+			subject.Type, subject.ID, subject.BeforeExpiredAt, subject.Limit, subject.Offset)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"count":   count,
+		"results": groups,
+	})
+}
+
 // UpdateSubjectMembersExpiredAt subject关系续期
 func UpdateSubjectMembersExpiredAt(c *gin.Context) {
 	var body subjectMemberExpiredAtSerializer
@@ -246,6 +371,11 @@ func UpdateSubjectMembersExpiredAt(c *gin.Context) {
 		return
 	}
 
+	if err := body.fillPolicyExpiredAt(); err != nil {
+		util.BadRequestErrorJSONResponse(c, err.Error())
+		return
+	}
+
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "UpdateSubjectMembersExpiredAt")
 
 	svc := service.NewSubjectService()
@@ -283,15 +413,18 @@ func UpdateSubjectMembersExpiredAt(c *gin.Context) {
 
 	// 更新成员过期时间
 	err = svc.UpdateMembersExpiredAt(updateMembers)
+	if settingErr := groupSettingError(err); settingErr != nil {
+		util.BadRequestErrorJSONResponse(c, settingErr.Error())
+		return
+	}
 	if err != nil {
 		err = errorWrapf(err,
 			"svc.UpdateMembersExpiredAt members=`%+v`", updateMembers)
 		util.SystemErrorJSONResponse(c, err)
 		return
 	}
-
-	// 清除涉及用户的缓存
-	batchDeleteUpdatedMembersFromCache(updateMembers)
+	recordSubjectMemberRelationEvents(types.Subject{Type: body.Type, ID: body.ID}, updateMembers,
+		service.SubjectMemberEventActionRenewed, body.Operator, util.GetClientID(c))
 
 	util.SuccessJSONResponse(c, "ok", gin.H{})
 }
@@ -319,8 +452,10 @@ func DeleteSubjectMembers(c *gin.Context) {
 		util.SystemErrorJSONResponse(c, err)
 		return
 	}
+	// NOTE: 删除的成员可能本就不在组内, 按请求记录删除操作
+	recordSubjectMemberEvents(types.Subject{Type: body.Type, ID: body.ID}, svcSubjects,
+		service.SubjectMemberEventActionRemoved, 0, body.Operator, util.GetClientID(c))
 
-	batchDeleteMembersFromCache(body.Members)
 	// TODO: 这里可以区分 dept -> group关系变更
 
 	util.SuccessJSONResponse(c, "ok", typeCount)
@@ -328,27 +463,101 @@ func DeleteSubjectMembers(c *gin.Context) {
 
 // BatchAddSubjectMembers 批量添加subject成员
 func BatchAddSubjectMembers(c *gin.Context) {
-	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "BatchAddSubjectMembers")
-
 	var body addSubjectMembersSerializer
 	if err := c.ShouldBindJSON(&body); err != nil {
 		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
 		return
 	}
+
+	policyExpiredAt, err := fillGroupDefaultPolicyExpiredAt(body.Type, body.ID, body.PolicyExpiredAt)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "BatchAddSubjectMembers",
+			"fillGroupDefaultPolicyExpiredAt type=`%s`, id=`%s`", body.Type, body.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+	body.PolicyExpiredAt = policyExpiredAt
+
+	if err := body.fillPolicyExpiredAt(); err != nil {
+		util.BadRequestErrorJSONResponse(c, err.Error())
+		return
+	}
 	if ok, message := body.validate(); !ok {
 		util.BadRequestErrorJSONResponse(c, message)
 		return
 	}
 
-	// 查询DB里已有成员
+	members := make([]types.Subject, 0, len(body.Members))
+	for _, m := range body.Members {
+		members = append(members, types.Subject{Type: m.Type, ID: m.ID})
+	}
+
 	svc := service.NewSubjectService()
-	relations, err := svc.ListMember(body.Type, body.ID)
+	result, err := addSubjectMembers(
+		svc, types.Subject{Type: body.Type, ID: body.ID}, members, body.PolicyExpiredAt,
+		util.GetClientID(c), body.Operator,
+	)
+	if errors.Is(err, service.ErrGroupMemberCycle) {
+		util.BadRequestErrorJSONResponse(c, service.ErrGroupMemberCycle.Error())
+		return
+	}
+	if settingErr := groupSettingError(err); settingErr != nil {
+		util.BadRequestErrorJSONResponse(c, settingErr.Error())
+		return
+	}
 	if err != nil {
-		err = errorWrapf(err, "svc.ListMember type=`%s` id=`%s`", body.Type, body.ID)
 		util.SystemErrorJSONResponse(c, err)
 		return
 	}
 
+	data := gin.H{}
+	for _type, count := range result.TypeCount {
+		data[_type] = count
+	}
+	if len(result.Pending) != 0 || len(result.Rejected) != 0 {
+		data["pending"] = result.Pending
+		data["rejected"] = result.Rejected
+	}
+
+	// TODO: 这里可以区分 dept -> group关系变更
+	util.SuccessJSONResponse(c, "ok", data)
+}
+
+// addSubjectMembersResult 添加成员的结果
+type addSubjectMembersResult struct {
+	// 实际添加的各类型成员数量
+	TypeCount map[string]int64
+	// 已存在且更新了过期时间的成员数量
+	Updated int64
+
+	Pending  []types.Subject
+	Rejected []types.Subject
+}
+
+// addSubjectMembers 添加成员: 重复的成员只处理一次, 已存在的成员只延长过期时间, 新成员需要通过添加成员的钩子
+func addSubjectMembers(
+	svc service.SubjectService,
+	group types.Subject,
+	bodyMembers []types.Subject,
+	policyExpiredAt int64,
+	clientID, operator string,
+) (result addSubjectMembersResult, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "addSubjectMembers")
+
+	result.TypeCount = map[string]int64{
+		types.UserType:           0,
+		types.DepartmentType:     0,
+		types.GroupType:          0,
+		types.ServiceAccountType: 0,
+	}
+
+	// 查询DB里已有成员
+	relations, err := svc.ListMember(group.Type, group.ID)
+	if err != nil {
+		err = errorWrapf(err, "svc.ListMember type=`%s` id=`%s`", group.Type, group.ID)
+		return
+	}
+
 	// 重复和已经存在DB里的不需要
 	memberMap := make(map[string]types.SubjectMember, len(relations))
 	for _, m := range relations {
@@ -356,32 +565,27 @@ func BatchAddSubjectMembers(c *gin.Context) {
 	}
 
 	// 获取实际需要添加的member
-	members := make([]types.Subject, 0, len(body.Members))
+	members := make([]types.Subject, 0, len(bodyMembers))
 
 	// 需要更新过期时间的member
-	updateMembers := make([]types.SubjectMember, 0, len(body.Members))
+	updateMembers := make([]types.SubjectMember, 0, len(bodyMembers))
 
-	typeCount := map[string]int64{
-		types.UserType:       0,
-		types.DepartmentType: 0,
-	}
-
-	bodyMembers := util.NewStringSet() // 用于去重
+	bodyMemberSet := util.NewStringSet() // 用于去重
 
-	for _, m := range body.Members {
+	for _, m := range bodyMembers {
 		key := fmt.Sprintf("%s:%s", m.Type, m.ID)
 
 		// 对Body Member参数去重
-		if bodyMembers.Has(key) {
+		if bodyMemberSet.Has(key) {
 			continue
 		}
-		bodyMembers.Add(key)
+		bodyMemberSet.Add(key)
 
 		// member已存在则不再添加
 		if oldMember, ok := memberMap[key]; ok {
 			// 如果过期时间大于已有的时间, 则更新过期时间
-			if body.PolicyExpiredAt > oldMember.PolicyExpiredAt {
-				oldMember.PolicyExpiredAt = body.PolicyExpiredAt
+			if policyExpiredAt > oldMember.PolicyExpiredAt {
+				oldMember.PolicyExpiredAt = policyExpiredAt
 				updateMembers = append(updateMembers, oldMember)
 			}
 			continue
@@ -391,7 +595,6 @@ func BatchAddSubjectMembers(c *gin.Context) {
 			Type: m.Type,
 			ID:   m.ID,
 		})
-		typeCount[m.Type]++
 	}
 
 	if len(updateMembers) != 0 {
@@ -399,41 +602,55 @@ func BatchAddSubjectMembers(c *gin.Context) {
 		err = svc.UpdateMembersExpiredAt(updateMembers)
 		if err != nil {
 			err = errorWrapf(err, "svc.UpdateMembersExpiredAt members=`%+v`", updateMembers)
-			util.SystemErrorJSONResponse(c, err)
 			return
 		}
-
-		// 清除 更新了过期时间的成员的cache
-		batchDeleteUpdatedMembersFromCache(updateMembers)
+		recordSubjectMemberRelationEvents(
+			group, updateMembers, service.SubjectMemberEventActionRenewed, operator, clientID)
+		result.Updated = int64(len(updateMembers))
 	}
 
 	// 无成员可添加，直接返回
 	if len(members) == 0 {
-		util.SuccessJSONResponse(c, "ok", typeCount)
+		return
+	}
+
+	// 执行添加成员的钩子, 被拒绝或待审批的成员不添加
+	hookResult, err := common.CheckMemberAddHooks(group, members, policyExpiredAt, clientID)
+	if err != nil {
+		err = errorWrapf(err, "common.CheckMemberAddHooks type=`%s` id=`%s` members=`%+v`",
+			group.Type, group.ID, members)
+		return
+	}
+	members = hookResult.Approved
+	result.Pending = hookResult.Pending
+	result.Rejected = hookResult.Rejected
+
+	if len(members) == 0 {
 		return
 	}
 
 	// 添加成员
-	err = svc.BulkCreateSubjectMembers(body.Type, body.ID, members, body.PolicyExpiredAt)
+	err = svc.BulkCreateSubjectMembers(group.Type, group.ID, members, policyExpiredAt)
 	if err != nil {
 		err = errorWrapf(err,
 			"svc.BulkCreateSubjectMembers type=`%s` id=`%s` members=`%+v` policy_expired_at=`%d`",
-			body.Type, body.ID, members, body.PolicyExpiredAt)
-		util.SystemErrorJSONResponse(c, err)
+			group.Type, group.ID, members, policyExpiredAt)
 		return
 	}
+	recordSubjectMemberEvents(
+		group, members, service.SubjectMemberEventActionAdded, policyExpiredAt, operator, clientID)
 
-	// 清除涉及用户的缓存
-	batchDeleteMembersFromCache(body.Members)
-	// TODO: 这里可以区分 dept -> group关系变更
-	util.SuccessJSONResponse(c, "ok", typeCount)
+	for _, m := range members {
+		result.TypeCount[m.Type]++
+	}
+	return result, nil
 }
 
 // BatchCreateSubjectDepartments ...
 func BatchCreateSubjectDepartments(c *gin.Context) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "BatchCreateSubjectDepartments")
 
-	var subjectDepartments []subjectDepartment
+	var subjectDepartments []createSubjectDepartmentSerializer
 	if err := c.ShouldBindJSON(&subjectDepartments); err != nil {
 		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
 		return
@@ -441,22 +658,30 @@ func BatchCreateSubjectDepartments(c *gin.Context) {
 
 	svcSubjectDepartments := make([]types.SubjectDepartment, 0, len(subjectDepartments))
 	for _, sd := range subjectDepartments {
+		var departmentParents []types.DepartmentParent
+		for _, dp := range sd.DepartmentParents {
+			departmentParents = append(departmentParents, types.DepartmentParent{
+				ID:       dp.ID,
+				ParentID: dp.ParentID,
+			})
+		}
+
 		svcSubjectDepartments = append(svcSubjectDepartments, types.SubjectDepartment{
-			SubjectID:     sd.SubjectID,
-			DepartmentIDs: sd.DepartmentIDs,
+			SubjectID:         sd.SubjectID,
+			DepartmentIDs:     sd.DepartmentIDs,
+			DepartmentParents: departmentParents,
 		})
 	}
 
 	svc := service.NewSubjectService()
-	err := svc.BulkCreateSubjectDepartments(svcSubjectDepartments)
+	result, err := svc.BulkCreateSubjectDepartments(svcSubjectDepartments, util.GetClientID(c))
 	if err != nil {
 		err = errorWrapf(err, "svc.BulkCreateSubjectDepartments subjectDepartments=`%+v`", svcSubjectDepartments)
 		util.SystemErrorJSONResponse(c, err)
 		return
 	}
-	// TODO: 确认这里没有清subject detail?
 
-	util.SuccessJSONResponse(c, "ok", nil)
+	util.SuccessJSONResponse(c, "ok", result)
 }
 
 // BatchDeleteSubjectDepartments ...
@@ -475,16 +700,13 @@ func BatchDeleteSubjectDepartments(c *gin.Context) {
 	}
 
 	svc := service.NewSubjectService()
-	pks, err := svc.BulkDeleteSubjectDepartments(subjectIDs)
+	_, err := svc.BulkDeleteSubjectDepartments(subjectIDs, util.GetClientID(c))
 	if err != nil {
 		err = errorWrapf(err, "svc.BulkUpdateSubjectDepartments BulkDeleteSubjectDepartments=`%+v`", subjectIDs)
 		util.SystemErrorJSONResponse(c, err)
 		return
 	}
 
-	// delete from cache
-	impls.BatchDeleteSubjectCache(pks)
-
 	util.SuccessJSONResponse(c, "ok", nil)
 }
 
@@ -507,16 +729,13 @@ func BatchUpdateSubjectDepartments(c *gin.Context) {
 	}
 
 	svc := service.NewSubjectService()
-	pks, err := svc.BulkUpdateSubjectDepartments(svcSubjectDepartments)
+	_, err := svc.BulkUpdateSubjectDepartments(svcSubjectDepartments, util.GetClientID(c))
 	if err != nil {
 		err = errorWrapf(err, "svc.BulkUpdateSubjectDepartments subjectDepartments=`%+v`", svcSubjectDepartments)
 		util.SystemErrorJSONResponse(c, err)
 		return
 	}
 
-	// delete from cache
-	impls.BatchDeleteSubjectCache(pks)
-
 	util.SuccessJSONResponse(c, "ok", nil)
 }
 
@@ -584,7 +803,7 @@ func BatchUpdateSubject(c *gin.Context) {
 func CreateSubjectRole(c *gin.Context) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "BulkCreateSubjectRole")
 
-	var body subjectRoleSerializer
+	var body createSubjectRoleSerializer
 	if err := c.ShouldBindJSON(&body); err != nil {
 		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
 		return
@@ -594,31 +813,45 @@ func CreateSubjectRole(c *gin.Context) {
 		return
 	}
 
+	roles := body.roles()
+	svcRoles := make([]types.SubjectRole, 0, len(roles))
+	for _, role := range roles {
+		if common.IsSystemFrozen(role.SystemID) {
+			common.FrozenSystemJSONResponse(c, role.SystemID)
+			return
+		}
+
+		svcRoles = append(svcRoles, types.SubjectRole{RoleType: role.RoleType, System: role.SystemID})
+	}
+
 	svcSubjects := make([]types.Subject, 0, len(body.Subjects))
 	copier.Copy(&svcSubjects, &body.Subjects)
 
 	// TODO: 校验 systemID 存在
 
 	svc := service.NewSubjectService()
-	err := svc.BulkCreateSubjectRoles(body.RoleType, body.SystemID, svcSubjects)
+
+	var err error
+	if len(svcRoles) == 1 {
+		err = svc.BulkCreateSubjectRoles(
+			svcRoles[0].RoleType, svcRoles[0].System, svcSubjects, body.Operator, util.GetClientID(c),
+		)
+	} else {
+		// 跨系统批量授予角色, 在同一个事务中完成
+		err = svc.BulkCreateSubjectMultiRoles(svcRoles, svcSubjects, body.Operator, util.GetClientID(c))
+	}
 
 	if err != nil {
 		err = errorWrapf(
 			err,
-			"svc.BulkCreateSubjectRoles roleType=`%s`, system=`%s`, subjects=`%+v`",
-			body.RoleType,
-			body.SystemID,
+			"svc.BulkCreateSubjectRoles roles=`%+v`, subjects=`%+v`",
+			svcRoles,
 			svcSubjects,
 		)
 		util.SystemErrorJSONResponse(c, err)
 		return
 	}
 
-	// clean cache
-	for _, subject := range svcSubjects {
-		impls.DeleteSubjectRoleSystemID(subject.Type, subject.ID)
-	}
-
 	util.SuccessJSONResponse(c, "ok", nil)
 }
 
@@ -636,11 +869,18 @@ func DeleteSubjectRole(c *gin.Context) {
 		return
 	}
 
+	if common.IsSystemFrozen(body.SystemID) {
+		common.FrozenSystemJSONResponse(c, body.SystemID)
+		return
+	}
+
 	svcSubjects := make([]types.Subject, 0, len(body.Subjects))
 	copier.Copy(&svcSubjects, &body.Subjects)
 
 	svc := service.NewSubjectService()
-	err := svc.BulkDeleteSubjectRoles(body.RoleType, body.SystemID, svcSubjects)
+	err := svc.BulkDeleteSubjectRoles(
+		body.RoleType, body.SystemID, svcSubjects, body.Operator, util.GetClientID(c),
+	)
 
 	if err != nil {
 		err = errorWrapf(
@@ -654,11 +894,6 @@ func DeleteSubjectRole(c *gin.Context) {
 		return
 	}
 
-	// clean cache
-	for _, subject := range svcSubjects {
-		impls.DeleteSubjectRoleSystemID(subject.Type, subject.ID)
-	}
-
 	util.SuccessJSONResponse(c, "ok", nil)
 }
 
@@ -0,0 +1,207 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/cache/impls"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// subjectAttributeProviderMaskedToken 返回时隐藏回调的token
+const subjectAttributeProviderMaskedToken = "******"
+
+// ListSubjectAttributeProviders godoc
+// @Summary list subject attribute providers/查询subject属性提供方
+// @Description list all the subject attribute providers, the token of the http provider is masked
+// @ID api-web-list-subject-attribute-providers
+// @Tags web
+// @Accept json
+// @Produce json
+// @Success 200 {object} util.Response{data=[]svctypes.SubjectAttributeProvider}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-attribute-providers [get]
+func ListSubjectAttributeProviders(c *gin.Context) {
+	svc := service.NewSubjectAttributeProviderService()
+	providers, err := svc.ListAll()
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "ListSubjectAttributeProviders", "svc.ListAll fail")
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	for i := range providers {
+		maskSubjectAttributeProviderToken(&providers[i])
+	}
+
+	util.SuccessJSONResponse(c, "ok", providers)
+}
+
+// CreateSubjectAttributeProvider godoc
+// @Summary create subject attribute provider/创建subject属性提供方
+// @Description create a provider, the provided attributes can be used in the conditions as subject.{attribute}
+// @ID api-web-create-subject-attribute-provider
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param body body createSubjectAttributeProviderSerializer true "the provider"
+// @Success 200 {object} util.Response
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-attribute-providers [post]
+func CreateSubjectAttributeProvider(c *gin.Context) {
+	var body createSubjectAttributeProviderSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	if valid, message := body.validate(); !valid {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "CreateSubjectAttributeProvider")
+
+	svc := service.NewSubjectAttributeProviderService()
+	_, err := svc.Get(body.ID)
+	if err == nil {
+		util.ConflictJSONResponse(c, fmt.Sprintf("subject attribute provider(%s) already exists", body.ID))
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		err = errorWrapf(err, "svc.Get id=`%s` fail", body.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	err = svc.Create(body.toServiceProvider(body.ID))
+	if err != nil {
+		err = errorWrapf(err, "svc.Create id=`%s` fail", body.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	deleteSubjectAttributeProvidersCache()
+
+	util.SuccessJSONResponse(c, "ok", gin.H{})
+}
+
+// UpdateSubjectAttributeProvider godoc
+// @Summary update subject attribute provider/更新subject属性提供方
+// @Description update the provider, the cached attributes will be expired by the cache_ttl
+// @ID api-web-update-subject-attribute-provider
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param provider_id path string true "provider id"
+// @Param body body updateSubjectAttributeProviderSerializer true "the provider"
+// @Success 200 {object} util.Response
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-attribute-providers/{provider_id} [put]
+func UpdateSubjectAttributeProvider(c *gin.Context) {
+	var body updateSubjectAttributeProviderSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	if valid, message := body.validate(); !valid {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "UpdateSubjectAttributeProvider")
+
+	providerID := c.Param("provider_id")
+	svc := service.NewSubjectAttributeProviderService()
+	oldProvider, err := svc.Get(providerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		util.NotFoundJSONResponse(c, fmt.Sprintf("subject attribute provider(%s)", providerID))
+		return
+	}
+	if err != nil {
+		err = errorWrapf(err, "svc.Get id=`%s` fail", providerID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	provider := body.toServiceProvider(providerID)
+	// 未传入或传入隐藏后的token, 保留原来的token
+	if provider.Config.Token == "" || provider.Config.Token == subjectAttributeProviderMaskedToken {
+		provider.Config.Token = oldProvider.Config.Token
+	}
+
+	err = svc.Update(provider)
+	if err != nil {
+		err = errorWrapf(err, "svc.Update id=`%s` fail", providerID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	deleteSubjectAttributeProvidersCache()
+
+	util.SuccessJSONResponse(c, "ok", gin.H{})
+}
+
+// DeleteSubjectAttributeProvider godoc
+// @Summary delete subject attribute provider/删除subject属性提供方
+// @Description delete the provider, the conditions referencing the provided attributes will not match any more
+// @ID api-web-delete-subject-attribute-provider
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param provider_id path string true "provider id"
+// @Success 200 {object} util.Response
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-attribute-providers/{provider_id} [delete]
+func DeleteSubjectAttributeProvider(c *gin.Context) {
+	providerID := c.Param("provider_id")
+
+	svc := service.NewSubjectAttributeProviderService()
+	err := svc.Delete(providerID)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "DeleteSubjectAttributeProvider", "svc.Delete id=`%s` fail", providerID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	deleteSubjectAttributeProvidersCache()
+
+	util.SuccessJSONResponse(c, "ok", gin.H{})
+}
+
+func maskSubjectAttributeProviderToken(provider *svctypes.SubjectAttributeProvider) {
+	if provider.Config.Token != "" {
+		provider.Config.Token = subjectAttributeProviderMaskedToken
+	}
+}
+
+// deleteSubjectAttributeProvidersCache 清理本实例的提供方缓存, 其他实例在缓存过期后生效
+func deleteSubjectAttributeProvidersCache() {
+	err := impls.DeleteSubjectAttributeProviders()
+	if err != nil {
+		log.WithError(err).Error("delete the local subject attribute providers cache fail")
+	}
+}
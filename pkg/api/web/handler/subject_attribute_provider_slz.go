@@ -0,0 +1,85 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"fmt"
+
+	"iam/pkg/api/common"
+	svctypes "iam/pkg/service/types"
+)
+
+// subjectAttributeProviderReservedAttributes subject的内置属性, 提供方不能覆盖
+var subjectAttributeProviderReservedAttributes = map[string]struct{}{
+	"type":        {},
+	"id":          {},
+	"group":       {},
+	"groups":      {},
+	"department":  {},
+	"departments": {},
+}
+
+type subjectAttributeProviderConfigSerializer struct {
+	URL     string `json:"url" binding:"omitempty,url"`
+	Token   string `json:"token"`
+	Timeout int64  `json:"timeout" binding:"omitempty,min=1,max=30"`
+
+	Mapping map[string]map[string]interface{} `json:"mapping"`
+}
+
+type updateSubjectAttributeProviderSerializer struct {
+	Type       string                                   `json:"type" binding:"required,oneof=http static"`
+	Attributes []string                                 `json:"attributes" binding:"required,min=1,unique"`
+	Config     subjectAttributeProviderConfigSerializer `json:"config" binding:"required"`
+	CacheTTL   int64                                    `json:"cache_ttl" binding:"omitempty,min=0,max=86400"`
+}
+
+func (slz *updateSubjectAttributeProviderSerializer) validate() (bool, string) {
+	for _, name := range slz.Attributes {
+		if name == "" {
+			return false, "attributes should not contain empty name"
+		}
+		if _, ok := subjectAttributeProviderReservedAttributes[name]; ok {
+			return false, fmt.Sprintf("attribute `%s` is reserved by subject", name)
+		}
+	}
+
+	if slz.Type == svctypes.SubjectAttributeProviderTypeHTTP && slz.Config.URL == "" {
+		return false, "config.url is required for the http provider"
+	}
+	return true, ""
+}
+
+func (slz *updateSubjectAttributeProviderSerializer) toServiceProvider(id string) svctypes.SubjectAttributeProvider {
+	return svctypes.SubjectAttributeProvider{
+		ID:         id,
+		Type:       slz.Type,
+		Attributes: slz.Attributes,
+		Config: svctypes.SubjectAttributeProviderConfig{
+			URL:     slz.Config.URL,
+			Token:   slz.Config.Token,
+			Timeout: slz.Config.Timeout,
+			Mapping: slz.Config.Mapping,
+		},
+		CacheTTL: slz.CacheTTL,
+	}
+}
+
+type createSubjectAttributeProviderSerializer struct {
+	ID string `json:"id" binding:"required,max=32"`
+	updateSubjectAttributeProviderSerializer
+}
+
+func (slz *createSubjectAttributeProviderSerializer) validate() (bool, string) {
+	if !common.ValidIDRegex.MatchString(slz.ID) {
+		return false, common.ErrInvalidID.Error()
+	}
+	return slz.updateSubjectAttributeProviderSerializer.validate()
+}
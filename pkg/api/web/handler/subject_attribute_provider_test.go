@@ -0,0 +1,230 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache/impls"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestCreateSubjectAttributeProvider(t *testing.T) {
+	url := "/api/v1/web/subject-attribute-providers"
+
+	t.Run("bad request with invalid type", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, CreateSubjectAttributeProvider)(t).
+			JSON(map[string]interface{}{
+				"id":         "hr",
+				"type":       "ldap",
+				"attributes": []string{"job_level"},
+				"config":     map[string]interface{}{},
+			}).
+			BadRequestContainsMessage("Type")
+	})
+
+	t.Run("bad request with reserved attribute", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, CreateSubjectAttributeProvider)(t).
+			JSON(map[string]interface{}{
+				"id":         "hr",
+				"type":       "static",
+				"attributes": []string{"group"},
+				"config":     map[string]interface{}{},
+			}).
+			BadRequestContainsMessage("reserved")
+	})
+
+	t.Run("bad request with http provider without url", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, CreateSubjectAttributeProvider)(t).
+			JSON(map[string]interface{}{
+				"id":         "hr",
+				"type":       "http",
+				"attributes": []string{"job_level"},
+				"config":     map[string]interface{}{"token": "abc"},
+			}).
+			BadRequestContainsMessage("config.url")
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectAttributeProviderService(ctl)
+		mockSvc.EXPECT().Get("hr").Return(svctypes.SubjectAttributeProvider{ID: "hr"}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectAttributeProviderService,
+			func() service.SubjectAttributeProviderService {
+				return mockSvc
+			})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, CreateSubjectAttributeProvider)(t).
+			JSON(map[string]interface{}{
+				"id":         "hr",
+				"type":       "http",
+				"attributes": []string{"job_level"},
+				"config":     map[string]interface{}{"url": "http://hr.example.com/attributes"},
+			}).
+			ConflictContainsMessage("already exists")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectAttributeProviderService(ctl)
+		mockSvc.EXPECT().Get("hr").Return(svctypes.SubjectAttributeProvider{}, sql.ErrNoRows)
+		mockSvc.EXPECT().Create(svctypes.SubjectAttributeProvider{
+			ID:         "hr",
+			Type:       "http",
+			Attributes: []string{"job_level"},
+			Config:     svctypes.SubjectAttributeProviderConfig{URL: "http://hr.example.com/attributes"},
+			CacheTTL:   60,
+		}).Return(nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectAttributeProviderService,
+			func() service.SubjectAttributeProviderService {
+				return mockSvc
+			})
+		defer patches.Reset()
+		patches.ApplyFunc(impls.DeleteSubjectAttributeProviders, func() error {
+			return nil
+		})
+
+		util.CreateNewAPIRequestFunc("post", url, CreateSubjectAttributeProvider)(t).
+			JSON(map[string]interface{}{
+				"id":         "hr",
+				"type":       "http",
+				"attributes": []string{"job_level"},
+				"config":     map[string]interface{}{"url": "http://hr.example.com/attributes"},
+				"cache_ttl":  60,
+			}).
+			OK()
+	})
+}
+
+func TestUpdateSubjectAttributeProvider(t *testing.T) {
+	url := "/api/v1/web/subject-attribute-providers/hr"
+	handlerURL := "/api/v1/web/subject-attribute-providers/:provider_id"
+
+	t.Run("get fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectAttributeProviderService(ctl)
+		mockSvc.EXPECT().Get("hr").Return(svctypes.SubjectAttributeProvider{}, errors.New("get fail"))
+		patches := gomonkey.ApplyFunc(service.NewSubjectAttributeProviderService,
+			func() service.SubjectAttributeProviderService {
+				return mockSvc
+			})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("put", url, UpdateSubjectAttributeProvider, handlerURL)(t).
+			JSON(map[string]interface{}{
+				"type":       "static",
+				"attributes": []string{"region"},
+				"config":     map[string]interface{}{},
+			}).
+			SystemError()
+	})
+
+	t.Run("ok, keep the masked token", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectAttributeProviderService(ctl)
+		mockSvc.EXPECT().Get("hr").Return(svctypes.SubjectAttributeProvider{
+			ID:     "hr",
+			Config: svctypes.SubjectAttributeProviderConfig{URL: "http://hr.example.com/attributes", Token: "secret"},
+		}, nil)
+		mockSvc.EXPECT().Update(svctypes.SubjectAttributeProvider{
+			ID:         "hr",
+			Type:       "http",
+			Attributes: []string{"job_level", "region"},
+			Config: svctypes.SubjectAttributeProviderConfig{
+				URL:   "http://hr.example.com/v2/attributes",
+				Token: "secret",
+			},
+		}).Return(nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectAttributeProviderService,
+			func() service.SubjectAttributeProviderService {
+				return mockSvc
+			})
+		defer patches.Reset()
+		patches.ApplyFunc(impls.DeleteSubjectAttributeProviders, func() error {
+			return nil
+		})
+
+		util.CreateNewAPIRequestFunc("put", url, UpdateSubjectAttributeProvider, handlerURL)(t).
+			JSON(map[string]interface{}{
+				"type":       "http",
+				"attributes": []string{"job_level", "region"},
+				"config": map[string]interface{}{
+					"url":   "http://hr.example.com/v2/attributes",
+					"token": subjectAttributeProviderMaskedToken,
+				},
+			}).
+			OK()
+	})
+}
+
+func TestListSubjectAttributeProviders(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockSvc := mock.NewMockSubjectAttributeProviderService(ctl)
+	mockSvc.EXPECT().ListAll().Return([]svctypes.SubjectAttributeProvider{{
+		ID:     "hr",
+		Type:   "http",
+		Config: svctypes.SubjectAttributeProviderConfig{URL: "http://hr.example.com/attributes", Token: "secret"},
+	}}, nil)
+	patches := gomonkey.ApplyFunc(service.NewSubjectAttributeProviderService,
+		func() service.SubjectAttributeProviderService {
+			return mockSvc
+		})
+	defer patches.Reset()
+
+	util.CreateNewAPIRequestFunc("get", "/api/v1/web/subject-attribute-providers", ListSubjectAttributeProviders)(t).
+		OK()
+}
+
+func TestDeleteSubjectAttributeProvider(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockSvc := mock.NewMockSubjectAttributeProviderService(ctl)
+	mockSvc.EXPECT().Delete("hr").Return(nil)
+	patches := gomonkey.ApplyFunc(service.NewSubjectAttributeProviderService,
+		func() service.SubjectAttributeProviderService {
+			return mockSvc
+		})
+	defer patches.Reset()
+	patches.ApplyFunc(impls.DeleteSubjectAttributeProviders, func() error {
+		return nil
+	})
+
+	util.CreateNewAPIRequestFunc(
+		"delete", "/api/v1/web/subject-attribute-providers/hr", DeleteSubjectAttributeProvider,
+		"/api/v1/web/subject-attribute-providers/:provider_id",
+	)(t).OK()
+}
+
+func TestMaskSubjectAttributeProviderToken(t *testing.T) {
+	provider := svctypes.SubjectAttributeProvider{Config: svctypes.SubjectAttributeProviderConfig{Token: "secret"}}
+	maskSubjectAttributeProviderToken(&provider)
+	assert.Equal(t, subjectAttributeProviderMaskedToken, provider.Config.Token)
+}
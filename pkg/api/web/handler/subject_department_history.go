@@ -0,0 +1,71 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/util"
+)
+
+// ListSubjectDepartmentHistory godoc
+// @Summary subject department history/查询用户的部门变更记录
+// @Description list the department added/removed records of a user, the latest first
+// @ID api-web-list-subject-department-history
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param params query subjectDepartmentHistorySerializer true "the user and page"
+// @Success 200 {object} util.Response{data=[]types.SubjectDepartmentHistory}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-departments/history [get]
+func ListSubjectDepartmentHistory(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "ListSubjectDepartmentHistory")
+
+	var query subjectDepartmentHistorySerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	query.Default()
+
+	pk, err := service.NewSubjectBaseReadService().GetPK(query.Type, query.ID)
+	if err != nil {
+		err = errorWrapf(err, "svc.GetPK type=`%s`, id=`%s`", query.Type, query.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	svc := service.NewSubjectDepartmentReadService()
+	count, err := svc.GetSubjectDepartmentHistoryCount(pk)
+	if err != nil {
+		util.SystemErrorJSONResponse(c, errorWrapf(err, "svc.GetSubjectDepartmentHistoryCount pk=`%d`", pk))
+		return
+	}
+
+	histories, err := svc.ListPagingSubjectDepartmentHistory(pk, query.Limit, query.Offset)
+	if err != nil {
+		err = errorWrapf(err, "svc.ListPagingSubjectDepartmentHistory pk=`%d`, limit=`%d`, offset=`%d`",
+			pk, query.Limit, query.Offset)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"count":   count,
+		"results": histories,
+	})
+}
@@ -0,0 +1,80 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestListSubjectDepartmentHistory(t *testing.T) {
+	url := "/api/v1/web/subject-departments/history"
+
+	t.Run("bad request with invalid type", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectDepartmentHistory)(t).
+			QueryParams(map[string]string{"type": "group", "id": "1"}).BadRequestContainsMessage("Type")
+	})
+
+	t.Run("get pk fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectReadService(ctl)
+		mockSvc.EXPECT().GetPK("user", "admin").Return(int64(0), errors.New("get pk fail"))
+		patches := gomonkey.ApplyFunc(service.NewSubjectBaseReadService, func() service.SubjectBaseReadService {
+			return mockSvc
+		})
+		patches.ApplyFunc(service.NewSubjectDepartmentReadService, func() service.SubjectDepartmentReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectDepartmentHistory)(t).
+			QueryParams(map[string]string{"type": "user", "id": "admin"}).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectReadService(ctl)
+		mockSvc.EXPECT().GetPK("user", "admin").Return(int64(1), nil)
+		mockSvc.EXPECT().GetSubjectDepartmentHistoryCount(int64(1)).Return(int64(1), nil)
+		mockSvc.EXPECT().ListPagingSubjectDepartmentHistory(int64(1), int64(20), int64(0)).Return(
+			[]svctypes.SubjectDepartmentHistory{{
+				DepartmentID:   "10",
+				DepartmentName: "dept",
+				Action:         "removed",
+				Source:         "bk_iam",
+				CreatedAt:      time.Now(),
+			}}, nil,
+		)
+		patches := gomonkey.ApplyFunc(service.NewSubjectBaseReadService, func() service.SubjectBaseReadService {
+			return mockSvc
+		})
+		patches.ApplyFunc(service.NewSubjectDepartmentReadService, func() service.SubjectDepartmentReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectDepartmentHistory)(t).
+			QueryParams(map[string]string{"type": "user", "id": "admin"}).OK()
+	})
+}
@@ -0,0 +1,137 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/api/common"
+	"iam/pkg/cache/invalidation"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+type freezeSubjectSerializer struct {
+	// 防御, 避免一次性冻结太多subject
+	Subjects []deleteSubjectSerializer `json:"subjects" binding:"required,gt=0,lte=1000"`
+}
+
+func (slz *freezeSubjectSerializer) validate() (bool, string) {
+	return common.ValidateArray(slz.Subjects)
+}
+
+// ListFrozenSubjects godoc
+// @Summary list frozen subjects/查询冻结的subject
+// @Description list all the frozen subjects, the frozen subject has no permission
+// @ID api-web-list-frozen-subjects
+// @Tags web
+// @Accept json
+// @Produce json
+// @Success 200 {object} util.Response{data=[]types.Subject}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/frozen-subjects [get]
+func ListFrozenSubjects(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "ListFrozenSubjects")
+
+	svc := service.NewSubjectService()
+	pks, err := svc.ListFrozenPKs()
+	if err != nil {
+		err = errorWrapf(err, "svc.ListFrozenPKs")
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	subjects, err := svc.ListByPKs(pks)
+	if err != nil {
+		err = errorWrapf(err, "svc.ListByPKs pks=`%+v`", pks)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", subjects)
+}
+
+// BatchFreezeSubjects godoc
+// @Summary freeze subjects/冻结subject
+// @Description freeze the subjects, the auth of the frozen subject will be denied, the data will not be deleted
+// @ID api-web-freeze-subjects
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param body body freezeSubjectSerializer true "the subjects"
+// @Success 200 {object} util.Response
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/frozen-subjects [post]
+func BatchFreezeSubjects(c *gin.Context) {
+	batchUpdateSubjectsFrozen(c, true)
+}
+
+// BatchUnfreezeSubjects godoc
+// @Summary unfreeze subjects/解冻subject
+// @Description unfreeze the subjects, the permissions of the subjects will take effect again
+// @ID api-web-unfreeze-subjects
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param body body freezeSubjectSerializer true "the subjects"
+// @Success 200 {object} util.Response
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/frozen-subjects [delete]
+func BatchUnfreezeSubjects(c *gin.Context) {
+	batchUpdateSubjectsFrozen(c, false)
+}
+
+func batchUpdateSubjectsFrozen(c *gin.Context, frozen bool) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "batchUpdateSubjectsFrozen")
+
+	var body freezeSubjectSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	if valid, message := body.validate(); !valid {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
+	svcSubjects := make([]types.Subject, 0, len(body.Subjects))
+	copier.Copy(&svcSubjects, &body.Subjects)
+
+	svc := service.NewSubjectService()
+	pks, err := svc.BulkUpdateFrozen(svcSubjects, frozen)
+	if err != nil {
+		err = errorWrapf(err, "svc.BulkUpdateFrozen subjects=`%+v`, frozen=`%t`", svcSubjects, frozen)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	// 清理所有实例的冻结subject缓存, 失败时由失效重试队列重试
+	err = invalidation.DeleteFrozenSubjects()
+	if err != nil {
+		log.WithError(err).Error("invalidation.DeleteFrozenSubjects fail")
+	}
+
+	log.Infof("batchUpdateSubjectsFrozen by client=`%s`, frozen=`%t`, subjects=`%+v`, pks=`%v`",
+		util.GetClientID(c), frozen, svcSubjects, pks)
+
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"count": len(pks),
+	})
+}
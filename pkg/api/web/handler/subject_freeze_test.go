@@ -0,0 +1,139 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache/invalidation"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestListFrozenSubjects(t *testing.T) {
+	url := "/api/v1/web/frozen-subjects"
+
+	t.Run("list fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().ListFrozenPKs().Return(nil, errors.New("list fail"))
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListFrozenSubjects)(t).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().ListFrozenPKs().Return([]int64{1}, nil)
+		mockSvc.EXPECT().ListByPKs([]int64{1}).Return([]types.Subject{{Type: "user", ID: "admin"}}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListFrozenSubjects)(t).OK()
+	})
+}
+
+func TestBatchFreezeSubjects(t *testing.T) {
+	url := "/api/v1/web/frozen-subjects"
+	body := map[string]interface{}{
+		"subjects": []map[string]interface{}{{"type": "user", "id": "admin"}},
+	}
+
+	t.Run("bad request without subjects", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, BatchFreezeSubjects)(t).
+			JSON(map[string]interface{}{"subjects": []map[string]interface{}{}}).
+			BadRequestContainsMessage("Subjects")
+	})
+
+	t.Run("bad request invalid subject", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, BatchFreezeSubjects)(t).
+			JSON(map[string]interface{}{
+				"subjects": []map[string]interface{}{{"type": "app", "id": "admin"}},
+			}).BadRequestContainsMessage("data in array[0]")
+	})
+
+	t.Run("freeze fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().BulkUpdateFrozen([]types.Subject{{Type: "user", ID: "admin"}}, true).Return(
+			nil, errors.New("freeze fail"))
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, BatchFreezeSubjects)(t).JSON(body).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().BulkUpdateFrozen([]types.Subject{{Type: "user", ID: "admin"}}, true).Return(
+			[]int64{1}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		invalidated := false
+		patches.ApplyFunc(invalidation.DeleteFrozenSubjects, func() error {
+			invalidated = true
+			return nil
+		})
+
+		util.CreateNewAPIRequestFunc("post", url, BatchFreezeSubjects)(t).JSON(body).OK()
+		assert.True(t, invalidated)
+	})
+}
+
+func TestBatchUnfreezeSubjects(t *testing.T) {
+	url := "/api/v1/web/frozen-subjects"
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockSvc := mock.NewMockSubjectService(ctl)
+	mockSvc.EXPECT().BulkUpdateFrozen([]types.Subject{{Type: "user", ID: "admin"}}, false).Return(
+		[]int64{1}, nil)
+	patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+		return mockSvc
+	})
+	defer patches.Reset()
+	patches.ApplyFunc(invalidation.DeleteFrozenSubjects, func() error {
+		return errors.New("invalidate fail")
+	})
+
+	// the invalidation will be retried, not fail the request
+	util.CreateNewAPIRequestFunc("delete", url, BatchUnfreezeSubjects)(t).
+		JSON(map[string]interface{}{
+			"subjects": []map[string]interface{}{{"type": "user", "id": "admin"}},
+		}).OK()
+}
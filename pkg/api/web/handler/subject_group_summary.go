@@ -0,0 +1,143 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// GetSubjectGroupSummary godoc
+// @Summary subject group summary/查询subject的用户组统计
+// @Description count the direct groups, department-inherited groups and the systems covered by the groups of a subject
+// @ID api-web-get-subject-group-summary
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param params query subjectGroupSummarySerializer true "the subject"
+// @Success 200 {object} util.Response{data=subjectGroupSummaryResponse}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-relations/summary [get]
+func GetSubjectGroupSummary(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "GetSubjectGroupSummary")
+
+	var query subjectGroupSummarySerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	pk, err := service.NewSubjectBaseReadService().GetPK(query.Type, query.ID)
+	if err != nil {
+		err = errorWrapf(err, "svc.GetPK type=`%s`, id=`%s`", query.Type, query.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	// 只有用户才有继承自部门的用户组
+	subjectPKs := []int64{pk}
+	if query.Type == svctypes.UserType {
+		departmentPKs, err := service.NewSubjectDepartmentReadService().GetSubjectDepartmentPKs(pk)
+		if err != nil {
+			err = errorWrapf(err, "svc.GetSubjectDepartmentPKs pk=`%d`", pk)
+			util.SystemErrorJSONResponse(c, err)
+			return
+		}
+		subjectPKs = append(subjectPKs, departmentPKs...)
+	}
+
+	subjectGroups, err := service.NewSubjectGroupReadService().ListSubjectEffectGroups(subjectPKs)
+	if err != nil {
+		err = errorWrapf(err, "svc.ListSubjectEffectGroups pks=`%+v`", subjectPKs)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	// 部门继承的用户组, 不包含已经直接加入的
+	directGroupPKs := util.NewInt64Set()
+	for _, g := range subjectGroups[pk] {
+		directGroupPKs.Add(g.PK)
+	}
+	departmentGroupPKs := util.NewInt64Set()
+	for subjectPK, groups := range subjectGroups {
+		if subjectPK == pk {
+			continue
+		}
+		for _, g := range groups {
+			if !directGroupPKs.Has(g.PK) {
+				departmentGroupPKs.Add(g.PK)
+			}
+		}
+	}
+
+	groupPKs := append(directGroupPKs.ToSlice(), departmentGroupPKs.ToSlice()...)
+
+	systems, err := countGroupsBySystem(subjectPKs, groupPKs)
+	if err != nil {
+		err = errorWrapf(err, "countGroupsBySystem subjectPKs=`%+v`, groupPKs=`%+v`", subjectPKs, groupPKs)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", subjectGroupSummaryResponse{
+		DirectGroupCount:     directGroupPKs.Size(),
+		DepartmentGroupCount: departmentGroupPKs.Size(),
+		SystemCount:          len(systems),
+		Systems:              systems,
+	})
+}
+
+// countGroupsBySystem 统计每个系统下有策略的用户组数量
+// subjects在各个系统下有策略的用户组来自subject_system_group, 只统计groupPKs中成员关系未过期的用户组
+func countGroupsBySystem(subjectPKs []int64, groupPKs []int64) ([]systemGroupCount, error) {
+	systems := []systemGroupCount{}
+	if len(groupPKs) == 0 {
+		return systems, nil
+	}
+
+	svc := service.NewSubjectSystemGroupService()
+	systemGroups, err := svc.ListSystemGroupsBySubjectPKs(subjectPKs)
+	if err != nil {
+		return nil, err
+	}
+
+	groupPKSet := util.NewInt64SetWithValues(groupPKs)
+	nowUnix := time.Now().Unix()
+	for system, groups := range systemGroups {
+		pks := util.NewInt64Set()
+		for _, g := range groups {
+			if g.PolicyExpiredAt > nowUnix && groupPKSet.Has(g.PK) {
+				pks.Add(g.PK)
+			}
+		}
+
+		if pks.Size() == 0 {
+			continue
+		}
+		systems = append(systems, systemGroupCount{
+			System:     system,
+			GroupCount: pks.Size(),
+		})
+	}
+	sort.Slice(systems, func(i, j int) bool {
+		return systems[i].System < systems[j].System
+	})
+	return systems, nil
+}
@@ -0,0 +1,140 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestGetSubjectGroupSummary(t *testing.T) {
+	url := "/api/v1/web/subject-relations/summary"
+
+	t.Run("bad request without type", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, GetSubjectGroupSummary)(t).
+			QueryParams(map[string]string{"id": "admin"}).BadRequestContainsMessage("Type")
+	})
+
+	t.Run("get pk fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectReadService(ctl)
+		mockSvc.EXPECT().GetPK("user", "admin").Return(int64(0), errors.New("get pk fail"))
+		patches := gomonkey.ApplyFunc(service.NewSubjectBaseReadService, func() service.SubjectBaseReadService {
+			return mockSvc
+		})
+		patches.ApplyFunc(service.NewSubjectDepartmentReadService, func() service.SubjectDepartmentReadService {
+			return mockSvc
+		})
+		patches.ApplyFunc(service.NewSubjectGroupReadService, func() service.SubjectGroupReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, GetSubjectGroupSummary)(t).
+			QueryParams(map[string]string{"type": "user", "id": "admin"}).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectReadService(ctl)
+		mockSvc.EXPECT().GetPK("user", "admin").Return(int64(1), nil)
+		mockSvc.EXPECT().GetSubjectDepartmentPKs(int64(1)).Return([]int64{2}, nil)
+		mockSvc.EXPECT().ListSubjectEffectGroups([]int64{1, 2}).Return(map[int64][]svctypes.ThinSubjectGroup{
+			1: {{PK: 10}},
+			2: {{PK: 10}, {PK: 11}},
+		}, nil)
+
+		mockSystemGroupSvc := mock.NewMockSubjectSystemGroupService(ctl)
+		mockSystemGroupSvc.EXPECT().ListSystemGroupsBySubjectPKs([]int64{1, 2}).Return(
+			map[string][]svctypes.ThinSubjectGroup{
+				"bk_test": {{PK: 10, PolicyExpiredAt: time.Now().Unix() + 100}},
+			}, nil)
+
+		patches := gomonkey.ApplyFunc(service.NewSubjectBaseReadService, func() service.SubjectBaseReadService {
+			return mockSvc
+		})
+		patches.ApplyFunc(service.NewSubjectDepartmentReadService, func() service.SubjectDepartmentReadService {
+			return mockSvc
+		})
+		patches.ApplyFunc(service.NewSubjectGroupReadService, func() service.SubjectGroupReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+		patches.ApplyFunc(service.NewSubjectSystemGroupService, func() service.SubjectSystemGroupService {
+			return mockSystemGroupSvc
+		})
+
+		util.CreateNewAPIRequestFunc("get", url, GetSubjectGroupSummary)(t).
+			QueryParams(map[string]string{"type": "user", "id": "admin"}).OK()
+	})
+}
+
+func TestCountGroupsBySystem(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	now := time.Now().Unix()
+	mockSystemGroupSvc := mock.NewMockSubjectSystemGroupService(ctl)
+	patches := gomonkey.ApplyFunc(service.NewSubjectSystemGroupService, func() service.SubjectSystemGroupService {
+		return mockSystemGroupSvc
+	})
+	defer patches.Reset()
+
+	t.Run("list fail", func(t *testing.T) {
+		mockSystemGroupSvc.EXPECT().ListSystemGroupsBySubjectPKs([]int64{1, 2}).Return(nil, errors.New("list fail"))
+
+		_, err := countGroupsBySystem([]int64{1, 2}, []int64{10, 11})
+		assert.Error(t, err)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		mockSystemGroupSvc.EXPECT().ListSystemGroupsBySubjectPKs([]int64{1, 2}).Return(
+			map[string][]svctypes.ThinSubjectGroup{
+				// the group 10 from the user and the department counts once
+				"bk_test": {
+					{PK: 10, PolicyExpiredAt: now + 100},
+					{PK: 10, PolicyExpiredAt: now + 100},
+					{PK: 11, PolicyExpiredAt: now + 100},
+				},
+				// the nested group 12 is not in the groups
+				"bk_cmdb": {{PK: 11, PolicyExpiredAt: now + 100}, {PK: 12, PolicyExpiredAt: now + 100}},
+				// the expired group
+				"bk_job": {{PK: 10, PolicyExpiredAt: now - 100}},
+			}, nil)
+
+		systems, err := countGroupsBySystem([]int64{1, 2}, []int64{10, 11})
+		assert.NoError(t, err)
+		assert.Equal(t, []systemGroupCount{
+			{System: "bk_cmdb", GroupCount: 1},
+			{System: "bk_test", GroupCount: 2},
+		}, systems)
+	})
+
+	t.Run("ok, no groups", func(t *testing.T) {
+		systems, err := countGroupsBySystem([]int64{1}, []int64{})
+		assert.NoError(t, err)
+		assert.Empty(t, systems)
+	})
+}
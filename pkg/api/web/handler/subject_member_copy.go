@@ -0,0 +1,88 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// CopySubjectMembers godoc
+// @Summary copy group members/复制或移动用户组的成员
+// @Description copy(or move) all the members of the source group to the target group in one transaction,
+// @Description the expired_at of the members are preserved
+// @ID api-web-copy-subject-members
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param body body copySubjectMembersSerializer true "the source and target group"
+// @Success 200 {object} util.Response{data=gin.H}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-members/copy [post]
+func CopySubjectMembers(c *gin.Context) {
+	var body copySubjectMembersSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	if ok, message := body.validate(); !ok {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
+	svc := service.NewSubjectService()
+	result, err := svc.CopySubjectMembers(body.Type, body.SourceID, body.TargetID, body.Move)
+	if errors.Is(err, sql.ErrNoRows) {
+		util.BadRequestErrorJSONResponse(c, "source or target group not exists")
+		return
+	}
+	if errors.Is(err, service.ErrGroupMemberCycle) {
+		util.BadRequestErrorJSONResponse(c, service.ErrGroupMemberCycle.Error())
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "CopySubjectMembers",
+			"type=`%s`, source_id=`%s`, target_id=`%s`, move=`%t`", body.Type, body.SourceID, body.TargetID, body.Move)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	source := types.Subject{Type: body.Type, ID: body.SourceID}
+	target := types.Subject{Type: body.Type, ID: body.TargetID}
+	clientID := util.GetClientID(c)
+	recordSubjectMemberRelationEvents(
+		target, result.Added, service.SubjectMemberEventActionAdded, body.Operator, clientID)
+	recordSubjectMemberRelationEvents(
+		target, result.Renewed, service.SubjectMemberEventActionRenewed, body.Operator, clientID)
+	if body.Move {
+		removed := make([]types.Subject, 0, len(result.Removed))
+		for _, m := range result.Removed {
+			removed = append(removed, types.Subject{Type: m.Type, ID: m.ID})
+		}
+		recordSubjectMemberEvents(
+			source, removed, service.SubjectMemberEventActionRemoved, 0, body.Operator, clientID)
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"added":   len(result.Added),
+		"renewed": len(result.Renewed),
+		"removed": len(result.Removed),
+	})
+}
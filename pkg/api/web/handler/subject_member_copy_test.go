@@ -0,0 +1,102 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestCopySubjectMembers(t *testing.T) {
+	url := "/api/v1/web/subject-members/copy"
+
+	t.Run("bad request with same group", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("post", url, CopySubjectMembers)(t).
+			JSON(map[string]interface{}{"type": "group", "source_id": "1", "target_id": "1"}).
+			BadRequestContainsMessage("source_id and target_id should be different")
+	})
+
+	t.Run("group not exists", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().CopySubjectMembers("group", "1", "2", false).Return(
+			svctypes.SubjectMemberCopyResult{}, errorx.Wrapf(sql.ErrNoRows, "SubjectSVC", "CopySubjectMembers", ""))
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, CopySubjectMembers)(t).
+			JSON(map[string]interface{}{"type": "group", "source_id": "1", "target_id": "2"}).
+			BadRequestContainsMessage("group not exists")
+	})
+
+	t.Run("copy fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().CopySubjectMembers("group", "1", "2", true).Return(
+			svctypes.SubjectMemberCopyResult{}, errors.New("copy fail"))
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, CopySubjectMembers)(t).
+			JSON(map[string]interface{}{"type": "group", "source_id": "1", "target_id": "2", "move": true}).
+			SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().CopySubjectMembers("group", "1", "2", true).Return(svctypes.SubjectMemberCopyResult{
+			Added:   []svctypes.SubjectMember{{Type: "user", ID: "tom", PolicyExpiredAt: 1000}},
+			Removed: []svctypes.SubjectMember{{Type: "user", ID: "tom", PolicyExpiredAt: 1000}},
+		}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		var recorded []svctypes.SubjectMemberEvent
+		patches.ApplyFunc(service.RecordSubjectMemberEvents, func(events []svctypes.SubjectMemberEvent) {
+			recorded = append(recorded, events...)
+		})
+
+		util.CreateNewAPIRequestFunc("post", url, CopySubjectMembers)(t).
+			JSON(map[string]interface{}{
+				"type": "group", "source_id": "1", "target_id": "2", "move": true, "operator": "admin",
+			}).OK()
+
+		assert.Len(t, recorded, 2)
+		assert.Equal(t, "added", recorded[0].Action)
+		assert.Equal(t, "2", recorded[0].GroupID)
+		assert.Equal(t, "removed", recorded[1].Action)
+		assert.Equal(t, "1", recorded[1].GroupID)
+	})
+}
@@ -0,0 +1,323 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+/*
+ * > 用户组成员的CSV导入/导出
+ *
+ * CSV的表头为 `type,id[,policy_expired_at]`, 导出的文件可以直接导入, 导入时忽略id之后的列,
+ * 成员的过期时间统一使用请求参数中的policy_expired_at
+ *
+ * 导入时逐行校验(类型/ID格式/重复/成员是否存在), 校验失败的行记录在结果中, 不影响其他行;
+ * 校验通过的成员按批次添加, 与批量添加成员接口的逻辑一致(已存在的成员只延长过期时间, 新成员需要通过添加成员的钩子)
+ */
+
+var (
+	// 单次导入最多的行数
+	subjectMemberCSVMaxRows = 10000
+	// 每批添加的成员数量, 与批量添加成员接口的上限一致
+	subjectMemberCSVChunkSize = 1000
+	// 导入的CSV文件大小上限
+	subjectMemberCSVMaxBytes int64 = 2 << 20
+)
+
+var subjectMemberCSVHeader = []string{"type", "id", "policy_expired_at"}
+
+type subjectMemberCSVRow struct {
+	Row    int
+	Member types.Subject
+}
+
+type subjectMemberCSVRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+type importSubjectMembersResponse struct {
+	DryRun   bool                       `json:"dry_run"`
+	Total    int                        `json:"total"`
+	Valid    int                        `json:"valid"`
+	Created  int64                      `json:"created"`
+	Updated  int64                      `json:"updated"`
+	Pending  []types.Subject            `json:"pending"`
+	Rejected []types.Subject            `json:"rejected"`
+	Errors   []subjectMemberCSVRowError `json:"errors"`
+}
+
+// ExportSubjectMembersCSV godoc
+// @Summary export group members as csv/导出用户组成员
+// @Description export all the members of a group as a csv file, with the header `type,id,policy_expired_at`
+// @ID api-web-export-subject-members-csv
+// @Tags web
+// @Accept json
+// @Produce text/csv
+// @Param params query exportSubjectMembersSerializer true "the group"
+// @Success 200 {string} string "the csv file"
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-members/export [get]
+func ExportSubjectMembersCSV(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "ExportSubjectMembersCSV")
+
+	var query exportSubjectMembersSerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	svc := service.NewSubjectService()
+	members, err := svc.ListMember(query.Type, query.ID)
+	if err != nil {
+		err = errorWrapf(err, "svc.ListMember type=`%s` id=`%s`", query.Type, query.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(subjectMemberCSVHeader)
+	for _, m := range members {
+		w.Write([]string{m.Type, m.ID, strconv.FormatInt(m.PolicyExpiredAt, 10)})
+	}
+	w.Flush()
+	if err = w.Error(); err != nil {
+		util.SystemErrorJSONResponse(c, errorWrapf(err, "write csv fail"))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s_%s_members.csv", query.Type, query.ID))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// ImportSubjectMembersCSV godoc
+// @Summary import group members from csv/从CSV导入用户组成员
+// @Description validate each row of the csv(request body), then add the valid members in chunks, support dry-run
+// @ID api-web-import-subject-members-csv
+// @Tags web
+// @Accept text/csv
+// @Produce json
+// @Param params query importSubjectMembersSerializer true "the group and the options"
+// @Success 200 {object} util.Response{data=importSubjectMembersResponse}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-members/import [post]
+func ImportSubjectMembersCSV(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "ImportSubjectMembersCSV")
+
+	var query importSubjectMembersSerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	policyExpiredAt, err := fillGroupDefaultPolicyExpiredAt(query.Type, query.ID, query.PolicyExpiredAt)
+	if err != nil {
+		util.SystemErrorJSONResponse(c, errorWrapf(err, "fillGroupDefaultPolicyExpiredAt fail"))
+		return
+	}
+	query.PolicyExpiredAt = policyExpiredAt
+
+	if err := query.fillPolicyExpiredAt(); err != nil {
+		util.BadRequestErrorJSONResponse(c, err.Error())
+		return
+	}
+	if ok, message := query.validate(); !ok {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, subjectMemberCSVMaxBytes)
+	rows, rowErrors, err := parseSubjectMembersCSV(body)
+	if err != nil {
+		util.BadRequestErrorJSONResponse(c, err.Error())
+		return
+	}
+
+	resp := importSubjectMembersResponse{
+		DryRun:   query.DryRun,
+		Total:    len(rows) + len(rowErrors),
+		Pending:  []types.Subject{},
+		Rejected: []types.Subject{},
+	}
+
+	// 成员必须存在
+	svc := service.NewSubjectService()
+	rows, notExistErrors, err := filterExistSubjectMemberRows(svc, rows)
+	if err != nil {
+		util.SystemErrorJSONResponse(c, errorWrapf(err, "filterExistSubjectMemberRows fail"))
+		return
+	}
+	rowErrors = append(rowErrors, notExistErrors...)
+	resp.Valid = len(rows)
+
+	if !query.DryRun {
+		group := types.Subject{Type: query.Type, ID: query.ID}
+		for start := 0; start < len(rows); start += subjectMemberCSVChunkSize {
+			end := start + subjectMemberCSVChunkSize
+			if end > len(rows) {
+				end = len(rows)
+			}
+			chunk := rows[start:end]
+
+			members := make([]types.Subject, 0, len(chunk))
+			for _, r := range chunk {
+				members = append(members, r.Member)
+			}
+
+			result, err := addSubjectMembers(
+				svc, group, members, query.PolicyExpiredAt, util.GetClientID(c), query.Operator)
+			if err != nil {
+				// 失败的批次记录到每一行, 继续处理下一批
+				for _, r := range chunk {
+					rowErrors = append(rowErrors, subjectMemberCSVRowError{Row: r.Row, Error: err.Error()})
+				}
+				continue
+			}
+
+			for _, count := range result.TypeCount {
+				resp.Created += count
+			}
+			resp.Updated += result.Updated
+			resp.Pending = append(resp.Pending, result.Pending...)
+			resp.Rejected = append(resp.Rejected, result.Rejected...)
+		}
+	}
+
+	resp.Errors = sortSubjectMemberCSVRowErrors(rowErrors)
+	util.SuccessJSONResponse(c, "ok", resp)
+}
+
+// parseSubjectMembersCSV 解析并逐行校验CSV, 返回校验通过的行及校验失败的行; 文件格式错误时返回error
+func parseSubjectMembersCSV(r io.Reader) ([]subjectMemberCSVRow, []subjectMemberCSVRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, errors.New("the csv is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("read the csv header fail, %w", err)
+	}
+	if len(header) < 2 ||
+		strings.TrimPrefix(strings.TrimSpace(header[0]), "\ufeff") != subjectMemberCSVHeader[0] ||
+		strings.TrimSpace(header[1]) != subjectMemberCSVHeader[1] {
+		return nil, nil, fmt.Errorf("the csv header should be `%s`", strings.Join(subjectMemberCSVHeader, ","))
+	}
+
+	rows := []subjectMemberCSVRow{}
+	rowErrors := []subjectMemberCSVRowError{}
+	seen := map[string]int{}
+	// the header is the row 1
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("read the csv row %d fail, %w", row, err)
+		}
+		if len(rows)+len(rowErrors) >= subjectMemberCSVMaxRows {
+			return nil, nil, fmt.Errorf("the csv should not contain more than %d rows", subjectMemberCSVMaxRows)
+		}
+
+		if len(record) < 2 {
+			rowErrors = append(rowErrors, subjectMemberCSVRowError{Row: row, Error: "type and id are required"})
+			continue
+		}
+
+		member := memberSerializer{Type: strings.TrimSpace(record[0]), ID: strings.TrimSpace(record[1])}
+		if err := binding.Validator.ValidateStruct(member); err != nil {
+			rowErrors = append(rowErrors, subjectMemberCSVRowError{Row: row, Error: util.ValidationErrorMessage(err)})
+			continue
+		}
+
+		key := member.Type + ":" + member.ID
+		if firstRow, ok := seen[key]; ok {
+			rowErrors = append(rowErrors, subjectMemberCSVRowError{
+				Row:   row,
+				Error: fmt.Sprintf("duplicated with row %d", firstRow),
+			})
+			continue
+		}
+		seen[key] = row
+
+		rows = append(rows, subjectMemberCSVRow{Row: row, Member: types.Subject{Type: member.Type, ID: member.ID}})
+	}
+
+	return rows, rowErrors, nil
+}
+
+// filterExistSubjectMemberRows 过滤出成员存在的行, 不存在的记录为失败的行
+func filterExistSubjectMemberRows(
+	svc service.SubjectService, rows []subjectMemberCSVRow,
+) ([]subjectMemberCSVRow, []subjectMemberCSVRowError, error) {
+	rowErrors := []subjectMemberCSVRowError{}
+	if len(rows) == 0 {
+		return rows, rowErrors, nil
+	}
+
+	members := make([]types.Subject, 0, len(rows))
+	for _, r := range rows {
+		members = append(members, r.Member)
+	}
+
+	existMembers, err := svc.ListExistSubjects(members)
+	if err != nil {
+		return nil, nil, err
+	}
+	existSet := util.NewStringSet()
+	for _, m := range existMembers {
+		existSet.Add(m.Type + ":" + m.ID)
+	}
+
+	existRows := make([]subjectMemberCSVRow, 0, len(rows))
+	for _, r := range rows {
+		if !existSet.Has(r.Member.Type + ":" + r.Member.ID) {
+			rowErrors = append(rowErrors, subjectMemberCSVRowError{
+				Row:   r.Row,
+				Error: fmt.Sprintf("%s `%s` not exists", r.Member.Type, r.Member.ID),
+			})
+			continue
+		}
+		existRows = append(existRows, r)
+	}
+	return existRows, rowErrors, nil
+}
+
+func sortSubjectMemberCSVRowErrors(rowErrors []subjectMemberCSVRowError) []subjectMemberCSVRowError {
+	sort.Slice(rowErrors, func(i, j int) bool {
+		return rowErrors[i].Row < rowErrors[j].Row
+	})
+	return rowErrors
+}
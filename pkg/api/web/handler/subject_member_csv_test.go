@@ -0,0 +1,238 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestParseSubjectMembersCSV(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		_, _, err := parseSubjectMembersCSV(strings.NewReader(""))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid header", func(t *testing.T) {
+		_, _, err := parseSubjectMembersCSV(strings.NewReader("id,type\nadmin,user\n"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "type,id,policy_expired_at")
+	})
+
+	t.Run("ok with bom", func(t *testing.T) {
+		rows, rowErrors, err := parseSubjectMembersCSV(
+			strings.NewReader("\ufefftype,id\nuser,admin\ndepartment,1\n"))
+		assert.NoError(t, err)
+		assert.Len(t, rowErrors, 0)
+		assert.Equal(t, []subjectMemberCSVRow{
+			{Row: 2, Member: types.Subject{Type: "user", ID: "admin"}},
+			{Row: 3, Member: types.Subject{Type: "department", ID: "1"}},
+		}, rows)
+	})
+
+	t.Run("invalid rows", func(t *testing.T) {
+		rows, rowErrors, err := parseSubjectMembersCSV(strings.NewReader(
+			"type,id,policy_expired_at\nuser,admin,0\nrole,1,0\nuser\nuser,admin,0\nuser,test,0\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, []subjectMemberCSVRow{
+			{Row: 2, Member: types.Subject{Type: "user", ID: "admin"}},
+			{Row: 6, Member: types.Subject{Type: "user", ID: "test"}},
+		}, rows)
+		if assert.Len(t, rowErrors, 3) {
+			assert.Equal(t, 3, rowErrors[0].Row)
+			assert.Equal(t, 4, rowErrors[1].Row)
+			assert.Equal(t, subjectMemberCSVRowError{Row: 5, Error: "duplicated with row 2"}, rowErrors[2])
+		}
+	})
+
+	t.Run("too many rows", func(t *testing.T) {
+		oldMaxRows := subjectMemberCSVMaxRows
+		subjectMemberCSVMaxRows = 1
+		defer func() {
+			subjectMemberCSVMaxRows = oldMaxRows
+		}()
+
+		_, _, err := parseSubjectMembersCSV(strings.NewReader("type,id\nuser,admin\nuser,test\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestExportSubjectMembersCSV(t *testing.T) {
+	doRequest := func(url string) *httptest.ResponseRecorder {
+		r := util.SetupRouter()
+		r.GET("/api/v1/web/subject-members/export", ExportSubjectMembersCSV)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("bad request", func(t *testing.T) {
+		w := doRequest("/api/v1/web/subject-members/export?type=user&id=admin")
+		assert.Contains(t, w.Body.String(), "bad request")
+	})
+
+	var ctl *gomock.Controller
+	var patches *gomonkey.Patches
+
+	restMock := func() {
+		ctl.Finish()
+		if patches != nil {
+			patches.Reset()
+		}
+	}
+
+	t.Run("list_member error", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockService := mock.NewMockSubjectService(ctl)
+		mockService.EXPECT().ListMember("group", "1").Return(nil, errors.New("error")).AnyTimes()
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockService
+		})
+		defer restMock()
+
+		w := doRequest("/api/v1/web/subject-members/export?type=group&id=1")
+		assert.Contains(t, w.Body.String(), "system error")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockService := mock.NewMockSubjectService(ctl)
+		mockService.EXPECT().ListMember("group", "1").Return([]types.SubjectMember{
+			{PK: 1, Type: "user", ID: "admin", PolicyExpiredAt: 10},
+			{PK: 2, Type: "department", ID: "2", PolicyExpiredAt: 20},
+		}, nil).AnyTimes()
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockService
+		})
+		defer restMock()
+
+		w := doRequest("/api/v1/web/subject-members/export?type=group&id=1")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+		assert.Equal(t, "type,id,policy_expired_at\nuser,admin,10\ndepartment,2,20\n", w.Body.String())
+	})
+}
+
+func TestImportSubjectMembersCSV(t *testing.T) {
+	doRequest := func(url, body string) *httptest.ResponseRecorder {
+		r := util.SetupRouter()
+		r.POST("/api/v1/web/subject-members/import", ImportSubjectMembersCSV)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("bad request policy_expired_at", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+		mockService := mock.NewMockSubjectGroupReadService(ctl)
+		mockService.EXPECT().GetGroupSetting("group", "1").Return(types.GroupSetting{}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectGroupReadService, func() service.SubjectGroupReadService {
+			return mockService
+		})
+		defer patches.Reset()
+
+		w := doRequest("/api/v1/web/subject-members/import?type=group&id=1", "type,id\nuser,admin\n")
+		assert.Contains(t, w.Body.String(), "policy expires time required")
+	})
+
+	t.Run("bad request invalid header", func(t *testing.T) {
+		w := doRequest("/api/v1/web/subject-members/import?type=group&id=1&policy_expired_at=10", "a,b\n")
+		assert.Contains(t, w.Body.String(), "the csv header should be")
+	})
+
+	var ctl *gomock.Controller
+	var patches *gomonkey.Patches
+
+	restMock := func() {
+		ctl.Finish()
+		if patches != nil {
+			patches.Reset()
+		}
+	}
+
+	t.Run("list_exist_subjects error", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockService := mock.NewMockSubjectService(ctl)
+		mockService.EXPECT().ListExistSubjects(gomock.Any()).Return(nil, errors.New("error")).AnyTimes()
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockService
+		})
+		defer restMock()
+
+		w := doRequest("/api/v1/web/subject-members/import?type=group&id=1&policy_expired_at=10",
+			"type,id\nuser,admin\n")
+		assert.Contains(t, w.Body.String(), "system error")
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockService := mock.NewMockSubjectService(ctl)
+		mockService.EXPECT().ListExistSubjects([]types.Subject{
+			{Type: "user", ID: "admin"},
+			{Type: "user", ID: "notexist"},
+		}).Return([]types.Subject{{Type: "user", ID: "admin"}}, nil).Times(1)
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockService
+		})
+		defer restMock()
+
+		w := doRequest("/api/v1/web/subject-members/import?type=group&id=1&policy_expired_at=10&dry_run=true",
+			"type,id\nuser,admin\nunknown,1\nuser,notexist\n")
+		body := w.Body.String()
+		assert.Contains(t, body, `"dry_run":true`)
+		assert.Contains(t, body, `"total":3`)
+		assert.Contains(t, body, `"valid":1`)
+		assert.Contains(t, body, `"row":3`)
+		assert.Contains(t, body, "user `notexist` not exists")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockService := mock.NewMockSubjectService(ctl)
+		mockService.EXPECT().ListExistSubjects(gomock.Any()).Return([]types.Subject{
+			{Type: "user", ID: "admin"},
+		}, nil).Times(1)
+		mockService.EXPECT().ListMember("group", "1").Return([]types.SubjectMember{
+			{PK: 1, Type: "user", ID: "admin", PolicyExpiredAt: 9},
+		}, nil).Times(1)
+		mockService.EXPECT().UpdateMembersExpiredAt([]types.SubjectMember{
+			{PK: 1, Type: "user", ID: "admin", PolicyExpiredAt: 10},
+		}).Return(nil).Times(1)
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockService
+		})
+		defer restMock()
+
+		w := doRequest("/api/v1/web/subject-members/import?type=group&id=1&policy_expired_at=10",
+			"type,id\nuser,admin\n")
+		body := w.Body.String()
+		assert.Contains(t, body, `"dry_run":false`)
+		assert.Contains(t, body, `"updated":1`)
+		assert.Contains(t, body, `"errors":[]`)
+	})
+}
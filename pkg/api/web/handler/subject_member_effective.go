@@ -0,0 +1,43 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/util"
+)
+
+// ListGroupEffectiveUsers 游标分页查询用户组展开后的有效用户, 同一用户有多个来源时取最早的过期时间
+func ListGroupEffectiveUsers(c *gin.Context) {
+	var query groupEffectiveUserSerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	query.Default()
+
+	svc := service.NewSubjectMemberReadService()
+	users, nextID, err := svc.ListGroupEffectiveUsersAfterPK(query.Type, query.ID, query.AfterID, query.Limit)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "ListGroupEffectiveUsers",
+			"type=`%s`, id=`%s`, afterID=`%d`, limit=`%d`", query.Type, query.ID, query.AfterID, query.Limit)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"results":  users,
+		"next_id":  nextID,
+		"has_next": nextID != 0,
+	})
+}
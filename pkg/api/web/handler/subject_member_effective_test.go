@@ -0,0 +1,80 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestListGroupEffectiveUsers(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"get", "/api/v1/web/subject-members/effective-users", ListGroupEffectiveUsers,
+	)
+
+	var ctl *gomock.Controller
+	var patches *gomonkey.Patches
+	restMock := func() {
+		ctl.Finish()
+		if patches != nil {
+			patches.Reset()
+		}
+	}
+
+	t.Run("bad request", func(t *testing.T) {
+		newRequestFunc(t).
+			QueryParams(map[string]string{"type": "department", "id": "1"}).
+			BadRequestContainsMessage("Type")
+	})
+
+	t.Run("bad request limit", func(t *testing.T) {
+		newRequestFunc(t).
+			QueryParams(map[string]string{"type": "group", "id": "1", "limit": "1001"}).
+			BadRequestContainsMessage("Limit")
+	})
+
+	t.Run("svc error", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockSvc := mock.NewMockSubjectMemberReadService(ctl)
+		mockSvc.EXPECT().ListGroupEffectiveUsersAfterPK("group", "1", int64(0), int64(100)).
+			Return(nil, int64(0), errors.New("error"))
+		patches = gomonkey.ApplyFunc(service.NewSubjectMemberReadService, func() service.SubjectMemberReadService {
+			return mockSvc
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			QueryParams(map[string]string{"type": "group", "id": "1"}).
+			SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockSvc := mock.NewMockSubjectMemberReadService(ctl)
+		mockSvc.EXPECT().ListGroupEffectiveUsersAfterPK("group", "1", int64(10), int64(1)).
+			Return([]types.GroupEffectiveUser{{PK: 11, ID: "admin", PolicyExpiredAt: 100}}, int64(11), nil)
+		patches = gomonkey.ApplyFunc(service.NewSubjectMemberReadService, func() service.SubjectMemberReadService {
+			return mockSvc
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			QueryParams(map[string]string{"type": "group", "id": "1", "after_id": "10", "limit": "1"}).
+			OK()
+	})
+}
@@ -0,0 +1,121 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// ListSubjectMemberEvents godoc
+// @Summary subject member events/查询用户组成员的变更记录
+// @Description list the added/removed/renewed records of group members, filtered by group, operator and time range,
+// @Description the latest first
+// @ID api-web-list-subject-member-events
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param params query subjectMemberEventSerializer true "the filters and page"
+// @Success 200 {object} util.Response{data=[]types.SubjectMemberEvent}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-members/events [get]
+func ListSubjectMemberEvents(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "ListSubjectMemberEvents")
+
+	var query subjectMemberEventSerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	if ok, message := query.validate(); !ok {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
+	query.Default()
+	filter := query.filter()
+
+	svc := service.NewSubjectMemberReadService()
+	count, err := svc.GetSubjectMemberEventCount(filter)
+	if err != nil {
+		err = errorWrapf(err, "svc.GetSubjectMemberEventCount filter=`%+v`", filter)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	events, err := svc.ListPagingSubjectMemberEvent(filter, query.Limit, query.Offset)
+	if err != nil {
+		err = errorWrapf(err, "svc.ListPagingSubjectMemberEvent filter=`%+v`, limit=`%d`, offset=`%d`",
+			filter, query.Limit, query.Offset)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"count":   count,
+		"results": events,
+	})
+}
+
+// recordSubjectMemberEvents 记录成员的添加/删除, 重复的成员只记录一次
+func recordSubjectMemberEvents(
+	group types.Subject, members []types.Subject, action string, policyExpiredAt int64, operator, source string,
+) {
+	seen := util.NewStringSet()
+	events := make([]types.SubjectMemberEvent, 0, len(members))
+	for _, m := range members {
+		key := fmt.Sprintf("%s:%s", m.Type, m.ID)
+		if seen.Has(key) {
+			continue
+		}
+		seen.Add(key)
+
+		events = append(events, types.SubjectMemberEvent{
+			GroupType:       group.Type,
+			GroupID:         group.ID,
+			MemberType:      m.Type,
+			MemberID:        m.ID,
+			Action:          action,
+			PolicyExpiredAt: policyExpiredAt,
+			Operator:        operator,
+			Source:          source,
+		})
+	}
+	service.RecordSubjectMemberEvents(events)
+}
+
+// recordSubjectMemberRelationEvents 记录成员的添加/续期, 每个成员的过期时间可能不同
+func recordSubjectMemberRelationEvents(
+	group types.Subject, members []types.SubjectMember, action, operator, source string,
+) {
+	events := make([]types.SubjectMemberEvent, 0, len(members))
+	for _, m := range members {
+		events = append(events, types.SubjectMemberEvent{
+			GroupType:       group.Type,
+			GroupID:         group.ID,
+			MemberType:      m.Type,
+			MemberID:        m.ID,
+			Action:          action,
+			PolicyExpiredAt: m.PolicyExpiredAt,
+			Operator:        operator,
+			Source:          source,
+		})
+	}
+	service.RecordSubjectMemberEvents(events)
+}
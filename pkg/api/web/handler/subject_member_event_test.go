@@ -0,0 +1,112 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestListSubjectMemberEvents(t *testing.T) {
+	url := "/api/v1/web/subject-members/events"
+
+	t.Run("bad request with invalid time range", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectMemberEvents)(t).
+			QueryParams(map[string]string{"start_time": "1629000000", "end_time": "1628000000"}).
+			BadRequestContainsMessage("end_time should be greater than start_time")
+	})
+
+	t.Run("get count fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectMemberReadService(ctl)
+		mockSvc.EXPECT().GetSubjectMemberEventCount(gomock.Any()).Return(int64(0), errors.New("get count fail"))
+		patches := gomonkey.ApplyFunc(service.NewSubjectMemberReadService, func() service.SubjectMemberReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectMemberEvents)(t).
+			QueryParams(map[string]string{"group_id": "1"}).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		filter := svctypes.SubjectMemberEventFilter{
+			GroupType: "group",
+			GroupID:   "1",
+			Operator:  "admin",
+			StartTime: time.Unix(1628000000, 0),
+			EndTime:   time.Unix(1629000000, 0),
+		}
+		mockSvc := mock.NewMockSubjectMemberReadService(ctl)
+		mockSvc.EXPECT().GetSubjectMemberEventCount(filter).Return(int64(1), nil)
+		mockSvc.EXPECT().ListPagingSubjectMemberEvent(filter, int64(20), int64(0)).Return(
+			[]svctypes.SubjectMemberEvent{{
+				GroupType:       "group",
+				GroupID:         "1",
+				MemberType:      "user",
+				MemberID:        "tom",
+				Action:          "added",
+				PolicyExpiredAt: 4102444800,
+				Operator:        "admin",
+				Source:          "bk_iam",
+				CreatedAt:       time.Now(),
+			}}, nil,
+		)
+		patches := gomonkey.ApplyFunc(service.NewSubjectMemberReadService, func() service.SubjectMemberReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectMemberEvents)(t).
+			QueryParams(map[string]string{
+				"group_id":   "1",
+				"operator":   "admin",
+				"start_time": "1628000000",
+				"end_time":   "1629000000",
+			}).OK()
+	})
+}
+
+func TestRecordSubjectMemberEvents(t *testing.T) {
+	var recorded []svctypes.SubjectMemberEvent
+	patches := gomonkey.ApplyFunc(service.RecordSubjectMemberEvents, func(events []svctypes.SubjectMemberEvent) {
+		recorded = events
+	})
+	defer patches.Reset()
+
+	recordSubjectMemberEvents(
+		svctypes.Subject{Type: "group", ID: "1"},
+		[]svctypes.Subject{{Type: "user", ID: "tom"}, {Type: "department", ID: "2"}, {Type: "user", ID: "tom"}},
+		service.SubjectMemberEventActionRemoved, 0, "admin", "bk_iam",
+	)
+
+	assert.Equal(t, []svctypes.SubjectMemberEvent{
+		{GroupType: "group", GroupID: "1", MemberType: "user", MemberID: "tom",
+			Action: "removed", Operator: "admin", Source: "bk_iam"},
+		{GroupType: "group", GroupID: "1", MemberType: "department", MemberID: "2",
+			Action: "removed", Operator: "admin", Source: "bk_iam"},
+	}, recorded)
+}
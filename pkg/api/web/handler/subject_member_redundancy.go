@@ -0,0 +1,84 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// ListRedundantSubjectMembers 分析用户组的冗余成员关系
+func ListRedundantSubjectMembers(c *gin.Context) {
+	var query subjectMemberRedundancySerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	svc := service.NewSubjectService()
+	members, err := svc.ListRedundantMembers(query.Type, query.ID)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "ListRedundantSubjectMembers",
+			"type=`%s`, id=`%s`", query.Type, query.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"count":   len(members),
+		"results": members,
+	})
+}
+
+// DeleteRedundantSubjectMembers 移除被部门完全覆盖的直接成员关系, 通过多个部门继承的不处理
+func DeleteRedundantSubjectMembers(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "DeleteRedundantSubjectMembers")
+
+	var body subjectMemberRedundancySerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	svc := service.NewSubjectService()
+	members, err := svc.ListRedundantMembers(body.Type, body.ID)
+	if err != nil {
+		err = errorWrapf(err, "svc.ListRedundantMembers type=`%s`, id=`%s`", body.Type, body.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	removed := make([]types.Subject, 0, len(members))
+	for _, m := range members {
+		if m.Removable {
+			removed = append(removed, types.Subject{Type: m.Type, ID: m.ID, Name: m.Name})
+		}
+	}
+
+	if len(removed) > 0 {
+		_, err = svc.BulkDeleteSubjectMembers(body.Type, body.ID, removed)
+		if err != nil {
+			err = errorWrapf(err, "svc.BulkDeleteSubjectMembers type=`%s`, id=`%s`, members=`%+v`",
+				body.Type, body.ID, removed)
+			util.SystemErrorJSONResponse(c, err)
+			return
+		}
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"count":   len(removed),
+		"results": removed,
+	})
+}
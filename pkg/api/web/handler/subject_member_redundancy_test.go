@@ -0,0 +1,146 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestListRedundantSubjectMembers(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"get", "/api/v1/web/subject-members/redundancy", ListRedundantSubjectMembers,
+	)
+
+	var ctl *gomock.Controller
+	var patches *gomonkey.Patches
+	restMock := func() {
+		ctl.Finish()
+		if patches != nil {
+			patches.Reset()
+		}
+	}
+
+	t.Run("bad request", func(t *testing.T) {
+		newRequestFunc(t).
+			QueryParams(map[string]string{"type": "user", "id": "admin"}).
+			BadRequestContainsMessage("Type")
+	})
+
+	t.Run("svc error", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().ListRedundantMembers("group", "1").Return(nil, errors.New("error"))
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			QueryParams(map[string]string{"type": "group", "id": "1"}).
+			SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().ListRedundantMembers("group", "1").Return([]types.RedundantMember{
+			{Type: "user", ID: "admin", PolicyExpiredAt: 100, Removable: true},
+		}, nil)
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			QueryParams(map[string]string{"type": "group", "id": "1"}).
+			OK()
+	})
+}
+
+func TestDeleteRedundantSubjectMembers(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"delete", "/api/v1/web/subject-members/redundancy", DeleteRedundantSubjectMembers,
+	)
+
+	var ctl *gomock.Controller
+	var patches *gomonkey.Patches
+	restMock := func() {
+		ctl.Finish()
+		if patches != nil {
+			patches.Reset()
+		}
+	}
+
+	t.Run("no json", func(t *testing.T) {
+		newRequestFunc(t).NoJSON()
+	})
+
+	t.Run("delete fail", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().ListRedundantMembers("group", "1").Return([]types.RedundantMember{
+			{Type: "user", ID: "admin", PolicyExpiredAt: 100, Removable: true},
+		}, nil)
+		mockSvc.EXPECT().BulkDeleteSubjectMembers("group", "1", gomock.Any()).Return(nil, errors.New("error"))
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{"type": "group", "id": "1"}).
+			SystemError()
+	})
+
+	t.Run("ok, only removable", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().ListRedundantMembers("group", "1").Return([]types.RedundantMember{
+			{Type: "user", ID: "admin", Name: "admin", PolicyExpiredAt: 100, Removable: true},
+			{Type: "user", ID: "tom", Name: "tom", PolicyExpiredAt: 300, Removable: false},
+			{Type: "user", ID: "jerry", Name: "jerry"},
+		}, nil)
+		mockSvc.EXPECT().BulkDeleteSubjectMembers("group", "1", []types.Subject{
+			{Type: "user", ID: "admin", Name: "admin"},
+		}).Return(map[string]int64{"user": 1, "department": 0}, nil)
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{"type": "group", "id": "1"}).
+			OK()
+	})
+
+	t.Run("ok, nothing to remove", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().ListRedundantMembers("group", "1").Return([]types.RedundantMember{}, nil)
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{"type": "group", "id": "1"}).
+			OK()
+	})
+}
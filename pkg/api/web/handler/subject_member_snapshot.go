@@ -0,0 +1,189 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// ListSubjectMemberSnapshots godoc
+// @Summary list group member snapshots/查询用户组成员列表的快照
+// @Description list the member snapshots of the group without the members, the latest first
+// @ID api-web-list-subject-member-snapshots
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param params query subjectMemberSnapshotQuerySerializer true "the group"
+// @Success 200 {object} util.Response{data=[]types.SubjectMemberSnapshot}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-members/snapshots [get]
+func ListSubjectMemberSnapshots(c *gin.Context) {
+	var query subjectMemberSnapshotQuerySerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	svc := service.NewSubjectMemberReadService()
+	snapshots, err := svc.ListSubjectMemberSnapshots(query.Type, query.ID)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "ListSubjectMemberSnapshots", "type=`%s`, id=`%s`", query.Type, query.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", snapshots)
+}
+
+// CreateSubjectMemberSnapshot godoc
+// @Summary create group member snapshot/为用户组当前的成员列表创建快照
+// @Description save all the members(with the expired_at) of the group into a snapshot
+// @ID api-web-create-subject-member-snapshot
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param body body createSubjectMemberSnapshotSerializer true "the group"
+// @Success 200 {object} util.Response{data=types.SubjectMemberSnapshot}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-members/snapshots [post]
+func CreateSubjectMemberSnapshot(c *gin.Context) {
+	var body createSubjectMemberSnapshotSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	svc := service.NewSubjectService()
+	snapshot, err := svc.CreateSubjectMemberSnapshot(body.Type, body.ID, body.Operator, util.GetClientID(c))
+	if errors.Is(err, sql.ErrNoRows) {
+		util.BadRequestErrorJSONResponse(c, fmt.Sprintf("group(%s) not exists", body.ID))
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "CreateSubjectMemberSnapshot", "type=`%s`, id=`%s`", body.Type, body.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", snapshot)
+}
+
+// GetSubjectMemberSnapshot godoc
+// @Summary get group member snapshot/查询快照的成员列表
+// @Description get the snapshot with all the members
+// @ID api-web-get-subject-member-snapshot
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param snapshot_id path int true "Snapshot ID"
+// @Success 200 {object} util.Response{data=types.SubjectMemberSnapshot}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-members/snapshots/{snapshot_id} [get]
+func GetSubjectMemberSnapshot(c *gin.Context) {
+	var pathParams subjectMemberSnapshotPathSerializer
+	if err := c.ShouldBindUri(&pathParams); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	svc := service.NewSubjectMemberReadService()
+	snapshot, err := svc.GetSubjectMemberSnapshot(pathParams.SnapshotID)
+	if errors.Is(err, sql.ErrNoRows) {
+		util.NotFoundJSONResponse(c, fmt.Sprintf("subject member snapshot(%d)", pathParams.SnapshotID))
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "GetSubjectMemberSnapshot", "snapshotID=`%d`", pathParams.SnapshotID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", snapshot)
+}
+
+// RestoreSubjectMemberSnapshot godoc
+// @Summary restore group members from snapshot/从快照恢复用户组的成员
+// @Description restore the members of the group to the snapshot atomically, the members not in the snapshot
+// @Description will be removed; a snapshot of the current members is created before restoring
+// @ID api-web-restore-subject-member-snapshot
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param snapshot_id path int true "Snapshot ID"
+// @Param body body restoreSubjectMemberSnapshotSerializer true "the operator"
+// @Success 200 {object} util.Response{data=gin.H}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-members/snapshots/{snapshot_id}/restore [post]
+func RestoreSubjectMemberSnapshot(c *gin.Context) {
+	var pathParams subjectMemberSnapshotPathSerializer
+	if err := c.ShouldBindUri(&pathParams); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	var body restoreSubjectMemberSnapshotSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	clientID := util.GetClientID(c)
+	svc := service.NewSubjectService()
+	result, err := svc.RestoreSubjectMemberSnapshot(pathParams.SnapshotID, body.Operator, clientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		util.NotFoundJSONResponse(c, fmt.Sprintf("subject member snapshot(%d) or its group", pathParams.SnapshotID))
+		return
+	}
+	if errors.Is(err, service.ErrGroupMemberCycle) {
+		util.BadRequestErrorJSONResponse(c, service.ErrGroupMemberCycle.Error())
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "RestoreSubjectMemberSnapshot", "snapshotID=`%d`", pathParams.SnapshotID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	recordSubjectMemberRelationEvents(
+		result.Group, result.Added, service.SubjectMemberEventActionAdded, body.Operator, clientID)
+	recordSubjectMemberRelationEvents(
+		result.Group, result.Updated, service.SubjectMemberEventActionRenewed, body.Operator, clientID)
+	removed := make([]types.Subject, 0, len(result.Removed))
+	for _, m := range result.Removed {
+		removed = append(removed, types.Subject{Type: m.Type, ID: m.ID})
+	}
+	recordSubjectMemberEvents(
+		result.Group, removed, service.SubjectMemberEventActionRemoved, 0, body.Operator, clientID)
+
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"backup_snapshot_id": result.BackupSnapshotPK,
+		"added":              len(result.Added),
+		"updated":            len(result.Updated),
+		"removed":            len(result.Removed),
+		"skipped":            result.Skipped,
+	})
+}
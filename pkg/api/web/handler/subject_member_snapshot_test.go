@@ -0,0 +1,167 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestListSubjectMemberSnapshots(t *testing.T) {
+	url := "/api/v1/web/subject-members/snapshots"
+
+	t.Run("bad request", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectMemberSnapshots)(t).
+			QueryParams(map[string]string{"type": "user", "id": "1"}).
+			BadRequestContainsMessage("Type")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectMemberReadService(ctl)
+		mockSvc.EXPECT().ListSubjectMemberSnapshots("group", "1").Return(
+			[]svctypes.SubjectMemberSnapshot{{PK: 1, GroupType: "group", GroupID: "1", MemberCount: 2}}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectMemberReadService, func() service.SubjectMemberReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectMemberSnapshots)(t).
+			QueryParams(map[string]string{"type": "group", "id": "1"}).OK()
+	})
+}
+
+func TestCreateSubjectMemberSnapshot(t *testing.T) {
+	url := "/api/v1/web/subject-members/snapshots"
+
+	t.Run("group not exists", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().CreateSubjectMemberSnapshot("group", "1", "admin", gomock.Any()).Return(
+			svctypes.SubjectMemberSnapshot{},
+			errorx.Wrapf(sql.ErrNoRows, "SubjectSVC", "CreateSubjectMemberSnapshot", ""))
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, CreateSubjectMemberSnapshot)(t).
+			JSON(map[string]interface{}{"type": "group", "id": "1", "operator": "admin"}).
+			BadRequestContainsMessage("group(1) not exists")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().CreateSubjectMemberSnapshot("group", "1", "", gomock.Any()).Return(
+			svctypes.SubjectMemberSnapshot{PK: 1, GroupType: "group", GroupID: "1"}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", url, CreateSubjectMemberSnapshot)(t).
+			JSON(map[string]interface{}{"type": "group", "id": "1"}).OK()
+	})
+}
+
+func TestGetSubjectMemberSnapshot(t *testing.T) {
+	url := "/api/v1/web/subject-members/snapshots/:snapshot_id"
+
+	t.Run("bad request", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", "/api/v1/web/subject-members/snapshots/abc",
+			GetSubjectMemberSnapshot, url)(t).BadRequestContainsMessage("invalid syntax")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectMemberReadService(ctl)
+		mockSvc.EXPECT().GetSubjectMemberSnapshot(int64(1)).Return(svctypes.SubjectMemberSnapshot{}, sql.ErrNoRows)
+		patches := gomonkey.ApplyFunc(service.NewSubjectMemberReadService, func() service.SubjectMemberReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", "/api/v1/web/subject-members/snapshots/1",
+			GetSubjectMemberSnapshot, url)(t).NotFoundContainsMessage("subject member snapshot(1)")
+	})
+}
+
+func TestRestoreSubjectMemberSnapshot(t *testing.T) {
+	url := "/api/v1/web/subject-members/snapshots/:snapshot_id/restore"
+	requestURL := "/api/v1/web/subject-members/snapshots/1/restore"
+
+	t.Run("restore fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().RestoreSubjectMemberSnapshot(int64(1), "admin", gomock.Any()).Return(
+			svctypes.SubjectMemberRestoreResult{}, errors.New("restore fail"))
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("post", requestURL, RestoreSubjectMemberSnapshot, url)(t).
+			JSON(map[string]interface{}{"operator": "admin"}).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().RestoreSubjectMemberSnapshot(int64(1), "admin", gomock.Any()).Return(
+			svctypes.SubjectMemberRestoreResult{
+				Group:            svctypes.Subject{Type: "group", ID: "1"},
+				BackupSnapshotPK: 2,
+				Added:            []svctypes.SubjectMember{{Type: "user", ID: "tom", PolicyExpiredAt: 1000}},
+				Removed:          []svctypes.SubjectMember{{Type: "user", ID: "bob", PolicyExpiredAt: 1000}},
+			}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		var recorded []svctypes.SubjectMemberEvent
+		patches.ApplyFunc(service.RecordSubjectMemberEvents, func(events []svctypes.SubjectMemberEvent) {
+			recorded = append(recorded, events...)
+		})
+
+		util.CreateNewAPIRequestFunc("post", requestURL, RestoreSubjectMemberSnapshot, url)(t).
+			JSON(map[string]interface{}{"operator": "admin"}).OK()
+
+		assert.Len(t, recorded, 2)
+		assert.Equal(t, "added", recorded[0].Action)
+		assert.Equal(t, "removed", recorded[1].Action)
+		assert.Equal(t, "1", recorded[1].GroupID)
+	})
+}
@@ -0,0 +1,101 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/util"
+)
+
+// ListSubjectRoleSystems godoc
+// @Summary subject role systems/查询subject的所有角色及其管理的系统
+// @Description list the role types and systems of a subject
+// @ID api-web-list-subject-role-systems
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param params query subjectRoleSystemSerializer true "the subject"
+// @Success 200 {object} util.Response{data=[]types.SubjectRole}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-roles/systems [get]
+func ListSubjectRoleSystems(c *gin.Context) {
+	var query subjectRoleSystemSerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	svc := service.NewSubjectRoleReadService()
+	roles, err := svc.ListSubjectRoles(query.Type, query.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		util.BadRequestErrorJSONResponse(c, fmt.Sprintf("%s(%s) not exists", query.Type, query.ID))
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "ListSubjectRoleSystems", "type=`%s`, id=`%s`", query.Type, query.ID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", roles)
+}
+
+// ListSubjectRoleHolders godoc
+// @Summary subject role holders/分页查询所有系统的角色及其授予的subject
+// @Description list the roles of all systems with the granted subjects, order by role type and system
+// @ID api-web-list-subject-role-holders
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param params query pageSerializer true "the page"
+// @Success 200 {object} util.Response{data=[]types.SubjectRoleHolder}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-roles/holders [get]
+func ListSubjectRoleHolders(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "ListSubjectRoleHolders")
+
+	var query pageSerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	query.Default()
+
+	svc := service.NewSubjectRoleReadService()
+	count, err := svc.GetSubjectRoleCount()
+	if err != nil {
+		err = errorWrapf(err, "svc.GetSubjectRoleCount")
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	holders, err := svc.ListPagingSubjectRoles(query.Limit, query.Offset)
+	if err != nil {
+		err = errorWrapf(err, "svc.ListPagingSubjectRoles limit=`%d`, offset=`%d`", query.Limit, query.Offset)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"count":   count,
+		"results": holders,
+	})
+}
@@ -0,0 +1,69 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/util"
+)
+
+// ListSubjectRoleHistory godoc
+// @Summary subject role history/查询角色的授予/回收记录
+// @Description list the granted/revoked records of a role, the latest first
+// @ID api-web-list-subject-role-history
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param params query subjectRoleHistorySerializer true "the role and page"
+// @Success 200 {object} util.Response{data=[]types.SubjectRoleHistory}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/subject-roles/history [get]
+func ListSubjectRoleHistory(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "ListSubjectRoleHistory")
+
+	var query subjectRoleHistorySerializer
+	if err := c.ShouldBindQuery(&query); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	if ok, message := query.validate(); !ok {
+		util.BadRequestErrorJSONResponse(c, message)
+		return
+	}
+
+	query.Default()
+
+	svc := service.NewSubjectRoleReadService()
+	count, err := svc.GetSubjectRoleHistoryCount(query.RoleType, query.SystemID)
+	if err != nil {
+		err = errorWrapf(err, "svc.GetSubjectRoleHistoryCount roleType=`%s`, system=`%s`",
+			query.RoleType, query.SystemID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	histories, err := svc.ListPagingSubjectRoleHistory(query.RoleType, query.SystemID, query.Limit, query.Offset)
+	if err != nil {
+		err = errorWrapf(err, "svc.ListPagingSubjectRoleHistory roleType=`%s`, system=`%s`, limit=`%d`, offset=`%d`",
+			query.RoleType, query.SystemID, query.Limit, query.Offset)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{
+		"count":   count,
+		"results": histories,
+	})
+}
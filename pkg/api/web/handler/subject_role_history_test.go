@@ -0,0 +1,82 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestListSubjectRoleHistory(t *testing.T) {
+	url := "/api/v1/web/subject-roles/history"
+
+	t.Run("bad request with invalid role type", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectRoleHistory)(t).
+			QueryParams(map[string]string{"role_type": "admin", "system_id": "bk_cmdb"}).
+			BadRequestContainsMessage("RoleType")
+	})
+
+	t.Run("bad request with super_manager not SUPER", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectRoleHistory)(t).
+			QueryParams(map[string]string{"role_type": "super_manager", "system_id": "bk_cmdb"}).
+			BadRequestContainsMessage("system_id must be SUPER")
+	})
+
+	t.Run("get count fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectRoleReadService(ctl)
+		mockSvc.EXPECT().GetSubjectRoleHistoryCount("system_manager", "bk_cmdb").Return(
+			int64(0), errors.New("get count fail"))
+		patches := gomonkey.ApplyFunc(service.NewSubjectRoleReadService, func() service.SubjectRoleReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectRoleHistory)(t).
+			QueryParams(map[string]string{"role_type": "system_manager", "system_id": "bk_cmdb"}).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectRoleReadService(ctl)
+		mockSvc.EXPECT().GetSubjectRoleHistoryCount("system_manager", "bk_cmdb").Return(int64(1), nil)
+		mockSvc.EXPECT().ListPagingSubjectRoleHistory("system_manager", "bk_cmdb", int64(20), int64(0)).Return(
+			[]svctypes.SubjectRoleHistory{{
+				SubjectType: "user",
+				SubjectID:   "tom",
+				SubjectName: "tom",
+				Action:      "granted",
+				Operator:    "admin",
+				Source:      "bk_iam",
+				CreatedAt:   time.Now(),
+			}}, nil,
+		)
+		patches := gomonkey.ApplyFunc(service.NewSubjectRoleReadService, func() service.SubjectRoleReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectRoleHistory)(t).
+			QueryParams(map[string]string{"role_type": "system_manager", "system_id": "bk_cmdb"}).OK()
+	})
+}
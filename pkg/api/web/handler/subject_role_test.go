@@ -0,0 +1,127 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestListSubjectRoleSystems(t *testing.T) {
+	url := "/api/v1/web/subject-roles/systems"
+
+	t.Run("bad request with invalid type", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectRoleSystems)(t).
+			QueryParams(map[string]string{"type": "group", "id": "1"}).
+			BadRequestContainsMessage("Type")
+	})
+
+	t.Run("subject not exists", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectRoleReadService(ctl)
+		mockSvc.EXPECT().ListSubjectRoles("user", "tom").Return(nil, sql.ErrNoRows)
+		patches := gomonkey.ApplyFunc(service.NewSubjectRoleReadService, func() service.SubjectRoleReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectRoleSystems)(t).
+			QueryParams(map[string]string{"type": "user", "id": "tom"}).
+			BadRequest("bad request:user(tom) not exists")
+	})
+
+	t.Run("svc fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectRoleReadService(ctl)
+		mockSvc.EXPECT().ListSubjectRoles("user", "tom").Return(nil, errors.New("error"))
+		patches := gomonkey.ApplyFunc(service.NewSubjectRoleReadService, func() service.SubjectRoleReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectRoleSystems)(t).
+			QueryParams(map[string]string{"type": "user", "id": "tom"}).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectRoleReadService(ctl)
+		mockSvc.EXPECT().ListSubjectRoles("user", "tom").Return([]svctypes.SubjectRole{
+			{RoleType: "system_manager", System: "bk_cmdb"},
+		}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectRoleReadService, func() service.SubjectRoleReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectRoleSystems)(t).
+			QueryParams(map[string]string{"type": "user", "id": "tom"}).OK()
+	})
+}
+
+func TestListSubjectRoleHolders(t *testing.T) {
+	url := "/api/v1/web/subject-roles/holders"
+
+	t.Run("bad request with invalid offset", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectRoleHolders)(t).
+			QueryParams(map[string]string{"offset": "-1"}).
+			BadRequestContainsMessage("Offset")
+	})
+
+	t.Run("get count fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectRoleReadService(ctl)
+		mockSvc.EXPECT().GetSubjectRoleCount().Return(int64(0), errors.New("get count fail"))
+		patches := gomonkey.ApplyFunc(service.NewSubjectRoleReadService, func() service.SubjectRoleReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectRoleHolders)(t).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectRoleReadService(ctl)
+		mockSvc.EXPECT().GetSubjectRoleCount().Return(int64(1), nil)
+		mockSvc.EXPECT().ListPagingSubjectRoles(int64(20), int64(0)).Return([]svctypes.SubjectRoleHolder{{
+			RoleType:    "system_manager",
+			System:      "bk_cmdb",
+			SubjectType: "user",
+			SubjectID:   "tom",
+			SubjectName: "tom",
+		}}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectRoleReadService, func() service.SubjectRoleReadService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectRoleHolders)(t).OK()
+	})
+}
@@ -11,6 +11,10 @@
 package handler
 
 import (
+	"fmt"
+	"regexp"
+	"time"
+
 	"iam/pkg/api/common"
 	"iam/pkg/service/types"
 )
@@ -30,35 +34,78 @@ func (s *pageSerializer) Default() {
 }
 
 type listSubjectSerializer struct {
-	Type string `form:"type" binding:"required,oneof=user group department"`
+	Type string `form:"type" binding:"required,oneof=user group department service_account"`
 	pageSerializer
 }
 
+type searchSubjectSerializer struct {
+	Keyword string `form:"keyword" binding:"required,max=64"`
+	Type    string `form:"type" binding:"omitempty,oneof=user group department service_account"`
+	Limit   int64  `form:"limit" binding:"omitempty,min=0,max=100"`
+	Offset  int64  `form:"offset" binding:"omitempty,min=0"`
+}
+
+// Default ...
+func (s *searchSubjectSerializer) Default() {
+	if s.Limit == 0 {
+		s.Limit = 20
+	}
+}
+
 type createSubjectSerializer struct {
-	Type string `json:"type" binding:"required,oneof=user group department"`
+	Type string `json:"type" binding:"required,oneof=user group department service_account"`
 	ID   string `json:"id" binding:"required"`
 	Name string `json:"name" binding:"required"`
 }
 
+// 服务账号ID: 小写字母开头, 只能包含小写字母/数字/下划线/中划线, 长度3-64
+var serviceAccountIDRegex = regexp.MustCompile(`^[a-z][a-z0-9_-]{2,63}$`)
+
+func (slz *createSubjectSerializer) validate() (bool, string) {
+	if slz.Type == types.ServiceAccountType && !serviceAccountIDRegex.MatchString(slz.ID) {
+		return false, fmt.Sprintf("service_account id `%s` is invalid, should match `%s`",
+			slz.ID, serviceAccountIDRegex.String())
+	}
+	return true, ""
+}
+
 type deleteSubjectSerializer struct {
-	Type string `json:"type" binding:"required,oneof=user group department"`
+	Type string `json:"type" binding:"required,oneof=user group department service_account"`
 	ID   string `json:"id" binding:"required"`
 }
 
+type deleteSubjectCacheSerializer struct {
+	// 防御, 避免一次性清理太多subject的缓存
+	Subjects []deleteSubjectSerializer `json:"subjects" binding:"required,gt=0,lte=1000"`
+}
+
+func (slz *deleteSubjectCacheSerializer) validate() (bool, string) {
+	return common.ValidateArray(slz.Subjects)
+}
+
 type listSubjectMemberSerializer struct {
 	Type string `form:"type" binding:"required,oneof=group"`
 	ID   string `form:"id" binding:"required"`
+	// 可选, 只查询指定类型的成员
+	MemberType string `form:"member_type" binding:"omitempty,oneof=user department group service_account"`
 	pageSerializer
 }
 
 type subjectRelationSerializer struct {
-	Type            string `form:"type" binding:"required,oneof=user department"`
+	Type            string `form:"type" binding:"required,oneof=user department group service_account"`
 	ID              string `form:"id" binding:"required"`
 	BeforeExpiredAt int64  `form:"before_expired_at" binding:"omitempty,min=0"`
+	// 不传limit时返回全部用户组, 兼容原有的调用方
+	pageSerializer
+}
+
+func (s *subjectRelationSerializer) isPaging() bool {
+	return s.Limit > 0
 }
 
+// memberSerializer 用户组的成员, 用户组可以加入用户组(group-in-group)
 type memberSerializer struct {
-	Type string `json:"type" binding:"required,oneof=user department"`
+	Type string `json:"type" binding:"required,oneof=user department group service_account"`
 	ID   string `json:"id" binding:"required"`
 }
 
@@ -67,6 +114,8 @@ type deleteSubjectMemberSerializer struct {
 	ID   string `json:"id" binding:"required"`
 	// 防御，避免出现一次性删除太多成员，影响性能
 	Members []memberSerializer `json:"members" binding:"required,gt=0,lte=1000"`
+	// 操作人, 记录在成员的变更记录中
+	Operator string `json:"operator" binding:"omitempty,max=64"`
 }
 
 type addSubjectMembersSerializer struct {
@@ -75,6 +124,10 @@ type addSubjectMembersSerializer struct {
 	PolicyExpiredAt int64  `json:"policy_expired_at" binding:"omitempty,min=1,max=4102444800"`
 	// 防御，避免出现一次性添加太多成员，影响性能
 	Members []memberSerializer `json:"members" binding:"required,gt=0,lte=1000"`
+	// 操作人, 记录在成员的变更记录中
+	Operator string `json:"operator" binding:"omitempty,max=64"`
+	// 成员过期天数配置所属的系统, 为空时使用默认配置
+	System string `json:"system" binding:"omitempty,max=32"`
 }
 
 func (s *addSubjectMembersSerializer) validate() (bool, string) {
@@ -92,13 +145,88 @@ func (s *addSubjectMembersSerializer) validate() (bool, string) {
 	return true, "valid"
 }
 
+// fillMemberPolicyExpiredAt 未指定过期时间时使用配置的默认过期天数, 并校验不能超过配置的最大过期天数
+func fillMemberPolicyExpiredAt(systemID string, policyExpiredAt int64) (int64, error) {
+	now := time.Now()
+
+	if policyExpiredAt == 0 {
+		if days := common.GetMemberDefaultExpirationDays(systemID); days > 0 {
+			policyExpiredAt = now.AddDate(0, 0, days).Unix()
+		}
+	}
+
+	if days := common.GetMemberMaxExpirationDays(systemID); days > 0 {
+		maxExpiredAt := now.AddDate(0, 0, days).Unix()
+		if policyExpiredAt > maxExpiredAt {
+			return policyExpiredAt, fmt.Errorf(
+				"policy_expired_at should not be later than %d, the maximum expiration is %d days", maxExpiredAt, days)
+		}
+	}
+
+	return policyExpiredAt, nil
+}
+
+func (s *addSubjectMembersSerializer) fillPolicyExpiredAt() error {
+	policyExpiredAt, err := fillMemberPolicyExpiredAt(s.System, s.PolicyExpiredAt)
+	if err != nil {
+		return err
+	}
+	s.PolicyExpiredAt = policyExpiredAt
+	return nil
+}
+
+type exportSubjectMembersSerializer struct {
+	Type string `form:"type" binding:"required,oneof=group"`
+	ID   string `form:"id" binding:"required"`
+}
+
+type importSubjectMembersSerializer struct {
+	Type            string `form:"type" binding:"required,oneof=group"`
+	ID              string `form:"id" binding:"required"`
+	PolicyExpiredAt int64  `form:"policy_expired_at" binding:"omitempty,min=1,max=4102444800"`
+	// 只校验不导入
+	DryRun bool `form:"dry_run"`
+	// 操作人, 记录在成员的变更记录中
+	Operator string `form:"operator" binding:"omitempty,max=64"`
+	// 成员过期天数配置所属的系统, 为空时使用默认配置
+	System string `form:"system" binding:"omitempty,max=32"`
+}
+
+func (s *importSubjectMembersSerializer) validate() (bool, string) {
+	// type为group时必须有过期时间
+	if s.Type == types.GroupType && s.PolicyExpiredAt < 1 {
+		return false, "policy expires time required when add group member"
+	}
+	return true, "valid"
+}
+
+func (s *importSubjectMembersSerializer) fillPolicyExpiredAt() error {
+	policyExpiredAt, err := fillMemberPolicyExpiredAt(s.System, s.PolicyExpiredAt)
+	if err != nil {
+		return err
+	}
+	s.PolicyExpiredAt = policyExpiredAt
+	return nil
+}
+
 type subjectDepartment struct {
 	SubjectID     string   `json:"id" binding:"required"`
 	DepartmentIDs []string `json:"departments" binding:"required"`
 }
 
+type departmentParentSerializer struct {
+	ID       string `json:"id" binding:"required"`
+	ParentID string `json:"parent_id" binding:"required"`
+}
+
+// createSubjectDepartmentSerializer 创建时可以同时提交部门的上级关系
+type createSubjectDepartmentSerializer struct {
+	subjectDepartment
+	DepartmentParents []departmentParentSerializer `json:"department_parents" binding:"omitempty,dive"`
+}
+
 type updateSubjectSerializer struct {
-	Type string `json:"type" binding:"required,oneof=user group department"`
+	Type string `json:"type" binding:"required,oneof=user group department service_account"`
 	ID   string `json:"id" binding:"required"`
 	Name string `json:"name" binding:"required"`
 }
@@ -123,6 +251,8 @@ func (s *subjectRoleQuerySerializer) validate() (bool, string) {
 type subjectRoleSerializer struct {
 	subjectRoleQuerySerializer
 	Subjects []userSerializer `json:"subjects" binding:"required,gt=0"`
+	// 操作人, 记录在角色的变更记录中
+	Operator string `json:"operator" binding:"omitempty,max=64"`
 }
 
 func (s *subjectRoleSerializer) validate() (bool, string) {
@@ -137,6 +267,39 @@ func (s *subjectRoleSerializer) validate() (bool, string) {
 	return true, "valid"
 }
 
+type createSubjectRoleSerializer struct {
+	RoleType string `json:"role_type" binding:"required_without=Roles,omitempty,oneof=super_manager system_manager"`
+	SystemID string `json:"system_id" binding:"required_without=Roles"`
+	// 批量授予多个系统的角色, 可以与role_type/system_id同时指定
+	Roles    []subjectRoleQuerySerializer `json:"roles" binding:"omitempty,lte=100,dive"`
+	Subjects []userSerializer             `json:"subjects" binding:"required,gt=0"`
+	// 操作人, 记录在角色的变更记录中
+	Operator string `json:"operator" binding:"omitempty,max=64"`
+}
+
+// roles 合并role_type/system_id与roles中的所有角色
+func (s *createSubjectRoleSerializer) roles() []subjectRoleQuerySerializer {
+	roles := make([]subjectRoleQuerySerializer, 0, len(s.Roles)+1)
+	if s.RoleType != "" {
+		roles = append(roles, subjectRoleQuerySerializer{RoleType: s.RoleType, SystemID: s.SystemID})
+	}
+	return append(roles, s.Roles...)
+}
+
+func (s *createSubjectRoleSerializer) validate() (bool, string) {
+	for _, role := range s.roles() {
+		if valid, message := role.validate(); !valid {
+			return valid, message
+		}
+	}
+
+	if valid, message := common.ValidateArray(s.Subjects); !valid {
+		return valid, message
+	}
+
+	return true, "valid"
+}
+
 type memberExpiredAtSerializer struct {
 	memberSerializer
 	PolicyExpiredAt int64 `json:"policy_expired_at" binding:"omitempty,min=1,max=4102444800"`
@@ -146,6 +309,10 @@ type subjectMemberExpiredAtSerializer struct {
 	Type    string                      `json:"type" binding:"required,oneof=group"`
 	ID      string                      `json:"id" binding:"required"`
 	Members []memberExpiredAtSerializer `json:"members" binding:"required,gt=0,lte=1000"`
+	// 操作人, 记录在成员的变更记录中
+	Operator string `json:"operator" binding:"omitempty,max=64"`
+	// 成员过期天数配置所属的系统, 为空时使用默认配置
+	System string `json:"system" binding:"omitempty,max=32"`
 }
 
 func (slz *subjectMemberExpiredAtSerializer) validate() (bool, string) {
@@ -158,11 +325,42 @@ func (slz *subjectMemberExpiredAtSerializer) validate() (bool, string) {
 	return true, ""
 }
 
+func (slz *subjectMemberExpiredAtSerializer) fillPolicyExpiredAt() error {
+	for i := range slz.Members {
+		policyExpiredAt, err := fillMemberPolicyExpiredAt(slz.System, slz.Members[i].PolicyExpiredAt)
+		if err != nil {
+			return fmt.Errorf("members[%d] %w", i, err)
+		}
+		slz.Members[i].PolicyExpiredAt = policyExpiredAt
+	}
+	return nil
+}
+
 type listSubjectMemberBeforeExpiredAtSerializer struct {
 	listSubjectMemberSerializer
 	BeforeExpiredAt int64 `form:"before_expired_at" binding:"required,min=1,max=4102444800"`
 }
 
+type subjectMemberRedundancySerializer struct {
+	Type string `form:"type" json:"type" binding:"required,oneof=group"`
+	ID   string `form:"id" json:"id" binding:"required"`
+}
+
+type groupEffectiveUserSerializer struct {
+	Type string `form:"type" binding:"required,oneof=group"`
+	ID   string `form:"id" binding:"required"`
+	// 游标分页, 返回pk大于after_id的用户, 首页为0
+	AfterID int64 `form:"after_id" binding:"omitempty,min=0"`
+	Limit   int64 `form:"limit" binding:"omitempty,min=0,max=1000"`
+}
+
+// Default ...
+func (s *groupEffectiveUserSerializer) Default() {
+	if s.Limit == 0 {
+		s.Limit = 100
+	}
+}
+
 type subjectSerializer struct {
 	Type string `json:"type" binding:"required,oneof=group"`
 	ID   string `json:"id" binding:"required"`
@@ -182,3 +380,126 @@ func (slz *filterSubjectsBeforeExpiredAtSerializer) validate() (bool, string) {
 
 	return true, ""
 }
+
+type subjectGroupSummarySerializer struct {
+	Type string `form:"type" binding:"required,oneof=user department service_account"`
+	ID   string `form:"id" binding:"required"`
+}
+
+type systemGroupCount struct {
+	System     string `json:"system"`
+	GroupCount int    `json:"group_count"`
+}
+
+type subjectGroupSummaryResponse struct {
+	DirectGroupCount     int                `json:"direct_group_count"`
+	DepartmentGroupCount int                `json:"department_group_count"`
+	SystemCount          int                `json:"system_count"`
+	Systems              []systemGroupCount `json:"systems"`
+}
+
+type subjectDepartmentHistorySerializer struct {
+	Type string `form:"type" binding:"required,oneof=user"`
+	ID   string `form:"id" binding:"required"`
+	pageSerializer
+}
+
+type subjectRoleHistorySerializer struct {
+	subjectRoleQuerySerializer
+	pageSerializer
+}
+
+type subjectRoleSystemSerializer struct {
+	Type string `form:"type" binding:"required,oneof=user"`
+	ID   string `form:"id" binding:"required"`
+}
+
+type copySubjectMembersSerializer struct {
+	Type     string `json:"type" binding:"required,oneof=group"`
+	SourceID string `json:"source_id" binding:"required"`
+	TargetID string `json:"target_id" binding:"required"`
+	// 移动: 复制后移除源用户组的所有成员
+	Move bool `json:"move"`
+	// 操作人, 记录在成员的变更记录中
+	Operator string `json:"operator" binding:"omitempty,max=64"`
+}
+
+func (s *copySubjectMembersSerializer) validate() (bool, string) {
+	if s.SourceID == s.TargetID {
+		return false, "source_id and target_id should be different"
+	}
+	return true, "valid"
+}
+
+type subjectMemberSnapshotQuerySerializer struct {
+	Type string `form:"type" json:"type" binding:"required,oneof=group"`
+	ID   string `form:"id" json:"id" binding:"required"`
+}
+
+type createSubjectMemberSnapshotSerializer struct {
+	subjectMemberSnapshotQuerySerializer
+	// 操作人, 记录在快照中
+	Operator string `json:"operator" binding:"omitempty,max=64"`
+}
+
+type subjectMemberSnapshotPathSerializer struct {
+	SnapshotID int64 `uri:"snapshot_id" binding:"required,min=1"`
+}
+
+type restoreSubjectMemberSnapshotSerializer struct {
+	// 操作人, 记录在恢复前的快照及成员的变更记录中
+	Operator string `json:"operator" binding:"omitempty,max=64"`
+}
+
+type subjectMemberEventSerializer struct {
+	// 不传时查询所有用户组的变更记录
+	GroupID  string `form:"group_id" binding:"omitempty,max=64"`
+	Operator string `form:"operator" binding:"omitempty,max=64"`
+	// 变更时间范围[start_time, end_time), unix时间戳, 不传时不限制
+	StartTime int64 `form:"start_time" binding:"omitempty,min=1"`
+	EndTime   int64 `form:"end_time" binding:"omitempty,min=1"`
+	pageSerializer
+}
+
+func (s *subjectMemberEventSerializer) validate() (bool, string) {
+	if s.StartTime > 0 && s.EndTime > 0 && s.EndTime <= s.StartTime {
+		return false, "end_time should be greater than start_time"
+	}
+	return true, "valid"
+}
+
+func (s *subjectMemberEventSerializer) filter() types.SubjectMemberEventFilter {
+	filter := types.SubjectMemberEventFilter{Operator: s.Operator}
+	if s.GroupID != "" {
+		filter.GroupType = types.GroupType
+		filter.GroupID = s.GroupID
+	}
+	if s.StartTime > 0 {
+		filter.StartTime = time.Unix(s.StartTime, 0)
+	}
+	if s.EndTime > 0 {
+		filter.EndTime = time.Unix(s.EndTime, 0)
+	}
+	return filter
+}
+
+type groupSettingQuerySerializer struct {
+	Type string `form:"type" json:"type" binding:"required,oneof=group"`
+	ID   string `form:"id" json:"id" binding:"required"`
+}
+
+// saveGroupSettingSerializer 各项配置为0表示不限制/使用系统配置
+type saveGroupSettingSerializer struct {
+	groupSettingQuerySerializer
+	MaxMembers            int64 `json:"max_members" binding:"omitempty,min=0"`
+	DefaultExpirationDays int64 `json:"default_expiration_days" binding:"omitempty,min=0,max=36500"`
+	MaxExpirationDays     int64 `json:"max_expiration_days" binding:"omitempty,min=0,max=36500"`
+	RenewalWindowDays     int64 `json:"renewal_window_days" binding:"omitempty,min=0,max=36500"`
+}
+
+func (s *saveGroupSettingSerializer) validate() (bool, string) {
+	if s.MaxExpirationDays > 0 && s.DefaultExpirationDays > s.MaxExpirationDays {
+		return false, "default_expiration_days should not be greater than max_expiration_days"
+	}
+	return true, "valid"
+}
@@ -16,15 +16,69 @@ import (
 
 	"github.com/agiledragon/gomonkey"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 
 	pl "iam/pkg/abac/prp/policy"
+	"iam/pkg/api/common"
 	"iam/pkg/cache/impls"
+	"iam/pkg/config"
+	"iam/pkg/errorx"
 	"iam/pkg/service"
 	"iam/pkg/service/mock"
 	"iam/pkg/service/types"
 	"iam/pkg/util"
 )
 
+func TestSearchSubjects(t *testing.T) {
+	url := "/api/v1/web/subjects/search"
+
+	t.Run("bad request without keyword", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, SearchSubjects)(t).
+			QueryParams(map[string]string{"type": "user"}).
+			BadRequestContainsMessage("Keyword")
+	})
+
+	t.Run("bad request with invalid type", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, SearchSubjects)(t).
+			QueryParams(map[string]string{"keyword": "ad", "type": "role"}).
+			BadRequestContainsMessage("Type")
+	})
+
+	t.Run("count fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().GetSearchCount("user", "ad").Return(int64(0), errors.New("count fail"))
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, SearchSubjects)(t).
+			QueryParams(map[string]string{"keyword": "ad", "type": "user"}).
+			SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().GetSearchCount("", "ad").Return(int64(1), nil)
+		mockSvc.EXPECT().SearchPaging("", "ad", int64(20), int64(0)).Return(
+			[]types.Subject{{Type: "user", ID: "admin", Name: "admin"}}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, SearchSubjects)(t).
+			QueryParams(map[string]string{"keyword": "ad"}).
+			OK()
+	})
+}
+
 func TestBatchCreateSubjects(t *testing.T) {
 	newRequestFunc := util.CreateNewAPIRequestFunc(
 		"post", "/api/v1/subjects", BatchCreateSubjects,
@@ -116,6 +170,229 @@ func TestBatchCreateSubjects(t *testing.T) {
 	})
 }
 
+func TestBatchCreateSubjectsServiceAccount(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"post", "/api/v1/subjects", BatchCreateSubjects,
+	)
+
+	t.Run("bad request invalid service account id", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON([]interface{}{
+				map[string]interface{}{
+					"type": "service_account",
+					"id":   "CI Bot",
+					"name": "ci bot",
+				},
+			}).BadRequestContainsMessage("service_account id `CI Bot` is invalid")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockManager := mock.NewMockSubjectService(ctl)
+		mockManager.EXPECT().BulkCreate(
+			[]types.Subject{{
+				Type: "service_account",
+				ID:   "ci-bot",
+				Name: "ci bot",
+			}},
+		).Return(nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockManager
+		})
+		defer patches.Reset()
+
+		newRequestFunc(t).
+			JSON([]interface{}{
+				map[string]interface{}{
+					"type": "service_account",
+					"id":   "ci-bot",
+					"name": "ci bot",
+				},
+			}).OK()
+	})
+}
+
+func TestListSubjectMemberByMemberType(t *testing.T) {
+	url := "/api/v1/web/subject-members"
+
+	t.Run("bad request with invalid member_type", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectMember)(t).
+			QueryParams(map[string]string{"type": "group", "id": "1", "member_type": "role"}).
+			BadRequestContainsMessage("MemberType")
+	})
+
+	t.Run("count fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().GetMemberCountBySubjectType("group", "1", "service_account").Return(
+			int64(0), errors.New("count fail"))
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectMember)(t).
+			QueryParams(map[string]string{"type": "group", "id": "1", "member_type": "service_account"}).
+			SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().GetMemberCountBySubjectType("group", "1", "service_account").Return(int64(1), nil)
+		mockSvc.EXPECT().ListPagingMemberBySubjectType("group", "1", "service_account", int64(20), int64(0)).Return(
+			[]types.SubjectMember{{PK: 1, Type: "service_account", ID: "ci-bot"}}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, ListSubjectMember)(t).
+			QueryParams(map[string]string{"type": "group", "id": "1", "member_type": "service_account"}).
+			OK()
+	})
+}
+
+func TestGetSubjectGroup(t *testing.T) {
+	url := "/api/v1/web/subject-relations"
+
+	t.Run("bad request with invalid limit", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", url, GetSubjectGroup)(t).
+			QueryParams(map[string]string{"type": "user", "id": "admin", "limit": "-1"}).
+			BadRequestContainsMessage("Limit")
+	})
+
+	t.Run("without limit, list all", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().ListSubjectGroups("user", "admin", int64(0)).Return(
+			[]types.SubjectGroup{{Type: "group", ID: "1"}}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, GetSubjectGroup)(t).
+			QueryParams(map[string]string{"type": "user", "id": "admin"}).
+			OK()
+	})
+
+	t.Run("paging count fail", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().GetSubjectGroupCount("user", "admin", int64(0)).Return(int64(0), errors.New("count fail"))
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, GetSubjectGroup)(t).
+			QueryParams(map[string]string{"type": "user", "id": "admin", "limit": "10"}).
+			SystemError()
+	})
+
+	t.Run("paging ok", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().GetSubjectGroupCount("user", "admin", int64(1000)).Return(int64(30), nil)
+		mockSvc.EXPECT().ListPagingSubjectGroups("user", "admin", int64(1000), int64(10), int64(20)).Return(
+			[]types.SubjectGroup{{Type: "group", ID: "1"}}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", url, GetSubjectGroup)(t).
+			QueryParams(map[string]string{
+				"type": "user", "id": "admin", "before_expired_at": "1000", "limit": "10", "offset": "20",
+			}).
+			OK()
+	})
+}
+
+func TestDeleteSubjectsCache(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"delete", "/api/v1/web/subjects/cache", DeleteSubjectsCache,
+	)
+
+	body := map[string]interface{}{
+		"subjects": []map[string]interface{}{
+			{"type": "user", "id": "admin"},
+		},
+	}
+
+	t.Run("no json", func(t *testing.T) {
+		newRequestFunc(t).NoJSON()
+	})
+
+	t.Run("bad request empty subjects", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON(map[string]interface{}{"subjects": []interface{}{}}).
+			BadRequestContainsMessage("Subjects")
+	})
+
+	t.Run("bad request invalid subject", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"subjects": []map[string]interface{}{{"type": "app", "id": "admin"}},
+			}).BadRequestContainsMessage("data in array[0]")
+	})
+
+	var ctl *gomock.Controller
+	var patches *gomonkey.Patches
+
+	newPatches := func(listErr error) *[]int64 {
+		deletedPKs := []int64{}
+		ctl = gomock.NewController(t)
+		mockSvc := mock.NewMockSubjectService(ctl)
+		mockSvc.EXPECT().ListPKsBySubjects(
+			[]types.Subject{{Type: "user", ID: "admin"}},
+		).Return([]int64{1}, listErr).AnyTimes()
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockSvc
+		})
+		patches.ApplyFunc(impls.BatchDeleteSubjectPK, func(subjects []types.Subject) error {
+			return nil
+		})
+		patches.ApplyFunc(impls.BatchDeleteSubjectCache, func(pks []int64) error {
+			deletedPKs = append(deletedPKs, pks...)
+			return nil
+		})
+		return &deletedPKs
+	}
+	restMock := func() {
+		ctl.Finish()
+		patches.Reset()
+	}
+
+	t.Run("list pks fail", func(t *testing.T) {
+		newPatches(errors.New("list fail"))
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		deletedPKs := newPatches(nil)
+		defer restMock()
+
+		newRequestFunc(t).JSON(body).OK()
+		assert.Equal(t, []int64{1}, *deletedPKs)
+	})
+}
+
 func TestBatchDeleteSubjects(t *testing.T) {
 	newRequestFunc := util.CreateNewAPIRequestFunc(
 		"delete", "/api/v1/subjects", BatchDeleteSubjects,
@@ -357,6 +634,7 @@ func TestCreateSubjectRole(t *testing.T) {
 				Type: "user",
 				ID:   "admin",
 			}},
+			"", "",
 		).Return(
 			errors.New("create fail"),
 		).AnyTimes()
@@ -387,6 +665,7 @@ func TestCreateSubjectRole(t *testing.T) {
 				Type: "user",
 				ID:   "admin",
 			}},
+			"admin", "",
 		).Return(
 			nil,
 		).AnyTimes()
@@ -400,6 +679,65 @@ func TestCreateSubjectRole(t *testing.T) {
 			JSON(map[string]interface{}{
 				"role_type": "system_manager",
 				"system_id": "test",
+				"operator":  "admin",
+				"subjects": []map[string]interface{}{
+					{
+						"type": "user",
+						"id":   "admin",
+					},
+				},
+			}).OK()
+	})
+
+	t.Run("bad request roles", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"roles": []map[string]interface{}{
+					{
+						"role_type": "super_manager",
+						"system_id": "test",
+					},
+				},
+				"subjects": []map[string]interface{}{
+					{
+						"type": "user",
+						"id":   "admin",
+					},
+				},
+			}).BadRequest("bad request:system_id must be SUPER if role type is super_manager")
+	})
+
+	t.Run("ok multi roles", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockManager := mock.NewMockSubjectService(ctl)
+		mockManager.EXPECT().BulkCreateSubjectMultiRoles(
+			[]types.SubjectRole{
+				{RoleType: "system_manager", System: "test"},
+				{RoleType: "system_manager", System: "test2"},
+			},
+			[]types.Subject{{
+				Type: "user",
+				ID:   "admin",
+			}},
+			"", "",
+		).Return(
+			nil,
+		).AnyTimes()
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockManager
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"role_type": "system_manager",
+				"system_id": "test",
+				"roles": []map[string]interface{}{
+					{
+						"role_type": "system_manager",
+						"system_id": "test2",
+					},
+				},
 				"subjects": []map[string]interface{}{
 					{
 						"type": "user",
@@ -458,6 +796,7 @@ func TestDeleteSubjectRole(t *testing.T) {
 				Type: "user",
 				ID:   "admin",
 			}},
+			"", "",
 		).Return(
 			errors.New("create fail"),
 		).AnyTimes()
@@ -488,6 +827,7 @@ func TestDeleteSubjectRole(t *testing.T) {
 				Type: "user",
 				ID:   "admin",
 			}},
+			"", "",
 		).Return(
 			nil,
 		).AnyTimes()
@@ -528,6 +868,15 @@ func TestBatchAddSubjectMembers(t *testing.T) {
 	})
 
 	t.Run("bad request policy_expired_at", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+		mockService := mock.NewMockSubjectGroupReadService(ctl)
+		mockService.EXPECT().GetGroupSetting("group", "1").Return(types.GroupSetting{}, nil)
+		patches := gomonkey.ApplyFunc(service.NewSubjectGroupReadService, func() service.SubjectGroupReadService {
+			return mockService
+		})
+		defer patches.Reset()
+
 		newRequestFunc(t).
 			JSON(map[string]interface{}{
 				"type":              "group",
@@ -537,6 +886,35 @@ func TestBatchAddSubjectMembers(t *testing.T) {
 			}).BadRequest("bad request:policy expires time required when add group member")
 	})
 
+	t.Run("bad request policy_expired_at exceed the maximum", func(t *testing.T) {
+		common.InitQuota(config.Quota{Member: map[string]int{"max_expiration_days": 30}}, map[string]config.Quota{})
+		defer common.InitQuota(config.Quota{}, map[string]config.Quota{})
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"type":              "group",
+				"id":                "1",
+				"policy_expired_at": 4102444800,
+				"members":           []map[string]interface{}{{"type": "user", "id": "admin"}},
+			}).BadRequestContainsMessage("the maximum expiration is 30 days")
+	})
+
+	t.Run("bad request policy_expired_at exceed the maximum of system", func(t *testing.T) {
+		common.InitQuota(config.Quota{}, map[string]config.Quota{
+			"bk_test": {Member: map[string]int{"max_expiration_days": 7}},
+		})
+		defer common.InitQuota(config.Quota{}, map[string]config.Quota{})
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"type":              "group",
+				"id":                "1",
+				"policy_expired_at": 4102444800,
+				"members":           []map[string]interface{}{{"type": "user", "id": "admin"}},
+				"system":            "bk_test",
+			}).BadRequestContainsMessage("the maximum expiration is 7 days")
+	})
+
 	var ctl *gomock.Controller
 	var patches *gomonkey.Patches
 
@@ -697,6 +1075,73 @@ func TestBatchAddSubjectMembers(t *testing.T) {
 				},
 			}).OK()
 	})
+
+	t.Run("bad request - group member cycle", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockManager := mock.NewMockSubjectService(ctl)
+		mockManager.EXPECT().ListMember("group", "1").Return([]types.SubjectMember{}, nil).AnyTimes()
+		mockManager.EXPECT().BulkCreateSubjectMembers(
+			"group",
+			"1",
+			[]types.Subject{{Type: "group", ID: "2"}},
+			int64(10),
+		).Return(errorx.Wrapf(service.ErrGroupMemberCycle, "SubjectSVC", "BulkCreateSubjectMembers", "")).AnyTimes()
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockManager
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"type":              "group",
+				"id":                "1",
+				"policy_expired_at": 10,
+				"members": []map[string]interface{}{
+					{
+						"type": "group",
+						"id":   "2",
+					},
+				},
+			}).BadRequestContainsMessage("group member cycle")
+	})
+
+	t.Run("ok - member add hooks", func(t *testing.T) {
+		common.InitMemberAddHooks([]config.MemberAddHook{
+			{Name: "dept_pending", RuleMemberTypes: []string{"department"}, RuleDecision: "pending"},
+		})
+		defer common.InitMemberAddHooks(nil)
+
+		ctl = gomock.NewController(t)
+		mockManager := mock.NewMockSubjectService(ctl)
+		mockManager.EXPECT().ListMember("group", "1").Return([]types.SubjectMember{}, nil).AnyTimes()
+		mockManager.EXPECT().BulkCreateSubjectMembers(
+			"group",
+			"1",
+			[]types.Subject{{Type: "user", ID: "admin"}},
+			int64(10),
+		).Return(nil).Times(1)
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockManager
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON(map[string]interface{}{
+				"type":              "group",
+				"id":                "1",
+				"policy_expired_at": 10,
+				"members": []map[string]interface{}{
+					{
+						"type": "user",
+						"id":   "admin",
+					},
+					{
+						"type": "department",
+						"id":   "10",
+					},
+				},
+			}).OK()
+	})
 }
 
 func TestDeleteSubjectMembers(t *testing.T) {
@@ -970,7 +1415,7 @@ func TestBatchCreateSubjectDepartments(t *testing.T) {
 			JSON(map[string]interface{}{
 				"hello": "123",
 			}).BadRequest("bad request:json decode or validate fail, " +
-			"err=json: cannot unmarshal object into Go value of type []handler.subjectDepartment")
+			"err=json: cannot unmarshal object into Go value of type []handler.createSubjectDepartmentSerializer")
 	})
 
 	var ctl *gomock.Controller
@@ -991,8 +1436,9 @@ func TestBatchCreateSubjectDepartments(t *testing.T) {
 				SubjectID:     "admin",
 				DepartmentIDs: []string{"1", "2"},
 			}},
+			gomock.Any(),
 		).Return(
-			errors.New("error"),
+			types.SubjectDepartmentBulkResult{}, errors.New("error"),
 		).AnyTimes()
 		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
 			return mockManager
@@ -1016,8 +1462,9 @@ func TestBatchCreateSubjectDepartments(t *testing.T) {
 				SubjectID:     "admin",
 				DepartmentIDs: []string{"1", "2"},
 			}},
+			gomock.Any(),
 		).Return(
-			nil,
+			types.SubjectDepartmentBulkResult{Total: 1, Created: 1}, nil,
 		).AnyTimes()
 		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
 			return mockManager
@@ -1032,6 +1479,47 @@ func TestBatchCreateSubjectDepartments(t *testing.T) {
 				},
 			}).OK()
 	})
+
+	t.Run("bad request invalid department parents", func(t *testing.T) {
+		newRequestFunc(t).
+			JSON([]interface{}{
+				map[string]interface{}{
+					"id":                 "admin",
+					"departments":        []string{"1"},
+					"department_parents": []interface{}{map[string]interface{}{"id": "1"}},
+				},
+			}).BadRequestContainsMessage("ParentID")
+	})
+
+	t.Run("ok with department parents", func(t *testing.T) {
+		ctl = gomock.NewController(t)
+		mockManager := mock.NewMockSubjectService(ctl)
+		mockManager.EXPECT().BulkCreateSubjectDepartments(
+			[]types.SubjectDepartment{{
+				SubjectID:         "admin",
+				DepartmentIDs:     []string{"2"},
+				DepartmentParents: []types.DepartmentParent{{ID: "2", ParentID: "1"}},
+			}},
+			gomock.Any(),
+		).Return(
+			types.SubjectDepartmentBulkResult{Total: 1, Created: 1}, nil,
+		)
+		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
+			return mockManager
+		})
+		defer restMock()
+
+		newRequestFunc(t).
+			JSON([]interface{}{
+				map[string]interface{}{
+					"id":          "admin",
+					"departments": []string{"2"},
+					"department_parents": []interface{}{
+						map[string]interface{}{"id": "2", "parent_id": "1"},
+					},
+				},
+			}).OK()
+	})
 }
 
 func TestBatchDeleteSubjectDepartments(t *testing.T) {
@@ -1069,7 +1557,7 @@ func TestBatchDeleteSubjectDepartments(t *testing.T) {
 		ctl = gomock.NewController(t)
 		mockManager := mock.NewMockSubjectService(ctl)
 		mockManager.EXPECT().BulkDeleteSubjectDepartments(
-			[]string{"admin"},
+			[]string{"admin"}, gomock.Any(),
 		).Return(
 			nil, errors.New("error"),
 		).AnyTimes()
@@ -1084,7 +1572,7 @@ func TestBatchDeleteSubjectDepartments(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
 		ctl = gomock.NewController(t)
 		mockManager := mock.NewMockSubjectService(ctl)
-		mockManager.EXPECT().BulkDeleteSubjectDepartments([]string{"admin"}).Return([]int64{1}, nil).AnyTimes()
+		mockManager.EXPECT().BulkDeleteSubjectDepartments([]string{"admin"}, gomock.Any()).Return([]int64{1}, nil).AnyTimes()
 		patches = gomonkey.ApplyFunc(service.NewSubjectService, func() service.SubjectService {
 			return mockManager
 		})
@@ -1130,6 +1618,7 @@ func TestBatchUpdateSubjectDepartments(t *testing.T) {
 				SubjectID:     "admin",
 				DepartmentIDs: []string{"1", "2"},
 			}},
+			gomock.Any(),
 		).Return(
 			nil, errors.New("error"),
 		).AnyTimes()
@@ -1155,6 +1644,7 @@ func TestBatchUpdateSubjectDepartments(t *testing.T) {
 				SubjectID:     "admin",
 				DepartmentIDs: []string{"1", "2"},
 			}},
+			gomock.Any(),
 		).Return(
 			[]int64{1}, nil,
 		).AnyTimes()
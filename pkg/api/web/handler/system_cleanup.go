@@ -0,0 +1,149 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/api/common"
+	"iam/pkg/errorx"
+	"iam/pkg/offboarding"
+	"iam/pkg/util"
+)
+
+// GetSystemCleanupPreflight godoc
+// @Summary system cleanup preflight/系统下线预检
+// @Description count the policies, groups with auth and model entities that would be removed by the system cleanup
+// @ID api-web-get-system-cleanup-preflight
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param system_id path string true "system id"
+// @Success 200 {object} util.Response{data=offboarding.Preflight}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/system-cleanups/{system_id}/preflight [get]
+func GetSystemCleanupPreflight(c *gin.Context) {
+	systemID := c.Param("system_id")
+
+	preflight, err := offboarding.GetPreflight(systemID)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "GetSystemCleanupPreflight", "systemID=`%s`", systemID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", preflight)
+}
+
+// CreateSystemCleanupTask godoc
+// @Summary create system cleanup task/系统下线清理
+// @Description async delete the policies and the model(actions/instance selections/resource types) of a frozen system
+// @ID api-web-create-system-cleanup-task
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param system_id path string true "system id"
+// @Success 200 {object} util.Response{data=offboarding.Task}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/system-cleanups/{system_id} [post]
+func CreateSystemCleanupTask(c *gin.Context) {
+	systemID := c.Param("system_id")
+
+	// the system should be frozen first, avoid the model/policies being modified during the cleanup
+	if !common.IsSystemFrozen(systemID) {
+		util.ConflictJSONResponse(c, fmt.Sprintf("system(%s) should be frozen before cleanup", systemID))
+		return
+	}
+
+	task, err := offboarding.StartSystemCleanup(systemID)
+	if errors.Is(err, offboarding.ErrTaskRunning) {
+		util.ConflictJSONResponse(c, err.Error())
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "CreateSystemCleanupTask", "systemID=`%s`", systemID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", task)
+}
+
+// GetSystemCleanupTask godoc
+// @Summary get the progress of system cleanup task/查询系统清理任务的进度
+// @Description get the progress of system cleanup task
+// @ID api-web-get-system-cleanup-task
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param task_id path string true "Task ID"
+// @Success 200 {object} util.Response{data=offboarding.Task}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/system-cleanup-tasks/{task_id} [get]
+func GetSystemCleanupTask(c *gin.Context) {
+	taskID := c.Param("task_id")
+
+	task, err := offboarding.GetTask(taskID)
+	if errors.Is(err, offboarding.ErrTaskNotFound) {
+		// the task not exists or expired
+		util.NotFoundJSONResponse(c, fmt.Sprintf("system cleanup task(%s)", taskID))
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "GetSystemCleanupTask", "taskID=`%s`", taskID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", task)
+}
+
+// GetSystemCleanupTaskEvents godoc
+// @Summary stream the progress of system cleanup task/推送系统清理任务的进度
+// @Description stream the progress of the system cleanup task via Server-Sent Events, until the task finished or failed
+// @ID api-web-get-system-cleanup-task-events
+// @Tags web
+// @Accept json
+// @Produce text/event-stream
+// @Param task_id path string true "Task ID"
+// @Success 200 {object} offboarding.Task
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/system-cleanup-tasks/{task_id}/events [get]
+func GetSystemCleanupTaskEvents(c *gin.Context) {
+	taskID := c.Param("task_id")
+
+	task, err := offboarding.GetTask(taskID)
+	if errors.Is(err, offboarding.ErrTaskNotFound) {
+		// the task not exists or expired
+		util.NotFoundJSONResponse(c, fmt.Sprintf("system cleanup task(%s)", taskID))
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "GetSystemCleanupTaskEvents", "taskID=`%s`", taskID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	streamTaskEvents(c, task, task.Status != offboarding.TaskStatusRunning, func() (interface{}, bool, error) {
+		t, err := offboarding.GetTask(taskID)
+		return t, t.Status != offboarding.TaskStatusRunning, err
+	})
+}
@@ -0,0 +1,116 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+
+	"iam/pkg/api/common"
+	"iam/pkg/offboarding"
+	"iam/pkg/util"
+)
+
+func TestGetSystemCleanupPreflight(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"get", "/api/v1/web/system-cleanups/bk_test/preflight", GetSystemCleanupPreflight,
+		"/api/v1/web/system-cleanups/:system_id/preflight",
+	)
+
+	t.Run("error", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(offboarding.GetPreflight, func(systemID string) (offboarding.Preflight, error) {
+			return offboarding.Preflight{}, errors.New("count fail")
+		})
+		defer patches.Reset()
+
+		newRequestFunc(t).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(offboarding.GetPreflight, func(systemID string) (offboarding.Preflight, error) {
+			return offboarding.Preflight{SystemID: systemID}, nil
+		})
+		defer patches.Reset()
+
+		newRequestFunc(t).OK()
+	})
+}
+
+func TestCreateSystemCleanupTask(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"post", "/api/v1/web/system-cleanups/bk_test", CreateSystemCleanupTask,
+		"/api/v1/web/system-cleanups/:system_id",
+	)
+
+	newPatches := func(frozen bool, startErr error) *gomonkey.Patches {
+		patches := gomonkey.ApplyFunc(common.IsSystemFrozen, func(systemID string) bool {
+			return frozen
+		})
+		patches.ApplyFunc(offboarding.StartSystemCleanup, func(systemID string) (offboarding.Task, error) {
+			return offboarding.Task{ID: "abc", SystemID: systemID}, startErr
+		})
+		return patches
+	}
+
+	t.Run("system not frozen", func(t *testing.T) {
+		patches := newPatches(false, nil)
+		defer patches.Reset()
+
+		newRequestFunc(t).ConflictContainsMessage("should be frozen")
+	})
+
+	t.Run("task running", func(t *testing.T) {
+		patches := newPatches(true, offboarding.ErrTaskRunning)
+		defer patches.Reset()
+
+		newRequestFunc(t).ConflictContainsMessage("running")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		patches := newPatches(true, errors.New("save task fail"))
+		defer patches.Reset()
+
+		newRequestFunc(t).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := newPatches(true, nil)
+		defer patches.Reset()
+
+		newRequestFunc(t).OK()
+	})
+}
+
+func TestGetSystemCleanupTask(t *testing.T) {
+	newRequestFunc := util.CreateNewAPIRequestFunc(
+		"get", "/api/v1/web/system-cleanup-tasks/abc", GetSystemCleanupTask,
+		"/api/v1/web/system-cleanup-tasks/:task_id",
+	)
+
+	t.Run("error", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(offboarding.GetTask, func(taskID string) (offboarding.Task, error) {
+			return offboarding.Task{}, errors.New("redis fail")
+		})
+		defer patches.Reset()
+
+		newRequestFunc(t).SystemError()
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(offboarding.GetTask, func(taskID string) (offboarding.Task, error) {
+			return offboarding.Task{ID: taskID, Status: offboarding.TaskStatusFinished}, nil
+		})
+		defer patches.Reset()
+
+		newRequestFunc(t).OK()
+	})
+}
@@ -0,0 +1,103 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"iam/pkg/api/common"
+	"iam/pkg/errorx"
+	"iam/pkg/util"
+)
+
+type freezeSystemSerializer struct {
+	Reason string `json:"reason"`
+}
+
+// ListFrozenSystems godoc
+// @Summary list frozen systems/查询冻结的系统
+// @Description list all the frozen systems, the modification of frozen system is not allowed
+// @ID api-web-list-frozen-systems
+// @Tags web
+// @Accept json
+// @Produce json
+// @Success 200 {object} util.Response{data=[]common.FrozenSystem}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/frozen-systems [get]
+func ListFrozenSystems(c *gin.Context) {
+	systems, err := common.ListFrozenSystems()
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "ListFrozenSystems", "")
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", systems)
+}
+
+// FreezeSystem godoc
+// @Summary freeze system/冻结系统
+// @Description freeze the system, all the modification(model/policies/roles) of the system will be rejected, auth/query still work
+// @ID api-web-freeze-system
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param system_id path string true "system id"
+// @Param body body freezeSystemSerializer true "the reason of freezing"
+// @Success 200 {object} util.Response
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/frozen-systems/{system_id} [put]
+func FreezeSystem(c *gin.Context) {
+	var body freezeSystemSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+
+	systemID := c.Param("system_id")
+	err := common.FreezeSystem(systemID, body.Reason)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "FreezeSystem", "systemID=`%s`", systemID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{})
+}
+
+// UnfreezeSystem godoc
+// @Summary unfreeze system/解冻系统
+// @Description unfreeze the system
+// @ID api-web-unfreeze-system
+// @Tags web
+// @Accept json
+// @Produce json
+// @Param system_id path string true "system id"
+// @Success 200 {object} util.Response
+// @Header 200 {string} X-Request-Id "the request id"
+// @Security AppCode
+// @Security AppSecret
+// @Router /api/v1/web/frozen-systems/{system_id} [delete]
+func UnfreezeSystem(c *gin.Context) {
+	systemID := c.Param("system_id")
+	err := common.UnfreezeSystem(systemID)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "UnfreezeSystem", "systemID=`%s`", systemID)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", gin.H{})
+}
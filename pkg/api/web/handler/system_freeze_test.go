@@ -0,0 +1,73 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/api/common"
+	"iam/pkg/cache/impls"
+	"iam/pkg/cache/redis"
+	"iam/pkg/util"
+)
+
+func TestFreezeSystem(t *testing.T) {
+	impls.SystemFreezeCache = redis.NewMockCache("sys_frz", 0)
+	defer func() {
+		impls.SystemFreezeCache = nil
+	}()
+
+	t.Run("freeze", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc(
+			"put", "/api/v1/web/frozen-systems/bk_cmdb", FreezeSystem, "/api/v1/web/frozen-systems/:system_id",
+		)(t).
+			JSON(map[string]interface{}{"reason": "migration"}).
+			OK()
+
+		assert.True(t, common.IsSystemFrozen("bk_cmdb"))
+	})
+
+	t.Run("frozen system modification rejected", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("delete", "/api/v1/web/perm-templates/policies", DeleteSubjectTemplatePolicies)(t).
+			JSON(map[string]interface{}{
+				"subject_type": "user",
+				"subject_id":   "admin",
+				"template_id":  1,
+				"system_id":    "bk_cmdb",
+			}).
+			ConflictContainsMessage("frozen")
+	})
+
+	t.Run("list", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc("get", "/api/v1/web/frozen-systems", ListFrozenSystems)(t).OK()
+	})
+
+	t.Run("unfreeze", func(t *testing.T) {
+		util.CreateNewAPIRequestFunc(
+			"delete", "/api/v1/web/frozen-systems/bk_cmdb", UnfreezeSystem, "/api/v1/web/frozen-systems/:system_id",
+		)(t).OK()
+
+		assert.False(t, common.IsSystemFrozen("bk_cmdb"))
+	})
+
+	t.Run("list error", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(common.ListFrozenSystems, func() ([]common.FrozenSystem, error) {
+			return nil, errors.New("redis fail")
+		})
+		defer patches.Reset()
+
+		util.CreateNewAPIRequestFunc("get", "/api/v1/web/frozen-systems", ListFrozenSystems)(t).SystemError()
+	})
+}
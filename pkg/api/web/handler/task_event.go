@@ -0,0 +1,96 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	jsoniter "github.com/json-iterator/go"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+异步任务(导出/系统清理/模板解绑)的进度事件, 通过SSE(Server-Sent Events)推送, 避免SaaS高频轮询
+
+1. 服务端按间隔查询任务进度, 有变化时推送 `progress` 事件
+2. 任务结束(finished/failed)时推送 `done` 事件并关闭连接
+3. 查询进度失败(例如任务已过期)时推送 `error` 事件并关闭连接
+
+NOTE: 单个连接最长保持 taskEventMaxDuration, 需要小于server的write timeout(默认60s), 超时关闭后EventSource会自动重连
+*/
+
+// SSE事件类型
+const (
+	taskEventProgress = "progress"
+	taskEventDone     = "done"
+	taskEventError    = "error"
+)
+
+var (
+	taskEventPollInterval = 1 * time.Second
+	taskEventMaxDuration  = 50 * time.Second
+)
+
+// taskGetter 查询任务的最新进度, done表示任务已结束
+type taskGetter func() (task interface{}, done bool, err error)
+
+// streamTaskEvents 推送任务的进度事件, task/done为已查询到的任务进度
+func streamTaskEvents(c *gin.Context, task interface{}, done bool, getTask taskGetter) {
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// disable the response buffering of nginx
+	c.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(taskEventPollInterval)
+	defer ticker.Stop()
+	timeout := time.NewTimer(taskEventMaxDuration)
+	defer timeout.Stop()
+
+	lastData := ""
+	for {
+		data, err := jsoniter.MarshalToString(task)
+		if err != nil {
+			log.WithError(err).Errorf("streamTaskEvents marshal task=`%+v` fail", task)
+			sendTaskEvent(c, taskEventError, err.Error())
+			return
+		}
+
+		if done {
+			sendTaskEvent(c, taskEventDone, data)
+			return
+		}
+		// only send the changed progress
+		if data != lastData {
+			sendTaskEvent(c, taskEventProgress, data)
+			lastData = data
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-timeout.C:
+			return
+		case <-ticker.C:
+		}
+
+		task, done, err = getTask()
+		if err != nil {
+			log.WithError(err).Warn("streamTaskEvents getTask fail")
+			sendTaskEvent(c, taskEventError, err.Error())
+			return
+		}
+	}
+}
+
+func sendTaskEvent(c *gin.Context, event string, data string) {
+	c.SSEvent(event, data)
+	c.Writer.Flush()
+}
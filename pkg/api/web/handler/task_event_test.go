@@ -0,0 +1,97 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/export"
+	"iam/pkg/util"
+)
+
+func TestGetExportTaskEvents(t *testing.T) {
+	oldInterval := taskEventPollInterval
+	taskEventPollInterval = time.Millisecond
+	defer func() {
+		taskEventPollInterval = oldInterval
+	}()
+
+	url := "/api/v1/web/exports/tasks/abc/events"
+	doRequest := func() *httptest.ResponseRecorder {
+		r := util.SetupRouter()
+		r.GET("/api/v1/web/exports/tasks/:task_id/events", GetExportTaskEvents)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(export.GetTask, func(taskID string) (export.Task, error) {
+			return export.Task{}, export.ErrTaskNotFound
+		})
+		defer patches.Reset()
+
+		w := doRequest()
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "not found")
+		assert.NotContains(t, w.Header().Get("Content-Type"), "text/event-stream")
+	})
+
+	t.Run("progress until finished", func(t *testing.T) {
+		calls := 0
+		patches := gomonkey.ApplyFunc(export.GetTask, func(taskID string) (export.Task, error) {
+			calls++
+			switch {
+			case calls <= 2:
+				return export.Task{ID: "abc", Status: export.TaskStatusRunning, Files: []string{}}, nil
+			case calls == 3:
+				return export.Task{ID: "abc", Status: export.TaskStatusRunning, Files: []string{"a.csv"}}, nil
+			default:
+				return export.Task{ID: "abc", Status: export.TaskStatusFinished, Files: []string{"a.csv"}}, nil
+			}
+		})
+		defer patches.Reset()
+
+		w := doRequest()
+		body := w.Body.String()
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/event-stream")
+		// the unchanged progress will not be sent again
+		assert.Equal(t, 2, strings.Count(body, "event:progress"))
+		assert.Equal(t, 1, strings.Count(body, "event:done"))
+		assert.Contains(t, body, `"status":"finished"`)
+	})
+
+	t.Run("get task fail", func(t *testing.T) {
+		calls := 0
+		patches := gomonkey.ApplyFunc(export.GetTask, func(taskID string) (export.Task, error) {
+			calls++
+			if calls > 1 {
+				return export.Task{}, errors.New("redis fail")
+			}
+			return export.Task{ID: "abc", Status: export.TaskStatusRunning}, nil
+		})
+		defer patches.Reset()
+
+		body := doRequest().Body.String()
+		assert.Equal(t, 1, strings.Count(body, "event:progress"))
+		assert.Contains(t, body, "event:error")
+		assert.Contains(t, body, "redis fail")
+	})
+}
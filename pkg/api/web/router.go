@@ -26,6 +26,8 @@ func Register(r *gin.RouterGroup) {
 	// all resource in system
 	s := r.Group("/systems/:system_id")
 	s.Use(common.SystemExists())
+	// the modification of frozen system is not allowed
+	s.Use(common.SystemNotFrozen())
 	{
 		// system 信息
 		s.GET("", handler.GetSystem)
@@ -51,12 +53,43 @@ func Register(r *gin.RouterGroup) {
 		s.DELETE("/actions/:action_id/policies", handler.DeleteActionPolicies)
 	}
 
+	// 模拟策略变更后的鉴权结果, 不会写入, 冻结的系统也可以使用
+	r.POST("/systems/:system_id/policies/simulate", common.SystemExists(), handler.SimulatePolicies)
+	// 虚拟用户(部门+用户组)的鉴权结果, 不需要用户存在, 不会写入
+	r.POST("/systems/:system_id/policies/hypothetical-auth", common.SystemExists(), handler.EvalHypotheticalSubject)
+	// 反查对资源实例生效的策略(谁可以访问该资源), 用于审计, 按策略游标分页扫描
+	r.POST("/systems/:system_id/policies/by-resource", common.SystemExists(), handler.ListResourcePolicy)
+
+	// 系统冻结, 冻结期间系统的变更都会被拒绝, 不影响鉴权/查询
+	r.GET("/frozen-systems", handler.ListFrozenSystems)
+	fs := r.Group("/frozen-systems/:system_id")
+	fs.Use(common.SystemExists())
+	{
+		fs.PUT("", handler.FreezeSystem)
+		fs.DELETE("", handler.UnfreezeSystem)
+	}
+
+	// 系统下线: 预检将被清理的数据, 并异步清理冻结系统的策略及模型
+	sc := r.Group("/system-cleanups/:system_id")
+	sc.Use(common.SystemExists())
+	{
+		sc.GET("/preflight", handler.GetSystemCleanupPreflight)
+		sc.POST("", handler.CreateSystemCleanupTask)
+	}
+	// 查询系统清理任务的进度
+	r.GET("/system-cleanup-tasks/:task_id", handler.GetSystemCleanupTask)
+	// 推送系统清理任务的进度(SSE)
+	r.GET("/system-cleanup-tasks/:task_id/events", handler.GetSystemCleanupTaskEvents)
+
 	// 资源类型列表
 	r.GET("/resource-types", handler.ListResourceType)
 
 	// 查询过期的 policy 列表
 	r.GET("/policies", handler.ListPolicy)
 
+	// 查询即将过期的 policy 列表, 用于续期提醒
+	r.GET("/policies/expiring", handler.ListExpiringPolicy)
+
 	// 更新策略过期时间
 	r.PUT("/policies/expired_at", handler.UpdatePoliciesExpiredAt)
 
@@ -70,21 +103,38 @@ func Register(r *gin.RouterGroup) {
 		pt.POST("/policies", handler.CreateAndDeleteTemplatePolicies)
 		// 模板授权更新
 		pt.PUT("/policies", handler.UpdateTemplatePolicies)
+		// 模板授权续期, 更新模板生成的所有策略的过期时间
+		pt.PUT("/policies/expired_at", handler.UpdateTemplatePoliciesExpiredAt)
 		// 删除模板授权
 		pt.DELETE("/policies", handler.DeleteSubjectTemplatePolicies)
+		// 异步删除模板授权, 后台分批清理策略
+		pt.DELETE("/policies/async", handler.AsyncDeleteSubjectTemplatePolicies)
+		// 查询异步删除模板授权的进度
+		pt.GET("/unbind-tasks/:task_id", handler.GetTemplateUnbindTask)
+		// 推送异步删除模板授权的进度(SSE)
+		pt.GET("/unbind-tasks/:task_id/events", handler.GetTemplateUnbindTaskEvents)
 	}
 
 	// 查询subject列表
 	r.GET("/subjects", handler.ListSubject)
+	// 按关键字搜索subject
+	r.GET("/subjects/search", handler.SearchSubjects)
 	// 创建subject
 	r.POST("/subjects", handler.BatchCreateSubjects)
 	// 删除subject
 	r.DELETE("/subjects", handler.BatchDeleteSubjects)
 	// 更新subject
 	r.PUT("/subjects", handler.BatchUpdateSubject)
+	// 清理subject的缓存, 用于SaaS在数据修复后主动清理
+	r.DELETE("/subjects/cache", handler.DeleteSubjectsCache)
 	// 筛选有过期成员的subjects
 	r.POST("/subjects/before_expired_at", handler.ListExistSubjectsBeforeExpiredAt)
 
+	// subject冻结, 冻结的subject鉴权时没有任何权限, 不删除其数据
+	r.GET("/frozen-subjects", handler.ListFrozenSubjects)
+	r.POST("/frozen-subjects", handler.BatchFreezeSubjects)
+	r.DELETE("/frozen-subjects", handler.BatchUnfreezeSubjects)
+
 	// 查询subject的成员列表
 	r.GET("/subject-members", handler.ListSubjectMember)
 	// 批量添加subject成员
@@ -93,12 +143,39 @@ func Register(r *gin.RouterGroup) {
 	r.DELETE("/subject-members", handler.DeleteSubjectMembers)
 	// 批量subject成员过期时间
 	r.PUT("/subject-members/expired_at", handler.UpdateSubjectMembersExpiredAt)
+	// 复制/移动用户组的所有成员到另一个用户组
+	r.POST("/subject-members/copy", handler.CopySubjectMembers)
+	// 用户组成员列表的快照, 以及从快照恢复成员
+	r.GET("/subject-members/snapshots", handler.ListSubjectMemberSnapshots)
+	r.POST("/subject-members/snapshots", handler.CreateSubjectMemberSnapshot)
+	r.GET("/subject-members/snapshots/:snapshot_id", handler.GetSubjectMemberSnapshot)
+	r.POST("/subject-members/snapshots/:snapshot_id/restore", handler.RestoreSubjectMemberSnapshot)
+	// 查询用户组成员的变更记录, 用于合规审计
+	r.GET("/subject-members/events", handler.ListSubjectMemberEvents)
+
+	// 分析用户组的冗余成员关系(直接加入且通过部门继承/通过多个部门继承)
+	r.GET("/subject-members/redundancy", handler.ListRedundantSubjectMembers)
+	// 移除被部门完全覆盖的直接成员关系
+	r.DELETE("/subject-members/redundancy", handler.DeleteRedundantSubjectMembers)
+	// 查询用户组展开后的有效用户(直接加入的用户及部门成员下的用户), 用于通知类的任务
+	r.GET("/subject-members/effective-users", handler.ListGroupEffectiveUsers)
 
 	// 查询小于指定过期时间的成员列表, 批量用户组查询
 	r.GET("/subject-members/query", handler.ListSubjectMemberBeforeExpiredAt)
+	// 导出用户组成员为CSV
+	r.GET("/subject-members/export", handler.ExportSubjectMembersCSV)
+	// 从CSV导入用户组成员, 支持dry-run
+	r.POST("/subject-members/import", handler.ImportSubjectMembersCSV)
+
+	// 用户组的成员配置: 成员数量上限/默认及最大过期天数/续期窗口
+	r.GET("/group-settings", handler.GetGroupSetting)
+	r.PUT("/group-settings", handler.SaveGroupSetting)
+	r.DELETE("/group-settings", handler.DeleteGroupSetting)
 
 	// 查询subject所在的用户组/部门
 	r.GET("/subject-relations", handler.GetSubjectGroup)
+	// 查询subject的用户组统计(直接/部门继承/覆盖的系统)
+	r.GET("/subject-relations/summary", handler.GetSubjectGroupSummary)
 
 	// 查询subject-department关系
 	r.GET("/subject-departments", handler.ListSubjectDepartments)
@@ -108,6 +185,12 @@ func Register(r *gin.RouterGroup) {
 	r.PUT("/subject-departments", handler.BatchUpdateSubjectDepartments)
 	// 删除subject-department关系
 	r.DELETE("/subject-departments", handler.BatchDeleteSubjectDepartments)
+	// 查询用户的部门变更记录
+	r.GET("/subject-departments/history", handler.ListSubjectDepartmentHistory)
+
+	// 审计查询: 按条件查询部门/角色的变更记录, 以及导出为CSV
+	r.GET("/audits", handler.ListAuditRecords)
+	r.GET("/audits/export", handler.ExportAuditRecords)
 
 	// 查询subject role
 	r.GET("/subject-roles", handler.ListSubjectRole)
@@ -115,8 +198,45 @@ func Register(r *gin.RouterGroup) {
 	r.POST("/subject-roles", handler.CreateSubjectRole)
 	// 批量删除subject role
 	r.DELETE("/subject-roles", handler.DeleteSubjectRole)
+	// 查询角色的授予/回收记录
+	r.GET("/subject-roles/history", handler.ListSubjectRoleHistory)
+	// 查询subject的所有角色及其管理的系统
+	r.GET("/subject-roles/systems", handler.ListSubjectRoleSystems)
+	// 分页查询所有系统的角色及其授予的subject, 用于超级管理员的管理页面
+	r.GET("/subject-roles/holders", handler.ListSubjectRoleHolders)
+
+	// 导出subject/group/department关系到对象存储, 用于BI分析
+	r.POST("/exports/subject-relations", handler.CreateSubjectRelationExport)
+	// 查询导出任务的进度
+	r.GET("/exports/tasks/:task_id", handler.GetExportTask)
+	// 推送导出任务的进度(SSE)
+	r.GET("/exports/tasks/:task_id/events", handler.GetExportTaskEvents)
+
+	// 离线统计表达式中操作符及属性的使用情况
+	r.POST("/census/expressions", handler.CreateExpressionCensus)
+	// 查询统计任务的进度及结果
+	r.GET("/census/tasks/:task_id", handler.GetCensusTask)
+
+	// 外部的subject属性提供方, 提供的属性可以在条件中以subject.{attribute}引用
+	r.GET("/subject-attribute-providers", handler.ListSubjectAttributeProviders)
+	r.POST("/subject-attribute-providers", handler.CreateSubjectAttributeProvider)
+	r.PUT("/subject-attribute-providers/:provider_id", handler.UpdateSubjectAttributeProvider)
+	r.DELETE("/subject-attribute-providers/:provider_id", handler.DeleteSubjectAttributeProvider)
 
 	// 模型变更事件
 	r.GET("/model-change-event", handler.ListModelChangeEvent)
 	r.PUT("/model-change-event/:event_pk", handler.UpdateModelChangeEvent)
+
+	// 缓存的查看与清理, 出现脏数据时无需重启实例
+	ac := r.Group("/admin/caches")
+	{
+		// 查询所有缓存的概览(数量/命中率)
+		ac.GET("", handler.ListCaches)
+		// 按系统或subject清理所有相关的缓存
+		ac.DELETE("", handler.FlushCaches)
+		// 查看缓存中的key
+		ac.GET("/:name/peek", handler.PeekCache)
+		// 清理整个缓存, 或缓存中系统/subject相关的key
+		ac.DELETE("/:name", handler.FlushCache)
+	}
 }
@@ -0,0 +1,87 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package impls
+
+import (
+	"context"
+	"encoding/json"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/errorx"
+)
+
+/*
+ * > 通过运维接口清理缓存时, 需要让所有实例的本地缓存同时清理
+ *
+ * 1. 接收请求的实例清理本地缓存与redis缓存, 并通过 redis pub/sub 广播清理消息
+ * 2. 所有实例订阅该channel, 收到消息后只清理本地缓存
+ */
+
+const cacheFlushChannel = "cache_flush"
+
+// CacheFlush 缓存清理消息
+type CacheFlush struct {
+	Names []string `json:"names"`
+	Scope string   `json:"scope"`
+	Value string   `json:"value"`
+}
+
+func broadcastCacheFlush(names []string, scope, value string) error {
+	message, err := json.Marshal(CacheFlush{Names: names, Scope: scope, Value: value})
+	if err != nil {
+		return errorx.Wrapf(err, CacheLayer, "broadcastCacheFlush",
+			"json.Marshal names=`%+v`, scope=`%s`, value=`%s` fail", names, scope, value)
+	}
+
+	err = PubSubCache.Publish(cacheFlushChannel, string(message))
+	if err != nil {
+		return errorx.Wrapf(err, CacheLayer, "broadcastCacheFlush",
+			"PubSubCache.Publish channel=`%s` fail", cacheFlushChannel)
+	}
+	return nil
+}
+
+// SubscribeCacheFlush 订阅其他实例广播的缓存清理消息, 阻塞直到ctx结束
+func SubscribeCacheFlush(ctx context.Context) {
+	pubsub := PubSubCache.Subscribe(ctx, cacheFlushChannel)
+	defer pubsub.Close()
+
+	log.Infof("subscribe the cache flush channel `%s`", cacheFlushChannel)
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			handleCacheFlushMessage(msg.Payload)
+		}
+	}
+}
+
+func handleCacheFlushMessage(payload string) {
+	var flush CacheFlush
+	err := json.Unmarshal([]byte(payload), &flush)
+	if err != nil {
+		log.WithError(err).Errorf("unmarshal cache flush message fail, payload=`%s`", payload)
+		return
+	}
+
+	// NOTE: the instance received the request also receive the message, flush the local caches again is harmless
+	_, err = flushCaches(flush.Names, flush.Scope, flush.Value, true)
+	if err != nil {
+		log.WithError(err).Errorf("flush local caches fail, message=`%+v`", flush)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"go.uber.org/multierr"
 
 	"iam/pkg/cache"
+	"iam/pkg/service/types"
 )
 
 // NOTE: action
@@ -54,6 +55,7 @@ func (d resourceTypeCacheDeleter) Execute(key cache.Key) (err error) {
 //                                           |=>          BatchDeleteSubjectDepartments(pks)
 //                    => DeleteSubjectMembers =>      for DeleteSubjectGroup(pk)
 //                    => BatchAddSubjectMembers =>    for DeleteSubjectGroup(pk)
+//                    => UpdateSubjectMembersExpiredAt => BatchDeleteSubjectCache(member subject pks)
 //                    => BatchDeleteSubjectDepartments => BatchDeleteSubjectDepartments(pks)
 //                    => BatchUpdateSubjectDepartments => BatchDeleteSubjectDepartments(pks)
 // subject => 一个subject更新, 批量刷掉其所有缓存, 不考虑范围?  Delete SubjectPK/SubjectGroups/SubjectDepartments, batch support
@@ -65,6 +67,8 @@ func (d subjectCacheDeleter) Execute(key cache.Key) (err error) {
 	err = multierr.Combine(
 		SubjectGroupCache.Delete(key),
 		SubjectDetailCache.Delete(key),
+		// NOTE: only the local cache of current instance, others wait for expiration
+		LocalSubjectGroupCache.Delete(key),
 	)
 	return
 }
@@ -127,6 +131,19 @@ func BatchDeleteSubjectCache(pks []int64) error {
 	return nil
 }
 
+// BatchDeleteSubjectPK 清理subject type+id => pk 的缓存
+// NOTE: 本地缓存只能清理当前实例的, 其他实例的本地缓存有效期很短, 等待过期即可
+func BatchDeleteSubjectPK(subjects []types.Subject) (err error) {
+	for _, s := range subjects {
+		err = multierr.Combine(
+			err,
+			DeleteSubjectPK(s.Type, s.ID),
+			DeleteLocalSubjectPK(s.Type, s.ID),
+		)
+	}
+	return
+}
+
 // DeleteSystemCache ...
 func DeleteSystemCache(systemID string) error {
 	key := cache.NewStringKey(systemID)
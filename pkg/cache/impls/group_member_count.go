@@ -0,0 +1,91 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package impls
+
+import (
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/cache"
+	"iam/pkg/cache/redis"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+)
+
+// GetGroupMemberCount 获取用户组的成员数量
+// 计数缓存在redis中, 成员增删时调整计数; 缓存过期后重新从DB统计, 以修正计数可能出现的偏差
+func GetGroupMemberCount(_type, id string) (count int64, err error) {
+	key := SubjectIDCacheKey{
+		Type: _type,
+		ID:   id,
+	}
+
+	values, err := GroupMemberCountCache.BatchGet([]cache.Key{key})
+	if err != nil {
+		log.WithError(err).Errorf("GroupMemberCountCache.BatchGet key=`%s` fail", key.Key())
+	} else if value, ok := values[key]; ok {
+		count, err = strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			return count, nil
+		}
+		log.WithError(err).Errorf("GroupMemberCountCache parse value=`%s` of key=`%s` fail", value, key.Key())
+	}
+
+	// NOTE: 大用户组的计数过期时, 合并并发的重新统计
+	value, err, _ := GroupMemberCountCache.G.Do(key.Key(), func() (interface{}, error) {
+		svc := service.NewSubjectMemberReadService()
+		count, err := svc.GetMemberCount(_type, id)
+		if err != nil {
+			return nil, err
+		}
+
+		errNotImportant := GroupMemberCountCache.BatchSetWithTx([]redis.KV{{
+			Key:   key.Key(),
+			Value: strconv.FormatInt(count, 10),
+		}}, GroupMemberCountExpiration)
+		if errNotImportant != nil {
+			log.WithError(errNotImportant).Errorf("GroupMemberCountCache.BatchSetWithTx key=`%s` fail", key.Key())
+		}
+		return count, nil
+	})
+	if err != nil {
+		err = errorx.Wrapf(err, CacheLayer, "GetGroupMemberCount",
+			"svc.GetMemberCount _type=`%s`, id=`%s` fail", _type, id)
+		return
+	}
+	return value.(int64), nil
+}
+
+// AdjustGroupMemberCount 成员增删后调整用户组的成员数量, 未缓存的不处理
+func AdjustGroupMemberCount(_type, id string, delta int64) {
+	key := SubjectIDCacheKey{
+		Type: _type,
+		ID:   id,
+	}
+
+	err := GroupMemberCountCache.IncrByIfExists(key, delta)
+	if err != nil {
+		log.WithError(err).Errorf("GroupMemberCountCache.IncrByIfExists key=`%s`, delta=`%d` fail, will delete it",
+			key.Key(), delta)
+		// 调整失败则删除, 下次查询时重新统计
+		DeleteGroupMemberCount(_type, id)
+	}
+}
+
+// DeleteGroupMemberCount ...
+func DeleteGroupMemberCount(_type, id string) error {
+	key := SubjectIDCacheKey{
+		Type: _type,
+		ID:   id,
+	}
+	return GroupMemberCountCache.Delete(key)
+}
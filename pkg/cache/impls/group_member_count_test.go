@@ -0,0 +1,64 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package impls
+
+import (
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache/redis"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+)
+
+func TestGroupMemberCount(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	// only retrieve from db once, then adjust the counter in cache
+	mockService := mock.NewMockSubjectMemberReadService(ctl)
+	mockService.EXPECT().GetMemberCount("group", "1").Return(int64(10), nil).Times(1)
+
+	patches := gomonkey.ApplyFunc(service.NewSubjectMemberReadService,
+		func() service.SubjectMemberReadService {
+			return mockService
+		})
+	defer patches.Reset()
+
+	GroupMemberCountCache = redis.NewMockCache("mockCache", 5*time.Minute)
+
+	// not cached, do nothing
+	AdjustGroupMemberCount("group", "1", 1)
+
+	count, err := GetGroupMemberCount("group", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), count)
+
+	AdjustGroupMemberCount("group", "1", 3)
+	AdjustGroupMemberCount("group", "1", -1)
+
+	count, err = GetGroupMemberCount("group", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), count)
+
+	// deleted, retrieve from db again
+	mockService.EXPECT().GetMemberCount("group", "1").Return(int64(11), nil).Times(1)
+	err = DeleteGroupMemberCount("group", "1")
+	assert.NoError(t, err)
+
+	count, err = GetGroupMemberCount("group", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), count)
+}
@@ -0,0 +1,148 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service/types"
+)
+
+/*
+ * > 记录鉴权流量中的热点subject, 用于服务启动时的缓存预热
+ *
+ * 1. 鉴权时在本地累加subject的请求次数(只有开启预热时才记录)
+ * 2. 定时将本地的计数写入redis中按天分桶的sorted set, 保留3天
+ * 3. 服务启动预热时, 读取今天(不足时补充昨天)请求次数最多的topN subject
+ */
+
+var (
+	hotSubjectFlushInterval = 1 * time.Minute
+	hotSubjectKeyExpiration = 3 * 24 * time.Hour
+	// 每个周期本地最多记录的subject数量, 避免内存无限增长
+	hotSubjectMaxLocalCount = 10000
+)
+
+var (
+	hotSubjectRecordEnabled bool
+	hotSubjectCounts        = map[string]float64{}
+	hotSubjectLock          sync.Mutex
+)
+
+// EnableHotSubjectRecord 开启热点subject的记录
+func EnableHotSubjectRecord() {
+	hotSubjectRecordEnabled = true
+}
+
+// RecordHotSubject 在本地累加subject的请求次数, 未开启时不做任何事
+func RecordHotSubject(_type, id string) {
+	if !hotSubjectRecordEnabled {
+		return
+	}
+
+	member := _type + ":" + id
+
+	hotSubjectLock.Lock()
+	if _, ok := hotSubjectCounts[member]; ok || len(hotSubjectCounts) < hotSubjectMaxLocalCount {
+		hotSubjectCounts[member]++
+	}
+	hotSubjectLock.Unlock()
+}
+
+func hotSubjectKey(t time.Time) string {
+	return "sub:" + t.Format("20060102")
+}
+
+// FlushHotSubjects 将本地的计数写入redis, 并清空本地的计数
+func FlushHotSubjects() error {
+	hotSubjectLock.Lock()
+	counts := hotSubjectCounts
+	hotSubjectCounts = make(map[string]float64, len(counts))
+	hotSubjectLock.Unlock()
+
+	if len(counts) == 0 {
+		return nil
+	}
+
+	key := hotSubjectKey(time.Now())
+	err := HotSubjectCache.ZIncrByWithExpire(key, counts, hotSubjectKeyExpiration)
+	if err != nil {
+		return errorx.Wrapf(err, CacheLayer, "FlushHotSubjects",
+			"HotSubjectCache.ZIncrByWithExpire key=`%s`, count=`%d` fail", key, len(counts))
+	}
+	return nil
+}
+
+// RunHotSubjectRecorder 定时将本地的计数写入redis, 阻塞直到ctx结束, 结束前会写入剩余的计数
+func RunHotSubjectRecorder(ctx context.Context) {
+	ticker := time.NewTicker(hotSubjectFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := FlushHotSubjects(); err != nil {
+				log.WithError(err).Error("flush hot subjects fail")
+			}
+			return
+		case <-ticker.C:
+			if err := FlushHotSubjects(); err != nil {
+				log.WithError(err).Error("flush hot subjects fail")
+			}
+		}
+	}
+}
+
+// ListHotSubjects 查询最近请求次数最多的topN subject
+func ListHotSubjects(n int) ([]types.Subject, error) {
+	subjects := make([]types.Subject, 0, n)
+	if n <= 0 {
+		return subjects, nil
+	}
+
+	now := time.Now()
+	seen := make(map[string]struct{}, n)
+	for _, day := range []time.Time{now, now.AddDate(0, 0, -1)} {
+		if len(subjects) >= n {
+			break
+		}
+
+		key := hotSubjectKey(day)
+		members, err := HotSubjectCache.ZRevRange(key, 0, int64(n-1))
+		if err != nil {
+			return nil, errorx.Wrapf(err, CacheLayer, "ListHotSubjects",
+				"HotSubjectCache.ZRevRange key=`%s`, n=`%d` fail", key, n)
+		}
+
+		for _, member := range members {
+			if _, ok := seen[member]; ok {
+				continue
+			}
+			seen[member] = struct{}{}
+
+			parts := strings.SplitN(member, ":", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			subjects = append(subjects, types.Subject{Type: parts[0], ID: parts[1]})
+		}
+	}
+
+	if len(subjects) > n {
+		subjects = subjects[:n]
+	}
+	return subjects, nil
+}
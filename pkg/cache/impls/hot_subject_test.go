@@ -0,0 +1,76 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache/redis"
+	"iam/pkg/service/types"
+)
+
+func TestHotSubjects(t *testing.T) {
+	HotSubjectCache = redis.NewMockCache("test", 0)
+	defer func() {
+		hotSubjectRecordEnabled = false
+		hotSubjectCounts = map[string]float64{}
+	}()
+
+	// not enabled, do nothing
+	RecordHotSubject("user", "admin")
+	assert.Empty(t, hotSubjectCounts)
+
+	EnableHotSubjectRecord()
+	RecordHotSubject("user", "admin")
+	RecordHotSubject("user", "tom")
+	RecordHotSubject("user", "tom")
+	assert.NoError(t, FlushHotSubjects())
+	assert.Empty(t, hotSubjectCounts)
+
+	// the yesterday's hot subjects
+	err := HotSubjectCache.ZIncrByWithExpire(hotSubjectKey(time.Now().AddDate(0, 0, -1)),
+		map[string]float64{"user:admin": 10, "user:jerry": 5}, time.Minute)
+	assert.NoError(t, err)
+
+	subjects, err := ListHotSubjects(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Subject{{Type: "user", ID: "tom"}}, subjects)
+
+	subjects, err = ListHotSubjects(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Subject{
+		{Type: "user", ID: "tom"},
+		{Type: "user", ID: "admin"},
+		{Type: "user", ID: "jerry"},
+	}, subjects)
+
+	subjects, err = ListHotSubjects(0)
+	assert.NoError(t, err)
+	assert.Empty(t, subjects)
+}
+
+func TestRecordHotSubjectMaxLocalCount(t *testing.T) {
+	hotSubjectRecordEnabled = true
+	old := hotSubjectMaxLocalCount
+	hotSubjectMaxLocalCount = 1
+	defer func() {
+		hotSubjectRecordEnabled = false
+		hotSubjectMaxLocalCount = old
+		hotSubjectCounts = map[string]float64{}
+	}()
+
+	RecordHotSubject("user", "admin")
+	RecordHotSubject("user", "tom")
+	RecordHotSubject("user", "admin")
+	assert.Equal(t, map[string]float64{"user:admin": 2}, hotSubjectCounts)
+}
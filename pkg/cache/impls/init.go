@@ -12,6 +12,7 @@ package impls
 
 import (
 	"errors"
+	"sync"
 	"time"
 
 	gocache "github.com/patrickmn/go-cache"
@@ -21,6 +22,7 @@ import (
 	"iam/pkg/cache/cleaner"
 	"iam/pkg/cache/memory"
 	"iam/pkg/cache/redis"
+	"iam/pkg/service"
 )
 
 // CacheLayer ...
@@ -28,24 +30,32 @@ const CacheLayer = "Cache"
 
 // LocalAppCodeAppSecretCache ...
 var (
-	LocalAppCodeAppSecretCache      memory.Cache
-	LocalSubjectCache               memory.Cache
-	LocalSubjectRoleCache           memory.Cache
-	LocalSystemClientsCache         memory.Cache
-	LocalRemoteResourceListCache    memory.Cache
-	LocalSubjectPKCache             memory.Cache
-	LocalAPIGatewayJWTClientIDCache memory.Cache
-	LocalActionCache                memory.Cache // for iam engine
-	LocalUnmarshaledExpressionCache memory.Cache
-
-	RemoteResourceCache *redis.Cache
-	ResourceTypeCache   *redis.Cache
-	SubjectGroupCache   *redis.Cache
-	SubjectDetailCache  *redis.Cache
-	SubjectPKCache      *redis.Cache
-	SystemCache         *redis.Cache
-	ActionPKCache       *redis.Cache
-	ActionDetailCache   *redis.Cache
+	LocalAppCodeAppSecretCache          memory.Cache
+	LocalSubjectCache                   memory.Cache
+	LocalSubjectRoleCache               memory.Cache
+	LocalSubjectGroupCache              memory.Cache
+	LocalSystemClientsCache             memory.Cache
+	LocalRemoteResourceListCache        memory.Cache
+	LocalSubjectPKCache                 memory.Cache
+	LocalAPIGatewayJWTClientIDCache     memory.Cache
+	LocalActionCache                    memory.Cache // for iam engine
+	LocalUnmarshaledExpressionCache     memory.Cache
+	LocalResourceAttributeSchemaCache   memory.Cache
+	LocalSystemActionIndexCache         memory.Cache
+	LocalSystemDisabledActionsCache     memory.Cache
+	LocalSystemPolicyCacheBackendCache  memory.Cache
+	LocalSubjectAttributeProvidersCache memory.Cache
+	LocalFrozenSubjectPKsCache          memory.Cache
+
+	RemoteResourceCache   *redis.Cache
+	ResourceTypeCache     *redis.Cache
+	SubjectGroupCache     *redis.Cache
+	SubjectDetailCache    *redis.Cache
+	SubjectPKCache        *redis.Cache
+	SubjectAttributeCache *redis.Cache
+	SystemCache           *redis.Cache
+	ActionPKCache         *redis.Cache
+	ActionDetailCache     *redis.Cache
 
 	PolicyCache     *redis.Cache
 	ExpressionCache *redis.Cache
@@ -54,12 +64,35 @@ var (
 	LocalExpressionCache *gocache.Cache
 	ChangeListCache      *redis.Cache
 
+	TemplateUnbindTaskCache *redis.Cache
+	ExportTaskCache         *redis.Cache
+	SystemCleanupTaskCache  *redis.Cache
+	CensusTaskCache         *redis.Cache
+	TaskLockCache           *redis.Cache
+
+	// NOTE: the hot subjects in sorted sets, use ZIncrByWithExpire/ZRevRange instead of Get/Set
+	HotSubjectCache *redis.Cache
+
+	// NOTE: the frozen systems in a hash without expiration, use HSet/HDel/HGetAll instead of Get/Set
+	SystemFreezeCache *redis.Cache
+
+	// NOTE: the subject groups of each system in a hash, use BatchHGet/BatchHSetWithTx instead of Get/Set
+	SubjectSystemGroupCache *redis.Cache
+
+	// NOTE: the values are raw counters, use BatchGet/BatchSetWithTx instead of Get/Set
+	GroupMemberCountCache *redis.Cache
+
+	// NOTE: only for the pub/sub channels, use Publish/Subscribe instead of Get/Set
+	PubSubCache *redis.Cache
+
 	ActionCacheCleaner       *cleaner.CacheCleaner
 	ResourceTypeCacheCleaner *cleaner.CacheCleaner
 	SubjectCacheCleaner      *cleaner.CacheCleaner
 	SystemCacheCleaner       *cleaner.CacheCleaner
 )
 
+var subjectChangeHandlerRegisterOnce sync.Once
+
 // ErrNotExceptedTypeFromCache ...
 var ErrNotExceptedTypeFromCache = errors.New("not expected type from cache")
 
@@ -84,7 +117,15 @@ func InitCaches(disabled bool) {
 		"local_subject_role",
 		disabled,
 		retrieveSubjectRole,
-		1*time.Minute,
+		LocalSubjectRoleCacheExpiration,
+	)
+
+	// the groups of departments, short expiration for the repeated reads of the users in the same department
+	LocalSubjectGroupCache = memory.NewCache(
+		"local_subject_group",
+		disabled,
+		retrieveLocalSubjectGroups,
+		10*time.Second,
 	)
 
 	LocalRemoteResourceListCache = memory.NewCache(
@@ -97,8 +138,8 @@ func InitCaches(disabled bool) {
 	LocalSubjectPKCache = memory.NewCache(
 		"local_subject_pk",
 		disabled,
-		retrieveSubjectPK,
-		1*time.Minute,
+		retrieveLocalSubjectPK,
+		LocalSubjectPKCacheExpiration,
 	)
 
 	LocalSystemClientsCache = memory.NewCache(
@@ -129,6 +170,48 @@ func InitCaches(disabled bool) {
 		30*time.Minute,
 	)
 
+	LocalResourceAttributeSchemaCache = memory.NewCache(
+		"local_resource_attribute_schema",
+		disabled,
+		retrieveResourceAttributeSchemas,
+		1*time.Minute,
+	)
+
+	LocalSystemActionIndexCache = memory.NewCache(
+		"local_system_action_index",
+		disabled,
+		retrieveSystemActionIndex,
+		1*time.Minute,
+	)
+
+	LocalSystemDisabledActionsCache = memory.NewCache(
+		"local_system_disabled_actions",
+		disabled,
+		retrieveSystemDisabledActions,
+		1*time.Minute,
+	)
+
+	LocalSystemPolicyCacheBackendCache = memory.NewCache(
+		"local_system_policy_cache_backend",
+		disabled,
+		retrieveSystemPolicyCacheBackend,
+		1*time.Minute,
+	)
+
+	LocalSubjectAttributeProvidersCache = memory.NewCache(
+		"local_subject_attribute_providers",
+		disabled,
+		retrieveSubjectAttributeProviders,
+		1*time.Minute,
+	)
+
+	LocalFrozenSubjectPKsCache = memory.NewCache(
+		localFrozenSubjectPKsCacheName,
+		disabled,
+		retrieveFrozenSubjectPKs,
+		1*time.Minute,
+	)
+
 	//  ==========================
 
 	// NOTE: short key in 3 chars, make the redis key short enough, for better performance
@@ -143,6 +226,19 @@ func InitCaches(disabled bool) {
 	//     ex  = expression
 	//     cl = change list
 	//     grp = group
+	//     tpl = template
+	//     ubd = unbind
+	//     mbr = member
+	//     cnt = count
+	//     exp = export
+	//     tsk = task
+	//     frz = freeze
+	//     cln = cleanup
+	//     pub = publish/subscribe
+	//     ivd = invalidation
+	//     cns = census
+	//     atr = attribute
+	//     lck = lock
 
 	// inner system model
 	SystemCache = redis.NewCache(
@@ -185,6 +281,23 @@ func InitCaches(disabled bool) {
 		30*time.Minute,
 	)
 
+	// the groups which have policies in the system of the subjects
+	SubjectSystemGroupCache = redis.NewCache(
+		"sub_sys_grp",
+		SubjectSystemGroupCacheExpiration,
+	)
+
+	// the attributes of the subjects provided by the http providers, the expiration can be configured by the provider
+	SubjectAttributeCache = redis.NewCache(
+		"sub_atr",
+		5*time.Minute,
+	)
+
+	GroupMemberCountCache = redis.NewCache(
+		"grp_mbr_cnt",
+		GroupMemberCountExpiration,
+	)
+
 	LocalPolicyCache = gocache.New(5*time.Minute, 5*time.Minute)
 	LocalExpressionCache = gocache.New(5*time.Minute, 5*time.Minute)
 	ChangeListCache = redis.NewCache("cl", 5*time.Minute)
@@ -199,6 +312,52 @@ func InitCaches(disabled bool) {
 		30*time.Minute,
 	)
 
+	// the progress of async template unbinding, keep for query after finished
+	TemplateUnbindTaskCache = redis.NewCache(
+		"tpl_ubd",
+		24*time.Hour,
+	)
+
+	// the progress and the result files of the export task
+	ExportTaskCache = redis.NewCache(
+		"exp_tsk",
+		7*24*time.Hour,
+	)
+
+	// the progress of the system cleanup task, for system offboarding
+	SystemCleanupTaskCache = redis.NewCache(
+		"sys_cln_tsk",
+		7*24*time.Hour,
+	)
+
+	// the progress and the result of the offline census task
+	CensusTaskCache = redis.NewCache(
+		"cns_tsk",
+		7*24*time.Hour,
+	)
+
+	// the locks of the scheduled tasks, make sure only one instance run the task in a period
+	TaskLockCache = redis.NewCache(
+		"tsk_lck",
+		1*time.Hour,
+	)
+
+	// the hot subjects by recent auth traffic, for warming up the caches on startup
+	HotSubjectCache = redis.NewCache(
+		"hot",
+		0,
+	)
+
+	SystemFreezeCache = redis.NewCache(
+		"sys_frz",
+		0,
+	)
+
+	PubSubCache = redis.NewCache(
+		"pub",
+		0,
+	)
+
 	ActionCacheCleaner = cleaner.NewCacheCleaner("ActionCacheCleaner", actionCacheDeleter{})
 	go ActionCacheCleaner.Run()
 
@@ -210,14 +369,38 @@ func InitCaches(disabled bool) {
 
 	SystemCacheCleaner = cleaner.NewCacheCleaner("SystemCacheCleaner", systemCacheDeleter{})
 	go SystemCacheCleaner.Run()
+
+	// 注册到缓存注册表, 用于缓存的查看与清理
+	registerCaches()
+
+	// subject的写操作成功后, 清理读侧的缓存
+	subjectChangeHandlerRegisterOnce.Do(func() {
+		service.RegisterSubjectChangeHandler(handleSubjectChangeEvent)
+	})
 }
 
+// SubjectSystemGroupCacheExpiration subject在系统下有策略的用户组的缓存时间
+var SubjectSystemGroupCacheExpiration = 30 * time.Minute
+
+// GroupMemberCountExpiration 用户组成员数量的缓存时间, 即计数从DB重新统计的周期
+var GroupMemberCountExpiration = 10 * time.Minute
+
+// LocalSubjectPKCacheExpiration subject pk的本地缓存时间
+var LocalSubjectPKCacheExpiration = 1 * time.Minute
+
+// LocalSubjectRoleCacheExpiration subject角色(超级管理员/系统管理员)的本地缓存时间
+var LocalSubjectRoleCacheExpiration = 1 * time.Minute
+
 // PolicyCacheDisabled 策略缓存默认打开
 var PolicyCacheDisabled = false
 
 // PolicyCacheExpiration 策略缓存默认保留7天
 var PolicyCacheExpiration = 7 * 24 * time.Hour
 
+// PolicyCacheEmptyExpiration 空策略列表/不存在的表达式的缓存时间
+// NOTE: 避免不存在的数据反复查询DB, 同时不长时间保留; 策略/表达式变更时会主动清理
+var PolicyCacheEmptyExpiration = 1 * time.Minute
+
 // InitPolicyCacheSettings ...
 func InitPolicyCacheSettings(disabled bool, expirationDays int64) {
 	PolicyCacheDisabled = disabled
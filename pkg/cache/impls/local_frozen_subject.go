@@ -0,0 +1,68 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"errors"
+
+	"iam/pkg/cache"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/util"
+)
+
+/*
+ * > 鉴权时需要判断subject是否被冻结, 被冻结的subject数量很少, 全部缓存在本地
+ *
+ * 1. 冻结/解冻成功后, 清理本实例的缓存, 并通过 redis pub/sub 广播给其他实例清理
+ * 2. 广播失败时, 其他实例在缓存时间之内不生效, 过期后重新查询
+ *
+ * 当前设置的缓存时间: 1min
+ */
+
+const (
+	localFrozenSubjectPKsCacheName = "local_frozen_subject_pks"
+	// frozenSubjectPKsKey 所有冻结的subject缓存在同一个key下
+	frozenSubjectPKsKey = "all"
+)
+
+func retrieveFrozenSubjectPKs(k cache.Key) (interface{}, error) {
+	svc := service.NewSubjectBaseReadService()
+	pks, err := svc.ListFrozenPKs()
+	if err != nil {
+		return nil, err
+	}
+	return util.NewInt64SetWithValues(pks), nil
+}
+
+// IsSubjectFrozen subject是否被冻结
+func IsSubjectFrozen(pk int64) (bool, error) {
+	key := cache.NewStringKey(frozenSubjectPKsKey)
+
+	value, err := LocalFrozenSubjectPKsCache.Get(key)
+	if err != nil {
+		return false, errorx.Wrapf(err, CacheLayer, "IsSubjectFrozen",
+			"LocalFrozenSubjectPKsCache.Get key=`%s` fail", key.Key())
+	}
+
+	pks, ok := value.(*util.Int64Set)
+	if !ok {
+		err = errors.New("not *util.Int64Set in cache")
+		return false, errorx.Wrapf(err, CacheLayer, "IsSubjectFrozen",
+			"LocalFrozenSubjectPKsCache.Get key=`%s` fail", key.Key())
+	}
+	return pks.Has(pk), nil
+}
+
+// DeleteFrozenSubjectPKs 清理所有实例的冻结subject缓存
+func DeleteFrozenSubjectPKs() error {
+	_, err := FlushCaches([]string{localFrozenSubjectPKsCacheName}, FlushScopeAll, "")
+	return err
+}
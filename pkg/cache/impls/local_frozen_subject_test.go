@@ -0,0 +1,83 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache"
+	"iam/pkg/cache/memory"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	"iam/pkg/util"
+)
+
+func TestIsSubjectFrozen(t *testing.T) {
+	expiration := 5 * time.Minute
+
+	// valid
+	retrieveFunc := func(key cache.Key) (interface{}, error) {
+		return util.NewInt64SetWithValues([]int64{1}), nil
+	}
+	LocalFrozenSubjectPKsCache = memory.NewCache("mockCache", false, retrieveFunc, expiration)
+
+	frozen, err := IsSubjectFrozen(1)
+	assert.NoError(t, err)
+	assert.True(t, frozen)
+
+	frozen, err = IsSubjectFrozen(2)
+	assert.NoError(t, err)
+	assert.False(t, frozen)
+
+	// invalid type
+	retrieveFunc = func(key cache.Key) (interface{}, error) {
+		return "abc", nil
+	}
+	LocalFrozenSubjectPKsCache = memory.NewCache("mockCache", false, retrieveFunc, expiration)
+
+	_, err = IsSubjectFrozen(1)
+	assert.Error(t, err)
+
+	// error
+	retrieveFunc = func(key cache.Key) (interface{}, error) {
+		return nil, errors.New("error here")
+	}
+	LocalFrozenSubjectPKsCache = memory.NewCache("mockCache", false, retrieveFunc, expiration)
+
+	_, err = IsSubjectFrozen(1)
+	assert.Error(t, err)
+}
+
+func TestRetrieveFrozenSubjectPKs(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockService := mock.NewMockSubjectBaseReadService(ctl)
+	mockService.EXPECT().ListFrozenPKs().Return([]int64{1, 2}, nil)
+
+	patches := gomonkey.ApplyFunc(service.NewSubjectBaseReadService, func() service.SubjectBaseReadService {
+		return mockService
+	})
+	defer patches.Reset()
+
+	value, err := retrieveFrozenSubjectPKs(cache.NewStringKey(frozenSubjectPKsKey))
+	assert.NoError(t, err)
+	pks, ok := value.(*util.Int64Set)
+	assert.True(t, ok)
+	assert.True(t, pks.Has(1))
+	assert.True(t, pks.Has(2))
+	assert.False(t, pks.Has(3))
+}
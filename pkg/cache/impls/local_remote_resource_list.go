@@ -11,6 +11,7 @@
 package impls
 
 import (
+	"context"
 	"errors"
 	"sort"
 	"strings"
@@ -29,6 +30,9 @@ type RemoteResourceListCacheKey struct {
 	IDs string
 	// a;b;c
 	Fields string
+
+	// Ctx the context of the incoming request, not a part of the key
+	Ctx context.Context
 }
 
 // Key ...
@@ -47,7 +51,7 @@ func retrieveRemoteResourceList(k cache.Key) (interface{}, error) {
 	systemID := k1.System
 	_type := k1.Type
 
-	resources, err := listRemoteResources(systemID, _type, ids, fields)
+	resources, err := listRemoteResources(k1.Ctx, systemID, _type, ids, fields)
 	if err != nil {
 		err = errorWrapf(err,
 			"pip.ListRemoteResources systemID=`%s`, resourceTypeID=`%s`, resourceIDs=`%+v`, fields=`%s` fail",
@@ -58,9 +62,18 @@ func retrieveRemoteResourceList(k cache.Key) (interface{}, error) {
 }
 
 // listRemoteResources 批量获取资源的属性信息, without cache
-func listRemoteResources(systemID, _type string, ids []string, fields []string) ([]map[string]interface{}, error) {
+func listRemoteResources(
+	ctx context.Context,
+	systemID, _type string,
+	ids []string,
+	fields []string,
+) ([]map[string]interface{}, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(CacheLayer, "listRemoteResources")
 
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	// 1. get system and resourceType
 	system, err := GetSystem(systemID)
 	if err != nil {
@@ -80,7 +93,7 @@ func listRemoteResources(systemID, _type string, ids []string, fields []string)
 		return nil, err
 	}
 
-	resources, err := component.BKRemoteResource.GetResources(req, systemID, _type, ids, fields)
+	resources, err := component.BKRemoteResource.GetResources(ctx, req, systemID, _type, ids, fields)
 	if err != nil {
 		err = errorWrapf(
 			err, "BKRemoteResource.GetResource systemID=`%s`, resourceTypeID=`%s`, ids length=`%d`, fields=`%s` fail",
@@ -93,6 +106,7 @@ func listRemoteResources(systemID, _type string, ids []string, fields []string)
 
 // ListRemoteResources ...
 func ListRemoteResources(
+	ctx context.Context,
 	system string,
 	_type string,
 	ids []string,
@@ -114,6 +128,7 @@ func ListRemoteResources(
 		Type:   _type,
 		IDs:    i,
 		Fields: f,
+		Ctx:    ctx,
 	}
 
 	var value interface{}
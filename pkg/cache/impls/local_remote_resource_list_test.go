@@ -11,6 +11,7 @@
 package impls
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -46,7 +47,7 @@ func TestListRemoteResources(t *testing.T) {
 		"mockCache", false, retrieveFunc, expiration)
 	LocalRemoteResourceListCache = mockCache
 
-	_, err := ListRemoteResources("test", "app", []string{"1", "2"}, []string{"id", "name"})
+	_, err := ListRemoteResources(context.Background(), "test", "app", []string{"1", "2"}, []string{"id", "name"})
 	assert.NoError(t, err)
 
 	// error
@@ -57,6 +58,6 @@ func TestListRemoteResources(t *testing.T) {
 		"mockCache", false, retrieveFunc, expiration)
 	LocalRemoteResourceListCache = mockCache
 
-	_, err = ListRemoteResources("test", "app", []string{"1", "2"}, []string{"id", "name"})
+	_, err = ListRemoteResources(context.Background(), "test", "app", []string{"1", "2"}, []string{"id", "name"})
 	assert.Error(t, err)
 }
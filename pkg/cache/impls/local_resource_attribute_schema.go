@@ -0,0 +1,87 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package impls
+
+import (
+	"database/sql"
+	"errors"
+
+	"iam/pkg/cache"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+)
+
+/*
+ * > 资源属性schema仅在鉴权debug时用于校验接入系统传入的资源属性, 注册后基本不会变更
+ *
+ * 处理: 变成local-cache, 1分钟生效
+ *
+ * 当前设置的缓存时间: 1min
+ */
+
+// ResourceAttributeSchemas {resource_type_id: {attribute_name: attribute_type}}
+type ResourceAttributeSchemas map[string]map[string]string
+
+func retrieveResourceAttributeSchemas(k cache.Key) (interface{}, error) {
+	k1 := k.(cache.StringKey)
+
+	systemID := k1.Key()
+
+	svc := service.NewSystemConfigService()
+	data, err := svc.GetResourceAttributeSchemas(systemID)
+	// 系统未注册schema, 不校验
+	if errors.Is(err, sql.ErrNoRows) {
+		return ResourceAttributeSchemas{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make(ResourceAttributeSchemas, len(data))
+	for resourceTypeID, attrs := range data {
+		attrMap, ok := attrs.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		schema := make(map[string]string, len(attrMap))
+		for name, _type := range attrMap {
+			if t, ok := _type.(string); ok {
+				schema[name] = t
+			}
+		}
+		schemas[resourceTypeID] = schema
+	}
+	return schemas, nil
+}
+
+// GetResourceAttributeSchemas ...
+func GetResourceAttributeSchemas(systemID string) (schemas ResourceAttributeSchemas, err error) {
+	key := cache.NewStringKey(systemID)
+
+	var value interface{}
+	value, err = LocalResourceAttributeSchemaCache.Get(key)
+	if err != nil {
+		err = errorx.Wrapf(err, CacheLayer, "GetResourceAttributeSchemas",
+			"LocalResourceAttributeSchemaCache.Get key=`%s` fail", key.Key())
+		return
+	}
+
+	var ok bool
+	schemas, ok = value.(ResourceAttributeSchemas)
+	if !ok {
+		err = errors.New("not ResourceAttributeSchemas in cache")
+		err = errorx.Wrapf(err, CacheLayer, "GetResourceAttributeSchemas",
+			"LocalResourceAttributeSchemaCache.Get systemID=`%s` fail", systemID)
+		return
+	}
+	return schemas, nil
+}
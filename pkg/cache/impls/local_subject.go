@@ -20,7 +20,7 @@ import (
 
 func retrieveSubject(key cache.Key) (interface{}, error) {
 	k := key.(SubjectPKCacheKey)
-	svc := service.NewSubjectService()
+	svc := service.NewSubjectBaseReadService()
 	return svc.Get(k.PK)
 }
 
@@ -0,0 +1,82 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"errors"
+	"fmt"
+
+	"iam/pkg/cache"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	svctypes "iam/pkg/service/types"
+)
+
+/*
+ * > 鉴权时需要查询外部的subject属性提供方
+ *
+ * 1. 提供方的变更(创建/更新/删除)成功后, 清理本实例的缓存
+ * 2. 其他实例的变更, 在缓存时间之内不生效, 过期后重新查询
+ *
+ * 当前设置的缓存时间: 1min
+ */
+
+// subjectAttributeProvidersKey 所有的提供方缓存在同一个key下
+const subjectAttributeProvidersKey = "all"
+
+func retrieveSubjectAttributeProviders(k cache.Key) (interface{}, error) {
+	svc := service.NewSubjectAttributeProviderService()
+	return svc.ListAll()
+}
+
+// ListSubjectAttributeProviders 获取所有的subject属性提供方
+// NOTE: 列表在多个请求间共享, 只读, 不能修改
+func ListSubjectAttributeProviders() (providers []svctypes.SubjectAttributeProvider, err error) {
+	key := cache.NewStringKey(subjectAttributeProvidersKey)
+
+	var value interface{}
+	value, err = LocalSubjectAttributeProvidersCache.Get(key)
+	if err != nil {
+		err = errorx.Wrapf(err, CacheLayer, "ListSubjectAttributeProviders",
+			"LocalSubjectAttributeProvidersCache.Get key=`%s` fail", key.Key())
+		return
+	}
+
+	var ok bool
+	providers, ok = value.([]svctypes.SubjectAttributeProvider)
+	if !ok {
+		err = errors.New("not []types.SubjectAttributeProvider in cache")
+		err = errorx.Wrapf(err, CacheLayer, "ListSubjectAttributeProviders",
+			"LocalSubjectAttributeProvidersCache.Get key=`%s` fail", key.Key())
+		return
+	}
+
+	return providers, nil
+}
+
+// GetSubjectAttributeProvider 获取指定的subject属性提供方
+func GetSubjectAttributeProvider(id string) (provider svctypes.SubjectAttributeProvider, err error) {
+	providers, err := ListSubjectAttributeProviders()
+	if err != nil {
+		return
+	}
+
+	for _, p := range providers {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return provider, fmt.Errorf("subject attribute provider %s not exists", id)
+}
+
+// DeleteSubjectAttributeProviders ...
+func DeleteSubjectAttributeProviders() error {
+	return LocalSubjectAttributeProvidersCache.Delete(cache.NewStringKey(subjectAttributeProvidersKey))
+}
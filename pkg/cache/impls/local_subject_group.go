@@ -0,0 +1,69 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package impls
+
+import (
+	"iam/pkg/cache"
+	"iam/pkg/service/types"
+)
+
+// NOTE: 本地缓存的过期时间很短, 只用于合并短时间内同一部门的重复读取(一个部门下的大量用户同时鉴权)
+//       成员变更时只清理当前实例的本地缓存, 其他实例等待过期即可
+
+func retrieveLocalSubjectGroups(key cache.Key) (interface{}, error) {
+	k := key.(SubjectPKCacheKey)
+	return GetSubjectGroups(k.PK)
+}
+
+// batchGetLocalSubjectGroups 从本地缓存获取subject的用户组, 返回命中的 pk => 用户组 及未命中的pks
+func batchGetLocalSubjectGroups(
+	pks []int64,
+) (subjectGroups map[int64][]types.ThinSubjectGroup, notCachedPKs []int64) {
+	if LocalSubjectGroupCache.Disabled() {
+		return map[int64][]types.ThinSubjectGroup{}, pks
+	}
+
+	subjectGroups = make(map[int64][]types.ThinSubjectGroup, len(pks))
+	notCachedPKs = make([]int64, 0, len(pks))
+	for _, pk := range pks {
+		value, found := LocalSubjectGroupCache.DirectGet(SubjectPKCacheKey{PK: pk})
+		if !found {
+			notCachedPKs = append(notCachedPKs, pk)
+			continue
+		}
+
+		sgs, ok := value.([]types.ThinSubjectGroup)
+		if !ok {
+			notCachedPKs = append(notCachedPKs, pk)
+			continue
+		}
+		subjectGroups[pk] = sgs
+	}
+	return subjectGroups, notCachedPKs
+}
+
+// batchSetLocalSubjectGroups 写入本地缓存, NOTE: 缓存的slice不能被修改, 读取时只做append拷贝
+func batchSetLocalSubjectGroups(pkSubjectGroups map[int64][]types.ThinSubjectGroup) {
+	if LocalSubjectGroupCache.Disabled() {
+		return
+	}
+
+	for pk, sgs := range pkSubjectGroups {
+		LocalSubjectGroupCache.Set(SubjectPKCacheKey{PK: pk}, sgs)
+	}
+}
+
+// batchDeleteLocalSubjectGroups ...
+func batchDeleteLocalSubjectGroups(pks []int64) {
+	for _, pk := range pks {
+		LocalSubjectGroupCache.Delete(SubjectPKCacheKey{PK: pk})
+	}
+}
@@ -55,7 +55,7 @@ func retrieveSubjectRole(key cache.Key) (interface{}, error) {
 		return nil, err
 	}
 
-	svc := service.NewSubjectService()
+	svc := service.NewSubjectRoleReadService()
 	return svc.ListRoleSystemIDBySubjectPK(pk)
 }
 
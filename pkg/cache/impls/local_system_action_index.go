@@ -0,0 +1,107 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"errors"
+
+	"iam/pkg/cache"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/util"
+)
+
+/*
+ * > 策略变更(自定义/模板授权)时, 每次都需要查询系统的操作列表以及操作关联的资源类型, 用于校验及转换策略
+ *
+ * 1. 操作模型的写操作(创建/更新/删除)成功后, 通过 service.SystemModelChangeEvent 经统一的失效入口(invalidation)清理
+ *    本实例的索引, 并通过 redis pub/sub 广播给其他实例, 其他实例收到后清理本地的索引
+ * 2. pub/sub 不保证送达, 此时在缓存时间之内, 对应新增的操作会被认为不存在, 过期后重新构建
+ *
+ * 当前设置的缓存时间: 1min
+ */
+
+const (
+	localSystemActionIndexCacheName     = "local_system_action_index"
+	localSystemDisabledActionsCacheName = "local_system_disabled_actions"
+)
+
+// SystemActionIndex 系统的操作索引: 操作ID => 操作PK, 以及关联了资源类型的操作PK集合
+// NOTE: 索引在多个请求间共享, 只读, 不能修改
+type SystemActionIndex struct {
+	ActionPKMap                 map[string]int64
+	ActionPKWithResourceTypeSet *util.Int64Set
+}
+
+func retrieveSystemActionIndex(k cache.Key) (interface{}, error) {
+	k1 := k.(cache.StringKey)
+
+	systemID := k1.Key()
+
+	svc := service.NewActionService()
+	actions, err := svc.ListThinActionBySystem(systemID)
+	if err != nil {
+		return nil, err
+	}
+
+	actionPKMap := make(map[string]int64, len(actions))
+	for _, a := range actions {
+		actionPKMap[a.ID] = a.PK
+	}
+
+	actionResourceTypes, err := svc.ListActionResourceTypeIDByActionSystem(systemID)
+	if err != nil {
+		return nil, err
+	}
+
+	actionPKWithResourceTypeSet := util.NewInt64Set()
+	for _, t := range actionResourceTypes {
+		actionPKWithResourceTypeSet.Add(actionPKMap[t.ActionID])
+	}
+
+	return SystemActionIndex{
+		ActionPKMap:                 actionPKMap,
+		ActionPKWithResourceTypeSet: actionPKWithResourceTypeSet,
+	}, nil
+}
+
+// GetSystemActionIndex ...
+func GetSystemActionIndex(systemID string) (index SystemActionIndex, err error) {
+	key := cache.NewStringKey(systemID)
+
+	var value interface{}
+	value, err = LocalSystemActionIndexCache.Get(key)
+	if err != nil {
+		err = errorx.Wrapf(err, CacheLayer, "GetSystemActionIndex",
+			"LocalSystemActionIndexCache.Get key=`%s` fail", key.Key())
+		return
+	}
+
+	var ok bool
+	index, ok = value.(SystemActionIndex)
+	if !ok {
+		err = errors.New("not SystemActionIndex in cache")
+		err = errorx.Wrapf(err, CacheLayer, "GetSystemActionIndex",
+			"LocalSystemActionIndexCache.Get systemID=`%s` fail", systemID)
+		return
+	}
+
+	return index, nil
+}
+
+// DeleteSystemActionIndex 删除本实例系统的操作索引及禁用的操作, 并广播给其他实例
+func DeleteSystemActionIndex(systemID string) error {
+	key := cache.NewStringKey(systemID)
+	LocalSystemActionIndexCache.Delete(key)
+	LocalSystemDisabledActionsCache.Delete(key)
+
+	return broadcastCacheFlush(
+		[]string{localSystemActionIndexCacheName, localSystemDisabledActionsCacheName}, FlushScopeSystem, systemID)
+}
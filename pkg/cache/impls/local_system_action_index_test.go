@@ -0,0 +1,138 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache"
+	"iam/pkg/cache/memory"
+	"iam/pkg/cache/redis"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestGetSystemActionIndex(t *testing.T) {
+	expiration := 5 * time.Minute
+
+	// valid
+	retrieveFunc := func(key cache.Key) (interface{}, error) {
+		return SystemActionIndex{
+			ActionPKMap:                 map[string]int64{"view": 1},
+			ActionPKWithResourceTypeSet: util.NewInt64SetWithValues([]int64{1}),
+		}, nil
+	}
+	LocalSystemActionIndexCache = memory.NewCache("mockCache", false, retrieveFunc, expiration)
+
+	index, err := GetSystemActionIndex("test")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), index.ActionPKMap["view"])
+	assert.True(t, index.ActionPKWithResourceTypeSet.Has(1))
+
+	// invalid type
+	retrieveFunc = func(key cache.Key) (interface{}, error) {
+		return "abc", nil
+	}
+	LocalSystemActionIndexCache = memory.NewCache("mockCache", false, retrieveFunc, expiration)
+
+	_, err = GetSystemActionIndex("test")
+	assert.Error(t, err)
+
+	// error
+	retrieveFunc = func(key cache.Key) (interface{}, error) {
+		return nil, errors.New("error here")
+	}
+	LocalSystemActionIndexCache = memory.NewCache("mockCache", false, retrieveFunc, expiration)
+
+	_, err = GetSystemActionIndex("test")
+	assert.Error(t, err)
+}
+
+func TestRetrieveSystemActionIndex(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockService := mock.NewMockActionService(ctl)
+	mockService.EXPECT().ListThinActionBySystem("test").Return([]svctypes.ThinAction{
+		{PK: 1, System: "test", ID: "view"},
+		{PK: 2, System: "test", ID: "create"},
+	}, nil)
+	mockService.EXPECT().ListActionResourceTypeIDByActionSystem("test").Return([]svctypes.ActionResourceTypeID{
+		{ActionSystem: "test", ActionID: "view", ResourceTypeSystem: "test", ResourceTypeID: "app"},
+	}, nil)
+
+	patches := gomonkey.ApplyFunc(service.NewActionService, func() service.ActionService {
+		return mockService
+	})
+	defer patches.Reset()
+
+	value, err := retrieveSystemActionIndex(cache.NewStringKey("test"))
+	assert.NoError(t, err)
+
+	index := value.(SystemActionIndex)
+	assert.Equal(t, map[string]int64{"view": 1, "create": 2}, index.ActionPKMap)
+	assert.True(t, index.ActionPKWithResourceTypeSet.Has(1))
+	assert.False(t, index.ActionPKWithResourceTypeSet.Has(2))
+}
+
+func TestDeleteSystemActionIndex(t *testing.T) {
+	var published string
+	PubSubCache = redis.NewMockCache("test", 0)
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(PubSubCache), "Publish",
+		func(_ *redis.Cache, channel string, message string) error {
+			published = message
+			return nil
+		})
+	defer patches.Reset()
+
+	count := 0
+	retrieveFunc := func(key cache.Key) (interface{}, error) {
+		count++
+		return SystemActionIndex{}, nil
+	}
+	LocalSystemActionIndexCache = memory.NewCache("mockCache", false, retrieveFunc, 5*time.Minute)
+
+	disabledCount := 0
+	LocalSystemDisabledActionsCache = memory.NewCache("mockCache", false, func(key cache.Key) (interface{}, error) {
+		disabledCount++
+		return util.NewStringSet(), nil
+	}, 5*time.Minute)
+	_, err := GetSystemDisabledActions("test")
+	assert.NoError(t, err)
+
+	_, err = GetSystemActionIndex("test")
+	assert.NoError(t, err)
+	_, err = GetSystemActionIndex("test")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	err = DeleteSystemActionIndex("test")
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"names": ["local_system_action_index", "local_system_disabled_actions"], "scope": "system", "value": "test"}`,
+		published)
+
+	_, err = GetSystemActionIndex("test")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	_, err = GetSystemDisabledActions("test")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, disabledCount)
+}
@@ -0,0 +1,79 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"errors"
+
+	"iam/pkg/cache"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+/*
+ * > 鉴权时需要判断操作是否被接入系统临时下线(disabled)
+ *
+ * 1. 操作模型的写操作(创建/更新/删除)成功后, 通过 service.SystemModelChangeEvent 清理本实例的缓存
+ * 2. 其他实例的变更, 在缓存时间之内不生效, 过期后重新查询
+ *
+ * 当前设置的缓存时间: 1min
+ */
+
+func retrieveSystemDisabledActions(k cache.Key) (interface{}, error) {
+	k1 := k.(cache.StringKey)
+
+	systemID := k1.Key()
+
+	svc := service.NewActionService()
+	actions, err := svc.ListBySystem(systemID)
+	if err != nil {
+		return nil, err
+	}
+
+	disabledActions := util.NewStringSet()
+	for _, a := range actions {
+		if a.Status == svctypes.ActionStatusDisabled {
+			disabledActions.Add(a.ID)
+		}
+	}
+	return disabledActions, nil
+}
+
+// GetSystemDisabledActions 获取系统中被置为disabled的操作ID集合
+// NOTE: 集合在多个请求间共享, 只读, 不能修改
+func GetSystemDisabledActions(systemID string) (disabledActions *util.StringSet, err error) {
+	key := cache.NewStringKey(systemID)
+
+	var value interface{}
+	value, err = LocalSystemDisabledActionsCache.Get(key)
+	if err != nil {
+		err = errorx.Wrapf(err, CacheLayer, "GetSystemDisabledActions",
+			"LocalSystemDisabledActionsCache.Get key=`%s` fail", key.Key())
+		return
+	}
+
+	var ok bool
+	disabledActions, ok = value.(*util.StringSet)
+	if !ok {
+		err = errors.New("not *util.StringSet in cache")
+		err = errorx.Wrapf(err, CacheLayer, "GetSystemDisabledActions",
+			"LocalSystemDisabledActionsCache.Get systemID=`%s` fail", systemID)
+		return
+	}
+
+	return disabledActions, nil
+}
+
+// DeleteSystemDisabledActions ...
+func DeleteSystemDisabledActions(systemID string) error {
+	return LocalSystemDisabledActionsCache.Delete(cache.NewStringKey(systemID))
+}
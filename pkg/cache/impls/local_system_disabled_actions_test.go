@@ -0,0 +1,84 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache"
+	"iam/pkg/cache/memory"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestGetSystemDisabledActions(t *testing.T) {
+	expiration := 5 * time.Minute
+
+	// valid
+	retrieveFunc := func(key cache.Key) (interface{}, error) {
+		return util.NewStringSetWithValues([]string{"view"}), nil
+	}
+	LocalSystemDisabledActionsCache = memory.NewCache("mockCache", false, retrieveFunc, expiration)
+
+	disabledActions, err := GetSystemDisabledActions("test")
+	assert.NoError(t, err)
+	assert.True(t, disabledActions.Has("view"))
+	assert.False(t, disabledActions.Has("edit"))
+
+	// invalid type
+	retrieveFunc = func(key cache.Key) (interface{}, error) {
+		return "abc", nil
+	}
+	LocalSystemDisabledActionsCache = memory.NewCache("mockCache", false, retrieveFunc, expiration)
+
+	_, err = GetSystemDisabledActions("test")
+	assert.Error(t, err)
+
+	// error
+	retrieveFunc = func(key cache.Key) (interface{}, error) {
+		return nil, errors.New("error here")
+	}
+	LocalSystemDisabledActionsCache = memory.NewCache("mockCache", false, retrieveFunc, expiration)
+
+	_, err = GetSystemDisabledActions("test")
+	assert.Error(t, err)
+}
+
+func TestRetrieveSystemDisabledActions(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockService := mock.NewMockActionService(ctl)
+	mockService.EXPECT().ListBySystem("test").Return([]svctypes.Action{
+		{ID: "view", Status: svctypes.ActionStatusEnabled},
+		{ID: "create", Status: svctypes.ActionStatusDisabled},
+		{ID: "delete"},
+	}, nil)
+
+	patches := gomonkey.ApplyFunc(service.NewActionService, func() service.ActionService {
+		return mockService
+	})
+	defer patches.Reset()
+
+	value, err := retrieveSystemDisabledActions(cache.NewStringKey("test"))
+	assert.NoError(t, err)
+
+	disabledActions := value.(*util.StringSet)
+	assert.Equal(t, 1, disabledActions.Size())
+	assert.True(t, disabledActions.Has("create"))
+}
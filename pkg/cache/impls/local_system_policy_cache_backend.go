@@ -0,0 +1,73 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"database/sql"
+	"errors"
+
+	"iam/pkg/cache"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+)
+
+/*
+ * > 策略缓存的存储方式(memory/redis/both)可以由接入系统通过 system config 设置, 优先于配置文件
+ *
+ * 1. 设置接口调用成功后, 清理本实例的缓存
+ * 2. 其他实例的变更, 在缓存时间之内不生效, 过期后重新查询
+ *
+ * 当前设置的缓存时间: 1min
+ */
+
+func retrieveSystemPolicyCacheBackend(k cache.Key) (interface{}, error) {
+	k1 := k.(cache.StringKey)
+
+	systemID := k1.Key()
+
+	svc := service.NewSystemConfigService()
+	backend, err := svc.GetPolicyCacheBackend(systemID)
+	// 系统未设置, 使用配置文件中的设置
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return backend, nil
+}
+
+// GetSystemPolicyCacheBackend 获取系统设置的策略缓存存储方式, 未设置时返回空
+func GetSystemPolicyCacheBackend(systemID string) (backend string, err error) {
+	key := cache.NewStringKey(systemID)
+
+	var value interface{}
+	value, err = LocalSystemPolicyCacheBackendCache.Get(key)
+	if err != nil {
+		err = errorx.Wrapf(err, CacheLayer, "GetSystemPolicyCacheBackend",
+			"LocalSystemPolicyCacheBackendCache.Get key=`%s` fail", key.Key())
+		return
+	}
+
+	var ok bool
+	backend, ok = value.(string)
+	if !ok {
+		err = errors.New("not string in cache")
+		err = errorx.Wrapf(err, CacheLayer, "GetSystemPolicyCacheBackend",
+			"LocalSystemPolicyCacheBackendCache.Get systemID=`%s` fail", systemID)
+		return
+	}
+	return backend, nil
+}
+
+// DeleteSystemPolicyCacheBackend ...
+func DeleteSystemPolicyCacheBackend(systemID string) error {
+	return LocalSystemPolicyCacheBackendCache.Delete(cache.NewStringKey(systemID))
+}
@@ -0,0 +1,53 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache"
+	"iam/pkg/cache/memory"
+)
+
+func TestGetSystemPolicyCacheBackend(t *testing.T) {
+	expiration := 5 * time.Minute
+
+	// valid
+	retrieveFunc := func(key cache.Key) (interface{}, error) {
+		return "memory", nil
+	}
+	LocalSystemPolicyCacheBackendCache = memory.NewCache("mockCache", false, retrieveFunc, expiration)
+
+	backend, err := GetSystemPolicyCacheBackend("test")
+	assert.NoError(t, err)
+	assert.Equal(t, "memory", backend)
+
+	// invalid type
+	retrieveFunc = func(key cache.Key) (interface{}, error) {
+		return 1, nil
+	}
+	LocalSystemPolicyCacheBackendCache = memory.NewCache("mockCache", false, retrieveFunc, expiration)
+
+	_, err = GetSystemPolicyCacheBackend("test")
+	assert.Error(t, err)
+
+	// error
+	retrieveFunc = func(key cache.Key) (interface{}, error) {
+		return nil, errors.New("error here")
+	}
+	LocalSystemPolicyCacheBackendCache = memory.NewCache("mockCache", false, retrieveFunc, expiration)
+
+	_, err = GetSystemPolicyCacheBackend("test")
+	assert.Error(t, err)
+}
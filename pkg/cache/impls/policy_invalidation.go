@@ -0,0 +1,98 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"context"
+	"encoding/json"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/errorx"
+)
+
+/*
+ * > 策略变更时, 需要让所有实例的本地策略缓存(LocalPolicyCache)失效
+ *
+ * 1. 变更的实例删除本地的缓存, 并通过 redis pub/sub 广播失效消息
+ * 2. 所有实例订阅该channel, 收到消息后删除对应的 {system}:{actionPK}:{subjectPK} 缓存
+ * 3. pub/sub 不保证送达(例如订阅断线重连期间), 此时由 changelist 兜底, 最长延迟为本地缓存时间(60s)
+ */
+
+const policyInvalidationChannel = "pl_ivd"
+
+// PolicyInvalidation 策略缓存失效消息: {system}:{actionPK} => subjectPKs
+type PolicyInvalidation struct {
+	Keys map[string][]string `json:"keys"`
+}
+
+// BroadcastPolicyInvalidation 删除本实例的策略缓存, 并广播给其他实例
+func BroadcastPolicyInvalidation(keyMembers map[string][]string) error {
+	if len(keyMembers) == 0 {
+		return nil
+	}
+
+	deleteLocalPolicies(keyMembers)
+
+	message, err := json.Marshal(PolicyInvalidation{Keys: keyMembers})
+	if err != nil {
+		return errorx.Wrapf(err, CacheLayer, "BroadcastPolicyInvalidation",
+			"json.Marshal keyMembers=`%+v` fail", keyMembers)
+	}
+
+	err = PubSubCache.Publish(policyInvalidationChannel, string(message))
+	if err != nil {
+		return errorx.Wrapf(err, CacheLayer, "BroadcastPolicyInvalidation",
+			"PubSubCache.Publish channel=`%s` fail", policyInvalidationChannel)
+	}
+	return nil
+}
+
+// SubscribePolicyInvalidation 订阅其他实例广播的策略缓存失效消息, 阻塞直到ctx结束
+func SubscribePolicyInvalidation(ctx context.Context) {
+	pubsub := PubSubCache.Subscribe(ctx, policyInvalidationChannel)
+	defer pubsub.Close()
+
+	log.Infof("subscribe the policy invalidation channel `%s`", policyInvalidationChannel)
+
+	// NOTE: go-redis will reconnect automatically if the connection lost
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			handlePolicyInvalidationMessage(msg.Payload)
+		}
+	}
+}
+
+func handlePolicyInvalidationMessage(payload string) {
+	var invalidation PolicyInvalidation
+	err := json.Unmarshal([]byte(payload), &invalidation)
+	if err != nil {
+		log.WithError(err).Errorf("unmarshal policy invalidation message fail, payload=`%s`", payload)
+		return
+	}
+
+	deleteLocalPolicies(invalidation.Keys)
+}
+
+// deleteLocalPolicies 删除本地缓存的 {system}:{actionPK}:{subjectPK}
+func deleteLocalPolicies(keyMembers map[string][]string) {
+	for key, members := range keyMembers {
+		for _, member := range members {
+			LocalPolicyCache.Delete(key + ":" + member)
+		}
+	}
+}
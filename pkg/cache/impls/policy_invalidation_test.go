@@ -0,0 +1,76 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache/redis"
+)
+
+func TestBroadcastPolicyInvalidation(t *testing.T) {
+	LocalPolicyCache = gocache.New(1*time.Minute, 1*time.Minute)
+	PubSubCache = redis.NewMockCache("test", 0)
+
+	// empty
+	assert.NoError(t, BroadcastPolicyInvalidation(map[string][]string{}))
+
+	var published string
+	var publishErr error
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(PubSubCache), "Publish",
+		func(_ *redis.Cache, channel string, message string) error {
+			published = message
+			return publishErr
+		})
+	defer patches.Reset()
+
+	LocalPolicyCache.Set("test:1:123", "abc", 0)
+	LocalPolicyCache.Set("test:1:456", "abc", 0)
+	LocalPolicyCache.Set("test:2:123", "abc", 0)
+
+	err := BroadcastPolicyInvalidation(map[string][]string{"test:1": {"123", "456"}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"keys": {"test:1": ["123", "456"]}}`, published)
+
+	_, ok := LocalPolicyCache.Get("test:1:123")
+	assert.False(t, ok)
+	_, ok = LocalPolicyCache.Get("test:1:456")
+	assert.False(t, ok)
+	_, ok = LocalPolicyCache.Get("test:2:123")
+	assert.True(t, ok)
+
+	// publish fail, the local cache still deleted
+	publishErr = errors.New("publish fail")
+	err = BroadcastPolicyInvalidation(map[string][]string{"test:2": {"123"}})
+	assert.Error(t, err)
+	_, ok = LocalPolicyCache.Get("test:2:123")
+	assert.False(t, ok)
+}
+
+func TestHandlePolicyInvalidationMessage(t *testing.T) {
+	LocalPolicyCache = gocache.New(1*time.Minute, 1*time.Minute)
+	LocalPolicyCache.Set("test:1:123", "abc", 0)
+
+	// invalid payload, do nothing
+	handlePolicyInvalidationMessage("abc")
+	_, ok := LocalPolicyCache.Get("test:1:123")
+	assert.True(t, ok)
+
+	handlePolicyInvalidationMessage(`{"keys": {"test:1": ["123"]}}`)
+	_, ok = LocalPolicyCache.Get("test:1:123")
+	assert.False(t, ok)
+}
@@ -0,0 +1,490 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package impls
+
+import (
+	"errors"
+	"strings"
+
+	rediscache "github.com/go-redis/cache/v8"
+	gocache "github.com/patrickmn/go-cache"
+
+	"iam/pkg/cache"
+	"iam/pkg/cache/memory"
+	"iam/pkg/cache/redis"
+)
+
+/*
+ * > 缓存注册表, 用于缓存的查看与按需清理, 避免出现脏数据时只能重启实例
+ *
+ * 1. 每个缓存注册时声明支持的清理范围, 即key的组织方式: 以{system}开头 / 以{subject_pk}开头或结尾
+ * 2. 按范围清理时, 遍历缓存的所有key并删除匹配的; redis缓存通过scan遍历, 只在运维接口使用
+ * 3. 本地缓存只在当前实例生效, 清理时通过 redis pub/sub 广播给其他实例
+ */
+
+// 缓存的类型
+const (
+	CacheTypeMemory = "memory"
+	CacheTypeRedis  = "redis"
+)
+
+// 缓存清理的范围
+const (
+	FlushScopeAll       = "all"
+	FlushScopeSystem    = "system"
+	FlushScopeSubjectPK = "subject_pk"
+)
+
+// ErrCacheNotRegistered ...
+var (
+	ErrCacheNotRegistered     = errors.New("cache not registered")
+	ErrFlushScopeNotSupported = errors.New("flush scope not supported by the cache")
+)
+
+// CacheInfo 缓存的概览, redis缓存没有统计数量与命中率, Size为-1
+type CacheInfo struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Scopes   []string `json:"scopes"`
+	Size     int      `json:"size"`
+	Hit      uint64   `json:"hit"`
+	Miss     uint64   `json:"miss"`
+	HitRatio float64  `json:"hit_ratio"`
+}
+
+// keyMatcher 判断缓存的key是否属于某个系统/subject
+type keyMatcher func(key, value string) bool
+
+// matchFirstSegment key = {value} 或 {value}:...
+func matchFirstSegment(key, value string) bool {
+	return key == value || strings.HasPrefix(key, value+":")
+}
+
+// matchLastSegment key = {value} 或 ...:{value}
+func matchLastSegment(key, value string) bool {
+	return key == value || strings.HasSuffix(key, ":"+value)
+}
+
+// inspectableCache 不同实现的缓存, 统一查看与清理的操作
+type inspectableCache interface {
+	Type() string
+	Info() CacheInfo
+	Peek(key string) (interface{}, bool, error)
+	Keys() ([]string, error)
+	Delete(keys []string) error
+	Flush() (int, error)
+}
+
+type registeredCache struct {
+	name     string
+	cache    inspectableCache
+	matchers map[string]keyMatcher
+}
+
+func (r *registeredCache) scopes() []string {
+	scopes := []string{FlushScopeAll}
+	for _, scope := range []string{FlushScopeSystem, FlushScopeSubjectPK} {
+		if _, ok := r.matchers[scope]; ok {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+func (r *registeredCache) supports(scope string) bool {
+	if scope == FlushScopeAll {
+		return true
+	}
+	_, ok := r.matchers[scope]
+	return ok
+}
+
+// flush 清理缓存, 返回删除的key数量
+func (r *registeredCache) flush(scope, value string) (int, error) {
+	if scope == FlushScopeAll {
+		return r.cache.Flush()
+	}
+
+	matcher, ok := r.matchers[scope]
+	if !ok {
+		return 0, ErrFlushScopeNotSupported
+	}
+
+	keys, err := r.cache.Keys()
+	if err != nil {
+		return 0, err
+	}
+
+	matchedKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if matcher(key, value) {
+			matchedKeys = append(matchedKeys, key)
+		}
+	}
+	if len(matchedKeys) == 0 {
+		return 0, nil
+	}
+
+	return len(matchedKeys), r.cache.Delete(matchedKeys)
+}
+
+var registeredCaches []*registeredCache
+
+func registerCache(name string, c inspectableCache, matchers map[string]keyMatcher) {
+	registeredCaches = append(registeredCaches, &registeredCache{
+		name:     name,
+		cache:    c,
+		matchers: matchers,
+	})
+}
+
+func getRegisteredCache(name string) (*registeredCache, error) {
+	for _, r := range registeredCaches {
+		if r.name == name {
+			return r, nil
+		}
+	}
+	return nil, ErrCacheNotRegistered
+}
+
+var (
+	bySystem    = map[string]keyMatcher{FlushScopeSystem: matchFirstSegment}
+	bySubjectPK = map[string]keyMatcher{FlushScopeSubjectPK: matchFirstSegment}
+	// key = {system}:...:{subject_pk}
+	bySystemAndSubjectPK = map[string]keyMatcher{
+		FlushScopeSystem:    matchFirstSegment,
+		FlushScopeSubjectPK: matchLastSegment,
+	}
+)
+
+// registerCaches 注册需要支持查看与清理的缓存, 任务进度/计数排行等非数据缓存不注册
+func registerCaches() {
+	registeredCaches = nil
+
+	registerCache("app_code_app_secret", newMemoryInspectableCache(LocalAppCodeAppSecretCache), nil)
+	registerCache("local_subject", newMemoryInspectableCache(LocalSubjectCache), bySubjectPK)
+	registerCache("local_subject_role", newMemoryInspectableCache(LocalSubjectRoleCache), nil)
+	registerCache("local_subject_group", newMemoryInspectableCache(LocalSubjectGroupCache), bySubjectPK)
+	registerCache("local_remote_resource_list", newMemoryInspectableCache(LocalRemoteResourceListCache), nil)
+	registerCache("local_subject_pk", newMemoryInspectableCache(LocalSubjectPKCache), nil)
+	registerCache("local_system_clients", newMemoryInspectableCache(LocalSystemClientsCache), bySystem)
+	registerCache("local_apigw_jwt_client_id", newMemoryInspectableCache(LocalAPIGatewayJWTClientIDCache), nil)
+	registerCache("local_action", newMemoryInspectableCache(LocalActionCache), nil)
+	registerCache("local_unmarshaled_expression", newMemoryInspectableCache(LocalUnmarshaledExpressionCache), nil)
+	registerCache("local_resource_attribute_schema",
+		newMemoryInspectableCache(LocalResourceAttributeSchemaCache), bySystem)
+	registerCache(localSystemActionIndexCacheName, newMemoryInspectableCache(LocalSystemActionIndexCache), bySystem)
+	registerCache(localSystemDisabledActionsCacheName,
+		newMemoryInspectableCache(LocalSystemDisabledActionsCache), bySystem)
+	registerCache("local_system_policy_cache_backend",
+		newMemoryInspectableCache(LocalSystemPolicyCacheBackendCache), bySystem)
+	registerCache("local_subject_attribute_providers",
+		newMemoryInspectableCache(LocalSubjectAttributeProvidersCache), nil)
+	registerCache(localFrozenSubjectPKsCacheName, newMemoryInspectableCache(LocalFrozenSubjectPKsCache), nil)
+	// key = {system}:{action_pk}:{subject_pk}
+	registerCache("local_policy", newGoCacheInspectableCache(LocalPolicyCache), bySystemAndSubjectPK)
+	registerCache("local_expression", newGoCacheInspectableCache(LocalExpressionCache), nil)
+
+	registerCache(SystemCache.Name(), newRedisInspectableCache(SystemCache, redisValueCodec), bySystem)
+	registerCache(ResourceTypeCache.Name(), newRedisInspectableCache(ResourceTypeCache, redisValueCodec), bySystem)
+	registerCache(RemoteResourceCache.Name(), newRedisInspectableCache(RemoteResourceCache, redisValueCodec), nil)
+	registerCache(ActionPKCache.Name(), newRedisInspectableCache(ActionPKCache, redisValueCodec), bySystem)
+	registerCache(ActionDetailCache.Name(), newRedisInspectableCache(ActionDetailCache, redisValueCodec), bySystem)
+	registerCache(SubjectGroupCache.Name(), newRedisInspectableCache(SubjectGroupCache, redisValueCodec), bySubjectPK)
+	registerCache(SubjectPKCache.Name(), newRedisInspectableCache(SubjectPKCache, redisValueCodec), nil)
+	registerCache(SubjectDetailCache.Name(), newRedisInspectableCache(SubjectDetailCache, redisValueCodec), bySubjectPK)
+	// hash key = {subject_pk}, field = {system}
+	registerCache(SubjectSystemGroupCache.Name(),
+		newRedisInspectableCache(SubjectSystemGroupCache, redisValueHash), bySubjectPK)
+	registerCache(SubjectAttributeCache.Name(),
+		newRedisInspectableCache(SubjectAttributeCache, redisValueCodec), nil)
+	registerCache(GroupMemberCountCache.Name(), newRedisInspectableCache(GroupMemberCountCache, redisValueRaw), nil)
+	// hash key = {system}:{subject_pk}, field = {action_pk}
+	registerCache(PolicyCache.Name(), newRedisInspectableCache(PolicyCache, redisValueHash), bySystemAndSubjectPK)
+	registerCache(ExpressionCache.Name(), newRedisInspectableCache(ExpressionCache, redisValueCodec), nil)
+}
+
+// ListCacheInfos 列出所有注册的缓存
+func ListCacheInfos() []CacheInfo {
+	infos := make([]CacheInfo, 0, len(registeredCaches))
+	for _, r := range registeredCaches {
+		info := r.cache.Info()
+		info.Name = r.name
+		info.Type = r.cache.Type()
+		info.Scopes = r.scopes()
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// PeekCache 查看缓存中的key, 不会触发回源
+func PeekCache(name, key string) (value interface{}, found bool, err error) {
+	r, err := getRegisteredCache(name)
+	if err != nil {
+		return nil, false, err
+	}
+	return r.cache.Peek(key)
+}
+
+// CheckFlushScope 检查缓存是否支持该清理范围, names为空时不检查
+func CheckFlushScope(names []string, scope string) error {
+	for _, name := range names {
+		r, err := getRegisteredCache(name)
+		if err != nil {
+			return err
+		}
+		if !r.supports(scope) {
+			return ErrFlushScopeNotSupported
+		}
+	}
+	return nil
+}
+
+// FlushCaches 按范围清理缓存, names为空时清理所有支持该范围的缓存, 返回每个缓存删除的key数量
+// 本地缓存的清理会广播给其他实例
+func FlushCaches(names []string, scope, value string) (map[string]int, error) {
+	err := CheckFlushScope(names, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := flushCaches(names, scope, value, false)
+	if err != nil {
+		return counts, err
+	}
+
+	return counts, broadcastCacheFlush(names, scope, value)
+}
+
+// flushCaches 清理缓存, localOnly=true时只清理本地缓存(收到其他实例的广播时)
+func flushCaches(names []string, scope, value string, localOnly bool) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, r := range registeredCaches {
+		if len(names) > 0 && !containsName(names, r.name) {
+			continue
+		}
+		if !r.supports(scope) {
+			continue
+		}
+		if localOnly && r.cache.Type() != CacheTypeMemory {
+			continue
+		}
+
+		count, err := r.flush(scope, value)
+		counts[r.name] = count
+		if err != nil {
+			return counts, err
+		}
+	}
+	return counts, nil
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ========== memory ==========
+
+type memoryInspectableCache struct {
+	c memory.Cache
+}
+
+func newMemoryInspectableCache(c memory.Cache) inspectableCache {
+	return &memoryInspectableCache{c: c}
+}
+
+func (m *memoryInspectableCache) Type() string {
+	return CacheTypeMemory
+}
+
+func (m *memoryInspectableCache) Info() CacheInfo {
+	stats := m.c.Stats()
+	return CacheInfo{
+		Size:     m.c.Len(),
+		Hit:      stats.Hit,
+		Miss:     stats.Miss,
+		HitRatio: stats.HitRatio(),
+	}
+}
+
+func (m *memoryInspectableCache) Peek(key string) (interface{}, bool, error) {
+	value, found := m.c.DirectGet(cache.NewStringKey(key))
+	return value, found, nil
+}
+
+func (m *memoryInspectableCache) Keys() ([]string, error) {
+	return m.c.Keys(), nil
+}
+
+func (m *memoryInspectableCache) Delete(keys []string) error {
+	for _, key := range keys {
+		m.c.Delete(cache.NewStringKey(key))
+	}
+	return nil
+}
+
+func (m *memoryInspectableCache) Flush() (int, error) {
+	count := m.c.Len()
+	m.c.Flush()
+	return count, nil
+}
+
+// goCacheInspectableCache the local policy/expression caches, without the hit/miss stats
+type goCacheInspectableCache struct {
+	c *gocache.Cache
+}
+
+func newGoCacheInspectableCache(c *gocache.Cache) inspectableCache {
+	return &goCacheInspectableCache{c: c}
+}
+
+func (g *goCacheInspectableCache) Type() string {
+	return CacheTypeMemory
+}
+
+func (g *goCacheInspectableCache) Info() CacheInfo {
+	return CacheInfo{Size: g.c.ItemCount()}
+}
+
+func (g *goCacheInspectableCache) Peek(key string) (interface{}, bool, error) {
+	value, found := g.c.Get(key)
+	return value, found, nil
+}
+
+func (g *goCacheInspectableCache) Keys() ([]string, error) {
+	items := g.c.Items()
+
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (g *goCacheInspectableCache) Delete(keys []string) error {
+	for _, key := range keys {
+		g.c.Delete(key)
+	}
+	return nil
+}
+
+func (g *goCacheInspectableCache) Flush() (int, error) {
+	count := g.c.ItemCount()
+	g.c.Flush()
+	return count, nil
+}
+
+// ========== redis ==========
+
+// the value format of the redis cache
+const (
+	// set by Set/GetInto, encoded by go-redis/cache
+	redisValueCodec = iota
+	// the hash with the fields encoded by go-redis/cache
+	redisValueHash
+	// the raw string, e.g. the counters
+	redisValueRaw
+)
+
+// redisDeleteBatchSize the size of each pipeline while deleting the keys of the redis cache
+const redisDeleteBatchSize = 1000
+
+type redisInspectableCache struct {
+	c           *redis.Cache
+	valueFormat int
+}
+
+func newRedisInspectableCache(c *redis.Cache, valueFormat int) inspectableCache {
+	return &redisInspectableCache{c: c, valueFormat: valueFormat}
+}
+
+func (r *redisInspectableCache) Type() string {
+	return CacheTypeRedis
+}
+
+func (r *redisInspectableCache) Info() CacheInfo {
+	return CacheInfo{Size: -1}
+}
+
+func (r *redisInspectableCache) Peek(key string) (interface{}, bool, error) {
+	switch r.valueFormat {
+	case redisValueHash:
+		fields, err := r.c.HGetAll(key)
+		if err != nil || len(fields) == 0 {
+			return nil, false, err
+		}
+
+		values := make(map[string]interface{}, len(fields))
+		for field, data := range fields {
+			var value interface{}
+			err = r.c.Unmarshal([]byte(data), &value)
+			if err != nil {
+				return nil, false, err
+			}
+			values[field] = value
+		}
+		return values, true, nil
+	case redisValueRaw:
+		values, err := r.c.BatchGet([]cache.Key{cache.NewStringKey(key)})
+		if err != nil {
+			return nil, false, err
+		}
+		value, found := values[cache.NewStringKey(key)]
+		return value, found, nil
+	default:
+		var value interface{}
+		err := r.c.Get(cache.NewStringKey(key), &value)
+		if errors.Is(err, rediscache.ErrCacheMiss) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return value, true, nil
+	}
+}
+
+func (r *redisInspectableCache) Keys() ([]string, error) {
+	return r.c.Keys()
+}
+
+func (r *redisInspectableCache) Delete(keys []string) error {
+	for start := 0; start < len(keys); start += redisDeleteBatchSize {
+		end := start + redisDeleteBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		batchKeys := make([]cache.Key, 0, end-start)
+		for _, key := range keys[start:end] {
+			batchKeys = append(batchKeys, cache.NewStringKey(key))
+		}
+
+		err := r.c.BatchDelete(batchKeys)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *redisInspectableCache) Flush() (int, error) {
+	keys, err := r.c.Keys()
+	if err != nil {
+		return 0, err
+	}
+	return len(keys), r.Delete(keys)
+}
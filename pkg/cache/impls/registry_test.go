@@ -0,0 +1,189 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package impls
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache"
+	"iam/pkg/cache/memory"
+	"iam/pkg/cache/redis"
+)
+
+func setupTestRegisteredCaches() (memory.Cache, *gocache.Cache, *redis.Cache) {
+	retrieveFunc := func(key cache.Key) (interface{}, error) {
+		return nil, errors.New("should not retrieve")
+	}
+	localSystemCache := memory.NewMockCache(retrieveFunc)
+	localPolicyCache := gocache.New(1*time.Minute, 1*time.Minute)
+	subjectGroupCache := redis.NewMockCache("test_sub_grp", 1*time.Minute)
+
+	registeredCaches = nil
+	registerCache("local_system", newMemoryInspectableCache(localSystemCache), bySystem)
+	registerCache("local_policy", newGoCacheInspectableCache(localPolicyCache), bySystemAndSubjectPK)
+	registerCache("sub_grp", newRedisInspectableCache(subjectGroupCache, redisValueCodec), bySubjectPK)
+
+	return localSystemCache, localPolicyCache, subjectGroupCache
+}
+
+func TestKeyMatcher(t *testing.T) {
+	assert.True(t, matchFirstSegment("bk_cmdb", "bk_cmdb"))
+	assert.True(t, matchFirstSegment("bk_cmdb:host", "bk_cmdb"))
+	assert.False(t, matchFirstSegment("bk_cmdb_v2:host", "bk_cmdb"))
+
+	assert.True(t, matchLastSegment("123", "123"))
+	assert.True(t, matchLastSegment("bk_cmdb:1:123", "123"))
+	assert.False(t, matchLastSegment("bk_cmdb:1:1123", "123"))
+}
+
+func TestListCacheInfos(t *testing.T) {
+	localSystemCache, localPolicyCache, _ := setupTestRegisteredCaches()
+
+	localSystemCache.Set(cache.NewStringKey("bk_cmdb"), "a")
+	localSystemCache.DirectGet(cache.NewStringKey("bk_cmdb"))
+	localSystemCache.DirectGet(cache.NewStringKey("bk_job"))
+	localPolicyCache.Set("bk_cmdb:1:123", "a", 0)
+
+	infos := ListCacheInfos()
+	assert.Len(t, infos, 3)
+	assert.Equal(t, CacheInfo{
+		Name:     "local_system",
+		Type:     CacheTypeMemory,
+		Scopes:   []string{FlushScopeAll, FlushScopeSystem},
+		Size:     1,
+		Hit:      1,
+		Miss:     1,
+		HitRatio: 0.5,
+	}, infos[0])
+	assert.Equal(t, 1, infos[1].Size)
+	assert.Equal(t, []string{FlushScopeAll, FlushScopeSystem, FlushScopeSubjectPK}, infos[1].Scopes)
+	assert.Equal(t, CacheTypeRedis, infos[2].Type)
+	assert.Equal(t, -1, infos[2].Size)
+}
+
+func TestPeekCache(t *testing.T) {
+	localSystemCache, _, subjectGroupCache := setupTestRegisteredCaches()
+
+	_, _, err := PeekCache("not_exists", "a")
+	assert.ErrorIs(t, err, ErrCacheNotRegistered)
+
+	localSystemCache.Set(cache.NewStringKey("bk_cmdb"), "a")
+	value, found, err := PeekCache("local_system", "bk_cmdb")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "a", value)
+
+	_, found, err = PeekCache("sub_grp", "123")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	err = subjectGroupCache.Set(cache.NewStringKey("123"), map[string]string{"b": "c"}, 0)
+	assert.NoError(t, err)
+	value, found, err = PeekCache("sub_grp", "123")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, map[string]interface{}{"b": "c"}, value)
+}
+
+func TestFlushCaches(t *testing.T) {
+	var published string
+	PubSubCache = redis.NewMockCache("test", 0)
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(PubSubCache), "Publish",
+		func(_ *redis.Cache, channel string, message string) error {
+			published = message
+			return nil
+		})
+	defer patches.Reset()
+
+	t.Run("not registered", func(t *testing.T) {
+		setupTestRegisteredCaches()
+
+		_, err := FlushCaches([]string{"not_exists"}, FlushScopeAll, "")
+		assert.ErrorIs(t, err, ErrCacheNotRegistered)
+	})
+
+	t.Run("scope not supported", func(t *testing.T) {
+		setupTestRegisteredCaches()
+
+		_, err := FlushCaches([]string{"sub_grp"}, FlushScopeSystem, "bk_cmdb")
+		assert.ErrorIs(t, err, ErrFlushScopeNotSupported)
+	})
+
+	t.Run("flush by system", func(t *testing.T) {
+		localSystemCache, localPolicyCache, _ := setupTestRegisteredCaches()
+		localSystemCache.Set(cache.NewStringKey("bk_cmdb"), "a")
+		localSystemCache.Set(cache.NewStringKey("bk_job"), "a")
+		localPolicyCache.Set("bk_cmdb:1:123", "a", 0)
+		localPolicyCache.Set("bk_job:1:123", "a", 0)
+
+		counts, err := FlushCaches(nil, FlushScopeSystem, "bk_cmdb")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"local_system": 1, "local_policy": 1}, counts)
+		assert.JSONEq(t, `{"names": null, "scope": "system", "value": "bk_cmdb"}`, published)
+
+		assert.False(t, localSystemCache.Exists(cache.NewStringKey("bk_cmdb")))
+		assert.True(t, localSystemCache.Exists(cache.NewStringKey("bk_job")))
+		_, found := localPolicyCache.Get("bk_job:1:123")
+		assert.True(t, found)
+	})
+
+	t.Run("flush by subject pk", func(t *testing.T) {
+		_, localPolicyCache, subjectGroupCache := setupTestRegisteredCaches()
+		localPolicyCache.Set("bk_cmdb:1:123", "a", 0)
+		localPolicyCache.Set("bk_cmdb:1:456", "a", 0)
+		assert.NoError(t, subjectGroupCache.Set(cache.NewStringKey("123"), "a", 0))
+		assert.NoError(t, subjectGroupCache.Set(cache.NewStringKey("456"), "a", 0))
+
+		counts, err := FlushCaches(nil, FlushScopeSubjectPK, "123")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"local_policy": 1, "sub_grp": 1}, counts)
+
+		assert.False(t, subjectGroupCache.Exists(cache.NewStringKey("123")))
+		assert.True(t, subjectGroupCache.Exists(cache.NewStringKey("456")))
+		_, found := localPolicyCache.Get("bk_cmdb:1:456")
+		assert.True(t, found)
+	})
+
+	t.Run("flush whole cache", func(t *testing.T) {
+		localSystemCache, _, subjectGroupCache := setupTestRegisteredCaches()
+		localSystemCache.Set(cache.NewStringKey("bk_cmdb"), "a")
+		assert.NoError(t, subjectGroupCache.Set(cache.NewStringKey("123"), "a", 0))
+
+		counts, err := FlushCaches([]string{"sub_grp"}, FlushScopeAll, "")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"sub_grp": 1}, counts)
+
+		assert.False(t, subjectGroupCache.Exists(cache.NewStringKey("123")))
+		assert.Equal(t, 1, localSystemCache.Len())
+	})
+}
+
+func TestHandleCacheFlushMessage(t *testing.T) {
+	localSystemCache, _, subjectGroupCache := setupTestRegisteredCaches()
+	localSystemCache.Set(cache.NewStringKey("bk_cmdb"), "a")
+	assert.NoError(t, subjectGroupCache.Set(cache.NewStringKey("123"), "a", 0))
+
+	// invalid payload, do nothing
+	handleCacheFlushMessage("abc")
+	assert.Equal(t, 1, localSystemCache.Len())
+
+	// only flush the local caches
+	handleCacheFlushMessage(`{"names": null, "scope": "all", "value": ""}`)
+	assert.Equal(t, 0, localSystemCache.Len())
+	assert.True(t, subjectGroupCache.Exists(cache.NewStringKey("123")))
+}
@@ -11,8 +11,13 @@
 package impls
 
 import (
+	"context"
+	"reflect"
 	"sort"
 	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
 
 	"iam/pkg/cache"
 	"iam/pkg/errorx"
@@ -26,6 +31,9 @@ type RemoteResourceCacheKey struct {
 	ID     string
 	// a;b;c
 	Fields string
+
+	// Ctx the context of the incoming request, not a part of the key
+	Ctx context.Context
 }
 
 // Key ...
@@ -34,24 +42,58 @@ func (k RemoteResourceCacheKey) Key() string {
 	return util.GetMD5Hash(key)
 }
 
-func retrieveRemoteResource(k cache.Key) (interface{}, error) {
+// RemoteResourceAttrCacheTTLKey the key in resourceType.ProviderConfig, the cache ttl seconds of the attributes
+const RemoteResourceAttrCacheTTLKey = "attribute_cache_ttl"
+
+func retrieveRemoteResource(k cache.Key) (interface{}, time.Duration, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(CacheLayer, "retrieveRemoteResource")
 
 	k1 := k.(RemoteResourceCacheKey)
 
 	fields := strings.Split(k1.Fields, ";")
 
-	resources, err := listRemoteResources(k1.System, k1.Type, []string{k1.ID}, fields)
+	resources, err := listRemoteResources(k1.Ctx, k1.System, k1.Type, []string{k1.ID}, fields)
 	if err != nil {
 		err = errorWrapf(err, "listRemoteResources systemID=`%s`, resourceTypeID=`%s`, resourceID=`%s`, fields=`%s` fail",
 			k1.System, k1.Type, k1.ID, fields)
-		return nil, err
+		return nil, 0, err
+	}
+	return resources[0], getRemoteResourceCacheExpiration(k1.System, k1.Type), nil
+}
+
+// getRemoteResourceCacheExpiration 资源类型配置的属性缓存时间, 未配置或查询失败时返回0, 使用缓存的默认过期时间
+func getRemoteResourceCacheExpiration(system, _type string) time.Duration {
+	resourceType, err := GetResourceType(system, _type)
+	if err != nil {
+		log.WithError(err).Warnf("get resource type fail, system=`%s`, type=`%s`, use the default cache expiration",
+			system, _type)
+		return 0
+	}
+
+	// NOTE: the number in ProviderConfig may be decoded as any int/uint/float type by json or msgpack
+	var ttl int64
+	v := reflect.ValueOf(resourceType.ProviderConfig[RemoteResourceAttrCacheTTLKey])
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		ttl = v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		ttl = int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		ttl = int64(v.Float())
+	}
+
+	if ttl <= 0 {
+		return 0
 	}
-	return resources[0], nil
+	return time.Duration(ttl) * time.Second
 }
 
 // GetRemoteResource ...
-func GetRemoteResource(system, _type, id string, fields []string) (remoteResource map[string]interface{}, err error) {
+func GetRemoteResource(
+	ctx context.Context,
+	system, _type, id string,
+	fields []string,
+) (remoteResource map[string]interface{}, err error) {
 	// sort
 	if len(fields) > 1 {
 		sort.Strings(fields)
@@ -63,9 +105,11 @@ func GetRemoteResource(system, _type, id string, fields []string) (remoteResourc
 		Type:   _type,
 		ID:     id,
 		Fields: f,
+		Ctx:    ctx,
 	}
 
-	err = RemoteResourceCache.GetInto(key, &remoteResource, retrieveRemoteResource)
+	// the expiration is configured by the resource type
+	err = RemoteResourceCache.GetIntoWithExpiration(key, &remoteResource, retrieveRemoteResource)
 	err = errorx.Wrapf(err, CacheLayer, "GetRemoteResource",
 		"RemoteResourceCache.Get key=`%s` fail", key.Key())
 	return
@@ -11,6 +11,7 @@
 package impls
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -79,7 +80,7 @@ func TestGetCMDBResource(t *testing.T) {
 	req, _ := component.PrepareRequest(system, resourceType)
 
 	mockService := mock.NewMockRemoteResourceClient(ctl)
-	mockService.EXPECT().GetResources(req, "test", "app", []string{"checklist"}, []string{"name"}).Return(
+	mockService.EXPECT().GetResources(gomock.Any(), req, "test", "app", []string{"checklist"}, []string{"name"}).Return(
 		[]map[string]interface{}{{
 			"id": "checklist",
 		}}, nil).AnyTimes()
@@ -90,7 +91,30 @@ func TestGetCMDBResource(t *testing.T) {
 
 	RemoteResourceCache = mockCache
 
-	resource, err := GetRemoteResource("test", "app", "checklist", []string{"name"})
+	resource, err := GetRemoteResource(context.Background(), "test", "app", "checklist", []string{"name"})
 	assert.NoError(t, err)
 	assert.Equal(t, "checklist", resource["id"])
 }
+
+func TestGetRemoteResourceCacheExpiration(t *testing.T) {
+	expiration := 5 * time.Minute
+	ResourceTypeCache = redis.NewMockCache("mockCache", expiration)
+
+	// not configured
+	ResourceTypeCache.Set(ResourceTypeCacheKey{"test", "app"}, types.ResourceType{
+		ProviderConfig: map[string]interface{}{"path": "/api/v1/resources"},
+	}, 0)
+	assert.Equal(t, time.Duration(0), getRemoteResourceCacheExpiration("test", "app"))
+
+	// the int decoded from msgpack
+	ResourceTypeCache.Set(ResourceTypeCacheKey{"test", "host"}, types.ResourceType{
+		ProviderConfig: map[string]interface{}{"path": "/api/v1/resources", "attribute_cache_ttl": int64(60)},
+	}, 0)
+	assert.Equal(t, time.Minute, getRemoteResourceCacheExpiration("test", "host"))
+
+	// the float decoded from json
+	ResourceTypeCache.Set(ResourceTypeCacheKey{"test", "biz"}, types.ResourceType{
+		ProviderConfig: map[string]interface{}{"path": "/api/v1/resources", "attribute_cache_ttl": float64(3600)},
+	}, 0)
+	assert.Equal(t, time.Hour, getRemoteResourceCacheExpiration("test", "biz"))
+}
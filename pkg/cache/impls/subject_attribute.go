@@ -0,0 +1,77 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"time"
+
+	"iam/pkg/cache"
+	"iam/pkg/component"
+	"iam/pkg/errorx"
+)
+
+// SubjectAttributeCacheKey the attributes of the subject provided by the http provider
+type SubjectAttributeCacheKey struct {
+	ProviderID  string
+	SubjectType string
+	SubjectID   string
+}
+
+// Key ...
+func (k SubjectAttributeCacheKey) Key() string {
+	return k.ProviderID + ":" + k.SubjectType + ":" + k.SubjectID
+}
+
+func retrieveSubjectAttribute(k cache.Key) (interface{}, time.Duration, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(CacheLayer, "retrieveSubjectAttribute")
+
+	k1 := k.(SubjectAttributeCacheKey)
+
+	provider, err := GetSubjectAttributeProvider(k1.ProviderID)
+	if err != nil {
+		return nil, 0, errorWrapf(err, "GetSubjectAttributeProvider id=`%s` fail", k1.ProviderID)
+	}
+
+	req := component.SubjectAttributeProviderRequest{
+		URL:     provider.Config.URL,
+		Token:   provider.Config.Token,
+		Timeout: time.Duration(provider.Config.Timeout) * time.Second,
+	}
+	attrs, err := component.BKSubjectAttributeProvider.GetAttributes(
+		req, k1.SubjectType, k1.SubjectID, provider.Attributes)
+	if err != nil {
+		return nil, 0, errorWrapf(err,
+			"BKSubjectAttributeProvider.GetAttributes provider=`%s`, subjectType=`%s`, subjectID=`%s` fail",
+			k1.ProviderID, k1.SubjectType, k1.SubjectID)
+	}
+	if attrs == nil {
+		attrs = map[string]interface{}{}
+	}
+
+	// the expiration is configured by the provider, 0 means the default expiration of the cache
+	return attrs, time.Duration(provider.CacheTTL) * time.Second, nil
+}
+
+// GetSubjectProvidedAttributes 查询http类型的提供方提供的subject属性
+// NOTE: 提供方变更后, 已缓存的属性在过期后才会更新
+func GetSubjectProvidedAttributes(
+	providerID, subjectType, subjectID string,
+) (attrs map[string]interface{}, err error) {
+	key := SubjectAttributeCacheKey{
+		ProviderID:  providerID,
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+	}
+
+	err = SubjectAttributeCache.GetIntoWithExpiration(key, &attrs, retrieveSubjectAttribute)
+	err = errorx.Wrapf(err, CacheLayer, "GetSubjectProvidedAttributes",
+		"SubjectAttributeCache.Get key=`%s` fail", key.Key())
+	return
+}
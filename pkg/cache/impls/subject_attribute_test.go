@@ -0,0 +1,104 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache"
+	"iam/pkg/cache/memory"
+	"iam/pkg/cache/redis"
+	"iam/pkg/component"
+	"iam/pkg/component/mock"
+	svctypes "iam/pkg/service/types"
+)
+
+func TestListSubjectAttributeProviders(t *testing.T) {
+	expiration := 5 * time.Minute
+
+	// valid
+	retrieveFunc := func(key cache.Key) (interface{}, error) {
+		return []svctypes.SubjectAttributeProvider{{ID: "hr", Type: "http"}}, nil
+	}
+	LocalSubjectAttributeProvidersCache = memory.NewCache("mockCache", false, retrieveFunc, expiration)
+
+	providers, err := ListSubjectAttributeProviders()
+	assert.NoError(t, err)
+	assert.Len(t, providers, 1)
+
+	_, err = GetSubjectAttributeProvider("hr")
+	assert.NoError(t, err)
+	_, err = GetSubjectAttributeProvider("not_exists")
+	assert.Error(t, err)
+
+	// invalid type
+	retrieveFunc = func(key cache.Key) (interface{}, error) {
+		return "abc", nil
+	}
+	LocalSubjectAttributeProvidersCache = memory.NewCache("mockCache", false, retrieveFunc, expiration)
+
+	_, err = ListSubjectAttributeProviders()
+	assert.Error(t, err)
+
+	// error
+	retrieveFunc = func(key cache.Key) (interface{}, error) {
+		return nil, errors.New("error here")
+	}
+	LocalSubjectAttributeProvidersCache = memory.NewCache("mockCache", false, retrieveFunc, expiration)
+
+	_, err = ListSubjectAttributeProviders()
+	assert.Error(t, err)
+}
+
+func TestGetSubjectProvidedAttributes(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	retrieveFunc := func(key cache.Key) (interface{}, error) {
+		return []svctypes.SubjectAttributeProvider{{
+			ID:         "hr",
+			Type:       svctypes.SubjectAttributeProviderTypeHTTP,
+			Attributes: []string{"job_level"},
+			Config: svctypes.SubjectAttributeProviderConfig{
+				URL:     "http://hr",
+				Timeout: 3,
+			},
+			CacheTTL: 60,
+		}}, nil
+	}
+	LocalSubjectAttributeProvidersCache = memory.NewCache("mockCache", false, retrieveFunc, 5*time.Minute)
+
+	mockClient := mock.NewMockSubjectAttributeProviderClient(ctl)
+	mockClient.EXPECT().GetAttributes(component.SubjectAttributeProviderRequest{
+		URL:     "http://hr",
+		Timeout: 3 * time.Second,
+	}, "user", "tom", []string{"job_level"}).Return(map[string]interface{}{"job_level": "P7"}, nil).Times(1)
+	component.BKSubjectAttributeProvider = mockClient
+
+	SubjectAttributeCache = redis.NewMockCache("mockCache", 5*time.Minute)
+
+	attrs, err := GetSubjectProvidedAttributes("hr", "user", "tom")
+	assert.NoError(t, err)
+	assert.Equal(t, "P7", attrs["job_level"])
+
+	// hit the cache, with the ttl of the provider
+	attrs, err = GetSubjectProvidedAttributes("hr", "user", "tom")
+	assert.NoError(t, err)
+	assert.Equal(t, "P7", attrs["job_level"])
+
+	// provider not exists
+	_, err = GetSubjectProvidedAttributes("not_exists", "user", "tom")
+	assert.Error(t, err)
+}
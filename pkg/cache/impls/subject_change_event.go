@@ -0,0 +1,85 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package impls
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/service"
+	"iam/pkg/service/types"
+)
+
+// handleSubjectChangeEvent 清理subject写操作影响到的缓存
+func handleSubjectChangeEvent(event service.SubjectChangeEvent) {
+	pks := make([]int64, 0, len(event.SubjectPKs)+len(event.Subjects))
+	pks = append(pks, event.SubjectPKs...)
+
+	switch event.Type {
+	case service.SubjectChangeEventTypeSubject:
+		// subject被创建或删除, 只需要清理 type+id => pk 的缓存(包括不存在的subject的空值缓存)
+		for _, s := range event.Subjects {
+			DeleteSubjectPK(s.Type, s.ID)
+			DeleteLocalSubjectPK(s.Type, s.ID)
+			DeleteGroupMemberCount(s.Type, s.ID)
+		}
+	case service.SubjectChangeEventTypeRole:
+		for _, s := range event.Subjects {
+			DeleteSubjectRoleSystemID(s.Type, s.ID)
+		}
+	case service.SubjectChangeEventTypeSystemGroup:
+		// 只影响subject在系统下有策略的用户组
+		if len(pks) > 0 {
+			err := BatchDeleteSubjectSystemGroups(pks)
+			if err != nil {
+				log.WithError(err).Errorf("handleSubjectChangeEvent BatchDeleteSubjectSystemGroups fail pks=`%+v`", pks)
+			}
+		}
+		return
+	default:
+		if event.Group != nil && event.MemberDelta != 0 {
+			AdjustGroupMemberCount(event.Group.Type, event.Group.ID, event.MemberDelta)
+		}
+
+		for _, s := range event.Subjects {
+			pk, err := GetSubjectPK(s.Type, s.ID)
+			if err != nil {
+				log.WithError(err).Errorf("handleSubjectChangeEvent GetSubjectPK fail type=`%s`, id=`%s`", s.Type, s.ID)
+				continue
+			}
+			pks = append(pks, pk)
+		}
+	}
+
+	if len(pks) == 0 {
+		return
+	}
+
+	// 用户组成员的增删, 直接修改成员已缓存的用户组, 不需要删除缓存后重新计算
+	if event.Type == service.SubjectChangeEventTypeMember && event.Group != nil && event.MemberDelta != 0 {
+		groupPK, err := GetSubjectPK(event.Group.Type, event.Group.ID)
+		if err == nil {
+			if event.MemberDelta > 0 {
+				BatchAddSubjectGroup(pks, types.ThinSubjectGroup{
+					PK:              groupPK,
+					PolicyExpiredAt: event.PolicyExpiredAt,
+				})
+			} else {
+				BatchRemoveSubjectGroup(pks, groupPK)
+			}
+			return
+		}
+
+		log.WithError(err).Errorf("handleSubjectChangeEvent GetSubjectPK fail type=`%s`, id=`%s`",
+			event.Group.Type, event.Group.ID)
+	}
+
+	BatchDeleteSubjectCache(pks)
+}
@@ -20,14 +20,12 @@ import (
 func retrieveSubjectDetail(key cache.Key) (interface{}, error) {
 	k := key.(SubjectPKCacheKey)
 
-	svc := service.NewSubjectService()
-
-	depts, err := svc.GetSubjectDepartmentPKs(k.PK)
+	depts, err := service.NewSubjectDepartmentReadService().GetSubjectDepartmentPKs(k.PK)
 	if err != nil {
 		return nil, err
 	}
 
-	groups, err := svc.GetThinSubjectGroups(k.PK)
+	groups, err := service.NewSubjectGroupReadService().GetThinSubjectGroups(k.PK)
 	if err != nil {
 		return nil, err
 	}
@@ -14,6 +14,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"iam/pkg/cache"
+	"iam/pkg/cache/redis"
 	"iam/pkg/errorx"
 	"iam/pkg/service"
 	"iam/pkg/service/types"
@@ -23,7 +24,7 @@ import (
 func retrieveSubjectGroups(key cache.Key) (interface{}, error) {
 	k := key.(SubjectPKCacheKey)
 
-	svc := service.NewSubjectService()
+	svc := service.NewSubjectGroupReadService()
 	return svc.GetThinSubjectGroups(k.PK)
 }
 
@@ -41,26 +42,54 @@ func GetSubjectGroups(pk int64) (subjectGroups []types.ThinSubjectGroup, err err
 	return
 }
 
-// ListSubjectEffectGroups ...
+// subjectGroupPipelineChunkSize the max count of keys in one redis pipeline
+// NOTE: use pipeline instead of `mget`, the multi-key `mget` fails with CROSSSLOT in cluster mode
+const subjectGroupPipelineChunkSize = 500
+
+// ListSubjectEffectGroups 批量获取subject(部门)的用户组
+// 1. 本地缓存(短过期时间) 2. redis, 分批pipeline获取 3. DB批量查询, 并分批pipeline回写redis及本地缓存
 func ListSubjectEffectGroups(pks []int64) ([]types.ThinSubjectGroup, error) {
-	errorWrapf := errorx.NewLayerFunctionErrorWrapf(CacheLayer, "ListSubjectEffectGroups")
+	pkSubjectGroups, err := BatchListSubjectEffectGroups(pks)
+	if err != nil {
+		return nil, errorx.Wrapf(err, CacheLayer, "ListSubjectEffectGroups",
+			"BatchListSubjectEffectGroups pks=`%+v` fail", pks)
+	}
 
-	// 1. get from cache
-	subjectGroups := make([]types.ThinSubjectGroup, 0, len(pks))
+	subjectGroups := make([]types.ThinSubjectGroup, 0, len(pkSubjectGroups))
+	for _, pk := range pks {
+		subjectGroups = append(subjectGroups, pkSubjectGroups[pk]...)
+	}
+	return subjectGroups, nil
+}
 
-	cachedSubjectGroups, notExistCachePKs, err := batchGetSubjectGroups(pks)
+// BatchListSubjectEffectGroups 批量获取subject的用户组, 返回 pk => 用户组, 缓存的读取同ListSubjectEffectGroups
+// NOTE: 返回的slice可能是本地缓存中的, 只读, 不能修改
+func BatchListSubjectEffectGroups(pks []int64) (map[int64][]types.ThinSubjectGroup, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(CacheLayer, "BatchListSubjectEffectGroups")
+
+	// 1. get from local cache
+	subjectGroups, notLocalCachedPKs := batchGetLocalSubjectGroups(pks)
+	if len(notLocalCachedPKs) == 0 {
+		return subjectGroups, nil
+	}
+
+	// 2. get from redis
+	cachedSubjectGroups, notExistCachePKs, err := batchGetSubjectGroups(notLocalCachedPKs)
 	if err != nil {
-		err = errorWrapf(err, "batchGetSubjectGroups pks=`%+v` fail", pks)
+		err = errorWrapf(err, "batchGetSubjectGroups pks=`%+v` fail", notLocalCachedPKs)
 		return subjectGroups, err
 	}
-	subjectGroups = append(subjectGroups, cachedSubjectGroups...)
+	batchSetLocalSubjectGroups(cachedSubjectGroups)
+	for pk, sgs := range cachedSubjectGroups {
+		subjectGroups[pk] = sgs
+	}
 
-	// 2. all in cache, return
+	// 3. all in cache, return
 	if len(notExistCachePKs) == 0 {
 		return subjectGroups, nil
 	}
-	// 3. ids of no cache, retrieve multiple
-	svc := service.NewSubjectService()
+	// 4. ids of no cache, retrieve multiple
+	svc := service.NewSubjectGroupReadService()
 	// 按照时间过滤, 不应该查已过期的回来
 	notCachedSubjectGroups, err := svc.ListSubjectEffectGroups(notExistCachePKs)
 	if err != nil {
@@ -68,83 +97,97 @@ func ListSubjectEffectGroups(pks []int64) ([]types.ThinSubjectGroup, error) {
 		return nil, err
 	}
 	setMissing(notCachedSubjectGroups, notExistCachePKs)
-	// append the notCachedSubjectGroups
-	for _, sgs := range notCachedSubjectGroups {
-		subjectGroups = append(subjectGroups, sgs...)
+	for pk, sgs := range notCachedSubjectGroups {
+		subjectGroups[pk] = sgs
 	}
 
 	return subjectGroups, nil
 }
 
-func batchGetSubjectGroups(pks []int64) (subjectGroups []types.ThinSubjectGroup, notExistCachePKs []int64, err error) {
+// batchGetSubjectGroups 从redis分批获取subject的用户组, 返回命中的 pk => 用户组 及未命中的pks
+func batchGetSubjectGroups(
+	pks []int64,
+) (subjectGroups map[int64][]types.ThinSubjectGroup, notExistCachePKs []int64, err error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(CacheLayer, "batchGetSubjectGroups")
 
-	// batch get the subject_groups at one time
-	keys := make([]cache.Key, 0, len(pks))
-	for _, pk := range pks {
-		keys = append(keys, SubjectPKCacheKey{
-			PK: pk,
-		})
-	}
-	hitCacheResults, err := SubjectGroupCache.BatchGet(keys)
-	if err != nil {
-		err = errorWrapf(err, "SubjectGroupCache.BatchGet keys=`%+v` fail", keys)
-		return
-	}
+	subjectGroups = make(map[int64][]types.ThinSubjectGroup, len(pks))
+	for _, chunkPKs := range chunkInt64s(pks, subjectGroupPipelineChunkSize) {
+		// batch get the subject_groups of the chunk at one time
+		keys := make([]cache.Key, 0, len(chunkPKs))
+		for _, pk := range chunkPKs {
+			keys = append(keys, SubjectPKCacheKey{
+				PK: pk,
+			})
+		}
+		hitCacheResults, err := SubjectGroupCache.BatchGet(keys)
+		if err != nil {
+			err = errorWrapf(err, "SubjectGroupCache.BatchGet keys=`%+v` fail", keys)
+			return nil, nil, err
+		}
+
+		for _, pk := range chunkPKs {
+			key := SubjectPKCacheKey{PK: pk}
+			data, ok := hitCacheResults[key]
+			if !ok {
+				notExistCachePKs = append(notExistCachePKs, pk)
+				continue
+			}
 
-	for _, pk := range pks {
-		key := SubjectPKCacheKey{PK: pk}
-		if data, ok := hitCacheResults[key]; ok {
 			// do unmarshal
 			var sg []types.ThinSubjectGroup
 			err = SubjectGroupCache.Unmarshal(util.StringToBytes(data), &sg)
 			if err != nil {
 				err = errorWrapf(err, "unmarshal text in cache into SubjectGroup fail", "")
-				return
+				return nil, nil, err
 			}
-			subjectGroups = append(subjectGroups, sg...)
-		} else {
-			notExistCachePKs = append(notExistCachePKs, pk)
+			subjectGroups[pk] = sg
 		}
 	}
 
-	return subjectGroups, notExistCachePKs, err
+	return subjectGroups, notExistCachePKs, nil
 }
 
+// setMissing 分批pipeline回写redis, 没有用户组的pk写入空列表, 防止重复查询DB
 func setMissing(notCachedSubjectGroups map[int64][]types.ThinSubjectGroup, missingPKs []int64) {
-	hasGroupPKs := util.NewFixedLengthInt64Set(len(notCachedSubjectGroups))
-	// 3. set to cache
-	for pk, sgs := range notCachedSubjectGroups {
-		hasGroupPKs.Add(pk)
+	pkSubjectGroups := make(map[int64][]types.ThinSubjectGroup, len(missingPKs))
+	for _, chunkPKs := range chunkInt64s(missingPKs, subjectGroupPipelineChunkSize) {
+		kvs := make([]redis.KV, 0, len(chunkPKs))
+		for _, pk := range chunkPKs {
+			sgs, ok := notCachedSubjectGroups[pk]
+			if !ok {
+				sgs = []types.ThinSubjectGroup{}
+			}
+			pkSubjectGroups[pk] = sgs
 
-		key := SubjectPKCacheKey{
-			PK: pk,
+			key := SubjectPKCacheKey{
+				PK: pk,
+			}
+			value, err := SubjectGroupCache.Marshal(sgs)
+			if err != nil {
+				log.Errorf("marshal subject_group fail, key=%s, err=%s", key.Key(), err)
+				continue
+			}
+			kvs = append(kvs, redis.KV{Key: key.Key(), Value: util.BytesToString(value)})
 		}
 
-		// TODO: pipeline batch set
-		err := setSubjectGroupCache(key, sgs)
+		err := SubjectGroupCache.BatchSet(kvs, 0)
 		if err != nil {
-			log.Errorf("set subject_group to redis fail, key=%s, err=%s", key.Key(), err)
+			log.Errorf("batch set subject_group to redis fail, pks=%v, err=%s", chunkPKs, err)
 		}
 	}
 
-	// 4. set the no-groups key cache
-	if len(missingPKs) != hasGroupPKs.Size() {
-		for _, pk := range missingPKs {
-			if !hasGroupPKs.Has(pk) {
-				key := SubjectPKCacheKey{
-					PK: pk,
-				}
-				// TODO: pipeline batch set
-				err := setSubjectGroupCache(key, []types.ThinSubjectGroup{})
-				if err != nil {
-					log.Errorf("set empty subject_group to redis fail, key=%s, err=%s", key.Key(), err)
-				}
-			}
-		}
-	}
+	batchSetLocalSubjectGroups(pkSubjectGroups)
 }
 
-func setSubjectGroupCache(key cache.Key, subjectGroups []types.ThinSubjectGroup) error {
-	return SubjectGroupCache.Set(key, subjectGroups, 0)
+// chunkInt64s split the slice into chunks with the max size
+func chunkInt64s(s []int64, size int) [][]int64 {
+	chunks := make([][]int64, 0, (len(s)+size-1)/size)
+	for start := 0; start < len(s); start += size {
+		end := start + size
+		if end > len(s) {
+			end = len(s)
+		}
+		chunks = append(chunks, s[start:end])
+	}
+	return chunks
 }
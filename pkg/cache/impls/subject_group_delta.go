@@ -0,0 +1,87 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/service/types"
+)
+
+/*
+ * 用户组新增/删除成员时, 直接修改成员已缓存的 subject detail 以及 subject groups, 而不是删除缓存后全量重新计算
+ * (一个用户可能属于上千个用户组, 重新计算的代价很高)
+ *
+ * 1. 缓存不存在, 不处理, 下次读取时从DB获取
+ * 2. 修改失败或并发冲突, 删除对应的缓存
+ */
+
+// BatchAddSubjectGroup 成员已缓存的用户组列表中加入用户组, 已存在则更新过期时间
+func BatchAddSubjectGroup(pks []int64, group types.ThinSubjectGroup) {
+	batchUpdateSubjectGroups(pks, func(groups []types.ThinSubjectGroup) []types.ThinSubjectGroup {
+		for i := range groups {
+			if groups[i].PK == group.PK {
+				groups[i].PolicyExpiredAt = group.PolicyExpiredAt
+				return groups
+			}
+		}
+		return append(groups, group)
+	})
+}
+
+// BatchRemoveSubjectGroup 成员已缓存的用户组列表中移除用户组
+func BatchRemoveSubjectGroup(pks []int64, groupPK int64) {
+	batchUpdateSubjectGroups(pks, func(groups []types.ThinSubjectGroup) []types.ThinSubjectGroup {
+		result := groups[:0]
+		for _, g := range groups {
+			if g.PK != groupPK {
+				result = append(result, g)
+			}
+		}
+		return result
+	})
+}
+
+func batchUpdateSubjectGroups(pks []int64, change func([]types.ThinSubjectGroup) []types.ThinSubjectGroup) {
+	failedPKs := make([]int64, 0)
+	for _, pk := range pks {
+		key := SubjectPKCacheKey{
+			PK: pk,
+		}
+
+		var detail types.SubjectDetail
+		err := SubjectDetailCache.UpdateIfExists(key, &detail, func() error {
+			detail.SubjectGroups = change(detail.SubjectGroups)
+			return nil
+		})
+		if err != nil {
+			log.WithError(err).Errorf("SubjectDetailCache.UpdateIfExists key=`%s` fail", key.Key())
+			failedPKs = append(failedPKs, pk)
+			continue
+		}
+
+		var subjectGroups []types.ThinSubjectGroup
+		err = SubjectGroupCache.UpdateIfExists(key, &subjectGroups, func() error {
+			subjectGroups = change(subjectGroups)
+			return nil
+		})
+		if err != nil {
+			log.WithError(err).Errorf("SubjectGroupCache.UpdateIfExists key=`%s` fail", key.Key())
+			failedPKs = append(failedPKs, pk)
+		}
+	}
+
+	// the local cache is short-lived, delete instead of update
+	batchDeleteLocalSubjectGroups(pks)
+
+	if len(failedPKs) > 0 {
+		BatchDeleteSubjectCache(failedPKs)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache/memory"
+	"iam/pkg/cache/redis"
+	"iam/pkg/service"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectGroupDelta", func() {
+	key := SubjectPKCacheKey{PK: 1}
+
+	BeforeEach(func() {
+		SubjectDetailCache = redis.NewMockCache("mockCache", 5*time.Minute)
+		SubjectGroupCache = redis.NewMockCache("mockCache", 5*time.Minute)
+		LocalSubjectGroupCache = memory.NewMockCache(retrieveLocalSubjectGroups)
+
+		err := SubjectDetailCache.Set(key, &types.SubjectDetail{
+			DepartmentPKs: []int64{10},
+			SubjectGroups: []types.ThinSubjectGroup{{PK: 100, PolicyExpiredAt: 1}},
+		}, 0)
+		assert.NoError(GinkgoT(), err)
+		err = SubjectGroupCache.Set(key, []types.ThinSubjectGroup{{PK: 100, PolicyExpiredAt: 1}}, 0)
+		assert.NoError(GinkgoT(), err)
+	})
+
+	getCached := func() (types.SubjectDetail, []types.ThinSubjectGroup) {
+		var detail types.SubjectDetail
+		err := SubjectDetailCache.Get(key, &detail)
+		assert.NoError(GinkgoT(), err)
+
+		var subjectGroups []types.ThinSubjectGroup
+		err = SubjectGroupCache.Get(key, &subjectGroups)
+		assert.NoError(GinkgoT(), err)
+		return detail, subjectGroups
+	}
+
+	It("BatchAddSubjectGroup", func() {
+		BatchAddSubjectGroup([]int64{1, 2}, types.ThinSubjectGroup{PK: 200, PolicyExpiredAt: 2})
+
+		detail, subjectGroups := getCached()
+		expected := []types.ThinSubjectGroup{{PK: 100, PolicyExpiredAt: 1}, {PK: 200, PolicyExpiredAt: 2}}
+		assert.Equal(GinkgoT(), []int64{10}, detail.DepartmentPKs)
+		assert.Equal(GinkgoT(), expected, detail.SubjectGroups)
+		assert.Equal(GinkgoT(), expected, subjectGroups)
+
+		// the missing one will not be set
+		assert.False(GinkgoT(), SubjectDetailCache.Exists(SubjectPKCacheKey{PK: 2}))
+		assert.False(GinkgoT(), SubjectGroupCache.Exists(SubjectPKCacheKey{PK: 2}))
+	})
+
+	It("BatchAddSubjectGroup exists, update expired at", func() {
+		BatchAddSubjectGroup([]int64{1}, types.ThinSubjectGroup{PK: 100, PolicyExpiredAt: 3})
+
+		detail, subjectGroups := getCached()
+		expected := []types.ThinSubjectGroup{{PK: 100, PolicyExpiredAt: 3}}
+		assert.Equal(GinkgoT(), expected, detail.SubjectGroups)
+		assert.Equal(GinkgoT(), expected, subjectGroups)
+	})
+
+	It("BatchRemoveSubjectGroup", func() {
+		BatchRemoveSubjectGroup([]int64{1}, 100)
+
+		detail, subjectGroups := getCached()
+		assert.Empty(GinkgoT(), detail.SubjectGroups)
+		assert.Empty(GinkgoT(), subjectGroups)
+	})
+
+	It("handleSubjectChangeEvent member added", func() {
+		patches := gomonkey.ApplyFunc(GetSubjectPK, func(_type, id string) (int64, error) {
+			return 200, nil
+		})
+		defer patches.Reset()
+		patches.ApplyFunc(AdjustGroupMemberCount, func(_type, id string, delta int64) {})
+
+		handleSubjectChangeEvent(service.SubjectChangeEvent{
+			Type:            service.SubjectChangeEventTypeMember,
+			SubjectPKs:      []int64{1},
+			Group:           &types.Subject{Type: "group", ID: "200"},
+			MemberDelta:     1,
+			PolicyExpiredAt: 2,
+		})
+
+		detail, subjectGroups := getCached()
+		expected := []types.ThinSubjectGroup{{PK: 100, PolicyExpiredAt: 1}, {PK: 200, PolicyExpiredAt: 2}}
+		assert.Equal(GinkgoT(), expected, detail.SubjectGroups)
+		assert.Equal(GinkgoT(), expected, subjectGroups)
+	})
+})
@@ -13,6 +13,7 @@ package impls
 import (
 	"errors"
 	"reflect"
+	"testing"
 	"time"
 
 	"github.com/agiledragon/gomonkey"
@@ -21,6 +22,8 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"iam/pkg/cache"
+	"iam/pkg/cache/memory"
+	"iam/pkg/cache/memory/backend"
 	"iam/pkg/cache/redis"
 	"iam/pkg/service"
 	"iam/pkg/service/mock"
@@ -36,13 +39,14 @@ var _ = Describe("SubjectGroups", func() {
 		mockCache := redis.NewMockCache("mockCache", expiration)
 
 		SubjectGroupCache = mockCache
+		LocalSubjectGroupCache = memory.NewMockCache(retrieveLocalSubjectGroups)
 	})
 
 	It("GetSubjectGroups", func() {
 		ctl := gomock.NewController(GinkgoT())
 		defer ctl.Finish()
 
-		mockService := mock.NewMockSubjectService(ctl)
+		mockService := mock.NewMockSubjectGroupReadService(ctl)
 		mockService.EXPECT().GetThinSubjectGroups(int64(1)).Return([]types.ThinSubjectGroup{
 			{
 				PK:              int64(1),
@@ -50,8 +54,8 @@ var _ = Describe("SubjectGroups", func() {
 			},
 		}, nil).AnyTimes()
 
-		patches := gomonkey.ApplyFunc(service.NewSubjectService,
-			func() service.SubjectService {
+		patches := gomonkey.ApplyFunc(service.NewSubjectGroupReadService,
+			func() service.SubjectGroupReadService {
 				return mockService
 			})
 		defer patches.Reset()
@@ -64,7 +68,7 @@ var _ = Describe("SubjectGroups", func() {
 	Context("ListSubjectEffectGroups", func() {
 		It("batchGetSubjectGroups fail", func() {
 			patches := gomonkey.ApplyFunc(batchGetSubjectGroups,
-				func([]int64) ([]types.ThinSubjectGroup, []int64, error) {
+				func([]int64) (map[int64][]types.ThinSubjectGroup, []int64, error) {
 					return nil, nil, errors.New("error")
 				})
 			defer patches.Reset()
@@ -76,16 +80,16 @@ var _ = Describe("SubjectGroups", func() {
 
 		It("batchGetSubjectGroups ok, all cached", func() {
 			patches := gomonkey.ApplyFunc(batchGetSubjectGroups,
-				func([]int64) ([]types.ThinSubjectGroup, []int64, error) {
-					return []types.ThinSubjectGroup{
-						{
+				func([]int64) (map[int64][]types.ThinSubjectGroup, []int64, error) {
+					return map[int64][]types.ThinSubjectGroup{
+						2: {{
 							PK:              2,
 							PolicyExpiredAt: 21,
-						},
-						{
+						}},
+						3: {{
 							PK:              3,
 							PolicyExpiredAt: 31,
-						},
+						}},
 					}, []int64{}, nil
 				})
 			defer patches.Reset()
@@ -103,16 +107,16 @@ var _ = Describe("SubjectGroups", func() {
 			BeforeEach(func() {
 				ctl = gomock.NewController(GinkgoT())
 				patches = gomonkey.ApplyFunc(batchGetSubjectGroups,
-					func([]int64) ([]types.ThinSubjectGroup, []int64, error) {
-						return []types.ThinSubjectGroup{
-							{
+					func([]int64) (map[int64][]types.ThinSubjectGroup, []int64, error) {
+						return map[int64][]types.ThinSubjectGroup{
+							2: {{
 								PK:              2,
 								PolicyExpiredAt: 21,
-							},
-							{
+							}},
+							3: {{
 								PK:              3,
 								PolicyExpiredAt: 31,
-							},
+							}},
 						}, []int64{1}, nil
 					})
 
@@ -124,12 +128,12 @@ var _ = Describe("SubjectGroups", func() {
 			})
 
 			It("has no cached, get from database fail", func() {
-				mockService := mock.NewMockSubjectService(ctl)
+				mockService := mock.NewMockSubjectGroupReadService(ctl)
 				mockService.EXPECT().ListSubjectEffectGroups([]int64{1}).Return(
 					nil, errors.New("error")).AnyTimes()
 
-				patches.ApplyFunc(service.NewSubjectService,
-					func() service.SubjectService {
+				patches.ApplyFunc(service.NewSubjectGroupReadService,
+					func() service.SubjectGroupReadService {
 						return mockService
 					})
 
@@ -139,7 +143,7 @@ var _ = Describe("SubjectGroups", func() {
 				assert.Contains(GinkgoT(), err.Error(), "SubjectService.ListSubjectEffectGroups")
 			})
 			It("has no cached, get from database success", func() {
-				mockService := mock.NewMockSubjectService(ctl)
+				mockService := mock.NewMockSubjectGroupReadService(ctl)
 				mockService.EXPECT().ListSubjectEffectGroups([]int64{1}).Return(
 					map[int64][]types.ThinSubjectGroup{
 						int64(1): {
@@ -150,8 +154,8 @@ var _ = Describe("SubjectGroups", func() {
 						},
 					}, nil).AnyTimes()
 
-				patches.ApplyFunc(service.NewSubjectService,
-					func() service.SubjectService {
+				patches.ApplyFunc(service.NewSubjectGroupReadService,
+					func() service.SubjectGroupReadService {
 						return mockService
 					})
 
@@ -162,12 +166,12 @@ var _ = Describe("SubjectGroups", func() {
 			})
 
 			It("has no cached, get from database success, has empty cached", func() {
-				mockService := mock.NewMockSubjectService(ctl)
+				mockService := mock.NewMockSubjectGroupReadService(ctl)
 				mockService.EXPECT().ListSubjectEffectGroups([]int64{1}).Return(
 					map[int64][]types.ThinSubjectGroup{}, nil).AnyTimes()
 
-				patches.ApplyFunc(service.NewSubjectService,
-					func() service.SubjectService {
+				patches.ApplyFunc(service.NewSubjectGroupReadService,
+					func() service.SubjectGroupReadService {
 						return mockService
 					})
 
@@ -181,6 +185,67 @@ var _ = Describe("SubjectGroups", func() {
 
 	})
 
+	Context("ListSubjectEffectGroups with local cache", func() {
+		It("read from redis, then from local cache", func() {
+			err := SubjectGroupCache.Set(SubjectPKCacheKey{PK: 1}, []types.ThinSubjectGroup{{PK: 10}}, 0)
+			assert.NoError(GinkgoT(), err)
+
+			sgs, err := ListSubjectEffectGroups([]int64{1})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.ThinSubjectGroup{{PK: 10}}, sgs)
+
+			// redis changed, still hit the local cache
+			err = SubjectGroupCache.Set(SubjectPKCacheKey{PK: 1}, []types.ThinSubjectGroup{{PK: 20}}, 0)
+			assert.NoError(GinkgoT(), err)
+			sgs, err = ListSubjectEffectGroups([]int64{1})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.ThinSubjectGroup{{PK: 10}}, sgs)
+
+			// deleted by the subject cache deleter
+			SubjectDetailCache = redis.NewMockCache("mockCache", 5*time.Minute)
+			assert.NoError(GinkgoT(), subjectCacheDeleter{}.Execute(SubjectPKCacheKey{PK: 1}))
+			_, found := LocalSubjectGroupCache.DirectGet(SubjectPKCacheKey{PK: 1})
+			assert.False(GinkgoT(), found)
+		})
+
+		It("retrieve from database, set to redis and local cache in chunks", func() {
+			ctl := gomock.NewController(GinkgoT())
+			defer ctl.Finish()
+
+			pks := make([]int64, 0, subjectGroupPipelineChunkSize+10)
+			for i := 1; i <= subjectGroupPipelineChunkSize+10; i++ {
+				pks = append(pks, int64(i))
+			}
+
+			mockService := mock.NewMockSubjectGroupReadService(ctl)
+			mockService.EXPECT().ListSubjectEffectGroups(pks).Return(
+				map[int64][]types.ThinSubjectGroup{
+					1: {{PK: 10, PolicyExpiredAt: 100}},
+				}, nil).Times(1)
+			patches := gomonkey.ApplyFunc(service.NewSubjectGroupReadService,
+				func() service.SubjectGroupReadService {
+					return mockService
+				})
+			defer patches.Reset()
+
+			sgs, err := ListSubjectEffectGroups(pks)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.ThinSubjectGroup{{PK: 10, PolicyExpiredAt: 100}}, sgs)
+
+			// all set to redis, including the empty ones
+			cached, notExistCachePKs, err := batchGetSubjectGroups(pks)
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), notExistCachePKs)
+			assert.Len(GinkgoT(), cached, len(pks))
+
+			// all set to local cache, will not query the database again
+			sgs, err = ListSubjectEffectGroups(pks)
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), sgs, 1)
+			assert.Equal(GinkgoT(), len(pks), LocalSubjectGroupCache.Len())
+		})
+	})
+
 	Context("batchGetSubjectGroups", func() {
 		It("SubjectGroupCache.BatchGet empty", func() {
 			var (
@@ -264,3 +329,36 @@ var _ = Describe("SubjectGroups", func() {
 	})
 
 })
+
+func TestChunkInt64s(t *testing.T) {
+	assert.Empty(t, chunkInt64s(nil, 2))
+	assert.Equal(t, [][]int64{{1, 2}, {3}}, chunkInt64s([]int64{1, 2, 3}, 2))
+	assert.Equal(t, [][]int64{{1, 2}}, chunkInt64s([]int64{1, 2}, 2))
+}
+
+func benchmarkListSubjectEffectGroups(b *testing.B, localCacheDisabled bool) {
+	SubjectGroupCache = redis.NewMockCache("mockCache", 5*time.Minute)
+	LocalSubjectGroupCache = memory.NewBaseCache(localCacheDisabled, retrieveLocalSubjectGroups,
+		backend.NewMemoryBackend("benchmark", 10*time.Second))
+
+	// a user in 200 departments
+	pks := make([]int64, 0, 200)
+	for i := 1; i <= 200; i++ {
+		pk := int64(i)
+		pks = append(pks, pk)
+		SubjectGroupCache.Set(SubjectPKCacheKey{PK: pk}, []types.ThinSubjectGroup{{PK: pk, PolicyExpiredAt: 1}}, 0)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ListSubjectEffectGroups(pks)
+	}
+}
+
+func BenchmarkListSubjectEffectGroupsRedis(b *testing.B) {
+	benchmarkListSubjectEffectGroups(b, true)
+}
+
+func BenchmarkListSubjectEffectGroupsLocalCache(b *testing.B) {
+	benchmarkListSubjectEffectGroups(b, false)
+}
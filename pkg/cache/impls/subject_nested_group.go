@@ -0,0 +1,100 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/service/types"
+)
+
+/*
+ * > 用户组可以加入用户组(group-in-group), 鉴权时需要展开用户组加入的用户组
+ *
+ * 1. 逐层批量查询用户组加入的用户组, 复用subject用户组的本地缓存/redis缓存
+ * 2. 最多展开 MaxNestedGroupDepth 层(包含直接加入的用户组), 超过的部分不生效
+ * 3. 嵌套加入的用户组的过期时间取路径上最小的过期时间, 多条路径时取最大的
+ */
+
+// MaxNestedGroupDepth 用户组嵌套展开的最大层数, 与subject_system_group的维护保持一致
+const MaxNestedGroupDepth = service.MaxNestedGroupDepth
+
+// ExpandNestedGroups 展开用户组加入的用户组, 返回的结果包含传入的用户组
+func ExpandNestedGroups(groups []types.ThinSubjectGroup) ([]types.ThinSubjectGroup, error) {
+	if len(groups) == 0 {
+		return groups, nil
+	}
+
+	// pk => expiredAt, 保持发现的顺序
+	expiredAts := make(map[int64]int64, len(groups))
+	pks := make([]int64, 0, len(groups))
+	mergeGroup := func(pk, expiredAt int64) bool {
+		oldExpiredAt, ok := expiredAts[pk]
+		if ok && oldExpiredAt >= expiredAt {
+			return false
+		}
+		if !ok {
+			pks = append(pks, pk)
+		}
+		expiredAts[pk] = expiredAt
+		return true
+	}
+
+	frontier := make(map[int64]int64, len(groups))
+	for _, g := range groups {
+		if mergeGroup(g.PK, g.PolicyExpiredAt) {
+			frontier[g.PK] = g.PolicyExpiredAt
+		}
+	}
+
+	nested := false
+	for depth := 1; depth < MaxNestedGroupDepth && len(frontier) > 0; depth++ {
+		frontierPKs := make([]int64, 0, len(frontier))
+		for pk := range frontier {
+			frontierPKs = append(frontierPKs, pk)
+		}
+
+		pkSubjectGroups, err := BatchListSubjectEffectGroups(frontierPKs)
+		if err != nil {
+			return nil, errorx.Wrapf(err, CacheLayer, "ExpandNestedGroups",
+				"BatchListSubjectEffectGroups pks=`%+v` fail", frontierPKs)
+		}
+
+		next := make(map[int64]int64)
+		for pk, expiredAt := range frontier {
+			for _, parent := range pkSubjectGroups[pk] {
+				parentExpiredAt := parent.PolicyExpiredAt
+				if expiredAt < parentExpiredAt {
+					parentExpiredAt = expiredAt
+				}
+
+				if mergeGroup(parent.PK, parentExpiredAt) {
+					next[parent.PK] = expiredAts[parent.PK]
+					nested = true
+				}
+			}
+		}
+		frontier = next
+	}
+
+	// 没有嵌套的用户组, 直接返回
+	if !nested {
+		return groups, nil
+	}
+
+	expanded := make([]types.ThinSubjectGroup, 0, len(pks))
+	for _, pk := range pks {
+		expanded = append(expanded, types.ThinSubjectGroup{
+			PK:              pk,
+			PolicyExpiredAt: expiredAts[pk],
+		})
+	}
+	return expanded, nil
+}
@@ -0,0 +1,109 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"errors"
+
+	"github.com/agiledragon/gomonkey"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("ExpandNestedGroups", func() {
+	var patches *gomonkey.Patches
+	AfterEach(func() {
+		if patches != nil {
+			patches.Reset()
+		}
+	})
+
+	It("empty", func() {
+		groups, err := ExpandNestedGroups([]types.ThinSubjectGroup{})
+		assert.NoError(GinkgoT(), err)
+		assert.Empty(GinkgoT(), groups)
+	})
+
+	It("BatchListSubjectEffectGroups fail", func() {
+		patches = gomonkey.ApplyFunc(BatchListSubjectEffectGroups,
+			func(pks []int64) (map[int64][]types.ThinSubjectGroup, error) {
+				return nil, errors.New("list fail")
+			})
+
+		_, err := ExpandNestedGroups([]types.ThinSubjectGroup{{PK: 1, PolicyExpiredAt: 10}})
+		assert.Error(GinkgoT(), err)
+		assert.Contains(GinkgoT(), err.Error(), "list fail")
+	})
+
+	It("no nested groups", func() {
+		patches = gomonkey.ApplyFunc(BatchListSubjectEffectGroups,
+			func(pks []int64) (map[int64][]types.ThinSubjectGroup, error) {
+				return map[int64][]types.ThinSubjectGroup{}, nil
+			})
+
+		groups := []types.ThinSubjectGroup{{PK: 1, PolicyExpiredAt: 10}, {PK: 2, PolicyExpiredAt: 20}}
+		expanded, err := ExpandNestedGroups(groups)
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), groups, expanded)
+	})
+
+	It("ok, min expired_at on the path, max of the paths", func() {
+		// 1 => 3(100), 2 => 3(15), 3 => 4(100)
+		parents := map[int64][]types.ThinSubjectGroup{
+			1: {{PK: 3, PolicyExpiredAt: 100}},
+			2: {{PK: 3, PolicyExpiredAt: 15}},
+			3: {{PK: 4, PolicyExpiredAt: 100}},
+		}
+		patches = gomonkey.ApplyFunc(BatchListSubjectEffectGroups,
+			func(pks []int64) (map[int64][]types.ThinSubjectGroup, error) {
+				result := make(map[int64][]types.ThinSubjectGroup, len(pks))
+				for _, pk := range pks {
+					result[pk] = parents[pk]
+				}
+				return result, nil
+			})
+
+		expanded, err := ExpandNestedGroups([]types.ThinSubjectGroup{
+			{PK: 1, PolicyExpiredAt: 10},
+			{PK: 2, PolicyExpiredAt: 20},
+		})
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), []types.ThinSubjectGroup{
+			{PK: 1, PolicyExpiredAt: 10},
+			{PK: 2, PolicyExpiredAt: 20},
+			{PK: 3, PolicyExpiredAt: 15},
+			{PK: 4, PolicyExpiredAt: 15},
+		}, expanded)
+	})
+
+	It("bounded depth and cycle", func() {
+		// 1 => 2 => 3 => ... , 2 => 1
+		calls := 0
+		patches = gomonkey.ApplyFunc(BatchListSubjectEffectGroups,
+			func(pks []int64) (map[int64][]types.ThinSubjectGroup, error) {
+				calls++
+				result := make(map[int64][]types.ThinSubjectGroup, len(pks))
+				for _, pk := range pks {
+					result[pk] = []types.ThinSubjectGroup{{PK: pk + 1, PolicyExpiredAt: 10}}
+					if pk == 2 {
+						result[pk] = append(result[pk], types.ThinSubjectGroup{PK: 1, PolicyExpiredAt: 10})
+					}
+				}
+				return result, nil
+			})
+
+		expanded, err := ExpandNestedGroups([]types.ThinSubjectGroup{{PK: 1, PolicyExpiredAt: 10}})
+		assert.NoError(GinkgoT(), err)
+		assert.Len(GinkgoT(), expanded, MaxNestedGroupDepth)
+		assert.Equal(GinkgoT(), MaxNestedGroupDepth-1, calls)
+	})
+})
@@ -11,6 +11,12 @@
 package impls
 
 import (
+	"database/sql"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
 	"iam/pkg/cache"
 	"iam/pkg/errorx"
 	"iam/pkg/service"
@@ -29,10 +35,22 @@ func (k SubjectIDCacheKey) Key() string {
 
 func retrieveSubjectPK(key cache.Key) (interface{}, error) {
 	k := key.(SubjectIDCacheKey)
-	svc := service.NewSubjectService()
+	svc := service.NewSubjectBaseReadService()
 	return svc.GetPK(k.Type, k.ID)
 }
 
+// missingSubjectPK 不存在的subject在缓存中的占位值, 真实的pk从1开始
+const missingSubjectPK int64 = -1
+
+// SubjectPKMissingExpiration 不存在的subject的空值缓存时间
+// NOTE: 避免爬虫等流量使用大量不存在的用户反复查询DB; 创建subject时会主动清理
+var SubjectPKMissingExpiration = 1 * time.Minute
+
+func retrieveLocalSubjectPK(key cache.Key) (interface{}, error) {
+	k := key.(SubjectIDCacheKey)
+	return GetSubjectPK(k.Type, k.ID)
+}
+
 // GetSubjectPK ...
 func GetSubjectPK(_type, id string) (pk int64, err error) {
 	key := SubjectIDCacheKey{
@@ -40,6 +58,16 @@ func GetSubjectPK(_type, id string) (pk int64, err error) {
 		ID:   id,
 	}
 	err = SubjectPKCache.GetInto(key, &pk, retrieveSubjectPK)
+	if errors.Is(err, sql.ErrNoRows) {
+		// subject不存在, 缓存空值
+		errNotImportant := SubjectPKCache.Set(key, missingSubjectPK, SubjectPKMissingExpiration)
+		if errNotImportant != nil {
+			log.Errorf("set missing subject pk to redis fail, key=%s, err=%s", key.Key(), errNotImportant)
+		}
+	} else if err == nil && pk == missingSubjectPK {
+		pk, err = 0, sql.ErrNoRows
+	}
+
 	if err != nil {
 		err = errorx.Wrapf(err, CacheLayer, "GetSubjectPK",
 			"SubjectPKCache.Get _type=`%s`, id=`%s` fail", _type, id)
@@ -11,12 +11,16 @@
 package impls
 
 import (
+	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
+	"iam/pkg/cache/memory"
 	"iam/pkg/cache/redis"
 	"iam/pkg/service"
 	"iam/pkg/service/mock"
+	"iam/pkg/service/types"
 
 	"github.com/agiledragon/gomonkey"
 	"github.com/golang/mock/gomock"
@@ -39,11 +43,11 @@ func TestGetSubjectPK(t *testing.T) {
 		expiration = 5 * time.Minute
 	)
 
-	mockService := mock.NewMockSubjectService(ctl)
+	mockService := mock.NewMockSubjectBaseReadService(ctl)
 	mockService.EXPECT().GetPK("user", "admin").Return(int64(64), nil).AnyTimes()
 
-	patches := gomonkey.ApplyFunc(service.NewSubjectService,
-		func() service.SubjectService {
+	patches := gomonkey.ApplyFunc(service.NewSubjectBaseReadService,
+		func() service.SubjectBaseReadService {
 			return mockService
 		})
 	defer patches.Reset()
@@ -56,3 +60,50 @@ func TestGetSubjectPK(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, int64(64), pk)
 }
+
+func TestGetSubjectPKMissing(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockService := mock.NewMockSubjectBaseReadService(ctl)
+	// the missing subject will only be retrieved once
+	mockService.EXPECT().GetPK("user", "notexist").Return(int64(0), sql.ErrNoRows).Times(1)
+
+	patches := gomonkey.ApplyFunc(service.NewSubjectBaseReadService,
+		func() service.SubjectBaseReadService {
+			return mockService
+		})
+	defer patches.Reset()
+
+	SubjectPKCache = redis.NewMockCache("mockCache", 5*time.Minute)
+	LocalSubjectPKCache = memory.NewCache("mockLocalCache", false, retrieveLocalSubjectPK, 5*time.Minute)
+	GroupMemberCountCache = redis.NewMockCache("mockGroupMemberCountCache", 5*time.Minute)
+
+	_, err := GetSubjectPK("user", "notexist")
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+
+	pk, err := GetSubjectPK("user", "notexist")
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+	assert.Equal(t, int64(0), pk)
+
+	// created, the missing cache should be deleted
+	handleSubjectChangeEvent(service.SubjectChangeEvent{
+		Type:     service.SubjectChangeEventTypeSubject,
+		Subjects: []types.Subject{{Type: "user", ID: "notexist"}},
+	})
+	assert.False(t, SubjectPKCache.Exists(SubjectIDCacheKey{Type: "user", ID: "notexist"}))
+}
+
+func TestBatchDeleteSubjectPK(t *testing.T) {
+	SubjectPKCache = redis.NewMockCache("mockCache", 5*time.Minute)
+	LocalSubjectPKCache = memory.NewCache("mockLocalCache", false, retrieveSubjectPK, 5*time.Minute)
+
+	key := SubjectIDCacheKey{Type: "user", ID: "admin"}
+	assert.NoError(t, SubjectPKCache.Set(key, int64(64), 0))
+	LocalSubjectPKCache.Set(key, int64(64))
+
+	err := BatchDeleteSubjectPK([]types.Subject{{Type: "user", ID: "admin"}, {Type: "group", ID: "1"}})
+	assert.NoError(t, err)
+	assert.False(t, SubjectPKCache.Exists(key))
+	assert.False(t, LocalSubjectPKCache.Exists(key))
+}
@@ -0,0 +1,152 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"math/rand"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/cache"
+	"iam/pkg/cache/redis"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+/*
+ * > subject在系统下有策略的用户组, hash key = {subject_pk}, field = {system}
+ *
+ * 1. redis分批pipeline获取 2. DB批量查询, 并分批回写redis, 没有用户组的写入空列表, 防止重复查询DB
+ * 3. subject_system_group的记录变更后, 按subject pk删除整个hash
+ */
+
+// ListSubjectSystemGroups 批量获取subject在系统下有策略的用户组(包括已过期的), 返回 pk => 用户组
+func ListSubjectSystemGroups(systemID string, pks []int64) (map[int64][]types.ThinSubjectGroup, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(CacheLayer, "ListSubjectSystemGroups")
+
+	subjectGroups, notExistCachePKs, err := batchGetSubjectSystemGroups(systemID, pks)
+	if err != nil {
+		return nil, errorWrapf(err, "batchGetSubjectSystemGroups systemID=`%s`, pks=`%+v` fail", systemID, pks)
+	}
+	if len(notExistCachePKs) == 0 {
+		return subjectGroups, nil
+	}
+
+	svc := service.NewSubjectSystemGroupService()
+	notCachedSubjectGroups, err := svc.ListSubjectSystemGroups(systemID, notExistCachePKs)
+	if err != nil {
+		return nil, errorWrapf(err, "SubjectSystemGroupService.ListSubjectSystemGroups systemID=`%s`, pks=`%+v` fail",
+			systemID, notExistCachePKs)
+	}
+	setMissingSubjectSystemGroups(systemID, notCachedSubjectGroups, notExistCachePKs)
+	for pk, sgs := range notCachedSubjectGroups {
+		subjectGroups[pk] = sgs
+	}
+	return subjectGroups, nil
+}
+
+// batchGetSubjectSystemGroups 从redis分批获取, 返回命中的 pk => 用户组 及未命中的pks
+func batchGetSubjectSystemGroups(
+	systemID string, pks []int64,
+) (subjectGroups map[int64][]types.ThinSubjectGroup, notExistCachePKs []int64, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(CacheLayer, "batchGetSubjectSystemGroups")
+
+	subjectGroups = make(map[int64][]types.ThinSubjectGroup, len(pks))
+	for _, chunkPKs := range chunkInt64s(pks, subjectGroupPipelineChunkSize) {
+		hashKeyFields := make([]redis.HashKeyField, 0, len(chunkPKs))
+		for _, pk := range chunkPKs {
+			hashKeyFields = append(hashKeyFields, redis.HashKeyField{
+				Key:   strconv.FormatInt(pk, 10),
+				Field: systemID,
+			})
+		}
+		hitValues, err := SubjectSystemGroupCache.BatchHGet(hashKeyFields)
+		if err != nil {
+			err = errorWrapf(err, "SubjectSystemGroupCache.BatchHGet hashKeyFields=`%+v` fail", hashKeyFields)
+			return nil, nil, err
+		}
+
+		for _, hkf := range hashKeyFields {
+			pk, _ := strconv.ParseInt(hkf.Key, 10, 64)
+			data, ok := hitValues[hkf]
+			if !ok {
+				notExistCachePKs = append(notExistCachePKs, pk)
+				continue
+			}
+
+			var sgs []types.ThinSubjectGroup
+			err = SubjectSystemGroupCache.Unmarshal(util.StringToBytes(data), &sgs)
+			if err != nil {
+				err = errorWrapf(err, "unmarshal text in cache into SubjectSystemGroup fail", "")
+				return nil, nil, err
+			}
+			subjectGroups[pk] = sgs
+		}
+	}
+	return subjectGroups, notExistCachePKs, nil
+}
+
+// setMissingSubjectSystemGroups 分批回写redis, 没有用户组的pk写入空列表
+func setMissingSubjectSystemGroups(
+	systemID string, notCachedSubjectGroups map[int64][]types.ThinSubjectGroup, missingPKs []int64,
+) {
+	for _, chunkPKs := range chunkInt64s(missingPKs, subjectGroupPipelineChunkSize) {
+		hashes := make([]redis.Hash, 0, len(chunkPKs))
+		keys := make([]cache.Key, 0, len(chunkPKs))
+		for _, pk := range chunkPKs {
+			sgs, ok := notCachedSubjectGroups[pk]
+			if !ok {
+				sgs = []types.ThinSubjectGroup{}
+			}
+
+			key := SubjectPKCacheKey{PK: pk}
+			value, err := SubjectSystemGroupCache.Marshal(sgs)
+			if err != nil {
+				log.Errorf("marshal subject_system_group fail, key=%s, err=%s", key.Key(), err)
+				continue
+			}
+			hashes = append(hashes, redis.Hash{
+				HashKeyField: redis.HashKeyField{
+					Key:   key.Key(),
+					Field: systemID,
+				},
+				Value: util.BytesToString(value),
+			})
+			keys = append(keys, key)
+		}
+
+		err := SubjectSystemGroupCache.BatchHSetWithTx(hashes)
+		if err != nil {
+			log.Errorf("batch hset subject_system_group to redis fail, system=%s, pks=%v, err=%s",
+				systemID, chunkPKs, err)
+			continue
+		}
+
+		// NOTE: 随机的过期时间, 防止同时过期
+		err = SubjectSystemGroupCache.BatchExpireWithTx(keys,
+			SubjectSystemGroupCacheExpiration+time.Duration(rand.Intn(60))*time.Second)
+		if err != nil {
+			log.Errorf("batch expire subject_system_group in redis fail, pks=%v, err=%s", chunkPKs, err)
+		}
+	}
+}
+
+// BatchDeleteSubjectSystemGroups 删除subject所有系统的缓存
+func BatchDeleteSubjectSystemGroups(pks []int64) error {
+	keys := make([]cache.Key, 0, len(pks))
+	for _, pk := range pks {
+		keys = append(keys, SubjectPKCacheKey{PK: pk})
+	}
+	return SubjectSystemGroupCache.BatchDelete(keys)
+}
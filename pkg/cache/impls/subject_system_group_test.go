@@ -0,0 +1,109 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"errors"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache/redis"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectSystemGroups", func() {
+	var ctl *gomock.Controller
+	var patches *gomonkey.Patches
+	var mockService *mock.MockSubjectSystemGroupService
+
+	BeforeEach(func() {
+		SubjectSystemGroupCache = redis.NewMockCache("mockCache", 5*time.Minute)
+
+		ctl = gomock.NewController(GinkgoT())
+		mockService = mock.NewMockSubjectSystemGroupService(ctl)
+		patches = gomonkey.ApplyFunc(service.NewSubjectSystemGroupService,
+			func() service.SubjectSystemGroupService {
+				return mockService
+			})
+	})
+
+	AfterEach(func() {
+		ctl.Finish()
+		patches.Reset()
+	})
+
+	It("service fail", func() {
+		mockService.EXPECT().ListSubjectSystemGroups("test", []int64{1}).Return(nil, errors.New("list fail"))
+
+		_, err := ListSubjectSystemGroups("test", []int64{1})
+		assert.Error(GinkgoT(), err)
+		assert.Contains(GinkgoT(), err.Error(), "list fail")
+	})
+
+	It("ok, cache the missing subjects", func() {
+		mockService.EXPECT().ListSubjectSystemGroups("test", []int64{1, 2}).Return(
+			map[int64][]types.ThinSubjectGroup{
+				1: {{PK: 10, PolicyExpiredAt: 2000}},
+			}, nil).Times(1)
+
+		expected := map[int64][]types.ThinSubjectGroup{
+			1: {{PK: 10, PolicyExpiredAt: 2000}},
+			2: {},
+		}
+
+		subjectGroups, err := ListSubjectSystemGroups("test", []int64{1, 2})
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), map[int64][]types.ThinSubjectGroup{
+			1: {{PK: 10, PolicyExpiredAt: 2000}},
+		}, subjectGroups)
+
+		// hit the cache
+		subjectGroups, err = ListSubjectSystemGroups("test", []int64{1, 2})
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), expected, subjectGroups)
+	})
+
+	It("ok, cached by system", func() {
+		mockService.EXPECT().ListSubjectSystemGroups("test", []int64{1}).Return(
+			map[int64][]types.ThinSubjectGroup{1: {{PK: 10, PolicyExpiredAt: 2000}}}, nil).Times(1)
+		mockService.EXPECT().ListSubjectSystemGroups("other", []int64{1}).Return(
+			map[int64][]types.ThinSubjectGroup{1: {{PK: 20, PolicyExpiredAt: 3000}}}, nil).Times(1)
+
+		_, err := ListSubjectSystemGroups("test", []int64{1})
+		assert.NoError(GinkgoT(), err)
+
+		subjectGroups, err := ListSubjectSystemGroups("other", []int64{1})
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), map[int64][]types.ThinSubjectGroup{
+			1: {{PK: 20, PolicyExpiredAt: 3000}},
+		}, subjectGroups)
+	})
+
+	It("BatchDeleteSubjectSystemGroups", func() {
+		mockService.EXPECT().ListSubjectSystemGroups("test", []int64{1}).Return(
+			map[int64][]types.ThinSubjectGroup{1: {{PK: 10, PolicyExpiredAt: 2000}}}, nil).Times(2)
+
+		_, err := ListSubjectSystemGroups("test", []int64{1})
+		assert.NoError(GinkgoT(), err)
+
+		err = BatchDeleteSubjectSystemGroups([]int64{1})
+		assert.NoError(GinkgoT(), err)
+
+		// miss the cache after deleted
+		_, err = ListSubjectSystemGroups("test", []int64{1})
+		assert.NoError(GinkgoT(), err)
+	})
+})
@@ -0,0 +1,34 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"os"
+	"time"
+
+	"iam/pkg/cache"
+)
+
+// AcquireTaskLock 获取定时任务在一个周期内的锁, 多个实例中只有获取到锁的实例执行任务
+// NOTE: 锁不主动释放, 过期后下一个周期再竞争, 避免多个实例的定时器不同步时同一周期内重复执行
+func AcquireTaskLock(name string, expiration time.Duration) (bool, error) {
+	hostname, _ := os.Hostname()
+	return TaskLockCache.SetNX(cache.NewStringKey(name), hostname, expiration)
+}
+
+// RenewTaskLock 延长任务锁的过期时间, 长时间运行的任务通过续期作为心跳, 实例退出后锁过期由其他实例接管
+func RenewTaskLock(name string, expiration time.Duration) error {
+	return TaskLockCache.BatchExpireWithTx([]cache.Key{cache.NewStringKey(name)}, expiration)
+}
+
+// ReleaseTaskLock 任务执行完成后释放锁
+func ReleaseTaskLock(name string) error {
+	return TaskLockCache.Delete(cache.NewStringKey(name))
+}
@@ -0,0 +1,56 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package impls
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache/redis"
+)
+
+func TestAcquireTaskLock(t *testing.T) {
+	TaskLockCache = redis.NewMockCache("mockCache", 5*time.Minute)
+
+	ok, err := AcquireTaskLock("purge", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// locked by other instance
+	ok, err = AcquireTaskLock("purge", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = AcquireTaskLock("other", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestRenewAndReleaseTaskLock(t *testing.T) {
+	TaskLockCache = redis.NewMockCache("mockCache", 5*time.Minute)
+
+	ok, err := AcquireTaskLock("unbind", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.NoError(t, RenewTaskLock("unbind", time.Minute))
+
+	ok, err = AcquireTaskLock("unbind", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, ReleaseTaskLock("unbind"))
+
+	ok, err = AcquireTaskLock("unbind", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
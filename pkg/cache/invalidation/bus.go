@@ -0,0 +1,119 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package invalidation
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/util"
+)
+
+/*
+ * > 缓存失效的统一入口, 所有handler/service对缓存的删除都应该通过本包
+ *
+ * 1. 同步删除: 调用方直接执行删除, 返回删除的结果
+ * 2. 异步广播: 本地缓存(策略等)的删除, 由各缓存层通过 redis pub/sub 广播给其他实例
+ * 3. 重试: 同步删除失败的, 放入重试队列, 由 Run 在后台按间隔重试, 超过最大次数后上报sentry
+ */
+
+var (
+	retryInterval    = 1 * time.Second
+	retryMaxAttempts = 3
+	retryBufferSize  = 2000
+)
+
+type deletion struct {
+	// 失效的缓存类型, 用于日志
+	kind string
+	// 失效的对象, 用于日志
+	detail string
+	// 执行删除
+	do func() error
+
+	attempts int
+}
+
+var retryQueue = make(chan deletion, retryBufferSize)
+
+// invalidate 同步执行删除, 失败时放入重试队列
+func invalidate(kind, detail string, do func() error) error {
+	d := deletion{kind: kind, detail: detail, do: do}
+	err := execute(&d)
+	if err != nil {
+		enqueueRetry(d)
+	}
+	return err
+}
+
+func execute(d *deletion) error {
+	d.attempts++
+
+	err := d.do()
+	if err != nil {
+		log.WithError(err).Errorf("invalidate %s cache fail, detail=`%s`, attempts=%d", d.kind, d.detail, d.attempts)
+	}
+	return err
+}
+
+func enqueueRetry(d deletion) {
+	if d.attempts >= retryMaxAttempts {
+		util.ReportToSentry(
+			"cache error: invalidate fail",
+			map[string]interface{}{
+				"kind":     d.kind,
+				"detail":   d.detail,
+				"attempts": d.attempts,
+			},
+		)
+		return
+	}
+
+	select {
+	case retryQueue <- d:
+	default:
+		log.Errorf("the invalidation retry queue is full, drop the %s cache invalidation, detail=`%s`",
+			d.kind, d.detail)
+	}
+}
+
+// Run 后台重试失败的删除, 阻塞直到ctx结束
+func Run(ctx context.Context) {
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			retryPending()
+		}
+	}
+}
+
+// retryPending 重试当前队列中所有的删除, 重试中再次失败的等待下一轮
+func retryPending() {
+	for n := len(retryQueue); n > 0; n-- {
+		var d deletion
+		select {
+		case d = <-retryQueue:
+		default:
+			return
+		}
+
+		if err := execute(&d); err != nil {
+			enqueueRetry(d)
+		}
+	}
+}
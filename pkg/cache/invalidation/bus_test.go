@@ -0,0 +1,125 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package invalidation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache/impls"
+	"iam/pkg/service"
+)
+
+func resetRetryQueue() {
+	retryQueue = make(chan deletion, retryBufferSize)
+}
+
+func TestInvalidate(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		resetRetryQueue()
+
+		calls := 0
+		err := invalidate("test", "ok", func() error {
+			calls++
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Len(t, retryQueue, 0)
+	})
+
+	t.Run("retry until success", func(t *testing.T) {
+		resetRetryQueue()
+
+		calls := 0
+		err := invalidate("test", "retry", func() error {
+			calls++
+			if calls < 2 {
+				return errors.New("delete fail")
+			}
+			return nil
+		})
+		assert.Error(t, err)
+		assert.Len(t, retryQueue, 1)
+
+		retryPending()
+		assert.Equal(t, 2, calls)
+		assert.Len(t, retryQueue, 0)
+	})
+
+	t.Run("give up after max attempts", func(t *testing.T) {
+		resetRetryQueue()
+
+		calls := 0
+		err := invalidate("test", "fail", func() error {
+			calls++
+			return errors.New("delete fail")
+		})
+		assert.Error(t, err)
+
+		for i := 0; i < retryMaxAttempts; i++ {
+			retryPending()
+		}
+		assert.Equal(t, retryMaxAttempts, calls)
+		assert.Len(t, retryQueue, 0)
+	})
+
+	t.Run("queue full", func(t *testing.T) {
+		retryQueue = make(chan deletion, 1)
+		defer resetRetryQueue()
+
+		fail := func() error {
+			return errors.New("delete fail")
+		}
+		assert.Error(t, invalidate("test", "a", fail))
+		assert.Error(t, invalidate("test", "b", fail))
+		assert.Len(t, retryQueue, 1)
+	})
+}
+
+func TestDeleteEmpty(t *testing.T) {
+	assert.NoError(t, DeleteSubjects(nil))
+	assert.NoError(t, DeleteSubjectPKs(nil))
+	assert.NoError(t, DeleteSystemSubjectPolicies("test", nil))
+	assert.NoError(t, BatchDeleteSystemSubjectPolicies(nil, []int64{1}))
+	assert.NoError(t, DeleteExpressions(nil))
+	assert.NoError(t, DeleteActions("test", nil))
+	assert.NoError(t, DeleteResourceTypes("test", nil))
+}
+
+func TestHandleSystemModelChangeEvent(t *testing.T) {
+	resetRetryQueue()
+
+	var deleted []string
+	patches := gomonkey.ApplyFunc(impls.DeleteSystemActionIndex, func(systemID string) error {
+		deleted = append(deleted, systemID)
+		return errors.New("publish fail")
+	})
+	defer patches.Reset()
+
+	handleSystemModelChangeEvent(service.SystemModelChangeEvent{
+		Type:     "resource_type",
+		SystemID: "test",
+	})
+	assert.Empty(t, deleted)
+
+	handleSystemModelChangeEvent(service.SystemModelChangeEvent{
+		Type:     service.SystemModelChangeEventTypeAction,
+		SystemID: "test",
+		IDs:      []string{"view"},
+	})
+	assert.Equal(t, []string{"test"}, deleted)
+	// 广播失败的, 放入重试队列
+	assert.Len(t, retryQueue, 1)
+}
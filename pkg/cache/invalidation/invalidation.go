@@ -0,0 +1,149 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package invalidation
+
+import (
+	"fmt"
+	"sync"
+
+	"iam/pkg/abac/prp/expression"
+	"iam/pkg/abac/prp/policy"
+	"iam/pkg/cache/impls"
+	"iam/pkg/service"
+	"iam/pkg/service/types"
+)
+
+// 失效的缓存类型
+const (
+	KindSubject                  = "subject"
+	KindSubjectPK                = "subject_pk"
+	KindPolicy                   = "policy"
+	KindExpression               = "expression"
+	KindAction                   = "action"
+	KindResourceType             = "resource_type"
+	KindSystem                   = "system"
+	KindSystemPolicyCacheBackend = "system_policy_cache_backend"
+	KindFrozenSubject            = "frozen_subject"
+	KindSystemActionIndex        = "system_action_index"
+)
+
+// DeleteSubjects 删除subject的缓存 [subjectGroup / subjectDetail]
+func DeleteSubjects(pks []int64) error {
+	if len(pks) == 0 {
+		return nil
+	}
+	return invalidate(KindSubject, fmt.Sprintf("pks=%v", pks), func() error {
+		return impls.BatchDeleteSubjectCache(pks)
+	})
+}
+
+// DeleteSubjectPKs 删除subject type+id => pk 的缓存
+func DeleteSubjectPKs(subjects []types.Subject) error {
+	if len(subjects) == 0 {
+		return nil
+	}
+	return invalidate(KindSubjectPK, fmt.Sprintf("subjects=%+v", subjects), func() error {
+		return impls.BatchDeleteSubjectPK(subjects)
+	})
+}
+
+// DeleteSystemSubjectPolicies 删除系统下subject的策略缓存, 本地缓存的删除会广播给其他实例
+func DeleteSystemSubjectPolicies(system string, subjectPKs []int64) error {
+	if len(subjectPKs) == 0 {
+		return nil
+	}
+	return invalidate(KindPolicy, fmt.Sprintf("system=%s, subject_pks=%v", system, subjectPKs), func() error {
+		return policy.DeleteSystemSubjectPKsFromCache(system, subjectPKs)
+	})
+}
+
+// BatchDeleteSystemSubjectPolicies 删除多个系统下subject的策略缓存, 本地缓存的删除会广播给其他实例
+func BatchDeleteSystemSubjectPolicies(systems []string, subjectPKs []int64) error {
+	if len(systems) == 0 || len(subjectPKs) == 0 {
+		return nil
+	}
+	return invalidate(KindPolicy, fmt.Sprintf("systems=%v, subject_pks=%v", systems, subjectPKs), func() error {
+		return policy.BatchDeleteSystemSubjectPKsFromCache(systems, subjectPKs)
+	})
+}
+
+// DeleteExpressions 删除表达式的缓存, actionPK => expressionPKs
+func DeleteExpressions(actionPKExpressionPKs map[int64][]int64) error {
+	if len(actionPKExpressionPKs) == 0 {
+		return nil
+	}
+	return invalidate(KindExpression, fmt.Sprintf("action_pk_expression_pks=%v", actionPKExpressionPKs), func() error {
+		return expression.BatchDeleteExpressionsFromCache(actionPKExpressionPKs)
+	})
+}
+
+// DeleteActions 删除操作的缓存 [actionPK / actionDetail]
+func DeleteActions(systemID string, actionIDs []string) error {
+	if len(actionIDs) == 0 {
+		return nil
+	}
+	return invalidate(KindAction, fmt.Sprintf("system=%s, action_ids=%v", systemID, actionIDs), func() error {
+		return impls.BatchDeleteActionCache(systemID, actionIDs)
+	})
+}
+
+// DeleteResourceTypes 删除资源类型的缓存
+func DeleteResourceTypes(systemID string, resourceTypeIDs []string) error {
+	if len(resourceTypeIDs) == 0 {
+		return nil
+	}
+	return invalidate(KindResourceType, fmt.Sprintf("system=%s, ids=%v", systemID, resourceTypeIDs), func() error {
+		return impls.BatchDeleteResourceTypeCache(systemID, resourceTypeIDs)
+	})
+}
+
+// DeleteSystem 删除系统的缓存 [system / systemClients]
+func DeleteSystem(systemID string) error {
+	return invalidate(KindSystem, fmt.Sprintf("system=%s", systemID), func() error {
+		return impls.DeleteSystemCache(systemID)
+	})
+}
+
+// DeleteSystemPolicyCacheBackend 删除系统的策略缓存后端配置的本地缓存
+func DeleteSystemPolicyCacheBackend(systemID string) error {
+	return invalidate(KindSystemPolicyCacheBackend, fmt.Sprintf("system=%s", systemID), func() error {
+		return impls.DeleteSystemPolicyCacheBackend(systemID)
+	})
+}
+
+// DeleteFrozenSubjects 删除冻结subject的本地缓存, 会广播给其他实例
+func DeleteFrozenSubjects() error {
+	return invalidate(KindFrozenSubject, "all", func() error {
+		return impls.DeleteFrozenSubjectPKs()
+	})
+}
+
+// DeleteSystemActionIndex 删除系统的操作索引的本地缓存 [systemActionIndex / systemDisabledActions], 会广播给其他实例
+func DeleteSystemActionIndex(systemID string) error {
+	return invalidate(KindSystemActionIndex, fmt.Sprintf("system=%s", systemID), func() error {
+		return impls.DeleteSystemActionIndex(systemID)
+	})
+}
+
+var systemModelChangeHandlerRegisterOnce sync.Once
+
+// RegisterSystemModelChangeHandler 系统模型的写操作成功后, 清理所有实例的操作索引
+func RegisterSystemModelChangeHandler() {
+	systemModelChangeHandlerRegisterOnce.Do(func() {
+		service.RegisterSystemModelChangeHandler(handleSystemModelChangeEvent)
+	})
+}
+
+func handleSystemModelChangeEvent(event service.SystemModelChangeEvent) {
+	if event.Type == service.SystemModelChangeEventTypeAction {
+		DeleteSystemActionIndex(event.SystemID)
+	}
+}
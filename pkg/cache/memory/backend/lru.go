@@ -0,0 +1,176 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package backend
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Limit the size limit of a memory cache, 0 means unlimited
+type Limit struct {
+	MaxEntries int
+	MaxBytes   int64
+}
+
+// Unlimited ...
+func (l Limit) Unlimited() bool {
+	return l.MaxEntries <= 0 && l.MaxBytes <= 0
+}
+
+type lruEntry struct {
+	key       string
+	value     interface{}
+	size      int64
+	expiredAt time.Time
+}
+
+// LRUBackend a memory backend with the size limit, evict the least recently used entries when exceeding the limit
+type LRUBackend struct {
+	name    string
+	limit   Limit
+	metrics cacheMetrics
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+	bytes int64
+
+	defaultExpiration time.Duration
+}
+
+// Set ...
+func (c *LRUBackend) Set(key string, value interface{}, duration time.Duration) {
+	if duration == time.Duration(0) {
+		duration = c.defaultExpiration
+	}
+
+	entry := &lruEntry{
+		key:       key,
+		value:     value,
+		size:      int64(len(key)) + estimateSize(value),
+		expiredAt: time.Now().Add(duration),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.removeElement(e)
+	}
+
+	c.items[key] = c.ll.PushFront(entry)
+	c.bytes += entry.size
+
+	c.evict()
+}
+
+// Get ...
+func (c *LRUBackend) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		c.metrics.onMiss()
+		return nil, false
+	}
+
+	entry := e.Value.(*lruEntry)
+	if time.Now().After(entry.expiredAt) {
+		c.removeElement(e)
+		c.metrics.onMiss()
+		return nil, false
+	}
+
+	c.ll.MoveToFront(e)
+	c.metrics.onHit()
+	return entry.value, true
+}
+
+// Delete ...
+func (c *LRUBackend) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.removeElement(e)
+	}
+	return nil
+}
+
+// Len the count of the entries, including the expired ones not evicted yet
+func (c *LRUBackend) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+// Keys the keys of all the entries, including the expired ones not evicted yet
+func (c *LRUBackend) Keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]string, 0, len(c.items))
+	for key := range c.items {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Flush delete all the entries
+func (c *LRUBackend) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+	c.bytes = 0
+}
+
+// Stats ...
+func (c *LRUBackend) Stats() Stats {
+	return c.metrics.snapshot()
+}
+
+func (c *LRUBackend) removeElement(e *list.Element) {
+	entry := c.ll.Remove(e).(*lruEntry)
+	delete(c.items, entry.key)
+	c.bytes -= entry.size
+}
+
+// evict 淘汰最久未使用的entry, 直到满足大小限制; 最新写入的entry即使超过max bytes也会保留
+func (c *LRUBackend) evict() {
+	for c.ll.Len() > 1 {
+		overEntries := c.limit.MaxEntries > 0 && c.ll.Len() > c.limit.MaxEntries
+		overBytes := c.limit.MaxBytes > 0 && c.bytes > c.limit.MaxBytes
+		if !overEntries && !overBytes {
+			return
+		}
+
+		c.removeElement(c.ll.Back())
+		c.metrics.eviction.Inc()
+	}
+}
+
+// NewLRUBackend ...
+func NewLRUBackend(name string, expiration time.Duration, limit Limit) *LRUBackend {
+	return &LRUBackend{
+		name:              name,
+		limit:             limit,
+		metrics:           newCacheMetrics(name),
+		ll:                list.New(),
+		items:             make(map[string]*list.Element),
+		defaultExpiration: expiration,
+	}
+}
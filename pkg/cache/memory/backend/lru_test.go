@@ -0,0 +1,130 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimit_Unlimited(t *testing.T) {
+	assert.True(t, Limit{}.Unlimited())
+	assert.False(t, Limit{MaxEntries: 1}.Unlimited())
+	assert.False(t, Limit{MaxBytes: 1}.Unlimited())
+}
+
+func TestLRUBackend(t *testing.T) {
+	t.Run("get set delete", func(t *testing.T) {
+		be := NewLRUBackend("test", 5*time.Second, Limit{MaxEntries: 10})
+
+		_, found := be.Get("not_exists")
+		assert.False(t, found)
+
+		be.Set("hello", "world", time.Duration(0))
+		value, found := be.Get("hello")
+		assert.True(t, found)
+		assert.Equal(t, "world", value)
+
+		be.Delete("hello")
+		_, found = be.Get("hello")
+		assert.False(t, found)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		be := NewLRUBackend("test", 5*time.Second, Limit{MaxEntries: 10})
+
+		be.Set("hello", "world", time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+		_, found := be.Get("hello")
+		assert.False(t, found)
+		assert.Equal(t, 0, be.Len())
+	})
+
+	t.Run("evict by max entries", func(t *testing.T) {
+		be := NewLRUBackend("test", 5*time.Second, Limit{MaxEntries: 2})
+
+		be.Set("a", 1, 0)
+		be.Set("b", 2, 0)
+		// a is recently used, b will be evicted
+		be.Get("a")
+		be.Set("c", 3, 0)
+
+		assert.Equal(t, 2, be.Len())
+		_, found := be.Get("b")
+		assert.False(t, found)
+		_, found = be.Get("a")
+		assert.True(t, found)
+		_, found = be.Get("c")
+		assert.True(t, found)
+	})
+
+	t.Run("evict by max bytes", func(t *testing.T) {
+		be := NewLRUBackend("test", 5*time.Second, Limit{MaxBytes: 100})
+
+		be.Set("a", "01234567890123456789", 0)
+		be.Set("b", "01234567890123456789", 0)
+		be.Set("c", "01234567890123456789", 0)
+		assert.Equal(t, 2, be.Len())
+		_, found := be.Get("a")
+		assert.False(t, found)
+
+		// the latest one is kept even exceeding the max bytes
+		be.Set("d", string(make([]byte, 200)), 0)
+		assert.Equal(t, 1, be.Len())
+		_, found = be.Get("d")
+		assert.True(t, found)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		be := NewLRUBackend("test", 5*time.Second, Limit{MaxEntries: 2})
+
+		be.Set("a", 1, 0)
+		be.Set("a", 2, 0)
+		assert.Equal(t, 1, be.Len())
+		value, _ := be.Get("a")
+		assert.Equal(t, 2, value)
+	})
+
+	t.Run("keys flush stats", func(t *testing.T) {
+		be := NewLRUBackend("test", 5*time.Second, Limit{MaxEntries: 10})
+
+		be.Set("a", 1, 0)
+		be.Set("b", 2, 0)
+		assert.ElementsMatch(t, []string{"a", "b"}, be.Keys())
+
+		be.Get("a")
+		be.Get("c")
+		assert.Equal(t, Stats{Hit: 1, Miss: 1}, be.Stats())
+		assert.Equal(t, 0.5, be.Stats().HitRatio())
+
+		be.Flush()
+		assert.Equal(t, 0, be.Len())
+		assert.Empty(t, be.Keys())
+		_, found := be.Get("a")
+		assert.False(t, found)
+	})
+}
+
+func TestEstimateSize(t *testing.T) {
+	assert.Equal(t, int64(0), estimateSize(nil))
+	assert.Equal(t, int64(8), estimateSize(int64(1)))
+	assert.Equal(t, int64(21), estimateSize("hello"))
+	assert.Equal(t, int64(24+8*3), estimateSize([]int64{1, 2, 3}))
+
+	type s struct {
+		ID   int64
+		Name string
+	}
+	assert.Equal(t, int64(8+16+4), estimateSize(s{ID: 1, Name: "test"}))
+	assert.Equal(t, int64(8+8+16+4), estimateSize(&s{ID: 1, Name: "test"}))
+}
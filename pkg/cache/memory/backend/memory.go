@@ -33,8 +33,9 @@ func newTTLCache(expiration time.Duration, cleanupInterval time.Duration) *gocac
 
 // MemoryBackend ...
 type MemoryBackend struct {
-	name  string
-	cache *gocache.Cache
+	name    string
+	cache   *gocache.Cache
+	metrics cacheMetrics
 
 	defaultExpiration time.Duration
 }
@@ -50,7 +51,13 @@ func (c *MemoryBackend) Set(key string, value interface{}, duration time.Duratio
 
 // Get ...
 func (c *MemoryBackend) Get(key string) (interface{}, bool) {
-	return c.cache.Get(key)
+	value, found := c.cache.Get(key)
+	if found {
+		c.metrics.onHit()
+	} else {
+		c.metrics.onMiss()
+	}
+	return value, found
 }
 
 // GetInto ...
@@ -64,6 +71,32 @@ func (c *MemoryBackend) Delete(key string) error {
 	return nil
 }
 
+// Len the count of the entries, including the expired ones not cleaned up yet
+func (c *MemoryBackend) Len() int {
+	return c.cache.ItemCount()
+}
+
+// Keys the keys of all the unexpired entries
+func (c *MemoryBackend) Keys() []string {
+	items := c.cache.Items()
+
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Flush delete all the entries
+func (c *MemoryBackend) Flush() {
+	c.cache.Flush()
+}
+
+// Stats ...
+func (c *MemoryBackend) Stats() Stats {
+	return c.metrics.snapshot()
+}
+
 // NewMemoryBackend ...
 func NewMemoryBackend(name string, expiration time.Duration) *MemoryBackend {
 	cleanupInterval := expiration + (5 * time.Minute)
@@ -71,6 +104,7 @@ func NewMemoryBackend(name string, expiration time.Duration) *MemoryBackend {
 	return &MemoryBackend{
 		name:              name,
 		cache:             newTTLCache(expiration, cleanupInterval),
+		metrics:           newCacheMetrics(name),
 		defaultExpiration: expiration,
 	}
 }
@@ -40,4 +40,13 @@ func TestMemoryBackend(t *testing.T) {
 	be.Delete("hello")
 	_, found = be.Get("hello")
 	assert.False(t, found)
+
+	be.Set("a", 1, 0)
+	be.Set("b", 2, 0)
+	assert.Equal(t, 2, be.Len())
+	assert.ElementsMatch(t, []string{"a", "b"}, be.Keys())
+	assert.Equal(t, Stats{Hit: 1, Miss: 2}, be.Stats())
+
+	be.Flush()
+	assert.Equal(t, 0, be.Len())
 }
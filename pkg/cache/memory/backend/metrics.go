@@ -0,0 +1,70 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package backend
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"iam/pkg/metric"
+)
+
+// cacheMetrics the counters of a memory cache, resolved once when creating the backend, avoid the lookup on each get
+type cacheMetrics struct {
+	hit      prometheus.Counter
+	miss     prometheus.Counter
+	eviction prometheus.Counter
+
+	// the counters in process, for the cache inspection api
+	stats *Stats
+}
+
+// Stats the hit/miss counters of a memory cache since the process started
+type Stats struct {
+	Hit  uint64
+	Miss uint64
+}
+
+// HitRatio ...
+func (s Stats) HitRatio() float64 {
+	total := s.Hit + s.Miss
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hit) / float64(total)
+}
+
+func newCacheMetrics(name string) cacheMetrics {
+	return cacheMetrics{
+		hit:      metric.MemoryCacheRequestCount.With(prometheus.Labels{"name": name, "result": "hit"}),
+		miss:     metric.MemoryCacheRequestCount.With(prometheus.Labels{"name": name, "result": "miss"}),
+		eviction: metric.MemoryCacheEvictionCount.With(prometheus.Labels{"name": name}),
+		stats:    &Stats{},
+	}
+}
+
+func (m cacheMetrics) onHit() {
+	m.hit.Inc()
+	atomic.AddUint64(&m.stats.Hit, 1)
+}
+
+func (m cacheMetrics) onMiss() {
+	m.miss.Inc()
+	atomic.AddUint64(&m.stats.Miss, 1)
+}
+
+func (m cacheMetrics) snapshot() Stats {
+	return Stats{
+		Hit:  atomic.LoadUint64(&m.stats.Hit),
+		Miss: atomic.LoadUint64(&m.stats.Miss),
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package backend
+
+import (
+	"reflect"
+)
+
+// the max depth of walking the nested values, the deeper ones are counted as the pointer size
+const maxEstimateDepth = 5
+
+const pointerSize = 8
+
+// estimateSize 估算value占用的内存大小(bytes), 只用于缓存的大小限制, 不需要精确
+func estimateSize(value interface{}) int64 {
+	if value == nil {
+		return 0
+	}
+	return estimateValueSize(reflect.ValueOf(value), 0)
+}
+
+func estimateValueSize(v reflect.Value, depth int) int64 {
+	if !v.IsValid() {
+		return 0
+	}
+	if depth > maxEstimateDepth {
+		return pointerSize
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len()) + 16
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return pointerSize
+		}
+		return pointerSize + estimateValueSize(v.Elem(), depth+1)
+	case reflect.Slice, reflect.Array:
+		size := int64(24)
+		for i := 0; i < v.Len(); i++ {
+			size += estimateValueSize(v.Index(i), depth+1)
+		}
+		return size
+	case reflect.Map:
+		size := int64(48)
+		iter := v.MapRange()
+		for iter.Next() {
+			size += estimateValueSize(iter.Key(), depth+1) + estimateValueSize(iter.Value(), depth+1)
+		}
+		return size
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += estimateValueSize(v.Field(i), depth+1)
+		}
+		return size
+	default:
+		return int64(v.Type().Size())
+	}
+}
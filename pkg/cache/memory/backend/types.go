@@ -19,4 +19,10 @@ type Backend interface {
 
 	// Get(key string, value interface{}) error
 	Delete(key string) error
+
+	// for the cache inspection
+	Len() int
+	Keys() []string
+	Flush()
+	Stats() Stats
 }
@@ -48,7 +48,10 @@ func (c *BaseCache) Exists(key cache.Key) bool {
 func (c *BaseCache) Get(key cache.Key) (interface{}, error) {
 	// 1. if cache is disabled, fetch and return
 	if c.disabled {
-		value, err := c.retrieveFunc(key)
+		// NOTE: still merge the concurrent retrieves of the same key
+		value, err, _ := c.g.Do(key.Key(), func() (interface{}, error) {
+			return c.retrieveFunc(key)
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -74,20 +77,23 @@ func (c *BaseCache) Get(key cache.Key) (interface{}, error) {
 func (c *BaseCache) doRetrieve(k cache.Key) (interface{}, error) {
 	key := k.Key()
 
-	// 3.2 fetch
+	// 3.2 fetch, only one of the concurrent callers of the same key do the retrieve and set the cache
 	value, err, _ := c.g.Do(key, func() (interface{}, error) {
-		return c.retrieveFunc(k)
-	})
+		value, err := c.retrieveFunc(k)
+		if err != nil {
+			// ! if error, cache it too, make it short enough(5s)
+			c.backend.Set(key, EmptyCache{err: err}, EmptyCacheExpiration)
+			return nil, err
+		}
 
+		// 4. set value to cache, use default expiration
+		c.backend.Set(key, value, 0)
+		return value, nil
+	})
 	if err != nil {
-		// ! if error, cache it too, make it short enough(5s)
-		c.backend.Set(key, EmptyCache{err: err}, EmptyCacheExpiration)
 		return nil, err
 	}
 
-	// 4. set value to cache, use default expiration
-	c.backend.Set(key, value, 0)
-
 	return value, nil
 }
 
@@ -174,6 +180,26 @@ func (c *BaseCache) Disabled() bool {
 	return c.disabled
 }
 
+// Len ...
+func (c *BaseCache) Len() int {
+	return c.backend.Len()
+}
+
+// Keys ...
+func (c *BaseCache) Keys() []string {
+	return c.backend.Keys()
+}
+
+// Flush ...
+func (c *BaseCache) Flush() {
+	c.backend.Flush()
+}
+
+// Stats ...
+func (c *BaseCache) Stats() backend.Stats {
+	return c.backend.Stats()
+}
+
 // NewBaseCache ...
 func NewBaseCache(disabled bool, retrieveFunc RetrieveFunc, backend backend.Backend) Cache {
 	return &BaseCache{
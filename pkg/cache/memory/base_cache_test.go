@@ -12,6 +12,8 @@ package memory
 
 import (
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -197,6 +199,39 @@ func BenchmarkRawRetrieve(b *testing.B) {
 	}
 }
 
+func TestBaseCacheConcurrentRetrieve(t *testing.T) {
+	for _, disabled := range []bool{false, true} {
+		var called int32
+		release := make(chan struct{})
+		retrieve := func(k cache.Key) (interface{}, error) {
+			atomic.AddInt32(&called, 1)
+			<-release
+			return "1", nil
+		}
+
+		c := NewBaseCache(disabled, retrieve, backend.NewMemoryBackend("test", 5*time.Minute))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				x, err := c.Get(cache.NewStringKey("hot"))
+				assert.NoError(t, err)
+				assert.Equal(t, "1", x)
+			}()
+		}
+
+		// wait for all the callers blocked in the retrieve
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&called), "disabled=%t", disabled)
+	}
+}
+
 func BenchmarkSingleFlightRetrieve(b *testing.B) {
 	var keys []cache.StringKey
 	for i := 0; i < 100000; i++ {
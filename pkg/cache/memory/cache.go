@@ -13,13 +13,45 @@ package memory
 import (
 	"time"
 
+	log "github.com/sirupsen/logrus"
+
 	"iam/pkg/cache/memory/backend"
+	"iam/pkg/config"
+)
+
+var (
+	defaultLimit backend.Limit
+	cacheLimits  = map[string]backend.Limit{}
 )
 
-// NewCache create a memory cache
+// InitLimits 初始化本地缓存的大小限制, 需要在创建缓存之前调用
+func InitLimits(cfg config.Cache) {
+	defaultLimit = backend.Limit{MaxEntries: cfg.MaxEntries, MaxBytes: cfg.MaxBytes}
+
+	cacheLimits = make(map[string]backend.Limit, len(cfg.Limits))
+	for _, l := range cfg.Limits {
+		cacheLimits[l.Name] = backend.Limit{MaxEntries: l.MaxEntries, MaxBytes: l.MaxBytes}
+	}
+}
+
+func getLimit(name string) backend.Limit {
+	if limit, ok := cacheLimits[name]; ok {
+		return limit
+	}
+	return defaultLimit
+}
+
+// NewCache create a memory cache, the cache with size limit will evict the least recently used entries
 func NewCache(name string, disabled bool, retrieveFunc RetrieveFunc,
 	expiration time.Duration) Cache {
-	be := backend.NewMemoryBackend(name, expiration)
+	limit := getLimit(name)
+	if limit.Unlimited() {
+		be := backend.NewMemoryBackend(name, expiration)
+		return NewBaseCache(disabled, retrieveFunc, be)
+	}
+
+	log.Infof("init memory cache `%s` with limit, max_entries=%d, max_bytes=%d", name, limit.MaxEntries, limit.MaxBytes)
+	be := backend.NewLRUBackend(name, expiration, limit)
 	return NewBaseCache(disabled, retrieveFunc, be)
 }
 
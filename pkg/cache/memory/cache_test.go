@@ -15,6 +15,8 @@ import (
 	"time"
 
 	"iam/pkg/cache"
+	"iam/pkg/cache/memory/backend"
+	"iam/pkg/config"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -29,3 +31,19 @@ func TestNewCache(t *testing.T) {
 	c := NewCache("test", false, retrieveOK, expiration)
 	assert.NotNil(t, c)
 }
+
+func TestNewCacheWithLimit(t *testing.T) {
+	InitLimits(config.Cache{
+		MaxEntries: 100,
+		Limits:     []config.MemoryCacheLimit{{Name: "unlimited"}},
+	})
+	defer InitLimits(config.Cache{})
+
+	c := NewCache("test", false, retrieveOK, 5*time.Minute)
+	_, isLRU := c.(*BaseCache).backend.(*backend.LRUBackend)
+	assert.True(t, isLRU)
+
+	c = NewCache("unlimited", false, retrieveOK, 5*time.Minute)
+	_, isLRU = c.(*BaseCache).backend.(*backend.LRUBackend)
+	assert.False(t, isLRU)
+}
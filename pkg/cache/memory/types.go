@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"iam/pkg/cache"
+	"iam/pkg/cache/memory/backend"
 )
 
 // RetrieveFunc ...
@@ -35,4 +36,10 @@ type Cache interface {
 	DirectGet(key cache.Key) (interface{}, bool)
 
 	Disabled() bool
+
+	// for the cache inspection
+	Len() int
+	Keys() []string
+	Flush()
+	Stats() backend.Stats
 }
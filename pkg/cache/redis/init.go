@@ -27,9 +27,20 @@ import (
 const (
 	ModeStandalone = "standalone"
 	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
 )
 
-var rds *redis.Client
+// ReadPreferenceMaster the read preference of mode=sentinel/cluster, where the read-only commands are routed to
+const (
+	// all commands to the master, the default
+	ReadPreferenceMaster = "master"
+	// the read-only commands to a random node(master or replica)
+	ReadPreferenceReplica = "replica"
+	// the read-only commands to the node with the lowest latency
+	ReadPreferenceNearest = "nearest"
+)
+
+var rds redis.UniversalClient
 
 var redisClientInitOnce sync.Once
 
@@ -73,7 +84,7 @@ func newStandaloneClient(redisConfig *config.Redis) *redis.Client {
 	return redis.NewClient(opt)
 }
 
-func newSentinelClient(redisConfig *config.Redis) *redis.Client {
+func newSentinelClient(redisConfig *config.Redis) redis.UniversalClient {
 	sentinelAddrs := strings.Split(redisConfig.SentinelAddr, ",")
 	opt := &redis.FailoverOptions{
 		MasterName:    redisConfig.MasterName,
@@ -112,9 +123,67 @@ func newSentinelClient(redisConfig *config.Redis) *redis.Client {
 		opt.MinIdleConns = redisConfig.MinIdleConns
 	}
 
+	// the read-only commands to the replicas, should route by the sentinel cluster client
+	switch redisConfig.ReadPreference {
+	case ReadPreferenceReplica:
+		opt.RouteRandomly = true
+		return redis.NewFailoverClusterClient(opt)
+	case ReadPreferenceNearest:
+		opt.RouteByLatency = true
+		return redis.NewFailoverClusterClient(opt)
+	}
+
 	return redis.NewFailoverClient(opt)
 }
 
+func newClusterClient(redisConfig *config.Redis) *redis.ClusterClient {
+	clusterAddrs := strings.Split(redisConfig.ClusterAddr, ",")
+	// NOTE: the cluster mode only support db 0
+	opt := &redis.ClusterOptions{
+		Addrs:    clusterAddrs,
+		Password: redisConfig.Password,
+	}
+
+	// set default options
+	opt.DialTimeout = 2 * time.Second
+	opt.ReadTimeout = 1 * time.Second
+	opt.WriteTimeout = 1 * time.Second
+	opt.PoolSize = 20 * runtime.NumCPU()
+	opt.MinIdleConns = 10 * runtime.NumCPU()
+	opt.IdleTimeout = 3 * time.Minute
+
+	// set custom options, from config.yaml
+	if redisConfig.DialTimeout > 0 {
+		opt.DialTimeout = time.Duration(redisConfig.DialTimeout) * time.Second
+	}
+	if redisConfig.ReadTimeout > 0 {
+		opt.ReadTimeout = time.Duration(redisConfig.ReadTimeout) * time.Second
+	}
+	if redisConfig.WriteTimeout > 0 {
+		opt.WriteTimeout = time.Duration(redisConfig.WriteTimeout) * time.Second
+	}
+
+	if redisConfig.PoolSize > 0 {
+		opt.PoolSize = redisConfig.PoolSize
+	}
+	if redisConfig.MinIdleConns > 0 {
+		opt.MinIdleConns = redisConfig.MinIdleConns
+	}
+
+	switch redisConfig.ReadPreference {
+	case ReadPreferenceReplica:
+		opt.RouteRandomly = true
+	case ReadPreferenceNearest:
+		opt.RouteByLatency = true
+	}
+
+	log.Infof(
+		"connect to redis cluster: %s[readPreference=%s, poolSize=%d, minIdleConns=%d]",
+		redisConfig.ClusterAddr, redisConfig.ReadPreference, opt.PoolSize, opt.MinIdleConns)
+
+	return redis.NewClusterClient(opt)
+}
+
 // InitRedisClient ...
 func InitRedisClient(debugMode bool, redisConfig *config.Redis) {
 	if rds == nil {
@@ -124,8 +193,10 @@ func InitRedisClient(debugMode bool, redisConfig *config.Redis) {
 				rds = newStandaloneClient(redisConfig)
 			case ModeSentinel:
 				rds = newSentinelClient(redisConfig)
+			case ModeCluster:
+				rds = newClusterClient(redisConfig)
 			default:
-				panic("init redis client fail, invalid redis.id, should be `standalone` or `sentinel`")
+				panic("init redis client fail, invalid redis.id, should be `standalone`, `sentinel` or `cluster`")
 			}
 
 			_, err := rds.Ping(context.TODO()).Result()
@@ -141,6 +212,6 @@ func InitRedisClient(debugMode bool, redisConfig *config.Redis) {
 }
 
 // GetDefaultRedisClient 获取默认的Redis实例
-func GetDefaultRedisClient() *redis.Client {
+func GetDefaultRedisClient() redis.UniversalClient {
 	return rds
 }
@@ -14,6 +14,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/assert"
 
 	"iam/pkg/config"
@@ -42,3 +43,43 @@ func TestInitRedisClient(t *testing.T) {
 
 	// TODO: add success init
 }
+
+func TestNewSentinelClient(t *testing.T) {
+	redisConfig := &config.Redis{
+		ID:           ModeSentinel,
+		SentinelAddr: "127.0.0.1:26379,127.0.0.2:26379",
+		MasterName:   "mymaster",
+	}
+
+	cli := newSentinelClient(redisConfig)
+	defer cli.Close()
+	_, ok := cli.(*redis.Client)
+	assert.True(t, ok)
+
+	// read from the replicas
+	redisConfig.ReadPreference = ReadPreferenceReplica
+	cli = newSentinelClient(redisConfig)
+	defer cli.Close()
+	clusterCli, ok := cli.(*redis.ClusterClient)
+	if assert.True(t, ok) {
+		assert.True(t, clusterCli.Options().RouteRandomly)
+	}
+}
+
+func TestNewClusterClient(t *testing.T) {
+	redisConfig := &config.Redis{
+		ID:             ModeCluster,
+		ClusterAddr:    "127.0.0.1:7000,127.0.0.2:7000",
+		PoolSize:       3,
+		ReadPreference: ReadPreferenceNearest,
+	}
+
+	cli := newClusterClient(redisConfig)
+	defer cli.Close()
+
+	opt := cli.Options()
+	assert.Equal(t, []string{"127.0.0.1:7000", "127.0.0.2:7000"}, opt.Addrs)
+	assert.Equal(t, 3, opt.PoolSize)
+	assert.True(t, opt.RouteByLatency)
+	assert.True(t, opt.ReadOnly)
+}
@@ -14,6 +14,8 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-redis/cache/v8"
@@ -41,12 +43,15 @@ const (
 // RetrieveFunc ...
 type RetrieveFunc func(key iamcache.Key) (interface{}, error)
 
+// RetrieveWithExpirationFunc retrieve the data and the expiration of it, 0 means the default expiration of the cache
+type RetrieveWithExpirationFunc func(key iamcache.Key) (interface{}, time.Duration, error)
+
 // Cache is a cache implements
 type Cache struct {
 	name              string
 	keyPrefix         string
 	codec             *cache.Cache
-	cli               *redis.Client
+	cli               redis.UniversalClient
 	defaultExpiration time.Duration
 	G                 singleflight.Group
 }
@@ -105,6 +110,11 @@ func (c *Cache) copyTo(source interface{}, dest interface{}) error {
 	return err
 }
 
+// Name ...
+func (c *Cache) Name() string {
+	return c.name
+}
+
 // Set execute `set`
 func (c *Cache) Set(key iamcache.Key, value interface{}, duration time.Duration) error {
 	if duration == time.Duration(0) {
@@ -134,8 +144,30 @@ func (c *Cache) Exists(key iamcache.Key) bool {
 	return err == nil && count == 1
 }
 
+// SetNX execute `set nx` with the raw value, return false if the key already exists, can be used as a simple lock
+func (c *Cache) SetNX(key iamcache.Key, value string, duration time.Duration) (bool, error) {
+	if duration == time.Duration(0) {
+		duration = c.defaultExpiration
+	}
+
+	k := c.genKey(key.Key())
+	return c.cli.SetNX(context.TODO(), k, value, duration).Result()
+}
+
 // GetInto will retrieve the data from cache and unmarshal into the obj
 func (c *Cache) GetInto(key iamcache.Key, obj interface{}, retrieveFunc RetrieveFunc) (err error) {
+	return c.GetIntoWithExpiration(key, obj, func(key iamcache.Key) (interface{}, time.Duration, error) {
+		data, err := retrieveFunc(key)
+		return data, 0, err
+	})
+}
+
+// GetIntoWithExpiration same as GetInto, but the retrieved data will be set with the expiration from the retrieveFunc
+func (c *Cache) GetIntoWithExpiration(
+	key iamcache.Key,
+	obj interface{},
+	retrieveFunc RetrieveWithExpirationFunc,
+) (err error) {
 	// 1. get from cache, hit, return
 	err = c.Get(key, obj)
 	if err == nil {
@@ -144,9 +176,19 @@ func (c *Cache) GetInto(key iamcache.Key, obj interface{}, retrieveFunc Retrieve
 
 	// 2. if missing
 	// 2.1 check the guard
-	// 2.2 do retrieve
+	// 2.2 do retrieve, only one of the concurrent callers of the same key do the retrieve and set the cache
 	data, err, _ := c.G.Do(key.Key(), func() (interface{}, error) {
-		return retrieveFunc(key)
+		data, expiration, err := retrieveFunc(key)
+		if err != nil {
+			return nil, err
+		}
+
+		// 3. set to cache
+		errNotImportant := c.Set(key, data, expiration)
+		if errNotImportant != nil {
+			log.Errorf("set to redis fail, key=%s, err=%s", key.Key(), errNotImportant)
+		}
+		return data, nil
 	})
 	// 2.3 do retrieve fail, make guard and return
 	if err != nil {
@@ -155,12 +197,6 @@ func (c *Cache) GetInto(key iamcache.Key, obj interface{}, retrieveFunc Retrieve
 		return
 	}
 
-	// 3. set to cache
-	errNotImportant := c.Set(key, data, 0)
-	if errNotImportant != nil {
-		log.Errorf("set to redis fail, key=%s, err=%s", key.Key(), errNotImportant)
-	}
-
 	// 注意, 这里基础类型无法通过 *obj = value 来赋值
 	// 所以利用从缓存再次反序列化给对应指针赋值(相当于底层msgpack.unmarshal帮做了转换再次反序列化给对应指针赋值
 	return c.copyTo(data, obj)
@@ -185,7 +221,8 @@ func (c *Cache) BatchDelete(keys []iamcache.Key) error {
 	ctx := context.TODO()
 
 	var err error
-	if len(newKeys) < PipelineSizeThreshold {
+	// NOTE: the multi-key `del` fails with CROSSSLOT in cluster mode, should use the pipeline
+	if _, isCluster := c.cli.(*redis.ClusterClient); !isCluster && len(newKeys) < PipelineSizeThreshold {
 		_, err = c.cli.Del(ctx, newKeys...).Result()
 	} else {
 		pipe := c.cli.Pipeline()
@@ -199,6 +236,36 @@ func (c *Cache) BatchDelete(keys []iamcache.Key) error {
 	return err
 }
 
+// Keys execute `scan` to list all the keys of the cache, without the key prefix
+// NOTE: it will scan all the keys of the cache, only for the cache inspection, do not use it in the hot path
+func (c *Cache) Keys() ([]string, error) {
+	ctx := context.TODO()
+	match := c.genKey("*")
+
+	var mu sync.Mutex
+	keys := make([]string, 0)
+	scan := func(ctx context.Context, cli redis.UniversalClient) error {
+		iter := cli.Scan(ctx, 0, match, PipelineSizeThreshold).Iterator()
+		for iter.Next(ctx) {
+			mu.Lock()
+			keys = append(keys, strings.TrimPrefix(iter.Val(), c.keyPrefix+":"))
+			mu.Unlock()
+		}
+		return iter.Err()
+	}
+
+	// NOTE: in cluster mode, should scan the keys on each master
+	if cc, isCluster := c.cli.(*redis.ClusterClient); isCluster {
+		err := cc.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return scan(ctx, client)
+		})
+		return keys, err
+	}
+
+	err := scan(ctx, c.cli)
+	return keys, err
+}
+
 // BatchExpireWithTx execute `expire` with tx pipeline
 func (c *Cache) BatchExpireWithTx(keys []iamcache.Key, expiration time.Duration) error {
 	pipe := c.cli.TxPipeline()
@@ -213,6 +280,80 @@ func (c *Cache) BatchExpireWithTx(keys []iamcache.Key, expiration time.Duration)
 	return err
 }
 
+// incrByIfExistsScript only incr the counter which exists, the missing one will be retrieved from the source
+var incrByIfExistsScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return redis.call("INCRBY", KEYS[1], ARGV[1])
+end
+return nil`)
+
+// IncrByIfExists execute `incrby` only when the key exists, the ttl of the key will not be changed
+func (c *Cache) IncrByIfExists(key iamcache.Key, delta int64) error {
+	k := c.genKey(key.Key())
+
+	err := incrByIfExistsScript.Run(context.TODO(), c.cli, []string{k}, delta).Err()
+	// Nil reply returned by Redis when key does not exist.
+	if err != nil && err != redis.Nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateIfExists execute `get` then `set` with the value changed by update, only when the key exists
+// use `watch` to make sure the concurrent changes will not be lost, if conflict, delete the key for retrieving again
+// the ttl of the key will not be changed
+func (c *Cache) UpdateIfExists(key iamcache.Key, value interface{}, update func() error) error {
+	k := c.genKey(key.Key())
+	ctx := context.TODO()
+
+	txf := func(tx *redis.Tx) error {
+		b, err := tx.Get(ctx, k).Bytes()
+		if err != nil {
+			// Nil reply returned by Redis when key does not exist.
+			if err == redis.Nil {
+				return nil
+			}
+			return err
+		}
+
+		ttl, err := tx.PTTL(ctx, k).Result()
+		if err != nil {
+			return err
+		}
+		// no expiration
+		if ttl < 0 {
+			ttl = 0
+		}
+
+		err = c.codec.Unmarshal(b, value)
+		if err != nil {
+			return err
+		}
+
+		err = update()
+		if err != nil {
+			return err
+		}
+
+		b, err = c.codec.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, k, b, ttl)
+			return nil
+		})
+		return err
+	}
+
+	err := c.cli.Watch(ctx, txf, k)
+	if err == redis.TxFailedErr {
+		return c.Delete(key)
+	}
+	return err
+}
+
 // KV is a key-value pair
 type KV struct {
 	Key   string
@@ -255,6 +396,7 @@ func (c *Cache) BatchGet(keys []iamcache.Key) (map[iamcache.Key]string, error) {
 }
 
 // BatchSetWithTx execute `set` with tx pipeline
+// NOTE: in cluster mode, the keys are grouped by slot, and only the keys in the same slot are in one tx
 func (c *Cache) BatchSetWithTx(kvs []KV, expiration time.Duration) error {
 	// tx, all success or all fail
 	pipe := c.cli.TxPipeline()
@@ -270,6 +412,26 @@ func (c *Cache) BatchSetWithTx(kvs []KV, expiration time.Duration) error {
 	return err
 }
 
+// BatchSet execute `set` with pipeline, not in tx, use the default expiration of the cache if expiration is 0
+// NOTE: the value of KV should be marshaled by Marshal, then can be read by Get/GetInto/Unmarshal
+func (c *Cache) BatchSet(kvs []KV, expiration time.Duration) error {
+	if expiration == time.Duration(0) {
+		expiration = c.defaultExpiration
+	}
+
+	pipe := c.cli.Pipeline()
+
+	ctx := context.TODO()
+
+	for _, kv := range kvs {
+		key := c.genKey(kv.Key)
+		pipe.Set(ctx, key, kv.Value, expiration)
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
 // ZData is a sorted-set data for redis `key: {member: score}`
 type ZData struct {
 	Key string
@@ -308,6 +470,33 @@ func (c *Cache) ZRevRangeByScore(k string, min int64, max int64, offset int64, c
 	return cmds.Result()
 }
 
+// ZIncrByWithExpire execute `zincrby` of all the members with pipeline, then reset the expiration of the key
+func (c *Cache) ZIncrByWithExpire(k string, members map[string]float64, expiration time.Duration) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	pipe := c.cli.TxPipeline()
+	ctx := context.TODO()
+
+	key := c.genKey(k)
+	for member, increment := range members {
+		pipe.ZIncrBy(ctx, key, increment, member)
+	}
+	pipe.Expire(ctx, key, expiration)
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ZRevRange execute `zrevrange`, the members with the highest scores first
+func (c *Cache) ZRevRange(k string, start, stop int64) ([]string, error) {
+	ctx := context.TODO()
+
+	key := c.genKey(k)
+	return c.cli.ZRevRange(ctx, key, start, stop).Result()
+}
+
 // BatchZRemove execute `zremrangebyscore` with pipeline
 func (c *Cache) BatchZRemove(keys []string, min int64, max int64) error {
 	pipe := c.cli.TxPipeline()
@@ -392,6 +581,43 @@ func (c *Cache) HKeys(hashKey string) ([]string, error) {
 	return c.cli.HKeys(context.TODO(), key).Result()
 }
 
+// HSet execute `hset`
+func (c *Cache) HSet(hashKey string, field string, value string) error {
+	key := c.genKey(hashKey)
+	return c.cli.HSet(context.TODO(), key, field, value).Err()
+}
+
+// HExists execute `hexists`
+func (c *Cache) HExists(hashKey string, field string) (bool, error) {
+	key := c.genKey(hashKey)
+	return c.cli.HExists(context.TODO(), key, field).Result()
+}
+
+// HGetAll execute `hgetall`
+func (c *Cache) HGetAll(hashKey string) (map[string]string, error) {
+	key := c.genKey(hashKey)
+	return c.cli.HGetAll(context.TODO(), key).Result()
+}
+
+// HDel execute `hdel`
+func (c *Cache) HDel(hashKey string, fields ...string) error {
+	key := c.genKey(hashKey)
+	return c.cli.HDel(context.TODO(), key, fields...).Err()
+}
+
+// Publish execute `publish`, the channel will be prefixed the same as the keys
+func (c *Cache) Publish(channel string, message string) error {
+	ch := c.genKey(channel)
+	return c.cli.Publish(context.TODO(), ch, message).Err()
+}
+
+// Subscribe execute `subscribe`, the channel will be prefixed the same as the keys
+// NOTE: the caller should close the returned PubSub after used
+func (c *Cache) Subscribe(ctx context.Context, channel string) *redis.PubSub {
+	ch := c.genKey(channel)
+	return c.cli.Subscribe(ctx, ch)
+}
+
 // Unmarshal with compress, via go-redis/cache, use s2 compression
 // Note: YOU SHOULD NOT USE THE RAW msgpack.Unmarshal directly! will panic with decode fail
 func (c *Cache) Unmarshal(b []byte, value interface{}) error {
@@ -11,6 +11,8 @@
 package redis
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -78,6 +80,34 @@ func TestGetInto(t *testing.T) {
 	assert.Equal(t, "ok", i2)
 }
 
+func TestGetIntoWithExpiration(t *testing.T) {
+	c := NewMockCache("test", 5*time.Minute)
+
+	key := cache.NewStringKey("ekey")
+
+	var i string
+	err := c.GetIntoWithExpiration(key, &i, func(k cache.Key) (interface{}, time.Duration, error) {
+		return "ok", time.Minute, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", i)
+
+	ttl, err := c.cli.TTL(context.TODO(), c.genKey(key.Key())).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, ttl)
+
+	// the default expiration
+	key = cache.NewStringKey("ekey2")
+	err = c.GetIntoWithExpiration(key, &i, func(k cache.Key) (interface{}, time.Duration, error) {
+		return "ok", 0, nil
+	})
+	assert.NoError(t, err)
+
+	ttl, err = c.cli.TTL(context.TODO(), c.genKey(key.Key())).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, ttl)
+}
+
 func TestDelete(t *testing.T) {
 	c := NewMockCache("test", 5*time.Minute)
 
@@ -122,6 +152,29 @@ func TestBatchDelete(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestKeys(t *testing.T) {
+	c := NewMockCache("test_keys", 5*time.Minute)
+
+	keys, err := c.Keys()
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	err = c.Set(cache.NewStringKey("k1"), 1, 0)
+	assert.NoError(t, err)
+	err = c.Set(cache.NewStringKey("k2:a"), 2, 0)
+	assert.NoError(t, err)
+
+	// the keys of other caches should not be listed
+	other := NewMockCache("test_other", 5*time.Minute)
+	err = other.Set(cache.NewStringKey("k3"), 3, 0)
+	assert.NoError(t, err)
+
+	keys, err = c.Keys()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"k1", "k2:a"}, keys)
+	assert.Equal(t, "test_keys", c.Name())
+}
+
 // func TestExpire(t *testing.T) {
 // 	c := NewMockCache("test", 5*time.Minute)
 //
@@ -217,6 +270,24 @@ func TestBatchExpireWithTx(t *testing.T) {
 //	assert.Equal(t, "1", data[keyField1])
 //}
 
+func TestBatchSet_and_GetInto(t *testing.T) {
+	c := NewMockCache("test_batch_set", 5*time.Minute)
+
+	b, err := c.Marshal([]int64{1, 2})
+	assert.NoError(t, err)
+
+	err = c.BatchSet([]KV{{Key: "a", Value: string(b)}}, 0)
+	assert.NoError(t, err)
+
+	var value []int64
+	err = c.Get(cache.NewStringKey("a"), &value)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, value)
+
+	ttl := c.cli.TTL(context.TODO(), c.genKey("a")).Val()
+	assert.True(t, ttl > 0 && ttl <= 5*time.Minute)
+}
+
 func TestBatchSetWithTx_and_BatchGet(t *testing.T) {
 	c := NewMockCache("test", 5*time.Minute)
 
@@ -362,3 +433,135 @@ func TestBatchSetAndGet(t *testing.T) {
 	assert.Equal(t, v2.Y, 123)
 	assert.Equal(t, v2.Z, "123456789012345678901234567890123456789012345678901234567890")
 }
+
+func TestIncrByIfExists(t *testing.T) {
+	c := NewMockCache("test", 5*time.Minute)
+
+	key := cache.NewStringKey("counter")
+
+	// missing, do nothing
+	err := c.IncrByIfExists(key, 2)
+	assert.NoError(t, err)
+	assert.False(t, c.Exists(key))
+
+	err = c.BatchSetWithTx([]KV{{Key: "counter", Value: "10"}}, 5*time.Minute)
+	assert.NoError(t, err)
+
+	err = c.IncrByIfExists(key, -3)
+	assert.NoError(t, err)
+
+	data, err := c.BatchGet([]cache.Key{key})
+	assert.NoError(t, err)
+	assert.Equal(t, "7", data[key])
+}
+
+func TestSetNX(t *testing.T) {
+	c := NewMockCache("test", 5*time.Minute)
+
+	key := cache.NewStringKey("lock")
+
+	ok, err := c.SetNX(key, "1", 0)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// already exists
+	ok, err = c.SetNX(key, "2", 0)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	data, err := c.BatchGet([]cache.Key{key})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", data[key])
+}
+
+func TestUpdateIfExists(t *testing.T) {
+	c := NewMockCache("test", 5*time.Minute)
+
+	key := cache.NewStringKey("update")
+
+	// missing, do nothing
+	var v []int64
+	err := c.UpdateIfExists(key, &v, func() error {
+		v = append(v, 1)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.False(t, c.Exists(key))
+
+	err = c.Set(key, []int64{1, 2}, 0)
+	assert.NoError(t, err)
+
+	err = c.UpdateIfExists(key, &v, func() error {
+		v = append(v, 3)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	var v1 []int64
+	err = c.Get(key, &v1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, v1)
+
+	// update fail, not changed
+	err = c.UpdateIfExists(key, &v, func() error {
+		return errors.New("update fail")
+	})
+	assert.Error(t, err)
+
+	err = c.Get(key, &v1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, v1)
+}
+
+func TestZIncrByWithExpire_and_ZRevRange(t *testing.T) {
+	c := NewMockCache("test", 5*time.Minute)
+
+	err := c.ZIncrByWithExpire("z", map[string]float64{"a": 1, "b": 3}, 5*time.Minute)
+	assert.NoError(t, err)
+	err = c.ZIncrByWithExpire("z", map[string]float64{"a": 5}, 5*time.Minute)
+	assert.NoError(t, err)
+
+	// empty, do nothing
+	err = c.ZIncrByWithExpire("z", map[string]float64{}, 5*time.Minute)
+	assert.NoError(t, err)
+
+	members, err := c.ZRevRange("z", 0, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, members)
+
+	members, err = c.ZRevRange("z", 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, members)
+
+	members, err = c.ZRevRange("not_exists", 0, -1)
+	assert.NoError(t, err)
+	assert.Empty(t, members)
+}
+
+func TestHashOperations(t *testing.T) {
+	c := NewMockCache("test", 5*time.Minute)
+
+	err := c.HSet("h", "f1", "1")
+	assert.NoError(t, err)
+	err = c.HSet("h", "f2", "2")
+	assert.NoError(t, err)
+
+	exists, err := c.HExists("h", "f1")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	data, err := c.HGetAll("h")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"f1": "1", "f2": "2"}, data)
+
+	err = c.HDel("h", "f1")
+	assert.NoError(t, err)
+
+	exists, err = c.HExists("h", "f1")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	data, err = c.HGetAll("not_exists")
+	assert.NoError(t, err)
+	assert.Empty(t, data)
+}
@@ -0,0 +1,156 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package warmup
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/cache/impls"
+	"iam/pkg/config"
+	"iam/pkg/service"
+)
+
+/*
+ * > 服务启动时的缓存预热, 在开始监听之前执行, 避免冷启动时大量回源导致的延迟抖动
+ *
+ * 1. 系统: 系统信息, 系统被禁用的操作
+ * 2. 操作: 所有系统的操作详情(包含操作关联的资源类型)
+ * 3. subject: 最近鉴权流量最多的topN subject的PK及详情(部门/用户组), 见impls/hot_subject.go
+ *
+ * 预热是尽力而为的: 单个缓存加载失败只记录日志, 超时后跳过剩余的预热, 都不影响服务启动
+ */
+
+const defaultTimeout = 30 * time.Second
+
+// Result 预热的结果
+type Result struct {
+	Systems  int
+	Actions  int
+	Subjects int
+	Failed   int
+	TimedOut bool
+}
+
+type warmer struct {
+	deadline time.Time
+	result   Result
+}
+
+func (w *warmer) timedOut() bool {
+	if time.Now().After(w.deadline) {
+		w.result.TimedOut = true
+	}
+	return w.result.TimedOut
+}
+
+// Run 预热缓存, 阻塞直到预热完成或超时
+func Run(cfg config.Warmup) Result {
+	timeout := defaultTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+
+	start := time.Now()
+	w := &warmer{deadline: start.Add(timeout)}
+
+	w.warmSystems()
+	w.warmSubjects(cfg.TopSubjects)
+
+	log.Infof("warm up the caches done, cost=%s, result=%+v", time.Since(start), w.result)
+	if w.result.TimedOut {
+		log.Warnf("warm up the caches timeout after %s, the rest are skipped", timeout)
+	}
+	return w.result
+}
+
+func (w *warmer) warmSystems() {
+	systems, err := service.NewSystemService().ListAll()
+	if err != nil {
+		log.WithError(err).Error("warm up: list all systems fail")
+		w.result.Failed++
+		return
+	}
+
+	for _, system := range systems {
+		if w.timedOut() {
+			return
+		}
+
+		_, err = impls.GetSystem(system.ID)
+		if err == nil {
+			_, err = impls.GetSystemDisabledActions(system.ID)
+		}
+		if err != nil {
+			log.WithError(err).Errorf("warm up: system=`%s` fail", system.ID)
+			w.result.Failed++
+			continue
+		}
+		w.result.Systems++
+
+		w.warmActions(system.ID)
+	}
+}
+
+func (w *warmer) warmActions(systemID string) {
+	actions, err := service.NewActionService().ListThinActionBySystem(systemID)
+	if err != nil {
+		log.WithError(err).Errorf("warm up: list actions of system=`%s` fail", systemID)
+		w.result.Failed++
+		return
+	}
+
+	for _, action := range actions {
+		if w.timedOut() {
+			return
+		}
+
+		// the action detail contains the pk and the action resource types
+		_, err = impls.GetActionDetail(systemID, action.ID)
+		if err != nil {
+			log.WithError(err).Errorf("warm up: action detail system=`%s`, action=`%s` fail", systemID, action.ID)
+			w.result.Failed++
+			continue
+		}
+		w.result.Actions++
+	}
+}
+
+func (w *warmer) warmSubjects(topN int) {
+	if topN <= 0 || w.timedOut() {
+		return
+	}
+
+	subjects, err := impls.ListHotSubjects(topN)
+	if err != nil {
+		log.WithError(err).Errorf("warm up: list top %d hot subjects fail", topN)
+		w.result.Failed++
+		return
+	}
+
+	for _, subject := range subjects {
+		if w.timedOut() {
+			return
+		}
+
+		pk, err := impls.GetLocalSubjectPK(subject.Type, subject.ID)
+		if err == nil {
+			_, err = impls.GetSubjectDetail(pk)
+		}
+		if err != nil {
+			// NOTE: the subject may be deleted
+			log.WithError(err).Warnf("warm up: subject type=`%s`, id=`%s` fail", subject.Type, subject.ID)
+			w.result.Failed++
+			continue
+		}
+		w.result.Subjects++
+	}
+}
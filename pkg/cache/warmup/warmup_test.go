@@ -0,0 +1,89 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package warmup
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache/impls"
+	"iam/pkg/config"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+func TestRun(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockSystemService := mock.NewMockSystemService(ctl)
+	mockSystemService.EXPECT().ListAll().Return([]types.System{{ID: "bk_cmdb"}, {ID: "bk_job"}}, nil).AnyTimes()
+	mockActionService := mock.NewMockActionService(ctl)
+	mockActionService.EXPECT().ListThinActionBySystem("bk_cmdb").Return(
+		[]types.ThinAction{{ID: "view_host"}, {ID: "edit_host"}}, nil).AnyTimes()
+
+	patches := gomonkey.ApplyFunc(service.NewSystemService, func() service.SystemService {
+		return mockSystemService
+	})
+	defer patches.Reset()
+	patches.ApplyFunc(service.NewActionService, func() service.ActionService {
+		return mockActionService
+	})
+	patches.ApplyFunc(impls.GetSystem, func(systemID string) (types.System, error) {
+		if systemID == "bk_job" {
+			return types.System{}, errors.New("get system fail")
+		}
+		return types.System{ID: systemID}, nil
+	})
+	patches.ApplyFunc(impls.GetSystemDisabledActions, func(systemID string) (*util.StringSet, error) {
+		return util.NewStringSet(), nil
+	})
+	patches.ApplyFunc(impls.GetActionDetail, func(systemID, actionID string) (types.ActionDetail, error) {
+		return types.ActionDetail{}, nil
+	})
+	patches.ApplyFunc(impls.ListHotSubjects, func(n int) ([]types.Subject, error) {
+		return []types.Subject{{Type: "user", ID: "admin"}, {Type: "user", ID: "deleted"}}, nil
+	})
+	patches.ApplyFunc(impls.GetLocalSubjectPK, func(_type, id string) (int64, error) {
+		if id == "deleted" {
+			return 0, errors.New("not found")
+		}
+		return 1, nil
+	})
+	patches.ApplyFunc(impls.GetSubjectDetail, func(pk int64) (types.SubjectDetail, error) {
+		return types.SubjectDetail{}, nil
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		result := Run(config.Warmup{Enabled: true, TopSubjects: 2})
+		assert.Equal(t, Result{Systems: 1, Actions: 2, Subjects: 1, Failed: 2}, result)
+	})
+
+	t.Run("no subjects", func(t *testing.T) {
+		result := Run(config.Warmup{Enabled: true})
+		assert.Equal(t, Result{Systems: 1, Actions: 2, Failed: 1}, result)
+	})
+}
+
+func TestWarmerTimedOut(t *testing.T) {
+	w := &warmer{}
+	assert.True(t, w.timedOut())
+	assert.True(t, w.result.TimedOut)
+
+	// skip all after timeout
+	w.warmSubjects(10)
+	assert.Equal(t, 0, w.result.Subjects)
+}
@@ -0,0 +1,146 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package census
+
+import (
+	"sort"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/abac/pdp/types"
+	"iam/pkg/abac/pdp/util"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+)
+
+// 分批查询, 每批的数量
+var censusPageSize int64 = 1000
+
+// SystemExpressionUsage 一个系统的表达式中操作符及属性的使用次数
+// Fields的key为 {resource_type}.{field}
+type SystemExpressionUsage struct {
+	System      string           `json:"system"`
+	Expressions int64            `json:"expressions"`
+	Operators   map[string]int64 `json:"operators"`
+	Fields      map[string]int64 `json:"fields"`
+}
+
+type expressionCensus struct {
+	svc service.PolicyService
+
+	task  Task
+	usage map[string]*SystemExpressionUsage
+}
+
+func (c *expressionCensus) run() {
+	c.usage = map[string]*SystemExpressionUsage{}
+
+	var afterPK int64
+	for {
+		expressions, err := c.svc.ListExpressionAfterPK(afterPK, censusPageSize)
+		if err != nil {
+			err = errorx.Wrapf(err, CensusLayer, "expressionCensus.run",
+				"svc.ListExpressionAfterPK afterPK=`%d` fail", afterPK)
+			log.WithError(err).Errorf("expression census fail, task=`%+v`", c.task)
+
+			c.task.Status = TaskStatusFailed
+			c.task.Error = err.Error()
+			c.saveTask()
+			return
+		}
+
+		for _, e := range expressions {
+			c.count(e.Expression)
+		}
+		c.task.Scanned += int64(len(expressions))
+
+		if int64(len(expressions)) < censusPageSize {
+			break
+		}
+		afterPK = expressions[len(expressions)-1].PK
+		c.saveTask()
+	}
+
+	c.task.Systems = c.result()
+	c.task.Status = TaskStatusFinished
+	c.saveTask()
+}
+
+func (c *expressionCensus) saveTask() {
+	c.task.UpdatedAt = time.Now().Unix()
+	if err := saveTask(c.task); err != nil {
+		log.WithError(err).Errorf("census saveTask fail, task=`%+v`", c.task)
+	}
+}
+
+// count 统计一个表达式(即policy.Expression)中每个资源类型的条件
+func (c *expressionCensus) count(expression string) {
+	expressions := []types.ResourceExpression{}
+	if err := jsoniter.UnmarshalFromString(expression, &expressions); err != nil {
+		c.task.Invalid++
+		return
+	}
+
+	for _, e := range expressions {
+		usage, ok := c.usage[e.System]
+		if !ok {
+			usage = &SystemExpressionUsage{
+				System:    e.System,
+				Operators: map[string]int64{},
+				Fields:    map[string]int64{},
+			}
+			c.usage[e.System] = usage
+		}
+
+		usage.Expressions++
+		if !countPolicyCondition(usage, e.Type, e.Expression) {
+			c.task.Invalid++
+		}
+	}
+}
+
+func (c *expressionCensus) result() []SystemExpressionUsage {
+	systems := make([]SystemExpressionUsage, 0, len(c.usage))
+	for _, usage := range c.usage {
+		systems = append(systems, *usage)
+	}
+	sort.Slice(systems, func(i, j int) bool {
+		return systems[i].System < systems[j].System
+	})
+	return systems
+}
+
+// countPolicyCondition 递归统计条件中的操作符及属性, 逻辑操作符(AND/OR/NOT)的属性为content, 不计入属性
+// 条件无法解析时返回false
+func countPolicyCondition(usage *SystemExpressionUsage, _type string, condition types.PolicyCondition) bool {
+	valid := true
+	for operator, options := range condition {
+		usage.Operators[operator]++
+
+		for key, values := range options {
+			switch operator {
+			case "AND", "OR", "NOT":
+				for _, v := range values {
+					pc, err := util.InterfaceToPolicyCondition(v)
+					if err != nil {
+						valid = false
+						continue
+					}
+					valid = countPolicyCondition(usage, _type, pc) && valid
+				}
+			default:
+				usage.Fields[_type+"."+key]++
+			}
+		}
+	}
+	return valid
+}
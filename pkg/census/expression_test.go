@@ -0,0 +1,114 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package census
+
+import (
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/service/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("Expression", func() {
+
+	Describe("expressionCensus.run", func() {
+		var ctl *gomock.Controller
+		var saved []Task
+		var oldSaveTask func(task Task) error
+		var oldPageSize int64
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+
+			saved = []Task{}
+			oldSaveTask = saveTask
+			saveTask = func(task Task) error {
+				saved = append(saved, task)
+				return nil
+			}
+
+			oldPageSize = censusPageSize
+			censusPageSize = 2
+		})
+		AfterEach(func() {
+			ctl.Finish()
+			saveTask = oldSaveTask
+			censusPageSize = oldPageSize
+		})
+
+		It("list fail", func() {
+			mockService := mock.NewMockPolicyService(ctl)
+			mockService.EXPECT().ListExpressionAfterPK(int64(0), int64(2)).Return(nil, errors.New("list fail"))
+
+			c := &expressionCensus{svc: mockService, task: Task{ID: "abc", Status: TaskStatusRunning}}
+			c.run()
+
+			last := saved[len(saved)-1]
+			assert.Equal(GinkgoT(), TaskStatusFailed, last.Status)
+			assert.Contains(GinkgoT(), last.Error, "list fail")
+		})
+
+		It("ok", func() {
+			mockService := mock.NewMockPolicyService(ctl)
+			mockService.EXPECT().ListExpressionAfterPK(int64(0), int64(2)).Return([]types.AuthExpression{
+				{
+					PK: 1,
+					Expression: `[{"system": "bk_test", "type": "host", "expression": ` +
+						`{"StringEquals": {"id": ["1", "2"]}}}]`,
+				},
+				{
+					PK: 2,
+					Expression: `[{"system": "bk_test", "type": "host", "expression": {"AND": {"content": [` +
+						`{"StringPrefix": {"_bk_iam_path_": ["/biz,1/"]}}, {"NumericGt": {"cpu": [4]}}]}}}, ` +
+						`{"system": "bk_job", "type": "script", "expression": {"Any": {"id": []}}}]`,
+				},
+			}, nil)
+			mockService.EXPECT().ListExpressionAfterPK(int64(2), int64(2)).Return([]types.AuthExpression{
+				{PK: 3, Expression: `not json`},
+			}, nil)
+
+			c := &expressionCensus{svc: mockService, task: Task{ID: "abc", Status: TaskStatusRunning}}
+			c.run()
+
+			last := saved[len(saved)-1]
+			assert.Equal(GinkgoT(), TaskStatusFinished, last.Status)
+			assert.Equal(GinkgoT(), int64(3), last.Scanned)
+			assert.Equal(GinkgoT(), int64(1), last.Invalid)
+			assert.Equal(GinkgoT(), []SystemExpressionUsage{
+				{
+					System:      "bk_job",
+					Expressions: 1,
+					Operators:   map[string]int64{"Any": 1},
+					Fields:      map[string]int64{"script.id": 1},
+				},
+				{
+					System:      "bk_test",
+					Expressions: 2,
+					Operators:   map[string]int64{"StringEquals": 1, "AND": 1, "StringPrefix": 1, "NumericGt": 1},
+					Fields:      map[string]int64{"host.id": 1, "host._bk_iam_path_": 1, "host.cpu": 1},
+				},
+			}, last.Systems)
+		})
+	})
+
+	Describe("countPolicyCondition", func() {
+		It("invalid logical content", func() {
+			usage := &SystemExpressionUsage{Operators: map[string]int64{}, Fields: map[string]int64{}}
+			valid := countPolicyCondition(usage, "host", map[string]map[string][]interface{}{
+				"OR": {"content": {"abc"}},
+			})
+			assert.False(GinkgoT(), valid)
+			assert.Equal(GinkgoT(), map[string]int64{"OR": 1}, usage.Operators)
+		})
+	})
+})
@@ -0,0 +1,96 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package census
+
+import (
+	"encoding/hex"
+	"errors"
+	"time"
+
+	rediscache "github.com/go-redis/cache/v8"
+	"github.com/gofrs/uuid"
+
+	"iam/pkg/cache"
+	"iam/pkg/cache/impls"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+)
+
+// CensusLayer ...
+const CensusLayer = "Census"
+
+// Task status
+const (
+	TaskStatusRunning  = "running"
+	TaskStatusFinished = "finished"
+	TaskStatusFailed   = "failed"
+)
+
+// ErrTaskNotFound 任务不存在或已过期
+var ErrTaskNotFound = errors.New("census task not found")
+
+// Task 表达式统计任务的进度及结果
+type Task struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error"`
+
+	// 已扫描的表达式数量, 及其中无法解析的数量
+	Scanned int64 `json:"scanned"`
+	Invalid int64 `json:"invalid"`
+
+	// 任务完成后才有统计结果, 按系统排序
+	Systems []SystemExpressionUsage `json:"systems"`
+
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// StartExpressionCensus 创建表达式操作符/属性使用情况的统计任务, 后台扫描所有表达式
+func StartExpressionCensus() (task Task, err error) {
+	now := time.Now().Unix()
+	task = Task{
+		ID:        hex.EncodeToString(uuid.Must(uuid.NewV4()).Bytes()),
+		Status:    TaskStatusRunning,
+		Systems:   []SystemExpressionUsage{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err = saveTask(task)
+	if err != nil {
+		err = errorx.Wrapf(err, CensusLayer, "StartExpressionCensus", "saveTask task=`%+v` fail", task)
+		return
+	}
+
+	c := &expressionCensus{
+		svc:  service.NewPolicyService(),
+		task: task,
+	}
+	go c.run()
+
+	return task, nil
+}
+
+// GetTask 查询统计任务的进度及结果
+func GetTask(taskID string) (task Task, err error) {
+	err = impls.CensusTaskCache.Get(cache.NewStringKey(taskID), &task)
+	if errors.Is(err, rediscache.ErrCacheMiss) {
+		err = ErrTaskNotFound
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, CensusLayer, "GetTask", "CensusTaskCache.Get taskID=`%s` fail", taskID)
+	}
+	return
+}
+
+var saveTask = func(task Task) error {
+	return impls.CensusTaskCache.Set(cache.NewStringKey(task.ID), task, 0)
+}
@@ -30,12 +30,6 @@ func PrepareRequest(
 		err = errorWrapf(err, "MapValueInterfaceToString system.ProviderConfig=`%s` fail", system.ProviderConfig)
 		return
 	}
-	resourceTypeProviderConfig, err := util.MapValueInterfaceToString(resourceType.ProviderConfig)
-	if err != nil {
-		err = errorWrapf(err, "MapValueInterfaceToString resource.ProviderConfig=`%s` fail",
-			resourceType.ProviderConfig)
-		return
-	}
 
 	// NOTE: `token` in System.ProviderConfig is sensitive
 
@@ -59,9 +53,10 @@ func PrepareRequest(
 		}
 	}
 
-	path, ok := resourceTypeProviderConfig["path"]
+	// NOTE: the resourceType.ProviderConfig contains the non-string values, e.g. attribute_cache_ttl
+	path, ok := resourceType.ProviderConfig["path"].(string)
 	if !ok {
-		err = errorWrapf(err, "key `path` not in resourceType.ProviderConfig=`%s`", resourceTypeProviderConfig)
+		err = errorWrapf(err, "key `path` not in resourceType.ProviderConfig=`%s`", resourceType.ProviderConfig)
 		return
 	}
 
@@ -31,12 +31,18 @@ const (
 
 // BKRemoteResource ...
 var (
-	BKRemoteResource RemoteResourceClient
+	BKRemoteResource           RemoteResourceClient
+	BKMemberAddHook            MemberAddHookClient
+	BKModelChangeEventCallback ModelChangeEventCallbackClient
+	BKSubjectAttributeProvider SubjectAttributeProviderClient
 )
 
 // InitComponentClients ...
 func InitComponentClients() {
 	BKRemoteResource = NewRemoteResourceClient()
+	BKMemberAddHook = NewMemberAddHookClient()
+	BKModelChangeEventCallback = NewModelChangeEventCallbackClient()
+	BKSubjectAttributeProvider = NewSubjectAttributeProviderClient()
 }
 
 // CallbackFunc ...
@@ -0,0 +1,144 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package component
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/parnurzeal/gorequest"
+
+	"iam/pkg/errorx"
+	"iam/pkg/util"
+)
+
+// MemberAddHookDefaultTimeout ...
+const MemberAddHookDefaultTimeout = 5 * time.Second
+
+// MemberAddHookRequest ...
+type MemberAddHookRequest struct {
+	URL     string
+	Token   string
+	Timeout time.Duration
+}
+
+// MemberAddHookSubject ...
+type MemberAddHookSubject struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// MemberAddHookResult the decision of each member, the members not in the results are approved
+type MemberAddHookResult struct {
+	Type     string `json:"type"`
+	ID       string `json:"id"`
+	Decision string `json:"decision"`
+	Reason   string `json:"reason"`
+}
+
+// MemberAddHookResponse ...
+type MemberAddHookResponse struct {
+	Code    int                   `json:"code"`
+	Message string                `json:"message"`
+	Data    []MemberAddHookResult `json:"data"`
+}
+
+// Error ...
+func (r *MemberAddHookResponse) Error() error {
+	if r.Code == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("response error[code=`%d`,  message=`%s`]", r.Code, r.Message)
+}
+
+// MemberAddHookClient ...
+type MemberAddHookClient interface {
+	Check(
+		req MemberAddHookRequest,
+		group MemberAddHookSubject,
+		members []MemberAddHookSubject,
+		policyExpiredAt int64,
+		clientID string,
+	) ([]MemberAddHookResult, error)
+}
+
+type memberAddHookClient struct {
+}
+
+// NewMemberAddHookClient ...
+func NewMemberAddHookClient() MemberAddHookClient {
+	return &memberAddHookClient{}
+}
+
+// Check ask the hook whether the members can be added to the group
+func (c *memberAddHookClient) Check(
+	req MemberAddHookRequest,
+	group MemberAddHookSubject,
+	members []MemberAddHookSubject,
+	policyExpiredAt int64,
+	clientID string,
+) ([]MemberAddHookResult, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("MemberAddHookClient", "Check")
+
+	var err error
+
+	data := map[string]interface{}{
+		"group":             group,
+		"members":           members,
+		"policy_expired_at": policyExpiredAt,
+		"client_id":         clientID,
+	}
+
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = MemberAddHookDefaultTimeout
+	}
+
+	result := MemberAddHookResponse{}
+	start := time.Now()
+	callbackFunc := NewMetricCallback("member_add_hook", start)
+
+	request := gorequest.New().Timeout(timeout).Post(req.URL).Type("json")
+	if req.Token != "" {
+		request.Header.Set("Authorization", util.BasicAuthAuthorizationHeader("bk_iam", req.Token))
+	}
+	// do request
+	resp, respBody, errs := request.
+		Send(data).
+		EndStruct(&result, callbackFunc)
+
+	logFailHTTPRequest(start, request, resp, respBody, errs, &result)
+
+	if len(errs) != 0 {
+		// 敏感信息泄漏 ip+端口号, 替换为 *.*.*.*
+		errsMessage := fmt.Sprintf("gorequest errorx=`%s`", errs)
+		errsMessage = ipRegex.ReplaceAllString(errsMessage, replaceToIP)
+		err = errors.New(errsMessage)
+
+		err = errorWrapf(err, "errsCount=`%d`", len(errs))
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		err = errors.New("check member add hook not 200")
+		return nil, errorWrapf(err, "status=%d", resp.StatusCode)
+	}
+	if result.Code != 0 {
+		err = errors.New(result.Message)
+		err = errorWrapf(err, "result.Code=%d", result.Code)
+		return nil, err
+	}
+	return result.Data, nil
+}
@@ -5,6 +5,7 @@
 package mock
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	component "iam/pkg/component"
 	reflect "reflect"
@@ -34,31 +35,31 @@ func (m *MockRemoteResourceClient) EXPECT() *MockRemoteResourceClientMockRecorde
 }
 
 // QueryResources mocks base method
-func (m *MockRemoteResourceClient) QueryResources(req component.RemoteResourceRequest, system, _type string, ids, fields []string) ([]map[string]interface{}, error) {
+func (m *MockRemoteResourceClient) QueryResources(ctx context.Context, req component.RemoteResourceRequest, system, _type string, ids, fields []string) ([]map[string]interface{}, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "QueryResources", req, system, _type, ids, fields)
+	ret := m.ctrl.Call(m, "QueryResources", ctx, req, system, _type, ids, fields)
 	ret0, _ := ret[0].([]map[string]interface{})
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // QueryResources indicates an expected call of QueryResources
-func (mr *MockRemoteResourceClientMockRecorder) QueryResources(req, system, _type, ids, fields interface{}) *gomock.Call {
+func (mr *MockRemoteResourceClientMockRecorder) QueryResources(ctx, req, system, _type, ids, fields interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryResources", reflect.TypeOf((*MockRemoteResourceClient)(nil).QueryResources), req, system, _type, ids, fields)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryResources", reflect.TypeOf((*MockRemoteResourceClient)(nil).QueryResources), ctx, req, system, _type, ids, fields)
 }
 
 // GetResources mocks base method
-func (m *MockRemoteResourceClient) GetResources(req component.RemoteResourceRequest, system, _type string, ids, fields []string) ([]map[string]interface{}, error) {
+func (m *MockRemoteResourceClient) GetResources(ctx context.Context, req component.RemoteResourceRequest, system, _type string, ids, fields []string) ([]map[string]interface{}, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetResources", req, system, _type, ids, fields)
+	ret := m.ctrl.Call(m, "GetResources", ctx, req, system, _type, ids, fields)
 	ret0, _ := ret[0].([]map[string]interface{})
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetResources indicates an expected call of GetResources
-func (mr *MockRemoteResourceClientMockRecorder) GetResources(req, system, _type, ids, fields interface{}) *gomock.Call {
+func (mr *MockRemoteResourceClientMockRecorder) GetResources(ctx, req, system, _type, ids, fields interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResources", reflect.TypeOf((*MockRemoteResourceClient)(nil).GetResources), req, system, _type, ids, fields)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResources", reflect.TypeOf((*MockRemoteResourceClient)(nil).GetResources), ctx, req, system, _type, ids, fields)
 }
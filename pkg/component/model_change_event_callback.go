@@ -0,0 +1,120 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package component
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/parnurzeal/gorequest"
+
+	"iam/pkg/errorx"
+	"iam/pkg/util"
+)
+
+// ModelChangeEventCallbackDefaultTimeout ...
+const ModelChangeEventCallbackDefaultTimeout = 5 * time.Second
+
+// ModelChangeEventCallbackRequest ...
+type ModelChangeEventCallbackRequest struct {
+	URL     string
+	Token   string
+	Timeout time.Duration
+}
+
+// ModelChangeEvent the model change event processed by iam, e.g. the policies of the deleted action are cleaned
+type ModelChangeEvent struct {
+	PK        int64  `json:"pk"`
+	Type      string `json:"type"`
+	Status    string `json:"status"`
+	SystemID  string `json:"system_id"`
+	ModelType string `json:"model_type"`
+	ModelID   string `json:"model_id"`
+}
+
+// ModelChangeEventCallbackResponse ...
+type ModelChangeEventCallbackResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error ...
+func (r *ModelChangeEventCallbackResponse) Error() error {
+	if r.Code == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("response error[code=`%d`,  message=`%s`]", r.Code, r.Message)
+}
+
+// ModelChangeEventCallbackClient ...
+type ModelChangeEventCallbackClient interface {
+	Notify(req ModelChangeEventCallbackRequest, event ModelChangeEvent) error
+}
+
+type modelChangeEventCallbackClient struct {
+}
+
+// NewModelChangeEventCallbackClient ...
+func NewModelChangeEventCallbackClient() ModelChangeEventCallbackClient {
+	return &modelChangeEventCallbackClient{}
+}
+
+// Notify notify the system that its model change event has been processed
+func (c *modelChangeEventCallbackClient) Notify(req ModelChangeEventCallbackRequest, event ModelChangeEvent) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("ModelChangeEventCallbackClient", "Notify")
+
+	var err error
+
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = ModelChangeEventCallbackDefaultTimeout
+	}
+
+	result := ModelChangeEventCallbackResponse{}
+	start := time.Now()
+	callbackFunc := NewMetricCallback("model_change_event_callback", start)
+
+	request := gorequest.New().Timeout(timeout).Post(req.URL).Type("json")
+	if req.Token != "" {
+		request.Header.Set("Authorization", util.BasicAuthAuthorizationHeader("bk_iam", req.Token))
+	}
+	// do request
+	resp, respBody, errs := request.
+		Send(event).
+		EndStruct(&result, callbackFunc)
+
+	logFailHTTPRequest(start, request, resp, respBody, errs, &result)
+
+	if len(errs) != 0 {
+		// 敏感信息泄漏 ip+端口号, 替换为 *.*.*.*
+		errsMessage := fmt.Sprintf("gorequest errorx=`%s`", errs)
+		errsMessage = ipRegex.ReplaceAllString(errsMessage, replaceToIP)
+		err = errors.New(errsMessage)
+
+		err = errorWrapf(err, "errsCount=`%d`", len(errs))
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		err = errors.New("notify model change event callback not 200")
+		return errorWrapf(err, "status=%d", resp.StatusCode)
+	}
+	if result.Code != 0 {
+		err = errors.New(result.Message)
+		err = errorWrapf(err, "result.Code=%d", result.Code)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package component
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/util"
+)
+
+func TestModelChangeEventCallbackClient_Notify(t *testing.T) {
+	event := ModelChangeEvent{
+		PK:        1,
+		Type:      "action_policy_deleted",
+		Status:    "finished",
+		SystemID:  "bk_test",
+		ModelType: "action",
+		ModelID:   "edit",
+	}
+
+	t.Run("500", func(t *testing.T) {
+		ts := util.CreateTesting500Server()
+		defer ts.Close()
+
+		err := NewModelChangeEventCallbackClient().Notify(ModelChangeEventCallbackRequest{URL: ts.URL}, event)
+		assert.Error(t, err)
+	})
+
+	t.Run("code != 0", func(t *testing.T) {
+		ts := util.CreateTestingServer(map[string]interface{}{
+			"code":    1902000,
+			"message": "fail",
+		})
+		defer ts.Close()
+
+		err := NewModelChangeEventCallbackClient().Notify(ModelChangeEventCallbackRequest{URL: ts.URL}, event)
+		assert.Error(t, err)
+		assert.Equal(t, "[ModelChangeEventCallbackClient:Notify] result.Code=1902000 => [Raw:Error] fail", err.Error())
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ts := util.CreateTestingServer(map[string]interface{}{
+			"code":    0,
+			"message": "ok",
+		})
+		defer ts.Close()
+
+		err := NewModelChangeEventCallbackClient().Notify(
+			ModelChangeEventCallbackRequest{URL: ts.URL, Token: "token"}, event)
+		assert.NoError(t, err)
+	})
+}
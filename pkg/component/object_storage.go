@@ -0,0 +1,89 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package component
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/parnurzeal/gorequest"
+
+	"iam/pkg/errorx"
+	"iam/pkg/util"
+)
+
+// ObjectStorageUploadTimeout ...
+const ObjectStorageUploadTimeout = 60 * time.Second
+
+// ObjectStorageClient upload the file to the object storage(e.g. bkrepo generic repository) by http PUT
+type ObjectStorageClient interface {
+	Upload(path string, contentType string, data []byte) error
+}
+
+type objectStorageClient struct {
+	URL      string
+	Username string
+	Password string
+}
+
+// NewObjectStorageClient ...
+func NewObjectStorageClient(url, username, password string) ObjectStorageClient {
+	return &objectStorageClient{
+		URL:      strings.TrimRight(url, "/"),
+		Username: username,
+		Password: password,
+	}
+}
+
+// Upload put the data to `{URL}/{path}`, will overwrite the exists file
+func (c *objectStorageClient) Upload(path string, contentType string, data []byte) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("ObjectStorageClient", "Upload")
+
+	if c.URL == "" {
+		return errorWrapf(errors.New("object storage url not configured"), "path=`%s`", path)
+	}
+
+	url := c.URL + "/" + strings.TrimLeft(path, "/")
+
+	start := time.Now()
+	callbackFunc := NewMetricCallback("object_storage", start)
+
+	request := gorequest.New().Timeout(ObjectStorageUploadTimeout).Put(url).Type(gorequest.TypeText)
+	request.Header.Set("Content-Type", contentType)
+	request.Header.Set("X-BKREPO-OVERWRITE", "true")
+	if c.Username != "" {
+		request.Header.Set("Authorization", util.BasicAuthAuthorizationHeader(c.Username, c.Password))
+	}
+
+	resp, respBody, errs := request.Send(string(data)).EndBytes(func(response gorequest.Response, body []byte, errs []error) {
+		callbackFunc(response, nil, body, errs)
+	})
+
+	if len(errs) != 0 {
+		// 敏感信息泄漏 ip+端口号, 替换为 *.*.*.*
+		errsMessage := fmt.Sprintf("gorequest errorx=`%s`", errs)
+		errsMessage = ipRegex.ReplaceAllString(errsMessage, replaceToIP)
+		return errorWrapf(errors.New(errsMessage), "path=`%s`, errsCount=`%d`", path, len(errs))
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		if len(respBody) > maxResponseBodyLength {
+			respBody = respBody[:maxResponseBodyLength]
+		}
+		return errorWrapf(errors.New("upload to object storage not 200"),
+			"path=`%s`, status=%d, body=`%s`", path, resp.StatusCode, respBody)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package component
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectStorageClient_Upload(t *testing.T) {
+	// 1. url not configured
+	client := NewObjectStorageClient("", "", "")
+	err := client.Upload("a/b.csv", "text/csv", []byte("a,b\n"))
+	assert.Error(t, err)
+
+	// 2. 500
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+	client = NewObjectStorageClient(ts.URL, "admin", "123")
+	err = client.Upload("a/b.csv", "text/csv", []byte("a,b\n"))
+	assert.Error(t, err)
+
+	// 3. 200
+	var method, path, contentType, body string
+	var hasAuth bool
+	ts1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		path = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		_, _, hasAuth = r.BasicAuth()
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts1.Close()
+	client = NewObjectStorageClient(ts1.URL+"/", "admin", "123")
+	err = client.Upload("/a/b.csv", "text/csv", []byte("a,b\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/a/b.csv", path)
+	assert.Equal(t, "text/csv", contentType)
+	assert.True(t, hasAuth)
+	assert.Equal(t, "a,b\n", body)
+}
@@ -13,6 +13,7 @@ package component
 //go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -20,7 +21,9 @@ import (
 	"time"
 
 	"github.com/parnurzeal/gorequest"
+	log "github.com/sirupsen/logrus"
 
+	"iam/pkg/config"
 	"iam/pkg/errorx"
 )
 
@@ -36,6 +39,41 @@ var (
 	ipRegex = regexp.MustCompile(ipRegexString)
 )
 
+// remoteResourceRetryPolicy 资源属性查询的重试策略, 所有的重试共享deadline
+type remoteResourceRetryPolicy struct {
+	count    int
+	backoff  time.Duration
+	deadline time.Duration
+}
+
+// 默认不重试
+var (
+	defaultRemoteResourceRetry = remoteResourceRetryPolicy{
+		count:    0,
+		backoff:  100 * time.Millisecond,
+		deadline: RemoteResourceTimeout,
+	}
+	remoteResourceRetry = defaultRemoteResourceRetry
+)
+
+// InitRemoteResourceRetry ...
+func InitRemoteResourceRetry(cfg config.RemoteResourceRetry) {
+	retry := defaultRemoteResourceRetry
+	if cfg.Count > 0 {
+		retry.count = cfg.Count
+	}
+	if cfg.BackoffMilliseconds > 0 {
+		retry.backoff = time.Duration(cfg.BackoffMilliseconds) * time.Millisecond
+	}
+	if cfg.DeadlineSeconds > 0 {
+		retry.deadline = time.Duration(cfg.DeadlineSeconds) * time.Second
+	}
+	remoteResourceRetry = retry
+
+	log.Infof("init remote resource retry: count=%d, backoff=%s, deadline=%s",
+		retry.count, retry.backoff, retry.deadline)
+}
+
 // RemoteResourceRequest ...
 type RemoteResourceRequest struct {
 	URL     string
@@ -60,9 +98,9 @@ func (r *RemoteResourceResponse) Error() error {
 
 // RemoteResourceClient ...
 type RemoteResourceClient interface {
-	QueryResources(req RemoteResourceRequest, system string, _type string, ids []string,
+	QueryResources(ctx context.Context, req RemoteResourceRequest, system string, _type string, ids []string,
 		fields []string) ([]map[string]interface{}, error)
-	GetResources(req RemoteResourceRequest, system string, _type string, ids []string,
+	GetResources(ctx context.Context, req RemoteResourceRequest, system string, _type string, ids []string,
 		fields []string) ([]map[string]interface{}, error)
 }
 
@@ -74,8 +112,10 @@ func NewRemoteResourceClient() RemoteResourceClient {
 	return &remoteResourceClient{}
 }
 
-// QueryResources ...
+// QueryResources 查询资源的属性, 网络错误或5xx时按重试策略重试
+// NOTE: gorequest不支持context, 每次请求的超时时间取剩余的时间, 所有的重试不会超过ctx的deadline
 func (c *remoteResourceClient) QueryResources(
+	ctx context.Context,
 	req RemoteResourceRequest,
 	system, _type string,
 	ids []string,
@@ -83,9 +123,6 @@ func (c *remoteResourceClient) QueryResources(
 ) ([]map[string]interface{}, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf("RemoteResourceClient", "QueryResources")
 
-	var err error
-	url := req.URL
-
 	data := map[string]interface{}{
 		"type":   _type,
 		"method": "fetch_instance_info",
@@ -95,11 +132,63 @@ func (c *remoteResourceClient) QueryResources(
 		},
 	}
 
+	retry := remoteResourceRetry
+	ctx, cancel := context.WithTimeout(ctx, retry.deadline)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
+	backoff := retry.backoff
+	for attempt := 1; ; attempt++ {
+		timeout := time.Until(deadline)
+		if ctx.Err() != nil || timeout <= 0 {
+			err := ctx.Err()
+			if err == nil {
+				err = context.DeadlineExceeded
+			}
+			return nil, errorWrapf(err, "ctx done before the request, attempts=`%d`", attempt-1)
+		}
+		if timeout > RemoteResourceTimeout {
+			timeout = RemoteResourceTimeout
+		}
+
+		resources, retryable, err := c.doQueryResources(req, system, data, timeout)
+		if err == nil {
+			return resources, nil
+		}
+
+		// 业务错误不重试; 剩余的时间不够等待backoff时, 也不再重试
+		if !retryable || attempt > retry.count || time.Until(deadline) <= backoff {
+			return nil, err
+		}
+
+		log.WithError(err).Warnf("query resources from %s fail, will retry after %s, attempt=%d",
+			system, backoff, attempt)
+
+		select {
+		case <-ctx.Done():
+			return nil, errorWrapf(err, "retry canceled, ctx.Err=`%s`", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// doQueryResources 请求一次资源提供方, retryable表示失败是否可以重试(网络错误或5xx)
+func (c *remoteResourceClient) doQueryResources(
+	req RemoteResourceRequest,
+	system string,
+	data map[string]interface{},
+	timeout time.Duration,
+) (resources []map[string]interface{}, retryable bool, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("RemoteResourceClient", "QueryResources")
+
+	url := req.URL
+
 	result := RemoteResourceResponse{}
 	start := time.Now()
 	callbackFunc := NewMetricCallback(system, start)
 
-	request := gorequest.New().Timeout(RemoteResourceTimeout).Post(url).Type("json")
+	request := gorequest.New().Timeout(timeout).Post(url).Type("json")
 	// set headers
 	if len(req.Headers) > 0 {
 		for key, value := range req.Headers {
@@ -120,22 +209,23 @@ func (c *remoteResourceClient) QueryResources(
 		err = errors.New(errsMessage)
 
 		err = errorWrapf(err, "errsCount=`%d`", len(errs))
-		return nil, err
+		return nil, true, err
 	}
 	if resp.StatusCode != http.StatusOK {
 		err = fmt.Errorf("query resources from %s not 200", system)
-		return nil, errorWrapf(err, "status=%d", resp.StatusCode)
+		return nil, resp.StatusCode >= http.StatusInternalServerError, errorWrapf(err, "status=%d", resp.StatusCode)
 	}
 	if result.Code != 0 {
 		err = errors.New(result.Message)
 		err = errorWrapf(err, "result.Code=%d", result.Code)
-		return nil, err
+		return nil, false, err
 	}
-	return result.Data, nil
+	return result.Data, false, nil
 }
 
 // GetResources ...
 func (c *remoteResourceClient) GetResources(
+	ctx context.Context,
 	req RemoteResourceRequest,
 	system string,
 	_type string,
@@ -144,7 +234,7 @@ func (c *remoteResourceClient) GetResources(
 ) ([]map[string]interface{}, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf("RemoteResourceClient", "QueryResources")
 
-	data, err := c.QueryResources(req, system, _type, ids, fields)
+	data, err := c.QueryResources(ctx, req, system, _type, ids, fields)
 	if err != nil {
 		return nil, errorWrapf(err, "queryResources system=`%s`, type=`%s`, ids=`%v`, fields=`%v` fail",
 			system, _type, ids, fields)
@@ -11,8 +11,14 @@
 package component
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"iam/pkg/config"
 	"iam/pkg/util"
 
 	"github.com/stretchr/testify/assert"
@@ -30,7 +36,8 @@ func TestRemoteResourceClient_QueryResources(t *testing.T) {
 		Headers: nil,
 	}
 
-	resources, err := client.QueryResources(req, "paas", "app", []string{"1", "2"}, []string{"name"})
+	resources, err := client.QueryResources(
+		context.Background(), req, "paas", "app", []string{"1", "2"}, []string{"name"})
 	assert.Error(t, err)
 	assert.Nil(t, resources)
 
@@ -45,7 +52,8 @@ func TestRemoteResourceClient_QueryResources(t *testing.T) {
 		URL:     ts1.URL,
 		Headers: nil,
 	}
-	resources, err = client1.QueryResources(req1, "paas", "app", []string{"1", "2"}, []string{"name"})
+	resources, err = client1.QueryResources(
+		context.Background(), req1, "paas", "app", []string{"1", "2"}, []string{"name"})
 	assert.Error(t, err)
 	assert.Equal(t, "[RemoteResourceClient:QueryResources] result.Code=140000 => [Raw:Error] fail", err.Error())
 	assert.Empty(t, resources)
@@ -64,8 +72,113 @@ func TestRemoteResourceClient_QueryResources(t *testing.T) {
 		URL:     ts2.URL,
 		Headers: nil,
 	}
-	resources, err = client2.QueryResources(req2, "paas", "app", []string{"1", "2"}, []string{"name"})
+	resources, err = client2.QueryResources(
+		context.Background(), req2, "paas", "app", []string{"1", "2"}, []string{"name"})
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(resources))
 	assert.Equal(t, "tom", resources[0]["name"])
 }
+
+func newCountingRemoteResourceServer(failures int32) (*httptest.Server, *int32) {
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) <= failures {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code": 0, "message": "ok", "data": [{"id": "1", "name": "tom"}]}`))
+	}))
+	return ts, &count
+}
+
+func TestRemoteResourceClient_QueryResourcesRetry(t *testing.T) {
+	old := remoteResourceRetry
+	defer func() {
+		remoteResourceRetry = old
+	}()
+	InitRemoteResourceRetry(config.RemoteResourceRetry{Count: 2, BackoffMilliseconds: 1, DeadlineSeconds: 5})
+
+	client := NewRemoteResourceClient()
+
+	t.Run("retry on 5xx then success", func(t *testing.T) {
+		ts, count := newCountingRemoteResourceServer(2)
+		defer ts.Close()
+
+		resources, err := client.QueryResources(
+			context.Background(), RemoteResourceRequest{URL: ts.URL}, "paas", "app", []string{"1"}, []string{"name"})
+		assert.NoError(t, err)
+		assert.Len(t, resources, 1)
+		assert.Equal(t, int32(3), atomic.LoadInt32(count))
+	})
+
+	t.Run("retry count exceeded", func(t *testing.T) {
+		ts, count := newCountingRemoteResourceServer(10)
+		defer ts.Close()
+
+		_, err := client.QueryResources(
+			context.Background(), RemoteResourceRequest{URL: ts.URL}, "paas", "app", []string{"1"}, []string{"name"})
+		assert.Error(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(count))
+	})
+
+	t.Run("no retry on business error", func(t *testing.T) {
+		var count int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&count, 1)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"code": 140000, "message": "fail"}`))
+		}))
+		defer ts.Close()
+
+		_, err := client.QueryResources(
+			context.Background(), RemoteResourceRequest{URL: ts.URL}, "paas", "app", []string{"1"}, []string{"name"})
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+	})
+
+	t.Run("never exceed the deadline of the caller", func(t *testing.T) {
+		InitRemoteResourceRetry(config.RemoteResourceRetry{Count: 100, BackoffMilliseconds: 20, DeadlineSeconds: 5})
+
+		ts, count := newCountingRemoteResourceServer(1000)
+		defer ts.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := client.QueryResources(
+			ctx, RemoteResourceRequest{URL: ts.URL}, "paas", "app", []string{"1"}, []string{"name"})
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Less(t, atomic.LoadInt32(count), int32(10))
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ts, count := newCountingRemoteResourceServer(0)
+		defer ts.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := client.QueryResources(
+			ctx, RemoteResourceRequest{URL: ts.URL}, "paas", "app", []string{"1"}, []string{"name"})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int32(0), atomic.LoadInt32(count))
+	})
+}
+
+func TestInitRemoteResourceRetry(t *testing.T) {
+	old := remoteResourceRetry
+	defer func() {
+		remoteResourceRetry = old
+	}()
+
+	InitRemoteResourceRetry(config.RemoteResourceRetry{})
+	assert.Equal(t, defaultRemoteResourceRetry, remoteResourceRetry)
+
+	InitRemoteResourceRetry(config.RemoteResourceRetry{Count: 3, BackoffMilliseconds: 200, DeadlineSeconds: 10})
+	assert.Equal(t, 3, remoteResourceRetry.count)
+	assert.Equal(t, 200*time.Millisecond, remoteResourceRetry.backoff)
+	assert.Equal(t, 10*time.Second, remoteResourceRetry.deadline)
+}
@@ -0,0 +1,126 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package component
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/parnurzeal/gorequest"
+
+	"iam/pkg/errorx"
+	"iam/pkg/util"
+)
+
+// SubjectAttributeProviderDefaultTimeout ...
+const SubjectAttributeProviderDefaultTimeout = 3 * time.Second
+
+// SubjectAttributeProviderRequest ...
+type SubjectAttributeProviderRequest struct {
+	URL     string
+	Token   string
+	Timeout time.Duration
+}
+
+// SubjectAttributeProviderResponse ...
+type SubjectAttributeProviderResponse struct {
+	Code    int                    `json:"code"`
+	Message string                 `json:"message"`
+	Data    map[string]interface{} `json:"data"`
+}
+
+// Error ...
+func (r *SubjectAttributeProviderResponse) Error() error {
+	if r.Code == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("response error[code=`%d`,  message=`%s`]", r.Code, r.Message)
+}
+
+// SubjectAttributeProviderClient ...
+type SubjectAttributeProviderClient interface {
+	GetAttributes(
+		req SubjectAttributeProviderRequest,
+		subjectType, subjectID string,
+		attributes []string,
+	) (map[string]interface{}, error)
+}
+
+type subjectAttributeProviderClient struct {
+}
+
+// NewSubjectAttributeProviderClient ...
+func NewSubjectAttributeProviderClient() SubjectAttributeProviderClient {
+	return &subjectAttributeProviderClient{}
+}
+
+// GetAttributes query the attributes of the subject from the provider
+func (c *subjectAttributeProviderClient) GetAttributes(
+	req SubjectAttributeProviderRequest,
+	subjectType, subjectID string,
+	attributes []string,
+) (map[string]interface{}, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("SubjectAttributeProviderClient", "GetAttributes")
+
+	var err error
+
+	data := map[string]interface{}{
+		"subject": map[string]string{
+			"type": subjectType,
+			"id":   subjectID,
+		},
+		"attributes": attributes,
+	}
+
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = SubjectAttributeProviderDefaultTimeout
+	}
+
+	result := SubjectAttributeProviderResponse{}
+	start := time.Now()
+	callbackFunc := NewMetricCallback("subject_attribute_provider", start)
+
+	request := gorequest.New().Timeout(timeout).Post(req.URL).Type("json")
+	if req.Token != "" {
+		request.Header.Set("Authorization", util.BasicAuthAuthorizationHeader("bk_iam", req.Token))
+	}
+	// do request
+	resp, respBody, errs := request.
+		Send(data).
+		EndStruct(&result, callbackFunc)
+
+	logFailHTTPRequest(start, request, resp, respBody, errs, &result)
+
+	if len(errs) != 0 {
+		// 敏感信息泄漏 ip+端口号, 替换为 *.*.*.*
+		errsMessage := fmt.Sprintf("gorequest errorx=`%s`", errs)
+		errsMessage = ipRegex.ReplaceAllString(errsMessage, replaceToIP)
+		err = errors.New(errsMessage)
+
+		err = errorWrapf(err, "errsCount=`%d`", len(errs))
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		err = errors.New("get subject attributes not 200")
+		return nil, errorWrapf(err, "status=%d", resp.StatusCode)
+	}
+	if result.Code != 0 {
+		err = errors.New(result.Message)
+		err = errorWrapf(err, "result.Code=%d", result.Code)
+		return nil, err
+	}
+	return result.Data, nil
+}
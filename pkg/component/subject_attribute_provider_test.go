@@ -0,0 +1,59 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package component
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/util"
+)
+
+func newRawJSONServer(body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+}
+
+func TestSubjectAttributeProviderClient_GetAttributes(t *testing.T) {
+	t.Run("500", func(t *testing.T) {
+		ts := util.CreateTesting500Server()
+		defer ts.Close()
+
+		_, err := NewSubjectAttributeProviderClient().GetAttributes(
+			SubjectAttributeProviderRequest{URL: ts.URL}, "user", "tom", []string{"job_level"})
+		assert.Error(t, err)
+	})
+
+	t.Run("code != 0", func(t *testing.T) {
+		ts := newRawJSONServer(`{"code": 1902000, "message": "fail"}`)
+		defer ts.Close()
+
+		_, err := NewSubjectAttributeProviderClient().GetAttributes(
+			SubjectAttributeProviderRequest{URL: ts.URL}, "user", "tom", []string{"job_level"})
+		assert.Error(t, err)
+		assert.Equal(t, "[SubjectAttributeProviderClient:GetAttributes] result.Code=1902000 => [Raw:Error] fail",
+			err.Error())
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ts := newRawJSONServer(`{"code": 0, "message": "ok", "data": {"job_level": 3}}`)
+		defer ts.Close()
+
+		attrs, err := NewSubjectAttributeProviderClient().GetAttributes(
+			SubjectAttributeProviderRequest{URL: ts.URL, Token: "token"}, "user", "tom", []string{"job_level"})
+		assert.NoError(t, err)
+		assert.Equal(t, float64(3), attrs["job_level"])
+	})
+}
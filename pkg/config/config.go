@@ -31,12 +31,79 @@ type Server struct {
 // Cache ...
 type Cache struct {
 	Disabled bool
+
+	// the default size limit of each local memory cache, 0 means unlimited(only expired by the ttl)
+	// the least recently used entries will be evicted when exceeding the limit
+	MaxEntries int
+	MaxBytes   int64
+	// the size limit of the specified local memory caches, by the cache name
+	Limits []MemoryCacheLimit
+}
+
+// MemoryCacheLimit the size limit of a local memory cache, e.g. local_subject_pk
+type MemoryCacheLimit struct {
+	Name       string
+	MaxEntries int
+	MaxBytes   int64
 }
 
 // PolicyCache ...
 type PolicyCache struct {
 	Disabled       bool
 	ExpirationDays int64
+
+	// the cache backend of policies, `both`(default), `memory` or `redis`
+	Backend string
+	Systems []SystemPolicyCacheBackend
+}
+
+// SystemPolicyCacheBackend store the policy cache backend for specific system
+type SystemPolicyCacheBackend struct {
+	ID      string
+	Backend string
+}
+
+// Warmup the config of preloading the hot caches before the server starts serving
+type Warmup struct {
+	Enabled bool
+	// the top N subjects by recent auth traffic to preload, 0 means not to preload subjects
+	TopSubjects int
+	// the max seconds of warming up, the rest will be skipped after timeout
+	Timeout int64
+}
+
+// PriorityLane the config of shedding the low priority requests(web/model apis) when the database is under pressure,
+// the auth/query apis are never shed
+type PriorityLane struct {
+	Enabled bool
+	// the database is under pressure when the average wait time of getting a connection exceeds it, in milliseconds
+	WaitThreshold int64
+	// the seconds between two samplings of the database connection pool
+	CheckInterval int64
+	// the max concurrent low priority requests when the database is under pressure
+	Concurrency int
+	// the max milliseconds of a low priority request queueing for a slot, will be shed after timeout
+	QueueTimeout int64
+}
+
+// ExpiredMemberPurge the config of the scheduled task purging the subject relations expired long ago
+type ExpiredMemberPurge struct {
+	Enabled bool
+	// the relations expired more than the days will be purged
+	RetentionDays int64
+	// the seconds between two runs
+	Interval int64
+	// the count of relations purged in one transaction
+	BatchSize int64
+}
+
+// SubjectSystemGroupReconcile the config of the scheduled task reconciling the subject system groups
+type SubjectSystemGroupReconcile struct {
+	Enabled bool
+	// the seconds between two runs
+	Interval int64
+	// the count of subjects reconciled in one batch
+	BatchSize int64
 }
 
 // Logger ...
@@ -47,6 +114,7 @@ type Logger struct {
 	Audit     LogConfig
 	Web       LogConfig
 	Component LogConfig
+	Access    LogConfig
 }
 
 // LogConfig ...
@@ -68,6 +136,10 @@ type Database struct {
 	MaxOpenConns          int
 	MaxIdleConns          int
 	ConnMaxLifetimeSecond int
+
+	// 读写超时时间, 0表示不设置
+	ReadTimeoutSecond  int
+	WriteTimeoutSecond int
 }
 
 // Redis ...
@@ -87,6 +159,12 @@ type Redis struct {
 	SentinelAddr     string
 	MasterName       string
 	SentinelPassword string
+
+	// mode=cluster required, the comma separated addresses of the cluster nodes
+	ClusterAddr string
+
+	// mode=sentinel/cluster, where the read-only commands are routed to: master(default)/replica/nearest
+	ReadPreference string
 }
 
 // Sentry ...
@@ -101,6 +179,9 @@ type Quota struct {
 
 	// NOTE: only used for rate limit middleware, will remove in the future
 	API map[string]int
+
+	// the default and maximum expiration days of group members
+	Member map[string]int
 }
 
 // SystemQuota store the settings for specific system
@@ -109,6 +190,137 @@ type SystemQuota struct {
 	Quota Quota
 }
 
+// EvalConcurrency limit the concurrent evaluations of each system, 0 means no limit
+type EvalConcurrency struct {
+	Default int
+	Systems []SystemEvalConcurrency
+}
+
+// SystemEvalConcurrency store the concurrent evaluations limit for specific system
+type SystemEvalConcurrency struct {
+	ID    string
+	Limit int
+}
+
+// EvaluationMode the policy evaluation mode of each system, `deny_overrides`(default) or `first_match`
+type EvaluationMode struct {
+	Default string
+	Systems []SystemEvaluationMode
+}
+
+// SystemEvaluationMode store the policy evaluation mode for specific system
+type SystemEvaluationMode struct {
+	ID   string
+	Mode string
+}
+
+// EvalFailurePolicy the behavior of each system when the dependencies(redis/database/remote resource) fail
+// during evaluation, `error`(default), `fail_closed` or `serve_stale`
+type EvalFailurePolicy struct {
+	Default string
+	Systems []SystemEvalFailurePolicy
+}
+
+// SystemEvalFailurePolicy store the dependency failure behavior for specific system
+type SystemEvalFailurePolicy struct {
+	ID   string
+	Mode string
+	// the read-only and low-risk actions which will be allowed when the dependencies fail
+	FailOpenActions []string
+}
+
+// RemoteResourceBreaker the circuit breaker of the remote resource attribute calls of each resource provider system,
+// opens after `FailureThreshold` consecutive failures(0 means disabled), and half-open after `OpenSeconds`.
+// the behavior when the calls fail or the breaker is open, `fail_closed`(default) or `degrade`
+type RemoteResourceBreaker struct {
+	FailureThreshold int
+	OpenSeconds      int
+	Default          string
+	Systems          []SystemRemoteResourceBreaker
+}
+
+// SystemRemoteResourceBreaker store the remote resource failure behavior for specific resource provider system
+type SystemRemoteResourceBreaker struct {
+	ID   string
+	Mode string
+}
+
+// RemoteResourceRetry the retry of the remote resource attribute calls to the resource provider systems,
+// retry at most `Count` times(0 means no retry) on the network errors or 5xx responses, wait `BackoffMilliseconds`
+// before the first retry and double it for each next retry; all the attempts share the `DeadlineSeconds` budget,
+// and never exceed the deadline of the incoming request
+type RemoteResourceRetry struct {
+	Count               int
+	BackoffMilliseconds int
+	DeadlineSeconds     int
+}
+
+// DisabledAction the evaluation behavior of each system when the requested action is disabled,
+// `deny`(default, response with the action disabled error) or `bypass`(allowed)
+type DisabledAction struct {
+	Default string
+	Systems []SystemDisabledAction
+}
+
+// SystemDisabledAction store the disabled action behavior for specific system
+type SystemDisabledAction struct {
+	ID   string
+	Mode string
+}
+
+// MemberAddHook the pre-commit hook of adding group members, can reject the members or mark them as pending
+type MemberAddHook struct {
+	Name string
+	// the hook only works for these groups, empty means all groups
+	GroupIDs []string
+	// the app_codes which can skip the hook, e.g. the approval system commits the members after approved
+	BypassClients []string
+
+	// http callback
+	URL   string
+	Token string
+	// seconds
+	Timeout int
+
+	// embedded rule, works if the url is empty: the members of RuleMemberTypes will be rejected/pending
+	RuleMemberTypes []string
+	RuleDecision    string
+}
+
+// AccessLog the sampling of the access log, the error requests(status >= 400) are always logged
+type AccessLog struct {
+	// the default sample rate of all routes, 0 means 1.0(log all)
+	SampleRate float64
+	Routes     []AccessLogRoute
+}
+
+// AccessLogRoute the sample rate of the route, the path is the route pattern, e.g. /api/v1/policy/auth
+type AccessLogRoute struct {
+	Path       string
+	SampleRate float64
+}
+
+// Export the config of exporting the subject relations for BI
+type Export struct {
+	// the object storage(e.g. bkrepo generic repository) to store the datasets
+	StorageURL      string
+	StorageUsername string
+	StoragePassword string
+
+	// the salt of hashing the usernames when anonymize
+	AnonymizeSalt string
+}
+
+// SubjectRelationEventSink the sink of the group member change events(added/removed/renewed) for downstream sync,
+// `redis_stream` or empty(disabled)
+type SubjectRelationEventSink struct {
+	Type string
+	// redis_stream: the key of the stream, default `iam:subject_relation_events`
+	Stream string
+	// redis_stream: the approximate max length of the stream, the old events will be trimmed, default 100000
+	MaxLen int64
+}
+
 // type Host struct {
 // 	ID   string
 // 	Addr string
@@ -144,14 +356,41 @@ type Config struct {
 	CustomQuotas    []SystemQuota
 	CustomQuotasMap map[string]Quota
 
+	EvalConcurrency    EvalConcurrency
+	EvalConcurrencyMap map[string]int
+
+	EvaluationMode    EvaluationMode
+	EvaluationModeMap map[string]string
+
+	EvalFailurePolicy EvalFailurePolicy
+
+	RemoteResourceBreaker RemoteResourceBreaker
+	RemoteResourceRetry   RemoteResourceRetry
+
+	DisabledAction DisabledAction
+
+	MemberAddHooks []MemberAddHook
+
+	Export Export
+
+	SubjectRelationEventSink SubjectRelationEventSink
+
+	AccessLog AccessLog
+
 	// Hosts   []Host
 	// HostMap map[string]Host
 	Switch map[string]bool
 
 	Cache       Cache
 	PolicyCache PolicyCache
+	Warmup      Warmup
 	Logger      Logger
 
+	PriorityLane PriorityLane
+
+	ExpiredMemberPurge          ExpiredMemberPurge
+	SubjectSystemGroupReconcile SubjectSystemGroupReconcile
+
 	Cryptos map[string]*Crypto
 }
 
@@ -186,6 +425,18 @@ func Load(v *viper.Viper) (*Config, error) {
 		cfg.CustomQuotasMap[q.ID] = q.Quota
 	}
 
+	// 4. eval concurrency
+	cfg.EvalConcurrencyMap = make(map[string]int)
+	for _, ec := range cfg.EvalConcurrency.Systems {
+		cfg.EvalConcurrencyMap[ec.ID] = ec.Limit
+	}
+
+	// 5. evaluation mode
+	cfg.EvaluationModeMap = make(map[string]string)
+	for _, em := range cfg.EvaluationMode.Systems {
+		cfg.EvaluationModeMap[em.ID] = em.Mode
+	}
+
 	// 3. hosts
 	// cfg.HostMap = make(map[string]Host)
 	// for _, host := range cfg.Hosts {
@@ -0,0 +1,157 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dao
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"iam/pkg/database"
+)
+
+// AuditFilter 审计查询的过滤条件, 空值表示不过滤; 时间范围为[StartTime, EndTime)
+// NOTE: 部门变更记录没有system_id/operator, 只有角色变更记录支持这两个条件
+type AuditFilter struct {
+	SubjectPK int64
+	System    string
+	Operator  string
+	Source    string
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// AuditManager 按过滤条件查询各个变更记录表, 使用pk游标分页(pk倒序, 即最近的在前)
+type AuditManager interface {
+	ListDepartmentHistoryBeforePK(filter AuditFilter, beforePK, limit int64) ([]SubjectDepartmentHistory, error)
+	ListRoleHistoryBeforePK(filter AuditFilter, beforePK, limit int64) ([]SubjectRoleHistory, error)
+}
+
+type auditManager struct {
+	DB *sqlx.DB
+}
+
+// NewAuditManager ...
+func NewAuditManager() AuditManager {
+	return &auditManager{
+		DB: database.GetDefaultDBClient().DB,
+	}
+}
+
+// NewBatchAuditManager 使用批量任务的连接池, 用于导出大时间范围的审计记录
+func NewBatchAuditManager() AuditManager {
+	return &auditManager{
+		DB: database.GetBatchDBClient().DB,
+	}
+}
+
+// ListDepartmentHistoryBeforePK 查询pk小于beforePK的部门变更记录, beforePK为0时从最新的开始
+func (m *auditManager) ListDepartmentHistoryBeforePK(
+	filter AuditFilter, beforePK, limit int64,
+) (histories []SubjectDepartmentHistory, err error) {
+	err = m.selectDepartmentHistoryBeforePK(&histories, filter, beforePK, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return histories, nil
+	}
+	return
+}
+
+// ListRoleHistoryBeforePK 查询pk小于beforePK的角色变更记录, beforePK为0时从最新的开始
+func (m *auditManager) ListRoleHistoryBeforePK(
+	filter AuditFilter, beforePK, limit int64,
+) (histories []SubjectRoleHistory, err error) {
+	err = m.selectRoleHistoryBeforePK(&histories, filter, beforePK, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return histories, nil
+	}
+	return
+}
+
+func auditFilterCondition(filter AuditFilter, beforePK int64, withRoleFields bool) (string, []interface{}) {
+	conditions := make([]string, 0, 7)
+	args := make([]interface{}, 0, 7)
+	if beforePK > 0 {
+		conditions = append(conditions, "pk < ?")
+		args = append(args, beforePK)
+	}
+	if filter.SubjectPK > 0 {
+		conditions = append(conditions, "subject_pk = ?")
+		args = append(args, filter.SubjectPK)
+	}
+	if withRoleFields && filter.System != "" {
+		conditions = append(conditions, "system_id = ?")
+		args = append(args, filter.System)
+	}
+	if withRoleFields && filter.Operator != "" {
+		conditions = append(conditions, "operator = ?")
+		args = append(args, filter.Operator)
+	}
+	if filter.Source != "" {
+		conditions = append(conditions, "source = ?")
+		args = append(args, filter.Source)
+	}
+	if !filter.StartTime.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.EndTime)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func (m *auditManager) selectDepartmentHistoryBeforePK(
+	histories *[]SubjectDepartmentHistory, filter AuditFilter, beforePK, limit int64,
+) error {
+	condition, args := auditFilterCondition(filter, beforePK, false)
+	query := `SELECT
+		pk,
+		subject_pk,
+		department_pk,
+		action,
+		source,
+		created_at
+		FROM subject_department_history` + condition + `
+		ORDER BY pk DESC
+		LIMIT ?`
+	args = append(args, limit)
+	return database.SqlxSelect(m.DB, histories, query, args...)
+}
+
+func (m *auditManager) selectRoleHistoryBeforePK(
+	histories *[]SubjectRoleHistory, filter AuditFilter, beforePK, limit int64,
+) error {
+	condition, args := auditFilterCondition(filter, beforePK, true)
+	query := `SELECT
+		pk,
+		subject_pk,
+		role_type,
+		system_id,
+		action,
+		operator,
+		source,
+		created_at
+		FROM subject_role_history` + condition + `
+		ORDER BY pk DESC
+		LIMIT ?`
+	args = append(args, limit)
+	return database.SqlxSelect(m.DB, histories, query, args...)
+}
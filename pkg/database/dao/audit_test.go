@@ -0,0 +1,70 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dao
+
+import (
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+)
+
+func Test_auditManager_ListDepartmentHistoryBeforePK(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Now()
+		start := time.Unix(1629000000, 0)
+		mockQuery := `^SELECT pk, subject_pk, department_pk, action, source, created_at ` +
+			`FROM subject_department_history WHERE pk < (.*) AND subject_pk = (.*) AND created_at >= (.*) ` +
+			`ORDER BY pk DESC LIMIT (.*)$`
+		mockRows := sqlmock.NewRows([]string{
+			"pk", "subject_pk", "department_pk", "action", "source", "created_at",
+		}).AddRow(int64(9), int64(1), int64(2), "added", "bk_iam", now)
+		mock.ExpectQuery(mockQuery).WithArgs(int64(10), int64(1), start, int64(100)).WillReturnRows(mockRows)
+
+		manager := &auditManager{DB: db}
+		// system/operator are ignored for the department history
+		histories, err := manager.ListDepartmentHistoryBeforePK(
+			AuditFilter{SubjectPK: 1, System: "bk_cmdb", Operator: "admin", StartTime: start}, 10, 100)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []SubjectDepartmentHistory{{
+			PK: 9, SubjectPK: 1, DepartmentPK: 2, Action: "added", Source: "bk_iam", CreatedAt: now,
+		}}, histories)
+	})
+}
+
+func Test_auditManager_ListRoleHistoryBeforePK(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Now()
+		end := time.Unix(1629000000, 0)
+		mockQuery := `^SELECT pk, subject_pk, role_type, system_id, action, operator, source, created_at ` +
+			`FROM subject_role_history WHERE system_id = (.*) AND operator = (.*) AND source = (.*) ` +
+			`AND created_at < (.*) ORDER BY pk DESC LIMIT (.*)$`
+		mockRows := sqlmock.NewRows([]string{
+			"pk", "subject_pk", "role_type", "system_id", "action", "operator", "source", "created_at",
+		}).AddRow(int64(3), int64(1), "system_manager", "bk_cmdb", "granted", "admin", "bk_iam", now)
+		mock.ExpectQuery(mockQuery).WithArgs("bk_cmdb", "admin", "bk_iam", end, int64(100)).WillReturnRows(mockRows)
+
+		manager := &auditManager{DB: db}
+		histories, err := manager.ListRoleHistoryBeforePK(
+			AuditFilter{System: "bk_cmdb", Operator: "admin", Source: "bk_iam", EndTime: end}, 0, 100)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []SubjectRoleHistory{{
+			PK: 3, SubjectPK: 1, RoleType: "system_manager", System: "bk_cmdb", Action: "granted",
+			Operator: "admin", Source: "bk_iam", CreatedAt: now,
+		}}, histories)
+	})
+}
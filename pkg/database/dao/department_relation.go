@@ -0,0 +1,143 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package dao
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"iam/pkg/database"
+)
+
+// DepartmentRelation 部门的上级部门, 每个部门最多一个上级部门
+type DepartmentRelation struct {
+	PK           int64 `db:"pk"`
+	DepartmentPK int64 `db:"department_pk"`
+	ParentPK     int64 `db:"parent_pk"`
+}
+
+// DepartmentRelationManager ...
+type DepartmentRelationManager interface {
+	ListByDepartmentPKs(departmentPKs []int64) ([]DepartmentRelation, error)
+	ListByParentPKs(parentPKs []int64) ([]DepartmentRelation, error)
+
+	BulkCreateWithTx(tx *sqlx.Tx, relations []DepartmentRelation) error
+	BulkUpdateParentWithTx(tx *sqlx.Tx, relations []DepartmentRelation) error
+	BulkDeleteWithTx(tx *sqlx.Tx, departmentPKs []int64) error
+}
+
+type departmentRelationManager struct {
+	DB *sqlx.DB
+}
+
+// NewDepartmentRelationManager ...
+func NewDepartmentRelationManager() DepartmentRelationManager {
+	return &departmentRelationManager{
+		DB: database.GetDefaultDBClient().DB,
+	}
+}
+
+// ListByDepartmentPKs 查询部门的上级部门
+func (m *departmentRelationManager) ListByDepartmentPKs(
+	departmentPKs []int64,
+) (relations []DepartmentRelation, err error) {
+	if len(departmentPKs) == 0 {
+		return
+	}
+	err = m.selectByDepartmentPKs(&relations, departmentPKs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return relations, nil
+	}
+	return
+}
+
+// ListByParentPKs 查询部门的下级部门
+func (m *departmentRelationManager) ListByParentPKs(parentPKs []int64) (relations []DepartmentRelation, err error) {
+	if len(parentPKs) == 0 {
+		return
+	}
+	err = m.selectByParentPKs(&relations, parentPKs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return relations, nil
+	}
+	return
+}
+
+// BulkCreateWithTx ...
+func (m *departmentRelationManager) BulkCreateWithTx(tx *sqlx.Tx, relations []DepartmentRelation) error {
+	if len(relations) == 0 {
+		return nil
+	}
+	return m.bulkInsertWithTx(tx, relations)
+}
+
+// BulkUpdateParentWithTx 按department_pk更新上级部门
+func (m *departmentRelationManager) BulkUpdateParentWithTx(tx *sqlx.Tx, relations []DepartmentRelation) error {
+	if len(relations) == 0 {
+		return nil
+	}
+	return m.bulkUpdateParentWithTx(tx, relations)
+}
+
+// BulkDeleteWithTx 删除部门自身的上级关系, 以及其下级部门的上级关系
+func (m *departmentRelationManager) BulkDeleteWithTx(tx *sqlx.Tx, departmentPKs []int64) error {
+	if len(departmentPKs) == 0 {
+		return nil
+	}
+	return m.bulkDeleteWithTx(tx, departmentPKs)
+}
+
+func (m *departmentRelationManager) selectByDepartmentPKs(
+	relations *[]DepartmentRelation, departmentPKs []int64,
+) error {
+	query := `SELECT
+		pk,
+		department_pk,
+		parent_pk
+		FROM department_relation
+		WHERE department_pk IN (?)`
+	return database.SqlxSelect(m.DB, relations, query, departmentPKs)
+}
+
+func (m *departmentRelationManager) selectByParentPKs(relations *[]DepartmentRelation, parentPKs []int64) error {
+	query := `SELECT
+		pk,
+		department_pk,
+		parent_pk
+		FROM department_relation
+		WHERE parent_pk IN (?)`
+	return database.SqlxSelect(m.DB, relations, query, parentPKs)
+}
+
+func (m *departmentRelationManager) bulkInsertWithTx(tx *sqlx.Tx, relations []DepartmentRelation) error {
+	sql := `INSERT INTO department_relation (
+		department_pk,
+		parent_pk
+	) VALUES (
+		:department_pk,
+		:parent_pk)`
+	return database.SqlxBulkInsertWithTx(tx, sql, relations)
+}
+
+func (m *departmentRelationManager) bulkUpdateParentWithTx(tx *sqlx.Tx, relations []DepartmentRelation) error {
+	sql := `UPDATE department_relation
+		SET parent_pk=:parent_pk
+		WHERE department_pk=:department_pk`
+	return database.SqlxBulkUpdateWithTx(tx, sql, relations)
+}
+
+func (m *departmentRelationManager) bulkDeleteWithTx(tx *sqlx.Tx, departmentPKs []int64) error {
+	sql := `DELETE FROM department_relation WHERE department_pk IN (?) OR parent_pk IN (?)`
+	return database.SqlxDeleteWithTx(tx, sql, departmentPKs, departmentPKs)
+}
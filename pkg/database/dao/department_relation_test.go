@@ -0,0 +1,116 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package dao
+
+import (
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+)
+
+func Test_departmentRelationManager_ListByDepartmentPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT (.*) FROM department_relation WHERE department_pk IN`
+		mockRows := sqlmock.NewRows([]string{"pk", "department_pk", "parent_pk"}).
+			AddRow(int64(1), int64(10), int64(20)).
+			AddRow(int64(2), int64(11), int64(20))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(10), int64(11)).WillReturnRows(mockRows)
+
+		manager := &departmentRelationManager{DB: db}
+		relations, err := manager.ListByDepartmentPKs([]int64{10, 11})
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []DepartmentRelation{
+			{PK: 1, DepartmentPK: 10, ParentPK: 20},
+			{PK: 2, DepartmentPK: 11, ParentPK: 20},
+		}, relations)
+	})
+}
+
+func Test_departmentRelationManager_ListByParentPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT (.*) FROM department_relation WHERE parent_pk IN`
+		mockRows := sqlmock.NewRows([]string{"pk", "department_pk", "parent_pk"}).
+			AddRow(int64(1), int64(10), int64(20)).
+			AddRow(int64(2), int64(11), int64(20))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(20)).WillReturnRows(mockRows)
+
+		manager := &departmentRelationManager{DB: db}
+		relations, err := manager.ListByParentPKs([]int64{20})
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []DepartmentRelation{
+			{PK: 1, DepartmentPK: 10, ParentPK: 20},
+			{PK: 2, DepartmentPK: 11, ParentPK: 20},
+		}, relations)
+	})
+}
+
+func Test_departmentRelationManager_BulkCreateWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`^INSERT INTO department_relation`).WithArgs(
+			int64(10), int64(20),
+		).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &departmentRelationManager{DB: db}
+		err = manager.BulkCreateWithTx(tx, []DepartmentRelation{{DepartmentPK: 10, ParentPK: 20}})
+
+		tx.Commit()
+		assert.NoError(t, err)
+	})
+}
+
+func Test_departmentRelationManager_BulkUpdateParentWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectPrepare(`UPDATE department_relation SET parent_pk=(.*) WHERE department_pk=(.*)`)
+		mock.ExpectExec(`UPDATE department_relation SET parent_pk=`).WithArgs(
+			int64(21), int64(10),
+		).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &departmentRelationManager{DB: db}
+		err = manager.BulkUpdateParentWithTx(tx, []DepartmentRelation{{DepartmentPK: 10, ParentPK: 21}})
+
+		tx.Commit()
+		assert.NoError(t, err)
+	})
+}
+
+func Test_departmentRelationManager_BulkDeleteWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM department_relation WHERE department_pk IN (.*) OR parent_pk IN`).WithArgs(
+			int64(10), int64(11), int64(10), int64(11),
+		).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &departmentRelationManager{DB: db}
+		err = manager.BulkDeleteWithTx(tx, []int64{10, 11})
+
+		tx.Commit()
+		assert.NoError(t, err)
+	})
+}
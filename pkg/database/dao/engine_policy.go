@@ -32,6 +32,7 @@ type EnginePolicy struct {
 type EnginePolicyManager interface {
 	ListBetweenPK(expiredAt, minPK, maxPK int64) (policies []EnginePolicy, err error)
 	ListByPKs(pks []int64) (policies []EnginePolicy, err error)
+	ListAfterPKByActionPKs(expiredAt, afterPK int64, actionPKs []int64, limit int64) (policies []EnginePolicy, err error)
 	GetMaxPKBeforeUpdatedAt(updatedAt int64) (pk int64, err error)
 	ListPKBetweenUpdatedAt(beginUpdatedAt, endUpdatedAt int64) (pks []int64, err error)
 }
@@ -43,7 +44,8 @@ type enginePolicyManager struct {
 // NewEnginePolicyManager create EnginePolicyManager
 func NewEnginePolicyManager() EnginePolicyManager {
 	return &enginePolicyManager{
-		DB: database.GetDefaultDBClient().DB,
+		// engine全量同步会扫描整个策略表, 使用批量任务的连接池
+		DB: database.GetBatchDBClient().DB,
 	}
 }
 
@@ -69,6 +71,23 @@ func (m *enginePolicyManager) ListByPKs(pks []int64) (policies []EnginePolicy, e
 	return
 }
 
+// ListAfterPKByActionPKs 按pk顺序查询指定操作在afterPK之后的策略, 用于按系统分页导出
+func (m *enginePolicyManager) ListAfterPKByActionPKs(
+	expiredAt, afterPK int64,
+	actionPKs []int64,
+	limit int64,
+) (policies []EnginePolicy, err error) {
+	if len(actionPKs) == 0 {
+		return
+	}
+
+	err = m.selectAfterPKByActionPKs(&policies, expiredAt, afterPK, actionPKs, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return policies, nil
+	}
+	return
+}
+
 // GetMaxPKBeforeUpdatedAt 查询更新时间之前的最大pk
 func (m *enginePolicyManager) GetMaxPKBeforeUpdatedAt(updatedAt int64) (pk int64, err error) {
 	var maxPK sql.NullInt64
@@ -109,6 +128,8 @@ func (m *enginePolicyManager) selectBetweenPK(
 		subject_pk,
 		action_pk,
 		expression_pk,
+		effect,
+		priority,
 		expired_at,
 		template_id,
 		updated_at
@@ -124,6 +145,8 @@ func (m *enginePolicyManager) selectByPKs(policies *[]EnginePolicy, pks []int64)
 		subject_pk,
 		action_pk,
 		expression_pk,
+		effect,
+		priority,
 		expired_at,
 		template_id,
 		updated_at
@@ -132,6 +155,32 @@ func (m *enginePolicyManager) selectByPKs(policies *[]EnginePolicy, pks []int64)
 	return database.SqlxSelect(m.DB, policies, query, pks)
 }
 
+func (m *enginePolicyManager) selectAfterPKByActionPKs(
+	policies *[]EnginePolicy,
+	expiredAt int64,
+	afterPK int64,
+	actionPKs []int64,
+	limit int64,
+) error {
+	query := `SELECT
+		pk,
+		subject_pk,
+		action_pk,
+		expression_pk,
+		effect,
+		priority,
+		expired_at,
+		template_id,
+		updated_at
+		FROM policy
+		WHERE expired_at > ?
+		AND action_pk IN (?)
+		AND pk > ?
+		ORDER BY pk
+		LIMIT ?`
+	return database.SqlxSelect(m.DB, policies, query, expiredAt, actionPKs, afterPK, limit)
+}
+
 func (m *enginePolicyManager) selectPKBetweenUpdatedAt(pks *[]int64, beginUpdatedAt, endUpdatedAt int64) error {
 	query := `SELECT pk FROM policy WHERE updated_at BETWEEN FROM_UNIXTIME(?) AND FROM_UNIXTIME(?)`
 	return database.SqlxSelect(m.DB, pks, query, beginUpdatedAt, endUpdatedAt)
@@ -61,14 +61,17 @@ func Test_enginePolicyManager_ListBetweenPK(t *testing.T) {
 		now := time.Unix(1617457847, 0)
 
 		mockRows := sqlmock.NewRows([]string{
-			"pk", "subject_pk", "action_pk", "expression_pk", "expired_at", "template_id", "updated_at",
-		}).AddRow(int64(1), int64(1), int64(1), int64(1), int64(1), int64(1), now)
+			"pk", "subject_pk", "action_pk", "expression_pk", "effect", "priority", "expired_at", "template_id",
+			"updated_at",
+		}).AddRow(int64(1), int64(1), int64(1), int64(1), "allow", int64(10), int64(1), int64(1), now)
 		mock.ExpectQuery(
 			`SELECT
 			pk,
 			subject_pk,
 			action_pk,
 			expression_pk,
+			effect,
+			priority,
 			expired_at,
 			template_id,
 			updated_at
@@ -89,6 +92,8 @@ func Test_enginePolicyManager_ListBetweenPK(t *testing.T) {
 				SubjectPK:    int64(1),
 				ActionPK:     int64(1),
 				ExpressionPK: int64(1),
+				Effect:       "allow",
+				Priority:     int64(10),
 
 				ExpiredAt:  int64(1),
 				TemplateID: int64(1),
@@ -105,14 +110,17 @@ func Test_enginePolicyManager_ListByPKs(t *testing.T) {
 		now := time.Unix(1617457847, 0)
 
 		mockRows := sqlmock.NewRows([]string{
-			"pk", "subject_pk", "action_pk", "expression_pk", "expired_at", "template_id", "updated_at",
-		}).AddRow(int64(1), int64(1), int64(1), int64(1), int64(1), int64(1), now)
+			"pk", "subject_pk", "action_pk", "expression_pk", "effect", "priority", "expired_at", "template_id",
+			"updated_at",
+		}).AddRow(int64(1), int64(1), int64(1), int64(1), "allow", int64(10), int64(1), int64(1), now)
 		mock.ExpectQuery(
 			`SELECT
 			pk,
 			subject_pk,
 			action_pk,
 			expression_pk,
+			effect,
+			priority,
 			expired_at,
 			template_id,
 			updated_at
@@ -130,6 +138,8 @@ func Test_enginePolicyManager_ListByPKs(t *testing.T) {
 				SubjectPK:    int64(1),
 				ActionPK:     int64(1),
 				ExpressionPK: int64(1),
+				Effect:       "allow",
+				Priority:     int64(10),
 
 				ExpiredAt:  int64(1),
 				TemplateID: int64(1),
@@ -140,3 +150,49 @@ func Test_enginePolicyManager_ListByPKs(t *testing.T) {
 		assert.Equal(t, expected, policies[0])
 	})
 }
+
+func Test_enginePolicyManager_ListAfterPKByActionPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Unix(1617457847, 0)
+
+		mockRows := sqlmock.NewRows([]string{
+			"pk", "subject_pk", "action_pk", "expression_pk", "effect", "priority", "expired_at", "template_id",
+			"updated_at",
+		}).AddRow(int64(2), int64(1), int64(1), int64(1), "allow", int64(0), int64(1), int64(0), now)
+		mock.ExpectQuery(
+			`SELECT
+			pk,
+			subject_pk,
+			action_pk,
+			expression_pk,
+			effect,
+			priority,
+			expired_at,
+			template_id,
+			updated_at
+			FROM policy
+			WHERE expired_at > .*
+			AND action_pk IN .*
+			AND pk > .*
+			ORDER BY pk
+			LIMIT .*`,
+		).WithArgs(int64(1), int64(1), int64(2), int64(1), int64(10)).WillReturnRows(mockRows)
+
+		manager := &enginePolicyManager{DB: db}
+		policies, err := manager.ListAfterPKByActionPKs(int64(1), int64(1), []int64{1, 2}, int64(10))
+
+		assert.NoError(t, err)
+		assert.Len(t, policies, 1)
+		assert.Equal(t, int64(2), policies[0].PK)
+	})
+}
+
+func Test_enginePolicyManager_ListAfterPKByActionPKs_Empty(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		manager := &enginePolicyManager{DB: db}
+		policies, err := manager.ListAfterPKByActionPKs(int64(1), int64(1), []int64{}, int64(10))
+
+		assert.NoError(t, err)
+		assert.Empty(t, policies)
+	})
+}
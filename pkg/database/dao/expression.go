@@ -34,6 +34,8 @@ type Expression struct {
 	Type       int64  `db:"type"`
 	Expression string `db:"expression"`
 	Signature  string `db:"signature"`
+	// 被policy引用的次数, 与policy的写操作在同一个事务中维护
+	RefCount int64 `db:"ref_count"`
 }
 
 // ExpressionManager ...
@@ -42,6 +44,10 @@ type ExpressionManager interface {
 
 	ListAuthByPKs(pks []int64) ([]AuthExpression, error)
 
+	// for census
+
+	ListAuthAfterPK(afterPK, limit int64) ([]AuthExpression, error)
+
 	// for saas
 
 	ListDistinctBySignaturesType(signatures []string, _type int64) ([]Expression, error)
@@ -49,6 +55,13 @@ type ExpressionManager interface {
 	BulkCreateWithTx(tx *sqlx.Tx, expressions []Expression) (int64, error) // 返回批量创建的last id
 	BulkUpdateWithTx(tx *sqlx.Tx, expressions []Expression) error
 	BulkDeleteByPKsWithTx(tx *sqlx.Tx, pks []int64) (int64, error)
+
+	// for reference count
+
+	UpdateRefCountByPKsWithTx(tx *sqlx.Tx, pks []int64, delta int64) (int64, error)
+	DecrRefCountBySubjectPKsWithTx(tx *sqlx.Tx, subjectPKs []int64) error
+	BulkDeleteUnreferencedByPKsWithTx(tx *sqlx.Tx, pks []int64) (int64, error)
+	DeleteUnreferencedBySubjectPKsWithTx(tx *sqlx.Tx, subjectPKs []int64) (int64, error)
 }
 
 type expressionManager struct {
@@ -62,6 +75,13 @@ func NewExpressionManager() ExpressionManager {
 	}
 }
 
+// NewBatchExpressionManager 批量任务(清理等)使用的ExpressionManager, 使用批量任务的DB连接池
+func NewBatchExpressionManager() ExpressionManager {
+	return &expressionManager{
+		DB: database.GetBatchDBClient().DB,
+	}
+}
+
 // ListAuthByPKs ...
 func (m *expressionManager) ListAuthByPKs(pks []int64) (expressions []AuthExpression, err error) {
 	if len(pks) == 0 {
@@ -74,6 +94,15 @@ func (m *expressionManager) ListAuthByPKs(pks []int64) (expressions []AuthExpres
 	return
 }
 
+// ListAuthAfterPK 查询pk大于afterPK的expression, 按pk升序
+func (m *expressionManager) ListAuthAfterPK(afterPK, limit int64) (expressions []AuthExpression, err error) {
+	err = m.selectAuthAfterPK(&expressions, afterPK, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return expressions, nil
+	}
+	return
+}
+
 // ListDistinctBySignaturesType List distinct expressions by signatures and type
 func (m *expressionManager) ListDistinctBySignaturesType(
 	signatures []string, _type int64,
@@ -112,6 +141,38 @@ func (m *expressionManager) BulkDeleteByPKsWithTx(tx *sqlx.Tx, pks []int64) (int
 	return m.bulkDeleteByPKsWithTx(tx, pks)
 }
 
+// UpdateRefCountByPKsWithTx add delta to the ref_count of expressions, return the rows affected
+func (m *expressionManager) UpdateRefCountByPKsWithTx(tx *sqlx.Tx, pks []int64, delta int64) (int64, error) {
+	if len(pks) == 0 || delta == 0 {
+		return 0, nil
+	}
+	return m.updateRefCountByPKsWithTx(tx, pks, delta)
+}
+
+// DecrRefCountBySubjectPKsWithTx 在删除subject的所有policy之前, 减去这些policy对expression的引用
+func (m *expressionManager) DecrRefCountBySubjectPKsWithTx(tx *sqlx.Tx, subjectPKs []int64) error {
+	if len(subjectPKs) == 0 {
+		return nil
+	}
+	return m.decrRefCountBySubjectPKsWithTx(tx, subjectPKs)
+}
+
+// BulkDeleteUnreferencedByPKsWithTx delete the expressions which are no longer referenced by any policy
+func (m *expressionManager) BulkDeleteUnreferencedByPKsWithTx(tx *sqlx.Tx, pks []int64) (int64, error) {
+	if len(pks) == 0 {
+		return 0, nil
+	}
+	return m.bulkDeleteUnreferencedByPKsWithTx(tx, pks)
+}
+
+// DeleteUnreferencedBySubjectPKsWithTx ...
+func (m *expressionManager) DeleteUnreferencedBySubjectPKsWithTx(tx *sqlx.Tx, subjectPKs []int64) (int64, error) {
+	if len(subjectPKs) == 0 {
+		return 0, nil
+	}
+	return m.deleteUnreferencedBySubjectPKsWithTx(tx, subjectPKs)
+}
+
 func (m *expressionManager) selectAuthByPKs(expressions *[]AuthExpression, pks []int64) error {
 	query := `SELECT
 		pk,
@@ -122,6 +183,18 @@ func (m *expressionManager) selectAuthByPKs(expressions *[]AuthExpression, pks [
 	return database.SqlxSelect(m.DB, expressions, query, pks)
 }
 
+func (m *expressionManager) selectAuthAfterPK(expressions *[]AuthExpression, afterPK, limit int64) error {
+	query := `SELECT
+		pk,
+		expression,
+		signature
+		FROM expression
+		WHERE pk > ?
+		ORDER BY pk ASC
+		LIMIT ?`
+	return database.SqlxSelect(m.DB, expressions, query, afterPK, limit)
+}
+
 func (m *expressionManager) selectBySignaturesType(expressions *[]Expression, signatures []string, _type int64) error {
 	query := `SELECT
 		pk,
@@ -144,11 +217,13 @@ func (m *expressionManager) bulkInsertWithTx(tx *sqlx.Tx, expressions []Expressi
 	sql := `INSERT INTO expression (
 		type,
 		expression,
-		signature
+		signature,
+		ref_count
 	) VALUES (
 		:type,
 		:expression,
-		:signature)`
+		:signature,
+		:ref_count)`
 	return database.SqlxBulkInsertReturnIDWithTx(tx, sql, expressions)
 }
 
@@ -164,3 +239,35 @@ func (m *expressionManager) bulkDeleteByPKsWithTx(tx *sqlx.Tx, pks []int64) (int
 	sql := `DELETE FROM expression WHERE pk IN (?)`
 	return database.SqlxDeleteReturnRowsWithTx(tx, sql, pks)
 }
+
+func (m *expressionManager) updateRefCountByPKsWithTx(tx *sqlx.Tx, pks []int64, delta int64) (int64, error) {
+	sql := `UPDATE expression SET ref_count = ref_count + ? WHERE pk IN (?)`
+	return database.SqlxExecReturnRowsWithTx(tx, sql, delta, pks)
+}
+
+func (m *expressionManager) decrRefCountBySubjectPKsWithTx(tx *sqlx.Tx, subjectPKs []int64) error {
+	sql := `UPDATE expression e
+		INNER JOIN (
+			SELECT
+			expression_pk,
+			COUNT(*) AS cnt
+			FROM policy
+			WHERE subject_pk IN (?)
+			GROUP BY expression_pk
+		) p ON e.pk = p.expression_pk
+		SET e.ref_count = e.ref_count - p.cnt`
+	_, err := database.SqlxExecReturnRowsWithTx(tx, sql, subjectPKs)
+	return err
+}
+
+func (m *expressionManager) bulkDeleteUnreferencedByPKsWithTx(tx *sqlx.Tx, pks []int64) (int64, error) {
+	sql := `DELETE FROM expression WHERE pk IN (?) AND ref_count <= 0`
+	return database.SqlxDeleteReturnRowsWithTx(tx, sql, pks)
+}
+
+func (m *expressionManager) deleteUnreferencedBySubjectPKsWithTx(tx *sqlx.Tx, subjectPKs []int64) (int64, error) {
+	sql := `DELETE FROM expression
+		WHERE ref_count <= 0
+		AND pk IN (SELECT expression_pk FROM policy WHERE subject_pk IN (?))`
+	return database.SqlxDeleteReturnRowsWithTx(tx, sql, subjectPKs)
+}
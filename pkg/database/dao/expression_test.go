@@ -48,11 +48,32 @@ func Test_expressionManager_ListAuthByPKs(t *testing.T) {
 	})
 }
 
+func Test_expressionManager_ListAuthAfterPK(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockData := []interface{}{
+			AuthExpression{
+				PK:         11,
+				Expression: "test",
+				Signature:  "test",
+			},
+		}
+		mockQuery := `^SELECT pk, expression, signature FROM expression WHERE pk > (.*) ORDER BY pk ASC LIMIT`
+		mockRows := database.NewMockRows(mock, mockData...)
+		mock.ExpectQuery(mockQuery).WithArgs(int64(10), int64(2)).WillReturnRows(mockRows)
+
+		manager := &expressionManager{DB: db}
+		expressions, err := manager.ListAuthAfterPK(int64(10), int64(2))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []AuthExpression{mockData[0].(AuthExpression)}, expressions)
+	})
+}
+
 func Test_expressionManager_BulkCreateWithTx(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectExec(`INSERT INTO expression`).WithArgs(
-			int64(1), "expression", "test",
+			int64(1), "expression", "test", int64(1),
 		).WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
@@ -63,6 +84,7 @@ func Test_expressionManager_BulkCreateWithTx(t *testing.T) {
 			Type:       1,
 			Expression: "expression",
 			Signature:  "test",
+			RefCount:   1,
 		}
 
 		manager := &expressionManager{DB: db}
@@ -153,3 +175,43 @@ func Test_expressionManager_ListDistinctBySignaturesType(t *testing.T) {
 		assert.Equal(t, mockData[1].(Expression), expressions[1])
 	})
 }
+
+func Test_expressionManager_UpdateRefCountByPKsWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE expression SET ref_count = ref_count \+ (.*) WHERE pk IN`).WithArgs(
+			int64(-1), int64(1), int64(2),
+		).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &expressionManager{DB: db}
+		rows, err := manager.UpdateRefCountByPKsWithTx(tx, []int64{1, 2}, -1)
+		tx.Commit()
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), rows)
+	})
+}
+
+func Test_expressionManager_BulkDeleteUnreferencedByPKsWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM expression WHERE pk IN (.*) AND ref_count <= 0`).WithArgs(
+			int64(1), int64(2),
+		).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &expressionManager{DB: db}
+		rows, err := manager.BulkDeleteUnreferencedByPKsWithTx(tx, []int64{1, 2})
+		tx.Commit()
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), rows)
+	})
+}
@@ -0,0 +1,130 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package dao
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"iam/pkg/database"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+// GroupSetting 用户组的成员配置, 值为0表示不限制
+type GroupSetting struct {
+	PK                    int64 `db:"pk"`
+	GroupPK               int64 `db:"group_pk"`
+	MaxMembers            int64 `db:"max_members"`
+	DefaultExpirationDays int64 `db:"default_expiration_days"`
+	MaxExpirationDays     int64 `db:"max_expiration_days"`
+	RenewalWindowDays     int64 `db:"renewal_window_days"`
+}
+
+// GroupSettingManager ...
+type GroupSettingManager interface {
+	Get(groupPK int64) (GroupSetting, error)
+	ListByGroupPKs(groupPKs []int64) ([]GroupSetting, error)
+	Create(setting GroupSetting) error
+	Update(setting GroupSetting) error
+	Delete(groupPK int64) (int64, error)
+	BulkDeleteWithTx(tx *sqlx.Tx, groupPKs []int64) error
+}
+
+type groupSettingManager struct {
+	DB *sqlx.DB
+}
+
+// NewGroupSettingManager ...
+func NewGroupSettingManager() GroupSettingManager {
+	return &groupSettingManager{
+		DB: database.GetDefaultDBClient().DB,
+	}
+}
+
+// Get ...
+func (m *groupSettingManager) Get(groupPK int64) (setting GroupSetting, err error) {
+	query := `SELECT
+		pk,
+		group_pk,
+		max_members,
+		default_expiration_days,
+		max_expiration_days,
+		renewal_window_days
+		FROM group_setting
+		WHERE group_pk = ?
+		LIMIT 1`
+	err = database.SqlxGet(m.DB, &setting, query, groupPK)
+	return
+}
+
+// ListByGroupPKs ...
+func (m *groupSettingManager) ListByGroupPKs(groupPKs []int64) (settings []GroupSetting, err error) {
+	if len(groupPKs) == 0 {
+		return
+	}
+
+	query := `SELECT
+		pk,
+		group_pk,
+		max_members,
+		default_expiration_days,
+		max_expiration_days,
+		renewal_window_days
+		FROM group_setting
+		WHERE group_pk IN (?)`
+	err = database.SqlxSelect(m.DB, &settings, query, groupPKs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return settings, nil
+	}
+	return
+}
+
+// Create ...
+func (m *groupSettingManager) Create(setting GroupSetting) error {
+	query := `INSERT INTO group_setting (
+		group_pk,
+		max_members,
+		default_expiration_days,
+		max_expiration_days,
+		renewal_window_days
+	) VALUES (:group_pk, :max_members, :default_expiration_days, :max_expiration_days, :renewal_window_days)`
+	return database.SqlxBulkInsert(m.DB, query, []GroupSetting{setting})
+}
+
+// Update ...
+func (m *groupSettingManager) Update(setting GroupSetting) error {
+	query := `UPDATE group_setting
+		SET max_members = :max_members,
+		default_expiration_days = :default_expiration_days,
+		max_expiration_days = :max_expiration_days,
+		renewal_window_days = :renewal_window_days
+		WHERE group_pk = :group_pk`
+	_, err := database.SqlxUpdate(m.DB, query, setting)
+	return err
+}
+
+// Delete ...
+func (m *groupSettingManager) Delete(groupPK int64) (int64, error) {
+	query := `DELETE FROM group_setting WHERE group_pk = ?`
+	return database.SqlxDelete(m.DB, query, groupPK)
+}
+
+// BulkDeleteWithTx ...
+func (m *groupSettingManager) BulkDeleteWithTx(tx *sqlx.Tx, groupPKs []int64) error {
+	if len(groupPKs) == 0 {
+		return nil
+	}
+
+	query := `DELETE FROM group_setting WHERE group_pk IN (?)`
+	return database.SqlxDeleteWithTx(tx, query, groupPKs)
+}
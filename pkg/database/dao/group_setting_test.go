@@ -0,0 +1,130 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package dao
+
+import (
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+)
+
+func Test_groupSettingManager_Get(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT pk, group_pk, max_members, default_expiration_days, max_expiration_days, ` +
+			`renewal_window_days FROM group_setting WHERE group_pk = (.*) LIMIT 1`
+		mockRows := sqlmock.NewRows([]string{
+			"pk", "group_pk", "max_members", "default_expiration_days", "max_expiration_days", "renewal_window_days",
+		}).AddRow(int64(1), int64(10), int64(100), int64(30), int64(180), int64(7))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(10)).WillReturnRows(mockRows)
+
+		manager := &groupSettingManager{DB: db}
+		setting, err := manager.Get(10)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, GroupSetting{
+			PK:                    1,
+			GroupPK:               10,
+			MaxMembers:            100,
+			DefaultExpirationDays: 30,
+			MaxExpirationDays:     180,
+			RenewalWindowDays:     7,
+		}, setting)
+	})
+}
+
+func Test_groupSettingManager_ListByGroupPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT (.*) FROM group_setting WHERE group_pk IN`
+		mockRows := sqlmock.NewRows([]string{
+			"pk", "group_pk", "max_members", "default_expiration_days", "max_expiration_days", "renewal_window_days",
+		}).AddRow(int64(1), int64(10), int64(100), int64(0), int64(0), int64(0))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(10), int64(11)).WillReturnRows(mockRows)
+
+		manager := &groupSettingManager{DB: db}
+		settings, err := manager.ListByGroupPKs([]int64{10, 11})
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Len(t, settings, 1)
+	})
+}
+
+func Test_groupSettingManager_Create(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectExec(`^INSERT INTO group_setting`).
+			WithArgs(int64(10), int64(100), int64(30), int64(180), int64(7)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		manager := &groupSettingManager{DB: db}
+		err := manager.Create(GroupSetting{
+			GroupPK:               10,
+			MaxMembers:            100,
+			DefaultExpirationDays: 30,
+			MaxExpirationDays:     180,
+			RenewalWindowDays:     7,
+		})
+
+		assert.NoError(t, err)
+	})
+}
+
+func Test_groupSettingManager_Update(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectExec(`^UPDATE group_setting SET`).
+			WithArgs(int64(200), int64(30), int64(180), int64(0), int64(10)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		manager := &groupSettingManager{DB: db}
+		err := manager.Update(GroupSetting{
+			GroupPK:               10,
+			MaxMembers:            200,
+			DefaultExpirationDays: 30,
+			MaxExpirationDays:     180,
+		})
+
+		assert.NoError(t, err)
+	})
+}
+
+func Test_groupSettingManager_Delete(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectExec(`^DELETE FROM group_setting WHERE group_pk = (.*)`).
+			WithArgs(int64(10)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		manager := &groupSettingManager{DB: db}
+		cnt, err := manager.Delete(10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), cnt)
+	})
+}
+
+func Test_groupSettingManager_BulkDeleteWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`^DELETE FROM group_setting WHERE group_pk IN`).
+			WithArgs(int64(10), int64(11)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &groupSettingManager{DB: db}
+		err = manager.BulkDeleteWithTx(tx, []int64{10, 11})
+
+		tx.Commit()
+		assert.NoError(t, err)
+	})
+}
@@ -63,6 +63,21 @@ func (mr *MockEnginePolicyManagerMockRecorder) ListByPKs(pks interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPKs", reflect.TypeOf((*MockEnginePolicyManager)(nil).ListByPKs), pks)
 }
 
+// ListAfterPKByActionPKs mocks base method
+func (m *MockEnginePolicyManager) ListAfterPKByActionPKs(expiredAt, afterPK int64, actionPKs []int64, limit int64) ([]dao.EnginePolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAfterPKByActionPKs", expiredAt, afterPK, actionPKs, limit)
+	ret0, _ := ret[0].([]dao.EnginePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAfterPKByActionPKs indicates an expected call of ListAfterPKByActionPKs
+func (mr *MockEnginePolicyManagerMockRecorder) ListAfterPKByActionPKs(expiredAt, afterPK, actionPKs, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAfterPKByActionPKs", reflect.TypeOf((*MockEnginePolicyManager)(nil).ListAfterPKByActionPKs), expiredAt, afterPK, actionPKs, limit)
+}
+
 // GetMaxPKBeforeUpdatedAt mocks base method
 func (m *MockEnginePolicyManager) GetMaxPKBeforeUpdatedAt(updatedAt int64) (int64, error) {
 	m.ctrl.T.Helper()
@@ -49,6 +49,21 @@ func (mr *MockExpressionManagerMockRecorder) ListAuthByPKs(pks interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuthByPKs", reflect.TypeOf((*MockExpressionManager)(nil).ListAuthByPKs), pks)
 }
 
+// ListAuthAfterPK mocks base method
+func (m *MockExpressionManager) ListAuthAfterPK(afterPK, limit int64) ([]dao.AuthExpression, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuthAfterPK", afterPK, limit)
+	ret0, _ := ret[0].([]dao.AuthExpression)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuthAfterPK indicates an expected call of ListAuthAfterPK
+func (mr *MockExpressionManagerMockRecorder) ListAuthAfterPK(afterPK, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuthAfterPK", reflect.TypeOf((*MockExpressionManager)(nil).ListAuthAfterPK), afterPK, limit)
+}
+
 // ListDistinctBySignaturesType mocks base method
 func (m *MockExpressionManager) ListDistinctBySignaturesType(signatures []string, _type int64) ([]dao.Expression, error) {
 	m.ctrl.T.Helper()
@@ -107,3 +122,62 @@ func (mr *MockExpressionManagerMockRecorder) BulkDeleteByPKsWithTx(tx, pks inter
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDeleteByPKsWithTx", reflect.TypeOf((*MockExpressionManager)(nil).BulkDeleteByPKsWithTx), tx, pks)
 }
+
+// UpdateRefCountByPKsWithTx mocks base method
+func (m *MockExpressionManager) UpdateRefCountByPKsWithTx(tx *sqlx.Tx, pks []int64, delta int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRefCountByPKsWithTx", tx, pks, delta)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRefCountByPKsWithTx indicates an expected call of UpdateRefCountByPKsWithTx
+func (mr *MockExpressionManagerMockRecorder) UpdateRefCountByPKsWithTx(tx, pks, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRefCountByPKsWithTx", reflect.TypeOf((*MockExpressionManager)(nil).UpdateRefCountByPKsWithTx), tx, pks, delta)
+}
+
+// DecrRefCountBySubjectPKsWithTx mocks base method
+func (m *MockExpressionManager) DecrRefCountBySubjectPKsWithTx(tx *sqlx.Tx, subjectPKs []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecrRefCountBySubjectPKsWithTx", tx, subjectPKs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecrRefCountBySubjectPKsWithTx indicates an expected call of DecrRefCountBySubjectPKsWithTx
+func (mr *MockExpressionManagerMockRecorder) DecrRefCountBySubjectPKsWithTx(tx, subjectPKs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrRefCountBySubjectPKsWithTx", reflect.TypeOf((*MockExpressionManager)(nil).DecrRefCountBySubjectPKsWithTx), tx, subjectPKs)
+}
+
+// BulkDeleteUnreferencedByPKsWithTx mocks base method
+func (m *MockExpressionManager) BulkDeleteUnreferencedByPKsWithTx(tx *sqlx.Tx, pks []int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkDeleteUnreferencedByPKsWithTx", tx, pks)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkDeleteUnreferencedByPKsWithTx indicates an expected call of BulkDeleteUnreferencedByPKsWithTx
+func (mr *MockExpressionManagerMockRecorder) BulkDeleteUnreferencedByPKsWithTx(tx, pks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDeleteUnreferencedByPKsWithTx", reflect.TypeOf((*MockExpressionManager)(nil).BulkDeleteUnreferencedByPKsWithTx), tx, pks)
+}
+
+// DeleteUnreferencedBySubjectPKsWithTx mocks base method
+func (m *MockExpressionManager) DeleteUnreferencedBySubjectPKsWithTx(tx *sqlx.Tx, subjectPKs []int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUnreferencedBySubjectPKsWithTx", tx, subjectPKs)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUnreferencedBySubjectPKsWithTx indicates an expected call of DeleteUnreferencedBySubjectPKsWithTx
+func (mr *MockExpressionManagerMockRecorder) DeleteUnreferencedBySubjectPKsWithTx(tx, subjectPKs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUnreferencedBySubjectPKsWithTx", reflect.TypeOf((*MockExpressionManager)(nil).DeleteUnreferencedBySubjectPKsWithTx), tx, subjectPKs)
+}
@@ -33,6 +33,21 @@ func (m *MockModelChangeEventManager) EXPECT() *MockModelChangeEventManagerMockR
 	return m.recorder
 }
 
+// Get mocks base method
+func (m *MockModelChangeEventManager) Get(pk int64) (dao.ModelChangeEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", pk)
+	ret0, _ := ret[0].(dao.ModelChangeEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockModelChangeEventManagerMockRecorder) Get(pk interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockModelChangeEventManager)(nil).Get), pk)
+}
+
 // GetByTypeModel mocks base method
 func (m *MockModelChangeEventManager) GetByTypeModel(eventType, status, modelType string, modelPK int64) (dao.ModelChangeEvent, error) {
 	m.ctrl.T.Helper()
@@ -77,6 +92,20 @@ func (mr *MockModelChangeEventManagerMockRecorder) UpdateStatusByPK(pk, status i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusByPK", reflect.TypeOf((*MockModelChangeEventManager)(nil).UpdateStatusByPK), pk, status)
 }
 
+// UpdateCallbackStatusByPK mocks base method
+func (m *MockModelChangeEventManager) UpdateCallbackStatusByPK(pk int64, callbackStatus, callbackMessage string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCallbackStatusByPK", pk, callbackStatus, callbackMessage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCallbackStatusByPK indicates an expected call of UpdateCallbackStatusByPK
+func (mr *MockModelChangeEventManagerMockRecorder) UpdateCallbackStatusByPK(pk, callbackStatus, callbackMessage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCallbackStatusByPK", reflect.TypeOf((*MockModelChangeEventManager)(nil).UpdateCallbackStatusByPK), pk, callbackStatus, callbackMessage)
+}
+
 // BulkCreate mocks base method
 func (m *MockModelChangeEventManager) BulkCreate(modelChangeEvents []dao.ModelChangeEvent) error {
 	m.ctrl.T.Helper()
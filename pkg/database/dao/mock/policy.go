@@ -124,6 +124,36 @@ func (mr *MockPolicyManagerMockRecorder) ListBySubjectTemplateBeforeExpiredAt(su
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBySubjectTemplateBeforeExpiredAt", reflect.TypeOf((*MockPolicyManager)(nil).ListBySubjectTemplateBeforeExpiredAt), subjectPK, templateID, expiredAt)
 }
 
+// ListBySubjectTemplate mocks base method
+func (m *MockPolicyManager) ListBySubjectTemplate(subjectPK, templateID int64) ([]dao.Policy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBySubjectTemplate", subjectPK, templateID)
+	ret0, _ := ret[0].([]dao.Policy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBySubjectTemplate indicates an expected call of ListBySubjectTemplate
+func (mr *MockPolicyManagerMockRecorder) ListBySubjectTemplate(subjectPK, templateID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBySubjectTemplate", reflect.TypeOf((*MockPolicyManager)(nil).ListBySubjectTemplate), subjectPK, templateID)
+}
+
+// ListBySubjectTemplateWithLimit mocks base method
+func (m *MockPolicyManager) ListBySubjectTemplateWithLimit(subjectPK, templateID, limit int64) ([]dao.Policy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBySubjectTemplateWithLimit", subjectPK, templateID, limit)
+	ret0, _ := ret[0].([]dao.Policy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBySubjectTemplateWithLimit indicates an expected call of ListBySubjectTemplateWithLimit
+func (mr *MockPolicyManagerMockRecorder) ListBySubjectTemplateWithLimit(subjectPK, templateID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBySubjectTemplateWithLimit", reflect.TypeOf((*MockPolicyManager)(nil).ListBySubjectTemplateWithLimit), subjectPK, templateID, limit)
+}
+
 // BulkCreateWithTx mocks base method
 func (m *MockPolicyManager) BulkCreateWithTx(tx *sqlx.Tx, policies []dao.Policy) error {
 	m.ctrl.T.Helper()
@@ -209,19 +239,34 @@ func (mr *MockPolicyManagerMockRecorder) BulkUpdateExpiredAtWithTx(tx, policies
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpdateExpiredAtWithTx", reflect.TypeOf((*MockPolicyManager)(nil).BulkUpdateExpiredAtWithTx), tx, policies)
 }
 
-// DeleteByActionPKWithTx mocks base method
-func (m *MockPolicyManager) DeleteByActionPKWithTx(tx *sqlx.Tx, actionPK, limit int64) (int64, error) {
+// ListByActionPKWithLimit mocks base method
+func (m *MockPolicyManager) ListByActionPKWithLimit(actionPK, limit int64) ([]dao.Policy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByActionPKWithLimit", actionPK, limit)
+	ret0, _ := ret[0].([]dao.Policy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByActionPKWithLimit indicates an expected call of ListByActionPKWithLimit
+func (mr *MockPolicyManagerMockRecorder) ListByActionPKWithLimit(actionPK, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByActionPKWithLimit", reflect.TypeOf((*MockPolicyManager)(nil).ListByActionPKWithLimit), actionPK, limit)
+}
+
+// BulkDeleteByActionPKsWithTx mocks base method
+func (m *MockPolicyManager) BulkDeleteByActionPKsWithTx(tx *sqlx.Tx, actionPK int64, pks []int64) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteByActionPKWithTx", tx, actionPK, limit)
+	ret := m.ctrl.Call(m, "BulkDeleteByActionPKsWithTx", tx, actionPK, pks)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DeleteByActionPKWithTx indicates an expected call of DeleteByActionPKWithTx
-func (mr *MockPolicyManagerMockRecorder) DeleteByActionPKWithTx(tx, actionPK, limit interface{}) *gomock.Call {
+// BulkDeleteByActionPKsWithTx indicates an expected call of BulkDeleteByActionPKsWithTx
+func (mr *MockPolicyManagerMockRecorder) BulkDeleteByActionPKsWithTx(tx, actionPK, pks interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByActionPKWithTx", reflect.TypeOf((*MockPolicyManager)(nil).DeleteByActionPKWithTx), tx, actionPK, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDeleteByActionPKsWithTx", reflect.TypeOf((*MockPolicyManager)(nil).BulkDeleteByActionPKsWithTx), tx, actionPK, pks)
 }
 
 // HasAnyByActionPK mocks base method
@@ -269,6 +314,51 @@ func (mr *MockPolicyManagerMockRecorder) GetCountByActionBeforeExpiredAt(actionP
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCountByActionBeforeExpiredAt", reflect.TypeOf((*MockPolicyManager)(nil).GetCountByActionBeforeExpiredAt), actionPK, expiredAt)
 }
 
+// GetCountBySubjectTemplate mocks base method
+func (m *MockPolicyManager) GetCountBySubjectTemplate(subjectPK, templateID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCountBySubjectTemplate", subjectPK, templateID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCountBySubjectTemplate indicates an expected call of GetCountBySubjectTemplate
+func (mr *MockPolicyManagerMockRecorder) GetCountBySubjectTemplate(subjectPK, templateID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCountBySubjectTemplate", reflect.TypeOf((*MockPolicyManager)(nil).GetCountBySubjectTemplate), subjectPK, templateID)
+}
+
+// GetCountByActionPKs mocks base method
+func (m *MockPolicyManager) GetCountByActionPKs(actionPKs []int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCountByActionPKs", actionPKs)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCountByActionPKs indicates an expected call of GetCountByActionPKs
+func (mr *MockPolicyManagerMockRecorder) GetCountByActionPKs(actionPKs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCountByActionPKs", reflect.TypeOf((*MockPolicyManager)(nil).GetCountByActionPKs), actionPKs)
+}
+
+// GetSubjectCountByActionPKs mocks base method
+func (m *MockPolicyManager) GetSubjectCountByActionPKs(actionPKs []int64, subjectType string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectCountByActionPKs", actionPKs, subjectType)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectCountByActionPKs indicates an expected call of GetSubjectCountByActionPKs
+func (mr *MockPolicyManagerMockRecorder) GetSubjectCountByActionPKs(actionPKs, subjectType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectCountByActionPKs", reflect.TypeOf((*MockPolicyManager)(nil).GetSubjectCountByActionPKs), actionPKs, subjectType)
+}
+
 // ListPagingByActionPKBeforeExpiredAt mocks base method
 func (m *MockPolicyManager) ListPagingByActionPKBeforeExpiredAt(actionPK, expiredAt, offset, limit int64) ([]dao.Policy, error) {
 	m.ctrl.T.Helper()
@@ -298,3 +388,18 @@ func (mr *MockPolicyManagerMockRecorder) ListByPKs(pks interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPKs", reflect.TypeOf((*MockPolicyManager)(nil).ListByPKs), pks)
 }
+
+// ListPagingBetweenExpiredAtAfterPK mocks base method
+func (m *MockPolicyManager) ListPagingBetweenExpiredAtAfterPK(minExpiredAt, minPK, beginExpiredAt, endExpiredAt, limit int64) ([]dao.Policy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingBetweenExpiredAtAfterPK", minExpiredAt, minPK, beginExpiredAt, endExpiredAt, limit)
+	ret0, _ := ret[0].([]dao.Policy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingBetweenExpiredAtAfterPK indicates an expected call of ListPagingBetweenExpiredAtAfterPK
+func (mr *MockPolicyManagerMockRecorder) ListPagingBetweenExpiredAtAfterPK(minExpiredAt, minPK, beginExpiredAt, endExpiredAt, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingBetweenExpiredAtAfterPK", reflect.TypeOf((*MockPolicyManager)(nil).ListPagingBetweenExpiredAtAfterPK), minExpiredAt, minPK, beginExpiredAt, endExpiredAt, limit)
+}
@@ -124,6 +124,51 @@ func (mr *MockSubjectManagerMockRecorder) GetCount(_type interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCount", reflect.TypeOf((*MockSubjectManager)(nil).GetCount), _type)
 }
 
+// GetSearchCount mocks base method
+func (m *MockSubjectManager) GetSearchCount(_type, keyword string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSearchCount", _type, keyword)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSearchCount indicates an expected call of GetSearchCount
+func (mr *MockSubjectManagerMockRecorder) GetSearchCount(_type, keyword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSearchCount", reflect.TypeOf((*MockSubjectManager)(nil).GetSearchCount), _type, keyword)
+}
+
+// SearchPaging mocks base method
+func (m *MockSubjectManager) SearchPaging(_type, keyword string, limit, offset int64) ([]dao.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPaging", _type, keyword, limit, offset)
+	ret0, _ := ret[0].([]dao.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPaging indicates an expected call of SearchPaging
+func (mr *MockSubjectManagerMockRecorder) SearchPaging(_type, keyword, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPaging", reflect.TypeOf((*MockSubjectManager)(nil).SearchPaging), _type, keyword, limit, offset)
+}
+
+// ListFrozenPKs mocks base method
+func (m *MockSubjectManager) ListFrozenPKs() ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFrozenPKs")
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFrozenPKs indicates an expected call of ListFrozenPKs
+func (mr *MockSubjectManagerMockRecorder) ListFrozenPKs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFrozenPKs", reflect.TypeOf((*MockSubjectManager)(nil).ListFrozenPKs))
+}
+
 // BulkCreate mocks base method
 func (m *MockSubjectManager) BulkCreate(subjects []dao.Subject) error {
 	m.ctrl.T.Helper()
@@ -165,3 +210,18 @@ func (mr *MockSubjectManagerMockRecorder) BulkUpdate(subjects interface{}) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpdate", reflect.TypeOf((*MockSubjectManager)(nil).BulkUpdate), subjects)
 }
+
+// UpdateFrozenByPKsWithTx mocks base method
+func (m *MockSubjectManager) UpdateFrozenByPKsWithTx(tx *sqlx.Tx, pks []int64, frozen bool) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFrozenByPKsWithTx", tx, pks, frozen)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFrozenByPKsWithTx indicates an expected call of UpdateFrozenByPKsWithTx
+func (mr *MockSubjectManagerMockRecorder) UpdateFrozenByPKsWithTx(tx, pks, frozen interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFrozenByPKsWithTx", reflect.TypeOf((*MockSubjectManager)(nil).UpdateFrozenByPKsWithTx), tx, pks, frozen)
+}
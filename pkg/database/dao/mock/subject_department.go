@@ -79,6 +79,51 @@ func (mr *MockSubjectDepartmentManagerMockRecorder) ListPaging(limit, offset int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaging", reflect.TypeOf((*MockSubjectDepartmentManager)(nil).ListPaging), limit, offset)
 }
 
+// ListBySubjectPKs mocks base method
+func (m *MockSubjectDepartmentManager) ListBySubjectPKs(subjectPKs []int64) ([]dao.SubjectDepartment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBySubjectPKs", subjectPKs)
+	ret0, _ := ret[0].([]dao.SubjectDepartment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBySubjectPKs indicates an expected call of ListBySubjectPKs
+func (mr *MockSubjectDepartmentManagerMockRecorder) ListBySubjectPKs(subjectPKs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBySubjectPKs", reflect.TypeOf((*MockSubjectDepartmentManager)(nil).ListBySubjectPKs), subjectPKs)
+}
+
+// ListByDepartmentPKs mocks base method
+func (m *MockSubjectDepartmentManager) ListByDepartmentPKs(departmentPKs []int64) ([]dao.SubjectDepartment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByDepartmentPKs", departmentPKs)
+	ret0, _ := ret[0].([]dao.SubjectDepartment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByDepartmentPKs indicates an expected call of ListByDepartmentPKs
+func (mr *MockSubjectDepartmentManagerMockRecorder) ListByDepartmentPKs(departmentPKs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByDepartmentPKs", reflect.TypeOf((*MockSubjectDepartmentManager)(nil).ListByDepartmentPKs), departmentPKs)
+}
+
+// ListSubjectPKsAfterPKByDepartmentPKs mocks base method
+func (m *MockSubjectDepartmentManager) ListSubjectPKsAfterPKByDepartmentPKs(departmentPKs []int64, afterPK, limit int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjectPKsAfterPKByDepartmentPKs", departmentPKs, afterPK, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubjectPKsAfterPKByDepartmentPKs indicates an expected call of ListSubjectPKsAfterPKByDepartmentPKs
+func (mr *MockSubjectDepartmentManagerMockRecorder) ListSubjectPKsAfterPKByDepartmentPKs(departmentPKs, afterPK, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectPKsAfterPKByDepartmentPKs", reflect.TypeOf((*MockSubjectDepartmentManager)(nil).ListSubjectPKsAfterPKByDepartmentPKs), departmentPKs, afterPK, limit)
+}
+
 // BulkCreate mocks base method
 func (m *MockSubjectDepartmentManager) BulkCreate(subjectDepartments []dao.SubjectDepartment) error {
 	m.ctrl.T.Helper()
@@ -121,6 +166,34 @@ func (mr *MockSubjectDepartmentManagerMockRecorder) BulkDelete(subjectPKs interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDelete", reflect.TypeOf((*MockSubjectDepartmentManager)(nil).BulkDelete), subjectPKs)
 }
 
+// BulkCreateWithTx mocks base method
+func (m *MockSubjectDepartmentManager) BulkCreateWithTx(tx *sqlx.Tx, subjectDepartments []dao.SubjectDepartment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkCreateWithTx", tx, subjectDepartments)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkCreateWithTx indicates an expected call of BulkCreateWithTx
+func (mr *MockSubjectDepartmentManagerMockRecorder) BulkCreateWithTx(tx, subjectDepartments interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateWithTx", reflect.TypeOf((*MockSubjectDepartmentManager)(nil).BulkCreateWithTx), tx, subjectDepartments)
+}
+
+// BulkUpdateWithTx mocks base method
+func (m *MockSubjectDepartmentManager) BulkUpdateWithTx(tx *sqlx.Tx, subjectDepartments []dao.SubjectDepartment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkUpdateWithTx", tx, subjectDepartments)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkUpdateWithTx indicates an expected call of BulkUpdateWithTx
+func (mr *MockSubjectDepartmentManagerMockRecorder) BulkUpdateWithTx(tx, subjectDepartments interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpdateWithTx", reflect.TypeOf((*MockSubjectDepartmentManager)(nil).BulkUpdateWithTx), tx, subjectDepartments)
+}
+
 // BulkDeleteWithTx mocks base method
 func (m *MockSubjectDepartmentManager) BulkDeleteWithTx(tx *sqlx.Tx, subjectPKs []int64) error {
 	m.ctrl.T.Helper()
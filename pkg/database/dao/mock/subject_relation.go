@@ -64,6 +64,21 @@ func (mr *MockSubjectRelationManagerMockRecorder) ListRelationBySubjectPK(subjec
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRelationBySubjectPK", reflect.TypeOf((*MockSubjectRelationManager)(nil).ListRelationBySubjectPK), subjectPK)
 }
 
+// ListRelationByPKs mocks base method
+func (m *MockSubjectRelationManager) ListRelationByPKs(pks []int64) ([]dao.SubjectRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRelationByPKs", pks)
+	ret0, _ := ret[0].([]dao.SubjectRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRelationByPKs indicates an expected call of ListRelationByPKs
+func (mr *MockSubjectRelationManagerMockRecorder) ListRelationByPKs(pks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRelationByPKs", reflect.TypeOf((*MockSubjectRelationManager)(nil).ListRelationByPKs), pks)
+}
+
 // ListThinRelationBySubjectPK mocks base method
 func (m *MockSubjectRelationManager) ListThinRelationBySubjectPK(subjectPK int64) ([]dao.ThinSubjectRelation, error) {
 	m.ctrl.T.Helper()
@@ -109,6 +124,66 @@ func (mr *MockSubjectRelationManagerMockRecorder) ListRelationBeforeExpiredAt(_t
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRelationBeforeExpiredAt", reflect.TypeOf((*MockSubjectRelationManager)(nil).ListRelationBeforeExpiredAt), _type, id, expiredAt)
 }
 
+// ListPagingRelation mocks base method
+func (m *MockSubjectRelationManager) ListPagingRelation(_type, id string, limit, offset int64) ([]dao.SubjectRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingRelation", _type, id, limit, offset)
+	ret0, _ := ret[0].([]dao.SubjectRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingRelation indicates an expected call of ListPagingRelation
+func (mr *MockSubjectRelationManagerMockRecorder) ListPagingRelation(_type, id, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingRelation", reflect.TypeOf((*MockSubjectRelationManager)(nil).ListPagingRelation), _type, id, limit, offset)
+}
+
+// ListPagingRelationBeforeExpiredAt mocks base method
+func (m *MockSubjectRelationManager) ListPagingRelationBeforeExpiredAt(_type, id string, expiredAt, limit, offset int64) ([]dao.SubjectRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingRelationBeforeExpiredAt", _type, id, expiredAt, limit, offset)
+	ret0, _ := ret[0].([]dao.SubjectRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingRelationBeforeExpiredAt indicates an expected call of ListPagingRelationBeforeExpiredAt
+func (mr *MockSubjectRelationManagerMockRecorder) ListPagingRelationBeforeExpiredAt(_type, id, expiredAt, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingRelationBeforeExpiredAt", reflect.TypeOf((*MockSubjectRelationManager)(nil).ListPagingRelationBeforeExpiredAt), _type, id, expiredAt, limit, offset)
+}
+
+// GetRelationCount mocks base method
+func (m *MockSubjectRelationManager) GetRelationCount(_type, id string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRelationCount", _type, id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRelationCount indicates an expected call of GetRelationCount
+func (mr *MockSubjectRelationManagerMockRecorder) GetRelationCount(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRelationCount", reflect.TypeOf((*MockSubjectRelationManager)(nil).GetRelationCount), _type, id)
+}
+
+// GetRelationCountBeforeExpiredAt mocks base method
+func (m *MockSubjectRelationManager) GetRelationCountBeforeExpiredAt(_type, id string, expiredAt int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRelationCountBeforeExpiredAt", _type, id, expiredAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRelationCountBeforeExpiredAt indicates an expected call of GetRelationCountBeforeExpiredAt
+func (mr *MockSubjectRelationManagerMockRecorder) GetRelationCountBeforeExpiredAt(_type, id, expiredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRelationCountBeforeExpiredAt", reflect.TypeOf((*MockSubjectRelationManager)(nil).GetRelationCountBeforeExpiredAt), _type, id, expiredAt)
+}
+
 // ListPagingMember mocks base method
 func (m *MockSubjectRelationManager) ListPagingMember(_type, id string, limit, offset int64) ([]dao.SubjectRelation, error) {
 	m.ctrl.T.Helper()
@@ -124,6 +199,21 @@ func (mr *MockSubjectRelationManagerMockRecorder) ListPagingMember(_type, id, li
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingMember", reflect.TypeOf((*MockSubjectRelationManager)(nil).ListPagingMember), _type, id, limit, offset)
 }
 
+// ListPagingMemberBySubjectType mocks base method
+func (m *MockSubjectRelationManager) ListPagingMemberBySubjectType(_type, id, subjectType string, limit, offset int64) ([]dao.SubjectRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingMemberBySubjectType", _type, id, subjectType, limit, offset)
+	ret0, _ := ret[0].([]dao.SubjectRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingMemberBySubjectType indicates an expected call of ListPagingMemberBySubjectType
+func (mr *MockSubjectRelationManagerMockRecorder) ListPagingMemberBySubjectType(_type, id, subjectType, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingMemberBySubjectType", reflect.TypeOf((*MockSubjectRelationManager)(nil).ListPagingMemberBySubjectType), _type, id, subjectType, limit, offset)
+}
+
 // ListPagingMemberBeforeExpiredAt mocks base method
 func (m *MockSubjectRelationManager) ListPagingMemberBeforeExpiredAt(_type, id string, expiredAt, limit, offset int64) ([]dao.SubjectRelation, error) {
 	m.ctrl.T.Helper()
@@ -154,6 +244,66 @@ func (mr *MockSubjectRelationManagerMockRecorder) ListMember(_type, id interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMember", reflect.TypeOf((*MockSubjectRelationManager)(nil).ListMember), _type, id)
 }
 
+// ListMemberByParentPKs mocks base method
+func (m *MockSubjectRelationManager) ListMemberByParentPKs(parentPKs []int64) ([]dao.SubjectRelationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMemberByParentPKs", parentPKs)
+	ret0, _ := ret[0].([]dao.SubjectRelationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMemberByParentPKs indicates an expected call of ListMemberByParentPKs
+func (mr *MockSubjectRelationManagerMockRecorder) ListMemberByParentPKs(parentPKs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMemberByParentPKs", reflect.TypeOf((*MockSubjectRelationManager)(nil).ListMemberByParentPKs), parentPKs)
+}
+
+// ListSubjectPKsAfterPK mocks base method
+func (m *MockSubjectRelationManager) ListSubjectPKsAfterPK(afterPK, limit int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjectPKsAfterPK", afterPK, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubjectPKsAfterPK indicates an expected call of ListSubjectPKsAfterPK
+func (mr *MockSubjectRelationManagerMockRecorder) ListSubjectPKsAfterPK(afterPK, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectPKsAfterPK", reflect.TypeOf((*MockSubjectRelationManager)(nil).ListSubjectPKsAfterPK), afterPK, limit)
+}
+
+// ListEffectMemberByParentPKs mocks base method
+func (m *MockSubjectRelationManager) ListEffectMemberByParentPKs(parentPKs []int64, subjectType string) ([]dao.EffectSubjectRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEffectMemberByParentPKs", parentPKs, subjectType)
+	ret0, _ := ret[0].([]dao.EffectSubjectRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEffectMemberByParentPKs indicates an expected call of ListEffectMemberByParentPKs
+func (mr *MockSubjectRelationManagerMockRecorder) ListEffectMemberByParentPKs(parentPKs, subjectType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEffectMemberByParentPKs", reflect.TypeOf((*MockSubjectRelationManager)(nil).ListEffectMemberByParentPKs), parentPKs, subjectType)
+}
+
+// ListEffectMemberPKsAfterPKByParentPKs mocks base method
+func (m *MockSubjectRelationManager) ListEffectMemberPKsAfterPKByParentPKs(parentPKs []int64, subjectType string, afterPK, limit int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEffectMemberPKsAfterPKByParentPKs", parentPKs, subjectType, afterPK, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEffectMemberPKsAfterPKByParentPKs indicates an expected call of ListEffectMemberPKsAfterPKByParentPKs
+func (mr *MockSubjectRelationManagerMockRecorder) ListEffectMemberPKsAfterPKByParentPKs(parentPKs, subjectType, afterPK, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEffectMemberPKsAfterPKByParentPKs", reflect.TypeOf((*MockSubjectRelationManager)(nil).ListEffectMemberPKsAfterPKByParentPKs), parentPKs, subjectType, afterPK, limit)
+}
+
 // GetMemberCount mocks base method
 func (m *MockSubjectRelationManager) GetMemberCount(_type, id string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -169,6 +319,21 @@ func (mr *MockSubjectRelationManagerMockRecorder) GetMemberCount(_type, id inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberCount", reflect.TypeOf((*MockSubjectRelationManager)(nil).GetMemberCount), _type, id)
 }
 
+// GetMemberCountBySubjectType mocks base method
+func (m *MockSubjectRelationManager) GetMemberCountBySubjectType(_type, id, subjectType string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberCountBySubjectType", _type, id, subjectType)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberCountBySubjectType indicates an expected call of GetMemberCountBySubjectType
+func (mr *MockSubjectRelationManagerMockRecorder) GetMemberCountBySubjectType(_type, id, subjectType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberCountBySubjectType", reflect.TypeOf((*MockSubjectRelationManager)(nil).GetMemberCountBySubjectType), _type, id, subjectType)
+}
+
 // GetMemberCountBeforeExpiredAt mocks base method
 func (m *MockSubjectRelationManager) GetMemberCountBeforeExpiredAt(_type, id string, expiredAt int64) (int64, error) {
 	m.ctrl.T.Helper()
@@ -213,6 +378,20 @@ func (mr *MockSubjectRelationManagerMockRecorder) UpdateExpiredAt(relations inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateExpiredAt", reflect.TypeOf((*MockSubjectRelationManager)(nil).UpdateExpiredAt), relations)
 }
 
+// UpdateExpiredAtWithTx mocks base method
+func (m *MockSubjectRelationManager) UpdateExpiredAtWithTx(tx *sqlx.Tx, relations []dao.SubjectRelationPKPolicyExpiredAt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateExpiredAtWithTx", tx, relations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateExpiredAtWithTx indicates an expected call of UpdateExpiredAtWithTx
+func (mr *MockSubjectRelationManagerMockRecorder) UpdateExpiredAtWithTx(tx, relations interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateExpiredAtWithTx", reflect.TypeOf((*MockSubjectRelationManager)(nil).UpdateExpiredAtWithTx), tx, relations)
+}
+
 // BulkDeleteByMembersWithTx mocks base method
 func (m *MockSubjectRelationManager) BulkDeleteByMembersWithTx(tx *sqlx.Tx, _type, id, subjectType string, subjectIDs []string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -242,6 +421,20 @@ func (mr *MockSubjectRelationManagerMockRecorder) BulkCreate(relations interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreate", reflect.TypeOf((*MockSubjectRelationManager)(nil).BulkCreate), relations)
 }
 
+// BulkCreateWithTx mocks base method
+func (m *MockSubjectRelationManager) BulkCreateWithTx(tx *sqlx.Tx, relations []dao.SubjectRelation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkCreateWithTx", tx, relations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkCreateWithTx indicates an expected call of BulkCreateWithTx
+func (mr *MockSubjectRelationManagerMockRecorder) BulkCreateWithTx(tx, relations interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateWithTx", reflect.TypeOf((*MockSubjectRelationManager)(nil).BulkCreateWithTx), tx, relations)
+}
+
 // BulkDeleteBySubjectPKs mocks base method
 func (m *MockSubjectRelationManager) BulkDeleteBySubjectPKs(tx *sqlx.Tx, subjectPKs []int64) error {
 	m.ctrl.T.Helper()
@@ -269,3 +462,33 @@ func (mr *MockSubjectRelationManagerMockRecorder) BulkDeleteByParentPKs(tx, pare
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDeleteByParentPKs", reflect.TypeOf((*MockSubjectRelationManager)(nil).BulkDeleteByParentPKs), tx, parentPKs)
 }
+
+// ListExpiredRelation mocks base method
+func (m *MockSubjectRelationManager) ListExpiredRelation(expiredAt, limit int64) ([]dao.SubjectRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiredRelation", expiredAt, limit)
+	ret0, _ := ret[0].([]dao.SubjectRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiredRelation indicates an expected call of ListExpiredRelation
+func (mr *MockSubjectRelationManagerMockRecorder) ListExpiredRelation(expiredAt, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredRelation", reflect.TypeOf((*MockSubjectRelationManager)(nil).ListExpiredRelation), expiredAt, limit)
+}
+
+// BulkDeleteExpiredByPKsWithTx mocks base method
+func (m *MockSubjectRelationManager) BulkDeleteExpiredByPKsWithTx(tx *sqlx.Tx, pks []int64, expiredAt int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkDeleteExpiredByPKsWithTx", tx, pks, expiredAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkDeleteExpiredByPKsWithTx indicates an expected call of BulkDeleteExpiredByPKsWithTx
+func (mr *MockSubjectRelationManagerMockRecorder) BulkDeleteExpiredByPKsWithTx(tx, pks, expiredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDeleteExpiredByPKsWithTx", reflect.TypeOf((*MockSubjectRelationManager)(nil).BulkDeleteExpiredByPKsWithTx), tx, pks, expiredAt)
+}
@@ -6,6 +6,7 @@ package mock
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	sqlx "github.com/jmoiron/sqlx"
 	dao "iam/pkg/database/dao"
 	reflect "reflect"
 )
@@ -63,6 +64,51 @@ func (mr *MockSubjectRoleManagerMockRecorder) ListSystemIDBySubjectPK(pk interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSystemIDBySubjectPK", reflect.TypeOf((*MockSubjectRoleManager)(nil).ListSystemIDBySubjectPK), pk)
 }
 
+// ListBySubjectPK mocks base method
+func (m *MockSubjectRoleManager) ListBySubjectPK(pk int64) ([]dao.SubjectRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBySubjectPK", pk)
+	ret0, _ := ret[0].([]dao.SubjectRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBySubjectPK indicates an expected call of ListBySubjectPK
+func (mr *MockSubjectRoleManagerMockRecorder) ListBySubjectPK(pk interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBySubjectPK", reflect.TypeOf((*MockSubjectRoleManager)(nil).ListBySubjectPK), pk)
+}
+
+// GetCount mocks base method
+func (m *MockSubjectRoleManager) GetCount() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCount")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCount indicates an expected call of GetCount
+func (mr *MockSubjectRoleManagerMockRecorder) GetCount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCount", reflect.TypeOf((*MockSubjectRoleManager)(nil).GetCount))
+}
+
+// ListPaging mocks base method
+func (m *MockSubjectRoleManager) ListPaging(limit, offset int64) ([]dao.SubjectRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPaging", limit, offset)
+	ret0, _ := ret[0].([]dao.SubjectRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPaging indicates an expected call of ListPaging
+func (mr *MockSubjectRoleManagerMockRecorder) ListPaging(limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaging", reflect.TypeOf((*MockSubjectRoleManager)(nil).ListPaging), limit, offset)
+}
+
 // BulkCreate mocks base method
 func (m *MockSubjectRoleManager) BulkCreate(roles []dao.SubjectRole) error {
 	m.ctrl.T.Helper()
@@ -77,6 +123,20 @@ func (mr *MockSubjectRoleManagerMockRecorder) BulkCreate(roles interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreate", reflect.TypeOf((*MockSubjectRoleManager)(nil).BulkCreate), roles)
 }
 
+// BulkCreateWithTx mocks base method
+func (m *MockSubjectRoleManager) BulkCreateWithTx(tx *sqlx.Tx, roles []dao.SubjectRole) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkCreateWithTx", tx, roles)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkCreateWithTx indicates an expected call of BulkCreateWithTx
+func (mr *MockSubjectRoleManagerMockRecorder) BulkCreateWithTx(tx, roles interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateWithTx", reflect.TypeOf((*MockSubjectRoleManager)(nil).BulkCreateWithTx), tx, roles)
+}
+
 // BulkDelete mocks base method
 func (m *MockSubjectRoleManager) BulkDelete(roleType, system string, subjectPKs []int64) error {
 	m.ctrl.T.Helper()
@@ -90,3 +150,17 @@ func (mr *MockSubjectRoleManagerMockRecorder) BulkDelete(roleType, system, subje
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDelete", reflect.TypeOf((*MockSubjectRoleManager)(nil).BulkDelete), roleType, system, subjectPKs)
 }
+
+// BulkDeleteWithTx mocks base method
+func (m *MockSubjectRoleManager) BulkDeleteWithTx(tx *sqlx.Tx, roleType, system string, subjectPKs []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkDeleteWithTx", tx, roleType, system, subjectPKs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkDeleteWithTx indicates an expected call of BulkDeleteWithTx
+func (mr *MockSubjectRoleManagerMockRecorder) BulkDeleteWithTx(tx, roleType, system, subjectPKs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDeleteWithTx", reflect.TypeOf((*MockSubjectRoleManager)(nil).BulkDeleteWithTx), tx, roleType, system, subjectPKs)
+}
@@ -33,13 +33,19 @@ type ModelChangeEvent struct {
 	ModelType string `db:"model_type"`
 	ModelID   string `db:"model_id"`
 	ModelPK   int64  `db:"model_pk"`
+
+	// 接入系统回调的投递状态, 系统未注册回调时为空
+	CallbackStatus  string `db:"callback_status"`
+	CallbackMessage string `db:"callback_message"`
 }
 
 // ModelChangeEventManager define the event crud for model change
 type ModelChangeEventManager interface {
+	Get(pk int64) (ModelChangeEvent, error)
 	GetByTypeModel(eventType, status, modelType string, modelPK int64) (ModelChangeEvent, error)
 	ListByStatus(status string) ([]ModelChangeEvent, error)
 	UpdateStatusByPK(pk int64, status string) error
+	UpdateCallbackStatusByPK(pk int64, callbackStatus, callbackMessage string) error
 	BulkCreate(modelChangeEvents []ModelChangeEvent) error
 }
 
@@ -54,6 +60,12 @@ func NewModelChangeEventManager() ModelChangeEventManager {
 	}
 }
 
+// Get ...
+func (m *modelChangeEventManager) Get(pk int64) (modelChangeEvent ModelChangeEvent, err error) {
+	err = m.selectByPK(&modelChangeEvent, pk)
+	return
+}
+
 // GetByTypeModel ...
 func (m *modelChangeEventManager) GetByTypeModel(eventType, status, modelType string,
 	modelPK int64) (modelChangeEvent ModelChangeEvent, err error) {
@@ -88,6 +100,19 @@ func (m *modelChangeEventManager) UpdateStatusByPK(pk int64, status string) erro
 	return m.update(updatedSQL, data)
 }
 
+// UpdateCallbackStatusByPK ...
+func (m *modelChangeEventManager) UpdateCallbackStatusByPK(pk int64, callbackStatus, callbackMessage string) error {
+	updatedSQL := `UPDATE model_change_event
+		SET callback_status = :callback_status,
+		callback_message = :callback_message
+		WHERE pk = :pk`
+	return m.update(updatedSQL, map[string]interface{}{
+		"pk":               pk,
+		"callback_status":  callbackStatus,
+		"callback_message": callbackMessage,
+	})
+}
+
 // BulkCreate ...
 func (m *modelChangeEventManager) BulkCreate(modelChangeEvents []ModelChangeEvent) error {
 	return m.insert(modelChangeEvents)
@@ -102,7 +127,9 @@ func (m *modelChangeEventManager) selectOne(modelChangeEvent *ModelChangeEvent,
 		system_id,
 		model_type,
 		model_id,
-		model_pk
+		model_pk,
+		callback_status,
+		callback_message
 		FROM model_change_event
 		WHERE type = ?
 		AND status = ?
@@ -112,6 +139,23 @@ func (m *modelChangeEventManager) selectOne(modelChangeEvent *ModelChangeEvent,
 	return database.SqlxGet(m.DB, modelChangeEvent, query, eventType, status, modelType, modelPK)
 }
 
+func (m *modelChangeEventManager) selectByPK(modelChangeEvent *ModelChangeEvent, pk int64) error {
+	query := `SELECT
+		pk,
+		type,
+		status,
+		system_id,
+		model_type,
+		model_id,
+		model_pk,
+		callback_status,
+		callback_message
+		FROM model_change_event
+		WHERE pk = ?
+		LIMIT 1`
+	return database.SqlxGet(m.DB, modelChangeEvent, query, pk)
+}
+
 func (m *modelChangeEventManager) selectByStatus(modelChangeEvents *[]ModelChangeEvent, status string) error {
 	query := `SELECT
 		pk,
@@ -120,7 +164,9 @@ func (m *modelChangeEventManager) selectByStatus(modelChangeEvents *[]ModelChang
 		system_id,
 		model_type,
 		model_id,
-		model_pk
+		model_pk,
+		callback_status,
+		callback_message
 		FROM model_change_event
 		WHERE status=?`
 	return database.SqlxSelect(m.DB, modelChangeEvents, query, status)
@@ -23,10 +23,12 @@ import (
 
 // AuthPolicy ...
 type AuthPolicy struct {
-	PK           int64 `db:"pk"`
-	SubjectPK    int64 `db:"subject_pk"`
-	ExpressionPK int64 `db:"expression_pk"`
-	ExpiredAt    int64 `db:"expired_at"`
+	PK           int64  `db:"pk"`
+	SubjectPK    int64  `db:"subject_pk"`
+	ExpressionPK int64  `db:"expression_pk"`
+	Effect       string `db:"effect"`
+	Priority     int64  `db:"priority"`
+	ExpiredAt    int64  `db:"expired_at"`
 }
 
 // Policy ...
@@ -36,6 +38,10 @@ type Policy struct {
 	SubjectPK    int64 `db:"subject_pk"` // 关联Subject表自增列
 	ActionPK     int64 `db:"action_pk"`  // 关联Action表自增列
 	ExpressionPK int64 `db:"expression_pk"`
+	// 策略效果, allow/deny, deny优先
+	Effect string `db:"effect"`
+	// 策略优先级, 仅在first_match求值模式下生效, 值越大越先求值
+	Priority int64 `db:"priority"`
 
 	// 策略有效期，unix time，单位秒(s)
 	ExpiredAt  int64 `db:"expired_at"`
@@ -55,13 +61,16 @@ type PolicyManager interface {
 	ListBySubjectActionTemplate(subjectPK int64, actionPKs []int64, templateID int64) ([]Policy, error)
 	ListExpressionBySubjectsTemplate(subjectPKs []int64, templateID int64) ([]int64, error)
 	ListBySubjectTemplateBeforeExpiredAt(subjectPK int64, templateID, expiredAt int64) ([]Policy, error)
+	ListBySubjectTemplate(subjectPK int64, templateID int64) ([]Policy, error)
+	ListBySubjectTemplateWithLimit(subjectPK int64, templateID int64, limit int64) ([]Policy, error)
 	BulkCreateWithTx(tx *sqlx.Tx, policies []Policy) error
 	BulkDeleteByTemplatePKsWithTx(tx *sqlx.Tx, subjectPK, templateID int64, pks []int64) (int64, error)
 	BulkDeleteBySubjectPKsWithTx(tx *sqlx.Tx, subjectPKs []int64) error
 	BulkUpdateExpressionPKWithTx(tx *sqlx.Tx, policies []Policy) error
 	BulkDeleteBySubjectTemplate(subjectPK int64, templateID int64) error
 	BulkUpdateExpiredAtWithTx(tx *sqlx.Tx, policies []Policy) error
-	DeleteByActionPKWithTx(tx *sqlx.Tx, actionPK, limit int64) (int64, error)
+	ListByActionPKWithLimit(actionPK int64, limit int64) ([]Policy, error)
+	BulkDeleteByActionPKsWithTx(tx *sqlx.Tx, actionPK int64, pks []int64) (int64, error)
 	// for model update
 
 	HasAnyByActionPK(actionPK int64) (bool, error)
@@ -70,8 +79,17 @@ type PolicyManager interface {
 
 	Get(pk int64) (Policy, error)
 	GetCountByActionBeforeExpiredAt(actionPK int64, expiredAt int64) (int64, error)
+	GetCountBySubjectTemplate(subjectPK int64, templateID int64) (int64, error)
+	GetCountByActionPKs(actionPKs []int64) (int64, error)
+	GetSubjectCountByActionPKs(actionPKs []int64, subjectType string) (int64, error)
 	ListPagingByActionPKBeforeExpiredAt(actionPK int64, expiredAt int64, offset int64, limit int64) ([]Policy, error)
 	ListByPKs(pks []int64) ([]Policy, error)
+
+	// for expiration notification
+
+	ListPagingBetweenExpiredAtAfterPK(
+		minExpiredAt int64, minPK int64, beginExpiredAt int64, endExpiredAt int64, limit int64,
+	) ([]Policy, error)
 }
 
 type policyManager struct {
@@ -85,6 +103,13 @@ func NewPolicyManager() PolicyManager {
 	}
 }
 
+// NewBatchPolicyManager 批量任务(清理等)使用的PolicyManager, 使用批量任务的DB连接池
+func NewBatchPolicyManager() PolicyManager {
+	return &policyManager{
+		DB: database.GetBatchDBClient().DB,
+	}
+}
+
 // ListBySubjectPKAndPKs ...
 func (m *policyManager) ListBySubjectPKAndPKs(subjectPK int64, pks []int64) (policies []Policy, err error) {
 	if len(pks) == 0 {
@@ -134,6 +159,26 @@ func (m *policyManager) ListBySubjectTemplateBeforeExpiredAt(
 	return
 }
 
+// ListBySubjectTemplate ...
+func (m *policyManager) ListBySubjectTemplate(subjectPK int64, templateID int64) (policies []Policy, err error) {
+	err = m.selectBySubjectTemplate(&policies, subjectPK, templateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return policies, nil
+	}
+	return
+}
+
+// ListBySubjectTemplateWithLimit list at most `limit` policies by subjectPK and templateID
+func (m *policyManager) ListBySubjectTemplateWithLimit(
+	subjectPK int64, templateID int64, limit int64,
+) (policies []Policy, err error) {
+	err = m.selectBySubjectTemplateWithLimit(&policies, subjectPK, templateID, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return policies, nil
+	}
+	return
+}
+
 // ListBySubjectActionTemplate ...
 func (m *policyManager) ListBySubjectActionTemplate(
 	subjectPK int64,
@@ -235,6 +280,18 @@ func (m *policyManager) ListByPKs(pks []int64) (policies []Policy, err error) {
 	return
 }
 
+// ListPagingBetweenExpiredAtAfterPK 游标分页查询过期时间在(beginExpiredAt, endExpiredAt]之间的策略,
+// 按(expired_at, pk)升序, 游标为上一页最后一条的(expired_at, pk)
+func (m *policyManager) ListPagingBetweenExpiredAtAfterPK(
+	minExpiredAt int64, minPK int64, beginExpiredAt int64, endExpiredAt int64, limit int64,
+) (policies []Policy, err error) {
+	err = m.selectBetweenExpiredAtAfterPK(&policies, minExpiredAt, minPK, beginExpiredAt, endExpiredAt, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return policies, nil
+	}
+	return
+}
+
 // BulkUpdateExpiredAtWithTx ...
 func (m *policyManager) BulkUpdateExpiredAtWithTx(tx *sqlx.Tx, policies []Policy) error {
 	return m.updateExpiredAtWithTx(tx, policies)
@@ -245,9 +302,45 @@ func (m *policyManager) BulkDeleteBySubjectTemplate(subjectPK int64, templateID
 	return m.bulkDeleteBySubjectPKTemplateID(subjectPK, templateID)
 }
 
-// DeleteByActionPKWithTx ...
-func (m *policyManager) DeleteByActionPKWithTx(tx *sqlx.Tx, actionPK, limit int64) (int64, error) {
-	return m.deleteByActionPKWithTx(tx, actionPK, limit)
+// GetCountBySubjectTemplate ...
+func (m *policyManager) GetCountBySubjectTemplate(subjectPK int64, templateID int64) (count int64, err error) {
+	err = m.selectCountBySubjectTemplate(&count, subjectPK, templateID)
+	return
+}
+
+// GetCountByActionPKs ...
+func (m *policyManager) GetCountByActionPKs(actionPKs []int64) (count int64, err error) {
+	if len(actionPKs) == 0 {
+		return
+	}
+	err = m.selectCountByActionPKs(&count, actionPKs)
+	return
+}
+
+// GetSubjectCountByActionPKs 统计拥有这些action策略的subject数量
+func (m *policyManager) GetSubjectCountByActionPKs(actionPKs []int64, subjectType string) (count int64, err error) {
+	if len(actionPKs) == 0 {
+		return
+	}
+	err = m.selectSubjectCountByActionPKs(&count, actionPKs, subjectType)
+	return
+}
+
+// ListByActionPKWithLimit 按pk顺序查询action的最多limit条策略
+func (m *policyManager) ListByActionPKWithLimit(actionPK int64, limit int64) (policies []Policy, err error) {
+	err = m.selectByActionPKWithLimit(&policies, actionPK, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return policies, nil
+	}
+	return
+}
+
+// BulkDeleteByActionPKsWithTx ...
+func (m *policyManager) BulkDeleteByActionPKsWithTx(tx *sqlx.Tx, actionPK int64, pks []int64) (int64, error) {
+	if len(pks) == 0 {
+		return 0, nil
+	}
+	return m.bulkDeleteByActionPKsWithTx(tx, actionPK, pks)
 }
 
 func (m *policyManager) getByActionTemplate(
@@ -257,6 +350,8 @@ func (m *policyManager) getByActionTemplate(
 		subject_pk,
 		action_pk,
 		expression_pk,
+		effect,
+		priority,
 		expired_at,
 		template_id
 		FROM policy
@@ -274,6 +369,8 @@ func (m *policyManager) selectByPK(
 		subject_pk,
 		action_pk,
 		expression_pk,
+		effect,
+		priority,
 		expired_at,
 		template_id
 		FROM policy
@@ -289,6 +386,8 @@ func (m *policyManager) selectByPKs(
 		subject_pk,
 		action_pk,
 		expression_pk,
+		effect,
+		priority,
 		expired_at,
 		template_id
 		FROM policy
@@ -317,6 +416,8 @@ func (m *policyManager) selectByActionPKOrderByPKAsc(
 		subject_pk,
 		action_pk,
 		expression_pk,
+		effect,
+		priority,
 		expired_at,
 		template_id
 		FROM policy
@@ -332,6 +433,8 @@ func (m *policyManager) selectByActionPKOrderByPKAsc(
 			t.subject_pk,
 			t.action_pk,
 			t.expression_pk,
+			t.effect,
+			t.priority,
 			t.expired_at,
 			t.template_id
 			FROM policy t
@@ -349,6 +452,33 @@ func (m *policyManager) selectByActionPKOrderByPKAsc(
 	return database.SqlxSelect(m.DB, policies, query, actionPK, expiredAt, offset, limit)
 }
 
+func (m *policyManager) selectBetweenExpiredAtAfterPK(
+	policies *[]Policy,
+	minExpiredAt int64,
+	minPK int64,
+	beginExpiredAt int64,
+	endExpiredAt int64,
+	limit int64,
+) error {
+	query := `SELECT
+		pk,
+		subject_pk,
+		action_pk,
+		expression_pk,
+		effect,
+		priority,
+		expired_at,
+		template_id
+		FROM policy
+		WHERE expired_at > ?
+		AND expired_at <= ?
+		AND (expired_at > ? OR (expired_at = ? AND pk > ?))
+		ORDER BY expired_at asc, pk asc
+		LIMIT ?`
+	return database.SqlxSelect(m.DB, policies, query,
+		beginExpiredAt, endExpiredAt, minExpiredAt, minExpiredAt, minPK, limit)
+}
+
 func (m *policyManager) selectBySubjectPKAndPKs(
 	policies *[]Policy, subjectPK int64, pks []int64) error {
 	query := `SELECT
@@ -356,6 +486,8 @@ func (m *policyManager) selectBySubjectPKAndPKs(
 		subject_pk,
 		action_pk,
 		expression_pk,
+		effect,
+		priority,
 		expired_at,
 		template_id
 		FROM policy
@@ -370,6 +502,8 @@ func (m *policyManager) selectAuthBySubjectAction(
 		pk,
 		subject_pk,
 		expression_pk,
+		effect,
+		priority,
 		expired_at
 		FROM policy
 		WHERE subject_pk in (?)
@@ -395,6 +529,8 @@ func (m *policyManager) selectBySubjectActionTemplate(
 		subject_pk,
 		action_pk,
 		expression_pk,
+		effect,
+		priority,
 		expired_at,
 		template_id
 		FROM policy
@@ -411,6 +547,8 @@ func (m *policyManager) selectBySubjectTemplateBeforeExpiredAt(
 		subject_pk,
 		action_pk,
 		expression_pk,
+		effect,
+		priority,
 		expired_at,
 		template_id
 		FROM policy
@@ -421,17 +559,73 @@ func (m *policyManager) selectBySubjectTemplateBeforeExpiredAt(
 	return database.SqlxSelect(m.DB, policies, query, subjectPK, templateID, expiredAt)
 }
 
+func (m *policyManager) selectBySubjectTemplate(policies *[]Policy, subjectPK int64, templateID int64) error {
+	query := `SELECT
+		pk,
+		subject_pk,
+		action_pk,
+		expression_pk,
+		effect,
+		priority,
+		expired_at,
+		template_id
+		FROM policy
+		WHERE subject_pk = ?
+		AND template_id = ?`
+	return database.SqlxSelect(m.DB, policies, query, subjectPK, templateID)
+}
+
+func (m *policyManager) selectBySubjectTemplateWithLimit(
+	policies *[]Policy, subjectPK int64, templateID int64, limit int64) error {
+	query := `SELECT
+		pk,
+		subject_pk,
+		action_pk,
+		expression_pk,
+		effect,
+		priority,
+		expired_at,
+		template_id
+		FROM policy
+		WHERE subject_pk = ?
+		AND template_id = ?
+		ORDER BY pk
+		LIMIT ?`
+	return database.SqlxSelect(m.DB, policies, query, subjectPK, templateID, limit)
+}
+
+func (m *policyManager) selectByActionPKWithLimit(policies *[]Policy, actionPK int64, limit int64) error {
+	query := `SELECT
+		pk,
+		subject_pk,
+		action_pk,
+		expression_pk,
+		effect,
+		priority,
+		expired_at,
+		template_id
+		FROM policy
+		WHERE action_pk = ?
+		ORDER BY pk
+		LIMIT ?`
+	return database.SqlxSelect(m.DB, policies, query, actionPK, limit)
+}
+
 func (m *policyManager) bulkInsertWithTx(tx *sqlx.Tx, policies []Policy) error {
 	sql := `INSERT INTO policy (
 		subject_pk,
 		action_pk,
 		expression_pk,
+		effect,
+		priority,
 		expired_at,
 		template_id
 	) VALUES (
 		:subject_pk,
 		:action_pk,
 		:expression_pk,
+		:effect,
+		:priority,
 		:expired_at,
 		:template_id)`
 	return database.SqlxBulkInsertWithTx(tx, sql, policies)
@@ -475,7 +669,34 @@ func (m *policyManager) bulkDeleteBySubjectPKTemplateID(subjectPK int64, templat
 	return err
 }
 
-func (m *policyManager) deleteByActionPKWithTx(tx *sqlx.Tx, actionPK, limit int64) (int64, error) {
-	sql := `DELETE FROM policy WHERE action_pk = ? LIMIT ?`
-	return database.SqlxDeleteReturnRowsWithTx(tx, sql, actionPK, limit)
+func (m *policyManager) selectCountBySubjectTemplate(count *int64, subjectPK int64, templateID int64) error {
+	query := `SELECT
+		COUNT(*)
+		FROM policy
+		WHERE subject_pk = ?
+		AND template_id = ?`
+	return database.SqlxGet(m.DB, count, query, subjectPK, templateID)
+}
+
+func (m *policyManager) selectCountByActionPKs(count *int64, actionPKs []int64) error {
+	query := `SELECT
+		COUNT(*)
+		FROM policy
+		WHERE action_pk IN (?)`
+	return database.SqlxGet(m.DB, count, query, actionPKs)
+}
+
+func (m *policyManager) selectSubjectCountByActionPKs(count *int64, actionPKs []int64, subjectType string) error {
+	query := `SELECT
+		COUNT(DISTINCT p.subject_pk)
+		FROM policy p
+		JOIN subject s ON p.subject_pk = s.pk
+		WHERE p.action_pk IN (?)
+		AND s.type = ?`
+	return database.SqlxGet(m.DB, count, query, actionPKs, subjectType)
+}
+
+func (m *policyManager) bulkDeleteByActionPKsWithTx(tx *sqlx.Tx, actionPK int64, pks []int64) (int64, error) {
+	sql := `DELETE FROM policy WHERE action_pk = ? AND pk IN (?)`
+	return database.SqlxDeleteReturnRowsWithTx(tx, sql, actionPK, pks)
 }
@@ -33,10 +33,12 @@ func Test_policyManager_ListAuthBySubjectAction(t *testing.T) {
 				PK:           2,
 				SubjectPK:    2,
 				ExpressionPK: 2,
+				Effect:       "deny",
+				Priority:     10,
 				ExpiredAt:    2,
 			},
 		}
-		mockQuery := `^SELECT pk, subject_pk, expression_pk, expired_at FROM policy WHERE subject_pk`
+		mockQuery := `^SELECT pk, subject_pk, expression_pk, effect, priority, expired_at FROM policy WHERE subject_pk`
 		mockRows := database.NewMockRows(mock, mockData...)
 		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(2), int64(1), 0).WillReturnRows(mockRows)
 
@@ -54,7 +56,7 @@ func Test_policyManager_BulkCreateWithTx(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectExec(`INSERT INTO policy`).WithArgs(
-			int64(1), int64(1), int64(1), int64(1), int64(1),
+			int64(1), int64(1), int64(1), "deny", int64(10), int64(1), int64(1),
 		).WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
@@ -65,6 +67,8 @@ func Test_policyManager_BulkCreateWithTx(t *testing.T) {
 			SubjectPK:    1,
 			ActionPK:     1,
 			ExpressionPK: 1,
+			Effect:       "deny",
+			Priority:     10,
 			ExpiredAt:    1,
 			TemplateID:   1,
 		}
@@ -115,7 +119,7 @@ func Test_policyManager_ListByPKs(t *testing.T) {
 				ExpiredAt:    2,
 			},
 		}
-		mockQuery := `^SELECT pk, subject_pk, action_pk, expression_pk, expired_at, template_id FROM policy WHERE subject_pk`
+		mockQuery := `^SELECT pk, subject_pk, action_pk, expression_pk, effect, priority, expired_at, template_id FROM policy WHERE subject_pk`
 		mockRows := database.NewMockRows(mock, mockData...)
 		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(1), int64(2)).WillReturnRows(mockRows)
 
@@ -180,7 +184,7 @@ func Test_policyManager_ListBySubjectTemplateBeforeExpiredAt(t *testing.T) {
 				ExpiredAt:    2,
 			},
 		}
-		mockQuery := `^SELECT pk, subject_pk, action_pk, expression_pk, expired_at, template_id FROM policy WHERE subject_pk`
+		mockQuery := `^SELECT pk, subject_pk, action_pk, expression_pk, effect, priority, expired_at, template_id FROM policy WHERE subject_pk`
 		mockRows := database.NewMockRows(mock, mockData...)
 		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(0), int64(1000)).WillReturnRows(mockRows)
 
@@ -258,3 +262,153 @@ func Test_policyManager_BulkDeleteBySubjectTemplate(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func Test_policyManager_GetCountBySubjectTemplate(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT COUNT\(\*\) FROM policy WHERE subject_pk = (.*) AND template_id = (.*)`
+		mockRows := sqlmock.NewRows([]string{"count(*)"}).AddRow(int64(3))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(2)).WillReturnRows(mockRows)
+
+		manager := &policyManager{DB: db}
+		count, err := manager.GetCountBySubjectTemplate(int64(1), int64(2))
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+}
+
+func Test_policyManager_GetCountByActionPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT COUNT\(\*\) FROM policy WHERE action_pk IN \((.*)\)`
+		mockRows := sqlmock.NewRows([]string{"count(*)"}).AddRow(int64(5))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(2)).WillReturnRows(mockRows)
+
+		manager := &policyManager{DB: db}
+		count, err := manager.GetCountByActionPKs([]int64{1, 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), count)
+	})
+}
+
+func Test_policyManager_GetSubjectCountByActionPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT COUNT\(DISTINCT p.subject_pk\) FROM policy p JOIN subject s ON p.subject_pk = s.pk ` +
+			`WHERE p.action_pk IN \((.*)\) AND s.type = (.*)`
+		mockRows := sqlmock.NewRows([]string{"count"}).AddRow(int64(2))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(2), "group").WillReturnRows(mockRows)
+
+		manager := &policyManager{DB: db}
+		count, err := manager.GetSubjectCountByActionPKs([]int64{1, 2}, "group")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		count, err = manager.GetSubjectCountByActionPKs([]int64{}, "group")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+}
+
+func Test_policyManager_ListBySubjectTemplateWithLimit(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockData := []interface{}{
+			Policy{
+				PK:           1,
+				SubjectPK:    1,
+				ActionPK:     1,
+				ExpressionPK: 3,
+				ExpiredAt:    100,
+				TemplateID:   2,
+			},
+		}
+		mockQuery := `^SELECT pk, subject_pk, action_pk, expression_pk, effect, priority, expired_at, template_id FROM policy ` +
+			`WHERE subject_pk = (.*) AND template_id = (.*) ORDER BY pk LIMIT`
+		mockRows := database.NewMockRows(mock, mockData...)
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(2), int64(1000)).WillReturnRows(mockRows)
+
+		manager := &policyManager{DB: db}
+		policies, err := manager.ListBySubjectTemplateWithLimit(int64(1), int64(2), int64(1000))
+
+		assert.NoError(t, err)
+		assert.Len(t, policies, 1)
+		assert.Equal(t, mockData[0].(Policy), policies[0])
+	})
+}
+
+func Test_policyManager_ListByActionPKWithLimit(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockData := []interface{}{
+			Policy{
+				PK:           1,
+				SubjectPK:    1,
+				ActionPK:     2,
+				ExpressionPK: 3,
+				ExpiredAt:    100,
+				TemplateID:   0,
+			},
+		}
+		mockQuery := `^SELECT pk, subject_pk, action_pk, expression_pk, effect, priority, expired_at, template_id FROM policy ` +
+			`WHERE action_pk = (.*) ORDER BY pk LIMIT`
+		mockRows := database.NewMockRows(mock, mockData...)
+		mock.ExpectQuery(mockQuery).WithArgs(int64(2), int64(1000)).WillReturnRows(mockRows)
+
+		manager := &policyManager{DB: db}
+		policies, err := manager.ListByActionPKWithLimit(int64(2), int64(1000))
+
+		assert.NoError(t, err)
+		assert.Len(t, policies, 1)
+		assert.Equal(t, mockData[0].(Policy), policies[0])
+	})
+}
+
+func Test_policyManager_BulkDeleteByActionPKsWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`^DELETE FROM policy WHERE action_pk = (.*) AND pk IN`).WithArgs(
+			int64(2), int64(1), int64(3),
+		).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &policyManager{DB: db}
+		rows, err := manager.BulkDeleteByActionPKsWithTx(tx, int64(2), []int64{1, 3})
+
+		tx.Commit()
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), rows)
+	})
+}
+
+func Test_policyManager_ListPagingBetweenExpiredAtAfterPK(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockData := []interface{}{
+			Policy{
+				PK:           11,
+				SubjectPK:    1,
+				ActionPK:     1,
+				ExpressionPK: 1,
+				ExpiredAt:    150,
+				TemplateID:   0,
+			},
+		}
+		mockQuery := `^SELECT pk, subject_pk, action_pk, expression_pk, effect, priority, expired_at, template_id FROM policy ` +
+			`WHERE expired_at > (.*) AND expired_at <= (.*) ` +
+			`AND \(expired_at > (.*) OR \(expired_at = (.*) AND pk > (.*)\)\) ` +
+			`ORDER BY expired_at asc, pk asc LIMIT`
+		mockRows := database.NewMockRows(mock, mockData...)
+		mock.ExpectQuery(mockQuery).WithArgs(
+			int64(100), int64(200), int64(120), int64(120), int64(10), int64(50),
+		).WillReturnRows(mockRows)
+
+		manager := &policyManager{DB: db}
+		policies, err := manager.ListPagingBetweenExpiredAtAfterPK(120, 10, 100, 200, 50)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Len(t, policies, 1)
+		assert.Equal(t, policies[0], mockData[0].(Policy))
+	})
+}
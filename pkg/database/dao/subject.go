@@ -38,11 +38,15 @@ type SubjectManager interface {
 	ListPaging(_type string, limit, offset int64) ([]Subject, error)
 	ListByPKs(pks []int64) ([]Subject, error)
 	GetCount(_type string) (int64, error)
+	GetSearchCount(_type, keyword string) (int64, error)
+	SearchPaging(_type, keyword string, limit, offset int64) ([]Subject, error)
+	ListFrozenPKs() ([]int64, error)
 
 	BulkCreate(subjects []Subject) error
 	//Delete(subject Subject) error
 	BulkDeleteByPKsWithTx(tx *sqlx.Tx, pks []int64) error
 	BulkUpdate(subjects []Subject) error
+	UpdateFrozenByPKsWithTx(tx *sqlx.Tx, pks []int64, frozen bool) (int64, error)
 }
 
 type subjectManager struct {
@@ -56,6 +60,13 @@ func NewSubjectManager() SubjectManager {
 	}
 }
 
+// NewBatchSubjectManager 使用批量任务的连接池
+func NewBatchSubjectManager() SubjectManager {
+	return &subjectManager{
+		DB: database.GetBatchDBClient().DB,
+	}
+}
+
 // Get ...
 func (m *subjectManager) Get(pk int64) (subject Subject, err error) {
 	err = m.selectOne(&subject, pk)
@@ -109,6 +120,31 @@ func (m *subjectManager) GetCount(_type string) (int64, error) {
 	return cnt, err
 }
 
+// GetSearchCount ...
+func (m *subjectManager) GetSearchCount(_type, keyword string) (int64, error) {
+	var cnt int64
+	err := m.getSearchCount(&cnt, _type, keyword)
+	return cnt, err
+}
+
+// SearchPaging 按id/name前缀匹配查询subject, _type为空时不限制类型
+func (m *subjectManager) SearchPaging(_type, keyword string, limit, offset int64) (subjects []Subject, err error) {
+	err = m.selectSearchSubjects(&subjects, _type, keyword, limit, offset)
+	if errors.Is(err, sql.ErrNoRows) {
+		return subjects, nil
+	}
+	return
+}
+
+// ListFrozenPKs 查询所有被冻结的subject pk
+func (m *subjectManager) ListFrozenPKs() (pks []int64, err error) {
+	err = m.selectFrozenPKs(&pks)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pks, nil
+	}
+	return
+}
+
 // BulkCreate ...
 func (m *subjectManager) BulkCreate(subjects []Subject) error {
 	if len(subjects) == 0 {
@@ -137,6 +173,14 @@ func (m *subjectManager) BulkUpdate(subjects []Subject) error {
 	return m.bulkUpdate(subjects)
 }
 
+// UpdateFrozenByPKsWithTx 冻结/解冻subject, 返回状态有变化的数量
+func (m *subjectManager) UpdateFrozenByPKsWithTx(tx *sqlx.Tx, pks []int64, frozen bool) (int64, error) {
+	if len(pks) == 0 {
+		return 0, nil
+	}
+	return m.updateFrozenByPKsWithTx(tx, pks, frozen)
+}
+
 func (m *subjectManager) selectOne(subject *Subject, pk int64) error {
 	query := `SELECT
 		pk,
@@ -202,6 +246,48 @@ func (m *subjectManager) getCount(cnt *int64, _type string) error {
 	return database.SqlxGet(m.DB, cnt, query, _type)
 }
 
+// NOTE: 只支持前缀匹配, 才能使用 type+id / type+name 的索引
+func searchCondition(_type, keyword string) (string, []interface{}) {
+	pattern := database.EscapeLike(keyword) + "%"
+	if _type == "" {
+		return "(id LIKE ? OR name LIKE ?)", []interface{}{pattern, pattern}
+	}
+	return "type = ? AND (id LIKE ? OR name LIKE ?)", []interface{}{_type, pattern, pattern}
+}
+
+func (m *subjectManager) getSearchCount(cnt *int64, _type, keyword string) error {
+	condition, args := searchCondition(_type, keyword)
+	query := `SELECT
+		COUNT(*)
+		FROM subject
+		WHERE ` + condition
+	return database.SqlxGet(m.DB, cnt, query, args...)
+}
+
+func (m *subjectManager) selectSearchSubjects(
+	subjects *[]Subject, _type, keyword string, limit, offset int64,
+) error {
+	condition, args := searchCondition(_type, keyword)
+	query := `SELECT
+		pk,
+		type,
+		id,
+		name
+		FROM subject
+		WHERE ` + condition + `
+		LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+	return database.SqlxSelect(m.DB, subjects, query, args...)
+}
+
+func (m *subjectManager) selectFrozenPKs(pks *[]int64) error {
+	query := `SELECT
+		pk
+		FROM subject
+		WHERE frozen = 1`
+	return database.SqlxSelect(m.DB, pks, query)
+}
+
 func (m *subjectManager) bulkInsert(subjects []Subject) error {
 	sql := "INSERT INTO subject (type, id, name) VALUES (:type, :id, :name)"
 	return database.SqlxBulkInsert(m.DB, sql, subjects)
@@ -216,3 +302,8 @@ func (m *subjectManager) bulkUpdate(subjects []Subject) error {
 	sql := "UPDATE subject SET name=:name WHERE type=:type AND id=:id"
 	return database.SqlxBulkUpdate(m.DB, sql, subjects)
 }
+
+func (m *subjectManager) updateFrozenByPKsWithTx(tx *sqlx.Tx, pks []int64, frozen bool) (int64, error) {
+	sql := `UPDATE subject SET frozen = ? WHERE pk IN (?)`
+	return database.SqlxExecReturnRowsWithTx(tx, sql, frozen, pks)
+}
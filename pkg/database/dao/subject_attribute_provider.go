@@ -0,0 +1,118 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package dao
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"iam/pkg/database"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+// SubjectAttributeProvider 外部的subject属性提供方
+type SubjectAttributeProvider struct {
+	PK         int64     `db:"pk"`
+	ID         string    `db:"id"`
+	Type       string    `db:"type"`
+	Attributes string    `db:"attributes"` // json list
+	Config     string    `db:"config"`     // json
+	CacheTTL   int64     `db:"cache_ttl"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+// SubjectAttributeProviderManager ...
+type SubjectAttributeProviderManager interface {
+	ListAll() ([]SubjectAttributeProvider, error)
+	Get(id string) (SubjectAttributeProvider, error)
+	Create(provider SubjectAttributeProvider) error
+	Update(provider SubjectAttributeProvider) error
+	Delete(id string) error
+}
+
+type subjectAttributeProviderManager struct {
+	DB *sqlx.DB
+}
+
+// NewSubjectAttributeProviderManager ...
+func NewSubjectAttributeProviderManager() SubjectAttributeProviderManager {
+	return &subjectAttributeProviderManager{
+		DB: database.GetDefaultDBClient().DB,
+	}
+}
+
+// ListAll ...
+func (m *subjectAttributeProviderManager) ListAll() (providers []SubjectAttributeProvider, err error) {
+	query := `SELECT
+		pk,
+		id,
+		type,
+		attributes,
+		config,
+		cache_ttl,
+		created_at,
+		updated_at
+		FROM subject_attribute_provider
+		ORDER BY pk`
+	err = database.SqlxSelect(m.DB, &providers, query)
+	return
+}
+
+// Get ...
+func (m *subjectAttributeProviderManager) Get(id string) (provider SubjectAttributeProvider, err error) {
+	query := `SELECT
+		pk,
+		id,
+		type,
+		attributes,
+		config,
+		cache_ttl,
+		created_at,
+		updated_at
+		FROM subject_attribute_provider
+		WHERE id = ?
+		LIMIT 1`
+	err = database.SqlxGet(m.DB, &provider, query, id)
+	return
+}
+
+// Create ...
+func (m *subjectAttributeProviderManager) Create(provider SubjectAttributeProvider) error {
+	query := `INSERT INTO subject_attribute_provider (
+		id,
+		type,
+		attributes,
+		config,
+		cache_ttl
+	) VALUES (:id, :type, :attributes, :config, :cache_ttl)`
+	return database.SqlxBulkInsert(m.DB, query, []SubjectAttributeProvider{provider})
+}
+
+// Update ...
+func (m *subjectAttributeProviderManager) Update(provider SubjectAttributeProvider) error {
+	query := `UPDATE subject_attribute_provider
+		SET type = :type,
+		attributes = :attributes,
+		config = :config,
+		cache_ttl = :cache_ttl
+		WHERE id = :id`
+	_, err := database.SqlxUpdate(m.DB, query, provider)
+	return err
+}
+
+// Delete ...
+func (m *subjectAttributeProviderManager) Delete(id string) error {
+	query := `DELETE FROM subject_attribute_provider WHERE id = ?`
+	_, err := database.SqlxDelete(m.DB, query, id)
+	return err
+}
@@ -0,0 +1,118 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package dao
+
+import (
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+)
+
+func Test_subjectAttributeProviderManager_ListAll(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Now()
+		mockQuery := `^SELECT pk, id, type, attributes, config, cache_ttl, created_at, updated_at ` +
+			`FROM subject_attribute_provider ORDER BY pk`
+		mockRows := sqlmock.NewRows([]string{
+			"pk", "id", "type", "attributes", "config", "cache_ttl", "created_at", "updated_at",
+		}).AddRow(int64(1), "hr", "http", `["job_level"]`, `{"url":"http://hr"}`, int64(60), now, now)
+		mock.ExpectQuery(mockQuery).WillReturnRows(mockRows)
+
+		manager := &subjectAttributeProviderManager{DB: db}
+		providers, err := manager.ListAll()
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []SubjectAttributeProvider{{
+			PK:         1,
+			ID:         "hr",
+			Type:       "http",
+			Attributes: `["job_level"]`,
+			Config:     `{"url":"http://hr"}`,
+			CacheTTL:   60,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}}, providers)
+	})
+}
+
+func Test_subjectAttributeProviderManager_Get(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Now()
+		mockQuery := `^SELECT pk, id, type, attributes, config, cache_ttl, created_at, updated_at ` +
+			`FROM subject_attribute_provider WHERE id = (.*) LIMIT 1`
+		mockRows := sqlmock.NewRows([]string{
+			"pk", "id", "type", "attributes", "config", "cache_ttl", "created_at", "updated_at",
+		}).AddRow(int64(1), "cost", "static", `["cost_center"]`, `{}`, int64(0), now, now)
+		mock.ExpectQuery(mockQuery).WithArgs("cost").WillReturnRows(mockRows)
+
+		manager := &subjectAttributeProviderManager{DB: db}
+		provider, err := manager.Get("cost")
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, "static", provider.Type)
+		assert.Equal(t, `["cost_center"]`, provider.Attributes)
+	})
+}
+
+func Test_subjectAttributeProviderManager_Create(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectExec(`^INSERT INTO subject_attribute_provider`).
+			WithArgs("hr", "http", `["job_level"]`, `{"url":"http://hr"}`, int64(60)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		manager := &subjectAttributeProviderManager{DB: db}
+		err := manager.Create(SubjectAttributeProvider{
+			ID:         "hr",
+			Type:       "http",
+			Attributes: `["job_level"]`,
+			Config:     `{"url":"http://hr"}`,
+			CacheTTL:   60,
+		})
+
+		assert.NoError(t, err)
+	})
+}
+
+func Test_subjectAttributeProviderManager_Update(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectExec(`^UPDATE subject_attribute_provider SET`).
+			WithArgs("http", `["job_level"]`, `{"url":"http://hr"}`, int64(120), "hr").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		manager := &subjectAttributeProviderManager{DB: db}
+		err := manager.Update(SubjectAttributeProvider{
+			ID:         "hr",
+			Type:       "http",
+			Attributes: `["job_level"]`,
+			Config:     `{"url":"http://hr"}`,
+			CacheTTL:   120,
+		})
+
+		assert.NoError(t, err)
+	})
+}
+
+func Test_subjectAttributeProviderManager_Delete(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectExec(`^DELETE FROM subject_attribute_provider WHERE id = (.*)`).
+			WithArgs("hr").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		manager := &subjectAttributeProviderManager{DB: db}
+		err := manager.Delete("hr")
+
+		assert.NoError(t, err)
+	})
+}
@@ -15,6 +15,8 @@ package dao
 import (
 	"database/sql"
 	"errors"
+	"strconv"
+	"strings"
 
 	"iam/pkg/database"
 
@@ -28,15 +30,26 @@ type SubjectDepartment struct {
 	DepartmentPKs string `db:"department_pks"`
 }
 
+// departmentMember 部门的直接成员, 由department_pks拆分得到, 用于按部门查询subject时走索引
+type departmentMember struct {
+	DepartmentPK int64 `db:"department_pk"`
+	SubjectPK    int64 `db:"subject_pk"`
+}
+
 // SubjectDepartmentManager ...
 type SubjectDepartmentManager interface {
 	Get(subjectPK int64) (string, error)
 	GetCount() (int64, error)
 	ListPaging(limit, offset int64) ([]SubjectDepartment, error)
+	ListBySubjectPKs(subjectPKs []int64) ([]SubjectDepartment, error)
+	ListByDepartmentPKs(departmentPKs []int64) ([]SubjectDepartment, error)
+	ListSubjectPKsAfterPKByDepartmentPKs(departmentPKs []int64, afterPK, limit int64) ([]int64, error)
 
 	BulkCreate(subjectDepartments []SubjectDepartment) error
 	BulkUpdate(subjectDepartments []SubjectDepartment) error
 	BulkDelete(subjectPKs []int64) error
+	BulkCreateWithTx(tx *sqlx.Tx, subjectDepartments []SubjectDepartment) error
+	BulkUpdateWithTx(tx *sqlx.Tx, subjectDepartments []SubjectDepartment) error
 	BulkDeleteWithTx(tx *sqlx.Tx, subjectPKs []int64) error
 }
 
@@ -66,12 +79,34 @@ func (m *subjectDepartmentManger) GetCount() (count int64, err error) {
 	return
 }
 
+// NOTE: subject_department的变更需要同步维护department_member, 所以不带事务的变更也会开启事务
+
 // BulkCreate ...
 func (m *subjectDepartmentManger) BulkCreate(subjectDepartments []SubjectDepartment) error {
 	if len(subjectDepartments) == 0 {
 		return nil
 	}
-	return m.bulkInsert(subjectDepartments)
+	return m.withTx(func(tx *sqlx.Tx) error {
+		return m.BulkCreateWithTx(tx, subjectDepartments)
+	})
+}
+
+// BulkCreateWithTx ...
+func (m *subjectDepartmentManger) BulkCreateWithTx(tx *sqlx.Tx, subjectDepartments []SubjectDepartment) error {
+	if len(subjectDepartments) == 0 {
+		return nil
+	}
+
+	members, err := splitDepartmentMembers(subjectDepartments)
+	if err != nil {
+		return err
+	}
+
+	err = m.bulkInsertWithTx(tx, subjectDepartments)
+	if err != nil {
+		return err
+	}
+	return m.bulkInsertMemberWithTx(tx, members)
 }
 
 // BulkDelete ...
@@ -79,7 +114,9 @@ func (m *subjectDepartmentManger) BulkDelete(subjectPKs []int64) error {
 	if len(subjectPKs) == 0 {
 		return nil
 	}
-	return m.bulkDelete(subjectPKs)
+	return m.withTx(func(tx *sqlx.Tx) error {
+		return m.BulkDeleteWithTx(tx, subjectPKs)
+	})
 }
 
 // BulkDeleteWithTx ...
@@ -87,7 +124,12 @@ func (m *subjectDepartmentManger) BulkDeleteWithTx(tx *sqlx.Tx, subjectPKs []int
 	if len(subjectPKs) == 0 {
 		return nil
 	}
-	return m.bulkDeleteWithTx(tx, subjectPKs)
+
+	err := m.bulkDeleteWithTx(tx, subjectPKs)
+	if err != nil {
+		return err
+	}
+	return m.bulkDeleteMemberWithTx(tx, subjectPKs)
 }
 
 // BulkUpdate ...
@@ -95,7 +137,76 @@ func (m *subjectDepartmentManger) BulkUpdate(subjectDepartments []SubjectDepartm
 	if len(subjectDepartments) == 0 {
 		return nil
 	}
-	return m.bulkUpdate(subjectDepartments)
+	return m.withTx(func(tx *sqlx.Tx) error {
+		return m.BulkUpdateWithTx(tx, subjectDepartments)
+	})
+}
+
+// BulkUpdateWithTx 更新部门后, 重建subject的department_member
+func (m *subjectDepartmentManger) BulkUpdateWithTx(tx *sqlx.Tx, subjectDepartments []SubjectDepartment) error {
+	if len(subjectDepartments) == 0 {
+		return nil
+	}
+
+	members, err := splitDepartmentMembers(subjectDepartments)
+	if err != nil {
+		return err
+	}
+
+	err = m.bulkUpdateWithTx(tx, subjectDepartments)
+	if err != nil {
+		return err
+	}
+
+	subjectPKs := make([]int64, 0, len(subjectDepartments))
+	for _, sd := range subjectDepartments {
+		subjectPKs = append(subjectPKs, sd.SubjectPK)
+	}
+	err = m.bulkDeleteMemberWithTx(tx, subjectPKs)
+	if err != nil {
+		return err
+	}
+	return m.bulkInsertMemberWithTx(tx, members)
+}
+
+// ListBySubjectPKs ...
+func (m *subjectDepartmentManger) ListBySubjectPKs(subjectPKs []int64) (subjectDepartments []SubjectDepartment, err error) {
+	if len(subjectPKs) == 0 {
+		return
+	}
+	err = m.selectBySubjectPKs(&subjectDepartments, subjectPKs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return subjectDepartments, nil
+	}
+	return
+}
+
+// ListByDepartmentPKs 查询直接属于任一部门的subject, 通过department_member关联查询
+func (m *subjectDepartmentManger) ListByDepartmentPKs(
+	departmentPKs []int64,
+) (subjectDepartments []SubjectDepartment, err error) {
+	if len(departmentPKs) == 0 {
+		return
+	}
+	err = m.selectByDepartmentPKs(&subjectDepartments, departmentPKs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return subjectDepartments, nil
+	}
+	return
+}
+
+// ListSubjectPKsAfterPKByDepartmentPKs 查询直接属于任一部门且pk大于afterPK的subject pk, 按pk升序
+func (m *subjectDepartmentManger) ListSubjectPKsAfterPKByDepartmentPKs(
+	departmentPKs []int64, afterPK, limit int64,
+) (subjectPKs []int64, err error) {
+	if len(departmentPKs) == 0 {
+		return
+	}
+	err = m.selectSubjectPKsAfterPKByDepartmentPKs(&subjectPKs, departmentPKs, afterPK, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return subjectPKs, nil
+	}
+	return
 }
 
 // ListPaging ...
@@ -108,6 +219,45 @@ func (m *subjectDepartmentManger) ListPaging(limit, offset int64) ([]SubjectDepa
 	return subjectDepartments, err
 }
 
+func (m *subjectDepartmentManger) withTx(f func(tx *sqlx.Tx) error) error {
+	tx, err := m.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer database.RollBackWithLog(tx)
+
+	err = f(tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// splitDepartmentMembers 拆分逗号分隔的department_pks
+func splitDepartmentMembers(subjectDepartments []SubjectDepartment) ([]departmentMember, error) {
+	members := make([]departmentMember, 0, len(subjectDepartments))
+	for _, sd := range subjectDepartments {
+		if sd.DepartmentPKs == "" {
+			continue
+		}
+
+		seen := make(map[int64]struct{})
+		for _, s := range strings.Split(sd.DepartmentPKs, ",") {
+			pk, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := seen[pk]; ok {
+				continue
+			}
+			seen[pk] = struct{}{}
+
+			members = append(members, departmentMember{DepartmentPK: pk, SubjectPK: sd.SubjectPK})
+		}
+	}
+	return members, nil
+}
+
 func (m *subjectDepartmentManger) getDepartmentPKs(departmentPKs *string, subjectPK int64) error {
 	query := `SELECT
 		department_pks
@@ -123,32 +273,79 @@ func (m *subjectDepartmentManger) getCount(count *int64) error {
 	return database.SqlxGet(m.DB, count, query)
 }
 
-func (m *subjectDepartmentManger) bulkInsert(subjectDepartments []SubjectDepartment) error {
+func (m *subjectDepartmentManger) bulkInsertWithTx(tx *sqlx.Tx, subjectDepartments []SubjectDepartment) error {
 	sql := `INSERT INTO subject_department (
 		subject_pk,
 		department_pks
 	) VALUES (
 		:subject_pk,
 		:department_pks)`
-	return database.SqlxBulkInsert(m.DB, sql, subjectDepartments)
+	return database.SqlxBulkInsertWithTx(tx, sql, subjectDepartments)
 }
 
-func (m *subjectDepartmentManger) bulkDelete(subjectPKs []int64) error {
+func (m *subjectDepartmentManger) bulkDeleteWithTx(tx *sqlx.Tx, subjectPKs []int64) error {
 	sql := `DELETE FROM subject_department WHERE subject_pk in (?)`
-	_, err := database.SqlxDelete(m.DB, sql, subjectPKs)
-	return err
+	return database.SqlxDeleteWithTx(tx, sql, subjectPKs)
 }
 
-func (m *subjectDepartmentManger) bulkDeleteWithTx(tx *sqlx.Tx, subjectPKs []int64) error {
-	sql := `DELETE FROM subject_department WHERE subject_pk in (?)`
+func (m *subjectDepartmentManger) bulkInsertMemberWithTx(tx *sqlx.Tx, members []departmentMember) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	sql := `INSERT INTO department_member (
+		department_pk,
+		subject_pk
+	) VALUES (
+		:department_pk,
+		:subject_pk)`
+	return database.SqlxBulkInsertWithTx(tx, sql, members)
+}
+
+func (m *subjectDepartmentManger) bulkDeleteMemberWithTx(tx *sqlx.Tx, subjectPKs []int64) error {
+	sql := `DELETE FROM department_member WHERE subject_pk in (?)`
 	return database.SqlxDeleteWithTx(tx, sql, subjectPKs)
 }
 
-func (m *subjectDepartmentManger) bulkUpdate(subjectDepartments []SubjectDepartment) error {
+func (m *subjectDepartmentManger) bulkUpdateWithTx(tx *sqlx.Tx, subjectDepartments []SubjectDepartment) error {
 	sql := `UPDATE subject_department
 		SET department_pks=:department_pks
 		WHERE subject_pk=:subject_pk`
-	return database.SqlxBulkUpdate(m.DB, sql, subjectDepartments)
+	return database.SqlxBulkUpdateWithTx(tx, sql, subjectDepartments)
+}
+
+func (m *subjectDepartmentManger) selectBySubjectPKs(subjectDepartments *[]SubjectDepartment, subjectPKs []int64) error {
+	query := `SELECT
+		subject_pk,
+		department_pks
+		FROM subject_department
+		WHERE subject_pk IN (?)`
+	return database.SqlxSelect(m.DB, subjectDepartments, query, subjectPKs)
+}
+
+func (m *subjectDepartmentManger) selectByDepartmentPKs(
+	subjectDepartments *[]SubjectDepartment, departmentPKs []int64,
+) error {
+	query := `SELECT
+		DISTINCT sd.subject_pk,
+		sd.department_pks
+		FROM department_member dm
+		JOIN subject_department sd ON sd.subject_pk = dm.subject_pk
+		WHERE dm.department_pk IN (?)`
+	return database.SqlxSelect(m.DB, subjectDepartments, query, departmentPKs)
+}
+
+func (m *subjectDepartmentManger) selectSubjectPKsAfterPKByDepartmentPKs(
+	subjectPKs *[]int64, departmentPKs []int64, afterPK, limit int64,
+) error {
+	query := `SELECT
+		DISTINCT subject_pk
+		FROM department_member
+		WHERE department_pk IN (?)
+		AND subject_pk > ?
+		ORDER BY subject_pk
+		LIMIT ?`
+	return database.SqlxSelect(m.DB, subjectPKs, query, departmentPKs, afterPK, limit)
 }
 
 func (m *subjectDepartmentManger) selectPaging(subjectDepartments *[]SubjectDepartment, limit, offset int64) error {
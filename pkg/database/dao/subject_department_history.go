@@ -0,0 +1,116 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dao
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"iam/pkg/database"
+)
+
+// SubjectDepartmentHistory 用户部门关系的变更记录
+type SubjectDepartmentHistory struct {
+	PK           int64     `db:"pk"`
+	SubjectPK    int64     `db:"subject_pk"`
+	DepartmentPK int64     `db:"department_pk"`
+	Action       string    `db:"action"` // added / removed
+	Source       string    `db:"source"` // 发起变更的来源, 如调用方的app_code
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// SubjectDepartmentHistoryManager ...
+type SubjectDepartmentHistoryManager interface {
+	GetCountBySubjectPK(subjectPK int64) (int64, error)
+	ListPagingBySubjectPK(subjectPK int64, limit, offset int64) ([]SubjectDepartmentHistory, error)
+
+	BulkCreateWithTx(tx *sqlx.Tx, histories []SubjectDepartmentHistory) error
+}
+
+type subjectDepartmentHistoryManager struct {
+	DB *sqlx.DB
+}
+
+// NewSubjectDepartmentHistoryManager ...
+func NewSubjectDepartmentHistoryManager() SubjectDepartmentHistoryManager {
+	return &subjectDepartmentHistoryManager{
+		DB: database.GetDefaultDBClient().DB,
+	}
+}
+
+// GetCountBySubjectPK ...
+func (m *subjectDepartmentHistoryManager) GetCountBySubjectPK(subjectPK int64) (count int64, err error) {
+	err = m.getCountBySubjectPK(&count, subjectPK)
+	return
+}
+
+// ListPagingBySubjectPK 按时间倒序查询subject的部门变更记录
+func (m *subjectDepartmentHistoryManager) ListPagingBySubjectPK(
+	subjectPK int64, limit, offset int64,
+) (histories []SubjectDepartmentHistory, err error) {
+	err = m.selectPagingBySubjectPK(&histories, subjectPK, limit, offset)
+	if errors.Is(err, sql.ErrNoRows) {
+		return histories, nil
+	}
+	return
+}
+
+// BulkCreateWithTx ...
+func (m *subjectDepartmentHistoryManager) BulkCreateWithTx(tx *sqlx.Tx, histories []SubjectDepartmentHistory) error {
+	if len(histories) == 0 {
+		return nil
+	}
+	return m.bulkInsertWithTx(tx, histories)
+}
+
+func (m *subjectDepartmentHistoryManager) getCountBySubjectPK(count *int64, subjectPK int64) error {
+	query := `SELECT
+		COUNT(*)
+		FROM subject_department_history
+		WHERE subject_pk = ?`
+	return database.SqlxGet(m.DB, count, query, subjectPK)
+}
+
+func (m *subjectDepartmentHistoryManager) selectPagingBySubjectPK(
+	histories *[]SubjectDepartmentHistory, subjectPK int64, limit, offset int64,
+) error {
+	query := `SELECT
+		pk,
+		subject_pk,
+		department_pk,
+		action,
+		source,
+		created_at
+		FROM subject_department_history
+		WHERE subject_pk = ?
+		ORDER BY pk DESC
+		LIMIT ? OFFSET ?`
+	return database.SqlxSelect(m.DB, histories, query, subjectPK, limit, offset)
+}
+
+func (m *subjectDepartmentHistoryManager) bulkInsertWithTx(tx *sqlx.Tx, histories []SubjectDepartmentHistory) error {
+	sql := `INSERT INTO subject_department_history (
+		subject_pk,
+		department_pk,
+		action,
+		source
+	) VALUES (
+		:subject_pk,
+		:department_pk,
+		:action,
+		:source)`
+	return database.SqlxBulkInsertWithTx(tx, sql, histories)
+}
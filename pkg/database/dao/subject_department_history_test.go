@@ -0,0 +1,86 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dao
+
+import (
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+)
+
+func Test_subjectDepartmentHistoryManager_GetCountBySubjectPK(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT COUNT\(\*\) FROM subject_department_history WHERE subject_pk = ?`
+		mockRows := sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(int64(2))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1)).WillReturnRows(mockRows)
+
+		manager := &subjectDepartmentHistoryManager{DB: db}
+		cnt, err := manager.GetCountBySubjectPK(int64(1))
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, int64(2), cnt)
+	})
+}
+
+func Test_subjectDepartmentHistoryManager_ListPagingBySubjectPK(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Now()
+		mockQuery := `^SELECT pk, subject_pk, department_pk, action, source, created_at ` +
+			`FROM subject_department_history WHERE subject_pk = (.*) ORDER BY pk DESC LIMIT (.*) OFFSET (.*)`
+		mockRows := sqlmock.NewRows([]string{
+			"pk", "subject_pk", "department_pk", "action", "source", "created_at",
+		}).AddRow(int64(2), int64(1), int64(10), "removed", "bk_iam", now)
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(10), int64(0)).WillReturnRows(mockRows)
+
+		manager := &subjectDepartmentHistoryManager{DB: db}
+		histories, err := manager.ListPagingBySubjectPK(int64(1), int64(10), int64(0))
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []SubjectDepartmentHistory{{
+			PK:           2,
+			SubjectPK:    1,
+			DepartmentPK: 10,
+			Action:       "removed",
+			Source:       "bk_iam",
+			CreatedAt:    now,
+		}}, histories)
+	})
+}
+
+func Test_subjectDepartmentHistoryManager_BulkCreateWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`^INSERT INTO subject_department_history`).
+			WithArgs(int64(1), int64(10), "added", "bk_iam").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &subjectDepartmentHistoryManager{DB: db}
+		err = manager.BulkCreateWithTx(tx, []SubjectDepartmentHistory{{
+			SubjectPK:    1,
+			DepartmentPK: 10,
+			Action:       "added",
+			Source:       "bk_iam",
+		}})
+		assert.NoError(t, err)
+
+		err = tx.Commit()
+		assert.NoError(t, err)
+	})
+}
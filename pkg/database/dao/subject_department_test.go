@@ -49,13 +49,19 @@ func Test_subjectDepartmentManger_GetCount(t *testing.T) {
 
 func Test_subjectDepartmentManger_BulkCreate(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
-		mockQuery := `^INSERT INTO subject_department`
-		mock.ExpectExec(mockQuery).WithArgs(int64(1), "1").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectBegin()
+		mock.ExpectExec(`^INSERT INTO subject_department`).WithArgs(
+			int64(1), "1,2,1",
+		).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`^INSERT INTO department_member`).WithArgs(
+			int64(1), int64(1), int64(2), int64(1),
+		).WillReturnResult(sqlmock.NewResult(1, 2))
+		mock.ExpectCommit()
 
 		manager := &subjectDepartmentManger{DB: db}
 		err := manager.BulkCreate([]SubjectDepartment{{
 			SubjectPK:     int64(1),
-			DepartmentPKs: "1",
+			DepartmentPKs: "1,2,1",
 		}})
 
 		assert.NoError(t, err, "query from db fail.")
@@ -64,8 +70,14 @@ func Test_subjectDepartmentManger_BulkCreate(t *testing.T) {
 
 func Test_subjectDepartmentManger_BulkDelete(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
-		mockQuery := `^DELETE FROM subject_department`
-		mock.ExpectExec(mockQuery).WithArgs(int64(1), int64(2)).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectBegin()
+		mock.ExpectExec(`^DELETE FROM subject_department`).WithArgs(
+			int64(1), int64(2),
+		).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`^DELETE FROM department_member`).WithArgs(
+			int64(1), int64(2),
+		).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
 		manager := &subjectDepartmentManger{DB: db}
 		err := manager.BulkDelete([]int64{1, 2})
@@ -80,6 +92,10 @@ func Test_subjectDepartmentManger_BulkUpdate(t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectPrepare(mockQuery)
 		mock.ExpectExec(mockQuery).WithArgs("1", int64(1)).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`^DELETE FROM department_member`).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`^INSERT INTO department_member`).WithArgs(
+			int64(1), int64(1),
+		).WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
 		manager := &subjectDepartmentManger{DB: db}
@@ -106,3 +122,52 @@ func Test_subjectDepartmentManger_ListPaging(t *testing.T) {
 		assert.Len(t, subjectDepartments, 2)
 	})
 }
+
+func Test_subjectDepartmentManger_ListByDepartmentPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT DISTINCT sd.subject_pk, sd.department_pks FROM department_member dm ` +
+			`JOIN subject_department sd ON sd.subject_pk = dm.subject_pk WHERE dm.department_pk IN (.*)`
+		mockRows := sqlmock.NewRows([]string{"subject_pk", "department_pks"}).AddRow(
+			int64(1), "1,3").AddRow(int64(2), "2")
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(2)).WillReturnRows(mockRows)
+
+		manager := &subjectDepartmentManger{DB: db}
+		subjectDepartments, err := manager.ListByDepartmentPKs([]int64{1, 2})
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []SubjectDepartment{
+			{SubjectPK: 1, DepartmentPKs: "1,3"},
+			{SubjectPK: 2, DepartmentPKs: "2"},
+		}, subjectDepartments)
+	})
+}
+
+func Test_subjectDepartmentManger_ListSubjectPKsAfterPKByDepartmentPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT DISTINCT subject_pk FROM department_member WHERE department_pk IN (.*) ` +
+			`AND subject_pk > (.*) ORDER BY subject_pk LIMIT`
+		mockRows := sqlmock.NewRows([]string{"subject_pk"}).AddRow(int64(3)).AddRow(int64(5))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(2), int64(2), int64(10)).WillReturnRows(mockRows)
+
+		manager := &subjectDepartmentManger{DB: db}
+		subjectPKs, err := manager.ListSubjectPKsAfterPKByDepartmentPKs([]int64{1, 2}, 2, 10)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []int64{3, 5}, subjectPKs)
+	})
+}
+
+func Test_splitDepartmentMembers(t *testing.T) {
+	members, err := splitDepartmentMembers([]SubjectDepartment{
+		{SubjectPK: 1, DepartmentPKs: "1,2,1"},
+		{SubjectPK: 2, DepartmentPKs: ""},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []departmentMember{
+		{DepartmentPK: 1, SubjectPK: 1},
+		{DepartmentPK: 2, SubjectPK: 1},
+	}, members)
+
+	_, err = splitDepartmentMembers([]SubjectDepartment{{SubjectPK: 1, DepartmentPKs: "a"}})
+	assert.Error(t, err)
+}
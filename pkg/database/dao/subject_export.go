@@ -0,0 +1,115 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dao
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"iam/pkg/database"
+)
+
+// SubjectExportManager provide the database query for exporting the subject relations
+// NOTE: 按pk游标分批查询全表, 使用批量任务的连接池
+type SubjectExportManager interface {
+	ListSubjectAfterPK(afterPK, limit int64) ([]Subject, error)
+	ListRelationAfterPK(afterPK, limit int64) ([]SubjectRelation, error)
+	ListSubjectDepartmentAfterPK(afterPK, limit int64) ([]SubjectDepartment, error)
+}
+
+type subjectExportManager struct {
+	DB *sqlx.DB
+}
+
+// NewSubjectExportManager create SubjectExportManager
+func NewSubjectExportManager() SubjectExportManager {
+	return &subjectExportManager{
+		DB: database.GetBatchDBClient().DB,
+	}
+}
+
+// ListSubjectAfterPK 查询pk大于afterPK的subject, 按pk升序
+func (m *subjectExportManager) ListSubjectAfterPK(afterPK, limit int64) (subjects []Subject, err error) {
+	err = m.selectSubjectAfterPK(&subjects, afterPK, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return subjects, nil
+	}
+	return
+}
+
+// ListRelationAfterPK 查询pk大于afterPK的subject relation, 按pk升序
+func (m *subjectExportManager) ListRelationAfterPK(afterPK, limit int64) (relations []SubjectRelation, err error) {
+	err = m.selectRelationAfterPK(&relations, afterPK, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return relations, nil
+	}
+	return
+}
+
+// ListSubjectDepartmentAfterPK 查询pk大于afterPK的subject department, 按pk升序
+func (m *subjectExportManager) ListSubjectDepartmentAfterPK(
+	afterPK, limit int64,
+) (subjectDepartments []SubjectDepartment, err error) {
+	err = m.selectSubjectDepartmentAfterPK(&subjectDepartments, afterPK, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return subjectDepartments, nil
+	}
+	return
+}
+
+func (m *subjectExportManager) selectSubjectAfterPK(subjects *[]Subject, afterPK, limit int64) error {
+	query := `SELECT
+		pk,
+		type,
+		id,
+		name
+		FROM subject
+		WHERE pk > ?
+		ORDER BY pk ASC
+		LIMIT ?`
+	return database.SqlxSelect(m.DB, subjects, query, afterPK, limit)
+}
+
+func (m *subjectExportManager) selectRelationAfterPK(relations *[]SubjectRelation, afterPK, limit int64) error {
+	query := `SELECT
+		pk,
+		subject_pk,
+		subject_type,
+		subject_id,
+		parent_pk,
+		parent_type,
+		parent_id,
+		policy_expired_at,
+		created_at
+		FROM subject_relation
+		WHERE pk > ?
+		ORDER BY pk ASC
+		LIMIT ?`
+	return database.SqlxSelect(m.DB, relations, query, afterPK, limit)
+}
+
+func (m *subjectExportManager) selectSubjectDepartmentAfterPK(
+	subjectDepartments *[]SubjectDepartment, afterPK, limit int64,
+) error {
+	query := `SELECT
+		pk,
+		subject_pk,
+		department_pks
+		FROM subject_department
+		WHERE pk > ?
+		ORDER BY pk ASC
+		LIMIT ?`
+	return database.SqlxSelect(m.DB, subjectDepartments, query, afterPK, limit)
+}
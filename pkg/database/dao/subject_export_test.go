@@ -0,0 +1,76 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+)
+
+func Test_subjectExportManager_ListSubjectAfterPK(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockRows := sqlmock.NewRows([]string{"pk", "type", "id", "name"}).
+			AddRow(int64(11), "user", "admin", "Admin").
+			AddRow(int64(12), "group", "1", "g1")
+		mock.ExpectQuery(
+			`^SELECT (.*) FROM subject WHERE pk > (.*) ORDER BY pk ASC LIMIT`,
+		).WithArgs(int64(10), int64(2)).WillReturnRows(mockRows)
+
+		manager := &subjectExportManager{DB: db}
+		subjects, err := manager.ListSubjectAfterPK(int64(10), int64(2))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []Subject{
+			{PK: 11, Type: "user", ID: "admin", Name: "Admin"},
+			{PK: 12, Type: "group", ID: "1", Name: "g1"},
+		}, subjects)
+	})
+}
+
+func Test_subjectExportManager_ListRelationAfterPK(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockRows := sqlmock.NewRows([]string{
+			"pk", "subject_pk", "subject_type", "subject_id", "parent_pk", "parent_type", "parent_id", "policy_expired_at",
+		}).AddRow(int64(1), int64(11), "user", "admin", int64(12), "group", "1", int64(4102444800))
+		mock.ExpectQuery(
+			`^SELECT (.*) FROM subject_relation WHERE pk > (.*) ORDER BY pk ASC LIMIT`,
+		).WithArgs(int64(0), int64(10)).WillReturnRows(mockRows)
+
+		manager := &subjectExportManager{DB: db}
+		relations, err := manager.ListRelationAfterPK(int64(0), int64(10))
+
+		assert.NoError(t, err)
+		assert.Len(t, relations, 1)
+		assert.Equal(t, "admin", relations[0].SubjectID)
+		assert.Equal(t, int64(12), relations[0].ParentPK)
+	})
+}
+
+func Test_subjectExportManager_ListSubjectDepartmentAfterPK(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockRows := sqlmock.NewRows([]string{"pk", "subject_pk", "department_pks"}).
+			AddRow(int64(1), int64(11), "2,3")
+		mock.ExpectQuery(
+			`^SELECT (.*) FROM subject_department WHERE pk > (.*) ORDER BY pk ASC LIMIT`,
+		).WithArgs(int64(0), int64(10)).WillReturnRows(mockRows)
+
+		manager := &subjectExportManager{DB: db}
+		subjectDepartments, err := manager.ListSubjectDepartmentAfterPK(int64(0), int64(10))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []SubjectDepartment{{PK: 1, SubjectPK: 11, DepartmentPKs: "2,3"}}, subjectDepartments)
+	})
+}
@@ -0,0 +1,175 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dao
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"iam/pkg/database"
+)
+
+// SubjectMemberEvent 用户组成员的变更记录, CreatedAt即变更的生效时间
+type SubjectMemberEvent struct {
+	PK              int64     `db:"pk"`
+	GroupType       string    `db:"group_type"`
+	GroupID         string    `db:"group_id"`
+	MemberType      string    `db:"member_type"`
+	MemberID        string    `db:"member_id"`
+	Action          string    `db:"action"` // added / removed / renewed
+	PolicyExpiredAt int64     `db:"policy_expired_at"`
+	Operator        string    `db:"operator"` // 操作人
+	Source          string    `db:"source"`   // 发起变更的来源, 如调用方的app_code
+	CreatedAt       time.Time `db:"created_at"`
+}
+
+// SubjectMemberEventFilter 查询变更记录的过滤条件, 空值表示不过滤; 时间范围为[StartTime, EndTime)
+type SubjectMemberEventFilter struct {
+	GroupType string
+	GroupID   string
+	Operator  string
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// SubjectMemberEventManager ...
+type SubjectMemberEventManager interface {
+	GetCount(filter SubjectMemberEventFilter) (int64, error)
+	ListPaging(filter SubjectMemberEventFilter, limit, offset int64) ([]SubjectMemberEvent, error)
+
+	BulkCreate(events []SubjectMemberEvent) error
+}
+
+type subjectMemberEventManager struct {
+	DB *sqlx.DB
+}
+
+// NewSubjectMemberEventManager ...
+func NewSubjectMemberEventManager() SubjectMemberEventManager {
+	return &subjectMemberEventManager{
+		DB: database.GetDefaultDBClient().DB,
+	}
+}
+
+// GetCount ...
+func (m *subjectMemberEventManager) GetCount(filter SubjectMemberEventFilter) (count int64, err error) {
+	err = m.getCount(&count, filter)
+	return
+}
+
+// ListPaging 按时间倒序查询成员的变更记录
+func (m *subjectMemberEventManager) ListPaging(
+	filter SubjectMemberEventFilter, limit, offset int64,
+) (events []SubjectMemberEvent, err error) {
+	err = m.selectPaging(&events, filter, limit, offset)
+	if errors.Is(err, sql.ErrNoRows) {
+		return events, nil
+	}
+	return
+}
+
+// BulkCreate ...
+func (m *subjectMemberEventManager) BulkCreate(events []SubjectMemberEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return m.bulkInsert(events)
+}
+
+func memberEventFilterCondition(filter SubjectMemberEventFilter) (string, []interface{}) {
+	conditions := make([]string, 0, 5)
+	args := make([]interface{}, 0, 5)
+	if filter.GroupType != "" {
+		conditions = append(conditions, "group_type = ?")
+		args = append(args, filter.GroupType)
+	}
+	if filter.GroupID != "" {
+		conditions = append(conditions, "group_id = ?")
+		args = append(args, filter.GroupID)
+	}
+	if filter.Operator != "" {
+		conditions = append(conditions, "operator = ?")
+		args = append(args, filter.Operator)
+	}
+	if !filter.StartTime.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.EndTime)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func (m *subjectMemberEventManager) getCount(count *int64, filter SubjectMemberEventFilter) error {
+	condition, args := memberEventFilterCondition(filter)
+	query := `SELECT
+		COUNT(*)
+		FROM subject_member_event` + condition
+	return database.SqlxGet(m.DB, count, query, args...)
+}
+
+func (m *subjectMemberEventManager) selectPaging(
+	events *[]SubjectMemberEvent, filter SubjectMemberEventFilter, limit, offset int64,
+) error {
+	condition, args := memberEventFilterCondition(filter)
+	query := `SELECT
+		pk,
+		group_type,
+		group_id,
+		member_type,
+		member_id,
+		action,
+		policy_expired_at,
+		operator,
+		source,
+		created_at
+		FROM subject_member_event` + condition + `
+		ORDER BY created_at DESC, pk DESC
+		LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+	return database.SqlxSelect(m.DB, events, query, args...)
+}
+
+func (m *subjectMemberEventManager) bulkInsert(events []SubjectMemberEvent) error {
+	sql := `INSERT INTO subject_member_event (
+		group_type,
+		group_id,
+		member_type,
+		member_id,
+		action,
+		policy_expired_at,
+		operator,
+		source,
+		created_at
+	) VALUES (
+		:group_type,
+		:group_id,
+		:member_type,
+		:member_id,
+		:action,
+		:policy_expired_at,
+		:operator,
+		:source,
+		:created_at)`
+	return database.SqlxBulkInsert(m.DB, sql, events)
+}
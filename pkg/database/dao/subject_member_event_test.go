@@ -0,0 +1,107 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dao
+
+import (
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+)
+
+func Test_subjectMemberEventManager_GetCount(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		start := time.Unix(1629000000, 0)
+		mockQuery := `^SELECT COUNT\(\*\) FROM subject_member_event ` +
+			`WHERE group_type = (.*) AND group_id = (.*) AND created_at >= (.*)$`
+		mockRows := sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(int64(2))
+		mock.ExpectQuery(mockQuery).WithArgs("group", "1", start).WillReturnRows(mockRows)
+
+		manager := &subjectMemberEventManager{DB: db}
+		cnt, err := manager.GetCount(SubjectMemberEventFilter{GroupType: "group", GroupID: "1", StartTime: start})
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, int64(2), cnt)
+	})
+}
+
+func Test_subjectMemberEventManager_GetCountNoFilter(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT COUNT\(\*\) FROM subject_member_event$`
+		mockRows := sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(int64(5))
+		mock.ExpectQuery(mockQuery).WillReturnRows(mockRows)
+
+		manager := &subjectMemberEventManager{DB: db}
+		cnt, err := manager.GetCount(SubjectMemberEventFilter{})
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, int64(5), cnt)
+	})
+}
+
+func Test_subjectMemberEventManager_ListPaging(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Now()
+		end := time.Unix(1629000000, 0)
+		mockQuery := `^SELECT pk, group_type, group_id, member_type, member_id, action, policy_expired_at, ` +
+			`operator, source, created_at FROM subject_member_event WHERE operator = (.*) AND created_at < (.*) ` +
+			`ORDER BY created_at DESC, pk DESC LIMIT (.*) OFFSET (.*)`
+		mockRows := sqlmock.NewRows([]string{
+			"pk", "group_type", "group_id", "member_type", "member_id", "action", "policy_expired_at",
+			"operator", "source", "created_at",
+		}).AddRow(int64(2), "group", "1", "user", "tom", "added", int64(4102444800), "admin", "bk_iam", now)
+		mock.ExpectQuery(mockQuery).WithArgs("admin", end, int64(10), int64(0)).WillReturnRows(mockRows)
+
+		manager := &subjectMemberEventManager{DB: db}
+		events, err := manager.ListPaging(SubjectMemberEventFilter{Operator: "admin", EndTime: end}, 10, 0)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []SubjectMemberEvent{{
+			PK:              2,
+			GroupType:       "group",
+			GroupID:         "1",
+			MemberType:      "user",
+			MemberID:        "tom",
+			Action:          "added",
+			PolicyExpiredAt: 4102444800,
+			Operator:        "admin",
+			Source:          "bk_iam",
+			CreatedAt:       now,
+		}}, events)
+	})
+}
+
+func Test_subjectMemberEventManager_BulkCreate(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Now()
+		mock.ExpectExec(`^INSERT INTO subject_member_event`).
+			WithArgs("group", "1", "user", "tom", "removed", int64(0), "admin", "bk_iam", now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		manager := &subjectMemberEventManager{DB: db}
+		err := manager.BulkCreate([]SubjectMemberEvent{{
+			GroupType:  "group",
+			GroupID:    "1",
+			MemberType: "user",
+			MemberID:   "tom",
+			Action:     "removed",
+			Operator:   "admin",
+			Source:     "bk_iam",
+			CreatedAt:  now,
+		}})
+
+		assert.NoError(t, err, "query from db fail.")
+	})
+}
@@ -0,0 +1,139 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dao
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"iam/pkg/database"
+)
+
+// SubjectMemberSnapshot 用户组成员列表的快照, Members为成员及其过期时间的JSON
+type SubjectMemberSnapshot struct {
+	PK          int64     `db:"pk"`
+	GroupType   string    `db:"group_type"`
+	GroupID     string    `db:"group_id"`
+	MemberCount int64     `db:"member_count"`
+	Members     string    `db:"members"`
+	Operator    string    `db:"operator"` // 操作人
+	Source      string    `db:"source"`   // 发起快照的来源, 如调用方的app_code
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// ThinSubjectMemberSnapshot 不包含成员列表的快照, 用于列表展示
+type ThinSubjectMemberSnapshot struct {
+	PK          int64     `db:"pk"`
+	GroupType   string    `db:"group_type"`
+	GroupID     string    `db:"group_id"`
+	MemberCount int64     `db:"member_count"`
+	Operator    string    `db:"operator"`
+	Source      string    `db:"source"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// SubjectMemberSnapshotManager ...
+type SubjectMemberSnapshotManager interface {
+	Get(pk int64) (SubjectMemberSnapshot, error)
+	ListThinByGroup(groupType, groupID string) ([]ThinSubjectMemberSnapshot, error)
+
+	CreateWithTx(tx *sqlx.Tx, snapshot SubjectMemberSnapshot) (int64, error)
+}
+
+type subjectMemberSnapshotManager struct {
+	DB *sqlx.DB
+}
+
+// NewSubjectMemberSnapshotManager ...
+func NewSubjectMemberSnapshotManager() SubjectMemberSnapshotManager {
+	return &subjectMemberSnapshotManager{
+		DB: database.GetDefaultDBClient().DB,
+	}
+}
+
+// Get ...
+func (m *subjectMemberSnapshotManager) Get(pk int64) (snapshot SubjectMemberSnapshot, err error) {
+	err = m.selectByPK(&snapshot, pk)
+	return
+}
+
+// ListThinByGroup 按时间倒序查询用户组的快照
+func (m *subjectMemberSnapshotManager) ListThinByGroup(
+	groupType, groupID string,
+) (snapshots []ThinSubjectMemberSnapshot, err error) {
+	err = m.selectThinByGroup(&snapshots, groupType, groupID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return snapshots, nil
+	}
+	return
+}
+
+// CreateWithTx 创建快照, 返回快照的pk
+func (m *subjectMemberSnapshotManager) CreateWithTx(tx *sqlx.Tx, snapshot SubjectMemberSnapshot) (int64, error) {
+	return m.insertWithTx(tx, snapshot)
+}
+
+func (m *subjectMemberSnapshotManager) selectByPK(snapshot *SubjectMemberSnapshot, pk int64) error {
+	query := `SELECT
+		pk,
+		group_type,
+		group_id,
+		member_count,
+		members,
+		operator,
+		source,
+		created_at
+		FROM subject_member_snapshot
+		WHERE pk = ?
+		LIMIT 1`
+	return database.SqlxGet(m.DB, snapshot, query, pk)
+}
+
+func (m *subjectMemberSnapshotManager) selectThinByGroup(
+	snapshots *[]ThinSubjectMemberSnapshot, groupType, groupID string,
+) error {
+	query := `SELECT
+		pk,
+		group_type,
+		group_id,
+		member_count,
+		operator,
+		source,
+		created_at
+		FROM subject_member_snapshot
+		WHERE group_type = ?
+		AND group_id = ?
+		ORDER BY created_at DESC, pk DESC`
+	return database.SqlxSelect(m.DB, snapshots, query, groupType, groupID)
+}
+
+func (m *subjectMemberSnapshotManager) insertWithTx(tx *sqlx.Tx, snapshot SubjectMemberSnapshot) (int64, error) {
+	sql := `INSERT INTO subject_member_snapshot (
+		group_type,
+		group_id,
+		member_count,
+		members,
+		operator,
+		source
+	) VALUES (
+		:group_type,
+		:group_id,
+		:member_count,
+		:members,
+		:operator,
+		:source)`
+	return database.SqlxBulkInsertReturnIDWithTx(tx, sql, []SubjectMemberSnapshot{snapshot})
+}
@@ -0,0 +1,101 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dao
+
+import (
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+)
+
+func Test_subjectMemberSnapshotManager_Get(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Now()
+		mockQuery := `^SELECT pk, group_type, group_id, member_count, members, operator, source, created_at ` +
+			`FROM subject_member_snapshot WHERE pk = (.*) LIMIT 1`
+		mockRows := sqlmock.NewRows([]string{
+			"pk", "group_type", "group_id", "member_count", "members", "operator", "source", "created_at",
+		}).AddRow(int64(1), "group", "1", int64(1), `[{"type":"user","id":"tom"}]`, "admin", "bk_iam", now)
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1)).WillReturnRows(mockRows)
+
+		manager := &subjectMemberSnapshotManager{DB: db}
+		snapshot, err := manager.Get(1)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, SubjectMemberSnapshot{
+			PK:          1,
+			GroupType:   "group",
+			GroupID:     "1",
+			MemberCount: 1,
+			Members:     `[{"type":"user","id":"tom"}]`,
+			Operator:    "admin",
+			Source:      "bk_iam",
+			CreatedAt:   now,
+		}, snapshot)
+	})
+}
+
+func Test_subjectMemberSnapshotManager_ListThinByGroup(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Now()
+		mockQuery := `^SELECT pk, group_type, group_id, member_count, operator, source, created_at ` +
+			`FROM subject_member_snapshot WHERE group_type = (.*) AND group_id = (.*) ORDER BY created_at DESC`
+		mockRows := sqlmock.NewRows([]string{
+			"pk", "group_type", "group_id", "member_count", "operator", "source", "created_at",
+		}).AddRow(int64(1), "group", "1", int64(2), "admin", "bk_iam", now)
+		mock.ExpectQuery(mockQuery).WithArgs("group", "1").WillReturnRows(mockRows)
+
+		manager := &subjectMemberSnapshotManager{DB: db}
+		snapshots, err := manager.ListThinByGroup("group", "1")
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []ThinSubjectMemberSnapshot{{
+			PK:          1,
+			GroupType:   "group",
+			GroupID:     "1",
+			MemberCount: 2,
+			Operator:    "admin",
+			Source:      "bk_iam",
+			CreatedAt:   now,
+		}}, snapshots)
+	})
+}
+
+func Test_subjectMemberSnapshotManager_CreateWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`^INSERT INTO subject_member_snapshot`).
+			WithArgs("group", "1", int64(0), "[]", "admin", "bk_iam").
+			WillReturnResult(sqlmock.NewResult(3, 1))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &subjectMemberSnapshotManager{DB: db}
+		pk, err := manager.CreateWithTx(tx, SubjectMemberSnapshot{
+			GroupType: "group",
+			GroupID:   "1",
+			Members:   "[]",
+			Operator:  "admin",
+			Source:    "bk_iam",
+		})
+
+		tx.Commit()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), pk)
+	})
+}
@@ -51,6 +51,12 @@ type ThinSubjectRelation struct {
 	PolicyExpiredAt int64 `db:"policy_expired_at"`
 }
 
+// SubjectRelationMember keep the member pk and type of the relationship
+type SubjectRelationMember struct {
+	SubjectPK   int64  `db:"subject_pk"`
+	SubjectType string `db:"subject_type"`
+}
+
 // EffectSubjectRelation with the minimum fields of the relationship: subject-group-expired_at
 type EffectSubjectRelation struct {
 	SubjectPK       int64 `db:"subject_pk"`
@@ -58,29 +64,96 @@ type EffectSubjectRelation struct {
 	PolicyExpiredAt int64 `db:"policy_expired_at"`
 }
 
+// NOTE: 高频查询的SQL, 索引诊断(EXPLAIN)也使用同样的语句, 修改时需要同步检查索引
+const (
+	// 用户组成员列表
+	pagingMembersQuery = `SELECT
+		pk,
+		subject_pk,
+		subject_type,
+		subject_id,
+		parent_pk,
+		parent_type,
+		parent_id,
+		policy_expired_at,
+		created_at
+		FROM subject_relation
+		WHERE parent_type = ?
+		AND parent_id = ?
+		ORDER BY pk DESC
+		LIMIT ? OFFSET ?`
+
+	// 鉴权时查询subject的有效用户组
+	effectRelationBySubjectPKsQuery = `SELECT
+		subject_pk,
+		parent_pk,
+		policy_expired_at
+		FROM subject_relation
+		WHERE subject_pk in (?)
+		AND policy_expired_at > ?`
+
+	// 用户组已过期的成员列表
+	pagingMembersBeforeExpiredAtQuery = `SELECT
+		pk,
+		subject_pk,
+		subject_type,
+		subject_id,
+		parent_pk,
+		parent_type,
+		parent_id,
+		policy_expired_at,
+		created_at
+		FROM subject_relation
+		WHERE parent_type = ?
+		AND parent_id = ?
+		AND policy_expired_at < ?
+		ORDER BY policy_expired_at DESC, pk DESC
+		LIMIT ? OFFSET ?`
+)
+
 // SubjectRelationManager ...
 type SubjectRelationManager interface {
 	ListRelation(_type, id string) ([]SubjectRelation, error)
 	ListRelationBySubjectPK(subjectPK int64) ([]SubjectRelation, error)
+	ListRelationByPKs(pks []int64) ([]SubjectRelation, error)
 	ListThinRelationBySubjectPK(subjectPK int64) ([]ThinSubjectRelation, error)
 	ListEffectRelationBySubjectPKs(subjectPKs []int64) ([]EffectSubjectRelation, error)
 	ListRelationBeforeExpiredAt(_type, id string, expiredAt int64) ([]SubjectRelation, error)
+	ListPagingRelation(_type, id string, limit, offset int64) ([]SubjectRelation, error)
+	ListPagingRelationBeforeExpiredAt(
+		_type, id string, expiredAt int64, limit, offset int64,
+	) ([]SubjectRelation, error)
+	GetRelationCount(_type, id string) (int64, error)
+	GetRelationCountBeforeExpiredAt(_type, id string, expiredAt int64) (int64, error)
 
 	ListPagingMember(_type, id string, limit, offset int64) ([]SubjectRelation, error)
+	ListPagingMemberBySubjectType(_type, id, subjectType string, limit, offset int64) ([]SubjectRelation, error)
 	ListPagingMemberBeforeExpiredAt(
 		_type string, id string, expiredAt int64, limit, offset int64,
 	) (members []SubjectRelation, err error)
 	ListMember(_type, id string) ([]SubjectRelation, error)
+	ListMemberByParentPKs(parentPKs []int64) ([]SubjectRelationMember, error)
+	ListSubjectPKsAfterPK(afterPK, limit int64) ([]int64, error)
+	ListEffectMemberByParentPKs(parentPKs []int64, subjectType string) ([]EffectSubjectRelation, error)
+	ListEffectMemberPKsAfterPKByParentPKs(
+		parentPKs []int64, subjectType string, afterPK, limit int64,
+	) ([]int64, error)
 	GetMemberCount(_type, id string) (int64, error)
+	GetMemberCountBySubjectType(_type, id, subjectType string) (int64, error)
 	GetMemberCountBeforeExpiredAt(_type string, id string, expiredAt int64) (int64, error)
 	ListParentIDsBeforeExpiredAt(_type string, ids []string, expiredAt int64) ([]string, error)
 
 	UpdateExpiredAt(relations []SubjectRelationPKPolicyExpiredAt) error
+	UpdateExpiredAtWithTx(tx *sqlx.Tx, relations []SubjectRelationPKPolicyExpiredAt) error
 
 	BulkDeleteByMembersWithTx(tx *sqlx.Tx, _type, id, subjectType string, subjectIDs []string) (int64, error)
 	BulkCreate(relations []SubjectRelation) error
+	BulkCreateWithTx(tx *sqlx.Tx, relations []SubjectRelation) error
 	BulkDeleteBySubjectPKs(tx *sqlx.Tx, subjectPKs []int64) error
 	BulkDeleteByParentPKs(tx *sqlx.Tx, parentPKs []int64) error
+
+	ListExpiredRelation(expiredAt int64, limit int64) ([]SubjectRelation, error)
+	BulkDeleteExpiredByPKsWithTx(tx *sqlx.Tx, pks []int64, expiredAt int64) (int64, error)
 }
 
 type subjectRelationManager struct {
@@ -94,6 +167,13 @@ func NewSubjectRelationManager() SubjectRelationManager {
 	}
 }
 
+// NewBatchSubjectRelationManager 批量任务(清理等)使用的SubjectRelationManager, 使用批量任务的DB连接池
+func NewBatchSubjectRelationManager() SubjectRelationManager {
+	return &subjectRelationManager{
+		DB: database.GetBatchDBClient().DB,
+	}
+}
+
 // ListRelation ...
 func (m *subjectRelationManager) ListRelation(_type, id string) (relations []SubjectRelation, err error) {
 	err = m.selectRelation(&relations, _type, id)
@@ -116,6 +196,41 @@ func (m *subjectRelationManager) ListRelationBeforeExpiredAt(
 	return
 }
 
+// ListPagingRelation 分页查询subject加入的用户组
+func (m *subjectRelationManager) ListPagingRelation(_type, id string, limit, offset int64) (
+	relations []SubjectRelation, err error) {
+	err = m.selectPagingRelation(&relations, _type, id, limit, offset)
+	if errors.Is(err, sql.ErrNoRows) {
+		return relations, nil
+	}
+	return
+}
+
+// ListPagingRelationBeforeExpiredAt 分页查询subject加入的在expiredAt之前过期的用户组
+func (m *subjectRelationManager) ListPagingRelationBeforeExpiredAt(
+	_type, id string, expiredAt int64, limit, offset int64,
+) (relations []SubjectRelation, err error) {
+	err = m.selectPagingRelationBeforeExpiredAt(&relations, _type, id, expiredAt, limit, offset)
+	if errors.Is(err, sql.ErrNoRows) {
+		return relations, nil
+	}
+	return
+}
+
+// GetRelationCount subject加入的用户组数量
+func (m *subjectRelationManager) GetRelationCount(_type, id string) (int64, error) {
+	var cnt int64
+	err := m.getRelationCount(&cnt, _type, id)
+	return cnt, err
+}
+
+// GetRelationCountBeforeExpiredAt subject加入的在expiredAt之前过期的用户组数量
+func (m *subjectRelationManager) GetRelationCountBeforeExpiredAt(_type, id string, expiredAt int64) (int64, error) {
+	var cnt int64
+	err := m.getRelationCountBeforeExpiredAt(&cnt, _type, id, expiredAt)
+	return cnt, err
+}
+
 // ListRelationBySubjectPK ...
 func (m *subjectRelationManager) ListRelationBySubjectPK(subjectPK int64) (relations []SubjectRelation, err error) {
 	err = m.selectRelationBySubjectPK(&relations, subjectPK)
@@ -126,6 +241,18 @@ func (m *subjectRelationManager) ListRelationBySubjectPK(subjectPK int64) (relat
 	return
 }
 
+// ListRelationByPKs 按关系的pk查询
+func (m *subjectRelationManager) ListRelationByPKs(pks []int64) (relations []SubjectRelation, err error) {
+	if len(pks) == 0 {
+		return
+	}
+	err = m.selectRelationByPKs(&relations, pks)
+	if errors.Is(err, sql.ErrNoRows) {
+		return relations, nil
+	}
+	return
+}
+
 // ListThinRelationBySubjectPK ...
 func (m *subjectRelationManager) ListThinRelationBySubjectPK(subjectPK int64) (
 	relations []ThinSubjectRelation, err error) {
@@ -165,6 +292,16 @@ func (m *subjectRelationManager) ListPagingMember(_type, id string, limit, offse
 	return
 }
 
+// ListPagingMemberBySubjectType 按成员类型过滤的用户组成员列表
+func (m *subjectRelationManager) ListPagingMemberBySubjectType(_type, id, subjectType string, limit, offset int64) (
+	members []SubjectRelation, err error) {
+	err = m.selectPagingMembersBySubjectType(&members, _type, id, subjectType, limit, offset)
+	if errors.Is(err, sql.ErrNoRows) {
+		return members, nil
+	}
+	return
+}
+
 // ListMember ...
 func (m *subjectRelationManager) ListMember(_type, id string) (members []SubjectRelation, err error) {
 	err = m.selectMembers(&members, _type, id)
@@ -174,6 +311,85 @@ func (m *subjectRelationManager) ListMember(_type, id string) (members []Subject
 	return
 }
 
+// ListMemberByParentPKs 多个用户组的成员, 不过滤过期时间
+func (m *subjectRelationManager) ListMemberByParentPKs(parentPKs []int64) (members []SubjectRelationMember, err error) {
+	if len(parentPKs) == 0 {
+		return
+	}
+
+	query := `SELECT
+		DISTINCT subject_pk,
+		subject_type
+		FROM subject_relation
+		WHERE parent_pk IN (?)`
+	err = database.SqlxSelect(m.DB, &members, query, parentPKs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return members, nil
+	}
+	return
+}
+
+// ListSubjectPKsAfterPK 按pk顺序分批获取加入了用户组的subject, 不过滤过期时间
+func (m *subjectRelationManager) ListSubjectPKsAfterPK(afterPK, limit int64) (pks []int64, err error) {
+	query := `SELECT
+		DISTINCT subject_pk
+		FROM subject_relation
+		WHERE subject_pk > ?
+		ORDER BY subject_pk
+		LIMIT ?`
+	err = database.SqlxSelect(m.DB, &pks, query, afterPK, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pks, nil
+	}
+	return
+}
+
+// ListEffectMemberByParentPKs 多个用户组指定类型的未过期成员
+func (m *subjectRelationManager) ListEffectMemberByParentPKs(parentPKs []int64, subjectType string) (
+	relations []EffectSubjectRelation, err error) {
+	if len(parentPKs) == 0 {
+		return
+	}
+
+	query := `SELECT
+		subject_pk,
+		parent_pk,
+		policy_expired_at
+		FROM subject_relation
+		WHERE parent_pk IN (?)
+		AND subject_type = ?
+		AND policy_expired_at > ?`
+	err = database.SqlxSelect(m.DB, &relations, query, parentPKs, subjectType, time.Now().Unix())
+	if errors.Is(err, sql.ErrNoRows) {
+		return relations, nil
+	}
+	return
+}
+
+// ListEffectMemberPKsAfterPKByParentPKs 多个用户组指定类型且pk大于afterPK的未过期成员pk, 按pk升序
+func (m *subjectRelationManager) ListEffectMemberPKsAfterPKByParentPKs(
+	parentPKs []int64, subjectType string, afterPK, limit int64,
+) (subjectPKs []int64, err error) {
+	if len(parentPKs) == 0 {
+		return
+	}
+
+	query := `SELECT
+		DISTINCT subject_pk
+		FROM subject_relation
+		WHERE parent_pk IN (?)
+		AND subject_type = ?
+		AND policy_expired_at > ?
+		AND subject_pk > ?
+		ORDER BY subject_pk
+		LIMIT ?`
+	err = database.SqlxSelect(m.DB, &subjectPKs, query, parentPKs, subjectType, time.Now().Unix(), afterPK, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return subjectPKs, nil
+	}
+	return
+}
+
 // GetMemberCount ...
 func (m *subjectRelationManager) GetMemberCount(_type, id string) (int64, error) {
 	var cnt int64
@@ -181,6 +397,13 @@ func (m *subjectRelationManager) GetMemberCount(_type, id string) (int64, error)
 	return cnt, err
 }
 
+// GetMemberCountBySubjectType 按成员类型过滤的用户组成员数量
+func (m *subjectRelationManager) GetMemberCountBySubjectType(_type, id, subjectType string) (int64, error) {
+	var cnt int64
+	err := m.getMemberCountBySubjectType(&cnt, _type, id, subjectType)
+	return cnt, err
+}
+
 // BulkDeleteByMembersWithTx ...
 func (m *subjectRelationManager) BulkDeleteByMembersWithTx(
 	tx *sqlx.Tx, _type, id, subjectType string, subjectIDs []string) (int64, error) {
@@ -198,6 +421,14 @@ func (m *subjectRelationManager) BulkCreate(relations []SubjectRelation) error {
 	return m.bulkInsert(relations)
 }
 
+// BulkCreateWithTx ...
+func (m *subjectRelationManager) BulkCreateWithTx(tx *sqlx.Tx, relations []SubjectRelation) error {
+	if len(relations) == 0 {
+		return nil
+	}
+	return m.bulkInsertWithTx(tx, relations)
+}
+
 // BulkDeleteBySubjectPKs ...
 func (m *subjectRelationManager) BulkDeleteBySubjectPKs(tx *sqlx.Tx, subjectPKs []int64) error {
 	if len(subjectPKs) == 0 {
@@ -214,11 +445,40 @@ func (m *subjectRelationManager) BulkDeleteByParentPKs(tx *sqlx.Tx, parentPKs []
 	return m.bulkDeleteByParentPKs(tx, parentPKs)
 }
 
+// ListExpiredRelation 查询过期时间早于expiredAt的关系, 用于后台清理
+func (m *subjectRelationManager) ListExpiredRelation(expiredAt int64, limit int64) (
+	relations []SubjectRelation, err error) {
+	err = m.selectExpiredRelation(&relations, expiredAt, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return relations, nil
+	}
+	return
+}
+
+// BulkDeleteExpiredByPKsWithTx 删除指定的关系, 只删除依然过期的, 查询后被续期的关系不会被删除
+func (m *subjectRelationManager) BulkDeleteExpiredByPKsWithTx(
+	tx *sqlx.Tx, pks []int64, expiredAt int64) (int64, error) {
+	if len(pks) == 0 {
+		return 0, nil
+	}
+	return m.bulkDeleteExpiredByPKsWithTx(tx, pks, expiredAt)
+}
+
 // UpdateExpiredAt ...
 func (m *subjectRelationManager) UpdateExpiredAt(relations []SubjectRelationPKPolicyExpiredAt) error {
 	return m.updateExpiredAt(relations)
 }
 
+// UpdateExpiredAtWithTx ...
+func (m *subjectRelationManager) UpdateExpiredAtWithTx(
+	tx *sqlx.Tx, relations []SubjectRelationPKPolicyExpiredAt,
+) error {
+	if len(relations) == 0 {
+		return nil
+	}
+	return m.updateExpiredAtWithTx(tx, relations)
+}
+
 // GetMemberCountBeforeExpiredAt ...
 func (m *subjectRelationManager) GetMemberCountBeforeExpiredAt(
 	_type string, id string, expiredAt int64,
@@ -289,6 +549,86 @@ func (m *subjectRelationManager) selectRelationBeforeExpiredAt(
 	return database.SqlxSelect(m.DB, relations, query, _type, id, expiredAt)
 }
 
+func (m *subjectRelationManager) selectPagingRelation(
+	relations *[]SubjectRelation, _type, id string, limit, offset int64,
+) error {
+	query := `SELECT
+		pk,
+		subject_pk,
+		subject_type,
+		subject_id,
+		parent_pk,
+		parent_type,
+		parent_id,
+		policy_expired_at,
+		created_at
+		FROM subject_relation
+		WHERE subject_type = ?
+		AND subject_id = ?
+		ORDER BY pk DESC
+		LIMIT ? OFFSET ?`
+	return database.SqlxSelect(m.DB, relations, query, _type, id, limit, offset)
+}
+
+func (m *subjectRelationManager) selectPagingRelationBeforeExpiredAt(
+	relations *[]SubjectRelation, _type, id string, expiredAt int64, limit, offset int64,
+) error {
+	query := `SELECT
+		pk,
+		subject_pk,
+		subject_type,
+		subject_id,
+		parent_pk,
+		parent_type,
+		parent_id,
+		policy_expired_at,
+		created_at
+		FROM subject_relation
+		WHERE subject_type = ?
+		AND subject_id = ?
+		AND policy_expired_at < ?
+		ORDER BY policy_expired_at DESC, pk DESC
+		LIMIT ? OFFSET ?`
+	return database.SqlxSelect(m.DB, relations, query, _type, id, expiredAt, limit, offset)
+}
+
+func (m *subjectRelationManager) getRelationCount(cnt *int64, _type, id string) error {
+	query := `SELECT
+		COUNT(*)
+		FROM subject_relation
+		WHERE subject_type = ?
+		AND subject_id = ?`
+	return database.SqlxGet(m.DB, cnt, query, _type, id)
+}
+
+func (m *subjectRelationManager) getRelationCountBeforeExpiredAt(
+	cnt *int64, _type, id string, expiredAt int64,
+) error {
+	query := `SELECT
+		COUNT(*)
+		FROM subject_relation
+		WHERE subject_type = ?
+		AND subject_id = ?
+		AND policy_expired_at < ?`
+	return database.SqlxGet(m.DB, cnt, query, _type, id, expiredAt)
+}
+
+func (m *subjectRelationManager) selectRelationByPKs(relations *[]SubjectRelation, pks []int64) error {
+	query := `SELECT
+		pk,
+		subject_pk,
+		subject_type,
+		subject_id,
+		parent_pk,
+		parent_type,
+		parent_id,
+		policy_expired_at,
+		created_at
+		FROM subject_relation
+		WHERE pk IN (?)`
+	return database.SqlxSelect(m.DB, relations, query, pks)
+}
+
 func (m *subjectRelationManager) selectRelationBySubjectPK(relations *[]SubjectRelation, pk int64) error {
 	query := `SELECT
 		pk,
@@ -319,18 +659,16 @@ func (m *subjectRelationManager) selectEffectRelationBySubjectPKs(
 	pks []int64,
 	now int64,
 ) error {
-	query := `SELECT
-		subject_pk,
-		parent_pk,
-		policy_expired_at
-		FROM subject_relation
-		WHERE subject_pk in (?)
-		AND policy_expired_at > ?`
-	return database.SqlxSelect(m.DB, relations, query, pks, now)
+	return database.SqlxSelect(m.DB, relations, effectRelationBySubjectPKsQuery, pks, now)
 }
 
 func (m *subjectRelationManager) selectPagingMembers(
 	members *[]SubjectRelation, _type, id string, limit, offset int64) error {
+	return database.SqlxSelect(m.DB, members, pagingMembersQuery, _type, id, limit, offset)
+}
+
+func (m *subjectRelationManager) selectPagingMembersBySubjectType(
+	members *[]SubjectRelation, _type, id, subjectType string, limit, offset int64) error {
 	query := `SELECT
 		pk,
 		subject_pk,
@@ -344,30 +682,15 @@ func (m *subjectRelationManager) selectPagingMembers(
 		FROM subject_relation
 		WHERE parent_type = ?
 		AND parent_id = ?
+		AND subject_type = ?
 		ORDER BY pk DESC
 		LIMIT ? OFFSET ?`
-	return database.SqlxSelect(m.DB, members, query, _type, id, limit, offset)
+	return database.SqlxSelect(m.DB, members, query, _type, id, subjectType, limit, offset)
 }
 
 func (m *subjectRelationManager) selectPagingMembersBeforeExpiredAt(
 	members *[]SubjectRelation, _type string, id string, expiredAt int64, limit, offset int64) error {
-	query := `SELECT
-		pk,
-		subject_pk,
-		subject_type,
-		subject_id,
-		parent_pk,
-		parent_type,
-		parent_id,
-		policy_expired_at,
-		created_at
-		FROM subject_relation
-		WHERE parent_type = ?
-		AND parent_id = ?
-		AND policy_expired_at < ?
-		ORDER BY policy_expired_at DESC, pk DESC
-		LIMIT ? OFFSET ?`
-	return database.SqlxSelect(m.DB, members, query, _type, id, expiredAt, limit, offset)
+	return database.SqlxSelect(m.DB, members, pagingMembersBeforeExpiredAtQuery, _type, id, expiredAt, limit, offset)
 }
 
 func (m *subjectRelationManager) selectMembers(
@@ -397,6 +720,16 @@ func (m *subjectRelationManager) getMemberCount(cnt *int64, _type, id string) er
 	return database.SqlxGet(m.DB, cnt, query, _type, id)
 }
 
+func (m *subjectRelationManager) getMemberCountBySubjectType(cnt *int64, _type, id, subjectType string) error {
+	query := `SELECT
+		COUNT(*)
+		FROM subject_relation
+		WHERE parent_type = ?
+		AND parent_id = ?
+		AND subject_type = ?`
+	return database.SqlxGet(m.DB, cnt, query, _type, id, subjectType)
+}
+
 func (m *subjectRelationManager) getMemberCountBeforeExpiredAt(
 	cnt *int64, _type string, id string, expiredAt int64,
 ) error {
@@ -436,6 +769,27 @@ func (m *subjectRelationManager) bulkInsert(relations []SubjectRelation) error {
 	return database.SqlxBulkInsert(m.DB, sql, relations)
 }
 
+func (m *subjectRelationManager) bulkInsertWithTx(tx *sqlx.Tx, relations []SubjectRelation) error {
+	sql := `INSERT INTO subject_relation (
+		subject_pk,
+		subject_type,
+		subject_id,
+		parent_pk,
+		parent_type,
+		parent_id,
+		policy_expired_at,
+		created_at
+	) VALUES (:subject_pk,
+		:subject_type,
+		:subject_id,
+		:parent_pk,
+		:parent_type,
+		:parent_id,
+		:policy_expired_at,
+		:created_at)`
+	return database.SqlxBulkInsertWithTx(tx, sql, relations)
+}
+
 func (m *subjectRelationManager) bulkDeleteBySubjectPKs(tx *sqlx.Tx, subjectPKs []int64) error {
 	sql := `DELETE FROM subject_relation WHERE subject_pk in (?)`
 	return database.SqlxDeleteWithTx(tx, sql, subjectPKs)
@@ -447,12 +801,45 @@ func (m *subjectRelationManager) bulkDeleteByParentPKs(tx *sqlx.Tx, parentPKs []
 	return database.SqlxDeleteWithTx(tx, sql, parentPKs)
 }
 
+func (m *subjectRelationManager) selectExpiredRelation(
+	relations *[]SubjectRelation, expiredAt int64, limit int64,
+) error {
+	query := `SELECT
+		pk,
+		subject_pk,
+		subject_type,
+		subject_id,
+		parent_pk,
+		parent_type,
+		parent_id,
+		policy_expired_at,
+		created_at
+		FROM subject_relation
+		WHERE policy_expired_at < ?
+		ORDER BY policy_expired_at
+		LIMIT ?`
+	return database.SqlxSelect(m.DB, relations, query, expiredAt, limit)
+}
+
+func (m *subjectRelationManager) bulkDeleteExpiredByPKsWithTx(
+	tx *sqlx.Tx, pks []int64, expiredAt int64) (int64, error) {
+	sql := `DELETE FROM subject_relation WHERE pk in (?) AND policy_expired_at < ?`
+	return database.SqlxDeleteReturnRowsWithTx(tx, sql, pks, expiredAt)
+}
+
 func (m *subjectRelationManager) updateExpiredAt(relations []SubjectRelationPKPolicyExpiredAt) error {
 	sql := `UPDATE subject_relation SET policy_expired_at = :policy_expired_at WHERE pk = :pk`
 
 	return database.SqlxBulkUpdate(m.DB, sql, relations)
 }
 
+func (m *subjectRelationManager) updateExpiredAtWithTx(
+	tx *sqlx.Tx, relations []SubjectRelationPKPolicyExpiredAt,
+) error {
+	sql := `UPDATE subject_relation SET policy_expired_at = :policy_expired_at WHERE pk = :pk`
+	return database.SqlxBulkUpdateWithTx(tx, sql, relations)
+}
+
 func (m *subjectRelationManager) listParentIDsBeforeExpiredAt(
 	parentIDs *[]string, _type string, ids []string, expiredAt int64,
 ) error {
@@ -0,0 +1,114 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dao
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+
+	"iam/pkg/database"
+)
+
+// TableIndex SHOW INDEX 输出中的索引列, 一个索引的每一列一行
+type TableIndex struct {
+	KeyName    string `db:"Key_name"`
+	SeqInIndex int64  `db:"Seq_in_index"`
+	ColumnName string `db:"Column_name"`
+	NonUnique  int64  `db:"Non_unique"`
+}
+
+// ExplainRow EXPLAIN 输出的一行, 兼容不同版本mysql的输出列
+type ExplainRow struct {
+	ID           sql.NullInt64  `db:"id"`
+	SelectType   sql.NullString `db:"select_type"`
+	Table        sql.NullString `db:"table"`
+	Type         sql.NullString `db:"type"`
+	PossibleKeys sql.NullString `db:"possible_keys"`
+	Key          sql.NullString `db:"key"`
+	KeyLen       sql.NullString `db:"key_len"`
+	Ref          sql.NullString `db:"ref"`
+	Rows         sql.NullInt64  `db:"rows"`
+	Extra        sql.NullString `db:"Extra"`
+}
+
+// SubjectRelationHotQuery subject_relation表的高频查询, 以及期望命中的索引
+type SubjectRelationHotQuery struct {
+	Name  string
+	Query string
+	// 用于EXPLAIN的样例参数
+	Args []interface{}
+	// 期望命中的索引, 命中其中任意一个即可
+	Indexes []string
+}
+
+// SubjectRelationIndexes subject_relation表期望的索引及其列, 与 build/support-files/sql 中的建表/变更语句保持一致
+var SubjectRelationIndexes = map[string][]string{
+	"PRIMARY":               {"pk"},
+	"idx_subject":           {"subject_id", "subject_type"},
+	"idx_parent":            {"parent_id", "parent_type"},
+	"idx_uk_subject_parent": {"subject_pk", "parent_pk"},
+	"idx_subject_pk_expire": {"subject_pk", "policy_expired_at"},
+}
+
+// SubjectRelationHotQueries subject_relation表的高频查询
+var SubjectRelationHotQueries = []SubjectRelationHotQuery{
+	{
+		Name:    "member_listing",
+		Query:   pagingMembersQuery,
+		Args:    []interface{}{"group", "0", 10, 0},
+		Indexes: []string{"idx_parent"},
+	},
+	{
+		Name:    "effect_group_resolution",
+		Query:   effectRelationBySubjectPKsQuery,
+		Args:    []interface{}{[]int64{0}, 0},
+		Indexes: []string{"idx_subject_pk_expire", "idx_uk_subject_parent"},
+	},
+	{
+		Name:    "expired_member_listing",
+		Query:   pagingMembersBeforeExpiredAtQuery,
+		Args:    []interface{}{"group", "0", 0, 10, 0},
+		Indexes: []string{"idx_parent"},
+	},
+}
+
+// SubjectRelationIndexManager 诊断subject_relation表的索引
+type SubjectRelationIndexManager interface {
+	ListIndexes() ([]TableIndex, error)
+	Explain(query string, args ...interface{}) ([]ExplainRow, error)
+}
+
+type subjectRelationIndexManager struct {
+	DB *sqlx.DB
+}
+
+// NewSubjectRelationIndexManager ...
+func NewSubjectRelationIndexManager() SubjectRelationIndexManager {
+	return &subjectRelationIndexManager{
+		DB: database.GetDefaultDBClient().DB,
+	}
+}
+
+// ListIndexes 查询线上表结构中的索引
+func (m *subjectRelationIndexManager) ListIndexes() (indexes []TableIndex, err error) {
+	// NOTE: SHOW INDEX 的输出列随mysql版本变化, 使用Unsafe忽略未定义的列
+	err = database.SqlxSelect(m.DB.Unsafe(), &indexes, `SHOW INDEX FROM subject_relation`)
+	return
+}
+
+// Explain 获取查询的执行计划
+func (m *subjectRelationIndexManager) Explain(query string, args ...interface{}) (rows []ExplainRow, err error) {
+	err = database.SqlxSelect(m.DB.Unsafe(), &rows, "EXPLAIN "+query, args...)
+	return
+}
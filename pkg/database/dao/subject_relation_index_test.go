@@ -0,0 +1,61 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dao
+
+import (
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+)
+
+func Test_subjectRelationIndexManager_ListIndexes(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SHOW INDEX FROM subject_relation`
+		mockRows := sqlmock.NewRows(
+			[]string{"Table", "Non_unique", "Key_name", "Seq_in_index", "Column_name", "Collation"},
+		).AddRow("subject_relation", int64(1), "idx_parent", int64(1), "parent_id", "A").
+			AddRow("subject_relation", int64(1), "idx_parent", int64(2), "parent_type", "A")
+		mock.ExpectQuery(mockQuery).WillReturnRows(mockRows)
+
+		manager := &subjectRelationIndexManager{DB: db}
+		indexes, err := manager.ListIndexes()
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Len(t, indexes, 2)
+		assert.Equal(t, "idx_parent", indexes[1].KeyName)
+		assert.Equal(t, "parent_type", indexes[1].ColumnName)
+	})
+}
+
+func Test_subjectRelationIndexManager_Explain(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^EXPLAIN SELECT (.*) FROM subject_relation`
+		mockRows := sqlmock.NewRows(
+			[]string{"id", "select_type", "table", "partitions", "type", "possible_keys",
+				"key", "key_len", "ref", "rows", "filtered", "Extra"},
+		).AddRow(int64(1), "SIMPLE", "subject_relation", nil, "range",
+			"idx_uk_subject_parent,idx_subject_pk_expire", "idx_subject_pk_expire", "16", nil, int64(1), 100.0,
+			"Using where; Using index")
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(2), 0).WillReturnRows(mockRows)
+
+		manager := &subjectRelationIndexManager{DB: db}
+		rows, err := manager.Explain(effectRelationBySubjectPKsQuery, []int64{1, 2}, 0)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Len(t, rows, 1)
+		assert.Equal(t, "idx_subject_pk_expire", rows[0].Key.String)
+		assert.Equal(t, int64(1), rows[0].Rows.Int64)
+	})
+}
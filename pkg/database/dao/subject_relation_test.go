@@ -51,6 +51,37 @@ func Test_subjectRelationManager_List(t *testing.T) {
 	})
 }
 
+func Test_subjectRelationManager_GetMemberCountBySubjectType(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT (.*) FROM subject_relation WHERE parent_type = (.*) AND subject_type = (.*)`
+		mockRows := sqlmock.NewRows([]string{"count(*)"}).AddRow(int64(2))
+		mock.ExpectQuery(mockQuery).WithArgs("group", "1", "service_account").WillReturnRows(mockRows)
+
+		manager := &subjectRelationManager{DB: db}
+		cnt, err := manager.GetMemberCountBySubjectType("group", "1", "service_account")
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, int64(2), cnt)
+	})
+}
+
+func Test_subjectRelationManager_ListPagingMemberBySubjectType(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT (.*) FROM subject_relation WHERE parent_type = (.*) AND subject_type = (.*)`
+		mockRows := sqlmock.NewRows(
+			[]string{"pk", "subject_type", "subject_id", "parent_type", "parent_id", "policy_expired_at"},
+		).AddRow(int64(1), "service_account", "ci-bot", "group", "1", int64(0))
+		mock.ExpectQuery(mockQuery).WithArgs("group", "1", "service_account", 10, 0).WillReturnRows(mockRows)
+
+		manager := &subjectRelationManager{DB: db}
+		relations, err := manager.ListPagingMemberBySubjectType("group", "1", "service_account", 10, 0)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Len(t, relations, 1)
+		assert.Equal(t, "ci-bot", relations[0].SubjectID)
+	})
+}
+
 func Test_subjectRelationManager_ListRelation(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mockQuery := `^SELECT (.*) FROM subject_relation`
@@ -85,6 +116,76 @@ func Test_subjectRelationManager_ListEffectRelationBySubjectPKs(t *testing.T) {
 	})
 }
 
+func Test_subjectRelationManager_ListMemberByParentPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT DISTINCT subject_pk, subject_type FROM subject_relation WHERE parent_pk IN`
+		mockRows := sqlmock.NewRows(
+			[]string{"subject_pk", "subject_type"},
+		).AddRow(int64(1), "user").AddRow(int64(2), "group")
+		mock.ExpectQuery(mockQuery).WithArgs(int64(10), int64(11)).WillReturnRows(mockRows)
+
+		manager := &subjectRelationManager{DB: db}
+		members, err := manager.ListMemberByParentPKs([]int64{10, 11})
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []SubjectRelationMember{
+			{SubjectPK: 1, SubjectType: "user"},
+			{SubjectPK: 2, SubjectType: "group"},
+		}, members)
+	})
+}
+
+func Test_subjectRelationManager_ListSubjectPKsAfterPK(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT DISTINCT subject_pk FROM subject_relation WHERE subject_pk > (.*) ` +
+			`ORDER BY subject_pk LIMIT (.*)`
+		mockRows := sqlmock.NewRows([]string{"subject_pk"}).AddRow(int64(2)).AddRow(int64(3))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(2)).WillReturnRows(mockRows)
+
+		manager := &subjectRelationManager{DB: db}
+		pks, err := manager.ListSubjectPKsAfterPK(1, 2)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []int64{2, 3}, pks)
+	})
+}
+
+func Test_subjectRelationManager_ListEffectMemberByParentPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT subject_pk, parent_pk, policy_expired_at FROM subject_relation WHERE parent_pk IN (.*) ` +
+			`AND subject_type = (.*) AND policy_expired_at >`
+		mockRows := sqlmock.NewRows(
+			[]string{"subject_pk", "parent_pk", "policy_expired_at"},
+		).AddRow(int64(2), int64(10), int64(100))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(10), int64(11), "group", sqlmock.AnyArg()).WillReturnRows(mockRows)
+
+		manager := &subjectRelationManager{DB: db}
+		relations, err := manager.ListEffectMemberByParentPKs([]int64{10, 11}, "group")
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []EffectSubjectRelation{
+			{SubjectPK: 2, ParentPK: 10, PolicyExpiredAt: 100},
+		}, relations)
+	})
+}
+
+func Test_subjectRelationManager_ListEffectMemberPKsAfterPKByParentPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT DISTINCT subject_pk FROM subject_relation WHERE parent_pk IN (.*) ` +
+			`AND subject_type = (.*) AND policy_expired_at > (.*) AND subject_pk > (.*) ORDER BY subject_pk LIMIT`
+		mockRows := sqlmock.NewRows([]string{"subject_pk"}).AddRow(int64(3)).AddRow(int64(4))
+		mock.ExpectQuery(mockQuery).WithArgs(
+			int64(10), "user", sqlmock.AnyArg(), int64(2), int64(100),
+		).WillReturnRows(mockRows)
+
+		manager := &subjectRelationManager{DB: db}
+		subjectPKs, err := manager.ListEffectMemberPKsAfterPKByParentPKs([]int64{10}, "user", 2, 100)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []int64{3, 4}, subjectPKs)
+	})
+}
+
 func Test_subjectRelationManager_BulkDeleteByMembersWithTx(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mock.ExpectBegin()
@@ -123,6 +224,91 @@ func Test_subjectRelationManager_ListRelationBeforeExpiredAt(t *testing.T) {
 	})
 }
 
+func Test_subjectRelationManager_ListRelationByPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT (.*) FROM subject_relation WHERE pk IN`
+		mockRows := sqlmock.NewRows(
+			[]string{
+				"pk", "subject_pk", "subject_type", "subject_id", "parent_pk",
+				"parent_type", "parent_id", "policy_expired_at"},
+		).AddRow(int64(1), int64(1), "user", "admin", int64(2), "group", "1", int64(1000))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(3)).WillReturnRows(mockRows)
+
+		manager := &subjectRelationManager{DB: db}
+		relations, err := manager.ListRelationByPKs([]int64{1, 3})
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Len(t, relations, 1)
+		assert.Equal(t, int64(2), relations[0].ParentPK)
+	})
+}
+
+func Test_subjectRelationManager_GetRelationCount(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT (.*) FROM subject_relation WHERE subject_type = (.*) AND subject_id = (.*)`
+		mockRows := sqlmock.NewRows([]string{"count(*)"}).AddRow(int64(3))
+		mock.ExpectQuery(mockQuery).WithArgs("user", "admin").WillReturnRows(mockRows)
+
+		manager := &subjectRelationManager{DB: db}
+		cnt, err := manager.GetRelationCount("user", "admin")
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, int64(3), cnt)
+	})
+}
+
+func Test_subjectRelationManager_GetRelationCountBeforeExpiredAt(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT (.*) FROM subject_relation WHERE subject_type = (.*) AND policy_expired_at < (.*)`
+		mockRows := sqlmock.NewRows([]string{"count(*)"}).AddRow(int64(2))
+		mock.ExpectQuery(mockQuery).WithArgs("user", "admin", int64(1000)).WillReturnRows(mockRows)
+
+		manager := &subjectRelationManager{DB: db}
+		cnt, err := manager.GetRelationCountBeforeExpiredAt("user", "admin", int64(1000))
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, int64(2), cnt)
+	})
+}
+
+func Test_subjectRelationManager_ListPagingRelation(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT (.*) FROM subject_relation WHERE subject_type = (.*) ORDER BY pk DESC LIMIT (.*)`
+		mockRows := sqlmock.NewRows(
+			[]string{
+				"pk", "subject_pk", "subject_type", "subject_id", "parent_pk",
+				"parent_type", "parent_id", "policy_expired_at"},
+		).AddRow(int64(1), int64(1), "user", "admin", int64(2), "group", "1", int64(0))
+		mock.ExpectQuery(mockQuery).WithArgs("user", "admin", int64(10), int64(0)).WillReturnRows(mockRows)
+
+		manager := &subjectRelationManager{DB: db}
+		relations, err := manager.ListPagingRelation("user", "admin", 10, 0)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Len(t, relations, 1)
+	})
+}
+
+func Test_subjectRelationManager_ListPagingRelationBeforeExpiredAt(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT (.*) FROM subject_relation WHERE subject_type = (.*) AND policy_expired_at < (.*)`
+		mockRows := sqlmock.NewRows(
+			[]string{
+				"pk", "subject_pk", "subject_type", "subject_id", "parent_pk",
+				"parent_type", "parent_id", "policy_expired_at"},
+		).AddRow(int64(1), int64(1), "user", "admin", int64(2), "group", "1", int64(0))
+		mock.ExpectQuery(mockQuery).
+			WithArgs("user", "admin", int64(1000), int64(10), int64(20)).
+			WillReturnRows(mockRows)
+
+		manager := &subjectRelationManager{DB: db}
+		relations, err := manager.ListPagingRelationBeforeExpiredAt("user", "admin", int64(1000), 10, 20)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Len(t, relations, 1)
+	})
+}
+
 func Test_subjectRelationManager_GetMemberCountBeforeExpiredAt(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mockQuery := `^SELECT (.*) FROM subject_relation`
@@ -136,3 +322,90 @@ func Test_subjectRelationManager_GetMemberCountBeforeExpiredAt(t *testing.T) {
 		assert.Equal(t, cnt, int64(1))
 	})
 }
+
+func Test_subjectRelationManager_ListExpiredRelation(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT (.*) FROM subject_relation WHERE policy_expired_at < (.*) ORDER BY`
+		mockRows := sqlmock.NewRows(
+			[]string{
+				"pk", "subject_pk", "subject_type", "subject_id", "parent_pk",
+				"parent_type", "parent_id", "policy_expired_at"},
+		).AddRow(int64(1), int64(1), "user", "admin", int64(2), "group", "1", int64(10))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1000), int64(100)).WillReturnRows(mockRows)
+
+		manager := &subjectRelationManager{DB: db}
+		relations, err := manager.ListExpiredRelation(int64(1000), int64(100))
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Len(t, relations, 1)
+	})
+}
+
+func Test_subjectRelationManager_BulkDeleteExpiredByPKsWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM subject_relation WHERE pk in`).WithArgs(
+			int64(1), int64(2), int64(1000),
+		).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &subjectRelationManager{DB: db}
+		cnt, err := manager.BulkDeleteExpiredByPKsWithTx(tx, []int64{1, 2}, int64(1000))
+
+		tx.Commit()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), cnt)
+	})
+}
+
+func Test_subjectRelationManager_BulkCreateWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectExec(`^INSERT INTO subject_relation`).WithArgs(
+			int64(1), "user", "tom", int64(2), "group", "2", int64(1000), now,
+		).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &subjectRelationManager{DB: db}
+		err = manager.BulkCreateWithTx(tx, []SubjectRelation{{
+			SubjectPK:       1,
+			SubjectType:     "user",
+			SubjectID:       "tom",
+			ParentPK:        2,
+			ParentType:      "group",
+			ParentID:        "2",
+			PolicyExpiredAt: 1000,
+			CreateAt:        now,
+		}})
+
+		tx.Commit()
+		assert.NoError(t, err)
+	})
+}
+
+func Test_subjectRelationManager_UpdateExpiredAtWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectPrepare(`UPDATE subject_relation SET policy_expired_at = (.*) WHERE pk = (.*)`)
+		mock.ExpectExec(`UPDATE subject_relation SET policy_expired_at =`).WithArgs(
+			int64(2000), int64(1),
+		).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &subjectRelationManager{DB: db}
+		err = manager.UpdateExpiredAtWithTx(tx, []SubjectRelationPKPolicyExpiredAt{{PK: 1, PolicyExpiredAt: 2000}})
+
+		tx.Commit()
+		assert.NoError(t, err)
+	})
+}
@@ -32,9 +32,14 @@ type SubjectRole struct {
 type SubjectRoleManager interface {
 	ListSubjectPKByRole(roleType, system string) ([]int64, error)
 	ListSystemIDBySubjectPK(pk int64) ([]string, error)
+	ListBySubjectPK(pk int64) ([]SubjectRole, error)
+	GetCount() (int64, error)
+	ListPaging(limit, offset int64) ([]SubjectRole, error)
 
 	BulkCreate(roles []SubjectRole) error
+	BulkCreateWithTx(tx *sqlx.Tx, roles []SubjectRole) error
 	BulkDelete(roleType, system string, subjectPKs []int64) error
+	BulkDeleteWithTx(tx *sqlx.Tx, roleType, system string, subjectPKs []int64) error
 }
 
 type subjectRoleManager struct {
@@ -65,6 +70,30 @@ func (m *subjectRoleManager) ListSystemIDBySubjectPK(pk int64) ([]string, error)
 	return systemIDs, err
 }
 
+// ListBySubjectPK 查询subject的所有角色
+func (m *subjectRoleManager) ListBySubjectPK(pk int64) (roles []SubjectRole, err error) {
+	err = m.selectBySubjectPK(&roles, pk)
+	if errors.Is(err, sql.ErrNoRows) {
+		return roles, nil
+	}
+	return
+}
+
+// GetCount ...
+func (m *subjectRoleManager) GetCount() (count int64, err error) {
+	err = m.getCount(&count)
+	return
+}
+
+// ListPaging 按角色类型/系统分页查询所有角色
+func (m *subjectRoleManager) ListPaging(limit, offset int64) (roles []SubjectRole, err error) {
+	err = m.selectPaging(&roles, limit, offset)
+	if errors.Is(err, sql.ErrNoRows) {
+		return roles, nil
+	}
+	return
+}
+
 // BulkCreate ...
 func (m *subjectRoleManager) BulkCreate(roles []SubjectRole) error {
 	if len(roles) == 0 {
@@ -73,11 +102,27 @@ func (m *subjectRoleManager) BulkCreate(roles []SubjectRole) error {
 	return m.bulkInsert(roles)
 }
 
+// BulkCreateWithTx ...
+func (m *subjectRoleManager) BulkCreateWithTx(tx *sqlx.Tx, roles []SubjectRole) error {
+	if len(roles) == 0 {
+		return nil
+	}
+	return m.bulkInsertWithTx(tx, roles)
+}
+
 // BulkDelete ...
 func (m *subjectRoleManager) BulkDelete(roleType, system string, subjectPKs []int64) error {
 	return m.bulkDelete(roleType, system, subjectPKs)
 }
 
+// BulkDeleteWithTx ...
+func (m *subjectRoleManager) BulkDeleteWithTx(tx *sqlx.Tx, roleType, system string, subjectPKs []int64) error {
+	if len(subjectPKs) == 0 {
+		return nil
+	}
+	return m.bulkDeleteWithTx(tx, roleType, system, subjectPKs)
+}
+
 func (m *subjectRoleManager) selectSubjectPKByRole(subjectPKs *[]int64, roleType, system string) error {
 	query := `SELECT
 		subject_pk
@@ -98,12 +143,28 @@ func (m *subjectRoleManager) bulkInsert(roles []SubjectRole) error {
 	return database.SqlxBulkInsert(m.DB, sql, roles)
 }
 
+func (m *subjectRoleManager) bulkInsertWithTx(tx *sqlx.Tx, roles []SubjectRole) error {
+	sql := `INSERT INTO subject_role (
+		role_type,
+		system_id,
+		subject_pk
+	) VALUES (:role_type,
+		:system_id,
+		:subject_pk)`
+	return database.SqlxBulkInsertWithTx(tx, sql, roles)
+}
+
 func (m *subjectRoleManager) bulkDelete(roleType, system string, subjectPKs []int64) error {
 	sql := `DELETE FROM subject_role WHERE role_type = ? AND system_id = ? AND subject_pk in (?)`
 	_, err := database.SqlxDelete(m.DB, sql, roleType, system, subjectPKs)
 	return err
 }
 
+func (m *subjectRoleManager) bulkDeleteWithTx(tx *sqlx.Tx, roleType, system string, subjectPKs []int64) error {
+	sql := `DELETE FROM subject_role WHERE role_type = ? AND system_id = ? AND subject_pk in (?)`
+	return database.SqlxDeleteWithTx(tx, sql, roleType, system, subjectPKs)
+}
+
 func (m *subjectRoleManager) selectSubjectSystem(systemIDs *[]string, subjectPK int64) error {
 	query := `SELECT
 		system_id
@@ -112,3 +173,34 @@ func (m *subjectRoleManager) selectSubjectSystem(systemIDs *[]string, subjectPK
 		AND subject_pk = ?`
 	return database.SqlxSelect(m.DB, systemIDs, query, subjectPK)
 }
+
+func (m *subjectRoleManager) selectBySubjectPK(roles *[]SubjectRole, subjectPK int64) error {
+	query := `SELECT
+		pk,
+		role_type,
+		system_id,
+		subject_pk
+		FROM subject_role
+		WHERE subject_pk = ?
+		ORDER BY role_type, system_id`
+	return database.SqlxSelect(m.DB, roles, query, subjectPK)
+}
+
+func (m *subjectRoleManager) getCount(count *int64) error {
+	query := `SELECT
+		COUNT(*)
+		FROM subject_role`
+	return database.SqlxGet(m.DB, count, query)
+}
+
+func (m *subjectRoleManager) selectPaging(roles *[]SubjectRole, limit, offset int64) error {
+	query := `SELECT
+		pk,
+		role_type,
+		system_id,
+		subject_pk
+		FROM subject_role
+		ORDER BY role_type, system_id, subject_pk
+		LIMIT ? OFFSET ?`
+	return database.SqlxSelect(m.DB, roles, query, limit, offset)
+}
@@ -0,0 +1,125 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package dao
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"iam/pkg/database"
+)
+
+// SubjectRoleHistory 角色授予/回收的变更记录, CreatedAt即变更的生效时间
+type SubjectRoleHistory struct {
+	PK        int64     `db:"pk"`
+	SubjectPK int64     `db:"subject_pk"`
+	RoleType  string    `db:"role_type"`
+	System    string    `db:"system_id"`
+	Action    string    `db:"action"`   // granted / revoked
+	Operator  string    `db:"operator"` // 操作人
+	Source    string    `db:"source"`   // 发起变更的来源, 如调用方的app_code
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// SubjectRoleHistoryManager ...
+type SubjectRoleHistoryManager interface {
+	GetCountByRole(roleType, system string) (int64, error)
+	ListPagingByRole(roleType, system string, limit, offset int64) ([]SubjectRoleHistory, error)
+
+	BulkCreateWithTx(tx *sqlx.Tx, histories []SubjectRoleHistory) error
+}
+
+type subjectRoleHistoryManager struct {
+	DB *sqlx.DB
+}
+
+// NewSubjectRoleHistoryManager ...
+func NewSubjectRoleHistoryManager() SubjectRoleHistoryManager {
+	return &subjectRoleHistoryManager{
+		DB: database.GetDefaultDBClient().DB,
+	}
+}
+
+// GetCountByRole ...
+func (m *subjectRoleHistoryManager) GetCountByRole(roleType, system string) (count int64, err error) {
+	err = m.getCountByRole(&count, roleType, system)
+	return
+}
+
+// ListPagingByRole 按时间倒序查询角色的变更记录
+func (m *subjectRoleHistoryManager) ListPagingByRole(
+	roleType, system string, limit, offset int64,
+) (histories []SubjectRoleHistory, err error) {
+	err = m.selectPagingByRole(&histories, roleType, system, limit, offset)
+	if errors.Is(err, sql.ErrNoRows) {
+		return histories, nil
+	}
+	return
+}
+
+// BulkCreateWithTx ...
+func (m *subjectRoleHistoryManager) BulkCreateWithTx(tx *sqlx.Tx, histories []SubjectRoleHistory) error {
+	if len(histories) == 0 {
+		return nil
+	}
+	return m.bulkInsertWithTx(tx, histories)
+}
+
+func (m *subjectRoleHistoryManager) getCountByRole(count *int64, roleType, system string) error {
+	query := `SELECT
+		COUNT(*)
+		FROM subject_role_history
+		WHERE role_type = ?
+		AND system_id = ?`
+	return database.SqlxGet(m.DB, count, query, roleType, system)
+}
+
+func (m *subjectRoleHistoryManager) selectPagingByRole(
+	histories *[]SubjectRoleHistory, roleType, system string, limit, offset int64,
+) error {
+	query := `SELECT
+		pk,
+		subject_pk,
+		role_type,
+		system_id,
+		action,
+		operator,
+		source,
+		created_at
+		FROM subject_role_history
+		WHERE role_type = ?
+		AND system_id = ?
+		ORDER BY pk DESC
+		LIMIT ? OFFSET ?`
+	return database.SqlxSelect(m.DB, histories, query, roleType, system, limit, offset)
+}
+
+func (m *subjectRoleHistoryManager) bulkInsertWithTx(tx *sqlx.Tx, histories []SubjectRoleHistory) error {
+	sql := `INSERT INTO subject_role_history (
+		subject_pk,
+		role_type,
+		system_id,
+		action,
+		operator,
+		source
+	) VALUES (
+		:subject_pk,
+		:role_type,
+		:system_id,
+		:action,
+		:operator,
+		:source)`
+	return database.SqlxBulkInsertWithTx(tx, sql, histories)
+}
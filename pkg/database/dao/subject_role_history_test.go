@@ -0,0 +1,91 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package dao
+
+import (
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+)
+
+func Test_subjectRoleHistoryManager_GetCountByRole(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT COUNT\(\*\) FROM subject_role_history WHERE role_type = (.*) AND system_id = (.*)`
+		mockRows := sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(int64(2))
+		mock.ExpectQuery(mockQuery).WithArgs("system_manager", "bk_cmdb").WillReturnRows(mockRows)
+
+		manager := &subjectRoleHistoryManager{DB: db}
+		cnt, err := manager.GetCountByRole("system_manager", "bk_cmdb")
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, int64(2), cnt)
+	})
+}
+
+func Test_subjectRoleHistoryManager_ListPagingByRole(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Now()
+		mockQuery := `^SELECT pk, subject_pk, role_type, system_id, action, operator, source, created_at ` +
+			`FROM subject_role_history WHERE role_type = (.*) AND system_id = (.*) ` +
+			`ORDER BY pk DESC LIMIT (.*) OFFSET (.*)`
+		mockRows := sqlmock.NewRows([]string{
+			"pk", "subject_pk", "role_type", "system_id", "action", "operator", "source", "created_at",
+		}).AddRow(int64(2), int64(1), "system_manager", "bk_cmdb", "revoked", "admin", "bk_iam", now)
+		mock.ExpectQuery(mockQuery).WithArgs("system_manager", "bk_cmdb", int64(10), int64(0)).
+			WillReturnRows(mockRows)
+
+		manager := &subjectRoleHistoryManager{DB: db}
+		histories, err := manager.ListPagingByRole("system_manager", "bk_cmdb", int64(10), int64(0))
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []SubjectRoleHistory{{
+			PK:        2,
+			SubjectPK: 1,
+			RoleType:  "system_manager",
+			System:    "bk_cmdb",
+			Action:    "revoked",
+			Operator:  "admin",
+			Source:    "bk_iam",
+			CreatedAt: now,
+		}}, histories)
+	})
+}
+
+func Test_subjectRoleHistoryManager_BulkCreateWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`^INSERT INTO subject_role_history`).
+			WithArgs(int64(1), "system_manager", "bk_cmdb", "granted", "admin", "bk_iam").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &subjectRoleHistoryManager{DB: db}
+		err = manager.BulkCreateWithTx(tx, []SubjectRoleHistory{{
+			SubjectPK: 1,
+			RoleType:  "system_manager",
+			System:    "bk_cmdb",
+			Action:    "granted",
+			Operator:  "admin",
+			Source:    "bk_iam",
+		}})
+		assert.NoError(t, err)
+
+		err = tx.Commit()
+		assert.NoError(t, err)
+	})
+}
@@ -50,6 +50,29 @@ func Test_subjectRoleManager_BulkCreate(t *testing.T) {
 	})
 }
 
+func Test_subjectRoleManager_BulkCreateWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`^INSERT INTO subject_role`).WithArgs(
+			"system_manager", "bk_cmdb", int64(1), "system_manager", "bk_job", int64(1),
+		).WillReturnResult(sqlmock.NewResult(1, 2))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &subjectRoleManager{DB: db}
+		err = manager.BulkCreateWithTx(tx, []SubjectRole{
+			{RoleType: "system_manager", System: "bk_cmdb", SubjectPK: 1},
+			{RoleType: "system_manager", System: "bk_job", SubjectPK: 1},
+		})
+
+		tx.Commit()
+
+		assert.NoError(t, err, "query from db fail.")
+	})
+}
+
 func Test_subjectRoleManager_BulkDelete(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mockQuery := `^DELETE FROM subject_role WHERE role_type`
@@ -62,6 +85,26 @@ func Test_subjectRoleManager_BulkDelete(t *testing.T) {
 	})
 }
 
+func Test_subjectRoleManager_BulkDeleteWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`^DELETE FROM subject_role WHERE role_type`).
+			WithArgs("system_manager", "bk_cmdb", int64(1), int64(2)).
+			WillReturnResult(sqlmock.NewResult(1, 2))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &subjectRoleManager{DB: db}
+		err = manager.BulkDeleteWithTx(tx, "system_manager", "bk_cmdb", []int64{1, 2})
+		assert.NoError(t, err)
+
+		err = tx.Commit()
+		assert.NoError(t, err)
+	})
+}
+
 func Test_subjectRoleManager_ListSystemIDBySubjectPK(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mockQuery := `^SELECT (.*) FROM subject_role`
@@ -75,3 +118,53 @@ func Test_subjectRoleManager_ListSystemIDBySubjectPK(t *testing.T) {
 		assert.Equal(t, systems, []string{"bk_cmdb", "bk_job"})
 	})
 }
+
+func Test_subjectRoleManager_ListBySubjectPK(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT pk, role_type, system_id, subject_pk FROM subject_role WHERE subject_pk = (.*) ` +
+			`ORDER BY role_type, system_id`
+		mockRows := sqlmock.NewRows([]string{"pk", "role_type", "system_id", "subject_pk"}).
+			AddRow(int64(1), "super_manager", "SUPER", int64(1)).
+			AddRow(int64(2), "system_manager", "bk_cmdb", int64(1))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1)).WillReturnRows(mockRows)
+
+		manager := &subjectRoleManager{DB: db}
+		roles, err := manager.ListBySubjectPK(int64(1))
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []SubjectRole{
+			{PK: 1, RoleType: "super_manager", System: "SUPER", SubjectPK: 1},
+			{PK: 2, RoleType: "system_manager", System: "bk_cmdb", SubjectPK: 1},
+		}, roles)
+	})
+}
+
+func Test_subjectRoleManager_GetCount(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT COUNT\(\*\) FROM subject_role`
+		mockRows := sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(int64(2))
+		mock.ExpectQuery(mockQuery).WillReturnRows(mockRows)
+
+		manager := &subjectRoleManager{DB: db}
+		cnt, err := manager.GetCount()
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, int64(2), cnt)
+	})
+}
+
+func Test_subjectRoleManager_ListPaging(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT pk, role_type, system_id, subject_pk FROM subject_role ` +
+			`ORDER BY role_type, system_id, subject_pk LIMIT (.*) OFFSET (.*)`
+		mockRows := sqlmock.NewRows([]string{"pk", "role_type", "system_id", "subject_pk"}).
+			AddRow(int64(2), "system_manager", "bk_cmdb", int64(1))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(10), int64(0)).WillReturnRows(mockRows)
+
+		manager := &subjectRoleManager{DB: db}
+		roles, err := manager.ListPaging(int64(10), int64(0))
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []SubjectRole{{PK: 2, RoleType: "system_manager", System: "bk_cmdb", SubjectPK: 1}}, roles)
+	})
+}
@@ -0,0 +1,218 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package dao
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"iam/pkg/database"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+// SubjectSystemGroup subject在系统下有策略的用户组(直接加入或嵌套加入), 鉴权时只查询这些用户组的策略
+type SubjectSystemGroup struct {
+	PK              int64  `db:"pk"`
+	SubjectPK       int64  `db:"subject_pk"`
+	SystemID        string `db:"system_id"`
+	GroupPK         int64  `db:"group_pk"`
+	PolicyExpiredAt int64  `db:"policy_expired_at"`
+}
+
+// GroupSystem 用户组有策略的系统
+type GroupSystem struct {
+	GroupPK  int64  `db:"group_pk"`
+	SystemID string `db:"system_id"`
+}
+
+// SubjectSystemGroupManager ...
+type SubjectSystemGroupManager interface {
+	ListBySystemSubjectPKs(systemID string, subjectPKs []int64) ([]SubjectSystemGroup, error)
+	ListBySubjectPKs(subjectPKs []int64) ([]SubjectSystemGroup, error)
+	ListSubjectPKsAfterPK(afterPK, limit int64) ([]int64, error)
+	ListSubjectPKsBySystemGroup(systemID string, groupPK int64) ([]int64, error)
+	ListSubjectPKsByGroupPKs(groupPKs []int64) ([]int64, error)
+	ListSystemIDsByGroupPK(groupPK int64) ([]string, error)
+	ListGroupSystems(groupPKs []int64) ([]GroupSystem, error)
+
+	BulkCreateWithTx(tx *sqlx.Tx, groups []SubjectSystemGroup) error
+	BulkDeleteBySubjectPKsWithTx(tx *sqlx.Tx, subjectPKs []int64) error
+	DeleteBySystemGroup(systemID string, groupPK int64) (int64, error)
+}
+
+type subjectSystemGroupManager struct {
+	DB *sqlx.DB
+}
+
+// NewSubjectSystemGroupManager ...
+func NewSubjectSystemGroupManager() SubjectSystemGroupManager {
+	return &subjectSystemGroupManager{
+		DB: database.GetDefaultDBClient().DB,
+	}
+}
+
+// ListBySystemSubjectPKs 鉴权时查询subject在系统下有策略的用户组, 包括已过期的, 由调用方按过期时间过滤
+func (m *subjectSystemGroupManager) ListBySystemSubjectPKs(
+	systemID string, subjectPKs []int64,
+) (groups []SubjectSystemGroup, err error) {
+	if len(subjectPKs) == 0 {
+		return
+	}
+
+	query := `SELECT
+		pk,
+		subject_pk,
+		system_id,
+		group_pk,
+		policy_expired_at
+		FROM subject_system_group
+		WHERE subject_pk IN (?)
+		AND system_id = ?`
+	err = database.SqlxSelect(m.DB, &groups, query, subjectPKs, systemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return groups, nil
+	}
+	return
+}
+
+// ListBySubjectPKs 查询subjects在所有系统下的记录, 用于与重新计算的结果比对
+func (m *subjectSystemGroupManager) ListBySubjectPKs(subjectPKs []int64) (groups []SubjectSystemGroup, err error) {
+	if len(subjectPKs) == 0 {
+		return
+	}
+
+	query := `SELECT
+		pk,
+		subject_pk,
+		system_id,
+		group_pk,
+		policy_expired_at
+		FROM subject_system_group
+		WHERE subject_pk IN (?)`
+	err = database.SqlxSelect(m.DB, &groups, query, subjectPKs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return groups, nil
+	}
+	return
+}
+
+// ListSubjectPKsAfterPK 按pk顺序分批获取有记录的subject
+func (m *subjectSystemGroupManager) ListSubjectPKsAfterPK(afterPK, limit int64) (pks []int64, err error) {
+	query := `SELECT
+		DISTINCT subject_pk
+		FROM subject_system_group
+		WHERE subject_pk > ?
+		ORDER BY subject_pk
+		LIMIT ?`
+	err = database.SqlxSelect(m.DB, &pks, query, afterPK, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pks, nil
+	}
+	return
+}
+
+// ListSubjectPKsBySystemGroup ...
+func (m *subjectSystemGroupManager) ListSubjectPKsBySystemGroup(
+	systemID string, groupPK int64,
+) (pks []int64, err error) {
+	query := `SELECT
+		subject_pk
+		FROM subject_system_group
+		WHERE system_id = ?
+		AND group_pk = ?`
+	err = database.SqlxSelect(m.DB, &pks, query, systemID, groupPK)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pks, nil
+	}
+	return
+}
+
+// ListSubjectPKsByGroupPKs ...
+func (m *subjectSystemGroupManager) ListSubjectPKsByGroupPKs(groupPKs []int64) (pks []int64, err error) {
+	if len(groupPKs) == 0 {
+		return
+	}
+
+	query := `SELECT
+		DISTINCT subject_pk
+		FROM subject_system_group
+		WHERE group_pk IN (?)`
+	err = database.SqlxSelect(m.DB, &pks, query, groupPKs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pks, nil
+	}
+	return
+}
+
+// ListSystemIDsByGroupPK 用户组已记录的系统
+func (m *subjectSystemGroupManager) ListSystemIDsByGroupPK(groupPK int64) (systemIDs []string, err error) {
+	query := `SELECT
+		DISTINCT system_id
+		FROM subject_system_group
+		WHERE group_pk = ?`
+	err = database.SqlxSelect(m.DB, &systemIDs, query, groupPK)
+	if errors.Is(err, sql.ErrNoRows) {
+		return systemIDs, nil
+	}
+	return
+}
+
+// ListGroupSystems 用户组当前有策略的系统, policy表没有system_id, 通过action关联获取
+func (m *subjectSystemGroupManager) ListGroupSystems(groupPKs []int64) (groupSystems []GroupSystem, err error) {
+	if len(groupPKs) == 0 {
+		return
+	}
+
+	query := `SELECT
+		DISTINCT p.subject_pk AS group_pk,
+		a.system_id
+		FROM policy p
+		INNER JOIN action a ON a.pk = p.action_pk
+		WHERE p.subject_pk IN (?)`
+	err = database.SqlxSelect(m.DB, &groupSystems, query, groupPKs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return groupSystems, nil
+	}
+	return
+}
+
+// BulkCreateWithTx ...
+func (m *subjectSystemGroupManager) BulkCreateWithTx(tx *sqlx.Tx, groups []SubjectSystemGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO subject_system_group (
+		subject_pk,
+		system_id,
+		group_pk,
+		policy_expired_at
+	) VALUES (:subject_pk, :system_id, :group_pk, :policy_expired_at)`
+	return database.SqlxBulkInsertWithTx(tx, query, groups)
+}
+
+// BulkDeleteBySubjectPKsWithTx ...
+func (m *subjectSystemGroupManager) BulkDeleteBySubjectPKsWithTx(tx *sqlx.Tx, subjectPKs []int64) error {
+	if len(subjectPKs) == 0 {
+		return nil
+	}
+
+	query := `DELETE FROM subject_system_group WHERE subject_pk IN (?)`
+	return database.SqlxDeleteWithTx(tx, query, subjectPKs)
+}
+
+// DeleteBySystemGroup 用户组在系统下已没有策略时, 删除所有成员的记录
+func (m *subjectSystemGroupManager) DeleteBySystemGroup(systemID string, groupPK int64) (int64, error) {
+	query := `DELETE FROM subject_system_group WHERE system_id = ? AND group_pk = ?`
+	return database.SqlxDelete(m.DB, query, systemID, groupPK)
+}
@@ -0,0 +1,195 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package dao
+
+import (
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+)
+
+func Test_subjectSystemGroupManager_ListBySystemSubjectPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT pk, subject_pk, system_id, group_pk, policy_expired_at FROM subject_system_group ` +
+			`WHERE subject_pk IN (.*) AND system_id = (.*)`
+		mockRows := sqlmock.NewRows([]string{
+			"pk", "subject_pk", "system_id", "group_pk", "policy_expired_at",
+		}).AddRow(int64(1), int64(1), "bk_cmdb", int64(10), int64(4102444800))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(2), "bk_cmdb").WillReturnRows(mockRows)
+
+		manager := &subjectSystemGroupManager{DB: db}
+		groups, err := manager.ListBySystemSubjectPKs("bk_cmdb", []int64{1, 2})
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []SubjectSystemGroup{{
+			PK:              1,
+			SubjectPK:       1,
+			SystemID:        "bk_cmdb",
+			GroupPK:         10,
+			PolicyExpiredAt: 4102444800,
+		}}, groups)
+	})
+}
+
+func Test_subjectSystemGroupManager_ListBySubjectPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT pk, subject_pk, system_id, group_pk, policy_expired_at FROM subject_system_group ` +
+			`WHERE subject_pk IN (.*)`
+		mockRows := sqlmock.NewRows([]string{
+			"pk", "subject_pk", "system_id", "group_pk", "policy_expired_at",
+		}).AddRow(int64(1), int64(1), "bk_cmdb", int64(10), int64(4102444800))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(2)).WillReturnRows(mockRows)
+
+		manager := &subjectSystemGroupManager{DB: db}
+		groups, err := manager.ListBySubjectPKs([]int64{1, 2})
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []SubjectSystemGroup{{
+			PK:              1,
+			SubjectPK:       1,
+			SystemID:        "bk_cmdb",
+			GroupPK:         10,
+			PolicyExpiredAt: 4102444800,
+		}}, groups)
+	})
+}
+
+func Test_subjectSystemGroupManager_ListSubjectPKsAfterPK(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT DISTINCT subject_pk FROM subject_system_group WHERE subject_pk > (.*) ` +
+			`ORDER BY subject_pk LIMIT (.*)`
+		mockRows := sqlmock.NewRows([]string{"subject_pk"}).AddRow(int64(2)).AddRow(int64(3))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(1), int64(2)).WillReturnRows(mockRows)
+
+		manager := &subjectSystemGroupManager{DB: db}
+		pks, err := manager.ListSubjectPKsAfterPK(1, 2)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []int64{2, 3}, pks)
+	})
+}
+
+func Test_subjectSystemGroupManager_ListSubjectPKsBySystemGroup(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT subject_pk FROM subject_system_group WHERE system_id = (.*) AND group_pk = (.*)`
+		mockRows := sqlmock.NewRows([]string{"subject_pk"}).AddRow(int64(1)).AddRow(int64(2))
+		mock.ExpectQuery(mockQuery).WithArgs("bk_cmdb", int64(10)).WillReturnRows(mockRows)
+
+		manager := &subjectSystemGroupManager{DB: db}
+		pks, err := manager.ListSubjectPKsBySystemGroup("bk_cmdb", 10)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []int64{1, 2}, pks)
+	})
+}
+
+func Test_subjectSystemGroupManager_ListSubjectPKsByGroupPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT DISTINCT subject_pk FROM subject_system_group WHERE group_pk IN`
+		mockRows := sqlmock.NewRows([]string{"subject_pk"}).AddRow(int64(1))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(10), int64(11)).WillReturnRows(mockRows)
+
+		manager := &subjectSystemGroupManager{DB: db}
+		pks, err := manager.ListSubjectPKsByGroupPKs([]int64{10, 11})
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []int64{1}, pks)
+	})
+}
+
+func Test_subjectSystemGroupManager_ListSystemIDsByGroupPK(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT DISTINCT system_id FROM subject_system_group WHERE group_pk = (.*)`
+		mockRows := sqlmock.NewRows([]string{"system_id"}).AddRow("bk_cmdb").AddRow("bk_job")
+		mock.ExpectQuery(mockQuery).WithArgs(int64(10)).WillReturnRows(mockRows)
+
+		manager := &subjectSystemGroupManager{DB: db}
+		systemIDs, err := manager.ListSystemIDsByGroupPK(10)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []string{"bk_cmdb", "bk_job"}, systemIDs)
+	})
+}
+
+func Test_subjectSystemGroupManager_ListGroupSystems(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT DISTINCT p.subject_pk AS group_pk, a.system_id FROM policy p ` +
+			`INNER JOIN action a ON a.pk = p.action_pk WHERE p.subject_pk IN`
+		mockRows := sqlmock.NewRows([]string{"group_pk", "system_id"}).AddRow(int64(10), "bk_cmdb")
+		mock.ExpectQuery(mockQuery).WithArgs(int64(10), int64(11)).WillReturnRows(mockRows)
+
+		manager := &subjectSystemGroupManager{DB: db}
+		groupSystems, err := manager.ListGroupSystems([]int64{10, 11})
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []GroupSystem{{GroupPK: 10, SystemID: "bk_cmdb"}}, groupSystems)
+	})
+}
+
+func Test_subjectSystemGroupManager_BulkCreateWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`^INSERT INTO subject_system_group`).
+			WithArgs(int64(1), "bk_cmdb", int64(10), int64(4102444800)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &subjectSystemGroupManager{DB: db}
+		err = manager.BulkCreateWithTx(tx, []SubjectSystemGroup{{
+			SubjectPK:       1,
+			SystemID:        "bk_cmdb",
+			GroupPK:         10,
+			PolicyExpiredAt: 4102444800,
+		}})
+
+		tx.Commit()
+		assert.NoError(t, err)
+	})
+}
+
+func Test_subjectSystemGroupManager_BulkDeleteBySubjectPKsWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`^DELETE FROM subject_system_group WHERE subject_pk IN`).
+			WithArgs(int64(1), int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &subjectSystemGroupManager{DB: db}
+		err = manager.BulkDeleteBySubjectPKsWithTx(tx, []int64{1, 2})
+
+		tx.Commit()
+		assert.NoError(t, err)
+	})
+}
+
+func Test_subjectSystemGroupManager_DeleteBySystemGroup(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectExec(`^DELETE FROM subject_system_group WHERE system_id = (.*) AND group_pk = (.*)`).
+			WithArgs("bk_cmdb", int64(10)).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		manager := &subjectSystemGroupManager{DB: db}
+		cnt, err := manager.DeleteBySystemGroup("bk_cmdb", 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), cnt)
+	})
+}
@@ -0,0 +1,104 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package dao
+
+import (
+	"github.com/jmoiron/sqlx"
+
+	"iam/pkg/database"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+// SubjectTemplate subject与权限模板的绑定, 记录模板授权的过期时间, 模板生成的所有策略都使用该过期时间
+type SubjectTemplate struct {
+	PK         int64 `db:"pk"`
+	SubjectPK  int64 `db:"subject_pk"`
+	TemplateID int64 `db:"template_id"`
+	ExpiredAt  int64 `db:"expired_at"`
+}
+
+// SubjectTemplateManager ...
+type SubjectTemplateManager interface {
+	Get(subjectPK, templateID int64) (SubjectTemplate, error)
+	Create(subjectTemplate SubjectTemplate) error
+	UpdateExpiredAt(subjectTemplate SubjectTemplate) error
+	Delete(subjectPK, templateID int64) (int64, error)
+	BulkDeleteBySubjectPKsWithTx(tx *sqlx.Tx, subjectPKs []int64) error
+}
+
+type subjectTemplateManager struct {
+	DB *sqlx.DB
+}
+
+// NewSubjectTemplateManager ...
+func NewSubjectTemplateManager() SubjectTemplateManager {
+	return &subjectTemplateManager{
+		DB: database.GetDefaultDBClient().DB,
+	}
+}
+
+// NewBatchSubjectTemplateManager 使用批量任务的DB连接池
+func NewBatchSubjectTemplateManager() SubjectTemplateManager {
+	return &subjectTemplateManager{
+		DB: database.GetBatchDBClient().DB,
+	}
+}
+
+// Get ...
+func (m *subjectTemplateManager) Get(subjectPK, templateID int64) (subjectTemplate SubjectTemplate, err error) {
+	query := `SELECT
+		pk,
+		subject_pk,
+		template_id,
+		expired_at
+		FROM subject_template
+		WHERE subject_pk = ?
+		AND template_id = ?
+		LIMIT 1`
+	err = database.SqlxGet(m.DB, &subjectTemplate, query, subjectPK, templateID)
+	return
+}
+
+// Create ...
+func (m *subjectTemplateManager) Create(subjectTemplate SubjectTemplate) error {
+	query := `INSERT INTO subject_template (
+		subject_pk,
+		template_id,
+		expired_at
+	) VALUES (:subject_pk, :template_id, :expired_at)`
+	return database.SqlxBulkInsert(m.DB, query, []SubjectTemplate{subjectTemplate})
+}
+
+// UpdateExpiredAt ...
+func (m *subjectTemplateManager) UpdateExpiredAt(subjectTemplate SubjectTemplate) error {
+	query := `UPDATE subject_template
+		SET expired_at = :expired_at
+		WHERE subject_pk = :subject_pk
+		AND template_id = :template_id`
+	_, err := database.SqlxUpdate(m.DB, query, subjectTemplate)
+	return err
+}
+
+// Delete ...
+func (m *subjectTemplateManager) Delete(subjectPK, templateID int64) (int64, error) {
+	query := `DELETE FROM subject_template WHERE subject_pk = ? AND template_id = ?`
+	return database.SqlxDelete(m.DB, query, subjectPK, templateID)
+}
+
+// BulkDeleteBySubjectPKsWithTx ...
+func (m *subjectTemplateManager) BulkDeleteBySubjectPKsWithTx(tx *sqlx.Tx, subjectPKs []int64) error {
+	if len(subjectPKs) == 0 {
+		return nil
+	}
+
+	query := `DELETE FROM subject_template WHERE subject_pk IN (?)`
+	return database.SqlxDeleteWithTx(tx, query, subjectPKs)
+}
@@ -0,0 +1,108 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package dao
+
+import (
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+)
+
+func Test_subjectTemplateManager_Get(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT pk, subject_pk, template_id, expired_at FROM subject_template ` +
+			`WHERE subject_pk = (.*) AND template_id = (.*) LIMIT 1`
+		mockRows := sqlmock.NewRows([]string{"pk", "subject_pk", "template_id", "expired_at"}).
+			AddRow(int64(1), int64(10), int64(2), int64(4102444800))
+		mock.ExpectQuery(mockQuery).WithArgs(int64(10), int64(2)).WillReturnRows(mockRows)
+
+		manager := &subjectTemplateManager{DB: db}
+		subjectTemplate, err := manager.Get(10, 2)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, SubjectTemplate{
+			PK:         1,
+			SubjectPK:  10,
+			TemplateID: 2,
+			ExpiredAt:  4102444800,
+		}, subjectTemplate)
+	})
+}
+
+func Test_subjectTemplateManager_Create(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectExec(`^INSERT INTO subject_template`).
+			WithArgs(int64(10), int64(2), int64(4102444800)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		manager := &subjectTemplateManager{DB: db}
+		err := manager.Create(SubjectTemplate{
+			SubjectPK:  10,
+			TemplateID: 2,
+			ExpiredAt:  4102444800,
+		})
+
+		assert.NoError(t, err)
+	})
+}
+
+func Test_subjectTemplateManager_UpdateExpiredAt(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectExec(`^UPDATE subject_template SET expired_at = (.*) WHERE subject_pk = (.*)`).
+			WithArgs(int64(4102444800), int64(10), int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		manager := &subjectTemplateManager{DB: db}
+		err := manager.UpdateExpiredAt(SubjectTemplate{
+			SubjectPK:  10,
+			TemplateID: 2,
+			ExpiredAt:  4102444800,
+		})
+
+		assert.NoError(t, err)
+	})
+}
+
+func Test_subjectTemplateManager_Delete(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectExec(`^DELETE FROM subject_template WHERE subject_pk = (.*) AND template_id = (.*)`).
+			WithArgs(int64(10), int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		manager := &subjectTemplateManager{DB: db}
+		cnt, err := manager.Delete(10, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), cnt)
+	})
+}
+
+func Test_subjectTemplateManager_BulkDeleteBySubjectPKsWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`^DELETE FROM subject_template WHERE subject_pk IN`).
+			WithArgs(int64(10), int64(11)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &subjectTemplateManager{DB: db}
+		err = manager.BulkDeleteBySubjectPKsWithTx(tx, []int64{10, 11})
+
+		tx.Commit()
+		assert.NoError(t, err)
+	})
+}
@@ -63,6 +63,77 @@ func Test_subjectManager_ListPaging(t *testing.T) {
 	})
 }
 
+func Test_subjectManager_SearchPaging(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockData := []interface{}{
+			Subject{
+				PK:   1,
+				Type: "user",
+				ID:   "admin",
+				Name: "admin",
+			},
+		}
+
+		mockQuery := `^SELECT pk, type, id, name FROM subject WHERE type = .* AND \(id LIKE .* OR name LIKE .*\) LIMIT`
+		mockRows := database.NewMockRows(mock, mockData...)
+		mock.ExpectQuery(mockQuery).WithArgs("user", `ad\_%`, `ad\_%`, 10, 0).WillReturnRows(mockRows)
+
+		manager := &subjectManager{DB: db}
+		subjects, err := manager.SearchPaging("user", "ad_", 10, 0)
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Len(t, subjects, 1)
+	})
+}
+
+func Test_subjectManager_GetSearchCount(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT COUNT\(\*\) FROM subject WHERE \(id LIKE .* OR name LIKE .*\)`
+		mockRows := sqlmock.NewRows([]string{"count"}).AddRow(int64(3))
+		mock.ExpectQuery(mockQuery).WithArgs("ad%", "ad%").WillReturnRows(mockRows)
+
+		manager := &subjectManager{DB: db}
+		cnt, err := manager.GetSearchCount("", "ad")
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, int64(3), cnt)
+	})
+}
+
+func Test_subjectManager_ListFrozenPKs(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockQuery := `^SELECT pk FROM subject WHERE frozen = 1`
+		mockRows := sqlmock.NewRows([]string{"pk"}).AddRow(int64(1)).AddRow(int64(2))
+		mock.ExpectQuery(mockQuery).WillReturnRows(mockRows)
+
+		manager := &subjectManager{DB: db}
+		pks, err := manager.ListFrozenPKs()
+
+		assert.NoError(t, err, "query from db fail.")
+		assert.Equal(t, []int64{1, 2}, pks)
+	})
+}
+
+func Test_subjectManager_UpdateFrozenByPKsWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE subject SET frozen = (.*) WHERE pk IN`).WithArgs(
+			true, int64(1), int64(2),
+		).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &subjectManager{DB: db}
+		cnt, err := manager.UpdateFrozenByPKsWithTx(tx, []int64{1, 2}, true)
+
+		tx.Commit()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), cnt)
+	})
+}
+
 //func Test_subjectManager_Delete(t *testing.T) {
 //	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 //		mockQuery := `^DELETE FROM subject`
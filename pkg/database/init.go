@@ -26,15 +26,18 @@ import (
 var (
 	// DefaultDBClient 默认DB实例
 	DefaultDBClient *DBClient
+	// BatchDBClient 批量任务(全量同步/清理/统计等)使用的DB实例, 与默认DB实例使用不同的连接池
+	BatchDBClient *DBClient
 	// BKPaaSDBClient BKPaaS DB实例
 	BKPaaSDBClient *DBClient
 )
 
 var defaultDBClientOnce sync.Once
+var batchDBClientOnce sync.Once
 var bkPaaSDBClientOnce sync.Once
 
 // InitDBClients ...
-func InitDBClients(defaultDBConfig, bkPaaSDBConfig *config.Database) {
+func InitDBClients(defaultDBConfig, batchDBConfig, bkPaaSDBConfig *config.Database) {
 	if DefaultDBClient == nil {
 		defaultDBClientOnce.Do(func() {
 			DefaultDBClient = NewDBClient(defaultDBConfig)
@@ -50,6 +53,19 @@ func InitDBClients(defaultDBConfig, bkPaaSDBConfig *config.Database) {
 		})
 	}
 
+	// NOTE: batch scans should not exhaust the pool serving the latency-sensitive requests
+	if BatchDBClient == nil {
+		batchDBClientOnce.Do(func() {
+			BatchDBClient = NewDBClient(batchDBConfig)
+			if err := BatchDBClient.Connect(); err != nil {
+				panic(err)
+			}
+
+			collector := sqlstats.NewStatsCollector(batchDBConfig.Name+"_batch", BatchDBClient.DB)
+			prometheus.MustRegister(collector)
+		})
+	}
+
 	// NOTE: change to app_code/app_secret verify api in the future
 	if BKPaaSDBClient == nil {
 		bkPaaSDBClientOnce.Do(func() {
@@ -66,6 +82,11 @@ func GetDefaultDBClient() *DBClient {
 	return DefaultDBClient
 }
 
+// GetBatchDBClient 获取批量任务使用的DB实例
+func GetBatchDBClient() *DBClient {
+	return BatchDBClient
+}
+
 // GetBKPaaSDBClient BKPaas DB的实例
 func GetBKPaaSDBClient() *DBClient {
 	return BKPaaSDBClient
@@ -75,3 +96,8 @@ func GetBKPaaSDBClient() *DBClient {
 func GenerateDefaultDBTx() (*sqlx.Tx, error) {
 	return GetDefaultDBClient().DB.Beginx()
 }
+
+// GenerateBatchDBTx 在批量任务的DB实例上生成一个事务链接
+func GenerateBatchDBTx() (*sqlx.Tx, error) {
+	return GetBatchDBClient().DB.Beginx()
+}
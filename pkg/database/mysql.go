@@ -33,6 +33,38 @@ const (
 	defaultConnMaxLifetime = 10 * time.Minute
 )
 
+// the batch pool is smaller, and keep the connections longer for the long-running scans
+const (
+	DefaultBatchMaxOpenConns    = 10
+	DefaultBatchMaxIdleConns    = 2
+	DefaultBatchConnMaxLifetime = 30 * time.Minute
+
+	// the batch queries should not hang forever on a broken connection
+	DefaultBatchReadTimeout  = 5 * time.Minute
+	DefaultBatchWriteTimeout = 1 * time.Minute
+)
+
+// NewBatchDBConfig make the config of batch pool from the default database config, with the batch pool settings
+func NewBatchDBConfig(cfg config.Database) config.Database {
+	cfg.MaxOpenConns = DefaultBatchMaxOpenConns
+	cfg.MaxIdleConns = DefaultBatchMaxIdleConns
+	cfg.ConnMaxLifetimeSecond = int(DefaultBatchConnMaxLifetime.Seconds())
+	cfg.ReadTimeoutSecond = int(DefaultBatchReadTimeout.Seconds())
+	cfg.WriteTimeoutSecond = int(DefaultBatchWriteTimeout.Seconds())
+	return cfg
+}
+
+// FillBatchDBConfigTimeout fill the default read/write timeout of batch pool if not configured
+func FillBatchDBConfigTimeout(cfg config.Database) config.Database {
+	if cfg.ReadTimeoutSecond <= 0 {
+		cfg.ReadTimeoutSecond = int(DefaultBatchReadTimeout.Seconds())
+	}
+	if cfg.WriteTimeoutSecond <= 0 {
+		cfg.WriteTimeoutSecond = int(DefaultBatchWriteTimeout.Seconds())
+	}
+	return cfg
+}
+
 // DBClient MySQL DB Instance
 type DBClient struct {
 	name string
@@ -44,6 +76,9 @@ type DBClient struct {
 	maxOpenConns    int
 	maxIdleConns    int
 	connMaxLifetime time.Duration
+
+	readTimeout  time.Duration
+	writeTimeout time.Duration
 }
 
 // TestConnection ...
@@ -69,8 +104,9 @@ func (db *DBClient) Connect() error {
 	db.DB.SetMaxIdleConns(db.maxIdleConns)
 	db.DB.SetConnMaxLifetime(db.connMaxLifetime)
 
-	log.Infof("connect to database: %s[maxOpenConns=%d, maxIdleConns=%d, connMaxLifetime=%s]",
-		db.name, db.maxOpenConns, db.maxIdleConns, db.connMaxLifetime)
+	log.Infof("connect to database: %s[maxOpenConns=%d, maxIdleConns=%d, connMaxLifetime=%s, "+
+		"readTimeout=%s, writeTimeout=%s]",
+		db.name, db.maxOpenConns, db.maxIdleConns, db.connMaxLifetime, db.readTimeout, db.writeTimeout)
 
 	return nil
 }
@@ -94,6 +130,16 @@ func NewDBClient(cfg *config.Database) *DBClient {
 		"UTC",
 	)
 
+	// NOTE: 未配置时不设置, 使用驱动默认的不超时
+	readTimeout := time.Duration(cfg.ReadTimeoutSecond) * time.Second
+	if readTimeout > 0 {
+		dataSource += fmt.Sprintf("&readTimeout=%s", readTimeout)
+	}
+	writeTimeout := time.Duration(cfg.WriteTimeoutSecond) * time.Second
+	if writeTimeout > 0 {
+		dataSource += fmt.Sprintf("&writeTimeout=%s", writeTimeout)
+	}
+
 	maxOpenConns := defaultMaxOpenConns
 	if cfg.MaxOpenConns > 0 {
 		maxOpenConns = cfg.MaxOpenConns
@@ -129,5 +175,7 @@ func NewDBClient(cfg *config.Database) *DBClient {
 		maxOpenConns:    maxOpenConns,
 		maxIdleConns:    maxIdleConns,
 		connMaxLifetime: connMaxLifetime,
+		readTimeout:     readTimeout,
+		writeTimeout:    writeTimeout,
 	}
 }
@@ -0,0 +1,64 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package database
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/config"
+)
+
+var _ = Describe("Mysql", func() {
+
+	It("NewBatchDBConfig", func() {
+		cfg := config.Database{
+			ID:                    "iam",
+			Host:                  "127.0.0.1",
+			Port:                  3306,
+			Name:                  "bkiam",
+			MaxOpenConns:          200,
+			MaxIdleConns:          50,
+			ConnMaxLifetimeSecond: 600,
+		}
+
+		batchCfg := NewBatchDBConfig(cfg)
+		assert.Equal(GinkgoT(), "127.0.0.1", batchCfg.Host)
+		assert.Equal(GinkgoT(), "bkiam", batchCfg.Name)
+		assert.Equal(GinkgoT(), DefaultBatchMaxOpenConns, batchCfg.MaxOpenConns)
+		assert.Equal(GinkgoT(), DefaultBatchMaxIdleConns, batchCfg.MaxIdleConns)
+		assert.Equal(GinkgoT(), 1800, batchCfg.ConnMaxLifetimeSecond)
+		assert.Equal(GinkgoT(), 300, batchCfg.ReadTimeoutSecond)
+		assert.Equal(GinkgoT(), 60, batchCfg.WriteTimeoutSecond)
+
+		// the default config is not changed
+		assert.Equal(GinkgoT(), 200, cfg.MaxOpenConns)
+		assert.Equal(GinkgoT(), 0, cfg.ReadTimeoutSecond)
+	})
+
+	It("FillBatchDBConfigTimeout", func() {
+		cfg := FillBatchDBConfigTimeout(config.Database{ReadTimeoutSecond: 600})
+		assert.Equal(GinkgoT(), 600, cfg.ReadTimeoutSecond)
+		assert.Equal(GinkgoT(), 60, cfg.WriteTimeoutSecond)
+	})
+
+	It("NewDBClient timeout", func() {
+		client := NewDBClient(&config.Database{Name: "bkiam"})
+		assert.NotContains(GinkgoT(), client.dataSource, "Timeout")
+
+		client = NewDBClient(&config.Database{Name: "bkiam", ReadTimeoutSecond: 300, WriteTimeoutSecond: 60})
+		assert.Contains(GinkgoT(), client.dataSource, "&readTimeout=5m0s&writeTimeout=1m0s")
+		assert.Equal(GinkgoT(), 5*time.Minute, client.readTimeout)
+		assert.Equal(GinkgoT(), time.Minute, client.writeTimeout)
+	})
+})
@@ -0,0 +1,83 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// NOTE: 周期性采样连接池的等待情况, 采样周期内获取连接的平均等待时间超过阈值时, 认为DB有压力;
+//       用于在DB压力大时, 限制低优先级的请求(写/列表等), 保证鉴权/查询的请求不受影响
+
+const (
+	defaultPressureCheckInterval = 5 * time.Second
+	defaultPressureWaitThreshold = 100 * time.Millisecond
+)
+
+var underPressure int32
+
+// IsUnderPressure DB连接池是否有压力
+func IsUnderPressure() bool {
+	return atomic.LoadInt32(&underPressure) == 1
+}
+
+func setUnderPressure(pressure bool) {
+	var value int32
+	if pressure {
+		value = 1
+	}
+
+	if atomic.SwapInt32(&underPressure, value) != value {
+		log.Warnf("the database connection pool pressure changed, under_pressure=%t", pressure)
+	}
+}
+
+// isPoolUnderPressure 两次采样之间, 获取连接的平均等待时间是否超过阈值
+func isPoolUnderPressure(prev, cur sql.DBStats, threshold time.Duration) bool {
+	waitCount := cur.WaitCount - prev.WaitCount
+	if waitCount <= 0 {
+		return false
+	}
+
+	waitDuration := cur.WaitDuration - prev.WaitDuration
+	return waitDuration/time.Duration(waitCount) > threshold
+}
+
+// RunPressureMonitor 定时采样DB连接池的状态, 阻塞直到ctx结束
+func RunPressureMonitor(ctx context.Context, client *DBClient, interval, threshold time.Duration) {
+	if interval <= 0 {
+		interval = defaultPressureCheckInterval
+	}
+	if threshold <= 0 {
+		threshold = defaultPressureWaitThreshold
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	prev := client.DB.Stats()
+	for {
+		select {
+		case <-ctx.Done():
+			setUnderPressure(false)
+			return
+		case <-ticker.C:
+			cur := client.DB.Stats()
+			setUnderPressure(isPoolUnderPressure(prev, cur, threshold))
+			prev = cur
+		}
+	}
+}
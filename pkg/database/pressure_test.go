@@ -0,0 +1,48 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ = Describe("Pressure", func() {
+
+	Describe("isPoolUnderPressure", func() {
+		prev := sql.DBStats{WaitCount: 10, WaitDuration: time.Second}
+
+		It("no wait", func() {
+			assert.False(GinkgoT(), isPoolUnderPressure(prev, prev, 100*time.Millisecond))
+		})
+
+		It("wait less than threshold", func() {
+			cur := sql.DBStats{WaitCount: 20, WaitDuration: 1500 * time.Millisecond}
+			assert.False(GinkgoT(), isPoolUnderPressure(prev, cur, 100*time.Millisecond))
+		})
+
+		It("wait more than threshold", func() {
+			cur := sql.DBStats{WaitCount: 20, WaitDuration: 3 * time.Second}
+			assert.True(GinkgoT(), isPoolUnderPressure(prev, cur, 100*time.Millisecond))
+		})
+	})
+
+	It("setUnderPressure", func() {
+		setUnderPressure(true)
+		assert.True(GinkgoT(), IsUnderPressure())
+
+		setUnderPressure(false)
+		assert.False(GinkgoT(), IsUnderPressure())
+	})
+})
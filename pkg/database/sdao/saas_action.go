@@ -35,6 +35,7 @@ type SaaSAction struct {
 	DescriptionEn  string `db:"description_en"`
 	RelatedActions string `db:"related_actions"`
 	Type           string `db:"type"`
+	Status         string `db:"status"`
 	Version        int64  `db:"version"`
 }
 
@@ -119,8 +120,10 @@ func (m *saasActionManager) bulkInsertWithTx(tx *sqlx.Tx, saasActions []SaaSActi
 		description_en,
 		related_actions,
 		type,
+		status,
 		version
-	) VALUES (:system_id, :id, :name, :name_en, :description, :description_en, :related_actions, :type, :version)`
+	) VALUES (:system_id, :id, :name, :name_en, :description, :description_en, :related_actions, :type, :status,
+		:version)`
 	return database.SqlxBulkInsertWithTx(tx, query, saasActions)
 }
 
@@ -148,6 +151,7 @@ func (m *saasActionManager) selectBySystem(saasAction *[]SaaSAction, system stri
 		description_en,
 		related_actions,
 		type,
+		status,
 		version
 		FROM saas_action
 		WHERE system_id = ?
@@ -163,6 +167,7 @@ func (m *saasActionManager) getByActionID(saasAction *SaaSAction, system, action
 		name,
 		name_en,
 		type,
+		status,
 		version
 		FROM saas_action
 		WHERE system_id = ?
@@ -239,6 +239,17 @@ func deleteReturnRowsWithTxTimer(f deleteReturnRowsWithTxFunc) deleteReturnRowsW
 	}
 }
 
+type execReturnRowsWithTxFunc func(tx *sqlx.Tx, query string, args ...interface{}) (int64, error)
+
+func execReturnRowsWithTxTimer(f execReturnRowsWithTxFunc) execReturnRowsWithTxFunc {
+	return func(tx *sqlx.Tx, query string, args ...interface{}) (int64, error) {
+		start := time.Now()
+		defer logSlowSQL(start, query, args)
+		// NOTE: must be args...
+		return f(tx, query, args...)
+	}
+}
+
 type updateWithTxFunc func(tx *sqlx.Tx, query string, args interface{}) (int64, error)
 
 func updateWithTxTimer(f updateWithTxFunc) updateWithTxFunc {
@@ -340,6 +351,23 @@ func sqlxDeleteReturnRowsWithTx(tx *sqlx.Tx, query string, args ...interface{})
 	return rowsAffected, nil
 }
 
+func sqlxExecReturnRowsWithTx(tx *sqlx.Tx, query string, args ...interface{}) (int64, error) {
+	query, args, err := sqlx.In(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return rowsAffected, nil
+}
+
 func sqlxUpdateWithTx(tx *sqlx.Tx, query string, args interface{}) (int64, error) {
 	result, err := tx.NamedExec(query, args)
 	if err != nil {
@@ -375,6 +403,7 @@ var (
 	SqlxDeleteWithTx             = execWithTxTimer(sqlxDeleteWithTx)
 	SqlxDeleteReturnRowsWithTx   = deleteReturnRowsWithTxTimer(sqlxDeleteReturnRowsWithTx)
 	SqlxUpdateWithTx             = updateWithTxTimer(sqlxUpdateWithTx)
+	SqlxExecReturnRowsWithTx     = execReturnRowsWithTxTimer(sqlxExecReturnRowsWithTx)
 	// SqlxExecWithTx               = execWithTxTimer(sqlxExecWithTx)
 
 	// SqlxSensitiveGet will query without timer and logger
@@ -139,3 +139,10 @@ func ParseUpdateStruct(values interface{}, allowBlankFields AllowBlankFields) (s
 
 	return setExpr, updateData, nil
 }
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escape the wildcards in the keyword of `LIKE`, with the default escape character `\`
+func EscapeLike(keyword string) string {
+	return likeEscaper.Replace(keyword)
+}
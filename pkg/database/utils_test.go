@@ -175,3 +175,8 @@ func BenchmarkTruncateInterfaceViaJsonToBytes(b *testing.B) {
 		truncateInterfaceViaJSONToBytes(x)
 	}
 }
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, "admin", EscapeLike("admin"))
+	assert.Equal(t, `a\%b\_c\\d`, EscapeLike(`a%b_c\d`))
+}
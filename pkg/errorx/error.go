@@ -17,8 +17,10 @@ import (
 
 // IAMError is a wrapped struct for err
 type IAMError struct {
-	message string
-	err     error
+	message  string
+	err      error
+	layer    string
+	function string
 }
 
 // Error show the error message
@@ -35,6 +37,11 @@ func (e IAMError) Is(target error) bool {
 	return errors.Is(e.err, target)
 }
 
+// As check if the wrapped error can be assigned to target
+func (e IAMError) As(target interface{}) bool {
+	return errors.As(e.err, target)
+}
+
 // Unwrap will unwrap the wrapped error
 func (e *IAMError) Unwrap() error {
 	u, ok := e.err.(interface {
@@ -67,8 +74,10 @@ func Wrap(err error, layer string, function string, message string) error {
 	}
 
 	return IAMError{
-		message: makeMessage(err, layer, function, message),
-		err:     err,
+		message:  makeMessage(err, layer, function, message),
+		err:      err,
+		layer:    layer,
+		function: function,
 	}
 }
 
@@ -81,9 +90,25 @@ func Wrapf(err error, layer string, function string, format string, args ...inte
 	msg := fmt.Sprintf(format, args...)
 
 	return IAMError{
-		message: makeMessage(err, layer, function, msg),
-		err:     err,
+		message:  makeMessage(err, layer, function, msg),
+		err:      err,
+		layer:    layer,
+		function: function,
+	}
+}
+
+// Breadcrumb return the `layer:function` of all the wrapped IAMError, from the outermost to the innermost
+func Breadcrumb(err error) []string {
+	breadcrumb := []string{}
+	for err != nil {
+		if e, ok := err.(IAMError); ok {
+			breadcrumb = append(breadcrumb, fmt.Sprintf("%s:%s", e.layer, e.function))
+			err = e.err
+			continue
+		}
+		err = errors.Unwrap(err)
 	}
+	return breadcrumb
 }
 
 // WrapFuncWithLayerFunction is a type alias for Wrap func
@@ -12,6 +12,7 @@ package errorx
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -62,3 +63,16 @@ func TestIAMError_Is(t *testing.T) {
 	assert.True(t, errors.Is(e5, e4))
 	assert.False(t, errors.Is(e4, e5))
 }
+
+func TestBreadcrumb(t *testing.T) {
+	assert.Empty(t, Breadcrumb(nil))
+
+	e1 := errors.New("a")
+	assert.Empty(t, Breadcrumb(e1))
+
+	e2 := Wrapf(e1, "Service", "Get", "get fail")
+	e3 := fmt.Errorf("wrapped: %w", e2)
+	e4 := Wrap(e3, "Handler", "Get", "get fail")
+
+	assert.Equal(t, []string{"Handler:Get", "Service:Get"}, Breadcrumb(e4))
+}
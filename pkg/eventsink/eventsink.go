@@ -0,0 +1,55 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package eventsink
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/cache/redis"
+	"iam/pkg/config"
+	"iam/pkg/service"
+)
+
+// TypeRedisStream ...
+const TypeRedisStream = "redis_stream"
+
+// 默认配置
+const (
+	DefaultStream = "iam:subject_relation_events"
+	DefaultMaxLen = 100000
+)
+
+// Init 按配置设置用户组成员关系变更事件的投递目标, 未配置时不投递
+// NOTE: should be after initRedis
+func Init(cfg config.SubjectRelationEventSink) {
+	switch cfg.Type {
+	case "":
+		log.Info("subject relation event sink is not configured, will not send the events")
+		return
+	case TypeRedisStream:
+		stream := cfg.Stream
+		if stream == "" {
+			stream = DefaultStream
+		}
+		maxLen := cfg.MaxLen
+		if maxLen <= 0 {
+			maxLen = DefaultMaxLen
+		}
+
+		service.SetSubjectRelationEventSink(NewRedisStreamSink(redis.GetDefaultRedisClient(), stream, maxLen))
+		log.Infof("init subject relation event sink type=`%s`, stream=`%s`, maxLen=`%d`",
+			cfg.Type, stream, maxLen)
+	default:
+		panic(fmt.Sprintf("init subject relation event sink fail, invalid type `%s`, should be `%s`",
+			cfg.Type, TypeRedisStream))
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package eventsink
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+)
+
+// RedisStreamSendTimeout ...
+const RedisStreamSendTimeout = 2 * time.Second
+
+type pipelinedClient interface {
+	Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
+}
+
+// redisStreamSink 每个事件为stream中的一条消息, 字段`data`为事件的json, 下游通过consumer group消费
+type redisStreamSink struct {
+	cli    pipelinedClient
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamSink ...
+func NewRedisStreamSink(cli pipelinedClient, stream string, maxLen int64) service.SubjectRelationEventSink {
+	return &redisStreamSink{
+		cli:    cli,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+// Send 一次pipeline写入所有事件
+func (s *redisStreamSink) Send(events []service.SubjectRelationEvent) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("RedisStreamSink", "Send")
+
+	values := make([]string, 0, len(events))
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return errorWrapf(err, "json.Marshal event=`%+v` fail", e)
+		}
+		values = append(values, string(data))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), RedisStreamSendTimeout)
+	defer cancel()
+
+	_, err := s.cli.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, v := range values {
+			pipe.XAdd(ctx, &redis.XAddArgs{
+				Stream:       s.stream,
+				MaxLenApprox: s.maxLen,
+				Values:       map[string]interface{}{"data": v},
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return errorWrapf(err, "xadd stream=`%s`, events=`%d` fail", s.stream, len(events))
+	}
+	return nil
+}
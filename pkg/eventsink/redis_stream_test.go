@@ -0,0 +1,89 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package eventsink
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/service"
+)
+
+// fakePipeliner only records the xadd args
+type fakePipeliner struct {
+	redis.Pipeliner
+	args []*redis.XAddArgs
+}
+
+func (p *fakePipeliner) XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd {
+	p.args = append(p.args, a)
+	return redis.NewStringCmd(ctx)
+}
+
+type fakePipelinedClient struct {
+	pipe *fakePipeliner
+	err  error
+}
+
+func (c *fakePipelinedClient) Pipelined(
+	ctx context.Context, fn func(redis.Pipeliner) error,
+) ([]redis.Cmder, error) {
+	if err := fn(c.pipe); err != nil {
+		return nil, err
+	}
+	return nil, c.err
+}
+
+func TestRedisStreamSink_Send(t *testing.T) {
+	events := []service.SubjectRelationEvent{
+		{
+			Action:          service.SubjectRelationEventActionAdded,
+			GroupPK:         1,
+			GroupType:       "group",
+			GroupID:         "10",
+			SubjectPK:       2,
+			SubjectType:     "user",
+			SubjectID:       "tom",
+			PolicyExpiredAt: 4102444800,
+			Timestamp:       1630000000,
+		},
+		{Action: service.SubjectRelationEventActionRemoved, GroupPK: 1, SubjectPK: 3},
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		cli := &fakePipelinedClient{pipe: &fakePipeliner{}}
+		sink := NewRedisStreamSink(cli, "test_stream", 100)
+
+		err := sink.Send(events)
+		assert.NoError(t, err)
+		if assert.Len(t, cli.pipe.args, 2) {
+			assert.Equal(t, "test_stream", cli.pipe.args[0].Stream)
+			assert.Equal(t, int64(100), cli.pipe.args[0].MaxLenApprox)
+			assert.Equal(t, map[string]interface{}{
+				"data": `{"action":"added","group_pk":1,"group_type":"group","group_id":"10",` +
+					`"subject_pk":2,"subject_type":"user","subject_id":"tom",` +
+					`"policy_expired_at":4102444800,"timestamp":1630000000}`,
+			}, cli.pipe.args[0].Values)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		cli := &fakePipelinedClient{pipe: &fakePipeliner{}, err: errors.New("error")}
+		sink := NewRedisStreamSink(cli, "test_stream", 100)
+
+		err := sink.Send(events)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "xadd stream=`test_stream`")
+	})
+}
@@ -0,0 +1,201 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package export
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/component"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	"iam/pkg/service/types"
+)
+
+// 分批查询, 每批的数量
+var exportPageSize int64 = 1000
+
+const csvContentType = "text/csv"
+
+// anonymizer 将用户ID替换为稳定的hash, 相同的salt下同一个用户的hash不变, 便于关联分析
+type anonymizer func(_type, id string) string
+
+func newAnonymizer(salt string) anonymizer {
+	return func(_type, id string) string {
+		// NOTE: 只有用户名是敏感信息, 部门/用户组的ID为自增的数字
+		if _type != types.UserType {
+			return id
+		}
+
+		mac := hmac.New(sha256.New, []byte(salt))
+		mac.Write([]byte(id))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+}
+
+type subjectRelationExporter struct {
+	svc        service.SubjectExportService
+	storage    component.ObjectStorageClient
+	anonymizer anonymizer
+
+	task Task
+}
+
+func (e *subjectRelationExporter) run() {
+	datasets := []struct {
+		name  string
+		build func() ([]byte, error)
+	}{
+		{"subjects", e.buildSubjects},
+		{"subject_relations", e.buildRelations},
+		{"subject_departments", e.buildSubjectDepartments},
+	}
+
+	for _, dataset := range datasets {
+		path := e.task.ID + "/" + dataset.name + ".csv"
+
+		data, err := dataset.build()
+		if err == nil {
+			err = e.storage.Upload(path, csvContentType, data)
+		}
+		if err != nil {
+			err = errorx.Wrapf(err, ExportLayer, "subjectRelationExporter.run", "export dataset `%s` fail", dataset.name)
+			log.WithError(err).Errorf("export subject relations fail, task=`%+v`", e.task)
+
+			e.task.Status = TaskStatusFailed
+			e.task.Error = err.Error()
+			e.saveTask()
+			return
+		}
+
+		e.task.Files = append(e.task.Files, path)
+		e.saveTask()
+	}
+
+	e.task.Status = TaskStatusFinished
+	e.saveTask()
+}
+
+func (e *subjectRelationExporter) saveTask() {
+	e.task.UpdatedAt = time.Now().Unix()
+	if err := saveTask(e.task); err != nil {
+		log.WithError(err).Errorf("export saveTask fail, task=`%+v`", e.task)
+	}
+}
+
+func (e *subjectRelationExporter) anonymize(_type, id string) string {
+	if e.anonymizer == nil {
+		return id
+	}
+	return e.anonymizer(_type, id)
+}
+
+func (e *subjectRelationExporter) buildSubjects() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	w.Write([]string{"pk", "type", "id", "name"})
+
+	var afterPK int64
+	for {
+		subjects, err := e.svc.ListSubjectAfterPK(afterPK, exportPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range subjects {
+			name := s.Name
+			// 用户的名称(中文名)同样是敏感信息
+			if e.anonymizer != nil && s.Type == types.UserType {
+				name = ""
+			}
+			w.Write([]string{strconv.FormatInt(s.PK, 10), s.Type, e.anonymize(s.Type, s.ID), name})
+		}
+
+		if int64(len(subjects)) < exportPageSize {
+			break
+		}
+		afterPK = subjects[len(subjects)-1].PK
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func (e *subjectRelationExporter) buildRelations() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	w.Write([]string{
+		"subject_pk", "subject_type", "subject_id", "parent_pk", "parent_type", "parent_id", "policy_expired_at",
+	})
+
+	var afterPK int64
+	for {
+		relations, err := e.svc.ListRelationAfterPK(afterPK, exportPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range relations {
+			w.Write([]string{
+				strconv.FormatInt(r.SubjectPK, 10),
+				r.SubjectType,
+				e.anonymize(r.SubjectType, r.SubjectID),
+				strconv.FormatInt(r.ParentPK, 10),
+				r.ParentType,
+				e.anonymize(r.ParentType, r.ParentID),
+				strconv.FormatInt(r.PolicyExpiredAt, 10),
+			})
+		}
+
+		if int64(len(relations)) < exportPageSize {
+			break
+		}
+		afterPK = relations[len(relations)-1].PK
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func (e *subjectRelationExporter) buildSubjectDepartments() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	w.Write([]string{"subject_pk", "department_pk"})
+
+	var afterPK int64
+	for {
+		subjectDepartments, err := e.svc.ListSubjectDepartmentAfterPK(afterPK, exportPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sd := range subjectDepartments {
+			for _, departmentPK := range sd.DepartmentPKs {
+				w.Write([]string{strconv.FormatInt(sd.SubjectPK, 10), strconv.FormatInt(departmentPK, 10)})
+			}
+		}
+
+		if int64(len(subjectDepartments)) < exportPageSize {
+			break
+		}
+		afterPK = subjectDepartments[len(subjectDepartments)-1].PK
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
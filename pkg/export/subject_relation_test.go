@@ -0,0 +1,159 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package export
+
+import (
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	cmock "iam/pkg/component/mock"
+	"iam/pkg/service/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectRelationExporter", func() {
+	var ctl *gomock.Controller
+	var mockSvc *mock.MockSubjectExportService
+	var mockStorage *cmock.MockObjectStorageClient
+	var savedTasks []Task
+	var oldSaveTask func(Task) error
+	var oldPageSize int64
+	var e *subjectRelationExporter
+
+	BeforeEach(func() {
+		ctl = gomock.NewController(GinkgoT())
+		mockSvc = mock.NewMockSubjectExportService(ctl)
+		mockStorage = cmock.NewMockObjectStorageClient(ctl)
+
+		savedTasks = []Task{}
+		oldSaveTask = saveTask
+		saveTask = func(task Task) error {
+			savedTasks = append(savedTasks, task)
+			return nil
+		}
+
+		oldPageSize = exportPageSize
+		exportPageSize = 2
+
+		e = &subjectRelationExporter{
+			svc:     mockSvc,
+			storage: mockStorage,
+			task:    Task{ID: "abc", Status: TaskStatusRunning, Files: []string{}},
+		}
+	})
+
+	AfterEach(func() {
+		saveTask = oldSaveTask
+		exportPageSize = oldPageSize
+		ctl.Finish()
+	})
+
+	Describe("anonymizer", func() {
+		It("stable hash for users only", func() {
+			a := newAnonymizer("salt")
+
+			assert.Equal(GinkgoT(), a("user", "admin"), a("user", "admin"))
+			assert.NotEqual(GinkgoT(), "admin", a("user", "admin"))
+			assert.Len(GinkgoT(), a("user", "admin"), 64)
+			assert.NotEqual(GinkgoT(), a("user", "admin"), newAnonymizer("other")("user", "admin"))
+			assert.Equal(GinkgoT(), "1", a("group", "1"))
+		})
+	})
+
+	Describe("buildSubjects", func() {
+		It("paging", func() {
+			mockSvc.EXPECT().ListSubjectAfterPK(int64(0), int64(2)).Return([]types.ExportSubject{
+				{PK: 1, Type: "user", ID: "admin", Name: "Admin"},
+				{PK: 2, Type: "group", ID: "1", Name: "g1"},
+			}, nil)
+			mockSvc.EXPECT().ListSubjectAfterPK(int64(2), int64(2)).Return([]types.ExportSubject{
+				{PK: 3, Type: "department", ID: "10", Name: "d1"},
+			}, nil)
+
+			data, err := e.buildSubjects()
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "pk,type,id,name\n1,user,admin,Admin\n2,group,1,g1\n3,department,10,d1\n", string(data))
+		})
+
+		It("anonymize", func() {
+			e.anonymizer = newAnonymizer("salt")
+			mockSvc.EXPECT().ListSubjectAfterPK(int64(0), int64(2)).Return([]types.ExportSubject{
+				{PK: 1, Type: "user", ID: "admin", Name: "Admin"},
+			}, nil)
+
+			data, err := e.buildSubjects()
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "pk,type,id,name\n1,user,"+newAnonymizer("salt")("user", "admin")+",\n", string(data))
+		})
+
+		It("error", func() {
+			mockSvc.EXPECT().ListSubjectAfterPK(int64(0), int64(2)).Return(nil, errors.New("error"))
+
+			_, err := e.buildSubjects()
+			assert.Error(GinkgoT(), err)
+		})
+	})
+
+	Describe("run", func() {
+		It("ok", func() {
+			mockSvc.EXPECT().ListSubjectAfterPK(int64(0), int64(2)).Return([]types.ExportSubject{}, nil)
+			mockSvc.EXPECT().ListRelationAfterPK(int64(0), int64(2)).Return([]types.ExportSubjectRelation{
+				{
+					PK:              1,
+					SubjectPK:       1,
+					SubjectType:     "user",
+					SubjectID:       "admin",
+					ParentPK:        2,
+					ParentType:      "group",
+					ParentID:        "1",
+					PolicyExpiredAt: 4102444800,
+				},
+			}, nil)
+			mockSvc.EXPECT().ListSubjectDepartmentAfterPK(int64(0), int64(2)).Return([]types.ExportSubjectDepartment{
+				{PK: 1, SubjectPK: 1, DepartmentPKs: []int64{3, 4}},
+			}, nil)
+
+			mockStorage.EXPECT().Upload("abc/subjects.csv", "text/csv", []byte("pk,type,id,name\n")).Return(nil)
+			mockStorage.EXPECT().Upload(
+				"abc/subject_relations.csv",
+				"text/csv",
+				[]byte("subject_pk,subject_type,subject_id,parent_pk,parent_type,parent_id,policy_expired_at\n"+
+					"1,user,admin,2,group,1,4102444800\n"),
+			).Return(nil)
+			mockStorage.EXPECT().Upload(
+				"abc/subject_departments.csv", "text/csv", []byte("subject_pk,department_pk\n1,3\n1,4\n"),
+			).Return(nil)
+
+			e.run()
+
+			last := savedTasks[len(savedTasks)-1]
+			assert.Equal(GinkgoT(), TaskStatusFinished, last.Status)
+			assert.Equal(GinkgoT(), []string{
+				"abc/subjects.csv", "abc/subject_relations.csv", "abc/subject_departments.csv",
+			}, last.Files)
+		})
+
+		It("upload fail", func() {
+			mockSvc.EXPECT().ListSubjectAfterPK(int64(0), int64(2)).Return([]types.ExportSubject{}, nil)
+			mockStorage.EXPECT().Upload("abc/subjects.csv", "text/csv", gomock.Any()).Return(errors.New("upload fail"))
+
+			e.run()
+
+			last := savedTasks[len(savedTasks)-1]
+			assert.Equal(GinkgoT(), TaskStatusFailed, last.Status)
+			assert.Contains(GinkgoT(), last.Error, "upload fail")
+			assert.Empty(GinkgoT(), last.Files)
+		})
+	})
+})
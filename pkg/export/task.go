@@ -0,0 +1,121 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package export
+
+import (
+	"encoding/hex"
+	"errors"
+	"time"
+
+	rediscache "github.com/go-redis/cache/v8"
+	"github.com/gofrs/uuid"
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/cache"
+	"iam/pkg/cache/impls"
+	"iam/pkg/component"
+	"iam/pkg/config"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+)
+
+// ExportLayer ...
+const ExportLayer = "Export"
+
+// Task status
+const (
+	TaskStatusRunning  = "running"
+	TaskStatusFinished = "finished"
+	TaskStatusFailed   = "failed"
+)
+
+var (
+	// ErrTaskNotFound 任务不存在或已过期
+	ErrTaskNotFound = errors.New("export task not found")
+	// ErrAnonymizeSaltNotConfigured 匿名化需要配置salt, 否则用户名可以被穷举还原
+	ErrAnonymizeSaltNotConfigured = errors.New("export anonymize salt not configured")
+)
+
+var (
+	storage       component.ObjectStorageClient
+	anonymizeSalt string
+)
+
+// Init ...
+func Init(cfg config.Export) {
+	storage = component.NewObjectStorageClient(cfg.StorageURL, cfg.StorageUsername, cfg.StoragePassword)
+	anonymizeSalt = cfg.AnonymizeSalt
+
+	log.Infof("init export storageURL=`%s`, anonymizeSalt configured=%t", cfg.StorageURL, cfg.AnonymizeSalt != "")
+}
+
+// Task 导出任务的进度, Files为已上传到对象存储的文件路径
+type Task struct {
+	ID        string   `json:"id"`
+	Anonymize bool     `json:"anonymize"`
+	Status    string   `json:"status"`
+	Files     []string `json:"files"`
+	Error     string   `json:"error"`
+
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// StartSubjectRelationExport 创建导出subject/group/department关系的任务, 后台导出到对象存储
+func StartSubjectRelationExport(anonymize bool) (task Task, err error) {
+	if anonymize && anonymizeSalt == "" {
+		return task, ErrAnonymizeSaltNotConfigured
+	}
+
+	now := time.Now().Unix()
+	task = Task{
+		ID:        hex.EncodeToString(uuid.Must(uuid.NewV4()).Bytes()),
+		Anonymize: anonymize,
+		Status:    TaskStatusRunning,
+		Files:     []string{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err = saveTask(task)
+	if err != nil {
+		err = errorx.Wrapf(err, ExportLayer, "StartSubjectRelationExport", "saveTask task=`%+v` fail", task)
+		return
+	}
+
+	e := &subjectRelationExporter{
+		svc:     service.NewSubjectExportService(),
+		storage: storage,
+		task:    task,
+	}
+	if anonymize {
+		e.anonymizer = newAnonymizer(anonymizeSalt)
+	}
+	go e.run()
+
+	return task, nil
+}
+
+// GetTask 查询导出任务的进度
+func GetTask(taskID string) (task Task, err error) {
+	err = impls.ExportTaskCache.Get(cache.NewStringKey(taskID), &task)
+	if errors.Is(err, rediscache.ErrCacheMiss) {
+		err = ErrTaskNotFound
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, ExportLayer, "GetTask", "ExportTaskCache.Get taskID=`%s` fail", taskID)
+	}
+	return
+}
+
+var saveTask = func(task Task) error {
+	return impls.ExportTaskCache.Set(cache.NewStringKey(task.ID), task, 0)
+}
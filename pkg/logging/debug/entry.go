@@ -31,6 +31,8 @@ type Entry struct {
 	Evals     map[int64]string `json:"evals"`
 	Error     string           `json:"error"`
 	SubDebugs []*Entry         `json:"sub_debugs"`
+
+	timing *Timing
 }
 
 // WithValue ...
@@ -92,12 +94,34 @@ func NewStep(name string) Step {
 	}
 }
 
+// Timing the time spent of the request, will be set in the X-IAM-Timing header of the response
+func (e *Entry) Timing() string {
+	if e.timing == nil {
+		return ""
+	}
+	return e.timing.String()
+}
+
+// AddTiming ...
+func (e *Entry) AddTiming(category string, duration time.Duration) {
+	if e.timing == nil {
+		e.timing = newTiming()
+	}
+	e.timing.Add(category, duration)
+}
+
 // AddSubDebug ...
 func (e *Entry) AddSubDebug(debug *Entry) {
 	if debug == nil {
 		return
 	}
 
+	// the sub entries share the timing of the root entry
+	if e.timing == nil {
+		e.timing = newTiming()
+	}
+	debug.timing = e.timing
+
 	if e.SubDebugs == nil {
 		e.SubDebugs = make([]*Entry, 0, 5)
 	}
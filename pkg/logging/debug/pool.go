@@ -40,6 +40,7 @@ func (p *entryPool) Get() *Entry {
 	entry := p.pool.Get().(*Entry)
 
 	entry.Time = time.Now()
+	entry.timing = newTiming()
 	return entry
 }
 
@@ -56,6 +57,7 @@ func (p *entryPool) Put(e *Entry) {
 	e.Steps = []Step{}
 	e.SubDebugs = []*Entry{}
 	e.Evals = map[int64]string{}
+	e.timing = nil
 
 	p.pool.Put(e)
 }
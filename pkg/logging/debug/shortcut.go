@@ -10,7 +10,11 @@
 
 package debug
 
-import "iam/pkg/abac/types"
+import (
+	"time"
+
+	"iam/pkg/abac/types"
+)
 
 // WithValue ...
 func WithValue(e *Entry, key string, value interface{}) {
@@ -117,3 +121,15 @@ func NewSubDebug(e *Entry) *Entry {
 	e.AddSubDebug(entry)
 	return entry
 }
+
+// StartTiming start timing the category, call the returned func to stop and add the time spent to the entry
+func StartTiming(e *Entry, category string) func() {
+	if e == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		e.AddTiming(category, time.Since(start))
+	}
+}
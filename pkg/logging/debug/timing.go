@@ -0,0 +1,71 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package debug
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the categories of the time spent
+const (
+	// the cache reads, including the database fallback on cache miss
+	TimingCache = "cache"
+	// the database reads without cache
+	TimingDB = "db"
+	// the remote PIP, query the resource attributes from the system
+	TimingRemote = "remote"
+)
+
+var timingCategories = []string{TimingCache, TimingDB, TimingRemote}
+
+// Timing the time spent of each category in a request, shared by the entry and its sub entries
+type Timing struct {
+	start time.Time
+
+	mu        sync.Mutex
+	durations map[string]time.Duration
+}
+
+func newTiming() *Timing {
+	return &Timing{
+		start:     time.Now(),
+		durations: make(map[string]time.Duration, len(timingCategories)),
+	}
+}
+
+// Add ...
+func (t *Timing) Add(category string, duration time.Duration) {
+	t.mu.Lock()
+	t.durations[category] += duration
+	t.mu.Unlock()
+}
+
+// String the summary in the format of Server-Timing
+// e.g. `total;dur=12.35, cache;dur=1.20, db;dur=0.00, remote;dur=8.10`, the dur is in milliseconds
+// the total is the time since the root entry created
+func (t *Timing) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	parts := make([]string, 0, 1+len(timingCategories))
+	parts = append(parts, formatTimingPart("total", time.Since(t.start)))
+	for _, category := range timingCategories {
+		parts = append(parts, formatTimingPart(category, t.durations[category]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatTimingPart(name string, duration time.Duration) string {
+	return fmt.Sprintf("%s;dur=%.2f", name, float64(duration)/float64(time.Millisecond))
+}
@@ -0,0 +1,51 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package debug
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimingString(t *testing.T) {
+	timing := newTiming()
+	timing.Add(TimingCache, 1200*time.Microsecond)
+	timing.Add(TimingCache, 300*time.Microsecond)
+	timing.Add(TimingRemote, 8*time.Millisecond)
+
+	s := timing.String()
+	assert.True(t, strings.HasPrefix(s, "total;dur="))
+	assert.Contains(t, s, "cache;dur=1.50, db;dur=0.00, remote;dur=8.00")
+}
+
+func TestStartTiming(t *testing.T) {
+	// nil entry
+	stop := StartTiming(nil, TimingCache)
+	stop()
+
+	pool := newEntryPool()
+	e := pool.Get()
+	assert.NotEmpty(t, e.Timing())
+
+	// the sub entry shares the timing of the root entry
+	sub := NewSubDebug(e)
+	stop = StartTiming(sub, TimingDB)
+	time.Sleep(2 * time.Millisecond)
+	stop()
+
+	assert.Same(t, e.timing, sub.timing)
+	assert.NotContains(t, e.Timing(), "db;dur=0.00")
+
+	pool.Put(e)
+	assert.Empty(t, e.Timing())
+}
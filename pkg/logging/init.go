@@ -28,6 +28,7 @@ var loggerInitOnce sync.Once
 // use zap for better performance
 var apiLogger *zap.Logger
 var webLogger *zap.Logger
+var accessLogger *zap.Logger
 
 // use logrus for better usage
 var sqlLogger *logrus.Logger
@@ -42,6 +43,7 @@ func InitLogger(logger *config.Logger) {
 		// json logger
 		apiLogger = newZapJSONLogger(&logger.API)
 		webLogger = newZapJSONLogger(&logger.Web)
+		accessLogger = newZapJSONLogger(&logger.Access)
 
 		sqlLogger = newJSONLogger(&logger.SQL)
 		auditLogger = newJSONLogger(&logger.Audit)
@@ -174,6 +176,16 @@ func GetWebLogger() *zap.Logger {
 	return webLogger
 }
 
+// GetAccessLogger access log
+func GetAccessLogger() *zap.Logger {
+	// if not init yet, use system logger
+	if accessLogger == nil {
+		accessLogger, _ = zap.NewProduction()
+		defer accessLogger.Sync()
+	}
+	return accessLogger
+}
+
 // GetSQLLogger sql log
 func GetSQLLogger() *logrus.Logger {
 	// if not init yet, use system logger
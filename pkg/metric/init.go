@@ -49,6 +49,85 @@ var (
 	},
 		[]string{"method", "path", "status", "component"},
 	)
+
+	// ShedRequestCount DB压力大时被拒绝的低优先级请求数量
+	ShedRequestCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        "api_shed_requests_total",
+			Help:        "How many low priority HTTP requests shed when the database is under pressure.",
+			ConstLabels: prometheus.Labels{"service": serviceName},
+		},
+		[]string{"method", "path", "client_id"},
+	)
+
+	// MemoryCacheRequestCount 本地内存缓存的查询数量, 按缓存名及是否命中区分
+	MemoryCacheRequestCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        "memory_cache_requests_total",
+			Help:        "How many local memory cache lookups, partitioned by cache name and result(hit/miss).",
+			ConstLabels: prometheus.Labels{"service": serviceName},
+		},
+		[]string{"name", "result"},
+	)
+
+	// MemoryCacheEvictionCount 本地内存缓存因超过大小限制被淘汰的数量
+	MemoryCacheEvictionCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        "memory_cache_evictions_total",
+			Help:        "How many local memory cache entries evicted by the size limit, partitioned by cache name.",
+			ConstLabels: prometheus.Labels{"service": serviceName},
+		},
+		[]string{"name"},
+	)
+
+	// SubjectDepartmentSyncCount 用户部门关系批量同步的数量, 按结果(created/failed)区分
+	SubjectDepartmentSyncCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        "subject_department_sync_total",
+			Help:        "How many subject departments synced, partitioned by result.",
+			ConstLabels: prometheus.Labels{"service": serviceName},
+		},
+		[]string{"status"},
+	)
+
+	// SubjectDepartmentSyncPending 用户部门关系批量同步中待处理的数量, 用于观察同步进度
+	SubjectDepartmentSyncPending = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        "subject_department_sync_pending",
+		Help:        "How many subject departments are waiting to be synced.",
+		ConstLabels: prometheus.Labels{"service": serviceName},
+	})
+
+	// SubjectDepartmentSyncChunkDuration 用户部门关系批量同步每个批次的耗时分布
+	SubjectDepartmentSyncChunkDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:        "subject_department_sync_chunk_duration_milliseconds",
+		Help:        "How long it took to sync a chunk of subject departments.",
+		ConstLabels: prometheus.Labels{"service": serviceName},
+		Buckets:     []float64{50, 100, 200, 500, 1000, 2000, 5000},
+	})
+
+	// TaskRunCount 定时任务的执行次数, 按任务名及结果(success/failed/skipped)区分
+	TaskRunCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        "task_runs_total",
+			Help:        "How many scheduled task runs, partitioned by task name and result.",
+			ConstLabels: prometheus.Labels{"service": serviceName},
+		},
+		[]string{"name", "status"},
+	)
+
+	// ExpiredSubjectRelationPurgeCount 定时清理的过期成员关系数量
+	ExpiredSubjectRelationPurgeCount = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "expired_subject_relation_purged_total",
+		Help:        "How many expired subject relations purged by the scheduled task.",
+		ConstLabels: prometheus.Labels{"service": serviceName},
+	})
+
+	// SubjectSystemGroupReconcileCount 定时比对修正的subject_system_group不一致的subject数量
+	SubjectSystemGroupReconcileCount = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "subject_system_group_reconciled_total",
+		Help:        "How many subjects with inconsistent system groups reconciled by the scheduled task.",
+		ConstLabels: prometheus.Labels{"service": serviceName},
+	})
 )
 
 // InitMetrics ...
@@ -57,4 +136,13 @@ func InitMetrics() {
 	prometheus.MustRegister(RequestCount)
 	prometheus.MustRegister(RequestDuration)
 	prometheus.MustRegister(ComponentRequestDuration)
+	prometheus.MustRegister(ShedRequestCount)
+	prometheus.MustRegister(MemoryCacheRequestCount)
+	prometheus.MustRegister(MemoryCacheEvictionCount)
+	prometheus.MustRegister(SubjectDepartmentSyncCount)
+	prometheus.MustRegister(SubjectDepartmentSyncPending)
+	prometheus.MustRegister(SubjectDepartmentSyncChunkDuration)
+	prometheus.MustRegister(TaskRunCount)
+	prometheus.MustRegister(ExpiredSubjectRelationPurgeCount)
+	prometheus.MustRegister(SubjectSystemGroupReconcileCount)
 }
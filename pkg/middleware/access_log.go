@@ -0,0 +1,144 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"iam/pkg/config"
+	"iam/pkg/logging"
+	"iam/pkg/util"
+)
+
+// accessLogSampler decide whether to log the request by the route pattern
+type accessLogSampler struct {
+	defaultRate float64
+	routeRates  map[string]float64
+
+	// for testing
+	random func() float64
+}
+
+func newAccessLogSampler(cfg config.AccessLog) *accessLogSampler {
+	defaultRate := cfg.SampleRate
+	if defaultRate <= 0 {
+		defaultRate = 1
+	}
+
+	routeRates := make(map[string]float64, len(cfg.Routes))
+	for _, r := range cfg.Routes {
+		routeRates[r.Path] = r.SampleRate
+	}
+
+	return &accessLogSampler{
+		defaultRate: defaultRate,
+		routeRates:  routeRates,
+		random:      rand.Float64,
+	}
+}
+
+// Rate return the sample rate of the route
+func (s *accessLogSampler) Rate(route string) float64 {
+	if rate, ok := s.routeRates[route]; ok {
+		return rate
+	}
+	return s.defaultRate
+}
+
+// Sample ...
+func (s *accessLogSampler) Sample(route string) bool {
+	rate := s.Rate(route)
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	return s.random() < rate
+}
+
+// AccessLogger log all the requests in json, the success requests are sampled by route
+func AccessLogger(cfg config.AccessLog) gin.HandlerFunc {
+	logger := logging.GetAccessLogger()
+	sampler := newAccessLogSampler(cfg)
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		// NOTE: read the body before the handlers consume it
+		var system, subjectDigest string
+		if c.Request.Method != http.MethodGet {
+			if body, err := util.ReadRequestBody(c.Request); err == nil && len(body) > 0 {
+				system, subjectDigest = parseAccessLogBody(body)
+			}
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		// the route pattern, e.g. /api/v1/model/systems/:system_id, empty if not matched
+		route := c.FullPath()
+
+		_, hasError := util.GetError(c)
+		if status < http.StatusBadRequest && !hasError && !sampler.Sample(route) {
+			return
+		}
+
+		if system == "" {
+			system = c.Param("system_id")
+		}
+
+		duration := time.Since(start)
+		logger.Info("-",
+			zap.String("method", c.Request.Method),
+			zap.String("route", route),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", status),
+			// always add 1ms, in case the 0ms in log
+			zap.Float64("latency", float64(duration/time.Millisecond)+1),
+			zap.String("request_id", util.GetRequestID(c)),
+			zap.String("app_code", util.GetClientID(c)),
+			zap.String("system", system),
+			zap.String("subject", subjectDigest),
+			zap.String("client_ip", c.ClientIP()),
+			zap.Float64("sample_rate", sampler.Rate(route)),
+		)
+	}
+}
+
+// parseAccessLogBody get the system and the digest of subject from the json body
+// NOTE: the subject id(username) is sensitive, only log the digest which can be used for aggregation
+func parseAccessLogBody(body []byte) (system, subjectDigest string) {
+	system = jsoniter.Get(body, "system").ToString()
+
+	subject := jsoniter.Get(body, "subject")
+	if subject.LastError() != nil {
+		return
+	}
+
+	subjectType := subject.Get("type").ToString()
+	subjectID := subject.Get("id").ToString()
+	if subjectID == "" {
+		return
+	}
+
+	sum := sha256.Sum256([]byte(subjectType + ":" + subjectID))
+	subjectDigest = hex.EncodeToString(sum[:8])
+	return
+}
@@ -0,0 +1,91 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/config"
+	"iam/pkg/logging"
+	"iam/pkg/util"
+)
+
+func TestAccessLogger(t *testing.T) {
+	t.Parallel()
+
+	logging.InitLogger(&config.Logger{})
+
+	r := gin.Default()
+	r.Use(AccessLogger(config.AccessLog{
+		Routes: []config.AccessLogRoute{{Path: "/ping", SampleRate: 0}},
+	}))
+	util.NewTestRouter(r)
+
+	req, _ := http.NewRequest("GET", "/ping", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAccessLogSampler(t *testing.T) {
+	t.Parallel()
+
+	s := newAccessLogSampler(config.AccessLog{
+		Routes: []config.AccessLogRoute{
+			{Path: "/api/v1/policy/auth", SampleRate: 0.1},
+			{Path: "/ping", SampleRate: 0},
+		},
+	})
+	s.random = func() float64 { return 0.5 }
+
+	// default
+	assert.Equal(t, float64(1), s.Rate("/api/v1/systems"))
+	assert.True(t, s.Sample("/api/v1/systems"))
+
+	assert.Equal(t, 0.1, s.Rate("/api/v1/policy/auth"))
+	assert.False(t, s.Sample("/api/v1/policy/auth"))
+
+	s.random = func() float64 { return 0.05 }
+	assert.True(t, s.Sample("/api/v1/policy/auth"))
+
+	assert.False(t, s.Sample("/ping"))
+
+	s = newAccessLogSampler(config.AccessLog{SampleRate: 0.2})
+	assert.Equal(t, 0.2, s.Rate("/ping"))
+}
+
+func TestParseAccessLogBody(t *testing.T) {
+	t.Parallel()
+
+	system, digest := parseAccessLogBody([]byte(`{"system": "bk_cmdb", "subject": {"type": "user", "id": "admin"}}`))
+	assert.Equal(t, "bk_cmdb", system)
+	assert.Len(t, digest, 16)
+	assert.False(t, strings.Contains(digest, "admin"))
+
+	_, digest2 := parseAccessLogBody([]byte(`{"subject": {"type": "user", "id": "admin"}}`))
+	assert.Equal(t, digest, digest2)
+
+	system, digest = parseAccessLogBody([]byte(`{"system": "bk_cmdb"}`))
+	assert.Equal(t, "bk_cmdb", system)
+	assert.Empty(t, digest)
+
+	system, digest = parseAccessLogBody([]byte(`not json`))
+	assert.Empty(t, system)
+	assert.Empty(t, digest)
+}
@@ -0,0 +1,79 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/config"
+	"iam/pkg/database"
+	"iam/pkg/metric"
+	"iam/pkg/util"
+)
+
+const (
+	defaultPriorityLaneConcurrency  = 10
+	defaultPriorityLaneQueueTimeout = 1000
+)
+
+// NOTE: this middleware used for the low priority apis(web/model), DO NOT use it for the auth/query apis
+//       when the database is under pressure, the low priority requests queue for limited slots,
+//       the requests not getting a slot before timeout will be shed
+
+// NewPriorityLaneMiddleware ...
+func NewPriorityLaneMiddleware(c *config.Config) gin.HandlerFunc {
+	if !c.PriorityLane.Enabled {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	concurrency := c.PriorityLane.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultPriorityLaneConcurrency
+	}
+	queueTimeout := c.PriorityLane.QueueTimeout
+	if queueTimeout <= 0 {
+		queueTimeout = defaultPriorityLaneQueueTimeout
+	}
+
+	sem := make(chan struct{}, concurrency)
+	return func(c *gin.Context) {
+		log.Debug("Middleware: PriorityLane")
+
+		if !database.IsUnderPressure() {
+			c.Next()
+			return
+		}
+
+		timer := time.NewTimer(time.Duration(queueTimeout) * time.Millisecond)
+		defer timer.Stop()
+
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+			c.Next()
+		case <-timer.C:
+			metric.ShedRequestCount.With(prometheus.Labels{
+				"method":    c.Request.Method,
+				"path":      c.FullPath(),
+				"client_id": util.GetClientID(c),
+			}).Inc()
+
+			util.TooManyRequestsJSONResponse(c, "the database is busy, please retry later")
+			c.Abort()
+		}
+	}
+}
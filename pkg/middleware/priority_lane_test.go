@@ -0,0 +1,103 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/config"
+	"iam/pkg/database"
+	"iam/pkg/util"
+)
+
+func TestNewPriorityLaneMiddleware(t *testing.T) {
+	newRouter := func(cfg *config.Config, handler gin.HandlerFunc) *gin.Engine {
+		r := gin.Default()
+		r.Use(NewPriorityLaneMiddleware(cfg))
+		r.GET("/ping", handler)
+		return r
+	}
+	doRequest := func(r *gin.Engine) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/ping", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	pong := func(c *gin.Context) {
+		util.SuccessJSONResponse(c, "pong", nil)
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(database.IsUnderPressure, func() bool {
+			return true
+		})
+		defer patches.Reset()
+
+		w := doRequest(newRouter(&config.Config{}, pong))
+		assert.Contains(t, w.Body.String(), "pong")
+	})
+
+	cfg := &config.Config{PriorityLane: config.PriorityLane{Enabled: true, Concurrency: 1, QueueTimeout: 10}}
+
+	t.Run("not under pressure", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(database.IsUnderPressure, func() bool {
+			return false
+		})
+		defer patches.Reset()
+
+		w := doRequest(newRouter(cfg, pong))
+		assert.Contains(t, w.Body.String(), "pong")
+	})
+
+	t.Run("under pressure", func(t *testing.T) {
+		patches := gomonkey.ApplyFunc(database.IsUnderPressure, func() bool {
+			return true
+		})
+		defer patches.Reset()
+
+		started := make(chan struct{})
+		finish := make(chan struct{})
+		r := newRouter(cfg, func(c *gin.Context) {
+			if c.Query("slow") != "" {
+				close(started)
+				<-finish
+			}
+			pong(c)
+		})
+
+		// the slot is free
+		w := doRequest(r)
+		assert.Contains(t, w.Body.String(), "pong")
+
+		// hold the only slot, the next request will be shed after the queue timeout
+		done := make(chan struct{})
+		go func() {
+			req, _ := http.NewRequest("GET", "/ping?slow=1", nil)
+			r.ServeHTTP(httptest.NewRecorder(), req)
+			close(done)
+		}()
+		<-started
+
+		w = doRequest(r)
+		assert.Contains(t, w.Body.String(), "too many requests")
+
+		close(finish)
+		<-done
+		w = doRequest(r)
+		assert.Contains(t, w.Body.String(), "pong")
+	})
+}
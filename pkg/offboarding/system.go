@@ -0,0 +1,284 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package offboarding
+
+import (
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/cache/invalidation"
+	"iam/pkg/errorx"
+	"iam/pkg/service"
+	svctypes "iam/pkg/service/types"
+)
+
+// 清理策略时每个action之间的间隔, 避免持续大量删除影响鉴权
+var policyCleanupInterval = 100 * time.Millisecond
+
+// NOTE: only guard the cleanup tasks in this process, the cleanup is idempotent
+var runningSystems sync.Map
+
+// DependentAction 其他系统中关联了本系统资源类型的操作, 清理后这些操作关联的资源类型将失效
+type DependentAction struct {
+	System         string `json:"system"`
+	ID             string `json:"id"`
+	ResourceTypeID string `json:"resource_type_id"`
+}
+
+// Preflight 系统下线前的预检, 返回清理任务将会删除的数据量
+type Preflight struct {
+	SystemID string `json:"system_id"`
+	Counts
+	DependentActions []DependentAction `json:"dependent_actions"`
+}
+
+type systemCleaner struct {
+	systemID string
+
+	actionService            service.ActionService
+	resourceTypeService      service.ResourceTypeService
+	instanceSelectionService service.InstanceSelectionService
+	policyService            service.PolicyService
+}
+
+func newSystemCleaner(systemID string) *systemCleaner {
+	return &systemCleaner{
+		systemID:                 systemID,
+		actionService:            service.NewActionService(),
+		resourceTypeService:      service.NewResourceTypeService(),
+		instanceSelectionService: service.NewInstanceSelectionService(),
+		policyService:            service.NewBatchPolicyService(),
+	}
+}
+
+// GetPreflight 统计系统下的策略/有权限的用户组/模型数量, 以及依赖本系统资源类型的其他系统操作
+func GetPreflight(systemID string) (Preflight, error) {
+	return newSystemCleaner(systemID).preflight()
+}
+
+// StartSystemCleanup 创建系统清理任务, 后台按 策略 -> 操作 -> 实例视图 -> 资源类型 的顺序删除
+// NOTE: 系统本身的注册信息及配置会保留
+func StartSystemCleanup(systemID string) (task Task, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OffboardingLayer, "StartSystemCleanup")
+
+	if _, loaded := runningSystems.LoadOrStore(systemID, struct{}{}); loaded {
+		return task, ErrTaskRunning
+	}
+
+	c := newSystemCleaner(systemID)
+	preflight, err := c.preflight()
+	if err != nil {
+		runningSystems.Delete(systemID)
+		err = errorWrapf(err, "preflight systemID=`%s` fail", systemID)
+		return
+	}
+
+	now := time.Now().Unix()
+	task = Task{
+		ID:        hex.EncodeToString(uuid.Must(uuid.NewV4()).Bytes()),
+		SystemID:  systemID,
+		Status:    TaskStatusRunning,
+		Stage:     TaskStagePolicies,
+		Total:     preflight.Counts,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err = saveTask(task)
+	if err != nil {
+		runningSystems.Delete(systemID)
+		err = errorWrapf(err, "saveTask task=`%+v` fail", task)
+		return
+	}
+
+	go func() {
+		defer runningSystems.Delete(systemID)
+		c.run(task)
+	}()
+
+	return task, nil
+}
+
+func (c *systemCleaner) preflight() (p Preflight, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OffboardingLayer, "preflight")
+
+	p.SystemID = c.systemID
+	p.DependentActions = []DependentAction{}
+
+	actions, err := c.actionService.ListThinActionBySystem(c.systemID)
+	if err != nil {
+		err = errorWrapf(err, "actionService.ListThinActionBySystem systemID=`%s` fail", c.systemID)
+		return
+	}
+	actionPKs := make([]int64, 0, len(actions))
+	for _, a := range actions {
+		actionPKs = append(actionPKs, a.PK)
+	}
+	p.Actions = int64(len(actions))
+
+	p.Policies, err = c.policyService.GetCountByActionPKs(actionPKs)
+	if err != nil {
+		err = errorWrapf(err, "policyService.GetCountByActionPKs systemID=`%s` fail", c.systemID)
+		return
+	}
+
+	p.GroupsWithAuth, err = c.policyService.GetSubjectCountByActionPKs(actionPKs, svctypes.GroupType)
+	if err != nil {
+		err = errorWrapf(err, "policyService.GetSubjectCountByActionPKs systemID=`%s` fail", c.systemID)
+		return
+	}
+
+	resourceTypes, err := c.resourceTypeService.ListBySystem(c.systemID)
+	if err != nil {
+		err = errorWrapf(err, "resourceTypeService.ListBySystem systemID=`%s` fail", c.systemID)
+		return
+	}
+	p.ResourceTypes = int64(len(resourceTypes))
+
+	instanceSelections, err := c.instanceSelectionService.ListBySystem(c.systemID)
+	if err != nil {
+		err = errorWrapf(err, "instanceSelectionService.ListBySystem systemID=`%s` fail", c.systemID)
+		return
+	}
+	p.InstanceSelections = int64(len(instanceSelections))
+
+	actionResourceTypes, err := c.actionService.ListActionResourceTypeIDByResourceTypeSystem(c.systemID)
+	if err != nil {
+		err = errorWrapf(err, "actionService.ListActionResourceTypeIDByResourceTypeSystem systemID=`%s` fail",
+			c.systemID)
+		return
+	}
+	for _, art := range actionResourceTypes {
+		if art.ActionSystem != c.systemID {
+			p.DependentActions = append(p.DependentActions, DependentAction{
+				System:         art.ActionSystem,
+				ID:             art.ActionID,
+				ResourceTypeID: art.ResourceTypeID,
+			})
+		}
+	}
+
+	return p, nil
+}
+
+func (c *systemCleaner) run(task Task) {
+	err := c.cleanup(&task)
+
+	task.UpdatedAt = time.Now().Unix()
+	if err != nil {
+		log.WithError(err).Errorf("system cleanup fail, task=`%+v`", task)
+
+		task.Status = TaskStatusFailed
+		task.Error = err.Error()
+	} else {
+		task.Status = TaskStatusFinished
+	}
+
+	if err = saveTask(task); err != nil {
+		log.WithError(err).Errorf("system cleanup saveTask fail, task=`%+v`", task)
+	}
+}
+
+func (c *systemCleaner) cleanup(task *Task) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OffboardingLayer, "cleanup")
+
+	// 1. policies, 逐个action删除, 每个action删除后更新进度
+	actions, err := c.actionService.ListThinActionBySystem(c.systemID)
+	if err != nil {
+		return errorWrapf(err, "actionService.ListThinActionBySystem systemID=`%s` fail", c.systemID)
+	}
+	for _, a := range actions {
+		count, err := c.policyService.GetCountByActionPKs([]int64{a.PK})
+		if err != nil {
+			return errorWrapf(err, "policyService.GetCountByActionPKs actionPK=`%d` fail", a.PK)
+		}
+		if count == 0 {
+			continue
+		}
+
+		err = c.policyService.DeleteByActionPK(a.PK)
+		if err != nil {
+			return errorWrapf(err, "policyService.DeleteByActionPK actionPK=`%d` fail", a.PK)
+		}
+
+		task.Deleted.Policies += count
+		c.saveProgress(task)
+
+		time.Sleep(policyCleanupInterval)
+	}
+	task.Deleted.GroupsWithAuth = task.Total.GroupsWithAuth
+
+	// 2. actions
+	task.Stage = TaskStageActions
+	c.saveProgress(task)
+	if len(actions) > 0 {
+		actionIDs := make([]string, 0, len(actions))
+		for _, a := range actions {
+			actionIDs = append(actionIDs, a.ID)
+		}
+		err = c.actionService.BulkDelete(c.systemID, actionIDs)
+		if err != nil {
+			return errorWrapf(err, "actionService.BulkDelete systemID=`%s`, ids=`%v` fail", c.systemID, actionIDs)
+		}
+		invalidation.DeleteActions(c.systemID, actionIDs)
+		task.Deleted.Actions = int64(len(actionIDs))
+	}
+
+	// 3. instance selections
+	task.Stage = TaskStageInstanceSelections
+	c.saveProgress(task)
+	instanceSelections, err := c.instanceSelectionService.ListBySystem(c.systemID)
+	if err != nil {
+		return errorWrapf(err, "instanceSelectionService.ListBySystem systemID=`%s` fail", c.systemID)
+	}
+	if len(instanceSelections) > 0 {
+		ids := make([]string, 0, len(instanceSelections))
+		for _, is := range instanceSelections {
+			ids = append(ids, is.ID)
+		}
+		err = c.instanceSelectionService.BulkDelete(c.systemID, ids)
+		if err != nil {
+			return errorWrapf(err, "instanceSelectionService.BulkDelete systemID=`%s`, ids=`%v` fail", c.systemID, ids)
+		}
+		task.Deleted.InstanceSelections = int64(len(ids))
+	}
+
+	// 4. resource types
+	task.Stage = TaskStageResourceTypes
+	c.saveProgress(task)
+	resourceTypes, err := c.resourceTypeService.ListBySystem(c.systemID)
+	if err != nil {
+		return errorWrapf(err, "resourceTypeService.ListBySystem systemID=`%s` fail", c.systemID)
+	}
+	if len(resourceTypes) > 0 {
+		ids := make([]string, 0, len(resourceTypes))
+		for _, rt := range resourceTypes {
+			ids = append(ids, rt.ID)
+		}
+		err = c.resourceTypeService.BulkDelete(c.systemID, ids)
+		if err != nil {
+			return errorWrapf(err, "resourceTypeService.BulkDelete systemID=`%s`, ids=`%v` fail", c.systemID, ids)
+		}
+		invalidation.DeleteResourceTypes(c.systemID, ids)
+		task.Deleted.ResourceTypes = int64(len(ids))
+	}
+
+	return nil
+}
+
+func (c *systemCleaner) saveProgress(task *Task) {
+	task.UpdatedAt = time.Now().Unix()
+	if err := saveTask(*task); err != nil {
+		log.WithError(err).Errorf("system cleanup saveTask fail, task=`%+v`", *task)
+	}
+}
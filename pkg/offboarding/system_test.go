@@ -0,0 +1,171 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package offboarding
+
+import (
+	"errors"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/cache/impls"
+	"iam/pkg/service/mock"
+	svctypes "iam/pkg/service/types"
+)
+
+var _ = Describe("System", func() {
+	var ctl *gomock.Controller
+	var actionService *mock.MockActionService
+	var resourceTypeService *mock.MockResourceTypeService
+	var instanceSelectionService *mock.MockInstanceSelectionService
+	var policyService *mock.MockPolicyService
+	var c *systemCleaner
+
+	BeforeEach(func() {
+		ctl = gomock.NewController(GinkgoT())
+		actionService = mock.NewMockActionService(ctl)
+		resourceTypeService = mock.NewMockResourceTypeService(ctl)
+		instanceSelectionService = mock.NewMockInstanceSelectionService(ctl)
+		policyService = mock.NewMockPolicyService(ctl)
+		c = &systemCleaner{
+			systemID:                 "test",
+			actionService:            actionService,
+			resourceTypeService:      resourceTypeService,
+			instanceSelectionService: instanceSelectionService,
+			policyService:            policyService,
+		}
+	})
+	AfterEach(func() {
+		ctl.Finish()
+	})
+
+	Describe("preflight", func() {
+		It("ListThinActionBySystem fail", func() {
+			actionService.EXPECT().ListThinActionBySystem("test").Return(nil, errors.New("error"))
+
+			_, err := c.preflight()
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListThinActionBySystem")
+		})
+
+		It("ok", func() {
+			actionService.EXPECT().ListThinActionBySystem("test").Return([]svctypes.ThinAction{
+				{PK: 1, System: "test", ID: "view"},
+				{PK: 2, System: "test", ID: "edit"},
+			}, nil)
+			policyService.EXPECT().GetCountByActionPKs([]int64{1, 2}).Return(int64(10), nil)
+			policyService.EXPECT().GetSubjectCountByActionPKs([]int64{1, 2}, svctypes.GroupType).Return(int64(3), nil)
+			resourceTypeService.EXPECT().ListBySystem("test").Return([]svctypes.ResourceType{{ID: "app"}}, nil)
+			instanceSelectionService.EXPECT().ListBySystem("test").Return([]svctypes.InstanceSelection{}, nil)
+			actionService.EXPECT().ListActionResourceTypeIDByResourceTypeSystem("test").Return(
+				[]svctypes.ActionResourceTypeID{
+					{ActionSystem: "test", ActionID: "view", ResourceTypeSystem: "test", ResourceTypeID: "app"},
+					{ActionSystem: "other", ActionID: "bind", ResourceTypeSystem: "test", ResourceTypeID: "app"},
+				}, nil)
+
+			p, err := c.preflight()
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), Counts{
+				Policies:       10,
+				GroupsWithAuth: 3,
+				Actions:        2,
+				ResourceTypes:  1,
+			}, p.Counts)
+			assert.Equal(GinkgoT(), []DependentAction{{System: "other", ID: "bind", ResourceTypeID: "app"}},
+				p.DependentActions)
+		})
+	})
+
+	Describe("run", func() {
+		var patches *gomonkey.Patches
+		var saved []Task
+		BeforeEach(func() {
+			saved = nil
+			patches = gomonkey.ApplyGlobalVar(&policyCleanupInterval, time.Duration(0))
+			patches.ApplyGlobalVar(&saveTask, func(task Task) error {
+				saved = append(saved, task)
+				return nil
+			})
+			patches.ApplyFunc(impls.BatchDeleteActionCache, func(systemID string, actionIDs []string) error {
+				return nil
+			})
+			patches.ApplyFunc(impls.BatchDeleteResourceTypeCache, func(systemID string, ids []string) error {
+				return nil
+			})
+		})
+		AfterEach(func() {
+			patches.Reset()
+		})
+
+		It("delete policies fail", func() {
+			actionService.EXPECT().ListThinActionBySystem("test").Return([]svctypes.ThinAction{
+				{PK: 1, System: "test", ID: "view"},
+			}, nil)
+			policyService.EXPECT().GetCountByActionPKs([]int64{1}).Return(int64(10), nil)
+			policyService.EXPECT().DeleteByActionPK(int64(1)).Return(errors.New("delete fail"))
+
+			c.run(Task{ID: "t1", SystemID: "test", Status: TaskStatusRunning, Stage: TaskStagePolicies})
+
+			last := saved[len(saved)-1]
+			assert.Equal(GinkgoT(), TaskStatusFailed, last.Status)
+			assert.Equal(GinkgoT(), TaskStagePolicies, last.Stage)
+			assert.Contains(GinkgoT(), last.Error, "delete fail")
+		})
+
+		It("ok", func() {
+			actionService.EXPECT().ListThinActionBySystem("test").Return([]svctypes.ThinAction{
+				{PK: 1, System: "test", ID: "view"},
+				{PK: 2, System: "test", ID: "edit"},
+			}, nil)
+			policyService.EXPECT().GetCountByActionPKs([]int64{1}).Return(int64(10), nil)
+			policyService.EXPECT().GetCountByActionPKs([]int64{2}).Return(int64(0), nil)
+			policyService.EXPECT().DeleteByActionPK(int64(1)).Return(nil)
+			actionService.EXPECT().BulkDelete("test", []string{"view", "edit"}).Return(nil)
+			instanceSelectionService.EXPECT().ListBySystem("test").Return([]svctypes.InstanceSelection{
+				{ID: "is1"},
+			}, nil)
+			instanceSelectionService.EXPECT().BulkDelete("test", []string{"is1"}).Return(nil)
+			resourceTypeService.EXPECT().ListBySystem("test").Return([]svctypes.ResourceType{{ID: "app"}}, nil)
+			resourceTypeService.EXPECT().BulkDelete("test", []string{"app"}).Return(nil)
+
+			c.run(Task{
+				ID:       "t1",
+				SystemID: "test",
+				Status:   TaskStatusRunning,
+				Stage:    TaskStagePolicies,
+				Total:    Counts{Policies: 10, GroupsWithAuth: 2},
+			})
+
+			last := saved[len(saved)-1]
+			assert.Equal(GinkgoT(), TaskStatusFinished, last.Status)
+			assert.Equal(GinkgoT(), TaskStageResourceTypes, last.Stage)
+			assert.Equal(GinkgoT(), Counts{
+				Policies:           10,
+				GroupsWithAuth:     2,
+				Actions:            2,
+				ResourceTypes:      1,
+				InstanceSelections: 1,
+			}, last.Deleted)
+		})
+	})
+
+	Describe("StartSystemCleanup", func() {
+		It("task running", func() {
+			runningSystems.Store("running", struct{}{})
+			defer runningSystems.Delete("running")
+
+			_, err := StartSystemCleanup("running")
+			assert.ErrorIs(GinkgoT(), err, ErrTaskRunning)
+		})
+	})
+})
@@ -0,0 +1,86 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package offboarding
+
+import (
+	"errors"
+
+	rediscache "github.com/go-redis/cache/v8"
+
+	"iam/pkg/cache"
+	"iam/pkg/cache/impls"
+	"iam/pkg/errorx"
+)
+
+// OffboardingLayer ...
+const OffboardingLayer = "Offboarding"
+
+// Task status
+const (
+	TaskStatusRunning  = "running"
+	TaskStatusFinished = "finished"
+	TaskStatusFailed   = "failed"
+)
+
+// Task stage, 按顺序清理
+const (
+	TaskStagePolicies           = "policies"
+	TaskStageActions            = "actions"
+	TaskStageInstanceSelections = "instance_selections"
+	TaskStageResourceTypes      = "resource_types"
+)
+
+var (
+	// ErrTaskNotFound 任务不存在或已过期
+	ErrTaskNotFound = errors.New("system cleanup task not found")
+	// ErrTaskRunning 同一个系统同时只能有一个清理任务
+	ErrTaskRunning = errors.New("system cleanup task is running")
+)
+
+// Counts 清理涉及的数据量
+type Counts struct {
+	Policies           int64 `json:"policies"`
+	GroupsWithAuth     int64 `json:"groups_with_auth"`
+	Actions            int64 `json:"actions"`
+	ResourceTypes      int64 `json:"resource_types"`
+	InstanceSelections int64 `json:"instance_selections"`
+}
+
+// Task 系统清理任务的进度, Total为任务创建时的预检数据, Deleted为已清理的数据
+// NOTE: groups_with_auth在策略全部清理后才会更新
+type Task struct {
+	ID       string `json:"id"`
+	SystemID string `json:"system_id"`
+	Status   string `json:"status"`
+	Stage    string `json:"stage"`
+	Total    Counts `json:"total"`
+	Deleted  Counts `json:"deleted"`
+	Error    string `json:"error"`
+
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// GetTask 查询系统清理任务的进度
+func GetTask(taskID string) (task Task, err error) {
+	err = impls.SystemCleanupTaskCache.Get(cache.NewStringKey(taskID), &task)
+	if errors.Is(err, rediscache.ErrCacheMiss) {
+		err = ErrTaskNotFound
+		return
+	}
+	if err != nil {
+		err = errorx.Wrapf(err, OffboardingLayer, "GetTask", "SystemCleanupTaskCache.Get taskID=`%s` fail", taskID)
+	}
+	return
+}
+
+var saveTask = func(task Task) error {
+	return impls.SystemCleanupTaskCache.Set(cache.NewStringKey(task.ID), task, 0)
+}
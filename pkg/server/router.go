@@ -34,8 +34,8 @@ func NewRouter(cfg *config.Config) *gin.Engine {
 
 	// router := gin.Default()
 	router := gin.New()
-	// MW: gin default logger
-	router.Use(gin.Logger())
+	// MW: structured access log with sampling
+	router.Use(middleware.AccessLogger(cfg.AccessLog))
 	// MW: recovery with sentry
 	router.Use(middleware.Recovery(cfg.Sentry.Enable))
 	// MW: request_id
@@ -50,6 +50,7 @@ func NewRouter(cfg *config.Config) *gin.Engine {
 	webRouter.Use(middleware.WebLogger())
 	webRouter.Use(middleware.NewClientAuthMiddleware(cfg))
 	webRouter.Use(middleware.SuperClientMiddleware())
+	webRouter.Use(middleware.NewPriorityLaneMiddleware(cfg))
 	web.Register(webRouter)
 
 	// policy apis for auth/query
@@ -74,6 +75,7 @@ func NewRouter(cfg *config.Config) *gin.Engine {
 	permModelRouter.Use(middleware.Audit())
 	permModelRouter.Use(middleware.NewClientAuthMiddleware(cfg))
 	policyRouter.Use(middleware.NewRateLimitMiddleware(cfg))
+	permModelRouter.Use(middleware.NewPriorityLaneMiddleware(cfg))
 	model.Register(permModelRouter)
 
 	// debug api
@@ -120,6 +120,7 @@ func (s *Server) Stop() {
 	// flush logger
 	logging.GetAPILogger().Sync()
 	logging.GetWebLogger().Sync()
+	logging.GetAccessLogger().Sync()
 
 	s.stopChan <- struct{}{}
 }
@@ -131,6 +131,7 @@ func (l *actionService) Get(system, actionID string) (types.Action, error) {
 		Name:    dbAction.Name,
 		NameEn:  dbAction.NameEn,
 		Type:    dbAction.Type,
+		Status:  dbAction.Status,
 		Version: dbAction.Version,
 	}
 	relatedResourceTypes := []types.ActionResourceType{}
@@ -195,6 +196,7 @@ func (l *actionService) ListBySystem(system string) ([]types.Action, error) {
 			Description:   ac.Description,
 			DescriptionEn: ac.DescriptionEn,
 			Type:          ac.Type,
+			Status:        ac.Status,
 			Version:       ac.Version,
 		}
 		if ac.RelatedActions != "" {
@@ -295,6 +297,12 @@ func (l *actionService) BulkCreate(system string, actions []types.Action) error
 			return errorWrapf(err1, "marshal action.RelatedActions=`%+v` fail", ac.RelatedActions)
 		}
 
+		// 未指定状态的操作默认启用
+		status := ac.Status
+		if status == "" {
+			status = types.ActionStatusEnabled
+		}
+
 		dbSaaSActions = append(dbSaaSActions, sdao.SaaSAction{
 			System:         system,
 			ID:             ac.ID,
@@ -304,6 +312,7 @@ func (l *actionService) BulkCreate(system string, actions []types.Action) error
 			DescriptionEn:  ac.DescriptionEn,
 			RelatedActions: relatedActions,
 			Type:           ac.Type,
+			Status:         status,
 			Version:        ac.Version,
 		})
 
@@ -343,7 +352,21 @@ func (l *actionService) BulkCreate(system string, actions []types.Action) error
 		}
 	}
 
-	return tx.Commit()
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	actionIDs := make([]string, 0, len(actions))
+	for _, ac := range actions {
+		actionIDs = append(actionIDs, ac.ID)
+	}
+	emitSystemModelChangeEvent(SystemModelChangeEvent{
+		Type:     SystemModelChangeEventTypeAction,
+		SystemID: system,
+		IDs:      actionIDs,
+	})
+	return nil
 }
 
 // Update ...
@@ -424,6 +447,7 @@ func (l *actionService) Update(system, actionID string, action types.Action) err
 		Description:    action.Description,
 		DescriptionEn:  action.DescriptionEn,
 		Type:           action.Type,
+		Status:         action.Status,
 		Version:        action.Version,
 		RelatedActions: relatedActions,
 
@@ -436,7 +460,18 @@ func (l *actionService) Update(system, actionID string, action types.Action) err
 		return errorWrapf(err, "saasManager.Update system=`%s`, actionID=`%s`, data=`%+v`",
 			system, actionID, data)
 	}
-	return tx.Commit()
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	emitSystemModelChangeEvent(SystemModelChangeEvent{
+		Type:     SystemModelChangeEventTypeAction,
+		SystemID: system,
+		IDs:      []string{actionID},
+	})
+	return nil
 }
 
 // BulkDelete ...
@@ -474,7 +509,17 @@ func (l *actionService) BulkDelete(system string, actionIDs []string) error {
 			system, actionIDs)
 	}
 
-	return tx.Commit()
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	emitSystemModelChangeEvent(SystemModelChangeEvent{
+		Type:     SystemModelChangeEventTypeAction,
+		SystemID: system,
+		IDs:      actionIDs,
+	})
+	return nil
 }
 
 func (l *actionService) toServiceActionResourceType(
@@ -0,0 +1,167 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"errors"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/errorx"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// AuditSVC ...
+const AuditSVC = "AuditSVC"
+
+// AuditService 审计查询, 按过滤条件查询部门/角色的变更记录
+type AuditService interface {
+	ListBeforePK(filter types.AuditFilter, beforePK, limit int64) ([]types.AuditRecord, error)
+}
+
+type auditService struct {
+	manager        dao.AuditManager
+	subjectManager dao.SubjectManager
+}
+
+// NewAuditService ...
+func NewAuditService() AuditService {
+	return &auditService{
+		manager:        dao.NewAuditManager(),
+		subjectManager: dao.NewSubjectManager(),
+	}
+}
+
+// NewBatchAuditService 使用批量任务的连接池, 用于导出
+func NewBatchAuditService() AuditService {
+	return &auditService{
+		manager:        dao.NewBatchAuditManager(),
+		subjectManager: dao.NewBatchSubjectManager(),
+	}
+}
+
+// ListBeforePK 查询pk小于beforePK的审计记录, 最近的在前; beforePK为0时从最新的开始
+func (s *auditService) ListBeforePK(
+	filter types.AuditFilter, beforePK, limit int64,
+) ([]types.AuditRecord, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(AuditSVC, "ListBeforePK")
+
+	daoFilter := dao.AuditFilter{
+		SubjectPK: filter.SubjectPK,
+		System:    filter.System,
+		Operator:  filter.Operator,
+		Source:    filter.Source,
+		StartTime: filter.StartTime,
+		EndTime:   filter.EndTime,
+	}
+
+	switch filter.Type {
+	case types.AuditTypeDepartment:
+		histories, err := s.manager.ListDepartmentHistoryBeforePK(daoFilter, beforePK, limit)
+		if err != nil {
+			return nil, errorWrapf(err, "manager.ListDepartmentHistoryBeforePK filter=`%+v`, beforePK=`%d`, "+
+				"limit=`%d` fail", daoFilter, beforePK, limit)
+		}
+		return s.convertDepartmentHistories(histories)
+	case types.AuditTypeRole:
+		histories, err := s.manager.ListRoleHistoryBeforePK(daoFilter, beforePK, limit)
+		if err != nil {
+			return nil, errorWrapf(err, "manager.ListRoleHistoryBeforePK filter=`%+v`, beforePK=`%d`, "+
+				"limit=`%d` fail", daoFilter, beforePK, limit)
+		}
+		return s.convertRoleHistories(histories)
+	default:
+		return nil, errorWrapf(errors.New("unsupported audit type"), "type=`%s`", filter.Type)
+	}
+}
+
+func (s *auditService) listSubjectMap(pkSet *util.Int64Set) (map[int64]dao.Subject, error) {
+	subjectMap := make(map[int64]dao.Subject, pkSet.Size())
+	if pkSet.Size() == 0 {
+		return subjectMap, nil
+	}
+
+	pks := pkSet.ToSlice()
+	subjects, err := s.subjectManager.ListByPKs(pks)
+	if err != nil {
+		return nil, errorx.Wrapf(err, AuditSVC, "listSubjectMap", "subjectManager.ListByPKs pks=`%+v` fail", pks)
+	}
+	for _, subject := range subjects {
+		subjectMap[subject.PK] = subject
+	}
+	return subjectMap, nil
+}
+
+func (s *auditService) convertDepartmentHistories(
+	histories []dao.SubjectDepartmentHistory,
+) ([]types.AuditRecord, error) {
+	pkSet := util.NewInt64Set()
+	for _, h := range histories {
+		pkSet.Add(h.SubjectPK)
+		pkSet.Add(h.DepartmentPK)
+	}
+	subjectMap, err := s.listSubjectMap(pkSet)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]types.AuditRecord, 0, len(histories))
+	for _, h := range histories {
+		// NOTE: the subject/department may be deleted, keep the record with empty id/name
+		subject := subjectMap[h.SubjectPK]
+		department := subjectMap[h.DepartmentPK]
+		records = append(records, types.AuditRecord{
+			PK:          h.PK,
+			Type:        types.AuditTypeDepartment,
+			SubjectType: subject.Type,
+			SubjectID:   subject.ID,
+			SubjectName: subject.Name,
+			TargetID:    department.ID,
+			TargetName:  department.Name,
+			Action:      h.Action,
+			Source:      h.Source,
+			CreatedAt:   h.CreatedAt,
+		})
+	}
+	return records, nil
+}
+
+func (s *auditService) convertRoleHistories(histories []dao.SubjectRoleHistory) ([]types.AuditRecord, error) {
+	pkSet := util.NewInt64Set()
+	for _, h := range histories {
+		pkSet.Add(h.SubjectPK)
+	}
+	subjectMap, err := s.listSubjectMap(pkSet)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]types.AuditRecord, 0, len(histories))
+	for _, h := range histories {
+		subject := subjectMap[h.SubjectPK]
+		records = append(records, types.AuditRecord{
+			PK:          h.PK,
+			Type:        types.AuditTypeRole,
+			SubjectType: subject.Type,
+			SubjectID:   subject.ID,
+			SubjectName: subject.Name,
+			TargetID:    h.RoleType,
+			System:      h.System,
+			Action:      h.Action,
+			Operator:    h.Operator,
+			Source:      h.Source,
+			CreatedAt:   h.CreatedAt,
+		})
+	}
+	return records, nil
+}
@@ -0,0 +1,129 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("AuditService", func() {
+	Describe("ListBeforePK cases", func() {
+		var ctl *gomock.Controller
+		var manager *mock.MockAuditManager
+		var subjectManager *mock.MockSubjectManager
+		var svc AuditService
+		createdAt := time.Unix(1629000000, 0)
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			manager = mock.NewMockAuditManager(ctl)
+			subjectManager = mock.NewMockSubjectManager(ctl)
+			svc = &auditService{manager: manager, subjectManager: subjectManager}
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("department ok", func() {
+			filter := types.AuditFilter{Type: types.AuditTypeDepartment, SubjectPK: 1, Source: "bk_iam"}
+			manager.EXPECT().ListDepartmentHistoryBeforePK(
+				dao.AuditFilter{SubjectPK: 1, Source: "bk_iam"}, int64(10), int64(2),
+			).Return([]dao.SubjectDepartmentHistory{
+				{PK: 9, SubjectPK: 1, DepartmentPK: 2, Action: "added", Source: "bk_iam", CreatedAt: createdAt},
+				{PK: 8, SubjectPK: 1, DepartmentPK: 3, Action: "removed", Source: "bk_iam", CreatedAt: createdAt},
+			}, nil)
+			subjectManager.EXPECT().ListByPKs(gomock.Any()).Return([]dao.Subject{
+				{PK: 1, Type: "user", ID: "admin", Name: "admin"},
+				{PK: 2, Type: "department", ID: "d1", Name: "dept1"},
+			}, nil)
+
+			records, err := svc.ListBeforePK(filter, 10, 2)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.AuditRecord{
+				{
+					PK: 9, Type: "department", SubjectType: "user", SubjectID: "admin", SubjectName: "admin",
+					TargetID: "d1", TargetName: "dept1", Action: "added", Source: "bk_iam", CreatedAt: createdAt,
+				},
+				// the deleted department
+				{
+					PK: 8, Type: "department", SubjectType: "user", SubjectID: "admin", SubjectName: "admin",
+					Action: "removed", Source: "bk_iam", CreatedAt: createdAt,
+				},
+			}, records)
+		})
+
+		It("role ok", func() {
+			filter := types.AuditFilter{Type: types.AuditTypeRole, System: "bk_cmdb", Operator: "admin"}
+			manager.EXPECT().ListRoleHistoryBeforePK(
+				dao.AuditFilter{System: "bk_cmdb", Operator: "admin"}, int64(0), int64(100),
+			).Return([]dao.SubjectRoleHistory{
+				{
+					PK: 3, SubjectPK: 1, RoleType: "system_manager", System: "bk_cmdb", Action: "granted",
+					Operator: "admin", Source: "bk_iam", CreatedAt: createdAt,
+				},
+			}, nil)
+			subjectManager.EXPECT().ListByPKs([]int64{1}).Return([]dao.Subject{
+				{PK: 1, Type: "user", ID: "tom", Name: "tom"},
+			}, nil)
+
+			records, err := svc.ListBeforePK(filter, 0, 100)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.AuditRecord{{
+				PK: 3, Type: "role", SubjectType: "user", SubjectID: "tom", SubjectName: "tom",
+				TargetID: "system_manager", System: "bk_cmdb", Action: "granted", Operator: "admin",
+				Source: "bk_iam", CreatedAt: createdAt,
+			}}, records)
+		})
+
+		It("empty", func() {
+			manager.EXPECT().ListRoleHistoryBeforePK(gomock.Any(), int64(0), int64(100)).
+				Return([]dao.SubjectRoleHistory{}, nil)
+
+			records, err := svc.ListBeforePK(types.AuditFilter{Type: types.AuditTypeRole}, 0, 100)
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), records)
+		})
+
+		It("manager fail", func() {
+			manager.EXPECT().ListDepartmentHistoryBeforePK(gomock.Any(), int64(0), int64(100)).
+				Return(nil, errors.New("error"))
+
+			_, err := svc.ListBeforePK(types.AuditFilter{Type: types.AuditTypeDepartment}, 0, 100)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListDepartmentHistoryBeforePK")
+		})
+
+		It("subjectManager fail", func() {
+			manager.EXPECT().ListRoleHistoryBeforePK(gomock.Any(), int64(0), int64(100)).
+				Return([]dao.SubjectRoleHistory{{PK: 3, SubjectPK: 1}}, nil)
+			subjectManager.EXPECT().ListByPKs([]int64{1}).Return(nil, errors.New("error"))
+
+			_, err := svc.ListBeforePK(types.AuditFilter{Type: types.AuditTypeRole}, 0, 100)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListByPKs")
+		})
+
+		It("unsupported type", func() {
+			_, err := svc.ListBeforePK(types.AuditFilter{Type: "abc"}, 0, 100)
+			assert.Error(GinkgoT(), err)
+		})
+	})
+})
@@ -27,6 +27,9 @@ type EnginePolicyService interface {
 	ListPKBetweenUpdatedAt(beginUpdatedAt, endUpdatedAt int64) ([]int64, error)
 	ListBetweenPK(expiredAt, minPK, maxPK int64) (policies []types.EngineQueryPolicy, err error)
 	ListByPKs(pks []int64) (policies []types.EngineQueryPolicy, err error)
+	ListAfterPKByActionPKs(
+		expiredAt, afterPK int64, actionPKs []int64, limit int64,
+	) (policies []types.EngineQueryPolicy, err error)
 }
 
 type enginePolicyService struct {
@@ -98,6 +101,27 @@ func (s *enginePolicyService) ListByPKs(pks []int64) (queryPolicies []types.Engi
 	return queryPolicies, nil
 }
 
+// ListAfterPKByActionPKs ...
+func (s *enginePolicyService) ListAfterPKByActionPKs(
+	expiredAt, afterPK int64,
+	actionPKs []int64,
+	limit int64,
+) (queryPolicies []types.EngineQueryPolicy, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(EnginePolicySVC, "ListAfterPKByActionPKs")
+
+	policies, err := s.manager.ListAfterPKByActionPKs(expiredAt, afterPK, actionPKs, limit)
+	if err != nil {
+		err = errorWrapf(err,
+			"manager.ListAfterPKByActionPKs expiredAt=`%d`, afterPK=`%d`, actionPKs=`%+v`, limit=`%d` fail",
+			expiredAt, afterPK, actionPKs, limit,
+		)
+		return nil, err
+	}
+
+	queryPolicies = convertPoliciesToEngineQueryPolicies(policies)
+	return queryPolicies, nil
+}
+
 func convertPoliciesToEngineQueryPolicies(policies []dao.EnginePolicy) []types.EngineQueryPolicy {
 	queryPolicies := make([]types.EngineQueryPolicy, 0, len(policies))
 	for _, p := range policies {
@@ -109,6 +133,8 @@ func convertPoliciesToEngineQueryPolicies(policies []dao.EnginePolicy) []types.E
 				ExpressionPK: p.ExpressionPK,
 				ExpiredAt:    p.ExpiredAt,
 			},
+			Effect:     p.Effect,
+			Priority:   p.Priority,
 			TemplateID: p.TemplateID,
 			UpdatedAt:  p.UpdatedAt.Unix(),
 		})
@@ -237,4 +237,67 @@ var _ = Describe("PolicyEngine", func() {
 			assert.Error(GinkgoT(), err)
 		})
 	})
+
+	Describe("ListAfterPKByActionPKs cases", func() {
+		var ctl *gomock.Controller
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("ok", func() {
+			updatedAt := time.Now()
+			daoPolicies := []dao.EnginePolicy{
+				{
+					Policy: dao.Policy{
+						PK:           int64(2),
+						SubjectPK:    int64(1),
+						ActionPK:     int64(1),
+						ExpressionPK: int64(1),
+						ExpiredAt:    int64(1),
+					},
+					UpdatedAt: updatedAt,
+				},
+			}
+			mockPolicyManager := mock.NewMockEnginePolicyManager(ctl)
+			mockPolicyManager.EXPECT().ListAfterPKByActionPKs(
+				int64(1), int64(1), []int64{1, 2}, int64(10),
+			).Return(daoPolicies, nil)
+
+			svc := enginePolicyService{
+				manager: mockPolicyManager,
+			}
+
+			policies, err := svc.ListAfterPKByActionPKs(int64(1), int64(1), []int64{1, 2}, int64(10))
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.EngineQueryPolicy{{
+				QueryPolicy: types.QueryPolicy{
+					PK:           int64(2),
+					SubjectPK:    int64(1),
+					ActionPK:     int64(1),
+					ExpressionPK: int64(1),
+					ExpiredAt:    int64(1),
+				},
+				UpdatedAt: updatedAt.Unix(),
+			}}, policies)
+		})
+
+		It("ListAfterPKByActionPKs fail", func() {
+			mockPolicyManager := mock.NewMockEnginePolicyManager(ctl)
+			mockPolicyManager.EXPECT().ListAfterPKByActionPKs(
+				int64(1), int64(1), []int64{1, 2}, int64(10),
+			).Return(nil, errors.New("fail"))
+
+			svc := enginePolicyService{
+				manager: mockPolicyManager,
+			}
+
+			_, err := svc.ListAfterPKByActionPKs(int64(1), int64(1), []int64{1, 2}, int64(10))
+			assert.Error(GinkgoT(), err)
+		})
+	})
 })
@@ -92,3 +92,18 @@ func (mr *MockEnginePolicyServiceMockRecorder) ListByPKs(pks interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPKs", reflect.TypeOf((*MockEnginePolicyService)(nil).ListByPKs), pks)
 }
+
+// ListAfterPKByActionPKs mocks base method
+func (m *MockEnginePolicyService) ListAfterPKByActionPKs(expiredAt, afterPK int64, actionPKs []int64, limit int64) ([]types.EngineQueryPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAfterPKByActionPKs", expiredAt, afterPK, actionPKs, limit)
+	ret0, _ := ret[0].([]types.EngineQueryPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAfterPKByActionPKs indicates an expected call of ListAfterPKByActionPKs
+func (mr *MockEnginePolicyServiceMockRecorder) ListAfterPKByActionPKs(expiredAt, afterPK, actionPKs, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAfterPKByActionPKs", reflect.TypeOf((*MockEnginePolicyService)(nil).ListAfterPKByActionPKs), expiredAt, afterPK, actionPKs, limit)
+}
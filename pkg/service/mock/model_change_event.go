@@ -33,6 +33,21 @@ func (m *MockModelChangeEventService) EXPECT() *MockModelChangeEventServiceMockR
 	return m.recorder
 }
 
+// Get mocks base method
+func (m *MockModelChangeEventService) Get(pk int64) (types.ModelChangeEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", pk)
+	ret0, _ := ret[0].(types.ModelChangeEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockModelChangeEventServiceMockRecorder) Get(pk interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockModelChangeEventService)(nil).Get), pk)
+}
+
 // ListByStatus mocks base method
 func (m *MockModelChangeEventService) ListByStatus(status string) ([]types.ModelChangeEvent, error) {
 	m.ctrl.T.Helper()
@@ -62,6 +77,20 @@ func (mr *MockModelChangeEventServiceMockRecorder) UpdateStatusByPK(pk, status i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusByPK", reflect.TypeOf((*MockModelChangeEventService)(nil).UpdateStatusByPK), pk, status)
 }
 
+// UpdateCallbackStatusByPK mocks base method
+func (m *MockModelChangeEventService) UpdateCallbackStatusByPK(pk int64, callbackStatus, callbackMessage string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCallbackStatusByPK", pk, callbackStatus, callbackMessage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCallbackStatusByPK indicates an expected call of UpdateCallbackStatusByPK
+func (mr *MockModelChangeEventServiceMockRecorder) UpdateCallbackStatusByPK(pk, callbackStatus, callbackMessage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCallbackStatusByPK", reflect.TypeOf((*MockModelChangeEventService)(nil).UpdateCallbackStatusByPK), pk, callbackStatus, callbackMessage)
+}
+
 // BulkCreate mocks base method
 func (m *MockModelChangeEventService) BulkCreate(modelChangeEvents []types.ModelChangeEvent) error {
 	m.ctrl.T.Helper()
@@ -208,6 +208,51 @@ func (mr *MockPolicyServiceMockRecorder) DeleteTemplatePolicies(subjectPK, templ
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTemplatePolicies", reflect.TypeOf((*MockPolicyService)(nil).DeleteTemplatePolicies), subjectPK, templateID)
 }
 
+// GetTemplatePolicyCount mocks base method
+func (m *MockPolicyService) GetTemplatePolicyCount(subjectPK, templateID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplatePolicyCount", subjectPK, templateID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplatePolicyCount indicates an expected call of GetTemplatePolicyCount
+func (mr *MockPolicyServiceMockRecorder) GetTemplatePolicyCount(subjectPK, templateID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplatePolicyCount", reflect.TypeOf((*MockPolicyService)(nil).GetTemplatePolicyCount), subjectPK, templateID)
+}
+
+// DeleteTemplatePoliciesWithLimit mocks base method
+func (m *MockPolicyService) DeleteTemplatePoliciesWithLimit(subjectPK, templateID, limit int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTemplatePoliciesWithLimit", subjectPK, templateID, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteTemplatePoliciesWithLimit indicates an expected call of DeleteTemplatePoliciesWithLimit
+func (mr *MockPolicyServiceMockRecorder) DeleteTemplatePoliciesWithLimit(subjectPK, templateID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTemplatePoliciesWithLimit", reflect.TypeOf((*MockPolicyService)(nil).DeleteTemplatePoliciesWithLimit), subjectPK, templateID, limit)
+}
+
+// UpdateTemplatePoliciesExpiredAt mocks base method
+func (m *MockPolicyService) UpdateTemplatePoliciesExpiredAt(subjectPK, templateID, expiredAt int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplatePoliciesExpiredAt", subjectPK, templateID, expiredAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTemplatePoliciesExpiredAt indicates an expected call of UpdateTemplatePoliciesExpiredAt
+func (mr *MockPolicyServiceMockRecorder) UpdateTemplatePoliciesExpiredAt(subjectPK, templateID, expiredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplatePoliciesExpiredAt", reflect.TypeOf((*MockPolicyService)(nil).UpdateTemplatePoliciesExpiredAt), subjectPK, templateID, expiredAt)
+}
+
 // Get mocks base method
 func (m *MockPolicyService) Get(pk int64) (types.QueryPolicy, error) {
 	m.ctrl.T.Helper()
@@ -253,6 +298,36 @@ func (mr *MockPolicyServiceMockRecorder) GetCountByActionBeforeExpiredAt(actionP
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCountByActionBeforeExpiredAt", reflect.TypeOf((*MockPolicyService)(nil).GetCountByActionBeforeExpiredAt), actionPK, expiredAt)
 }
 
+// GetCountByActionPKs mocks base method
+func (m *MockPolicyService) GetCountByActionPKs(actionPKs []int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCountByActionPKs", actionPKs)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCountByActionPKs indicates an expected call of GetCountByActionPKs
+func (mr *MockPolicyServiceMockRecorder) GetCountByActionPKs(actionPKs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCountByActionPKs", reflect.TypeOf((*MockPolicyService)(nil).GetCountByActionPKs), actionPKs)
+}
+
+// GetSubjectCountByActionPKs mocks base method
+func (m *MockPolicyService) GetSubjectCountByActionPKs(actionPKs []int64, subjectType string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectCountByActionPKs", actionPKs, subjectType)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectCountByActionPKs indicates an expected call of GetSubjectCountByActionPKs
+func (mr *MockPolicyServiceMockRecorder) GetSubjectCountByActionPKs(actionPKs, subjectType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectCountByActionPKs", reflect.TypeOf((*MockPolicyService)(nil).GetSubjectCountByActionPKs), actionPKs, subjectType)
+}
+
 // ListQueryByPKs mocks base method
 func (m *MockPolicyService) ListQueryByPKs(pks []int64) ([]types.QueryPolicy, error) {
 	m.ctrl.T.Helper()
@@ -268,6 +343,21 @@ func (mr *MockPolicyServiceMockRecorder) ListQueryByPKs(pks interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListQueryByPKs", reflect.TypeOf((*MockPolicyService)(nil).ListQueryByPKs), pks)
 }
 
+// ListPagingQueryBetweenExpiredAtAfterPK mocks base method
+func (m *MockPolicyService) ListPagingQueryBetweenExpiredAtAfterPK(minExpiredAt, minPK, beginExpiredAt, endExpiredAt, limit int64) ([]types.QueryPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingQueryBetweenExpiredAtAfterPK", minExpiredAt, minPK, beginExpiredAt, endExpiredAt, limit)
+	ret0, _ := ret[0].([]types.QueryPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingQueryBetweenExpiredAtAfterPK indicates an expected call of ListPagingQueryBetweenExpiredAtAfterPK
+func (mr *MockPolicyServiceMockRecorder) ListPagingQueryBetweenExpiredAtAfterPK(minExpiredAt, minPK, beginExpiredAt, endExpiredAt, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingQueryBetweenExpiredAtAfterPK", reflect.TypeOf((*MockPolicyService)(nil).ListPagingQueryBetweenExpiredAtAfterPK), minExpiredAt, minPK, beginExpiredAt, endExpiredAt, limit)
+}
+
 // HasAnyByActionPK mocks base method
 func (m *MockPolicyService) HasAnyByActionPK(actionPK int64) (bool, error) {
 	m.ctrl.T.Helper()
@@ -282,3 +372,18 @@ func (mr *MockPolicyServiceMockRecorder) HasAnyByActionPK(actionPK interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasAnyByActionPK", reflect.TypeOf((*MockPolicyService)(nil).HasAnyByActionPK), actionPK)
 }
+
+// ListExpressionAfterPK mocks base method
+func (m *MockPolicyService) ListExpressionAfterPK(afterPK, limit int64) ([]types.AuthExpression, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpressionAfterPK", afterPK, limit)
+	ret0, _ := ret[0].([]types.AuthExpression)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpressionAfterPK indicates an expected call of ListExpressionAfterPK
+func (mr *MockPolicyServiceMockRecorder) ListExpressionAfterPK(afterPK, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpressionAfterPK", reflect.TypeOf((*MockPolicyService)(nil).ListExpressionAfterPK), afterPK, limit)
+}
@@ -93,6 +93,36 @@ func (mr *MockSubjectServiceMockRecorder) ListPaging(_type, limit, offset interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaging", reflect.TypeOf((*MockSubjectService)(nil).ListPaging), _type, limit, offset)
 }
 
+// GetSearchCount mocks base method
+func (m *MockSubjectService) GetSearchCount(_type, keyword string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSearchCount", _type, keyword)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSearchCount indicates an expected call of GetSearchCount
+func (mr *MockSubjectServiceMockRecorder) GetSearchCount(_type, keyword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSearchCount", reflect.TypeOf((*MockSubjectService)(nil).GetSearchCount), _type, keyword)
+}
+
+// SearchPaging mocks base method
+func (m *MockSubjectService) SearchPaging(_type, keyword string, limit, offset int64) ([]types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPaging", _type, keyword, limit, offset)
+	ret0, _ := ret[0].([]types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPaging indicates an expected call of SearchPaging
+func (mr *MockSubjectServiceMockRecorder) SearchPaging(_type, keyword, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPaging", reflect.TypeOf((*MockSubjectService)(nil).SearchPaging), _type, keyword, limit, offset)
+}
+
 // ListPKsBySubjects mocks base method
 func (m *MockSubjectService) ListPKsBySubjects(subjects []types.Subject) ([]int64, error) {
 	m.ctrl.T.Helper()
@@ -123,47 +153,34 @@ func (mr *MockSubjectServiceMockRecorder) ListByPKs(pks interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPKs", reflect.TypeOf((*MockSubjectService)(nil).ListByPKs), pks)
 }
 
-// BulkCreate mocks base method
-func (m *MockSubjectService) BulkCreate(subjects []types.Subject) error {
+// ListExistSubjects mocks base method
+func (m *MockSubjectService) ListExistSubjects(subjects []types.Subject) ([]types.Subject, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BulkCreate", subjects)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ListExistSubjects", subjects)
+	ret0, _ := ret[0].([]types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// BulkCreate indicates an expected call of BulkCreate
-func (mr *MockSubjectServiceMockRecorder) BulkCreate(subjects interface{}) *gomock.Call {
+// ListExistSubjects indicates an expected call of ListExistSubjects
+func (mr *MockSubjectServiceMockRecorder) ListExistSubjects(subjects interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreate", reflect.TypeOf((*MockSubjectService)(nil).BulkCreate), subjects)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExistSubjects", reflect.TypeOf((*MockSubjectService)(nil).ListExistSubjects), subjects)
 }
 
-// BulkDelete mocks base method
-func (m *MockSubjectService) BulkDelete(subjects []types.Subject) ([]int64, error) {
+// ListFrozenPKs mocks base method
+func (m *MockSubjectService) ListFrozenPKs() ([]int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BulkDelete", subjects)
+	ret := m.ctrl.Call(m, "ListFrozenPKs")
 	ret0, _ := ret[0].([]int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// BulkDelete indicates an expected call of BulkDelete
-func (mr *MockSubjectServiceMockRecorder) BulkDelete(subjects interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDelete", reflect.TypeOf((*MockSubjectService)(nil).BulkDelete), subjects)
-}
-
-// BulkUpdateName mocks base method
-func (m *MockSubjectService) BulkUpdateName(subjects []types.Subject) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BulkUpdateName", subjects)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// BulkUpdateName indicates an expected call of BulkUpdateName
-func (mr *MockSubjectServiceMockRecorder) BulkUpdateName(subjects interface{}) *gomock.Call {
+// ListFrozenPKs indicates an expected call of ListFrozenPKs
+func (mr *MockSubjectServiceMockRecorder) ListFrozenPKs() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpdateName", reflect.TypeOf((*MockSubjectService)(nil).BulkUpdateName), subjects)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFrozenPKs", reflect.TypeOf((*MockSubjectService)(nil).ListFrozenPKs))
 }
 
 // GetThinSubjectGroups mocks base method
@@ -211,6 +228,51 @@ func (mr *MockSubjectServiceMockRecorder) ListSubjectGroups(_type, id, beforeExp
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectGroups", reflect.TypeOf((*MockSubjectService)(nil).ListSubjectGroups), _type, id, beforeExpiredAt)
 }
 
+// GetSubjectGroupCount mocks base method
+func (m *MockSubjectService) GetSubjectGroupCount(_type, id string, beforeExpiredAt int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectGroupCount", _type, id, beforeExpiredAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectGroupCount indicates an expected call of GetSubjectGroupCount
+func (mr *MockSubjectServiceMockRecorder) GetSubjectGroupCount(_type, id, beforeExpiredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectGroupCount", reflect.TypeOf((*MockSubjectService)(nil).GetSubjectGroupCount), _type, id, beforeExpiredAt)
+}
+
+// ListPagingSubjectGroups mocks base method
+func (m *MockSubjectService) ListPagingSubjectGroups(_type, id string, beforeExpiredAt, limit, offset int64) ([]types.SubjectGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectGroups", _type, id, beforeExpiredAt, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectGroups indicates an expected call of ListPagingSubjectGroups
+func (mr *MockSubjectServiceMockRecorder) ListPagingSubjectGroups(_type, id, beforeExpiredAt, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectGroups", reflect.TypeOf((*MockSubjectService)(nil).ListPagingSubjectGroups), _type, id, beforeExpiredAt, limit, offset)
+}
+
+// GetGroupSetting mocks base method
+func (m *MockSubjectService) GetGroupSetting(_type, id string) (types.GroupSetting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupSetting", _type, id)
+	ret0, _ := ret[0].(types.GroupSetting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupSetting indicates an expected call of GetGroupSetting
+func (mr *MockSubjectServiceMockRecorder) GetGroupSetting(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupSetting", reflect.TypeOf((*MockSubjectService)(nil).GetGroupSetting), _type, id)
+}
+
 // GetMemberCount mocks base method
 func (m *MockSubjectService) GetMemberCount(_type, id string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -226,6 +288,21 @@ func (mr *MockSubjectServiceMockRecorder) GetMemberCount(_type, id interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberCount", reflect.TypeOf((*MockSubjectService)(nil).GetMemberCount), _type, id)
 }
 
+// GetMemberCountBySubjectType mocks base method
+func (m *MockSubjectService) GetMemberCountBySubjectType(_type, id, subjectType string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberCountBySubjectType", _type, id, subjectType)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberCountBySubjectType indicates an expected call of GetMemberCountBySubjectType
+func (mr *MockSubjectServiceMockRecorder) GetMemberCountBySubjectType(_type, id, subjectType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberCountBySubjectType", reflect.TypeOf((*MockSubjectService)(nil).GetMemberCountBySubjectType), _type, id, subjectType)
+}
+
 // GetMemberCountBeforeExpiredAt mocks base method
 func (m *MockSubjectService) GetMemberCountBeforeExpiredAt(_type, id string, expiredAt int64) (int64, error) {
 	m.ctrl.T.Helper()
@@ -256,6 +333,21 @@ func (mr *MockSubjectServiceMockRecorder) ListPagingMember(_type, id, limit, off
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingMember", reflect.TypeOf((*MockSubjectService)(nil).ListPagingMember), _type, id, limit, offset)
 }
 
+// ListPagingMemberBySubjectType mocks base method
+func (m *MockSubjectService) ListPagingMemberBySubjectType(_type, id, subjectType string, limit, offset int64) ([]types.SubjectMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingMemberBySubjectType", _type, id, subjectType, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingMemberBySubjectType indicates an expected call of ListPagingMemberBySubjectType
+func (mr *MockSubjectServiceMockRecorder) ListPagingMemberBySubjectType(_type, id, subjectType, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingMemberBySubjectType", reflect.TypeOf((*MockSubjectService)(nil).ListPagingMemberBySubjectType), _type, id, subjectType, limit, offset)
+}
+
 // ListPagingMemberBeforeExpiredAt mocks base method
 func (m *MockSubjectService) ListPagingMemberBeforeExpiredAt(_type, id string, expiredAt, limit, offset int64) ([]types.SubjectMember, error) {
 	m.ctrl.T.Helper()
@@ -301,47 +393,95 @@ func (mr *MockSubjectServiceMockRecorder) ListMember(_type, id interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMember", reflect.TypeOf((*MockSubjectService)(nil).ListMember), _type, id)
 }
 
-// UpdateMembersExpiredAt mocks base method
-func (m *MockSubjectService) UpdateMembersExpiredAt(members []types.SubjectMember) error {
+// ListRedundantMembers mocks base method
+func (m *MockSubjectService) ListRedundantMembers(_type, id string) ([]types.RedundantMember, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateMembersExpiredAt", members)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ListRedundantMembers", _type, id)
+	ret0, _ := ret[0].([]types.RedundantMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// UpdateMembersExpiredAt indicates an expected call of UpdateMembersExpiredAt
-func (mr *MockSubjectServiceMockRecorder) UpdateMembersExpiredAt(members interface{}) *gomock.Call {
+// ListRedundantMembers indicates an expected call of ListRedundantMembers
+func (mr *MockSubjectServiceMockRecorder) ListRedundantMembers(_type, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMembersExpiredAt", reflect.TypeOf((*MockSubjectService)(nil).UpdateMembersExpiredAt), members)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRedundantMembers", reflect.TypeOf((*MockSubjectService)(nil).ListRedundantMembers), _type, id)
 }
 
-// BulkDeleteSubjectMembers mocks base method
-func (m *MockSubjectService) BulkDeleteSubjectMembers(_type, id string, members []types.Subject) (map[string]int64, error) {
+// ListGroupEffectiveUsersAfterPK mocks base method
+func (m *MockSubjectService) ListGroupEffectiveUsersAfterPK(_type, id string, afterPK, limit int64) ([]types.GroupEffectiveUser, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BulkDeleteSubjectMembers", _type, id, members)
-	ret0, _ := ret[0].(map[string]int64)
+	ret := m.ctrl.Call(m, "ListGroupEffectiveUsersAfterPK", _type, id, afterPK, limit)
+	ret0, _ := ret[0].([]types.GroupEffectiveUser)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListGroupEffectiveUsersAfterPK indicates an expected call of ListGroupEffectiveUsersAfterPK
+func (mr *MockSubjectServiceMockRecorder) ListGroupEffectiveUsersAfterPK(_type, id, afterPK, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGroupEffectiveUsersAfterPK", reflect.TypeOf((*MockSubjectService)(nil).ListGroupEffectiveUsersAfterPK), _type, id, afterPK, limit)
+}
+
+// GetSubjectMemberSnapshot mocks base method
+func (m *MockSubjectService) GetSubjectMemberSnapshot(pk int64) (types.SubjectMemberSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectMemberSnapshot", pk)
+	ret0, _ := ret[0].(types.SubjectMemberSnapshot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// BulkDeleteSubjectMembers indicates an expected call of BulkDeleteSubjectMembers
-func (mr *MockSubjectServiceMockRecorder) BulkDeleteSubjectMembers(_type, id, members interface{}) *gomock.Call {
+// GetSubjectMemberSnapshot indicates an expected call of GetSubjectMemberSnapshot
+func (mr *MockSubjectServiceMockRecorder) GetSubjectMemberSnapshot(pk interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDeleteSubjectMembers", reflect.TypeOf((*MockSubjectService)(nil).BulkDeleteSubjectMembers), _type, id, members)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectMemberSnapshot", reflect.TypeOf((*MockSubjectService)(nil).GetSubjectMemberSnapshot), pk)
 }
 
-// BulkCreateSubjectMembers mocks base method
-func (m *MockSubjectService) BulkCreateSubjectMembers(_type, id string, members []types.Subject, policyExpiredAt int64) error {
+// ListSubjectMemberSnapshots mocks base method
+func (m *MockSubjectService) ListSubjectMemberSnapshots(_type, id string) ([]types.SubjectMemberSnapshot, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BulkCreateSubjectMembers", _type, id, members, policyExpiredAt)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ListSubjectMemberSnapshots", _type, id)
+	ret0, _ := ret[0].([]types.SubjectMemberSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// BulkCreateSubjectMembers indicates an expected call of BulkCreateSubjectMembers
-func (mr *MockSubjectServiceMockRecorder) BulkCreateSubjectMembers(_type, id, members, policyExpiredAt interface{}) *gomock.Call {
+// ListSubjectMemberSnapshots indicates an expected call of ListSubjectMemberSnapshots
+func (mr *MockSubjectServiceMockRecorder) ListSubjectMemberSnapshots(_type, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateSubjectMembers", reflect.TypeOf((*MockSubjectService)(nil).BulkCreateSubjectMembers), _type, id, members, policyExpiredAt)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectMemberSnapshots", reflect.TypeOf((*MockSubjectService)(nil).ListSubjectMemberSnapshots), _type, id)
+}
+
+// GetSubjectMemberEventCount mocks base method
+func (m *MockSubjectService) GetSubjectMemberEventCount(filter types.SubjectMemberEventFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectMemberEventCount", filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectMemberEventCount indicates an expected call of GetSubjectMemberEventCount
+func (mr *MockSubjectServiceMockRecorder) GetSubjectMemberEventCount(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectMemberEventCount", reflect.TypeOf((*MockSubjectService)(nil).GetSubjectMemberEventCount), filter)
+}
+
+// ListPagingSubjectMemberEvent mocks base method
+func (m *MockSubjectService) ListPagingSubjectMemberEvent(filter types.SubjectMemberEventFilter, limit, offset int64) ([]types.SubjectMemberEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectMemberEvent", filter, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectMemberEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectMemberEvent indicates an expected call of ListPagingSubjectMemberEvent
+func (mr *MockSubjectServiceMockRecorder) ListPagingSubjectMemberEvent(filter, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectMemberEvent", reflect.TypeOf((*MockSubjectService)(nil).ListPagingSubjectMemberEvent), filter, limit, offset)
 }
 
 // GetSubjectDepartmentPKs mocks base method
@@ -389,48 +529,34 @@ func (mr *MockSubjectServiceMockRecorder) ListPagingSubjectDepartment(limit, off
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectDepartment", reflect.TypeOf((*MockSubjectService)(nil).ListPagingSubjectDepartment), limit, offset)
 }
 
-// BulkCreateSubjectDepartments mocks base method
-func (m *MockSubjectService) BulkCreateSubjectDepartments(subjectDepartments []types.SubjectDepartment) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BulkCreateSubjectDepartments", subjectDepartments)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// BulkCreateSubjectDepartments indicates an expected call of BulkCreateSubjectDepartments
-func (mr *MockSubjectServiceMockRecorder) BulkCreateSubjectDepartments(subjectDepartments interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateSubjectDepartments", reflect.TypeOf((*MockSubjectService)(nil).BulkCreateSubjectDepartments), subjectDepartments)
-}
-
-// BulkUpdateSubjectDepartments mocks base method
-func (m *MockSubjectService) BulkUpdateSubjectDepartments(subjectDepartments []types.SubjectDepartment) ([]int64, error) {
+// GetSubjectDepartmentHistoryCount mocks base method
+func (m *MockSubjectService) GetSubjectDepartmentHistoryCount(subjectPK int64) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BulkUpdateSubjectDepartments", subjectDepartments)
-	ret0, _ := ret[0].([]int64)
+	ret := m.ctrl.Call(m, "GetSubjectDepartmentHistoryCount", subjectPK)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// BulkUpdateSubjectDepartments indicates an expected call of BulkUpdateSubjectDepartments
-func (mr *MockSubjectServiceMockRecorder) BulkUpdateSubjectDepartments(subjectDepartments interface{}) *gomock.Call {
+// GetSubjectDepartmentHistoryCount indicates an expected call of GetSubjectDepartmentHistoryCount
+func (mr *MockSubjectServiceMockRecorder) GetSubjectDepartmentHistoryCount(subjectPK interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpdateSubjectDepartments", reflect.TypeOf((*MockSubjectService)(nil).BulkUpdateSubjectDepartments), subjectDepartments)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectDepartmentHistoryCount", reflect.TypeOf((*MockSubjectService)(nil).GetSubjectDepartmentHistoryCount), subjectPK)
 }
 
-// BulkDeleteSubjectDepartments mocks base method
-func (m *MockSubjectService) BulkDeleteSubjectDepartments(subjectIDs []string) ([]int64, error) {
+// ListPagingSubjectDepartmentHistory mocks base method
+func (m *MockSubjectService) ListPagingSubjectDepartmentHistory(subjectPK, limit, offset int64) ([]types.SubjectDepartmentHistory, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BulkDeleteSubjectDepartments", subjectIDs)
-	ret0, _ := ret[0].([]int64)
+	ret := m.ctrl.Call(m, "ListPagingSubjectDepartmentHistory", subjectPK, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectDepartmentHistory)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// BulkDeleteSubjectDepartments indicates an expected call of BulkDeleteSubjectDepartments
-func (mr *MockSubjectServiceMockRecorder) BulkDeleteSubjectDepartments(subjectIDs interface{}) *gomock.Call {
+// ListPagingSubjectDepartmentHistory indicates an expected call of ListPagingSubjectDepartmentHistory
+func (mr *MockSubjectServiceMockRecorder) ListPagingSubjectDepartmentHistory(subjectPK, limit, offset interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDeleteSubjectDepartments", reflect.TypeOf((*MockSubjectService)(nil).BulkDeleteSubjectDepartments), subjectIDs)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectDepartmentHistory", reflect.TypeOf((*MockSubjectService)(nil).ListPagingSubjectDepartmentHistory), subjectPK, limit, offset)
 }
 
 // ListSubjectPKByRole mocks base method
@@ -463,30 +589,2052 @@ func (mr *MockSubjectServiceMockRecorder) ListRoleSystemIDBySubjectPK(pk interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoleSystemIDBySubjectPK", reflect.TypeOf((*MockSubjectService)(nil).ListRoleSystemIDBySubjectPK), pk)
 }
 
-// BulkCreateSubjectRoles mocks base method
-func (m *MockSubjectService) BulkCreateSubjectRoles(roleType, system string, subjects []types.Subject) error {
+// ListSubjectRoles mocks base method
+func (m *MockSubjectService) ListSubjectRoles(_type, id string) ([]types.SubjectRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjectRoles", _type, id)
+	ret0, _ := ret[0].([]types.SubjectRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubjectRoles indicates an expected call of ListSubjectRoles
+func (mr *MockSubjectServiceMockRecorder) ListSubjectRoles(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectRoles", reflect.TypeOf((*MockSubjectService)(nil).ListSubjectRoles), _type, id)
+}
+
+// GetSubjectRoleCount mocks base method
+func (m *MockSubjectService) GetSubjectRoleCount() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectRoleCount")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectRoleCount indicates an expected call of GetSubjectRoleCount
+func (mr *MockSubjectServiceMockRecorder) GetSubjectRoleCount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectRoleCount", reflect.TypeOf((*MockSubjectService)(nil).GetSubjectRoleCount))
+}
+
+// ListPagingSubjectRoles mocks base method
+func (m *MockSubjectService) ListPagingSubjectRoles(limit, offset int64) ([]types.SubjectRoleHolder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectRoles", limit, offset)
+	ret0, _ := ret[0].([]types.SubjectRoleHolder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectRoles indicates an expected call of ListPagingSubjectRoles
+func (mr *MockSubjectServiceMockRecorder) ListPagingSubjectRoles(limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectRoles", reflect.TypeOf((*MockSubjectService)(nil).ListPagingSubjectRoles), limit, offset)
+}
+
+// GetSubjectRoleHistoryCount mocks base method
+func (m *MockSubjectService) GetSubjectRoleHistoryCount(roleType, system string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectRoleHistoryCount", roleType, system)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectRoleHistoryCount indicates an expected call of GetSubjectRoleHistoryCount
+func (mr *MockSubjectServiceMockRecorder) GetSubjectRoleHistoryCount(roleType, system interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectRoleHistoryCount", reflect.TypeOf((*MockSubjectService)(nil).GetSubjectRoleHistoryCount), roleType, system)
+}
+
+// ListPagingSubjectRoleHistory mocks base method
+func (m *MockSubjectService) ListPagingSubjectRoleHistory(roleType, system string, limit, offset int64) ([]types.SubjectRoleHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectRoleHistory", roleType, system, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectRoleHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectRoleHistory indicates an expected call of ListPagingSubjectRoleHistory
+func (mr *MockSubjectServiceMockRecorder) ListPagingSubjectRoleHistory(roleType, system, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectRoleHistory", reflect.TypeOf((*MockSubjectService)(nil).ListPagingSubjectRoleHistory), roleType, system, limit, offset)
+}
+
+// BulkCreate mocks base method
+func (m *MockSubjectService) BulkCreate(subjects []types.Subject) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BulkCreateSubjectRoles", roleType, system, subjects)
+	ret := m.ctrl.Call(m, "BulkCreate", subjects)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// BulkCreateSubjectRoles indicates an expected call of BulkCreateSubjectRoles
-func (mr *MockSubjectServiceMockRecorder) BulkCreateSubjectRoles(roleType, system, subjects interface{}) *gomock.Call {
+// BulkCreate indicates an expected call of BulkCreate
+func (mr *MockSubjectServiceMockRecorder) BulkCreate(subjects interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreate", reflect.TypeOf((*MockSubjectService)(nil).BulkCreate), subjects)
+}
+
+// BulkDelete mocks base method
+func (m *MockSubjectService) BulkDelete(subjects []types.Subject) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkDelete", subjects)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkDelete indicates an expected call of BulkDelete
+func (mr *MockSubjectServiceMockRecorder) BulkDelete(subjects interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDelete", reflect.TypeOf((*MockSubjectService)(nil).BulkDelete), subjects)
+}
+
+// BulkUpdateName mocks base method
+func (m *MockSubjectService) BulkUpdateName(subjects []types.Subject) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkUpdateName", subjects)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkUpdateName indicates an expected call of BulkUpdateName
+func (mr *MockSubjectServiceMockRecorder) BulkUpdateName(subjects interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpdateName", reflect.TypeOf((*MockSubjectService)(nil).BulkUpdateName), subjects)
+}
+
+// BulkUpdateFrozen mocks base method
+func (m *MockSubjectService) BulkUpdateFrozen(subjects []types.Subject, frozen bool) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkUpdateFrozen", subjects, frozen)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkUpdateFrozen indicates an expected call of BulkUpdateFrozen
+func (mr *MockSubjectServiceMockRecorder) BulkUpdateFrozen(subjects, frozen interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpdateFrozen", reflect.TypeOf((*MockSubjectService)(nil).BulkUpdateFrozen), subjects, frozen)
+}
+
+// UpdateMembersExpiredAt mocks base method
+func (m *MockSubjectService) UpdateMembersExpiredAt(members []types.SubjectMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMembersExpiredAt", members)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMembersExpiredAt indicates an expected call of UpdateMembersExpiredAt
+func (mr *MockSubjectServiceMockRecorder) UpdateMembersExpiredAt(members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMembersExpiredAt", reflect.TypeOf((*MockSubjectService)(nil).UpdateMembersExpiredAt), members)
+}
+
+// BulkDeleteSubjectMembers mocks base method
+func (m *MockSubjectService) BulkDeleteSubjectMembers(_type, id string, members []types.Subject) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkDeleteSubjectMembers", _type, id, members)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkDeleteSubjectMembers indicates an expected call of BulkDeleteSubjectMembers
+func (mr *MockSubjectServiceMockRecorder) BulkDeleteSubjectMembers(_type, id, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDeleteSubjectMembers", reflect.TypeOf((*MockSubjectService)(nil).BulkDeleteSubjectMembers), _type, id, members)
+}
+
+// BulkCreateSubjectMembers mocks base method
+func (m *MockSubjectService) BulkCreateSubjectMembers(_type, id string, members []types.Subject, policyExpiredAt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkCreateSubjectMembers", _type, id, members, policyExpiredAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkCreateSubjectMembers indicates an expected call of BulkCreateSubjectMembers
+func (mr *MockSubjectServiceMockRecorder) BulkCreateSubjectMembers(_type, id, members, policyExpiredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateSubjectMembers", reflect.TypeOf((*MockSubjectService)(nil).BulkCreateSubjectMembers), _type, id, members, policyExpiredAt)
+}
+
+// PurgeExpiredMembers mocks base method
+func (m *MockSubjectService) PurgeExpiredMembers(expiredAt, limit int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredMembers", expiredAt, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpiredMembers indicates an expected call of PurgeExpiredMembers
+func (mr *MockSubjectServiceMockRecorder) PurgeExpiredMembers(expiredAt, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredMembers", reflect.TypeOf((*MockSubjectService)(nil).PurgeExpiredMembers), expiredAt, limit)
+}
+
+// SaveGroupSetting mocks base method
+func (m *MockSubjectService) SaveGroupSetting(_type, id string, setting types.GroupSetting) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveGroupSetting", _type, id, setting)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveGroupSetting indicates an expected call of SaveGroupSetting
+func (mr *MockSubjectServiceMockRecorder) SaveGroupSetting(_type, id, setting interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveGroupSetting", reflect.TypeOf((*MockSubjectService)(nil).SaveGroupSetting), _type, id, setting)
+}
+
+// DeleteGroupSetting mocks base method
+func (m *MockSubjectService) DeleteGroupSetting(_type, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGroupSetting", _type, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGroupSetting indicates an expected call of DeleteGroupSetting
+func (mr *MockSubjectServiceMockRecorder) DeleteGroupSetting(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGroupSetting", reflect.TypeOf((*MockSubjectService)(nil).DeleteGroupSetting), _type, id)
+}
+
+// CopySubjectMembers mocks base method
+func (m *MockSubjectService) CopySubjectMembers(_type, sourceID, targetID string, move bool) (types.SubjectMemberCopyResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopySubjectMembers", _type, sourceID, targetID, move)
+	ret0, _ := ret[0].(types.SubjectMemberCopyResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopySubjectMembers indicates an expected call of CopySubjectMembers
+func (mr *MockSubjectServiceMockRecorder) CopySubjectMembers(_type, sourceID, targetID, move interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopySubjectMembers", reflect.TypeOf((*MockSubjectService)(nil).CopySubjectMembers), _type, sourceID, targetID, move)
+}
+
+// CreateSubjectMemberSnapshot mocks base method
+func (m *MockSubjectService) CreateSubjectMemberSnapshot(_type, id, operator, source string) (types.SubjectMemberSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubjectMemberSnapshot", _type, id, operator, source)
+	ret0, _ := ret[0].(types.SubjectMemberSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubjectMemberSnapshot indicates an expected call of CreateSubjectMemberSnapshot
+func (mr *MockSubjectServiceMockRecorder) CreateSubjectMemberSnapshot(_type, id, operator, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubjectMemberSnapshot", reflect.TypeOf((*MockSubjectService)(nil).CreateSubjectMemberSnapshot), _type, id, operator, source)
+}
+
+// RestoreSubjectMemberSnapshot mocks base method
+func (m *MockSubjectService) RestoreSubjectMemberSnapshot(pk int64, operator, source string) (types.SubjectMemberRestoreResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreSubjectMemberSnapshot", pk, operator, source)
+	ret0, _ := ret[0].(types.SubjectMemberRestoreResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreSubjectMemberSnapshot indicates an expected call of RestoreSubjectMemberSnapshot
+func (mr *MockSubjectServiceMockRecorder) RestoreSubjectMemberSnapshot(pk, operator, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSubjectMemberSnapshot", reflect.TypeOf((*MockSubjectService)(nil).RestoreSubjectMemberSnapshot), pk, operator, source)
+}
+
+// BulkCreateSubjectDepartments mocks base method
+func (m *MockSubjectService) BulkCreateSubjectDepartments(subjectDepartments []types.SubjectDepartment, source string) (types.SubjectDepartmentBulkResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkCreateSubjectDepartments", subjectDepartments, source)
+	ret0, _ := ret[0].(types.SubjectDepartmentBulkResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkCreateSubjectDepartments indicates an expected call of BulkCreateSubjectDepartments
+func (mr *MockSubjectServiceMockRecorder) BulkCreateSubjectDepartments(subjectDepartments, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateSubjectDepartments", reflect.TypeOf((*MockSubjectService)(nil).BulkCreateSubjectDepartments), subjectDepartments, source)
+}
+
+// BulkUpdateSubjectDepartments mocks base method
+func (m *MockSubjectService) BulkUpdateSubjectDepartments(subjectDepartments []types.SubjectDepartment, source string) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkUpdateSubjectDepartments", subjectDepartments, source)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkUpdateSubjectDepartments indicates an expected call of BulkUpdateSubjectDepartments
+func (mr *MockSubjectServiceMockRecorder) BulkUpdateSubjectDepartments(subjectDepartments, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpdateSubjectDepartments", reflect.TypeOf((*MockSubjectService)(nil).BulkUpdateSubjectDepartments), subjectDepartments, source)
+}
+
+// BulkDeleteSubjectDepartments mocks base method
+func (m *MockSubjectService) BulkDeleteSubjectDepartments(subjectIDs []string, source string) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkDeleteSubjectDepartments", subjectIDs, source)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkDeleteSubjectDepartments indicates an expected call of BulkDeleteSubjectDepartments
+func (mr *MockSubjectServiceMockRecorder) BulkDeleteSubjectDepartments(subjectIDs, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDeleteSubjectDepartments", reflect.TypeOf((*MockSubjectService)(nil).BulkDeleteSubjectDepartments), subjectIDs, source)
+}
+
+// BulkCreateSubjectRoles mocks base method
+func (m *MockSubjectService) BulkCreateSubjectRoles(roleType, system string, subjects []types.Subject, operator, source string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkCreateSubjectRoles", roleType, system, subjects, operator, source)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkCreateSubjectRoles indicates an expected call of BulkCreateSubjectRoles
+func (mr *MockSubjectServiceMockRecorder) BulkCreateSubjectRoles(roleType, system, subjects, operator, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateSubjectRoles", reflect.TypeOf((*MockSubjectService)(nil).BulkCreateSubjectRoles), roleType, system, subjects, operator, source)
+}
+
+// BulkCreateSubjectMultiRoles mocks base method
+func (m *MockSubjectService) BulkCreateSubjectMultiRoles(roles []types.SubjectRole, subjects []types.Subject, operator, source string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkCreateSubjectMultiRoles", roles, subjects, operator, source)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkCreateSubjectMultiRoles indicates an expected call of BulkCreateSubjectMultiRoles
+func (mr *MockSubjectServiceMockRecorder) BulkCreateSubjectMultiRoles(roles, subjects, operator, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateSubjectMultiRoles", reflect.TypeOf((*MockSubjectService)(nil).BulkCreateSubjectMultiRoles), roles, subjects, operator, source)
+}
+
+// BulkDeleteSubjectRoles mocks base method
+func (m *MockSubjectService) BulkDeleteSubjectRoles(roleType, system string, subjects []types.Subject, operator, source string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkDeleteSubjectRoles", roleType, system, subjects, operator, source)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkDeleteSubjectRoles indicates an expected call of BulkDeleteSubjectRoles
+func (mr *MockSubjectServiceMockRecorder) BulkDeleteSubjectRoles(roleType, system, subjects, operator, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDeleteSubjectRoles", reflect.TypeOf((*MockSubjectService)(nil).BulkDeleteSubjectRoles), roleType, system, subjects, operator, source)
+}
+
+// MockSubjectReadService is a mock of SubjectReadService interface
+type MockSubjectReadService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubjectReadServiceMockRecorder
+}
+
+// MockSubjectReadServiceMockRecorder is the mock recorder for MockSubjectReadService
+type MockSubjectReadServiceMockRecorder struct {
+	mock *MockSubjectReadService
+}
+
+// NewMockSubjectReadService creates a new mock instance
+func NewMockSubjectReadService(ctrl *gomock.Controller) *MockSubjectReadService {
+	mock := &MockSubjectReadService{ctrl: ctrl}
+	mock.recorder = &MockSubjectReadServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSubjectReadService) EXPECT() *MockSubjectReadServiceMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockSubjectReadService) Get(pk int64) (types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", pk)
+	ret0, _ := ret[0].(types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockSubjectReadServiceMockRecorder) Get(pk interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSubjectReadService)(nil).Get), pk)
+}
+
+// GetPK mocks base method
+func (m *MockSubjectReadService) GetPK(_type, id string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPK", _type, id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPK indicates an expected call of GetPK
+func (mr *MockSubjectReadServiceMockRecorder) GetPK(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPK", reflect.TypeOf((*MockSubjectReadService)(nil).GetPK), _type, id)
+}
+
+// GetCount mocks base method
+func (m *MockSubjectReadService) GetCount(_type string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCount", _type)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCount indicates an expected call of GetCount
+func (mr *MockSubjectReadServiceMockRecorder) GetCount(_type interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCount", reflect.TypeOf((*MockSubjectReadService)(nil).GetCount), _type)
+}
+
+// ListPaging mocks base method
+func (m *MockSubjectReadService) ListPaging(_type string, limit, offset int64) ([]types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPaging", _type, limit, offset)
+	ret0, _ := ret[0].([]types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPaging indicates an expected call of ListPaging
+func (mr *MockSubjectReadServiceMockRecorder) ListPaging(_type, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaging", reflect.TypeOf((*MockSubjectReadService)(nil).ListPaging), _type, limit, offset)
+}
+
+// GetSearchCount mocks base method
+func (m *MockSubjectReadService) GetSearchCount(_type, keyword string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSearchCount", _type, keyword)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSearchCount indicates an expected call of GetSearchCount
+func (mr *MockSubjectReadServiceMockRecorder) GetSearchCount(_type, keyword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSearchCount", reflect.TypeOf((*MockSubjectReadService)(nil).GetSearchCount), _type, keyword)
+}
+
+// SearchPaging mocks base method
+func (m *MockSubjectReadService) SearchPaging(_type, keyword string, limit, offset int64) ([]types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPaging", _type, keyword, limit, offset)
+	ret0, _ := ret[0].([]types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPaging indicates an expected call of SearchPaging
+func (mr *MockSubjectReadServiceMockRecorder) SearchPaging(_type, keyword, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPaging", reflect.TypeOf((*MockSubjectReadService)(nil).SearchPaging), _type, keyword, limit, offset)
+}
+
+// ListPKsBySubjects mocks base method
+func (m *MockSubjectReadService) ListPKsBySubjects(subjects []types.Subject) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPKsBySubjects", subjects)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPKsBySubjects indicates an expected call of ListPKsBySubjects
+func (mr *MockSubjectReadServiceMockRecorder) ListPKsBySubjects(subjects interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPKsBySubjects", reflect.TypeOf((*MockSubjectReadService)(nil).ListPKsBySubjects), subjects)
+}
+
+// ListByPKs mocks base method
+func (m *MockSubjectReadService) ListByPKs(pks []int64) ([]types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByPKs", pks)
+	ret0, _ := ret[0].([]types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByPKs indicates an expected call of ListByPKs
+func (mr *MockSubjectReadServiceMockRecorder) ListByPKs(pks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPKs", reflect.TypeOf((*MockSubjectReadService)(nil).ListByPKs), pks)
+}
+
+// ListExistSubjects mocks base method
+func (m *MockSubjectReadService) ListExistSubjects(subjects []types.Subject) ([]types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExistSubjects", subjects)
+	ret0, _ := ret[0].([]types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExistSubjects indicates an expected call of ListExistSubjects
+func (mr *MockSubjectReadServiceMockRecorder) ListExistSubjects(subjects interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExistSubjects", reflect.TypeOf((*MockSubjectReadService)(nil).ListExistSubjects), subjects)
+}
+
+// ListFrozenPKs mocks base method
+func (m *MockSubjectReadService) ListFrozenPKs() ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFrozenPKs")
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFrozenPKs indicates an expected call of ListFrozenPKs
+func (mr *MockSubjectReadServiceMockRecorder) ListFrozenPKs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFrozenPKs", reflect.TypeOf((*MockSubjectReadService)(nil).ListFrozenPKs))
+}
+
+// GetThinSubjectGroups mocks base method
+func (m *MockSubjectReadService) GetThinSubjectGroups(pk int64) ([]types.ThinSubjectGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetThinSubjectGroups", pk)
+	ret0, _ := ret[0].([]types.ThinSubjectGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetThinSubjectGroups indicates an expected call of GetThinSubjectGroups
+func (mr *MockSubjectReadServiceMockRecorder) GetThinSubjectGroups(pk interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetThinSubjectGroups", reflect.TypeOf((*MockSubjectReadService)(nil).GetThinSubjectGroups), pk)
+}
+
+// ListSubjectEffectGroups mocks base method
+func (m *MockSubjectReadService) ListSubjectEffectGroups(pks []int64) (map[int64][]types.ThinSubjectGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjectEffectGroups", pks)
+	ret0, _ := ret[0].(map[int64][]types.ThinSubjectGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubjectEffectGroups indicates an expected call of ListSubjectEffectGroups
+func (mr *MockSubjectReadServiceMockRecorder) ListSubjectEffectGroups(pks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectEffectGroups", reflect.TypeOf((*MockSubjectReadService)(nil).ListSubjectEffectGroups), pks)
+}
+
+// ListSubjectGroups mocks base method
+func (m *MockSubjectReadService) ListSubjectGroups(_type, id string, beforeExpiredAt int64) ([]types.SubjectGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjectGroups", _type, id, beforeExpiredAt)
+	ret0, _ := ret[0].([]types.SubjectGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubjectGroups indicates an expected call of ListSubjectGroups
+func (mr *MockSubjectReadServiceMockRecorder) ListSubjectGroups(_type, id, beforeExpiredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectGroups", reflect.TypeOf((*MockSubjectReadService)(nil).ListSubjectGroups), _type, id, beforeExpiredAt)
+}
+
+// GetSubjectGroupCount mocks base method
+func (m *MockSubjectReadService) GetSubjectGroupCount(_type, id string, beforeExpiredAt int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectGroupCount", _type, id, beforeExpiredAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectGroupCount indicates an expected call of GetSubjectGroupCount
+func (mr *MockSubjectReadServiceMockRecorder) GetSubjectGroupCount(_type, id, beforeExpiredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectGroupCount", reflect.TypeOf((*MockSubjectReadService)(nil).GetSubjectGroupCount), _type, id, beforeExpiredAt)
+}
+
+// ListPagingSubjectGroups mocks base method
+func (m *MockSubjectReadService) ListPagingSubjectGroups(_type, id string, beforeExpiredAt, limit, offset int64) ([]types.SubjectGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectGroups", _type, id, beforeExpiredAt, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectGroups indicates an expected call of ListPagingSubjectGroups
+func (mr *MockSubjectReadServiceMockRecorder) ListPagingSubjectGroups(_type, id, beforeExpiredAt, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectGroups", reflect.TypeOf((*MockSubjectReadService)(nil).ListPagingSubjectGroups), _type, id, beforeExpiredAt, limit, offset)
+}
+
+// GetGroupSetting mocks base method
+func (m *MockSubjectReadService) GetGroupSetting(_type, id string) (types.GroupSetting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupSetting", _type, id)
+	ret0, _ := ret[0].(types.GroupSetting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupSetting indicates an expected call of GetGroupSetting
+func (mr *MockSubjectReadServiceMockRecorder) GetGroupSetting(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupSetting", reflect.TypeOf((*MockSubjectReadService)(nil).GetGroupSetting), _type, id)
+}
+
+// GetMemberCount mocks base method
+func (m *MockSubjectReadService) GetMemberCount(_type, id string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberCount", _type, id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberCount indicates an expected call of GetMemberCount
+func (mr *MockSubjectReadServiceMockRecorder) GetMemberCount(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberCount", reflect.TypeOf((*MockSubjectReadService)(nil).GetMemberCount), _type, id)
+}
+
+// GetMemberCountBySubjectType mocks base method
+func (m *MockSubjectReadService) GetMemberCountBySubjectType(_type, id, subjectType string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberCountBySubjectType", _type, id, subjectType)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberCountBySubjectType indicates an expected call of GetMemberCountBySubjectType
+func (mr *MockSubjectReadServiceMockRecorder) GetMemberCountBySubjectType(_type, id, subjectType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberCountBySubjectType", reflect.TypeOf((*MockSubjectReadService)(nil).GetMemberCountBySubjectType), _type, id, subjectType)
+}
+
+// GetMemberCountBeforeExpiredAt mocks base method
+func (m *MockSubjectReadService) GetMemberCountBeforeExpiredAt(_type, id string, expiredAt int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberCountBeforeExpiredAt", _type, id, expiredAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberCountBeforeExpiredAt indicates an expected call of GetMemberCountBeforeExpiredAt
+func (mr *MockSubjectReadServiceMockRecorder) GetMemberCountBeforeExpiredAt(_type, id, expiredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberCountBeforeExpiredAt", reflect.TypeOf((*MockSubjectReadService)(nil).GetMemberCountBeforeExpiredAt), _type, id, expiredAt)
+}
+
+// ListPagingMember mocks base method
+func (m *MockSubjectReadService) ListPagingMember(_type, id string, limit, offset int64) ([]types.SubjectMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingMember", _type, id, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingMember indicates an expected call of ListPagingMember
+func (mr *MockSubjectReadServiceMockRecorder) ListPagingMember(_type, id, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingMember", reflect.TypeOf((*MockSubjectReadService)(nil).ListPagingMember), _type, id, limit, offset)
+}
+
+// ListPagingMemberBySubjectType mocks base method
+func (m *MockSubjectReadService) ListPagingMemberBySubjectType(_type, id, subjectType string, limit, offset int64) ([]types.SubjectMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingMemberBySubjectType", _type, id, subjectType, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingMemberBySubjectType indicates an expected call of ListPagingMemberBySubjectType
+func (mr *MockSubjectReadServiceMockRecorder) ListPagingMemberBySubjectType(_type, id, subjectType, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingMemberBySubjectType", reflect.TypeOf((*MockSubjectReadService)(nil).ListPagingMemberBySubjectType), _type, id, subjectType, limit, offset)
+}
+
+// ListPagingMemberBeforeExpiredAt mocks base method
+func (m *MockSubjectReadService) ListPagingMemberBeforeExpiredAt(_type, id string, expiredAt, limit, offset int64) ([]types.SubjectMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingMemberBeforeExpiredAt", _type, id, expiredAt, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingMemberBeforeExpiredAt indicates an expected call of ListPagingMemberBeforeExpiredAt
+func (mr *MockSubjectReadServiceMockRecorder) ListPagingMemberBeforeExpiredAt(_type, id, expiredAt, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingMemberBeforeExpiredAt", reflect.TypeOf((*MockSubjectReadService)(nil).ListPagingMemberBeforeExpiredAt), _type, id, expiredAt, limit, offset)
+}
+
+// ListExistSubjectsBeforeExpiredAt mocks base method
+func (m *MockSubjectReadService) ListExistSubjectsBeforeExpiredAt(subjects []types.Subject, expiredAt int64) ([]types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExistSubjectsBeforeExpiredAt", subjects, expiredAt)
+	ret0, _ := ret[0].([]types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExistSubjectsBeforeExpiredAt indicates an expected call of ListExistSubjectsBeforeExpiredAt
+func (mr *MockSubjectReadServiceMockRecorder) ListExistSubjectsBeforeExpiredAt(subjects, expiredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExistSubjectsBeforeExpiredAt", reflect.TypeOf((*MockSubjectReadService)(nil).ListExistSubjectsBeforeExpiredAt), subjects, expiredAt)
+}
+
+// ListMember mocks base method
+func (m *MockSubjectReadService) ListMember(_type, id string) ([]types.SubjectMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMember", _type, id)
+	ret0, _ := ret[0].([]types.SubjectMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMember indicates an expected call of ListMember
+func (mr *MockSubjectReadServiceMockRecorder) ListMember(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMember", reflect.TypeOf((*MockSubjectReadService)(nil).ListMember), _type, id)
+}
+
+// ListRedundantMembers mocks base method
+func (m *MockSubjectReadService) ListRedundantMembers(_type, id string) ([]types.RedundantMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRedundantMembers", _type, id)
+	ret0, _ := ret[0].([]types.RedundantMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRedundantMembers indicates an expected call of ListRedundantMembers
+func (mr *MockSubjectReadServiceMockRecorder) ListRedundantMembers(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRedundantMembers", reflect.TypeOf((*MockSubjectReadService)(nil).ListRedundantMembers), _type, id)
+}
+
+// ListGroupEffectiveUsersAfterPK mocks base method
+func (m *MockSubjectReadService) ListGroupEffectiveUsersAfterPK(_type, id string, afterPK, limit int64) ([]types.GroupEffectiveUser, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGroupEffectiveUsersAfterPK", _type, id, afterPK, limit)
+	ret0, _ := ret[0].([]types.GroupEffectiveUser)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListGroupEffectiveUsersAfterPK indicates an expected call of ListGroupEffectiveUsersAfterPK
+func (mr *MockSubjectReadServiceMockRecorder) ListGroupEffectiveUsersAfterPK(_type, id, afterPK, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGroupEffectiveUsersAfterPK", reflect.TypeOf((*MockSubjectReadService)(nil).ListGroupEffectiveUsersAfterPK), _type, id, afterPK, limit)
+}
+
+// GetSubjectMemberSnapshot mocks base method
+func (m *MockSubjectReadService) GetSubjectMemberSnapshot(pk int64) (types.SubjectMemberSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectMemberSnapshot", pk)
+	ret0, _ := ret[0].(types.SubjectMemberSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectMemberSnapshot indicates an expected call of GetSubjectMemberSnapshot
+func (mr *MockSubjectReadServiceMockRecorder) GetSubjectMemberSnapshot(pk interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectMemberSnapshot", reflect.TypeOf((*MockSubjectReadService)(nil).GetSubjectMemberSnapshot), pk)
+}
+
+// ListSubjectMemberSnapshots mocks base method
+func (m *MockSubjectReadService) ListSubjectMemberSnapshots(_type, id string) ([]types.SubjectMemberSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjectMemberSnapshots", _type, id)
+	ret0, _ := ret[0].([]types.SubjectMemberSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubjectMemberSnapshots indicates an expected call of ListSubjectMemberSnapshots
+func (mr *MockSubjectReadServiceMockRecorder) ListSubjectMemberSnapshots(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectMemberSnapshots", reflect.TypeOf((*MockSubjectReadService)(nil).ListSubjectMemberSnapshots), _type, id)
+}
+
+// GetSubjectMemberEventCount mocks base method
+func (m *MockSubjectReadService) GetSubjectMemberEventCount(filter types.SubjectMemberEventFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectMemberEventCount", filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectMemberEventCount indicates an expected call of GetSubjectMemberEventCount
+func (mr *MockSubjectReadServiceMockRecorder) GetSubjectMemberEventCount(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectMemberEventCount", reflect.TypeOf((*MockSubjectReadService)(nil).GetSubjectMemberEventCount), filter)
+}
+
+// ListPagingSubjectMemberEvent mocks base method
+func (m *MockSubjectReadService) ListPagingSubjectMemberEvent(filter types.SubjectMemberEventFilter, limit, offset int64) ([]types.SubjectMemberEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectMemberEvent", filter, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectMemberEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectMemberEvent indicates an expected call of ListPagingSubjectMemberEvent
+func (mr *MockSubjectReadServiceMockRecorder) ListPagingSubjectMemberEvent(filter, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectMemberEvent", reflect.TypeOf((*MockSubjectReadService)(nil).ListPagingSubjectMemberEvent), filter, limit, offset)
+}
+
+// GetSubjectDepartmentPKs mocks base method
+func (m *MockSubjectReadService) GetSubjectDepartmentPKs(subjectPK int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectDepartmentPKs", subjectPK)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectDepartmentPKs indicates an expected call of GetSubjectDepartmentPKs
+func (mr *MockSubjectReadServiceMockRecorder) GetSubjectDepartmentPKs(subjectPK interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectDepartmentPKs", reflect.TypeOf((*MockSubjectReadService)(nil).GetSubjectDepartmentPKs), subjectPK)
+}
+
+// GetSubjectDepartmentCount mocks base method
+func (m *MockSubjectReadService) GetSubjectDepartmentCount() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectDepartmentCount")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectDepartmentCount indicates an expected call of GetSubjectDepartmentCount
+func (mr *MockSubjectReadServiceMockRecorder) GetSubjectDepartmentCount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectDepartmentCount", reflect.TypeOf((*MockSubjectReadService)(nil).GetSubjectDepartmentCount))
+}
+
+// ListPagingSubjectDepartment mocks base method
+func (m *MockSubjectReadService) ListPagingSubjectDepartment(limit, offset int64) ([]types.SubjectDepartment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectDepartment", limit, offset)
+	ret0, _ := ret[0].([]types.SubjectDepartment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectDepartment indicates an expected call of ListPagingSubjectDepartment
+func (mr *MockSubjectReadServiceMockRecorder) ListPagingSubjectDepartment(limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectDepartment", reflect.TypeOf((*MockSubjectReadService)(nil).ListPagingSubjectDepartment), limit, offset)
+}
+
+// GetSubjectDepartmentHistoryCount mocks base method
+func (m *MockSubjectReadService) GetSubjectDepartmentHistoryCount(subjectPK int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectDepartmentHistoryCount", subjectPK)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectDepartmentHistoryCount indicates an expected call of GetSubjectDepartmentHistoryCount
+func (mr *MockSubjectReadServiceMockRecorder) GetSubjectDepartmentHistoryCount(subjectPK interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectDepartmentHistoryCount", reflect.TypeOf((*MockSubjectReadService)(nil).GetSubjectDepartmentHistoryCount), subjectPK)
+}
+
+// ListPagingSubjectDepartmentHistory mocks base method
+func (m *MockSubjectReadService) ListPagingSubjectDepartmentHistory(subjectPK, limit, offset int64) ([]types.SubjectDepartmentHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectDepartmentHistory", subjectPK, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectDepartmentHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectDepartmentHistory indicates an expected call of ListPagingSubjectDepartmentHistory
+func (mr *MockSubjectReadServiceMockRecorder) ListPagingSubjectDepartmentHistory(subjectPK, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectDepartmentHistory", reflect.TypeOf((*MockSubjectReadService)(nil).ListPagingSubjectDepartmentHistory), subjectPK, limit, offset)
+}
+
+// ListSubjectPKByRole mocks base method
+func (m *MockSubjectReadService) ListSubjectPKByRole(roleType, system string) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjectPKByRole", roleType, system)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubjectPKByRole indicates an expected call of ListSubjectPKByRole
+func (mr *MockSubjectReadServiceMockRecorder) ListSubjectPKByRole(roleType, system interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectPKByRole", reflect.TypeOf((*MockSubjectReadService)(nil).ListSubjectPKByRole), roleType, system)
+}
+
+// ListRoleSystemIDBySubjectPK mocks base method
+func (m *MockSubjectReadService) ListRoleSystemIDBySubjectPK(pk int64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRoleSystemIDBySubjectPK", pk)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRoleSystemIDBySubjectPK indicates an expected call of ListRoleSystemIDBySubjectPK
+func (mr *MockSubjectReadServiceMockRecorder) ListRoleSystemIDBySubjectPK(pk interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoleSystemIDBySubjectPK", reflect.TypeOf((*MockSubjectReadService)(nil).ListRoleSystemIDBySubjectPK), pk)
+}
+
+// ListSubjectRoles mocks base method
+func (m *MockSubjectReadService) ListSubjectRoles(_type, id string) ([]types.SubjectRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjectRoles", _type, id)
+	ret0, _ := ret[0].([]types.SubjectRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubjectRoles indicates an expected call of ListSubjectRoles
+func (mr *MockSubjectReadServiceMockRecorder) ListSubjectRoles(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectRoles", reflect.TypeOf((*MockSubjectReadService)(nil).ListSubjectRoles), _type, id)
+}
+
+// GetSubjectRoleCount mocks base method
+func (m *MockSubjectReadService) GetSubjectRoleCount() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectRoleCount")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectRoleCount indicates an expected call of GetSubjectRoleCount
+func (mr *MockSubjectReadServiceMockRecorder) GetSubjectRoleCount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectRoleCount", reflect.TypeOf((*MockSubjectReadService)(nil).GetSubjectRoleCount))
+}
+
+// ListPagingSubjectRoles mocks base method
+func (m *MockSubjectReadService) ListPagingSubjectRoles(limit, offset int64) ([]types.SubjectRoleHolder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectRoles", limit, offset)
+	ret0, _ := ret[0].([]types.SubjectRoleHolder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectRoles indicates an expected call of ListPagingSubjectRoles
+func (mr *MockSubjectReadServiceMockRecorder) ListPagingSubjectRoles(limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectRoles", reflect.TypeOf((*MockSubjectReadService)(nil).ListPagingSubjectRoles), limit, offset)
+}
+
+// GetSubjectRoleHistoryCount mocks base method
+func (m *MockSubjectReadService) GetSubjectRoleHistoryCount(roleType, system string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectRoleHistoryCount", roleType, system)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectRoleHistoryCount indicates an expected call of GetSubjectRoleHistoryCount
+func (mr *MockSubjectReadServiceMockRecorder) GetSubjectRoleHistoryCount(roleType, system interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectRoleHistoryCount", reflect.TypeOf((*MockSubjectReadService)(nil).GetSubjectRoleHistoryCount), roleType, system)
+}
+
+// ListPagingSubjectRoleHistory mocks base method
+func (m *MockSubjectReadService) ListPagingSubjectRoleHistory(roleType, system string, limit, offset int64) ([]types.SubjectRoleHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectRoleHistory", roleType, system, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectRoleHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectRoleHistory indicates an expected call of ListPagingSubjectRoleHistory
+func (mr *MockSubjectReadServiceMockRecorder) ListPagingSubjectRoleHistory(roleType, system, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectRoleHistory", reflect.TypeOf((*MockSubjectReadService)(nil).ListPagingSubjectRoleHistory), roleType, system, limit, offset)
+}
+
+// MockSubjectBaseReadService is a mock of SubjectBaseReadService interface
+type MockSubjectBaseReadService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubjectBaseReadServiceMockRecorder
+}
+
+// MockSubjectBaseReadServiceMockRecorder is the mock recorder for MockSubjectBaseReadService
+type MockSubjectBaseReadServiceMockRecorder struct {
+	mock *MockSubjectBaseReadService
+}
+
+// NewMockSubjectBaseReadService creates a new mock instance
+func NewMockSubjectBaseReadService(ctrl *gomock.Controller) *MockSubjectBaseReadService {
+	mock := &MockSubjectBaseReadService{ctrl: ctrl}
+	mock.recorder = &MockSubjectBaseReadServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSubjectBaseReadService) EXPECT() *MockSubjectBaseReadServiceMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockSubjectBaseReadService) Get(pk int64) (types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", pk)
+	ret0, _ := ret[0].(types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockSubjectBaseReadServiceMockRecorder) Get(pk interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSubjectBaseReadService)(nil).Get), pk)
+}
+
+// GetPK mocks base method
+func (m *MockSubjectBaseReadService) GetPK(_type, id string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPK", _type, id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPK indicates an expected call of GetPK
+func (mr *MockSubjectBaseReadServiceMockRecorder) GetPK(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPK", reflect.TypeOf((*MockSubjectBaseReadService)(nil).GetPK), _type, id)
+}
+
+// GetCount mocks base method
+func (m *MockSubjectBaseReadService) GetCount(_type string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCount", _type)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCount indicates an expected call of GetCount
+func (mr *MockSubjectBaseReadServiceMockRecorder) GetCount(_type interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCount", reflect.TypeOf((*MockSubjectBaseReadService)(nil).GetCount), _type)
+}
+
+// ListPaging mocks base method
+func (m *MockSubjectBaseReadService) ListPaging(_type string, limit, offset int64) ([]types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPaging", _type, limit, offset)
+	ret0, _ := ret[0].([]types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPaging indicates an expected call of ListPaging
+func (mr *MockSubjectBaseReadServiceMockRecorder) ListPaging(_type, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaging", reflect.TypeOf((*MockSubjectBaseReadService)(nil).ListPaging), _type, limit, offset)
+}
+
+// GetSearchCount mocks base method
+func (m *MockSubjectBaseReadService) GetSearchCount(_type, keyword string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSearchCount", _type, keyword)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSearchCount indicates an expected call of GetSearchCount
+func (mr *MockSubjectBaseReadServiceMockRecorder) GetSearchCount(_type, keyword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSearchCount", reflect.TypeOf((*MockSubjectBaseReadService)(nil).GetSearchCount), _type, keyword)
+}
+
+// SearchPaging mocks base method
+func (m *MockSubjectBaseReadService) SearchPaging(_type, keyword string, limit, offset int64) ([]types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPaging", _type, keyword, limit, offset)
+	ret0, _ := ret[0].([]types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPaging indicates an expected call of SearchPaging
+func (mr *MockSubjectBaseReadServiceMockRecorder) SearchPaging(_type, keyword, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPaging", reflect.TypeOf((*MockSubjectBaseReadService)(nil).SearchPaging), _type, keyword, limit, offset)
+}
+
+// ListPKsBySubjects mocks base method
+func (m *MockSubjectBaseReadService) ListPKsBySubjects(subjects []types.Subject) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPKsBySubjects", subjects)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPKsBySubjects indicates an expected call of ListPKsBySubjects
+func (mr *MockSubjectBaseReadServiceMockRecorder) ListPKsBySubjects(subjects interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPKsBySubjects", reflect.TypeOf((*MockSubjectBaseReadService)(nil).ListPKsBySubjects), subjects)
+}
+
+// ListByPKs mocks base method
+func (m *MockSubjectBaseReadService) ListByPKs(pks []int64) ([]types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByPKs", pks)
+	ret0, _ := ret[0].([]types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByPKs indicates an expected call of ListByPKs
+func (mr *MockSubjectBaseReadServiceMockRecorder) ListByPKs(pks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByPKs", reflect.TypeOf((*MockSubjectBaseReadService)(nil).ListByPKs), pks)
+}
+
+// ListExistSubjects mocks base method
+func (m *MockSubjectBaseReadService) ListExistSubjects(subjects []types.Subject) ([]types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExistSubjects", subjects)
+	ret0, _ := ret[0].([]types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExistSubjects indicates an expected call of ListExistSubjects
+func (mr *MockSubjectBaseReadServiceMockRecorder) ListExistSubjects(subjects interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExistSubjects", reflect.TypeOf((*MockSubjectBaseReadService)(nil).ListExistSubjects), subjects)
+}
+
+// ListFrozenPKs mocks base method
+func (m *MockSubjectBaseReadService) ListFrozenPKs() ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFrozenPKs")
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFrozenPKs indicates an expected call of ListFrozenPKs
+func (mr *MockSubjectBaseReadServiceMockRecorder) ListFrozenPKs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFrozenPKs", reflect.TypeOf((*MockSubjectBaseReadService)(nil).ListFrozenPKs))
+}
+
+// MockSubjectGroupReadService is a mock of SubjectGroupReadService interface
+type MockSubjectGroupReadService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubjectGroupReadServiceMockRecorder
+}
+
+// MockSubjectGroupReadServiceMockRecorder is the mock recorder for MockSubjectGroupReadService
+type MockSubjectGroupReadServiceMockRecorder struct {
+	mock *MockSubjectGroupReadService
+}
+
+// NewMockSubjectGroupReadService creates a new mock instance
+func NewMockSubjectGroupReadService(ctrl *gomock.Controller) *MockSubjectGroupReadService {
+	mock := &MockSubjectGroupReadService{ctrl: ctrl}
+	mock.recorder = &MockSubjectGroupReadServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSubjectGroupReadService) EXPECT() *MockSubjectGroupReadServiceMockRecorder {
+	return m.recorder
+}
+
+// GetThinSubjectGroups mocks base method
+func (m *MockSubjectGroupReadService) GetThinSubjectGroups(pk int64) ([]types.ThinSubjectGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetThinSubjectGroups", pk)
+	ret0, _ := ret[0].([]types.ThinSubjectGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetThinSubjectGroups indicates an expected call of GetThinSubjectGroups
+func (mr *MockSubjectGroupReadServiceMockRecorder) GetThinSubjectGroups(pk interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetThinSubjectGroups", reflect.TypeOf((*MockSubjectGroupReadService)(nil).GetThinSubjectGroups), pk)
+}
+
+// ListSubjectEffectGroups mocks base method
+func (m *MockSubjectGroupReadService) ListSubjectEffectGroups(pks []int64) (map[int64][]types.ThinSubjectGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjectEffectGroups", pks)
+	ret0, _ := ret[0].(map[int64][]types.ThinSubjectGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubjectEffectGroups indicates an expected call of ListSubjectEffectGroups
+func (mr *MockSubjectGroupReadServiceMockRecorder) ListSubjectEffectGroups(pks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectEffectGroups", reflect.TypeOf((*MockSubjectGroupReadService)(nil).ListSubjectEffectGroups), pks)
+}
+
+// ListSubjectGroups mocks base method
+func (m *MockSubjectGroupReadService) ListSubjectGroups(_type, id string, beforeExpiredAt int64) ([]types.SubjectGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjectGroups", _type, id, beforeExpiredAt)
+	ret0, _ := ret[0].([]types.SubjectGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubjectGroups indicates an expected call of ListSubjectGroups
+func (mr *MockSubjectGroupReadServiceMockRecorder) ListSubjectGroups(_type, id, beforeExpiredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectGroups", reflect.TypeOf((*MockSubjectGroupReadService)(nil).ListSubjectGroups), _type, id, beforeExpiredAt)
+}
+
+// GetSubjectGroupCount mocks base method
+func (m *MockSubjectGroupReadService) GetSubjectGroupCount(_type, id string, beforeExpiredAt int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectGroupCount", _type, id, beforeExpiredAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectGroupCount indicates an expected call of GetSubjectGroupCount
+func (mr *MockSubjectGroupReadServiceMockRecorder) GetSubjectGroupCount(_type, id, beforeExpiredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectGroupCount", reflect.TypeOf((*MockSubjectGroupReadService)(nil).GetSubjectGroupCount), _type, id, beforeExpiredAt)
+}
+
+// ListPagingSubjectGroups mocks base method
+func (m *MockSubjectGroupReadService) ListPagingSubjectGroups(_type, id string, beforeExpiredAt, limit, offset int64) ([]types.SubjectGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectGroups", _type, id, beforeExpiredAt, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectGroups indicates an expected call of ListPagingSubjectGroups
+func (mr *MockSubjectGroupReadServiceMockRecorder) ListPagingSubjectGroups(_type, id, beforeExpiredAt, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectGroups", reflect.TypeOf((*MockSubjectGroupReadService)(nil).ListPagingSubjectGroups), _type, id, beforeExpiredAt, limit, offset)
+}
+
+// GetGroupSetting mocks base method
+func (m *MockSubjectGroupReadService) GetGroupSetting(_type, id string) (types.GroupSetting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupSetting", _type, id)
+	ret0, _ := ret[0].(types.GroupSetting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupSetting indicates an expected call of GetGroupSetting
+func (mr *MockSubjectGroupReadServiceMockRecorder) GetGroupSetting(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupSetting", reflect.TypeOf((*MockSubjectGroupReadService)(nil).GetGroupSetting), _type, id)
+}
+
+// MockSubjectMemberReadService is a mock of SubjectMemberReadService interface
+type MockSubjectMemberReadService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubjectMemberReadServiceMockRecorder
+}
+
+// MockSubjectMemberReadServiceMockRecorder is the mock recorder for MockSubjectMemberReadService
+type MockSubjectMemberReadServiceMockRecorder struct {
+	mock *MockSubjectMemberReadService
+}
+
+// NewMockSubjectMemberReadService creates a new mock instance
+func NewMockSubjectMemberReadService(ctrl *gomock.Controller) *MockSubjectMemberReadService {
+	mock := &MockSubjectMemberReadService{ctrl: ctrl}
+	mock.recorder = &MockSubjectMemberReadServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSubjectMemberReadService) EXPECT() *MockSubjectMemberReadServiceMockRecorder {
+	return m.recorder
+}
+
+// GetMemberCount mocks base method
+func (m *MockSubjectMemberReadService) GetMemberCount(_type, id string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberCount", _type, id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberCount indicates an expected call of GetMemberCount
+func (mr *MockSubjectMemberReadServiceMockRecorder) GetMemberCount(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberCount", reflect.TypeOf((*MockSubjectMemberReadService)(nil).GetMemberCount), _type, id)
+}
+
+// GetMemberCountBySubjectType mocks base method
+func (m *MockSubjectMemberReadService) GetMemberCountBySubjectType(_type, id, subjectType string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberCountBySubjectType", _type, id, subjectType)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberCountBySubjectType indicates an expected call of GetMemberCountBySubjectType
+func (mr *MockSubjectMemberReadServiceMockRecorder) GetMemberCountBySubjectType(_type, id, subjectType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberCountBySubjectType", reflect.TypeOf((*MockSubjectMemberReadService)(nil).GetMemberCountBySubjectType), _type, id, subjectType)
+}
+
+// GetMemberCountBeforeExpiredAt mocks base method
+func (m *MockSubjectMemberReadService) GetMemberCountBeforeExpiredAt(_type, id string, expiredAt int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberCountBeforeExpiredAt", _type, id, expiredAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberCountBeforeExpiredAt indicates an expected call of GetMemberCountBeforeExpiredAt
+func (mr *MockSubjectMemberReadServiceMockRecorder) GetMemberCountBeforeExpiredAt(_type, id, expiredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberCountBeforeExpiredAt", reflect.TypeOf((*MockSubjectMemberReadService)(nil).GetMemberCountBeforeExpiredAt), _type, id, expiredAt)
+}
+
+// ListPagingMember mocks base method
+func (m *MockSubjectMemberReadService) ListPagingMember(_type, id string, limit, offset int64) ([]types.SubjectMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingMember", _type, id, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingMember indicates an expected call of ListPagingMember
+func (mr *MockSubjectMemberReadServiceMockRecorder) ListPagingMember(_type, id, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingMember", reflect.TypeOf((*MockSubjectMemberReadService)(nil).ListPagingMember), _type, id, limit, offset)
+}
+
+// ListPagingMemberBySubjectType mocks base method
+func (m *MockSubjectMemberReadService) ListPagingMemberBySubjectType(_type, id, subjectType string, limit, offset int64) ([]types.SubjectMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingMemberBySubjectType", _type, id, subjectType, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingMemberBySubjectType indicates an expected call of ListPagingMemberBySubjectType
+func (mr *MockSubjectMemberReadServiceMockRecorder) ListPagingMemberBySubjectType(_type, id, subjectType, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingMemberBySubjectType", reflect.TypeOf((*MockSubjectMemberReadService)(nil).ListPagingMemberBySubjectType), _type, id, subjectType, limit, offset)
+}
+
+// ListPagingMemberBeforeExpiredAt mocks base method
+func (m *MockSubjectMemberReadService) ListPagingMemberBeforeExpiredAt(_type, id string, expiredAt, limit, offset int64) ([]types.SubjectMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingMemberBeforeExpiredAt", _type, id, expiredAt, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingMemberBeforeExpiredAt indicates an expected call of ListPagingMemberBeforeExpiredAt
+func (mr *MockSubjectMemberReadServiceMockRecorder) ListPagingMemberBeforeExpiredAt(_type, id, expiredAt, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingMemberBeforeExpiredAt", reflect.TypeOf((*MockSubjectMemberReadService)(nil).ListPagingMemberBeforeExpiredAt), _type, id, expiredAt, limit, offset)
+}
+
+// ListExistSubjectsBeforeExpiredAt mocks base method
+func (m *MockSubjectMemberReadService) ListExistSubjectsBeforeExpiredAt(subjects []types.Subject, expiredAt int64) ([]types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExistSubjectsBeforeExpiredAt", subjects, expiredAt)
+	ret0, _ := ret[0].([]types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExistSubjectsBeforeExpiredAt indicates an expected call of ListExistSubjectsBeforeExpiredAt
+func (mr *MockSubjectMemberReadServiceMockRecorder) ListExistSubjectsBeforeExpiredAt(subjects, expiredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExistSubjectsBeforeExpiredAt", reflect.TypeOf((*MockSubjectMemberReadService)(nil).ListExistSubjectsBeforeExpiredAt), subjects, expiredAt)
+}
+
+// ListMember mocks base method
+func (m *MockSubjectMemberReadService) ListMember(_type, id string) ([]types.SubjectMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMember", _type, id)
+	ret0, _ := ret[0].([]types.SubjectMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMember indicates an expected call of ListMember
+func (mr *MockSubjectMemberReadServiceMockRecorder) ListMember(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMember", reflect.TypeOf((*MockSubjectMemberReadService)(nil).ListMember), _type, id)
+}
+
+// ListRedundantMembers mocks base method
+func (m *MockSubjectMemberReadService) ListRedundantMembers(_type, id string) ([]types.RedundantMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRedundantMembers", _type, id)
+	ret0, _ := ret[0].([]types.RedundantMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRedundantMembers indicates an expected call of ListRedundantMembers
+func (mr *MockSubjectMemberReadServiceMockRecorder) ListRedundantMembers(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRedundantMembers", reflect.TypeOf((*MockSubjectMemberReadService)(nil).ListRedundantMembers), _type, id)
+}
+
+// ListGroupEffectiveUsersAfterPK mocks base method
+func (m *MockSubjectMemberReadService) ListGroupEffectiveUsersAfterPK(_type, id string, afterPK, limit int64) ([]types.GroupEffectiveUser, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGroupEffectiveUsersAfterPK", _type, id, afterPK, limit)
+	ret0, _ := ret[0].([]types.GroupEffectiveUser)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListGroupEffectiveUsersAfterPK indicates an expected call of ListGroupEffectiveUsersAfterPK
+func (mr *MockSubjectMemberReadServiceMockRecorder) ListGroupEffectiveUsersAfterPK(_type, id, afterPK, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGroupEffectiveUsersAfterPK", reflect.TypeOf((*MockSubjectMemberReadService)(nil).ListGroupEffectiveUsersAfterPK), _type, id, afterPK, limit)
+}
+
+// GetSubjectMemberSnapshot mocks base method
+func (m *MockSubjectMemberReadService) GetSubjectMemberSnapshot(pk int64) (types.SubjectMemberSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectMemberSnapshot", pk)
+	ret0, _ := ret[0].(types.SubjectMemberSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectMemberSnapshot indicates an expected call of GetSubjectMemberSnapshot
+func (mr *MockSubjectMemberReadServiceMockRecorder) GetSubjectMemberSnapshot(pk interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectMemberSnapshot", reflect.TypeOf((*MockSubjectMemberReadService)(nil).GetSubjectMemberSnapshot), pk)
+}
+
+// ListSubjectMemberSnapshots mocks base method
+func (m *MockSubjectMemberReadService) ListSubjectMemberSnapshots(_type, id string) ([]types.SubjectMemberSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjectMemberSnapshots", _type, id)
+	ret0, _ := ret[0].([]types.SubjectMemberSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubjectMemberSnapshots indicates an expected call of ListSubjectMemberSnapshots
+func (mr *MockSubjectMemberReadServiceMockRecorder) ListSubjectMemberSnapshots(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectMemberSnapshots", reflect.TypeOf((*MockSubjectMemberReadService)(nil).ListSubjectMemberSnapshots), _type, id)
+}
+
+// GetSubjectMemberEventCount mocks base method
+func (m *MockSubjectMemberReadService) GetSubjectMemberEventCount(filter types.SubjectMemberEventFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectMemberEventCount", filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectMemberEventCount indicates an expected call of GetSubjectMemberEventCount
+func (mr *MockSubjectMemberReadServiceMockRecorder) GetSubjectMemberEventCount(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectMemberEventCount", reflect.TypeOf((*MockSubjectMemberReadService)(nil).GetSubjectMemberEventCount), filter)
+}
+
+// ListPagingSubjectMemberEvent mocks base method
+func (m *MockSubjectMemberReadService) ListPagingSubjectMemberEvent(filter types.SubjectMemberEventFilter, limit, offset int64) ([]types.SubjectMemberEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectMemberEvent", filter, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectMemberEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectMemberEvent indicates an expected call of ListPagingSubjectMemberEvent
+func (mr *MockSubjectMemberReadServiceMockRecorder) ListPagingSubjectMemberEvent(filter, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectMemberEvent", reflect.TypeOf((*MockSubjectMemberReadService)(nil).ListPagingSubjectMemberEvent), filter, limit, offset)
+}
+
+// MockSubjectDepartmentReadService is a mock of SubjectDepartmentReadService interface
+type MockSubjectDepartmentReadService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubjectDepartmentReadServiceMockRecorder
+}
+
+// MockSubjectDepartmentReadServiceMockRecorder is the mock recorder for MockSubjectDepartmentReadService
+type MockSubjectDepartmentReadServiceMockRecorder struct {
+	mock *MockSubjectDepartmentReadService
+}
+
+// NewMockSubjectDepartmentReadService creates a new mock instance
+func NewMockSubjectDepartmentReadService(ctrl *gomock.Controller) *MockSubjectDepartmentReadService {
+	mock := &MockSubjectDepartmentReadService{ctrl: ctrl}
+	mock.recorder = &MockSubjectDepartmentReadServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSubjectDepartmentReadService) EXPECT() *MockSubjectDepartmentReadServiceMockRecorder {
+	return m.recorder
+}
+
+// GetSubjectDepartmentPKs mocks base method
+func (m *MockSubjectDepartmentReadService) GetSubjectDepartmentPKs(subjectPK int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectDepartmentPKs", subjectPK)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectDepartmentPKs indicates an expected call of GetSubjectDepartmentPKs
+func (mr *MockSubjectDepartmentReadServiceMockRecorder) GetSubjectDepartmentPKs(subjectPK interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectDepartmentPKs", reflect.TypeOf((*MockSubjectDepartmentReadService)(nil).GetSubjectDepartmentPKs), subjectPK)
+}
+
+// GetSubjectDepartmentCount mocks base method
+func (m *MockSubjectDepartmentReadService) GetSubjectDepartmentCount() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectDepartmentCount")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectDepartmentCount indicates an expected call of GetSubjectDepartmentCount
+func (mr *MockSubjectDepartmentReadServiceMockRecorder) GetSubjectDepartmentCount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectDepartmentCount", reflect.TypeOf((*MockSubjectDepartmentReadService)(nil).GetSubjectDepartmentCount))
+}
+
+// ListPagingSubjectDepartment mocks base method
+func (m *MockSubjectDepartmentReadService) ListPagingSubjectDepartment(limit, offset int64) ([]types.SubjectDepartment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectDepartment", limit, offset)
+	ret0, _ := ret[0].([]types.SubjectDepartment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectDepartment indicates an expected call of ListPagingSubjectDepartment
+func (mr *MockSubjectDepartmentReadServiceMockRecorder) ListPagingSubjectDepartment(limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectDepartment", reflect.TypeOf((*MockSubjectDepartmentReadService)(nil).ListPagingSubjectDepartment), limit, offset)
+}
+
+// GetSubjectDepartmentHistoryCount mocks base method
+func (m *MockSubjectDepartmentReadService) GetSubjectDepartmentHistoryCount(subjectPK int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectDepartmentHistoryCount", subjectPK)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectDepartmentHistoryCount indicates an expected call of GetSubjectDepartmentHistoryCount
+func (mr *MockSubjectDepartmentReadServiceMockRecorder) GetSubjectDepartmentHistoryCount(subjectPK interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectDepartmentHistoryCount", reflect.TypeOf((*MockSubjectDepartmentReadService)(nil).GetSubjectDepartmentHistoryCount), subjectPK)
+}
+
+// ListPagingSubjectDepartmentHistory mocks base method
+func (m *MockSubjectDepartmentReadService) ListPagingSubjectDepartmentHistory(subjectPK, limit, offset int64) ([]types.SubjectDepartmentHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectDepartmentHistory", subjectPK, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectDepartmentHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectDepartmentHistory indicates an expected call of ListPagingSubjectDepartmentHistory
+func (mr *MockSubjectDepartmentReadServiceMockRecorder) ListPagingSubjectDepartmentHistory(subjectPK, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectDepartmentHistory", reflect.TypeOf((*MockSubjectDepartmentReadService)(nil).ListPagingSubjectDepartmentHistory), subjectPK, limit, offset)
+}
+
+// MockSubjectRoleReadService is a mock of SubjectRoleReadService interface
+type MockSubjectRoleReadService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubjectRoleReadServiceMockRecorder
+}
+
+// MockSubjectRoleReadServiceMockRecorder is the mock recorder for MockSubjectRoleReadService
+type MockSubjectRoleReadServiceMockRecorder struct {
+	mock *MockSubjectRoleReadService
+}
+
+// NewMockSubjectRoleReadService creates a new mock instance
+func NewMockSubjectRoleReadService(ctrl *gomock.Controller) *MockSubjectRoleReadService {
+	mock := &MockSubjectRoleReadService{ctrl: ctrl}
+	mock.recorder = &MockSubjectRoleReadServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSubjectRoleReadService) EXPECT() *MockSubjectRoleReadServiceMockRecorder {
+	return m.recorder
+}
+
+// ListSubjectPKByRole mocks base method
+func (m *MockSubjectRoleReadService) ListSubjectPKByRole(roleType, system string) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjectPKByRole", roleType, system)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubjectPKByRole indicates an expected call of ListSubjectPKByRole
+func (mr *MockSubjectRoleReadServiceMockRecorder) ListSubjectPKByRole(roleType, system interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectPKByRole", reflect.TypeOf((*MockSubjectRoleReadService)(nil).ListSubjectPKByRole), roleType, system)
+}
+
+// ListRoleSystemIDBySubjectPK mocks base method
+func (m *MockSubjectRoleReadService) ListRoleSystemIDBySubjectPK(pk int64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRoleSystemIDBySubjectPK", pk)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRoleSystemIDBySubjectPK indicates an expected call of ListRoleSystemIDBySubjectPK
+func (mr *MockSubjectRoleReadServiceMockRecorder) ListRoleSystemIDBySubjectPK(pk interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoleSystemIDBySubjectPK", reflect.TypeOf((*MockSubjectRoleReadService)(nil).ListRoleSystemIDBySubjectPK), pk)
+}
+
+// ListSubjectRoles mocks base method
+func (m *MockSubjectRoleReadService) ListSubjectRoles(_type, id string) ([]types.SubjectRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjectRoles", _type, id)
+	ret0, _ := ret[0].([]types.SubjectRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubjectRoles indicates an expected call of ListSubjectRoles
+func (mr *MockSubjectRoleReadServiceMockRecorder) ListSubjectRoles(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjectRoles", reflect.TypeOf((*MockSubjectRoleReadService)(nil).ListSubjectRoles), _type, id)
+}
+
+// GetSubjectRoleCount mocks base method
+func (m *MockSubjectRoleReadService) GetSubjectRoleCount() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectRoleCount")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectRoleCount indicates an expected call of GetSubjectRoleCount
+func (mr *MockSubjectRoleReadServiceMockRecorder) GetSubjectRoleCount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectRoleCount", reflect.TypeOf((*MockSubjectRoleReadService)(nil).GetSubjectRoleCount))
+}
+
+// ListPagingSubjectRoles mocks base method
+func (m *MockSubjectRoleReadService) ListPagingSubjectRoles(limit, offset int64) ([]types.SubjectRoleHolder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectRoles", limit, offset)
+	ret0, _ := ret[0].([]types.SubjectRoleHolder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectRoles indicates an expected call of ListPagingSubjectRoles
+func (mr *MockSubjectRoleReadServiceMockRecorder) ListPagingSubjectRoles(limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectRoles", reflect.TypeOf((*MockSubjectRoleReadService)(nil).ListPagingSubjectRoles), limit, offset)
+}
+
+// GetSubjectRoleHistoryCount mocks base method
+func (m *MockSubjectRoleReadService) GetSubjectRoleHistoryCount(roleType, system string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectRoleHistoryCount", roleType, system)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectRoleHistoryCount indicates an expected call of GetSubjectRoleHistoryCount
+func (mr *MockSubjectRoleReadServiceMockRecorder) GetSubjectRoleHistoryCount(roleType, system interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectRoleHistoryCount", reflect.TypeOf((*MockSubjectRoleReadService)(nil).GetSubjectRoleHistoryCount), roleType, system)
+}
+
+// ListPagingSubjectRoleHistory mocks base method
+func (m *MockSubjectRoleReadService) ListPagingSubjectRoleHistory(roleType, system string, limit, offset int64) ([]types.SubjectRoleHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPagingSubjectRoleHistory", roleType, system, limit, offset)
+	ret0, _ := ret[0].([]types.SubjectRoleHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPagingSubjectRoleHistory indicates an expected call of ListPagingSubjectRoleHistory
+func (mr *MockSubjectRoleReadServiceMockRecorder) ListPagingSubjectRoleHistory(roleType, system, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPagingSubjectRoleHistory", reflect.TypeOf((*MockSubjectRoleReadService)(nil).ListPagingSubjectRoleHistory), roleType, system, limit, offset)
+}
+
+// MockSubjectWriteService is a mock of SubjectWriteService interface
+type MockSubjectWriteService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubjectWriteServiceMockRecorder
+}
+
+// MockSubjectWriteServiceMockRecorder is the mock recorder for MockSubjectWriteService
+type MockSubjectWriteServiceMockRecorder struct {
+	mock *MockSubjectWriteService
+}
+
+// NewMockSubjectWriteService creates a new mock instance
+func NewMockSubjectWriteService(ctrl *gomock.Controller) *MockSubjectWriteService {
+	mock := &MockSubjectWriteService{ctrl: ctrl}
+	mock.recorder = &MockSubjectWriteServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSubjectWriteService) EXPECT() *MockSubjectWriteServiceMockRecorder {
+	return m.recorder
+}
+
+// BulkCreate mocks base method
+func (m *MockSubjectWriteService) BulkCreate(subjects []types.Subject) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkCreate", subjects)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkCreate indicates an expected call of BulkCreate
+func (mr *MockSubjectWriteServiceMockRecorder) BulkCreate(subjects interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreate", reflect.TypeOf((*MockSubjectWriteService)(nil).BulkCreate), subjects)
+}
+
+// BulkDelete mocks base method
+func (m *MockSubjectWriteService) BulkDelete(subjects []types.Subject) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkDelete", subjects)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkDelete indicates an expected call of BulkDelete
+func (mr *MockSubjectWriteServiceMockRecorder) BulkDelete(subjects interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDelete", reflect.TypeOf((*MockSubjectWriteService)(nil).BulkDelete), subjects)
+}
+
+// BulkUpdateName mocks base method
+func (m *MockSubjectWriteService) BulkUpdateName(subjects []types.Subject) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkUpdateName", subjects)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkUpdateName indicates an expected call of BulkUpdateName
+func (mr *MockSubjectWriteServiceMockRecorder) BulkUpdateName(subjects interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpdateName", reflect.TypeOf((*MockSubjectWriteService)(nil).BulkUpdateName), subjects)
+}
+
+// BulkUpdateFrozen mocks base method
+func (m *MockSubjectWriteService) BulkUpdateFrozen(subjects []types.Subject, frozen bool) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkUpdateFrozen", subjects, frozen)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkUpdateFrozen indicates an expected call of BulkUpdateFrozen
+func (mr *MockSubjectWriteServiceMockRecorder) BulkUpdateFrozen(subjects, frozen interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpdateFrozen", reflect.TypeOf((*MockSubjectWriteService)(nil).BulkUpdateFrozen), subjects, frozen)
+}
+
+// UpdateMembersExpiredAt mocks base method
+func (m *MockSubjectWriteService) UpdateMembersExpiredAt(members []types.SubjectMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMembersExpiredAt", members)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMembersExpiredAt indicates an expected call of UpdateMembersExpiredAt
+func (mr *MockSubjectWriteServiceMockRecorder) UpdateMembersExpiredAt(members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMembersExpiredAt", reflect.TypeOf((*MockSubjectWriteService)(nil).UpdateMembersExpiredAt), members)
+}
+
+// BulkDeleteSubjectMembers mocks base method
+func (m *MockSubjectWriteService) BulkDeleteSubjectMembers(_type, id string, members []types.Subject) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkDeleteSubjectMembers", _type, id, members)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkDeleteSubjectMembers indicates an expected call of BulkDeleteSubjectMembers
+func (mr *MockSubjectWriteServiceMockRecorder) BulkDeleteSubjectMembers(_type, id, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDeleteSubjectMembers", reflect.TypeOf((*MockSubjectWriteService)(nil).BulkDeleteSubjectMembers), _type, id, members)
+}
+
+// BulkCreateSubjectMembers mocks base method
+func (m *MockSubjectWriteService) BulkCreateSubjectMembers(_type, id string, members []types.Subject, policyExpiredAt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkCreateSubjectMembers", _type, id, members, policyExpiredAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkCreateSubjectMembers indicates an expected call of BulkCreateSubjectMembers
+func (mr *MockSubjectWriteServiceMockRecorder) BulkCreateSubjectMembers(_type, id, members, policyExpiredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateSubjectMembers", reflect.TypeOf((*MockSubjectWriteService)(nil).BulkCreateSubjectMembers), _type, id, members, policyExpiredAt)
+}
+
+// PurgeExpiredMembers mocks base method
+func (m *MockSubjectWriteService) PurgeExpiredMembers(expiredAt, limit int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredMembers", expiredAt, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpiredMembers indicates an expected call of PurgeExpiredMembers
+func (mr *MockSubjectWriteServiceMockRecorder) PurgeExpiredMembers(expiredAt, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredMembers", reflect.TypeOf((*MockSubjectWriteService)(nil).PurgeExpiredMembers), expiredAt, limit)
+}
+
+// SaveGroupSetting mocks base method
+func (m *MockSubjectWriteService) SaveGroupSetting(_type, id string, setting types.GroupSetting) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveGroupSetting", _type, id, setting)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveGroupSetting indicates an expected call of SaveGroupSetting
+func (mr *MockSubjectWriteServiceMockRecorder) SaveGroupSetting(_type, id, setting interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveGroupSetting", reflect.TypeOf((*MockSubjectWriteService)(nil).SaveGroupSetting), _type, id, setting)
+}
+
+// DeleteGroupSetting mocks base method
+func (m *MockSubjectWriteService) DeleteGroupSetting(_type, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGroupSetting", _type, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGroupSetting indicates an expected call of DeleteGroupSetting
+func (mr *MockSubjectWriteServiceMockRecorder) DeleteGroupSetting(_type, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGroupSetting", reflect.TypeOf((*MockSubjectWriteService)(nil).DeleteGroupSetting), _type, id)
+}
+
+// CopySubjectMembers mocks base method
+func (m *MockSubjectWriteService) CopySubjectMembers(_type, sourceID, targetID string, move bool) (types.SubjectMemberCopyResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopySubjectMembers", _type, sourceID, targetID, move)
+	ret0, _ := ret[0].(types.SubjectMemberCopyResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopySubjectMembers indicates an expected call of CopySubjectMembers
+func (mr *MockSubjectWriteServiceMockRecorder) CopySubjectMembers(_type, sourceID, targetID, move interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopySubjectMembers", reflect.TypeOf((*MockSubjectWriteService)(nil).CopySubjectMembers), _type, sourceID, targetID, move)
+}
+
+// CreateSubjectMemberSnapshot mocks base method
+func (m *MockSubjectWriteService) CreateSubjectMemberSnapshot(_type, id, operator, source string) (types.SubjectMemberSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubjectMemberSnapshot", _type, id, operator, source)
+	ret0, _ := ret[0].(types.SubjectMemberSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubjectMemberSnapshot indicates an expected call of CreateSubjectMemberSnapshot
+func (mr *MockSubjectWriteServiceMockRecorder) CreateSubjectMemberSnapshot(_type, id, operator, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubjectMemberSnapshot", reflect.TypeOf((*MockSubjectWriteService)(nil).CreateSubjectMemberSnapshot), _type, id, operator, source)
+}
+
+// RestoreSubjectMemberSnapshot mocks base method
+func (m *MockSubjectWriteService) RestoreSubjectMemberSnapshot(pk int64, operator, source string) (types.SubjectMemberRestoreResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreSubjectMemberSnapshot", pk, operator, source)
+	ret0, _ := ret[0].(types.SubjectMemberRestoreResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreSubjectMemberSnapshot indicates an expected call of RestoreSubjectMemberSnapshot
+func (mr *MockSubjectWriteServiceMockRecorder) RestoreSubjectMemberSnapshot(pk, operator, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSubjectMemberSnapshot", reflect.TypeOf((*MockSubjectWriteService)(nil).RestoreSubjectMemberSnapshot), pk, operator, source)
+}
+
+// BulkCreateSubjectDepartments mocks base method
+func (m *MockSubjectWriteService) BulkCreateSubjectDepartments(subjectDepartments []types.SubjectDepartment, source string) (types.SubjectDepartmentBulkResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkCreateSubjectDepartments", subjectDepartments, source)
+	ret0, _ := ret[0].(types.SubjectDepartmentBulkResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkCreateSubjectDepartments indicates an expected call of BulkCreateSubjectDepartments
+func (mr *MockSubjectWriteServiceMockRecorder) BulkCreateSubjectDepartments(subjectDepartments, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateSubjectDepartments", reflect.TypeOf((*MockSubjectWriteService)(nil).BulkCreateSubjectDepartments), subjectDepartments, source)
+}
+
+// BulkUpdateSubjectDepartments mocks base method
+func (m *MockSubjectWriteService) BulkUpdateSubjectDepartments(subjectDepartments []types.SubjectDepartment, source string) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkUpdateSubjectDepartments", subjectDepartments, source)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkUpdateSubjectDepartments indicates an expected call of BulkUpdateSubjectDepartments
+func (mr *MockSubjectWriteServiceMockRecorder) BulkUpdateSubjectDepartments(subjectDepartments, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpdateSubjectDepartments", reflect.TypeOf((*MockSubjectWriteService)(nil).BulkUpdateSubjectDepartments), subjectDepartments, source)
+}
+
+// BulkDeleteSubjectDepartments mocks base method
+func (m *MockSubjectWriteService) BulkDeleteSubjectDepartments(subjectIDs []string, source string) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkDeleteSubjectDepartments", subjectIDs, source)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkDeleteSubjectDepartments indicates an expected call of BulkDeleteSubjectDepartments
+func (mr *MockSubjectWriteServiceMockRecorder) BulkDeleteSubjectDepartments(subjectIDs, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDeleteSubjectDepartments", reflect.TypeOf((*MockSubjectWriteService)(nil).BulkDeleteSubjectDepartments), subjectIDs, source)
+}
+
+// BulkCreateSubjectRoles mocks base method
+func (m *MockSubjectWriteService) BulkCreateSubjectRoles(roleType, system string, subjects []types.Subject, operator, source string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkCreateSubjectRoles", roleType, system, subjects, operator, source)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkCreateSubjectRoles indicates an expected call of BulkCreateSubjectRoles
+func (mr *MockSubjectWriteServiceMockRecorder) BulkCreateSubjectRoles(roleType, system, subjects, operator, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateSubjectRoles", reflect.TypeOf((*MockSubjectWriteService)(nil).BulkCreateSubjectRoles), roleType, system, subjects, operator, source)
+}
+
+// BulkCreateSubjectMultiRoles mocks base method
+func (m *MockSubjectWriteService) BulkCreateSubjectMultiRoles(roles []types.SubjectRole, subjects []types.Subject, operator, source string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkCreateSubjectMultiRoles", roles, subjects, operator, source)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkCreateSubjectMultiRoles indicates an expected call of BulkCreateSubjectMultiRoles
+func (mr *MockSubjectWriteServiceMockRecorder) BulkCreateSubjectMultiRoles(roles, subjects, operator, source interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateSubjectRoles", reflect.TypeOf((*MockSubjectService)(nil).BulkCreateSubjectRoles), roleType, system, subjects)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkCreateSubjectMultiRoles", reflect.TypeOf((*MockSubjectWriteService)(nil).BulkCreateSubjectMultiRoles), roles, subjects, operator, source)
 }
 
 // BulkDeleteSubjectRoles mocks base method
-func (m *MockSubjectService) BulkDeleteSubjectRoles(roleType, system string, subjects []types.Subject) error {
+func (m *MockSubjectWriteService) BulkDeleteSubjectRoles(roleType, system string, subjects []types.Subject, operator, source string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BulkDeleteSubjectRoles", roleType, system, subjects)
+	ret := m.ctrl.Call(m, "BulkDeleteSubjectRoles", roleType, system, subjects, operator, source)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // BulkDeleteSubjectRoles indicates an expected call of BulkDeleteSubjectRoles
-func (mr *MockSubjectServiceMockRecorder) BulkDeleteSubjectRoles(roleType, system, subjects interface{}) *gomock.Call {
+func (mr *MockSubjectWriteServiceMockRecorder) BulkDeleteSubjectRoles(roleType, system, subjects, operator, source interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDeleteSubjectRoles", reflect.TypeOf((*MockSubjectService)(nil).BulkDeleteSubjectRoles), roleType, system, subjects)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDeleteSubjectRoles", reflect.TypeOf((*MockSubjectWriteService)(nil).BulkDeleteSubjectRoles), roleType, system, subjects, operator, source)
 }
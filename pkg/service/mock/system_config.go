@@ -176,3 +176,90 @@ func (mr *MockSystemConfigServiceMockRecorder) CreateOrUpdateFeatureShieldRules(
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateFeatureShieldRules", reflect.TypeOf((*MockSystemConfigService)(nil).CreateOrUpdateFeatureShieldRules), system, featureShieldRules)
 }
+
+// GetResourceAttributeSchemas mocks base method
+func (m *MockSystemConfigService) GetResourceAttributeSchemas(system string) (map[string]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetResourceAttributeSchemas", system)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetResourceAttributeSchemas indicates an expected call of GetResourceAttributeSchemas
+func (mr *MockSystemConfigServiceMockRecorder) GetResourceAttributeSchemas(system interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResourceAttributeSchemas", reflect.TypeOf((*MockSystemConfigService)(nil).GetResourceAttributeSchemas), system)
+}
+
+// CreateOrUpdateResourceAttributeSchemas mocks base method
+func (m *MockSystemConfigService) CreateOrUpdateResourceAttributeSchemas(system string, schemas map[string]interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrUpdateResourceAttributeSchemas", system, schemas)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrUpdateResourceAttributeSchemas indicates an expected call of CreateOrUpdateResourceAttributeSchemas
+func (mr *MockSystemConfigServiceMockRecorder) CreateOrUpdateResourceAttributeSchemas(system, schemas interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateResourceAttributeSchemas", reflect.TypeOf((*MockSystemConfigService)(nil).CreateOrUpdateResourceAttributeSchemas), system, schemas)
+}
+
+// GetPolicyCacheBackend mocks base method
+func (m *MockSystemConfigService) GetPolicyCacheBackend(system string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPolicyCacheBackend", system)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPolicyCacheBackend indicates an expected call of GetPolicyCacheBackend
+func (mr *MockSystemConfigServiceMockRecorder) GetPolicyCacheBackend(system interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolicyCacheBackend", reflect.TypeOf((*MockSystemConfigService)(nil).GetPolicyCacheBackend), system)
+}
+
+// CreateOrUpdatePolicyCacheBackend mocks base method
+func (m *MockSystemConfigService) CreateOrUpdatePolicyCacheBackend(system, backend string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrUpdatePolicyCacheBackend", system, backend)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrUpdatePolicyCacheBackend indicates an expected call of CreateOrUpdatePolicyCacheBackend
+func (mr *MockSystemConfigServiceMockRecorder) CreateOrUpdatePolicyCacheBackend(system, backend interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdatePolicyCacheBackend", reflect.TypeOf((*MockSystemConfigService)(nil).CreateOrUpdatePolicyCacheBackend), system, backend)
+}
+
+// GetModelChangeEventCallback mocks base method
+func (m *MockSystemConfigService) GetModelChangeEventCallback(system string) (map[string]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetModelChangeEventCallback", system)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetModelChangeEventCallback indicates an expected call of GetModelChangeEventCallback
+func (mr *MockSystemConfigServiceMockRecorder) GetModelChangeEventCallback(system interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetModelChangeEventCallback", reflect.TypeOf((*MockSystemConfigService)(nil).GetModelChangeEventCallback), system)
+}
+
+// CreateOrUpdateModelChangeEventCallback mocks base method
+func (m *MockSystemConfigService) CreateOrUpdateModelChangeEventCallback(system string, callback map[string]interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrUpdateModelChangeEventCallback", system, callback)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrUpdateModelChangeEventCallback indicates an expected call of CreateOrUpdateModelChangeEventCallback
+func (mr *MockSystemConfigServiceMockRecorder) CreateOrUpdateModelChangeEventCallback(system, callback interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateModelChangeEventCallback", reflect.TypeOf((*MockSystemConfigService)(nil).CreateOrUpdateModelChangeEventCallback), system, callback)
+}
@@ -20,10 +20,18 @@ import (
 
 const ModelChangeEventSVC = "ModelChangeEventSVC"
 
+// ModelChangeEventCallbackStatusSucceeded 接入系统回调的投递状态, 未注册回调的系统为空
+const (
+	ModelChangeEventCallbackStatusSucceeded = "succeeded"
+	ModelChangeEventCallbackStatusFailed    = "failed"
+)
+
 // ModelChangeEventService define the interface for model change
 type ModelChangeEventService interface {
+	Get(pk int64) (types.ModelChangeEvent, error)
 	ListByStatus(status string) ([]types.ModelChangeEvent, error)
 	UpdateStatusByPK(pk int64, status string) error
+	UpdateCallbackStatusByPK(pk int64, callbackStatus, callbackMessage string) error
 	BulkCreate(modelChangeEvents []types.ModelChangeEvent) error
 	ExistByTypeModel(eventType, status, modelType string, modelPK int64) (bool, error)
 }
@@ -39,6 +47,17 @@ func NewModelChangeService() ModelChangeEventService {
 	}
 }
 
+// Get ...
+func (l *modelChangeEventService) Get(pk int64) (modelChangeEvent types.ModelChangeEvent, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(ModelChangeEventSVC, "Get")
+
+	event, err := l.manager.Get(pk)
+	if err != nil {
+		return modelChangeEvent, errorWrapf(err, "Get(pk=%d) fail", pk)
+	}
+	return convertToSvcModelChangeEvent(event), nil
+}
+
 // ListByStatus ...
 func (l *modelChangeEventService) ListByStatus(status string) (modelChangeEvents []types.ModelChangeEvent, err error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(ModelChangeEventSVC, "ListByStatus")
@@ -50,19 +69,25 @@ func (l *modelChangeEventService) ListByStatus(status string) (modelChangeEvents
 
 	modelChangeEvents = make([]types.ModelChangeEvent, 0, len(dbModelChangeEvents))
 	for _, event := range dbModelChangeEvents {
-		modelChangeEvents = append(modelChangeEvents, types.ModelChangeEvent{
-			PK:        event.PK,
-			Type:      event.Type,
-			Status:    event.Status,
-			SystemID:  event.SystemID,
-			ModelType: event.ModelType,
-			ModelID:   event.ModelID,
-			ModelPK:   event.ModelPK,
-		})
+		modelChangeEvents = append(modelChangeEvents, convertToSvcModelChangeEvent(event))
 	}
 	return
 }
 
+func convertToSvcModelChangeEvent(event dao.ModelChangeEvent) types.ModelChangeEvent {
+	return types.ModelChangeEvent{
+		PK:              event.PK,
+		Type:            event.Type,
+		Status:          event.Status,
+		SystemID:        event.SystemID,
+		ModelType:       event.ModelType,
+		ModelID:         event.ModelID,
+		ModelPK:         event.ModelPK,
+		CallbackStatus:  event.CallbackStatus,
+		CallbackMessage: event.CallbackMessage,
+	}
+}
+
 // UpdateStatusByPK ...
 func (l *modelChangeEventService) UpdateStatusByPK(pk int64, status string) (err error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(ModelChangeEventSVC, "UpdateStatusByPK")
@@ -74,6 +99,17 @@ func (l *modelChangeEventService) UpdateStatusByPK(pk int64, status string) (err
 	return
 }
 
+// UpdateCallbackStatusByPK ...
+func (l *modelChangeEventService) UpdateCallbackStatusByPK(pk int64, callbackStatus, callbackMessage string) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(ModelChangeEventSVC, "UpdateCallbackStatusByPK")
+
+	err := l.manager.UpdateCallbackStatusByPK(pk, callbackStatus, callbackMessage)
+	if err != nil {
+		return errorWrapf(err, "UpdateCallbackStatusByPK(pk=%d, callbackStatus=%s) fail", pk, callbackStatus)
+	}
+	return nil
+}
+
 // BulkCreate ...
 func (l *modelChangeEventService) BulkCreate(modelChangeEvents []types.ModelChangeEvent) (err error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(ModelChangeEventSVC, "BulkCreate")
@@ -11,7 +11,9 @@
 package service
 
 import (
+	"database/sql"
 	"errors"
+	"sort"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -43,6 +45,9 @@ const (
 
 var (
 	errPolicy = errors.New("policy data error")
+
+	errExpressionNotExists = errors.New("expression not exists")
+	errPolicyNotExists     = errors.New("policy not exists")
 )
 
 // PolicyService ...
@@ -70,6 +75,9 @@ type PolicyService interface {
 		actionPKWithResourceTypeSet *util.Int64Set) error
 	UpdateTemplatePolicies(subjectPK int64, policies []types.Policy, actionPKWithResourceTypeSet *util.Int64Set) error
 	DeleteTemplatePolicies(subjectPK int64, templateID int64) error
+	GetTemplatePolicyCount(subjectPK int64, templateID int64) (int64, error)
+	DeleteTemplatePoliciesWithLimit(subjectPK int64, templateID int64, limit int64) (int64, error)
+	UpdateTemplatePoliciesExpiredAt(subjectPK int64, templateID int64, expiredAt int64) (int64, error)
 
 	// for query
 
@@ -77,27 +85,58 @@ type PolicyService interface {
 	ListPagingQueryByActionBeforeExpiredAt(
 		actionPK int64, expiredAt int64, offset int64, limit int64) ([]types.QueryPolicy, error)
 	GetCountByActionBeforeExpiredAt(actionPK int64, expiredAt int64) (int64, error)
+	GetCountByActionPKs(actionPKs []int64) (int64, error)
+	GetSubjectCountByActionPKs(actionPKs []int64, subjectType string) (int64, error)
 
 	ListQueryByPKs(pks []int64) ([]types.QueryPolicy, error)
+	ListPagingQueryBetweenExpiredAtAfterPK(
+		minExpiredAt int64, minPK int64, beginExpiredAt int64, endExpiredAt int64, limit int64,
+	) ([]types.QueryPolicy, error)
 
 	// for model update
 
 	HasAnyByActionPK(actionPK int64) (bool, error)
+
+	// for census
+
+	ListExpressionAfterPK(afterPK, limit int64) ([]types.AuthExpression, error)
 }
 
 type policyService struct {
-	manager          dao.PolicyManager
-	expressionManger dao.ExpressionManager
+	manager                dao.PolicyManager
+	expressionManger       dao.ExpressionManager
+	subjectTemplateManager dao.SubjectTemplateManager
+
+	// batch 是否使用批量任务的DB连接池
+	batch bool
 }
 
 // NewPolicyService ...
 func NewPolicyService() PolicyService {
 	return &policyService{
-		manager:          dao.NewPolicyManager(),
-		expressionManger: dao.NewExpressionManager(),
+		manager:                dao.NewPolicyManager(),
+		expressionManger:       dao.NewExpressionManager(),
+		subjectTemplateManager: dao.NewSubjectTemplateManager(),
+	}
+}
+
+// NewBatchPolicyService 批量任务(清理等)使用的PolicyService, 查询及事务都使用批量任务的DB连接池
+func NewBatchPolicyService() PolicyService {
+	return &policyService{
+		manager:                dao.NewBatchPolicyManager(),
+		expressionManger:       dao.NewBatchExpressionManager(),
+		subjectTemplateManager: dao.NewBatchSubjectTemplateManager(),
+		batch:                  true,
 	}
 }
 
+func (s *policyService) generateDBTx() (*sqlx.Tx, error) {
+	if s.batch {
+		return database.GenerateBatchDBTx()
+	}
+	return database.GenerateDefaultDBTx()
+}
+
 // ListAuthBySubjectAction ...
 func (s *policyService) ListAuthBySubjectAction(subjectPKs []int64, actionPK int64) ([]types.AuthPolicy, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PolicySVC, "ListAuthBySubjectAction")
@@ -117,6 +156,8 @@ func (s *policyService) ListAuthBySubjectAction(subjectPKs []int64, actionPK int
 			SubjectPK:    p.SubjectPK,
 			ExpressionPK: p.ExpressionPK,
 			ExpiredAt:    p.ExpiredAt,
+			Effect:       p.Effect,
+			Priority:     p.Priority,
 		})
 	}
 	return policies, nil
@@ -141,6 +182,25 @@ func (s *policyService) ListExpressionByPKs(pks []int64) ([]types.AuthExpression
 	return expressions, nil
 }
 
+// ListExpressionAfterPK 分批查询所有的expression, 用于离线统计
+func (s *policyService) ListExpressionAfterPK(afterPK, limit int64) ([]types.AuthExpression, error) {
+	daoExpressions, err := s.expressionManger.ListAuthAfterPK(afterPK, limit)
+	if err != nil {
+		return nil, errorx.Wrapf(err, PolicySVC, "ListExpressionAfterPK",
+			"expressionManger.ListAuthAfterPK afterPK=`%d`, limit=`%d` fail", afterPK, limit)
+	}
+
+	expressions := make([]types.AuthExpression, 0, len(daoExpressions))
+	for _, e := range daoExpressions {
+		expressions = append(expressions, types.AuthExpression{
+			PK:         e.PK,
+			Expression: e.Expression,
+			Signature:  e.Signature,
+		})
+	}
+	return expressions, nil
+}
+
 func (s *policyService) convertToThinPolicies(daoPolicies []dao.Policy) []types.ThinPolicy {
 	thinPolicies := make([]types.ThinPolicy, 0, len(daoPolicies))
 	for _, p := range daoPolicies {
@@ -148,6 +208,8 @@ func (s *policyService) convertToThinPolicies(daoPolicies []dao.Policy) []types.
 			Version:   PolicyVersion,
 			ID:        p.PK,
 			ActionPK:  p.ActionPK,
+			Effect:    p.Effect,
+			Priority:  p.Priority,
 			ExpiredAt: p.ExpiredAt,
 		})
 	}
@@ -204,6 +266,8 @@ func (s *policyService) GetByActionTemplate(subjectPK, actionPK, templateID int6
 			ID:        daoPolicy.PK,
 			SubjectPK: daoPolicy.SubjectPK,
 			ActionPK:  daoPolicy.ActionPK,
+			Effect:    daoPolicy.Effect,
+			Priority:  daoPolicy.Priority,
 			ExpiredAt: daoPolicy.ExpiredAt,
 		}
 		return
@@ -218,6 +282,8 @@ func (s *policyService) GetByActionTemplate(subjectPK, actionPK, templateID int6
 		ID:         daoPolicy.PK,
 		SubjectPK:  daoPolicy.SubjectPK,
 		ActionPK:   daoPolicy.ActionPK,
+		Effect:     daoPolicy.Effect,
+		Priority:   daoPolicy.Priority,
 		ExpiredAt:  daoPolicy.ExpiredAt,
 		Expression: expression.Expression,
 		Signature:  expression.Signature,
@@ -246,11 +312,14 @@ func (s *policyService) AlterCustomPolicies(
 				Type:       expressionTypeCustom,
 				Expression: p.Expression,
 				Signature:  util.GetMD5Hash(p.Expression), // 计算Hash
+				RefCount:   1,                             // 自定义权限的expression只被一个policy引用
 			})
 
 			daoCreatePolicies = append(daoCreatePolicies, dao.Policy{
 				SubjectPK: p.SubjectPK,
 				ActionPK:  p.ActionPK,
+				Effect:    p.GetEffect(),
+				Priority:  p.Priority,
 				ExpiredAt: p.ExpiredAt,
 			})
 		} else {
@@ -259,6 +328,8 @@ func (s *policyService) AlterCustomPolicies(
 				SubjectPK:    p.SubjectPK,
 				ActionPK:     p.ActionPK,
 				ExpressionPK: expressionPKActionWithoutResource,
+				Effect:       p.GetEffect(),
+				Priority:     p.Priority,
 				ExpiredAt:    p.ExpiredAt,
 			})
 		}
@@ -516,6 +587,31 @@ func (s *policyService) ListQueryByPKs(pks []int64) (queryPolicies []types.Query
 	return
 }
 
+// ListPagingQueryBetweenExpiredAtAfterPK 游标分页, 查询过期时间在(beginExpiredAt, endExpiredAt]之间的策略
+// 按(expired_at, pk)升序, 返回(minExpiredAt, minPK)之后的策略
+func (s *policyService) ListPagingQueryBetweenExpiredAtAfterPK(
+	minExpiredAt int64,
+	minPK int64,
+	beginExpiredAt int64,
+	endExpiredAt int64,
+	limit int64,
+) (queryPolicies []types.QueryPolicy, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PolicySVC, "ListPagingQueryBetweenExpiredAtAfterPK")
+
+	policies, err := s.manager.ListPagingBetweenExpiredAtAfterPK(
+		minExpiredAt, minPK, beginExpiredAt, endExpiredAt, limit)
+	if err != nil {
+		err = errorWrapf(err,
+			"manager.ListPagingBetweenExpiredAtAfterPK minExpiredAt=`%d`, minPK=`%d`, "+
+				"beginExpiredAt=`%d`, endExpiredAt=`%d`, limit=`%d` fail",
+			minExpiredAt, minPK, beginExpiredAt, endExpiredAt, limit)
+		return nil, err
+	}
+
+	queryPolicies = convertPoliciesToQueryPolicies(policies)
+	return
+}
+
 func convertPoliciesToQueryPolicies(policies []dao.Policy) []types.QueryPolicy {
 	queryPolicies := make([]types.QueryPolicy, 0, len(policies))
 	for _, p := range policies {
@@ -614,6 +710,20 @@ func (s *policyService) CreateAndDeleteTemplatePolicies(
 ) (err error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PolicySVC, "CreateAndDeleteTemplatePolicies")
 
+	// 查询要删除的policies, 用于减去对expression的引用
+	deletePolicies, err := s.manager.ListBySubjectPKAndPKs(subjectPK, deletePolicyIDs)
+	if err != nil {
+		err = errorWrapf(err, "manager.ListBySubjectPKAndPKs subjectPK=`%d`, pks=`%+v`", subjectPK, deletePolicyIDs)
+		return
+	}
+
+	// 模板授权设置了过期时间时, 模板新增的策略同样使用模板的过期时间
+	templateExpiredAt, err := s.getTemplateExpiredAt(subjectPK, templateID)
+	if err != nil {
+		err = errorWrapf(err, "getTemplateExpiredAt subjectPK=`%d`, templateID=`%d`", subjectPK, templateID)
+		return
+	}
+
 	// 使用事务
 	tx, err := database.GenerateDefaultDBTx()
 	defer database.RollBackWithLog(tx)
@@ -628,6 +738,10 @@ func (s *policyService) CreateAndDeleteTemplatePolicies(
 
 	daoCreatePolicies := make([]dao.Policy, 0, len(createPolicies))
 	for _, p := range createPolicies {
+		if templateExpiredAt != 0 {
+			p.ExpiredAt = templateExpiredAt
+		}
+
 		signature := util.GetMD5Hash(p.Expression)
 		// 操作有关联资源类型
 		if actionPKWithResourceTypeSet.Has(p.ActionPK) {
@@ -642,6 +756,8 @@ func (s *policyService) CreateAndDeleteTemplatePolicies(
 				ActionPK:     p.ActionPK,
 				ExpiredAt:    p.ExpiredAt,
 				ExpressionPK: expressionPK,
+				Effect:       p.GetEffect(),
+				Priority:     p.Priority,
 				TemplateID:   p.TemplateID,
 			})
 		} else {
@@ -650,6 +766,8 @@ func (s *policyService) CreateAndDeleteTemplatePolicies(
 				SubjectPK:    p.SubjectPK,
 				ActionPK:     p.ActionPK,
 				ExpressionPK: expressionPKActionWithoutResource,
+				Effect:       p.GetEffect(),
+				Priority:     p.Priority,
 				ExpiredAt:    p.ExpiredAt,
 				TemplateID:   p.TemplateID,
 			})
@@ -668,6 +786,21 @@ func (s *policyService) CreateAndDeleteTemplatePolicies(
 		return
 	}
 
+	expressionPKDeltas := make(map[int64]int64, len(daoCreatePolicies)+len(deletePolicies))
+	for _, p := range daoCreatePolicies {
+		expressionPKDeltas[p.ExpressionPK]++
+	}
+	for _, p := range deletePolicies {
+		if p.TemplateID == templateID {
+			expressionPKDeltas[p.ExpressionPK]--
+		}
+	}
+	err = s.updateExpressionRefCountWithTx(tx, expressionPKDeltas)
+	if err != nil {
+		err = errorWrapf(err, "updateExpressionRefCountWithTx expressionPKDeltas=`%+v`", expressionPKDeltas)
+		return
+	}
+
 	err = tx.Commit()
 	return err
 }
@@ -695,6 +828,20 @@ func (s *policyService) UpdateTemplatePolicies(
 		daoPolicyMap[p.PK] = p
 	}
 
+	// 模板授权设置了过期时间时, 同步策略的过期时间
+	templateExpiredAts := make(map[int64]int64, 1)
+	for _, p := range daoPolicies {
+		if _, ok := templateExpiredAts[p.TemplateID]; ok {
+			continue
+		}
+
+		templateExpiredAts[p.TemplateID], err = s.getTemplateExpiredAt(subjectPK, p.TemplateID)
+		if err != nil {
+			err = errorWrapf(err, "getTemplateExpiredAt subjectPK=`%d`, templateID=`%d`", subjectPK, p.TemplateID)
+			return
+		}
+	}
+
 	// 使用事务
 	tx, err := database.GenerateDefaultDBTx()
 	defer database.RollBackWithLog(tx)
@@ -707,8 +854,10 @@ func (s *policyService) UpdateTemplatePolicies(
 	signatureExpressionPKMap, err := s.generateSignatureExpressionPKMap(
 		tx, policies, actionPKWithResourceTypeSet)
 
-	// 3. 生成需要更新的policies
+	// 3. 生成需要更新的policies, 同时记录expression引用的变化
 	daoUpdatePolicies := make([]dao.Policy, 0, len(policies))
+	daoExpiredAtPolicies := make([]dao.Policy, 0, len(policies))
+	expressionPKDeltas := make(map[int64]int64, 2*len(policies))
 	for _, p := range policies {
 		daoPolicy, ok := daoPolicyMap[p.ID]
 		// policy不存在
@@ -724,11 +873,18 @@ func (s *policyService) UpdateTemplatePolicies(
 			return
 		}
 
+		expiredAt := templateExpiredAts[daoPolicy.TemplateID]
+		if expiredAt != 0 && daoPolicy.ExpiredAt != expiredAt {
+			daoPolicy.ExpiredAt = expiredAt
+			daoExpiredAtPolicies = append(daoExpiredAtPolicies, daoPolicy)
+		}
+
 		// 操作未关联资源类型, 不更新
 		if daoPolicy.ExpressionPK == expressionPKActionWithoutResource {
 			continue
 		}
 
+		oldExpressionPK := daoPolicy.ExpressionPK
 		signature := util.GetMD5Hash(p.Expression)
 		daoPolicy.ExpressionPK, ok = signatureExpressionPKMap[signature]
 		if !ok {
@@ -736,6 +892,9 @@ func (s *policyService) UpdateTemplatePolicies(
 			return
 		}
 
+		expressionPKDeltas[oldExpressionPK]--
+		expressionPKDeltas[daoPolicy.ExpressionPK]++
+
 		daoUpdatePolicies = append(daoUpdatePolicies, daoPolicy)
 	}
 
@@ -746,48 +905,353 @@ func (s *policyService) UpdateTemplatePolicies(
 		return
 	}
 
+	// 5. 更新policy的过期时间
+	if len(daoExpiredAtPolicies) > 0 {
+		err = s.manager.BulkUpdateExpiredAtWithTx(tx, daoExpiredAtPolicies)
+		if err != nil {
+			err = errorWrapf(err, "manager.BulkUpdateExpiredAtWithTx policies=`%+v`", daoExpiredAtPolicies)
+			return
+		}
+	}
+
+	// 6. 更新expression的引用计数
+	err = s.updateExpressionRefCountWithTx(tx, expressionPKDeltas)
+	if err != nil {
+		err = errorWrapf(err, "updateExpressionRefCountWithTx expressionPKDeltas=`%+v`", expressionPKDeltas)
+		return
+	}
+
 	err = tx.Commit()
 	return err
 }
 
 // DeleteTemplatePolicies delete subject template policies
 func (s *policyService) DeleteTemplatePolicies(subjectPK int64, templateID int64) error {
-	err := s.manager.BulkDeleteBySubjectTemplate(subjectPK, templateID)
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PolicySVC, "DeleteTemplatePolicies")
+
+	policies, err := s.manager.ListBySubjectTemplate(subjectPK, templateID)
 	if err != nil {
-		return errorx.Wrapf(err, PolicySVC, "DeleteTemplatePolicies",
-			"manager.BulkDeleteBySubjectTemplate subjectPK=`%d`, templateID=`%d` fail",
+		return errorWrapf(err, "manager.ListBySubjectTemplate subjectPK=`%d`, templateID=`%d` fail",
+			subjectPK, templateID)
+	}
+
+	_, err = s.deleteTemplatePolicies(subjectPK, templateID, policies)
+	if err != nil {
+		return errorWrapf(err, "deleteTemplatePolicies subjectPK=`%d`, templateID=`%d` fail", subjectPK, templateID)
+	}
+
+	_, err = s.subjectTemplateManager.Delete(subjectPK, templateID)
+	if err != nil {
+		return errorWrapf(err, "subjectTemplateManager.Delete subjectPK=`%d`, templateID=`%d` fail",
 			subjectPK, templateID)
 	}
 	return nil
 }
 
-// DeleteByActionPK ...
-func (s *policyService) DeleteByActionPK(actionPK int64) error {
-	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PolicySVC, "DeleteByActionPK")
+// GetTemplatePolicyCount ...
+func (s *policyService) GetTemplatePolicyCount(subjectPK int64, templateID int64) (int64, error) {
+	count, err := s.manager.GetCountBySubjectTemplate(subjectPK, templateID)
+	if err != nil {
+		return 0, errorx.Wrapf(err, PolicySVC, "GetTemplatePolicyCount",
+			"manager.GetCountBySubjectTemplate subjectPK=`%d`, templateID=`%d` fail",
+			subjectPK, templateID)
+	}
+	return count, nil
+}
+
+// GetCountByActionPKs ...
+func (s *policyService) GetCountByActionPKs(actionPKs []int64) (int64, error) {
+	count, err := s.manager.GetCountByActionPKs(actionPKs)
+	if err != nil {
+		return 0, errorx.Wrapf(err, PolicySVC, "GetCountByActionPKs",
+			"manager.GetCountByActionPKs actionPKs=`%+v` fail", actionPKs)
+	}
+	return count, nil
+}
+
+// GetSubjectCountByActionPKs ...
+func (s *policyService) GetSubjectCountByActionPKs(actionPKs []int64, subjectType string) (int64, error) {
+	count, err := s.manager.GetSubjectCountByActionPKs(actionPKs, subjectType)
+	if err != nil {
+		return 0, errorx.Wrapf(err, PolicySVC, "GetSubjectCountByActionPKs",
+			"manager.GetSubjectCountByActionPKs actionPKs=`%+v`, subjectType=`%s` fail", actionPKs, subjectType)
+	}
+	return count, nil
+}
+
+// templatePolicyExpiredAtChunkSize 分批更新模板策略的过期时间, 每批一个事务, 避免大事务长时间锁表
+var templatePolicyExpiredAtChunkSize = 1000
+
+// UpdateTemplatePoliciesExpiredAt 记录模板授权的过期时间, 并将模板生成的所有策略的过期时间更新为模板的过期时间,
+// 之后模板新增或同步的策略也使用该过期时间, return the updated count
+func (s *policyService) UpdateTemplatePoliciesExpiredAt(
+	subjectPK int64, templateID int64, expiredAt int64,
+) (int64, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PolicySVC, "UpdateTemplatePoliciesExpiredAt")
+
+	err := s.saveTemplateExpiredAt(subjectPK, templateID, expiredAt)
+	if err != nil {
+		return 0, errorWrapf(err, "saveTemplateExpiredAt subjectPK=`%d`, templateID=`%d`, expiredAt=`%d` fail",
+			subjectPK, templateID, expiredAt)
+	}
+
+	policies, err := s.manager.ListBySubjectTemplate(subjectPK, templateID)
+	if err != nil {
+		return 0, errorWrapf(err, "manager.ListBySubjectTemplate subjectPK=`%d`, templateID=`%d` fail",
+			subjectPK, templateID)
+	}
+
+	updatePolicies := make([]dao.Policy, 0, len(policies))
+	for _, p := range policies {
+		if p.ExpiredAt != expiredAt {
+			p.ExpiredAt = expiredAt
+			updatePolicies = append(updatePolicies, p)
+		}
+	}
+
+	var updated int64
+	for start := 0; start < len(updatePolicies); start += templatePolicyExpiredAtChunkSize {
+		end := start + templatePolicyExpiredAtChunkSize
+		if end > len(updatePolicies) {
+			end = len(updatePolicies)
+		}
+
+		err = s.updateExpiredAtWithTx(updatePolicies[start:end])
+		if err != nil {
+			return updated, errorWrapf(err, "updateExpiredAtWithTx subjectPK=`%d`, templateID=`%d` fail",
+				subjectPK, templateID)
+		}
+		updated += int64(end - start)
+	}
+	return updated, nil
+}
+
+// getTemplateExpiredAt 查询模板授权的过期时间, 未设置时返回0
+func (s *policyService) getTemplateExpiredAt(subjectPK, templateID int64) (int64, error) {
+	subjectTemplate, err := s.subjectTemplateManager.Get(subjectPK, templateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return subjectTemplate.ExpiredAt, nil
+}
+
+// saveTemplateExpiredAt 保存模板授权的过期时间, 不存在时创建
+func (s *policyService) saveTemplateExpiredAt(subjectPK, templateID, expiredAt int64) error {
+	subjectTemplate := dao.SubjectTemplate{
+		SubjectPK:  subjectPK,
+		TemplateID: templateID,
+		ExpiredAt:  expiredAt,
+	}
+
+	_, err := s.subjectTemplateManager.Get(subjectPK, templateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.subjectTemplateManager.Create(subjectTemplate)
+	}
+	if err != nil {
+		return err
+	}
+	return s.subjectTemplateManager.UpdateExpiredAt(subjectTemplate)
+}
+
+func (s *policyService) updateExpiredAtWithTx(policies []dao.Policy) error {
 	tx, err := database.GenerateDefaultDBTx()
 	if err != nil {
-		return errorWrapf(err, "define tx fail")
+		return err
+	}
+	defer database.RollBackWithLog(tx)
+
+	err = s.manager.BulkUpdateExpiredAtWithTx(tx, policies)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteTemplatePoliciesWithLimit delete at most `limit` subject template policies, return the deleted count
+// NOTE: 不足limit时模板的策略已经全部删除, 同时删除模板授权的过期时间
+func (s *policyService) DeleteTemplatePoliciesWithLimit(subjectPK int64, templateID int64, limit int64) (int64, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PolicySVC, "DeleteTemplatePoliciesWithLimit")
+
+	policies, err := s.manager.ListBySubjectTemplateWithLimit(subjectPK, templateID, limit)
+	if err != nil {
+		return 0, errorWrapf(err, "manager.ListBySubjectTemplateWithLimit subjectPK=`%d`, templateID=`%d`, limit=`%d` fail",
+			subjectPK, templateID, limit)
+	}
+
+	count, err := s.deleteTemplatePolicies(subjectPK, templateID, policies)
+	if err != nil {
+		return 0, errorWrapf(err, "deleteTemplatePolicies subjectPK=`%d`, templateID=`%d` fail", subjectPK, templateID)
+	}
+
+	if int64(len(policies)) < limit {
+		_, err = s.subjectTemplateManager.Delete(subjectPK, templateID)
+		if err != nil {
+			return count, errorWrapf(err, "subjectTemplateManager.Delete subjectPK=`%d`, templateID=`%d` fail",
+				subjectPK, templateID)
+		}
+	}
+	return count, nil
+}
+
+func (s *policyService) deleteTemplatePolicies(subjectPK int64, templateID int64, policies []dao.Policy) (int64, error) {
+	if len(policies) == 0 {
+		return 0, nil
+	}
+
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PolicySVC, "deleteTemplatePolicies")
+
+	pks := make([]int64, 0, len(policies))
+	expressionPKDeltas := make(map[int64]int64, len(policies))
+	for _, p := range policies {
+		pks = append(pks, p.PK)
+		expressionPKDeltas[p.ExpressionPK]--
+	}
+
+	tx, err := database.GenerateDefaultDBTx()
+	if err != nil {
+		return 0, errorWrapf(err, "define tx fail")
 	}
 	defer database.RollBackWithLog(tx)
-	// TODO: 需要改造，先查询数据量，然后再按照数量进行删除，同时需要考虑
-	// 由于删除时可能数量较大，耗时长，锁行数据较多，影响鉴权，所以需要循环删除，限制每次删除的记录数，以及最多执行删除多少次
+
+	count, err := s.manager.BulkDeleteByTemplatePKsWithTx(tx, subjectPK, templateID, pks)
+	if err != nil {
+		return 0, errorWrapf(err, "manager.BulkDeleteByTemplatePKsWithTx subjectPK=`%d`, templateID=`%d`, pks=`%+v` fail",
+			subjectPK, templateID, pks)
+	}
+
+	err = s.updateExpressionRefCountWithTx(tx, expressionPKDeltas)
+	if err != nil {
+		return 0, errorWrapf(err, "updateExpressionRefCountWithTx expressionPKDeltas=`%+v`", expressionPKDeltas)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, errorWrapf(err, "tx.Commit fail")
+	}
+	return count, nil
+}
+
+// updateExpressionRefCountWithTx 在policy写操作的事务中更新expression的引用计数, 并立即删除不再被引用的expression
+func (s *policyService) updateExpressionRefCountWithTx(tx *sqlx.Tx, expressionPKDeltas map[int64]int64) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PolicySVC, "updateExpressionRefCountWithTx")
+
+	deltaPKs := make(map[int64][]int64)
+	decrPKs := make([]int64, 0, len(expressionPKDeltas))
+	for pk, delta := range expressionPKDeltas {
+		// 操作未关联资源类型的policy不引用expression
+		if pk == expressionPKActionWithoutResource || delta == 0 {
+			continue
+		}
+
+		deltaPKs[delta] = append(deltaPKs[delta], pk)
+		if delta < 0 {
+			decrPKs = append(decrPKs, pk)
+		}
+	}
+
+	deltas := make([]int64, 0, len(deltaPKs))
+	for delta := range deltaPKs {
+		deltas = append(deltas, delta)
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i] > deltas[j] })
+
+	for _, delta := range deltas {
+		pks := deltaPKs[delta]
+		sort.Slice(pks, func(i, j int) bool { return pks[i] < pks[j] })
+
+		rowsAffected, err := s.expressionManger.UpdateRefCountByPKsWithTx(tx, pks, delta)
+		if err != nil {
+			return errorWrapf(err, "expressionManger.UpdateRefCountByPKsWithTx pks=`%+v`, delta=`%d`", pks, delta)
+		}
+		// expression已被其他事务当作无引用删除, 需要回滚, 避免policy引用不存在的expression
+		if rowsAffected != int64(len(pks)) {
+			return errorWrapf(errExpressionNotExists, "update ref_count of pks=`%+v`, rowsAffected=`%d`",
+				pks, rowsAffected)
+		}
+	}
+
+	if len(decrPKs) == 0 {
+		return nil
+	}
+
+	sort.Slice(decrPKs, func(i, j int) bool { return decrPKs[i] < decrPKs[j] })
+	_, err := s.expressionManger.BulkDeleteUnreferencedByPKsWithTx(tx, decrPKs)
+	if err != nil {
+		return errorWrapf(err, "expressionManger.BulkDeleteUnreferencedByPKsWithTx pks=`%+v`", decrPKs)
+	}
+	return nil
+}
+
+// DeleteByActionPK ...
+func (s *policyService) DeleteByActionPK(actionPK int64) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PolicySVC, "DeleteByActionPK")
+
+	// 由于删除时可能数量较大，耗时长，锁行数据较多，影响鉴权，所以需要分批删除，每批一个事务，
+	// 限制每次删除的记录数，以及最多执行删除多少次
 	rowLimit := int64(10000)
 	maxAttempts := 100 // 相当于最多删除100万数据
 
 	for i := 0; i < maxAttempts; i++ {
-		rowsAffected, err1 := s.manager.DeleteByActionPKWithTx(tx, actionPK, rowLimit)
-		if err1 != nil {
-			return errorWrapf(err1, "manager.DeleteByActionPKWithTx actionPK=`%d`", actionPK)
+		policies, err := s.manager.ListByActionPKWithLimit(actionPK, rowLimit)
+		if err != nil {
+			return errorWrapf(err, "manager.ListByActionPKWithLimit actionPK=`%d`, limit=`%d` fail", actionPK, rowLimit)
+		}
+
+		err = s.deleteActionPolicies(actionPK, policies)
+		if err != nil {
+			return errorWrapf(err, "deleteActionPolicies actionPK=`%d` fail", actionPK)
 		}
+
 		// 如果已经没有需要删除的了，就停止
-		if rowsAffected == 0 {
+		if int64(len(policies)) < rowLimit {
 			break
 		}
 	}
+	return nil
+}
+
+// deleteActionPolicies 在同一个事务中删除一批action的策略, 并减去这批策略对expression的引用
+func (s *policyService) deleteActionPolicies(actionPK int64, policies []dao.Policy) error {
+	if len(policies) == 0 {
+		return nil
+	}
+
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(PolicySVC, "deleteActionPolicies")
+
+	pks := make([]int64, 0, len(policies))
+	expressionPKDeltas := make(map[int64]int64, len(policies))
+	for _, p := range policies {
+		pks = append(pks, p.PK)
+		expressionPKDeltas[p.ExpressionPK]--
+	}
+
+	tx, err := s.generateDBTx()
+	if err != nil {
+		return errorWrapf(err, "define tx fail")
+	}
+	defer database.RollBackWithLog(tx)
+
+	count, err := s.manager.BulkDeleteByActionPKsWithTx(tx, actionPK, pks)
+	if err != nil {
+		return errorWrapf(err, "manager.BulkDeleteByActionPKsWithTx actionPK=`%d`, pks=`%+v` fail", actionPK, pks)
+	}
+	// 部分策略已被其他事务删除, 这批策略的引用计数已不可信, 需要回滚
+	if count != int64(len(pks)) {
+		return errorWrapf(errPolicyNotExists, "delete pks=`%+v`, rowsAffected=`%d`", pks, count)
+	}
+
+	err = s.updateExpressionRefCountWithTx(tx, expressionPKDeltas)
+	if err != nil {
+		return errorWrapf(err, "updateExpressionRefCountWithTx expressionPKDeltas=`%+v`", expressionPKDeltas)
+	}
 
 	err = tx.Commit()
 	if err != nil {
 		return errorWrapf(err, "tx.Commit fail")
 	}
-	return err
+	return nil
 }
@@ -11,6 +11,7 @@
 package service
 
 import (
+	"database/sql"
 	"errors"
 
 	"github.com/agiledragon/gomonkey"
@@ -135,6 +136,41 @@ var _ = Describe("PolicyService", func() {
 		})
 	})
 
+	Describe("ListExpressionAfterPK", func() {
+		var ctl *gomock.Controller
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("ok", func() {
+			mockExpressionManager := mock.NewMockExpressionManager(ctl)
+			mockExpressionManager.EXPECT().ListAuthAfterPK(int64(10), int64(2)).Return([]dao.AuthExpression{
+				{PK: 11, Expression: "[]", Signature: "s"},
+			}, nil)
+
+			svc := policyService{
+				expressionManger: mockExpressionManager,
+			}
+			expressions, err := svc.ListExpressionAfterPK(10, 2)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.AuthExpression{{PK: 11, Expression: "[]", Signature: "s"}}, expressions)
+		})
+
+		It("error", func() {
+			mockExpressionManager := mock.NewMockExpressionManager(ctl)
+			mockExpressionManager.EXPECT().ListAuthAfterPK(int64(10), int64(2)).Return(nil, errors.New("error"))
+
+			svc := policyService{
+				expressionManger: mockExpressionManager,
+			}
+			_, err := svc.ListExpressionAfterPK(10, 2)
+			assert.Error(GinkgoT(), err)
+		})
+	})
+
 	Describe("ListThinBySubjectSystemTemplate cases", func() {
 		var ctl *gomock.Controller
 
@@ -345,11 +381,13 @@ var _ = Describe("PolicyService", func() {
 					Type:       0,
 					Expression: "test",
 					Signature:  "098f6bcd4621d373cade4e832627b4f6",
+					RefCount:   1,
 				},
 				{
 					Type:       0,
 					Expression: "test",
 					Signature:  "098f6bcd4621d373cade4e832627b4f6",
+					RefCount:   1,
 				},
 			}).Return(int64(1), nil)
 
@@ -358,12 +396,14 @@ var _ = Describe("PolicyService", func() {
 					SubjectPK:    1,
 					ActionPK:     1,
 					ExpressionPK: 1,
+					Effect:       "allow",
 					ExpiredAt:    1,
 				},
 				{
 					SubjectPK:    1,
 					ActionPK:     2,
 					ExpressionPK: 2,
+					Effect:       "allow",
 					ExpiredAt:    1,
 				},
 			}).Return(nil)
@@ -640,6 +680,7 @@ var _ = Describe("PolicyService", func() {
 					SubjectPK:    1,
 					ActionPK:     1,
 					ExpressionPK: 1,
+					Effect:       "allow",
 					ExpiredAt:    1,
 					TemplateID:   1,
 				},
@@ -647,16 +688,24 @@ var _ = Describe("PolicyService", func() {
 					SubjectPK:    1,
 					ActionPK:     2,
 					ExpressionPK: 2,
+					Effect:       "allow",
 					ExpiredAt:    1,
 					TemplateID:   1,
 				},
 			}).Return(nil)
+			mockPolicyManager.EXPECT().ListBySubjectPKAndPKs(int64(1), []int64{}).Return([]dao.Policy{}, nil)
 			mockPolicyManager.EXPECT().BulkDeleteByTemplatePKsWithTx(
 				gomock.Any(), int64(1), int64(1), []int64{}).Return(int64(0), nil)
+			mockExpressionManager.EXPECT().UpdateRefCountByPKsWithTx(
+				gomock.Any(), []int64{1, 2}, int64(1)).Return(int64(2), nil)
+
+			mockSubjectTemplateManager := mock.NewMockSubjectTemplateManager(ctl)
+			mockSubjectTemplateManager.EXPECT().Get(int64(1), int64(1)).Return(dao.SubjectTemplate{}, sql.ErrNoRows)
 
 			svc := policyService{
-				manager:          mockPolicyManager,
-				expressionManger: mockExpressionManager,
+				manager:                mockPolicyManager,
+				expressionManger:       mockExpressionManager,
+				subjectTemplateManager: mockSubjectTemplateManager,
 			}
 
 			db, dbMock := database.NewMockSqlxDB()
@@ -698,6 +747,100 @@ var _ = Describe("PolicyService", func() {
 			err = dbMock.ExpectationsWereMet()
 			assert.NoError(GinkgoT(), err)
 		})
+
+		It("ok, with the expired_at of template", func() {
+			mockExpressionManager := mock.NewMockExpressionManager(ctl)
+			mockExpressionManager.EXPECT().ListDistinctBySignaturesType(gomock.Any(), int64(1)).Return([]dao.Expression{
+				{
+					PK:         1,
+					Expression: "test",
+					Signature:  "098f6bcd4621d373cade4e832627b4f6",
+				},
+			}, nil)
+			mockExpressionManager.EXPECT().BulkCreateWithTx(gomock.Any(), []dao.Expression{
+				{
+					Type:       1,
+					Expression: "expression",
+					Signature:  "63973cd3ad7ccf2c8d5dce94b215f683",
+				},
+			}).Return(int64(2), nil)
+
+			mockPolicyManager := mock.NewMockPolicyManager(ctl)
+			mockPolicyManager.EXPECT().BulkCreateWithTx(gomock.Any(), []dao.Policy{
+				{
+					SubjectPK:    1,
+					ActionPK:     1,
+					ExpressionPK: 1,
+					Effect:       "allow",
+					ExpiredAt:    100,
+					TemplateID:   1,
+				},
+				{
+					SubjectPK:    1,
+					ActionPK:     2,
+					ExpressionPK: 2,
+					Effect:       "allow",
+					ExpiredAt:    100,
+					TemplateID:   1,
+				},
+			}).Return(nil)
+			mockPolicyManager.EXPECT().ListBySubjectPKAndPKs(int64(1), []int64{}).Return([]dao.Policy{}, nil)
+			mockPolicyManager.EXPECT().BulkDeleteByTemplatePKsWithTx(
+				gomock.Any(), int64(1), int64(1), []int64{}).Return(int64(0), nil)
+			mockExpressionManager.EXPECT().UpdateRefCountByPKsWithTx(
+				gomock.Any(), []int64{1, 2}, int64(1)).Return(int64(2), nil)
+
+			mockSubjectTemplateManager := mock.NewMockSubjectTemplateManager(ctl)
+			mockSubjectTemplateManager.EXPECT().Get(int64(1), int64(1)).Return(dao.SubjectTemplate{
+				SubjectPK:  1,
+				TemplateID: 1,
+				ExpiredAt:  100,
+			}, nil)
+
+			svc := policyService{
+				manager:                mockPolicyManager,
+				expressionManger:       mockExpressionManager,
+				subjectTemplateManager: mockSubjectTemplateManager,
+			}
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+
+			patches := gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+			defer patches.Reset()
+
+			createPolicies := []types.Policy{
+				{
+					Version:    "1",
+					SubjectPK:  1,
+					ActionPK:   1,
+					Expression: "test",
+					Signature:  "",
+					ExpiredAt:  1,
+					TemplateID: 1,
+				},
+				{
+					Version:    "1",
+					SubjectPK:  1,
+					ActionPK:   2,
+					Expression: "expression",
+					Signature:  "",
+					ExpiredAt:  1,
+					TemplateID: 1,
+				},
+			}
+
+			set := util.NewInt64Set()
+			set.Add(1)
+			set.Add(2)
+
+			err := svc.CreateAndDeleteTemplatePolicies(1, 1, createPolicies, []int64{}, set)
+			assert.NoError(GinkgoT(), err)
+
+			err = dbMock.ExpectationsWereMet()
+			assert.NoError(GinkgoT(), err)
+		})
 	})
 
 	Describe("UpdateTemplatePolicies cases", func() {
@@ -767,10 +910,20 @@ var _ = Describe("PolicyService", func() {
 					TemplateID:   1,
 				},
 			}).Return(nil)
+			mockExpressionManager.EXPECT().UpdateRefCountByPKsWithTx(
+				gomock.Any(), []int64{1, 2}, int64(1)).Return(int64(2), nil)
+			mockExpressionManager.EXPECT().UpdateRefCountByPKsWithTx(
+				gomock.Any(), []int64{3, 4}, int64(-1)).Return(int64(2), nil)
+			mockExpressionManager.EXPECT().BulkDeleteUnreferencedByPKsWithTx(
+				gomock.Any(), []int64{3, 4}).Return(int64(1), nil)
+
+			mockSubjectTemplateManager := mock.NewMockSubjectTemplateManager(ctl)
+			mockSubjectTemplateManager.EXPECT().Get(int64(1), int64(1)).Return(dao.SubjectTemplate{}, sql.ErrNoRows)
 
 			svc := policyService{
-				manager:          mockPolicyManager,
-				expressionManger: mockExpressionManager,
+				manager:                mockPolicyManager,
+				expressionManger:       mockExpressionManager,
+				subjectTemplateManager: mockSubjectTemplateManager,
 			}
 
 			db, dbMock := database.NewMockSqlxDB()
@@ -814,6 +967,141 @@ var _ = Describe("PolicyService", func() {
 			err = dbMock.ExpectationsWereMet()
 			assert.NoError(GinkgoT(), err)
 		})
+
+		It("ok, sync the expired_at of template", func() {
+			mockExpressionManager := mock.NewMockExpressionManager(ctl)
+			mockExpressionManager.EXPECT().ListDistinctBySignaturesType(gomock.Any(), int64(1)).Return([]dao.Expression{
+				{
+					PK:         1,
+					Expression: "test",
+					Signature:  "098f6bcd4621d373cade4e832627b4f6",
+				},
+			}, nil)
+			mockExpressionManager.EXPECT().BulkCreateWithTx(gomock.Any(), []dao.Expression{
+				{
+					Type:       1,
+					Expression: "expression",
+					Signature:  "63973cd3ad7ccf2c8d5dce94b215f683",
+				},
+			}).Return(int64(2), nil)
+
+			mockPolicyManager := mock.NewMockPolicyManager(ctl)
+			mockPolicyManager.EXPECT().ListBySubjectPKAndPKs(int64(1), []int64{1, 2}).Return(
+				[]dao.Policy{
+					{
+						PK:           1,
+						SubjectPK:    1,
+						ActionPK:     1,
+						ExpressionPK: 3,
+						ExpiredAt:    1,
+						TemplateID:   1,
+					},
+					{
+						PK:           2,
+						SubjectPK:    1,
+						ActionPK:     2,
+						ExpressionPK: 4,
+						ExpiredAt:    1,
+						TemplateID:   1,
+					},
+				}, nil,
+			)
+			mockPolicyManager.EXPECT().BulkUpdateExpressionPKWithTx(gomock.Any(), []dao.Policy{
+				{
+					PK:           1,
+					SubjectPK:    1,
+					ActionPK:     1,
+					ExpressionPK: 1,
+					ExpiredAt:    100,
+					TemplateID:   1,
+				},
+				{
+					PK:           2,
+					SubjectPK:    1,
+					ActionPK:     2,
+					ExpressionPK: 2,
+					ExpiredAt:    100,
+					TemplateID:   1,
+				},
+			}).Return(nil)
+			mockPolicyManager.EXPECT().BulkUpdateExpiredAtWithTx(gomock.Any(), []dao.Policy{
+				{
+					PK:           1,
+					SubjectPK:    1,
+					ActionPK:     1,
+					ExpressionPK: 3,
+					ExpiredAt:    100,
+					TemplateID:   1,
+				},
+				{
+					PK:           2,
+					SubjectPK:    1,
+					ActionPK:     2,
+					ExpressionPK: 4,
+					ExpiredAt:    100,
+					TemplateID:   1,
+				},
+			}).Return(nil)
+			mockExpressionManager.EXPECT().UpdateRefCountByPKsWithTx(
+				gomock.Any(), []int64{1, 2}, int64(1)).Return(int64(2), nil)
+			mockExpressionManager.EXPECT().UpdateRefCountByPKsWithTx(
+				gomock.Any(), []int64{3, 4}, int64(-1)).Return(int64(2), nil)
+			mockExpressionManager.EXPECT().BulkDeleteUnreferencedByPKsWithTx(
+				gomock.Any(), []int64{3, 4}).Return(int64(1), nil)
+
+			mockSubjectTemplateManager := mock.NewMockSubjectTemplateManager(ctl)
+			mockSubjectTemplateManager.EXPECT().Get(int64(1), int64(1)).Return(dao.SubjectTemplate{
+				SubjectPK:  1,
+				TemplateID: 1,
+				ExpiredAt:  100,
+			}, nil)
+
+			svc := policyService{
+				manager:                mockPolicyManager,
+				expressionManger:       mockExpressionManager,
+				subjectTemplateManager: mockSubjectTemplateManager,
+			}
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+
+			patches := gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+			defer patches.Reset()
+
+			updatePolicies := []types.Policy{
+				{
+					Version:    "1",
+					ID:         1,
+					SubjectPK:  1,
+					ActionPK:   1,
+					Expression: "test",
+					Signature:  "",
+					ExpiredAt:  1,
+					TemplateID: 1,
+				},
+				{
+					Version:    "1",
+					ID:         2,
+					SubjectPK:  1,
+					ActionPK:   2,
+					Expression: "expression",
+					Signature:  "",
+					ExpiredAt:  1,
+					TemplateID: 1,
+				},
+			}
+
+			set := util.NewInt64Set()
+			set.Add(1)
+			set.Add(2)
+
+			err := svc.UpdateTemplatePolicies(1, updatePolicies, set)
+			assert.NoError(GinkgoT(), err)
+
+			err = dbMock.ExpectationsWereMet()
+			assert.NoError(GinkgoT(), err)
+		})
 	})
 
 	Describe("DeleteTemplatePolicies cases", func() {
@@ -829,15 +1117,354 @@ var _ = Describe("PolicyService", func() {
 
 		It("ok", func() {
 			mockPolicyManager := mock.NewMockPolicyManager(ctl)
-			mockPolicyManager.EXPECT().BulkDeleteBySubjectTemplate(int64(1), int64(1)).Return(nil)
+			mockPolicyManager.EXPECT().ListBySubjectTemplate(int64(1), int64(1)).Return([]dao.Policy{
+				{PK: 1, SubjectPK: 1, ActionPK: 1, ExpressionPK: 3, TemplateID: 1},
+				{PK: 2, SubjectPK: 1, ActionPK: 2, ExpressionPK: 3, TemplateID: 1},
+				{PK: 3, SubjectPK: 1, ActionPK: 3, ExpressionPK: -1, TemplateID: 1},
+			}, nil)
+			mockPolicyManager.EXPECT().BulkDeleteByTemplatePKsWithTx(
+				gomock.Any(), int64(1), int64(1), []int64{1, 2, 3}).Return(int64(3), nil)
+
+			mockExpressionManager := mock.NewMockExpressionManager(ctl)
+			mockExpressionManager.EXPECT().UpdateRefCountByPKsWithTx(
+				gomock.Any(), []int64{3}, int64(-2)).Return(int64(1), nil)
+			mockExpressionManager.EXPECT().BulkDeleteUnreferencedByPKsWithTx(
+				gomock.Any(), []int64{3}).Return(int64(1), nil)
+
+			mockSubjectTemplateManager := mock.NewMockSubjectTemplateManager(ctl)
+			mockSubjectTemplateManager.EXPECT().Delete(int64(1), int64(1)).Return(int64(1), nil)
 
 			svc := policyService{
-				manager: mockPolicyManager,
+				manager:                mockPolicyManager,
+				expressionManger:       mockExpressionManager,
+				subjectTemplateManager: mockSubjectTemplateManager,
+			}
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+
+			patches := gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+			defer patches.Reset()
+
+			err := svc.DeleteTemplatePolicies(int64(1), int64(1))
+			assert.NoError(GinkgoT(), err)
+
+			err = dbMock.ExpectationsWereMet()
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("expression deleted by others", func() {
+			mockPolicyManager := mock.NewMockPolicyManager(ctl)
+			mockPolicyManager.EXPECT().ListBySubjectTemplate(int64(1), int64(1)).Return([]dao.Policy{
+				{PK: 1, SubjectPK: 1, ActionPK: 1, ExpressionPK: 3, TemplateID: 1},
+			}, nil)
+			mockPolicyManager.EXPECT().BulkDeleteByTemplatePKsWithTx(
+				gomock.Any(), int64(1), int64(1), []int64{1}).Return(int64(1), nil)
+
+			mockExpressionManager := mock.NewMockExpressionManager(ctl)
+			mockExpressionManager.EXPECT().UpdateRefCountByPKsWithTx(
+				gomock.Any(), []int64{3}, int64(-1)).Return(int64(0), nil)
+
+			svc := policyService{
+				manager:          mockPolicyManager,
+				expressionManger: mockExpressionManager,
 			}
 
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectRollback()
+
+			patches := gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+			defer patches.Reset()
+
 			err := svc.DeleteTemplatePolicies(int64(1), int64(1))
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "expression not exists")
+		})
+	})
+
+	Describe("DeleteByActionPK cases", func() {
+		var ctl *gomock.Controller
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("ok", func() {
+			mockPolicyManager := mock.NewMockPolicyManager(ctl)
+			mockPolicyManager.EXPECT().ListByActionPKWithLimit(int64(2), int64(10000)).Return([]dao.Policy{
+				{PK: 1, SubjectPK: 1, ActionPK: 2, ExpressionPK: 3},
+				{PK: 2, SubjectPK: 2, ActionPK: 2, ExpressionPK: 3},
+				{PK: 3, SubjectPK: 3, ActionPK: 2, ExpressionPK: 4},
+			}, nil)
+			mockPolicyManager.EXPECT().BulkDeleteByActionPKsWithTx(
+				gomock.Any(), int64(2), []int64{1, 2, 3}).Return(int64(3), nil)
+
+			mockExpressionManager := mock.NewMockExpressionManager(ctl)
+			mockExpressionManager.EXPECT().UpdateRefCountByPKsWithTx(
+				gomock.Any(), []int64{4}, int64(-1)).Return(int64(1), nil)
+			mockExpressionManager.EXPECT().UpdateRefCountByPKsWithTx(
+				gomock.Any(), []int64{3}, int64(-2)).Return(int64(1), nil)
+			mockExpressionManager.EXPECT().BulkDeleteUnreferencedByPKsWithTx(
+				gomock.Any(), []int64{3, 4}).Return(int64(2), nil)
+
+			svc := policyService{
+				manager:          mockPolicyManager,
+				expressionManger: mockExpressionManager,
+			}
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+
+			patches := gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+			defer patches.Reset()
+
+			err := svc.DeleteByActionPK(int64(2))
+			assert.NoError(GinkgoT(), err)
+
+			err = dbMock.ExpectationsWereMet()
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("no policy", func() {
+			mockPolicyManager := mock.NewMockPolicyManager(ctl)
+			mockPolicyManager.EXPECT().ListByActionPKWithLimit(int64(2), int64(10000)).Return([]dao.Policy{}, nil)
+
+			svc := policyService{
+				manager: mockPolicyManager,
+			}
+
+			err := svc.DeleteByActionPK(int64(2))
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("policy deleted by others", func() {
+			mockPolicyManager := mock.NewMockPolicyManager(ctl)
+			mockPolicyManager.EXPECT().ListByActionPKWithLimit(int64(2), int64(10000)).Return([]dao.Policy{
+				{PK: 1, SubjectPK: 1, ActionPK: 2, ExpressionPK: 3},
+				{PK: 2, SubjectPK: 2, ActionPK: 2, ExpressionPK: 3},
+			}, nil)
+			mockPolicyManager.EXPECT().BulkDeleteByActionPKsWithTx(
+				gomock.Any(), int64(2), []int64{1, 2}).Return(int64(1), nil)
+
+			svc := policyService{
+				manager: mockPolicyManager,
+			}
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectRollback()
+
+			patches := gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+			defer patches.Reset()
+
+			err := svc.DeleteByActionPK(int64(2))
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "policy not exists")
+		})
+	})
+
+	Describe("DeleteTemplatePoliciesWithLimit cases", func() {
+		var ctl *gomock.Controller
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("ListBySubjectTemplateWithLimit fail", func() {
+			mockPolicyManager := mock.NewMockPolicyManager(ctl)
+			mockPolicyManager.EXPECT().ListBySubjectTemplateWithLimit(int64(1), int64(1), int64(10)).Return(
+				nil, errors.New("error"),
+			)
+
+			svc := policyService{
+				manager: mockPolicyManager,
+			}
+
+			_, err := svc.DeleteTemplatePoliciesWithLimit(int64(1), int64(1), int64(10))
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListBySubjectTemplateWithLimit")
+		})
+
+		It("ok, the last chunk delete the subject template", func() {
+			mockPolicyManager := mock.NewMockPolicyManager(ctl)
+			mockPolicyManager.EXPECT().ListBySubjectTemplateWithLimit(int64(1), int64(1), int64(10)).Return(
+				[]dao.Policy{}, nil,
+			)
+
+			mockSubjectTemplateManager := mock.NewMockSubjectTemplateManager(ctl)
+			mockSubjectTemplateManager.EXPECT().Delete(int64(1), int64(1)).Return(int64(1), nil)
+
+			svc := policyService{
+				manager:                mockPolicyManager,
+				subjectTemplateManager: mockSubjectTemplateManager,
+			}
+
+			count, err := svc.DeleteTemplatePoliciesWithLimit(int64(1), int64(1), int64(10))
 			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(0), count)
+		})
+
+		It("subjectTemplateManager.Delete fail", func() {
+			mockPolicyManager := mock.NewMockPolicyManager(ctl)
+			mockPolicyManager.EXPECT().ListBySubjectTemplateWithLimit(int64(1), int64(1), int64(10)).Return(
+				[]dao.Policy{}, nil,
+			)
+
+			mockSubjectTemplateManager := mock.NewMockSubjectTemplateManager(ctl)
+			mockSubjectTemplateManager.EXPECT().Delete(int64(1), int64(1)).Return(int64(0), errors.New("error"))
+
+			svc := policyService{
+				manager:                mockPolicyManager,
+				subjectTemplateManager: mockSubjectTemplateManager,
+			}
+
+			_, err := svc.DeleteTemplatePoliciesWithLimit(int64(1), int64(1), int64(10))
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "subjectTemplateManager.Delete")
 		})
 	})
 
+	Describe("UpdateTemplatePoliciesExpiredAt cases", func() {
+		var ctl *gomock.Controller
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("saveTemplateExpiredAt fail", func() {
+			mockSubjectTemplateManager := mock.NewMockSubjectTemplateManager(ctl)
+			mockSubjectTemplateManager.EXPECT().Get(int64(1), int64(1)).Return(
+				dao.SubjectTemplate{}, errors.New("error"),
+			)
+
+			svc := policyService{
+				subjectTemplateManager: mockSubjectTemplateManager,
+			}
+
+			_, err := svc.UpdateTemplatePoliciesExpiredAt(int64(1), int64(1), int64(100))
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "saveTemplateExpiredAt")
+		})
+
+		It("ListBySubjectTemplate fail", func() {
+			mockPolicyManager := mock.NewMockPolicyManager(ctl)
+			mockPolicyManager.EXPECT().ListBySubjectTemplate(int64(1), int64(1)).Return(nil, errors.New("error"))
+
+			mockSubjectTemplateManager := mock.NewMockSubjectTemplateManager(ctl)
+			mockSubjectTemplateManager.EXPECT().Get(int64(1), int64(1)).Return(dao.SubjectTemplate{}, sql.ErrNoRows)
+			mockSubjectTemplateManager.EXPECT().Create(dao.SubjectTemplate{
+				SubjectPK:  1,
+				TemplateID: 1,
+				ExpiredAt:  100,
+			}).Return(nil)
+
+			svc := policyService{
+				manager:                mockPolicyManager,
+				subjectTemplateManager: mockSubjectTemplateManager,
+			}
+
+			_, err := svc.UpdateTemplatePoliciesExpiredAt(int64(1), int64(1), int64(100))
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListBySubjectTemplate")
+		})
+
+		It("ok in chunks", func() {
+			mockPolicyManager := mock.NewMockPolicyManager(ctl)
+			mockPolicyManager.EXPECT().ListBySubjectTemplate(int64(1), int64(1)).Return([]dao.Policy{
+				{PK: 1, SubjectPK: 1, ActionPK: 1, ExpiredAt: 10, TemplateID: 1},
+				{PK: 2, SubjectPK: 1, ActionPK: 2, ExpiredAt: 100, TemplateID: 1},
+				{PK: 3, SubjectPK: 1, ActionPK: 3, ExpiredAt: 10, TemplateID: 1},
+				{PK: 4, SubjectPK: 1, ActionPK: 4, ExpiredAt: 200, TemplateID: 1},
+			}, nil)
+			mockPolicyManager.EXPECT().BulkUpdateExpiredAtWithTx(gomock.Any(), []dao.Policy{
+				{PK: 1, SubjectPK: 1, ActionPK: 1, ExpiredAt: 100, TemplateID: 1},
+				{PK: 3, SubjectPK: 1, ActionPK: 3, ExpiredAt: 100, TemplateID: 1},
+			}).Return(nil)
+			mockPolicyManager.EXPECT().BulkUpdateExpiredAtWithTx(gomock.Any(), []dao.Policy{
+				{PK: 4, SubjectPK: 1, ActionPK: 4, ExpiredAt: 100, TemplateID: 1},
+			}).Return(nil)
+
+			mockSubjectTemplateManager := mock.NewMockSubjectTemplateManager(ctl)
+			mockSubjectTemplateManager.EXPECT().Get(int64(1), int64(1)).Return(dao.SubjectTemplate{
+				PK:         1,
+				SubjectPK:  1,
+				TemplateID: 1,
+				ExpiredAt:  10,
+			}, nil)
+			mockSubjectTemplateManager.EXPECT().UpdateExpiredAt(dao.SubjectTemplate{
+				SubjectPK:  1,
+				TemplateID: 1,
+				ExpiredAt:  100,
+			}).Return(nil)
+
+			svc := policyService{
+				manager:                mockPolicyManager,
+				subjectTemplateManager: mockSubjectTemplateManager,
+			}
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+
+			patches := gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+			defer patches.Reset()
+			patches.ApplyGlobalVar(&templatePolicyExpiredAtChunkSize, 2)
+
+			updated, err := svc.UpdateTemplatePoliciesExpiredAt(int64(1), int64(1), int64(100))
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(3), updated)
+
+			err = dbMock.ExpectationsWereMet()
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("BulkUpdateExpiredAtWithTx fail", func() {
+			mockPolicyManager := mock.NewMockPolicyManager(ctl)
+			mockPolicyManager.EXPECT().ListBySubjectTemplate(int64(1), int64(1)).Return([]dao.Policy{
+				{PK: 1, SubjectPK: 1, ActionPK: 1, ExpiredAt: 10, TemplateID: 1},
+			}, nil)
+			mockPolicyManager.EXPECT().BulkUpdateExpiredAtWithTx(gomock.Any(), gomock.Any()).Return(errors.New("error"))
+
+			mockSubjectTemplateManager := mock.NewMockSubjectTemplateManager(ctl)
+			mockSubjectTemplateManager.EXPECT().Get(int64(1), int64(1)).Return(dao.SubjectTemplate{}, sql.ErrNoRows)
+			mockSubjectTemplateManager.EXPECT().Create(dao.SubjectTemplate{
+				SubjectPK:  1,
+				TemplateID: 1,
+				ExpiredAt:  100,
+			}).Return(nil)
+
+			svc := policyService{
+				manager:                mockPolicyManager,
+				subjectTemplateManager: mockSubjectTemplateManager,
+			}
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectRollback()
+
+			patches := gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+			defer patches.Reset()
+
+			updated, err := svc.UpdateTemplatePoliciesExpiredAt(int64(1), int64(1), int64(100))
+			assert.Error(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(0), updated)
+		})
+	})
 })
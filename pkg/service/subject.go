@@ -13,6 +13,8 @@ package service
 //go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
 
 import (
+	"github.com/jmoiron/sqlx"
+
 	"iam/pkg/database"
 	"iam/pkg/database/dao"
 	"iam/pkg/errorx"
@@ -22,8 +24,24 @@ import (
 // SubjectSVC ...
 const SubjectSVC = "SubjectSVC"
 
-// SubjectService subject加载器
+// SubjectService subject加载器, 包含读写两部分
 type SubjectService interface {
+	SubjectReadService
+	SubjectWriteService
+}
+
+// SubjectReadService subject只读接口, 鉴权/缓存回源只依赖这部分
+// NOTE: 按关注点拆分, 调用方只依赖用到的部分, 见 NewSubjectXXXReadService
+type SubjectReadService interface {
+	SubjectBaseReadService
+	SubjectGroupReadService
+	SubjectMemberReadService
+	SubjectDepartmentReadService
+	SubjectRoleReadService
+}
+
+// SubjectBaseReadService subject本身的只读接口
+type SubjectBaseReadService interface {
 	// in this file
 	// Subject
 
@@ -31,50 +49,148 @@ type SubjectService interface {
 	GetPK(_type, id string) (int64, error)
 	GetCount(_type string) (int64, error)
 	ListPaging(_type string, limit, offset int64) ([]types.Subject, error)
+	GetSearchCount(_type, keyword string) (int64, error)
+	SearchPaging(_type, keyword string, limit, offset int64) ([]types.Subject, error)
 	ListPKsBySubjects(subjects []types.Subject) ([]int64, error)
 	ListByPKs(pks []int64) ([]types.Subject, error)
-	BulkCreate(subjects []types.Subject) error
-	BulkDelete(subjects []types.Subject) ([]int64, error)
-	BulkUpdateName(subjects []types.Subject) error
+	ListExistSubjects(subjects []types.Subject) ([]types.Subject, error)
 
+	// in subject_freeze.go
+
+	ListFrozenPKs() ([]int64, error)
+}
+
+// SubjectGroupReadService subject所属用户组的只读接口
+type SubjectGroupReadService interface {
 	// in subject_group.go
 
 	GetThinSubjectGroups(pk int64) ([]types.ThinSubjectGroup, error)
 	ListSubjectEffectGroups(pks []int64) (map[int64][]types.ThinSubjectGroup, error)
 	ListSubjectGroups(_type, id string, beforeExpiredAt int64) ([]types.SubjectGroup, error)
+	GetSubjectGroupCount(_type, id string, beforeExpiredAt int64) (int64, error)
+	ListPagingSubjectGroups(_type, id string, beforeExpiredAt, limit, offset int64) ([]types.SubjectGroup, error)
+
+	// in subject_group_setting.go
 
+	GetGroupSetting(_type, id string) (types.GroupSetting, error)
+}
+
+// SubjectMemberReadService 用户组成员的只读接口
+type SubjectMemberReadService interface {
 	// in subject_member.go
 	// Member:
 
 	GetMemberCount(_type, id string) (int64, error)
+	GetMemberCountBySubjectType(_type, id, subjectType string) (int64, error)
 	GetMemberCountBeforeExpiredAt(_type, id string, expiredAt int64) (int64, error)
 	ListPagingMember(_type, id string, limit, offset int64) ([]types.SubjectMember, error)
+	ListPagingMemberBySubjectType(
+		_type, id, subjectType string, limit, offset int64,
+	) ([]types.SubjectMember, error)
 	ListPagingMemberBeforeExpiredAt(
 		_type, id string, expiredAt int64, limit, offset int64,
 	) ([]types.SubjectMember, error)
 	ListExistSubjectsBeforeExpiredAt(subjects []types.Subject, expiredAt int64) ([]types.Subject, error)
 	ListMember(_type, id string) ([]types.SubjectMember, error)
-	UpdateMembersExpiredAt(members []types.SubjectMember) error
-	BulkDeleteSubjectMembers(_type, id string, members []types.Subject) (map[string]int64, error)
-	BulkCreateSubjectMembers(_type, id string, members []types.Subject, policyExpiredAt int64) error
 
+	// in subject_member_redundancy.go
+
+	ListRedundantMembers(_type, id string) ([]types.RedundantMember, error)
+
+	// in subject_member_effective.go
+
+	ListGroupEffectiveUsersAfterPK(_type, id string, afterPK, limit int64) ([]types.GroupEffectiveUser, int64, error)
+
+	// in subject_member_snapshot.go
+
+	GetSubjectMemberSnapshot(pk int64) (types.SubjectMemberSnapshot, error)
+	ListSubjectMemberSnapshots(_type, id string) ([]types.SubjectMemberSnapshot, error)
+
+	// in subject_member_event.go
+
+	GetSubjectMemberEventCount(filter types.SubjectMemberEventFilter) (int64, error)
+	ListPagingSubjectMemberEvent(
+		filter types.SubjectMemberEventFilter, limit, offset int64,
+	) ([]types.SubjectMemberEvent, error)
+}
+
+// SubjectDepartmentReadService subject所属部门的只读接口
+type SubjectDepartmentReadService interface {
 	// in subject_department.go
 	// Department
 
 	GetSubjectDepartmentPKs(subjectPK int64) ([]int64, error)
 	GetSubjectDepartmentCount() (int64, error)
 	ListPagingSubjectDepartment(limit, offset int64) ([]types.SubjectDepartment, error)
-	BulkCreateSubjectDepartments(subjectDepartments []types.SubjectDepartment) error
-	BulkUpdateSubjectDepartments(subjectDepartments []types.SubjectDepartment) ([]int64, error)
-	BulkDeleteSubjectDepartments(subjectIDs []string) ([]int64, error)
+	GetSubjectDepartmentHistoryCount(subjectPK int64) (int64, error)
+	ListPagingSubjectDepartmentHistory(subjectPK int64, limit, offset int64) ([]types.SubjectDepartmentHistory, error)
+}
 
+// SubjectRoleReadService subject角色的只读接口
+type SubjectRoleReadService interface {
 	// in subject_role.go
 	// Role
 
 	ListSubjectPKByRole(roleType, system string) ([]int64, error)
 	ListRoleSystemIDBySubjectPK(pk int64) ([]string, error)
-	BulkCreateSubjectRoles(roleType, system string, subjects []types.Subject) error
-	BulkDeleteSubjectRoles(roleType, system string, subjects []types.Subject) error
+	ListSubjectRoles(_type, id string) ([]types.SubjectRole, error)
+	GetSubjectRoleCount() (int64, error)
+	ListPagingSubjectRoles(limit, offset int64) ([]types.SubjectRoleHolder, error)
+	GetSubjectRoleHistoryCount(roleType, system string) (int64, error)
+	ListPagingSubjectRoleHistory(roleType, system string, limit, offset int64) ([]types.SubjectRoleHistory, error)
+}
+
+// SubjectWriteService subject写接口, 写成功后会发出SubjectChangeEvent, 见subject_event.go
+type SubjectWriteService interface {
+	// in this file
+	// Subject
+
+	BulkCreate(subjects []types.Subject) error
+	BulkDelete(subjects []types.Subject) ([]int64, error)
+	BulkUpdateName(subjects []types.Subject) error
+
+	// in subject_freeze.go
+	// Freeze
+
+	BulkUpdateFrozen(subjects []types.Subject, frozen bool) ([]int64, error)
+
+	// in subject_member.go
+	// Member:
+
+	UpdateMembersExpiredAt(members []types.SubjectMember) error
+	BulkDeleteSubjectMembers(_type, id string, members []types.Subject) (map[string]int64, error)
+	BulkCreateSubjectMembers(_type, id string, members []types.Subject, policyExpiredAt int64) error
+	PurgeExpiredMembers(expiredAt int64, limit int64) (int64, error)
+
+	// in subject_group_setting.go
+
+	SaveGroupSetting(_type, id string, setting types.GroupSetting) error
+	DeleteGroupSetting(_type, id string) error
+
+	// in subject_member_copy.go
+
+	CopySubjectMembers(_type, sourceID, targetID string, move bool) (types.SubjectMemberCopyResult, error)
+
+	// in subject_member_snapshot.go
+
+	CreateSubjectMemberSnapshot(_type, id, operator, source string) (types.SubjectMemberSnapshot, error)
+	RestoreSubjectMemberSnapshot(pk int64, operator, source string) (types.SubjectMemberRestoreResult, error)
+
+	// in subject_department.go
+	// Department
+
+	BulkCreateSubjectDepartments(
+		subjectDepartments []types.SubjectDepartment, source string,
+	) (types.SubjectDepartmentBulkResult, error)
+	BulkUpdateSubjectDepartments(subjectDepartments []types.SubjectDepartment, source string) ([]int64, error)
+	BulkDeleteSubjectDepartments(subjectIDs []string, source string) ([]int64, error)
+
+	// in subject_role.go
+	// Role
+
+	BulkCreateSubjectRoles(roleType, system string, subjects []types.Subject, operator, source string) error
+	BulkCreateSubjectMultiRoles(roles []types.SubjectRole, subjects []types.Subject, operator, source string) error
+	BulkDeleteSubjectRoles(roleType, system string, subjects []types.Subject, operator, source string) error
 }
 
 type subjectService struct {
@@ -82,9 +198,19 @@ type subjectService struct {
 	policyManager     dao.PolicyManager
 	expressionManager dao.ExpressionManager
 
-	relationManager   dao.SubjectRelationManager
-	departmentManager dao.SubjectDepartmentManager
-	roleManager       dao.SubjectRoleManager
+	relationManager           dao.SubjectRelationManager
+	departmentManager         dao.SubjectDepartmentManager
+	departmentHistoryManager  dao.SubjectDepartmentHistoryManager
+	departmentRelationManager dao.DepartmentRelationManager
+	groupSettingManager       dao.GroupSettingManager
+	subjectTemplateManager    dao.SubjectTemplateManager
+	roleManager               dao.SubjectRoleManager
+	roleHistoryManager        dao.SubjectRoleHistoryManager
+	memberEventManager        dao.SubjectMemberEventManager
+	memberSnapshotManager     dao.SubjectMemberSnapshotManager
+
+	// batch 是否使用批量任务的DB连接池
+	batch bool
 }
 
 // NewSubjectService SubjectService工厂
@@ -94,12 +220,75 @@ func NewSubjectService() SubjectService {
 		policyManager:     dao.NewPolicyManager(),
 		expressionManager: dao.NewExpressionManager(),
 
-		relationManager:   dao.NewSubjectRelationManager(),
-		departmentManager: dao.NewSubjectDepartmentManager(),
-		roleManager:       dao.NewSubjectRoleManager(),
+		relationManager:           dao.NewSubjectRelationManager(),
+		departmentManager:         dao.NewSubjectDepartmentManager(),
+		departmentHistoryManager:  dao.NewSubjectDepartmentHistoryManager(),
+		departmentRelationManager: dao.NewDepartmentRelationManager(),
+		groupSettingManager:       dao.NewGroupSettingManager(),
+		subjectTemplateManager:    dao.NewSubjectTemplateManager(),
+		roleManager:               dao.NewSubjectRoleManager(),
+		roleHistoryManager:        dao.NewSubjectRoleHistoryManager(),
+		memberEventManager:        dao.NewSubjectMemberEventManager(),
+		memberSnapshotManager:     dao.NewSubjectMemberSnapshotManager(),
 	}
 }
 
+// NewBatchSubjectService 批量任务(清理等)使用的SubjectService, 成员关系的查询及事务使用批量任务的DB连接池
+func NewBatchSubjectService() SubjectService {
+	svc := NewSubjectService().(*subjectService)
+	svc.relationManager = dao.NewBatchSubjectRelationManager()
+	svc.batch = true
+	return svc
+}
+
+func (l *subjectService) generateDBTx() (*sqlx.Tx, error) {
+	if l.batch {
+		return database.GenerateBatchDBTx()
+	}
+	return database.GenerateDefaultDBTx()
+}
+
+// NewSubjectReadService 只读的SubjectService, 供缓存回源/鉴权使用
+func NewSubjectReadService() SubjectReadService {
+	return &subjectService{
+		manager:                   dao.NewSubjectManager(),
+		relationManager:           dao.NewSubjectRelationManager(),
+		departmentManager:         dao.NewSubjectDepartmentManager(),
+		departmentHistoryManager:  dao.NewSubjectDepartmentHistoryManager(),
+		departmentRelationManager: dao.NewDepartmentRelationManager(),
+		groupSettingManager:       dao.NewGroupSettingManager(),
+		roleManager:               dao.NewSubjectRoleManager(),
+		roleHistoryManager:        dao.NewSubjectRoleHistoryManager(),
+		memberEventManager:        dao.NewSubjectMemberEventManager(),
+		memberSnapshotManager:     dao.NewSubjectMemberSnapshotManager(),
+	}
+}
+
+// NewSubjectBaseReadService subject本身的只读service
+func NewSubjectBaseReadService() SubjectBaseReadService {
+	return NewSubjectReadService()
+}
+
+// NewSubjectGroupReadService subject所属用户组的只读service
+func NewSubjectGroupReadService() SubjectGroupReadService {
+	return NewSubjectReadService()
+}
+
+// NewSubjectMemberReadService 用户组成员的只读service
+func NewSubjectMemberReadService() SubjectMemberReadService {
+	return NewSubjectReadService()
+}
+
+// NewSubjectDepartmentReadService subject所属部门的只读service
+func NewSubjectDepartmentReadService() SubjectDepartmentReadService {
+	return NewSubjectReadService()
+}
+
+// NewSubjectRoleReadService subject角色的只读service
+func NewSubjectRoleReadService() SubjectRoleReadService {
+	return NewSubjectReadService()
+}
+
 // Get ...
 func (l *subjectService) Get(pk int64) (subject types.Subject, err error) {
 	var s dao.Subject
@@ -161,6 +350,29 @@ func (l *subjectService) ListPaging(_type string, limit, offset int64) ([]types.
 	return subjects, nil
 }
 
+// GetSearchCount ...
+func (l *subjectService) GetSearchCount(_type, keyword string) (int64, error) {
+	cnt, err := l.manager.GetSearchCount(_type, keyword)
+	if err != nil {
+		err = errorx.Wrapf(err, SubjectSVC, "GetSearchCount",
+			"manager.GetSearchCount _type=`%s`, keyword=`%s` fail", _type, keyword)
+		return 0, err
+	}
+	return cnt, nil
+}
+
+// SearchPaging 按关键字前缀匹配id/name查询subject
+func (l *subjectService) SearchPaging(_type, keyword string, limit, offset int64) ([]types.Subject, error) {
+	daoSubjects, err := l.manager.SearchPaging(_type, keyword, limit, offset)
+	if err != nil {
+		return nil, errorx.Wrapf(err, SubjectSVC,
+			"SearchPaging", "manager.SearchPaging _type=`%s`, keyword=`%s`, limit=`%d`, offset=`%d`",
+			_type, keyword, limit, offset)
+	}
+
+	return convertToSubjects(daoSubjects), nil
+}
+
 // BulkCreate ...
 func (l *subjectService) BulkCreate(subjects []types.Subject) error {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "BulkCreate")
@@ -178,14 +390,23 @@ func (l *subjectService) BulkCreate(subjects []types.Subject) error {
 	if err != nil {
 		return errorWrapf(err, "manager.BulkCreate subjects=`%+v`", daoSubjects)
 	}
+
+	// 新建的subject可能已经被缓存为不存在, 需要清理
+	emitSubjectChangeEvent(SubjectChangeEvent{
+		Type:     SubjectChangeEventTypeSubject,
+		Subjects: subjects,
+	})
 	return err
 }
 
-func groupBySubjectType(subjects []types.Subject) (userIDs []string, departmentIDs []string, groupIDs []string) {
+func groupBySubjectType(
+	subjects []types.Subject,
+) (userIDs []string, departmentIDs []string, groupIDs []string, serviceAccountIDs []string) {
 	// 分组获取Subject PK
 	userIDs = make([]string, 0, len(subjects))
 	departmentIDs = make([]string, 0, len(subjects))
 	groupIDs = make([]string, 0, len(subjects))
+	serviceAccountIDs = make([]string, 0, len(subjects))
 	for _, s := range subjects {
 		switch s.Type {
 		case types.UserType:
@@ -194,6 +415,8 @@ func groupBySubjectType(subjects []types.Subject) (userIDs []string, departmentI
 			departmentIDs = append(departmentIDs, s.ID)
 		case types.GroupType:
 			groupIDs = append(groupIDs, s.ID)
+		case types.ServiceAccountType:
+			serviceAccountIDs = append(serviceAccountIDs, s.ID)
 		}
 	}
 	return
@@ -204,7 +427,7 @@ func (l *subjectService) ListPKsBySubjects(subjects []types.Subject) ([]int64, e
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "ListPKsBySubjects")
 
 	// 分组获取Subject PK
-	userIDs, departmentIDs, groupIDs := groupBySubjectType(subjects)
+	userIDs, departmentIDs, groupIDs, serviceAccountIDs := groupBySubjectType(subjects)
 
 	pks := []int64{}
 	if len(userIDs) > 0 {
@@ -235,6 +458,16 @@ func (l *subjectService) ListPKsBySubjects(subjects []types.Subject) ([]int64, e
 			pks = append(pks, g.PK)
 		}
 	}
+	if len(serviceAccountIDs) > 0 {
+		serviceAccounts, newErr := l.manager.ListByIDs(types.ServiceAccountType, serviceAccountIDs)
+		if newErr != nil {
+			return nil, errorWrapf(newErr, "manager.ListByIDs _type=`%s`, ids=`%+v` fail",
+				types.ServiceAccountType, serviceAccountIDs)
+		}
+		for _, sa := range serviceAccounts {
+			pks = append(pks, sa.PK)
+		}
+	}
 	return pks, nil
 }
 
@@ -250,6 +483,34 @@ func (l *subjectService) ListByPKs(pks []int64) ([]types.Subject, error) {
 	return subjects, nil
 }
 
+// ListExistSubjects 查询subjects中在DB里存在的subject
+func (l *subjectService) ListExistSubjects(subjects []types.Subject) ([]types.Subject, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "ListExistSubjects")
+
+	userIDs, departmentIDs, groupIDs, serviceAccountIDs := groupBySubjectType(subjects)
+	typeIDs := map[string][]string{
+		types.UserType:           userIDs,
+		types.DepartmentType:     departmentIDs,
+		types.GroupType:          groupIDs,
+		types.ServiceAccountType: serviceAccountIDs,
+	}
+
+	existSubjects := make([]types.Subject, 0, len(subjects))
+	for _, _type := range []string{types.UserType, types.DepartmentType, types.GroupType, types.ServiceAccountType} {
+		ids := typeIDs[_type]
+		if len(ids) == 0 {
+			continue
+		}
+
+		daoSubjects, err := l.manager.ListByIDs(_type, ids)
+		if err != nil {
+			return nil, errorWrapf(err, "manager.ListByIDs _type=`%s`, ids=`%+v` fail", _type, ids)
+		}
+		existSubjects = append(existSubjects, convertToSubjects(daoSubjects)...)
+	}
+	return existSubjects, nil
+}
+
 // BulkDelete ...
 func (l *subjectService) BulkDelete(subjects []types.Subject) (pks []int64, err error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "BulkDelete")
@@ -272,6 +533,18 @@ func (l *subjectService) BulkDelete(subjects []types.Subject) (pks []int64, err
 		return pks, errorWrapf(err, "define tx error")
 	}
 
+	// 减去策略对expression的引用, 删除不再被引用的expression, 需要在删除策略之前
+	err = l.expressionManager.DecrRefCountBySubjectPKsWithTx(tx, pks)
+	if err != nil {
+		return pks, errorWrapf(
+			err, "expressionManager.DecrRefCountBySubjectPKsWithTx subject_pks=`%+v` fail", pks)
+	}
+	_, err = l.expressionManager.DeleteUnreferencedBySubjectPKsWithTx(tx, pks)
+	if err != nil {
+		return pks, errorWrapf(
+			err, "expressionManager.DeleteUnreferencedBySubjectPKsWithTx subject_pks=`%+v` fail", pks)
+	}
+
 	// 删除策略 policy
 	err = l.policyManager.BulkDeleteBySubjectPKsWithTx(tx, pks)
 	if err != nil {
@@ -286,6 +559,13 @@ func (l *subjectService) BulkDelete(subjects []types.Subject) (pks []int64, err
 			err, "expressionManager.BulkDeleteByPKsWithTx pks=`%+v` fail", expressionPKs)
 	}
 
+	// 删除模板授权的过期时间
+	err = l.subjectTemplateManager.BulkDeleteBySubjectPKsWithTx(tx, pks)
+	if err != nil {
+		return pks, errorWrapf(
+			err, "subjectTemplateManager.BulkDeleteBySubjectPKsWithTx subject_pks=`%+v` fail", pks)
+	}
+
 	// 批量用户组删除成员关系 subjectRelation
 	err = l.relationManager.BulkDeleteByParentPKs(tx, pks)
 	if err != nil {
@@ -306,6 +586,20 @@ func (l *subjectService) BulkDelete(subjects []types.Subject) (pks []int64, err
 			err, "departmentManager.BulkDeleteWithTx subject_pks=`%+v` fail", pks)
 	}
 
+	// 对于部门，需要删除其上级部门关系, 以及下级部门指向它的关系
+	err = l.departmentRelationManager.BulkDeleteWithTx(tx, pks)
+	if err != nil {
+		return pks, errorWrapf(
+			err, "departmentRelationManager.BulkDeleteWithTx department_pks=`%+v` fail", pks)
+	}
+
+	// 对于用户组，需要删除用户组的成员配置
+	err = l.groupSettingManager.BulkDeleteWithTx(tx, pks)
+	if err != nil {
+		return pks, errorWrapf(
+			err, "groupSettingManager.BulkDeleteWithTx group_pks=`%+v` fail", pks)
+	}
+
 	// 删除对象 subject
 	err = l.manager.BulkDeleteByPKsWithTx(tx, pks)
 	if err != nil {
@@ -318,6 +612,12 @@ func (l *subjectService) BulkDelete(subjects []types.Subject) (pks []int64, err
 	if err != nil {
 		return pks, errorWrapf(err, "tx commit error")
 	}
+
+	emitSubjectChangeEvent(SubjectChangeEvent{
+		Type:       SubjectChangeEventTypeSubject,
+		SubjectPKs: pks,
+		Subjects:   subjects,
+	})
 	return pks, err
 }
 
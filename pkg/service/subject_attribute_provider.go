@@ -0,0 +1,166 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package service
+
+import (
+	"encoding/json"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/errorx"
+	"iam/pkg/service/types"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+// SubjectAttributeProviderSVC ...
+const SubjectAttributeProviderSVC = "SubjectAttributeProviderSVC"
+
+// SubjectAttributeProviderService 外部subject属性提供方的管理
+type SubjectAttributeProviderService interface {
+	ListAll() ([]types.SubjectAttributeProvider, error)
+	Get(id string) (types.SubjectAttributeProvider, error)
+	Create(provider types.SubjectAttributeProvider) error
+	Update(provider types.SubjectAttributeProvider) error
+	Delete(id string) error
+}
+
+type subjectAttributeProviderService struct {
+	manager dao.SubjectAttributeProviderManager
+}
+
+// NewSubjectAttributeProviderService ...
+func NewSubjectAttributeProviderService() SubjectAttributeProviderService {
+	return &subjectAttributeProviderService{
+		manager: dao.NewSubjectAttributeProviderManager(),
+	}
+}
+
+// ListAll ...
+func (s *subjectAttributeProviderService) ListAll() ([]types.SubjectAttributeProvider, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectAttributeProviderSVC, "ListAll")
+
+	daoProviders, err := s.manager.ListAll()
+	if err != nil {
+		return nil, errorWrapf(err, "manager.ListAll fail")
+	}
+
+	providers := make([]types.SubjectAttributeProvider, 0, len(daoProviders))
+	for _, p := range daoProviders {
+		provider, err := convertToSvcSubjectAttributeProvider(p)
+		if err != nil {
+			return nil, errorWrapf(err, "convertToSvcSubjectAttributeProvider id=`%s` fail", p.ID)
+		}
+		providers = append(providers, provider)
+	}
+	return providers, nil
+}
+
+// Get ...
+func (s *subjectAttributeProviderService) Get(id string) (provider types.SubjectAttributeProvider, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectAttributeProviderSVC, "Get")
+
+	daoProvider, err := s.manager.Get(id)
+	if err != nil {
+		return provider, errorWrapf(err, "manager.Get id=`%s` fail", id)
+	}
+
+	provider, err = convertToSvcSubjectAttributeProvider(daoProvider)
+	if err != nil {
+		return provider, errorWrapf(err, "convertToSvcSubjectAttributeProvider id=`%s` fail", id)
+	}
+	return provider, nil
+}
+
+// Create ...
+func (s *subjectAttributeProviderService) Create(provider types.SubjectAttributeProvider) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectAttributeProviderSVC, "Create")
+
+	daoProvider, err := convertToDaoSubjectAttributeProvider(provider)
+	if err != nil {
+		return errorWrapf(err, "convertToDaoSubjectAttributeProvider id=`%s` fail", provider.ID)
+	}
+
+	err = s.manager.Create(daoProvider)
+	if err != nil {
+		return errorWrapf(err, "manager.Create provider=`%+v` fail", daoProvider)
+	}
+	return nil
+}
+
+// Update ...
+func (s *subjectAttributeProviderService) Update(provider types.SubjectAttributeProvider) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectAttributeProviderSVC, "Update")
+
+	daoProvider, err := convertToDaoSubjectAttributeProvider(provider)
+	if err != nil {
+		return errorWrapf(err, "convertToDaoSubjectAttributeProvider id=`%s` fail", provider.ID)
+	}
+
+	err = s.manager.Update(daoProvider)
+	if err != nil {
+		return errorWrapf(err, "manager.Update provider=`%+v` fail", daoProvider)
+	}
+	return nil
+}
+
+// Delete ...
+func (s *subjectAttributeProviderService) Delete(id string) error {
+	err := s.manager.Delete(id)
+	if err != nil {
+		return errorx.Wrapf(err, SubjectAttributeProviderSVC, "Delete", "manager.Delete id=`%s` fail", id)
+	}
+	return nil
+}
+
+func convertToSvcSubjectAttributeProvider(
+	provider dao.SubjectAttributeProvider,
+) (svcProvider types.SubjectAttributeProvider, err error) {
+	svcProvider = types.SubjectAttributeProvider{
+		ID:        provider.ID,
+		Type:      provider.Type,
+		CacheTTL:  provider.CacheTTL,
+		CreatedAt: provider.CreatedAt,
+		UpdatedAt: provider.UpdatedAt,
+	}
+
+	if provider.Attributes != "" {
+		err = json.Unmarshal([]byte(provider.Attributes), &svcProvider.Attributes)
+		if err != nil {
+			return
+		}
+	}
+	if provider.Config != "" {
+		err = json.Unmarshal([]byte(provider.Config), &svcProvider.Config)
+	}
+	return
+}
+
+func convertToDaoSubjectAttributeProvider(
+	provider types.SubjectAttributeProvider,
+) (daoProvider dao.SubjectAttributeProvider, err error) {
+	daoProvider = dao.SubjectAttributeProvider{
+		ID:       provider.ID,
+		Type:     provider.Type,
+		CacheTTL: provider.CacheTTL,
+	}
+
+	attributes, err := json.Marshal(provider.Attributes)
+	if err != nil {
+		return
+	}
+	config, err := json.Marshal(provider.Config)
+	if err != nil {
+		return
+	}
+
+	daoProvider.Attributes = string(attributes)
+	daoProvider.Config = string(config)
+	return
+}
@@ -0,0 +1,155 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package service
+
+import (
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectAttributeProviderService", func() {
+	var ctl *gomock.Controller
+	var mockManager *mock.MockSubjectAttributeProviderManager
+	var svc SubjectAttributeProviderService
+
+	BeforeEach(func() {
+		ctl = gomock.NewController(GinkgoT())
+		mockManager = mock.NewMockSubjectAttributeProviderManager(ctl)
+		svc = &subjectAttributeProviderService{
+			manager: mockManager,
+		}
+	})
+
+	AfterEach(func() {
+		ctl.Finish()
+	})
+
+	Describe("ListAll", func() {
+		It("manager.ListAll fail", func() {
+			mockManager.EXPECT().ListAll().Return(nil, errors.New("list fail"))
+
+			_, err := svc.ListAll()
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "list fail")
+		})
+
+		It("invalid config", func() {
+			mockManager.EXPECT().ListAll().Return([]dao.SubjectAttributeProvider{
+				{ID: "hr", Type: "http", Attributes: `["job_level"]`, Config: `{"url": 1}`},
+			}, nil)
+
+			_, err := svc.ListAll()
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("ok", func() {
+			mockManager.EXPECT().ListAll().Return([]dao.SubjectAttributeProvider{
+				{ID: "hr", Type: "http", Attributes: `["job_level"]`, Config: `{"url": "http://hr"}`, CacheTTL: 60},
+				{
+					ID:         "cost",
+					Type:       "static",
+					Attributes: `["cost_center"]`,
+					Config:     `{"mapping": {"user:tom": {"cost_center": "rd"}}}`,
+				},
+			}, nil)
+
+			providers, err := svc.ListAll()
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), providers, 2)
+			assert.Equal(GinkgoT(), []string{"job_level"}, providers[0].Attributes)
+			assert.Equal(GinkgoT(), "http://hr", providers[0].Config.URL)
+			assert.Equal(GinkgoT(), int64(60), providers[0].CacheTTL)
+			assert.Equal(GinkgoT(), "rd", providers[1].Config.Mapping["user:tom"]["cost_center"])
+		})
+	})
+
+	Describe("Create", func() {
+		It("ok", func() {
+			mockManager.EXPECT().Create(dao.SubjectAttributeProvider{
+				ID:         "hr",
+				Type:       "http",
+				Attributes: `["job_level"]`,
+				Config:     `{"url":"http://hr","timeout":3}`,
+				CacheTTL:   60,
+			}).Return(nil)
+
+			err := svc.Create(types.SubjectAttributeProvider{
+				ID:         "hr",
+				Type:       "http",
+				Attributes: []string{"job_level"},
+				Config: types.SubjectAttributeProviderConfig{
+					URL:     "http://hr",
+					Timeout: 3,
+				},
+				CacheTTL: 60,
+			})
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("manager.Create fail", func() {
+			mockManager.EXPECT().Create(gomock.Any()).Return(errors.New("create fail"))
+
+			err := svc.Create(types.SubjectAttributeProvider{ID: "hr", Type: "http"})
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "create fail")
+		})
+	})
+
+	Describe("Update", func() {
+		It("ok", func() {
+			mockManager.EXPECT().Update(dao.SubjectAttributeProvider{
+				ID:         "cost",
+				Type:       "static",
+				Attributes: `["cost_center"]`,
+				Config:     `{"mapping":{"user:tom":{"cost_center":"rd"}}}`,
+			}).Return(nil)
+
+			err := svc.Update(types.SubjectAttributeProvider{
+				ID:         "cost",
+				Type:       "static",
+				Attributes: []string{"cost_center"},
+				Config: types.SubjectAttributeProviderConfig{
+					Mapping: map[string]map[string]interface{}{
+						"user:tom": {"cost_center": "rd"},
+					},
+				},
+			})
+			assert.NoError(GinkgoT(), err)
+		})
+	})
+
+	Describe("Get", func() {
+		It("ok", func() {
+			mockManager.EXPECT().Get("hr").Return(dao.SubjectAttributeProvider{
+				ID: "hr", Type: "http", Attributes: `["job_level"]`, Config: `{"url": "http://hr"}`,
+			}, nil)
+
+			provider, err := svc.Get("hr")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "http://hr", provider.Config.URL)
+		})
+	})
+
+	Describe("Delete", func() {
+		It("ok", func() {
+			mockManager.EXPECT().Delete("hr").Return(nil)
+
+			err := svc.Delete("hr")
+			assert.NoError(GinkgoT(), err)
+		})
+	})
+})
@@ -12,14 +12,26 @@ package service
 
 import (
 	"fmt"
+	"sort"
+	"time"
 
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/database"
 	"iam/pkg/database/dao"
 	"iam/pkg/errorx"
+	"iam/pkg/metric"
 	"iam/pkg/service/types"
 	"iam/pkg/util"
 )
 
-// GetSubjectDepartmentPKs ...
+// SubjectDepartmentHistoryAction ...
+const (
+	SubjectDepartmentHistoryActionAdded   = "added"
+	SubjectDepartmentHistoryActionRemoved = "removed"
+)
+
+// GetSubjectDepartmentPKs 用户所属的部门, 包含按部门层级关系展开的所有上级部门
 func (l *subjectService) GetSubjectDepartmentPKs(subjectPK int64) ([]int64, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "GetSubjectDepartment")
 	departmentPKStr, err := l.departmentManager.Get(subjectPK)
@@ -31,30 +43,266 @@ func (l *subjectService) GetSubjectDepartmentPKs(subjectPK int64) ([]int64, erro
 	if err != nil {
 		return nil, errorWrapf(err, "util.StringToInt64Slice s=`%s` fail", departmentPKStr)
 	}
-	return departmentPKs, nil
+
+	if len(departmentPKs) == 0 {
+		return departmentPKs, nil
+	}
+
+	ancestorPKs, err := l.listDepartmentAncestorPKs(departmentPKs)
+	if err != nil {
+		return nil, errorWrapf(err, "listDepartmentAncestorPKs departmentPKs=`%+v` fail", departmentPKs)
+	}
+	return append(departmentPKs, ancestorPKs...), nil
+}
+
+// listDepartmentAncestorPKs 逐层向上查询部门的所有上级部门, 不包含已有的部门
+// NOTE: 没有配置部门层级关系时, 只有一次查询, 返回空
+func (l *subjectService) listDepartmentAncestorPKs(departmentPKs []int64) ([]int64, error) {
+	// 记录已经出现过的部门, 避免异常数据中的环导致无限查询
+	visited := util.NewInt64SetWithValues(departmentPKs)
+
+	ancestorPKs := []int64{}
+	current := departmentPKs
+	for len(current) > 0 {
+		relations, err := l.departmentRelationManager.ListByDepartmentPKs(current)
+		if err != nil {
+			return nil, err
+		}
+
+		current = make([]int64, 0, len(relations))
+		for _, r := range relations {
+			if visited.Has(r.ParentPK) {
+				continue
+			}
+			visited.Add(r.ParentPK)
+
+			ancestorPKs = append(ancestorPKs, r.ParentPK)
+			current = append(current, r.ParentPK)
+		}
+	}
+	return ancestorPKs, nil
 }
 
+var (
+	// 分批创建, 每批一个事务, 避免全量同步时的大事务锁表, 以及单个批次的异常导致全部失败
+	subjectDepartmentChunkSize = 1000
+	// 每个批次之后按该批次的耗时等待, DB压力越大写入越慢, 最长等待subjectDepartmentChunkMaxInterval
+	subjectDepartmentChunkMaxInterval = 1 * time.Second
+)
+
 // BulkCreateSubjectDepartments 批量创建用户部门关系
-func (l *subjectService) BulkCreateSubjectDepartments(subjectDepartments []types.SubjectDepartment) error {
+// NOTE: 按批次提交, 失败的批次记录在结果中, 只有全部批次都失败时才返回error
+func (l *subjectService) BulkCreateSubjectDepartments(
+	subjectDepartments []types.SubjectDepartment, source string,
+) (result types.SubjectDepartmentBulkResult, err error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "BulkCreateSubjectDepartments")
+
+	result.Total = len(subjectDepartments)
+	result.Failed = []types.SubjectDepartmentBulkFailure{}
+
+	metric.SubjectDepartmentSyncPending.Add(float64(len(subjectDepartments)))
+
+	var lastErr error
+	for i := 0; i < len(subjectDepartments); i += subjectDepartmentChunkSize {
+		end := i + subjectDepartmentChunkSize
+		if end > len(subjectDepartments) {
+			end = len(subjectDepartments)
+		}
+		chunk := subjectDepartments[i:end]
+
+		start := time.Now()
+		created, chunkErr := l.bulkCreateSubjectDepartmentsChunk(chunk, source)
+		elapsed := time.Since(start)
+
+		metric.SubjectDepartmentSyncChunkDuration.Observe(float64(elapsed / time.Millisecond))
+		metric.SubjectDepartmentSyncPending.Sub(float64(len(chunk)))
+
+		if chunkErr != nil {
+			log.WithError(chunkErr).Errorf("bulkCreateSubjectDepartmentsChunk fail, chunk=[%d, %d)", i, end)
+			metric.SubjectDepartmentSyncCount.WithLabelValues("failed").Add(float64(len(chunk)))
+
+			subjectIDs := make([]string, 0, len(chunk))
+			for _, sd := range chunk {
+				subjectIDs = append(subjectIDs, sd.SubjectID)
+			}
+			result.Failed = append(result.Failed, types.SubjectDepartmentBulkFailure{
+				SubjectIDs: subjectIDs,
+				Error:      chunkErr.Error(),
+			})
+			lastErr = chunkErr
+		} else {
+			metric.SubjectDepartmentSyncCount.WithLabelValues("created").Add(float64(created))
+			result.Created += created
+		}
+
+		if end < len(subjectDepartments) {
+			if elapsed > subjectDepartmentChunkMaxInterval {
+				elapsed = subjectDepartmentChunkMaxInterval
+			}
+			time.Sleep(elapsed)
+		}
+	}
+
+	if lastErr != nil && result.Created == 0 {
+		err = errorWrapf(lastErr, "all the %d chunks fail", len(result.Failed))
+		return
+	}
+	return result, nil
+}
+
+func (l *subjectService) bulkCreateSubjectDepartmentsChunk(
+	subjectDepartments []types.SubjectDepartment, source string,
+) (int, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "bulkCreateSubjectDepartmentsChunk")
 	daoSubjectDepartments, err := l.convertSubjectDepartments(subjectDepartments)
 	if err != nil {
-		return errorWrapf(err, "convertSubjectDepartments subjectDepartments=`%+v` fail", subjectDepartments)
+		return 0, errorWrapf(err, "convertSubjectDepartments subjectDepartments=`%+v` fail", subjectDepartments)
 	}
 
 	if len(daoSubjectDepartments) == 0 {
-		return nil
+		return 0, nil
+	}
+
+	histories, err := diffSubjectDepartmentHistories(nil, daoSubjectDepartments, source)
+	if err != nil {
+		return 0, errorWrapf(err, "diffSubjectDepartmentHistories fail")
 	}
 
-	err = l.departmentManager.BulkCreate(daoSubjectDepartments)
+	departmentRelations, err := l.convertDepartmentRelations(subjectDepartments)
 	if err != nil {
-		return errorWrapf(err, "departmentManager.BulkCreate subjectDepartments=`%+v` fail", daoSubjectDepartments)
+		return 0, errorWrapf(err, "convertDepartmentRelations subjectDepartments=`%+v` fail", subjectDepartments)
 	}
-	return nil
+
+	createdRelations, updatedRelations, err := l.splitDepartmentRelations(departmentRelations)
+	if err != nil {
+		return 0, errorWrapf(err, "splitDepartmentRelations relations=`%+v` fail", departmentRelations)
+	}
+
+	tx, err := database.GenerateDefaultDBTx()
+	defer database.RollBackWithLog(tx)
+	if err != nil {
+		return 0, errorWrapf(err, "define tx error")
+	}
+
+	err = l.departmentManager.BulkCreateWithTx(tx, daoSubjectDepartments)
+	if err != nil {
+		return 0, errorWrapf(err, "departmentManager.BulkCreateWithTx subjectDepartments=`%+v` fail", daoSubjectDepartments)
+	}
+
+	err = l.departmentHistoryManager.BulkCreateWithTx(tx, histories)
+	if err != nil {
+		return 0, errorWrapf(err, "departmentHistoryManager.BulkCreateWithTx histories=`%+v` fail", histories)
+	}
+
+	if len(createdRelations) > 0 {
+		err = l.departmentRelationManager.BulkCreateWithTx(tx, createdRelations)
+		if err != nil {
+			return 0, errorWrapf(err, "departmentRelationManager.BulkCreateWithTx relations=`%+v` fail",
+				createdRelations)
+		}
+	}
+
+	if len(updatedRelations) > 0 {
+		err = l.departmentRelationManager.BulkUpdateParentWithTx(tx, updatedRelations)
+		if err != nil {
+			return 0, errorWrapf(err, "departmentRelationManager.BulkUpdateParentWithTx relations=`%+v` fail",
+				updatedRelations)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, errorWrapf(err, "tx commit error")
+	}
+
+	subjectPKs := subjectPKsOfDepartments(daoSubjectDepartments)
+
+	// 部门层级变更后, 变更的部门及其所有下级部门的用户的上级部门都变化了, 需要一并刷新缓存
+	changedDepartmentPKs := make([]int64, 0, len(createdRelations)+len(updatedRelations))
+	for _, r := range createdRelations {
+		changedDepartmentPKs = append(changedDepartmentPKs, r.DepartmentPK)
+	}
+	for _, r := range updatedRelations {
+		changedDepartmentPKs = append(changedDepartmentPKs, r.DepartmentPK)
+	}
+	if len(changedDepartmentPKs) > 0 {
+		userPKs, err := l.listDepartmentUserPKs(changedDepartmentPKs)
+		if err != nil {
+			// NOTE: 已经提交, 不影响本批次的结果, 其他用户的缓存等待过期
+			log.WithError(err).Errorf("listDepartmentUserPKs departmentPKs=`%+v` fail", changedDepartmentPKs)
+		}
+
+		subjectPKSet := util.NewInt64SetWithValues(subjectPKs)
+		for _, pk := range userPKs {
+			if !subjectPKSet.Has(pk) {
+				subjectPKSet.Add(pk)
+				subjectPKs = append(subjectPKs, pk)
+			}
+		}
+	}
+
+	emitSubjectChangeEvent(SubjectChangeEvent{
+		Type:       SubjectChangeEventTypeDepartment,
+		SubjectPKs: subjectPKs,
+	})
+	return len(daoSubjectDepartments), nil
+}
+
+// listDepartmentUserPKs 部门及其所有下级部门的用户
+func (l *subjectService) listDepartmentUserPKs(departmentPKs []int64) ([]int64, error) {
+	descendantPKs, err := l.listDepartmentDescendantPKs(departmentPKs)
+	if err != nil {
+		return nil, err
+	}
+	allDepartmentPKs := append(append([]int64{}, departmentPKs...), descendantPKs...)
+
+	userPKs := []int64{}
+	for i := 0; i < len(allDepartmentPKs); i += effectiveDepartmentChunkSize {
+		end := i + effectiveDepartmentChunkSize
+		if end > len(allDepartmentPKs) {
+			end = len(allDepartmentPKs)
+		}
+
+		subjectDepartments, err := l.departmentManager.ListByDepartmentPKs(allDepartmentPKs[i:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, sd := range subjectDepartments {
+			userPKs = append(userPKs, sd.SubjectPK)
+		}
+	}
+	return userPKs, nil
+}
+
+// listDepartmentDescendantPKs 逐层向下查询部门的所有下级部门, 不包含已有的部门
+func (l *subjectService) listDepartmentDescendantPKs(departmentPKs []int64) ([]int64, error) {
+	// 记录已经出现过的部门, 避免异常数据中的环导致无限查询
+	visited := util.NewInt64SetWithValues(departmentPKs)
+
+	descendantPKs := []int64{}
+	current := departmentPKs
+	for len(current) > 0 {
+		relations, err := l.departmentRelationManager.ListByParentPKs(current)
+		if err != nil {
+			return nil, err
+		}
+
+		current = make([]int64, 0, len(relations))
+		for _, r := range relations {
+			if visited.Has(r.DepartmentPK) {
+				continue
+			}
+			visited.Add(r.DepartmentPK)
+
+			descendantPKs = append(descendantPKs, r.DepartmentPK)
+			current = append(current, r.DepartmentPK)
+		}
+	}
+	return descendantPKs, nil
 }
 
 // BulkDeleteSubjectDepartments ...
-func (l *subjectService) BulkDeleteSubjectDepartments(subjectIDs []string) ([]int64, error) {
+func (l *subjectService) BulkDeleteSubjectDepartments(subjectIDs []string, source string) ([]int64, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "BulkDeleteSubjectDepartments")
 
 	subjects := make([]types.Subject, 0, len(subjectIDs))
@@ -74,15 +322,48 @@ func (l *subjectService) BulkDeleteSubjectDepartments(subjectIDs []string) ([]in
 		return pks, nil
 	}
 
-	err = l.departmentManager.BulkDelete(pks)
+	oldSubjectDepartments, err := l.departmentManager.ListBySubjectPKs(pks)
 	if err != nil {
-		return pks, errorWrapf(err, "departmentManager.BulkDelete pks=`%+v` fail", pks)
+		return pks, errorWrapf(err, "departmentManager.ListBySubjectPKs pks=`%+v` fail", pks)
 	}
+
+	histories, err := diffSubjectDepartmentHistories(oldSubjectDepartments, nil, source)
+	if err != nil {
+		return pks, errorWrapf(err, "diffSubjectDepartmentHistories fail")
+	}
+
+	tx, err := database.GenerateDefaultDBTx()
+	defer database.RollBackWithLog(tx)
+	if err != nil {
+		return pks, errorWrapf(err, "define tx error")
+	}
+
+	err = l.departmentManager.BulkDeleteWithTx(tx, pks)
+	if err != nil {
+		return pks, errorWrapf(err, "departmentManager.BulkDeleteWithTx pks=`%+v` fail", pks)
+	}
+
+	err = l.departmentHistoryManager.BulkCreateWithTx(tx, histories)
+	if err != nil {
+		return pks, errorWrapf(err, "departmentHistoryManager.BulkCreateWithTx histories=`%+v` fail", histories)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return pks, errorWrapf(err, "tx commit error")
+	}
+
+	emitSubjectChangeEvent(SubjectChangeEvent{
+		Type:       SubjectChangeEventTypeDepartment,
+		SubjectPKs: pks,
+	})
 	return pks, err
 }
 
 // BulkUpdateSubjectDepartments ...
-func (l *subjectService) BulkUpdateSubjectDepartments(subjectDepartments []types.SubjectDepartment) ([]int64, error) {
+func (l *subjectService) BulkUpdateSubjectDepartments(
+	subjectDepartments []types.SubjectDepartment, source string,
+) ([]int64, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "BulkUpdateSubjectDepartments")
 	daoSubjectDepartments, err := l.convertSubjectDepartments(subjectDepartments)
 	if err != nil {
@@ -93,18 +374,121 @@ func (l *subjectService) BulkUpdateSubjectDepartments(subjectDepartments []types
 		return nil, nil
 	}
 
-	err = l.departmentManager.BulkUpdate(daoSubjectDepartments)
+	pks := subjectPKsOfDepartments(daoSubjectDepartments)
+	oldSubjectDepartments, err := l.departmentManager.ListBySubjectPKs(pks)
 	if err != nil {
-		return nil, errorWrapf(err, "departmentManager.BulkUpdate subjectDepartments=`%+v` fail", daoSubjectDepartments)
+		return nil, errorWrapf(err, "departmentManager.ListBySubjectPKs pks=`%+v` fail", pks)
 	}
 
-	pks := make([]int64, 0, len(daoSubjectDepartments))
-	for _, sd := range daoSubjectDepartments {
-		pks = append(pks, sd.SubjectPK)
+	histories, err := diffSubjectDepartmentHistories(oldSubjectDepartments, daoSubjectDepartments, source)
+	if err != nil {
+		return nil, errorWrapf(err, "diffSubjectDepartmentHistories fail")
+	}
+
+	tx, err := database.GenerateDefaultDBTx()
+	defer database.RollBackWithLog(tx)
+	if err != nil {
+		return nil, errorWrapf(err, "define tx error")
+	}
+
+	err = l.departmentManager.BulkUpdateWithTx(tx, daoSubjectDepartments)
+	if err != nil {
+		return nil, errorWrapf(err, "departmentManager.BulkUpdateWithTx subjectDepartments=`%+v` fail",
+			daoSubjectDepartments)
 	}
+
+	err = l.departmentHistoryManager.BulkCreateWithTx(tx, histories)
+	if err != nil {
+		return nil, errorWrapf(err, "departmentHistoryManager.BulkCreateWithTx histories=`%+v` fail", histories)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errorWrapf(err, "tx commit error")
+	}
+
+	emitSubjectChangeEvent(SubjectChangeEvent{
+		Type:       SubjectChangeEventTypeDepartment,
+		SubjectPKs: pks,
+	})
 	return pks, nil
 }
 
+// diffSubjectDepartmentHistories 对比变更前后的部门, 生成新增/移除的变更记录
+func diffSubjectDepartmentHistories(
+	oldSubjectDepartments, newSubjectDepartments []dao.SubjectDepartment, source string,
+) ([]dao.SubjectDepartmentHistory, error) {
+	oldDepartmentPKs := make(map[int64]*util.Int64Set, len(oldSubjectDepartments))
+	for _, sd := range oldSubjectDepartments {
+		departmentPKs, err := util.StringToInt64Slice(sd.DepartmentPKs, ",")
+		if err != nil {
+			return nil, fmt.Errorf("util.StringToInt64Slice s=`%s` fail, %w", sd.DepartmentPKs, err)
+		}
+		oldDepartmentPKs[sd.SubjectPK] = util.NewInt64SetWithValues(departmentPKs)
+	}
+
+	newDepartmentPKs := make(map[int64]*util.Int64Set, len(newSubjectDepartments))
+	for _, sd := range newSubjectDepartments {
+		departmentPKs, err := util.StringToInt64Slice(sd.DepartmentPKs, ",")
+		if err != nil {
+			return nil, fmt.Errorf("util.StringToInt64Slice s=`%s` fail, %w", sd.DepartmentPKs, err)
+		}
+		newDepartmentPKs[sd.SubjectPK] = util.NewInt64SetWithValues(departmentPKs)
+	}
+
+	histories := []dao.SubjectDepartmentHistory{}
+	for subjectPK, newSet := range newDepartmentPKs {
+		oldSet := oldDepartmentPKs[subjectPK]
+		for _, departmentPK := range newSet.ToSlice() {
+			if oldSet == nil || !oldSet.Has(departmentPK) {
+				histories = append(histories, dao.SubjectDepartmentHistory{
+					SubjectPK:    subjectPK,
+					DepartmentPK: departmentPK,
+					Action:       SubjectDepartmentHistoryActionAdded,
+					Source:       source,
+				})
+			}
+		}
+	}
+	for subjectPK, oldSet := range oldDepartmentPKs {
+		newSet := newDepartmentPKs[subjectPK]
+		// 不在本次变更中的subject, 部门不变
+		if newSubjectDepartments != nil && newSet == nil {
+			continue
+		}
+		for _, departmentPK := range oldSet.ToSlice() {
+			if newSet == nil || !newSet.Has(departmentPK) {
+				histories = append(histories, dao.SubjectDepartmentHistory{
+					SubjectPK:    subjectPK,
+					DepartmentPK: departmentPK,
+					Action:       SubjectDepartmentHistoryActionRemoved,
+					Source:       source,
+				})
+			}
+		}
+	}
+
+	// 保证同一批次的记录顺序稳定
+	sort.Slice(histories, func(i, j int) bool {
+		if histories[i].SubjectPK != histories[j].SubjectPK {
+			return histories[i].SubjectPK < histories[j].SubjectPK
+		}
+		if histories[i].Action != histories[j].Action {
+			return histories[i].Action < histories[j].Action
+		}
+		return histories[i].DepartmentPK < histories[j].DepartmentPK
+	})
+	return histories, nil
+}
+
+func subjectPKsOfDepartments(subjectDepartments []dao.SubjectDepartment) []int64 {
+	pks := make([]int64, 0, len(subjectDepartments))
+	for _, sd := range subjectDepartments {
+		pks = append(pks, sd.SubjectPK)
+	}
+	return pks
+}
+
 // ListPagingSubjectDepartment ...
 func (l *subjectService) ListPagingSubjectDepartment(limit, offset int64) ([]types.SubjectDepartment, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "ListPagingSubjectDepartment")
@@ -173,6 +557,61 @@ func (l *subjectService) GetSubjectDepartmentCount() (int64, error) {
 	return count, err
 }
 
+// GetSubjectDepartmentHistoryCount ...
+func (l *subjectService) GetSubjectDepartmentHistoryCount(subjectPK int64) (int64, error) {
+	count, err := l.departmentHistoryManager.GetCountBySubjectPK(subjectPK)
+	if err != nil {
+		return count, errorx.Wrapf(err, SubjectSVC, "GetSubjectDepartmentHistoryCount",
+			"departmentHistoryManager.GetCountBySubjectPK subjectPK=`%d` fail", subjectPK)
+	}
+	return count, nil
+}
+
+// ListPagingSubjectDepartmentHistory 查询用户的部门变更记录, 最近的在前
+func (l *subjectService) ListPagingSubjectDepartmentHistory(
+	subjectPK int64, limit, offset int64,
+) ([]types.SubjectDepartmentHistory, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "ListPagingSubjectDepartmentHistory")
+	daoHistories, err := l.departmentHistoryManager.ListPagingBySubjectPK(subjectPK, limit, offset)
+	if err != nil {
+		return nil, errorWrapf(err, "departmentHistoryManager.ListPagingBySubjectPK subjectPK=`%d`, "+
+			"limit=`%d`, offset=`%d` fail", subjectPK, limit, offset)
+	}
+
+	if len(daoHistories) == 0 {
+		return []types.SubjectDepartmentHistory{}, nil
+	}
+
+	departmentPKSet := util.NewInt64Set()
+	for _, h := range daoHistories {
+		departmentPKSet.Add(h.DepartmentPK)
+	}
+	departmentPKs := departmentPKSet.ToSlice()
+
+	departments, err := l.manager.ListByPKs(departmentPKs)
+	if err != nil {
+		return nil, errorWrapf(err, "manager.ListByPKs pks=`%+v` fail", departmentPKs)
+	}
+	departmentMap := make(map[int64]dao.Subject, len(departments))
+	for _, d := range departments {
+		departmentMap[d.PK] = d
+	}
+
+	histories := make([]types.SubjectDepartmentHistory, 0, len(daoHistories))
+	for _, h := range daoHistories {
+		// NOTE: the department may be deleted, keep the history with empty id/name
+		department := departmentMap[h.DepartmentPK]
+		histories = append(histories, types.SubjectDepartmentHistory{
+			DepartmentID:   department.ID,
+			DepartmentName: department.Name,
+			Action:         h.Action,
+			Source:         h.Source,
+			CreatedAt:      h.CreatedAt,
+		})
+	}
+	return histories, nil
+}
+
 func (l *subjectService) convertSubjectDepartments(
 	subjectDepartments []types.SubjectDepartment) ([]dao.SubjectDepartment, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "convertSubjectDepartments")
@@ -222,6 +661,93 @@ func (l *subjectService) convertSubjectDepartments(
 	return daoSubjectDepartment, nil
 }
 
+// convertDepartmentRelations 转换部门的上级关系, 同一部门出现多次时以最后一次为准, 忽略不存在的部门
+func (l *subjectService) convertDepartmentRelations(
+	subjectDepartments []types.SubjectDepartment,
+) ([]dao.DepartmentRelation, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "convertDepartmentRelations")
+
+	parentIDs := map[string]string{}
+	departmentIDSet := util.NewStringSet()
+	for _, sd := range subjectDepartments {
+		for _, dp := range sd.DepartmentParents {
+			// 部门不能是自己的上级部门
+			if dp.ID == dp.ParentID {
+				continue
+			}
+			parentIDs[dp.ID] = dp.ParentID
+			departmentIDSet.Add(dp.ID)
+			departmentIDSet.Add(dp.ParentID)
+		}
+	}
+
+	if len(parentIDs) == 0 {
+		return nil, nil
+	}
+
+	departmentIDs := departmentIDSet.ToSlice()
+	departments, err := l.manager.ListByIDs(types.DepartmentType, departmentIDs)
+	if err != nil {
+		return nil, errorWrapf(err, "manager.ListByIDs type=`%s`, ids=`%+v` fail", types.DepartmentType, departmentIDs)
+	}
+	departmentMap := convertSubjectsToMap(departments)
+
+	relations := make([]dao.DepartmentRelation, 0, len(parentIDs))
+	for id, parentID := range parentIDs {
+		departmentPK, ok := departmentMap.Get(types.DepartmentType, id)
+		if !ok {
+			continue
+		}
+		parentPK, ok := departmentMap.Get(types.DepartmentType, parentID)
+		if !ok {
+			continue
+		}
+		relations = append(relations, dao.DepartmentRelation{
+			DepartmentPK: departmentPK,
+			ParentPK:     parentPK,
+		})
+	}
+
+	// 保证同一批次的写入顺序稳定
+	sort.Slice(relations, func(i, j int) bool {
+		return relations[i].DepartmentPK < relations[j].DepartmentPK
+	})
+	return relations, nil
+}
+
+// splitDepartmentRelations 按已有的部门上级关系, 区分需要新建和需要变更上级的关系, 上级不变的忽略
+func (l *subjectService) splitDepartmentRelations(
+	relations []dao.DepartmentRelation,
+) (created, updated []dao.DepartmentRelation, err error) {
+	if len(relations) == 0 {
+		return
+	}
+
+	departmentPKs := make([]int64, 0, len(relations))
+	for _, r := range relations {
+		departmentPKs = append(departmentPKs, r.DepartmentPK)
+	}
+
+	oldRelations, err := l.departmentRelationManager.ListByDepartmentPKs(departmentPKs)
+	if err != nil {
+		return nil, nil, err
+	}
+	oldParentPKs := make(map[int64]int64, len(oldRelations))
+	for _, r := range oldRelations {
+		oldParentPKs[r.DepartmentPK] = r.ParentPK
+	}
+
+	for _, r := range relations {
+		parentPK, ok := oldParentPKs[r.DepartmentPK]
+		if !ok {
+			created = append(created, r)
+		} else if parentPK != r.ParentPK {
+			updated = append(updated, r)
+		}
+	}
+	return created, updated, nil
+}
+
 type subjectPKMap map[string]int64
 
 // Get ...
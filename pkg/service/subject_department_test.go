@@ -0,0 +1,331 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectDepartment", func() {
+	Describe("diffSubjectDepartmentHistories", func() {
+		It("create", func() {
+			histories, err := diffSubjectDepartmentHistories(nil, []dao.SubjectDepartment{
+				{SubjectPK: 1, DepartmentPKs: "10,11"},
+			}, "bk_iam")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []dao.SubjectDepartmentHistory{
+				{SubjectPK: 1, DepartmentPK: 10, Action: SubjectDepartmentHistoryActionAdded, Source: "bk_iam"},
+				{SubjectPK: 1, DepartmentPK: 11, Action: SubjectDepartmentHistoryActionAdded, Source: "bk_iam"},
+			}, histories)
+		})
+
+		It("update", func() {
+			histories, err := diffSubjectDepartmentHistories([]dao.SubjectDepartment{
+				{SubjectPK: 1, DepartmentPKs: "10,11"},
+				{SubjectPK: 2, DepartmentPKs: "10"},
+			}, []dao.SubjectDepartment{
+				{SubjectPK: 1, DepartmentPKs: "11,12"},
+				{SubjectPK: 2, DepartmentPKs: "10"},
+			}, "bk_iam")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []dao.SubjectDepartmentHistory{
+				{SubjectPK: 1, DepartmentPK: 12, Action: SubjectDepartmentHistoryActionAdded, Source: "bk_iam"},
+				{SubjectPK: 1, DepartmentPK: 10, Action: SubjectDepartmentHistoryActionRemoved, Source: "bk_iam"},
+			}, histories)
+		})
+
+		It("delete", func() {
+			histories, err := diffSubjectDepartmentHistories([]dao.SubjectDepartment{
+				{SubjectPK: 1, DepartmentPKs: "10"},
+			}, nil, "bk_iam")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []dao.SubjectDepartmentHistory{
+				{SubjectPK: 1, DepartmentPK: 10, Action: SubjectDepartmentHistoryActionRemoved, Source: "bk_iam"},
+			}, histories)
+		})
+
+		It("invalid department pks", func() {
+			_, err := diffSubjectDepartmentHistories(nil, []dao.SubjectDepartment{
+				{SubjectPK: 1, DepartmentPKs: "a"},
+			}, "bk_iam")
+			assert.Error(GinkgoT(), err)
+		})
+	})
+
+	Describe("BulkCreateSubjectDepartments", func() {
+		var ctl *gomock.Controller
+		var patches *gomonkey.Patches
+		var mockManager *mock.MockSubjectManager
+		var mockDepartmentManager *mock.MockSubjectDepartmentManager
+		var svc *subjectService
+		var events []SubjectChangeEvent
+
+		subjectDepartments := []types.SubjectDepartment{
+			{SubjectID: "tom", DepartmentIDs: []string{"d1"}},
+			{SubjectID: "jerry", DepartmentIDs: []string{"d1"}},
+		}
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			subjectDepartmentChunkSize = 1
+			subjectDepartmentChunkMaxInterval = 0
+
+			mockManager = mock.NewMockSubjectManager(ctl)
+			mockManager.EXPECT().ListByIDs(types.DepartmentType, []string{"d1"}).Return(
+				[]dao.Subject{{PK: 10, Type: types.DepartmentType, ID: "d1"}}, nil,
+			).AnyTimes()
+
+			mockDepartmentManager = mock.NewMockSubjectDepartmentManager(ctl)
+			mockDepartmentManager.EXPECT().BulkCreateWithTx(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			mockHistoryManager := mock.NewMockSubjectDepartmentHistoryManager(ctl)
+			mockHistoryManager.EXPECT().BulkCreateWithTx(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+			svc = &subjectService{
+				manager:                  mockManager,
+				departmentManager:        mockDepartmentManager,
+				departmentHistoryManager: mockHistoryManager,
+			}
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.MatchExpectationsInOrder(false)
+			for i := 0; i < len(subjectDepartments); i++ {
+				dbMock.ExpectBegin()
+				dbMock.ExpectCommit()
+			}
+			patches = gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+
+			events = nil
+			patches.ApplyFunc(emitSubjectChangeEvent, func(event SubjectChangeEvent) {
+				events = append(events, event)
+			})
+		})
+
+		AfterEach(func() {
+			subjectDepartmentChunkSize = 1000
+			subjectDepartmentChunkMaxInterval = 1 * time.Second
+			ctl.Finish()
+			patches.Reset()
+		})
+
+		It("ok", func() {
+			mockManager.EXPECT().ListByIDs(types.UserType, []string{"tom"}).Return(
+				[]dao.Subject{{PK: 1, Type: types.UserType, ID: "tom"}}, nil,
+			)
+			mockManager.EXPECT().ListByIDs(types.UserType, []string{"jerry"}).Return(
+				[]dao.Subject{{PK: 2, Type: types.UserType, ID: "jerry"}}, nil,
+			)
+
+			result, err := svc.BulkCreateSubjectDepartments(subjectDepartments, "bk_iam")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), 2, result.Total)
+			assert.Equal(GinkgoT(), 2, result.Created)
+			assert.Len(GinkgoT(), result.Failed, 0)
+		})
+
+		It("partial fail", func() {
+			mockManager.EXPECT().ListByIDs(types.UserType, []string{"tom"}).Return(
+				[]dao.Subject{{PK: 1, Type: types.UserType, ID: "tom"}}, nil,
+			)
+			mockManager.EXPECT().ListByIDs(types.UserType, []string{"jerry"}).Return(
+				nil, errors.New("error"),
+			)
+
+			result, err := svc.BulkCreateSubjectDepartments(subjectDepartments, "bk_iam")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), 2, result.Total)
+			assert.Equal(GinkgoT(), 1, result.Created)
+			assert.Len(GinkgoT(), result.Failed, 1)
+			assert.Equal(GinkgoT(), []string{"jerry"}, result.Failed[0].SubjectIDs)
+		})
+
+		It("all fail", func() {
+			mockManager.EXPECT().ListByIDs(types.UserType, gomock.Any()).Return(
+				nil, errors.New("error"),
+			).Times(2)
+
+			_, err := svc.BulkCreateSubjectDepartments(subjectDepartments, "bk_iam")
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("with department parents", func() {
+			mockManager.EXPECT().ListByIDs(types.UserType, []string{"tom"}).Return(
+				[]dao.Subject{{PK: 1, Type: types.UserType, ID: "tom"}}, nil,
+			)
+			mockManager.EXPECT().ListByIDs(types.DepartmentType, gomock.Any()).Return(
+				[]dao.Subject{
+					{PK: 10, Type: types.DepartmentType, ID: "d1"},
+					{PK: 20, Type: types.DepartmentType, ID: "d0"},
+				}, nil,
+			)
+
+			mockRelationManager := mock.NewMockDepartmentRelationManager(ctl)
+			mockRelationManager.EXPECT().ListByDepartmentPKs([]int64{10}).Return(nil, nil)
+			mockRelationManager.EXPECT().BulkCreateWithTx(gomock.Any(), []dao.DepartmentRelation{
+				{DepartmentPK: 10, ParentPK: 20},
+			}).Return(nil)
+			mockRelationManager.EXPECT().ListByParentPKs([]int64{10}).Return(
+				[]dao.DepartmentRelation{{DepartmentPK: 11, ParentPK: 10}}, nil,
+			)
+			mockRelationManager.EXPECT().ListByParentPKs([]int64{11}).Return(nil, nil)
+			svc.departmentRelationManager = mockRelationManager
+
+			mockDepartmentManager.EXPECT().ListByDepartmentPKs([]int64{10, 11}).Return(
+				[]dao.SubjectDepartment{{SubjectPK: 1, DepartmentPKs: "10"}, {SubjectPK: 3, DepartmentPKs: "11"}}, nil,
+			)
+
+			result, err := svc.BulkCreateSubjectDepartments([]types.SubjectDepartment{{
+				SubjectID:         "tom",
+				DepartmentIDs:     []string{"d1"},
+				DepartmentParents: []types.DepartmentParent{{ID: "d1", ParentID: "d0"}},
+			}}, "bk_iam")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), 1, result.Created)
+			assert.Len(GinkgoT(), result.Failed, 0)
+			assert.Equal(GinkgoT(), []SubjectChangeEvent{
+				{Type: SubjectChangeEventTypeDepartment, SubjectPKs: []int64{1, 3}},
+			}, events)
+		})
+
+		It("with department parents list users fail", func() {
+			mockManager.EXPECT().ListByIDs(types.UserType, []string{"tom"}).Return(
+				[]dao.Subject{{PK: 1, Type: types.UserType, ID: "tom"}}, nil,
+			)
+			mockManager.EXPECT().ListByIDs(types.DepartmentType, gomock.Any()).Return(
+				[]dao.Subject{
+					{PK: 10, Type: types.DepartmentType, ID: "d1"},
+					{PK: 20, Type: types.DepartmentType, ID: "d0"},
+				}, nil,
+			)
+
+			mockRelationManager := mock.NewMockDepartmentRelationManager(ctl)
+			mockRelationManager.EXPECT().ListByDepartmentPKs([]int64{10}).Return(nil, nil)
+			mockRelationManager.EXPECT().BulkCreateWithTx(gomock.Any(), gomock.Any()).Return(nil)
+			mockRelationManager.EXPECT().ListByParentPKs([]int64{10}).Return(nil, errors.New("error"))
+			svc.departmentRelationManager = mockRelationManager
+
+			result, err := svc.BulkCreateSubjectDepartments([]types.SubjectDepartment{{
+				SubjectID:         "tom",
+				DepartmentIDs:     []string{"d1"},
+				DepartmentParents: []types.DepartmentParent{{ID: "d1", ParentID: "d0"}},
+			}}, "bk_iam")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), 1, result.Created)
+			assert.Equal(GinkgoT(), []SubjectChangeEvent{
+				{Type: SubjectChangeEventTypeDepartment, SubjectPKs: []int64{1}},
+			}, events)
+		})
+	})
+
+	Describe("GetSubjectDepartmentPKs", func() {
+		var ctl *gomock.Controller
+		var mockDepartmentManager *mock.MockSubjectDepartmentManager
+		var mockRelationManager *mock.MockDepartmentRelationManager
+		var svc *subjectService
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			mockDepartmentManager = mock.NewMockSubjectDepartmentManager(ctl)
+			mockRelationManager = mock.NewMockDepartmentRelationManager(ctl)
+			svc = &subjectService{
+				departmentManager:         mockDepartmentManager,
+				departmentRelationManager: mockRelationManager,
+			}
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("no department", func() {
+			mockDepartmentManager.EXPECT().Get(int64(1)).Return("", nil)
+
+			pks, err := svc.GetSubjectDepartmentPKs(1)
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), pks, 0)
+		})
+
+		It("without hierarchy", func() {
+			mockDepartmentManager.EXPECT().Get(int64(1)).Return("10,11", nil)
+			mockRelationManager.EXPECT().ListByDepartmentPKs([]int64{10, 11}).Return(nil, nil)
+
+			pks, err := svc.GetSubjectDepartmentPKs(1)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []int64{10, 11}, pks)
+		})
+
+		It("with ancestors", func() {
+			mockDepartmentManager.EXPECT().Get(int64(1)).Return("10,11", nil)
+			mockRelationManager.EXPECT().ListByDepartmentPKs([]int64{10, 11}).Return([]dao.DepartmentRelation{
+				{DepartmentPK: 10, ParentPK: 20},
+				{DepartmentPK: 11, ParentPK: 10},
+			}, nil)
+			mockRelationManager.EXPECT().ListByDepartmentPKs([]int64{20}).Return([]dao.DepartmentRelation{
+				{DepartmentPK: 20, ParentPK: 30},
+			}, nil)
+			// the dirty data with cycle
+			mockRelationManager.EXPECT().ListByDepartmentPKs([]int64{30}).Return([]dao.DepartmentRelation{
+				{DepartmentPK: 30, ParentPK: 10},
+			}, nil)
+
+			pks, err := svc.GetSubjectDepartmentPKs(1)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []int64{10, 11, 20, 30}, pks)
+		})
+
+		It("list relation fail", func() {
+			mockDepartmentManager.EXPECT().Get(int64(1)).Return("10", nil)
+			mockRelationManager.EXPECT().ListByDepartmentPKs([]int64{10}).Return(nil, errors.New("error"))
+
+			_, err := svc.GetSubjectDepartmentPKs(1)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "listDepartmentAncestorPKs")
+		})
+	})
+
+	Describe("splitDepartmentRelations", func() {
+		var ctl *gomock.Controller
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("ok", func() {
+			mockRelationManager := mock.NewMockDepartmentRelationManager(ctl)
+			mockRelationManager.EXPECT().ListByDepartmentPKs([]int64{10, 11, 12}).Return([]dao.DepartmentRelation{
+				{DepartmentPK: 10, ParentPK: 20},
+				{DepartmentPK: 11, ParentPK: 20},
+			}, nil)
+
+			svc := &subjectService{departmentRelationManager: mockRelationManager}
+			created, updated, err := svc.splitDepartmentRelations([]dao.DepartmentRelation{
+				{DepartmentPK: 10, ParentPK: 20},
+				{DepartmentPK: 11, ParentPK: 21},
+				{DepartmentPK: 12, ParentPK: 20},
+			})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []dao.DepartmentRelation{{DepartmentPK: 12, ParentPK: 20}}, created)
+			assert.Equal(GinkgoT(), []dao.DepartmentRelation{{DepartmentPK: 11, ParentPK: 21}}, updated)
+		})
+	})
+})
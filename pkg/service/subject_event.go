@@ -0,0 +1,71 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"sync"
+
+	"iam/pkg/service/types"
+)
+
+// SubjectChangeEvent的类型
+const (
+	SubjectChangeEventTypeSubject    = "subject"
+	SubjectChangeEventTypeMember     = "member"
+	SubjectChangeEventTypeDepartment = "department"
+	SubjectChangeEventTypeRole       = "role"
+	// subject_system_group的记录变更, 只需要清理对应subject的缓存
+	SubjectChangeEventTypeSystemGroup = "system_group"
+)
+
+// SubjectChangeEvent subject写操作成功后发出的变更事件, 用于读侧(缓存等)失效
+// SubjectPKs 和 Subjects 至少有一个非空, 处理方需要自行将 Subjects 转换为 pk
+type SubjectChangeEvent struct {
+	Type       string
+	SubjectPKs []int64
+	Subjects   []types.Subject
+
+	// 仅member类型的事件: 成员变更的用户组, 以及成员数量的变化
+	Group       *types.Subject
+	MemberDelta int64
+	// 仅member类型的新增事件: 新增成员的过期时间
+	PolicyExpiredAt int64
+}
+
+// SubjectChangeHandler ...
+type SubjectChangeHandler func(event SubjectChangeEvent)
+
+var (
+	subjectChangeHandlersLock sync.RWMutex
+	subjectChangeHandlers     []SubjectChangeHandler
+)
+
+// RegisterSubjectChangeHandler 注册subject变更事件的处理函数, 一般在初始化缓存时注册
+func RegisterSubjectChangeHandler(handler SubjectChangeHandler) {
+	subjectChangeHandlersLock.Lock()
+	subjectChangeHandlers = append(subjectChangeHandlers, handler)
+	subjectChangeHandlersLock.Unlock()
+}
+
+func emitSubjectChangeEvent(event SubjectChangeEvent) {
+	if len(event.SubjectPKs) == 0 && len(event.Subjects) == 0 {
+		return
+	}
+
+	// NOTE: 处理函数中可能再次发出事件(如subject_system_group的维护), 不能在持有锁时调用
+	subjectChangeHandlersLock.RLock()
+	handlers := subjectChangeHandlers
+	subjectChangeHandlersLock.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"database/sql"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectChangeEvent", func() {
+	var events []SubjectChangeEvent
+	var oldHandlers []SubjectChangeHandler
+	BeforeEach(func() {
+		events = nil
+		oldHandlers = subjectChangeHandlers
+		subjectChangeHandlers = nil
+
+		RegisterSubjectChangeHandler(func(event SubjectChangeEvent) {
+			events = append(events, event)
+		})
+	})
+	AfterEach(func() {
+		subjectChangeHandlers = oldHandlers
+	})
+
+	It("emit", func() {
+		emitSubjectChangeEvent(SubjectChangeEvent{
+			Type:       SubjectChangeEventTypeDepartment,
+			SubjectPKs: []int64{1, 2},
+		})
+		assert.Len(GinkgoT(), events, 1)
+		assert.Equal(GinkgoT(), []int64{1, 2}, events[0].SubjectPKs)
+	})
+
+	It("emit empty", func() {
+		emitSubjectChangeEvent(SubjectChangeEvent{Type: SubjectChangeEventTypeMember})
+		assert.Empty(GinkgoT(), events)
+	})
+
+	It("UpdateMembersExpiredAt emit member subject pks", func() {
+		ctl := gomock.NewController(GinkgoT())
+		defer ctl.Finish()
+
+		mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+		mockRelationManager.EXPECT().ListRelationByPKs([]int64{10}).Return([]dao.SubjectRelation{
+			{PK: 10, SubjectPK: 2, ParentPK: 1, PolicyExpiredAt: 50},
+		}, nil)
+		mockRelationManager.EXPECT().UpdateExpiredAt([]dao.SubjectRelationPKPolicyExpiredAt{
+			{PK: 10, PolicyExpiredAt: 100},
+		}).Return(nil)
+		mockGroupSettingManager := mock.NewMockGroupSettingManager(ctl)
+		mockGroupSettingManager.EXPECT().ListByGroupPKs([]int64{1}).Return(nil, nil)
+
+		svc := &subjectService{
+			relationManager:     mockRelationManager,
+			groupSettingManager: mockGroupSettingManager,
+		}
+		err := svc.UpdateMembersExpiredAt([]types.SubjectMember{
+			{PK: 10, Type: "user", ID: "admin", PolicyExpiredAt: 100},
+		})
+		assert.NoError(GinkgoT(), err)
+		assert.Len(GinkgoT(), events, 1)
+		assert.Equal(GinkgoT(), SubjectChangeEventTypeMember, events[0].Type)
+		assert.Equal(GinkgoT(), []int64{2}, events[0].SubjectPKs)
+		assert.Empty(GinkgoT(), events[0].Subjects)
+	})
+
+	It("BulkCreate emit subjects", func() {
+		ctl := gomock.NewController(GinkgoT())
+		defer ctl.Finish()
+
+		mockManager := mock.NewMockSubjectManager(ctl)
+		mockManager.EXPECT().BulkCreate([]dao.Subject{{Type: "user", ID: "admin", Name: "admin"}}).Return(nil)
+
+		svc := &subjectService{
+			manager: mockManager,
+		}
+		err := svc.BulkCreate([]types.Subject{{Type: "user", ID: "admin", Name: "admin"}})
+		assert.NoError(GinkgoT(), err)
+		assert.Len(GinkgoT(), events, 1)
+		assert.Equal(GinkgoT(), SubjectChangeEventTypeSubject, events[0].Type)
+		assert.Equal(GinkgoT(), []types.Subject{{Type: "user", ID: "admin", Name: "admin"}}, events[0].Subjects)
+	})
+
+	It("BulkCreateSubjectMembers emit group member delta", func() {
+		ctl := gomock.NewController(GinkgoT())
+		defer ctl.Finish()
+
+		mockManager := mock.NewMockSubjectManager(ctl)
+		mockManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+		mockManager.EXPECT().ListByIDs("user", []string{"admin"}).Return([]dao.Subject{
+			{PK: 2, Type: "user", ID: "admin"},
+		}, nil)
+		mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+		mockRelationManager.EXPECT().BulkCreate(gomock.Any()).Return(nil)
+		mockGroupSettingManager := mock.NewMockGroupSettingManager(ctl)
+		mockGroupSettingManager.EXPECT().Get(int64(1)).Return(dao.GroupSetting{}, sql.ErrNoRows)
+
+		svc := &subjectService{
+			manager:             mockManager,
+			relationManager:     mockRelationManager,
+			groupSettingManager: mockGroupSettingManager,
+		}
+		err := svc.BulkCreateSubjectMembers("group", "1", []types.Subject{{Type: "user", ID: "admin"}}, 100)
+		assert.NoError(GinkgoT(), err)
+		assert.Len(GinkgoT(), events, 1)
+		assert.Equal(GinkgoT(), &types.Subject{Type: "group", ID: "1"}, events[0].Group)
+		assert.Equal(GinkgoT(), int64(1), events[0].MemberDelta)
+		assert.Equal(GinkgoT(), int64(100), events[0].PolicyExpiredAt)
+	})
+})
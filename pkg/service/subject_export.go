@@ -0,0 +1,113 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"iam/pkg/database/dao"
+	"iam/pkg/errorx"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// SubjectExportSVC is the layer-object name
+const SubjectExportSVC = "SubjectExportSVC"
+
+// SubjectExportService provide the func for exporting subject relations
+type SubjectExportService interface {
+	ListSubjectAfterPK(afterPK, limit int64) ([]types.ExportSubject, error)
+	ListRelationAfterPK(afterPK, limit int64) ([]types.ExportSubjectRelation, error)
+	ListSubjectDepartmentAfterPK(afterPK, limit int64) ([]types.ExportSubjectDepartment, error)
+}
+
+type subjectExportService struct {
+	manager dao.SubjectExportManager
+}
+
+// NewSubjectExportService create the SubjectExportService
+func NewSubjectExportService() SubjectExportService {
+	return &subjectExportService{
+		manager: dao.NewSubjectExportManager(),
+	}
+}
+
+// ListSubjectAfterPK ...
+func (s *subjectExportService) ListSubjectAfterPK(afterPK, limit int64) ([]types.ExportSubject, error) {
+	subjects, err := s.manager.ListSubjectAfterPK(afterPK, limit)
+	if err != nil {
+		return nil, errorx.Wrapf(err, SubjectExportSVC, "ListSubjectAfterPK",
+			"manager.ListSubjectAfterPK afterPK=`%d`, limit=`%d` fail", afterPK, limit)
+	}
+
+	exportSubjects := make([]types.ExportSubject, 0, len(subjects))
+	for _, subject := range subjects {
+		exportSubjects = append(exportSubjects, types.ExportSubject{
+			PK:   subject.PK,
+			Type: subject.Type,
+			ID:   subject.ID,
+			Name: subject.Name,
+		})
+	}
+	return exportSubjects, nil
+}
+
+// ListRelationAfterPK ...
+func (s *subjectExportService) ListRelationAfterPK(afterPK, limit int64) ([]types.ExportSubjectRelation, error) {
+	relations, err := s.manager.ListRelationAfterPK(afterPK, limit)
+	if err != nil {
+		return nil, errorx.Wrapf(err, SubjectExportSVC, "ListRelationAfterPK",
+			"manager.ListRelationAfterPK afterPK=`%d`, limit=`%d` fail", afterPK, limit)
+	}
+
+	exportRelations := make([]types.ExportSubjectRelation, 0, len(relations))
+	for _, r := range relations {
+		exportRelations = append(exportRelations, types.ExportSubjectRelation{
+			PK:              r.PK,
+			SubjectPK:       r.SubjectPK,
+			SubjectType:     r.SubjectType,
+			SubjectID:       r.SubjectID,
+			ParentPK:        r.ParentPK,
+			ParentType:      r.ParentType,
+			ParentID:        r.ParentID,
+			PolicyExpiredAt: r.PolicyExpiredAt,
+		})
+	}
+	return exportRelations, nil
+}
+
+// ListSubjectDepartmentAfterPK ...
+func (s *subjectExportService) ListSubjectDepartmentAfterPK(
+	afterPK, limit int64,
+) ([]types.ExportSubjectDepartment, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectExportSVC, "ListSubjectDepartmentAfterPK")
+
+	subjectDepartments, err := s.manager.ListSubjectDepartmentAfterPK(afterPK, limit)
+	if err != nil {
+		return nil, errorWrapf(err, "manager.ListSubjectDepartmentAfterPK afterPK=`%d`, limit=`%d` fail",
+			afterPK, limit)
+	}
+
+	exportSubjectDepartments := make([]types.ExportSubjectDepartment, 0, len(subjectDepartments))
+	for _, sd := range subjectDepartments {
+		departmentPKs, err := util.StringToInt64Slice(sd.DepartmentPKs, ",")
+		if err != nil {
+			return nil, errorWrapf(err, "util.StringToInt64Slice s=`%s` fail", sd.DepartmentPKs)
+		}
+
+		exportSubjectDepartments = append(exportSubjectDepartments, types.ExportSubjectDepartment{
+			PK:            sd.PK,
+			SubjectPK:     sd.SubjectPK,
+			DepartmentPKs: departmentPKs,
+		})
+	}
+	return exportSubjectDepartments, nil
+}
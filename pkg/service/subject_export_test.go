@@ -0,0 +1,114 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectExport", func() {
+	var ctl *gomock.Controller
+	var mockManager *mock.MockSubjectExportManager
+	var svc SubjectExportService
+
+	BeforeEach(func() {
+		ctl = gomock.NewController(GinkgoT())
+		mockManager = mock.NewMockSubjectExportManager(ctl)
+		svc = &subjectExportService{manager: mockManager}
+	})
+
+	AfterEach(func() {
+		ctl.Finish()
+	})
+
+	Describe("ListSubjectAfterPK", func() {
+		It("error", func() {
+			mockManager.EXPECT().ListSubjectAfterPK(int64(0), int64(10)).Return(nil, errors.New("error"))
+
+			_, err := svc.ListSubjectAfterPK(0, 10)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListSubjectAfterPK")
+		})
+
+		It("ok", func() {
+			mockManager.EXPECT().ListSubjectAfterPK(int64(0), int64(10)).Return([]dao.Subject{
+				{PK: 1, Type: "user", ID: "admin", Name: "Admin"},
+			}, nil)
+
+			subjects, err := svc.ListSubjectAfterPK(0, 10)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.ExportSubject{{PK: 1, Type: "user", ID: "admin", Name: "Admin"}}, subjects)
+		})
+	})
+
+	Describe("ListRelationAfterPK", func() {
+		It("ok", func() {
+			mockManager.EXPECT().ListRelationAfterPK(int64(0), int64(10)).Return([]dao.SubjectRelation{
+				{
+					PK:              1,
+					SubjectPK:       2,
+					SubjectType:     "user",
+					SubjectID:       "admin",
+					ParentPK:        3,
+					ParentType:      "group",
+					ParentID:        "1",
+					PolicyExpiredAt: 4102444800,
+				},
+			}, nil)
+
+			relations, err := svc.ListRelationAfterPK(0, 10)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.ExportSubjectRelation{
+				{
+					PK:              1,
+					SubjectPK:       2,
+					SubjectType:     "user",
+					SubjectID:       "admin",
+					ParentPK:        3,
+					ParentType:      "group",
+					ParentID:        "1",
+					PolicyExpiredAt: 4102444800,
+				},
+			}, relations)
+		})
+	})
+
+	Describe("ListSubjectDepartmentAfterPK", func() {
+		It("invalid department pks", func() {
+			mockManager.EXPECT().ListSubjectDepartmentAfterPK(int64(0), int64(10)).Return([]dao.SubjectDepartment{
+				{PK: 1, SubjectPK: 2, DepartmentPKs: "a,b"},
+			}, nil)
+
+			_, err := svc.ListSubjectDepartmentAfterPK(0, 10)
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("ok", func() {
+			mockManager.EXPECT().ListSubjectDepartmentAfterPK(int64(0), int64(10)).Return([]dao.SubjectDepartment{
+				{PK: 1, SubjectPK: 2, DepartmentPKs: "3,4"},
+			}, nil)
+
+			subjectDepartments, err := svc.ListSubjectDepartmentAfterPK(0, 10)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.ExportSubjectDepartment{
+				{PK: 1, SubjectPK: 2, DepartmentPKs: []int64{3, 4}},
+			}, subjectDepartments)
+		})
+	})
+})
@@ -0,0 +1,55 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package service
+
+import (
+	"iam/pkg/database"
+	"iam/pkg/errorx"
+	"iam/pkg/service/types"
+)
+
+// ListFrozenPKs 查询所有被冻结的subject pk
+func (l *subjectService) ListFrozenPKs() ([]int64, error) {
+	pks, err := l.manager.ListFrozenPKs()
+	if err != nil {
+		return nil, errorx.Wrapf(err, SubjectSVC, "ListFrozenPKs", "manager.ListFrozenPKs fail")
+	}
+	return pks, nil
+}
+
+// BulkUpdateFrozen 冻结/解冻subject, 冻结的subject鉴权时没有任何权限, 但不删除其数据; 返回存在的subject pk
+func (l *subjectService) BulkUpdateFrozen(subjects []types.Subject, frozen bool) ([]int64, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "BulkUpdateFrozen")
+
+	pks, err := l.ListPKsBySubjects(subjects)
+	if err != nil {
+		return nil, errorWrapf(err, "subjectService.ListPKsBySubjects subjects=`%+v` fail", subjects)
+	}
+	if len(pks) == 0 {
+		return pks, nil
+	}
+
+	tx, err := database.GenerateDefaultDBTx()
+	defer database.RollBackWithLog(tx)
+	if err != nil {
+		return nil, errorWrapf(err, "define tx error")
+	}
+
+	_, err = l.manager.UpdateFrozenByPKsWithTx(tx, pks, frozen)
+	if err != nil {
+		return nil, errorWrapf(err, "manager.UpdateFrozenByPKsWithTx pks=`%+v`, frozen=`%t` fail", pks, frozen)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errorWrapf(err, "tx commit error")
+	}
+	return pks, nil
+}
@@ -0,0 +1,133 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package service
+
+import (
+	"errors"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectService", func() {
+
+	Describe("ListFrozenPKs", func() {
+		var ctl *gomock.Controller
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("manager.ListFrozenPKs fail", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().ListFrozenPKs().Return(nil, errors.New("list fail"))
+
+			manager := &subjectService{
+				manager: mockSubjectManager,
+			}
+
+			_, err := manager.ListFrozenPKs()
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListFrozenPKs")
+		})
+
+		It("ok", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().ListFrozenPKs().Return([]int64{1, 2}, nil)
+
+			manager := &subjectService{
+				manager: mockSubjectManager,
+			}
+
+			pks, err := manager.ListFrozenPKs()
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []int64{1, 2}, pks)
+		})
+	})
+
+	Describe("BulkUpdateFrozen", func() {
+		var ctl *gomock.Controller
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("subjects not exists", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().ListByIDs("user", []string{"admin"}).Return([]dao.Subject{}, nil)
+
+			manager := &subjectService{
+				manager: mockSubjectManager,
+			}
+
+			pks, err := manager.BulkUpdateFrozen([]types.Subject{{Type: "user", ID: "admin"}}, true)
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), pks, 0)
+		})
+
+		It("manager.UpdateFrozenByPKsWithTx fail", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().ListByIDs("user", []string{"admin"}).Return(
+				[]dao.Subject{{PK: 1, Type: "user", ID: "admin"}}, nil)
+			mockSubjectManager.EXPECT().UpdateFrozenByPKsWithTx(gomock.Any(), []int64{1}, true).Return(
+				int64(0), errors.New("update fail"))
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectRollback()
+
+			patches := gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+			defer patches.Reset()
+
+			manager := &subjectService{
+				manager: mockSubjectManager,
+			}
+
+			_, err := manager.BulkUpdateFrozen([]types.Subject{{Type: "user", ID: "admin"}}, true)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "update fail")
+		})
+
+		It("ok", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().ListByIDs("user", []string{"admin"}).Return(
+				[]dao.Subject{{PK: 1, Type: "user", ID: "admin"}}, nil)
+			mockSubjectManager.EXPECT().UpdateFrozenByPKsWithTx(gomock.Any(), []int64{1}, false).Return(
+				int64(1), nil)
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+
+			patches := gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+			defer patches.Reset()
+
+			manager := &subjectService{
+				manager: mockSubjectManager,
+			}
+
+			pks, err := manager.BulkUpdateFrozen([]types.Subject{{Type: "user", ID: "admin"}}, false)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []int64{1}, pks)
+			assert.NoError(GinkgoT(), dbMock.ExpectationsWereMet())
+		})
+	})
+})
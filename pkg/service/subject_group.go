@@ -98,6 +98,47 @@ func (l *subjectService) ListSubjectGroups(
 	return subjectGroups, err
 }
 
+// GetSubjectGroupCount subject加入的用户组数量, beforeExpiredAt不为0时只统计在其之前过期的
+func (l *subjectService) GetSubjectGroupCount(_type, id string, beforeExpiredAt int64) (cnt int64, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "GetSubjectGroupCount")
+	if beforeExpiredAt == 0 {
+		cnt, err = l.relationManager.GetRelationCount(_type, id)
+	} else {
+		cnt, err = l.relationManager.GetRelationCountBeforeExpiredAt(_type, id, beforeExpiredAt)
+	}
+
+	if err != nil {
+		return 0, errorWrapf(err, "GetRelationCount _type=`%s`, id=`%s`, beforeExpiredAt=`%d` fail",
+			_type, id, beforeExpiredAt)
+	}
+	return cnt, nil
+}
+
+// ListPagingSubjectGroups 分页查询subject加入的用户组, beforeExpiredAt不为0时只查询在其之前过期的
+func (l *subjectService) ListPagingSubjectGroups(
+	_type, id string, beforeExpiredAt, limit, offset int64,
+) (subjectGroups []types.SubjectGroup, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "ListPagingSubjectGroups")
+	var relations []dao.SubjectRelation
+	if beforeExpiredAt == 0 {
+		relations, err = l.relationManager.ListPagingRelation(_type, id, limit, offset)
+	} else {
+		relations, err = l.relationManager.ListPagingRelationBeforeExpiredAt(_type, id, beforeExpiredAt, limit, offset)
+	}
+
+	if err != nil {
+		return subjectGroups, errorWrapf(err,
+			"ListPagingRelation _type=`%s`, id=`%s`, beforeExpiredAt=`%d`, limit=`%d`, offset=`%d` fail",
+			_type, id, beforeExpiredAt, limit, offset)
+	}
+
+	subjectGroups = make([]types.SubjectGroup, 0, len(relations))
+	for _, r := range relations {
+		subjectGroups = append(subjectGroups, convertToSubjectGroup(r))
+	}
+	return subjectGroups, nil
+}
+
 // ListExistSubjectsBeforeExpiredAt filter the exists and not expired subjects
 func (l *subjectService) ListExistSubjectsBeforeExpiredAt(
 	subjects []types.Subject, expiredAt int64,
@@ -0,0 +1,203 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/errorx"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// 违反用户组成员配置的错误
+var (
+	ErrGroupMemberLimitExceeded = errors.New(
+		"group member limit exceeded: the members of the group will be more than the max_members")
+	ErrGroupMemberExpiredAtExceeded = errors.New(
+		"policy_expired_at exceeds the max_expiration_days of the group")
+	ErrGroupMemberRenewalNotAllowed = errors.New(
+		"member renewal not allowed: the member is not within the renewal_window_days of the group")
+)
+
+// GetGroupSetting 用户组未配置时, 返回不做任何限制的配置
+func (l *subjectService) GetGroupSetting(_type, id string) (types.GroupSetting, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "GetGroupSetting")
+
+	pk, err := l.manager.GetPK(_type, id)
+	if err != nil {
+		return types.GroupSetting{}, errorWrapf(err, "manager.GetPK _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	setting, err := l.getGroupSetting(pk)
+	if err != nil {
+		return types.GroupSetting{}, errorWrapf(err, "getGroupSetting pk=`%d` fail", pk)
+	}
+	return convertToGroupSetting(setting), nil
+}
+
+// SaveGroupSetting 创建或更新用户组的成员配置
+// NOTE: 只对之后的添加/续期生效, 不处理已有的成员
+func (l *subjectService) SaveGroupSetting(_type, id string, setting types.GroupSetting) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "SaveGroupSetting")
+
+	pk, err := l.manager.GetPK(_type, id)
+	if err != nil {
+		return errorWrapf(err, "manager.GetPK _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	daoSetting := dao.GroupSetting{
+		GroupPK:               pk,
+		MaxMembers:            setting.MaxMembers,
+		DefaultExpirationDays: setting.DefaultExpirationDays,
+		MaxExpirationDays:     setting.MaxExpirationDays,
+		RenewalWindowDays:     setting.RenewalWindowDays,
+	}
+
+	_, err = l.groupSettingManager.Get(pk)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = l.groupSettingManager.Create(daoSetting)
+		if err != nil {
+			return errorWrapf(err, "groupSettingManager.Create setting=`%+v` fail", daoSetting)
+		}
+		return nil
+	}
+	if err != nil {
+		return errorWrapf(err, "groupSettingManager.Get groupPK=`%d` fail", pk)
+	}
+
+	err = l.groupSettingManager.Update(daoSetting)
+	if err != nil {
+		return errorWrapf(err, "groupSettingManager.Update setting=`%+v` fail", daoSetting)
+	}
+	return nil
+}
+
+// DeleteGroupSetting 删除用户组的成员配置, 删除后不再限制
+func (l *subjectService) DeleteGroupSetting(_type, id string) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "DeleteGroupSetting")
+
+	pk, err := l.manager.GetPK(_type, id)
+	if err != nil {
+		return errorWrapf(err, "manager.GetPK _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	_, err = l.groupSettingManager.Delete(pk)
+	if err != nil {
+		return errorWrapf(err, "groupSettingManager.Delete groupPK=`%d` fail", pk)
+	}
+	return nil
+}
+
+// getGroupSetting 用户组未配置时, 返回不做任何限制的配置
+func (l *subjectService) getGroupSetting(groupPK int64) (dao.GroupSetting, error) {
+	setting, err := l.groupSettingManager.Get(groupPK)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dao.GroupSetting{GroupPK: groupPK}, nil
+	}
+	return setting, err
+}
+
+// listGroupSettings 查询配置了成员配置的用户组, key为用户组pk
+func (l *subjectService) listGroupSettings(groupPKs []int64) (map[int64]dao.GroupSetting, error) {
+	settings, err := l.groupSettingManager.ListByGroupPKs(groupPKs)
+	if err != nil {
+		return nil, err
+	}
+
+	settingMap := make(map[int64]dao.GroupSetting, len(settings))
+	for _, s := range settings {
+		settingMap[s.GroupPK] = s
+	}
+	return settingMap, nil
+}
+
+// checkGroupMembersRenewal 按成员所在用户组的配置, 校验续期的过期时间及是否在可续期的时间内
+// NOTE: relations为续期前的成员关系, 用于获取所在的用户组及原过期时间
+func (l *subjectService) checkGroupMembersRenewal(
+	members []types.SubjectMember, relations []dao.SubjectRelation,
+) error {
+	relationMap := make(map[int64]dao.SubjectRelation, len(relations))
+	groupPKSet := util.NewInt64Set()
+	for _, r := range relations {
+		relationMap[r.PK] = r
+		groupPKSet.Add(r.ParentPK)
+	}
+
+	groupPKs := groupPKSet.ToSlice()
+	settingMap, err := l.listGroupSettings(groupPKs)
+	if err != nil {
+		return errorx.Wrapf(err, SubjectSVC, "checkGroupMembersRenewal",
+			"listGroupSettings groupPKs=`%+v` fail", groupPKs)
+	}
+	if len(settingMap) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for _, m := range members {
+		r, ok := relationMap[m.PK]
+		if !ok {
+			continue
+		}
+		setting, ok := settingMap[r.ParentPK]
+		if !ok {
+			continue
+		}
+
+		err = checkGroupMemberPolicyExpiredAt(setting, m.PolicyExpiredAt, now)
+		if err != nil {
+			return err
+		}
+		err = checkGroupMemberRenewal(setting, r.PolicyExpiredAt, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillGroupMemberPolicyExpiredAt 未指定过期时间时使用用户组的默认过期天数, 并校验不能超过用户组的最大过期天数
+func fillGroupMemberPolicyExpiredAt(setting dao.GroupSetting, policyExpiredAt int64, now time.Time) (int64, error) {
+	if policyExpiredAt == 0 && setting.DefaultExpirationDays > 0 {
+		policyExpiredAt = now.AddDate(0, 0, int(setting.DefaultExpirationDays)).Unix()
+	}
+	return policyExpiredAt, checkGroupMemberPolicyExpiredAt(setting, policyExpiredAt, now)
+}
+
+// checkGroupMemberPolicyExpiredAt 过期时间不能超过用户组的最大过期天数
+func checkGroupMemberPolicyExpiredAt(setting dao.GroupSetting, policyExpiredAt int64, now time.Time) error {
+	if setting.MaxExpirationDays > 0 &&
+		policyExpiredAt > now.AddDate(0, 0, int(setting.MaxExpirationDays)).Unix() {
+		return ErrGroupMemberExpiredAtExceeded
+	}
+	return nil
+}
+
+// checkGroupMemberRenewal 校验成员续期: 只有在过期前renewal_window_days天内才可以续期, 已过期的成员可以续期
+func checkGroupMemberRenewal(setting dao.GroupSetting, oldPolicyExpiredAt int64, now time.Time) error {
+	if setting.RenewalWindowDays > 0 &&
+		oldPolicyExpiredAt > now.AddDate(0, 0, int(setting.RenewalWindowDays)).Unix() {
+		return ErrGroupMemberRenewalNotAllowed
+	}
+	return nil
+}
+
+func convertToGroupSetting(setting dao.GroupSetting) types.GroupSetting {
+	return types.GroupSetting{
+		MaxMembers:            setting.MaxMembers,
+		DefaultExpirationDays: setting.DefaultExpirationDays,
+		MaxExpirationDays:     setting.MaxExpirationDays,
+		RenewalWindowDays:     setting.RenewalWindowDays,
+	}
+}
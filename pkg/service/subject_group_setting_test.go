@@ -0,0 +1,218 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("GroupSetting", func() {
+	var ctl *gomock.Controller
+	var mockManager *mock.MockSubjectManager
+	var mockRelationManager *mock.MockSubjectRelationManager
+	var mockGroupSettingManager *mock.MockGroupSettingManager
+	var svc *subjectService
+
+	BeforeEach(func() {
+		ctl = gomock.NewController(GinkgoT())
+		mockManager = mock.NewMockSubjectManager(ctl)
+		mockRelationManager = mock.NewMockSubjectRelationManager(ctl)
+		mockGroupSettingManager = mock.NewMockGroupSettingManager(ctl)
+		svc = &subjectService{
+			manager:             mockManager,
+			relationManager:     mockRelationManager,
+			groupSettingManager: mockGroupSettingManager,
+		}
+	})
+
+	AfterEach(func() {
+		ctl.Finish()
+	})
+
+	Describe("GetGroupSetting", func() {
+		It("not configured", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockGroupSettingManager.EXPECT().Get(int64(1)).Return(dao.GroupSetting{}, sql.ErrNoRows)
+
+			setting, err := svc.GetGroupSetting("group", "1")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), types.GroupSetting{}, setting)
+		})
+
+		It("ok", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockGroupSettingManager.EXPECT().Get(int64(1)).Return(dao.GroupSetting{
+				PK: 2, GroupPK: 1, MaxMembers: 100, MaxExpirationDays: 180,
+			}, nil)
+
+			setting, err := svc.GetGroupSetting("group", "1")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), types.GroupSetting{MaxMembers: 100, MaxExpirationDays: 180}, setting)
+		})
+
+		It("group not exists", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(0), sql.ErrNoRows)
+
+			_, err := svc.GetGroupSetting("group", "1")
+			assert.True(GinkgoT(), errors.Is(err, sql.ErrNoRows))
+		})
+	})
+
+	Describe("SaveGroupSetting", func() {
+		setting := types.GroupSetting{MaxMembers: 100, DefaultExpirationDays: 30}
+
+		It("create", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockGroupSettingManager.EXPECT().Get(int64(1)).Return(dao.GroupSetting{}, sql.ErrNoRows)
+			mockGroupSettingManager.EXPECT().Create(dao.GroupSetting{
+				GroupPK: 1, MaxMembers: 100, DefaultExpirationDays: 30,
+			}).Return(nil)
+
+			err := svc.SaveGroupSetting("group", "1", setting)
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("update", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockGroupSettingManager.EXPECT().Get(int64(1)).Return(dao.GroupSetting{PK: 2, GroupPK: 1}, nil)
+			mockGroupSettingManager.EXPECT().Update(dao.GroupSetting{
+				GroupPK: 1, MaxMembers: 100, DefaultExpirationDays: 30,
+			}).Return(nil)
+
+			err := svc.SaveGroupSetting("group", "1", setting)
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("get fail", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockGroupSettingManager.EXPECT().Get(int64(1)).Return(dao.GroupSetting{}, errors.New("error"))
+
+			err := svc.SaveGroupSetting("group", "1", setting)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "groupSettingManager.Get")
+		})
+	})
+
+	Describe("DeleteGroupSetting", func() {
+		It("ok", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockGroupSettingManager.EXPECT().Delete(int64(1)).Return(int64(1), nil)
+
+			err := svc.DeleteGroupSetting("group", "1")
+			assert.NoError(GinkgoT(), err)
+		})
+	})
+
+	Describe("fillGroupMemberPolicyExpiredAt", func() {
+		now := time.Unix(1629000000, 0)
+
+		It("no limit", func() {
+			policyExpiredAt, err := fillGroupMemberPolicyExpiredAt(dao.GroupSetting{}, 4102444800, now)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(4102444800), policyExpiredAt)
+		})
+
+		It("default expiration days", func() {
+			policyExpiredAt, err := fillGroupMemberPolicyExpiredAt(
+				dao.GroupSetting{DefaultExpirationDays: 30, MaxExpirationDays: 30}, 0, now)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), now.AddDate(0, 0, 30).Unix(), policyExpiredAt)
+		})
+
+		It("exceed max expiration days", func() {
+			_, err := fillGroupMemberPolicyExpiredAt(
+				dao.GroupSetting{MaxExpirationDays: 30}, now.AddDate(0, 0, 31).Unix(), now)
+			assert.Equal(GinkgoT(), ErrGroupMemberExpiredAtExceeded, err)
+		})
+	})
+
+	Describe("checkGroupMemberRenewal", func() {
+		now := time.Unix(1629000000, 0)
+
+		It("within the renewal window", func() {
+			err := checkGroupMemberRenewal(dao.GroupSetting{RenewalWindowDays: 7}, now.AddDate(0, 0, 7).Unix(), now)
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("expired", func() {
+			err := checkGroupMemberRenewal(dao.GroupSetting{RenewalWindowDays: 7}, now.Unix()-1, now)
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("not within the renewal window", func() {
+			err := checkGroupMemberRenewal(dao.GroupSetting{RenewalWindowDays: 7}, now.AddDate(0, 0, 8).Unix(), now)
+			assert.Equal(GinkgoT(), ErrGroupMemberRenewalNotAllowed, err)
+		})
+	})
+
+	Describe("BulkCreateSubjectMembers with group setting", func() {
+		members := []types.Subject{{Type: "user", ID: "tom"}, {Type: "user", ID: "jerry"}}
+
+		It("member limit exceeded", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockGroupSettingManager.EXPECT().Get(int64(1)).Return(dao.GroupSetting{GroupPK: 1, MaxMembers: 10}, nil)
+			mockRelationManager.EXPECT().GetMemberCount("group", "1").Return(int64(9), nil)
+
+			err := svc.BulkCreateSubjectMembers("group", "1", members, 4102444800)
+			assert.True(GinkgoT(), errors.Is(err, ErrGroupMemberLimitExceeded))
+		})
+
+		It("policy expired at exceeded", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockGroupSettingManager.EXPECT().Get(int64(1)).Return(
+				dao.GroupSetting{GroupPK: 1, MaxExpirationDays: 30}, nil)
+
+			err := svc.BulkCreateSubjectMembers("group", "1", members, 4102444800)
+			assert.True(GinkgoT(), errors.Is(err, ErrGroupMemberExpiredAtExceeded))
+		})
+	})
+
+	Describe("UpdateMembersExpiredAt with group setting", func() {
+		It("renewal not allowed", func() {
+			now := time.Now()
+			mockRelationManager.EXPECT().ListRelationByPKs([]int64{10}).Return([]dao.SubjectRelation{
+				{PK: 10, ParentPK: 1, PolicyExpiredAt: now.AddDate(0, 0, 30).Unix()},
+			}, nil)
+			mockGroupSettingManager.EXPECT().ListByGroupPKs([]int64{1}).Return([]dao.GroupSetting{
+				{GroupPK: 1, RenewalWindowDays: 7},
+			}, nil)
+
+			err := svc.UpdateMembersExpiredAt([]types.SubjectMember{
+				{PK: 10, Type: "user", ID: "tom", PolicyExpiredAt: now.AddDate(0, 0, 60).Unix()},
+			})
+			assert.True(GinkgoT(), errors.Is(err, ErrGroupMemberRenewalNotAllowed))
+		})
+
+		It("policy expired at exceeded", func() {
+			now := time.Now()
+			mockRelationManager.EXPECT().ListRelationByPKs([]int64{10}).Return([]dao.SubjectRelation{
+				{PK: 10, ParentPK: 1, PolicyExpiredAt: now.Unix()},
+			}, nil)
+			mockGroupSettingManager.EXPECT().ListByGroupPKs([]int64{1}).Return([]dao.GroupSetting{
+				{GroupPK: 1, MaxExpirationDays: 30, RenewalWindowDays: 7},
+			}, nil)
+
+			err := svc.UpdateMembersExpiredAt([]types.SubjectMember{
+				{PK: 10, Type: "user", ID: "tom", PolicyExpiredAt: now.AddDate(0, 0, 60).Unix()},
+			})
+			assert.True(GinkgoT(), errors.Is(err, ErrGroupMemberExpiredAtExceeded))
+		})
+	})
+})
@@ -11,8 +11,106 @@
 package service
 
 import (
+	"errors"
+
+	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
 )
 
 var _ = Describe("SubjectService", func() {
+	Describe("GetSubjectGroupCount cases", func() {
+		var ctl *gomock.Controller
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("ok", func() {
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().GetRelationCount("user", "admin").Return(int64(3), nil)
+
+			svc := subjectService{relationManager: mockRelationManager}
+			cnt, err := svc.GetSubjectGroupCount("user", "admin", 0)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(3), cnt)
+		})
+
+		It("before expired at ok", func() {
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().GetRelationCountBeforeExpiredAt("user", "admin", int64(1000)).
+				Return(int64(2), nil)
+
+			svc := subjectService{relationManager: mockRelationManager}
+			cnt, err := svc.GetSubjectGroupCount("user", "admin", 1000)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(2), cnt)
+		})
+
+		It("fail", func() {
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().GetRelationCount("user", "admin").Return(int64(0), errors.New("error"))
+
+			svc := subjectService{relationManager: mockRelationManager}
+			_, err := svc.GetSubjectGroupCount("user", "admin", 0)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "GetRelationCount")
+		})
+	})
+
+	Describe("ListPagingSubjectGroups cases", func() {
+		var ctl *gomock.Controller
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("ok", func() {
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().ListPagingRelation("user", "admin", int64(10), int64(0)).Return(
+				[]dao.SubjectRelation{
+					{ParentType: "group", ParentID: "1", PolicyExpiredAt: 1000},
+					{ParentType: "group", ParentID: "2", PolicyExpiredAt: 2000},
+				}, nil,
+			)
+
+			svc := subjectService{relationManager: mockRelationManager}
+			groups, err := svc.ListPagingSubjectGroups("user", "admin", 0, 10, 0)
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), groups, 2)
+			assert.Equal(GinkgoT(), "1", groups[0].ID)
+			assert.Equal(GinkgoT(), int64(2000), groups[1].PolicyExpiredAt)
+		})
+
+		It("before expired at ok", func() {
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().ListPagingRelationBeforeExpiredAt(
+				"user", "admin", int64(1500), int64(10), int64(0),
+			).Return([]dao.SubjectRelation{{ParentType: "group", ParentID: "1", PolicyExpiredAt: 1000}}, nil)
+
+			svc := subjectService{relationManager: mockRelationManager}
+			groups, err := svc.ListPagingSubjectGroups("user", "admin", 1500, 10, 0)
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), groups, 1)
+		})
+
+		It("fail", func() {
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().ListPagingRelation("user", "admin", int64(10), int64(0)).Return(
+				nil, errors.New("error"),
+			)
+
+			svc := subjectService{relationManager: mockRelationManager}
+			_, err := svc.ListPagingSubjectGroups("user", "admin", 0, 10, 0)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListPagingRelation")
+		})
+	})
 })
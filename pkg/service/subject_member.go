@@ -14,12 +14,18 @@ import (
 	"errors"
 	"time"
 
+	log "github.com/sirupsen/logrus"
+
 	"iam/pkg/database"
 	"iam/pkg/database/dao"
 	"iam/pkg/errorx"
 	"iam/pkg/service/types"
+	"iam/pkg/util"
 )
 
+// ErrGroupMemberCycle 用户组加入用户组(group-in-group)后形成了环
+var ErrGroupMemberCycle = errors.New("group member cycle: the group is already a member of the member group")
+
 func convertToSubjectMembers(daoRelations []dao.SubjectRelation) []types.SubjectMember {
 	relations := make([]types.SubjectMember, 0, len(daoRelations))
 	for _, r := range daoRelations {
@@ -57,6 +63,32 @@ func (l *subjectService) ListPagingMember(_type, id string, limit, offset int64)
 	return convertToSubjectMembers(daoRelations), nil
 }
 
+// GetMemberCountBySubjectType 按成员类型过滤的用户组成员数量
+func (l *subjectService) GetMemberCountBySubjectType(_type, id, subjectType string) (int64, error) {
+	cnt, err := l.relationManager.GetMemberCountBySubjectType(_type, id, subjectType)
+	if err != nil {
+		err = errorx.Wrapf(err, SubjectSVC, "GetMemberCountBySubjectType",
+			"relationManager.GetMemberCountBySubjectType _type=`%s`, id=`%s`, subjectType=`%s` fail",
+			_type, id, subjectType)
+		return 0, err
+	}
+	return cnt, nil
+}
+
+// ListPagingMemberBySubjectType 按成员类型过滤的用户组成员列表
+func (l *subjectService) ListPagingMemberBySubjectType(
+	_type, id, subjectType string, limit, offset int64,
+) ([]types.SubjectMember, error) {
+	daoRelations, err := l.relationManager.ListPagingMemberBySubjectType(_type, id, subjectType, limit, offset)
+	if err != nil {
+		return nil, errorx.Wrapf(err, SubjectSVC, "ListPagingMemberBySubjectType",
+			"relationManager.ListPagingMemberBySubjectType _type=`%s`, id=`%s`, subjectType=`%s`, "+
+				"limit=`%d`, offset=`%d`", _type, id, subjectType, limit, offset)
+	}
+
+	return convertToSubjectMembers(daoRelations), nil
+}
+
 // ListMember ...
 func (l *subjectService) ListMember(_type, id string) ([]types.SubjectMember, error) {
 	daoRelations, err := l.relationManager.ListMember(_type, id)
@@ -72,6 +104,22 @@ func (l *subjectService) ListMember(_type, id string) ([]types.SubjectMember, er
 func (l *subjectService) UpdateMembersExpiredAt(members []types.SubjectMember) error {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "BulkDeleteSubjectMember")
 
+	// NOTE: SubjectMember.PK 是关系的pk, 不是成员的subject pk, 需要查询关系获取成员的subject pk及所在的用户组
+	pks := make([]int64, 0, len(members))
+	for _, m := range members {
+		pks = append(pks, m.PK)
+	}
+	oldRelations, err := l.relationManager.ListRelationByPKs(pks)
+	if err != nil {
+		return errorWrapf(err, "relationManager.ListRelationByPKs pks=`%+v` fail", pks)
+	}
+
+	// 按用户组的成员配置, 校验续期
+	err = l.checkGroupMembersRenewal(members, oldRelations)
+	if err != nil {
+		return errorWrapf(err, "checkGroupMembersRenewal members=`%+v` fail", members)
+	}
+
 	relations := make([]dao.SubjectRelationPKPolicyExpiredAt, 0, len(members))
 	for _, m := range members {
 		relations = append(relations, dao.SubjectRelationPKPolicyExpiredAt{
@@ -80,13 +128,33 @@ func (l *subjectService) UpdateMembersExpiredAt(members []types.SubjectMember) e
 		})
 	}
 
-	err := l.relationManager.UpdateExpiredAt(relations)
+	err = l.relationManager.UpdateExpiredAt(relations)
 	if err != nil {
 		err = errorWrapf(err,
 			"relationManager.UpdateExpiredAt relations=`%+v` fail", relations)
 		return err
 	}
 
+	// 续期后成员的用户组过期时间变化, 直接使用成员的subject pk清理缓存, 不需要再查询type+id => pk
+	subjectPKs := make([]int64, 0, len(oldRelations))
+	for _, r := range oldRelations {
+		subjectPKs = append(subjectPKs, r.SubjectPK)
+	}
+	emitSubjectChangeEvent(SubjectChangeEvent{
+		Type:       SubjectChangeEventTypeMember,
+		SubjectPKs: subjectPKs,
+	})
+
+	expiredAts := make(map[int64]int64, len(members))
+	for _, m := range members {
+		expiredAts[m.PK] = m.PolicyExpiredAt
+	}
+	renewedRelations := make([]dao.SubjectRelation, 0, len(oldRelations))
+	for _, r := range oldRelations {
+		r.PolicyExpiredAt = expiredAts[r.PK]
+		renewedRelations = append(renewedRelations, r)
+	}
+	emitSubjectRelationEvents(newSubjectRelationEvents(SubjectRelationEventActionRenewed, renewedRelations))
 	return nil
 }
 
@@ -95,7 +163,7 @@ func (l *subjectService) BulkDeleteSubjectMembers(_type, id string, members []ty
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "BulkDeleteSubjectMember")
 
 	// 按类型分组
-	userIDs, departmentIDs, _ := groupBySubjectType(members)
+	userIDs, departmentIDs, groupIDs, serviceAccountIDs := groupBySubjectType(members)
 
 	// 使用事务
 	tx, err := database.GenerateDefaultDBTx()
@@ -106,8 +174,10 @@ func (l *subjectService) BulkDeleteSubjectMembers(_type, id string, members []ty
 	}
 
 	typeCount := map[string]int64{
-		types.UserType:       0,
-		types.DepartmentType: 0,
+		types.UserType:           0,
+		types.DepartmentType:     0,
+		types.GroupType:          0,
+		types.ServiceAccountType: 0,
 	}
 
 	var count int64
@@ -131,13 +201,87 @@ func (l *subjectService) BulkDeleteSubjectMembers(_type, id string, members []ty
 		typeCount[types.DepartmentType] = count
 	}
 
+	if len(groupIDs) != 0 {
+		count, err = l.relationManager.BulkDeleteByMembersWithTx(tx, _type, id, types.GroupType, groupIDs)
+		if err != nil {
+			return nil, errorWrapf(
+				err, "relationManager.BulkDeleteByMembersWithTx _type=`%s`, id=`%s`, subjectType=`%s`, subjectIDs=`%+v` fail",
+				_type, id, types.GroupType, groupIDs)
+		}
+		typeCount[types.GroupType] = count
+	}
+
+	if len(serviceAccountIDs) != 0 {
+		count, err = l.relationManager.BulkDeleteByMembersWithTx(
+			tx, _type, id, types.ServiceAccountType, serviceAccountIDs)
+		if err != nil {
+			return nil, errorWrapf(
+				err, "relationManager.BulkDeleteByMembersWithTx _type=`%s`, id=`%s`, subjectType=`%s`, subjectIDs=`%+v` fail",
+				_type, id, types.ServiceAccountType, serviceAccountIDs)
+		}
+		typeCount[types.ServiceAccountType] = count
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return nil, errorWrapf(err, "tx commit error")
 	}
+
+	emitSubjectChangeEvent(SubjectChangeEvent{
+		Type:     SubjectChangeEventTypeMember,
+		Subjects: members,
+		Group:    &types.Subject{Type: _type, ID: id},
+		MemberDelta: -(typeCount[types.UserType] + typeCount[types.DepartmentType] +
+			typeCount[types.GroupType] + typeCount[types.ServiceAccountType]),
+	})
+	l.emitRemoveSubjectRelationEvents(_type, id, members)
 	return typeCount, err
 }
 
+// emitRemoveSubjectRelationEvents 按type+id移除的成员, 投递事件时才查询用户组及成员的pk
+// NOTE: 不在用户组内的成员同样会产生移除事件, 下游应幂等处理; 移除前的过期时间未知, 为0
+func (l *subjectService) emitRemoveSubjectRelationEvents(_type, id string, members []types.Subject) {
+	if getSubjectRelationEventSink() == nil {
+		return
+	}
+
+	groupPK, err := l.manager.GetPK(_type, id)
+	if err != nil {
+		log.WithError(err).Errorf("emitRemoveSubjectRelationEvents manager.GetPK _type=`%s`, id=`%s` fail", _type, id)
+		return
+	}
+
+	userIDs, departmentIDs, groupIDs, serviceAccountIDs := groupBySubjectType(members)
+	subjectTypes := []string{types.UserType, types.DepartmentType, types.GroupType, types.ServiceAccountType}
+	typeIDs := [][]string{userIDs, departmentIDs, groupIDs, serviceAccountIDs}
+
+	relations := make([]dao.SubjectRelation, 0, len(members))
+	for i, subjectType := range subjectTypes {
+		subjectIDs := typeIDs[i]
+		if len(subjectIDs) == 0 {
+			continue
+		}
+
+		subjects, err := l.manager.ListByIDs(subjectType, subjectIDs)
+		if err != nil {
+			log.WithError(err).Errorf("emitRemoveSubjectRelationEvents manager.ListByIDs _type=`%s`, ids=`%+v` fail",
+				subjectType, subjectIDs)
+			return
+		}
+		for _, s := range subjects {
+			relations = append(relations, dao.SubjectRelation{
+				SubjectPK:   s.PK,
+				SubjectType: s.Type,
+				SubjectID:   s.ID,
+				ParentPK:    groupPK,
+				ParentType:  _type,
+				ParentID:    id,
+			})
+		}
+	}
+	emitSubjectRelationEvents(newSubjectRelationEvents(SubjectRelationEventActionRemoved, relations))
+}
+
 // BulkCreateSubjectMembers ...
 func (l *subjectService) BulkCreateSubjectMembers(
 	_type, id string,
@@ -151,10 +295,34 @@ func (l *subjectService) BulkCreateSubjectMembers(
 		return errorWrapf(err, "manager.GetPK _type=`%s`, id=`%s` fail", _type, id)
 	}
 
+	// 按用户组的成员配置, 填充默认过期时间, 校验过期时间及成员数量上限
+	setting, err := l.getGroupSetting(pk)
+	if err != nil {
+		return errorWrapf(err, "getGroupSetting pk=`%d` fail", pk)
+	}
+
+	policyExpiredAt, err = fillGroupMemberPolicyExpiredAt(setting, policyExpiredAt, time.Now())
+	if err != nil {
+		return errorWrapf(err, "fillGroupMemberPolicyExpiredAt setting=`%+v`, policyExpiredAt=`%d` fail",
+			setting, policyExpiredAt)
+	}
+
+	// NOTE: members为需要新加入的成员, 已在用户组内的成员由调用方过滤; 并发添加时可能略微超过上限
+	if setting.MaxMembers > 0 {
+		count, newErr := l.relationManager.GetMemberCount(_type, id)
+		if newErr != nil {
+			return errorWrapf(newErr, "relationManager.GetMemberCount _type=`%s`, id=`%s` fail", _type, id)
+		}
+		if count+int64(len(members)) > setting.MaxMembers {
+			return errorWrapf(ErrGroupMemberLimitExceeded, "count=`%d`, members=`%d`, maxMembers=`%d`",
+				count, len(members), setting.MaxMembers)
+		}
+	}
+
 	// 分组查询members PK
 	memberPKMap := subjectPKMap{}
 	// 按类型分组
-	userIDs, departmentIDs, _ := groupBySubjectType(members)
+	userIDs, departmentIDs, groupIDs, serviceAccountIDs := groupBySubjectType(members)
 
 	if len(userIDs) > 0 {
 		users, newErr := l.manager.ListByIDs(types.UserType, userIDs)
@@ -174,6 +342,34 @@ func (l *subjectService) BulkCreateSubjectMembers(
 			memberPKMap.Add(d.Type, d.ID, d.PK)
 		}
 	}
+	if len(serviceAccountIDs) > 0 {
+		serviceAccounts, newErr := l.manager.ListByIDs(types.ServiceAccountType, serviceAccountIDs)
+		if newErr != nil {
+			return errorWrapf(newErr, "manager.ListByIDs _type=`%s`, ids=`%+v` fail",
+				types.ServiceAccountType, serviceAccountIDs)
+		}
+		for _, sa := range serviceAccounts {
+			memberPKMap.Add(sa.Type, sa.ID, sa.PK)
+		}
+	}
+	if len(groupIDs) > 0 {
+		groups, newErr := l.manager.ListByIDs(types.GroupType, groupIDs)
+		if newErr != nil {
+			return errorWrapf(newErr, "manager.ListByIDs _type=`%s`, ids=`%+v` fail", types.GroupType, groupIDs)
+		}
+
+		groupPKs := make([]int64, 0, len(groups))
+		for _, g := range groups {
+			memberPKMap.Add(g.Type, g.ID, g.PK)
+			groupPKs = append(groupPKs, g.PK)
+		}
+
+		// 用户组加入用户组, 不能形成环
+		err = l.checkGroupMemberCycle(pk, groupPKs)
+		if err != nil {
+			return errorWrapf(err, "checkGroupMemberCycle pk=`%d`, groupPKs=`%+v` fail", pk, groupPKs)
+		}
+	}
 
 	now := time.Now()
 	// 组装需要创建的Subject关系
@@ -200,6 +396,53 @@ func (l *subjectService) BulkCreateSubjectMembers(
 	if err != nil {
 		return errorWrapf(err, "relationManager.BulkCreate relations=`%+v` fail", relations)
 	}
+
+	memberPKs := make([]int64, 0, len(relations))
+	for _, r := range relations {
+		memberPKs = append(memberPKs, r.SubjectPK)
+	}
+	emitSubjectChangeEvent(SubjectChangeEvent{
+		Type:            SubjectChangeEventTypeMember,
+		SubjectPKs:      memberPKs,
+		Group:           &types.Subject{Type: _type, ID: id},
+		MemberDelta:     int64(len(relations)),
+		PolicyExpiredAt: policyExpiredAt,
+	})
+	emitSubjectRelationEvents(newSubjectRelationEvents(SubjectRelationEventActionAdded, relations))
+	return nil
+}
+
+// checkGroupMemberCycle 检查用户组加入用户组后是否形成环: 成员用户组不能是当前用户组自身或其(间接)加入的用户组
+// NOTE: 不区分关系是否过期, 过期的关系续期后依然会生效
+func (l *subjectService) checkGroupMemberCycle(groupPK int64, memberGroupPKs []int64) error {
+	memberPKSet := util.NewInt64SetWithValues(memberGroupPKs)
+	if memberPKSet.Has(groupPK) {
+		return ErrGroupMemberCycle
+	}
+
+	// 向上遍历当前用户组加入的所有用户组
+	visited := util.NewInt64SetWithValues([]int64{groupPK})
+	queue := []int64{groupPK}
+	for len(queue) > 0 {
+		pk := queue[0]
+		queue = queue[1:]
+
+		relations, err := l.relationManager.ListThinRelationBySubjectPK(pk)
+		if err != nil {
+			return errorx.Wrapf(err, SubjectSVC, "checkGroupMemberCycle",
+				"relationManager.ListThinRelationBySubjectPK pk=`%d` fail", pk)
+		}
+
+		for _, r := range relations {
+			if memberPKSet.Has(r.ParentPK) {
+				return ErrGroupMemberCycle
+			}
+			if !visited.Has(r.ParentPK) {
+				visited.Add(r.ParentPK)
+				queue = append(queue, r.ParentPK)
+			}
+		}
+	}
 	return nil
 }
 
@@ -229,3 +472,85 @@ func (l *subjectService) ListPagingMemberBeforeExpiredAt(
 
 	return convertToSubjectMembers(daoRelations), nil
 }
+
+// PurgeExpiredMembers 删除最多limit条过期时间早于expiredAt的成员关系, 返回删除的数量
+func (l *subjectService) PurgeExpiredMembers(expiredAt int64, limit int64) (int64, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "PurgeExpiredMembers")
+
+	relations, err := l.relationManager.ListExpiredRelation(expiredAt, limit)
+	if err != nil {
+		return 0, errorWrapf(err, "relationManager.ListExpiredRelation expiredAt=`%d`, limit=`%d` fail",
+			expiredAt, limit)
+	}
+	if len(relations) == 0 {
+		return 0, nil
+	}
+
+	// 按用户组分组, 每个用户组单独删除, 才能得到每个用户组准确的成员变化数量
+	groups := make([]types.Subject, 0, 10)
+	groupRelations := make(map[types.Subject][]dao.SubjectRelation, 10)
+	for _, r := range relations {
+		group := types.Subject{Type: r.ParentType, ID: r.ParentID}
+		if _, ok := groupRelations[group]; !ok {
+			groups = append(groups, group)
+		}
+		groupRelations[group] = append(groupRelations[group], r)
+	}
+
+	tx, err := l.generateDBTx()
+	defer database.RollBackWithLog(tx)
+
+	if err != nil {
+		return 0, errorWrapf(err, "define tx error")
+	}
+
+	groupCount := make(map[types.Subject]int64, len(groups))
+	var total int64
+	for _, group := range groups {
+		pks := make([]int64, 0, len(groupRelations[group]))
+		for _, r := range groupRelations[group] {
+			pks = append(pks, r.PK)
+		}
+
+		count, err := l.relationManager.BulkDeleteExpiredByPKsWithTx(tx, pks, expiredAt)
+		if err != nil {
+			return 0, errorWrapf(err,
+				"relationManager.BulkDeleteExpiredByPKsWithTx pks=`%+v`, expiredAt=`%d` fail", pks, expiredAt)
+		}
+		groupCount[group] = count
+		total += count
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, errorWrapf(err, "tx commit error")
+	}
+
+	for _, group := range groups {
+		subjectPKs := make([]int64, 0, len(groupRelations[group]))
+		for _, r := range groupRelations[group] {
+			subjectPKs = append(subjectPKs, r.SubjectPK)
+		}
+
+		// NOTE: 查询与删除之间成员可能被续期, 此时不知道具体哪些成员被删除, 不调整成员数量, 只清理成员的缓存
+		var memberDelta int64
+		if groupCount[group] == int64(len(subjectPKs)) {
+			memberDelta = -groupCount[group]
+		}
+
+		g := group
+		emitSubjectChangeEvent(SubjectChangeEvent{
+			Type:        SubjectChangeEventTypeMember,
+			SubjectPKs:  subjectPKs,
+			Group:       &g,
+			MemberDelta: memberDelta,
+		})
+
+		// NOTE: 不知道具体哪些成员被删除时不投递移除事件, 被续期的成员仍然在用户组内, 未投递的成员本身已过期
+		if memberDelta != 0 {
+			emitSubjectRelationEvents(
+				newSubjectRelationEvents(SubjectRelationEventActionRemoved, groupRelations[group]))
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,206 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"iam/pkg/database"
+	"iam/pkg/database/dao"
+	"iam/pkg/errorx"
+	"iam/pkg/service/types"
+)
+
+// relationInsertChunkSize 复制/恢复成员时每次批量插入的关系数量, 避免单条SQL的占位符过多
+const relationInsertChunkSize = 1000
+
+// CopySubjectMembers 在一个事务中将源用户组的所有成员复制(move=true时移动)到目标用户组
+// 1. 成员保留在源用户组中的过期时间
+// 2. 已在目标用户组中的成员, 只在源用户组中的过期时间更晚时延长过期时间
+// 3. 移动时, 删除源用户组的所有成员关系
+// NOTE: 属于管理员的批量操作, 不经过添加成员的钩子
+func (l *subjectService) CopySubjectMembers(
+	_type, sourceID, targetID string, move bool,
+) (result types.SubjectMemberCopyResult, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "CopySubjectMembers")
+
+	sourcePK, err := l.manager.GetPK(_type, sourceID)
+	if err != nil {
+		return result, errorWrapf(err, "manager.GetPK _type=`%s`, id=`%s` fail", _type, sourceID)
+	}
+	targetPK, err := l.manager.GetPK(_type, targetID)
+	if err != nil {
+		return result, errorWrapf(err, "manager.GetPK _type=`%s`, id=`%s` fail", _type, targetID)
+	}
+
+	sourceRelations, err := l.relationManager.ListMember(_type, sourceID)
+	if err != nil {
+		return result, errorWrapf(err, "relationManager.ListMember _type=`%s`, id=`%s` fail", _type, sourceID)
+	}
+	targetRelations, err := l.relationManager.ListMember(_type, targetID)
+	if err != nil {
+		return result, errorWrapf(err, "relationManager.ListMember _type=`%s`, id=`%s` fail", _type, targetID)
+	}
+
+	targetRelationMap := make(map[string]dao.SubjectRelation, len(targetRelations))
+	for _, r := range targetRelations {
+		targetRelationMap[fmt.Sprintf("%s:%s", r.SubjectType, r.SubjectID)] = r
+	}
+
+	now := time.Now()
+	createdRelations := make([]dao.SubjectRelation, 0, len(sourceRelations))
+	renewedRelations := make([]dao.SubjectRelation, 0, len(sourceRelations))
+	updatedRelations := make([]dao.SubjectRelationPKPolicyExpiredAt, 0, len(sourceRelations))
+	memberGroupPKs := make([]int64, 0, len(sourceRelations))
+	for _, r := range sourceRelations {
+		if r.SubjectType == types.GroupType {
+			memberGroupPKs = append(memberGroupPKs, r.SubjectPK)
+		}
+
+		if oldRelation, ok := targetRelationMap[fmt.Sprintf("%s:%s", r.SubjectType, r.SubjectID)]; ok {
+			if r.PolicyExpiredAt > oldRelation.PolicyExpiredAt {
+				updatedRelations = append(updatedRelations, dao.SubjectRelationPKPolicyExpiredAt{
+					PK:              oldRelation.PK,
+					PolicyExpiredAt: r.PolicyExpiredAt,
+				})
+				oldRelation.PolicyExpiredAt = r.PolicyExpiredAt
+				renewedRelations = append(renewedRelations, oldRelation)
+			}
+			continue
+		}
+
+		createdRelations = append(createdRelations, dao.SubjectRelation{
+			SubjectPK:       r.SubjectPK,
+			SubjectType:     r.SubjectType,
+			SubjectID:       r.SubjectID,
+			ParentPK:        targetPK,
+			ParentType:      _type,
+			ParentID:        targetID,
+			PolicyExpiredAt: r.PolicyExpiredAt,
+			CreateAt:        now,
+		})
+	}
+
+	// 成员中的用户组加入目标用户组, 不能形成环
+	if len(memberGroupPKs) != 0 {
+		err = l.checkGroupMemberCycle(targetPK, memberGroupPKs)
+		if err != nil {
+			return result, errorWrapf(err, "checkGroupMemberCycle pk=`%d`, groupPKs=`%+v` fail",
+				targetPK, memberGroupPKs)
+		}
+	}
+
+	// 使用事务
+	tx, err := database.GenerateDefaultDBTx()
+	defer database.RollBackWithLog(tx)
+	if err != nil {
+		return result, errorWrapf(err, "define tx error")
+	}
+
+	err = l.bulkCreateRelationsInChunksWithTx(tx, createdRelations)
+	if err != nil {
+		return result, errorWrapf(err, "bulkCreateRelationsInChunksWithTx fail")
+	}
+
+	err = l.relationManager.UpdateExpiredAtWithTx(tx, updatedRelations)
+	if err != nil {
+		return result, errorWrapf(err, "relationManager.UpdateExpiredAtWithTx relations=`%+v` fail",
+			updatedRelations)
+	}
+
+	if move {
+		err = l.relationManager.BulkDeleteByParentPKs(tx, []int64{sourcePK})
+		if err != nil {
+			return result, errorWrapf(err, "relationManager.BulkDeleteByParentPKs parentPK=`%d` fail", sourcePK)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return result, errorWrapf(err, "tx commit error")
+	}
+
+	emitAddOrRenewSubjectMembersEvents(_type, targetID, createdRelations, renewedRelations)
+
+	result.Added = convertToSubjectMembers(createdRelations)
+	result.Renewed = convertToSubjectMembers(renewedRelations)
+	if move {
+		result.Removed = convertToSubjectMembers(sourceRelations)
+		emitRemoveSubjectMembersEvent(_type, sourceID, sourceRelations)
+	}
+	return result, nil
+}
+
+// bulkCreateRelationsInChunksWithTx 分批插入成员关系
+func (l *subjectService) bulkCreateRelationsInChunksWithTx(tx *sqlx.Tx, relations []dao.SubjectRelation) error {
+	for start := 0; start < len(relations); start += relationInsertChunkSize {
+		end := start + relationInsertChunkSize
+		if end > len(relations) {
+			end = len(relations)
+		}
+		err := l.relationManager.BulkCreateWithTx(tx, relations[start:end])
+		if err != nil {
+			return errorx.Wrapf(err, SubjectSVC, "bulkCreateRelationsInChunksWithTx",
+				"relationManager.BulkCreateWithTx relations=`%+v` fail", relations[start:end])
+		}
+	}
+	return nil
+}
+
+// emitAddOrRenewSubjectMembersEvents 新增的成员过期时间不同, 按过期时间分别发出新增事件
+func emitAddOrRenewSubjectMembersEvents(
+	_type, id string, createdRelations, renewedRelations []dao.SubjectRelation,
+) {
+	expiredAtMemberPKs := map[int64][]int64{}
+	for _, r := range createdRelations {
+		expiredAtMemberPKs[r.PolicyExpiredAt] = append(expiredAtMemberPKs[r.PolicyExpiredAt], r.SubjectPK)
+	}
+	for expiredAt, pks := range expiredAtMemberPKs {
+		emitSubjectChangeEvent(SubjectChangeEvent{
+			Type:            SubjectChangeEventTypeMember,
+			SubjectPKs:      pks,
+			Group:           &types.Subject{Type: _type, ID: id},
+			MemberDelta:     int64(len(pks)),
+			PolicyExpiredAt: expiredAt,
+		})
+	}
+
+	renewedPKs := make([]int64, 0, len(renewedRelations))
+	for _, r := range renewedRelations {
+		renewedPKs = append(renewedPKs, r.SubjectPK)
+	}
+	emitSubjectChangeEvent(SubjectChangeEvent{
+		Type:       SubjectChangeEventTypeMember,
+		SubjectPKs: renewedPKs,
+	})
+
+	events := newSubjectRelationEvents(SubjectRelationEventActionAdded, createdRelations)
+	events = append(events, newSubjectRelationEvents(SubjectRelationEventActionRenewed, renewedRelations)...)
+	emitSubjectRelationEvents(events)
+}
+
+// emitRemoveSubjectMembersEvent 移动后源用户组的成员被全部移除
+func emitRemoveSubjectMembersEvent(_type, id string, relations []dao.SubjectRelation) {
+	pks := make([]int64, 0, len(relations))
+	for _, r := range relations {
+		pks = append(pks, r.SubjectPK)
+	}
+	emitSubjectChangeEvent(SubjectChangeEvent{
+		Type:        SubjectChangeEventTypeMember,
+		SubjectPKs:  pks,
+		Group:       &types.Subject{Type: _type, ID: id},
+		MemberDelta: -int64(len(pks)),
+	})
+	emitSubjectRelationEvents(newSubjectRelationEvents(SubjectRelationEventActionRemoved, relations))
+}
@@ -0,0 +1,127 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectMemberCopyService", func() {
+	Describe("CopySubjectMembers cases", func() {
+		var ctl *gomock.Controller
+		var patches *gomonkey.Patches
+		var events []SubjectChangeEvent
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			events = nil
+			patches = gomonkey.ApplyFunc(emitSubjectChangeEvent, func(event SubjectChangeEvent) {
+				events = append(events, event)
+			})
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+			patches.Reset()
+		})
+
+		It("manager.GetPK fail", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().GetPK("group", "1").Return(int64(0), errors.New("get pk fail"))
+
+			svc := subjectService{manager: mockSubjectManager}
+			_, err := svc.CopySubjectMembers("group", "1", "2", false)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "get pk fail")
+		})
+
+		It("group member cycle", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockSubjectManager.EXPECT().GetPK("group", "2").Return(int64(2), nil)
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().ListMember("group", "1").Return([]dao.SubjectRelation{
+				{PK: 10, SubjectPK: 2, SubjectType: "group", SubjectID: "2", PolicyExpiredAt: 1000},
+			}, nil)
+			mockRelationManager.EXPECT().ListMember("group", "2").Return([]dao.SubjectRelation{}, nil)
+
+			svc := subjectService{manager: mockSubjectManager, relationManager: mockRelationManager}
+			_, err := svc.CopySubjectMembers("group", "1", "2", false)
+			assert.True(GinkgoT(), errors.Is(err, ErrGroupMemberCycle))
+		})
+
+		It("move ok", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockSubjectManager.EXPECT().GetPK("group", "2").Return(int64(2), nil)
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().ListMember("group", "1").Return([]dao.SubjectRelation{
+				{PK: 10, SubjectPK: 3, SubjectType: "user", SubjectID: "tom", PolicyExpiredAt: 1000},
+				{PK: 11, SubjectPK: 4, SubjectType: "user", SubjectID: "jerry", PolicyExpiredAt: 2000},
+				{PK: 12, SubjectPK: 5, SubjectType: "user", SubjectID: "bob", PolicyExpiredAt: 1000},
+			}, nil)
+			mockRelationManager.EXPECT().ListMember("group", "2").Return([]dao.SubjectRelation{
+				{PK: 20, SubjectPK: 4, SubjectType: "user", SubjectID: "jerry", PolicyExpiredAt: 1500},
+				{PK: 21, SubjectPK: 5, SubjectType: "user", SubjectID: "bob", PolicyExpiredAt: 3000},
+			}, nil)
+			mockRelationManager.EXPECT().BulkCreateWithTx(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, relations []dao.SubjectRelation) error {
+					assert.Len(GinkgoT(), relations, 1)
+					assert.Equal(GinkgoT(), int64(3), relations[0].SubjectPK)
+					assert.Equal(GinkgoT(), int64(2), relations[0].ParentPK)
+					assert.Equal(GinkgoT(), int64(1000), relations[0].PolicyExpiredAt)
+					return nil
+				})
+			mockRelationManager.EXPECT().UpdateExpiredAtWithTx(gomock.Any(), []dao.SubjectRelationPKPolicyExpiredAt{
+				{PK: 20, PolicyExpiredAt: 2000},
+			}).Return(nil)
+			mockRelationManager.EXPECT().BulkDeleteByParentPKs(gomock.Any(), []int64{1}).Return(nil)
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+			patches.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+
+			svc := subjectService{manager: mockSubjectManager, relationManager: mockRelationManager}
+			result, err := svc.CopySubjectMembers("group", "1", "2", true)
+			assert.NoError(GinkgoT(), err)
+			assert.NoError(GinkgoT(), dbMock.ExpectationsWereMet())
+
+			assert.Len(GinkgoT(), result.Added, 1)
+			assert.Equal(GinkgoT(), "tom", result.Added[0].ID)
+			assert.Equal(GinkgoT(), []types.SubjectMember{
+				{PK: 20, Type: "user", ID: "jerry", PolicyExpiredAt: 2000},
+			}, result.Renewed)
+			assert.Len(GinkgoT(), result.Removed, 3)
+
+			assert.Len(GinkgoT(), events, 3)
+			assert.Equal(GinkgoT(), SubjectChangeEvent{
+				Type:            SubjectChangeEventTypeMember,
+				SubjectPKs:      []int64{3},
+				Group:           &types.Subject{Type: "group", ID: "2"},
+				MemberDelta:     1,
+				PolicyExpiredAt: 1000,
+			}, events[0])
+			assert.Equal(GinkgoT(), []int64{4}, events[1].SubjectPKs)
+			assert.Equal(GinkgoT(), int64(-3), events[2].MemberDelta)
+		})
+	})
+})
@@ -0,0 +1,276 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"sort"
+
+	"iam/pkg/errorx"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// effectiveDepartmentChunkSize 按部门查询用户时每批的部门数量, 避免IN条件过长
+var effectiveDepartmentChunkSize = 500
+
+// ListGroupEffectiveUsersAfterPK 游标分页查询用户组展开后的有效用户, 返回pk大于afterPK的用户及下一页的游标
+// 1. 直接加入用户组的用户
+// 2. 部门成员(包含其所有下级部门)下的用户
+// 3. 嵌套加入的用户组(同鉴权的展开, 最多 MaxNestedGroupDepth 层)的以上成员
+// 只计算未过期的成员关系, 同一个用户有多个来源时取最早的过期时间, 按用户pk排序; 没有下一页时游标为0
+// NOTE: 每页只按pk顺序查询当前页的用户, 不会全量计算所有的用户
+func (l *subjectService) ListGroupEffectiveUsersAfterPK(
+	_type, id string, afterPK, limit int64,
+) ([]types.GroupEffectiveUser, int64, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "ListGroupEffectiveUsersAfterPK")
+
+	groupPK, err := l.manager.GetPK(_type, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return []types.GroupEffectiveUser{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, errorWrapf(err, "manager.GetPK _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	groupExpiredAts, err := l.listNestedMemberGroupExpiredAts(groupPK)
+	if err != nil {
+		return nil, 0, errorWrapf(err, "listNestedMemberGroupExpiredAts groupPK=`%d` fail", groupPK)
+	}
+	groupPKs := make([]int64, 0, len(groupExpiredAts))
+	for pk := range groupExpiredAts {
+		groupPKs = append(groupPKs, pk)
+	}
+
+	departmentExpiredAts, err := l.listMemberDepartmentExpiredAts(groupPKs, groupExpiredAts)
+	if err != nil {
+		return nil, 0, errorWrapf(err, "listMemberDepartmentExpiredAts groupPKs=`%+v` fail", groupPKs)
+	}
+	departmentPKs := make([]int64, 0, len(departmentExpiredAts))
+	for pk := range departmentExpiredAts {
+		departmentPKs = append(departmentPKs, pk)
+	}
+
+	// 多查询一个用户, 用于判断是否有下一页
+	userPKs, err := l.listEffectiveUserPKsAfterPK(groupPKs, departmentPKs, afterPK, limit+1)
+	if err != nil {
+		return nil, 0, errorWrapf(err,
+			"listEffectiveUserPKsAfterPK groupPKs=`%+v`, afterPK=`%d` fail", groupPKs, afterPK)
+	}
+
+	var nextPK int64
+	if int64(len(userPKs)) > limit {
+		userPKs = userPKs[:limit]
+		nextPK = userPKs[len(userPKs)-1]
+	}
+	if len(userPKs) == 0 {
+		return []types.GroupEffectiveUser{}, 0, nil
+	}
+
+	userExpiredAts, err := l.listEffectiveUserExpiredAts(userPKs, groupExpiredAts, departmentExpiredAts)
+	if err != nil {
+		return nil, 0, errorWrapf(err, "listEffectiveUserExpiredAts userPKs=`%+v` fail", userPKs)
+	}
+
+	// NOTE: 已删除的用户会被忽略, 游标仍然取查询到的最后一个用户
+	subjects, err := l.manager.ListByPKs(userPKs)
+	if err != nil {
+		return nil, 0, errorWrapf(err, "manager.ListByPKs pks=`%+v` fail", userPKs)
+	}
+	sort.Slice(subjects, func(i, j int) bool {
+		return subjects[i].PK < subjects[j].PK
+	})
+
+	users := make([]types.GroupEffectiveUser, 0, len(subjects))
+	for _, s := range subjects {
+		users = append(users, types.GroupEffectiveUser{
+			PK:              s.PK,
+			ID:              s.ID,
+			Name:            s.Name,
+			PolicyExpiredAt: userExpiredAts[s.PK],
+		})
+	}
+	return users, nextPK, nil
+}
+
+// listNestedMemberGroupExpiredAts 逐层向下展开嵌套加入用户组的成员用户组, 返回 用户组 => 过期时间, 包含用户组本身
+// 同 impls.ExpandNestedGroups: 过期时间取路径上最小的, 多条路径时取最大的
+func (l *subjectService) listNestedMemberGroupExpiredAts(groupPK int64) (map[int64]int64, error) {
+	groupExpiredAts := map[int64]int64{groupPK: math.MaxInt64}
+
+	// NOTE: 成员用户组的成员到该用户组的路径上也会经过成员用户组, 所以只需要展开 MaxNestedGroupDepth-1 层
+	frontier := map[int64]int64{groupPK: math.MaxInt64}
+	for depth := 1; depth < MaxNestedGroupDepth && len(frontier) > 0; depth++ {
+		frontierPKs := make([]int64, 0, len(frontier))
+		for pk := range frontier {
+			frontierPKs = append(frontierPKs, pk)
+		}
+
+		relations, err := l.relationManager.ListEffectMemberByParentPKs(frontierPKs, types.GroupType)
+		if err != nil {
+			return nil, err
+		}
+
+		next := map[int64]int64{}
+		for _, r := range relations {
+			expiredAt := minExpiredAt(r.PolicyExpiredAt, frontier[r.ParentPK])
+			if old, ok := groupExpiredAts[r.SubjectPK]; ok && old >= expiredAt {
+				continue
+			}
+			groupExpiredAts[r.SubjectPK] = expiredAt
+			next[r.SubjectPK] = expiredAt
+		}
+		frontier = next
+	}
+	return groupExpiredAts, nil
+}
+
+// listMemberDepartmentExpiredAts 用户组的部门成员及其所有下级部门 => 最早的过期时间
+func (l *subjectService) listMemberDepartmentExpiredAts(
+	groupPKs []int64, groupExpiredAts map[int64]int64,
+) (map[int64]int64, error) {
+	relations, err := l.relationManager.ListEffectMemberByParentPKs(groupPKs, types.DepartmentType)
+	if err != nil {
+		return nil, err
+	}
+
+	departmentExpiredAts := map[int64]int64{}
+	for _, r := range relations {
+		expiredAt := minExpiredAt(r.PolicyExpiredAt, groupExpiredAts[r.ParentPK])
+		setEarlierExpiredAt(departmentExpiredAts, r.SubjectPK, expiredAt)
+	}
+	if len(departmentExpiredAts) == 0 {
+		return departmentExpiredAts, nil
+	}
+
+	err = l.expandDescendantDepartmentExpiredAts(departmentExpiredAts)
+	if err != nil {
+		return nil, err
+	}
+	return departmentExpiredAts, nil
+}
+
+// listEffectiveUserPKsAfterPK 按pk顺序查询直接加入用户组或者属于任一部门, 且pk大于afterPK的前limit个用户
+func (l *subjectService) listEffectiveUserPKsAfterPK(
+	groupPKs, departmentPKs []int64, afterPK, limit int64,
+) ([]int64, error) {
+	userPKSet := util.NewInt64Set()
+
+	pks, err := l.relationManager.ListEffectMemberPKsAfterPKByParentPKs(groupPKs, types.UserType, afterPK, limit)
+	if err != nil {
+		return nil, err
+	}
+	userPKSet.Append(pks...)
+
+	// NOTE: 每批部门各自取前limit个用户, 合并后的前limit个即为所有部门的前limit个
+	for i := 0; i < len(departmentPKs); i += effectiveDepartmentChunkSize {
+		end := i + effectiveDepartmentChunkSize
+		if end > len(departmentPKs) {
+			end = len(departmentPKs)
+		}
+
+		pks, err = l.departmentManager.ListSubjectPKsAfterPKByDepartmentPKs(departmentPKs[i:end], afterPK, limit)
+		if err != nil {
+			return nil, err
+		}
+		userPKSet.Append(pks...)
+	}
+
+	userPKs := userPKSet.ToSlice()
+	sort.Slice(userPKs, func(i, j int) bool {
+		return userPKs[i] < userPKs[j]
+	})
+	if int64(len(userPKs)) > limit {
+		userPKs = userPKs[:limit]
+	}
+	return userPKs, nil
+}
+
+// listEffectiveUserExpiredAts 计算用户 => 所有来源中最早的过期时间
+func (l *subjectService) listEffectiveUserExpiredAts(
+	userPKs []int64, groupExpiredAts, departmentExpiredAts map[int64]int64,
+) (map[int64]int64, error) {
+	userExpiredAts := make(map[int64]int64, len(userPKs))
+
+	relations, err := l.relationManager.ListEffectRelationBySubjectPKs(userPKs)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range relations {
+		if groupExpiredAt, ok := groupExpiredAts[r.ParentPK]; ok {
+			setEarlierExpiredAt(userExpiredAts, r.SubjectPK, minExpiredAt(r.PolicyExpiredAt, groupExpiredAt))
+		}
+	}
+
+	if len(departmentExpiredAts) == 0 {
+		return userExpiredAts, nil
+	}
+
+	subjectDepartments, err := l.departmentManager.ListBySubjectPKs(userPKs)
+	if err != nil {
+		return nil, err
+	}
+	for _, sd := range subjectDepartments {
+		pks, err := util.StringToInt64Slice(sd.DepartmentPKs, ",")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pk := range pks {
+			if expiredAt, ok := departmentExpiredAts[pk]; ok {
+				setEarlierExpiredAt(userExpiredAts, sd.SubjectPK, expiredAt)
+			}
+		}
+	}
+	return userExpiredAts, nil
+}
+
+// expandDescendantDepartmentExpiredAts 逐层向下展开部门的所有下级部门, 下级部门继承上级部门的过期时间
+// NOTE: 下级部门可能通过多个上级部门继承, 只有过期时间更早时才会再次展开, 异常数据中的环也不会导致无限查询
+func (l *subjectService) expandDescendantDepartmentExpiredAts(departmentExpiredAts map[int64]int64) error {
+	current := make([]int64, 0, len(departmentExpiredAts))
+	for pk := range departmentExpiredAts {
+		current = append(current, pk)
+	}
+
+	for len(current) > 0 {
+		relations, err := l.departmentRelationManager.ListByParentPKs(current)
+		if err != nil {
+			return err
+		}
+
+		next := util.NewInt64Set()
+		for _, r := range relations {
+			expiredAt := departmentExpiredAts[r.ParentPK]
+			if old, ok := departmentExpiredAts[r.DepartmentPK]; ok && old <= expiredAt {
+				continue
+			}
+			departmentExpiredAts[r.DepartmentPK] = expiredAt
+			next.Add(r.DepartmentPK)
+		}
+		current = next.ToSlice()
+	}
+	return nil
+}
+
+func setEarlierExpiredAt(expiredAts map[int64]int64, pk, expiredAt int64) {
+	if old, ok := expiredAts[pk]; !ok || expiredAt < old {
+		expiredAts[pk] = expiredAt
+	}
+}
+
+func minExpiredAt(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,274 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectMemberEffective", func() {
+	// 2100-01-01, 未过期
+	var future int64 = 4102444800
+
+	Describe("ListGroupEffectiveUsersAfterPK", func() {
+		var ctl *gomock.Controller
+		var mockRelationManager *mock.MockSubjectRelationManager
+		var mockDepartmentManager *mock.MockSubjectDepartmentManager
+		var mockDepartmentRelationManager *mock.MockDepartmentRelationManager
+		var mockManager *mock.MockSubjectManager
+		var svc *subjectService
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			mockRelationManager = mock.NewMockSubjectRelationManager(ctl)
+			mockDepartmentManager = mock.NewMockSubjectDepartmentManager(ctl)
+			mockDepartmentRelationManager = mock.NewMockDepartmentRelationManager(ctl)
+			mockManager = mock.NewMockSubjectManager(ctl)
+			svc = &subjectService{
+				manager:                   mockManager,
+				relationManager:           mockRelationManager,
+				departmentManager:         mockDepartmentManager,
+				departmentRelationManager: mockDepartmentRelationManager,
+			}
+		})
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("group not exists", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(0), sql.ErrNoRows)
+
+			users, nextPK, err := svc.ListGroupEffectiveUsersAfterPK("group", "1", 0, 10)
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), users)
+			assert.Equal(GinkgoT(), int64(0), nextPK)
+		})
+
+		It("manager.GetPK fail", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(0), errors.New("error"))
+
+			_, _, err := svc.ListGroupEffectiveUsersAfterPK("group", "1", 0, 10)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "GetPK")
+		})
+
+		It("relationManager.ListEffectMemberByParentPKs fail", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockRelationManager.EXPECT().ListEffectMemberByParentPKs([]int64{1}, "group").Return(
+				nil, errors.New("error"),
+			)
+
+			_, _, err := svc.ListGroupEffectiveUsersAfterPK("group", "1", 0, 10)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "listNestedMemberGroupExpiredAts")
+		})
+
+		It("direct users, has next page", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockRelationManager.EXPECT().ListEffectMemberByParentPKs([]int64{1}, "group").Return(nil, nil)
+			mockRelationManager.EXPECT().ListEffectMemberByParentPKs([]int64{1}, "department").Return(nil, nil)
+			mockRelationManager.EXPECT().ListEffectMemberPKsAfterPKByParentPKs(
+				[]int64{1}, "user", int64(0), int64(3),
+			).Return([]int64{1, 2, 3}, nil)
+			mockRelationManager.EXPECT().ListEffectRelationBySubjectPKs([]int64{1, 2}).Return(
+				[]dao.EffectSubjectRelation{
+					{SubjectPK: 1, ParentPK: 1, PolicyExpiredAt: future + 1},
+					{SubjectPK: 2, ParentPK: 1, PolicyExpiredAt: future},
+					// 其他用户组的成员关系不影响
+					{SubjectPK: 2, ParentPK: 99, PolicyExpiredAt: 100},
+				}, nil,
+			)
+			mockManager.EXPECT().ListByPKs([]int64{1, 2}).Return([]dao.Subject{
+				{PK: 2, Type: "user", ID: "tom", Name: "tom"},
+				{PK: 1, Type: "user", ID: "admin", Name: "admin"},
+			}, nil)
+
+			users, nextPK, err := svc.ListGroupEffectiveUsersAfterPK("group", "1", 0, 2)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(2), nextPK)
+			assert.Equal(GinkgoT(), []types.GroupEffectiveUser{
+				{PK: 1, ID: "admin", Name: "admin", PolicyExpiredAt: future + 1},
+				{PK: 2, ID: "tom", Name: "tom", PolicyExpiredAt: future},
+			}, users)
+		})
+
+		It("nested groups and department members, earliest expired_at", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockRelationManager.EXPECT().ListEffectMemberByParentPKs([]int64{1}, "group").Return(
+				[]dao.EffectSubjectRelation{{SubjectPK: 2, ParentPK: 1, PolicyExpiredAt: future + 100}}, nil,
+			)
+			mockRelationManager.EXPECT().ListEffectMemberByParentPKs([]int64{2}, "group").Return(
+				[]dao.EffectSubjectRelation{
+					// 异常数据中的环
+					{SubjectPK: 1, ParentPK: 2, PolicyExpiredAt: future},
+					{SubjectPK: 3, ParentPK: 2, PolicyExpiredAt: future + 500},
+				}, nil,
+			)
+			mockRelationManager.EXPECT().ListEffectMemberByParentPKs([]int64{3}, "group").Return(nil, nil)
+			mockRelationManager.EXPECT().ListEffectMemberByParentPKs(gomock.Any(), "department").Return(
+				[]dao.EffectSubjectRelation{{SubjectPK: 10, ParentPK: 3, PolicyExpiredAt: future + 200}}, nil,
+			)
+			mockDepartmentRelationManager.EXPECT().ListByParentPKs([]int64{10}).Return([]dao.DepartmentRelation{
+				{DepartmentPK: 11, ParentPK: 10},
+			}, nil)
+			mockDepartmentRelationManager.EXPECT().ListByParentPKs([]int64{11}).Return(nil, nil)
+			mockRelationManager.EXPECT().ListEffectMemberPKsAfterPKByParentPKs(
+				gomock.Any(), "user", int64(5), int64(11),
+			).Return([]int64{7}, nil)
+			mockDepartmentManager.EXPECT().ListSubjectPKsAfterPKByDepartmentPKs(
+				gomock.Any(), int64(5), int64(11),
+			).Return([]int64{6, 7}, nil)
+			mockRelationManager.EXPECT().ListEffectRelationBySubjectPKs([]int64{6, 7}).Return(
+				[]dao.EffectSubjectRelation{{SubjectPK: 7, ParentPK: 2, PolicyExpiredAt: future + 50}}, nil,
+			)
+			mockDepartmentManager.EXPECT().ListBySubjectPKs([]int64{6, 7}).Return([]dao.SubjectDepartment{
+				{SubjectPK: 6, DepartmentPKs: "11"},
+				{SubjectPK: 7, DepartmentPKs: "10,30"},
+			}, nil)
+			mockManager.EXPECT().ListByPKs([]int64{6, 7}).Return([]dao.Subject{
+				{PK: 6, Type: "user", ID: "tom", Name: "tom"},
+				{PK: 7, Type: "user", ID: "jerry", Name: "jerry"},
+			}, nil)
+
+			users, nextPK, err := svc.ListGroupEffectiveUsersAfterPK("group", "1", 5, 10)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(0), nextPK)
+			// 部门10通过用户组3 -> 2 -> 1加入, 过期时间取路径上最小的
+			assert.Equal(GinkgoT(), []types.GroupEffectiveUser{
+				{PK: 6, ID: "tom", Name: "tom", PolicyExpiredAt: future + 100},
+				{PK: 7, ID: "jerry", Name: "jerry", PolicyExpiredAt: future + 50},
+			}, users)
+		})
+
+		It("empty page", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockRelationManager.EXPECT().ListEffectMemberByParentPKs([]int64{1}, "group").Return(nil, nil)
+			mockRelationManager.EXPECT().ListEffectMemberByParentPKs([]int64{1}, "department").Return(nil, nil)
+			mockRelationManager.EXPECT().ListEffectMemberPKsAfterPKByParentPKs(
+				[]int64{1}, "user", int64(10), int64(11),
+			).Return(nil, nil)
+
+			users, nextPK, err := svc.ListGroupEffectiveUsersAfterPK("group", "1", 10, 10)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(0), nextPK)
+			assert.Empty(GinkgoT(), users)
+		})
+
+		It("departmentManager.ListSubjectPKsAfterPKByDepartmentPKs fail", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockRelationManager.EXPECT().ListEffectMemberByParentPKs([]int64{1}, "group").Return(nil, nil)
+			mockRelationManager.EXPECT().ListEffectMemberByParentPKs([]int64{1}, "department").Return(
+				[]dao.EffectSubjectRelation{{SubjectPK: 10, ParentPK: 1, PolicyExpiredAt: future}}, nil,
+			)
+			mockDepartmentRelationManager.EXPECT().ListByParentPKs([]int64{10}).Return(nil, nil)
+			mockRelationManager.EXPECT().ListEffectMemberPKsAfterPKByParentPKs(
+				[]int64{1}, "user", int64(0), int64(11),
+			).Return(nil, nil)
+			mockDepartmentManager.EXPECT().ListSubjectPKsAfterPKByDepartmentPKs(
+				[]int64{10}, int64(0), int64(11),
+			).Return(nil, errors.New("error"))
+
+			_, _, err := svc.ListGroupEffectiveUsersAfterPK("group", "1", 0, 10)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "listEffectiveUserPKsAfterPK")
+		})
+
+		It("manager.ListByPKs fail", func() {
+			mockManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockRelationManager.EXPECT().ListEffectMemberByParentPKs([]int64{1}, "group").Return(nil, nil)
+			mockRelationManager.EXPECT().ListEffectMemberByParentPKs([]int64{1}, "department").Return(nil, nil)
+			mockRelationManager.EXPECT().ListEffectMemberPKsAfterPKByParentPKs(
+				[]int64{1}, "user", int64(0), int64(11),
+			).Return([]int64{1}, nil)
+			mockRelationManager.EXPECT().ListEffectRelationBySubjectPKs([]int64{1}).Return(nil, nil)
+			mockManager.EXPECT().ListByPKs([]int64{1}).Return(nil, errors.New("error"))
+
+			_, _, err := svc.ListGroupEffectiveUsersAfterPK("group", "1", 0, 10)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListByPKs")
+		})
+	})
+
+	Describe("listEffectiveUserPKsAfterPK", func() {
+		var ctl *gomock.Controller
+		var mockRelationManager *mock.MockSubjectRelationManager
+		var mockDepartmentManager *mock.MockSubjectDepartmentManager
+		var svc *subjectService
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			mockRelationManager = mock.NewMockSubjectRelationManager(ctl)
+			mockDepartmentManager = mock.NewMockSubjectDepartmentManager(ctl)
+			svc = &subjectService{
+				relationManager:   mockRelationManager,
+				departmentManager: mockDepartmentManager,
+			}
+			effectiveDepartmentChunkSize = 1
+		})
+		AfterEach(func() {
+			effectiveDepartmentChunkSize = 500
+			ctl.Finish()
+		})
+
+		It("merge the chunks", func() {
+			mockRelationManager.EXPECT().ListEffectMemberPKsAfterPKByParentPKs(
+				[]int64{1}, "user", int64(0), int64(2),
+			).Return([]int64{5}, nil)
+			mockDepartmentManager.EXPECT().ListSubjectPKsAfterPKByDepartmentPKs(
+				[]int64{10}, int64(0), int64(2),
+			).Return([]int64{3, 6}, nil)
+			mockDepartmentManager.EXPECT().ListSubjectPKsAfterPKByDepartmentPKs(
+				[]int64{11}, int64(0), int64(2),
+			).Return([]int64{3, 4}, nil)
+
+			userPKs, err := svc.listEffectiveUserPKsAfterPK([]int64{1}, []int64{10, 11}, 0, 2)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []int64{3, 4}, userPKs)
+		})
+	})
+
+	Describe("expandDescendantDepartmentExpiredAts", func() {
+		var ctl *gomock.Controller
+		var mockDepartmentRelationManager *mock.MockDepartmentRelationManager
+		var svc *subjectService
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			mockDepartmentRelationManager = mock.NewMockDepartmentRelationManager(ctl)
+			svc = &subjectService{
+				departmentRelationManager: mockDepartmentRelationManager,
+			}
+		})
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("inherit the earliest expired_at", func() {
+			// 部门12同时是部门10和部门11的下级部门, 部门11本身也是成员, 且过期时间更早
+			mockDepartmentRelationManager.EXPECT().ListByParentPKs(gomock.Any()).Return([]dao.DepartmentRelation{
+				{DepartmentPK: 11, ParentPK: 10},
+				{DepartmentPK: 12, ParentPK: 10},
+				{DepartmentPK: 12, ParentPK: 11},
+			}, nil)
+			mockDepartmentRelationManager.EXPECT().ListByParentPKs([]int64{12}).Return(nil, nil)
+
+			expiredAts := map[int64]int64{10: 300, 11: 100}
+			err := svc.expandDescendantDepartmentExpiredAts(expiredAts)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[int64]int64{10: 300, 11: 100, 12: 100}, expiredAts)
+		})
+	})
+})
@@ -0,0 +1,165 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/errorx"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+/*
+ * > 用户组成员变更的审计记录
+ *
+ * 1. 成员变更(添加/删除/续期)成功后, 调用RecordSubjectMemberEvents将记录放入本地的缓冲队列, 不阻塞请求
+ * 2. 后台定时将队列中的记录批量写入subject_member_event表, 服务退出前会写入剩余的记录
+ * 3. 队列满时丢弃记录并上报sentry, 审计记录的写入不影响成员变更本身
+ */
+
+// SubjectMemberEvent的变更类型
+const (
+	SubjectMemberEventActionAdded   = "added"
+	SubjectMemberEventActionRemoved = "removed"
+	SubjectMemberEventActionRenewed = "renewed"
+)
+
+var (
+	memberEventFlushInterval = 1 * time.Second
+	memberEventBatchSize     = 500
+	memberEventBufferSize    = 10000
+)
+
+var memberEventQueue = make(chan dao.SubjectMemberEvent, memberEventBufferSize)
+
+// RecordSubjectMemberEvents 将成员变更记录放入缓冲队列, 由RunSubjectMemberEventWriter异步写入DB
+func RecordSubjectMemberEvents(events []types.SubjectMemberEvent) {
+	now := time.Now()
+	for _, e := range events {
+		createdAt := e.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = now
+		}
+
+		daoEvent := dao.SubjectMemberEvent{
+			GroupType:       e.GroupType,
+			GroupID:         e.GroupID,
+			MemberType:      e.MemberType,
+			MemberID:        e.MemberID,
+			Action:          e.Action,
+			PolicyExpiredAt: e.PolicyExpiredAt,
+			Operator:        e.Operator,
+			Source:          e.Source,
+			CreatedAt:       createdAt,
+		}
+
+		select {
+		case memberEventQueue <- daoEvent:
+		default:
+			log.Errorf("the subject member event queue is full, drop the event=`%+v`", daoEvent)
+			util.ReportToSentry(
+				"subject member event: queue full",
+				map[string]interface{}{
+					"group":  daoEvent.GroupType + ":" + daoEvent.GroupID,
+					"member": daoEvent.MemberType + ":" + daoEvent.MemberID,
+					"action": daoEvent.Action,
+				},
+			)
+		}
+	}
+}
+
+// RunSubjectMemberEventWriter 定时将队列中的成员变更记录批量写入DB, 阻塞直到ctx结束, 结束前会写入剩余的记录
+func RunSubjectMemberEventWriter(ctx context.Context) {
+	manager := dao.NewSubjectMemberEventManager()
+
+	ticker := time.NewTicker(memberEventFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushSubjectMemberEvents(manager)
+			return
+		case <-ticker.C:
+			flushSubjectMemberEvents(manager)
+		}
+	}
+}
+
+// flushSubjectMemberEvents 分批写入当前队列中的所有记录, 写入失败的批次记录日志后丢弃
+func flushSubjectMemberEvents(manager dao.SubjectMemberEventManager) {
+	for n := len(memberEventQueue); n > 0; {
+		size := memberEventBatchSize
+		if n < size {
+			size = n
+		}
+		n -= size
+
+		events := make([]dao.SubjectMemberEvent, 0, size)
+		for i := 0; i < size; i++ {
+			events = append(events, <-memberEventQueue)
+		}
+
+		if err := manager.BulkCreate(events); err != nil {
+			log.WithError(err).Errorf("write %d subject member events fail", len(events))
+			util.ReportToSentry(
+				"subject member event: write fail",
+				map[string]interface{}{
+					"count": len(events),
+					"error": err.Error(),
+				},
+			)
+		}
+	}
+}
+
+// GetSubjectMemberEventCount ...
+func (l *subjectService) GetSubjectMemberEventCount(filter types.SubjectMemberEventFilter) (int64, error) {
+	count, err := l.memberEventManager.GetCount(dao.SubjectMemberEventFilter(filter))
+	if err != nil {
+		return count, errorx.Wrapf(err, SubjectSVC, "GetSubjectMemberEventCount",
+			"memberEventManager.GetCount filter=`%+v` fail", filter)
+	}
+	return count, nil
+}
+
+// ListPagingSubjectMemberEvent 查询用户组成员的变更记录, 最近的在前
+func (l *subjectService) ListPagingSubjectMemberEvent(
+	filter types.SubjectMemberEventFilter, limit, offset int64,
+) ([]types.SubjectMemberEvent, error) {
+	daoEvents, err := l.memberEventManager.ListPaging(dao.SubjectMemberEventFilter(filter), limit, offset)
+	if err != nil {
+		return nil, errorx.Wrapf(err, SubjectSVC, "ListPagingSubjectMemberEvent",
+			"memberEventManager.ListPaging filter=`%+v`, limit=`%d`, offset=`%d` fail", filter, limit, offset)
+	}
+
+	events := make([]types.SubjectMemberEvent, 0, len(daoEvents))
+	for _, e := range daoEvents {
+		events = append(events, types.SubjectMemberEvent{
+			GroupType:       e.GroupType,
+			GroupID:         e.GroupID,
+			MemberType:      e.MemberType,
+			MemberID:        e.MemberID,
+			Action:          e.Action,
+			PolicyExpiredAt: e.PolicyExpiredAt,
+			Operator:        e.Operator,
+			Source:          e.Source,
+			CreatedAt:       e.CreatedAt,
+		})
+	}
+	return events, nil
+}
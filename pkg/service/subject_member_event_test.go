@@ -0,0 +1,138 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectMemberEventService", func() {
+	Describe("RecordSubjectMemberEvents/flushSubjectMemberEvents cases", func() {
+		var ctl *gomock.Controller
+		var oldQueue chan dao.SubjectMemberEvent
+		var oldBatchSize int
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			oldQueue = memberEventQueue
+			oldBatchSize = memberEventBatchSize
+			memberEventQueue = make(chan dao.SubjectMemberEvent, 3)
+			memberEventBatchSize = 2
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+			memberEventQueue = oldQueue
+			memberEventBatchSize = oldBatchSize
+		})
+
+		It("flush in batches", func() {
+			createdAt := time.Unix(1629000000, 0)
+			RecordSubjectMemberEvents([]types.SubjectMemberEvent{
+				{
+					GroupType: "group", GroupID: "1", MemberType: "user", MemberID: "a",
+					Action: "added", CreatedAt: createdAt,
+				},
+				{GroupType: "group", GroupID: "1", MemberType: "user", MemberID: "b", Action: "added"},
+				{GroupType: "group", GroupID: "1", MemberType: "user", MemberID: "c", Action: "removed"},
+				// the queue is full, dropped
+				{GroupType: "group", GroupID: "1", MemberType: "user", MemberID: "d", Action: "removed"},
+			})
+			assert.Len(GinkgoT(), memberEventQueue, 3)
+
+			var written []dao.SubjectMemberEvent
+			mockManager := mock.NewMockSubjectMemberEventManager(ctl)
+			mockManager.EXPECT().BulkCreate(gomock.Any()).DoAndReturn(
+				func(events []dao.SubjectMemberEvent) error {
+					written = append(written, events...)
+					return nil
+				},
+			).Times(2)
+
+			flushSubjectMemberEvents(mockManager)
+
+			assert.Len(GinkgoT(), memberEventQueue, 0)
+			assert.Len(GinkgoT(), written, 3)
+			assert.Equal(GinkgoT(), "a", written[0].MemberID)
+			assert.Equal(GinkgoT(), createdAt, written[0].CreatedAt)
+			assert.Equal(GinkgoT(), "c", written[2].MemberID)
+			assert.False(GinkgoT(), written[2].CreatedAt.IsZero())
+		})
+
+		It("write fail, dropped", func() {
+			RecordSubjectMemberEvents([]types.SubjectMemberEvent{
+				{GroupType: "group", GroupID: "1", MemberType: "user", MemberID: "a", Action: "renewed"},
+			})
+
+			mockManager := mock.NewMockSubjectMemberEventManager(ctl)
+			mockManager.EXPECT().BulkCreate(gomock.Any()).Return(errors.New("error"))
+
+			flushSubjectMemberEvents(mockManager)
+			assert.Len(GinkgoT(), memberEventQueue, 0)
+		})
+
+		It("empty queue", func() {
+			mockManager := mock.NewMockSubjectMemberEventManager(ctl)
+
+			flushSubjectMemberEvents(mockManager)
+		})
+	})
+
+	Describe("ListPagingSubjectMemberEvent cases", func() {
+		var ctl *gomock.Controller
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("memberEventManager.ListPaging fail", func() {
+			mockManager := mock.NewMockSubjectMemberEventManager(ctl)
+			mockManager.EXPECT().ListPaging(dao.SubjectMemberEventFilter{GroupType: "group", GroupID: "1"},
+				int64(10), int64(0)).Return(nil, errors.New("error"))
+
+			svc := subjectService{memberEventManager: mockManager}
+			_, err := svc.ListPagingSubjectMemberEvent(
+				types.SubjectMemberEventFilter{GroupType: "group", GroupID: "1"}, 10, 0)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListPaging")
+		})
+
+		It("ok", func() {
+			now := time.Now()
+			mockManager := mock.NewMockSubjectMemberEventManager(ctl)
+			mockManager.EXPECT().ListPaging(dao.SubjectMemberEventFilter{Operator: "admin"},
+				int64(10), int64(0)).Return([]dao.SubjectMemberEvent{{
+				PK: 1, GroupType: "group", GroupID: "1", MemberType: "user", MemberID: "tom",
+				Action: "added", PolicyExpiredAt: 4102444800, Operator: "admin", Source: "bk_iam", CreatedAt: now,
+			}}, nil)
+
+			svc := subjectService{memberEventManager: mockManager}
+			events, err := svc.ListPagingSubjectMemberEvent(types.SubjectMemberEventFilter{Operator: "admin"}, 10, 0)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.SubjectMemberEvent{{
+				GroupType: "group", GroupID: "1", MemberType: "user", MemberID: "tom",
+				Action: "added", PolicyExpiredAt: 4102444800, Operator: "admin", Source: "bk_iam", CreatedAt: now,
+			}}, events)
+		})
+	})
+})
@@ -0,0 +1,179 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"sort"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/errorx"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// redundancyScanPageSize 全量扫描用户部门关系的分页大小
+var redundancyScanPageSize int64 = 1000
+
+// ListRedundantMembers 分析用户组的冗余成员关系
+// 1. 用户直接加入了用户组, 同时所在的部门也是用户组的成员
+// 2. 用户通过多个部门继承了用户组
+// NOTE: 用户组有多个部门成员时, 需要全量扫描用户部门关系, 只用于管理类的分析接口
+func (l *subjectService) ListRedundantMembers(_type, id string) ([]types.RedundantMember, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "ListRedundantMembers")
+
+	relations, err := l.relationManager.ListMember(_type, id)
+	if err != nil {
+		return nil, errorWrapf(err, "relationManager.ListMember _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	departmentExpiredAts := map[int64]int64{}
+	directUsers := map[int64]dao.SubjectRelation{}
+	for _, r := range relations {
+		switch r.SubjectType {
+		case types.DepartmentType:
+			departmentExpiredAts[r.SubjectPK] = r.PolicyExpiredAt
+		case types.UserType:
+			directUsers[r.SubjectPK] = r
+		}
+	}
+
+	// 没有部门成员, 不会有冗余
+	if len(departmentExpiredAts) == 0 {
+		return []types.RedundantMember{}, nil
+	}
+
+	var subjectDepartments []dao.SubjectDepartment
+	if len(departmentExpiredAts) == 1 {
+		// 只有一个部门成员时, 只有直接加入的用户可能冗余
+		if len(directUsers) == 0 {
+			return []types.RedundantMember{}, nil
+		}
+
+		userPKs := make([]int64, 0, len(directUsers))
+		for pk := range directUsers {
+			userPKs = append(userPKs, pk)
+		}
+		subjectDepartments, err = l.departmentManager.ListBySubjectPKs(userPKs)
+		if err != nil {
+			return nil, errorWrapf(err, "departmentManager.ListBySubjectPKs pks=`%+v` fail", userPKs)
+		}
+	} else {
+		subjectDepartments, err = l.listAllSubjectDepartments()
+		if err != nil {
+			return nil, errorWrapf(err, "listAllSubjectDepartments fail")
+		}
+	}
+
+	// 用户 => 继承了用户组的部门
+	userInheritedDepartmentPKs := map[int64][]int64{}
+	for _, sd := range subjectDepartments {
+		departmentPKs, err := util.StringToInt64Slice(sd.DepartmentPKs, ",")
+		if err != nil {
+			return nil, errorWrapf(err, "util.StringToInt64Slice s=`%s` fail", sd.DepartmentPKs)
+		}
+
+		inherited := make([]int64, 0, len(departmentPKs))
+		for _, pk := range departmentPKs {
+			if _, ok := departmentExpiredAts[pk]; ok {
+				inherited = append(inherited, pk)
+			}
+		}
+
+		_, isDirect := directUsers[sd.SubjectPK]
+		if (isDirect && len(inherited) > 0) || len(inherited) > 1 {
+			userInheritedDepartmentPKs[sd.SubjectPK] = inherited
+		}
+	}
+
+	if len(userInheritedDepartmentPKs) == 0 {
+		return []types.RedundantMember{}, nil
+	}
+
+	// 查询用户与部门的id/name
+	pks := make([]int64, 0, len(userInheritedDepartmentPKs)+len(departmentExpiredAts))
+	for pk := range userInheritedDepartmentPKs {
+		pks = append(pks, pk)
+	}
+	for pk := range departmentExpiredAts {
+		pks = append(pks, pk)
+	}
+	subjects, err := l.manager.ListByPKs(pks)
+	if err != nil {
+		return nil, errorWrapf(err, "manager.ListByPKs pks=`%+v` fail", pks)
+	}
+	subjectMap := make(map[int64]dao.Subject, len(subjects))
+	for _, s := range subjects {
+		subjectMap[s.PK] = s
+	}
+
+	members := make([]types.RedundantMember, 0, len(userInheritedDepartmentPKs))
+	for userPK, departmentPKs := range userInheritedDepartmentPKs {
+		user, ok := subjectMap[userPK]
+		// the user may be deleted
+		if !ok {
+			continue
+		}
+
+		member := types.RedundantMember{
+			Type:        user.Type,
+			ID:          user.ID,
+			Name:        user.Name,
+			Departments: make([]types.InheritedDepartment, 0, len(departmentPKs)),
+		}
+
+		var maxDepartmentExpiredAt int64
+		for _, pk := range departmentPKs {
+			department := subjectMap[pk]
+			expiredAt := departmentExpiredAts[pk]
+			member.Departments = append(member.Departments, types.InheritedDepartment{
+				ID:              department.ID,
+				Name:            department.Name,
+				PolicyExpiredAt: expiredAt,
+			})
+			if expiredAt > maxDepartmentExpiredAt {
+				maxDepartmentExpiredAt = expiredAt
+			}
+		}
+		sort.Slice(member.Departments, func(i, j int) bool {
+			return member.Departments[i].ID < member.Departments[j].ID
+		})
+
+		if r, ok := directUsers[userPK]; ok {
+			member.PolicyExpiredAt = r.PolicyExpiredAt
+			member.Removable = maxDepartmentExpiredAt >= r.PolicyExpiredAt
+		}
+
+		members = append(members, member)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].ID < members[j].ID
+	})
+	return members, nil
+}
+
+func (l *subjectService) listAllSubjectDepartments() ([]dao.SubjectDepartment, error) {
+	subjectDepartments := []dao.SubjectDepartment{}
+	var offset int64
+	for {
+		page, err := l.departmentManager.ListPaging(redundancyScanPageSize, offset)
+		if err != nil {
+			return nil, errorx.Wrapf(err, SubjectSVC, "listAllSubjectDepartments",
+				"departmentManager.ListPaging limit=`%d`, offset=`%d` fail", redundancyScanPageSize, offset)
+		}
+
+		subjectDepartments = append(subjectDepartments, page...)
+		if int64(len(page)) < redundancyScanPageSize {
+			return subjectDepartments, nil
+		}
+		offset += redundancyScanPageSize
+	}
+}
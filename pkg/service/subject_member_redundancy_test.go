@@ -0,0 +1,156 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectMemberRedundancy", func() {
+
+	Describe("ListRedundantMembers", func() {
+		var ctl *gomock.Controller
+		var mockRelationManager *mock.MockSubjectRelationManager
+		var mockDepartmentManager *mock.MockSubjectDepartmentManager
+		var mockManager *mock.MockSubjectManager
+		var svc *subjectService
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			mockRelationManager = mock.NewMockSubjectRelationManager(ctl)
+			mockDepartmentManager = mock.NewMockSubjectDepartmentManager(ctl)
+			mockManager = mock.NewMockSubjectManager(ctl)
+			svc = &subjectService{
+				manager:           mockManager,
+				relationManager:   mockRelationManager,
+				departmentManager: mockDepartmentManager,
+			}
+		})
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("relationManager.ListMember fail", func() {
+			mockRelationManager.EXPECT().ListMember("group", "1").Return(nil, errors.New("error"))
+
+			_, err := svc.ListRedundantMembers("group", "1")
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListMember")
+		})
+
+		It("no department member", func() {
+			mockRelationManager.EXPECT().ListMember("group", "1").Return([]dao.SubjectRelation{
+				{SubjectPK: 1, SubjectType: "user", SubjectID: "admin", PolicyExpiredAt: 100},
+			}, nil)
+
+			members, err := svc.ListRedundantMembers("group", "1")
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), members)
+		})
+
+		It("one department member, direct users", func() {
+			mockRelationManager.EXPECT().ListMember("group", "1").Return([]dao.SubjectRelation{
+				{SubjectPK: 1, SubjectType: "user", SubjectID: "admin", PolicyExpiredAt: 100},
+				{SubjectPK: 2, SubjectType: "user", SubjectID: "tom", PolicyExpiredAt: 300},
+				{SubjectPK: 3, SubjectType: "user", SubjectID: "jerry", PolicyExpiredAt: 100},
+				{SubjectPK: 10, SubjectType: "department", SubjectID: "d10", PolicyExpiredAt: 200},
+			}, nil)
+			mockDepartmentManager.EXPECT().ListBySubjectPKs(gomock.Any()).Return([]dao.SubjectDepartment{
+				{SubjectPK: 1, DepartmentPKs: "10,11"},
+				{SubjectPK: 2, DepartmentPKs: "10"},
+				{SubjectPK: 3, DepartmentPKs: "11"},
+			}, nil)
+			mockManager.EXPECT().ListByPKs(gomock.Any()).Return([]dao.Subject{
+				{PK: 1, Type: "user", ID: "admin", Name: "admin"},
+				{PK: 2, Type: "user", ID: "tom", Name: "tom"},
+				{PK: 10, Type: "department", ID: "d10", Name: "dept10"},
+			}, nil)
+
+			members, err := svc.ListRedundantMembers("group", "1")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.RedundantMember{
+				{
+					Type:            "user",
+					ID:              "admin",
+					Name:            "admin",
+					PolicyExpiredAt: 100,
+					Departments:     []types.InheritedDepartment{{ID: "d10", Name: "dept10", PolicyExpiredAt: 200}},
+					Removable:       true,
+				},
+				{
+					Type:            "user",
+					ID:              "tom",
+					Name:            "tom",
+					PolicyExpiredAt: 300,
+					Departments:     []types.InheritedDepartment{{ID: "d10", Name: "dept10", PolicyExpiredAt: 200}},
+					Removable:       false,
+				},
+			}, members)
+		})
+
+		It("multiple department members, scan all", func() {
+			redundancyScanPageSize = 2
+			defer func() {
+				redundancyScanPageSize = 1000
+			}()
+
+			mockRelationManager.EXPECT().ListMember("group", "1").Return([]dao.SubjectRelation{
+				{SubjectPK: 10, SubjectType: "department", SubjectID: "d10", PolicyExpiredAt: 200},
+				{SubjectPK: 11, SubjectType: "department", SubjectID: "d11", PolicyExpiredAt: 300},
+			}, nil)
+			mockDepartmentManager.EXPECT().ListPaging(int64(2), int64(0)).Return([]dao.SubjectDepartment{
+				{SubjectPK: 1, DepartmentPKs: "10,11"},
+				{SubjectPK: 2, DepartmentPKs: "10"},
+			}, nil)
+			mockDepartmentManager.EXPECT().ListPaging(int64(2), int64(2)).Return([]dao.SubjectDepartment{
+				{SubjectPK: 3, DepartmentPKs: "12"},
+			}, nil)
+			mockManager.EXPECT().ListByPKs(gomock.Any()).Return([]dao.Subject{
+				{PK: 1, Type: "user", ID: "admin", Name: "admin"},
+				{PK: 10, Type: "department", ID: "d10", Name: "dept10"},
+				{PK: 11, Type: "department", ID: "d11", Name: "dept11"},
+			}, nil)
+
+			members, err := svc.ListRedundantMembers("group", "1")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.RedundantMember{
+				{
+					Type: "user",
+					ID:   "admin",
+					Name: "admin",
+					Departments: []types.InheritedDepartment{
+						{ID: "d10", Name: "dept10", PolicyExpiredAt: 200},
+						{ID: "d11", Name: "dept11", PolicyExpiredAt: 300},
+					},
+				},
+			}, members)
+		})
+
+		It("departmentManager.ListPaging fail", func() {
+			mockRelationManager.EXPECT().ListMember("group", "1").Return([]dao.SubjectRelation{
+				{SubjectPK: 10, SubjectType: "department", SubjectID: "d10", PolicyExpiredAt: 200},
+				{SubjectPK: 11, SubjectType: "department", SubjectID: "d11", PolicyExpiredAt: 300},
+			}, nil)
+			mockDepartmentManager.EXPECT().ListPaging(gomock.Any(), gomock.Any()).Return(nil, errors.New("error"))
+
+			_, err := svc.ListRedundantMembers("group", "1")
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListPaging")
+		})
+	})
+})
@@ -0,0 +1,339 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"fmt"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"iam/pkg/database"
+	"iam/pkg/database/dao"
+	"iam/pkg/errorx"
+	"iam/pkg/service/types"
+)
+
+/*
+ * > 用户组成员列表的快照与恢复, 用于误操作(如批量删除成员)后的回滚
+ *
+ * 1. 快照保存用户组当前所有成员及其过期时间
+ * 2. 恢复时对比快照与当前成员: 移除不在快照中的成员, 加入快照中有但当前没有的成员, 恢复过期时间不同的成员
+ * 3. 恢复前会在同一事务中为当前成员列表创建一个快照, 恢复错误时可以再次恢复
+ */
+
+// GetSubjectMemberSnapshot 查询快照及其成员列表
+func (l *subjectService) GetSubjectMemberSnapshot(pk int64) (snapshot types.SubjectMemberSnapshot, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "GetSubjectMemberSnapshot")
+
+	daoSnapshot, err := l.memberSnapshotManager.Get(pk)
+	if err != nil {
+		return snapshot, errorWrapf(err, "memberSnapshotManager.Get pk=`%d` fail", pk)
+	}
+
+	var members []types.SubjectMemberSnapshotMember
+	err = jsoniter.UnmarshalFromString(daoSnapshot.Members, &members)
+	if err != nil {
+		return snapshot, errorWrapf(err, "unmarshal snapshot members pk=`%d` fail", pk)
+	}
+
+	return types.SubjectMemberSnapshot{
+		PK:          daoSnapshot.PK,
+		GroupType:   daoSnapshot.GroupType,
+		GroupID:     daoSnapshot.GroupID,
+		MemberCount: daoSnapshot.MemberCount,
+		Members:     members,
+		Operator:    daoSnapshot.Operator,
+		Source:      daoSnapshot.Source,
+		CreatedAt:   daoSnapshot.CreatedAt,
+	}, nil
+}
+
+// ListSubjectMemberSnapshots 查询用户组的快照, 不包含成员列表, 最近的在前
+func (l *subjectService) ListSubjectMemberSnapshots(_type, id string) ([]types.SubjectMemberSnapshot, error) {
+	daoSnapshots, err := l.memberSnapshotManager.ListThinByGroup(_type, id)
+	if err != nil {
+		return nil, errorx.Wrapf(err, SubjectSVC, "ListSubjectMemberSnapshots",
+			"memberSnapshotManager.ListThinByGroup _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	snapshots := make([]types.SubjectMemberSnapshot, 0, len(daoSnapshots))
+	for _, s := range daoSnapshots {
+		snapshots = append(snapshots, types.SubjectMemberSnapshot{
+			PK:          s.PK,
+			GroupType:   s.GroupType,
+			GroupID:     s.GroupID,
+			MemberCount: s.MemberCount,
+			Operator:    s.Operator,
+			Source:      s.Source,
+			CreatedAt:   s.CreatedAt,
+		})
+	}
+	return snapshots, nil
+}
+
+// CreateSubjectMemberSnapshot 为用户组当前的所有成员创建快照
+func (l *subjectService) CreateSubjectMemberSnapshot(
+	_type, id, operator, source string,
+) (snapshot types.SubjectMemberSnapshot, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "CreateSubjectMemberSnapshot")
+
+	// 用户组必须存在
+	_, err = l.manager.GetPK(_type, id)
+	if err != nil {
+		return snapshot, errorWrapf(err, "manager.GetPK _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	relations, err := l.relationManager.ListMember(_type, id)
+	if err != nil {
+		return snapshot, errorWrapf(err, "relationManager.ListMember _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	daoSnapshot, err := newSubjectMemberSnapshot(_type, id, relations, operator, source)
+	if err != nil {
+		return snapshot, errorWrapf(err, "newSubjectMemberSnapshot _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	// 使用事务
+	tx, err := database.GenerateDefaultDBTx()
+	defer database.RollBackWithLog(tx)
+	if err != nil {
+		return snapshot, errorWrapf(err, "define tx error")
+	}
+
+	pk, err := l.memberSnapshotManager.CreateWithTx(tx, daoSnapshot)
+	if err != nil {
+		return snapshot, errorWrapf(err, "memberSnapshotManager.CreateWithTx _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return snapshot, errorWrapf(err, "tx commit error")
+	}
+
+	return types.SubjectMemberSnapshot{
+		PK:          pk,
+		GroupType:   _type,
+		GroupID:     id,
+		MemberCount: daoSnapshot.MemberCount,
+		Operator:    operator,
+		Source:      source,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// RestoreSubjectMemberSnapshot 在一个事务中将用户组的成员恢复为快照中的成员
+func (l *subjectService) RestoreSubjectMemberSnapshot(
+	pk int64, operator, source string,
+) (result types.SubjectMemberRestoreResult, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "RestoreSubjectMemberSnapshot")
+
+	snapshot, err := l.GetSubjectMemberSnapshot(pk)
+	if err != nil {
+		return result, errorWrapf(err, "GetSubjectMemberSnapshot pk=`%d` fail", pk)
+	}
+	_type, id := snapshot.GroupType, snapshot.GroupID
+
+	groupPK, err := l.manager.GetPK(_type, id)
+	if err != nil {
+		return result, errorWrapf(err, "manager.GetPK _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	currentRelations, err := l.relationManager.ListMember(_type, id)
+	if err != nil {
+		return result, errorWrapf(err, "relationManager.ListMember _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	snapshotMemberMap := make(map[string]types.SubjectMemberSnapshotMember, len(snapshot.Members))
+	for _, m := range snapshot.Members {
+		snapshotMemberMap[fmt.Sprintf("%s:%s", m.Type, m.ID)] = m
+	}
+
+	// 对比当前成员与快照中的成员
+	removedRelations := make([]dao.SubjectRelation, 0, len(currentRelations))
+	updatedRelations := make([]dao.SubjectRelation, 0, len(currentRelations))
+	currentKeys := make(map[string]struct{}, len(currentRelations))
+	for _, r := range currentRelations {
+		key := fmt.Sprintf("%s:%s", r.SubjectType, r.SubjectID)
+		currentKeys[key] = struct{}{}
+
+		m, ok := snapshotMemberMap[key]
+		if !ok {
+			removedRelations = append(removedRelations, r)
+			continue
+		}
+		if m.PolicyExpiredAt != r.PolicyExpiredAt {
+			r.PolicyExpiredAt = m.PolicyExpiredAt
+			updatedRelations = append(updatedRelations, r)
+		}
+	}
+
+	addedMembers := make([]types.SubjectMemberSnapshotMember, 0, len(snapshot.Members))
+	for _, m := range snapshot.Members {
+		if _, ok := currentKeys[fmt.Sprintf("%s:%s", m.Type, m.ID)]; !ok {
+			addedMembers = append(addedMembers, m)
+		}
+	}
+
+	createdRelations, skipped, err := l.newRestoreRelations(_type, id, groupPK, addedMembers)
+	if err != nil {
+		return result, errorWrapf(err, "newRestoreRelations _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	// 恢复前为当前的成员创建快照
+	backup, err := newSubjectMemberSnapshot(_type, id, currentRelations, operator, source)
+	if err != nil {
+		return result, errorWrapf(err, "newSubjectMemberSnapshot _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	// 使用事务
+	tx, err := database.GenerateDefaultDBTx()
+	defer database.RollBackWithLog(tx)
+	if err != nil {
+		return result, errorWrapf(err, "define tx error")
+	}
+
+	backupPK, err := l.memberSnapshotManager.CreateWithTx(tx, backup)
+	if err != nil {
+		return result, errorWrapf(err, "memberSnapshotManager.CreateWithTx _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	removedIDs := map[string][]string{}
+	for _, r := range removedRelations {
+		removedIDs[r.SubjectType] = append(removedIDs[r.SubjectType], r.SubjectID)
+	}
+	for subjectType, subjectIDs := range removedIDs {
+		_, err = l.relationManager.BulkDeleteByMembersWithTx(tx, _type, id, subjectType, subjectIDs)
+		if err != nil {
+			return result, errorWrapf(err, "relationManager.BulkDeleteByMembersWithTx _type=`%s`, id=`%s`, "+
+				"subjectType=`%s`, subjectIDs=`%+v` fail", _type, id, subjectType, subjectIDs)
+		}
+	}
+
+	expiredAts := make([]dao.SubjectRelationPKPolicyExpiredAt, 0, len(updatedRelations))
+	for _, r := range updatedRelations {
+		expiredAts = append(expiredAts, dao.SubjectRelationPKPolicyExpiredAt{
+			PK:              r.PK,
+			PolicyExpiredAt: r.PolicyExpiredAt,
+		})
+	}
+	err = l.relationManager.UpdateExpiredAtWithTx(tx, expiredAts)
+	if err != nil {
+		return result, errorWrapf(err, "relationManager.UpdateExpiredAtWithTx relations=`%+v` fail", expiredAts)
+	}
+
+	err = l.bulkCreateRelationsInChunksWithTx(tx, createdRelations)
+	if err != nil {
+		return result, errorWrapf(err, "bulkCreateRelationsInChunksWithTx fail")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return result, errorWrapf(err, "tx commit error")
+	}
+
+	emitRemoveSubjectMembersEvent(_type, id, removedRelations)
+	emitAddOrRenewSubjectMembersEvents(_type, id, createdRelations, updatedRelations)
+
+	return types.SubjectMemberRestoreResult{
+		Group:            types.Subject{Type: _type, ID: id},
+		BackupSnapshotPK: backupPK,
+		Added:            convertToSubjectMembers(createdRelations),
+		Updated:          convertToSubjectMembers(updatedRelations),
+		Removed:          convertToSubjectMembers(removedRelations),
+		Skipped:          skipped,
+	}, nil
+}
+
+// newRestoreRelations 组装需要恢复加入的成员关系, 已不存在的subject跳过
+func (l *subjectService) newRestoreRelations(
+	_type, id string, groupPK int64, members []types.SubjectMemberSnapshotMember,
+) (relations []dao.SubjectRelation, skipped []types.Subject, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "newRestoreRelations")
+
+	idsByType := map[string][]string{}
+	for _, m := range members {
+		idsByType[m.Type] = append(idsByType[m.Type], m.ID)
+	}
+
+	memberPKMap := subjectPKMap{}
+	memberGroupPKs := []int64{}
+	for subjectType, ids := range idsByType {
+		subjects, newErr := l.manager.ListByIDs(subjectType, ids)
+		if newErr != nil {
+			return nil, nil, errorWrapf(newErr, "manager.ListByIDs _type=`%s`, ids=`%+v` fail", subjectType, ids)
+		}
+		for _, s := range subjects {
+			memberPKMap.Add(s.Type, s.ID, s.PK)
+			if s.Type == types.GroupType {
+				memberGroupPKs = append(memberGroupPKs, s.PK)
+			}
+		}
+	}
+
+	// 用户组加入用户组, 不能形成环
+	if len(memberGroupPKs) != 0 {
+		err = l.checkGroupMemberCycle(groupPK, memberGroupPKs)
+		if err != nil {
+			return nil, nil, errorWrapf(err, "checkGroupMemberCycle pk=`%d`, groupPKs=`%+v` fail",
+				groupPK, memberGroupPKs)
+		}
+	}
+
+	now := time.Now()
+	relations = make([]dao.SubjectRelation, 0, len(members))
+	skipped = []types.Subject{}
+	for _, m := range members {
+		pk, ok := memberPKMap.Get(m.Type, m.ID)
+		if !ok {
+			skipped = append(skipped, types.Subject{Type: m.Type, ID: m.ID})
+			continue
+		}
+		relations = append(relations, dao.SubjectRelation{
+			SubjectPK:       pk,
+			SubjectType:     m.Type,
+			SubjectID:       m.ID,
+			ParentPK:        groupPK,
+			ParentType:      _type,
+			ParentID:        id,
+			PolicyExpiredAt: m.PolicyExpiredAt,
+			CreateAt:        now,
+		})
+	}
+	return relations, skipped, nil
+}
+
+func newSubjectMemberSnapshot(
+	_type, id string, relations []dao.SubjectRelation, operator, source string,
+) (dao.SubjectMemberSnapshot, error) {
+	members := make([]types.SubjectMemberSnapshotMember, 0, len(relations))
+	for _, r := range relations {
+		members = append(members, types.SubjectMemberSnapshotMember{
+			Type:            r.SubjectType,
+			ID:              r.SubjectID,
+			PolicyExpiredAt: r.PolicyExpiredAt,
+		})
+	}
+
+	data, err := jsoniter.MarshalToString(members)
+	if err != nil {
+		return dao.SubjectMemberSnapshot{}, err
+	}
+
+	return dao.SubjectMemberSnapshot{
+		GroupType:   _type,
+		GroupID:     id,
+		MemberCount: int64(len(members)),
+		Members:     data,
+		Operator:    operator,
+		Source:      source,
+	}, nil
+}
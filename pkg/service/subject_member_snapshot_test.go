@@ -0,0 +1,216 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectMemberSnapshotService", func() {
+	var ctl *gomock.Controller
+	var patches *gomonkey.Patches
+	var events []SubjectChangeEvent
+
+	BeforeEach(func() {
+		ctl = gomock.NewController(GinkgoT())
+		events = nil
+		patches = gomonkey.ApplyFunc(emitSubjectChangeEvent, func(event SubjectChangeEvent) {
+			events = append(events, event)
+		})
+	})
+
+	AfterEach(func() {
+		ctl.Finish()
+		patches.Reset()
+	})
+
+	Describe("GetSubjectMemberSnapshot cases", func() {
+		It("invalid members", func() {
+			mockSnapshotManager := mock.NewMockSubjectMemberSnapshotManager(ctl)
+			mockSnapshotManager.EXPECT().Get(int64(1)).Return(dao.SubjectMemberSnapshot{PK: 1, Members: "{"}, nil)
+
+			svc := subjectService{memberSnapshotManager: mockSnapshotManager}
+			_, err := svc.GetSubjectMemberSnapshot(1)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "unmarshal")
+		})
+
+		It("ok", func() {
+			mockSnapshotManager := mock.NewMockSubjectMemberSnapshotManager(ctl)
+			mockSnapshotManager.EXPECT().Get(int64(1)).Return(dao.SubjectMemberSnapshot{
+				PK: 1, GroupType: "group", GroupID: "1", MemberCount: 1,
+				Members: `[{"type":"user","id":"tom","policy_expired_at":1000}]`,
+			}, nil)
+
+			svc := subjectService{memberSnapshotManager: mockSnapshotManager}
+			snapshot, err := svc.GetSubjectMemberSnapshot(1)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.SubjectMemberSnapshotMember{
+				{Type: "user", ID: "tom", PolicyExpiredAt: 1000},
+			}, snapshot.Members)
+		})
+	})
+
+	Describe("CreateSubjectMemberSnapshot cases", func() {
+		It("group not exists", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().GetPK("group", "1").Return(int64(0), errors.New("get pk fail"))
+
+			svc := subjectService{manager: mockSubjectManager}
+			_, err := svc.CreateSubjectMemberSnapshot("group", "1", "admin", "bk_iam")
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "get pk fail")
+		})
+
+		It("ok", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().ListMember("group", "1").Return([]dao.SubjectRelation{
+				{PK: 10, SubjectPK: 3, SubjectType: "user", SubjectID: "tom", PolicyExpiredAt: 1000},
+			}, nil)
+			mockSnapshotManager := mock.NewMockSubjectMemberSnapshotManager(ctl)
+			mockSnapshotManager.EXPECT().CreateWithTx(gomock.Any(), dao.SubjectMemberSnapshot{
+				GroupType:   "group",
+				GroupID:     "1",
+				MemberCount: 1,
+				Members:     `[{"type":"user","id":"tom","policy_expired_at":1000}]`,
+				Operator:    "admin",
+				Source:      "bk_iam",
+			}).Return(int64(5), nil)
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+			patches.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+
+			svc := subjectService{
+				manager:               mockSubjectManager,
+				relationManager:       mockRelationManager,
+				memberSnapshotManager: mockSnapshotManager,
+			}
+			snapshot, err := svc.CreateSubjectMemberSnapshot("group", "1", "admin", "bk_iam")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(5), snapshot.PK)
+			assert.Equal(GinkgoT(), int64(1), snapshot.MemberCount)
+		})
+	})
+
+	Describe("RestoreSubjectMemberSnapshot cases", func() {
+		It("ok", func() {
+			mockSnapshotManager := mock.NewMockSubjectMemberSnapshotManager(ctl)
+			mockSnapshotManager.EXPECT().Get(int64(1)).Return(dao.SubjectMemberSnapshot{
+				PK: 1, GroupType: "group", GroupID: "1", MemberCount: 3,
+				Members: `[{"type":"user","id":"tom","policy_expired_at":1000},` +
+					`{"type":"user","id":"jerry","policy_expired_at":2000},` +
+					`{"type":"user","id":"gone","policy_expired_at":2000}]`,
+			}, nil)
+			mockSnapshotManager.EXPECT().CreateWithTx(gomock.Any(), gomock.Any()).Return(int64(2), nil)
+
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockSubjectManager.EXPECT().ListByIDs("user", []string{"tom", "gone"}).Return(
+				[]dao.Subject{{PK: 3, Type: "user", ID: "tom"}}, nil)
+
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().ListMember("group", "1").Return([]dao.SubjectRelation{
+				{PK: 11, SubjectPK: 4, SubjectType: "user", SubjectID: "jerry", PolicyExpiredAt: 1500},
+				{PK: 12, SubjectPK: 5, SubjectType: "user", SubjectID: "bob", PolicyExpiredAt: 1000},
+			}, nil)
+			mockRelationManager.EXPECT().BulkDeleteByMembersWithTx(
+				gomock.Any(), "group", "1", "user", []string{"bob"}).Return(int64(1), nil)
+			mockRelationManager.EXPECT().UpdateExpiredAtWithTx(gomock.Any(), []dao.SubjectRelationPKPolicyExpiredAt{
+				{PK: 11, PolicyExpiredAt: 2000},
+			}).Return(nil)
+			mockRelationManager.EXPECT().BulkCreateWithTx(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, relations []dao.SubjectRelation) error {
+					assert.Len(GinkgoT(), relations, 1)
+					assert.Equal(GinkgoT(), int64(3), relations[0].SubjectPK)
+					assert.Equal(GinkgoT(), int64(1000), relations[0].PolicyExpiredAt)
+					return nil
+				})
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+			patches.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+
+			svc := subjectService{
+				manager:               mockSubjectManager,
+				relationManager:       mockRelationManager,
+				memberSnapshotManager: mockSnapshotManager,
+			}
+			result, err := svc.RestoreSubjectMemberSnapshot(1, "admin", "bk_iam")
+			assert.NoError(GinkgoT(), err)
+			assert.NoError(GinkgoT(), dbMock.ExpectationsWereMet())
+
+			assert.Equal(GinkgoT(), int64(2), result.BackupSnapshotPK)
+			assert.Len(GinkgoT(), result.Added, 1)
+			assert.Equal(GinkgoT(), "tom", result.Added[0].ID)
+			assert.Equal(GinkgoT(), []types.SubjectMember{
+				{PK: 11, Type: "user", ID: "jerry", PolicyExpiredAt: 2000},
+			}, result.Updated)
+			assert.Equal(GinkgoT(), "bob", result.Removed[0].ID)
+			assert.Equal(GinkgoT(), []types.Subject{{Type: "user", ID: "gone"}}, result.Skipped)
+
+			// removed, added, renewed
+			assert.Len(GinkgoT(), events, 3)
+			assert.Equal(GinkgoT(), int64(-1), events[0].MemberDelta)
+			assert.Equal(GinkgoT(), int64(1), events[1].MemberDelta)
+			assert.Equal(GinkgoT(), []int64{4}, events[2].SubjectPKs)
+		})
+
+		It("rollback when create relations fail", func() {
+			mockSnapshotManager := mock.NewMockSubjectMemberSnapshotManager(ctl)
+			mockSnapshotManager.EXPECT().Get(int64(1)).Return(dao.SubjectMemberSnapshot{
+				PK: 1, GroupType: "group", GroupID: "1", MemberCount: 1,
+				Members: `[{"type":"user","id":"tom","policy_expired_at":1000}]`,
+			}, nil)
+			mockSnapshotManager.EXPECT().CreateWithTx(gomock.Any(), gomock.Any()).Return(int64(2), nil)
+
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().GetPK("group", "1").Return(int64(1), nil)
+			mockSubjectManager.EXPECT().ListByIDs("user", []string{"tom"}).Return(
+				[]dao.Subject{{PK: 3, Type: "user", ID: "tom"}}, nil)
+
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().ListMember("group", "1").Return([]dao.SubjectRelation{}, nil)
+			mockRelationManager.EXPECT().UpdateExpiredAtWithTx(gomock.Any(), gomock.Any()).Return(nil)
+			mockRelationManager.EXPECT().BulkCreateWithTx(gomock.Any(), gomock.Any()).Return(errors.New("create fail"))
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectRollback()
+			patches.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+
+			svc := subjectService{
+				manager:               mockSubjectManager,
+				relationManager:       mockRelationManager,
+				memberSnapshotManager: mockSnapshotManager,
+			}
+			_, err := svc.RestoreSubjectMemberSnapshot(1, "admin", "bk_iam")
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "create fail")
+			assert.NoError(GinkgoT(), dbMock.ExpectationsWereMet())
+			assert.Len(GinkgoT(), events, 0)
+		})
+	})
+})
@@ -13,10 +13,12 @@ package service
 import (
 	"errors"
 
+	"github.com/agiledragon/gomonkey"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	"github.com/stretchr/testify/assert"
 
+	"iam/pkg/database"
 	"iam/pkg/database/dao"
 	"iam/pkg/database/dao/mock"
 	"iam/pkg/service/types"
@@ -63,4 +65,206 @@ var _ = Describe("SubjectService", func() {
 			assert.Equal(GinkgoT(), []types.SubjectMember{}, subjectMembers)
 		})
 	})
+
+	Describe("ListPagingMemberBySubjectType", func() {
+		var ctl *gomock.Controller
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("manager.ListPagingMemberBySubjectType fail", func() {
+			mockSubjectService := mock.NewMockSubjectRelationManager(ctl)
+			mockSubjectService.EXPECT().ListPagingMemberBySubjectType(
+				"group", "test", types.ServiceAccountType, int64(10), int64(0),
+			).Return(nil, errors.New("error")).AnyTimes()
+
+			manager := &subjectService{
+				relationManager: mockSubjectService,
+			}
+
+			_, err := manager.ListPagingMemberBySubjectType("group", "test", types.ServiceAccountType, 10, 0)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListPagingMemberBySubjectType")
+		})
+
+		It("success", func() {
+			mockSubjectService := mock.NewMockSubjectRelationManager(ctl)
+			mockSubjectService.EXPECT().ListPagingMemberBySubjectType(
+				"group", "test", types.ServiceAccountType, int64(10), int64(0),
+			).Return([]dao.SubjectRelation{
+				{PK: 1, SubjectType: types.ServiceAccountType, SubjectID: "ci-bot", PolicyExpiredAt: 100},
+			}, nil).AnyTimes()
+
+			manager := &subjectService{
+				relationManager: mockSubjectService,
+			}
+
+			subjectMembers, err := manager.ListPagingMemberBySubjectType("group", "test", types.ServiceAccountType, 10, 0)
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), subjectMembers, 1)
+			assert.Equal(GinkgoT(), "ci-bot", subjectMembers[0].ID)
+			assert.Equal(GinkgoT(), types.ServiceAccountType, subjectMembers[0].Type)
+		})
+	})
+
+	Describe("checkGroupMemberCycle", func() {
+		var ctl *gomock.Controller
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("add group self", func() {
+			manager := &subjectService{}
+
+			err := manager.checkGroupMemberCycle(1, []int64{1})
+			assert.ErrorIs(GinkgoT(), err, ErrGroupMemberCycle)
+		})
+
+		It("relationManager.ListThinRelationBySubjectPK fail", func() {
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().ListThinRelationBySubjectPK(int64(1)).Return(
+				nil, errors.New("error"),
+			).AnyTimes()
+
+			manager := &subjectService{
+				relationManager: mockRelationManager,
+			}
+
+			err := manager.checkGroupMemberCycle(1, []int64{2})
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListThinRelationBySubjectPK")
+		})
+
+		It("cycle, the member group is an ancestor", func() {
+			// 1 in 3, 3 in 2, add 2 into 1
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().ListThinRelationBySubjectPK(int64(1)).Return(
+				[]dao.ThinSubjectRelation{{ParentPK: 3}}, nil,
+			).AnyTimes()
+			mockRelationManager.EXPECT().ListThinRelationBySubjectPK(int64(3)).Return(
+				[]dao.ThinSubjectRelation{{ParentPK: 2}}, nil,
+			).AnyTimes()
+
+			manager := &subjectService{
+				relationManager: mockRelationManager,
+			}
+
+			err := manager.checkGroupMemberCycle(1, []int64{2})
+			assert.ErrorIs(GinkgoT(), err, ErrGroupMemberCycle)
+		})
+
+		It("ok", func() {
+			// 1 in 3, 3 in 4, add 2 into 1
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().ListThinRelationBySubjectPK(int64(1)).Return(
+				[]dao.ThinSubjectRelation{{ParentPK: 3}}, nil,
+			).AnyTimes()
+			mockRelationManager.EXPECT().ListThinRelationBySubjectPK(int64(3)).Return(
+				[]dao.ThinSubjectRelation{{ParentPK: 4}}, nil,
+			).AnyTimes()
+			mockRelationManager.EXPECT().ListThinRelationBySubjectPK(int64(4)).Return(
+				[]dao.ThinSubjectRelation{}, nil,
+			).AnyTimes()
+
+			manager := &subjectService{
+				relationManager: mockRelationManager,
+			}
+
+			err := manager.checkGroupMemberCycle(1, []int64{2})
+			assert.NoError(GinkgoT(), err)
+		})
+	})
+
+	Describe("PurgeExpiredMembers", func() {
+		var ctl *gomock.Controller
+		var patches *gomonkey.Patches
+		var events []SubjectChangeEvent
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			events = nil
+			patches = gomonkey.ApplyFunc(emitSubjectChangeEvent, func(event SubjectChangeEvent) {
+				events = append(events, event)
+			})
+		})
+		AfterEach(func() {
+			ctl.Finish()
+			patches.Reset()
+		})
+
+		It("relationManager.ListExpiredRelation fail", func() {
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().ListExpiredRelation(int64(1000), int64(10)).Return(
+				nil, errors.New("error"),
+			)
+
+			manager := &subjectService{
+				relationManager: mockRelationManager,
+			}
+
+			_, err := manager.PurgeExpiredMembers(1000, 10)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListExpiredRelation")
+		})
+
+		It("no expired", func() {
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().ListExpiredRelation(int64(1000), int64(10)).Return(
+				[]dao.SubjectRelation{}, nil,
+			)
+
+			manager := &subjectService{
+				relationManager: mockRelationManager,
+			}
+
+			count, err := manager.PurgeExpiredMembers(1000, 10)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(0), count)
+			assert.Len(GinkgoT(), events, 0)
+		})
+
+		It("ok", func() {
+			mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+			mockRelationManager.EXPECT().ListExpiredRelation(int64(1000), int64(10)).Return(
+				[]dao.SubjectRelation{
+					{PK: 1, SubjectPK: 11, ParentType: "group", ParentID: "1"},
+					{PK: 2, SubjectPK: 12, ParentType: "group", ParentID: "2"},
+					{PK: 3, SubjectPK: 13, ParentType: "group", ParentID: "1"},
+				}, nil,
+			)
+			mockRelationManager.EXPECT().BulkDeleteExpiredByPKsWithTx(
+				gomock.Any(), []int64{1, 3}, int64(1000),
+			).Return(int64(2), nil)
+			// one member of group 2 has been renewed
+			mockRelationManager.EXPECT().BulkDeleteExpiredByPKsWithTx(
+				gomock.Any(), []int64{2}, int64(1000),
+			).Return(int64(0), nil)
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+			patches.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+
+			manager := &subjectService{
+				relationManager: mockRelationManager,
+			}
+
+			count, err := manager.PurgeExpiredMembers(1000, 10)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(2), count)
+			assert.NoError(GinkgoT(), dbMock.ExpectationsWereMet())
+
+			assert.Len(GinkgoT(), events, 2)
+			assert.Equal(GinkgoT(), types.Subject{Type: "group", ID: "1"}, *events[0].Group)
+			assert.Equal(GinkgoT(), []int64{11, 13}, events[0].SubjectPKs)
+			assert.Equal(GinkgoT(), int64(-2), events[0].MemberDelta)
+			assert.Equal(GinkgoT(), types.Subject{Type: "group", ID: "2"}, *events[1].Group)
+			assert.Equal(GinkgoT(), int64(0), events[1].MemberDelta)
+		})
+	})
 })
@@ -0,0 +1,104 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package service
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/database/dao"
+)
+
+// 用户组成员关系变更事件的动作
+const (
+	SubjectRelationEventActionAdded   = "added"
+	SubjectRelationEventActionRemoved = "removed"
+	SubjectRelationEventActionRenewed = "renewed"
+)
+
+// SubjectRelationEvent 用户组成员关系的变更事件, 事务提交后投递给下游(鉴权引擎/审计)同步, 不需要再轮询关系表
+type SubjectRelationEvent struct {
+	Action string `json:"action"`
+
+	GroupPK   int64  `json:"group_pk"`
+	GroupType string `json:"group_type"`
+	GroupID   string `json:"group_id"`
+
+	SubjectPK   int64  `json:"subject_pk"`
+	SubjectType string `json:"subject_type"`
+	SubjectID   string `json:"subject_id"`
+
+	// 新增/续期后的过期时间; 移除时为移除前的过期时间, 未知时为0
+	PolicyExpiredAt int64 `json:"policy_expired_at"`
+	// 事件产生的时间, unix time
+	Timestamp int64 `json:"timestamp"`
+}
+
+// SubjectRelationEventSink 成员关系变更事件的投递目标, 例如redis stream
+type SubjectRelationEventSink interface {
+	Send(events []SubjectRelationEvent) error
+}
+
+var (
+	subjectRelationEventSinkLock sync.RWMutex
+	subjectRelationEventSink     SubjectRelationEventSink
+)
+
+// SetSubjectRelationEventSink 设置成员关系变更事件的投递目标, nil表示不投递, 一般在初始化时设置
+func SetSubjectRelationEventSink(sink SubjectRelationEventSink) {
+	subjectRelationEventSinkLock.Lock()
+	subjectRelationEventSink = sink
+	subjectRelationEventSinkLock.Unlock()
+}
+
+func getSubjectRelationEventSink() SubjectRelationEventSink {
+	subjectRelationEventSinkLock.RLock()
+	defer subjectRelationEventSinkLock.RUnlock()
+	return subjectRelationEventSink
+}
+
+// emitSubjectRelationEvents 投递失败只记录日志, 不影响已提交的成员变更
+func emitSubjectRelationEvents(events []SubjectRelationEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	sink := getSubjectRelationEventSink()
+	if sink == nil {
+		return
+	}
+
+	err := sink.Send(events)
+	if err != nil {
+		log.WithError(err).Errorf("send %d subject relation events fail", len(events))
+	}
+}
+
+// newSubjectRelationEvents 由成员关系生成变更事件, 过期时间取关系中的过期时间
+func newSubjectRelationEvents(action string, relations []dao.SubjectRelation) []SubjectRelationEvent {
+	now := time.Now().Unix()
+	events := make([]SubjectRelationEvent, 0, len(relations))
+	for _, r := range relations {
+		events = append(events, SubjectRelationEvent{
+			Action:          action,
+			GroupPK:         r.ParentPK,
+			GroupType:       r.ParentType,
+			GroupID:         r.ParentID,
+			SubjectPK:       r.SubjectPK,
+			SubjectType:     r.SubjectType,
+			SubjectID:       r.SubjectID,
+			PolicyExpiredAt: r.PolicyExpiredAt,
+			Timestamp:       now,
+		})
+	}
+	return events
+}
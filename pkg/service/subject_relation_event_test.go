@@ -0,0 +1,128 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package service
+
+import (
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+type fakeSubjectRelationEventSink struct {
+	events []SubjectRelationEvent
+	err    error
+}
+
+func (s *fakeSubjectRelationEventSink) Send(events []SubjectRelationEvent) error {
+	s.events = append(s.events, events...)
+	return s.err
+}
+
+var _ = Describe("SubjectRelationEvent", func() {
+	var sink *fakeSubjectRelationEventSink
+	BeforeEach(func() {
+		sink = &fakeSubjectRelationEventSink{}
+		SetSubjectRelationEventSink(sink)
+	})
+	AfterEach(func() {
+		SetSubjectRelationEventSink(nil)
+	})
+
+	It("newSubjectRelationEvents", func() {
+		events := newSubjectRelationEvents(SubjectRelationEventActionAdded, []dao.SubjectRelation{{
+			SubjectPK: 2, SubjectType: "user", SubjectID: "tom",
+			ParentPK: 1, ParentType: "group", ParentID: "10", PolicyExpiredAt: 100,
+		}})
+		assert.Len(GinkgoT(), events, 1)
+		assert.NotZero(GinkgoT(), events[0].Timestamp)
+		events[0].Timestamp = 0
+		assert.Equal(GinkgoT(), SubjectRelationEvent{
+			Action: SubjectRelationEventActionAdded, GroupPK: 1, GroupType: "group", GroupID: "10",
+			SubjectPK: 2, SubjectType: "user", SubjectID: "tom", PolicyExpiredAt: 100,
+		}, events[0])
+	})
+
+	It("emit without sink", func() {
+		SetSubjectRelationEventSink(nil)
+		emitSubjectRelationEvents([]SubjectRelationEvent{{Action: SubjectRelationEventActionAdded}})
+		assert.Empty(GinkgoT(), sink.events)
+	})
+
+	It("emit sink fail", func() {
+		sink.err = errors.New("error")
+		assert.NotPanics(GinkgoT(), func() {
+			emitSubjectRelationEvents([]SubjectRelationEvent{{Action: SubjectRelationEventActionAdded}})
+		})
+	})
+
+	It("UpdateMembersExpiredAt emit renewed", func() {
+		ctl := gomock.NewController(GinkgoT())
+		defer ctl.Finish()
+
+		mockRelationManager := mock.NewMockSubjectRelationManager(ctl)
+		mockRelationManager.EXPECT().ListRelationByPKs([]int64{10}).Return([]dao.SubjectRelation{
+			{PK: 10, SubjectPK: 2, SubjectType: "user", SubjectID: "tom", ParentPK: 1, PolicyExpiredAt: 50},
+		}, nil)
+		mockRelationManager.EXPECT().UpdateExpiredAt(gomock.Any()).Return(nil)
+		mockGroupSettingManager := mock.NewMockGroupSettingManager(ctl)
+		mockGroupSettingManager.EXPECT().ListByGroupPKs([]int64{1}).Return(nil, nil)
+
+		svc := &subjectService{
+			relationManager:     mockRelationManager,
+			groupSettingManager: mockGroupSettingManager,
+		}
+		err := svc.UpdateMembersExpiredAt([]types.SubjectMember{
+			{PK: 10, Type: "user", ID: "tom", PolicyExpiredAt: 100},
+		})
+		assert.NoError(GinkgoT(), err)
+		assert.Len(GinkgoT(), sink.events, 1)
+		assert.Equal(GinkgoT(), SubjectRelationEventActionRenewed, sink.events[0].Action)
+		assert.Equal(GinkgoT(), int64(2), sink.events[0].SubjectPK)
+		assert.Equal(GinkgoT(), int64(100), sink.events[0].PolicyExpiredAt)
+	})
+
+	It("emitRemoveSubjectRelationEvents", func() {
+		ctl := gomock.NewController(GinkgoT())
+		defer ctl.Finish()
+
+		mockManager := mock.NewMockSubjectManager(ctl)
+		mockManager.EXPECT().GetPK("group", "10").Return(int64(1), nil)
+		mockManager.EXPECT().ListByIDs("user", []string{"tom"}).Return([]dao.Subject{
+			{PK: 2, Type: "user", ID: "tom"},
+		}, nil)
+		mockManager.EXPECT().ListByIDs("department", []string{"20"}).Return([]dao.Subject{
+			{PK: 3, Type: "department", ID: "20"},
+		}, nil)
+
+		svc := &subjectService{manager: mockManager}
+		svc.emitRemoveSubjectRelationEvents("group", "10", []types.Subject{
+			{Type: "user", ID: "tom"}, {Type: "department", ID: "20"},
+		})
+		assert.Len(GinkgoT(), sink.events, 2)
+		assert.Equal(GinkgoT(), SubjectRelationEventActionRemoved, sink.events[0].Action)
+		assert.Equal(GinkgoT(), int64(1), sink.events[0].GroupPK)
+		assert.Equal(GinkgoT(), int64(2), sink.events[0].SubjectPK)
+		assert.Equal(GinkgoT(), int64(3), sink.events[1].SubjectPK)
+	})
+
+	It("emitRemoveSubjectRelationEvents without sink", func() {
+		SetSubjectRelationEventSink(nil)
+		svc := &subjectService{}
+		assert.NotPanics(GinkgoT(), func() {
+			svc.emitRemoveSubjectRelationEvents("group", "10", []types.Subject{{Type: "user", ID: "tom"}})
+		})
+	})
+})
@@ -0,0 +1,182 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/errorx"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+// SubjectRelationIndexSVC ...
+const SubjectRelationIndexSVC = "SubjectRelationIndexSVC"
+
+const subjectRelationTable = "subject_relation"
+
+// SubjectRelationIndexService 诊断subject_relation表的索引, 发现DBA手动变更后的索引漂移
+type SubjectRelationIndexService interface {
+	Advise() (types.IndexAdvisorReport, error)
+}
+
+type subjectRelationIndexService struct {
+	manager dao.SubjectRelationIndexManager
+}
+
+// NewSubjectRelationIndexService ...
+func NewSubjectRelationIndexService() SubjectRelationIndexService {
+	return &subjectRelationIndexService{
+		manager: dao.NewSubjectRelationIndexManager(),
+	}
+}
+
+// Advise 对比期望的索引与线上表结构, 并EXPLAIN高频查询, 检查索引是否缺失或未被使用
+func (s *subjectRelationIndexService) Advise() (report types.IndexAdvisorReport, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectRelationIndexSVC, "Advise")
+
+	tableIndexes, err := s.manager.ListIndexes()
+	if err != nil {
+		err = errorWrapf(err, "manager.ListIndexes fail")
+		return
+	}
+
+	sort.Slice(tableIndexes, func(i, j int) bool {
+		if tableIndexes[i].KeyName != tableIndexes[j].KeyName {
+			return tableIndexes[i].KeyName < tableIndexes[j].KeyName
+		}
+		return tableIndexes[i].SeqInIndex < tableIndexes[j].SeqInIndex
+	})
+	actualIndexes := make(map[string][]string, len(tableIndexes))
+	for _, idx := range tableIndexes {
+		actualIndexes[idx.KeyName] = append(actualIndexes[idx.KeyName], idx.ColumnName)
+	}
+
+	report = types.IndexAdvisorReport{
+		Table:          subjectRelationTable,
+		Healthy:        true,
+		Indexes:        make([]types.IndexCheck, 0, len(dao.SubjectRelationIndexes)),
+		Queries:        make([]types.QueryIndexCheck, 0, len(dao.SubjectRelationHotQueries)),
+		UnknownIndexes: []string{},
+	}
+
+	names := make([]string, 0, len(dao.SubjectRelationIndexes))
+	for name := range dao.SubjectRelationIndexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		check := checkIndex(name, dao.SubjectRelationIndexes[name], actualIndexes)
+		if check.Status != types.IndexStatusOK {
+			report.Healthy = false
+		}
+		report.Indexes = append(report.Indexes, check)
+	}
+
+	for name := range actualIndexes {
+		if _, ok := dao.SubjectRelationIndexes[name]; !ok {
+			report.UnknownIndexes = append(report.UnknownIndexes, name)
+		}
+	}
+	sort.Strings(report.UnknownIndexes)
+
+	for _, q := range dao.SubjectRelationHotQueries {
+		rows, err1 := s.manager.Explain(q.Query, q.Args...)
+		if err1 != nil {
+			err = errorWrapf(err1, "manager.Explain name=`%s` fail", q.Name)
+			return
+		}
+
+		check := checkQueryIndex(q, rows, actualIndexes)
+		if check.Status != types.IndexStatusOK {
+			report.Healthy = false
+		}
+		report.Queries = append(report.Queries, check)
+	}
+
+	return report, nil
+}
+
+func checkIndex(name string, expectedColumns []string, actualIndexes map[string][]string) types.IndexCheck {
+	check := types.IndexCheck{
+		Name:            name,
+		ExpectedColumns: expectedColumns,
+		ActualColumns:   []string{},
+		Status:          types.IndexStatusOK,
+	}
+
+	actualColumns, ok := actualIndexes[name]
+	if !ok {
+		check.Status = types.IndexStatusMissing
+		return check
+	}
+
+	check.ActualColumns = actualColumns
+	if strings.Join(actualColumns, ",") != strings.Join(expectedColumns, ",") {
+		check.Status = types.IndexStatusMismatch
+	}
+	return check
+}
+
+func checkQueryIndex(
+	q dao.SubjectRelationHotQuery, rows []dao.ExplainRow, actualIndexes map[string][]string,
+) types.QueryIndexCheck {
+	check := types.QueryIndexCheck{
+		Name:            q.Name,
+		Query:           strings.Join(strings.Fields(q.Query), " "),
+		ExpectedIndexes: q.Indexes,
+		PossibleKeys:    []string{},
+		Status:          types.IndexStatusOK,
+	}
+
+	// 单表查询, 取subject_relation表的执行计划
+	for _, row := range rows {
+		if row.Table.Valid && row.Table.String != subjectRelationTable {
+			continue
+		}
+
+		if row.PossibleKeys.String != "" {
+			check.PossibleKeys = strings.Split(row.PossibleKeys.String, ",")
+		}
+		check.Key = row.Key.String
+		check.AccessType = row.Type.String
+		check.Rows = row.Rows.Int64
+		check.Extra = row.Extra.String
+		break
+	}
+
+	existIndexes := make([]string, 0, len(q.Indexes))
+	for _, name := range q.Indexes {
+		if _, ok := actualIndexes[name]; ok {
+			existIndexes = append(existIndexes, name)
+		}
+	}
+
+	switch {
+	case len(existIndexes) == 0:
+		check.Status = types.IndexStatusMissing
+		check.Message = fmt.Sprintf("none of the expected indexes %v exists", q.Indexes)
+	case util.NewStringSetWithValues(q.Indexes).Has(check.Key):
+		check.Message = fmt.Sprintf("use index `%s`", check.Key)
+	case check.Key == "":
+		check.Status = types.IndexStatusIgnored
+		check.Message = fmt.Sprintf("no index used, access type `%s`, expected indexes %v", check.AccessType, existIndexes)
+	default:
+		check.Status = types.IndexStatusIgnored
+		check.Message = fmt.Sprintf("use index `%s` instead of the expected indexes %v", check.Key, existIndexes)
+	}
+	return check
+}
@@ -0,0 +1,146 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectRelationIndexService", func() {
+
+	Describe("Advise", func() {
+		var ctl *gomock.Controller
+		var mockManager *mock.MockSubjectRelationIndexManager
+		var svc *subjectRelationIndexService
+
+		allIndexes := []dao.TableIndex{
+			{KeyName: "PRIMARY", SeqInIndex: 1, ColumnName: "pk"},
+			{KeyName: "idx_subject", SeqInIndex: 1, ColumnName: "subject_id"},
+			{KeyName: "idx_subject", SeqInIndex: 2, ColumnName: "subject_type"},
+			{KeyName: "idx_parent", SeqInIndex: 2, ColumnName: "parent_type"},
+			{KeyName: "idx_parent", SeqInIndex: 1, ColumnName: "parent_id"},
+			{KeyName: "idx_uk_subject_parent", SeqInIndex: 1, ColumnName: "subject_pk"},
+			{KeyName: "idx_uk_subject_parent", SeqInIndex: 2, ColumnName: "parent_pk"},
+			{KeyName: "idx_subject_pk_expire", SeqInIndex: 1, ColumnName: "subject_pk"},
+			{KeyName: "idx_subject_pk_expire", SeqInIndex: 2, ColumnName: "policy_expired_at"},
+		}
+
+		explainRow := func(key string) dao.ExplainRow {
+			return dao.ExplainRow{
+				Table: sql.NullString{String: "subject_relation", Valid: true},
+				Type:  sql.NullString{String: "ref", Valid: true},
+				Key:   sql.NullString{String: key, Valid: key != ""},
+				Rows:  sql.NullInt64{Int64: 1, Valid: true},
+			}
+		}
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			mockManager = mock.NewMockSubjectRelationIndexManager(ctl)
+			svc = &subjectRelationIndexService{
+				manager: mockManager,
+			}
+		})
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("manager.ListIndexes fail", func() {
+			mockManager.EXPECT().ListIndexes().Return(nil, errors.New("error"))
+
+			_, err := svc.Advise()
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListIndexes")
+		})
+
+		It("manager.Explain fail", func() {
+			mockManager.EXPECT().ListIndexes().Return(allIndexes, nil)
+			mockManager.EXPECT().Explain(gomock.Any(), gomock.Any()).Return(nil, errors.New("error"))
+
+			_, err := svc.Advise()
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "Explain")
+		})
+
+		It("healthy", func() {
+			mockManager.EXPECT().ListIndexes().Return(allIndexes, nil)
+			mockManager.EXPECT().Explain(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(query string, args ...interface{}) ([]dao.ExplainRow, error) {
+					for _, q := range dao.SubjectRelationHotQueries {
+						if q.Query == query {
+							return []dao.ExplainRow{explainRow(q.Indexes[0])}, nil
+						}
+					}
+					return nil, errors.New("unknown query")
+				}).Times(len(dao.SubjectRelationHotQueries))
+
+			report, err := svc.Advise()
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), report.Healthy)
+			assert.Len(GinkgoT(), report.Indexes, len(dao.SubjectRelationIndexes))
+			assert.Len(GinkgoT(), report.Queries, len(dao.SubjectRelationHotQueries))
+			assert.Empty(GinkgoT(), report.UnknownIndexes)
+			for _, q := range report.Queries {
+				assert.Equal(GinkgoT(), types.IndexStatusOK, q.Status)
+			}
+		})
+
+		It("missing, mismatch and ignored", func() {
+			mockManager.EXPECT().ListIndexes().Return([]dao.TableIndex{
+				{KeyName: "PRIMARY", SeqInIndex: 1, ColumnName: "pk"},
+				{KeyName: "idx_subject", SeqInIndex: 1, ColumnName: "subject_type"},
+				{KeyName: "idx_subject", SeqInIndex: 2, ColumnName: "subject_id"},
+				{KeyName: "idx_parent", SeqInIndex: 1, ColumnName: "parent_id"},
+				{KeyName: "idx_parent", SeqInIndex: 2, ColumnName: "parent_type"},
+				{KeyName: "idx_dba_parent_pk", SeqInIndex: 1, ColumnName: "parent_pk"},
+			}, nil)
+			mockManager.EXPECT().Explain(gomock.Any(), gomock.Any()).Return(
+				[]dao.ExplainRow{explainRow("")}, nil,
+			).Times(len(dao.SubjectRelationHotQueries))
+
+			report, err := svc.Advise()
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), report.Healthy)
+			assert.Equal(GinkgoT(), []string{"idx_dba_parent_pk"}, report.UnknownIndexes)
+
+			indexStatus := map[string]string{}
+			for _, idx := range report.Indexes {
+				indexStatus[idx.Name] = idx.Status
+			}
+			assert.Equal(GinkgoT(), map[string]string{
+				"PRIMARY":               types.IndexStatusOK,
+				"idx_subject":           types.IndexStatusMismatch,
+				"idx_parent":            types.IndexStatusOK,
+				"idx_uk_subject_parent": types.IndexStatusMissing,
+				"idx_subject_pk_expire": types.IndexStatusMissing,
+			}, indexStatus)
+
+			queryStatus := map[string]string{}
+			for _, q := range report.Queries {
+				queryStatus[q.Name] = q.Status
+			}
+			assert.Equal(GinkgoT(), map[string]string{
+				"member_listing":          types.IndexStatusIgnored,
+				"effect_group_resolution": types.IndexStatusMissing,
+				"expired_member_listing":  types.IndexStatusIgnored,
+			}, queryStatus)
+		})
+	})
+})
@@ -11,6 +11,7 @@
 package service
 
 import (
+	"iam/pkg/database"
 	"iam/pkg/database/dao"
 	"iam/pkg/errorx"
 	"iam/pkg/service/types"
@@ -30,9 +31,101 @@ func (l *subjectService) ListSubjectPKByRole(roleType, system string) ([]int64,
 	return subjectPKs, err
 }
 
+// ListSubjectRoles 查询subject的所有角色及其管理的系统
+func (l *subjectService) ListSubjectRoles(_type, id string) ([]types.SubjectRole, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "ListSubjectRoles")
+	pk, err := l.manager.GetPK(_type, id)
+	if err != nil {
+		return nil, errorWrapf(err, "manager.GetPK _type=`%s`, id=`%s` fail", _type, id)
+	}
+
+	daoRoles, err := l.roleManager.ListBySubjectPK(pk)
+	if err != nil {
+		return nil, errorWrapf(err, "roleManager.ListBySubjectPK pk=`%d` fail", pk)
+	}
+
+	roles := make([]types.SubjectRole, 0, len(daoRoles))
+	for _, r := range daoRoles {
+		roles = append(roles, types.SubjectRole{
+			RoleType: r.RoleType,
+			System:   r.System,
+		})
+	}
+	return roles, nil
+}
+
+// GetSubjectRoleCount ...
+func (l *subjectService) GetSubjectRoleCount() (int64, error) {
+	count, err := l.roleManager.GetCount()
+	if err != nil {
+		return count, errorx.Wrapf(err, SubjectSVC, "GetSubjectRoleCount", "roleManager.GetCount fail")
+	}
+	return count, nil
+}
+
+// ListPagingSubjectRoles 分页查询所有系统的角色及其授予的subject
+func (l *subjectService) ListPagingSubjectRoles(limit, offset int64) ([]types.SubjectRoleHolder, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "ListPagingSubjectRoles")
+	daoRoles, err := l.roleManager.ListPaging(limit, offset)
+	if err != nil {
+		return nil, errorWrapf(err, "roleManager.ListPaging limit=`%d`, offset=`%d` fail", limit, offset)
+	}
+
+	if len(daoRoles) == 0 {
+		return []types.SubjectRoleHolder{}, nil
+	}
+
+	subjectPKSet := util.NewInt64Set()
+	for _, r := range daoRoles {
+		subjectPKSet.Add(r.SubjectPK)
+	}
+	subjectPKs := subjectPKSet.ToSlice()
+
+	subjects, err := l.manager.ListByPKs(subjectPKs)
+	if err != nil {
+		return nil, errorWrapf(err, "manager.ListByPKs pks=`%+v` fail", subjectPKs)
+	}
+	subjectMap := make(map[int64]dao.Subject, len(subjects))
+	for _, s := range subjects {
+		subjectMap[s.PK] = s
+	}
+
+	holders := make([]types.SubjectRoleHolder, 0, len(daoRoles))
+	for _, r := range daoRoles {
+		// NOTE: the subject may be deleted, keep the role with empty type/id/name
+		subject := subjectMap[r.SubjectPK]
+		holders = append(holders, types.SubjectRoleHolder{
+			RoleType:    r.RoleType,
+			System:      r.System,
+			SubjectType: subject.Type,
+			SubjectID:   subject.ID,
+			SubjectName: subject.Name,
+		})
+	}
+	return holders, nil
+}
+
+// SubjectRoleHistoryAction ...
+const (
+	SubjectRoleHistoryActionGranted = "granted"
+	SubjectRoleHistoryActionRevoked = "revoked"
+)
+
 // BulkCreateSubjectRoles ...
-func (l *subjectService) BulkCreateSubjectRoles(roleType, system string, subjects []types.Subject) error {
-	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "BulkCreateSubjectRoles")
+func (l *subjectService) BulkCreateSubjectRoles(
+	roleType, system string, subjects []types.Subject, operator, source string,
+) error {
+	return l.BulkCreateSubjectMultiRoles(
+		[]types.SubjectRole{{RoleType: roleType, System: system}}, subjects, operator, source,
+	)
+}
+
+// BulkCreateSubjectMultiRoles 批量授予多个系统的角色, 所有角色在同一个事务中创建, 成功后统一清理缓存
+// NOTE: 只有新授予的角色才会记录变更记录
+func (l *subjectService) BulkCreateSubjectMultiRoles(
+	roles []types.SubjectRole, subjects []types.Subject, operator, source string,
+) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "BulkCreateSubjectMultiRoles")
 
 	// 查询用户的subjectPK
 	subjectPKs, err := l.listSubjectPKs(subjects)
@@ -41,40 +134,58 @@ func (l *subjectService) BulkCreateSubjectRoles(roleType, system string, subject
 		return err
 	}
 
-	// 查询角色已有的subjectPK
-	oldSubjectPKs, err := l.roleManager.ListSubjectPKByRole(roleType, system)
-	if err != nil {
-		err = errorWrapf(err, "roleManager.ListSubjectPKByRole roleType=`%s`, system=`%s` fail", roleType, system)
-		return err
-	}
-
-	// 对比出需要创建subjectPK
-	oldPKs := util.NewInt64SetWithValues(oldSubjectPKs)
+	dbRoles := make([]dao.SubjectRole, 0, len(roles)*len(subjectPKs))
+	created := util.NewStringSet()
+	for _, role := range roles {
+		// 重复的角色只处理一次
+		key := role.RoleType + ":" + role.System
+		if created.Has(key) {
+			continue
+		}
+		created.Add(key)
 
-	roles := make([]dao.SubjectRole, 0, len(subjectPKs))
+		// 查询角色已有的subjectPK
+		oldSubjectPKs, err := l.roleManager.ListSubjectPKByRole(role.RoleType, role.System)
+		if err != nil {
+			err = errorWrapf(err, "roleManager.ListSubjectPKByRole roleType=`%s`, system=`%s` fail",
+				role.RoleType, role.System)
+			return err
+		}
 
-	for _, pk := range subjectPKs {
-		if !oldPKs.Has(pk) {
-			roles = append(roles, dao.SubjectRole{
-				RoleType:  roleType,
-				System:    system,
-				SubjectPK: pk,
-			})
+		// 对比出需要创建subjectPK
+		oldPKs := util.NewInt64SetWithValues(oldSubjectPKs)
+		for _, pk := range subjectPKs {
+			if !oldPKs.Has(pk) {
+				dbRoles = append(dbRoles, dao.SubjectRole{
+					RoleType:  role.RoleType,
+					System:    role.System,
+					SubjectPK: pk,
+				})
+			}
 		}
 	}
 
-	// 创建SubjectRole
-	err = l.roleManager.BulkCreate(roles)
-	if err != nil {
-		err = errorWrapf(err, "roleManager.BulkCreate roles=`%+v` fail", roles)
-		return err
+	if len(dbRoles) > 0 {
+		histories := newSubjectRoleHistories(dbRoles, SubjectRoleHistoryActionGranted, operator, source)
+		err = l.bulkCreateRolesWithTx(dbRoles, histories)
+		if err != nil {
+			err = errorWrapf(err, "bulkCreateRolesWithTx roles=`%+v` fail", dbRoles)
+			return err
+		}
 	}
 
+	emitSubjectChangeEvent(SubjectChangeEvent{
+		Type:     SubjectChangeEventTypeRole,
+		Subjects: subjects,
+	})
 	return nil
 }
 
 // BulkDeleteSubjectRoles ...
-func (l *subjectService) BulkDeleteSubjectRoles(roleType, system string, subjects []types.Subject) error {
+// NOTE: 只有实际被回收的角色才会记录变更记录
+func (l *subjectService) BulkDeleteSubjectRoles(
+	roleType, system string, subjects []types.Subject, operator, source string,
+) error {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "BulkDeleteSubjectRoles")
 
 	// 查询用户的subjectPK
@@ -88,22 +199,169 @@ func (l *subjectService) BulkDeleteSubjectRoles(roleType, system string, subject
 		return nil
 	}
 
-	// 创建SubjectRole
-	err = l.roleManager.BulkDelete(roleType, system, subjectPKs)
+	// 查询角色已有的subjectPK, 过滤出需要回收的subjectPK
+	oldSubjectPKs, err := l.roleManager.ListSubjectPKByRole(roleType, system)
+	if err != nil {
+		err = errorWrapf(err, "roleManager.ListSubjectPKByRole roleType=`%s`, system=`%s` fail", roleType, system)
+		return err
+	}
+
+	oldPKs := util.NewInt64SetWithValues(oldSubjectPKs)
+	dbRoles := make([]dao.SubjectRole, 0, len(subjectPKs))
+	deletePKs := make([]int64, 0, len(subjectPKs))
+	for _, pk := range subjectPKs {
+		if oldPKs.Has(pk) {
+			dbRoles = append(dbRoles, dao.SubjectRole{
+				RoleType:  roleType,
+				System:    system,
+				SubjectPK: pk,
+			})
+			deletePKs = append(deletePKs, pk)
+		}
+	}
+
+	if len(deletePKs) == 0 {
+		return nil
+	}
+
+	histories := newSubjectRoleHistories(dbRoles, SubjectRoleHistoryActionRevoked, operator, source)
+	err = l.bulkDeleteRolesWithTx(roleType, system, deletePKs, histories)
 	if err != nil {
 		err = errorWrapf(
 			err,
-			"roleManager.BulkDelete roleType=`%s`, system=`%s`, subjectPKs=`%+v` fail",
+			"bulkDeleteRolesWithTx roleType=`%s`, system=`%s`, subjectPKs=`%+v` fail",
 			roleType,
 			system,
-			subjectPKs,
+			deletePKs,
 		)
 		return err
 	}
 
+	emitSubjectChangeEvent(SubjectChangeEvent{
+		Type:     SubjectChangeEventTypeRole,
+		Subjects: subjects,
+	})
 	return nil
 }
 
+// GetSubjectRoleHistoryCount ...
+func (l *subjectService) GetSubjectRoleHistoryCount(roleType, system string) (int64, error) {
+	count, err := l.roleHistoryManager.GetCountByRole(roleType, system)
+	if err != nil {
+		return count, errorx.Wrapf(err, SubjectSVC, "GetSubjectRoleHistoryCount",
+			"roleHistoryManager.GetCountByRole roleType=`%s`, system=`%s` fail", roleType, system)
+	}
+	return count, nil
+}
+
+// ListPagingSubjectRoleHistory 查询角色的授予/回收记录, 最近的在前
+func (l *subjectService) ListPagingSubjectRoleHistory(
+	roleType, system string, limit, offset int64,
+) ([]types.SubjectRoleHistory, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSVC, "ListPagingSubjectRoleHistory")
+	daoHistories, err := l.roleHistoryManager.ListPagingByRole(roleType, system, limit, offset)
+	if err != nil {
+		return nil, errorWrapf(err, "roleHistoryManager.ListPagingByRole roleType=`%s`, system=`%s`, "+
+			"limit=`%d`, offset=`%d` fail", roleType, system, limit, offset)
+	}
+
+	if len(daoHistories) == 0 {
+		return []types.SubjectRoleHistory{}, nil
+	}
+
+	subjectPKSet := util.NewInt64Set()
+	for _, h := range daoHistories {
+		subjectPKSet.Add(h.SubjectPK)
+	}
+	subjectPKs := subjectPKSet.ToSlice()
+
+	subjects, err := l.manager.ListByPKs(subjectPKs)
+	if err != nil {
+		return nil, errorWrapf(err, "manager.ListByPKs pks=`%+v` fail", subjectPKs)
+	}
+	subjectMap := make(map[int64]dao.Subject, len(subjects))
+	for _, s := range subjects {
+		subjectMap[s.PK] = s
+	}
+
+	histories := make([]types.SubjectRoleHistory, 0, len(daoHistories))
+	for _, h := range daoHistories {
+		// NOTE: the subject may be deleted, keep the history with empty type/id/name
+		subject := subjectMap[h.SubjectPK]
+		histories = append(histories, types.SubjectRoleHistory{
+			SubjectType: subject.Type,
+			SubjectID:   subject.ID,
+			SubjectName: subject.Name,
+			Action:      h.Action,
+			Operator:    h.Operator,
+			Source:      h.Source,
+			CreatedAt:   h.CreatedAt,
+		})
+	}
+	return histories, nil
+}
+
+func newSubjectRoleHistories(
+	roles []dao.SubjectRole, action, operator, source string,
+) []dao.SubjectRoleHistory {
+	histories := make([]dao.SubjectRoleHistory, 0, len(roles))
+	for _, r := range roles {
+		histories = append(histories, dao.SubjectRoleHistory{
+			SubjectPK: r.SubjectPK,
+			RoleType:  r.RoleType,
+			System:    r.System,
+			Action:    action,
+			Operator:  operator,
+			Source:    source,
+		})
+	}
+	return histories
+}
+
+func (l *subjectService) bulkCreateRolesWithTx(roles []dao.SubjectRole, histories []dao.SubjectRoleHistory) error {
+	// 使用事务, 角色与变更记录一起提交
+	tx, err := database.GenerateDefaultDBTx()
+	defer database.RollBackWithLog(tx)
+	if err != nil {
+		return err
+	}
+
+	err = l.roleManager.BulkCreateWithTx(tx, roles)
+	if err != nil {
+		return err
+	}
+
+	err = l.roleHistoryManager.BulkCreateWithTx(tx, histories)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (l *subjectService) bulkDeleteRolesWithTx(
+	roleType, system string, subjectPKs []int64, histories []dao.SubjectRoleHistory,
+) error {
+	// 使用事务, 角色与变更记录一起提交
+	tx, err := database.GenerateDefaultDBTx()
+	defer database.RollBackWithLog(tx)
+	if err != nil {
+		return err
+	}
+
+	err = l.roleManager.BulkDeleteWithTx(tx, roleType, system, subjectPKs)
+	if err != nil {
+		return err
+	}
+
+	err = l.roleHistoryManager.BulkCreateWithTx(tx, histories)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (l *subjectService) listSubjectPKs(subjects []types.Subject) ([]int64, error) {
 	// 查询用户的subjectPK
 	subjectIDs := make([]string, 0, len(subjects))
@@ -0,0 +1,381 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectRoleService", func() {
+	Describe("BulkCreateSubjectMultiRoles cases", func() {
+		var ctl *gomock.Controller
+		var subjects []types.Subject
+		var roles []types.SubjectRole
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			subjects = []types.Subject{{Type: "user", ID: "admin"}, {Type: "user", ID: "tom"}}
+			roles = []types.SubjectRole{
+				{RoleType: "system_manager", System: "bk_cmdb"},
+				{RoleType: "system_manager", System: "bk_job"},
+				{RoleType: "system_manager", System: "bk_cmdb"},
+			}
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("roleManager.ListSubjectPKByRole fail", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().ListByIDs("user", []string{"admin", "tom"}).Return(
+				[]dao.Subject{{PK: 1}, {PK: 2}}, nil)
+			mockRoleManager := mock.NewMockSubjectRoleManager(ctl)
+			mockRoleManager.EXPECT().ListSubjectPKByRole("system_manager", "bk_cmdb").Return(
+				nil, errors.New("error"))
+
+			svc := subjectService{
+				manager:     mockSubjectManager,
+				roleManager: mockRoleManager,
+			}
+
+			err := svc.BulkCreateSubjectMultiRoles(roles, subjects, "admin", "bk_iam")
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListSubjectPKByRole")
+		})
+
+		It("ok", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().ListByIDs("user", []string{"admin", "tom"}).Return(
+				[]dao.Subject{{PK: 1}, {PK: 2}}, nil)
+			mockRoleManager := mock.NewMockSubjectRoleManager(ctl)
+			mockRoleManager.EXPECT().ListSubjectPKByRole("system_manager", "bk_cmdb").Return([]int64{1}, nil)
+			mockRoleManager.EXPECT().ListSubjectPKByRole("system_manager", "bk_job").Return([]int64{}, nil)
+			mockRoleManager.EXPECT().BulkCreateWithTx(gomock.Any(), []dao.SubjectRole{
+				{RoleType: "system_manager", System: "bk_cmdb", SubjectPK: 2},
+				{RoleType: "system_manager", System: "bk_job", SubjectPK: 1},
+				{RoleType: "system_manager", System: "bk_job", SubjectPK: 2},
+			}).Return(nil)
+			mockRoleHistoryManager := mock.NewMockSubjectRoleHistoryManager(ctl)
+			mockRoleHistoryManager.EXPECT().BulkCreateWithTx(gomock.Any(), []dao.SubjectRoleHistory{
+				{SubjectPK: 2, RoleType: "system_manager", System: "bk_cmdb", Action: "granted",
+					Operator: "admin", Source: "bk_iam"},
+				{SubjectPK: 1, RoleType: "system_manager", System: "bk_job", Action: "granted",
+					Operator: "admin", Source: "bk_iam"},
+				{SubjectPK: 2, RoleType: "system_manager", System: "bk_job", Action: "granted",
+					Operator: "admin", Source: "bk_iam"},
+			}).Return(nil)
+
+			svc := subjectService{
+				manager:            mockSubjectManager,
+				roleManager:        mockRoleManager,
+				roleHistoryManager: mockRoleHistoryManager,
+			}
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+
+			patches := gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+			defer patches.Reset()
+
+			err := svc.BulkCreateSubjectMultiRoles(roles, subjects, "admin", "bk_iam")
+			assert.NoError(GinkgoT(), err)
+
+			err = dbMock.ExpectationsWereMet()
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("all exists, no tx", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().ListByIDs("user", []string{"admin", "tom"}).Return(
+				[]dao.Subject{{PK: 1}, {PK: 2}}, nil)
+			mockRoleManager := mock.NewMockSubjectRoleManager(ctl)
+			mockRoleManager.EXPECT().ListSubjectPKByRole("system_manager", "bk_cmdb").Return([]int64{1, 2}, nil)
+			mockRoleManager.EXPECT().ListSubjectPKByRole("system_manager", "bk_job").Return([]int64{1, 2}, nil)
+
+			svc := subjectService{
+				manager:     mockSubjectManager,
+				roleManager: mockRoleManager,
+			}
+
+			err := svc.BulkCreateSubjectMultiRoles(roles, subjects, "admin", "bk_iam")
+			assert.NoError(GinkgoT(), err)
+		})
+	})
+
+	Describe("BulkDeleteSubjectRoles cases", func() {
+		var ctl *gomock.Controller
+		var subjects []types.Subject
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			subjects = []types.Subject{{Type: "user", ID: "admin"}, {Type: "user", ID: "tom"}}
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("none has the role, no tx", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().ListByIDs("user", []string{"admin", "tom"}).Return(
+				[]dao.Subject{{PK: 1}, {PK: 2}}, nil)
+			mockRoleManager := mock.NewMockSubjectRoleManager(ctl)
+			mockRoleManager.EXPECT().ListSubjectPKByRole("system_manager", "bk_cmdb").Return([]int64{3}, nil)
+
+			svc := subjectService{
+				manager:     mockSubjectManager,
+				roleManager: mockRoleManager,
+			}
+
+			err := svc.BulkDeleteSubjectRoles("system_manager", "bk_cmdb", subjects, "admin", "bk_iam")
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("roleHistoryManager.BulkCreateWithTx fail", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().ListByIDs("user", []string{"admin", "tom"}).Return(
+				[]dao.Subject{{PK: 1}, {PK: 2}}, nil)
+			mockRoleManager := mock.NewMockSubjectRoleManager(ctl)
+			mockRoleManager.EXPECT().ListSubjectPKByRole("system_manager", "bk_cmdb").Return([]int64{2, 3}, nil)
+			mockRoleManager.EXPECT().BulkDeleteWithTx(gomock.Any(), "system_manager", "bk_cmdb", []int64{2}).
+				Return(nil)
+			mockRoleHistoryManager := mock.NewMockSubjectRoleHistoryManager(ctl)
+			mockRoleHistoryManager.EXPECT().BulkCreateWithTx(gomock.Any(), gomock.Any()).Return(errors.New("error"))
+
+			svc := subjectService{
+				manager:            mockSubjectManager,
+				roleManager:        mockRoleManager,
+				roleHistoryManager: mockRoleHistoryManager,
+			}
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectRollback()
+
+			patches := gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+			defer patches.Reset()
+
+			err := svc.BulkDeleteSubjectRoles("system_manager", "bk_cmdb", subjects, "admin", "bk_iam")
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "bulkDeleteRolesWithTx")
+		})
+
+		It("ok", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().ListByIDs("user", []string{"admin", "tom"}).Return(
+				[]dao.Subject{{PK: 1}, {PK: 2}}, nil)
+			mockRoleManager := mock.NewMockSubjectRoleManager(ctl)
+			mockRoleManager.EXPECT().ListSubjectPKByRole("system_manager", "bk_cmdb").Return([]int64{2, 3}, nil)
+			mockRoleManager.EXPECT().BulkDeleteWithTx(gomock.Any(), "system_manager", "bk_cmdb", []int64{2}).
+				Return(nil)
+			mockRoleHistoryManager := mock.NewMockSubjectRoleHistoryManager(ctl)
+			mockRoleHistoryManager.EXPECT().BulkCreateWithTx(gomock.Any(), []dao.SubjectRoleHistory{
+				{SubjectPK: 2, RoleType: "system_manager", System: "bk_cmdb", Action: "revoked",
+					Operator: "admin", Source: "bk_iam"},
+			}).Return(nil)
+
+			svc := subjectService{
+				manager:            mockSubjectManager,
+				roleManager:        mockRoleManager,
+				roleHistoryManager: mockRoleHistoryManager,
+			}
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+
+			patches := gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+			defer patches.Reset()
+
+			err := svc.BulkDeleteSubjectRoles("system_manager", "bk_cmdb", subjects, "admin", "bk_iam")
+			assert.NoError(GinkgoT(), err)
+
+			err = dbMock.ExpectationsWereMet()
+			assert.NoError(GinkgoT(), err)
+		})
+	})
+
+	Describe("ListPagingSubjectRoleHistory cases", func() {
+		var ctl *gomock.Controller
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("empty", func() {
+			mockRoleHistoryManager := mock.NewMockSubjectRoleHistoryManager(ctl)
+			mockRoleHistoryManager.EXPECT().ListPagingByRole("system_manager", "bk_cmdb", int64(10), int64(0)).
+				Return([]dao.SubjectRoleHistory{}, nil)
+
+			svc := subjectService{roleHistoryManager: mockRoleHistoryManager}
+
+			histories, err := svc.ListPagingSubjectRoleHistory("system_manager", "bk_cmdb", 10, 0)
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), histories)
+		})
+
+		It("ok, with deleted subject", func() {
+			now := time.Now()
+			mockRoleHistoryManager := mock.NewMockSubjectRoleHistoryManager(ctl)
+			mockRoleHistoryManager.EXPECT().ListPagingByRole("system_manager", "bk_cmdb", int64(10), int64(0)).
+				Return([]dao.SubjectRoleHistory{
+					{SubjectPK: 1, Action: "revoked", Operator: "admin", Source: "bk_iam", CreatedAt: now},
+					{SubjectPK: 2, Action: "granted", Operator: "admin", Source: "bk_iam", CreatedAt: now},
+				}, nil)
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().ListByPKs(gomock.Any()).Return(
+				[]dao.Subject{{PK: 1, Type: "user", ID: "tom", Name: "Tom"}}, nil)
+
+			svc := subjectService{
+				manager:            mockSubjectManager,
+				roleHistoryManager: mockRoleHistoryManager,
+			}
+
+			histories, err := svc.ListPagingSubjectRoleHistory("system_manager", "bk_cmdb", 10, 0)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.SubjectRoleHistory{
+				{
+					SubjectType: "user", SubjectID: "tom", SubjectName: "Tom",
+					Action: "revoked", Operator: "admin", Source: "bk_iam", CreatedAt: now,
+				},
+				{Action: "granted", Operator: "admin", Source: "bk_iam", CreatedAt: now},
+			}, histories)
+		})
+	})
+
+	Describe("ListSubjectRoles cases", func() {
+		var ctl *gomock.Controller
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("manager.GetPK fail", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().GetPK("user", "tom").Return(int64(0), errors.New("error"))
+
+			svc := subjectService{manager: mockSubjectManager}
+
+			_, err := svc.ListSubjectRoles("user", "tom")
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "GetPK")
+		})
+
+		It("roleManager.ListBySubjectPK fail", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().GetPK("user", "tom").Return(int64(1), nil)
+			mockRoleManager := mock.NewMockSubjectRoleManager(ctl)
+			mockRoleManager.EXPECT().ListBySubjectPK(int64(1)).Return(nil, errors.New("error"))
+
+			svc := subjectService{manager: mockSubjectManager, roleManager: mockRoleManager}
+
+			_, err := svc.ListSubjectRoles("user", "tom")
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListBySubjectPK")
+		})
+
+		It("ok", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().GetPK("user", "tom").Return(int64(1), nil)
+			mockRoleManager := mock.NewMockSubjectRoleManager(ctl)
+			mockRoleManager.EXPECT().ListBySubjectPK(int64(1)).Return([]dao.SubjectRole{
+				{PK: 1, RoleType: "system_manager", System: "bk_cmdb", SubjectPK: 1},
+				{PK: 2, RoleType: "system_manager", System: "bk_job", SubjectPK: 1},
+			}, nil)
+
+			svc := subjectService{manager: mockSubjectManager, roleManager: mockRoleManager}
+
+			roles, err := svc.ListSubjectRoles("user", "tom")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.SubjectRole{
+				{RoleType: "system_manager", System: "bk_cmdb"},
+				{RoleType: "system_manager", System: "bk_job"},
+			}, roles)
+		})
+	})
+
+	Describe("ListPagingSubjectRoles cases", func() {
+		var ctl *gomock.Controller
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("roleManager.ListPaging fail", func() {
+			mockRoleManager := mock.NewMockSubjectRoleManager(ctl)
+			mockRoleManager.EXPECT().ListPaging(int64(10), int64(0)).Return(nil, errors.New("error"))
+
+			svc := subjectService{roleManager: mockRoleManager}
+
+			_, err := svc.ListPagingSubjectRoles(10, 0)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListPaging")
+		})
+
+		It("empty", func() {
+			mockRoleManager := mock.NewMockSubjectRoleManager(ctl)
+			mockRoleManager.EXPECT().ListPaging(int64(10), int64(0)).Return([]dao.SubjectRole{}, nil)
+
+			svc := subjectService{roleManager: mockRoleManager}
+
+			holders, err := svc.ListPagingSubjectRoles(10, 0)
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), holders)
+		})
+
+		It("ok, with deleted subject", func() {
+			mockRoleManager := mock.NewMockSubjectRoleManager(ctl)
+			mockRoleManager.EXPECT().ListPaging(int64(10), int64(0)).Return([]dao.SubjectRole{
+				{PK: 1, RoleType: "super_manager", System: "SUPER", SubjectPK: 1},
+				{PK: 2, RoleType: "system_manager", System: "bk_cmdb", SubjectPK: 2},
+			}, nil)
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().ListByPKs(gomock.Any()).Return(
+				[]dao.Subject{{PK: 1, Type: "user", ID: "admin", Name: "Admin"}}, nil)
+
+			svc := subjectService{manager: mockSubjectManager, roleManager: mockRoleManager}
+
+			holders, err := svc.ListPagingSubjectRoles(10, 0)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.SubjectRoleHolder{
+				{
+					RoleType: "super_manager", System: "SUPER",
+					SubjectType: "user", SubjectID: "admin", SubjectName: "Admin",
+				},
+				{RoleType: "system_manager", System: "bk_cmdb"},
+			}, holders)
+		})
+	})
+})
@@ -0,0 +1,673 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package service
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/database"
+	"iam/pkg/database/dao"
+	"iam/pkg/errorx"
+	"iam/pkg/service/types"
+	"iam/pkg/util"
+)
+
+/*
+ * > subject_system_group: subject在系统下有策略的用户组(直接加入或嵌套加入), 鉴权时只查询这些用户组的策略
+ *
+ * 1. 成员变更(member事件)后, 重新计算成员及其下级成员(成员是用户组时)的记录
+ * 2. 用户组的策略变更后, 新增了有策略的系统时重新计算其成员的记录, 系统下没有策略时删除对应的记录
+ *    成员数量较多时放入本地队列, 由 RunSubjectSystemGroupRefreshWorker 异步重新计算, 不阻塞策略变更的请求
+ * 3. subject删除(subject事件)后, 删除其记录, 并重新计算加入了被删除用户组的subject的记录
+ * 4. 记录变更后发出 system_group 事件, 由缓存清理对应subject的缓存
+ * 5. 以上处理都在变更提交后执行, 失败只记录日志; 由定时任务调用 ReconcileSubjects 分批比对并修正所有subject的记录
+ *
+ * NOTE: 多余的记录(用户组已没有策略)只会多查询一个subject的策略, 缺少的记录会导致鉴权失败, 所以只有新增需要及时处理
+ */
+
+// SubjectSystemGroupSVC ...
+const SubjectSystemGroupSVC = "SubjectSystemGroupSVC"
+
+// MaxNestedGroupDepth 用户组嵌套展开的最大层数(包含直接加入的用户组), 超过的部分不生效
+const MaxNestedGroupDepth = 5
+
+// subjectSystemGroupRefreshChunkSize 重新计算时每个事务处理的subject数量
+const subjectSystemGroupRefreshChunkSize = 100
+
+var (
+	// 用户组新增了有策略的系统时, 成员数量超过该值则异步重新计算
+	syncGroupSystemsMaxSyncMembers = 1000
+	// 异步重新计算的用户组队列, 队列满时由定时的比对修正
+	groupSystemsRefreshQueue = make(chan int64, 1000)
+)
+
+// SubjectSystemGroupService ...
+type SubjectSystemGroupService interface {
+	ListSubjectSystemGroups(systemID string, pks []int64) (map[int64][]types.ThinSubjectGroup, error)
+	ListSystemGroupsBySubjectPKs(pks []int64) (map[string][]types.ThinSubjectGroup, error)
+
+	RefreshSubjects(pks []int64) error
+	SyncGroupSystems(groupPK int64) error
+	DeleteSubjects(pks []int64) error
+
+	ReconcileSubjects(afterPK, limit int64) (lastPK int64, changed int64, err error)
+}
+
+type subjectSystemGroupService struct {
+	manager         dao.SubjectSystemGroupManager
+	relationManager dao.SubjectRelationManager
+	subjectManager  dao.SubjectManager
+}
+
+// NewSubjectSystemGroupService ...
+func NewSubjectSystemGroupService() SubjectSystemGroupService {
+	return &subjectSystemGroupService{
+		manager:         dao.NewSubjectSystemGroupManager(),
+		relationManager: dao.NewSubjectRelationManager(),
+		subjectManager:  dao.NewSubjectManager(),
+	}
+}
+
+// ListSubjectSystemGroups 批量获取subject在系统下有策略的用户组, 包括已过期的, 由调用方按过期时间过滤
+func (s *subjectSystemGroupService) ListSubjectSystemGroups(
+	systemID string, pks []int64,
+) (map[int64][]types.ThinSubjectGroup, error) {
+	groups, err := s.manager.ListBySystemSubjectPKs(systemID, pks)
+	if err != nil {
+		return nil, errorx.Wrapf(err, SubjectSystemGroupSVC, "ListSubjectSystemGroups",
+			"manager.ListBySystemSubjectPKs systemID=`%s`, pks=`%+v` fail", systemID, pks)
+	}
+
+	subjectGroups := make(map[int64][]types.ThinSubjectGroup, len(pks))
+	for _, g := range groups {
+		subjectGroups[g.SubjectPK] = append(subjectGroups[g.SubjectPK], types.ThinSubjectGroup{
+			PK:              g.GroupPK,
+			PolicyExpiredAt: g.PolicyExpiredAt,
+		})
+	}
+	return subjectGroups, nil
+}
+
+// ListSystemGroupsBySubjectPKs 批量获取subjects在各个系统下有策略的用户组, 返回 system => 用户组
+// NOTE: 同一个用户组可能来自多个subject, 包括已过期的, 由调用方去重及按过期时间过滤
+func (s *subjectSystemGroupService) ListSystemGroupsBySubjectPKs(
+	pks []int64,
+) (map[string][]types.ThinSubjectGroup, error) {
+	groups, err := s.manager.ListBySubjectPKs(pks)
+	if err != nil {
+		return nil, errorx.Wrapf(err, SubjectSystemGroupSVC, "ListSystemGroupsBySubjectPKs",
+			"manager.ListBySubjectPKs pks=`%+v` fail", pks)
+	}
+
+	systemGroups := map[string][]types.ThinSubjectGroup{}
+	for _, g := range groups {
+		systemGroups[g.SystemID] = append(systemGroups[g.SystemID], types.ThinSubjectGroup{
+			PK:              g.GroupPK,
+			PolicyExpiredAt: g.PolicyExpiredAt,
+		})
+	}
+	return systemGroups, nil
+}
+
+// RefreshSubjects 重新计算subjects及其下级成员的记录
+func (s *subjectSystemGroupService) RefreshSubjects(pks []int64) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSystemGroupSVC, "RefreshSubjects")
+	if len(pks) == 0 {
+		return nil
+	}
+
+	allPKs, err := s.listSubjectsWithDescendants(pks)
+	if err != nil {
+		return errorWrapf(err, "listSubjectsWithDescendants pks=`%+v` fail", pks)
+	}
+
+	for start := 0; start < len(allPKs); start += subjectSystemGroupRefreshChunkSize {
+		end := start + subjectSystemGroupRefreshChunkSize
+		if end > len(allPKs) {
+			end = len(allPKs)
+		}
+
+		err = s.refreshChunk(allPKs[start:end])
+		if err != nil {
+			return errorWrapf(err, "refreshChunk pks=`%+v` fail", allPKs[start:end])
+		}
+	}
+
+	emitSubjectChangeEvent(SubjectChangeEvent{
+		Type:       SubjectChangeEventTypeSystemGroup,
+		SubjectPKs: allPKs,
+	})
+	return nil
+}
+
+func (s *subjectSystemGroupService) refreshChunk(pks []int64) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSystemGroupSVC, "refreshChunk")
+
+	rows, err := s.listRows(pks)
+	if err != nil {
+		return errorWrapf(err, "listRows pks=`%+v` fail", pks)
+	}
+
+	err = s.replaceRows(pks, rows)
+	if err != nil {
+		return errorWrapf(err, "replaceRows pks=`%+v` fail", pks)
+	}
+	return nil
+}
+
+// listRows 根据成员关系及用户组的策略计算subjects的记录
+func (s *subjectSystemGroupService) listRows(pks []int64) ([]dao.SubjectSystemGroup, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSystemGroupSVC, "listRows")
+
+	subjectNestedGroups, err := s.listNestedGroups(pks)
+	if err != nil {
+		return nil, errorWrapf(err, "listNestedGroups pks=`%+v` fail", pks)
+	}
+
+	groupPKSet := util.NewInt64Set()
+	for _, nestedGroups := range subjectNestedGroups {
+		for groupPK := range nestedGroups {
+			groupPKSet.Add(groupPK)
+		}
+	}
+
+	groupSystems, err := s.manager.ListGroupSystems(groupPKSet.ToSlice())
+	if err != nil {
+		return nil, errorWrapf(err, "manager.ListGroupSystems groupPKs=`%+v` fail", groupPKSet.ToSlice())
+	}
+	groupSystemIDs := make(map[int64][]string, groupPKSet.Size())
+	for _, gs := range groupSystems {
+		groupSystemIDs[gs.GroupPK] = append(groupSystemIDs[gs.GroupPK], gs.SystemID)
+	}
+
+	rows := make([]dao.SubjectSystemGroup, 0, len(groupSystems))
+	for _, pk := range pks {
+		nestedGroups := subjectNestedGroups[pk]
+		groupPKs := make([]int64, 0, len(nestedGroups))
+		for groupPK := range nestedGroups {
+			groupPKs = append(groupPKs, groupPK)
+		}
+		sort.Slice(groupPKs, func(i, j int) bool { return groupPKs[i] < groupPKs[j] })
+
+		for _, groupPK := range groupPKs {
+			for _, systemID := range groupSystemIDs[groupPK] {
+				rows = append(rows, dao.SubjectSystemGroup{
+					SubjectPK:       pk,
+					SystemID:        systemID,
+					GroupPK:         groupPK,
+					PolicyExpiredAt: nestedGroups[groupPK],
+				})
+			}
+		}
+	}
+	return rows, nil
+}
+
+// replaceRows 在同一个事务中删除subjects的记录并写入新的记录
+func (s *subjectSystemGroupService) replaceRows(pks []int64, rows []dao.SubjectSystemGroup) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSystemGroupSVC, "replaceRows")
+
+	tx, err := database.GenerateDefaultDBTx()
+	defer database.RollBackWithLog(tx)
+	if err != nil {
+		return errorWrapf(err, "define tx error")
+	}
+
+	err = s.manager.BulkDeleteBySubjectPKsWithTx(tx, pks)
+	if err != nil {
+		return errorWrapf(err, "manager.BulkDeleteBySubjectPKsWithTx pks=`%+v` fail", pks)
+	}
+
+	err = s.manager.BulkCreateWithTx(tx, rows)
+	if err != nil {
+		return errorWrapf(err, "manager.BulkCreateWithTx rows=`%+v` fail", rows)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errorWrapf(err, "tx commit error")
+	}
+	return nil
+}
+
+// listSubjectsWithDescendants 用户组的成员也受用户组加入的用户组影响, 需要逐层加入下级成员
+func (s *subjectSystemGroupService) listSubjectsWithDescendants(pks []int64) ([]int64, error) {
+	visited := util.NewInt64Set()
+	allPKs := make([]int64, 0, len(pks))
+	for _, pk := range pks {
+		if !visited.Has(pk) {
+			visited.Add(pk)
+			allPKs = append(allPKs, pk)
+		}
+	}
+
+	// NOTE: 第N层的下级成员只受前 MaxNestedGroupDepth-N 层的用户组影响, 不需要展开超过 MaxNestedGroupDepth 层
+	parentPKs := allPKs
+	for depth := 1; depth < MaxNestedGroupDepth && len(parentPKs) > 0; depth++ {
+		members, err := s.relationManager.ListMemberByParentPKs(parentPKs)
+		if err != nil {
+			return nil, errorx.Wrapf(err, SubjectSystemGroupSVC, "listSubjectsWithDescendants",
+				"relationManager.ListMemberByParentPKs parentPKs=`%+v` fail", parentPKs)
+		}
+
+		parentPKs = make([]int64, 0, len(members))
+		for _, m := range members {
+			if visited.Has(m.SubjectPK) {
+				continue
+			}
+			visited.Add(m.SubjectPK)
+			allPKs = append(allPKs, m.SubjectPK)
+
+			if m.SubjectType == types.GroupType {
+				parentPKs = append(parentPKs, m.SubjectPK)
+			}
+		}
+	}
+	return allPKs, nil
+}
+
+// listNestedGroups 逐层展开subjects加入的用户组, 返回 subject pk => group pk => 过期时间
+// 同 impls.ExpandNestedGroups: 过期时间取路径上最小的, 多条路径时取最大的
+func (s *subjectSystemGroupService) listNestedGroups(pks []int64) (map[int64]map[int64]int64, error) {
+	parents := make(map[int64][]dao.EffectSubjectRelation, len(pks))
+	loadParents := func(pks []int64) error {
+		missingPKs := make([]int64, 0, len(pks))
+		for _, pk := range pks {
+			if _, ok := parents[pk]; !ok {
+				parents[pk] = nil
+				missingPKs = append(missingPKs, pk)
+			}
+		}
+
+		relations, err := s.relationManager.ListEffectRelationBySubjectPKs(missingPKs)
+		if err != nil {
+			return err
+		}
+		for _, r := range relations {
+			parents[r.SubjectPK] = append(parents[r.SubjectPK], r)
+		}
+		return nil
+	}
+
+	subjectNestedGroups := make(map[int64]map[int64]int64, len(pks))
+	frontiers := make(map[int64]map[int64]int64, len(pks))
+	for _, pk := range pks {
+		subjectNestedGroups[pk] = map[int64]int64{}
+		frontiers[pk] = map[int64]int64{pk: math.MaxInt64}
+	}
+
+	for depth := 0; depth < MaxNestedGroupDepth && len(frontiers) > 0; depth++ {
+		framePKSet := util.NewInt64Set()
+		for _, frontier := range frontiers {
+			for pk := range frontier {
+				framePKSet.Add(pk)
+			}
+		}
+		err := loadParents(framePKSet.ToSlice())
+		if err != nil {
+			return nil, errorx.Wrapf(err, SubjectSystemGroupSVC, "listNestedGroups",
+				"relationManager.ListEffectRelationBySubjectPKs pks=`%+v` fail", framePKSet.ToSlice())
+		}
+
+		nextFrontiers := make(map[int64]map[int64]int64, len(frontiers))
+		for subjectPK, frontier := range frontiers {
+			nestedGroups := subjectNestedGroups[subjectPK]
+			next := map[int64]int64{}
+			for pk, expiredAt := range frontier {
+				for _, parent := range parents[pk] {
+					parentExpiredAt := parent.PolicyExpiredAt
+					if expiredAt < parentExpiredAt {
+						parentExpiredAt = expiredAt
+					}
+
+					oldExpiredAt, ok := nestedGroups[parent.ParentPK]
+					if parent.ParentPK == subjectPK || (ok && oldExpiredAt >= parentExpiredAt) {
+						continue
+					}
+					nestedGroups[parent.ParentPK] = parentExpiredAt
+					next[parent.ParentPK] = parentExpiredAt
+				}
+			}
+			if len(next) > 0 {
+				nextFrontiers[subjectPK] = next
+			}
+		}
+		frontiers = nextFrontiers
+	}
+	return subjectNestedGroups, nil
+}
+
+// SyncGroupSystems 用户组的策略变更后, 同步用户组有策略的系统
+func (s *subjectSystemGroupService) SyncGroupSystems(groupPK int64) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSystemGroupSVC, "SyncGroupSystems")
+
+	groupSystems, err := s.manager.ListGroupSystems([]int64{groupPK})
+	if err != nil {
+		return errorWrapf(err, "manager.ListGroupSystems groupPK=`%d` fail", groupPK)
+	}
+	systemIDSet := util.NewStringSet()
+	for _, gs := range groupSystems {
+		systemIDSet.Add(gs.SystemID)
+	}
+
+	oldSystemIDs, err := s.manager.ListSystemIDsByGroupPK(groupPK)
+	if err != nil {
+		return errorWrapf(err, "manager.ListSystemIDsByGroupPK groupPK=`%d` fail", groupPK)
+	}
+	oldSystemIDSet := util.NewStringSetWithValues(oldSystemIDs)
+
+	// 1. 新增了有策略的系统, 重新计算所有成员的记录(同时会删除已没有策略的系统的记录)
+	if systemIDSet.Diff(oldSystemIDSet).Size() > 0 {
+		memberPKs, err := s.listGroupMemberPKs(groupPK)
+		if err != nil {
+			return errorWrapf(err, "listGroupMemberPKs groupPK=`%d` fail", groupPK)
+		}
+
+		// 成员较多时异步重新计算, 避免阻塞策略变更的请求
+		if len(memberPKs) > syncGroupSystemsMaxSyncMembers {
+			enqueueGroupSystemsRefresh(groupPK)
+			return nil
+		}
+
+		err = s.RefreshSubjects(memberPKs)
+		if err != nil {
+			return errorWrapf(err, "RefreshSubjects memberPKs=`%+v` fail", memberPKs)
+		}
+		return nil
+	}
+
+	// 2. 系统下已没有策略, 删除所有成员的记录
+	subjectPKSet := util.NewInt64Set()
+	for _, systemID := range oldSystemIDs {
+		if systemIDSet.Has(systemID) {
+			continue
+		}
+
+		subjectPKs, err := s.manager.ListSubjectPKsBySystemGroup(systemID, groupPK)
+		if err != nil {
+			return errorWrapf(err, "manager.ListSubjectPKsBySystemGroup systemID=`%s`, groupPK=`%d` fail",
+				systemID, groupPK)
+		}
+		subjectPKSet.Append(subjectPKs...)
+
+		_, err = s.manager.DeleteBySystemGroup(systemID, groupPK)
+		if err != nil {
+			return errorWrapf(err, "manager.DeleteBySystemGroup systemID=`%s`, groupPK=`%d` fail",
+				systemID, groupPK)
+		}
+	}
+
+	if subjectPKSet.Size() > 0 {
+		emitSubjectChangeEvent(SubjectChangeEvent{
+			Type:       SubjectChangeEventTypeSystemGroup,
+			SubjectPKs: subjectPKSet.ToSlice(),
+		})
+	}
+	return nil
+}
+
+func (s *subjectSystemGroupService) listGroupMemberPKs(groupPK int64) ([]int64, error) {
+	members, err := s.relationManager.ListMemberByParentPKs([]int64{groupPK})
+	if err != nil {
+		return nil, errorx.Wrapf(err, SubjectSystemGroupSVC, "listGroupMemberPKs",
+			"relationManager.ListMemberByParentPKs groupPK=`%d` fail", groupPK)
+	}
+	memberPKs := make([]int64, 0, len(members))
+	for _, m := range members {
+		memberPKs = append(memberPKs, m.SubjectPK)
+	}
+	return memberPKs, nil
+}
+
+func enqueueGroupSystemsRefresh(groupPK int64) {
+	select {
+	case groupSystemsRefreshQueue <- groupPK:
+	default:
+		log.Errorf("the group systems refresh queue is full, the members of group `%d` will be reconciled later",
+			groupPK)
+		util.ReportToSentry(
+			"subject system group: refresh queue full",
+			map[string]interface{}{
+				"group_pk": groupPK,
+			},
+		)
+	}
+}
+
+// RunSubjectSystemGroupRefreshWorker 异步重新计算成员较多的用户组的所有成员的记录, 阻塞直到ctx结束
+// NOTE: 服务退出时队列中未处理的用户组由定时的比对修正
+func RunSubjectSystemGroupRefreshWorker(ctx context.Context) {
+	svc := NewSubjectSystemGroupService().(*subjectSystemGroupService)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case groupPK := <-groupSystemsRefreshQueue:
+			svc.refreshGroupMembers(groupPK)
+		}
+	}
+}
+
+func (s *subjectSystemGroupService) refreshGroupMembers(groupPK int64) {
+	// NOTE: 处理时重新查询成员, 入队后成员可能已变更
+	memberPKs, err := s.listGroupMemberPKs(groupPK)
+	if err != nil {
+		log.WithError(err).Errorf("refreshGroupMembers listGroupMemberPKs groupPK=`%d` fail", groupPK)
+		return
+	}
+
+	err = s.RefreshSubjects(memberPKs)
+	if err != nil {
+		log.WithError(err).Errorf("refreshGroupMembers RefreshSubjects groupPK=`%d` fail", groupPK)
+	}
+}
+
+// ReconcileSubjects 按pk顺序比对一批subject(加入了用户组或已有记录的)的记录, 只修正与重新计算结果不一致的subject
+// 返回本批最后一个subject pk, 作为下一批的起点; 没有更多subject时返回0
+func (s *subjectSystemGroupService) ReconcileSubjects(afterPK, limit int64) (lastPK int64, changed int64, err error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSystemGroupSVC, "ReconcileSubjects")
+
+	// 1. 加入了用户组的subject可能缺少记录, 已有记录的subject可能已退出用户组, 合并两者中pk最小的一批
+	memberPKs, err := s.relationManager.ListSubjectPKsAfterPK(afterPK, limit)
+	if err != nil {
+		err = errorWrapf(err, "relationManager.ListSubjectPKsAfterPK afterPK=`%d`, limit=`%d` fail", afterPK, limit)
+		return
+	}
+	recordPKs, err := s.manager.ListSubjectPKsAfterPK(afterPK, limit)
+	if err != nil {
+		err = errorWrapf(err, "manager.ListSubjectPKsAfterPK afterPK=`%d`, limit=`%d` fail", afterPK, limit)
+		return
+	}
+
+	pks := util.NewInt64SetWithValues(append(memberPKs, recordPKs...)).ToSlice()
+	if len(pks) == 0 {
+		return 0, 0, nil
+	}
+	sort.Slice(pks, func(i, j int) bool { return pks[i] < pks[j] })
+	if int64(len(pks)) > limit {
+		pks = pks[:limit]
+	}
+	lastPK = pks[len(pks)-1]
+
+	// 2. 比对重新计算的记录与已有的记录
+	rows, err := s.listRows(pks)
+	if err != nil {
+		err = errorWrapf(err, "listRows pks=`%+v` fail", pks)
+		return
+	}
+	oldRows, err := s.manager.ListBySubjectPKs(pks)
+	if err != nil {
+		err = errorWrapf(err, "manager.ListBySubjectPKs pks=`%+v` fail", pks)
+		return
+	}
+
+	rowKeys := groupSubjectSystemGroupKeys(pks, rows)
+	oldRowKeys := groupSubjectSystemGroupKeys(pks, oldRows)
+	changedPKSet := util.NewInt64Set()
+	for _, pk := range pks {
+		keys, oldKeys := rowKeys[pk], oldRowKeys[pk]
+		if keys.Size() != oldKeys.Size() || keys.Diff(oldKeys).Size() > 0 {
+			changedPKSet.Add(pk)
+		}
+	}
+	if changedPKSet.Size() == 0 {
+		return lastPK, 0, nil
+	}
+
+	// 3. 只修正不一致的subject
+	changedPKs := make([]int64, 0, changedPKSet.Size())
+	for _, pk := range pks {
+		if changedPKSet.Has(pk) {
+			changedPKs = append(changedPKs, pk)
+		}
+	}
+	changedRows := make([]dao.SubjectSystemGroup, 0, len(rows))
+	for _, row := range rows {
+		if changedPKSet.Has(row.SubjectPK) {
+			changedRows = append(changedRows, row)
+		}
+	}
+
+	err = s.replaceRows(changedPKs, changedRows)
+	if err != nil {
+		err = errorWrapf(err, "replaceRows pks=`%+v` fail", changedPKs)
+		return
+	}
+
+	log.Infof("reconcile the subject system groups of subjects `%+v`", changedPKs)
+	emitSubjectChangeEvent(SubjectChangeEvent{
+		Type:       SubjectChangeEventTypeSystemGroup,
+		SubjectPKs: changedPKs,
+	})
+	return lastPK, int64(len(changedPKs)), nil
+}
+
+// groupSubjectSystemGroupKeys subject pk => 记录的 system:group:expiredAt
+func groupSubjectSystemGroupKeys(pks []int64, rows []dao.SubjectSystemGroup) map[int64]*util.StringSet {
+	keys := make(map[int64]*util.StringSet, len(pks))
+	for _, pk := range pks {
+		keys[pk] = util.NewStringSet()
+	}
+	for _, row := range rows {
+		keys[row.SubjectPK].Add(fmt.Sprintf("%s:%d:%d", row.SystemID, row.GroupPK, row.PolicyExpiredAt))
+	}
+	return keys
+}
+
+// DeleteSubjects subject删除后, 删除其记录, 并重新计算加入了被删除用户组的subject的记录
+func (s *subjectSystemGroupService) DeleteSubjects(pks []int64) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(SubjectSystemGroupSVC, "DeleteSubjects")
+	if len(pks) == 0 {
+		return nil
+	}
+
+	memberPKs, err := s.manager.ListSubjectPKsByGroupPKs(pks)
+	if err != nil {
+		return errorWrapf(err, "manager.ListSubjectPKsByGroupPKs pks=`%+v` fail", pks)
+	}
+
+	tx, err := database.GenerateDefaultDBTx()
+	defer database.RollBackWithLog(tx)
+	if err != nil {
+		return errorWrapf(err, "define tx error")
+	}
+
+	err = s.manager.BulkDeleteBySubjectPKsWithTx(tx, pks)
+	if err != nil {
+		return errorWrapf(err, "manager.BulkDeleteBySubjectPKsWithTx pks=`%+v` fail", pks)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errorWrapf(err, "tx commit error")
+	}
+
+	emitSubjectChangeEvent(SubjectChangeEvent{
+		Type:       SubjectChangeEventTypeSystemGroup,
+		SubjectPKs: pks,
+	})
+
+	deletedPKSet := util.NewInt64SetWithValues(pks)
+	refreshPKs := make([]int64, 0, len(memberPKs))
+	for _, pk := range memberPKs {
+		if !deletedPKSet.Has(pk) {
+			refreshPKs = append(refreshPKs, pk)
+		}
+	}
+
+	err = s.RefreshSubjects(refreshPKs)
+	if err != nil {
+		return errorWrapf(err, "RefreshSubjects pks=`%+v` fail", refreshPKs)
+	}
+	return nil
+}
+
+// listSubjectPKs 将事件中的 Subjects 转换为 pk
+func (s *subjectSystemGroupService) listSubjectPKs(subjects []types.Subject) ([]int64, error) {
+	typeIDs := map[string][]string{}
+	for _, subject := range subjects {
+		typeIDs[subject.Type] = append(typeIDs[subject.Type], subject.ID)
+	}
+
+	pks := make([]int64, 0, len(subjects))
+	for _type, ids := range typeIDs {
+		daoSubjects, err := s.subjectManager.ListByIDs(_type, ids)
+		if err != nil {
+			return nil, errorx.Wrapf(err, SubjectSystemGroupSVC, "listSubjectPKs",
+				"subjectManager.ListByIDs _type=`%s`, ids=`%+v` fail", _type, ids)
+		}
+		for _, subject := range daoSubjects {
+			pks = append(pks, subject.PK)
+		}
+	}
+	return pks, nil
+}
+
+// HandleSubjectChangeEventForSystemGroups 成员变更及subject删除后维护subject_system_group, 启动时注册
+func HandleSubjectChangeEventForSystemGroups(event SubjectChangeEvent) {
+	svc := NewSubjectSystemGroupService().(*subjectSystemGroupService)
+	handleSubjectChangeEventForSystemGroups(svc, event)
+}
+
+func handleSubjectChangeEventForSystemGroups(svc *subjectSystemGroupService, event SubjectChangeEvent) {
+	switch event.Type {
+	case SubjectChangeEventTypeMember:
+		pks := append([]int64{}, event.SubjectPKs...)
+		if len(event.Subjects) > 0 {
+			subjectPKs, err := svc.listSubjectPKs(event.Subjects)
+			if err != nil {
+				log.WithError(err).Errorf("handleSubjectChangeEventForSystemGroups listSubjectPKs fail, event=`%+v`",
+					event)
+				return
+			}
+			pks = append(pks, subjectPKs...)
+		}
+
+		err := svc.RefreshSubjects(pks)
+		if err != nil {
+			log.WithError(err).Errorf("handleSubjectChangeEventForSystemGroups RefreshSubjects fail, pks=`%+v`", pks)
+		}
+	case SubjectChangeEventTypeSubject:
+		// 只有删除事件带有 SubjectPKs
+		err := svc.DeleteSubjects(event.SubjectPKs)
+		if err != nil {
+			log.WithError(err).Errorf("handleSubjectChangeEventForSystemGroups DeleteSubjects fail, pks=`%+v`",
+				event.SubjectPKs)
+		}
+	}
+}
@@ -0,0 +1,438 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package service
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+	"iam/pkg/database/dao"
+	"iam/pkg/database/dao/mock"
+	"iam/pkg/service/types"
+)
+
+var _ = Describe("SubjectSystemGroupService", func() {
+	var ctl *gomock.Controller
+	var patches *gomonkey.Patches
+	var events []SubjectChangeEvent
+	var mockManager *mock.MockSubjectSystemGroupManager
+	var mockRelationManager *mock.MockSubjectRelationManager
+	var mockSubjectManager *mock.MockSubjectManager
+	var svc *subjectSystemGroupService
+
+	// user(1) => group(10, 2000) => group(20, 1500)
+	// user(1) => group(30, 3000) => group(20, 1800)
+	effectRelations := map[int64][]dao.EffectSubjectRelation{
+		1: {
+			{SubjectPK: 1, ParentPK: 10, PolicyExpiredAt: 2000},
+			{SubjectPK: 1, ParentPK: 30, PolicyExpiredAt: 3000},
+		},
+		10: {{SubjectPK: 10, ParentPK: 20, PolicyExpiredAt: 1500}},
+		30: {{SubjectPK: 30, ParentPK: 20, PolicyExpiredAt: 1800}},
+	}
+	listEffectRelation := func(pks []int64) ([]dao.EffectSubjectRelation, error) {
+		relations := []dao.EffectSubjectRelation{}
+		for _, pk := range pks {
+			relations = append(relations, effectRelations[pk]...)
+		}
+		return relations, nil
+	}
+
+	BeforeEach(func() {
+		ctl = gomock.NewController(GinkgoT())
+		mockManager = mock.NewMockSubjectSystemGroupManager(ctl)
+		mockRelationManager = mock.NewMockSubjectRelationManager(ctl)
+		mockSubjectManager = mock.NewMockSubjectManager(ctl)
+		svc = &subjectSystemGroupService{
+			manager:         mockManager,
+			relationManager: mockRelationManager,
+			subjectManager:  mockSubjectManager,
+		}
+
+		events = nil
+		patches = gomonkey.ApplyFunc(emitSubjectChangeEvent, func(event SubjectChangeEvent) {
+			events = append(events, event)
+		})
+	})
+
+	AfterEach(func() {
+		ctl.Finish()
+		patches.Reset()
+	})
+
+	Describe("ListSubjectSystemGroups", func() {
+		It("manager.ListBySystemSubjectPKs fail", func() {
+			mockManager.EXPECT().ListBySystemSubjectPKs("test", []int64{1, 2}).Return(nil, errors.New("list fail"))
+
+			_, err := svc.ListSubjectSystemGroups("test", []int64{1, 2})
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListBySystemSubjectPKs")
+		})
+
+		It("ok", func() {
+			mockManager.EXPECT().ListBySystemSubjectPKs("test", []int64{1, 2}).Return([]dao.SubjectSystemGroup{
+				{SubjectPK: 1, SystemID: "test", GroupPK: 10, PolicyExpiredAt: 2000},
+				{SubjectPK: 1, SystemID: "test", GroupPK: 20, PolicyExpiredAt: 1800},
+			}, nil)
+
+			subjectGroups, err := svc.ListSubjectSystemGroups("test", []int64{1, 2})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[int64][]types.ThinSubjectGroup{
+				1: {{PK: 10, PolicyExpiredAt: 2000}, {PK: 20, PolicyExpiredAt: 1800}},
+			}, subjectGroups)
+		})
+	})
+
+	Describe("ListSystemGroupsBySubjectPKs", func() {
+		It("manager.ListBySubjectPKs fail", func() {
+			mockManager.EXPECT().ListBySubjectPKs([]int64{1, 2}).Return(nil, errors.New("list fail"))
+
+			_, err := svc.ListSystemGroupsBySubjectPKs([]int64{1, 2})
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListBySubjectPKs")
+		})
+
+		It("ok", func() {
+			mockManager.EXPECT().ListBySubjectPKs([]int64{1, 2}).Return([]dao.SubjectSystemGroup{
+				{SubjectPK: 1, SystemID: "test", GroupPK: 10, PolicyExpiredAt: 2000},
+				{SubjectPK: 1, SystemID: "bk_cmdb", GroupPK: 10, PolicyExpiredAt: 2000},
+				{SubjectPK: 2, SystemID: "test", GroupPK: 20, PolicyExpiredAt: 1800},
+			}, nil)
+
+			systemGroups, err := svc.ListSystemGroupsBySubjectPKs([]int64{1, 2})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[string][]types.ThinSubjectGroup{
+				"test":    {{PK: 10, PolicyExpiredAt: 2000}, {PK: 20, PolicyExpiredAt: 1800}},
+				"bk_cmdb": {{PK: 10, PolicyExpiredAt: 2000}},
+			}, systemGroups)
+		})
+	})
+
+	Describe("listNestedGroups", func() {
+		It("fail", func() {
+			mockRelationManager.EXPECT().ListEffectRelationBySubjectPKs([]int64{1}).Return(nil, errors.New("list fail"))
+
+			_, err := svc.listNestedGroups([]int64{1})
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListEffectRelationBySubjectPKs")
+		})
+
+		It("ok, the expired_at of nested group is the max of the paths", func() {
+			mockRelationManager.EXPECT().ListEffectRelationBySubjectPKs(gomock.Any()).
+				DoAndReturn(listEffectRelation).AnyTimes()
+
+			subjectNestedGroups, err := svc.listNestedGroups([]int64{1})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[int64]map[int64]int64{
+				1: {10: 2000, 30: 3000, 20: 1800},
+			}, subjectNestedGroups)
+		})
+
+		It("ok, group cycle", func() {
+			mockRelationManager.EXPECT().ListEffectRelationBySubjectPKs(gomock.Any()).
+				DoAndReturn(func(pks []int64) ([]dao.EffectSubjectRelation, error) {
+					relations := []dao.EffectSubjectRelation{}
+					for _, pk := range pks {
+						parentPK := int64(10)
+						if pk == 10 {
+							parentPK = 1
+						}
+						relations = append(relations, dao.EffectSubjectRelation{
+							SubjectPK: pk, ParentPK: parentPK, PolicyExpiredAt: 2000,
+						})
+					}
+					return relations, nil
+				}).AnyTimes()
+
+			subjectNestedGroups, err := svc.listNestedGroups([]int64{1})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), map[int64]map[int64]int64{1: {10: 2000}}, subjectNestedGroups)
+		})
+	})
+
+	Describe("RefreshSubjects", func() {
+		It("relationManager.ListMemberByParentPKs fail", func() {
+			mockRelationManager.EXPECT().ListMemberByParentPKs([]int64{1}).Return(nil, errors.New("list fail"))
+
+			err := svc.RefreshSubjects([]int64{1})
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListMemberByParentPKs")
+			assert.Empty(GinkgoT(), events)
+		})
+
+		It("ok", func() {
+			mockRelationManager.EXPECT().ListMemberByParentPKs([]int64{1}).Return(nil, nil)
+			mockRelationManager.EXPECT().ListEffectRelationBySubjectPKs(gomock.Any()).
+				DoAndReturn(listEffectRelation).AnyTimes()
+			mockManager.EXPECT().ListGroupSystems(gomock.Any()).Return([]dao.GroupSystem{
+				{GroupPK: 20, SystemID: "test"},
+				{GroupPK: 30, SystemID: "test"},
+				{GroupPK: 30, SystemID: "other"},
+			}, nil)
+			mockManager.EXPECT().BulkDeleteBySubjectPKsWithTx(gomock.Any(), []int64{1}).Return(nil)
+			mockManager.EXPECT().BulkCreateWithTx(gomock.Any(), []dao.SubjectSystemGroup{
+				{SubjectPK: 1, SystemID: "test", GroupPK: 20, PolicyExpiredAt: 1800},
+				{SubjectPK: 1, SystemID: "test", GroupPK: 30, PolicyExpiredAt: 3000},
+				{SubjectPK: 1, SystemID: "other", GroupPK: 30, PolicyExpiredAt: 3000},
+			}).Return(nil)
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+			patches.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+
+			err := svc.RefreshSubjects([]int64{1})
+			assert.NoError(GinkgoT(), err)
+			assert.NoError(GinkgoT(), dbMock.ExpectationsWereMet())
+			assert.Equal(GinkgoT(), []SubjectChangeEvent{
+				{Type: SubjectChangeEventTypeSystemGroup, SubjectPKs: []int64{1}},
+			}, events)
+		})
+
+		It("ok, with the members of group", func() {
+			mockRelationManager.EXPECT().ListMemberByParentPKs([]int64{10}).Return([]dao.SubjectRelationMember{
+				{SubjectPK: 1, SubjectType: types.UserType},
+				{SubjectPK: 11, SubjectType: types.GroupType},
+			}, nil)
+			mockRelationManager.EXPECT().ListMemberByParentPKs([]int64{11}).Return([]dao.SubjectRelationMember{
+				{SubjectPK: 2, SubjectType: types.UserType},
+				{SubjectPK: 1, SubjectType: types.UserType},
+			}, nil)
+			mockRelationManager.EXPECT().ListMemberByParentPKs([]int64{}).Return(nil, nil).AnyTimes()
+			mockRelationManager.EXPECT().ListEffectRelationBySubjectPKs(gomock.Any()).Return(nil, nil).AnyTimes()
+			mockManager.EXPECT().ListGroupSystems(gomock.Any()).Return(nil, nil)
+			mockManager.EXPECT().BulkDeleteBySubjectPKsWithTx(gomock.Any(), []int64{10, 1, 11, 2}).Return(nil)
+			mockManager.EXPECT().BulkCreateWithTx(gomock.Any(), []dao.SubjectSystemGroup{}).Return(nil)
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+			patches.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+
+			err := svc.RefreshSubjects([]int64{10})
+			assert.NoError(GinkgoT(), err)
+			assert.NoError(GinkgoT(), dbMock.ExpectationsWereMet())
+			assert.Equal(GinkgoT(), []int64{10, 1, 11, 2}, events[0].SubjectPKs)
+		})
+	})
+
+	Describe("SyncGroupSystems", func() {
+		It("manager.ListGroupSystems fail", func() {
+			mockManager.EXPECT().ListGroupSystems([]int64{10}).Return(nil, errors.New("list fail"))
+
+			err := svc.SyncGroupSystems(10)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListGroupSystems")
+		})
+
+		It("no change", func() {
+			mockManager.EXPECT().ListGroupSystems([]int64{10}).Return([]dao.GroupSystem{
+				{GroupPK: 10, SystemID: "test"},
+			}, nil)
+			mockManager.EXPECT().ListSystemIDsByGroupPK(int64(10)).Return([]string{"test"}, nil)
+
+			err := svc.SyncGroupSystems(10)
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), events)
+		})
+
+		It("no policies in the system", func() {
+			mockManager.EXPECT().ListGroupSystems([]int64{10}).Return([]dao.GroupSystem{}, nil)
+			mockManager.EXPECT().ListSystemIDsByGroupPK(int64(10)).Return([]string{"test"}, nil)
+			mockManager.EXPECT().ListSubjectPKsBySystemGroup("test", int64(10)).Return([]int64{1}, nil)
+			mockManager.EXPECT().DeleteBySystemGroup("test", int64(10)).Return(int64(1), nil)
+
+			err := svc.SyncGroupSystems(10)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []SubjectChangeEvent{
+				{Type: SubjectChangeEventTypeSystemGroup, SubjectPKs: []int64{1}},
+			}, events)
+		})
+
+		It("new system, refresh the members", func() {
+			mockManager.EXPECT().ListGroupSystems([]int64{10}).Return([]dao.GroupSystem{
+				{GroupPK: 10, SystemID: "test"},
+			}, nil)
+			mockManager.EXPECT().ListSystemIDsByGroupPK(int64(10)).Return([]string{}, nil)
+			mockRelationManager.EXPECT().ListMemberByParentPKs([]int64{10}).Return([]dao.SubjectRelationMember{
+				{SubjectPK: 1, SubjectType: types.UserType},
+			}, nil)
+
+			refreshed := []int64{}
+			patches.ApplyMethod(reflect.TypeOf(svc), "RefreshSubjects",
+				func(_ *subjectSystemGroupService, pks []int64) error {
+					refreshed = append(refreshed, pks...)
+					return nil
+				})
+
+			err := svc.SyncGroupSystems(10)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []int64{1}, refreshed)
+		})
+
+		It("new system, refresh the members asynchronously", func() {
+			mockManager.EXPECT().ListGroupSystems([]int64{10}).Return([]dao.GroupSystem{
+				{GroupPK: 10, SystemID: "test"},
+			}, nil)
+			mockManager.EXPECT().ListSystemIDsByGroupPK(int64(10)).Return([]string{}, nil)
+			mockRelationManager.EXPECT().ListMemberByParentPKs([]int64{10}).Return([]dao.SubjectRelationMember{
+				{SubjectPK: 1, SubjectType: types.UserType},
+				{SubjectPK: 2, SubjectType: types.UserType},
+			}, nil).Times(2)
+
+			patches.ApplyGlobalVar(&syncGroupSystemsMaxSyncMembers, 1)
+			refreshed := []int64{}
+			patches.ApplyMethod(reflect.TypeOf(svc), "RefreshSubjects",
+				func(_ *subjectSystemGroupService, pks []int64) error {
+					refreshed = append(refreshed, pks...)
+					return nil
+				})
+
+			err := svc.SyncGroupSystems(10)
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), refreshed)
+			assert.Len(GinkgoT(), groupSystemsRefreshQueue, 1)
+
+			svc.refreshGroupMembers(<-groupSystemsRefreshQueue)
+			assert.Equal(GinkgoT(), []int64{1, 2}, refreshed)
+		})
+	})
+
+	Describe("ReconcileSubjects", func() {
+		It("relationManager.ListSubjectPKsAfterPK fail", func() {
+			mockRelationManager.EXPECT().ListSubjectPKsAfterPK(int64(0), int64(2)).Return(nil, errors.New("list fail"))
+
+			_, _, err := svc.ReconcileSubjects(0, 2)
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListSubjectPKsAfterPK")
+		})
+
+		It("no more subjects", func() {
+			mockRelationManager.EXPECT().ListSubjectPKsAfterPK(int64(5), int64(2)).Return([]int64{}, nil)
+			mockManager.EXPECT().ListSubjectPKsAfterPK(int64(5), int64(2)).Return([]int64{}, nil)
+
+			lastPK, changed, err := svc.ReconcileSubjects(5, 2)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(0), lastPK)
+			assert.Equal(GinkgoT(), int64(0), changed)
+		})
+
+		It("ok, only write the subjects changed", func() {
+			// subject 1 is consistent, subject 2 missing the row, subject 3 has been removed from the group
+			// subject 4 is out of the batch
+			mockRelationManager.EXPECT().ListSubjectPKsAfterPK(int64(0), int64(3)).Return([]int64{1, 2, 4}, nil)
+			mockManager.EXPECT().ListSubjectPKsAfterPK(int64(0), int64(3)).Return([]int64{1, 3, 4}, nil)
+			mockRelationManager.EXPECT().ListEffectRelationBySubjectPKs(gomock.Any()).DoAndReturn(
+				func(pks []int64) ([]dao.EffectSubjectRelation, error) {
+					relations := []dao.EffectSubjectRelation{}
+					for _, pk := range pks {
+						if pk == 1 || pk == 2 {
+							relations = append(relations,
+								dao.EffectSubjectRelation{SubjectPK: pk, ParentPK: 30, PolicyExpiredAt: 3000})
+						}
+					}
+					return relations, nil
+				}).AnyTimes()
+			mockManager.EXPECT().ListGroupSystems([]int64{30}).Return([]dao.GroupSystem{
+				{GroupPK: 30, SystemID: "test"},
+			}, nil)
+			mockManager.EXPECT().ListBySubjectPKs([]int64{1, 2, 3}).Return([]dao.SubjectSystemGroup{
+				{SubjectPK: 1, SystemID: "test", GroupPK: 30, PolicyExpiredAt: 3000},
+				{SubjectPK: 3, SystemID: "test", GroupPK: 30, PolicyExpiredAt: 3000},
+			}, nil)
+			mockManager.EXPECT().BulkDeleteBySubjectPKsWithTx(gomock.Any(), []int64{2, 3}).Return(nil)
+			mockManager.EXPECT().BulkCreateWithTx(gomock.Any(), []dao.SubjectSystemGroup{
+				{SubjectPK: 2, SystemID: "test", GroupPK: 30, PolicyExpiredAt: 3000},
+			}).Return(nil)
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+			patches.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+
+			lastPK, changed, err := svc.ReconcileSubjects(0, 3)
+			assert.NoError(GinkgoT(), err)
+			assert.NoError(GinkgoT(), dbMock.ExpectationsWereMet())
+			assert.Equal(GinkgoT(), int64(3), lastPK)
+			assert.Equal(GinkgoT(), int64(2), changed)
+			assert.Equal(GinkgoT(), []SubjectChangeEvent{
+				{Type: SubjectChangeEventTypeSystemGroup, SubjectPKs: []int64{2, 3}},
+			}, events)
+		})
+	})
+
+	Describe("DeleteSubjects", func() {
+		It("ok", func() {
+			mockManager.EXPECT().ListSubjectPKsByGroupPKs([]int64{10}).Return([]int64{1, 10}, nil)
+			mockManager.EXPECT().BulkDeleteBySubjectPKsWithTx(gomock.Any(), []int64{10}).Return(nil)
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+			patches.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+
+			refreshed := []int64{}
+			patches.ApplyMethod(reflect.TypeOf(svc), "RefreshSubjects",
+				func(_ *subjectSystemGroupService, pks []int64) error {
+					refreshed = append(refreshed, pks...)
+					return nil
+				})
+
+			err := svc.DeleteSubjects([]int64{10})
+			assert.NoError(GinkgoT(), err)
+			assert.NoError(GinkgoT(), dbMock.ExpectationsWereMet())
+			assert.Equal(GinkgoT(), []SubjectChangeEvent{
+				{Type: SubjectChangeEventTypeSystemGroup, SubjectPKs: []int64{10}},
+			}, events)
+			assert.Equal(GinkgoT(), []int64{1}, refreshed)
+		})
+	})
+
+	Describe("handleSubjectChangeEventForSystemGroups", func() {
+		It("member event", func() {
+			mockSubjectManager.EXPECT().ListByIDs("user", []string{"tom"}).Return([]dao.Subject{{PK: 2}}, nil)
+
+			refreshed := []int64{}
+			patches.ApplyMethod(reflect.TypeOf(svc), "RefreshSubjects",
+				func(_ *subjectSystemGroupService, pks []int64) error {
+					refreshed = append(refreshed, pks...)
+					return nil
+				})
+
+			handleSubjectChangeEventForSystemGroups(svc, SubjectChangeEvent{
+				Type:       SubjectChangeEventTypeMember,
+				SubjectPKs: []int64{1},
+				Subjects:   []types.Subject{{Type: "user", ID: "tom"}},
+			})
+			assert.Equal(GinkgoT(), []int64{1, 2}, refreshed)
+		})
+
+		It("subject deleted event", func() {
+			deleted := []int64{}
+			patches.ApplyMethod(reflect.TypeOf(svc), "DeleteSubjects",
+				func(_ *subjectSystemGroupService, pks []int64) error {
+					deleted = append(deleted, pks...)
+					return nil
+				})
+
+			handleSubjectChangeEventForSystemGroups(svc, SubjectChangeEvent{
+				Type:       SubjectChangeEventTypeSubject,
+				SubjectPKs: []int64{10},
+			})
+			assert.Equal(GinkgoT(), []int64{10}, deleted)
+		})
+	})
+})
@@ -154,6 +154,56 @@ var _ = Describe("SubjectService", func() {
 
 	})
 
+	Describe("ListExistSubjects", func() {
+		var ctl *gomock.Controller
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("ListByIDs fail", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().ListByIDs("user", []string{"test"}).Return(
+				nil, errors.New("list fail"),
+			)
+
+			manager := &subjectService{
+				manager: mockSubjectManager,
+			}
+
+			_, err := manager.ListExistSubjects([]types.Subject{{Type: "user", ID: "test"}})
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ListByIDs")
+		})
+
+		It("ok", func() {
+			mockSubjectManager := mock.NewMockSubjectManager(ctl)
+			mockSubjectManager.EXPECT().ListByIDs("user", []string{"tom", "deleted"}).Return(
+				[]dao.Subject{{PK: 1, Type: "user", ID: "tom", Name: "Tom"}}, nil,
+			)
+			mockSubjectManager.EXPECT().ListByIDs("department", []string{"1"}).Return(
+				[]dao.Subject{{PK: 2, Type: "department", ID: "1", Name: "dept"}}, nil,
+			)
+
+			manager := &subjectService{
+				manager: mockSubjectManager,
+			}
+
+			subjects, err := manager.ListExistSubjects([]types.Subject{
+				{Type: "user", ID: "tom"},
+				{Type: "department", ID: "1"},
+				{Type: "user", ID: "deleted"},
+			})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []types.Subject{
+				{Type: "user", ID: "tom", Name: "Tom"},
+				{Type: "department", ID: "1", Name: "dept"},
+			}, subjects)
+		})
+	})
+
 	Describe("BulkCreate", func() {
 		var ctl *gomock.Controller
 		BeforeEach(func() {
@@ -31,10 +31,13 @@ const (
 	// NOTE:  这里用复数!
 	// 操作组
 
-	ConfigKeyActionGroups           = "action_groups"
-	ConfigKeyResourceCreatorActions = "resource_creator_actions"
-	ConfigKeyCommonActions          = "common_actions"
-	ConfigKeyFeatureShieldRules     = "feature_shield_rules"
+	ConfigKeyActionGroups             = "action_groups"
+	ConfigKeyResourceCreatorActions   = "resource_creator_actions"
+	ConfigKeyCommonActions            = "common_actions"
+	ConfigKeyFeatureShieldRules       = "feature_shield_rules"
+	ConfigKeyResourceAttributeSchemas = "resource_attribute_schemas"
+	ConfigKeyPolicyCacheBackend       = "policy_cache_backend"
+	ConfigKeyModelChangeEventCallback = "model_change_event_callback"
 
 	ConfigTypeJSON = "json"
 )
@@ -63,6 +66,21 @@ type SystemConfigService interface {
 
 	GetFeatureShieldRules(system string) ([]interface{}, error)
 	CreateOrUpdateFeatureShieldRules(system string, featureShieldRules []interface{}) error
+
+	// resourceAttributeSchemas
+
+	GetResourceAttributeSchemas(system string) (map[string]interface{}, error)
+	CreateOrUpdateResourceAttributeSchemas(system string, schemas map[string]interface{}) error
+
+	// policyCacheBackend
+
+	GetPolicyCacheBackend(system string) (string, error)
+	CreateOrUpdatePolicyCacheBackend(system string, backend string) error
+
+	// modelChangeEventCallback
+
+	GetModelChangeEventCallback(system string) (map[string]interface{}, error)
+	CreateOrUpdateModelChangeEventCallback(system string, callback map[string]interface{}) error
 }
 
 type systemConfigService struct {
@@ -248,3 +266,48 @@ func (s *systemConfigService) CreateOrUpdateFeatureShieldRules(
 ) (err error) {
 	return s.createOrUpdate(system, ConfigKeyFeatureShieldRules, ConfigTypeJSON, featureShieldRules)
 }
+
+// GetResourceAttributeSchemas ...
+func (s *systemConfigService) GetResourceAttributeSchemas(system string) (map[string]interface{}, error) {
+	return s.getMapConfig(system, ConfigKeyResourceAttributeSchemas)
+}
+
+// CreateOrUpdateResourceAttributeSchemas ...
+func (s *systemConfigService) CreateOrUpdateResourceAttributeSchemas(
+	system string,
+	schemas map[string]interface{},
+) (err error) {
+	return s.createOrUpdate(system, ConfigKeyResourceAttributeSchemas, ConfigTypeJSON, schemas)
+}
+
+// GetPolicyCacheBackend ...
+func (s *systemConfigService) GetPolicyCacheBackend(system string) (string, error) {
+	data, err := s.get(system, ConfigKeyPolicyCacheBackend)
+	if err != nil {
+		return "", err
+	}
+
+	backend, ok := data.(string)
+	if !ok {
+		return "", errors.New("data in db not type string")
+	}
+	return backend, nil
+}
+
+// CreateOrUpdatePolicyCacheBackend ...
+func (s *systemConfigService) CreateOrUpdatePolicyCacheBackend(system string, backend string) (err error) {
+	return s.createOrUpdate(system, ConfigKeyPolicyCacheBackend, ConfigTypeJSON, backend)
+}
+
+// GetModelChangeEventCallback ...
+func (s *systemConfigService) GetModelChangeEventCallback(system string) (map[string]interface{}, error) {
+	return s.getMapConfig(system, ConfigKeyModelChangeEventCallback)
+}
+
+// CreateOrUpdateModelChangeEventCallback ...
+func (s *systemConfigService) CreateOrUpdateModelChangeEventCallback(
+	system string,
+	callback map[string]interface{},
+) error {
+	return s.createOrUpdate(system, ConfigKeyModelChangeEventCallback, ConfigTypeJSON, callback)
+}
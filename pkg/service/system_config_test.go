@@ -120,4 +120,43 @@ var _ = Describe("SystemConfigService", func() {
 			assert.Error(GinkgoT(), err)
 		})
 	})
+
+	Describe("GetPolicyCacheBackend cases", func() {
+		var ctl *gomock.Controller
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("ok", func() {
+			mockSaaSSystemConfigManager := mock.NewMockSaaSSystemConfigManager(ctl)
+			mockSaaSSystemConfigManager.EXPECT().Get("test", ConfigKeyPolicyCacheBackend).Return(
+				sdao.SaaSSystemConfig{Type: "json", Value: `"memory"`}, nil)
+
+			svc := &systemConfigService{
+				manager: mockSaaSSystemConfigManager,
+			}
+
+			backend, err := svc.GetPolicyCacheBackend("test")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "memory", backend)
+		})
+
+		It("error", func() {
+			mockSaaSSystemConfigManager := mock.NewMockSaaSSystemConfigManager(ctl)
+			mockSaaSSystemConfigManager.EXPECT().Get("test", ConfigKeyPolicyCacheBackend).Return(
+				sdao.SaaSSystemConfig{Type: "json", Value: `{"backend": "memory"}`}, nil)
+
+			svc := &systemConfigService{
+				manager: mockSaaSSystemConfigManager,
+			}
+
+			_, err := svc.GetPolicyCacheBackend("test")
+			assert.Error(GinkgoT(), err)
+		})
+	})
 })
@@ -0,0 +1,53 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package service
+
+import "sync"
+
+// SystemModelChangeEvent的模型类型
+const (
+	SystemModelChangeEventTypeAction = "action"
+)
+
+// SystemModelChangeEvent 系统模型写操作成功后发出的进程内变更事件, 用于读侧(内存索引等)失效
+// NOTE: 区别于 ModelChangeEventService 中持久化的模型变更事件(供SaaS异步处理删除)
+type SystemModelChangeEvent struct {
+	Type     string
+	SystemID string
+	IDs      []string
+}
+
+// SystemModelChangeHandler ...
+type SystemModelChangeHandler func(event SystemModelChangeEvent)
+
+var (
+	systemModelChangeHandlersLock sync.RWMutex
+	systemModelChangeHandlers     []SystemModelChangeHandler
+)
+
+// RegisterSystemModelChangeHandler 注册系统模型变更事件的处理函数, 一般在初始化缓存时注册
+func RegisterSystemModelChangeHandler(handler SystemModelChangeHandler) {
+	systemModelChangeHandlersLock.Lock()
+	systemModelChangeHandlers = append(systemModelChangeHandlers, handler)
+	systemModelChangeHandlersLock.Unlock()
+}
+
+func emitSystemModelChangeEvent(event SystemModelChangeEvent) {
+	if event.SystemID == "" {
+		return
+	}
+
+	systemModelChangeHandlersLock.RLock()
+	defer systemModelChangeHandlersLock.RUnlock()
+
+	for _, handler := range systemModelChangeHandlers {
+		handler(event)
+	}
+}
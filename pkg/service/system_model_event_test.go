@@ -0,0 +1,119 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package service
+
+import (
+	"errors"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/database"
+	"iam/pkg/database/dao/mock"
+	smock "iam/pkg/database/sdao/mock"
+)
+
+var _ = Describe("SystemModelChangeEvent", func() {
+	var events []SystemModelChangeEvent
+	var oldHandlers []SystemModelChangeHandler
+	BeforeEach(func() {
+		events = nil
+		oldHandlers = systemModelChangeHandlers
+		systemModelChangeHandlers = nil
+
+		RegisterSystemModelChangeHandler(func(event SystemModelChangeEvent) {
+			events = append(events, event)
+		})
+	})
+	AfterEach(func() {
+		systemModelChangeHandlers = oldHandlers
+	})
+
+	It("emit", func() {
+		emitSystemModelChangeEvent(SystemModelChangeEvent{
+			Type:     SystemModelChangeEventTypeAction,
+			SystemID: "test",
+			IDs:      []string{"view"},
+		})
+		assert.Len(GinkgoT(), events, 1)
+		assert.Equal(GinkgoT(), []string{"view"}, events[0].IDs)
+	})
+
+	It("emit empty", func() {
+		emitSystemModelChangeEvent(SystemModelChangeEvent{Type: SystemModelChangeEventTypeAction})
+		assert.Empty(GinkgoT(), events)
+	})
+
+	Describe("action BulkDelete", func() {
+		var ctl *gomock.Controller
+		var svc *actionService
+		var patches *gomonkey.Patches
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+
+			mockManager := mock.NewMockActionManager(ctl)
+			mockManager.EXPECT().BulkDeleteWithTx(gomock.Any(), "test", []string{"view"}).Return(nil)
+			mockActionResourceTypeManager := mock.NewMockActionResourceTypeManager(ctl)
+			mockActionResourceTypeManager.EXPECT().BulkDeleteWithTx(
+				gomock.Any(), "test", []string{"view"}).Return(nil)
+			mockSaaSManager := smock.NewMockSaaSActionManager(ctl)
+			mockSaaSManager.EXPECT().BulkDeleteWithTx(gomock.Any(), "test", []string{"view"}).Return(nil)
+
+			svc = &actionService{
+				manager:                   mockManager,
+				actionResourceTypeManager: mockActionResourceTypeManager,
+				saasManager:               mockSaaSManager,
+			}
+		})
+		AfterEach(func() {
+			ctl.Finish()
+			patches.Reset()
+		})
+
+		It("emit after commit", func() {
+			mockSaaSActionResourceTypeManager := smock.NewMockSaaSActionResourceTypeManager(ctl)
+			mockSaaSActionResourceTypeManager.EXPECT().BulkDeleteWithTx(
+				gomock.Any(), "test", []string{"view"}).Return(nil)
+			svc.saasActionResourceTypeManager = mockSaaSActionResourceTypeManager
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+			patches = gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+
+			err := svc.BulkDelete("test", []string{"view"})
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), events, 1)
+			assert.Equal(GinkgoT(), SystemModelChangeEvent{
+				Type:     SystemModelChangeEventTypeAction,
+				SystemID: "test",
+				IDs:      []string{"view"},
+			}, events[0])
+		})
+
+		It("no emit when fail", func() {
+			mockSaaSActionResourceTypeManager := smock.NewMockSaaSActionResourceTypeManager(ctl)
+			mockSaaSActionResourceTypeManager.EXPECT().BulkDeleteWithTx(
+				gomock.Any(), "test", []string{"view"}).Return(errors.New("error"))
+			svc.saasActionResourceTypeManager = mockSaaSActionResourceTypeManager
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectRollback()
+			patches = gomonkey.ApplyFunc(database.GenerateDefaultDBTx, db.Beginx)
+
+			err := svc.BulkDelete("test", []string{"view"})
+			assert.Error(GinkgoT(), err)
+			assert.Empty(GinkgoT(), events)
+		})
+	})
+})
@@ -42,6 +42,7 @@ type Action struct {
 	Description          string               `json:"description" structs:"description"`
 	DescriptionEn        string               `json:"description_en" structs:"description_en"`
 	Type                 string               `json:"type" structs:"type"`
+	Status               string               `json:"status" structs:"status"`
 	Version              int64                `json:"version" structs:"version"`
 	RelatedResourceTypes []ActionResourceType `json:"related_resource_types" structs:"related_resource_types"`
 	RelatedActions       []string             `json:"related_actions" structs:"related_actions"`
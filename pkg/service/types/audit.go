@@ -0,0 +1,46 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package types
+
+import "time"
+
+// 审计记录的类型, 对应不同的变更记录表
+const (
+	AuditTypeDepartment = "department"
+	AuditTypeRole       = "role"
+)
+
+// AuditFilter 审计查询的过滤条件, 空值表示不过滤; 时间范围为[StartTime, EndTime)
+type AuditFilter struct {
+	Type      string
+	SubjectPK int64
+	System    string
+	Operator  string
+	Source    string
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// AuditRecord 审计记录, Target为变更的对象: 部门变更记录为部门, 角色变更记录为角色类型
+type AuditRecord struct {
+	PK          int64     `json:"pk"`
+	Type        string    `json:"type"`
+	SubjectType string    `json:"subject_type"`
+	SubjectID   string    `json:"subject_id"`
+	SubjectName string    `json:"subject_name"`
+	TargetID    string    `json:"target_id"`
+	TargetName  string    `json:"target_name"`
+	System      string    `json:"system_id"`
+	Action      string    `json:"action"`
+	Operator    string    `json:"operator"`
+	Source      string    `json:"source"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -15,7 +15,27 @@ const (
 	UserType       = "user"
 	GroupType      = "group"
 	DepartmentType = "department"
+	// 非自然人的服务账号(机器身份), 不属于任何部门, 不继承部门的权限
+	ServiceAccountType = "service_account"
 
 	SuperManager  = "super_manager"
 	SystemManager = "system_manager"
 )
+
+// 内置的代表所有认证用户的subject, 系统可以将低风险的操作授权给它, 对所有用户生效
+const (
+	AllUsersSubjectType = "special"
+	AllUsersSubjectID   = "all_users"
+)
+
+// 策略的效果, 同一个操作/资源上命中deny策略时, 无论是否命中allow策略, 都没有权限
+const (
+	PolicyEffectAllow = "allow"
+	PolicyEffectDeny  = "deny"
+)
+
+// 操作的状态, 接入系统临时下线某个功能时, 可以将对应的操作置为disabled, 鉴权时会拒绝(或放行, 可配置)
+const (
+	ActionStatusEnabled  = "enabled"
+	ActionStatusDisabled = "disabled"
+)
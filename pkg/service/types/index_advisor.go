@@ -0,0 +1,54 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package types
+
+// 索引诊断的状态
+const (
+	IndexStatusOK = "ok"
+	// 期望的索引在线上表结构中不存在
+	IndexStatusMissing = "missing"
+	// 索引存在, 但是列与期望的不一致
+	IndexStatusMismatch = "mismatch"
+	// 索引存在, 但是执行计划中没有使用
+	IndexStatusIgnored = "ignored"
+)
+
+// IndexCheck 期望的索引与线上表结构的对比
+type IndexCheck struct {
+	Name            string   `json:"name"`
+	ExpectedColumns []string `json:"expected_columns"`
+	ActualColumns   []string `json:"actual_columns"`
+	Status          string   `json:"status"`
+}
+
+// QueryIndexCheck 高频查询的执行计划检查
+type QueryIndexCheck struct {
+	Name            string   `json:"name"`
+	Query           string   `json:"query"`
+	ExpectedIndexes []string `json:"expected_indexes"`
+	PossibleKeys    []string `json:"possible_keys"`
+	Key             string   `json:"key"`
+	AccessType      string   `json:"access_type"`
+	Rows            int64    `json:"rows"`
+	Extra           string   `json:"extra"`
+	Status          string   `json:"status"`
+	Message         string   `json:"message"`
+}
+
+// IndexAdvisorReport 表索引诊断报告
+type IndexAdvisorReport struct {
+	Table   string            `json:"table"`
+	Healthy bool              `json:"healthy"`
+	Indexes []IndexCheck      `json:"indexes"`
+	Queries []QueryIndexCheck `json:"queries"`
+	// 线上存在, 但是不在期望列表中的索引, 可能是DBA手动添加的
+	UnknownIndexes []string `json:"unknown_indexes"`
+}
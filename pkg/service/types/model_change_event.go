@@ -19,4 +19,8 @@ type ModelChangeEvent struct {
 	ModelType string `json:"model_type" structs:"model_type"`
 	ModelID   string `json:"model_id" structs:"model_id"`
 	ModelPK   int64  `json:"model_pk" structs:"model_pk"`
+
+	// 接入系统回调的投递状态, 系统未注册回调时为空
+	CallbackStatus  string `json:"callback_status" structs:"callback_status"`
+	CallbackMessage string `json:"callback_message" structs:"callback_message"`
 }
@@ -14,10 +14,12 @@ package types
 
 // AuthPolicy for auth
 type AuthPolicy struct {
-	PK           int64 `msgpack:"p"`
-	SubjectPK    int64 `msgpack:"s"`
-	ExpressionPK int64 `msgpack:"e1"`
-	ExpiredAt    int64 `msgpack:"e2"`
+	PK           int64  `msgpack:"p"`
+	SubjectPK    int64  `msgpack:"s"`
+	ExpressionPK int64  `msgpack:"e1"`
+	ExpiredAt    int64  `msgpack:"e2"`
+	Effect       string `msgpack:"ef"`
+	Priority     int64  `msgpack:"pr"`
 }
 
 // GetPK return the Primary key of auth policy
@@ -56,6 +58,8 @@ type QueryPolicy struct {
 // EngineQueryPolicy query policy for iam engine
 type EngineQueryPolicy struct {
 	QueryPolicy
+	Effect     string
+	Priority   int64
 	TemplateID int64
 	UpdatedAt  int64
 }
@@ -69,16 +73,28 @@ type Policy struct {
 	ActionPK   int64
 	Expression string
 	Signature  string
+	Effect     string
+	Priority   int64
 
 	ExpiredAt  int64
 	TemplateID int64
 }
 
+// GetEffect 未指定效果的策略默认为allow
+func (p Policy) GetEffect() string {
+	if p.Effect == PolicyEffectDeny {
+		return PolicyEffectDeny
+	}
+	return PolicyEffectAllow
+}
+
 // ThinPolicy ...
 type ThinPolicy struct {
 	Version string
 	ID      int64
 
 	ActionPK  int64
+	Effect    string
+	Priority  int64
 	ExpiredAt int64
 }
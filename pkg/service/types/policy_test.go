@@ -40,4 +40,20 @@ var _ = Describe("policy", func() {
 
 	})
 
+	Describe("Policy cases", func() {
+
+		Describe("GetEffect", func() {
+
+			It("default allow", func() {
+				assert.Equal(GinkgoT(), types.PolicyEffectAllow, types.Policy{}.GetEffect())
+				assert.Equal(GinkgoT(), types.PolicyEffectAllow, types.Policy{Effect: "other"}.GetEffect())
+			})
+
+			It("deny", func() {
+				assert.Equal(GinkgoT(), types.PolicyEffectDeny, types.Policy{Effect: "deny"}.GetEffect())
+			})
+		})
+
+	})
+
 })
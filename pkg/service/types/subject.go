@@ -37,8 +37,198 @@ type SubjectGroup struct {
 	CreateAt        time.Time `json:"created_at"`
 }
 
+// SubjectRole 角色类型及其管理的系统, super_manager的系统为SUPER
+type SubjectRole struct {
+	RoleType string `json:"role_type"`
+	System   string `json:"system_id"`
+}
+
+// SubjectRoleHolder 角色及其授予的subject
+type SubjectRoleHolder struct {
+	RoleType    string `json:"role_type"`
+	System      string `json:"system_id"`
+	SubjectType string `json:"subject_type"`
+	SubjectID   string `json:"subject_id"`
+	SubjectName string `json:"subject_name"`
+}
+
+// GroupSetting 用户组的成员配置, 值为0表示不限制
+type GroupSetting struct {
+	// 成员数量上限
+	MaxMembers int64 `json:"max_members"`
+	// 添加成员未指定过期时间时, 默认的过期天数
+	DefaultExpirationDays int64 `json:"default_expiration_days"`
+	// 成员过期时间最多为当前时间之后的天数
+	MaxExpirationDays int64 `json:"max_expiration_days"`
+	// 成员在过期前多少天内才可以续期
+	RenewalWindowDays int64 `json:"renewal_window_days"`
+}
+
 // SubjectDepartment 用户的部门ID列表
 type SubjectDepartment struct {
 	SubjectID     string   `json:"id"`
 	DepartmentIDs []string `json:"departments"`
+	// 可选, 部门的上级部门关系, 用于计算用户所属部门的所有上级部门
+	DepartmentParents []DepartmentParent `json:"department_parents,omitempty"`
+}
+
+// DepartmentParent 部门及其上级部门
+type DepartmentParent struct {
+	ID       string `json:"id"`
+	ParentID string `json:"parent_id"`
+}
+
+// SubjectDepartmentBulkResult 批量创建用户部门关系的结果, 按批次提交, 单个批次失败不影响其他批次
+type SubjectDepartmentBulkResult struct {
+	Total   int                            `json:"total"`
+	Created int                            `json:"created"`
+	Failed  []SubjectDepartmentBulkFailure `json:"failed"`
+}
+
+// SubjectDepartmentBulkFailure 提交失败的批次
+type SubjectDepartmentBulkFailure struct {
+	SubjectIDs []string `json:"subject_ids"`
+	Error      string   `json:"error"`
+}
+
+// SubjectDepartmentHistory 用户部门关系的变更记录
+type SubjectDepartmentHistory struct {
+	DepartmentID   string    `json:"department_id"`
+	DepartmentName string    `json:"department_name"`
+	Action         string    `json:"action"`
+	Source         string    `json:"source"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// SubjectRoleHistory 角色授予/回收的变更记录, CreatedAt即变更的生效时间
+type SubjectRoleHistory struct {
+	SubjectType string    `json:"subject_type"`
+	SubjectID   string    `json:"subject_id"`
+	SubjectName string    `json:"subject_name"`
+	Action      string    `json:"action"`
+	Operator    string    `json:"operator"`
+	Source      string    `json:"source"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SubjectMemberCopyResult 复制/移动用户组成员的结果
+type SubjectMemberCopyResult struct {
+	// 新加入目标用户组的成员
+	Added []SubjectMember
+	// 已在目标用户组中, 过期时间被延长的成员
+	Renewed []SubjectMember
+	// 移动时从源用户组移除的成员
+	Removed []SubjectMember
+}
+
+// SubjectMemberSnapshotMember 快照中的成员
+type SubjectMemberSnapshotMember struct {
+	Type            string `json:"type"`
+	ID              string `json:"id"`
+	PolicyExpiredAt int64  `json:"policy_expired_at"`
+}
+
+// SubjectMemberSnapshot 用户组成员列表的快照, 列表查询时不包含Members
+type SubjectMemberSnapshot struct {
+	PK          int64                         `json:"id"`
+	GroupType   string                        `json:"group_type"`
+	GroupID     string                        `json:"group_id"`
+	MemberCount int64                         `json:"member_count"`
+	Members     []SubjectMemberSnapshotMember `json:"members,omitempty"`
+	Operator    string                        `json:"operator"`
+	Source      string                        `json:"source"`
+	CreatedAt   time.Time                     `json:"created_at"`
+}
+
+// SubjectMemberRestoreResult 从快照恢复用户组成员的结果
+type SubjectMemberRestoreResult struct {
+	// 恢复的用户组
+	Group Subject
+	// 恢复前自动创建的当前成员列表的快照
+	BackupSnapshotPK int64
+	// 恢复加入的成员
+	Added []SubjectMember
+	// 恢复了过期时间的成员
+	Updated []SubjectMember
+	// 不在快照中被移除的成员
+	Removed []SubjectMember
+	// 快照中已不存在的subject, 不做恢复
+	Skipped []Subject
+}
+
+// SubjectMemberEvent 用户组成员的变更记录, CreatedAt即变更的生效时间
+type SubjectMemberEvent struct {
+	GroupType       string    `json:"group_type"`
+	GroupID         string    `json:"group_id"`
+	MemberType      string    `json:"member_type"`
+	MemberID        string    `json:"member_id"`
+	Action          string    `json:"action"`
+	PolicyExpiredAt int64     `json:"policy_expired_at"`
+	Operator        string    `json:"operator"`
+	Source          string    `json:"source"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// SubjectMemberEventFilter 查询成员变更记录的过滤条件, 空值表示不过滤; 时间范围为[StartTime, EndTime)
+type SubjectMemberEventFilter struct {
+	GroupType string
+	GroupID   string
+	Operator  string
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// ExportSubject 导出的subject, PK用于关联关系数据
+type ExportSubject struct {
+	PK   int64
+	Type string
+	ID   string
+	Name string
+}
+
+// ExportSubjectRelation 导出的subject-group关系
+type ExportSubjectRelation struct {
+	PK              int64
+	SubjectPK       int64
+	SubjectType     string
+	SubjectID       string
+	ParentPK        int64
+	ParentType      string
+	ParentID        string
+	PolicyExpiredAt int64
+}
+
+// ExportSubjectDepartment 导出的用户-部门关系
+type ExportSubjectDepartment struct {
+	PK            int64
+	SubjectPK     int64
+	DepartmentPKs []int64
+}
+
+// InheritedDepartment 成员通过该部门继承了用户组
+type InheritedDepartment struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	PolicyExpiredAt int64  `json:"policy_expired_at"`
+}
+
+// GroupEffectiveUser 用户组展开后的有效用户: 直接加入, 或者所在的部门(及上级部门)是用户组的成员, 包含嵌套加入的用户组
+type GroupEffectiveUser struct {
+	PK   int64  `json:"pk"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// 多个来源时取最早的过期时间
+	PolicyExpiredAt int64 `json:"policy_expired_at"`
+}
+
+// RedundantMember 冗余的用户组成员关系: 直接加入且通过部门继承, 或者通过多个部门继承
+type RedundantMember struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// 直接加入的过期时间, 0表示不是直接加入的成员
+	PolicyExpiredAt int64                 `json:"policy_expired_at"`
+	Departments     []InheritedDepartment `json:"departments"`
+	// 直接加入的关系被部门完全覆盖(部门的过期时间不早于直接加入的), 可以移除
+	Removable bool `json:"removable"`
 }
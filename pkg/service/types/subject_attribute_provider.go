@@ -0,0 +1,43 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package types
+
+import "time"
+
+// SubjectAttributeProviderTypeHTTP 属性提供方的类型
+const (
+	// 回调提供方的接口查询subject的属性
+	SubjectAttributeProviderTypeHTTP = "http"
+	// 静态的subject属性映射
+	SubjectAttributeProviderTypeStatic = "static"
+)
+
+// SubjectAttributeProvider 外部的subject属性提供方, 提供的属性可以在条件中以subject.{attribute}引用
+type SubjectAttributeProvider struct {
+	ID         string                         `json:"id"`
+	Type       string                         `json:"type"`
+	Attributes []string                       `json:"attributes"`
+	Config     SubjectAttributeProviderConfig `json:"config"`
+	// http类型查询结果的缓存时间(秒), 0使用默认的缓存时间
+	CacheTTL  int64     `json:"cache_ttl"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SubjectAttributeProviderConfig the config of the provider
+type SubjectAttributeProviderConfig struct {
+	// http
+	URL     string `json:"url,omitempty"`
+	Token   string `json:"token,omitempty"`
+	Timeout int64  `json:"timeout,omitempty"` // seconds
+
+	// static, key={subject_type}:{subject_id}
+	Mapping map[string]map[string]interface{} `json:"mapping,omitempty"`
+}
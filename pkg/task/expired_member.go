@@ -0,0 +1,82 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package task
+
+import (
+	"context"
+	"time"
+
+	"iam/pkg/config"
+	"iam/pkg/errorx"
+	"iam/pkg/metric"
+	"iam/pkg/service"
+)
+
+const (
+	defaultExpiredMemberRetentionDays = 90
+	defaultExpiredMemberPurgeInterval = 1 * time.Hour
+	defaultExpiredMemberPurgeBatch    = 1000
+)
+
+// expiredMemberPurgeTask 清理过期超过一定天数的用户组成员关系, 过期的成员不再生效, 只保留一段时间用于续期
+type expiredMemberPurgeTask struct {
+	svc           service.SubjectService
+	retentionDays int64
+	batchSize     int64
+}
+
+// NewExpiredMemberPurgeTask ...
+func NewExpiredMemberPurgeTask(cfg config.ExpiredMemberPurge) Task {
+	t := &expiredMemberPurgeTask{
+		svc:           service.NewBatchSubjectService(),
+		retentionDays: cfg.RetentionDays,
+		batchSize:     cfg.BatchSize,
+	}
+	if t.retentionDays <= 0 {
+		t.retentionDays = defaultExpiredMemberRetentionDays
+	}
+	if t.batchSize <= 0 {
+		t.batchSize = defaultExpiredMemberPurgeBatch
+	}
+	return t
+}
+
+// ExpiredMemberPurgeInterval 任务的执行周期
+func ExpiredMemberPurgeInterval(cfg config.ExpiredMemberPurge) time.Duration {
+	if cfg.Interval <= 0 {
+		return defaultExpiredMemberPurgeInterval
+	}
+	return time.Duration(cfg.Interval) * time.Second
+}
+
+// Name ...
+func (t *expiredMemberPurgeTask) Name() string {
+	return "expired_member_purge"
+}
+
+// Run 分批删除, 每批一个事务, 直到某一批的数量不足batchSize
+func (t *expiredMemberPurgeTask) Run(ctx context.Context) error {
+	expiredAt := time.Now().Unix() - t.retentionDays*24*60*60
+
+	for ctx.Err() == nil {
+		count, err := t.svc.PurgeExpiredMembers(expiredAt, t.batchSize)
+		if err != nil {
+			return errorx.Wrapf(err, TaskLayer, "expiredMemberPurgeTask.Run",
+				"svc.PurgeExpiredMembers expiredAt=`%d`, batchSize=`%d` fail", expiredAt, t.batchSize)
+		}
+		metric.ExpiredSubjectRelationPurgeCount.Add(float64(count))
+
+		// NOTE: 查询后被续期的成员不会删除, 数量不足时剩余的在下个周期继续清理
+		if count < t.batchSize {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package task
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/config"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+)
+
+var _ = Describe("ExpiredMemberPurgeTask", func() {
+	It("NewExpiredMemberPurgeTask default", func() {
+		ctl := gomock.NewController(GinkgoT())
+		defer ctl.Finish()
+
+		patches := gomonkey.ApplyFunc(service.NewBatchSubjectService, func() service.SubjectService {
+			return mock.NewMockSubjectService(ctl)
+		})
+		defer patches.Reset()
+
+		t := NewExpiredMemberPurgeTask(config.ExpiredMemberPurge{}).(*expiredMemberPurgeTask)
+		assert.Equal(GinkgoT(), int64(defaultExpiredMemberRetentionDays), t.retentionDays)
+		assert.Equal(GinkgoT(), int64(defaultExpiredMemberPurgeBatch), t.batchSize)
+
+		assert.Equal(GinkgoT(), defaultExpiredMemberPurgeInterval,
+			ExpiredMemberPurgeInterval(config.ExpiredMemberPurge{}))
+		assert.Equal(GinkgoT(), 10*time.Second, ExpiredMemberPurgeInterval(config.ExpiredMemberPurge{Interval: 10}))
+	})
+
+	Describe("Run", func() {
+		var ctl *gomock.Controller
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("svc.PurgeExpiredMembers fail", func() {
+			mockService := mock.NewMockSubjectService(ctl)
+			mockService.EXPECT().PurgeExpiredMembers(gomock.Any(), int64(10)).Return(int64(0), errors.New("error"))
+
+			t := &expiredMemberPurgeTask{svc: mockService, retentionDays: 1, batchSize: 10}
+			err := t.Run(context.Background())
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "PurgeExpiredMembers")
+		})
+
+		It("purge in batches", func() {
+			mockService := mock.NewMockSubjectService(ctl)
+			gomock.InOrder(
+				mockService.EXPECT().PurgeExpiredMembers(gomock.Any(), int64(10)).Return(int64(10), nil),
+				mockService.EXPECT().PurgeExpiredMembers(gomock.Any(), int64(10)).Return(int64(10), nil),
+				mockService.EXPECT().PurgeExpiredMembers(gomock.Any(), int64(10)).Return(int64(3), nil),
+			)
+
+			t := &expiredMemberPurgeTask{svc: mockService, retentionDays: 1, batchSize: 10}
+			err := t.Run(context.Background())
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("stop when ctx done", func() {
+			mockService := mock.NewMockSubjectService(ctl)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			t := &expiredMemberPurgeTask{svc: mockService, retentionDays: 1, batchSize: 10}
+			err := t.Run(ctx)
+			assert.NoError(GinkgoT(), err)
+		})
+	})
+})
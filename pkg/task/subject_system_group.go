@@ -0,0 +1,76 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package task
+
+import (
+	"context"
+	"time"
+
+	"iam/pkg/config"
+	"iam/pkg/errorx"
+	"iam/pkg/metric"
+	"iam/pkg/service"
+)
+
+const (
+	defaultSubjectSystemGroupReconcileInterval = 1 * time.Hour
+	defaultSubjectSystemGroupReconcileBatch    = 500
+)
+
+// subjectSystemGroupReconcileTask 比对并修正所有subject的subject_system_group记录
+// 记录在成员/策略变更提交后维护, 失败时只记录日志, 缺少的记录会导致鉴权失败, 由该任务兜底
+type subjectSystemGroupReconcileTask struct {
+	svc       service.SubjectSystemGroupService
+	batchSize int64
+}
+
+// NewSubjectSystemGroupReconcileTask ...
+func NewSubjectSystemGroupReconcileTask(cfg config.SubjectSystemGroupReconcile) Task {
+	t := &subjectSystemGroupReconcileTask{
+		svc:       service.NewSubjectSystemGroupService(),
+		batchSize: cfg.BatchSize,
+	}
+	if t.batchSize <= 0 {
+		t.batchSize = defaultSubjectSystemGroupReconcileBatch
+	}
+	return t
+}
+
+// SubjectSystemGroupReconcileInterval 任务的执行周期
+func SubjectSystemGroupReconcileInterval(cfg config.SubjectSystemGroupReconcile) time.Duration {
+	if cfg.Interval <= 0 {
+		return defaultSubjectSystemGroupReconcileInterval
+	}
+	return time.Duration(cfg.Interval) * time.Second
+}
+
+// Name ...
+func (t *subjectSystemGroupReconcileTask) Name() string {
+	return "subject_system_group_reconcile"
+}
+
+// Run 按subject pk分批比对, 直到没有更多的subject
+func (t *subjectSystemGroupReconcileTask) Run(ctx context.Context) error {
+	var afterPK int64
+	for ctx.Err() == nil {
+		lastPK, changed, err := t.svc.ReconcileSubjects(afterPK, t.batchSize)
+		if err != nil {
+			return errorx.Wrapf(err, TaskLayer, "subjectSystemGroupReconcileTask.Run",
+				"svc.ReconcileSubjects afterPK=`%d`, batchSize=`%d` fail", afterPK, t.batchSize)
+		}
+		metric.SubjectSystemGroupReconcileCount.Add(float64(changed))
+
+		if lastPK == 0 {
+			return nil
+		}
+		afterPK = lastPK
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package task
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+
+	"iam/pkg/config"
+	"iam/pkg/service"
+	"iam/pkg/service/mock"
+)
+
+var _ = Describe("SubjectSystemGroupReconcileTask", func() {
+	It("NewSubjectSystemGroupReconcileTask default", func() {
+		ctl := gomock.NewController(GinkgoT())
+		defer ctl.Finish()
+
+		patches := gomonkey.ApplyFunc(service.NewSubjectSystemGroupService, func() service.SubjectSystemGroupService {
+			return mock.NewMockSubjectSystemGroupService(ctl)
+		})
+		defer patches.Reset()
+
+		t := NewSubjectSystemGroupReconcileTask(config.SubjectSystemGroupReconcile{}).(*subjectSystemGroupReconcileTask)
+		assert.Equal(GinkgoT(), int64(defaultSubjectSystemGroupReconcileBatch), t.batchSize)
+
+		assert.Equal(GinkgoT(), defaultSubjectSystemGroupReconcileInterval,
+			SubjectSystemGroupReconcileInterval(config.SubjectSystemGroupReconcile{}))
+		assert.Equal(GinkgoT(), 10*time.Second,
+			SubjectSystemGroupReconcileInterval(config.SubjectSystemGroupReconcile{Interval: 10}))
+	})
+
+	Describe("Run", func() {
+		var ctl *gomock.Controller
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+		})
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("svc.ReconcileSubjects fail", func() {
+			mockService := mock.NewMockSubjectSystemGroupService(ctl)
+			mockService.EXPECT().ReconcileSubjects(int64(0), int64(10)).Return(int64(0), int64(0), errors.New("error"))
+
+			t := &subjectSystemGroupReconcileTask{svc: mockService, batchSize: 10}
+			err := t.Run(context.Background())
+			assert.Error(GinkgoT(), err)
+			assert.Contains(GinkgoT(), err.Error(), "ReconcileSubjects")
+		})
+
+		It("reconcile in batches", func() {
+			mockService := mock.NewMockSubjectSystemGroupService(ctl)
+			gomock.InOrder(
+				mockService.EXPECT().ReconcileSubjects(int64(0), int64(10)).Return(int64(12), int64(1), nil),
+				mockService.EXPECT().ReconcileSubjects(int64(12), int64(10)).Return(int64(30), int64(0), nil),
+				mockService.EXPECT().ReconcileSubjects(int64(30), int64(10)).Return(int64(0), int64(0), nil),
+			)
+
+			t := &subjectSystemGroupReconcileTask{svc: mockService, batchSize: 10}
+			err := t.Run(context.Background())
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("stop when ctx done", func() {
+			mockService := mock.NewMockSubjectSystemGroupService(ctl)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			t := &subjectSystemGroupReconcileTask{svc: mockService, batchSize: 10}
+			err := t.Run(ctx)
+			assert.NoError(GinkgoT(), err)
+		})
+	})
+})
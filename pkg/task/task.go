@@ -0,0 +1,78 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package task
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"iam/pkg/cache/impls"
+	"iam/pkg/metric"
+)
+
+// TaskLayer ...
+const TaskLayer = "Task"
+
+// task run status, for metrics
+const (
+	runStatusSuccess = "success"
+	runStatusFailed  = "failed"
+	runStatusSkipped = "skipped"
+)
+
+// Task 定时任务, 每个周期内所有实例中只有一个实例执行
+type Task interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+var acquireTaskLock = impls.AcquireTaskLock
+
+// Schedule 按周期执行任务, 阻塞直到ctx结束
+func Schedule(ctx context.Context, task Task, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runOnce(ctx, task, interval)
+		}
+	}
+}
+
+func runOnce(ctx context.Context, task Task, interval time.Duration) {
+	// NOTE: 锁的过期时间比周期略短, 保证下个周期可以重新竞争
+	locked, err := acquireTaskLock(task.Name(), interval-interval/10)
+	if err != nil {
+		log.WithError(err).Errorf("acquire the lock of task `%s` fail", task.Name())
+		metric.TaskRunCount.WithLabelValues(task.Name(), runStatusFailed).Inc()
+		return
+	}
+	if !locked {
+		metric.TaskRunCount.WithLabelValues(task.Name(), runStatusSkipped).Inc()
+		return
+	}
+
+	start := time.Now()
+	err = task.Run(ctx)
+	if err != nil {
+		log.WithError(err).Errorf("run task `%s` fail", task.Name())
+		metric.TaskRunCount.WithLabelValues(task.Name(), runStatusFailed).Inc()
+		return
+	}
+
+	log.Infof("run task `%s` success, took %s", task.Name(), time.Since(start))
+	metric.TaskRunCount.WithLabelValues(task.Name(), runStatusSuccess).Inc()
+}
@@ -0,0 +1,94 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making 蓝鲸智云-权限中心(BlueKing-IAM) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package task
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockTask struct {
+	runs int
+	err  error
+}
+
+func (t *mockTask) Name() string {
+	return "mock"
+}
+
+func (t *mockTask) Run(ctx context.Context) error {
+	t.runs++
+	return t.err
+}
+
+var _ = Describe("Task", func() {
+	var originAcquireTaskLock func(name string, expiration time.Duration) (bool, error)
+	BeforeEach(func() {
+		originAcquireTaskLock = acquireTaskLock
+	})
+	AfterEach(func() {
+		acquireTaskLock = originAcquireTaskLock
+	})
+
+	Describe("runOnce", func() {
+		It("acquire lock fail", func() {
+			acquireTaskLock = func(name string, expiration time.Duration) (bool, error) {
+				return false, errors.New("error")
+			}
+
+			t := &mockTask{}
+			runOnce(context.Background(), t, time.Minute)
+			assert.Equal(GinkgoT(), 0, t.runs)
+		})
+
+		It("locked by other instance", func() {
+			acquireTaskLock = func(name string, expiration time.Duration) (bool, error) {
+				return false, nil
+			}
+
+			t := &mockTask{}
+			runOnce(context.Background(), t, time.Minute)
+			assert.Equal(GinkgoT(), 0, t.runs)
+		})
+
+		It("ok", func() {
+			var lockExpiration time.Duration
+			acquireTaskLock = func(name string, expiration time.Duration) (bool, error) {
+				assert.Equal(GinkgoT(), "mock", name)
+				lockExpiration = expiration
+				return true, nil
+			}
+
+			t := &mockTask{}
+			runOnce(context.Background(), t, time.Minute)
+			assert.Equal(GinkgoT(), 1, t.runs)
+			assert.Equal(GinkgoT(), 54*time.Second, lockExpiration)
+		})
+	})
+
+	Describe("Schedule", func() {
+		It("run until ctx done", func() {
+			acquireTaskLock = func(name string, expiration time.Duration) (bool, error) {
+				return true, nil
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 55*time.Millisecond)
+			defer cancel()
+
+			t := &mockTask{err: errors.New("error")}
+			Schedule(ctx, t, 10*time.Millisecond)
+			assert.GreaterOrEqual(GinkgoT(), t.runs, 3)
+		})
+	})
+})
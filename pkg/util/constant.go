@@ -15,6 +15,9 @@ const (
 	RequestIDKey       = "request_id"
 	RequestIDHeaderKey = "X-Request-Id"
 
+	// TimingHeaderKey the time spent of the request, only for the requests with debug
+	TimingHeaderKey = "X-IAM-Timing"
+
 	ClientIDKey = "client_id"
 
 	ErrorIDKey = "err"
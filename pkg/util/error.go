@@ -11,6 +11,8 @@
 package util
 
 import (
+	"errors"
+	"net/http"
 	"time"
 
 	"github.com/getsentry/sentry-go"
@@ -29,8 +31,59 @@ const (
 	ConflictError     = 1901409
 	SystemError       = 1901500
 	TooManyRequests   = 1901429
+
+	QuotaExceededError  = 1901422
+	ActionDisabledError = 1901423
+	DependencyError     = 1901502
 )
 
+// CodeError the typed error with a stable code and http status
+// NOTE: wrap it via fmt.Errorf("%w")/errorx.Wrapf, then SystemErrorJSONResponse will response with the code and status
+type CodeError struct {
+	Code    int
+	Status  int
+	Message string
+}
+
+// Error ...
+func (e *CodeError) Error() string {
+	return e.Message
+}
+
+// the typed errors
+var (
+	ErrBadRequest    = &CodeError{Code: BadRequestError, Status: http.StatusBadRequest, Message: "bad request"}
+	ErrNotFound      = &CodeError{Code: NotFoundError, Status: http.StatusNotFound, Message: "not found"}
+	ErrConflict      = &CodeError{Code: ConflictError, Status: http.StatusConflict, Message: "conflict"}
+	ErrQuotaExceeded = &CodeError{
+		Code:    QuotaExceededError,
+		Status:  http.StatusUnprocessableEntity,
+		Message: "quota exceeded",
+	}
+	ErrDependency     = &CodeError{Code: DependencyError, Status: http.StatusBadGateway, Message: "dependency error"}
+	ErrActionDisabled = &CodeError{Code: ActionDisabledError, Status: http.StatusLocked, Message: "action disabled"}
+)
+
+var codeErrors = []*CodeError{
+	ErrBadRequest,
+	ErrNotFound,
+	ErrConflict,
+	ErrQuotaExceeded,
+	ErrDependency,
+	ErrActionDisabled,
+}
+
+// GetCodeError return the typed error wrapped in the err, nil if not found
+func GetCodeError(err error) *CodeError {
+	// NOTE: errorx.IAMError only support errors.Is, not errors.As
+	for _, e := range codeErrors {
+		if errors.Is(err, e) {
+			return e
+		}
+	}
+	return nil
+}
+
 // ReportToSentry is a shortcut to build and send an event to sentry
 func ReportToSentry(message string, extra map[string]interface{}) {
 	// report to sentry
@@ -16,6 +16,8 @@ import (
 	"reflect"
 
 	"github.com/gin-gonic/gin"
+
+	"iam/pkg/errorx"
 )
 
 // Response ...
@@ -52,12 +54,27 @@ func SuccessJSONResponse(c *gin.Context, message string, data interface{}) {
 	BaseJSONResponse(c, http.StatusOK, NoError, message, data)
 }
 
+// timingReporter the debug info which reports the time spent of the request
+type timingReporter interface {
+	Timing() string
+}
+
+// setTimingHeader set the time spent reported by the debug info to the X-IAM-Timing header
+func setTimingHeader(c *gin.Context, debug interface{}) {
+	if r, ok := debug.(timingReporter); ok {
+		if timing := r.Timing(); timing != "" {
+			c.Header(TimingHeaderKey, timing)
+		}
+	}
+}
+
 // SuccessJSONResponseWithDebug ...
 func SuccessJSONResponseWithDebug(c *gin.Context, message string, data interface{}, debug interface{}) {
 	if debug == nil || reflect.ValueOf(debug).IsNil() {
 		SuccessJSONResponse(c, message, data)
 		return
 	}
+	setTimingHeader(c, debug)
 
 	body := DebugResponse{
 		Response: Response{
@@ -94,11 +111,52 @@ var (
 	TooManyRequestsJSONResponse = NewErrorJSONResponse(TooManyRequests, "too many requests")
 )
 
-// SystemErrorJSONResponse ...
+// NOTE: keep http status 200 for the system error, as before
+var errSystem = &CodeError{Code: SystemError, Status: http.StatusOK, Message: "system error"}
+
+// ErrorDebug the debug info of the error response
+type ErrorDebug struct {
+	// the `layer:function` of errorx wrapped, from the outermost to the innermost
+	Breadcrumb []string `json:"breadcrumb"`
+}
+
+// SystemErrorJSONResponse response the typed error(see CodeError) with its code and status,
+// others with SystemError
 func SystemErrorJSONResponse(c *gin.Context, err error) {
-	message := fmt.Sprintf("system error[request_id=%s]: %s", GetRequestID(c), err.Error())
-	SetError(c, err)
-	BaseErrorJSONResponse(c, SystemError, message)
+	ce := GetCodeError(err)
+	if ce == nil {
+		SetError(c, err)
+		codeErrorJSONResponse(c, errSystem, err)
+		return
+	}
+
+	// the typed errors are expected, not set error(will not be reported to sentry)
+	codeErrorJSONResponse(c, ce, err)
+}
+
+// BadRequestErrorJSONResponseWithError response the typed error with its code and status, others with BadRequestError
+func BadRequestErrorJSONResponseWithError(c *gin.Context, err error) {
+	ce := GetCodeError(err)
+	if ce == nil {
+		ce = ErrBadRequest
+	}
+
+	codeErrorJSONResponse(c, ce, err)
+}
+
+func codeErrorJSONResponse(c *gin.Context, ce *CodeError, err error) {
+	message := fmt.Sprintf("%s[request_id=%s]: %s", ce.Message, GetRequestID(c), err.Error())
+	body := DebugResponse{
+		Response: Response{
+			Code:    ce.Code,
+			Message: message,
+			Data:    gin.H{},
+		},
+		Debug: ErrorDebug{
+			Breadcrumb: errorx.Breadcrumb(err),
+		},
+	}
+	c.JSON(ce.Status, body)
 }
 
 // SystemErrorJSONResponseWithDebug ...
@@ -107,6 +165,20 @@ func SystemErrorJSONResponseWithDebug(c *gin.Context, err error, debug interface
 		SystemErrorJSONResponse(c, err)
 		return
 	}
+	setTimingHeader(c, debug)
+
+	// the typed errors response with its code and status, keep the debug info
+	if ce := GetCodeError(err); ce != nil {
+		c.JSON(ce.Status, DebugResponse{
+			Response: Response{
+				Code:    ce.Code,
+				Message: fmt.Sprintf("%s[request_id=%s]: %s", ce.Message, GetRequestID(c), err.Error()),
+				Data:    gin.H{},
+			},
+			Debug: debug,
+		})
+		return
+	}
 
 	message := fmt.Sprintf("system error[request_id=%s]: %s", GetRequestID(c), err.Error())
 	SetError(c, err)
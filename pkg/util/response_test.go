@@ -13,10 +13,14 @@ package util_test
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
+	"iam/pkg/errorx"
 	"iam/pkg/logging/debug"
 
 	"iam/pkg/util"
@@ -91,6 +95,17 @@ var _ = Describe("Response", func() {
 
 			got := readResponse(w)
 			assert.Equal(GinkgoT(), util.NoError, got.Code)
+			assert.Empty(GinkgoT(), w.Header().Get(util.TimingHeaderKey))
+		})
+
+		It("debug with timing", func() {
+			entry := debug.EntryPool.Get()
+			defer debug.EntryPool.Put(entry)
+			entry.AddTiming(debug.TimingCache, time.Millisecond)
+
+			util.SuccessJSONResponseWithDebug(c, "ok", nil, entry)
+			assert.Equal(GinkgoT(), 200, c.Writer.Status())
+			assert.Contains(GinkgoT(), w.Header().Get(util.TimingHeaderKey), "cache;dur=1.00")
 		})
 	})
 
@@ -110,6 +125,66 @@ var _ = Describe("Response", func() {
 		got := readResponse(w)
 		assert.Equal(GinkgoT(), util.SystemError, got.Code)
 		assert.Contains(GinkgoT(), got.Message, "system error")
+
+		_, hasError := util.GetError(c)
+		assert.True(GinkgoT(), hasError)
+	})
+
+	Context("SystemErrorJSONResponse with CodeError", func() {
+		It("not found", func() {
+			err := errorx.Wrapf(
+				fmt.Errorf("group not exists: %w", util.ErrNotFound), "Service", "GetGroup", "pk=`%d`", 1,
+			)
+			err = errorx.Wrapf(err, "Handler", "GetGroup", "")
+			util.SystemErrorJSONResponse(c, err)
+			assert.Equal(GinkgoT(), http.StatusNotFound, c.Writer.Status())
+
+			var got util.DebugResponse
+			assert.NoError(GinkgoT(), json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(GinkgoT(), util.NotFoundError, got.Code)
+			assert.Contains(GinkgoT(), got.Message, "not found")
+			assert.Equal(GinkgoT(), map[string]interface{}{
+				"breadcrumb": []interface{}{"Handler:GetGroup", "Service:GetGroup"},
+			}, got.Debug)
+
+			_, hasError := util.GetError(c)
+			assert.False(GinkgoT(), hasError)
+		})
+
+		It("conflict", func() {
+			util.SystemErrorJSONResponse(c, fmt.Errorf("dup: %w", util.ErrConflict))
+			assert.Equal(GinkgoT(), http.StatusConflict, c.Writer.Status())
+			assert.Equal(GinkgoT(), util.ConflictError, readResponse(w).Code)
+		})
+
+		It("quota exceeded", func() {
+			util.SystemErrorJSONResponse(c, fmt.Errorf("too many: %w", util.ErrQuotaExceeded))
+			assert.Equal(GinkgoT(), http.StatusUnprocessableEntity, c.Writer.Status())
+			assert.Equal(GinkgoT(), util.QuotaExceededError, readResponse(w).Code)
+		})
+
+		It("dependency", func() {
+			util.SystemErrorJSONResponse(c, fmt.Errorf("call usermgr fail: %w", util.ErrDependency))
+			assert.Equal(GinkgoT(), http.StatusBadGateway, c.Writer.Status())
+			assert.Equal(GinkgoT(), util.DependencyError, readResponse(w).Code)
+		})
+	})
+
+	Context("BadRequestErrorJSONResponseWithError", func() {
+		It("plain error", func() {
+			util.BadRequestErrorJSONResponseWithError(c, errors.New("anError"))
+			assert.Equal(GinkgoT(), http.StatusBadRequest, c.Writer.Status())
+
+			got := readResponse(w)
+			assert.Equal(GinkgoT(), util.BadRequestError, got.Code)
+			assert.Contains(GinkgoT(), got.Message, "anError")
+		})
+
+		It("code error", func() {
+			util.BadRequestErrorJSONResponseWithError(c, fmt.Errorf("dup: %w", util.ErrConflict))
+			assert.Equal(GinkgoT(), http.StatusConflict, c.Writer.Status())
+			assert.Equal(GinkgoT(), util.ConflictError, readResponse(w).Code)
+		})
 	})
 
 	Context("SystemErrorJSONResponseWithDebug", func() {
@@ -129,6 +204,15 @@ var _ = Describe("Response", func() {
 			got := readResponse(w)
 			assert.Equal(GinkgoT(), util.SystemError, got.Code)
 		})
+
+		It("code error with debug", func() {
+			err := fmt.Errorf("disabled: %w", util.ErrActionDisabled)
+			util.SystemErrorJSONResponseWithDebug(c, err, map[string]interface{}{"hello": "world"})
+			assert.Equal(GinkgoT(), http.StatusLocked, c.Writer.Status())
+
+			got := readResponse(w)
+			assert.Equal(GinkgoT(), util.ActionDisabledError, got.Code)
+		})
 	})
 
 })
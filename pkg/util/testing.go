@@ -235,6 +235,13 @@ func (g *GinAPIRequest) JSON(data interface{}) *GinAPIRequest {
 	return g
 }
 
+// QueryParams ...
+func (g *GinAPIRequest) QueryParams(params map[string]string) *GinAPIRequest {
+	g.request.QueryParams(params)
+
+	return g
+}
+
 // NoJSON ...
 func (g *GinAPIRequest) NoJSON() {
 	g.request.
@@ -273,6 +280,32 @@ func (g *GinAPIRequest) BadRequestContainsMessage(message string) {
 		End()
 }
 
+// ConflictContainsMessage assert the conflict message field should contains a specific message
+func (g *GinAPIRequest) ConflictContainsMessage(message string) {
+	g.request.
+		Expect(g.t).
+		Assert(NewResponseAssertFunc(g.t, func(resp Response) error {
+			assert.Equal(g.t, ConflictError, resp.Code)
+			assert.Contains(g.t, resp.Message, message)
+			return nil
+		})).
+		Status(http.StatusOK).
+		End()
+}
+
+// NotFoundContainsMessage ...
+func (g *GinAPIRequest) NotFoundContainsMessage(message string) {
+	g.request.
+		Expect(g.t).
+		Assert(NewResponseAssertFunc(g.t, func(resp Response) error {
+			assert.Equal(g.t, NotFoundError, resp.Code)
+			assert.Contains(g.t, resp.Message, message)
+			return nil
+		})).
+		Status(http.StatusOK).
+		End()
+}
+
 // SystemError ...
 func (g *GinAPIRequest) SystemError() {
 	g.request.
@@ -34,7 +34,7 @@ func (v ValidationFieldError) String() string {
 	e := v.Err
 
 	switch e.Tag() {
-	case "required":
+	case "required", "required_without":
 		return fmt.Sprintf("%s is required", e.Field())
 	case "max":
 		return fmt.Sprintf("%s cannot be longer than %s", e.Field(), e.Param())